@@ -0,0 +1,578 @@
+package main
+
+// dag.go — composite pipelines: a DAG of scenarios where a node runs only
+// after every node it depends_on has succeeded (dimensions before facts).
+//
+// A DAG is not a new kind of job. Each node is an ordinary scenario run
+// through the same executor.Submit path as cron, manual activation and
+// pub/sub — approval, trust gate, runner resolution and job audit apply to
+// every node exactly as they would to a standalone run. What this file adds
+// is only the ordering on top: when a node may start, how often it is
+// retried, and what its failure means for the rest of the graph.
+//
+//	dags:
+//	  - id: nightly-dwh
+//	    schedule: "0 3 * * *"        # optional; omit for manual-only DAGs
+//	    timezone: Europe/Moscow
+//	    params: { period: "{{current_date}}" }
+//	    nodes:
+//	      - id: dims
+//	        scenario: load-dimensions
+//	        retries: 2
+//	        retry_delay: 1m
+//	      - id: facts
+//	        scenario: load-facts
+//	        depends_on: [dims]
+//	      - id: report
+//	        scenario: export-report
+//	        depends_on: [facts]
+//	        on_failure: continue
+//
+// Run graphs live in memory (the last maxDAGRuns runs); the jobs they
+// started are persisted like any other job and outlive a restart.
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+)
+
+// FailurePolicy decides what a node's final failure means for the rest of the DAG.
+type FailurePolicy string
+
+const (
+	// FailStop (default): start no further nodes; the ones already running finish.
+	FailStop FailurePolicy = "stop"
+	// FailSkipDependents: skip everything downstream of the node; independent branches carry on.
+	FailSkipDependents FailurePolicy = "skip_dependents"
+	// FailContinue: treat the failure as satisfied — dependents still run.
+	FailContinue FailurePolicy = "continue"
+)
+
+const (
+	defaultDAGRetryDelay = 30 * time.Second
+	defaultDAGPoll       = 2 * time.Second
+	maxDAGRuns           = 100
+)
+
+// ErrDAGNotFound is returned by Start for an unknown DAG id.
+var ErrDAGNotFound = errors.New("dag not found")
+
+// DAGNodeDef is one scenario in a DAG.
+type DAGNodeDef struct {
+	ID         string            `yaml:"id" json:"id"`
+	Scenario   string            `yaml:"scenario" json:"scenario"`
+	DependsOn  []string          `yaml:"depends_on" json:"depends_on,omitempty"`
+	Params     map[string]string `yaml:"params" json:"params,omitempty"` // override DAG-level params
+	Retries    int               `yaml:"retries" json:"retries,omitempty"`
+	RetryDelay string            `yaml:"retry_delay" json:"retry_delay,omitempty"` // Go duration; default 30s
+	OnFailure  FailurePolicy     `yaml:"on_failure" json:"on_failure,omitempty"`   // default: stop
+
+	retryDelay time.Duration
+}
+
+// DAGDef is a named graph of dependent scenarios.
+type DAGDef struct {
+	ID          string            `yaml:"id" json:"id"`
+	Description string            `yaml:"description" json:"description,omitempty"`
+	Schedule    string            `yaml:"schedule" json:"schedule,omitempty"` // cron expression; empty = manual only
+	Timezone    string            `yaml:"timezone" json:"timezone,omitempty"` // for magic params, as in schedules
+	Params      map[string]string `yaml:"params" json:"params,omitempty"`     // passed to every node
+	Nodes       []DAGNodeDef      `yaml:"nodes" json:"nodes"`
+
+	order []string // node ids in dependency order, set by validate
+}
+
+type dagFile struct {
+	DAGs []DAGDef `yaml:"dags"`
+}
+
+// LoadDAGs reads a dags.yaml file and validates each graph's structure
+// (node ids, dependencies, cycles, policies). Scenario names are checked
+// separately by ValidateDAGScenarios, once scenarios are loaded.
+func LoadDAGs(path string) (map[string]*DAGDef, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("dag: read %s: %w", path, err)
+	}
+	var df dagFile
+	if err := yaml.Unmarshal(data, &df); err != nil {
+		return nil, fmt.Errorf("dag: parse %s: %w", path, err)
+	}
+	dags := make(map[string]*DAGDef, len(df.DAGs))
+	for i := range df.DAGs {
+		d := &df.DAGs[i]
+		if d.ID == "" {
+			return nil, fmt.Errorf("dag: %s: dag %d missing id", path, i)
+		}
+		if _, dup := dags[d.ID]; dup {
+			return nil, fmt.Errorf("dag: %s: duplicate dag id %q", path, d.ID)
+		}
+		if err := d.validate(); err != nil {
+			return nil, fmt.Errorf("dag %q: %w", d.ID, err)
+		}
+		dags[d.ID] = d
+	}
+	return dags, nil
+}
+
+// validate checks node ids, dependencies and policies, fills defaults, and
+// rejects cycles — a cycle would leave its nodes pending forever.
+func (d *DAGDef) validate() error {
+	if len(d.Nodes) == 0 {
+		return fmt.Errorf("no nodes")
+	}
+	ids := make(map[string]bool, len(d.Nodes))
+	for i := range d.Nodes {
+		n := &d.Nodes[i]
+		if n.ID == "" || n.Scenario == "" {
+			return fmt.Errorf("node %d missing id or scenario", i)
+		}
+		if ids[n.ID] {
+			return fmt.Errorf("duplicate node id %q", n.ID)
+		}
+		ids[n.ID] = true
+		if n.Retries < 0 {
+			return fmt.Errorf("node %q: retries must be >= 0", n.ID)
+		}
+		n.retryDelay = defaultDAGRetryDelay
+		if n.RetryDelay != "" {
+			delay, err := time.ParseDuration(n.RetryDelay)
+			if err != nil || delay < 0 {
+				return fmt.Errorf("node %q: invalid retry_delay %q", n.ID, n.RetryDelay)
+			}
+			n.retryDelay = delay
+		}
+		switch n.OnFailure {
+		case "":
+			n.OnFailure = FailStop
+		case FailStop, FailSkipDependents, FailContinue:
+		default:
+			return fmt.Errorf("node %q: unknown on_failure %q (expected stop, skip_dependents or continue)", n.ID, n.OnFailure)
+		}
+	}
+	for _, n := range d.Nodes {
+		for _, dep := range n.DependsOn {
+			if !ids[dep] {
+				return fmt.Errorf("node %q depends on unknown node %q", n.ID, dep)
+			}
+		}
+	}
+
+	// Kahn: whatever can't be ordered sits on a cycle.
+	indegree := make(map[string]int, len(d.Nodes))
+	for _, n := range d.Nodes {
+		indegree[n.ID] = len(n.DependsOn)
+	}
+	queue := make([]string, 0, len(d.Nodes))
+	for _, n := range d.Nodes {
+		if indegree[n.ID] == 0 {
+			queue = append(queue, n.ID)
+		}
+	}
+	d.order = d.order[:0]
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		d.order = append(d.order, id)
+		for _, n := range d.Nodes {
+			if slices.Contains(n.DependsOn, id) {
+				if indegree[n.ID]--; indegree[n.ID] == 0 {
+					queue = append(queue, n.ID)
+				}
+			}
+		}
+		delete(indegree, id)
+	}
+	if len(indegree) > 0 {
+		cyclic := slices.Sorted(maps.Keys(indegree))
+		return fmt.Errorf("dependency cycle among nodes %v", cyclic)
+	}
+	return nil
+}
+
+// ValidateDAGScenarios checks that every node's scenario is actually loaded —
+// the same fail-fast-at-startup treatment as runners and pubsub get.
+func ValidateDAGScenarios(dags map[string]*DAGDef, scenes map[string]*Scenario) error {
+	for _, d := range dags {
+		for _, n := range d.Nodes {
+			if _, ok := scenes[n.Scenario]; !ok {
+				return fmt.Errorf("dag %q node %q references unknown scenario %q", d.ID, n.ID, n.Scenario)
+			}
+		}
+	}
+	return nil
+}
+
+// DAGNodeStatus is the state of one node within a run.
+type DAGNodeStatus string
+
+const (
+	NodePending   DAGNodeStatus = "pending"
+	NodeRunning   DAGNodeStatus = "running"
+	NodeSucceeded DAGNodeStatus = "succeeded"
+	NodeFailed    DAGNodeStatus = "failed"
+	NodeSkipped   DAGNodeStatus = "skipped"
+)
+
+// DAGRunStatus is the overall state of a run.
+type DAGRunStatus string
+
+const (
+	DAGRunning   DAGRunStatus = "running"
+	DAGSucceeded DAGRunStatus = "succeeded"
+	DAGFailed    DAGRunStatus = "failed" // at least one node failed (even under on_failure: continue)
+)
+
+// DAGNodeRun is a node's state in the run graph. JobIDs lists every
+// attempt's job, oldest first — each one is a regular /jobs/{id} record.
+type DAGNodeRun struct {
+	ID         string            `json:"id"`
+	Scenario   string            `json:"scenario"`
+	DependsOn  []string          `json:"depends_on,omitempty"`
+	Status     DAGNodeStatus     `json:"status"`
+	Attempts   int               `json:"attempts"`
+	JobIDs     []string          `json:"job_ids,omitempty"`
+	Params     map[string]string `json:"params,omitempty"`
+	Error      string            `json:"error,omitempty"`
+	StartedAt  *time.Time        `json:"started_at,omitempty"`
+	FinishedAt *time.Time        `json:"finished_at,omitempty"`
+}
+
+// DAGRun is one execution of a DAG: the run graph returned by the status API.
+type DAGRun struct {
+	ID          string        `json:"id"`
+	DAG         string        `json:"dag"`
+	Status      DAGRunStatus  `json:"status"`
+	ScheduleID  string        `json:"schedule_id,omitempty"`  // empty = manual run
+	SubmittedBy string        `json:"submitted_by,omitempty"` // principal ID; empty for cron runs
+	StartedAt   time.Time     `json:"started_at"`
+	FinishedAt  *time.Time    `json:"finished_at,omitempty"`
+	Nodes       []*DAGNodeRun `json:"nodes"`
+}
+
+// DAGRunner starts DAG runs and keeps their run graphs.
+type DAGRunner struct {
+	dags     map[string]*DAGDef
+	scenes   map[string]*Scenario
+	executor *Executor
+	db       *OrchestratorDB
+	gate     *TrustGate
+	poll     time.Duration // job status polling interval
+	// done, when non-nil, receives each run ID once the whole run has finished.
+	// Used by tests to await async completion without polling.
+	done chan string
+
+	mu    sync.Mutex
+	runs  map[string]*DAGRun
+	order []string // run IDs, oldest first; trimmed to maxDAGRuns
+}
+
+func NewDAGRunner(dags map[string]*DAGDef, scenes map[string]*Scenario, executor *Executor, db *OrchestratorDB, gate *TrustGate) *DAGRunner {
+	if dags == nil {
+		dags = map[string]*DAGDef{}
+	}
+	return &DAGRunner{
+		dags: dags, scenes: scenes, executor: executor, db: db, gate: gate,
+		poll: defaultDAGPoll,
+		runs: make(map[string]*DAGRun),
+	}
+}
+
+// DAG returns a DAG definition by id.
+func (r *DAGRunner) DAG(id string) (*DAGDef, bool) {
+	d, ok := r.dags[id]
+	return d, ok
+}
+
+// DAGs returns all DAG definitions, ordered by id.
+func (r *DAGRunner) DAGs() []*DAGDef {
+	out := make([]*DAGDef, 0, len(r.dags))
+	for _, id := range slices.Sorted(maps.Keys(r.dags)) {
+		out = append(out, r.dags[id])
+	}
+	return out
+}
+
+// Start validates every node's params up front — a DAG that can't finish
+// shouldn't run its first half — then runs the graph asynchronously.
+// params override the DAG's own params; node params override both. Magic
+// tokens ({{current_date}} …) are resolved in the DAG's timezone.
+func (r *DAGRunner) Start(dagID string, params map[string]string, scheduleID, submittedBy string) (*DAGRun, error) {
+	d, ok := r.dags[dagID]
+	if !ok {
+		return nil, ErrDAGNotFound
+	}
+
+	run := &DAGRun{
+		ID:          uuid.New().String(),
+		DAG:         d.ID,
+		Status:      DAGRunning,
+		ScheduleID:  scheduleID,
+		SubmittedBy: submittedBy,
+		StartedAt:   time.Now().UTC(),
+	}
+	for _, n := range d.Nodes {
+		scene, ok := r.scenes[n.Scenario]
+		if !ok {
+			return nil, fmt.Errorf("node %q: scenario %q not found", n.ID, n.Scenario)
+		}
+		merged := make(map[string]string, len(d.Params)+len(params)+len(n.Params))
+		maps.Copy(merged, d.Params)
+		maps.Copy(merged, params)
+		maps.Copy(merged, n.Params)
+		resolved, err := scene.ValidateParams(resolveMagicParams(merged, d.Timezone))
+		if err != nil {
+			return nil, fmt.Errorf("node %q: %w", n.ID, err)
+		}
+		run.Nodes = append(run.Nodes, &DAGNodeRun{
+			ID: n.ID, Scenario: n.Scenario, DependsOn: n.DependsOn,
+			Status: NodePending, Params: resolved,
+		})
+	}
+
+	r.mu.Lock()
+	r.runs[run.ID] = run
+	r.order = append(r.order, run.ID)
+	if len(r.order) > maxDAGRuns {
+		delete(r.runs, r.order[0])
+		r.order = r.order[1:]
+	}
+	r.mu.Unlock()
+
+	log.Info().Str("dag", d.ID).Str("run_id", run.ID).Int("nodes", len(d.Nodes)).Msg("dag run started")
+	go r.execute(d, run)
+	return r.snapshot(run), nil
+}
+
+// Run returns a snapshot of one run graph, or nil if unknown (or trimmed).
+func (r *DAGRunner) Run(runID string) *DAGRun {
+	r.mu.Lock()
+	run, ok := r.runs[runID]
+	r.mu.Unlock()
+	if !ok {
+		return nil
+	}
+	return r.snapshot(run)
+}
+
+// Runs returns snapshots of a DAG's recent runs, newest first.
+func (r *DAGRunner) Runs(dagID string) []*DAGRun {
+	r.mu.Lock()
+	var ids []string
+	for i := len(r.order) - 1; i >= 0; i-- {
+		if r.runs[r.order[i]].DAG == dagID {
+			ids = append(ids, r.order[i])
+		}
+	}
+	r.mu.Unlock()
+	out := make([]*DAGRun, 0, len(ids))
+	for _, id := range ids {
+		if run := r.Run(id); run != nil {
+			out = append(out, run)
+		}
+	}
+	return out
+}
+
+// snapshot deep-copies a run under the lock, so handlers can encode it while
+// node goroutines keep mutating the original.
+func (r *DAGRunner) snapshot(run *DAGRun) *DAGRun {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cp := *run
+	cp.Nodes = make([]*DAGNodeRun, len(run.Nodes))
+	for i, n := range run.Nodes {
+		nc := *n
+		nc.JobIDs = slices.Clone(n.JobIDs)
+		cp.Nodes[i] = &nc
+	}
+	return &cp
+}
+
+// nodeResult is what a node goroutine reports back to execute.
+type nodeResult struct {
+	id     string
+	status DAGNodeStatus
+}
+
+// execute drives one run: starts every node whose dependencies are
+// satisfied, applies failure policies as nodes finish, and marks whatever
+// can no longer run as skipped.
+func (r *DAGRunner) execute(d *DAGDef, run *DAGRun) {
+	defs := make(map[string]DAGNodeDef, len(d.Nodes))
+	nodes := make(map[string]*DAGNodeRun, len(run.Nodes))
+	for i, n := range d.Nodes {
+		defs[n.ID] = n
+		nodes[n.ID] = run.Nodes[i]
+	}
+
+	results := make(chan nodeResult)
+	running := 0
+	stopped := false
+	failed := false
+
+	for {
+		r.mu.Lock()
+		// Dependency order: a node skipped in this pass is already visible
+		// to its dependents later in the same pass.
+		for _, id := range d.order {
+			n := nodes[id]
+			if n.Status != NodePending {
+				continue
+			}
+			if stopped {
+				r.skip(n, "dag stopped after a failed node")
+				continue
+			}
+			ready, skip := true, ""
+			for _, dep := range n.DependsOn {
+				switch nodes[dep].Status {
+				case NodeSucceeded:
+				case NodeFailed:
+					if defs[dep].OnFailure != FailContinue {
+						skip = "dependency " + dep + " failed"
+					}
+				case NodeSkipped:
+					skip = "dependency " + dep + " skipped"
+				default:
+					ready = false
+				}
+			}
+			switch {
+			case skip != "":
+				r.skip(n, skip)
+			case ready:
+				now := time.Now().UTC()
+				n.Status, n.StartedAt = NodeRunning, &now
+				running++
+				go func(def DAGNodeDef, n *DAGNodeRun) {
+					results <- nodeResult{id: def.ID, status: r.runNode(run, def, n)}
+				}(defs[n.ID], n)
+			}
+		}
+		r.mu.Unlock()
+
+		if running == 0 {
+			break
+		}
+		res := <-results
+		running--
+		if res.status == NodeFailed {
+			failed = true
+			if defs[res.id].OnFailure == FailStop {
+				stopped = true
+			}
+		}
+	}
+
+	r.mu.Lock()
+	now := time.Now().UTC()
+	run.FinishedAt = &now
+	run.Status = DAGSucceeded
+	if failed {
+		run.Status = DAGFailed
+	}
+	r.mu.Unlock()
+
+	log.Info().Str("dag", d.ID).Str("run_id", run.ID).Str("status", string(run.Status)).Msg("dag run finished")
+	if r.done != nil {
+		r.done <- run.ID
+	}
+}
+
+// skip marks a pending node as skipped. Caller holds r.mu.
+func (r *DAGRunner) skip(n *DAGNodeRun, reason string) {
+	now := time.Now().UTC()
+	n.Status, n.Error, n.FinishedAt = NodeSkipped, reason, &now
+}
+
+// runNode runs one node to its final status, retrying failed jobs up to
+// def.Retries times. Gate refusals, submit errors and cancelled jobs are not
+// retried: another attempt would be refused the same way, and a job stopped
+// by hand was stopped on purpose.
+func (r *DAGRunner) runNode(run *DAGRun, def DAGNodeDef, n *DAGNodeRun) DAGNodeStatus {
+	scene := r.scenes[def.Scenario]
+	finish := func(status DAGNodeStatus, errMsg string) DAGNodeStatus {
+		r.mu.Lock()
+		now := time.Now().UTC()
+		n.Status, n.Error, n.FinishedAt = status, errMsg, &now
+		r.mu.Unlock()
+		return status
+	}
+
+	for attempt := 1; ; attempt++ {
+		if err := r.admit(scene); err != nil {
+			return finish(NodeFailed, err.Error())
+		}
+		job, err := r.executor.Submit(scene, n.Params, run.ScheduleID, run.SubmittedBy)
+		if err != nil {
+			return finish(NodeFailed, err.Error())
+		}
+		r.mu.Lock()
+		n.Attempts = attempt
+		n.JobIDs = append(n.JobIDs, job.ID)
+		r.mu.Unlock()
+
+		status, errMsg := r.awaitJob(job.ID)
+		switch {
+		case status == JobDone:
+			return finish(NodeSucceeded, "")
+		case status == JobCancelled:
+			return finish(NodeFailed, "job "+job.ID+" was cancelled")
+		case attempt > def.Retries:
+			return finish(NodeFailed, errMsg)
+		}
+		log.Warn().Str("dag", run.DAG).Str("node", def.ID).Int("attempt", attempt).Str("error", errMsg).
+			Dur("retry_in", def.retryDelay).Msg("dag node failed, retrying")
+		time.Sleep(def.retryDelay)
+	}
+}
+
+// admit runs the checks every other trigger runs before Submit: content
+// approval, trust gate and the licensed pipeline limit.
+func (r *DAGRunner) admit(scene *Scenario) error {
+	if err := VerifyScenarioChecksum(r.db, scene); err != nil {
+		return err
+	}
+	if r.gate == nil {
+		return nil
+	}
+	if err := r.gate.GateScenario(scene); err != nil {
+		return err
+	}
+	if active, err := r.db.CountActiveJobs(); err == nil {
+		return r.gate.CheckPipelineLimit(active)
+	}
+	return nil
+}
+
+// awaitJob polls the job record until it reaches a terminal status. The DB,
+// not the executor's in-memory registry, is the source of truth — the same
+// record GET /jobs/{id} serves.
+func (r *DAGRunner) awaitJob(jobID string) (JobStatus, string) {
+	for {
+		job, err := r.db.GetJob(jobID)
+		switch {
+		case err != nil:
+			return JobFailed, "job status lookup failed: " + err.Error()
+		case job == nil:
+			return JobFailed, "job " + jobID + " disappeared"
+		case job.Status == JobDone || job.Status == JobFailed || job.Status == JobCancelled:
+			errMsg := job.Error
+			if errMsg == "" && job.Status == JobFailed {
+				errMsg = "job " + jobID + " failed"
+			}
+			return job.Status, errMsg
+		}
+		time.Sleep(r.poll)
+	}
+}
@@ -0,0 +1,280 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// dagHarness runs DAG nodes through a real Executor with an injected runner.
+// Each scenario's rendered file is its name, so the runner knows what it runs.
+type dagHarness struct {
+	runner *DAGRunner
+	db     *OrchestratorDB
+
+	mu    sync.Mutex
+	ran   []string       // scenario names, in start order
+	fails map[string]int // scenario → remaining failures before it succeeds
+}
+
+func newDAGHarness(t *testing.T, yaml string, scenarios ...string) *dagHarness {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "dags.yaml")
+	if err := os.WriteFile(path, []byte(yaml), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	dags, err := LoadDAGs(path)
+	if err != nil {
+		t.Fatalf("LoadDAGs: %v", err)
+	}
+	db, err := OpenOrchestratorDB(t.TempDir() + "/orch.db")
+	if err != nil {
+		t.Fatalf("OpenOrchestratorDB: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	h := &dagHarness{db: db, fails: map[string]int{}}
+	scenes := make(map[string]*Scenario, len(scenarios))
+	for _, name := range scenarios {
+		s := scenarioFromYAML(name, name)
+		scenes[name] = s
+		if err := db.UpsertScenarioApproval(name, scenarioChecksum(s), "test-setup"); err != nil {
+			t.Fatalf("UpsertScenarioApproval: %v", err)
+		}
+	}
+	if err := ValidateDAGScenarios(dags, scenes); err != nil {
+		t.Fatalf("ValidateDAGScenarios: %v", err)
+	}
+
+	run := func(_ context.Context, _ string, args ...string) ([]byte, error) {
+		data, _ := os.ReadFile(args[len(args)-1])
+		name := string(data)
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		h.ran = append(h.ran, name)
+		if h.fails[name] > 0 {
+			h.fails[name]--
+			return []byte("boom"), &exitError{msg: "exit status 1"}
+		}
+		return []byte("ok"), nil
+	}
+	exec := &Executor{
+		runners: map[string]RunnerSpec{
+			defaultRunnerName: {Binary: "stub", Args: []string{"--pipeline", "{{.tmpfile}}"}},
+		},
+		defaultRunner: defaultRunnerName,
+		tmpDir:        t.TempDir(), db: db, run: run,
+		registry: make(map[string]*runningJob),
+	}
+	h.runner = NewDAGRunner(dags, scenes, exec, db, nil)
+	h.runner.poll = 5 * time.Millisecond
+	h.runner.done = make(chan string, 1)
+	return h
+}
+
+func (h *dagHarness) runToEnd(t *testing.T, dagID string) *DAGRun {
+	t.Helper()
+	run, err := h.runner.Start(dagID, nil, "", "")
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	select {
+	case id := <-h.runner.done:
+		if id != run.ID {
+			t.Fatalf("done run = %s, want %s", id, run.ID)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for dag run")
+	}
+	return h.runner.Run(run.ID)
+}
+
+func nodeByID(run *DAGRun, id string) *DAGNodeRun {
+	for _, n := range run.Nodes {
+		if n.ID == id {
+			return n
+		}
+	}
+	return nil
+}
+
+func TestLoadDAGs_RejectsInvalidGraphs(t *testing.T) {
+	cases := map[string]string{
+		"cycle": `
+dags:
+  - id: d
+    nodes:
+      - { id: a, scenario: s, depends_on: [c] }
+      - { id: b, scenario: s, depends_on: [a] }
+      - { id: c, scenario: s, depends_on: [b] }
+`,
+		"unknown node": `
+dags:
+  - id: d
+    nodes:
+      - { id: a, scenario: s, depends_on: [ghost] }
+`,
+		"bad policy": `
+dags:
+  - id: d
+    nodes:
+      - { id: a, scenario: s, on_failure: retry-forever }
+`,
+		"bad delay": `
+dags:
+  - id: d
+    nodes:
+      - { id: a, scenario: s, retry_delay: soon }
+`,
+	}
+	for name, yaml := range cases {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "dags.yaml")
+			_ = os.WriteFile(path, []byte(yaml), 0o600)
+			if _, err := LoadDAGs(path); err == nil {
+				t.Error("LoadDAGs accepted an invalid dag")
+			}
+		})
+	}
+}
+
+func TestDAGRunner_RunsInDependencyOrder(t *testing.T) {
+	h := newDAGHarness(t, `
+dags:
+  - id: dwh
+    nodes:
+      - { id: facts, scenario: load-facts, depends_on: [dims] }
+      - { id: dims, scenario: load-dims }
+      - { id: report, scenario: report, depends_on: [facts] }
+`, "load-facts", "load-dims", "report")
+
+	run := h.runToEnd(t, "dwh")
+	if run.Status != DAGSucceeded {
+		t.Fatalf("run status = %s, want succeeded", run.Status)
+	}
+	if got := strings.Join(h.ran, ","); got != "load-dims,load-facts,report" {
+		t.Errorf("execution order = %s", got)
+	}
+	for _, n := range run.Nodes {
+		if n.Status != NodeSucceeded || len(n.JobIDs) != 1 {
+			t.Errorf("node %s: status=%s jobs=%v", n.ID, n.Status, n.JobIDs)
+		}
+	}
+}
+
+func TestDAGRunner_RetriesFailedNode(t *testing.T) {
+	h := newDAGHarness(t, `
+dags:
+  - id: d
+    nodes:
+      - { id: a, scenario: flaky, retries: 2, retry_delay: 1ms }
+`, "flaky")
+	h.fails["flaky"] = 2
+
+	run := h.runToEnd(t, "d")
+	a := nodeByID(run, "a")
+	if run.Status != DAGSucceeded || a.Status != NodeSucceeded {
+		t.Fatalf("run=%s node=%s (%s)", run.Status, a.Status, a.Error)
+	}
+	if a.Attempts != 3 || len(a.JobIDs) != 3 {
+		t.Errorf("attempts=%d jobs=%d, want 3/3", a.Attempts, len(a.JobIDs))
+	}
+	// Every attempt is an ordinary persisted job.
+	if job, _ := h.db.GetJob(a.JobIDs[0]); job == nil || job.Status != JobFailed {
+		t.Errorf("first attempt job = %+v, want failed", job)
+	}
+}
+
+func TestDAGRunner_StopPolicySkipsRemainingNodes(t *testing.T) {
+	h := newDAGHarness(t, `
+dags:
+  - id: d
+    nodes:
+      - { id: dims, scenario: dims }
+      - { id: facts, scenario: facts, depends_on: [dims] }
+      - { id: later, scenario: other, depends_on: [facts] }
+`, "dims", "facts", "other")
+	h.fails["dims"] = 1
+
+	run := h.runToEnd(t, "d")
+	if run.Status != DAGFailed {
+		t.Errorf("run status = %s, want failed", run.Status)
+	}
+	if n := nodeByID(run, "dims"); n.Status != NodeFailed || n.Attempts != 1 {
+		t.Errorf("dims: status=%s attempts=%d", n.Status, n.Attempts)
+	}
+	for _, id := range []string{"facts", "later"} {
+		if n := nodeByID(run, id); n.Status != NodeSkipped {
+			t.Errorf("%s status = %s, want skipped", id, n.Status)
+		}
+	}
+	if len(h.ran) != 1 {
+		t.Errorf("ran %v, want only dims", h.ran)
+	}
+}
+
+func TestDAGRunner_SkipDependentsKeepsIndependentBranch(t *testing.T) {
+	h := newDAGHarness(t, `
+dags:
+  - id: d
+    nodes:
+      - { id: a, scenario: a, on_failure: skip_dependents }
+      - { id: a2, scenario: a2, depends_on: [a] }
+      - { id: b, scenario: b }
+      - { id: c, scenario: c, depends_on: [a, b] }
+`, "a", "a2", "b", "c")
+	h.fails["a"] = 1
+
+	run := h.runToEnd(t, "d")
+	want := map[string]DAGNodeStatus{"a": NodeFailed, "a2": NodeSkipped, "b": NodeSucceeded, "c": NodeSkipped}
+	for id, status := range want {
+		if n := nodeByID(run, id); n.Status != status {
+			t.Errorf("%s status = %s, want %s", id, n.Status, status)
+		}
+	}
+}
+
+func TestDAGRunner_ContinuePolicyRunsDependents(t *testing.T) {
+	h := newDAGHarness(t, `
+dags:
+  - id: d
+    nodes:
+      - { id: optional, scenario: optional, on_failure: continue }
+      - { id: main, scenario: main, depends_on: [optional] }
+`, "optional", "main")
+	h.fails["optional"] = 1
+
+	run := h.runToEnd(t, "d")
+	if n := nodeByID(run, "main"); n.Status != NodeSucceeded {
+		t.Errorf("main status = %s, want succeeded", n.Status)
+	}
+	// A tolerated failure still shows on the run as a whole.
+	if run.Status != DAGFailed {
+		t.Errorf("run status = %s, want failed", run.Status)
+	}
+}
+
+func TestDAGRunner_UnapprovedNodeFailsWithoutRetry(t *testing.T) {
+	h := newDAGHarness(t, `
+dags:
+  - id: d
+    nodes:
+      - { id: a, scenario: a, retries: 3, retry_delay: 1ms }
+`, "a")
+	if err := h.db.DeleteScenarioApproval("a"); err != nil {
+		t.Fatal(err)
+	}
+
+	run := h.runToEnd(t, "d")
+	a := nodeByID(run, "a")
+	if a.Status != NodeFailed || a.Attempts != 0 || !strings.Contains(a.Error, "not approved") {
+		t.Errorf("node: status=%s attempts=%d error=%q", a.Status, a.Attempts, a.Error)
+	}
+	if len(h.ran) != 0 {
+		t.Errorf("unapproved scenario ran: %v", h.ran)
+	}
+}
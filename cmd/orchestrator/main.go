@@ -14,6 +14,7 @@
 //	orchestrator --scenarios ./scenarios --db orchestrator.db --tdtpcli ./tdtpcli
 //	orchestrator --scenarios ./scenarios --db orchestrator.db --runners ./runners.yaml
 //	orchestrator --scenarios ./scenarios --db orchestrator.db --redis-addr localhost:6379 --pubsub ./pubsub.yaml
+//	orchestrator --scenarios ./scenarios --db orchestrator.db --dags ./dags.yaml
 //
 // API:
 //
@@ -33,6 +34,11 @@
 //	PATCH /schedules/{id}/enable      resume
 //	PATCH /schedules/{id}/disable     pause
 //	DELETE /schedules/{id}            remove
+//	GET  /dags                        list DAGs (composite pipelines)
+//	GET  /dags/{id}                   DAG definition
+//	POST /dags/{id}/run               run the DAG with params → {run_id}
+//	GET  /dags/{id}/runs              recent runs of a DAG
+//	GET  /dag-runs/{id}               run graph: per-node status, attempts, job ids
 //	GET  /healthz
 package main
 
@@ -69,6 +75,7 @@ func main() {
 	redisPassword := flag.String("redis-password", "", "Redis password (pubsub trigger only)")
 	redisDB := flag.Int("redis-db", 0, "Redis DB number (pubsub trigger only)")
 	pubsubPath := flag.String("pubsub", "", "path to pubsub.yaml mapping pipeline result_name -> scenario (requires --redis-addr)")
+	dagsPath := flag.String("dags", "", "path to dags.yaml with composite pipelines (empty = no DAGs)")
 	flag.Parse()
 
 	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr, TimeFormat: time.RFC3339})
@@ -148,6 +155,22 @@ func main() {
 		fatal(err, "load schedules from db")
 	}
 
+	// DAGs: dependent scenarios run node by node through the same executor.
+	var dagDefs map[string]*DAGDef
+	if *dagsPath != "" {
+		if dagDefs, err = LoadDAGs(*dagsPath); err != nil {
+			fatal(err, "load dags: "+*dagsPath)
+		}
+		if err := ValidateDAGScenarios(dagDefs, scenes); err != nil {
+			fatal(err, "dag scenario validation failed")
+		}
+		log.Info().Int("count", len(dagDefs)).Str("file", *dagsPath).Msg("dags loaded")
+	}
+	dagRunner := NewDAGRunner(dagDefs, scenes, executor, db, gate)
+	if err := scheduler.RegisterDAGs(dagRunner); err != nil {
+		fatal(err, "register dag schedules")
+	}
+
 	// Pub/sub trigger: subscribe to pkg/resultlog's pipeline-completion events
 	// (tdtp:pipeline:*) and run the scenario mapped to each result_name.
 	subscriber, err := setupPubSub(*redisAddr, *redisPassword, *redisDB, *pubsubPath, scenes, executor, gate, db)
@@ -174,6 +197,7 @@ func main() {
 		scenes:         scenes,
 		executor:       executor,
 		scheduler:      scheduler,
+		dags:           dagRunner,
 		gate:           gate,
 		auth:           auth,
 		authMiddleware: authMiddleware,
@@ -6,6 +6,8 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"path/filepath"
 	"time"
@@ -23,6 +25,7 @@ type routerDeps struct {
 	scenes         map[string]*Scenario
 	executor       *Executor
 	scheduler      *Scheduler
+	dags           *DAGRunner
 	gate           *TrustGate
 	auth           *Authenticator // nil in ldap auth mode — /tokens routes 501 in that case
 	authMiddleware func(http.Handler) http.Handler
@@ -221,6 +224,73 @@ func newRouter(deps routerDeps) chi.Router {
 			writeJSON(w, http.StatusOK, jobs)
 		}))
 
+		// ── DAGs (composite pipelines, see dag.go) ───────────────────────────────────
+		dags := deps.dags
+		r.Get("/dags", RequireRole(RoleConsumer, func(w http.ResponseWriter, _ *http.Request) {
+			writeJSON(w, http.StatusOK, dags.DAGs())
+		}))
+
+		r.Get("/dags/{id}", RequireRole(RoleConsumer, func(w http.ResponseWriter, r *http.Request) {
+			d, ok := dags.DAG(chi.URLParam(r, "id"))
+			if !ok {
+				writeError(w, http.StatusNotFound, "dag not found")
+				return
+			}
+			writeJSON(w, http.StatusOK, d)
+		}))
+
+		r.Post("/dags/{id}/run", RequireRole(RoleActivator, func(w http.ResponseWriter, r *http.Request) {
+			d, ok := dags.DAG(chi.URLParam(r, "id"))
+			if !ok {
+				writeError(w, http.StatusNotFound, "dag not found")
+				return
+			}
+			// A scoped token must be allowed every scenario the DAG would run.
+			principal := PrincipalFrom(r.Context())
+			if principal != nil {
+				for _, n := range d.Nodes {
+					if !principal.AllowsScenario(n.Scenario) {
+						writeError(w, http.StatusForbidden, "token not authorized for scenario "+n.Scenario)
+						return
+					}
+				}
+			}
+
+			// Body is optional: DAG-level params usually suffice.
+			var body struct {
+				Params map[string]string `json:"params"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil && !errors.Is(err, io.EOF) {
+				writeError(w, http.StatusBadRequest, "invalid json: "+err.Error())
+				return
+			}
+
+			run, err := dags.Start(d.ID, body.Params, "" /* manual run */, principalID(principal))
+			if err != nil {
+				writeError(w, http.StatusUnprocessableEntity, err.Error())
+				return
+			}
+			writeJSON(w, http.StatusAccepted, map[string]string{"run_id": run.ID})
+		}))
+
+		r.Get("/dags/{id}/runs", RequireRole(RoleConsumer, func(w http.ResponseWriter, r *http.Request) {
+			id := chi.URLParam(r, "id")
+			if _, ok := dags.DAG(id); !ok {
+				writeError(w, http.StatusNotFound, "dag not found")
+				return
+			}
+			writeJSON(w, http.StatusOK, dags.Runs(id))
+		}))
+
+		r.Get("/dag-runs/{id}", RequireRole(RoleConsumer, func(w http.ResponseWriter, r *http.Request) {
+			run := dags.Run(chi.URLParam(r, "id"))
+			if run == nil {
+				writeError(w, http.StatusNotFound, "dag run not found")
+				return
+			}
+			writeJSON(w, http.StatusOK, run)
+		}))
+
 		// ── Schedules (admin) ───────────────────────────────────────────────────────
 		r.Get("/schedules", RequireRole(RoleAdmin, func(w http.ResponseWriter, r *http.Request) {
 			schedules, err := db.ListSchedules()
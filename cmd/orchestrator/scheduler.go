@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/robfig/cron/v3"
+	"github.com/rs/zerolog/log"
 	"gopkg.in/yaml.v3"
 )
 
@@ -182,6 +183,31 @@ func (s *Scheduler) Delete(id string) error {
 	return s.db.DeleteSchedule(id)
 }
 
+// RegisterDAGs registers every DAG with a schedule: as a cron entry under
+// "dag:<id>". Unlike scenario schedules these come from the --dags file on
+// every start, not from the DB — the file is the DAG's definition, so there
+// is no runtime enable/disable for them.
+func (s *Scheduler) RegisterDAGs(runner *DAGRunner) error {
+	for _, d := range runner.DAGs() {
+		if d.Schedule == "" {
+			continue
+		}
+		dagID := d.ID
+		schedID := "dag:" + dagID
+		if _, err := s.c.AddFunc(d.Schedule, func() {
+			status := "running"
+			if _, err := runner.Start(dagID, nil, schedID, ""); err != nil {
+				status = "failed"
+				log.Warn().Err(err).Str("dag", dagID).Msg("scheduled dag run refused")
+			}
+			RecordScheduleRun(schedID, dagID, status)
+		}); err != nil {
+			return fmt.Errorf("scheduler: register dag %q: %w", dagID, err)
+		}
+	}
+	return nil
+}
+
 func (s *Scheduler) Start() { s.c.Start() }
 func (s *Scheduler) Stop()  { s.c.Stop() }
 
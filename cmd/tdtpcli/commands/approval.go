@@ -0,0 +1,33 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ruslano69/tdtp-framework/pkg/approval"
+)
+
+// awaitApproval publishes an approval request for a sensitive operation
+// and blocks until it is approved, rejected or times out (approval:
+// section). The decision goes to the audit entry through OpMetrics.
+func awaitApproval(ctx context.Context, gate *approval.Gate, req *approval.Request) error {
+	// A retry (resilience.retry) must not ask again: the decision stands
+	if d := recordedApproval(ctx, req); d != nil {
+		if d.Status != approval.StatusApproved {
+			return fmt.Errorf("%w: %s %s (request %s)", approval.ErrRejected, req.Operation, req.Target, d.RequestID)
+		}
+		return nil
+	}
+	if gate == nil {
+		return fmt.Errorf("%s %s requires approval, but no approval webhook is configured (approval.url)", req.Operation, req.Target)
+	}
+	d, err := gate.Await(ctx, req)
+	if d != nil {
+		recordApproval(ctx, req, d)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("✓ Approved by %s (request %s)\n", d.Approver, d.RequestID)
+	return nil
+}
@@ -0,0 +1,400 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+	"github.com/ruslano69/tdtp-framework/pkg/brokers"
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+	"github.com/ruslano69/tdtp-framework/pkg/fake"
+	"github.com/ruslano69/tdtp-framework/pkg/processors"
+)
+
+// Пути нагрузочного теста (--bench).
+const (
+	BenchImport   = "import"   // синтетические пакеты → ImportPacket в БД из config
+	BenchBroker   = "broker"   // синтетические пакеты → XML → брокер из config
+	BenchPipeline = "pipeline" // процессоры → сжатие → XML → парсинг → распаковка → ImportPacket
+)
+
+// BenchOptions — параметры команды --bench.
+type BenchOptions struct {
+	Path          string
+	Table         string // целевая таблица; если она есть в БД — данные генерируются по её схеме
+	Concurrency   int
+	Duration      time.Duration
+	RowsPerPacket int
+	Seed          uint64
+	Strategy      adapters.ImportStrategy
+	Compress      bool
+	CompressLevel int
+	CompressAlgo  string
+	ProcessorMgr  ProcessorManager
+	BrokerCfg     *BrokerConfig
+}
+
+// BenchReport — итог нагрузочного теста.
+type BenchReport struct {
+	Packets int64
+	Rows    int64
+	Errors  int64
+	// FirstError — первая ошибка операции (остальные только считаются).
+	FirstError error
+	Elapsed    time.Duration
+
+	P50, P95, P99, Max time.Duration
+
+	PeakHeapBytes  uint64
+	AllocatedBytes uint64
+	GCCycles       uint32
+	PeakGoroutines int
+}
+
+// RowsPerSec — пропускная способность по строкам.
+func (r *BenchReport) RowsPerSec() float64 {
+	if r.Elapsed <= 0 {
+		return 0
+	}
+	return float64(r.Rows) / r.Elapsed.Seconds()
+}
+
+// benchOp выполняет одну операцию (один пакет) и возвращает число строк в
+// нём и длительность измеряемой части — без генерации данных.
+type benchOp func(ctx context.Context) (int, time.Duration, error)
+
+// defaultBenchSchema — схема для таблицы, которой ещё нет в целевой БД.
+func defaultBenchSchema() packet.Schema {
+	return packet.Schema{Fields: []packet.Field{
+		{Name: "id", Type: "INTEGER", Key: true},
+		{Name: "email", Type: "TEXT", Length: 100},
+		{Name: "name", Type: "TEXT", Length: 100},
+		{Name: "city", Type: "TEXT", Length: 50},
+		{Name: "amount", Type: "DECIMAL", Precision: 12, Scale: 2},
+		{Name: "created_at", Type: "DATETIME"},
+		{Name: "active", Type: "BOOLEAN"},
+		{Name: "note", Type: "TEXT", Length: 200},
+	}}
+}
+
+// Bench генерирует синтетические пакеты и прогоняет их по выбранному пути
+// в Concurrency потоков в течение Duration, затем печатает отчёт:
+// строк/с, перцентили задержки одной операции, пик кучи и GC.
+//
+// Генерация данных в задержку не входит. Каждый поток получает свой
+// диапазон ключей, чтобы параллельные вставки не конфликтовали.
+func Bench(ctx context.Context, dbConfig *adapters.Config, opts BenchOptions) error {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 1
+	}
+	if opts.RowsPerPacket <= 0 {
+		opts.RowsPerPacket = 1000
+	}
+	if opts.Duration <= 0 {
+		return UsageErrorf("--duration must be positive")
+	}
+	if opts.Table == "" {
+		opts.Table = "tdtp_bench"
+	}
+
+	var adapter adapters.Adapter
+	sch := defaultBenchSchema()
+	switch opts.Path {
+	case BenchImport, BenchPipeline:
+		var err error
+		adapter, err = adapters.New(ctx, *dbConfig)
+		if err != nil {
+			return fmt.Errorf("failed to connect: %w", err)
+		}
+		defer func() { _ = adapter.Close(ctx) }()
+		if exists, _ := adapter.TableExists(ctx, opts.Table); exists {
+			if sch, err = adapter.GetTableSchema(ctx, opts.Table); err != nil {
+				return fmt.Errorf("failed to read schema of %s: %w", opts.Table, err)
+			}
+		}
+	case BenchBroker:
+		if opts.BrokerCfg == nil || opts.BrokerCfg.Type == "" {
+			return fmt.Errorf("--bench broker requires a broker section in config")
+		}
+	default:
+		return fmt.Errorf("unknown bench path %q (want %s, %s or %s)", opts.Path, BenchImport, BenchBroker, BenchPipeline)
+	}
+
+	ops := make([]benchOp, opts.Concurrency)
+	for w := range ops {
+		gen, err := benchGenerator(sch, opts.Seed, w)
+		if err != nil {
+			return err
+		}
+		switch opts.Path {
+		case BenchImport:
+			ops[w] = importBenchOp(adapter, gen, opts)
+		case BenchPipeline:
+			ops[w] = pipelineBenchOp(adapter, gen, opts)
+		case BenchBroker:
+			broker, err := createBroker(opts.BrokerCfg)
+			if err != nil {
+				return fmt.Errorf("failed to create broker: %w", err)
+			}
+			defer func() { _ = broker.Close() }()
+			if err := broker.Connect(ctx); err != nil {
+				return fmt.Errorf("failed to connect to broker: %w", err)
+			}
+			ops[w] = brokerBenchOp(broker, gen, opts)
+		}
+	}
+
+	fmt.Printf("Benchmark: %s → %s (%d workers, %d rows/packet, %s)\n",
+		opts.Path, opts.Table, opts.Concurrency, opts.RowsPerPacket, opts.Duration)
+
+	report := runBench(ctx, ops, opts.Duration)
+	printBenchReport(report)
+	recordOpMetrics(ctx, opts.Table, report.Rows)
+
+	if report.Packets == 0 && report.FirstError != nil {
+		return fmt.Errorf("benchmark failed: %w", report.FirstError)
+	}
+	return nil
+}
+
+// benchGenerator создаёт генератор для потока worker: ключевые поля
+// начинаются с worker·10^12, остальные — по подсказкам pkg/fake из имён.
+func benchGenerator(sch packet.Schema, seed uint64, worker int) (*fake.Generator, error) {
+	hints := make(map[string]fake.Hint)
+	for _, f := range sch.Fields {
+		if f.Key {
+			hints[f.Name] = fake.Hint{Kind: fake.KindSequence, Min: fmt.Sprint(int64(worker)*1_000_000_000_000 + 1)}
+		}
+	}
+	if seed != 0 {
+		seed += uint64(worker)
+	}
+	gen, err := fake.New(sch, fake.Config{Seed: seed, Hints: hints})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create data generator: %w", err)
+	}
+	return gen, nil
+}
+
+func benchPacket(gen *fake.Generator, opts BenchOptions) *packet.DataPacket {
+	pkt := packet.NewDataPacket(packet.TypeReference, opts.Table)
+	pkt.Schema = gen.Schema()
+	pkt.Data = packet.RowsToData(gen.Rows(opts.RowsPerPacket))
+	pkt.Header.RecordsInPart = opts.RowsPerPacket
+	pkt.Header.PartNumber = 1
+	pkt.Header.TotalParts = 1
+	return pkt
+}
+
+func importBenchOp(adapter adapters.Adapter, gen *fake.Generator, opts BenchOptions) benchOp {
+	return func(ctx context.Context) (int, time.Duration, error) {
+		pkt := benchPacket(gen, opts)
+		start := time.Now()
+		err := adapter.ImportPacket(ctx, pkt, opts.Strategy)
+		return opts.RowsPerPacket, time.Since(start), err
+	}
+}
+
+func brokerBenchOp(broker brokers.MessageBroker, gen *fake.Generator, opts BenchOptions) benchOp {
+	return func(ctx context.Context) (int, time.Duration, error) {
+		pkt := benchPacket(gen, opts)
+		start := time.Now()
+		if opts.Compress {
+			if err := compressPacketData(pkt, opts.CompressLevel, opts.CompressAlgo, true); err != nil {
+				return 0, 0, fmt.Errorf("compress: %w", err)
+			}
+		}
+		msg, err := packet.NewGenerator().ToXML(pkt, true)
+		if err != nil {
+			return 0, 0, fmt.Errorf("marshal: %w", err)
+		}
+		err = broker.Send(ctx, msg)
+		return opts.RowsPerPacket, time.Since(start), err
+	}
+}
+
+// pipelineBenchOp повторяет путь пакета от экспорта до импорта:
+// процессоры → сжатие → сериализация → парсинг → проверка checksum и
+// распаковка → ImportPacket.
+func pipelineBenchOp(adapter adapters.Adapter, gen *fake.Generator, opts BenchOptions) benchOp {
+	return func(ctx context.Context) (int, time.Duration, error) {
+		pkt := benchPacket(gen, opts)
+		start := time.Now()
+
+		if opts.ProcessorMgr != nil && opts.ProcessorMgr.HasProcessors() {
+			if err := opts.ProcessorMgr.ProcessPacket(ctx, pkt); err != nil {
+				return 0, 0, fmt.Errorf("processors: %w", err)
+			}
+		}
+		if opts.Compress {
+			if err := compressPacketData(pkt, opts.CompressLevel, opts.CompressAlgo, true); err != nil {
+				return 0, 0, fmt.Errorf("compress: %w", err)
+			}
+		}
+		xml, err := packet.NewGenerator().ToXML(pkt, true)
+		if err != nil {
+			return 0, 0, fmt.Errorf("marshal: %w", err)
+		}
+		parsed, err := packet.NewParser().ParseBytes(xml)
+		if err != nil {
+			return 0, 0, fmt.Errorf("parse: %w", err)
+		}
+		if parsed.Data.Compression != "" {
+			if err := decompressBenchPacket(parsed); err != nil {
+				return 0, 0, err
+			}
+		}
+		err = adapter.ImportPacket(ctx, parsed, opts.Strategy)
+		return opts.RowsPerPacket, time.Since(start), err
+	}
+}
+
+// decompressBenchPacket — decompressPacketData без вывода в консоль на каждый пакет.
+func decompressBenchPacket(pkt *packet.DataPacket) error {
+	if len(pkt.Data.Rows) != 1 {
+		return fmt.Errorf("compressed packet should have exactly 1 row, got %d", len(pkt.Data.Rows))
+	}
+	compressed := pkt.Data.Rows[0].Value
+	if pkt.Data.Checksum != "" {
+		if err := processors.ValidateChecksum([]byte(compressed), pkt.Data.Checksum); err != nil {
+			return fmt.Errorf("data corruption detected: %w", err)
+		}
+	}
+	rows, err := processors.DecompressDataForTdtpAlgo(compressed, pkt.Data.Compression)
+	if err != nil {
+		return fmt.Errorf("decompress: %w", err)
+	}
+	pkt.Data.Rows = make([]packet.Row, len(rows))
+	for i, r := range rows {
+		pkt.Data.Rows[i] = packet.Row{Value: r}
+	}
+	pkt.Data.Compression = ""
+	pkt.Data.Checksum = ""
+	return nil
+}
+
+// runBench крутит ops параллельно (по горутине на op) до истечения duration
+// и собирает статистику. Операция, начатая до дедлайна, доигрывается.
+func runBench(ctx context.Context, ops []benchOp, duration time.Duration) *BenchReport {
+	report := &BenchReport{}
+	var before runtime.MemStats
+	runtime.ReadMemStats(&before)
+
+	// Сэмплер ресурсов: пик кучи и числа горутин.
+	var peakHeap atomic.Uint64
+	var peakGoroutines atomic.Int64
+	samplerDone := make(chan struct{})
+	samplerStopped := make(chan struct{})
+	go func() {
+		defer close(samplerStopped)
+		ticker := time.NewTicker(100 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			var ms runtime.MemStats
+			runtime.ReadMemStats(&ms)
+			if ms.HeapInuse > peakHeap.Load() {
+				peakHeap.Store(ms.HeapInuse)
+			}
+			if g := int64(runtime.NumGoroutine()); g > peakGoroutines.Load() {
+				peakGoroutines.Store(g)
+			}
+			select {
+			case <-samplerDone:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	var (
+		mu        sync.Mutex
+		latencies []time.Duration
+		wg        sync.WaitGroup
+	)
+	deadline := time.Now().Add(duration)
+	start := time.Now()
+	for _, op := range ops {
+		wg.Add(1)
+		go func(op benchOp) {
+			defer wg.Done()
+			var local []time.Duration
+			var rows, packets, errs int64
+			var firstErr error
+			for time.Now().Before(deadline) && ctx.Err() == nil {
+				n, lat, err := op(ctx)
+				if err != nil {
+					errs++
+					if firstErr == nil {
+						firstErr = err
+					}
+					continue
+				}
+				packets++
+				rows += int64(n)
+				local = append(local, lat)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			latencies = append(latencies, local...)
+			report.Rows += rows
+			report.Packets += packets
+			report.Errors += errs
+			if report.FirstError == nil {
+				report.FirstError = firstErr
+			}
+		}(op)
+	}
+	wg.Wait()
+	report.Elapsed = time.Since(start)
+
+	close(samplerDone)
+	<-samplerStopped
+
+	var after runtime.MemStats
+	runtime.ReadMemStats(&after)
+	report.PeakHeapBytes = max(peakHeap.Load(), after.HeapInuse)
+	report.AllocatedBytes = after.TotalAlloc - before.TotalAlloc
+	report.GCCycles = after.NumGC - before.NumGC
+	report.PeakGoroutines = int(peakGoroutines.Load())
+
+	slices.Sort(latencies)
+	report.P50 = percentile(latencies, 0.50)
+	report.P95 = percentile(latencies, 0.95)
+	report.P99 = percentile(latencies, 0.99)
+	if len(latencies) > 0 {
+		report.Max = latencies[len(latencies)-1]
+	}
+	return report
+}
+
+// percentile возвращает p-перцентиль (nearest-rank) отсортированных задержек.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(p*float64(len(sorted))+0.999999) - 1
+	return sorted[min(max(rank, 0), len(sorted)-1)]
+}
+
+func printBenchReport(r *BenchReport) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "  Packets:     %d (%d errors)\n", r.Packets, r.Errors)
+	fmt.Fprintf(&b, "  Rows:        %d in %s\n", r.Rows, r.Elapsed.Round(time.Millisecond))
+	fmt.Fprintf(&b, "  Throughput:  %.0f rows/s, %.1f packets/s\n",
+		r.RowsPerSec(), float64(r.Packets)/max(r.Elapsed.Seconds(), 1e-9))
+	fmt.Fprintf(&b, "  Latency:     p50 %s  p95 %s  p99 %s  max %s\n",
+		r.P50.Round(time.Microsecond), r.P95.Round(time.Microsecond),
+		r.P99.Round(time.Microsecond), r.Max.Round(time.Microsecond))
+	fmt.Fprintf(&b, "  Memory:      peak heap %.1f MB, allocated %.1f MB, %d GC cycles, peak %d goroutines\n",
+		float64(r.PeakHeapBytes)/(1<<20), float64(r.AllocatedBytes)/(1<<20), r.GCCycles, r.PeakGoroutines)
+	if r.FirstError != nil {
+		fmt.Fprintf(&b, "  First error: %v\n", r.FirstError)
+	}
+	fmt.Print(b.String())
+}
@@ -0,0 +1,53 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPercentile(t *testing.T) {
+	var lat []time.Duration
+	for i := 1; i <= 100; i++ {
+		lat = append(lat, time.Duration(i)*time.Millisecond)
+	}
+	cases := map[float64]time.Duration{0.50: 50 * time.Millisecond, 0.95: 95 * time.Millisecond, 0.99: 99 * time.Millisecond, 1: 100 * time.Millisecond}
+	for p, want := range cases {
+		if got := percentile(lat, p); got != want {
+			t.Errorf("p%.0f = %s, want %s", p*100, got, want)
+		}
+	}
+	if percentile(nil, 0.95) != 0 {
+		t.Error("percentile of empty set must be 0")
+	}
+}
+
+func TestRunBench(t *testing.T) {
+	ok := func(context.Context) (int, time.Duration, error) {
+		time.Sleep(time.Millisecond)
+		return 10, time.Millisecond, nil
+	}
+	calls := 0
+	flaky := func(context.Context) (int, time.Duration, error) {
+		calls++
+		if calls%2 == 0 {
+			return 0, 0, errors.New("boom")
+		}
+		return ok(context.Background())
+	}
+
+	r := runBench(context.Background(), []benchOp{ok, flaky}, 50*time.Millisecond)
+	if r.Packets == 0 || r.Rows != r.Packets*10 {
+		t.Fatalf("packets %d, rows %d", r.Packets, r.Rows)
+	}
+	if r.Errors == 0 || r.FirstError == nil {
+		t.Errorf("errors %d, first %v — flaky op errors were not counted", r.Errors, r.FirstError)
+	}
+	if r.P95 != time.Millisecond || r.Max != time.Millisecond {
+		t.Errorf("p95 %s, max %s, want 1ms", r.P95, r.Max)
+	}
+	if r.Elapsed < 50*time.Millisecond || r.RowsPerSec() <= 0 {
+		t.Errorf("elapsed %s, rows/s %.0f", r.Elapsed, r.RowsPerSec())
+	}
+}
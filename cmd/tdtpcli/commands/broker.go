@@ -13,9 +13,12 @@ import (
 	"github.com/ruslano69/tdtp-framework/pkg/adapters"
 	"github.com/ruslano69/tdtp-framework/pkg/brokers"
 	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+	"github.com/ruslano69/tdtp-framework/pkg/history"
 	"github.com/ruslano69/tdtp-framework/pkg/mercury"
 	"github.com/ruslano69/tdtp-framework/pkg/pipeline"
 	"github.com/ruslano69/tdtp-framework/pkg/processors"
+	"github.com/ruslano69/tdtp-framework/pkg/quota"
+	"github.com/ruslano69/tdtp-framework/pkg/tracing"
 )
 
 // BrokerConfig holds broker configuration
@@ -38,6 +41,16 @@ type BrokerConfig struct {
 	QueuePath      string   // MSMQ: полный путь к очереди (например: ".\private$\tdtp_in")
 	Brokers        []string // Kafka: список брокеров (["localhost:9092"])
 	ConsumerGroup  string   // Kafka: consumer group ID
+	Dir            string   // FileQueue: каталог очереди (общая папка / съёмный носитель)
+	AutoAck        bool     // FileQueue: подтверждать сообщение сразу при получении
+
+	Quota       *quota.Tracker // ExportToBroker: учёт квоты получателя Queue (nil — без квот)
+	RowChecksum string         // ExportToBroker: Header.Checksum пакетов (packet.ChecksumPacket/ChecksumRows)
+	History     *history.Store // ExportToBroker: доставка по частям в истории запусков (nil — одним пакетом)
+	ArchiveDir  string         // ExportToBroker: копии отправляемых сообщений для --retry-failed-parts
+	RetryRun    string         // ExportToBroker: повторить только недоставленные части этого запуска
+
+	Exclusions *adapters.ColumnExclusions // ExportToBroker: служебные колонки, не выдаваемые в очередь (export.exclude_columns)
 }
 
 // ExportToBroker exports table data to message broker.
@@ -51,7 +64,28 @@ type BrokerConfig struct {
 //     Header.MessageID.
 //   - encryptLegacy=true (--enc13): whole-packet binary blob via
 //     EncryptPacket, same as --export --enc13 produces to a file.
-func ExportToBroker(ctx context.Context, dbConfig *adapters.Config, brokerCfg *BrokerConfig, tableName string, query *packet.Query, compress bool, compressLevel int, compressAlgo string, procMgr ProcessorManager, packetSizeMB int, mercuryURL string, encrypt, encryptLegacy bool) error {
+func ExportToBroker(ctx context.Context, dbConfig *adapters.Config, brokerCfg *BrokerConfig, tableName string, query *packet.Query, compress bool, compressLevel int, compressAlgo string, procMgr ProcessorManager, packetSizeMB int, mercuryURL string, encrypt, encryptLegacy bool) (err error) {
+	if encrypt && mercuryURL == "" {
+		return UsageErrorf("--enc/--enc13 requires --mercury-url pointing at a running xZMercury instance")
+	}
+	enc := &brokerEncoder{
+		tableName:     tableName,
+		compress:      compress,
+		compressLevel: compressLevel,
+		compressAlgo:  compressAlgo,
+		mercuryURL:    mercuryURL,
+		encrypt:       encrypt,
+		encryptLegacy: encryptLegacy,
+		rowChecksum:   brokerCfg.RowChecksum,
+		exclusions:    brokerCfg.Exclusions,
+	}
+	// v1.5 encryption needs a Mercury client shared across the per-packet
+	// goroutines below for the mandatory integrity step — one instance,
+	// not one per packet.
+	if encrypt && !encryptLegacy {
+		enc.integrityClient = mercury.NewClient(mercuryURL, 5000)
+	}
+
 	// Create database adapter
 	adapter, err := adapters.New(ctx, *dbConfig)
 	if err != nil {
@@ -59,8 +93,6 @@ func ExportToBroker(ctx context.Context, dbConfig *adapters.Config, brokerCfg *B
 	}
 	defer func() { _ = adapter.Close(ctx) }()
 
-	fmt.Printf("Exporting table '%s' to broker...\n", tableName)
-
 	// Configure packet size if requested
 	type packetSizeSetter interface{ SetMaxMessageSize(int) }
 	if packetSizeMB > 0 {
@@ -70,6 +102,12 @@ func ExportToBroker(ctx context.Context, dbConfig *adapters.Config, brokerCfg *B
 		}
 	}
 
+	if brokerCfg.RetryRun != "" {
+		return retryFailedParts(ctx, adapter, brokerCfg, tableName, enc)
+	}
+
+	fmt.Printf("Exporting table '%s' to broker...\n", tableName)
+
 	// Export data
 	var packets []*packet.DataPacket
 	if query != nil {
@@ -90,6 +128,11 @@ func ExportToBroker(ctx context.Context, dbConfig *adapters.Config, brokerCfg *B
 
 	fmt.Printf("✓ Exported %d packet(s)\n", len(packets))
 
+	// The publish span's context goes into every packet header (encode),
+	// so the consumer continues this trace
+	ctx, span := tracing.StartPublish(ctx, brokerCfg.Type, brokerCfg.Queue)
+	defer func() { tracing.End(span, err) }()
+
 	// Create broker (параллельно с подготовкой данных)
 	broker, err := createBroker(brokerCfg)
 	if err != nil {
@@ -102,9 +145,6 @@ func ExportToBroker(ctx context.Context, dbConfig *adapters.Config, brokerCfg *B
 		fmt.Printf("Compressing data (algo: %s, level %d)...\n", compressAlgo, compressLevel)
 	}
 	if encrypt {
-		if mercuryURL == "" {
-			return fmt.Errorf("--enc/--enc13 requires --mercury-url pointing at a running xZMercury instance")
-		}
 		if encryptLegacy {
 			fmt.Println("Encrypting data (TDTP v1.3 whole-blob via xZMercury)...")
 		} else {
@@ -112,17 +152,18 @@ func ExportToBroker(ctx context.Context, dbConfig *adapters.Config, brokerCfg *B
 		}
 	}
 
+	// Part keys are taken from plaintext rows, before compression/encryption
+	var parts []history.Part
+	if brokerCfg.History != nil {
+		parts = make([]history.Part, len(packets))
+		for i, pkt := range packets {
+			parts[i] = newExportPart(i+1, pkt)
+		}
+	}
+
 	xmlMsgs := make([][]byte, len(packets))
 	errs := make([]error, len(packets))
 
-	// v1.5 encryption needs a Mercury client shared across the per-packet
-	// goroutines below for the mandatory integrity step — one instance,
-	// not one per packet.
-	var integrityClient *mercury.Client
-	if encrypt && !encryptLegacy {
-		integrityClient = mercury.NewClient(mercuryURL, 5000)
-	}
-
 	// Encryption calls xZMercury over HTTP per packet — keep this concurrent
 	// like compression/marshal already are, not a reason to serialize.
 	var wg sync.WaitGroup
@@ -130,56 +171,12 @@ func ExportToBroker(ctx context.Context, dbConfig *adapters.Config, brokerCfg *B
 		wg.Add(1)
 		go func(i int, pkt *packet.DataPacket) {
 			defer wg.Done()
-
-			// v1.4 integrity is mandatory ahead of v1.5 encryption, not
-			// opt-in — see pkg/pipeline/produce.go's doc comment: without
-			// this, VerifyAndPrepare's consumer-side pre-flight (which
-			// always runs once --mercury-url is set, and v1.5 decryption
-			// requires it) blocks the packet with HASH_NOT_REGISTERED.
-			// Must run before compression (hashes cover plaintext).
-			if encrypt && !encryptLegacy {
-				if err := pipeline.ComputeAndRegisterIntegrity(ctx, pkt, integrityClient, tableName); err != nil {
-					errs[i] = fmt.Errorf("packet %d integrity: %w", i+1, err)
-					return
-				}
-			}
-
-			if compress {
-				if err := compressPacketData(pkt, compressLevel, compressAlgo, true); err != nil { // checksum always enabled with compression
-					errs[i] = fmt.Errorf("packet %d compress: %w", i+1, err)
-					return
-				}
-			}
-
-			if encrypt && encryptLegacy {
-				// EncryptPacket marshals pkt to XML internally before encrypting —
-				// no separate marshal step needed here.
-				blob, _, err := EncryptPacket(ctx, pkt, mercuryURL, tableName)
-				if err != nil {
-					errs[i] = fmt.Errorf("packet %d encrypt (v1.3): %w", i+1, err)
-					return
-				}
-				xmlMsgs[i] = blob
-				return
-			}
-
-			if encrypt {
-				xml, _, err := EncryptPacketV15(ctx, pkt, mercuryURL, tableName)
-				if err != nil {
-					errs[i] = fmt.Errorf("packet %d encrypt (v1.5): %w", i+1, err)
-					return
-				}
-				xmlMsgs[i] = xml
-				return
-			}
-
-			gen := packet.NewGenerator()
-			xml, err := gen.ToXML(pkt, true)
+			msg, err := enc.encode(ctx, pkt)
 			if err != nil {
-				errs[i] = fmt.Errorf("packet %d marshal: %w", i+1, err)
+				errs[i] = fmt.Errorf("packet %d %w", i+1, err)
 				return
 			}
-			xmlMsgs[i] = xml
+			xmlMsgs[i] = msg
 		}(i, pkt)
 	}
 	wg.Wait()
@@ -197,6 +194,11 @@ func ExportToBroker(ctx context.Context, dbConfig *adapters.Config, brokerCfg *B
 		fmt.Printf("✓ Data encrypted (%s)\n", map[bool]string{true: "v1.3 whole-blob", false: "v1.5 section-level"}[encryptLegacy])
 	}
 
+	// Per-part delivery is tracked in the run history
+	if parts != nil {
+		return sendTrackedParts(ctx, broker, brokerCfg, tableName, query, parts, xmlMsgs)
+	}
+
 	// Connect to broker
 	if err := broker.Connect(ctx); err != nil {
 		return fmt.Errorf("failed to connect to broker: %w", err)
@@ -221,9 +223,103 @@ func ExportToBroker(ctx context.Context, dbConfig *adapters.Config, brokerCfg *B
 	fmt.Printf("✓ Sent %d packet(s)\n", len(packets))
 	fmt.Println("✓ Export to broker complete!")
 
+	if brokerCfg.Quota != nil {
+		var rows, size int64
+		for i, pkt := range packets {
+			rows += int64(pkt.Header.RecordsInPart)
+			size += int64(len(xmlMsgs[i]))
+		}
+		recordBrokerQuota(brokerCfg, rows, size)
+	}
+
 	return nil
 }
 
+// brokerEncoder turns an exported packet into a broker message: column
+// exclusions, row checksum, v1.4 integrity, compression and encryption as
+// requested.
+type brokerEncoder struct {
+	tableName       string
+	compress        bool
+	compressLevel   int
+	compressAlgo    string
+	mercuryURL      string
+	encrypt         bool
+	encryptLegacy   bool
+	rowChecksum     string
+	integrityClient *mercury.Client
+	exclusions      *adapters.ColumnExclusions
+}
+
+// encode prepares pkt (modified in place) and returns the message bytes.
+func (e *brokerEncoder) encode(ctx context.Context, pkt *packet.DataPacket) ([]byte, error) {
+	tracing.Inject(ctx, &pkt.Header)
+
+	// Excluded columns leave before anything is computed over the rows
+	if _, err := e.exclusions.Apply(pkt); err != nil {
+		return nil, err
+	}
+
+	// v1.4 integrity is mandatory ahead of v1.5 encryption, not
+	// opt-in — see pkg/pipeline/produce.go's doc comment: without
+	// this, VerifyAndPrepare's consumer-side pre-flight (which
+	// always runs once --mercury-url is set, and v1.5 decryption
+	// requires it) blocks the packet with HASH_NOT_REGISTERED.
+	// Must run before compression (hashes cover plaintext).
+	if e.rowChecksum != packet.ChecksumOff {
+		if err := packet.StampRowChecksum(pkt, e.rowChecksum == packet.ChecksumRows); err != nil {
+			return nil, fmt.Errorf("checksum: %w", err)
+		}
+	}
+
+	if e.encrypt && !e.encryptLegacy {
+		if err := pipeline.ComputeAndRegisterIntegrity(ctx, pkt, e.integrityClient, e.tableName); err != nil {
+			return nil, fmt.Errorf("integrity: %w", err)
+		}
+	}
+
+	if e.compress {
+		if err := compressPacketData(pkt, e.compressLevel, e.compressAlgo, true); err != nil { // checksum always enabled with compression
+			return nil, fmt.Errorf("compress: %w", err)
+		}
+	}
+
+	if e.encrypt && e.encryptLegacy {
+		// EncryptPacket marshals pkt to XML internally before encrypting —
+		// no separate marshal step needed here.
+		blob, _, err := EncryptPacket(ctx, pkt, e.mercuryURL, e.tableName)
+		if err != nil {
+			return nil, fmt.Errorf("encrypt (v1.3): %w", err)
+		}
+		return blob, nil
+	}
+
+	if e.encrypt {
+		xml, _, err := EncryptPacketV15(ctx, pkt, e.mercuryURL, e.tableName)
+		if err != nil {
+			return nil, fmt.Errorf("encrypt (v1.5): %w", err)
+		}
+		return xml, nil
+	}
+
+	gen := packet.NewGenerator()
+	xml, err := gen.ToXML(pkt, true)
+	if err != nil {
+		return nil, fmt.Errorf("marshal: %w", err)
+	}
+	return xml, nil
+}
+
+// recordBrokerQuota accounts delivered rows and bytes against the queue quota.
+func recordBrokerQuota(brokerCfg *BrokerConfig, rows, size int64) {
+	if brokerCfg.Quota == nil {
+		return
+	}
+	if _, err := brokerCfg.Quota.Record(brokerCfg.Queue, rows, size, time.Now()); err != nil {
+		fmt.Printf("⚠ Quota accounting failed: %v\n", err)
+	}
+}
+
 // defaultIdleTimeout is how long --import-broker waits for the next message
 // before deciding the queue is empty and stopping.
 const defaultIdleTimeout = 5 * time.Second
@@ -255,7 +351,7 @@ type ImportBrokerOptions struct {
 // Packets from a different batch are Nack'd with requeue=true so they stay in
 // the queue untouched. The function exits once all TotalParts are received or
 // the idle timeout fires (queue empty).
-func ImportFromBroker(ctx context.Context, dbConfig *adapters.Config, brokerCfg *BrokerConfig, opts ImportBrokerOptions) error {
+func ImportFromBroker(ctx context.Context, dbConfig *adapters.Config, brokerCfg *BrokerConfig, opts ImportBrokerOptions) (err error) {
 	// Create and connect broker.
 	broker, err := createBroker(brokerCfg)
 	if err != nil {
@@ -284,7 +380,7 @@ func ImportFromBroker(ctx context.Context, dbConfig *adapters.Config, brokerCfg
 
 	// --keep mode: streaming — receive → decompress → import immediately, no full buffer.
 	if opts.Keep {
-		return importBrokerKeep(ctx, broker, adapter, opts)
+		return importBrokerKeep(ctx, broker, adapter, brokerCfg.Queue, opts)
 	}
 
 	idleTimeout := opts.IdleTimeout
@@ -327,6 +423,10 @@ func ImportFromBroker(ctx context.Context, dbConfig *adapters.Config, brokerCfg
 		return fmt.Errorf("failed to parse first packet: %w", err)
 	}
 
+	// The batch continues the producer's trace (traceparent in the header)
+	ctx, span := tracing.StartConsume(ctx, &firstPkt.Header, brokerCfg.Type, brokerCfg.Queue)
+	defer func() { tracing.End(span, err) }()
+
 	batchID := batchIDFromMessageID(firstPkt.Header.MessageID)
 	totalParts := firstPkt.Header.TotalParts
 	if totalParts == 0 {
@@ -461,7 +561,7 @@ func ImportFromBroker(ctx context.Context, dbConfig *adapters.Config, brokerCfg
 // Each packet is received, decompressed, and committed to the DB immediately —
 // no full in-memory buffering of the whole batch. On failure the successfully
 // committed parts remain in the table and can be inspected or rolled back manually.
-func importBrokerKeep(ctx context.Context, broker brokers.MessageBroker, adapter adapters.Adapter, opts ImportBrokerOptions) error {
+func importBrokerKeep(ctx context.Context, broker brokers.MessageBroker, adapter adapters.Adapter, queue string, opts ImportBrokerOptions) error {
 	idleTimeout := opts.IdleTimeout
 	if idleTimeout == 0 {
 		idleTimeout = defaultIdleTimeout
@@ -510,7 +610,11 @@ func importBrokerKeep(ctx context.Context, broker brokers.MessageBroker, adapter
 	fmt.Printf("Importing batch '%s' (%d part(s)) from queue '%s' (strategy: %s, --keep)\n",
 		batchID, totalParts, opts.IdleTimeout, opts.Strategy)
 
-	importOne := func(pkt *packet.DataPacket, n int) error {
+	importOne := func(pkt *packet.DataPacket, n int) (err error) {
+		// Each committed part is its own consume span of the producer's trace
+		ctx, span := tracing.StartConsume(ctx, &pkt.Header, broker.GetBrokerType(), queue)
+		defer func() { tracing.End(span, err) }()
+
 		// ── Security gate (v1.4) ─────────────────────────────────────────────
 		if err := applyV14SecurityGate(ctx, pkt, opts.MercuryURL); err != nil {
 			return fmt.Errorf("part %d: %w", n, err)
@@ -579,7 +683,7 @@ func batchIDFromMessageID(messageID string) string {
 // Files: outputFile (single) or base_part_N_of_Total.ext (multi-part).
 func importBrokerRaw(ctx context.Context, broker brokers.MessageBroker, opts ImportBrokerOptions) error {
 	if opts.OutputFile == "" {
-		return fmt.Errorf("--raw requires --output <file>")
+		return UsageErrorf("--raw requires --output <file>")
 	}
 
 	idleTimeout := opts.IdleTimeout
@@ -708,6 +812,8 @@ func createBroker(cfg *BrokerConfig) (brokers.MessageBroker, error) {
 		Brokers:        kafkaBrokers,
 		Topic:          cfg.Queue,
 		ConsumerGroup:  cfg.ConsumerGroup,
+		Dir:            cfg.Dir,
+		AutoAck:        cfg.AutoAck,
 	}
 
 	return brokers.New(brokerConfig)
@@ -0,0 +1,303 @@
+package commands
+
+// Per-part delivery of --export-broker.
+//
+// With a history store configured, every exported part is sent on its own
+// and its delivery status (with the primary-key range of its rows and,
+// with --parts-archive, a copy of the message) is recorded in the run
+// history. A run with undelivered parts fails, and
+// --retry-failed-parts <run-id> resends only those parts: from the archive
+// when the copy exists, otherwise by re-exporting the rows with the part's
+// recorded primary-key values under the run's original query.
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+	"github.com/ruslano69/tdtp-framework/pkg/brokers"
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+	"github.com/ruslano69/tdtp-framework/pkg/core/tdtql"
+	"github.com/ruslano69/tdtp-framework/pkg/history"
+)
+
+// retryKeysPerQuery bounds the key values re-exported by one query.
+const retryKeysPerQuery = 500
+
+// newExportPart describes part n before it is encoded: row count and the
+// values of its single-column primary key (none for composite or no key).
+// The exact values, not a range, are kept: a range of one part can cover
+// rows of another, and text keys do not sort the same in Go and in the
+// database collation.
+func newExportPart(n int, pkt *packet.DataPacket) history.Part {
+	rows := pkt.GetRows()
+	part := history.Part{Number: n, MessageID: pkt.Header.MessageID, Rows: len(rows)}
+	keyIdx := -1
+	for i, f := range pkt.Schema.Fields {
+		if f.Key {
+			if keyIdx >= 0 {
+				return part
+			}
+			keyIdx = i
+		}
+	}
+	if keyIdx < 0 || len(rows) == 0 {
+		return part
+	}
+
+	keys := make([]string, 0, len(rows))
+	for _, row := range rows {
+		if keyIdx >= len(row) {
+			return part
+		}
+		keys = append(keys, row[keyIdx])
+	}
+	part.KeyField, part.Keys = pkt.Schema.Fields[keyIdx].Name, keys
+	return part
+}
+
+// sendTrackedParts sends the messages one by one, recording each part's
+// delivery in a KindExport run. Undelivered parts fail the run.
+// The export query is kept in the run so a retry re-exports with the same
+// filters and projection.
+func sendTrackedParts(ctx context.Context, broker brokers.MessageBroker, brokerCfg *BrokerConfig, tableName string, query *packet.Query, parts []history.Part, msgs [][]byte) error {
+	run := history.NewRun(history.KindExport, tableName)
+	run.Metadata = map[string]string{
+		"broker": brokerCfg.Type,
+		"queue":  brokerCfg.Queue,
+		"parts":  strconv.Itoa(len(parts)),
+	}
+	if query != nil {
+		data, err := json.Marshal(query)
+		if err != nil {
+			return fmt.Errorf("failed to record export query: %w", err)
+		}
+		run.Metadata["query"] = string(data)
+	}
+
+	var archiveDir string
+	if brokerCfg.ArchiveDir != "" {
+		archiveDir = filepath.Join(brokerCfg.ArchiveDir, run.ID)
+		if err := os.MkdirAll(archiveDir, 0o750); err != nil {
+			fmt.Printf("⚠ Parts archive disabled: %v\n", err)
+			archiveDir = ""
+		}
+	}
+
+	connectErr := broker.Connect(ctx)
+	if connectErr != nil {
+		connectErr = fmt.Errorf("failed to connect to broker: %w", connectErr)
+	}
+	var delivered, size int64
+	for i, part := range parts {
+		if archiveDir != "" {
+			path := filepath.Join(archiveDir, fmt.Sprintf("part_%06d.msg", part.Number))
+			if err := os.WriteFile(path, msgs[i], 0o600); err != nil {
+				fmt.Printf("⚠ Failed to archive part %d: %v\n", part.Number, err)
+			} else {
+				part.Archive = path
+			}
+		}
+
+		err := connectErr
+		if err == nil {
+			err = broker.Send(ctx, msgs[i])
+		}
+		part.Status = history.PartDelivered
+		if err != nil {
+			part.Status, part.Error = history.PartFailed, err.Error()
+		} else {
+			delivered += int64(part.Rows)
+			size += int64(len(msgs[i]))
+		}
+		run.SetPart(part)
+		run.RowsRead += int64(part.Rows)
+	}
+	run.RowsWritten = delivered
+	recordBrokerQuota(brokerCfg, delivered, size)
+
+	err := partsError(run)
+	if err == nil {
+		fmt.Printf("✓ Sent %d packet(s)\n", len(parts))
+		fmt.Println("✓ Export to broker complete!")
+	}
+	recordRun(ctx, brokerCfg.History, run, err)
+	return err
+}
+
+// partsError reports the undelivered parts of run.
+func partsError(run *history.Run) error {
+	failed := run.FailedParts()
+	if len(failed) == 0 {
+		return nil
+	}
+	numbers := make([]string, 0, len(failed))
+	for i, p := range failed {
+		if i == 10 {
+			numbers = append(numbers, "...")
+			break
+		}
+		numbers = append(numbers, strconv.Itoa(p.Number))
+	}
+	return fmt.Errorf("%d of %d part(s) not delivered (parts %v, first error: %s); resend them with --retry-failed-parts %s",
+		len(failed), len(run.Parts), numbers, failed[0].Error, run.ID)
+}
+
+// retryFailedParts resends the undelivered parts of brokerCfg.RetryRun and
+// records the outcome as a new run carrying the state of every part.
+func retryFailedParts(ctx context.Context, adapter adapters.Adapter, brokerCfg *BrokerConfig, tableName string, enc *brokerEncoder) error {
+	if brokerCfg.History == nil {
+		return fmt.Errorf("--retry-failed-parts requires a history: section in --config")
+	}
+	prev, err := brokerCfg.History.Get(ctx, brokerCfg.RetryRun)
+	if err != nil {
+		return err
+	}
+	if prev == nil {
+		return fmt.Errorf("run %s not found in history", brokerCfg.RetryRun)
+	}
+	if prev.Kind != history.KindExport || prev.Name != tableName {
+		return fmt.Errorf("run %s is not an --export-broker run of table '%s'", prev.ID, tableName)
+	}
+	// Parts go only where the original run sent them
+	if prev.Metadata["queue"] != brokerCfg.Queue {
+		return fmt.Errorf("run %s was sent to queue '%s', the config points at '%s'", prev.ID, prev.Metadata["queue"], brokerCfg.Queue)
+	}
+	failed := prev.FailedParts()
+	if len(failed) == 0 {
+		fmt.Printf("✓ All %d part(s) of run %s were delivered, nothing to retry\n", len(prev.Parts), prev.ID)
+		return nil
+	}
+	fmt.Printf("Retrying %d of %d part(s) of run %s...\n", len(failed), len(prev.Parts), prev.ID)
+
+	broker, err := createBroker(brokerCfg)
+	if err != nil {
+		return fmt.Errorf("failed to create broker: %w", err)
+	}
+	defer func() { _ = broker.Close() }()
+
+	run := history.NewRun(history.KindExport, tableName)
+	run.Metadata = make(map[string]string, len(prev.Metadata)+1)
+	for k, v := range prev.Metadata {
+		run.Metadata[k] = v
+	}
+	run.Metadata["retry_of"] = prev.ID
+	run.Parts = append([]history.Part(nil), prev.Parts...)
+
+	connectErr := broker.Connect(ctx)
+	if connectErr != nil {
+		connectErr = fmt.Errorf("failed to connect to broker: %w", connectErr)
+	}
+	var size int64
+	for _, part := range failed {
+		err := connectErr
+		var msgs [][]byte
+		if err == nil {
+			msgs, err = partMessages(ctx, adapter, tableName, prev.Metadata["query"], &part, enc)
+		}
+		var partSize int64
+		for _, msg := range msgs {
+			if err = broker.Send(ctx, msg); err != nil {
+				break
+			}
+			partSize += int64(len(msg))
+		}
+		size += partSize
+		if err != nil {
+			part.Status, part.Error = history.PartFailed, err.Error()
+			fmt.Printf("  ❌ part %d: %v\n", part.Number, err)
+		} else {
+			part.Status, part.Error = history.PartDelivered, ""
+			run.RowsWritten += int64(part.Rows)
+			fmt.Printf("  ✓ part %d (%d rows)\n", part.Number, part.Rows)
+		}
+		run.RowsRead += int64(part.Rows)
+		run.SetPart(part)
+	}
+	recordBrokerQuota(brokerCfg, run.RowsWritten, size)
+
+	err = partsError(run)
+	if err == nil {
+		fmt.Printf("✓ All %d part(s) of run %s delivered\n", len(run.Parts), prev.ID)
+	}
+	recordRun(ctx, brokerCfg.History, run, err)
+	return err
+}
+
+// partMessages returns the messages that redeliver part: the archived copy
+// or, without one, the rows with its key values exported again (current row
+// values) under the original query of the run (JSON, empty — no query).
+func partMessages(ctx context.Context, adapter adapters.Adapter, tableName, origQuery string, part *history.Part, enc *brokerEncoder) ([][]byte, error) {
+	if part.Archive != "" {
+		data, err := os.ReadFile(part.Archive)
+		if err == nil {
+			return [][]byte{data}, nil
+		}
+		if len(part.Keys) == 0 {
+			return nil, fmt.Errorf("archived copy unavailable: %w", err)
+		}
+		fmt.Printf("⚠ Part %d: archived copy unavailable (%v), re-exporting its rows by key\n", part.Number, err)
+	}
+	if len(part.Keys) == 0 {
+		return nil, fmt.Errorf("no archived copy and no single-column primary key values to re-export")
+	}
+
+	var msgs [][]byte
+	rows := 0
+	for start := 0; start < len(part.Keys); start += retryKeysPerQuery {
+		keys := part.Keys[start:min(start+retryKeysPerQuery, len(part.Keys))]
+		query, err := partQuery(origQuery, part.KeyField, keys)
+		if err != nil {
+			return nil, err
+		}
+		packets, err := adapter.ExportTableWithQuery(ctx, tableName, query, "tdtpcli", "")
+		if err != nil {
+			return nil, fmt.Errorf("re-export of %d %s value(s): %w", len(keys), part.KeyField, err)
+		}
+		for _, pkt := range packets {
+			rows += pkt.Header.RecordsInPart
+			msg, err := enc.encode(ctx, pkt)
+			if err != nil {
+				return nil, fmt.Errorf("re-export %w", err)
+			}
+			msgs = append(msgs, msg)
+		}
+	}
+	part.Rows = rows
+	return msgs, nil
+}
+
+// partQuery narrows the original export query (JSON) to the rows with the
+// given key values. Paging is dropped: the keys already name the rows.
+// Keys are compared exactly, whatever the query's default collation.
+func partQuery(origQuery, keyField string, keys []string) (*packet.Query, error) {
+	query := packet.NewQuery()
+	if origQuery != "" {
+		if err := json.Unmarshal([]byte(origQuery), query); err != nil {
+			return nil, fmt.Errorf("invalid export query in run: %w", err)
+		}
+	}
+	query.Limit, query.Offset, query.After = 0, 0, nil
+
+	match := packet.LogicalGroup{Filters: make([]packet.Filter, len(keys))}
+	for i, key := range keys {
+		match.Filters[i] = packet.Filter{Field: keyField, Operator: "eq", Value: key, Collation: tdtql.CollationBinary}
+	}
+	where := &packet.LogicalGroup{Or: []packet.LogicalGroup{match}}
+	if f := query.Filters; f != nil {
+		if f.And != nil {
+			where.And = append(where.And, *f.And)
+		}
+		if f.Or != nil {
+			where.Or = append(where.Or, *f.Or)
+		}
+		f.And, f.Or = where, nil
+	} else {
+		query.Filters = &packet.Filters{And: where}
+	}
+	return query, nil
+}
@@ -0,0 +1,118 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+	_ "github.com/ruslano69/tdtp-framework/pkg/adapters/sqlite"
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+	"github.com/ruslano69/tdtp-framework/pkg/history"
+)
+
+// TestNewExportPart_Keys checks that a part records the values of a
+// single-column primary key and no keys for a composite key.
+func TestNewExportPart_Keys(t *testing.T) {
+	gen := packet.NewGenerator()
+	schema := packet.Schema{Fields: []packet.Field{
+		{Name: "id", Type: "INTEGER", Key: true},
+		{Name: "name", Type: "TEXT"},
+	}}
+	packets, err := gen.GenerateReference("users", schema, [][]string{{"9", "a"}, {"10", "b"}, {"2", "c"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	part := newExportPart(3, packets[0])
+	if part.Number != 3 || part.Rows != 3 || part.KeyField != "id" || !slices.Equal(part.Keys, []string{"9", "10", "2"}) {
+		t.Errorf("part: %+v", part)
+	}
+
+	schema.Fields[1].Key = true
+	packets, err = gen.GenerateReference("users", schema, [][]string{{"1", "a"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if part := newExportPart(1, packets[0]); part.KeyField != "" {
+		t.Errorf("composite key got keys %+v", part)
+	}
+}
+
+// TestPartsError checks that only undelivered parts fail the run and the
+// error names the run to retry.
+func TestPartsError(t *testing.T) {
+	run := history.NewRun(history.KindExport, "users")
+	run.SetPart(history.Part{Number: 1, Status: history.PartDelivered})
+	if err := partsError(run); err != nil {
+		t.Fatalf("all delivered: %v", err)
+	}
+	run.SetPart(history.Part{Number: 2, Status: history.PartFailed, Error: "timeout"})
+	err := partsError(run)
+	if err == nil || !strings.Contains(err.Error(), "1 of 2 part(s)") || !strings.Contains(err.Error(), "--retry-failed-parts "+run.ID) {
+		t.Errorf("got %v", err)
+	}
+}
+
+// TestPartMessages_ReExportByKeys checks that a part without an archive is
+// re-exported as exactly its own rows, under the filters and projection of
+// the original query: no rows of neighbouring parts, whatever the key order.
+func TestPartMessages_ReExportByKeys(t *testing.T) {
+	ctx := context.Background()
+	adapter, err := adapters.New(ctx, adapters.Config{Type: "sqlite", DSN: filepath.Join(t.TempDir(), "parts.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = adapter.Close(ctx) }()
+
+	schema := packet.Schema{Fields: []packet.Field{
+		{Name: "code", Type: "TEXT", Length: 10, Key: true},
+		{Name: "status", Type: "TEXT", Length: 10},
+		{Name: "note", Type: "TEXT", Length: 10},
+	}}
+	pkts, err := packet.NewGenerator().GenerateReference("items", schema, [][]string{
+		{"a", "open", "x"}, {"B", "open", "x"}, {"b", "closed", "x"}, {"c", "open", "x"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := adapter.ImportPacket(ctx, pkts[0], adapters.StrategyReplace); err != nil {
+		t.Fatal(err)
+	}
+
+	// The failed part held "c" and "a"; "B" and "b" went in another part
+	query := queryWithWhere(t, "status = 'open'")
+	query.Fields = []string{"code", "status"}
+	query.Limit = 2
+	data, err := json.Marshal(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	part := history.Part{Number: 2, KeyField: "code", Keys: []string{"c", "a"}}
+	msgs, err := partMessages(ctx, adapter, "items", string(data), &part, &brokerEncoder{tableName: "items"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(msgs) != 1 || part.Rows != 2 {
+		t.Fatalf("got %d message(s), %d row(s); want 1, 2", len(msgs), part.Rows)
+	}
+	pkt, err := packet.NewParser().ParseBytes(msgs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var codes []string
+	for _, row := range pkt.GetRows() {
+		codes = append(codes, row[0])
+	}
+	slices.Sort(codes)
+	if !slices.Equal(codes, []string{"a", "c"}) || len(pkt.Schema.Fields) != 2 {
+		t.Errorf("re-exported %v with %d field(s)", codes, len(pkt.Schema.Fields))
+	}
+
+	part = history.Part{Number: 3, Rows: 1}
+	if _, err := partMessages(ctx, adapter, "items", "", &part, &brokerEncoder{}); err == nil {
+		t.Error("part without archive and keys: want error")
+	}
+}
@@ -0,0 +1,81 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+	"github.com/ruslano69/tdtp-framework/pkg/processors"
+)
+
+// writeBundle writes all parts of an export into one .tdtpz bundle
+// (opts.OutputFile): parts are processed in order and streamed into the
+// archive, the manifest with per-part SHA-256 goes last. xZMercury
+// encryption (--enc/--enc13) is per packet and does not combine with
+// bundles — a bundle is encrypted as a whole with opts.BundleKey.
+func writeBundle(ctx context.Context, packets []*packet.DataPacket, chain *processors.PacketChain, opts ExportOptions) (err error) {
+	if opts.Encrypt {
+		return fmt.Errorf("--enc/--enc13 cannot be used with a %s bundle; encrypt the bundle with --bundle-key-file", packet.BundleExt)
+	}
+	if dir := filepath.Dir(opts.OutputFile); dir != "" && dir != "." {
+		if err := os.MkdirAll(dir, 0o750); err != nil {
+			return fmt.Errorf("failed to create directory: %w", err)
+		}
+	}
+	f, err := os.OpenFile(opts.OutputFile, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to create bundle: %w", err)
+	}
+	defer func() {
+		if closeErr := f.Close(); err == nil && closeErr != nil {
+			err = fmt.Errorf("failed to write bundle: %w", closeErr)
+		}
+		if err != nil {
+			_ = os.Remove(opts.OutputFile)
+		}
+	}()
+
+	bw, err := packet.NewBundleWriter(f, packet.BundleOptions{Key: opts.BundleKey})
+	if err != nil {
+		return err
+	}
+	for i, pkt := range packets {
+		if err := chain.ProcessPacket(ctx, pkt); err != nil {
+			return err
+		}
+		if err := bw.Add(pkt); err != nil {
+			return err
+		}
+		packets[i] = nil
+	}
+	manifest, err := bw.Close()
+	if err != nil {
+		return err
+	}
+
+	encrypted := ""
+	if manifest.Encryption != "" {
+		encrypted = ", encrypted"
+	}
+	fmt.Printf("✓ Bundle written: %s (%d part(s), %d row(s)%s)\n", opts.OutputFile, len(manifest.Parts), manifest.Rows(), encrypted)
+	return nil
+}
+
+// ReadBundleKeyFile reads a bundle key (64 hex characters) from path;
+// "" → no key.
+func ReadBundleKeyFile(path string) ([]byte, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bundle key: %w", err)
+	}
+	key, err := packet.ParseBundleKey(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return key, nil
+}
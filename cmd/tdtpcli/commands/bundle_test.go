@@ -0,0 +1,55 @@
+package commands
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+	"github.com/ruslano69/tdtp-framework/pkg/processors"
+)
+
+func TestWriteBundle(t *testing.T) {
+	schema := packet.Schema{Fields: []packet.Field{{Name: "id", Type: "INTEGER", Key: true}, {Name: "note", Type: "TEXT"}}}
+	var rows [][]string
+	for i := 1; i <= 100; i++ {
+		rows = append(rows, []string{strconv.Itoa(i), strings.Repeat("n", 50)})
+	}
+	gen := packet.NewGenerator()
+	gen.SetMaxMessageSize(3000)
+	packets, err := gen.GenerateReference("orders", schema, rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "bundle.key")
+	if err := os.WriteFile(keyFile, []byte(strings.Repeat("ab", 32)+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	key, err := ReadBundleKeyFile(keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := filepath.Join(dir, "out", "orders.tdtpz")
+	total := len(packets)
+	if err := writeBundle(context.Background(), packets, processors.NewPacketChain(), ExportOptions{OutputFile: out, BundleKey: key}); err != nil {
+		t.Fatal(err)
+	}
+	b, err := packet.OpenBundle(out, packet.BundleOptions{Key: key})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = b.Close() }()
+	if len(b.Manifest.Parts) != total || b.Manifest.Rows() != 100 || b.Manifest.Encryption == "" {
+		t.Errorf("manifest = %+v", b.Manifest)
+	}
+
+	if err := writeBundle(context.Background(), nil, processors.NewPacketChain(), ExportOptions{OutputFile: out, Encrypt: true}); err == nil {
+		t.Error("--enc with a bundle should fail")
+	}
+}
@@ -92,44 +92,20 @@ func ConvertTDTPToCSV(ctx context.Context, opts CSVOptions) error {
 	fmt.Printf("✓ Packet: table=%q version=%s fields=%d rows=%d\n",
 		pkt.Header.TableName, pkt.Version, len(pkt.Schema.Fields), len(pkt.Data.Rows))
 
-	// Apply TDTQL filters (--where, --order-by, --limit, --offset)
+	// Apply TDTQL query (--where, --order-by, --limit, --offset) and the
+	// column projection (--fields)
 	if opts.Query != nil {
 		executor := tdtql.NewExecutor()
 		execResult, err := executor.Execute(opts.Query, pkt.GetRows(), pkt.Schema)
 		if err != nil {
 			return fmt.Errorf("failed to apply query filters: %w", err)
 		}
-		pkt.SetRows(execResult.FilteredRows)
-		fmt.Printf("✓ Filtered: %d row(s) matched\n", len(execResult.FilteredRows))
-	}
-
-	// Resolve column projection from --fields.
-	// query.Fields drives SQL pushdown for DB exports; for in-memory packet
-	// conversion we apply projection here against the packet schema.
-	schemaFields := pkt.Schema.Fields
-	colIndices := make([]int, len(schemaFields)) // identity: all columns
-	for i := range schemaFields {
-		colIndices[i] = i
-	}
-	if opts.Query != nil && len(opts.Query.Fields) > 0 {
-		// Build a name→index map for fast lookup (case-insensitive).
-		nameIdx := make(map[string]int, len(schemaFields))
-		for i, f := range schemaFields {
-			nameIdx[strings.ToLower(f.Name)] = i
+		pkt.Schema = execResult.Schema
+		pkt.SetRows(execResult.Rows)
+		fmt.Printf("✓ Filtered: %d row(s) matched\n", len(execResult.Rows))
+		if len(opts.Query.Fields) > 0 {
+			fmt.Printf("✓ Projection: %d column(s) selected\n", len(pkt.Schema.Fields))
 		}
-		var projIndices []int
-		var projFields []packet.Field
-		for _, name := range opts.Query.Fields {
-			idx, ok := nameIdx[strings.ToLower(name)]
-			if !ok {
-				return fmt.Errorf("--fields: column %q not found in schema", name)
-			}
-			projIndices = append(projIndices, idx)
-			projFields = append(projFields, schemaFields[idx])
-		}
-		colIndices = projIndices
-		schemaFields = projFields
-		fmt.Printf("✓ Projection: %d column(s) selected\n", len(schemaFields))
 	}
 
 	// Open output writer
@@ -154,30 +130,16 @@ func ConvertTDTPToCSV(ctx context.Context, opts CSVOptions) error {
 	w := csv.NewWriter(out)
 	w.Comma = delim
 
-	// Header: projected field names
-	headers := make([]string, len(schemaFields))
-	for i, f := range schemaFields {
+	// Header: field names (projected by --fields)
+	headers := make([]string, len(pkt.Schema.Fields))
+	for i, f := range pkt.Schema.Fields {
 		headers[i] = f.Name
 	}
 	if err := w.Write(headers); err != nil {
 		return fmt.Errorf("failed to write CSV header: %w", err)
 	}
 
-	// Data rows — apply column projection when needed.
-	rows := pkt.GetRows()
-	projected := len(colIndices) < len(pkt.Schema.Fields)
-	for _, values := range rows {
-		var record []string
-		if projected {
-			record = make([]string, len(colIndices))
-			for i, idx := range colIndices {
-				if idx < len(values) {
-					record[i] = values[idx]
-				}
-			}
-		} else {
-			record = values
-		}
+	for _, record := range pkt.GetRows() {
 		if err := w.Write(record); err != nil {
 			return fmt.Errorf("failed to write CSV row: %w", err)
 		}
@@ -0,0 +1,531 @@
+package commands
+
+// Continuous incremental sync: tdtpcli --daemon --sync-config sync.yaml.
+//
+// The daemon runs the --sync-incremental jobs of the sync config on their
+// cron schedules (pkg/sync.Scheduler: overlap protection, jitter, one
+// checkpoint file per job, audit entries), serves GET /healthz and
+// GET /metrics, re-reads the sync config on SIGHUP and stops on
+// SIGTERM/SIGINT after the running syncs finish.
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	stdsync "sync"
+	"syscall"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/robfig/cron/v3"
+	"gopkg.in/yaml.v3"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+	"github.com/ruslano69/tdtp-framework/pkg/audit"
+	"github.com/ruslano69/tdtp-framework/pkg/history"
+	"github.com/ruslano69/tdtp-framework/pkg/sync"
+)
+
+const (
+	// DefaultDaemonListen is the /healthz and /metrics address when the sync
+	// config does not set listen.
+	DefaultDaemonListen = ":9464"
+
+	// daemonShutdownTimeout bounds how long shutdown and reload wait for
+	// running syncs before cancelling them.
+	daemonShutdownTimeout = 5 * time.Minute
+)
+
+// DaemonConfig is the --sync-config file of --daemon.
+type DaemonConfig struct {
+	Listen    string      `yaml:"listen"`     // /healthz and /metrics address (default :9464)
+	StateDir  string      `yaml:"state_dir"`  // checkpoint file per job: <state_dir>/<name>.json
+	OutputDir string      `yaml:"output_dir"` // packets: <output_dir>/<name>_sync_<timestamp>.xml
+	Timezone  string      `yaml:"timezone"`   // time zone of the schedules (default: local)
+	Defaults  JobTuning   `yaml:"defaults"`   // batch_size, commit_interval, parallelism of jobs that do not set them
+	Jobs      []DaemonJob `yaml:"jobs"`
+}
+
+// JobTuning is the per-table batching of a sync job. Small batches with a
+// short commit interval suit near-real-time tables, large ones bulk history.
+type JobTuning struct {
+	BatchSize int `yaml:"batch_size"` // rows per read (0 = all changes in one read)
+	// CommitInterval > 0 drains the backlog in one run: batches are read
+	// until the changes run out and the checkpoint is saved once this
+	// interval has passed since the last save (and after the last batch).
+	// 0 = one batch per run. Requires batch_size.
+	CommitInterval time.Duration `yaml:"commit_interval"`
+	Parallelism    int           `yaml:"parallelism"` // packet files written concurrently (default 1)
+}
+
+// apply fills the zero fields of t from defaults.
+func (t *JobTuning) apply(defaults JobTuning) {
+	if t.BatchSize == 0 {
+		t.BatchSize = defaults.BatchSize
+	}
+	if t.CommitInterval == 0 {
+		t.CommitInterval = defaults.CommitInterval
+	}
+	if t.Parallelism == 0 {
+		t.Parallelism = defaults.Parallelism
+	}
+}
+
+// validate rejects negative values and a commit interval without batches.
+func (t JobTuning) validate() error {
+	if t.BatchSize < 0 || t.CommitInterval < 0 || t.Parallelism < 0 {
+		return fmt.Errorf("batch_size, commit_interval and parallelism must not be negative")
+	}
+	if t.CommitInterval > 0 && t.BatchSize == 0 {
+		return fmt.Errorf("commit_interval requires batch_size")
+	}
+	return nil
+}
+
+// DaemonJob is one scheduled incremental sync; the fields mirror the
+// --sync-incremental flags.
+type DaemonJob struct {
+	Name           string        `yaml:"name"` // default: table
+	Table          string        `yaml:"table"`
+	Schedule       string        `yaml:"schedule"` // cron: "*/15 * * * *", "@hourly", "@every 10m"
+	Jitter         time.Duration `yaml:"jitter"`
+	TrackingField  string        `yaml:"tracking_field"` // default: updated_at
+	JobTuning      `yaml:",inline"`
+	Fields         []string `yaml:"fields"`
+	Deletes        string   `yaml:"deletes"` // --sync-deletes syntax
+	CDCSlot        string   `yaml:"cdc_slot"`
+	ChangeTracking bool     `yaml:"change_tracking"`
+	SchemaDrift    string   `yaml:"schema_drift"` // --sync-schema-drift: pause (default), migrate, accept
+}
+
+// DaemonOptions holds the tdtpcli-level settings shared by every job.
+type DaemonOptions struct {
+	ConfigFile   string
+	ProcessorMgr ProcessorManager
+	History      *history.Store
+	TableQueries map[string]string
+	Exclusions   *adapters.ColumnExclusions // export.exclude_columns, applied to every job
+	Audit        audit.Logger               // nil = no audit entries
+	Target       *adapters.Config           // target migrated by schema_drift: migrate (--target-config)
+}
+
+// LoadDaemonConfig reads and validates a sync config.
+func LoadDaemonConfig(path string) (*DaemonConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sync config: %w", err)
+	}
+	var cfg DaemonConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse sync config %s: %w", path, err)
+	}
+	if cfg.Listen == "" {
+		cfg.Listen = DefaultDaemonListen
+	}
+	if cfg.StateDir == "" {
+		cfg.StateDir = "sync_state"
+	}
+	if cfg.OutputDir == "" {
+		cfg.OutputDir = "."
+	}
+	if cfg.Timezone != "" {
+		if _, err := time.LoadLocation(cfg.Timezone); err != nil {
+			return nil, fmt.Errorf("sync config: invalid timezone %q: %w", cfg.Timezone, err)
+		}
+	}
+	if len(cfg.Jobs) == 0 {
+		return nil, fmt.Errorf("sync config %s defines no jobs", path)
+	}
+	if err := cfg.Defaults.validate(); err != nil {
+		return nil, fmt.Errorf("sync config: defaults: %w", err)
+	}
+
+	seen := make(map[string]bool, len(cfg.Jobs))
+	for i := range cfg.Jobs {
+		job := &cfg.Jobs[i]
+		if job.Table == "" {
+			return nil, fmt.Errorf("sync config: job %d: table is required", i+1)
+		}
+		if job.Name == "" {
+			job.Name = job.Table
+		}
+		if seen[job.Name] {
+			return nil, fmt.Errorf("sync config: duplicate job name %q", job.Name)
+		}
+		seen[job.Name] = true
+		if job.Schedule == "" {
+			return nil, fmt.Errorf("sync config: job %s: schedule is required", job.Name)
+		}
+		if _, err := cron.ParseStandard(job.Schedule); err != nil {
+			return nil, fmt.Errorf("sync config: job %s: invalid schedule %q: %w", job.Name, job.Schedule, err)
+		}
+		if job.TrackingField == "" && job.CDCSlot == "" && !job.ChangeTracking {
+			job.TrackingField = "updated_at"
+		}
+		job.JobTuning.apply(cfg.Defaults)
+		if err := job.JobTuning.validate(); err != nil {
+			return nil, fmt.Errorf("sync config: job %s: %w", job.Name, err)
+		}
+		if _, err := cfg.syncOptions(*job, DaemonOptions{}); err != nil {
+			return nil, fmt.Errorf("sync config: job %s: %w", job.Name, err)
+		}
+	}
+	return &cfg, nil
+}
+
+// requireTarget rejects schema_drift: migrate without a target database.
+func (c *DaemonConfig) requireTarget(opts DaemonOptions) error {
+	if opts.Target != nil {
+		return nil
+	}
+	for _, job := range c.Jobs {
+		if strings.EqualFold(job.SchemaDrift, string(sync.DriftMigrate)) {
+			return fmt.Errorf("sync config: job %s: schema_drift: migrate requires --target-config", job.Name)
+		}
+	}
+	return nil
+}
+
+// stateFile returns the checkpoint file of a job.
+func (c *DaemonConfig) stateFile(job DaemonJob) string {
+	return filepath.Join(c.StateDir, job.Name+".json")
+}
+
+// syncOptions builds the --sync-incremental options of one run of job.
+func (c *DaemonConfig) syncOptions(job DaemonJob, opts DaemonOptions) (SyncOptions, error) {
+	deletes, err := sync.ParseDeleteDetection(job.Deletes)
+	if err != nil {
+		return SyncOptions{}, fmt.Errorf("deletes: %w", err)
+	}
+	drift, err := sync.ParseSchemaDriftPolicy(job.SchemaDrift)
+	if err != nil {
+		return SyncOptions{}, fmt.Errorf("schema_drift: %w", err)
+	}
+	so := SyncOptions{
+		TableName:      job.Table,
+		OutputFile:     filepath.Join(c.OutputDir, fmt.Sprintf("%s_sync_%s.xml", job.Name, time.Now().Format("20060102_150405"))),
+		TrackingField:  job.TrackingField,
+		CheckpointFile: c.stateFile(job),
+		BatchSize:      job.BatchSize,
+		Parallelism:    job.Parallelism,
+		Fields:         job.Fields,
+		ProcessorMgr:   opts.ProcessorMgr,
+		History:        opts.History,
+		TableQueries:   opts.TableQueries,
+		Exclusions:     opts.Exclusions,
+		Deletes:        deletes,
+		CDCSlot:        job.CDCSlot,
+		ChangeTracking: job.ChangeTracking,
+		SchemaDrift:    drift,
+		Target:         opts.Target,
+		Audit:          opts.Audit,
+	}
+	return so, so.validate()
+}
+
+// daemonMetrics are the /metrics series of the daemon.
+type daemonMetrics struct {
+	registry    *prometheus.Registry
+	runs        *prometheus.CounterVec
+	records     *prometheus.CounterVec
+	duration    *prometheus.HistogramVec
+	lastSuccess *prometheus.GaugeVec
+	skipped     *prometheus.CounterVec
+	reloads     *prometheus.CounterVec
+}
+
+func newDaemonMetrics() *daemonMetrics {
+	m := &daemonMetrics{
+		registry: prometheus.NewRegistry(),
+		runs: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "tdtp_sync_runs_total",
+			Help: "Completed sync runs by job and status (success|failure).",
+		}, []string{"job", "status"}),
+		records: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "tdtp_sync_records_total",
+			Help: "Records synced by job.",
+		}, []string{"job"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "tdtp_sync_duration_seconds",
+			Help:    "Sync run wall-clock time by job.",
+			Buckets: []float64{1, 5, 15, 30, 60, 120, 300, 600, 1800},
+		}, []string{"job"}),
+		lastSuccess: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "tdtp_sync_last_success_timestamp_seconds",
+			Help: "Unix time of the last successful sync run by job.",
+		}, []string{"job"}),
+		skipped: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "tdtp_sync_skipped_total",
+			Help: "Scheduled runs skipped because the previous run was still in progress.",
+		}, []string{"job"}),
+		reloads: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "tdtp_sync_config_reloads_total",
+			Help: "Sync config reloads (SIGHUP) by status (success|failure).",
+		}, []string{"status"}),
+	}
+	m.registry.MustRegister(m.runs, m.records, m.duration, m.lastSuccess, m.skipped, m.reloads,
+		collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	return m
+}
+
+func (m *daemonMetrics) observe(job string, result sync.SyncResult, elapsed time.Duration, err error) {
+	m.duration.WithLabelValues(job).Observe(elapsed.Seconds())
+	if err != nil {
+		m.runs.WithLabelValues(job, "failure").Inc()
+		return
+	}
+	m.runs.WithLabelValues(job, "success").Inc()
+	m.records.WithLabelValues(job).Add(float64(result.Records))
+	m.lastSuccess.WithLabelValues(job).SetToCurrentTime()
+}
+
+// daemon holds the active scheduler; reload swaps it.
+type daemon struct {
+	db      *adapters.Config
+	opts    DaemonOptions
+	metrics *daemonMetrics
+
+	mu        stdsync.Mutex
+	cfg       *DaemonConfig
+	scheduler *sync.Scheduler
+	loadedAt  time.Time
+}
+
+// RunDaemon runs the sync config jobs until SIGTERM/SIGINT or ctx is done.
+func RunDaemon(ctx context.Context, dbConfig *adapters.Config, opts DaemonOptions) error {
+	cfg, err := LoadDaemonConfig(opts.ConfigFile)
+	if err != nil {
+		return err
+	}
+	if err := cfg.requireTarget(opts); err != nil {
+		return err
+	}
+	d := &daemon{db: dbConfig, opts: opts, metrics: newDaemonMetrics()}
+	scheduler, err := d.newScheduler(cfg)
+	if err != nil {
+		return err
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	d.activate(runCtx, cfg, scheduler)
+
+	server := &http.Server{Addr: cfg.Listen, Handler: d.handler(), ReadHeaderTimeout: 10 * time.Second}
+	serverErr := make(chan error, 1)
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+	}()
+
+	fmt.Printf("[daemon] %d sync job(s) from %s\n", len(cfg.Jobs), opts.ConfigFile)
+	for _, job := range cfg.Jobs {
+		next, _ := scheduler.NextRun(job.Name)
+		fmt.Printf("[daemon]   %-20s %-16s next run %s\n", job.Name, job.Schedule, next.Format(time.RFC3339))
+	}
+	fmt.Printf("[daemon] /healthz and /metrics on %s; SIGHUP reloads the sync config\n", cfg.Listen)
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGTERM, syscall.SIGINT, syscall.SIGHUP)
+	defer signal.Stop(sigCh)
+
+loop:
+	for {
+		select {
+		case sig := <-sigCh:
+			if sig == syscall.SIGHUP {
+				d.reload(runCtx)
+				continue
+			}
+			fmt.Printf("\n[daemon] %s received, waiting for running syncs...\n", sig)
+			break loop
+		case <-ctx.Done():
+			break loop
+		case err := <-serverErr:
+			d.stop()
+			return fmt.Errorf("daemon HTTP server: %w", err)
+		}
+	}
+
+	d.stop()
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelShutdown()
+	_ = server.Shutdown(shutdownCtx) //nolint:errcheck // best effort on exit
+	fmt.Printf("[daemon] stopped\n")
+	return nil
+}
+
+// newScheduler registers the jobs of cfg with a new (not started) scheduler.
+func (d *daemon) newScheduler(cfg *DaemonConfig) (*sync.Scheduler, error) {
+	location := time.Local
+	if cfg.Timezone != "" {
+		location, _ = time.LoadLocation(cfg.Timezone) //nolint:errcheck // checked by LoadDaemonConfig
+	}
+	scheduler := sync.NewScheduler(sync.SchedulerConfig{
+		StateDir: cfg.StateDir,
+		Location: location,
+		Audit:    d.opts.Audit,
+		OnSkip: func(job string) {
+			d.metrics.skipped.WithLabelValues(job).Inc()
+			fmt.Printf("[daemon] %s: previous run still in progress, skipped\n", job)
+		},
+	})
+	for _, job := range cfg.Jobs {
+		err := scheduler.Add(sync.ScheduledJob{
+			Name:           job.Name,
+			Schedule:       job.Schedule,
+			Table:          job.Table,
+			StateFile:      cfg.stateFile(job),
+			Jitter:         job.Jitter,
+			CommitInterval: job.CommitInterval,
+			Run:            d.syncFunc(cfg, job),
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return scheduler, nil
+}
+
+// syncFunc runs one --sync-incremental of job; the scheduler saves the
+// returned checkpoints.
+func (d *daemon) syncFunc(cfg *DaemonConfig, job DaemonJob) sync.SyncFunc {
+	batch := 0 // runs of a job never overlap
+	return func(ctx context.Context, state *sync.SyncState) (sync.SyncResult, error) {
+		opts, err := cfg.syncOptions(job, d.opts)
+		if err != nil {
+			return sync.SyncResult{}, err
+		}
+		if job.CommitInterval > 0 {
+			// Several batches of one run may start within the same second
+			batch++
+			ext := filepath.Ext(opts.OutputFile)
+			opts.OutputFile = fmt.Sprintf("%s_b%d%s", strings.TrimSuffix(opts.OutputFile, ext), batch, ext)
+		}
+		fmt.Printf("[daemon] %s: sync started (checkpoint %q)\n", job.Name, state.LastSyncValue)
+		start := time.Now()
+		result, err := syncOnce(ctx, d.db, opts, state)
+		d.metrics.observe(job.Name, result, time.Since(start), err)
+		if err != nil {
+			fmt.Printf("[daemon] %s: sync failed: %v\n", job.Name, err)
+			return result, err
+		}
+		fmt.Printf("[daemon] %s: %d record(s) in %s\n", job.Name, result.Records, time.Since(start).Round(time.Millisecond))
+		return result, nil
+	}
+}
+
+// activate starts scheduler and makes it the active one.
+func (d *daemon) activate(ctx context.Context, cfg *DaemonConfig, scheduler *sync.Scheduler) {
+	scheduler.Start(ctx)
+	d.mu.Lock()
+	d.cfg, d.scheduler, d.loadedAt = cfg, scheduler, time.Now()
+	d.mu.Unlock()
+}
+
+// reload re-reads the sync config. An invalid config keeps the running one.
+func (d *daemon) reload(ctx context.Context) {
+	fmt.Printf("[daemon] SIGHUP: reloading %s\n", d.opts.ConfigFile)
+	cfg, err := LoadDaemonConfig(d.opts.ConfigFile)
+	if err == nil {
+		err = cfg.requireTarget(d.opts)
+	}
+	if err != nil {
+		d.metrics.reloads.WithLabelValues("failure").Inc()
+		fmt.Printf("⚠ [daemon] reload failed, keeping the current config: %v\n", err)
+		return
+	}
+
+	d.mu.Lock()
+	oldCfg, oldScheduler := d.cfg, d.scheduler
+	d.mu.Unlock()
+	if cfg.Listen != oldCfg.Listen {
+		fmt.Printf("⚠ [daemon] listen address change (%s → %s) takes effect after restart\n", oldCfg.Listen, cfg.Listen)
+		cfg.Listen = oldCfg.Listen
+	}
+
+	// Running syncs finish first: the new jobs read their checkpoint files
+	// when registered
+	d.stop()
+	scheduler, err := d.newScheduler(cfg)
+	if err != nil {
+		d.metrics.reloads.WithLabelValues("failure").Inc()
+		fmt.Printf("⚠ [daemon] reload failed, keeping the current config: %v\n", err)
+		d.activate(ctx, oldCfg, oldScheduler)
+		return
+	}
+	d.activate(ctx, cfg, scheduler)
+	d.metrics.reloads.WithLabelValues("success").Inc()
+	fmt.Printf("[daemon] reloaded: %d sync job(s)\n", len(cfg.Jobs))
+}
+
+// stop stops the active scheduler, waiting for running syncs.
+func (d *daemon) stop() {
+	d.mu.Lock()
+	scheduler := d.scheduler
+	d.mu.Unlock()
+	if scheduler == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), daemonShutdownTimeout)
+	defer cancel()
+	if err := scheduler.Stop(ctx); err != nil {
+		fmt.Printf("⚠ [daemon] running syncs cancelled after %s\n", daemonShutdownTimeout)
+	}
+}
+
+// daemonJobStatus is one job in the /healthz response.
+type daemonJobStatus struct {
+	Name          string    `json:"name"`
+	Table         string    `json:"table"`
+	Schedule      string    `json:"schedule"`
+	NextRun       time.Time `json:"next_run,omitempty"`
+	LastSync      time.Time `json:"last_sync,omitempty"`
+	LastSyncValue string    `json:"last_sync_value,omitempty"`
+	Records       int64     `json:"records,omitempty"`
+	LastError     string    `json:"last_error,omitempty"`
+}
+
+// handler serves /healthz (job status, always 200 while the daemon runs:
+// a failing source must not restart it) and /metrics.
+func (d *daemon) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
+		d.mu.Lock()
+		cfg, scheduler, loadedAt := d.cfg, d.scheduler, d.loadedAt
+		d.mu.Unlock()
+
+		jobs := make([]daemonJobStatus, 0, len(cfg.Jobs))
+		status := "ok"
+		for _, job := range cfg.Jobs {
+			js := daemonJobStatus{Name: job.Name, Table: job.Table, Schedule: job.Schedule}
+			js.NextRun, _ = scheduler.NextRun(job.Name)
+			if state, ok := scheduler.State(job.Name); ok {
+				js.LastSync, js.LastSyncValue, js.Records, js.LastError =
+					state.LastSyncTime, state.LastSyncValue, state.RecordsExported, state.LastError
+			}
+			if js.LastError != "" {
+				status = "degraded"
+			}
+			jobs = append(jobs, js)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{ //nolint:errcheck // client gone
+			"status":    status,
+			"config":    d.opts.ConfigFile,
+			"loaded_at": loadedAt,
+			"jobs":      jobs,
+		})
+	})
+	mux.Handle("/metrics", promhttp.HandlerFor(d.metrics.registry, promhttp.HandlerOpts{}))
+	return mux
+}
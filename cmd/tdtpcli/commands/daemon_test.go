@@ -0,0 +1,117 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeSyncConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "sync.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// TestLoadDaemonConfig_Defaults checks the defaults filled in for a minimal
+// sync config.
+func TestLoadDaemonConfig_Defaults(t *testing.T) {
+	cfg, err := LoadDaemonConfig(writeSyncConfig(t, `
+jobs:
+  - table: orders
+    schedule: "*/15 * * * *"
+    jitter: 30s
+  - name: customers-ct
+    table: customers
+    schedule: "@hourly"
+    change_tracking: true
+`))
+	if err != nil {
+		t.Fatalf("LoadDaemonConfig: %v", err)
+	}
+	if cfg.Listen != DefaultDaemonListen || cfg.StateDir != "sync_state" {
+		t.Errorf("defaults: listen %q, state_dir %q", cfg.Listen, cfg.StateDir)
+	}
+	orders := cfg.Jobs[0]
+	if orders.Name != "orders" || orders.TrackingField != "updated_at" || orders.Jitter != 30*time.Second {
+		t.Errorf("orders job: %+v", orders)
+	}
+	if ct := cfg.Jobs[1]; ct.TrackingField != "" {
+		t.Errorf("change tracking job got tracking field %q", ct.TrackingField)
+	}
+	if got := cfg.stateFile(orders); got != filepath.Join("sync_state", "orders.json") {
+		t.Errorf("state file: %s", got)
+	}
+}
+
+// TestLoadDaemonConfig_Tuning checks that per-job batch settings override
+// the defaults block field by field.
+func TestLoadDaemonConfig_Tuning(t *testing.T) {
+	cfg, err := LoadDaemonConfig(writeSyncConfig(t, `
+defaults:
+  batch_size: 10000
+  commit_interval: 5m
+  parallelism: 2
+jobs:
+  - table: history
+    schedule: "@daily"
+  - table: dashboard
+    schedule: "@every 30s"
+    batch_size: 200
+    commit_interval: 1s
+  - table: audit_log
+    schedule: "@hourly"
+    parallelism: 8
+`))
+	if err != nil {
+		t.Fatalf("LoadDaemonConfig: %v", err)
+	}
+	want := []JobTuning{
+		{BatchSize: 10000, CommitInterval: 5 * time.Minute, Parallelism: 2},
+		{BatchSize: 200, CommitInterval: time.Second, Parallelism: 2},
+		{BatchSize: 10000, CommitInterval: 5 * time.Minute, Parallelism: 8},
+	}
+	for i, job := range cfg.Jobs {
+		if job.JobTuning != want[i] {
+			t.Errorf("%s: tuning %+v, want %+v", job.Name, job.JobTuning, want[i])
+		}
+	}
+	opts, err := cfg.syncOptions(cfg.Jobs[1], DaemonOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.BatchSize != 200 || opts.Parallelism != 2 {
+		t.Errorf("sync options: batch %d, parallelism %d", opts.BatchSize, opts.Parallelism)
+	}
+}
+
+// TestLoadDaemonConfig_Invalid checks that a bad sync config is rejected as a
+// whole, so SIGHUP keeps the running one.
+func TestLoadDaemonConfig_Invalid(t *testing.T) {
+	tests := []struct {
+		name, config, want string
+	}{
+		{"no jobs", "listen: \":9000\"\n", "no jobs"},
+		{"no table", "jobs:\n  - schedule: \"@hourly\"\n", "table is required"},
+		{"no schedule", "jobs:\n  - table: orders\n", "schedule is required"},
+		{"bad schedule", "jobs:\n  - {table: orders, schedule: \"every minute\"}\n", "invalid schedule"},
+		{"duplicate", "jobs:\n  - {table: orders, schedule: \"@hourly\"}\n  - {table: orders, schedule: \"@daily\"}\n", "duplicate job name"},
+		{"bad deletes", "jobs:\n  - {table: orders, schedule: \"@hourly\", deletes: \"hard\"}\n", "deletes"},
+		{"cdc and ct", "jobs:\n  - {table: orders, schedule: \"@hourly\", cdc_slot: s, change_tracking: true}\n", "mutually exclusive"},
+		{"negative batch", "jobs:\n  - {table: orders, schedule: \"@hourly\", batch_size: -1}\n", "must not be negative"},
+		{"commit without batch", "jobs:\n  - {table: orders, schedule: \"@hourly\", commit_interval: 10s}\n", "commit_interval requires batch_size"},
+		{"bad defaults", "defaults: {parallelism: -2}\njobs:\n  - {table: orders, schedule: \"@hourly\"}\n", "defaults"},
+		{"bad timezone", "timezone: Mars/Olympus\njobs:\n  - {table: orders, schedule: \"@hourly\"}\n", "timezone"},
+		{"bad schema drift", "jobs:\n  - {table: orders, schedule: \"@hourly\", schema_drift: drop}\n", "schema_drift"},
+	}
+	for _, tt := range tests {
+		_, err := LoadDaemonConfig(writeSyncConfig(t, tt.config))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: got %v, want error containing %q", tt.name, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package commands
+
+// decrypt_archive.go — offline decryption of archived v1.5 packets with the
+// escrow private key (--decrypt-archive).
+//
+// xZMercury burns a packet key on first retrieval and expires it by TTL, so
+// an archived .tdtp.xml cannot be opened through Mercury months later. When
+// the producer ran with TDTP_ESCROW_PUBLIC_KEY, every packet also carries its
+// key wrapped to the escrow public key (Header.KeyEscrow). A security officer
+// holding the escrow private key recovers the key locally — no Mercury, no
+// network — and gets a plain packet back:
+//
+//	tdtpcli --decrypt-archive archive/2025-03/ --escrow-private-key escrow.pem --output restored/
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+	tdtpcrypto "github.com/ruslano69/tdtp-framework/pkg/crypto"
+)
+
+// DecryptArchiveOptions holds options for --decrypt-archive
+type DecryptArchiveOptions struct {
+	Input         string // packet file or directory of packets
+	Output        string // output file (file input) or directory (directory input)
+	EscrowKeyFile string // escrow private key, PEM
+}
+
+// DecryptArchive decrypts escrowed v1.5 packets with the escrow private key
+// and writes them as plain, uncompressed TDTP XML. Integrity hashes (xxh3)
+// are verified after decryption. Packets that are not encrypted are written
+// unchanged; --enc13 blobs are rejected — their keys are never escrowed.
+func DecryptArchive(ctx context.Context, opts DecryptArchiveOptions) error {
+	if opts.EscrowKeyFile == "" {
+		return UsageErrorf("--decrypt-archive requires --escrow-private-key <file.pem>")
+	}
+	if opts.Output == "" {
+		return UsageErrorf("--decrypt-archive requires --output (file, or directory for a directory input)")
+	}
+	escrow, err := tdtpcrypto.LoadEscrowPrivateKey(opts.EscrowKeyFile)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Escrow key: %s\n", escrow.ID)
+
+	info, err := os.Stat(opts.Input)
+	if err != nil {
+		return fmt.Errorf("decrypt archive: %w", err)
+	}
+	if !info.IsDir() {
+		return decryptArchiveFile(ctx, escrow, opts.Input, opts.Output)
+	}
+
+	entries, err := os.ReadDir(opts.Input)
+	if err != nil {
+		return fmt.Errorf("decrypt archive: %w", err)
+	}
+	var files []string
+	for _, e := range entries {
+		if !e.IsDir() {
+			files = append(files, e.Name())
+		}
+	}
+	sort.Strings(files)
+
+	var failed int
+	for _, name := range files {
+		if err := decryptArchiveFile(ctx, escrow, filepath.Join(opts.Input, name), filepath.Join(opts.Output, name)); err != nil {
+			fmt.Printf("  ✗ %s: %v\n", name, err)
+			failed++
+		}
+	}
+	fmt.Printf("✓ Decrypted %d of %d file(s) to %s\n", len(files)-failed, len(files), opts.Output)
+	if failed > 0 {
+		return fmt.Errorf("decrypt archive: %d of %d file(s) failed", failed, len(files))
+	}
+	return nil
+}
+
+// decryptArchiveFile decrypts one packet file into outPath
+func decryptArchiveFile(ctx context.Context, escrow *tdtpcrypto.EscrowPrivateKey, inPath, outPath string) error {
+	data, err := os.ReadFile(inPath)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", inPath, err)
+	}
+	if IsEncryptedBlob(data) {
+		return fmt.Errorf("%s is a v1.3 (--enc13) blob: its key is not escrowed", inPath)
+	}
+	pkt, err := packet.NewParser().ParseBytes(data)
+	if err != nil {
+		return fmt.Errorf("parse %s: %w", inPath, err)
+	}
+
+	if IsEncryptedPacket(pkt) {
+		key, err := packet.RecoverPacketKey(pkt, escrow)
+		if err != nil {
+			return err
+		}
+		if err := packet.DecryptSections(pkt, key); err != nil {
+			return fmt.Errorf("packet %s: %w", pkt.Header.MessageID, err)
+		}
+		if err := packet.DecompressPacketData(ctx, pkt); err != nil {
+			return fmt.Errorf("packet %s: %w", pkt.Header.MessageID, err)
+		}
+		if err := packet.VerifyIntegrity(pkt); err != nil {
+			return fmt.Errorf("packet %s: %w", pkt.Header.MessageID, err)
+		}
+		pkt.Header.KeyEscrow = nil
+		fmt.Printf("✓ %s: %s, %d row(s) (uuid=%s)\n", filepath.Base(inPath), pkt.Header.TableName, len(pkt.Data.Rows), pkt.Header.MessageID)
+	} else {
+		fmt.Printf("  %s: not encrypted, written unchanged\n", filepath.Base(inPath))
+	}
+	return writePacketToFile(pkt, outPath)
+}
@@ -0,0 +1,123 @@
+package commands
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+// writeEscrowPair writes an escrow key pair into dir and returns the paths.
+func writeEscrowPair(t *testing.T, dir string) (pubPath, privPath string) {
+	t.Helper()
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubDER, err := x509.MarshalPKIXPublicKey(&priv.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubPath = filepath.Join(dir, "escrow.pub.pem")
+	privPath = filepath.Join(dir, "escrow.pem")
+	if err := os.WriteFile(pubPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(privPath, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(priv)}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return pubPath, privPath
+}
+
+// TestDecryptArchive_AfterBurn — the archived packet opens with the escrow
+// private key after Mercury has already burned its key.
+func TestDecryptArchive_AfterBurn(t *testing.T) {
+	dir := t.TempDir()
+	pubPath, privPath := writeEscrowPair(t, dir)
+	t.Setenv("MERCURY_SERVER_SECRET", "dev-mode")
+	t.Setenv(escrowKeyEnv, pubPath)
+	srv := newMercuryEncMock(t)
+	defer srv.Close()
+
+	ctx := context.Background()
+	xmlData, uuid, err := EncryptPacketV15(ctx, makeV15TestPacket(t), srv.URL, "archive")
+	if err != nil {
+		t.Fatalf("EncryptPacketV15: %v", err)
+	}
+	archived := filepath.Join(dir, "archive", "v15_table.tdtp.xml")
+	if err := os.MkdirAll(filepath.Dir(archived), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(archived, xmlData, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// Normal consumer burns the key
+	parsed, err := packet.NewParser().ParseBytes(xmlData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := DecryptPacketV15(ctx, parsed, srv.URL); err != nil {
+		t.Fatalf("DecryptPacketV15: %v", err)
+	}
+
+	out := filepath.Join(dir, "restored")
+	if err := DecryptArchive(ctx, DecryptArchiveOptions{
+		Input:         filepath.Dir(archived),
+		Output:        out,
+		EscrowKeyFile: privPath,
+	}); err != nil {
+		t.Fatalf("DecryptArchive: %v", err)
+	}
+
+	restored, err := packet.NewParser().ParseFile(filepath.Join(out, "v15_table.tdtp.xml"))
+	if err != nil {
+		t.Fatalf("parse restored packet: %v", err)
+	}
+	if packet.IsEncrypted(restored) || restored.Header.KeyEscrow != nil {
+		t.Error("restored packet is still encrypted or carries the escrowed key")
+	}
+	if restored.Header.MessageID != uuid {
+		t.Errorf("MessageID = %q, want %q", restored.Header.MessageID, uuid)
+	}
+	rows := restored.GetRows()
+	if len(rows) != 2 || rows[0][1] != "Alice" {
+		t.Errorf("restored rows = %v", rows)
+	}
+}
+
+func TestDecryptArchive_RequiresEscrowedKey(t *testing.T) {
+	dir := t.TempDir()
+	_, privPath := writeEscrowPair(t, dir)
+	t.Setenv("MERCURY_SERVER_SECRET", "dev-mode")
+	srv := newMercuryEncMock(t)
+	defer srv.Close()
+
+	// Exported without TDTP_ESCROW_PUBLIC_KEY
+	xmlData, _, err := EncryptPacketV15(context.Background(), makeV15TestPacket(t), srv.URL, "archive")
+	if err != nil {
+		t.Fatal(err)
+	}
+	in := filepath.Join(dir, "plain-escrow.tdtp.xml")
+	if err := os.WriteFile(in, xmlData, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	err = DecryptArchive(context.Background(), DecryptArchiveOptions{Input: in, Output: filepath.Join(dir, "out.xml"), EscrowKeyFile: privPath})
+	if err == nil {
+		t.Fatal("expected error for a packet without an escrowed key")
+	}
+}
+
+func TestEncryptPacket_EscrowRequiresV15(t *testing.T) {
+	pubPath, _ := writeEscrowPair(t, t.TempDir())
+	t.Setenv(escrowKeyEnv, pubPath)
+	if _, _, err := EncryptPacket(context.Background(), makeV15TestPacket(t), "http://127.0.0.1:1", "archive"); err == nil {
+		t.Fatal("--enc13 with key escrow: expected error")
+	}
+}
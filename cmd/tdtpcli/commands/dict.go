@@ -0,0 +1,169 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+	"github.com/ruslano69/tdtp-framework/pkg/processors"
+)
+
+// embedCompressionDict — встраивать словарь zstd в каждый сжатый пакет (--embed-dict).
+var embedCompressionDict bool
+
+// LoadCompressionDicts загружает каталог словарей <table>.zdict (--dict-dir):
+// пакеты таблиц, для которых есть словарь, сжимаются с ним при --compress
+// (zstd), а входящие пакеты со словарём распаковываются по его ID.
+func LoadCompressionDicts(dir string, embed bool) error {
+	embedCompressionDict = embed
+	if dir == "" {
+		return nil
+	}
+	tables, err := processors.LoadZstdDictDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to load compression dictionaries: %w", err)
+	}
+	if len(tables) > 0 {
+		fmt.Printf("Compression dictionaries: %s\n", strings.Join(tables, ", "))
+	}
+	return nil
+}
+
+// TrainDictOptions holds options for the --train-dict command.
+type TrainDictOptions struct {
+	OutputFile string // <table>.zdict; пустой — <dir>/<table>.zdict рядом с первым образцом
+	Inputs     string // образцы: пути или glob-шаблоны через запятую
+	Table      string // таблица (если образцы содержат пакеты разных таблиц)
+	MaxSizeKB  int    // максимальный размер словаря (0 = 64 KB)
+}
+
+// TrainDict обучает словарь zstd на образцах пакетов одной таблицы и
+// сохраняет его в .zdict. Имя файла без расширения — имя таблицы, для
+// которой --dict-dir применит словарь при экспорте.
+func TrainDict(opts TrainDictOptions) error {
+	files, err := expandInputs(opts.Inputs)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no sample packets: specify --input (files or glob, comma-separated)")
+	}
+
+	parser := packet.NewParser()
+	var (
+		rows    []string
+		packets int
+		tables  = make(map[string]int)
+	)
+	for _, file := range files {
+		pkt, err := parser.ParseFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", file, err)
+		}
+		if pkt.Data.Encryption != "" {
+			return fmt.Errorf("%s: encrypted packets cannot be used as dictionary samples", file)
+		}
+		table := pkt.Header.TableName
+		if opts.Table != "" && !strings.EqualFold(table, opts.Table) {
+			continue
+		}
+		if err := decompressPacketData(pkt); err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		tables[table]++
+		packets++
+		for _, r := range pkt.Data.Rows {
+			rows = append(rows, r.Value)
+		}
+	}
+	if len(tables) > 1 {
+		names := make([]string, 0, len(tables))
+		for name := range tables {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		return fmt.Errorf("samples contain packets of several tables (%s): a dictionary is trained per table, use --table", strings.Join(names, ", "))
+	}
+	if packets == 0 {
+		return fmt.Errorf("no sample packets for table %q", opts.Table)
+	}
+	table := opts.Table
+	for name := range tables {
+		table = name
+	}
+
+	d, err := processors.TrainZstdDict(rows, opts.MaxSizeKB*1024)
+	if err != nil {
+		return err
+	}
+
+	out := opts.OutputFile
+	if out == "" {
+		out = filepath.Join(filepath.Dir(files[0]), table+processors.ZstdDictExt)
+	}
+	if err := os.WriteFile(out, d.Data, 0o644); err != nil {
+		return fmt.Errorf("failed to write dictionary: %w", err)
+	}
+
+	fmt.Printf("✓ Dictionary for '%s' trained on %d packet(s), %d row(s)\n", table, packets, len(rows))
+	fmt.Printf("  ID:   %d\n", d.ID)
+	fmt.Printf("  Size: %d bytes\n", len(d.Data))
+	fmt.Printf("  File: %s\n", out)
+	if plain, withDict, err := dictSavings(rows, d); err == nil {
+		fmt.Printf("  Samples compressed: %d bytes without dictionary → %d bytes with dictionary\n", plain, withDict)
+	}
+	if base := strings.TrimSuffix(filepath.Base(out), processors.ZstdDictExt); !strings.EqualFold(base, table) {
+		fmt.Printf("  ⚠ --dict-dir selects dictionaries by file name: rename to %s%s to use it for '%s'\n",
+			table, processors.ZstdDictExt, table)
+	}
+	return nil
+}
+
+// expandInputs разворачивает список путей/glob-шаблонов через запятую.
+func expandInputs(inputs string) ([]string, error) {
+	var files []string
+	for _, pattern := range splitNonEmpty(inputs) {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid input pattern %q: %w", pattern, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no files match %q", pattern)
+		}
+		files = append(files, matches...)
+	}
+	return files, nil
+}
+
+func splitNonEmpty(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// dictSavings сравнивает суммарный размер образцов, сжатых порциями
+// по 64 строки, без словаря и со словарём.
+func dictSavings(rows []string, d *processors.ZstdDict) (plain, withDict int, err error) {
+	const chunk = 64
+	for start := 0; start < len(rows); start += chunk {
+		part := rows[start:min(start+chunk, len(rows))]
+		a, _, err := processors.CompressDataForTdtp(part, 3)
+		if err != nil {
+			return 0, 0, err
+		}
+		b, _, err := processors.CompressDataForTdtpDict(part, 3, d)
+		if err != nil {
+			return 0, 0, err
+		}
+		plain += len(a)
+		withDict += len(b)
+	}
+	return plain, withDict, nil
+}
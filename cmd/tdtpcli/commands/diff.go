@@ -16,6 +16,7 @@ type DiffOptions struct {
 	IgnoreFields  []string // Игнорировать поля
 	CaseSensitive bool     // Учитывать регистр
 	OutputFormat  string   // Формат вывода: text, json
+	DeltaOutput   string   // Сохранить изменённые строки как delta-пакет (опционально)
 }
 
 // DiffFiles сравнивает два TDTP файла
@@ -59,6 +60,12 @@ func DiffFiles(ctx context.Context, options *DiffOptions) error {
 		fmt.Print(output)
 	}
 
+	if options.DeltaOutput != "" {
+		if err := writeDeltaPacket(result, packetB.Header.TableName, options.DeltaOutput); err != nil {
+			return err
+		}
+	}
+
 	// Возвращаем exit code в зависимости от результата
 	if result.IsEqual() {
 		fmt.Println("\n✓ Files are identical")
@@ -70,6 +77,23 @@ func DiffFiles(ctx context.Context, options *DiffOptions) error {
 	}
 }
 
+// writeDeltaPacket сохраняет изменённые строки diff как delta-пакет:
+// ключ и изменённые колонки, применяемые при импорте точечными UPDATE.
+func writeDeltaPacket(result *diff.DiffResult, tableName, file string) error {
+	pkt, err := result.ToDeltaPacket(tableName)
+	if err != nil {
+		return fmt.Errorf("failed to build delta packet: %w", err)
+	}
+	if err := packet.NewGenerator().WriteToFile(pkt, file); err != nil {
+		return fmt.Errorf("failed to write delta packet: %w", err)
+	}
+	fmt.Printf("\n✓ Delta packet saved to: %s (%d modified row(s))\n", file, len(pkt.Data.Rows))
+	if n := len(result.Added) + len(result.Removed); n > 0 {
+		fmt.Printf("  ⚠ %d added/removed row(s) are not included: delta packets carry updates only\n", n)
+	}
+	return nil
+}
+
 // PrintDiffHelp выводит справку по команде diff
 func PrintDiffHelp() {
 	fmt.Print(`Usage: tdtpcli --diff <file-a> <file-b> [options]
@@ -81,6 +105,7 @@ Options:
   --ignore-fields <field1,field2> Fields to ignore during comparison
   --case-sensitive                Enable case-sensitive comparison (default: false)
   --output-format <text|json>     Output format (default: text)
+  --delta-output <file>           Save modified rows as a delta packet (key + changed columns)
 
 Examples:
   # Compare two files
@@ -92,6 +117,10 @@ Examples:
   # Ignore timestamp fields
   tdtpcli --diff data-old.xml data-new.xml --ignore-fields created_at,updated_at
 
+  # Ship only changed columns: import applies them as UPDATEs by key
+  tdtpcli --diff data-old.xml data-new.xml --delta-output changes.xml
+  tdtpcli --import changes.xml
+
 Exit codes:
   0 - Files are identical or comparison successful
   1 - Error occurred
@@ -20,6 +20,12 @@ package commands
 // Server secret (HMAC verification):
 //   The producer reads MERCURY_SERVER_SECRET from the environment.
 //   Empty env var → HMAC verification is skipped (dev / internal-only setups).
+//
+// Key escrow (archives):
+//   TDTP_ESCROW_PUBLIC_KEY=<escrow.pub.pem> makes the v1.5 producer also wrap
+//   each packet key to the offline escrow key (Header.KeyEscrow), so archived
+//   packets stay readable after Mercury burns the key: --decrypt-archive.
+//   The --enc13 blob has no plain header for it — escrow requires --enc.
 
 import (
 	"context"
@@ -155,7 +161,10 @@ func WriteErrorPacket(code, message, table, inReplyTo, serverMode, path string)
 // serverSecret is read from MERCURY_SERVER_SECRET env var when empty.
 func EncryptPacket(ctx context.Context, pkt *packet.DataPacket, mercuryURL, pipelineName string) (blob []byte, packageUUID string, err error) {
 	if mercuryURL == "" {
-		return nil, "", fmt.Errorf("--enc requires --mercury-url pointing at a running xZMercury instance")
+		return nil, "", UsageErrorf("--enc requires --mercury-url pointing at a running xZMercury instance")
+	}
+	if os.Getenv(escrowKeyEnv) != "" {
+		return nil, "", fmt.Errorf("%s is set, but key escrow requires v1.5 encryption (--enc): the --enc13 blob has no header to carry the escrowed key", escrowKeyEnv)
 	}
 
 	// Serialize to TDTP XML first.
@@ -201,7 +210,7 @@ func EncryptPacket(ctx context.Context, pkt *packet.DataPacket, mercuryURL, pipe
 // (hash -> compress -> encrypt) and is the caller's responsibility.
 func EncryptPacketV15(ctx context.Context, pkt *packet.DataPacket, mercuryURL, pipelineName string) (xmlData []byte, packageUUID string, err error) {
 	if mercuryURL == "" {
-		return nil, "", fmt.Errorf("--enc requires --mercury-url pointing at a running xZMercury instance")
+		return nil, "", UsageErrorf("--enc requires --mercury-url pointing at a running xZMercury instance")
 	}
 
 	packageUUID = pkt.Header.MessageID
@@ -209,6 +218,11 @@ func EncryptPacketV15(ctx context.Context, pkt *packet.DataPacket, mercuryURL, p
 		return nil, "", fmt.Errorf("encrypt v1.5: packet Header.MessageID is empty — cannot bind a key without it")
 	}
 
+	escrow, err := loadEscrowKey()
+	if err != nil {
+		return nil, "", err
+	}
+
 	key, err := bindAndVerifyKey(ctx, mercuryURL, packageUUID, pipelineName)
 	if err != nil {
 		return nil, "", err
@@ -217,6 +231,11 @@ func EncryptPacketV15(ctx context.Context, pkt *packet.DataPacket, mercuryURL, p
 	if err := packet.EncryptSections(pkt, key); err != nil {
 		return nil, "", fmt.Errorf("encrypt sections: %w", err)
 	}
+	if escrow != nil {
+		if err := packet.EscrowPacketKey(pkt, key, escrow); err != nil {
+			return nil, "", err
+		}
+	}
 
 	gen := packet.NewGenerator()
 	xmlData, err = gen.ToXML(pkt, true)
@@ -227,6 +246,23 @@ func EncryptPacketV15(ctx context.Context, pkt *packet.DataPacket, mercuryURL, p
 	return xmlData, packageUUID, nil
 }
 
+// escrowKeyEnv names the escrow public key (PEM) used by the v1.5 producer.
+const escrowKeyEnv = "TDTP_ESCROW_PUBLIC_KEY"
+
+// loadEscrowKey loads the escrow public key from TDTP_ESCROW_PUBLIC_KEY;
+// nil when key escrow is not enabled.
+func loadEscrowKey() (*tdtpcrypto.EscrowKey, error) {
+	path := os.Getenv(escrowKeyEnv)
+	if path == "" {
+		return nil, nil
+	}
+	escrow, err := tdtpcrypto.LoadEscrowKey(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", escrowKeyEnv, err)
+	}
+	return escrow, nil
+}
+
 // bindAndVerifyKey calls xZMercury BindKey and verifies the HMAC exactly
 // like processors.FileEncryptor.Encrypt does for the legacy path — shared
 // here so v1.5 gets the identical ACL/quota/HMAC guarantees without
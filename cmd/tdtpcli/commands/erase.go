@@ -0,0 +1,113 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+	"github.com/ruslano69/tdtp-framework/pkg/sync"
+)
+
+// EraseTarget — копия таблицы, из которой стираются данные субъекта.
+type EraseTarget struct {
+	Name   string
+	Config adapters.Config
+}
+
+// EraseOptions holds options for the --erase command.
+type EraseOptions struct {
+	TableName   string
+	Keys        [][]string    // значения ключа (каждый элемент — один ключ)
+	Query       *packet.Query // либо TDTQL-фильтр по источнику (--where)
+	SubjectRef  string
+	Reason      string
+	Targets     []EraseTarget // источник (--config) идёт первым
+	PacketFile  string        // сохранить пакет удаления для брокера/пайплайнов
+	ReceiptFile string        // JSON-квитанция (пусто — erasure_<table>_<time>.json)
+}
+
+// Erase стирает данные субъекта из таблицы источника и всех приёмников:
+// строит пакет удаления (ключи — явно или по фильтру в источнике),
+// применяет его к каждой копии, проверяет отсутствие строк и сохраняет
+// квитанцию. Ошибка возвращается, если хотя бы одна копия не подтверждена;
+// квитанция при этом всё равно сохраняется.
+func Erase(ctx context.Context, opts EraseOptions) (*sync.ErasureReceipt, error) {
+	if len(opts.Targets) == 0 {
+		return nil, fmt.Errorf("no erasure targets")
+	}
+	// Ключи ищутся в источнике — первой цели.
+	var source sync.ErasureSource
+	conns := make([]sync.NamedErasureTarget, 0, len(opts.Targets))
+	for _, t := range opts.Targets {
+		adapter, err := adapters.New(ctx, t.Config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to %s: %w", t.Name, err)
+		}
+		defer func() { _ = adapter.Close(ctx) }()
+		if source == nil {
+			source = adapter
+		}
+
+		target, ok := adapter.(sync.ErasureTarget)
+		if !ok {
+			return nil, fmt.Errorf("%s: adapter %s does not support delete packets", t.Name, t.Config.Type)
+		}
+		conns = append(conns, sync.NamedErasureTarget{Name: t.Name, Target: target})
+	}
+
+	req := sync.ErasureRequest{
+		Table:      opts.TableName,
+		Keys:       opts.Keys,
+		Query:      opts.Query,
+		SubjectRef: opts.SubjectRef,
+		Reason:     opts.Reason,
+	}
+	pkt, err := sync.BuildErasurePacket(ctx, source, req)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Printf("🗑  Erasing %d key(s) from '%s' in %d target(s)...\n", pkt.Header.RecordsInPart, opts.TableName, len(conns))
+
+	if opts.PacketFile != "" {
+		if err := packet.NewGenerator().WriteToFile(pkt, opts.PacketFile); err != nil {
+			return nil, fmt.Errorf("failed to write delete packet: %w", err)
+		}
+		fmt.Printf("  → %s (delete packet)\n", opts.PacketFile)
+	}
+
+	receipt, err := sync.Erase(ctx, pkt, conns, req)
+	if err != nil {
+		return nil, err
+	}
+
+	deleted := 0
+	for _, tr := range receipt.Targets {
+		deleted += tr.RowsDeleted
+		switch {
+		case tr.Error != "":
+			fmt.Printf("  ❌ %-20s %s\n", tr.Name, tr.Error)
+		case !tr.Verified:
+			fmt.Printf("  ⚠ %-20s %d row(s) deleted, %d still present\n", tr.Name, tr.RowsDeleted, tr.Remaining)
+		default:
+			fmt.Printf("  ✅ %-20s %d row(s) deleted, verified\n", tr.Name, tr.RowsDeleted)
+		}
+	}
+	recordOpMetrics(ctx, opts.TableName, int64(deleted))
+
+	receiptFile := opts.ReceiptFile
+	if receiptFile == "" {
+		receiptFile = fmt.Sprintf("erasure_%s_%s.json", opts.TableName, receipt.StartedAt.Format("20060102T150405Z"))
+	}
+	if err := receipt.WriteFile(receiptFile); err != nil {
+		return receipt, err
+	}
+	fmt.Printf("📄 Receipt: %s (keys digest %s)\n", receiptFile, receipt.KeysDigest[:16])
+
+	if !receipt.Complete {
+		return receipt, fmt.Errorf("erasure not confirmed on all targets (see %s)", receiptFile)
+	}
+	fmt.Printf("✅ Subject erased from all %d target(s) in %v\n", len(receipt.Targets), receipt.CompletedAt.Sub(receipt.StartedAt).Round(time.Millisecond))
+	return receipt, nil
+}
@@ -0,0 +1,211 @@
+package commands
+
+// exitcode.go — error taxonomy and process exit codes of tdtpcli.
+//
+// Every failure is classified into one ErrorClass, and each class has a fixed,
+// documented exit code, so wrappers and CI can tell "table not found" from
+// "connection refused" from "partial import" without parsing messages.
+// With --error-format json the error is also printed to stderr as a single
+// JSON object (ErrorReport).
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"os"
+	"syscall"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+	"github.com/ruslano69/tdtp-framework/pkg/approval"
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+	tdtpcrypto "github.com/ruslano69/tdtp-framework/pkg/crypto"
+	"github.com/ruslano69/tdtp-framework/pkg/etl"
+	"github.com/ruslano69/tdtp-framework/pkg/operations"
+	"github.com/ruslano69/tdtp-framework/pkg/quota"
+	"github.com/ruslano69/tdtp-framework/pkg/resilience"
+	"github.com/ruslano69/tdtp-framework/pkg/security"
+	"github.com/ruslano69/tdtp-framework/pkg/sync"
+)
+
+// ErrorClass is the failure class of a tdtpcli run.
+type ErrorClass string
+
+const (
+	ClassError      ErrorClass = "error"      // unclassified failure
+	ClassUsage      ErrorClass = "usage"      // invalid flags or flag combination
+	ClassConfig     ErrorClass = "config"     // config file missing or invalid, key not resolved
+	ClassLicense    ErrorClass = "license"    // invalid license, unlicensed feature or adapter
+	ClassConnection ErrorClass = "connection" // database or broker unreachable
+	ClassNotFound   ErrorClass = "not_found"  // table, file, object or operation does not exist
+	ClassData       ErrorClass = "data"       // packet does not fit the table: columns, keys, checksums, drift
+	ClassPartial    ErrorClass = "partial"    // import finished but rejected rows
+	ClassDenied     ErrorClass = "denied"     // refused by approval, policy, quota or maintenance window
+	ClassLocked     ErrorClass = "locked"     // table or job is busy with another run
+	ClassTimeout    ErrorClass = "timeout"    // deadline or approval timeout
+	ClassCanceled   ErrorClass = "canceled"   // run was cancelled (--cancel, signal)
+)
+
+// exitCodes maps error classes to process exit codes. The codes are part of
+// the CLI contract (docs/USER_GUIDE.md) — do not renumber.
+var exitCodes = map[ErrorClass]int{
+	ClassError:      1,
+	ClassUsage:      2,
+	ClassConfig:     3,
+	ClassLicense:    4,
+	ClassConnection: 5,
+	ClassNotFound:   6,
+	ClassData:       7,
+	ClassPartial:    8,
+	ClassDenied:     9,
+	ClassLocked:     10,
+	ClassTimeout:    11,
+	ClassCanceled:   130,
+}
+
+// ExitCode returns the process exit code of the class.
+func (c ErrorClass) ExitCode() int {
+	if code, ok := exitCodes[c]; ok {
+		return code
+	}
+	return exitCodes[ClassError]
+}
+
+// ClassifiedError carries an explicit class for errors that have no sentinel
+// of their own (flag validation, config loading, license gates).
+type ClassifiedError struct {
+	Class ErrorClass
+	Err   error
+}
+
+func (e *ClassifiedError) Error() string { return e.Err.Error() }
+func (e *ClassifiedError) Unwrap() error { return e.Err }
+
+// WithClass tags err with class; nil stays nil.
+func WithClass(class ErrorClass, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &ClassifiedError{Class: class, Err: err}
+}
+
+// UsageErrorf returns a ClassUsage error.
+func UsageErrorf(format string, args ...any) error {
+	return WithClass(ClassUsage, fmt.Errorf(format, args...))
+}
+
+// PartialImportError reports an import that committed the valid rows but
+// rejected others under the row error policy (database.row_errors skip,
+// dead-letter or partial_rollback).
+type PartialImportError struct {
+	Table    string
+	Imported int
+	Rejected int
+}
+
+func (e *PartialImportError) Error() string {
+	return fmt.Sprintf("partial import into '%s': %d row(s) imported, %d row(s) rejected",
+		e.Table, e.Imported, e.Rejected)
+}
+
+// Classify returns the class of err. An explicit ClassifiedError wins; then
+// cancellation and timeouts; then the sentinel errors of the packages.
+func Classify(err error) ErrorClass {
+	if err == nil {
+		return ""
+	}
+	var classified *ClassifiedError
+	if errors.As(err, &classified) {
+		return classified.Class
+	}
+	var partial *PartialImportError
+	if errors.As(err, &partial) {
+		return ClassPartial
+	}
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		return ClassCanceled
+	case errors.Is(err, context.DeadlineExceeded),
+		errors.Is(err, os.ErrDeadlineExceeded),
+		errors.Is(err, approval.ErrTimeout):
+		return ClassTimeout
+
+	case errors.Is(err, adapters.ErrTableLocked),
+		errors.Is(err, sync.ErrJobRunning):
+		return ClassLocked
+
+	case errors.Is(err, adapters.ErrOutsideMaintenanceWindow),
+		errors.Is(err, approval.ErrRejected),
+		errors.Is(err, security.ErrPolicyViolation),
+		errors.Is(err, quota.ErrExceeded),
+		errors.Is(err, quota.ErrUnknownRecipient),
+		errors.Is(err, etl.ErrBudgetExceeded):
+		return ClassDenied
+
+	case errors.Is(err, adapters.ErrColumnMismatch),
+		errors.Is(err, adapters.ErrDuplicateKeys),
+		errors.Is(err, packet.ErrChecksumMismatch),
+		errors.Is(err, sync.ErrSchemaDrift):
+		return ClassData
+
+	case errors.Is(err, tdtpcrypto.ErrKeyNotFound):
+		return ClassConfig
+
+	case errors.Is(err, adapters.ErrTableNotFound),
+		errors.Is(err, operations.ErrNotFound),
+		errors.Is(err, fs.ErrNotExist):
+		return ClassNotFound
+
+	case errors.Is(err, adapters.ErrConnect),
+		errors.Is(err, resilience.ErrCircuitOpen),
+		errors.Is(err, syscall.ECONNREFUSED):
+		return ClassConnection
+	}
+
+	var opErr *net.OpError
+	var dnsErr *net.DNSError
+	if errors.As(err, &opErr) || errors.As(err, &dnsErr) {
+		return ClassConnection
+	}
+	return ClassError
+}
+
+// ErrorReport is the --error-format json representation of a failed run.
+type ErrorReport struct {
+	Error    string     `json:"error"`
+	Class    ErrorClass `json:"class"`
+	ExitCode int        `json:"exit_code"`
+
+	// Table, Imported and Rejected are set for ClassPartial.
+	Table    string `json:"table,omitempty"`
+	Imported int    `json:"imported,omitempty"`
+	Rejected int    `json:"rejected,omitempty"`
+}
+
+// NewErrorReport classifies err.
+func NewErrorReport(err error) ErrorReport {
+	class := Classify(err)
+	report := ErrorReport{Error: err.Error(), Class: class, ExitCode: class.ExitCode()}
+	var partial *PartialImportError
+	if errors.As(err, &partial) {
+		report.Table, report.Imported, report.Rejected = partial.Table, partial.Imported, partial.Rejected
+	}
+	return report
+}
+
+// WriteError prints err to w in the given format ("text" or "json") and
+// returns the exit code of its class.
+func WriteError(w io.Writer, format string, err error) int {
+	report := NewErrorReport(err)
+	if format == "json" {
+		data, _ := json.Marshal(report)
+		fmt.Fprintln(w, string(data))
+	} else {
+		fmt.Fprintf(w, "Error: %s\n", report.Error)
+	}
+	return report.ExitCode
+}
@@ -0,0 +1,92 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+	"github.com/ruslano69/tdtp-framework/pkg/approval"
+)
+
+// TestClassify maps wrapped errors to their class and exit code.
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want ErrorClass
+		code int
+	}{
+		{"unclassified", errors.New("boom"), ClassError, 1},
+		{"usage", UsageErrorf("--keep-scratch requires --simulate"), ClassUsage, 2},
+		{"explicit class wins", WithClass(ClassConfig, fmt.Errorf("load: %w", os.ErrNotExist)), ClassConfig, 3},
+		{"license gate", GateFeature("unsafe"), ClassLicense, 4},
+		{"connect", fmt.Errorf("%w to postgres: %w", adapters.ErrConnect, errors.New("auth failed")), ClassConnection, 5},
+		{"connection refused", fmt.Errorf("dial: %w", syscall.ECONNREFUSED), ClassConnection, 5},
+		{"net error", &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("no route")}, ClassConnection, 5},
+		{"table not found", fmt.Errorf("export failed: %w", fmt.Errorf("table orders %w", adapters.ErrTableNotFound)), ClassNotFound, 6},
+		{"file not found", fmt.Errorf("open in.tdtp: %w", os.ErrNotExist), ClassNotFound, 6},
+		{"column mismatch", fmt.Errorf("import failed: %w", adapters.ErrColumnMismatch), ClassData, 7},
+		{"partial import", &PartialImportError{Table: "orders", Imported: 98, Rejected: 2}, ClassPartial, 8},
+		{"approval rejected", fmt.Errorf("orders: %w", approval.ErrRejected), ClassDenied, 9},
+		{"maintenance window", fmt.Errorf("orders: %w", adapters.ErrOutsideMaintenanceWindow), ClassDenied, 9},
+		{"table locked", fmt.Errorf("orders: %w", adapters.ErrTableLocked), ClassLocked, 10},
+		{"approval timeout", fmt.Errorf("orders: %w", approval.ErrTimeout), ClassTimeout, 11},
+		{"deadline", fmt.Errorf("query: %w", context.DeadlineExceeded), ClassTimeout, 11},
+		{"canceled", fmt.Errorf("import failed: %w", context.Canceled), ClassCanceled, 130},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Classify(tt.err)
+			if got != tt.want {
+				t.Errorf("Classify(%v) = %q, want %q", tt.err, got, tt.want)
+			}
+			if code := got.ExitCode(); code != tt.code {
+				t.Errorf("%s exit code = %d, want %d", got, code, tt.code)
+			}
+		})
+	}
+}
+
+// TestWriteError_JSON emits one JSON object with the class and exit code.
+func TestWriteError_JSON(t *testing.T) {
+	var buf bytes.Buffer
+	err := fmt.Errorf("Command failed: %w", &PartialImportError{Table: "orders", Imported: 98, Rejected: 2})
+	code := WriteError(&buf, "json", err)
+	if code != 8 {
+		t.Errorf("exit code = %d, want 8", code)
+	}
+
+	var report ErrorReport
+	if jerr := json.Unmarshal(buf.Bytes(), &report); jerr != nil {
+		t.Fatalf("output is not JSON: %v\n%s", jerr, buf.String())
+	}
+	if report.Class != ClassPartial || report.ExitCode != 8 {
+		t.Errorf("report = %+v, want class partial, exit_code 8", report)
+	}
+	if report.Table != "orders" || report.Imported != 98 || report.Rejected != 2 {
+		t.Errorf("partial details = %+v", report)
+	}
+	if report.Error != err.Error() {
+		t.Errorf("error = %q, want %q", report.Error, err.Error())
+	}
+}
+
+// TestWriteError_Text keeps the classic "Error: ..." line.
+func TestWriteError_Text(t *testing.T) {
+	var buf bytes.Buffer
+	code := WriteError(&buf, "text", fmt.Errorf("table orders %w", adapters.ErrTableNotFound))
+	if code != 6 {
+		t.Errorf("exit code = %d, want 6", code)
+	}
+	if got := buf.String(); !strings.HasPrefix(got, "Error: table orders not found or has no columns") {
+		t.Errorf("text output = %q", got)
+	}
+}
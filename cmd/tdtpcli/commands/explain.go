@@ -0,0 +1,67 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+// explainExport prints the query plan of an export instead of running it
+// (--explain): which filters go to SQL and which run in memory, the
+// strategy and its reason, the planned stages and the source SQL.
+func explainExport(ctx context.Context, adapter adapters.Adapter, table string, query *packet.Query) error {
+	if query == nil {
+		query = packet.NewQuery()
+	}
+	plan, err := adapters.ExplainQuery(ctx, adapter, table, query)
+	if err != nil {
+		return fmt.Errorf("explain failed: %w", err)
+	}
+	fmt.Printf("Query plan for '%s':\n", table)
+	printQueryPlan(plan, "  ")
+	return nil
+}
+
+// printQueryPlan prints an ExecutionPlan: a dry-run plan from --explain or
+// the executed plan embedded in a response packet's QueryContext.
+func printQueryPlan(plan *packet.ExecutionPlan, indent string) {
+	fmt.Printf("%sstrategy: %s\n", indent, plan.Strategy)
+	if plan.Reason != "" {
+		fmt.Printf("%sreason: %s\n", indent, plan.Reason)
+	}
+	fmt.Printf("%sestimated_rows: %s\n", indent, planRows(plan.EstimatedRows))
+	fmt.Printf("%sselectivity: %.4g\n", indent, plan.Selectivity)
+	fmt.Printf("%sestimated_matches: %s\n", indent, planRows(plan.EstimatedMatches))
+	if len(plan.Filters) > 0 {
+		fmt.Printf("%sfilters:\n", indent)
+		for _, f := range plan.Filters {
+			fmt.Printf("%s  - %-6s %s %s %q  # selectivity %.4g\n", indent, f.Where+":", f.Field, f.Operator, f.Value, f.Selectivity)
+		}
+	}
+	if len(plan.Stages) > 0 {
+		fmt.Printf("%sstages:\n", indent)
+		for _, s := range plan.Stages {
+			line := fmt.Sprintf("%s  - %-9s %-6s estimated %-10s", indent, s.Name, s.Where, planRows(s.EstimatedRows))
+			if s.ActualRows >= 0 {
+				line += fmt.Sprintf(" actual %-10d %.1f ms", s.ActualRows, s.ElapsedMs)
+			}
+			fmt.Println(line)
+		}
+		if total := plan.Elapsed(); total > 0 {
+			fmt.Printf("%selapsed_ms: %.1f\n", indent, total)
+		}
+	}
+	if plan.SQL != "" {
+		fmt.Printf("%ssql: %s\n", indent, plan.SQL)
+	}
+}
+
+// planRows formats a plan row count (-1 = unknown).
+func planRows(n int64) string {
+	if n < 0 {
+		return "?"
+	}
+	return fmt.Sprintf("%d", n)
+}
@@ -8,12 +8,12 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
 
 	"github.com/ruslano69/tdtp-framework/pkg/adapters"
-	"github.com/ruslano69/tdtp-framework/pkg/adapters/mssql"
 	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
 	"github.com/ruslano69/tdtp-framework/pkg/mercury"
 	"github.com/ruslano69/tdtp-framework/pkg/processors"
@@ -27,6 +27,9 @@ type ExportOptions struct {
 	Query            *packet.Query
 	Fields           []string // Column projection: nil/empty = all columns
 	ProcessorMgr     ProcessorManager
+	Recipient        string                     // Header.Recipient пакетов (--recipient)
+	KeepProvenance   bool                       // Не исключать колонки _tdtp_* (--keep-provenance)
+	Exclusions       *adapters.ColumnExclusions // Служебные колонки, не выдаваемые при экспорте (export.exclude_columns)
 	Compress         bool
 	CompressLevel    int
 	CompressAlgo     string // Алгоритм сжатия: "zstd" (по умолчанию) или "kanzi"
@@ -34,6 +37,10 @@ type ExportOptions struct {
 	ReadOnlyFields   bool   // Include read-only fields (timestamp, computed, identity)
 	Fast             bool   // Skip SpecialValues detection for maximum export speed
 	FallbackRowLimit int64  // Max rows for in-memory fallback when SQL pushdown fails (0 = unlimited)
+	Explain          bool   // Print the query plan instead of exporting (--explain)
+
+	// Custom read-only SELECT per table instead of SELECT * (export.table_queries)
+	TableQueries map[string]string
 
 	// v1.3.1 compact format
 	Compact     bool     // Enable compact format output
@@ -47,6 +54,10 @@ type ExportOptions struct {
 	MercuryURL    string // Optional: register hash in xzMercury (empty = local integrity only)
 	MercuryCaller string // X-Caller header for Mercury registration (default: "tdtpcli")
 
+	// Header.Checksum — SHA-256 of canonical rows, verified by Parser and
+	// ImportHelper: packet.ChecksumPacket or packet.ChecksumRows ("" = off).
+	RowChecksum string
+
 	// Encryption (--enc / --enc13 tier). Requires MercuryURL.
 	// Key is bound in xZMercury (burn-on-read).
 	//
@@ -65,6 +76,13 @@ type ExportOptions struct {
 	// Object storage (S3/SeaweedFS). Non-nil → stream to object storage instead of local file.
 	StorageCfg *storage.Config // storage driver config with bucket
 	StorageKey string          // object key within the bucket
+
+	// ManifestStats adds source table statistics (adapters.StatisticsReader)
+	// to the S3 export manifest for monitoring.
+	ManifestStats bool
+
+	// BundleKey encrypts a .tdtpz bundle output (--bundle-key-file); nil → plain bundle.
+	BundleKey []byte
 }
 
 // ProcessorManager interface for applying data processors.
@@ -85,6 +103,17 @@ func (p *compactProc) ProcessPacket(_ context.Context, pkt *packet.DataPacket) e
 	return applyCompactToPacket(pkt, p.fixedNames, p.writeTail)
 }
 
+// rowChecksumProc stamps Header.Checksum over the final rows — after the
+// row processors, before compact/compress (the sum covers logical values).
+type rowChecksumProc struct {
+	perRow bool
+}
+
+func (p *rowChecksumProc) Name() string { return "row-checksum" }
+func (p *rowChecksumProc) ProcessPacket(_ context.Context, pkt *packet.DataPacket) error {
+	return packet.StampRowChecksum(pkt, p.perRow)
+}
+
 // compressProc адаптирует compressPacketData в PacketProcessor.
 type compressProc struct {
 	algo     string
@@ -184,6 +213,32 @@ func (p *integrityProc) ProcessPacket(ctx context.Context, pkt *packet.DataPacke
 	return nil
 }
 
+// applyTableQueries passes export.table_queries to the adapter: the table is
+// exported with its custom SELECT instead of SELECT *. Queries are validated
+// as read-only. An adapter without support is an error only when the
+// exported table actually has a custom query.
+func applyTableQueries(adapter adapters.Adapter, tableName string, queries map[string]string) error {
+	if len(queries) == 0 {
+		return nil
+	}
+	type tableQuerySetter interface {
+		SetTableQueries(map[string]string) error
+	}
+	tq, ok := adapter.(tableQuerySetter)
+	if !ok {
+		for table := range queries {
+			if strings.EqualFold(table, tableName) {
+				return fmt.Errorf("table_queries: adapter does not support custom table queries (%s)", tableName)
+			}
+		}
+		return nil
+	}
+	if err := tq.SetTableQueries(queries); err != nil {
+		return fmt.Errorf("table_queries: %w", err)
+	}
+	return nil
+}
+
 // ExportTable exports a table to TDTP XML file
 func ExportTable(ctx context.Context, config *adapters.Config, opts ExportOptions) error {
 	// Create adapter
@@ -197,7 +252,12 @@ func ExportTable(ctx context.Context, config *adapters.Config, opts ExportOption
 
 	// Add includeReadOnly flag to context for MS SQL adapter
 	// (other adapters will ignore it)
-	ctx = mssql.WithIncludeReadOnlyFields(ctx, opts.ReadOnlyFields)
+	ctx = adapters.WithIncludeReadOnlyFields(ctx, opts.ReadOnlyFields)
+	// Provenance columns describe the previous import, not this export: the
+	// adapter drops them unless --keep-provenance (the receiver fills its own)
+	if opts.KeepProvenance {
+		ctx = adapters.WithKeepProvenance(ctx)
+	}
 
 	// --fast: skip SpecialValues detection for maximum throughput
 	if opts.Fast {
@@ -215,6 +275,10 @@ func ExportTable(ctx context.Context, config *adapters.Config, opts ExportOption
 		}
 	}
 
+	if err := applyTableQueries(adapter, opts.TableName, opts.TableQueries); err != nil {
+		return err
+	}
+
 	// If fields projection is requested, ensure we go through ExportTableWithQuery
 	// (even if no other query params are set) so the adapter can build SELECT f1,f2,...
 	if len(opts.Fields) > 0 {
@@ -224,11 +288,21 @@ func ExportTable(ctx context.Context, config *adapters.Config, opts ExportOption
 		opts.Query.Fields = opts.Fields
 	}
 
-	// Export with or without query
+	if err := opts.Exclusions.CheckQuery(opts.TableName, opts.Query); err != nil {
+		return err
+	}
+
+	if opts.Explain {
+		return explainExport(ctx, adapter, opts.TableName, opts.Query)
+	}
+
+	// Export with or without query. The adapter stamps the recipient on the
+	// packets and applies the configured export policy for it.
+	ctx = adapters.WithRecipient(ctx, opts.Recipient)
 	var packets []*packet.DataPacket
 	if opts.Query != nil {
 		fmt.Printf("Applying filters...\n")
-		packets, err = adapter.ExportTableWithQuery(ctx, opts.TableName, opts.Query, "tdtpcli", "")
+		packets, err = adapter.ExportTableWithQuery(ctx, opts.TableName, opts.Query, "tdtpcli", opts.Recipient)
 	} else {
 		packets, err = adapter.ExportTable(ctx, opts.TableName)
 	}
@@ -251,16 +325,31 @@ func ExportTable(ctx context.Context, config *adapters.Config, opts ExportOption
 		totalRows += pkt.Header.RecordsInPart
 	}
 	fmt.Printf("✓ Total rows: %d\n", totalRows)
+	if qc := packets[len(packets)-1].QueryContext; qc != nil && len(qc.ExecutionResults.NextAfter) > 0 {
+		fmt.Printf("  → Next page: --after %s\n", strings.Join(qc.ExecutionResults.NextAfter, ","))
+	}
 	recordOpMetrics(ctx, opts.TableName, int64(totalRows))
 
+	if err := applyColumnExclusions(opts.Exclusions, packets); err != nil {
+		return err
+	}
+
 	// Build packet processing chain.
-	// Порядок: mask/normalize/validate → compact → compress → (encrypt) → (hash)
+	// Порядок: mask/normalize/validate → row-checksum → compact → compress → (encrypt) → (hash)
 	chain := processors.NewPacketChain()
 
 	if opts.ProcessorMgr != nil && opts.ProcessorMgr.HasProcessors() {
 		chain.Add(opts.ProcessorMgr)
 	}
 
+	switch opts.RowChecksum {
+	case packet.ChecksumOff:
+	case packet.ChecksumPacket, packet.ChecksumRows:
+		chain.Add(&rowChecksumProc{perRow: opts.RowChecksum == packet.ChecksumRows})
+	default:
+		return fmt.Errorf("invalid --row-checksum %q (expected packet or rows)", opts.RowChecksum)
+	}
+
 	if opts.Compact {
 		fixedNames := BuildFixedFieldsForExport(packets[0].Schema, opts.FixedFields)
 		if len(fixedNames) == 0 {
@@ -306,20 +395,35 @@ func ExportTable(ctx context.Context, config *adapters.Config, opts ExportOption
 	}
 
 	// Open object storage once outside the loop (if needed).
+	// Uploads go through a manifest recorder: key.manifest.json lists every
+	// object with its SHA-256 for --import --verify-manifest.
 	var store storage.ObjectStorage
+	var manifest *storage.ManifestRecorder
 	if opts.StorageCfg != nil {
 		store, err = storage.New(*opts.StorageCfg)
 		if err != nil {
 			return fmt.Errorf("failed to open storage: %w", err)
 		}
 		defer func() { _ = store.Close() }()
+		manifest = storage.NewManifestRecorder(store)
+		store = manifest
+
+		if opts.ManifestStats {
+			if err := recordSourceStatistics(ctx, adapter, opts.TableName, manifest); err != nil {
+				return err
+			}
+		}
 	}
 
 	total := len(packets)
 
-	// stdout требует строгого порядка → последовательно.
+	// stdout и бандл требуют строгого порядка → последовательно.
 	// Файлы и S3 независимы (разные имена/ключи) → параллельно.
-	if opts.OutputFile == "" || opts.OutputFile == "-" {
+	if store == nil && packet.IsBundlePath(opts.OutputFile) {
+		if err := writeBundle(ctx, packets, chain, opts); err != nil {
+			return err
+		}
+	} else if opts.OutputFile == "" || opts.OutputFile == "-" {
 		for i, pkt := range packets {
 			if err := chain.ProcessPacket(ctx, pkt); err != nil {
 				return err
@@ -335,6 +439,13 @@ func ExportTable(ctx context.Context, config *adapters.Config, opts ExportOption
 		}
 	}
 
+	if manifest != nil {
+		if _, err := manifest.WriteManifest(ctx, opts.StorageKey, opts.TableName); err != nil {
+			return fmt.Errorf("failed to write manifest: %w", err)
+		}
+		fmt.Printf("✓ Manifest: s3://%s/%s\n", opts.StorageCfg.S3.Bucket, storage.ManifestKey(opts.StorageKey))
+	}
+
 	if opts.EnableChecksum {
 		fmt.Printf("✓ Checksums generated (xxh3)\n")
 	}
@@ -356,6 +467,22 @@ func ExportTable(ctx context.Context, config *adapters.Config, opts ExportOption
 	return nil
 }
 
+// recordSourceStatistics attaches the source table statistics to the
+// manifest. An adapter without adapters.StatisticsReader is skipped with a note.
+func recordSourceStatistics(ctx context.Context, adapter adapters.Adapter, tableName string, manifest *storage.ManifestRecorder) error {
+	reader, ok := adapter.(adapters.StatisticsReader)
+	if !ok {
+		fmt.Printf("⚠ --manifest-stats: %s does not expose table statistics, manifest written without them\n", adapter.GetDatabaseType())
+		return nil
+	}
+	stats, err := reader.TableStatistics(ctx, tableName)
+	if err != nil {
+		return fmt.Errorf("failed to read table statistics: %w", err)
+	}
+	fmt.Printf("✓ Source statistics: ~%d row(s), %d column histogram(s)\n", stats.RowCount, len(stats.Columns))
+	return manifest.SetStatistics(stats)
+}
+
 // parallelProcessAndWrite обрабатывает и записывает пакеты параллельно.
 // Пакеты независимы (разные файлы/S3-ключи) → каждый пакет обрабатывается
 // в отдельной горутине. Размер пула = min(len(packets), runtime.NumCPU()).
@@ -481,7 +608,7 @@ func writePacket(ctx context.Context, pkt *packet.DataPacket, n, total int, opts
 
 	case opts.OutputFile == "" || opts.OutputFile == "-":
 		if opts.Encrypt && opts.EncryptLegacy {
-			return fmt.Errorf("--enc13 cannot be used with stdout output; specify --output file.tdtp.enc")
+			return UsageErrorf("--enc13 cannot be used with stdout output; specify --output file.tdtp.enc")
 		}
 		if opts.Encrypt {
 			xmlData, _, err := EncryptPacketV15(ctx, pkt, opts.MercuryURL, pkt.Header.TableName)
@@ -730,8 +857,18 @@ func compressPacketData(pkt *packet.DataPacket, level int, algo string, enableCh
 		rows[i] = row.Value
 	}
 
-	// Compress
-	compressed, stats, err := processors.CompressDataForTdtpAlgo(rows, algo, level)
+	// Compress — zstd со словарём таблицы, если он загружен (--dict-dir)
+	var (
+		compressed string
+		stats      processors.CompressionStats
+		err        error
+	)
+	dict := processors.TableZstdDict(pkt.Header.TableName)
+	if algo == processors.AlgoZstd && dict != nil {
+		compressed, stats, err = processors.CompressDataForTdtpDict(rows, level, dict)
+	} else {
+		compressed, stats, err = processors.CompressDataForTdtpAlgo(rows, algo, level)
+	}
 	if err != nil {
 		return err
 	}
@@ -745,10 +882,16 @@ func compressPacketData(pkt *packet.DataPacket, level int, algo string, enableCh
 	// Update packet with compressed data
 	pkt.Data.Compression = algo
 	pkt.Data.Rows = []packet.Row{{Value: compressed}}
+	if algo == processors.AlgoZstd && dict != nil {
+		processors.SetPacketZstdDict(pkt, dict, embedCompressionDict)
+	}
 
 	// Log compression stats
 	fmt.Printf("  → Compressed: %d → %d bytes (ratio: %.2fx)\n",
 		stats.OriginalSize, stats.CompressedSize, stats.Ratio)
+	if pkt.Data.ZstdDictID != "" {
+		fmt.Printf("  → Dictionary: %s\n", pkt.Data.ZstdDictID)
+	}
 	if enableChecksum {
 		fmt.Printf("  → Checksum: %s\n", pkt.Data.Checksum)
 	}
@@ -777,6 +920,11 @@ func decompressPacketData(pkt *packet.DataPacket) error {
 		fmt.Printf("  ✓ Checksum validated: %s\n", pkt.Data.Checksum)
 	}
 
+	// Embedded zstd dictionary must be registered before decompression
+	if err := processors.LoadPacketZstdDict(pkt); err != nil {
+		return err
+	}
+
 	// Decompress — dispatch by algorithm stored in packet
 	rows, err := processors.DecompressDataForTdtpAlgo(compressedData, pkt.Data.Compression)
 	if err != nil {
@@ -786,6 +934,8 @@ func decompressPacketData(pkt *packet.DataPacket) error {
 	// Update packet with decompressed data
 	pkt.Data.Compression = ""
 	pkt.Data.Checksum = "" // Clear checksum after validation
+	pkt.Data.ZstdDictID = ""
+	pkt.Data.ZstdDict = ""
 	pkt.Data.Rows = make([]packet.Row, len(rows))
 	for i, row := range rows {
 		pkt.Data.Rows[i] = packet.Row{Value: row}
@@ -806,3 +956,24 @@ func IsCompressedFile(filename string) bool {
 	return strings.HasSuffix(strings.ToLower(filename), ".zst") ||
 		strings.HasSuffix(strings.ToLower(filename), ".zstd")
 }
+
+// applyColumnExclusions удаляет из пакетов колонки export.exclude_columns
+// (до контрольных сумм и сжатия) и печатает их список.
+func applyColumnExclusions(exclusions *adapters.ColumnExclusions, packets []*packet.DataPacket) error {
+	var excluded []string
+	for _, pkt := range packets {
+		removed, err := exclusions.Apply(pkt)
+		if err != nil {
+			return err
+		}
+		for _, name := range removed {
+			if !slices.Contains(excluded, name) {
+				excluded = append(excluded, name)
+			}
+		}
+	}
+	if len(excluded) > 0 {
+		fmt.Printf("✓ Excluded column(s): %s\n", strings.Join(excluded, ", "))
+	}
+	return nil
+}
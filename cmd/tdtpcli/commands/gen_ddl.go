@@ -0,0 +1,80 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+// GenDDLOptions holds options for the --gen-ddl command.
+type GenDDLOptions struct {
+	Source     string // TDTP file, or table of the --config database
+	Target     string // Target dialect: postgres, mssql, mysql, sqlite, oracle
+	Table      string // Table name in the DDL (default: packet TableName / source table)
+	OutputFile string // Empty = stdout
+}
+
+// GenerateDDL prints CREATE TABLE DDL for the target dialect without
+// connecting to the target database. The schema comes from a TDTP packet
+// (no DB connection at all) or from a live table of the source database.
+//
+//	tdtpcli --gen-ddl orders.tdtp.xml --target mssql
+//	tdtpcli --gen-ddl orders --target oracle --config pg.yaml
+func GenerateDDL(ctx context.Context, config *adapters.Config, opts GenDDLOptions) error {
+	if opts.Target == "" {
+		return UsageErrorf("--gen-ddl requires --target (postgres, mssql, mysql, sqlite, oracle)")
+	}
+
+	tableName, schema, err := ddlSourceSchema(ctx, config, opts.Source)
+	if err != nil {
+		return err
+	}
+	if opts.Table != "" {
+		tableName = opts.Table
+	}
+
+	ddl, err := adapters.GenerateDDL(opts.Target, tableName, schema)
+	if err != nil {
+		return fmt.Errorf("failed to generate DDL: %w", err)
+	}
+
+	if opts.OutputFile == "" {
+		fmt.Print(ddl)
+		return nil
+	}
+	if err := os.WriteFile(opts.OutputFile, []byte(ddl), 0o644); err != nil {
+		return fmt.Errorf("failed to write DDL: %w", err)
+	}
+	fmt.Printf("✓ DDL for %s (%s) written to %s\n", tableName, opts.Target, opts.OutputFile)
+	return nil
+}
+
+// ddlSourceSchema reads the schema from a TDTP file when source is an
+// existing file, otherwise from the table of the configured database.
+func ddlSourceSchema(ctx context.Context, config *adapters.Config, source string) (string, packet.Schema, error) {
+	if info, err := os.Stat(source); err == nil && !info.IsDir() {
+		pkt, err := packet.NewParser().ParseFile(source)
+		if err != nil {
+			return "", packet.Schema{}, fmt.Errorf("failed to parse TDTP packet: %w", err)
+		}
+		return pkt.Header.TableName, pkt.Schema, nil
+	}
+
+	if config == nil || config.Type == "" {
+		return "", packet.Schema{}, fmt.Errorf("%s is not a file; reading a live table requires --config", source)
+	}
+	adapter, err := adapters.New(ctx, *config)
+	if err != nil {
+		return "", packet.Schema{}, fmt.Errorf("failed to connect: %w", err)
+	}
+	defer func() { _ = adapter.Close(ctx) }()
+
+	schema, err := adapter.GetTableSchema(ctx, source)
+	if err != nil {
+		return "", packet.Schema{}, fmt.Errorf("failed to read schema of %s: %w", source, err)
+	}
+	return source, schema, nil
+}
@@ -0,0 +1,92 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ruslano69/tdtp-framework/pkg/history"
+)
+
+// HistoryOptions holds options for the --history command.
+type HistoryOptions struct {
+	Filter history.Filter
+	RunID  string // --history-id: показать одну запись целиком (JSON, со статистикой шагов)
+}
+
+// ShowHistory выводит историю запусков --pipeline / --sync-incremental.
+func ShowHistory(ctx context.Context, store *history.Store, opts HistoryOptions) error {
+	if opts.RunID != "" {
+		run, err := store.Get(ctx, opts.RunID)
+		if err != nil {
+			return err
+		}
+		if run == nil {
+			return fmt.Errorf("run %s not found in history", opts.RunID)
+		}
+		data, err := json.MarshalIndent(run, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal run: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	runs, err := store.Query(ctx, opts.Filter)
+	if err != nil {
+		return err
+	}
+	if len(runs) == 0 {
+		fmt.Println("No runs found")
+		return nil
+	}
+
+	fmt.Printf("%-36s  %-8s  %-24s  %-21s  %-19s  %10s  %10s  %10s\n",
+		"ID", "KIND", "NAME", "STATUS", "STARTED (UTC)", "DURATION", "READ", "WRITTEN")
+	for _, r := range runs {
+		fmt.Printf("%-36s  %-8s  %-24s  %-21s  %-19s  %10s  %10d  %10d\n",
+			r.ID, r.Kind, truncate(r.Name, 24), r.Status, r.StartedAt.UTC().Format("2006-01-02 15:04:05"),
+			(time.Duration(r.DurationMs) * time.Millisecond).Round(time.Millisecond), r.RowsRead, r.RowsWritten)
+		if r.Error != "" {
+			fmt.Printf("    ❌ %s\n", truncate(r.Error, 120))
+		}
+	}
+	fmt.Printf("\n%d run(s)\n", len(runs))
+	return nil
+}
+
+// ParseHistorySince разбирает --history-since: длительность назад ("24h",
+// "30m"), дата ("2026-01-31") или RFC3339.
+func ParseHistorySince(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return time.Now().UTC().Add(-d), nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid --history-since %q (expected a duration like 24h, a date or RFC3339)", value)
+}
+
+// recordRun завершает запись о запуске и сохраняет её. Ошибка хранилища
+// истории не роняет сам запуск — только предупреждение (как resultlog).
+func recordRun(ctx context.Context, store *history.Store, run *history.Run, err error) {
+	run.Finish(err)
+	if recErr := store.Record(ctx, run); recErr != nil {
+		fmt.Printf("WARNING: failed to record run history: %v\n", recErr)
+		return
+	}
+	fmt.Printf("   History: run %s recorded (%s)\n", run.ID, run.Status)
+}
+
+func truncate(s string, n int) string {
+	if len([]rune(s)) <= n {
+		return s
+	}
+	return string([]rune(s)[:n-1]) + "…"
+}
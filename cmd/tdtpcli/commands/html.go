@@ -132,7 +132,8 @@ func renderHTML(inputFile string, pkt *packet.DataPacket, opts HTMLOptions) (str
 		if err != nil {
 			return "", 0, fmt.Errorf("failed to apply query filters: %w", err)
 		}
-		allRows = execResult.FilteredRows
+		pkt.Schema = execResult.Schema // --fields
+		allRows = execResult.Rows
 		totalRows = len(allRows)
 	}
 
@@ -8,13 +8,17 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+	"github.com/ruslano69/tdtp-framework/pkg/approval"
 	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
 	"github.com/ruslano69/tdtp-framework/pkg/mercury"
+	"github.com/ruslano69/tdtp-framework/pkg/progress"
 	"github.com/ruslano69/tdtp-framework/pkg/sanitize"
 	"github.com/ruslano69/tdtp-framework/pkg/storage"
 )
@@ -39,6 +43,11 @@ type ImportOptions struct {
 	StorageCfg *storage.Config // storage driver config with bucket
 	StorageKey string          // object key within the bucket
 
+	// VerifyManifest imports exactly the objects of key.manifest.json,
+	// checking each SHA-256 before parsing, and writes key.receipt.json
+	// afterwards; a receipt for the same manifest and target skips the import.
+	VerifyManifest bool
+
 	// PipelineContext precondition check (v1.4): --expect-var name=value.
 	// Import fails before any DB writes if packet variables don't match.
 	ExpectVars map[string]string
@@ -46,16 +55,83 @@ type ImportOptions struct {
 	// MercuryURL enables full executor verification for v1.4 packets.
 	// Empty → local xxh3 integrity check only (FallbackDegrade policy).
 	MercuryURL string
+
+	// Partition routes rows into daily/monthly partition tables (--partition-by).
+	Partition *adapters.PartitionRouting
+
+	// Provenance adds and fills _tdtp_source/_tdtp_message_id/_tdtp_imported_at/_tdtp_part
+	// (--provenance). Missing columns are added to an existing target table.
+	Provenance bool
+
+	// Analyze updates planner statistics of the target table(s) after a
+	// successful import (--analyze), see adapters.StatisticsUpdater.
+	Analyze bool
+
+	// BundleKey decrypts an encrypted .tdtpz bundle (--bundle-key-file).
+	BundleKey []byte
+
+	// DryRun checks the packets against the target (schema, value
+	// conversion, existing keys) and prints a report without writing
+	// anything (--dry-run), see adapters.DryRunImporter.
+	DryRun bool
+
+	// Simulate imports the packets into a scratch copy of the target
+	// tables instead of the target and prints the rows the import would
+	// add, remove or change (--simulate), see adapters.SimulateImport.
+	// KeepScratch leaves the copy in place for manual review.
+	Simulate    bool
+	KeepScratch bool
+
+	// Approval publishes an approval request and blocks until a decision
+	// before importing into a sensitive table (approval.tables) or, with
+	// RequireApproval (--require-approval), into any table.
+	Approval        *approval.Gate
+	RequireApproval bool
+
+	// MergeColumns limits the columns --strategy merge updates in existing
+	// rows (--merge-columns); empty updates all non-key packet fields.
+	MergeColumns []string
 }
 
 // ImportFile imports a TDTP XML file (or multi-part set) to database.
 // Parts are processed one at a time (streaming): each part is read, parsed,
 // inserted, and released before the next part is loaded. This keeps memory
 // usage constant regardless of the number of parts.
-func ImportFile(ctx context.Context, config *adapters.Config, opts ImportOptions) error {
+func ImportFile(ctx context.Context, config *adapters.Config, opts ImportOptions) (err error) {
+	// --progress-format json: run_started → parse (по частям) → import → run_completed
+	source := opts.FilePath
+	if opts.StorageCfg != nil {
+		source = opts.StorageKey
+	}
+	if opts.DryRun && opts.Partition != nil {
+		return UsageErrorf("--dry-run cannot be combined with --partition-by")
+	}
+	if opts.Simulate && (opts.DryRun || opts.Partition != nil || opts.Provenance) {
+		return UsageErrorf("--simulate cannot be combined with --dry-run, --partition-by or --provenance")
+	}
+	if opts.KeepScratch && !opts.Simulate {
+		return UsageErrorf("--keep-scratch requires --simulate")
+	}
+	if len(opts.MergeColumns) > 0 {
+		if opts.Strategy != adapters.StrategyMerge {
+			return UsageErrorf("--merge-columns requires --strategy merge")
+		}
+		ctx = adapters.WithMergeColumns(ctx, opts.MergeColumns...)
+	}
+	var importedRows int64
+	prog := progress.Begin(ctx, "import", source)
+	defer func() { prog.End(err, importedRows) }()
+
 	// Resolve source list without loading data yet.
-	type sourceRef struct{ label, key string }
+	type sourceRef struct {
+		label, key string
+		object     *storage.ManifestObject // --verify-manifest: expected checksum
+		bundlePart *packet.BundlePart      // part of a .tdtpz bundle
+	}
 	var sourceRefs []sourceRef
+	var manifestSHA, manifestTable string
+	var bundle *packet.Bundle
+	var receiptObjects []storage.ReceiptObject
 
 	var store storage.ObjectStorage
 	if opts.StorageCfg != nil {
@@ -66,6 +142,37 @@ func ImportFile(ctx context.Context, config *adapters.Config, opts ImportOptions
 		}
 		defer func() { _ = store.Close() }()
 
+		if opts.VerifyManifest {
+			manifest, sum, err := storage.ReadManifest(ctx, store, opts.StorageKey)
+			if err != nil {
+				return err
+			}
+			manifestSHA, manifestTable = sum, manifest.Table
+			receipt, err := storage.ReadReceipt(ctx, store, opts.StorageKey)
+			if err != nil {
+				return err
+			}
+			if receipt != nil && receipt.ManifestSHA256 == manifestSHA && receipt.Target == receiptTarget(config, opts, manifest.Table) {
+				fmt.Printf("✓ Already imported at %s into %s (%d row(s)), receipt s3://%s/%s — skipping\n",
+					receipt.ImportedAt.Format(time.RFC3339), receipt.Target, receipt.Rows,
+					opts.StorageCfg.S3.Bucket, storage.ReceiptKey(opts.StorageKey))
+				return nil
+			}
+			fmt.Printf("Manifest: %d object(s), sha256 %s\n", len(manifest.Objects), manifestSHA[:12])
+			for i := range manifest.Objects {
+				obj := &manifest.Objects[i]
+				sourceRefs = append(sourceRefs, sourceRef{
+					label:  "s3://" + opts.StorageCfg.S3.Bucket + "/" + obj.Key,
+					key:    obj.Key,
+					object: obj,
+				})
+			}
+		}
+	} else if opts.VerifyManifest {
+		return UsageErrorf("--verify-manifest requires an s3:// source")
+	}
+
+	if store != nil && !opts.VerifyManifest {
 		keys := []string{opts.StorageKey}
 		if !strings.Contains(opts.StorageKey, "_part_") {
 			keyExt := filepath.Ext(opts.StorageKey)
@@ -84,7 +191,19 @@ func ImportFile(ctx context.Context, config *adapters.Config, opts ImportOptions
 				key:   k,
 			})
 		}
-	} else {
+	} else if store == nil && packet.IsBundlePath(opts.FilePath) {
+		b, err := packet.OpenBundle(opts.FilePath, packet.BundleOptions{Key: opts.BundleKey})
+		if err != nil {
+			return err
+		}
+		defer func() { _ = b.Close() }()
+		bundle = b
+		fmt.Printf("Bundle: table %s, %d part(s), %d row(s)\n", b.Manifest.Table, len(b.Manifest.Parts), b.Manifest.Rows())
+		for i := range b.Manifest.Parts {
+			part := &b.Manifest.Parts[i]
+			sourceRefs = append(sourceRefs, sourceRef{label: opts.FilePath + "#" + part.Name, key: part.Name, bundlePart: part})
+		}
+	} else if store == nil {
 		filePaths := discoverMultiPartFiles(opts.FilePath)
 		if filePaths == nil {
 			filePaths = []string{opts.FilePath}
@@ -98,8 +217,10 @@ func ImportFile(ctx context.Context, config *adapters.Config, opts ImportOptions
 	// but parsed packets are kept for atomic multi-part insertion via ImportPackets.
 	p := packet.NewParser()
 	packets := make([]*packet.DataPacket, 0, len(sourceRefs))
+	parseStep := progress.StartStep(ctx, "parse")
+	var parsedRows int64
 
-	for _, src := range sourceRefs {
+	for i, src := range sourceRefs {
 		var data []byte
 		var err error
 		if store != nil {
@@ -113,6 +234,18 @@ func ImportFile(ctx context.Context, config *adapters.Config, opts ImportOptions
 			if err != nil {
 				return fmt.Errorf("failed to read object %s: %w", src.key, err)
 			}
+			if src.object != nil {
+				if err := src.object.Verify(data); err != nil {
+					return err
+				}
+				fmt.Printf("  ✓ sha256 verified\n")
+			}
+		} else if src.bundlePart != nil {
+			fmt.Printf("Reading '%s'...\n", src.label)
+			data, err = bundle.ReadPart(*src.bundlePart)
+			if err != nil {
+				return fmt.Errorf("failed to read bundle: %w", err)
+			}
 		} else {
 			fmt.Printf("Reading '%s'...\n", src.label)
 			data, err = os.ReadFile(src.key)
@@ -216,6 +349,12 @@ func ImportFile(ctx context.Context, config *adapters.Config, opts ImportOptions
 			pkt.Schema.Dictionary = nil
 		}
 
+		// Процессоры и фильтр полей работают с полными строками в порядке
+		// схемы — delta-строки (ключ + тройки поле/old/new) они бы испортили.
+		if pkt.Data.Delta && ((opts.ProcessorMgr != nil && opts.ProcessorMgr.HasProcessors()) || len(opts.Fields) > 0) {
+			return fmt.Errorf("delta packets cannot be combined with --mask/--normalize/--validate or --fields")
+		}
+
 		if opts.ProcessorMgr != nil && opts.ProcessorMgr.HasProcessors() {
 			if err := opts.ProcessorMgr.ProcessPacket(ctx, pkt); err != nil {
 				return fmt.Errorf("processor failed: %w", err)
@@ -239,8 +378,15 @@ func ImportFile(ctx context.Context, config *adapters.Config, opts ImportOptions
 		}
 
 		fmt.Printf("  ✓ %d row(s)\n", len(pkt.Data.Rows))
+		if src.object != nil {
+			receiptObjects = append(receiptObjects, storage.ReceiptObject{Key: src.key, SHA256: src.object.SHA256, Rows: len(pkt.Data.Rows)})
+		}
 		packets = append(packets, pkt)
+		parsedRows += int64(len(pkt.Data.Rows))
+		progress.Emit(ctx, progress.Event{Event: progress.Progress, Step: "parse", Item: src.label,
+			Rows: int64(len(pkt.Data.Rows)), Done: i + 1, Total: len(sourceRefs)})
 	}
+	parseStep.End(nil, parsedRows)
 
 	// Validate session integrity unconditionally:
 	// - catches packets from different export sessions (batch ID mismatch)
@@ -264,37 +410,263 @@ func ImportFile(ctx context.Context, config *adapters.Config, opts ImportOptions
 		}
 	}
 
+	// Sensitive tables: the decision comes before any change to the target
+	// (--provenance alters the table); --dry-run and --simulate write nothing
+	if !opts.DryRun && !opts.Simulate {
+		if err := approveImport(ctx, opts, source, packets, parsedRows); err != nil {
+			return err
+		}
+	}
+
 	adapter, err := adapters.New(ctx, *config)
 	if err != nil {
 		return fmt.Errorf("failed to create adapter: %w", err)
 	}
 	defer func() { _ = adapter.Close(ctx) }()
 
+	// --provenance: the adapter adds the columns to the table and fills them
+	// (--dry-run only checks the packets with them)
+	if opts.Provenance {
+		ctx = adapters.WithProvenance(ctx, time.Now())
+	}
+
+	// Имена длиннее ограничения целевой СУБД (Oracle 30, PostgreSQL 63, ...)
+	// укорачиваются детерминированно — до CREATE TABLE и INSERT
+	renames, err := adapters.FitPacketIdentifiers(adapter, packets)
+	if err != nil {
+		return fmt.Errorf("identifier validation failed: %w", err)
+	}
+	if len(renames) > 0 {
+		fmt.Printf("  Identifier shortening for %s (%d renamed):\n", config.Type, len(renames))
+		for _, r := range renames {
+			kind := "column"
+			if r.Table {
+				kind = "table"
+			}
+			fmt.Printf("    %s '%s' → '%s'\n", kind, r.Original, r.Short)
+		}
+	}
+
 	tableName := packets[0].Header.TableName
 	totalRows := 0
 	for _, pkt := range packets {
 		totalRows += len(pkt.Data.Rows)
 	}
 
+	if opts.DryRun {
+		fmt.Printf("Dry run: checking table '%s': %d packet(s), %d row(s), strategy '%s' — nothing will be written\n",
+			tableName, len(packets), totalRows, opts.Strategy)
+		report, err := adapters.DryRunImport(ctx, adapter, packets, opts.Strategy)
+		if err != nil {
+			return err
+		}
+		printImportReport(report)
+		return report.Err()
+	}
+
+	if opts.Simulate {
+		fmt.Printf("Simulation: importing table '%s' into a scratch copy: %d packet(s), %d row(s), strategy '%s' — the target will not be written\n",
+			tableName, len(packets), totalRows, opts.Strategy)
+		report, err := adapters.SimulateImport(ctx, adapter, *config, packets, adapters.SimulateOptions{
+			Strategy:    opts.Strategy,
+			KeepScratch: opts.KeepScratch,
+		})
+		if err != nil {
+			return err
+		}
+		printSimulationReport(report)
+		return nil
+	}
+
 	fmt.Printf("Importing table '%s': %d packet(s), %d row(s), strategy '%s'...\n",
 		tableName, len(packets), totalRows, opts.Strategy)
 
-	// Single packet: ImportPacket. Multiple packets: ImportPackets (one transaction,
-	// atomicity preserved, --strategy copy does a single temp-table swap).
-	if len(packets) == 1 {
+	importStep := progress.StartStep(ctx, "import")
+	// Partition routing: rows go to <table>_<yyyy>_<mm>[_<dd>] tables.
+	// Row error policy (database.row_errors): ImportPacketsReport reports the
+	// rejected rows, per partition with routing. Single packet: ImportPacket.
+	// Multiple packets: ImportPackets (one transaction, atomicity preserved,
+	// --strategy copy does a single temp-table swap).
+	var report *adapters.ImportReport
+	reporting, canReport := adapter.(adapters.ReportingImporter)
+	if opts.Partition != nil {
+		report, err = adapters.ImportPartitionedReport(ctx, adapter, packets, adapters.ImportOptions{
+			Strategy:         opts.Strategy,
+			Partition:        opts.Partition,
+			UpdateStatistics: opts.Analyze,
+		})
+	} else if canReport && (config.RowErrors.Policy != adapters.RowErrorsFailFast || config.RowErrors.PartialRollback) {
+		report, err = reporting.ImportPacketsReport(ctx, packets, opts.Strategy)
+	} else if len(packets) == 1 {
 		err = adapter.ImportPacket(ctx, packets[0], opts.Strategy)
 	} else {
 		err = adapter.ImportPackets(ctx, packets, opts.Strategy)
 	}
+	rejectedRows := 0
+	if report != nil {
+		totalRows, rejectedRows = printRejectedRows(report)
+	}
+	importStep.End(err, int64(totalRows))
 	if err != nil {
 		return fmt.Errorf("import failed: %w", err)
 	}
 
 	fmt.Printf("✓ Import complete! Table '%s' — %d row(s)\n", tableName, totalRows)
+	if opts.Analyze && opts.Partition == nil {
+		// ImportPartitioned analyzes each partition itself
+		updateStatistics(ctx, adapter, tableName)
+	}
 	recordOpMetrics(ctx, tableName, int64(totalRows))
+	importedRows = int64(totalRows)
+
+	if opts.VerifyManifest {
+		receipt := &storage.Receipt{
+			ManifestKey:    storage.ManifestKey(opts.StorageKey),
+			ManifestSHA256: manifestSHA,
+			ImportedAt:     time.Now().UTC(),
+			Target:         receiptTarget(config, opts, manifestTable),
+			Rows:           int64(totalRows),
+			Objects:        receiptObjects,
+		}
+		if err := storage.PutReceipt(ctx, store, opts.StorageKey, receipt); err != nil {
+			return fmt.Errorf("import succeeded but the receipt was not written (a re-run imports again): %w", err)
+		}
+		fmt.Printf("✓ Receipt: s3://%s/%s\n", opts.StorageCfg.S3.Bucket, storage.ReceiptKey(opts.StorageKey))
+	}
+	// Valid rows are committed; rejected ones make the run partial (exit code 8)
+	if rejectedRows > 0 {
+		return &PartialImportError{Table: tableName, Imported: totalRows, Rejected: rejectedRows}
+	}
 	return nil
 }
 
+// approveImport blocks an import into sensitive tables until it is
+// approved (see ImportOptions.Approval); other imports pass through.
+func approveImport(ctx context.Context, opts ImportOptions, source string, packets []*packet.DataPacket, rows int64) error {
+	var tables []string
+	sensitive := opts.RequireApproval
+	for _, pkt := range packets {
+		if table := pkt.Header.TableName; !slices.Contains(tables, table) {
+			tables = append(tables, table)
+			sensitive = sensitive || opts.Approval.Sensitive(table)
+		}
+	}
+	if !sensitive {
+		return nil
+	}
+	req := approval.NewRequest("import", strings.Join(tables, ","), source)
+	req.Strategy = string(opts.Strategy)
+	req.Rows = rows
+	return awaitApproval(ctx, opts.Approval, req)
+}
+
+// printImportReport prints the outcome of a --dry-run import per table.
+func printImportReport(report *adapters.ImportReport) {
+	for _, t := range report.Tables {
+		state := "exists"
+		if !t.Exists {
+			state = "will be created"
+		}
+		fmt.Printf("Table '%s' (%s): %d packet(s), %d row(s)\n", t.Table, state, t.Packets, t.Rows)
+		for _, e := range t.Errors {
+			fmt.Printf("  ❌ %s\n", e)
+		}
+		for _, w := range t.Warnings {
+			fmt.Printf("  ⚠ %s\n", w)
+		}
+		if t.RowErrorCount > 0 {
+			fmt.Printf("  ❌ %d value(s) do not convert to column types:\n", t.RowErrorCount)
+			printRowErrors(t)
+		}
+		if t.Conflicts > 0 {
+			action := map[adapters.ImportStrategy]string{
+				adapters.StrategyReplace: "will be updated",
+				adapters.StrategyMerge:   "will be updated (merge columns only)",
+				adapters.StrategyIgnore:  "will be skipped",
+				adapters.StrategyFail:    "fail the import",
+				adapters.StrategyAppend:  "fail the import",
+			}[report.Strategy]
+			fmt.Printf("  • %d row(s) match existing keys (%s), e.g. %v\n", t.Conflicts, action, t.ConflictKeys[0])
+		}
+	}
+	if err := report.Err(); err == nil {
+		fmt.Println("✓ Dry run passed: the import would succeed")
+	}
+}
+
+// printSimulationReport prints the changes a --simulate import would make
+// per table.
+func printSimulationReport(report *adapters.SimulationReport) {
+	for _, t := range report.Tables {
+		state := "exists"
+		if t.Created {
+			state = "will be created"
+		}
+		s := t.Diff.Stats
+		fmt.Printf("\nTable '%s' (%s): %d added, %d removed, %d modified, %d unchanged\n",
+			t.Table, state, s.AddedCount, s.RemovedCount, s.ModifiedCount, s.UnchangedCount)
+		if !t.Diff.IsEqual() {
+			fmt.Print(t.Diff.FormatText())
+		}
+	}
+	if report.Kept {
+		fmt.Printf("\nScratch copy kept: %s\n", report.Scratch)
+	}
+	if report.Changed() {
+		fmt.Println("\n✗ The import would change the target — review the diff before applying it")
+	} else {
+		fmt.Println("\n✓ The import would not change the target")
+	}
+}
+
+// printRejectedRows prints the rows a row error policy rejected or rolled
+// back and returns the numbers of rows written and rejected.
+func printRejectedRows(report *adapters.ImportReport) (written, rejected int) {
+	for _, t := range report.Tables {
+		written += t.Rows
+		rejected += t.RowErrorCount + t.RolledBackRows
+		if len(t.RolledBack) > 0 {
+			fmt.Printf("  ⚠ Table '%s': %d packet(s) rolled back, %d row(s):\n", t.Table, len(t.RolledBack), t.RolledBackRows)
+			for _, msg := range t.RolledBack {
+				fmt.Printf("     %s\n", msg)
+			}
+		}
+		if t.RowErrorCount == 0 {
+			continue
+		}
+		fmt.Printf("  ⚠ Table '%s': %d row(s) rejected:\n", t.Table, t.RowErrorCount)
+		printRowErrors(t)
+		for _, path := range t.DeadLetters {
+			fmt.Printf("     quarantined: %s\n", path)
+		}
+	}
+	return written, rejected
+}
+
+// printRowErrors lists the reported row errors of a table.
+func printRowErrors(t *adapters.TableImportReport) {
+	for _, e := range t.RowErrors {
+		if e.Field == "" {
+			fmt.Printf("     packet %d row %d: %s\n", e.Packet, e.Row, e.Err)
+			continue
+		}
+		fmt.Printf("     packet %d row %d %s=%q: %s\n", e.Packet, e.Row, e.Field, e.Value, e.Err)
+	}
+	if t.RowErrorCount > len(t.RowErrors) {
+		fmt.Printf("     ... and %d more\n", t.RowErrorCount-len(t.RowErrors))
+	}
+}
+
+// receiptTarget is the receipt target of a verified import: "<db type>:<table>",
+// where the table is --table or the manifest's table.
+func receiptTarget(config *adapters.Config, opts ImportOptions, manifestTable string) string {
+	table := manifestTable
+	if opts.TargetTable != "" {
+		table = opts.TargetTable
+	}
+	return config.Type + ":" + table
+}
+
 // discoverMultiPartFiles detects a multi-part export set on disk.
 // Handles two cases:
 //   - filePath IS a part file (e.g. "data.tdtp_part_1_of_9.xml")
@@ -344,8 +716,14 @@ func ParseImportStrategy(strategy string) (adapters.ImportStrategy, error) {
 		return adapters.StrategyFail, nil
 	case "copy":
 		return adapters.StrategyCopy, nil
+	case "merge":
+		return adapters.StrategyMerge, nil
+	case "append":
+		return adapters.StrategyAppend, nil
+	case "truncate":
+		return adapters.StrategyTruncate, nil
 	default:
-		return "", fmt.Errorf("invalid import strategy: %s (valid: replace, ignore, fail, copy)", strategy)
+		return "", fmt.Errorf("invalid import strategy: %s (valid: replace, ignore, fail, copy, merge, append, truncate)", strategy)
 	}
 }
 
@@ -556,6 +934,20 @@ func filterPacketFields(pkt *packet.DataPacket, fields []string) error {
 	}
 
 	pkt.Schema.Fields = filteredFields
-	pkt.Data = packet.RowsToData(projected)
+	pkt.SetRows(projected)
 	return nil
 }
+
+// updateStatistics refreshes planner statistics of the imported table. The
+// data is already committed, so a failure is only reported.
+func updateStatistics(ctx context.Context, adapter adapters.Adapter, tableName string) {
+	supported, err := adapters.UpdateStatistics(ctx, adapter, tableName)
+	switch {
+	case !supported:
+		fmt.Printf("⚠ --analyze: %s does not support statistics update, skipped\n", adapter.GetDatabaseType())
+	case err != nil:
+		fmt.Printf("⚠ %v\n", err)
+	default:
+		fmt.Printf("✓ Statistics updated: %s\n", tableName)
+	}
+}
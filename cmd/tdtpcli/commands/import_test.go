@@ -39,6 +39,24 @@ func TestParseImportStrategy(t *testing.T) {
 			expected:    adapters.StrategyCopy,
 			expectError: false,
 		},
+		{
+			name:        "Merge strategy",
+			strategy:    "merge",
+			expected:    adapters.StrategyMerge,
+			expectError: false,
+		},
+		{
+			name:        "Append strategy",
+			strategy:    "append",
+			expected:    adapters.StrategyAppend,
+			expectError: false,
+		},
+		{
+			name:        "Truncate strategy",
+			strategy:    "truncate",
+			expected:    adapters.StrategyTruncate,
+			expectError: false,
+		},
 		{
 			name:        "Invalid strategy",
 			strategy:    "invalid",
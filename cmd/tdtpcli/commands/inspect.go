@@ -101,6 +101,11 @@ func InspectFile(ctx context.Context, inputFile string, storageCfg *storage.Conf
 	fmt.Printf("filter: %s\n", filter)
 	fmt.Printf("special_values: %s\n", specialValues)
 
+	if pkt.QueryContext != nil && pkt.QueryContext.ExecutionPlan != nil {
+		fmt.Println("execution_plan:")
+		printQueryPlan(pkt.QueryContext.ExecutionPlan, "  ")
+	}
+
 	if pkt.PipelineContext != nil {
 		pc := pkt.PipelineContext
 		ver := ""
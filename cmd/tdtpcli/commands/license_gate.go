@@ -36,10 +36,10 @@ func ResolveLicense(flagPath string) (*license.License, error) {
 
 	lic, err := license.Load(path)
 	if err != nil {
-		return nil, fmt.Errorf("license load: %w", err)
+		return nil, WithClass(ClassLicense, fmt.Errorf("license load: %w", err))
 	}
 	if err := lic.Verify(); err != nil {
-		return nil, fmt.Errorf("license verification failed: %w", err)
+		return nil, WithClass(ClassLicense, fmt.Errorf("license verification failed: %w", err))
 	}
 	active = lic
 	return lic, nil
@@ -77,8 +77,8 @@ func GateFeature(feature string) error {
 	if lic.AllowsFeature(feature) {
 		return nil
 	}
-	return fmt.Errorf("feature %q is not licensed (tier=%s); current license: %s",
-		feature, lic.GetTier(), lic.LicenseeName())
+	return WithClass(ClassLicense, fmt.Errorf("feature %q is not licensed (tier=%s); current license: %s",
+		feature, lic.GetTier(), lic.LicenseeName()))
 }
 
 // GateAdapter returns an error if the active license does not permit the adapter.
@@ -87,8 +87,8 @@ func GateAdapter(adapter string) error {
 	if lic.AllowsAdapter(adapter) {
 		return nil
 	}
-	return fmt.Errorf("database adapter %q is not licensed (tier=%s); "+
-		"community tier allows sqlite only", adapter, lic.GetTier())
+	return WithClass(ClassLicense, fmt.Errorf("database adapter %q is not licensed (tier=%s); "+
+		"community tier allows sqlite only", adapter, lic.GetTier()))
 }
 
 // GateRowCount returns an error if rowCount exceeds the licensed per-export limit.
@@ -98,6 +98,6 @@ func GateRowCount(rowCount int) error {
 	if limit == 0 || rowCount <= limit {
 		return nil
 	}
-	return fmt.Errorf("export of %d rows exceeds licensed limit of %d rows per export",
-		rowCount, limit)
+	return WithClass(ClassLicense, fmt.Errorf("export of %d rows exceeds licensed limit of %d rows per export",
+		rowCount, limit))
 }
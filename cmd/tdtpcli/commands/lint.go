@@ -0,0 +1,94 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/schema"
+)
+
+// LintReport is the machine-readable result of --lint (--lint-format json).
+type LintReport struct {
+	Files    int                  `json:"files"`
+	Errors   int                  `json:"errors"`
+	Warnings int                  `json:"warnings"`
+	Findings []schema.LintFinding `json:"findings"`
+}
+
+// LintFiles validates TDTP files against the spec without a database:
+// XML structure, required header fields, schema consistency, field counts
+// and value formats of every row, and part numbering of multi-part sets
+// (any part of name_part_N_of_M expands to the whole set, as in --test).
+// format is "text" or "json"; the json report goes to stdout for CI gating.
+// Returns an error if any finding is an error, so the exit code fails the job.
+func LintFiles(ctx context.Context, paths []string, format string) error {
+	if format != "text" && format != "json" {
+		return fmt.Errorf("invalid --lint-format: %s (valid: text, json)", format)
+	}
+
+	report := LintReport{Findings: []schema.LintFinding{}}
+	for _, path := range paths {
+		files, missing, err := resolvePartSet(path)
+		if err != nil {
+			return err
+		}
+		for _, m := range missing {
+			report.Findings = append(report.Findings, schema.LintFinding{
+				Severity: schema.LintError, Check: schema.LintCheckParts, File: filepath.Base(m),
+				Message: "part file is missing",
+			})
+		}
+
+		var parts []schema.LintedPacket
+		for _, f := range files {
+			data, err := os.ReadFile(f)
+			if err != nil {
+				return fmt.Errorf("failed to read file: %w", err)
+			}
+			report.Files++
+			label := filepath.Base(f)
+			pkt, findings := schema.LintPacket(ctx, label, data)
+			report.Findings = append(report.Findings, findings...)
+			if pkt != nil {
+				parts = append(parts, schema.LintedPacket{File: label, Packet: pkt})
+			}
+		}
+		if len(missing) == 0 {
+			report.Findings = append(report.Findings, schema.LintParts(parts)...)
+		}
+	}
+	for _, f := range report.Findings {
+		if f.Severity == schema.LintError {
+			report.Errors++
+		} else {
+			report.Warnings++
+		}
+	}
+
+	if format == "json" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal lint report: %w", err)
+		}
+		fmt.Println(string(data))
+	} else {
+		for _, f := range report.Findings {
+			mark := "✗"
+			if f.Severity == schema.LintWarning {
+				mark = "⚠"
+			}
+			fmt.Printf("  %s %s\n", mark, f)
+		}
+		if report.Errors == 0 {
+			fmt.Printf("✓ %d file(s) conform to the TDTP spec (%d warning(s))\n", report.Files, report.Warnings)
+		}
+	}
+
+	if report.Errors > 0 {
+		return fmt.Errorf("lint failed: %d error(s), %d warning(s) in %d file(s)", report.Errors, report.Warnings, report.Files)
+	}
+	return nil
+}
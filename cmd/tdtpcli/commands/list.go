@@ -34,6 +34,11 @@ func ListTables(ctx context.Context, config *adapters.Config, pattern string) er
 	}
 	defer func() { _ = adapter.Close(ctx) }()
 
+	// "sales.*" / "*.orders" — таблицы других схем (PostgreSQL)
+	if strings.Contains(pattern, ".") {
+		return listSchemaTables(ctx, adapter, pattern)
+	}
+
 	// Get full table list from the database
 	tables, err := adapter.GetTableNames(ctx)
 	if err != nil {
@@ -70,6 +75,85 @@ func ListTables(ctx context.Context, config *adapters.Config, pattern string) er
 	return nil
 }
 
+// listSchemaTables lists tables for a schema-qualified pattern
+// ("sales.*", "*.orders"): schemas matching the part before the dot,
+// tables matching the part after it. Names are printed qualified, ready
+// for --export.
+func listSchemaTables(ctx context.Context, adapter adapters.Adapter, pattern string) error {
+	lister, ok := adapter.(adapters.SchemaLister)
+	if !ok {
+		return fmt.Errorf("adapter %s does not support schemas (pattern %q)", adapter.GetDatabaseType(), pattern)
+	}
+	schemaPattern, tablePattern, _ := strings.Cut(pattern, ".")
+
+	schemas, err := lister.GetSchemas(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list schemas: %w", err)
+	}
+
+	var filtered []string
+	for _, schema := range schemas {
+		if !matchesPattern(schema, schemaPattern) {
+			continue
+		}
+		tables, err := lister.GetSchemaTableNames(ctx, schema)
+		if err != nil {
+			return fmt.Errorf("failed to list tables: %w", err)
+		}
+		for _, t := range tables {
+			if matchesPattern(t, tablePattern) {
+				filtered = append(filtered, schema+"."+t)
+			}
+		}
+	}
+
+	if len(filtered) == 0 {
+		fmt.Printf("No tables matching %q\n", pattern)
+		return nil
+	}
+	fmt.Printf("Found %d table(s) matching %q:\n", len(filtered), pattern)
+	for i, table := range filtered {
+		fmt.Printf("  %d. %s\n", i+1, table)
+	}
+	return nil
+}
+
+// ListSchemas lists database schemas (namespaces) with their table counts
+func ListSchemas(ctx context.Context, config *adapters.Config) error {
+	// Create adapter
+	adapter, err := adapters.New(ctx, *config)
+	if err != nil {
+		return fmt.Errorf("failed to create adapter: %w", err)
+	}
+	defer func() { _ = adapter.Close(ctx) }()
+
+	lister, ok := adapter.(adapters.SchemaLister)
+	if !ok {
+		return fmt.Errorf("adapter %s does not support schemas", adapter.GetDatabaseType())
+	}
+
+	schemas, err := lister.GetSchemas(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list schemas: %w", err)
+	}
+	if len(schemas) == 0 {
+		fmt.Println("No schemas found")
+		return nil
+	}
+
+	fmt.Printf("Found %d schema(s):\n", len(schemas))
+	for i, schema := range schemas {
+		tables, err := lister.GetSchemaTableNames(ctx, schema)
+		if err != nil {
+			return fmt.Errorf("failed to list tables: %w", err)
+		}
+		fmt.Printf("  %d. %s (%d tables)\n", i+1, schema, len(tables))
+	}
+	fmt.Println("\nUse --list \"<schema>.*\" to list tables of a schema")
+
+	return nil
+}
+
 // ListViews lists all database views with updatable status
 func ListViews(ctx context.Context, config *adapters.Config) error {
 	// Create adapter
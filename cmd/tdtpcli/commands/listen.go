@@ -1,14 +1,15 @@
 package commands
 
-// Streaming consumer daemon for Kafka only.
+// Streaming consumer daemon for Kafka and filequeue.
 //
 // Broker tier selection:
 //
-//	MSMQ     — Legacy     (Windows-only, no partition ordering; batch mode only)
-//	RabbitMQ — Stability  (reliable delivery, acknowledgements; batch mode only)
-//	Kafka    — Speed      (ordered partitions, offset commit; batch + streaming)
+//	MSMQ      — Legacy     (Windows-only, no partition ordering; batch mode only)
+//	RabbitMQ  — Stability  (reliable delivery, acknowledgements; batch mode only)
+//	Kafka     — Speed      (ordered partitions, offset commit; batch + streaming)
+//	filequeue — Air-gapped (shared folder / removable media, sequence-numbered files)
 //
-// Only Kafka guarantees strict per-partition ordering required to assemble
+// Only Kafka and filequeue guarantee the strict ordering required to assemble
 // stream sessions from sequentially numbered parts (PartNumber 1…N).
 //
 // Design notes:
@@ -29,6 +30,7 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"strings"
 	"syscall"
 	"time"
@@ -68,11 +70,11 @@ func extractStreamBase(messageID string) string {
 // ListenKafkaStream runs the streaming consumer daemon.
 // It blocks until SIGTERM/SIGINT is received or a fatal error occurs.
 func ListenKafkaStream(ctx context.Context, dbConfig *adapters.Config, cfg ListenConfig) error {
-	if !strings.EqualFold(cfg.BrokerCfg.Type, "kafka") {
+	if !strings.EqualFold(cfg.BrokerCfg.Type, "kafka") && !strings.EqualFold(cfg.BrokerCfg.Type, "filequeue") {
 		return fmt.Errorf(
-			"--listen supports Kafka only (got: %q)\n\n"+
+			"--listen supports Kafka and filequeue only (got: %q)\n\n"+
 				"  Streaming mode requires strict message ordering, which is guaranteed\n"+
-				"  by Kafka partitions but not by RabbitMQ or MSMQ.\n\n"+
+				"  by Kafka partitions and filequeue sequence numbers but not by RabbitMQ or MSMQ.\n\n"+
 				"  For RabbitMQ/MSMQ use batch mode: --import-broker",
 			cfg.BrokerCfg.Type,
 		)
@@ -85,7 +87,7 @@ func ListenKafkaStream(ctx context.Context, dbConfig *adapters.Config, cfg Liste
 	}
 	defer func() { _ = adapter.Close(ctx) }()
 
-	// Create and connect Kafka broker
+	// Create and connect Kafka/filequeue broker
 	broker, err := createBroker(cfg.BrokerCfg)
 	if err != nil {
 		return fmt.Errorf("failed to create broker: %w", err)
@@ -93,12 +95,16 @@ func ListenKafkaStream(ctx context.Context, dbConfig *adapters.Config, cfg Liste
 	defer func() { _ = broker.Close() }()
 
 	if err := broker.Connect(ctx); err != nil {
-		return fmt.Errorf("failed to connect to Kafka: %w", err)
+		return fmt.Errorf("failed to connect to %s: %w", cfg.BrokerCfg.Type, err)
 	}
 
 	topic := cfg.BrokerCfg.Queue
 	fmt.Printf("[listen] streaming consumer started\n")
-	fmt.Printf("[listen] Kafka topic : %s\n", topic)
+	if strings.EqualFold(cfg.BrokerCfg.Type, "filequeue") {
+		fmt.Printf("[listen] filequeue   : %s\n", filepath.Join(cfg.BrokerCfg.Dir, topic))
+	} else {
+		fmt.Printf("[listen] Kafka topic : %s\n", topic)
+	}
 	fmt.Printf("[listen] DB strategy : %s\n", cfg.Strategy)
 	fmt.Printf("[listen] WARNING: requires stable channel (99.99%% uptime recommended)\n")
 	fmt.Printf("[listen] Press Ctrl+C to stop\n\n")
@@ -141,6 +147,11 @@ func ListenKafkaStream(ctx context.Context, dbConfig *adapters.Config, cfg Liste
 		})
 		if err != nil {
 			fmt.Printf("[listen] parse error (skipping message): %v\n", err)
+			// filequeue: move the unparseable message to failed/ instead of
+			// requeueing it on shutdown. Kafka skips it with the next commit.
+			if nacker, ok := broker.(interface{ NackLast(requeue bool) error }); ok {
+				_ = nacker.NackLast(false)
+			}
 			continue
 		}
 
@@ -10,6 +10,7 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/ruslano69/tdtp-framework/pkg/approval"
 	"github.com/ruslano69/tdtp-framework/pkg/brokers"
 	"github.com/ruslano69/tdtp-framework/pkg/core/mapping"
 	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
@@ -23,6 +24,8 @@ type MapOptions struct {
 	DryRun      bool   // print what would happen without writing to DB
 	MercuryURL  string // xZMercury base URL for decrypting .enc input (burn-on-read)
 	Listen      bool   // daemon mode: loop on broker queue until SIGTERM
+
+	Approval *approval.Gate // approval of sensitive mappings (sensitive: true)
 }
 
 // RunMap executes a cross-system field mapping: reads a TDTP packet, applies
@@ -49,11 +52,14 @@ func RunMap(ctx context.Context, opts MapOptions) error {
 	// Daemon mode: hand off to the listen loop (no loop guard — broker regulates rate)
 	if opts.Listen {
 		if !isBrokerURI(opts.InputFile) {
-			return fmt.Errorf("--listen requires a broker:// URI in --input")
+			return UsageErrorf("--listen requires a broker:// URI in --input")
 		}
 		if brokercfg == nil {
 			return fmt.Errorf("--listen: mapping YAML has no input_source.broker section")
 		}
+		if cfg.Sensitive {
+			return fmt.Errorf("--listen: mapping %s is sensitive (each run needs approval), run it one-shot", cfg.ID)
+		}
 		return runMapListen(ctx, cfg, opts, brokercfg)
 	}
 
@@ -88,6 +94,18 @@ func RunMap(ctx context.Context, opts MapOptions) error {
 	fmt.Printf("  input: %s (%d rows, %d fields)\n",
 		pkt.Header.TableName, pkt.Header.RecordsInPart, len(pkt.Schema.Fields))
 
+	if cfg.Sensitive && !opts.DryRun {
+		tables := make([]string, len(cfg.Targets))
+		for i, t := range cfg.Targets {
+			tables[i] = t.Table
+		}
+		req := approval.NewRequest("map", strings.Join(tables, ","), cfg.ID+": "+opts.InputFile)
+		req.Rows = int64(pkt.Header.RecordsInPart)
+		if err := awaitApproval(ctx, opts.Approval, req); err != nil {
+			return fmt.Errorf("--map: %w", err)
+		}
+	}
+
 	// Execute mapping
 	if err := mapping.Execute(ctx, cfg, pkt, opts.DryRun); err != nil {
 		return fmt.Errorf("--map execute: %w", err)
@@ -0,0 +1,71 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ruslano69/tdtp-framework/pkg/operations"
+)
+
+// ListOperations выводит выполняющиеся операции всех процессов хоста
+// (tdtpcli и tdtpserve), зарегистрированные в реестре dir.
+func ListOperations(dir string) error {
+	reg, err := operations.Open(dir)
+	if err != nil {
+		return err
+	}
+	ops, err := reg.List()
+	if err != nil {
+		return err
+	}
+	if len(ops) == 0 {
+		fmt.Println("No operations in progress")
+		return nil
+	}
+
+	fmt.Printf("%-15s  %-16s  %-24s  %8s  %-19s  %10s  %-20s  %10s\n",
+		"ID", "COMMAND", "NAME", "PID", "STARTED (UTC)", "ELAPSED", "PROGRESS", "ROWS")
+	for _, op := range ops {
+		status := formatOperationProgress(op)
+		if op.CancelRequested {
+			status = "cancelling"
+		}
+		fmt.Printf("%-15s  %-16s  %-24s  %8d  %-19s  %10s  %-20s  %10d\n",
+			op.ID, op.Command, truncate(op.Name, 24), op.PID, op.StartedAt.UTC().Format("2006-01-02 15:04:05"),
+			time.Since(op.StartedAt).Round(time.Second), truncate(status, 20), op.Rows)
+	}
+	fmt.Printf("\n%d operation(s). Cancel one: tdtpcli --cancel <ID>\n", len(ops))
+	return nil
+}
+
+// formatOperationProgress — "3/10 (30%)" или шаг/элемент, если счётчиков нет.
+func formatOperationProgress(op operations.Info) string {
+	if op.Total > 0 {
+		return fmt.Sprintf("%d/%d (%.0f%%)", op.Done, op.Total, op.Percent)
+	}
+	if op.Item != "" {
+		return op.Item
+	}
+	if op.Step != "" {
+		return op.Step
+	}
+	return "running"
+}
+
+// CancelOperation запрашивает отмену операции id; её процесс отменяет
+// выполнение в течение секунды (импорт откатывает транзакцию).
+func CancelOperation(dir, id string) error {
+	reg, err := operations.Open(dir)
+	if err != nil {
+		return err
+	}
+	if err := reg.Cancel(id); err != nil {
+		if errors.Is(err, operations.ErrNotFound) {
+			return fmt.Errorf("operation %s is not running (see --operations)", id)
+		}
+		return err
+	}
+	fmt.Printf("✓ Cancellation of %s requested\n", id)
+	return nil
+}
@@ -1,6 +1,10 @@
 package commands
 
-import "context"
+import (
+	"context"
+
+	"github.com/ruslano69/tdtp-framework/pkg/approval"
+)
 
 // opMetricsKey is the context key for the per-invocation OpMetrics side channel.
 type opMetricsKey struct{}
@@ -18,6 +22,11 @@ type opMetricsKey struct{}
 type OpMetrics struct {
 	Resource        string
 	RecordsAffected int64
+
+	// Approval is the decision on a sensitive operation (approval: section),
+	// nil when the operation needed no approval.
+	Approval       *approval.Decision
+	approvalTarget string // operation and target of Approval
 }
 
 // WithOpMetrics attaches a fresh OpMetrics to ctx and returns both — main.go
@@ -38,3 +47,42 @@ func recordOpMetrics(ctx context.Context, resource string, records int64) {
 		m.RecordsAffected = records
 	}
 }
+
+// recordApproval stores the approval decision for the audit entry.
+func recordApproval(ctx context.Context, req *approval.Request, d *approval.Decision) {
+	if m, ok := ctx.Value(opMetricsKey{}).(*OpMetrics); ok {
+		m.Approval = d
+		m.approvalTarget = req.Operation + " " + req.Target
+	}
+}
+
+// recordedApproval returns the decision already taken on req in this
+// invocation (a resilience retry of the same command), nil otherwise.
+func recordedApproval(ctx context.Context, req *approval.Request) *approval.Decision {
+	if m, ok := ctx.Value(opMetricsKey{}).(*OpMetrics); ok && m.approvalTarget == req.Operation+" "+req.Target {
+		return m.Approval
+	}
+	return nil
+}
+
+// ApprovalMetadata returns the audit metadata of the approval decision:
+// request id, status, approver and comment (nil without a decision).
+func (m *OpMetrics) ApprovalMetadata() map[string]string {
+	if m.Approval == nil {
+		return nil
+	}
+	md := map[string]string{
+		"approval_id":     m.Approval.RequestID,
+		"approval_status": string(m.Approval.Status),
+	}
+	if m.Approval.Approver != "" {
+		md["approval_by"] = m.Approval.Approver
+	}
+	if m.Approval.Comment != "" {
+		md["approval_comment"] = m.Approval.Comment
+	}
+	if m.Approval.TimedOut {
+		md["approval_timed_out"] = "true"
+	}
+	return md
+}
@@ -2,7 +2,10 @@ package commands
 
 import (
 	"context"
+	"errors"
 	"testing"
+
+	"github.com/ruslano69/tdtp-framework/pkg/approval"
 )
 
 // TestWithOpMetrics_ReturnsFreshPointer verifies WithOpMetrics attaches a
@@ -67,3 +70,31 @@ func TestRecordOpMetrics_LastWriteWins(t *testing.T) {
 		t.Errorf("last write did not win: %+v", *m)
 	}
 }
+
+// TestAwaitApproval_RetryKeepsDecision covers a resilience retry of the
+// same command: the recorded decision stands and no new request is sent
+// (a nil gate would otherwise fail with "no approval webhook").
+func TestAwaitApproval_RetryKeepsDecision(t *testing.T) {
+	ctx, m := WithOpMetrics(context.Background())
+	req := approval.NewRequest("import", "currencies", "currencies.tdtp.xml")
+
+	recordApproval(ctx, req, &approval.Decision{RequestID: req.ID, Status: approval.StatusApproved, Approver: "ivanov"})
+	if err := awaitApproval(ctx, nil, req); err != nil {
+		t.Fatalf("approved retry: %v", err)
+	}
+	md := m.ApprovalMetadata()
+	if md["approval_by"] != "ivanov" || md["approval_status"] != "approved" || md["approval_id"] != req.ID {
+		t.Errorf("ApprovalMetadata = %v", md)
+	}
+
+	m.Approval.Status = approval.StatusRejected
+	if err := awaitApproval(ctx, nil, req); !errors.Is(err, approval.ErrRejected) {
+		t.Errorf("rejected retry: err = %v, want ErrRejected", err)
+	}
+
+	// A different target does not inherit the decision
+	other := approval.NewRequest("import", "orders", "")
+	if err := awaitApproval(ctx, nil, other); err == nil || errors.Is(err, approval.ErrRejected) {
+		t.Errorf("other target: err = %v, want missing webhook error", err)
+	}
+}
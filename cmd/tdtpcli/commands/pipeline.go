@@ -2,14 +2,20 @@ package commands
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"os"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
 	"github.com/ruslano69/tdtp-framework/pkg/etl"
+	"github.com/ruslano69/tdtp-framework/pkg/history"
 	"github.com/ruslano69/tdtp-framework/pkg/mercury"
+	"github.com/ruslano69/tdtp-framework/pkg/progress"
 	"github.com/ruslano69/tdtp-framework/pkg/resultlog"
 	"github.com/ruslano69/tdtp-framework/pkg/security"
 )
@@ -22,6 +28,8 @@ type PipelineOptions struct {
 	EncryptLegacy  bool              // --enc13: legacy TDTP v1.3 whole-blob формат вместо v1.5
 	EncDev         bool              // --enc-dev: использовать DevClient вместо xZMercury (только !production сборки)
 	Variables      map[string]string // @name=value аргументы из CLI
+	History        *history.Store    // история запусков (nil — не настроена)
+	StatsJSON      string            // --stats-json: файл статистики по шагам ("-" — stdout)
 }
 
 // ExecutePipeline executes an ETL pipeline from YAML configuration file.
@@ -41,7 +49,25 @@ type PipelineOptions struct {
 //   - All SQL queries allowed
 //   - Administrator privileges required
 //   - Use with extreme caution
-func ExecutePipeline(ctx context.Context, configPath string, opts PipelineOptions) error {
+func ExecutePipeline(ctx context.Context, configPath string, opts PipelineOptions) (err error) {
+	// 0. Run history: every execution is recorded, including ones refused below
+	run := history.NewRun(history.KindPipeline, configPath)
+	if opts.History != nil {
+		if data, readErr := os.ReadFile(configPath); readErr == nil {
+			run.ConfigHash = history.HashConfig(data, opts.Variables)
+		}
+		mode := "safe"
+		if opts.Unsafe {
+			mode = "unsafe"
+		}
+		run.Metadata = map[string]string{"config": configPath, "mode": mode}
+		defer func() { recordRun(ctx, opts.History, run, err) }()
+	}
+
+	// --progress-format json: run_started/run_completed вокруг шагов pkg/etl
+	prog := progress.Begin(ctx, "pipeline", configPath)
+	defer func() { prog.End(err, run.RowsWritten) }()
+
 	// 1. Security Check: unsafe mode requires either a capability cert or admin privileges
 	if opts.Unsafe {
 		if err := applyUnsafeGate(opts.UnsafeCertPath); err != nil {
@@ -54,6 +80,10 @@ func ExecutePipeline(ctx context.Context, configPath string, opts PipelineOption
 	if err != nil {
 		return fmt.Errorf("failed to load pipeline config: %w", err)
 	}
+	if config.Name != "" {
+		run.Name = config.Name
+		prog.SetName(config.Name)
+	}
 
 	// 2a. Apply CLI encryption overrides (--enc / --enc13 / --enc-dev переопределяют YAML)
 	if opts.Encrypt || opts.EncDev {
@@ -148,6 +178,14 @@ func ExecutePipeline(ctx context.Context, configPath string, opts PipelineOption
 	// Execute ETL pipeline
 	fmt.Println("Starting ETL pipeline execution...")
 	execErr := processor.Execute(ctx)
+	fillPipelineRun(run, processor)
+
+	// Статистика пишется и при ошибке — по ней видно, на каком шаге упало
+	if opts.StatsJSON != "" {
+		if statsErr := writeStatsJSON(opts.StatsJSON, processor.StatsReport()); statsErr != nil {
+			fmt.Printf("WARNING: failed to write stats: %v\n", statsErr)
+		}
+	}
 
 	// 7. Publish result to Redis if result_log is configured
 	// Published regardless of success or failure — orchestrator tracks both states
@@ -164,6 +202,8 @@ func ExecutePipeline(ctx context.Context, configPath string, opts PipelineOption
 	if execErr != nil && isMercuryDegraded(execErr) {
 		fmt.Printf("WARNING: Encryption degraded: %v\n", execErr)
 		fmt.Println("   Error packet written to output. Pipeline completed with errors (exit 0).")
+		run.Status = history.StatusCompletedWithErrors
+		run.Error = execErr.Error()
 		return nil
 	}
 
@@ -178,6 +218,19 @@ func ExecutePipeline(ctx context.Context, configPath string, opts PipelineOption
 	fmt.Printf("   Sources loaded: %d\n", stats.SourcesLoaded)
 	fmt.Printf("   Rows loaded: %d\n", stats.TotalRowsLoaded)
 	fmt.Printf("   Rows exported: %d\n", stats.TotalRowsExported)
+	if b := stats.Bottleneck(); b != nil {
+		where := b.Step
+		if b.Item != "" {
+			where += " (" + b.Item + ")"
+		}
+		fmt.Printf("   Bottleneck: %s — %s, %.0f%% of run\n", where, b.Duration.Round(time.Millisecond), b.Share*100)
+	}
+	if t := stats.Throttle; t.Throttled > 0 {
+		fmt.Printf("   Backpressure: throttled %s (%d pause(s), %s paused), max queue depth %d\n",
+			t.Throttled.Round(time.Millisecond), t.Pauses, t.Paused.Round(time.Millisecond), t.MaxDepth)
+	}
+	printTimezoneReports(stats.Sources)
+	printUnmappedValues(stats.Unmapped)
 	recordOpMetrics(ctx, configPath, int64(stats.TotalRowsExported))
 	if processor.GetPackageUUID() != "" && config.Output.TDTP != nil && config.Output.TDTP.Encryption {
 		fmt.Printf("   Package UUID: %s\n", processor.GetPackageUUID())
@@ -186,6 +239,92 @@ func ExecutePipeline(ctx context.Context, configPath string, opts PipelineOption
 	return nil
 }
 
+// printTimezoneReports выводит отчёт о переводе naive datetime источников
+// (source.timezone) в UTC с примерами подозрительных значений.
+func printTimezoneReports(sources []etl.StageStats) {
+	for _, src := range sources {
+		tz := src.Timezone
+		if tz == nil {
+			continue
+		}
+		fmt.Printf("   Timezone %s (%s): %d values converted to UTC, %d suspicious\n",
+			src.Name, tz.Location, tz.Converted, tz.Suspicious())
+		kinds := make([]string, 0, len(tz.Issues))
+		for kind := range tz.Issues {
+			kinds = append(kinds, kind)
+		}
+		sort.Strings(kinds)
+		for _, kind := range kinds {
+			fmt.Printf("      %s: %d\n", kind, tz.Issues[kind])
+		}
+		for _, s := range tz.Samples {
+			fmt.Printf("      %s %s = %s → %s\n", s.Kind, s.Field, s.Local, s.UTC)
+		}
+	}
+}
+
+// printUnmappedValues выводит значения, не найденные в таблицах value_mapper.
+func printUnmappedValues(unmapped map[string]map[string]int64) {
+	fields := make([]string, 0, len(unmapped))
+	for field := range unmapped {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		values := make([]string, 0, len(unmapped[field]))
+		for v := range unmapped[field] {
+			values = append(values, v)
+		}
+		sort.Strings(values)
+		fmt.Printf("   Unmapped %s: %d distinct value(s)\n", field, len(values))
+		for _, v := range values {
+			fmt.Printf("      %q: %d\n", v, unmapped[field][v])
+		}
+	}
+}
+
+// fillPipelineRun переносит статистику процессора в запись истории:
+// шаги по порядку, затем источники ("source:<name>").
+func fillPipelineRun(run *history.Run, processor *etl.Processor) {
+	stats := processor.GetStats()
+	run.RowsRead = int64(stats.TotalRowsLoaded)
+	run.RowsWritten = int64(stats.TotalRowsExported)
+	addSteps := func(prefix string, stages []etl.StageStats) {
+		for _, st := range stages {
+			step := history.Step{Name: prefix + st.Name, Rows: int64(st.Rows), DurationMs: st.Duration.Milliseconds()}
+			if st.Error != nil {
+				step.Error = st.Error.Error()
+			}
+			run.Steps = append(run.Steps, step)
+		}
+	}
+	addSteps("", stats.Steps)
+	addSteps("source:", stats.Sources)
+	if run.Metadata != nil {
+		run.Metadata["sources_loaded"] = strconv.Itoa(stats.SourcesLoaded)
+		if uuid := processor.GetPackageUUID(); uuid != "" {
+			run.Metadata["package_uuid"] = uuid
+		}
+	}
+}
+
+// writeStatsJSON записывает отчёт --stats-json в файл или stdout ("-").
+func writeStatsJSON(path string, report etl.StatsReport) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal stats: %w", err)
+	}
+	if path == "-" {
+		fmt.Println(string(data))
+		return nil
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	fmt.Printf("   Stats: %s\n", path)
+	return nil
+}
+
 // isMercuryDegraded возвращает true если ошибка — управляемая деградация xZMercury.
 // В этом случае error-пакет уже записан и pipeline завершается с exit 0.
 func isMercuryDegraded(err error) bool {
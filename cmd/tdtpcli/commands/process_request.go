@@ -9,15 +9,18 @@ import (
 
 	"github.com/ruslano69/tdtp-framework/pkg/adapters"
 	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+	"github.com/ruslano69/tdtp-framework/pkg/security"
 	"gopkg.in/yaml.v3"
 )
 
 // ProcessRequestOptions holds options for process-request operation
 type ProcessRequestOptions struct {
-	RequestFile   string           // Путь к входящему request.tdtp
-	OutputFile    string           // Куда писать response (опционально, иначе авто)
-	ConfigsDir    string           // Директория с конфигами вида {Recipient}.yaml
-	DefaultConfig *adapters.Config // Fallback если {Recipient}.yaml не найден
+	RequestFile   string                     // Путь к входящему request.tdtp
+	OutputFile    string                     // Куда писать response (опционально, иначе авто)
+	ConfigsDir    string                     // Директория с конфигами вида {Recipient}.yaml
+	DefaultConfig *adapters.Config           // Fallback если {Recipient}.yaml не найден
+	Policy        *security.ExportPolicy     // Колонки по получателю ответа (Sender запроса)
+	Exclusions    *adapters.ColumnExclusions // Служебные колонки, не выдаваемые в ответе (export.exclude_columns)
 }
 
 // adapterConfigFromYAML загружает adapters.Config из yaml-файла конфига tdtpcli
@@ -82,6 +85,16 @@ func adapterConfigFromYAML(path string) (*adapters.Config, error) {
 	case "mysql":
 		dsn = fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?parseTime=true",
 			db.User, db.Password, db.Host, db.Port, db.Database)
+	case "mongodb":
+		if db.User == "" {
+			dsn = fmt.Sprintf("mongodb://%s:%d/%s", db.Host, db.Port, db.Database)
+		} else {
+			dsn = fmt.Sprintf("mongodb://%s:%s@%s:%d/%s",
+				db.User, db.Password, db.Host, db.Port, db.Database)
+		}
+	case "oracle":
+		dsn = fmt.Sprintf("oracle://%s:%s@%s:%d/%s",
+			db.User, db.Password, db.Host, db.Port, db.Database)
 	default:
 		return nil, fmt.Errorf("unsupported database type: %s", db.Type)
 	}
@@ -145,8 +158,17 @@ func ProcessRequest(ctx context.Context, opts ProcessRequestOptions) error {
 		return err
 	}
 
-	// 3. Создаём адаптер
-	adapter, err := adapters.New(ctx, *adapterConfig)
+	if err := opts.Exclusions.CheckQuery(tableName, reqPacket.Query); err != nil {
+		return err
+	}
+
+	// 3. Создаём адаптер. Получатель ответа — отправитель запроса: адаптер
+	// отклоняет запрос запрещённых ему колонок и фильтрует ответ по политике.
+	cfg := *adapterConfig
+	if opts.Policy != nil {
+		cfg.ExportPolicy = opts.Policy
+	}
+	adapter, err := adapters.New(ctx, cfg)
 	if err != nil {
 		return fmt.Errorf("failed to create adapter: %w", err)
 	}
@@ -161,7 +183,7 @@ func ProcessRequest(ctx context.Context, opts ProcessRequestOptions) error {
 		packets, err = adapter.ExportTableWithQuery(ctx, tableName, reqPacket.Query, recipient, sender)
 	} else {
 		// Экспорт без фильтров
-		packets, err = adapter.ExportTable(ctx, tableName)
+		packets, err = adapter.ExportTable(adapters.WithRecipient(ctx, sender), tableName)
 	}
 	if err != nil {
 		return fmt.Errorf("query execution failed: %w", err)
@@ -179,6 +201,10 @@ func ProcessRequest(ctx context.Context, opts ProcessRequestOptions) error {
 		pkt.Header.Recipient = sender
 	}
 
+	if err := applyColumnExclusions(opts.Exclusions, packets); err != nil {
+		return err
+	}
+
 	fmt.Printf("  Generated %d response packet(s)\n", len(packets))
 
 	// 6. Определяем выходной файл
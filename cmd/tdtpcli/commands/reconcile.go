@@ -0,0 +1,120 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+	"github.com/ruslano69/tdtp-framework/pkg/sync"
+)
+
+// ReconcileOptions holds options for the --reconcile command.
+type ReconcileOptions struct {
+	TableName     string
+	KeyFields     []string
+	Depth         int    // глубина Merkle-дерева (0 = по умолчанию)
+	OutputFile    string // корректирующие пакеты (пусто — не сохранять)
+	Apply         bool   // применить корректировки к приёмнику (StrategyReplace)
+	StateDir      string // деревья между запусками (пусто — строятся заново)
+	TrackingField string // поле отслеживания изменений для StateDir
+}
+
+// Reconcile сверяет таблицу источника (config) и приёмника (--target-config)
+// через Merkle-деревья хешей строк и выдаёт корректирующие пакеты: строки
+// источника для недостающих/отличающихся ключей. Строки, которые есть
+// только в приёмнике, сохраняются отдельно (<output>_extra) — удаление
+// остаётся решением оператора.
+func Reconcile(ctx context.Context, sourceCfg, targetCfg adapters.Config, opts ReconcileOptions) error {
+	source, err := adapters.New(ctx, sourceCfg)
+	if err != nil {
+		return fmt.Errorf("failed to connect to source: %w", err)
+	}
+	defer func() { _ = source.Close(ctx) }()
+
+	target, err := adapters.New(ctx, targetCfg)
+	if err != nil {
+		return fmt.Errorf("failed to connect to target: %w", err)
+	}
+	defer func() { _ = target.Close(ctx) }()
+
+	fmt.Printf("🔍 Reconciling '%s' (%s → %s)...\n", opts.TableName, sourceCfg.Type, targetCfg.Type)
+	result, err := sync.Reconcile(ctx, source, target, opts.TableName, sync.ReconcileOptions{
+		KeyFields:     opts.KeyFields,
+		Depth:         opts.Depth,
+		StateDir:      opts.StateDir,
+		TrackingField: opts.TrackingField,
+	})
+	if err != nil {
+		return err
+	}
+
+	d := result.Diff
+	fmt.Printf("  Rows:      source %d, target %d\n", result.SourceRows, result.TargetRows)
+	fmt.Printf("  Nodes:     %d compared, %d leaf range(s) diverged\n", d.NodesCompared, d.LeavesDiverged)
+	if d.InSync() {
+		fmt.Printf("✅ Tables are in sync\n")
+		return nil
+	}
+	fmt.Printf("  Missing:   %d\n", len(d.Missing))
+	fmt.Printf("  Changed:   %d\n", len(d.Changed))
+	fmt.Printf("  Extra:     %d (only in target)\n", len(d.Extra))
+
+	generator := packet.NewGenerator()
+	var upserts []*packet.DataPacket
+	if len(result.Upserts) > 0 {
+		if upserts, err = generator.GenerateReference(opts.TableName, result.Schema, result.Upserts); err != nil {
+			return fmt.Errorf("failed to build corrective packets: %w", err)
+		}
+	}
+
+	if opts.OutputFile != "" {
+		if err := writeReconcilePackets(generator, upserts, opts.OutputFile); err != nil {
+			return err
+		}
+		if len(result.Extras) > 0 {
+			extras, err := generator.GenerateReference(opts.TableName, result.Schema, result.Extras)
+			if err != nil {
+				return fmt.Errorf("failed to build extra-row packets: %w", err)
+			}
+			ext := filepath.Ext(opts.OutputFile)
+			extraFile := strings.TrimSuffix(opts.OutputFile, ext) + "_extra" + ext
+			if err := writeReconcilePackets(generator, extras, extraFile); err != nil {
+				return err
+			}
+		}
+	}
+
+	if opts.Apply && len(upserts) > 0 {
+		if err := target.ImportPackets(ctx, upserts, adapters.StrategyReplace); err != nil {
+			return fmt.Errorf("failed to apply corrections: %w", err)
+		}
+		if err := result.MarkApplied(); err != nil {
+			return err
+		}
+		fmt.Printf("✅ Applied %d corrective row(s) to target\n", len(result.Upserts))
+	} else if len(upserts) > 0 && opts.OutputFile == "" {
+		fmt.Printf("  ℹ Use --reconcile-apply to repair the target or --output to save corrective packets\n")
+	}
+	if len(d.Extra) > 0 {
+		fmt.Printf("  ⚠ %d row(s) exist only in target and were not removed\n", len(d.Extra))
+	}
+	return nil
+}
+
+// writeReconcilePackets сохраняет пакеты в файл (или части <file>_part_N_of_M).
+func writeReconcilePackets(generator *packet.Generator, packets []*packet.DataPacket, file string) error {
+	for i, pkt := range packets {
+		name := file
+		if len(packets) > 1 {
+			name = generatePacketFilename(file, i+1, len(packets))
+		}
+		if err := generator.WriteToFile(pkt, name); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+		fmt.Printf("  → %s (%d rows)\n", name, pkt.Header.RecordsInPart)
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+	"github.com/ruslano69/tdtp-framework/pkg/processors"
+	"github.com/ruslano69/tdtp-framework/pkg/sampling"
+	"gopkg.in/yaml.v3"
+)
+
+// SampleFile — YAML-описание выборки для --export-sample.
+//
+//	seed: 42
+//	tables:
+//	  - name: orders
+//	    rows: 1000
+//	    stratify_by: status
+//	  - name: customers        # rows: 0 — только клиенты, на которых ссылаются заказы
+//	relations:                 # необязательно: по умолчанию — FK из БД
+//	  - {table: orders, column: customer_id, ref_table: customers, ref_column: id}
+//	processors:                # применяются к каждой таблице
+//	  - type: field_pseudonymizer
+//	    params: {fields: [id, customer_id], secret_env: TDTP_PSEUDO_SECRET}
+//	  - type: field_masker
+//	    params: {fields: {email: partial}}
+type SampleFile struct {
+	sampling.Config `yaml:",inline"`
+	Processors      []processors.Config `yaml:"processors,omitempty"`
+}
+
+// SampleOptions — параметры команды --export-sample.
+type SampleOptions struct {
+	ConfigFile   string
+	OutputDir    string // каталог для <table>.tdtp.xml (по умолчанию — текущий)
+	ProcessorMgr ProcessorManager
+}
+
+// LoadSampleFile читает YAML-описание выборки.
+func LoadSampleFile(path string) (*SampleFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sample config: %w", err)
+	}
+	var sf SampleFile
+	if err := yaml.Unmarshal(data, &sf); err != nil {
+		return nil, fmt.Errorf("failed to parse sample config: %w", err)
+	}
+	return &sf, nil
+}
+
+// ExportSample выгружает согласованную выборку из нескольких таблиц:
+// случайные строки плюс всё, на что они ссылаются, пропущенные через
+// процессоры из файла и --mask/--normalize/--validate. Каждая таблица
+// пишется в отдельный файл <output>/<table>.tdtp.xml.
+func ExportSample(ctx context.Context, config *adapters.Config, opts SampleOptions) error {
+	sf, err := LoadSampleFile(opts.ConfigFile)
+	if err != nil {
+		return err
+	}
+
+	// Процессоры создаём до подключения к БД: ошибка в конфиге (например,
+	// пустой secret_env) не должна стоить полного чтения таблиц.
+	chain, err := processors.CreateChainFromConfigs(sf.Processors)
+	if err != nil {
+		return fmt.Errorf("failed to configure processors: %w", err)
+	}
+
+	adapter, err := adapters.New(ctx, *config)
+	if err != nil {
+		return fmt.Errorf("failed to connect: %w", err)
+	}
+	defer func() { _ = adapter.Close(ctx) }()
+
+	fmt.Printf("Sampling %d table(s)...\n", len(sf.Tables))
+	samples, err := sampling.Sample(ctx, adapter, sf.Config)
+	if err != nil {
+		return fmt.Errorf("sampling failed: %w", err)
+	}
+
+	outDir := opts.OutputDir
+	if outDir == "" {
+		outDir = "."
+	}
+	generator := packet.NewGenerator()
+	var totalRows int64
+
+	for _, s := range samples {
+		rows, err := chain.Process(ctx, s.Rows, s.Schema)
+		if err != nil {
+			return fmt.Errorf("processing %s failed: %w", s.Table, err)
+		}
+
+		packets, err := generator.GenerateReference(s.Table, s.Schema, rows)
+		if err != nil {
+			return fmt.Errorf("failed to generate packets for %s: %w", s.Table, err)
+		}
+
+		file := filepath.Join(outDir, s.Table+".tdtp.xml")
+		for i, pkt := range packets {
+			if opts.ProcessorMgr != nil && opts.ProcessorMgr.HasProcessors() {
+				if err := opts.ProcessorMgr.ProcessPacket(ctx, pkt); err != nil {
+					return fmt.Errorf("processing %s failed: %w", s.Table, err)
+				}
+			}
+			name := file
+			if len(packets) > 1 {
+				name = generatePacketFilename(file, i+1, len(packets))
+			}
+			if err := writePacketToFile(pkt, name); err != nil {
+				return fmt.Errorf("failed to write %s: %w", name, err)
+			}
+		}
+
+		fmt.Printf("✓ %s: %d rows (%d sampled, %d referenced) → %s\n",
+			s.Table, len(rows), s.Sampled, s.Referenced, file)
+		totalRows += int64(len(rows))
+	}
+
+	recordOpMetrics(ctx, "sample", totalRows)
+	return nil
+}
@@ -4,10 +4,14 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	stdsync "sync"
 	"time"
 
 	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+	"github.com/ruslano69/tdtp-framework/pkg/adapters/base"
+	"github.com/ruslano69/tdtp-framework/pkg/audit"
 	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+	"github.com/ruslano69/tdtp-framework/pkg/history"
 	"github.com/ruslano69/tdtp-framework/pkg/sync"
 )
 
@@ -18,14 +22,54 @@ type SyncOptions struct {
 	TrackingField  string
 	CheckpointFile string
 	BatchSize      int
+	Parallelism    int      // Packet files written concurrently (0/1 = one at a time)
 	Fields         []string // Column projection; tracking field is always included automatically
 	ProcessorMgr   ProcessorManager
+	History        *history.Store             // nil = history not configured
+	TableQueries   map[string]string          // Custom SELECT per table (export.table_queries)
+	Exclusions     *adapters.ColumnExclusions // Columns never shipped (export.exclude_columns)
+	Deletes        sync.DeleteDetection       // Delete propagation (--sync-deletes); zero = deletes are not synced
+	CDCSlot        string                     // Logical replication slot (--sync-cdc); set = changes come from the source's change stream
+	ChangeTracking bool                       // SQL Server Change Tracking (--sync-change-tracking) instead of a tracking field
+	SchemaDrift    sync.SchemaDriftPolicy     // Reaction to a source schema change (--sync-schema-drift); "" = pause
+	Target         *adapters.Config           // Target database migrated by --sync-schema-drift migrate (--target-config)
+	Audit          audit.Logger               // nil = schema changes are not audited
+}
+
+// changeStream returns the incremental config when the source itself
+// supplies changes, deletes and the checkpoint (--sync-cdc,
+// --sync-change-tracking); ok is false for tracking-field sync.
+func (o SyncOptions) changeStream(lastSyncValue string) (cfg adapters.IncrementalConfig, ok bool) {
+	switch {
+	case o.CDCSlot != "":
+		cfg = sync.EnableIncrementalSync("")
+		cfg.Strategy = sync.TrackingCDC
+		cfg.Slot = o.CDCSlot
+	case o.ChangeTracking:
+		cfg = sync.EnableIncrementalSync("")
+		cfg.Strategy = sync.TrackingChangeTable
+	default:
+		return cfg, false
+	}
+	cfg.InitialValue = lastSyncValue
+	cfg.BatchSize = o.BatchSize
+	return cfg, true
 }
 
 // IncrementalSync performs incremental synchronization of a table
 func IncrementalSync(ctx context.Context, config *adapters.Config, opts SyncOptions) error {
+	if err := opts.validate(); err != nil {
+		return err
+	}
+
 	fmt.Printf("Starting incremental sync for table '%s'...\n", opts.TableName)
-	fmt.Printf("Tracking field: %s\n", opts.TrackingField)
+	if opts.CDCSlot != "" {
+		fmt.Printf("Change capture: replication slot %s\n", opts.CDCSlot)
+	} else if opts.ChangeTracking {
+		fmt.Printf("Change capture: SQL Server change tracking\n")
+	} else {
+		fmt.Printf("Tracking field: %s\n", opts.TrackingField)
+	}
 	fmt.Printf("Checkpoint file: %s\n", opts.CheckpointFile)
 
 	// Initialize state manager
@@ -36,31 +80,86 @@ func IncrementalSync(ctx context.Context, config *adapters.Config, opts SyncOpti
 
 	// Get last sync state
 	state := stateMgr.GetState(opts.TableName)
-	var lastSyncValue string
 	if state.LastSyncValue != "" {
-		lastSyncValue = state.LastSyncValue
 		fmt.Printf("Last sync: %s (value: %s)\n",
 			state.LastSyncTime.Format("2006-01-02 15:04:05"),
-			lastSyncValue)
+			state.LastSyncValue)
 	} else {
 		fmt.Printf("First sync - will export all records\n")
 	}
 
+	result, err := syncOnce(ctx, config, opts, state)
+	if err != nil {
+		if stateErr := stateMgr.UpdateStateWithError(opts.TableName, err); stateErr != nil {
+			fmt.Printf("⚠ Warning: failed to save error state: %v\n", stateErr)
+		}
+		return err
+	}
+
+	// Update sync state with the new checkpoints
+	if result.LastSyncValue != state.LastSyncValue {
+		if err := stateMgr.UpdateState(opts.TableName, result.LastSyncValue, result.Records); err != nil {
+			fmt.Printf("⚠ Warning: failed to update sync state: %v\n", err)
+		} else {
+			fmt.Printf("✓ Checkpoint updated: %s\n", result.LastSyncValue)
+		}
+	}
+	if result.Schema != nil && sync.SchemaHash(*result.Schema) != state.SchemaHash {
+		if err := stateMgr.UpdateSchemaState(opts.TableName, *result.Schema); err != nil {
+			fmt.Printf("⚠ Warning: failed to save source schema: %v\n", err)
+		}
+	}
+	if result.LastDeleteValue != state.LastDeleteValue {
+		if err := stateMgr.UpdateDeleteState(opts.TableName, result.LastDeleteValue); err != nil {
+			fmt.Printf("⚠ Warning: failed to update delete log checkpoint: %v\n", err)
+		} else {
+			fmt.Printf("✓ Delete log checkpoint updated: %s\n", result.LastDeleteValue)
+		}
+	}
+	return nil
+}
+
+// validate rejects option combinations the sync cannot honour.
+func (o SyncOptions) validate() error {
+	_, stream := o.changeStream("")
+	if o.CDCSlot != "" && o.ChangeTracking {
+		return UsageErrorf("--sync-cdc and --sync-change-tracking are mutually exclusive")
+	}
+	if stream && (o.Deletes.Strategy != sync.DeleteNone || len(o.Fields) > 0) {
+		return UsageErrorf("--sync-cdc and --sync-change-tracking capture whole rows and deletes from the source: --sync-deletes and --fields are not supported")
+	}
+	return nil
+}
+
+// syncOnce exports the changes after the checkpoints of state and writes
+// them to opts.OutputFile. It returns the new checkpoints; saving them is
+// up to the caller (IncrementalSync, the --daemon scheduler), so a failed
+// run is re-read next time.
+func syncOnce(ctx context.Context, config *adapters.Config, opts SyncOptions, state *sync.SyncState) (result sync.SyncResult, err error) {
+	run := history.NewRun(history.KindSync, opts.TableName)
+	run.Metadata = map[string]string{"tracking_field": opts.TrackingField, "checkpoint_file": opts.CheckpointFile}
+	switch {
+	case opts.CDCSlot != "":
+		run.Metadata = map[string]string{"cdc_slot": opts.CDCSlot, "checkpoint_file": opts.CheckpointFile}
+	case opts.ChangeTracking:
+		run.Metadata = map[string]string{"change_tracking": "true", "checkpoint_file": opts.CheckpointFile}
+	}
+	if opts.History != nil {
+		defer func() { recordRun(ctx, opts.History, run, err) }()
+	}
+	_, stream := opts.changeStream("")
+	lastSyncValue := state.LastSyncValue
+	result = sync.SyncResult{LastSyncValue: lastSyncValue, LastDeleteValue: state.LastDeleteValue}
+
 	// Build TDTQL query for incremental sync
 	query := buildIncrementalQuery(opts.TrackingField, lastSyncValue, opts.BatchSize)
 
 	// Apply column projection if requested, auto-including the tracking field
+	// (and the soft-delete flag, which decides where a row goes)
 	if len(opts.Fields) > 0 {
-		fields := opts.Fields
-		hasTracking := false
-		for _, f := range fields {
-			if strings.EqualFold(f, opts.TrackingField) {
-				hasTracking = true
-				break
-			}
-		}
-		if !hasTracking {
-			fields = append(fields, opts.TrackingField)
+		fields := withField(opts.Fields, opts.TrackingField)
+		if opts.Deletes.Strategy == sync.DeleteSoft {
+			fields = withField(fields, opts.Deletes.Field)
 		}
 		if query == nil {
 			query = packet.NewQuery()
@@ -71,31 +170,68 @@ func IncrementalSync(ctx context.Context, config *adapters.Config, opts SyncOpti
 	// Create adapter
 	adapter, err := adapters.New(ctx, *config)
 	if err != nil {
-		return fmt.Errorf("failed to create adapter: %w", err)
+		return sync.SyncResult{}, fmt.Errorf("failed to create adapter: %w", err)
 	}
 	defer func() { _ = adapter.Close(ctx) }()
 
+	// С собственным запросом таблицы условие по tracking field применяется в памяти
+	if err := applyTableQueries(adapter, opts.TableName, opts.TableQueries); err != nil {
+		return sync.SyncResult{}, err
+	}
+
+	// A changed source schema is handled before any packet is written
+	var fields []string
+	if query != nil {
+		fields = query.Fields
+	}
+	schema, err := checkSchemaDrift(ctx, adapter, opts, state, fields)
+	if err != nil {
+		return sync.SyncResult{}, err
+	}
+	result.Schema = schema
+
 	fmt.Printf("Exporting incremental changes...\n")
 
 	// Export with incremental query
 	var packets []*packet.DataPacket
-	if query != nil {
+	var streamCheckpoint string
+	exportStart := time.Now()
+	if cfg, ok := opts.changeStream(lastSyncValue); ok {
+		// Changes after the saved checkpoint (LSN, change tracking version);
+		// the checkpoint is saved only after the packets are written, so a
+		// failed run is re-read next time
+		packets, streamCheckpoint, err = adapter.ExportTableIncremental(ctx, opts.TableName, cfg)
+	} else if query != nil {
 		packets, err = adapter.ExportTableWithQuery(ctx, opts.TableName, query, "tdtpcli", "")
 	} else {
 		packets, err = adapter.ExportTable(ctx, opts.TableName)
 	}
 
 	if err != nil {
-		// Update state with error
-		if stateErr := stateMgr.UpdateStateWithError(opts.TableName, err); stateErr != nil {
-			fmt.Printf("⚠ Warning: failed to save error state: %v\n", stateErr)
+		return sync.SyncResult{}, fmt.Errorf("export failed: %w", err)
+	}
+
+	// The delete log is read from its own checkpoint
+	var tombstones []*packet.DataPacket
+	newLastDeleteValue := state.LastDeleteValue
+	if opts.Deletes.Strategy == sync.DeleteLog {
+		tombstones, newLastDeleteValue, err = readDeleteLog(ctx, adapter, opts, state.LastDeleteValue)
+		if err != nil {
+			return sync.SyncResult{}, err
 		}
-		return fmt.Errorf("export failed: %w", err)
 	}
 
-	if len(packets) == 0 {
+	if stream {
+		packets, tombstones = base.SplitDeletePackets(packets)
+	}
+
+	if len(packets) == 0 && len(tombstones) == 0 {
 		fmt.Println("✓ No new changes to sync")
-		return nil
+		// Transactions of other tables still move the source checkpoint
+		if streamCheckpoint != "" {
+			result.LastSyncValue = streamCheckpoint
+		}
+		return result, nil
 	}
 
 	fmt.Printf("✓ Exported %d packet(s)\n", len(packets))
@@ -106,23 +242,58 @@ func IncrementalSync(ctx context.Context, config *adapters.Config, opts SyncOpti
 		totalRows += int64(len(pkt.Data.Rows))
 	}
 	fmt.Printf("✓ Total rows: %d\n", totalRows)
+	readRows := totalRows
+	run.RowsRead = totalRows
+	run.AddStep("export", totalRows, time.Since(exportStart))
+
+	// Extract new last sync value from the data (soft-deleted rows included)
+	newLastSyncValue := lastSyncValue
+	if stream {
+		newLastSyncValue = streamCheckpoint
+	} else if len(packets) > 0 {
+		newLastSyncValue, err = extractLastSyncValue(packets, opts.TrackingField)
+		if err != nil {
+			return sync.SyncResult{}, fmt.Errorf("failed to extract last sync value: %w", err)
+		}
+	}
+
+	// Soft-deleted rows leave the data packets and travel as tombstones
+	if opts.Deletes.Strategy == sync.DeleteSoft {
+		var deleted int
+		packets, deleted, err = sync.SplitSoftDeletedPackets(packets, opts.Deletes)
+		if err != nil {
+			return sync.SyncResult{}, fmt.Errorf("soft delete detection failed: %w", err)
+		}
+		totalRows -= int64(deleted)
+		packets, tombstones = base.SplitDeletePackets(packets)
+	}
+	deletedRows := int64(0)
+	for _, pkt := range tombstones {
+		deletedRows += int64(len(pkt.Data.Rows))
+	}
+	if deletedRows > 0 {
+		fmt.Printf("✓ Deleted rows: %d\n", deletedRows)
+	}
+
+	// Excluded columns leave after the checkpoint is taken: the tracking
+	// field (e.g. rowversion) may itself be one of them
+	if err := applyColumnExclusions(opts.Exclusions, packets); err != nil {
+		return sync.SyncResult{}, err
+	}
 
-	// Apply data processors if configured
+	// Apply data processors if configured (tombstones carry only keys)
 	if opts.ProcessorMgr != nil && opts.ProcessorMgr.HasProcessors() {
 		fmt.Printf("Applying data processors...\n")
+		processStart := time.Now()
 		for _, pkt := range packets {
 			if err := opts.ProcessorMgr.ProcessPacket(ctx, pkt); err != nil {
-				return fmt.Errorf("processor failed: %w", err)
+				return sync.SyncResult{}, fmt.Errorf("processor failed: %w", err)
 			}
 		}
+		run.AddStep("processors", totalRows, time.Since(processStart))
 		fmt.Printf("✓ Data processors applied\n")
 	}
-
-	// Extract new last sync value from the data
-	newLastSyncValue, err := extractLastSyncValue(packets, opts.TrackingField)
-	if err != nil {
-		return fmt.Errorf("failed to extract last sync value: %w", err)
-	}
+	packets = append(packets, tombstones...)
 
 	// Write packets to file(s)
 	outputFile := opts.OutputFile
@@ -132,37 +303,171 @@ func IncrementalSync(ctx context.Context, config *adapters.Config, opts SyncOpti
 		outputFile = fmt.Sprintf("%s_sync_%s.xml", opts.TableName, timestamp)
 	}
 
+	writeStart := time.Now()
 	if len(packets) == 1 {
 		// Single file
 		if err := writePacketToFile(packets[0], outputFile); err != nil {
-			return err
+			return sync.SyncResult{}, err
 		}
 		fmt.Printf("✓ Written to: %s\n", outputFile)
-	} else {
-		// Multiple files (packets)
-		for i, pkt := range packets {
-			filename := generatePacketFilename(outputFile, i+1, len(packets))
-			if err := writePacketToFile(pkt, filename); err != nil {
-				return err
-			}
-			fmt.Printf("✓ Written packet %d/%d to: %s\n", i+1, len(packets), filename)
-		}
+	} else if err := writeSyncPackets(packets, outputFile, opts.Parallelism); err != nil {
+		return sync.SyncResult{}, err
 	}
 
-	// Update sync state with new last value
-	if err := stateMgr.UpdateState(opts.TableName, newLastSyncValue, totalRows); err != nil {
-		fmt.Printf("⚠ Warning: failed to update sync state: %v\n", err)
-	} else {
-		fmt.Printf("✓ Checkpoint updated: %s\n", newLastSyncValue)
-	}
+	run.RowsWritten = totalRows
+	run.AddStep("write", totalRows, time.Since(writeStart))
 
 	fmt.Printf("✓ Incremental sync complete!\n")
 	fmt.Printf("  Records synced: %d\n", totalRows)
+	if opts.Deletes.Strategy != sync.DeleteNone {
+		fmt.Printf("  Records deleted: %d\n", deletedRows)
+	}
 	fmt.Printf("  New checkpoint: %s\n", newLastSyncValue)
 
+	return sync.SyncResult{
+		LastSyncValue:   newLastSyncValue,
+		LastDeleteValue: newLastDeleteValue,
+		Records:         totalRows,
+		Schema:          schema,
+		More:            !stream && opts.BatchSize > 0 && readRows >= int64(opts.BatchSize),
+	}, nil
+}
+
+// checkSchemaDrift compares the source schema of the table (the synced
+// fields only, if set, without excluded columns) with the one saved by the
+// last run and applies opts.SchemaDrift to a change. It returns the schema
+// to save with the checkpoints.
+func checkSchemaDrift(ctx context.Context, source adapters.Adapter, opts SyncOptions, state *sync.SyncState, fields []string) (*packet.Schema, error) {
+	current, err := source.GetTableSchema(ctx, opts.TableName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read source schema: %w", err)
+	}
+	// Only columns that reach the packets count: excluded ones may change freely
+	synced := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		synced[strings.ToLower(f)] = true
+	}
+	projected := packet.Schema{}
+	for _, f := range current.Fields {
+		if (len(synced) == 0 || synced[strings.ToLower(f.Name)]) && !opts.Exclusions.Excluded(opts.TableName, f.Name) {
+			projected.Fields = append(projected.Fields, f)
+		}
+	}
+	current = projected
+	// The first run (or a checkpoint without a schema) records the baseline
+	if state.SchemaHash == "" || state.SchemaHash == sync.SchemaHash(current) {
+		return &current, nil
+	}
+	drift := sync.DiffSchema(packet.Schema{Fields: state.Schema}, current)
+	if drift.Empty() {
+		return &current, nil // columns reordered
+	}
+
+	policy := opts.SchemaDrift
+	if policy == "" {
+		policy = sync.DriftPause
+	}
+	fmt.Printf("  ⚠ Source schema of '%s' changed: %s\n", opts.TableName, drift)
+	err = applySchemaDrift(ctx, opts, policy, drift)
+	if opts.Audit != nil {
+		_ = opts.Audit.Log(context.WithoutCancel(ctx), sync.SchemaDriftEntry(opts.TableName, drift, policy, err)) //nolint:errcheck // audit does not stop the sync
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &current, nil
+}
+
+// applySchemaDrift handles a source schema change by policy; an error
+// pauses the sync: the checkpoint and the saved schema stay, so every run
+// stops here until the change is migrated or accepted.
+func applySchemaDrift(ctx context.Context, opts SyncOptions, policy sync.SchemaDriftPolicy, drift sync.SchemaDrift) error {
+	switch policy {
+	case sync.DriftAccept:
+		fmt.Printf("  New schema accepted, target not changed\n")
+		return nil
+	case sync.DriftMigrate:
+		if opts.Target == nil {
+			return fmt.Errorf("%w: %s: %s (--sync-schema-drift migrate requires --target-config)", sync.ErrSchemaDrift, opts.TableName, drift)
+		}
+		target, err := adapters.New(ctx, *opts.Target)
+		if err != nil {
+			return fmt.Errorf("failed to connect to target: %w", err)
+		}
+		defer func() { _ = target.Close(ctx) }()
+		added, err := sync.MigrateSchema(ctx, target, opts.TableName, drift)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✓ Target migrated: %d column(s) added to %s\n", len(added), opts.TableName)
+		return nil
+	}
+	return fmt.Errorf("%w: %s: %s (sync paused: update the target, then rerun with --sync-schema-drift migrate or accept)",
+		sync.ErrSchemaDrift, opts.TableName, drift)
+}
+
+// writeSyncPackets writes packets to numbered files, up to parallelism at a
+// time; the first error is returned after the started writes finish.
+func writeSyncPackets(packets []*packet.DataPacket, outputFile string, parallelism int) error {
+	parallelism = max(parallelism, 1)
+	sem := make(chan struct{}, parallelism)
+	errs := make([]error, len(packets))
+	var wg stdsync.WaitGroup
+	for i, pkt := range packets {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int, pkt *packet.DataPacket) {
+			defer func() { <-sem; wg.Done() }()
+			filename := generatePacketFilename(outputFile, i+1, len(packets))
+			if errs[i] = writePacketToFile(pkt, filename); errs[i] == nil {
+				fmt.Printf("✓ Written packet %d/%d to: %s\n", i+1, len(packets), filename)
+			}
+		}(i, pkt)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// withField returns fields with name appended unless already present
+func withField(fields []string, name string) []string {
+	for _, f := range fields {
+		if strings.EqualFold(f, name) {
+			return fields
+		}
+	}
+	return append(fields, name)
+}
+
+// readDeleteLog reads the delete log after the given checkpoint and returns
+// tombstone packets for the table plus the new delete log checkpoint
+func readDeleteLog(ctx context.Context, adapter adapters.Adapter, opts SyncOptions, after string) ([]*packet.DataPacket, string, error) {
+	schema, err := adapter.GetTableSchema(ctx, opts.TableName)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get schema of %s: %w", opts.TableName, err)
+	}
+	var keyFields []packet.Field
+	for _, f := range schema.Fields {
+		if f.Key {
+			keyFields = append(keyFields, f)
+		}
+	}
+
+	keys, last, err := sync.ReadDeleteLog(ctx, adapter, opts.Deletes, opts.TrackingField, keyFields, after, opts.BatchSize)
+	if err != nil {
+		return nil, "", err
+	}
+	tombstones, err := sync.TombstonePackets(opts.TableName, schema, keys)
+	if err != nil {
+		return nil, "", err
+	}
+	return tombstones, last, nil
+}
+
 // buildIncrementalQuery builds TDTQL query for incremental sync
 func buildIncrementalQuery(trackingField, lastSyncValue string, batchSize int) *packet.Query {
 	query := packet.NewQuery()
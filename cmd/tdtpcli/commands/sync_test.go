@@ -1,6 +1,8 @@
 package commands
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
@@ -239,3 +241,33 @@ func TestExtractLastSyncValue(t *testing.T) {
 		})
 	}
 }
+
+func TestWriteSyncPackets_Parallel(t *testing.T) {
+	schema := packet.Schema{Fields: []packet.Field{{Name: "id", Type: "INTEGER", Key: true}}}
+	var packets []*packet.DataPacket
+	for i := 0; i < 5; i++ {
+		pkt := packet.NewDataPacket(packet.TypeReference, "orders")
+		pkt.Schema = schema
+		pkt.Data = packet.Data{Rows: []packet.Row{{Value: "1"}}}
+		packets = append(packets, pkt)
+	}
+
+	out := filepath.Join(t.TempDir(), "orders_sync.xml")
+	if err := writeSyncPackets(packets, out, 3); err != nil {
+		t.Fatalf("writeSyncPackets: %v", err)
+	}
+	for i := 1; i <= len(packets); i++ {
+		if _, err := os.Stat(generatePacketFilename(out, i, len(packets))); err != nil {
+			t.Errorf("packet %d: %v", i, err)
+		}
+	}
+
+	// Ошибка записи любой части возвращается: родитель пути — обычный файл
+	blocker := filepath.Join(t.TempDir(), "blocker")
+	if err := os.WriteFile(blocker, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeSyncPackets(packets, filepath.Join(blocker, "y.xml"), 3); err == nil {
+		t.Error("expected error writing under a file")
+	}
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/ruslano69/tdtp-framework/pkg/adapters"
 	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+	"github.com/ruslano69/tdtp-framework/pkg/core/schema"
 	"github.com/ruslano69/tdtp-framework/pkg/core/tdtql"
 	"github.com/ruslano69/tdtp-framework/pkg/storage"
 	"github.com/ruslano69/tdtp-framework/pkg/xlsx"
@@ -30,6 +31,53 @@ type XLSXOptions struct {
 	// MercuryURL enables full executor verification for v1.4 packets.
 	// Empty → local xxh3 integrity check only (FallbackDegrade policy).
 	MercuryURL string
+
+	// OriginalFile is the TDTP export the XLSX was made from (--xlsx-original).
+	// Non-empty → --from-xlsx / --import-xlsx produce/apply only the edited
+	// cells as an update-only delta packet instead of the whole sheet.
+	OriginalFile string
+
+	// NumberFormat is the locale of numbers stored as text cells (--number-format:
+	// ru, fr, de, en, ch; see schema.LookupNumberFormat). Empty → taken as is.
+	NumberFormat string
+
+	// Booleans maps BOOLEAN text cells (--booleans "Y,Да/N,Нет").
+	Booleans string
+
+	// DateFormats is the order of text dates (--date-format "dmy,shipped=mdy":
+	// dmy, mdy, ymd, auto; see schema.ParseDateFormats). Empty → taken as is.
+	DateFormats string
+
+	// Exclusions are columns never exported (export.exclude_columns).
+	Exclusions *adapters.ColumnExclusions
+}
+
+// readXLSX reads opts.InputFile with the configured import options
+func readXLSX(opts XLSXOptions) (*packet.DataPacket, error) {
+	var importOpts xlsx.ImportOptions
+	if opts.NumberFormat != "" {
+		nf, err := schema.LookupNumberFormat(opts.NumberFormat)
+		if err != nil {
+			return nil, err
+		}
+		importOpts.NumberFormat = &nf
+	}
+	if opts.Booleans != "" {
+		m, err := schema.ParseBoolMapping(opts.Booleans)
+		if err != nil {
+			return nil, err
+		}
+		importOpts.Booleans = &m
+	}
+	if opts.DateFormats != "" {
+		dfs, err := schema.ParseDateFormats(opts.DateFormats)
+		if err != nil {
+			return nil, err
+		}
+		importOpts.Dates = &dfs
+		importOpts.Warn = func(msg string) { fmt.Printf("  ⚠ %s\n", msg) }
+	}
+	return xlsx.FromXLSXWithOptions(opts.InputFile, opts.SheetName, importOpts)
 }
 
 // ConvertTDTPToXLSX converts a TDTP XML file to XLSX
@@ -123,8 +171,9 @@ func ConvertTDTPToXLSX(ctx context.Context, opts XLSXOptions) error {
 		if err != nil {
 			return fmt.Errorf("failed to apply query filters: %w", err)
 		}
-		pkt.SetRows(execResult.FilteredRows)
-		fmt.Printf("✓ Filtered: %d row(s) matched\n", len(execResult.FilteredRows))
+		pkt.Schema = execResult.Schema // --fields
+		pkt.SetRows(execResult.Rows)
+		fmt.Printf("✓ Filtered: %d row(s) matched\n", len(execResult.Rows))
 	}
 
 	// Determine local output path (temp file when uploading to S3)
@@ -167,7 +216,7 @@ func ConvertXLSXToTDTP(opts XLSXOptions) error {
 	fmt.Printf("Sheet: %s\n", opts.SheetName)
 
 	// Convert from XLSX
-	pkt, err := xlsx.FromXLSX(opts.InputFile, opts.SheetName)
+	pkt, err := readXLSX(opts)
 	if err != nil {
 		return fmt.Errorf("conversion failed: %w", err)
 	}
@@ -177,6 +226,17 @@ func ConvertXLSXToTDTP(opts XLSXOptions) error {
 	fmt.Printf("✓ Schema: %d field(s)\n", len(pkt.Schema.Fields))
 	fmt.Printf("✓ Data: %d row(s)\n", len(pkt.Data.Rows))
 
+	// Only edited cells: delta packet against the original export
+	if opts.OriginalFile != "" {
+		pkt, err = xlsxEditsPacket(opts.OriginalFile, pkt)
+		if err != nil {
+			return err
+		}
+		if pkt == nil {
+			return nil
+		}
+	}
+
 	// Marshal to XML
 	generator := packet.NewGenerator()
 	xml, err := generator.ToXML(pkt, true)
@@ -218,6 +278,10 @@ func ExportTableToXLSX(ctx context.Context, config *adapters.Config, opts XLSXOp
 
 	fmt.Printf("Exporting table '%s' to XLSX...\n", opts.TableName)
 
+	if err := opts.Exclusions.CheckQuery(opts.TableName, opts.Query); err != nil {
+		return err
+	}
+
 	// Export data
 	var packets []*packet.DataPacket
 	if opts.Query != nil {
@@ -238,6 +302,10 @@ func ExportTableToXLSX(ctx context.Context, config *adapters.Config, opts XLSXOp
 
 	fmt.Printf("✓ Exported %d packet(s)\n", len(packets))
 
+	if err := applyColumnExclusions(opts.Exclusions, packets); err != nil {
+		return err
+	}
+
 	// Merge all packets into the first one (XLSX has no size limits unlike TDTP parts)
 	pkt := packets[0]
 	for _, extra := range packets[1:] {
@@ -302,7 +370,7 @@ func ImportXLSXToTable(ctx context.Context, config *adapters.Config, opts XLSXOp
 	fmt.Printf("Strategy: %s\n", opts.Strategy)
 
 	// Convert from XLSX
-	pkt, err := xlsx.FromXLSX(opts.InputFile, opts.SheetName)
+	pkt, err := readXLSX(opts)
 	if err != nil {
 		return fmt.Errorf("failed to parse XLSX: %w", err)
 	}
@@ -321,6 +389,17 @@ func ImportXLSXToTable(ctx context.Context, config *adapters.Config, opts XLSXOp
 		fmt.Printf("✓ Data processors applied\n")
 	}
 
+	// Only edited cells: UPDATEs by key instead of importing the whole sheet
+	if opts.OriginalFile != "" {
+		pkt, err = xlsxEditsPacket(opts.OriginalFile, pkt)
+		if err != nil {
+			return err
+		}
+		if pkt == nil {
+			return nil
+		}
+	}
+
 	// Create adapter
 	adapter, err := adapters.New(ctx, *config)
 	if err != nil {
@@ -339,6 +418,41 @@ func ImportXLSXToTable(ctx context.Context, config *adapters.Config, opts XLSXOp
 	return nil
 }
 
+// xlsxEditsPacket compares a re-imported XLSX with the original TDTP export,
+// prints the change summary and returns the update-only delta packet with
+// the edited cells. nil, nil — nothing was edited.
+func xlsxEditsPacket(originalFile string, edited *packet.DataPacket) (*packet.DataPacket, error) {
+	original, err := packet.NewParser().ParseFile(originalFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse original export %s: %w", originalFile, err)
+	}
+	if err := decompressPacketData(original); err != nil {
+		return nil, fmt.Errorf("failed to decompress original export: %w", err)
+	}
+	if err := packet.ExpandCompactRows(original); err != nil {
+		return nil, fmt.Errorf("failed to expand original export: %w", err)
+	}
+	// The sheet may have been renamed: compare against the exported table
+	edited.Header.TableName = original.Header.TableName
+
+	changes, err := xlsx.CompareEdited(original, edited)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare with original export: %w", err)
+	}
+	fmt.Printf("\n%s\n", changes.Summary())
+	if !changes.HasEdits() {
+		fmt.Printf("✓ No edited cells — nothing to apply\n")
+		return nil, nil
+	}
+
+	delta, err := changes.DeltaPacket()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build delta packet: %w", err)
+	}
+	fmt.Printf("✓ Delta packet: %d edited row(s) (update-only)\n", len(delta.Data.Rows))
+	return delta, nil
+}
+
 // uploadXLSXToS3 uploads a local file to S3 and deletes the local file on success.
 func uploadXLSXToS3(ctx context.Context, cfg *storage.Config, key, localPath string) error {
 	store, err := storage.New(*cfg)
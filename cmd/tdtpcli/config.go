@@ -4,8 +4,18 @@ package main
 import (
 	"fmt"
 	"os"
-
+	"time"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+	"github.com/ruslano69/tdtp-framework/pkg/adapters/base"
+	"github.com/ruslano69/tdtp-framework/pkg/approval"
+	"github.com/ruslano69/tdtp-framework/pkg/core/schema"
+	tdtpcrypto "github.com/ruslano69/tdtp-framework/pkg/crypto"
+	"github.com/ruslano69/tdtp-framework/pkg/history"
+	"github.com/ruslano69/tdtp-framework/pkg/quota"
+	"github.com/ruslano69/tdtp-framework/pkg/security"
 	"github.com/ruslano69/tdtp-framework/pkg/storage"
+	"github.com/ruslano69/tdtp-framework/pkg/tracing"
 	"gopkg.in/yaml.v3"
 )
 
@@ -19,6 +29,13 @@ type Config struct {
 	Resilience ResilienceConfig `yaml:"resilience,omitempty"`
 	Audit      AuditConfig      `yaml:"audit,omitempty"`
 	Processors ProcessorsConfig `yaml:"processors,omitempty"`
+
+	ColumnEncryption base.ColumnEncryptionConfig `yaml:"column_encryption,omitempty"`
+	ExportPolicy     ExportPolicyConfig          `yaml:"export_policy,omitempty"`
+	History          HistoryConfig               `yaml:"history,omitempty"`
+	Quota            QuotaConfig                 `yaml:"quota,omitempty"`
+	Approval         ApprovalConfig              `yaml:"approval,omitempty"`
+	Tracing          TracingConfig               `yaml:"tracing,omitempty"`
 }
 
 // ExportConfig contains export settings
@@ -26,26 +43,301 @@ type ExportConfig struct {
 	Compress      bool   `yaml:"compress"`       // Enable compression by default
 	CompressLevel int    `yaml:"compress_level"` // Compression level: 1-19 (zstd) or 6-7 (kanzi)
 	CompressAlgo  string `yaml:"compress_algo"`  // Algorithm: "zstd" (default) or "kanzi"
+	DictDir       string `yaml:"dict_dir"`       // Directory of zstd dictionaries <table>.zdict
+	EmbedDict     bool   `yaml:"embed_dict"`     // Embed dictionary into compressed packets
+
+	// Custom read-only SELECT per table, used instead of SELECT * on export
+	// and incremental sync. Key is the table name (case-insensitive).
+	TableQueries map[string]string `yaml:"table_queries,omitempty"`
+
+	// Columns never shipped on export and sync (rowversion, replication
+	// artifacts). Key is the table name or "*" for all tables; values are
+	// column names or regular expressions such as "^audit_".
+	ExcludeColumns map[string][]string `yaml:"exclude_columns,omitempty"`
+}
+
+// Exclusions compiles export.exclude_columns (nil when not configured).
+func (c ExportConfig) Exclusions() (*adapters.ColumnExclusions, error) {
+	return adapters.NewColumnExclusions(c.ExcludeColumns)
 }
 
 // DatabaseConfig contains database connection settings
 type DatabaseConfig struct {
-	Type        string `yaml:"type"`                   // sqlite, postgres, mssql, access
+	Type        string `yaml:"type"`                   // sqlite, postgres, mssql, mysql, mongodb, oracle, access
 	Host        string `yaml:"host,omitempty"`         // For network databases
 	Port        int    `yaml:"port,omitempty"`         // Database port
 	Database    string `yaml:"database,omitempty"`     // Database name or file path
 	User        string `yaml:"user,omitempty"`         // Username
 	Password    string `yaml:"password,omitempty"`     // Password
-	Schema      string `yaml:"schema,omitempty"`       // PostgreSQL schema (default: public)
+	Schema      string `yaml:"schema,omitempty"`       // PostgreSQL schema (default: public), Oracle owner
 	WindowsAuth bool   `yaml:"windows_auth,omitempty"` // MS SQL Windows authentication
 	SSLMode     string `yaml:"sslmode,omitempty"`      // PostgreSQL SSL mode
 	DSN         string `yaml:"dsn,omitempty"`          // Raw connection string (overrides other fields; required for access)
 	Charset     string `yaml:"charset,omitempty"`      // Charset for string decoding, e.g. "windows-1251" (ODBC/legacy drivers)
+
+	CompatibilityMode string `yaml:"compatibility_mode,omitempty"` // MSSQL "2012"/"2016"/..., Oracle "11g"/"12c"/"19c"; "auto" by default
+
+	ImportLimits ImportLimitsConfig  `yaml:"import_limits,omitempty"`       // Throttle imports to protect the target DB
+	Encryption   *DBEncryptionConfig `yaml:"encryption,omitempty"`          // SQLCipher: SQLite encrypted at rest
+	QueryLog     *QueryLogConfig     `yaml:"query_log,omitempty"`           // Slow query log / statement capture
+	TableLock    *TableLockConfig    `yaml:"table_lock,omitempty"`          // Lock target tables against concurrent imports
+	Maintenance  *MaintenanceConfig  `yaml:"maintenance_windows,omitempty"` // Windows for imports that replace or truncate tables
+	Duplicates   string              `yaml:"duplicates,omitempty"`          // Rows repeating a primary key in one packet: keep-first | keep-last | fail
+	Columns      string              `yaml:"columns,omitempty"`             // Packet fields vs table columns: by name (default) | skip-extra | strict
+	RowErrors    *RowErrorsConfig    `yaml:"row_errors,omitempty"`          // Rows whose values do not convert: fail-fast | skip | dead-letter
+	Packets      *PacketsConfig      `yaml:"packets,omitempty"`             // Export packet size: max bytes, max rows, size estimate
+
+	// BOOLEAN stored as text (Y/N, Да/Нет): parsed on export, rendered on import
+	Booleans       *schema.BoolMapping           `yaml:"booleans,omitempty"`
+	ColumnBooleans map[string]schema.BoolMapping `yaml:"column_booleans,omitempty"` // per column, overrides booleans
+}
+
+// DBEncryptionConfig — ключ SQLCipher для локальной реплики SQLite.
+// Ключ берётся из keyring (как column_encryption) или задаётся напрямую.
+//
+//	database:
+//	  type: sqlite
+//	  dsn: replica.db
+//	  encryption:
+//	    keyring: /etc/tdtp/keyring   # строки "key_id = key"
+//	    key_id: replica-2025
+//	    # key: env:TDTP_REPLICA_KEY  # либо base64 / hex / env:NAME
+type DBEncryptionConfig struct {
+	Keyring string `yaml:"keyring,omitempty"`
+	KeyID   string `yaml:"key_id,omitempty"`
+	Key     string `yaml:"key,omitempty"`
+}
+
+// ResolveKey возвращает ключ SQLCipher (nil — шифрование не настроено).
+func (c *DBEncryptionConfig) ResolveKey(dbType string) ([]byte, error) {
+	if c == nil {
+		return nil, nil
+	}
+	if dbType != "sqlite" {
+		return nil, fmt.Errorf("database.encryption is supported only for sqlite, got %q", dbType)
+	}
+	switch {
+	case c.Key != "" && c.Keyring != "":
+		return nil, fmt.Errorf("database.encryption: key and keyring are mutually exclusive")
+	case c.Key != "":
+		key, err := tdtpcrypto.ParseKey(c.Key)
+		if err != nil {
+			return nil, fmt.Errorf("database.encryption key: %w", err)
+		}
+		return key, nil
+	case c.Keyring != "":
+		if c.KeyID == "" {
+			return nil, fmt.Errorf("database.encryption: key_id is required with keyring")
+		}
+		ring, err := tdtpcrypto.LoadKeyring(c.Keyring)
+		if err != nil {
+			return nil, err
+		}
+		key, err := ring.ColumnKey(c.KeyID)
+		if err != nil {
+			return nil, fmt.Errorf("database.encryption: %w", err)
+		}
+		return key, nil
+	}
+	return nil, fmt.Errorf("database.encryption requires key or keyring + key_id")
+}
+
+// ImportLimitsConfig limits import load on the target database (0 = unlimited).
+// With target_batch_latency_ms set the rate adapts (AIMD) between
+// min_rows_per_sec and max_rows_per_sec based on observed batch latency.
+type ImportLimitsConfig struct {
+	MaxRowsPerSec        float64 `yaml:"max_rows_per_sec,omitempty"`        // Row rate ceiling
+	MinRowsPerSec        float64 `yaml:"min_rows_per_sec,omitempty"`        // AIMD floor (default: 5% of max)
+	MaxConcurrentTx      int     `yaml:"max_concurrent_tx,omitempty"`       // Concurrent import transactions
+	TargetBatchLatencyMs int     `yaml:"target_batch_latency_ms,omitempty"` // Halve the rate when a batch is slower
+}
+
+// ToAdapterLimits converts config values to adapters.ImportLimits.
+func (c ImportLimitsConfig) ToAdapterLimits() adapters.ImportLimits {
+	return adapters.ImportLimits{
+		MaxRowsPerSec:      c.MaxRowsPerSec,
+		MinRowsPerSec:      c.MinRowsPerSec,
+		MaxConcurrentTx:    c.MaxConcurrentTx,
+		TargetBatchLatency: time.Duration(c.TargetBatchLatencyMs) * time.Millisecond,
+	}
+}
+
+// QueryLogConfig enables the adapter's SQL statement log: statements at
+// least slow_ms long are written with duration, row count and the MessageID
+// of the packet being imported. Parameter values are redacted unless
+// show_args is set.
+//
+//	database:
+//	  query_log:
+//	    enabled: true
+//	    slow_ms: 500
+//	    file: /var/log/tdtp/sql.log   # stderr by default
+type QueryLogConfig struct {
+	Enabled         bool   `yaml:"enabled"`
+	SlowMs          int    `yaml:"slow_ms,omitempty"`           // Log only statements at least this slow (0 = all)
+	ShowArgs        bool   `yaml:"show_args,omitempty"`         // Log parameter values
+	MaxStatementLen int    `yaml:"max_statement_len,omitempty"` // Truncate statement text (default 2000 chars)
+	File            string `yaml:"file,omitempty"`              // Append to this file instead of stderr
+}
+
+// ToAdapterConfig converts the section to adapters.QueryLogConfig, opening
+// the log file if one is set (it stays open for the life of the process).
+func (c *QueryLogConfig) ToAdapterConfig() (adapters.QueryLogConfig, error) {
+	if c == nil {
+		return adapters.QueryLogConfig{}, nil
+	}
+	cfg := adapters.QueryLogConfig{
+		Enabled:         c.Enabled,
+		SlowThreshold:   time.Duration(c.SlowMs) * time.Millisecond,
+		ShowArgs:        c.ShowArgs,
+		MaxStatementLen: c.MaxStatementLen,
+	}
+	if c.Enabled && c.File != "" {
+		f, err := os.OpenFile(c.File, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+		if err != nil {
+			return adapters.QueryLogConfig{}, fmt.Errorf("database.query_log: %w", err)
+		}
+		cfg.Output = f
+	}
+	return cfg, nil
+}
+
+// TableLockConfig serializes concurrent imports into the same table with a
+// cooperative lock (pg_advisory_lock, sp_getapplock, GET_LOCK, or a lock
+// file next to the SQLite database). Only tdtpcli imports honour it.
+//
+//	database:
+//	  table_lock:
+//	    mode: wait          # wait | fail
+//	    timeout_ms: 60000   # wait at most a minute (0 = no limit)
+type TableLockConfig struct {
+	Mode      string `yaml:"mode"`
+	TimeoutMs int    `yaml:"timeout_ms,omitempty"`
+}
+
+// ToAdapterConfig converts the section to adapters.TableLocking.
+func (c *TableLockConfig) ToAdapterConfig() adapters.TableLocking {
+	if c == nil {
+		return adapters.TableLocking{}
+	}
+	return adapters.TableLocking{
+		Mode:    adapters.TableLockMode(c.Mode),
+		Timeout: time.Duration(c.TimeoutMs) * time.Millisecond,
+	}
+}
+
+// MaintenanceConfig confines destructive imports — copy imports that
+// replace a table through a temporary one (DROP/RENAME) and truncate
+// imports — to maintenance windows. A window opens on a cron schedule and
+// stays open for duration; "*" applies to every table without windows of
+// its own, tables with no windows are not restricted. Outside a window the
+// import fails, or with outside: wait is queued until the window opens.
+//
+//	database:
+//	  maintenance_windows:
+//	    outside: wait            # fail (default) | wait
+//	    max_wait: 8h             # give up waiting after 8h (0 = no limit)
+//	    timezone: Europe/Moscow  # cron times (default: local time)
+//	    tables:
+//	      "*":    [{start: "0 2 * * *", duration: 3h}]
+//	      orders: [{start: "0 22 * * 6", duration: 8h}]
+type MaintenanceConfig struct {
+	Outside  string                               `yaml:"outside,omitempty"`
+	MaxWait  time.Duration                        `yaml:"max_wait,omitempty"`
+	Timezone string                               `yaml:"timezone,omitempty"`
+	Tables   map[string][]MaintenanceWindowConfig `yaml:"tables"`
+}
+
+// MaintenanceWindowConfig is one window: a cron start and its length.
+type MaintenanceWindowConfig struct {
+	Start    string        `yaml:"start"`
+	Duration time.Duration `yaml:"duration"`
+}
+
+// ToAdapterConfig converts the section to adapters.MaintenanceWindows
+// (nil when no windows are configured).
+func (c *MaintenanceConfig) ToAdapterConfig() (*adapters.MaintenanceWindows, error) {
+	if c == nil || len(c.Tables) == 0 {
+		return nil, nil
+	}
+	var location *time.Location
+	if c.Timezone != "" {
+		loc, err := time.LoadLocation(c.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("database.maintenance_windows: %w", err)
+		}
+		location = loc
+	}
+	rules := make(map[string][]adapters.MaintenanceWindow, len(c.Tables))
+	for table, windows := range c.Tables {
+		for _, w := range windows {
+			rules[table] = append(rules[table], adapters.MaintenanceWindow{Start: w.Start, Duration: w.Duration})
+		}
+	}
+	m, err := adapters.NewMaintenanceWindows(rules, adapters.MaintenanceMode(c.Outside), c.MaxWait, location)
+	if err != nil {
+		return nil, fmt.Errorf("database.maintenance_windows: %w", err)
+	}
+	return m, nil
+}
+
+// RowErrorsConfig decides what an import does with a row whose values do
+// not convert to the column types. fail-fast (the default) aborts the
+// packet; skip imports the remaining rows and reports the rejected ones;
+// dead-letter also writes them to a quarantine packet for repair and
+// re-import. partial_rollback rolls back only the packet the database
+// rejects and commits the rest (SQLite, MySQL, Oracle).
+//
+//	database:
+//	  row_errors:
+//	    policy: dead-letter        # fail-fast | skip | dead-letter
+//	    dead_letter_dir: ./rejected
+//	    partial_rollback: true
+type RowErrorsConfig struct {
+	Policy          string `yaml:"policy"`
+	DeadLetterDir   string `yaml:"dead_letter_dir,omitempty"`
+	PartialRollback bool   `yaml:"partial_rollback,omitempty"`
+}
+
+// ToAdapterConfig converts the section to adapters.RowErrorConfig.
+func (c *RowErrorsConfig) ToAdapterConfig() adapters.RowErrorConfig {
+	if c == nil {
+		return adapters.RowErrorConfig{}
+	}
+	cfg := adapters.RowErrorConfig{PartialRollback: c.PartialRollback}
+	if c.Policy != "fail-fast" {
+		cfg.Policy = adapters.RowErrorPolicy(c.Policy)
+		cfg.DeadLetterDir = c.DeadLetterDir
+	}
+	return cfg
+}
+
+// PacketsConfig sets how exports split rows into packets. The default
+// (~1.9MB of XML per packet) fits MSMQ; Kafka topics with the 1MB message
+// limit want smaller packets, file exports far larger ones.
+//
+//	database:
+//	  packets:
+//	    max_bytes: 900000   # part size limit (default 3800000 in utf16 units)
+//	    max_rows: 50000     # and/or a row limit per packet (0 = none)
+//	    estimate: xml       # utf16 (default, length x2) | xml (real XML bytes)
+type PacketsConfig struct {
+	MaxBytes int    `yaml:"max_bytes,omitempty"`
+	MaxRows  int    `yaml:"max_rows,omitempty"`
+	Estimate string `yaml:"estimate,omitempty"`
+}
+
+// ToAdapterConfig converts the section to adapters.PacketSizing.
+func (c *PacketsConfig) ToAdapterConfig() adapters.PacketSizing {
+	if c == nil {
+		return adapters.PacketSizing{}
+	}
+	return adapters.PacketSizing{MaxBytes: c.MaxBytes, MaxRows: c.MaxRows, Estimate: c.Estimate}
 }
 
 // BrokerConfig contains message broker settings
 type BrokerConfig struct {
-	Type           string `yaml:"type"`                      // rabbitmq, msmq, kafka
+	Type           string `yaml:"type"`                      // rabbitmq, msmq, kafka, filequeue
 	Host           string `yaml:"host,omitempty"`            // Broker host
 	Port           int    `yaml:"port,omitempty"`            // Broker port
 	User           string `yaml:"user,omitempty"`            // Username
@@ -64,6 +356,9 @@ type BrokerConfig struct {
 	// Kafka-specific
 	Brokers       []string `yaml:"brokers,omitempty"`        // Kafka: список брокеров (["localhost:9092"])
 	ConsumerGroup string   `yaml:"consumer_group,omitempty"` // Kafka: consumer group ID
+	// FileQueue-specific (queue = optional subdirectory)
+	Dir     string `yaml:"dir,omitempty"`      // FileQueue: queue directory (shared folder, removable media)
+	AutoAck bool   `yaml:"auto_ack,omitempty"` // FileQueue: acknowledge on receive
 }
 
 // ResilienceConfig contains circuit breaker and retry settings
@@ -113,6 +408,113 @@ type AuditDatabaseConfig struct {
 	AutoCreateTable bool   `yaml:"auto_create_table,omitempty"` // Create the table (and indexes) if missing
 }
 
+// ExportPolicyConfig — политика выдачи колонок по получателям (--recipient,
+// Recipient request-пакета). Правила задаются в конфиге или файлом.
+//
+//	export_policy:
+//	  file: /etc/tdtp/export_policy.yaml   # либо tables: прямо здесь
+//	  tables:
+//	    customers:
+//	      columns:
+//	        email: { allow: [crm, billing] }
+//	        phone: { allow: [crm], action: mask }
+type ExportPolicyConfig struct {
+	File                  string `yaml:"file,omitempty"`
+	security.ExportPolicy `yaml:",inline"`
+}
+
+// Policy возвращает политику (nil — политика не настроена).
+func (c ExportPolicyConfig) Policy() (*security.ExportPolicy, error) {
+	if c.File != "" {
+		if len(c.Tables) > 0 {
+			return nil, fmt.Errorf("export_policy: use either file or tables, not both")
+		}
+		return security.LoadExportPolicy(c.File)
+	}
+	if len(c.Tables) == 0 {
+		return nil, nil
+	}
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	policy := c.ExportPolicy
+	return &policy, nil
+}
+
+// HistoryConfig — хранилище истории запусков --pipeline и --sync-incremental
+// (tdtpcli --history). Драйверы те же, что и для database.
+//
+//	history:
+//	  type: sqlite              # или postgres
+//	  dsn: /var/lib/tdtp/history.db
+//	  table: tdtp_runs          # необязательно
+type HistoryConfig struct {
+	history.Config `yaml:",inline"`
+}
+
+// Open открывает хранилище (nil — история не настроена).
+func (c HistoryConfig) Open() (*history.Store, error) {
+	if c.Type == "" {
+		return nil, nil
+	}
+	return history.Open(c.Config)
+}
+
+// QuotaConfig — квоты получателей --export-broker: получатель — очередь
+// (broker.queue), лимиты строк, байт и запусков за час и за сутки.
+//
+//	quota:
+//	  state_file: /var/lib/tdtp/quota.json
+//	  alert_percent: 80
+//	  recipients:
+//	    bi_orders:
+//	      daily: {rows: 5000000, requests: 24}
+type QuotaConfig struct {
+	quota.Config `yaml:",inline"`
+}
+
+// Open открывает учёт квот (nil — квоты не настроены).
+func (c QuotaConfig) Open() (*quota.Tracker, error) {
+	if len(c.Recipients) == 0 && c.Default == nil {
+		return nil, nil
+	}
+	return quota.Open(c.Config)
+}
+
+// ApprovalConfig — согласование чувствительных операций: импорт в таблицы
+// tables (и любой импорт с --require-approval), --map с sensitive: true.
+// Запрос публикуется на webhook, операция ждёт решения; согласующий
+// записывается в аудит.
+//
+//	approval:
+//	  url: https://change.example.com/api/tdtp/approvals
+//	  headers: {Authorization: "Bearer 3f9c..."}
+//	  tables: [currencies, "ref_*"]
+//	  timeout: 2h
+//	  poll_interval: 15s
+//	  on_timeout: reject        # или approve
+type ApprovalConfig struct {
+	approval.Config `yaml:",inline"`
+}
+
+// Open создаёт шлюз согласования (nil — согласование не настроено).
+func (c ApprovalConfig) Open() (*approval.Gate, error) {
+	return approval.New(c.Config)
+}
+
+// TracingConfig — распределённая трассировка OpenTelemetry (OTLP/HTTP):
+// экспорт, брокер и импорт пакета — одна трасса. Без секции трассировку
+// включает переменная OTEL_EXPORTER_OTLP_ENDPOINT.
+//
+//	tracing:
+//	  enabled: true
+//	  endpoint: otel-collector:4318
+//	  insecure: true
+//	  service_name: tdtp-orders
+type TracingConfig struct {
+	tracing.Config `yaml:",inline"`
+}
+
 // ProcessorsConfig for data processing settings
 type ProcessorsConfig struct {
 	Mask      []MaskRule      `yaml:"mask,omitempty"`
@@ -234,6 +636,14 @@ func CreateSampleConfig(dbType string) *Config {
 		config.Database.Database = "mydb"
 		config.Database.User = "root"
 		config.Database.Password = "password"
+
+	case "oracle":
+		config.Database.Host = "localhost"
+		config.Database.Port = 1521
+		config.Database.Database = "ORCLPDB1"
+		config.Database.User = "scott"
+		config.Database.Password = "tiger"
+		config.Database.CompatibilityMode = "auto"
 	}
 
 	return config
@@ -277,6 +687,18 @@ func (c *DatabaseConfig) BuildDSN() string {
 		return fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?parseTime=true",
 			c.User, c.Password, c.Host, c.Port, c.Database)
 
+	case "mongodb":
+		if c.User == "" {
+			return fmt.Sprintf("mongodb://%s:%d/%s", c.Host, c.Port, c.Database)
+		}
+		return fmt.Sprintf("mongodb://%s:%s@%s:%d/%s",
+			c.User, c.Password, c.Host, c.Port, c.Database)
+
+	case "oracle":
+		// Database — имя сервиса (service name) Oracle
+		return fmt.Sprintf("oracle://%s:%s@%s:%d/%s",
+			c.User, c.Password, c.Host, c.Port, c.Database)
+
 	default:
 		return ""
 	}
@@ -0,0 +1,9 @@
+//go:build !nomongodb && !minimal
+
+package main
+
+import (
+	// Register MongoDB adapter (pure Go mongo-driver).
+	// Exclude with -tags nomongodb (or -tags minimal) to build without it.
+	_ "github.com/ruslano69/tdtp-framework/pkg/adapters/mongodb"
+)
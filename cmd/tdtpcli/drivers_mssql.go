@@ -0,0 +1,9 @@
+//go:build !nomssql && !minimal
+
+package main
+
+import (
+	// Register MS SQL Server adapter (pure Go go-mssqldb driver).
+	// Exclude with -tags nomssql (or -tags minimal) to build without it.
+	_ "github.com/ruslano69/tdtp-framework/pkg/adapters/mssql"
+)
@@ -0,0 +1,9 @@
+//go:build !nomysql && !minimal
+
+package main
+
+import (
+	// Register MySQL adapter (pure Go go-sql-driver/mysql).
+	// Exclude with -tags nomysql (or -tags minimal) to build without it.
+	_ "github.com/ruslano69/tdtp-framework/pkg/adapters/mysql"
+)
@@ -0,0 +1,9 @@
+//go:build !nooracle && !minimal
+
+package main
+
+import (
+	// Register Oracle adapter (pure Go go-ora driver).
+	// Exclude with -tags nooracle (or -tags minimal) to build without it.
+	_ "github.com/ruslano69/tdtp-framework/pkg/adapters/oracle"
+)
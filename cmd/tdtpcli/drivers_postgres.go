@@ -0,0 +1,9 @@
+//go:build !nopostgres && !minimal
+
+package main
+
+import (
+	// Register PostgreSQL adapter (pure Go pgx driver).
+	// Exclude with -tags nopostgres (or -tags minimal) to build without it.
+	_ "github.com/ruslano69/tdtp-framework/pkg/adapters/postgres"
+)
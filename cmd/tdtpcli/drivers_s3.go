@@ -1,8 +1,9 @@
-//go:build !nos3
+//go:build !nos3 && !minimal
 
 package main
 
 import (
 	// Register S3 storage driver so s3:// URIs work in --export / --import.
+	// Exclude with -tags nos3 (or -tags minimal) to build without it.
 	_ "github.com/ruslano69/tdtp-framework/pkg/storage/s3"
 )
@@ -4,5 +4,6 @@ package main
 
 import (
 	// Register SQLite adapter so --database.type: sqlite works.
+	// Kept in the minimal preset; exclude with -tags nosqlite.
 	_ "github.com/ruslano69/tdtp-framework/pkg/adapters/sqlite"
 )
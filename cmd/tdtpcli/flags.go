@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 )
 
 // MultiStringFlag is a flag that can be specified multiple times.
@@ -48,14 +49,20 @@ func (f *ListFlag) IsBoolFlag() bool { return true }
 type Flags struct {
 	// Commands
 	Test           *string // Dry-run integrity check of a TDTP file (decompress in memory, validate XML)
+	Lint           *string // Validate TDTP file(s) against the spec, findings for CI gating
+	LintFormat     *string // --lint output: text | json
 	List           *ListFlag
 	ListViews      *bool
+	ListSchemas    *bool
 	Export         *string
+	ExportSample   *string // --export-sample: referentially consistent multi-table sample (sample YAML)
 	Import         *string
 	ExportBroker   *string
 	ImportBroker   *bool
-	RawBroker      *bool // --raw: save broker messages as-is, no parse/decompress
-	KeepBroker     *bool // --keep: allow partial writes (non-atomic import from broker)
+	RawBroker      *bool   // --raw: save broker messages as-is, no parse/decompress
+	KeepBroker     *bool   // --keep: allow partial writes (non-atomic import from broker)
+	RetryParts     *string // --retry-failed-parts: resend undelivered parts of an --export-broker run
+	PartsArchive   *string // --parts-archive: keep sent broker messages for --retry-failed-parts
 	ToHTML         *string
 	OpenBrowser    *bool
 	Row            *string // Row range for HTML viewer (e.g., "100-150")
@@ -67,25 +74,48 @@ type Flags struct {
 	FromXLSX       *string
 	ExportXLSX     *string
 	ImportXLSX     *string
+	XLSXOriginal   *string // --xlsx-original: исходный TDTP экспорт — --from-xlsx/--import-xlsx берут только правки
+	NumberFormat   *string // --number-format: формат чисел в текстовых ячейках XLSX (ru, de, en...)
+	Booleans       *string // --booleans: представление BOOLEAN в текстовых ячейках XLSX ("Y,Да/N,Нет")
+	DateFormat     *string // --date-format: порядок дня и месяца в текстовых датах XLSX (dmy, mdy, auto; по колонкам)
 	SyncIncr       *string
+	Reconcile      *string // --reconcile: Merkle-сверка таблицы источника (--config) и приёмника (--target-config)
+	Erase          *string // --erase: стирание данных субъекта (GDPR) в источнике и всех --erase-targets
+	History        *bool   // --history: история запусков --pipeline / --sync-incremental (history: в конфиге)
+	Operations     *bool   // --operations: выполняющиеся операции всех процессов хоста (pkg/operations)
+	Cancel         *string // --cancel: отменить выполняющуюся операцию по ID из --operations
 	Pipeline       *string
 	ProcessRequest *string // Process incoming TDTP request file and generate response
 	Diff           *string // First file for diff (second as positional arg)
 	Merge          *string // Comma-separated list of files to merge
 	Inspect        *string // Print YAML metadata summary of a TDTP file
 	InspectTable   *string // Print extended metadata of a live DB table (Agentic Discovery Mode)
+	GenDDL         *string // --gen-ddl: CREATE TABLE DDL from a TDTP file or live table
+	GenDDLTarget   *string // --target: DDL dialect for --gen-ddl (postgres, mssql, mysql, sqlite, oracle)
 	Listen         *bool   // [BETA] Stream consumer daemon mode (Kafka only)
 	Map            *string // --map: cross-system field mapping (mapping YAML file)
 	MapInput       *string // --input: source TDTP file for --map
-	MapDryRun      *bool   // --dry-run: validate mapping without writing to DB
+	MapDryRun      *bool   // --dry-run: validate mapping / check --import without writing to DB
 	Steps          *string // --steps: execute multi-step workflow YAML (depends_on + on_error)
+	Bench          *string // --bench: load test with synthetic packets (import | broker | pipeline)
+	TrainDict      *string // --train-dict: train zstd dictionary from sample packets (output .zdict)
+	DictSize       *int    // --dict-size: max dictionary size in KB for --train-dict
+
+	// Benchmark options (--bench)
+	Concurrency *int
+	Duration    *time.Duration
+	BenchRows   *int
 
 	// TDTQL Filters
 	Where   MultiStringFlag // repeatable: --where "A>1" --where "B IN (1,2)"
 	OrderBy *string
 	Limit   *int
 	Offset  *int
+	After   *string // Keyset cursor: primary key values of the previous page's last row
 	Fields  *string // Column projection: comma-separated list (e.g. "id,email,status")
+	// Recipient — система-получатель экспорта (Header.Recipient), по ней
+	// применяется export_policy.
+	Recipient *string
 
 	// Options
 	Config         *string
@@ -94,17 +124,47 @@ type Flags struct {
 	Table          *string // Target table name (overrides name from XML during import)
 	Sheet          *string
 	Strategy       *string
-	Batch          *int  // [deprecated, no-op] alias kept for backward compat; use --batch-size
-	ReadOnlyFields *bool // Include read-only fields (timestamp, computed, identity) in export
+	MergeColumns   *string // Columns updated by --strategy merge (comma-separated)
+	Batch          *int    // [deprecated, no-op] alias kept for backward compat; use --batch-size
+	ReadOnlyFields *bool   // Include read-only fields (timestamp, computed, identity) in export
+
+	// Partition routing (import)
+	PartitionBy       *string // Колонка даты/времени для маршрутизации по партициям
+	PartitionInterval *string // day | month
+	PartitionPattern  *string // Шаблон имени партиции ({table}, {yyyy}, {mm}, {dd})
+	PartitionCreate   *bool   // Создавать недостающие партиции
+
+	// Schemas (PostgreSQL)
+	CreateSchema *bool // --create-schema: создавать схему таблицы "schema.table" при импорте
+
+	// Provenance (_tdtp_* columns)
+	Provenance     *bool // --provenance: добавить колонки происхождения при импорте
+	KeepProvenance *bool // --keep-provenance: не исключать их при экспорте
+
+	VerifyManifest *bool // --verify-manifest: проверка SHA-256 по манифесту и квитанция импорта (s3://)
+	ManifestStats  *bool // --manifest-stats: статистика таблицы источника в манифесте выгрузки (s3://)
+
+	// Statistics
+	Analyze *bool // --analyze: обновить статистику планировщика после импорта
+
+	// Import simulation
+	Simulate    *bool // --simulate: импорт в scratch-копию и отчёт об изменениях
+	KeepScratch *bool // --keep-scratch: не удалять scratch-копию после отчёта
+
+	// Approval
+	RequireApproval *bool // --require-approval: согласование импорта через approval: webhook
 
 	// Compression
 	Compress         *bool
 	CompressLevel    *int
 	CompressAlgo     *string // Алгоритм сжатия: "zstd" (по умолчанию) или "kanzi"
+	DictDir          *string // Каталог словарей zstd <table>.zdict
+	EmbedDict        *bool   // Встраивать словарь в сжатые пакеты
 	Hash             *bool   // Add XXH3 checksum for data integrity verification
 	PacketSize       *int    // Broker packet size in MB (default 0 = use built-in default ~1.9MB)
 	Fast             *bool   // Skip SpecialValues detection (no NULL/NaN/Inf markers) for maximum export speed
 	FallbackRowLimit *int64  // Max rows for in-memory fallback when SQL pushdown fails (0 = unlimited)
+	Explain          *bool   // Print the export query plan (SQL vs in-memory filters, stages) without exporting
 
 	// Compact format (v1.3.1)
 	Compact     *bool   // Enable compact format on export (fixed fields written once per group)
@@ -116,15 +176,48 @@ type Flags struct {
 	Encrypt *bool // --enc: активирует шифрование через xZMercury (переопределяет output.tdtp.encryption в YAML). С версии 1.5 — TDTP v1.5 section-level формат (Header остаётся plain XML).
 	Enc13   *bool // --enc13: явно запросить legacy v1.3 whole-blob формат (для консьюмеров, ещё не обновлённых до v1.5)
 
+	DecryptArchive   *string // --decrypt-archive: расшифровать архивные пакеты v1.5 ключом депозитария (файл или каталог)
+	EscrowPrivateKey *string // --escrow-private-key: закрытый ключ депозитария (PEM) для --decrypt-archive
+
 	// v1.4 Integrity (TDTP v1.4 xxh3 hashes + Mercury hash registration)
 	Integrity     *bool   // --integrity: compute Schema+Data+Packet xxh3_128 hashes and stamp the packet
 	MercuryURL    *string // --mercury-url: xzMercury base URL for hash registration (optional; local integrity if empty)
 	MercuryCaller *string // --mercury-caller: X-Caller identity sent to Mercury (default: "tdtpcli")
 
+	RowChecksum *string // --row-checksum: SHA-256 of canonical rows in Header.Checksum (packet | rows)
+
+	BundleKeyFile *string // --bundle-key-file: ключ AES-256 (hex) бандла .tdtpz — шифрует при экспорте, расшифровывает при импорте
+
 	// Incremental Sync
 	TrackingField  *string
 	CheckpointFile *string
-	BatchSize      *int
+	SyncDeletes    *string // --sync-deletes: soft:<field>[=<value>] | log:<table>[=<field>]
+	SyncCDC        *string // --sync-cdc: logical replication slot (PostgreSQL + wal2json)
+	SyncCT         *bool   // --sync-change-tracking: SQL Server Change Tracking (CHANGETABLE)
+	SyncDrift      *string // --sync-schema-drift: pause | migrate | accept
+	Daemon         *bool   // --daemon: run the --sync-config jobs on their schedules
+	SyncConfig     *string // --sync-config: sync jobs YAML for --daemon
+
+	// Reconcile Options
+	TargetConfig   *string
+	ReconcileApply *bool
+	ReconcileState *string // --reconcile-state: Merkle trees kept between runs
+	MerkleDepth    *int
+
+	// Erase Options
+	EraseKeys    MultiStringFlag // --erase-key: значение ключа (составной — через запятую), повторяемый
+	EraseTargets *string         // --erase-targets: конфиги копий таблицы через запятую
+	SubjectRef   *string
+	EraseReason  *string
+	EraseReceipt *string
+	BatchSize    *int
+
+	// History Options
+	HistoryID     *string
+	HistoryKind   *string
+	HistoryName   *string
+	HistoryStatus *string
+	HistorySince  *string
 
 	// Field Name Sanitization (--import)
 	Translit *bool // transliterate non-ASCII field names to ASCII via go-unidecode
@@ -134,17 +227,23 @@ type Flags struct {
 	Mask      *string
 	Validate  *string
 	Normalize *string
+	Tokenize  *string
+	MapValues *string
 
 	// Config Creation
 	CreateConfigPG     *bool
 	CreateConfigMSSQL  *bool
 	CreateConfigSQLite *bool
 	CreateConfigMySQL  *bool
+	CreateConfigOracle *bool
 
 	// ETL Pipeline
-	Unsafe       *bool
-	UnsafeCert   *string           // --unsafe-cert: path to unsafe-op.cert capability certificate
-	PipelineVars map[string]string // @name=value args passed after --pipeline flag
+	Unsafe         *bool
+	UnsafeCert     *string           // --unsafe-cert: path to unsafe-op.cert capability certificate
+	StatsJSON      *string           // --stats-json: статистика запуска по шагам/источникам/частям в JSON ("-" — stdout)
+	ProgressFormat *string           // --progress-format: text | json (NDJSON-события в stdout, текст — в stderr)
+	ErrorFormat    *string           // --error-format: text | json (ошибка запуска одним JSON-объектом в stderr)
+	PipelineVars   map[string]string // @name=value args passed after --pipeline flag
 
 	// Import precondition check (v1.4)
 	ExpectVars map[string]string // --expect-var name=value: verify PipelineContext before import
@@ -153,13 +252,17 @@ type Flags struct {
 	KeyFields     *string
 	IgnoreFields  *string
 	CaseSensitive *bool
+	DeltaOutput   *string
 	MergeStrategy *string
 	ShowConflicts *bool
 
 	// Misc
-	Version   *bool
-	Help      *bool
-	ShortHelp *bool
+	Plugins         *string // --plugins: каталог внешних адаптеров (pkg/adapters/plugin), по умолчанию TDTP_PLUGIN_DIR
+	IsolateAdapters *string // --isolate-adapters: адаптеры в дочерних процессах (plugin.Isolate), по умолчанию TDTP_ISOLATE_ADAPTERS
+	IsolateRecycle  *int    // --isolate-recycle: вызовов до замены дочернего процесса
+	Version         *bool
+	Help            *bool
+	ShortHelp       *bool
 }
 
 // ParseFlags defines and parses all command-line flags
@@ -168,16 +271,22 @@ func ParseFlags() *Flags {
 
 	// Commands
 	f.Test = flag.String("test", "", "Dry-run integrity check of a TDTP file: decompress in memory, verify checksum, validate XML (no DB needed)")
+	f.Lint = flag.String("lint", "", "Validate TDTP file(s) against the spec: header, schema, row field counts, value formats, part numbering (comma-separated, no DB needed)")
+	f.LintFormat = flag.String("lint-format", "text", "--lint output: text or json (machine-readable findings for CI)")
 
 	f.List = &ListFlag{}
 	flag.Var(f.List, "list", `List tables in database, optionally filtered by glob pattern (e.g. --list "user*", --list "order?")`)
 
 	f.ListViews = flag.Bool("list-views", false, "List all database views with updatable status")
+	f.ListSchemas = flag.Bool("list-schemas", false, "List database schemas with table counts (postgres)")
 	f.Export = flag.String("export", "", "Export table to TDTP XML file (table name)")
+	f.ExportSample = flag.String("export-sample", "", "Export a random/stratified multi-table sample with referenced rows, masked/pseudonymized per sample YAML (file path; --output = target directory)")
 	f.Import = flag.String("import", "", "Import TDTP XML file to database (file path)")
 	f.ExportBroker = flag.String("export-broker", "", "Export table to message broker (table name)")
 	f.ImportBroker = flag.Bool("import-broker", false, "Import from message broker to database")
 	f.RawBroker = flag.Bool("raw", false, "Save broker messages as-is without parsing or decompression (use with --import-broker --output)")
+	f.RetryParts = flag.String("retry-failed-parts", "", "With --export-broker: resend only the undelivered parts of this run ID (per-part delivery is tracked when history: is configured)")
+	f.PartsArchive = flag.String("parts-archive", "", "With --export-broker and history: keep a copy of every sent message under <dir>/<run-id> so --retry-failed-parts resends the exact parts")
 	f.KeepBroker = flag.Bool("keep", false, "Allow partial writes: import each broker part immediately (non-atomic). Default: atomic (all-or-nothing via ImportPackets)")
 	f.ToHTML = flag.String("to-html", "", "Convert TDTP XML file to HTML for browser viewing (input TDTP file)")
 	f.OpenBrowser = flag.Bool("open", false, "Open generated HTML file in default browser (use with --to-html)")
@@ -192,16 +301,29 @@ func ParseFlags() *Flags {
 	f.ExportXLSX = flag.String("export-xlsx", "", "Export table directly to XLSX (table name)")
 	f.ImportXLSX = flag.String("import-xlsx", "", "Import XLSX file directly to database (file path)")
 	f.SyncIncr = flag.String("sync-incremental", "", "Incremental sync from table (table name)")
+	f.Reconcile = flag.String("reconcile", "", "Reconcile table between --config (source) and --target-config (target) using Merkle trees of row hashes")
+	f.Erase = flag.String("erase", "", "Erase a data subject's rows (GDPR) from the table in --config and every --erase-targets database")
+	f.History = flag.Bool("history", false, "Show recorded --pipeline / --sync-incremental runs (requires history: in --config)")
+	f.Operations = flag.Bool("operations", false, "List in-flight export/import/sync/pipeline operations of all tdtpcli and tdtpserve processes on this host")
+	f.Cancel = flag.String("cancel", "", "Cancel an in-flight operation by ID (from --operations); it stops within a second and rolls back like a failure")
 	f.Pipeline = flag.String("pipeline", "", "Execute ETL pipeline from YAML config (file path)")
 	f.ProcessRequest = flag.String("process-request", "", "Process TDTP request file and generate response (file path)")
 	f.Diff = flag.String("diff", "", "Compare two TDTP files: --diff file1.xml file2.xml")
 	f.Merge = flag.String("merge", "", "Merge multiple TDTP files (comma-separated file paths)")
 	f.Inspect = flag.String("inspect", "", "Print YAML metadata summary of a TDTP file (no config needed)")
 	f.InspectTable = flag.String("inspect-table", "", "Print extended metadata of a live DB table: native types, FK relationships, row count, sample row (Agentic Discovery Mode)")
+	f.GenDDL = flag.String("gen-ddl", "", "Print CREATE TABLE DDL for --target dialect from a TDTP file or a table of the --config database")
+	f.GenDDLTarget = flag.String("target", "", "DDL dialect for --gen-ddl: postgres, mssql, mysql, sqlite, oracle")
 	f.Listen = flag.Bool("listen", false, "Daemon mode: loop on broker queue until SIGTERM. Use with --map --input broker://queue for continuous upsert, or with Kafka streaming consumer (legacy).")
 	f.Map = flag.String("map", "", "Cross-system field mapping: apply mapping.yaml to a TDTP file and upsert into target DB")
-	f.MapInput = flag.String("input", "", "Source TDTP file for --map (e.g. out/emp_00247.tdtp.xml)")
-	f.MapDryRun = flag.Bool("dry-run", false, "Validate --map transformation without writing to DB")
+	f.MapInput = flag.String("input", "", "Source TDTP file for --map (e.g. out/emp_00247.tdtp.xml); sample packets for --train-dict (files or globs, comma-separated)")
+	f.MapDryRun = flag.Bool("dry-run", false, "Validate --map transformation, or check --import against the target (report only), without writing to DB")
+	f.Bench = flag.String("bench", "", "Load test with synthetic packets: import (into DB), broker (publish), pipeline (process → compress → XML → parse → import)")
+	f.Concurrency = flag.Int("concurrency", 4, "Parallel workers for --bench")
+	f.Duration = flag.Duration("duration", 30*time.Second, "How long to run --bench (e.g. 30s, 5m)")
+	f.BenchRows = flag.Int("bench-rows", 1000, "Rows per synthetic packet for --bench")
+	f.TrainDict = flag.String("train-dict", "", "Train zstd compression dictionary from sample packets of one table (--input) and write it to file (<table>.zdict)")
+	f.DictSize = flag.Int("dict-size", 64, "Max dictionary size in KB for --train-dict")
 	f.Steps = flag.String("steps", "", "Execute multi-step workflow from YAML (depends_on, parallel waves, on_error: stop|skip|retry(N))")
 
 	// TDTQL Filters
@@ -211,7 +333,9 @@ func ParseFlags() *Flags {
 	f.Limit = flag.Int("limit", 0, "LIMIT rows: positive = first N rows, negative = last N rows (like tail -n)")
 	flag.IntVar(f.Limit, "l", 0, "Row limit shorthand (alias for --limit), e.g. -l=10")
 	f.Offset = flag.Int("offset", 0, "OFFSET number of rows to skip")
+	f.After = flag.String("after", "", "Keyset pagination: primary key value(s) of the last row of the previous page, comma-separated\n\t(printed as 'Next page: --after ...' by --export --limit N; stable under concurrent inserts, unlike --offset)")
 	f.Fields = flag.String("fields", "", "Column projection: comma-separated list of columns to select/import (e.g. 'id,email,status')")
+	f.Recipient = flag.String("recipient", "", "Recipient system of the export (packet Header.Recipient); columns are filtered by export_policy")
 
 	// Options
 	f.Config = flag.String("config", "config.yaml", "Configuration file path")
@@ -219,18 +343,54 @@ func ParseFlags() *Flags {
 	f.Output = flag.String("output", "", "Output file path (default: stdout or auto-generated)")
 	f.Table = flag.String("table", "", "Target table name (overrides name from XML during import)")
 	f.Sheet = flag.String("sheet", "Sheet1", "Excel sheet name for XLSX operations")
-	f.Strategy = flag.String("strategy", "replace", "Import strategy: replace, ignore, fail, copy")
+	f.NumberFormat = flag.String("number-format", "", "Number format of numeric text cells for --from-xlsx/--import-xlsx: ru, fr (\"1 234,56\"), de (\"1.234,56\"), en (\"1,234.56\"), ch (\"1'234.56\")")
+	f.DateFormat = flag.String("date-format", "", "Date format of text date cells for --from-xlsx/--import-xlsx: dmy (31.12.2024), mdy (12/31/2024), ymd, auto (detect per column); per column as \"dmy,shipped=mdy\"")
+	f.Booleans = flag.String("booleans", "", "BOOLEAN text cells for --from-xlsx/--import-xlsx as \"true1,true2/false1,false2\", e.g. \"Y,Да/N,Нет\"")
+	f.XLSXOriginal = flag.String("xlsx-original", "", "Original TDTP export of an edited XLSX: --from-xlsx/--import-xlsx diff by primary key and produce/apply only edited cells as a delta packet")
+	f.Strategy = flag.String("strategy", "replace", "Import strategy: replace, ignore, fail, copy, merge, append, truncate")
+	f.MergeColumns = flag.String("merge-columns", "", "Columns --strategy merge updates in existing rows (comma-separated, default: all non-key packet fields)")
 	f.Batch = flag.Int("batch", 1000, "[deprecated, no-op] use --batch-size")
 	f.ReadOnlyFields = flag.Bool("readonly-fields", false, "Include read-only fields (timestamp, computed, identity) in export")
 
+	// Partition routing
+	f.PartitionBy = flag.String("partition-by", "", "Route imported rows to partition tables by this date/time column")
+	f.PartitionInterval = flag.String("partition-interval", "day", "Partition interval: day or month")
+	f.PartitionPattern = flag.String("partition-pattern", "", "Partition table name pattern: {table}, {yyyy}, {mm}, {dd} (default {table}_{yyyy}_{mm}_{dd})")
+	f.PartitionCreate = flag.Bool("partition-create", false, "Create missing partition tables (postgres, mysql)")
+
+	// Schemas
+	f.CreateSchema = flag.Bool("create-schema", false, "Create missing schemas of schema-qualified tables on import (postgres)")
+
+	// Provenance
+	f.Provenance = flag.Bool("provenance", false, "Add and fill provenance columns on import (_tdtp_source, _tdtp_message_id, _tdtp_imported_at, _tdtp_part)")
+	f.KeepProvenance = flag.Bool("keep-provenance", false, "Keep _tdtp_* provenance columns on export (excluded by default)")
+
+	// Verified object storage ingestion
+	f.VerifyManifest = flag.Bool("verify-manifest", false, "Import from s3:// exactly the objects of <key>.manifest.json, verifying each SHA-256, then write <key>.receipt.json; skips if a receipt for the same manifest and target exists")
+
+	f.ManifestStats = flag.Bool("manifest-stats", false, "Add source table statistics (approximate row count, column histograms where the database keeps them) to the s3:// export manifest")
+
+	// Statistics
+	f.Analyze = flag.Bool("analyze", false, "Update planner statistics of the imported table(s) after import (ANALYZE / UPDATE STATISTICS / DBMS_STATS)")
+
+	// Import simulation
+	f.Simulate = flag.Bool("simulate", false, "With --import: import into a scratch copy of the target tables and print the rows the import would add, remove or change (the target is not written)")
+	f.KeepScratch = flag.Bool("keep-scratch", false, "With --simulate: keep the scratch copy (schema or file) for manual review")
+
+	// Approval
+	f.RequireApproval = flag.Bool("require-approval", false, "With --import: publish an approval request (approval: section) and wait for approve/reject before writing, as for approval.tables")
+
 	// Compression
 	f.Compress = flag.Bool("compress", false, "Enable compression for exported data")
 	f.CompressLevel = flag.Int("compress-level", 3, "Compression level: 1-19 (zstd) or 6-7 (kanzi)")
 	f.CompressAlgo = flag.String("compress-algo", "zstd", "Compression algorithm: zstd (default) or kanzi")
+	f.DictDir = flag.String("dict-dir", "", "Directory of zstd dictionaries (<table>.zdict): compress matching tables with their dictionary, decompress packets that reference one")
+	f.EmbedDict = flag.Bool("embed-dict", false, "Embed the zstd dictionary into each compressed packet (for receivers without --dict-dir)")
 	f.PacketSize = flag.Int("packet-size", 0, "Max broker packet size in MB (default 0 = ~1.9MB; use 8 for large kanzi-compressed packets)")
 	f.Hash = flag.Bool("hash", false, "[deprecated, no-op] XXH3 checksum is now always added when --compress is used")
 	f.Fast = flag.Bool("fast", false, "Skip SpecialValues detection for maximum export speed (no NULL/NaN/Inf schema markers)")
 	f.FallbackRowLimit = flag.Int64("fallback-row-limit", 1_000_000, "Max rows for in-memory fallback when SQL pushdown fails (0 = unlimited). Protects prod DBs from full-table scans on broken queries")
+	f.Explain = flag.Bool("explain", false, "With --export: print the query plan (filters pushed to SQL vs evaluated in memory, stages with row estimates, source SQL) without exporting")
 
 	// Compact format (v1.3.1)
 	f.Compact = flag.Bool("compact", false, "Enable TDTP v1.3.1 compact format on export (fixed fields written once per group)")
@@ -241,17 +401,47 @@ func ParseFlags() *Flags {
 	// Encryption
 	f.Encrypt = flag.Bool("enc", false, "Encrypt output via xZMercury (AES-256-GCM, UUID-binding). TDTP v1.5 section-level format (Header stays plain XML; QueryContext/Schema/Data opaque). Requires security.mercury_url in pipeline YAML")
 	f.Enc13 = flag.Bool("enc13", false, "Encrypt output using the legacy TDTP v1.3 whole-packet binary blob format, for consumers not yet updated to v1.5. Same xZMercury BindKey/RetrieveKey flow as --enc")
+	f.DecryptArchive = flag.String("decrypt-archive", "", "Decrypt archived v1.5 packets (file or directory) offline with the escrow private key, no xZMercury needed; packets must be exported with TDTP_ESCROW_PUBLIC_KEY set. Writes plain TDTP to --output")
+	f.EscrowPrivateKey = flag.String("escrow-private-key", "", "Escrow private key (PEM) for --decrypt-archive")
 
 	// v1.4 Integrity
 	f.Integrity = flag.Bool("integrity", false, "Stamp packet with TDTP v1.4 xxh3_128 integrity hashes (Schema + Data + Packet fingerprint). Optionally register in xzMercury with --mercury-url.")
 	f.MercuryURL = flag.String("mercury-url", "", "xzMercury base URL for hash registration (e.g. http://mercury:3000). Used with --integrity to register the packet fingerprint.")
+	f.RowChecksum = flag.String("row-checksum", "", "Stamp exported packets with a SHA-256 row checksum verified on parse/import: packet (one sum) or rows (plus a hash per row to locate corruption)")
+	f.BundleKeyFile = flag.String("bundle-key-file", "", "File with a 64-hex-char AES-256 key: encrypts a .tdtpz bundle on --export, decrypts it on --import")
 	f.MercuryCaller = flag.String("mercury-caller", "tdtpcli", "Caller identity sent to xzMercury as X-Caller header (use service account name, e.g. svc-exporter)")
 
 	// Incremental Sync Options
 	f.TrackingField = flag.String("tracking-field", "updated_at", "Field to track changes (timestamp, sequence, version)")
 	f.CheckpointFile = flag.String("checkpoint-file", "checkpoint.yaml", "Checkpoint file for incremental sync state")
+	f.SyncDeletes = flag.String("sync-deletes", "", "Propagate deletes in --sync-incremental as tombstone packets: soft:<field>[=<value>] (soft-delete flag, e.g. soft:is_deleted=1) or log:<table>[=<field>] (delete log filled by a trigger/CDC)")
+	f.SyncCDC = flag.String("sync-cdc", "", "Capture changes for --sync-incremental from a PostgreSQL logical replication slot (wal2json, created on first run) instead of --tracking-field; inserts, updates and deletes in commit order, checkpoint = LSN")
+	f.SyncCT = flag.Bool("sync-change-tracking", false, "Capture changes for --sync-incremental with SQL Server Change Tracking (CHANGETABLE) instead of --tracking-field; the table needs change tracking enabled, checkpoint = change tracking version")
+	f.SyncDrift = flag.String("sync-schema-drift", "pause", "Reaction of --sync-incremental to a changed source schema: pause (stop until handled), migrate (add new columns to the --target-config table), accept (record the new schema and continue)")
+	f.Daemon = flag.Bool("daemon", false, "Run the incremental sync jobs of --sync-config on their cron schedules until SIGTERM; serves /healthz and /metrics, SIGHUP reloads the sync config")
+	f.SyncConfig = flag.String("sync-config", "", "Sync jobs YAML for --daemon (schedule, table and --sync-incremental options per job)")
+
+	// Reconcile Options
+	f.TargetConfig = flag.String("target-config", "", "Target database config for --reconcile and --sync-schema-drift migrate")
+	f.ReconcileApply = flag.Bool("reconcile-apply", false, "Apply corrective rows to the target (upsert) after --reconcile")
+	f.ReconcileState = flag.String("reconcile-state", "", "Keep the --reconcile Merkle trees in this directory and refresh them by --tracking-field and key columns instead of reading both tables again")
+	f.MerkleDepth = flag.Int("merkle-depth", 0, "Merkle tree depth for --reconcile (leaves = 2^depth, default 12)")
+
+	// Erase Options
+	flag.Var(&f.EraseKeys, "erase-key", "Primary key of a row to erase; repeatable, composite key values comma-separated (e.g., --erase-key 42 --erase-key 43)")
+	f.EraseTargets = flag.String("erase-targets", "", "Comma-separated configs of downstream copies for --erase (e.g., replica.yaml,dwh.yaml)")
+	f.SubjectRef = flag.String("subject-ref", "", "Data subject request reference recorded in the erasure receipt (e.g., DSR-2025-0042)")
+	f.EraseReason = flag.String("erase-reason", "", "Reason recorded in the erasure receipt")
+	f.EraseReceipt = flag.String("erase-receipt", "", "Erasure receipt file (default: erasure_<table>_<time>.json)")
 	f.BatchSize = flag.Int("batch-size", 1000, "Batch size for incremental sync")
 
+	// History Options
+	f.HistoryID = flag.String("history-id", "", "Show one run from --history in full (JSON with step statistics)")
+	f.HistoryKind = flag.String("history-kind", "", "Filter --history by kind: pipeline, sync, export")
+	f.HistoryName = flag.String("history-name", "", "Filter --history by pipeline name or sync table")
+	f.HistoryStatus = flag.String("history-status", "", "Filter --history by status: success, failed, completed_with_errors")
+	f.HistorySince = flag.String("history-since", "", "Filter --history by start time: duration back (24h), date (2026-01-31) or RFC3339")
+
 	// Field Name Sanitization
 	f.Translit = flag.Bool("translit", false, "Transliterate non-ASCII field names to ASCII (Cyrillic, European diacritics) using go-unidecode. Use with --import.")
 	f.Clear = flag.Bool("clear", false, "Replace special chars in field names with safe tokens (% → _pct, @ → _at, space → _, …). Use with --import.")
@@ -260,16 +450,22 @@ func ParseFlags() *Flags {
 	f.Mask = flag.String("mask", "", "Mask sensitive fields (comma-separated: email,phone,card)")
 	f.Validate = flag.String("validate", "", "Validate fields (YAML file with validation rules)")
 	f.Normalize = flag.String("normalize", "", "Normalize fields (YAML file with normalization rules)")
+	f.Tokenize = flag.String("tokenize", "", "Tokenize fields via external token service (YAML file: fields, url, auth_token_env)")
+	f.MapValues = flag.String("map-values", "", "Translate codes per column (YAML file: fields with values or lookup table, unmapped: pass|null|fail)")
 
 	// Config Creation
 	f.CreateConfigPG = flag.Bool("create-config-pg", false, "Create sample PostgreSQL config file")
 	f.CreateConfigMSSQL = flag.Bool("create-config-mssql", false, "Create sample MS SQL config file")
 	f.CreateConfigSQLite = flag.Bool("create-config-sqlite", false, "Create sample SQLite config file")
 	f.CreateConfigMySQL = flag.Bool("create-config-mysql", false, "Create sample MySQL config file")
+	f.CreateConfigOracle = flag.Bool("create-config-oracle", false, "Create sample Oracle config file")
 
 	// ETL Pipeline
 	f.Unsafe = flag.Bool("unsafe", false, "Enable unsafe mode for pipeline (allows all SQL, requires admin)")
 	f.UnsafeCert = flag.String("unsafe-cert", "", "path to unsafe-op.cert capability certificate")
+	f.StatsJSON = flag.String("stats-json", "", "Write pipeline run statistics (per source, step and output part: durations, rows, bytes, wait times, bottleneck) as JSON to file (- = stdout)")
+	f.ProgressFormat = flag.String("progress-format", "text", "Progress output for --pipeline/--import: text or json (newline-delimited events on stdout, human output moves to stderr)")
+	f.ErrorFormat = flag.String("error-format", "text", "Error output on failure: text or json (one object with error, class and exit_code on stderr)")
 
 	// Import precondition check (v1.4)
 	flag.Func("expect-var", "Require PipelineContext variable to match before import (name=value); repeatable", func(s string) error {
@@ -288,10 +484,14 @@ func ParseFlags() *Flags {
 	f.KeyFields = flag.String("key-fields", "", "Key fields for diff/merge (comma-separated)")
 	f.IgnoreFields = flag.String("ignore-fields", "", "Fields to ignore in diff (comma-separated)")
 	f.CaseSensitive = flag.Bool("case-sensitive", false, "Case-sensitive comparison for diff")
+	f.DeltaOutput = flag.String("delta-output", "", "Save diff modified rows as a delta packet (applied as UPDATEs on import)")
 	f.MergeStrategy = flag.String("merge-strategy", "union", "Merge strategy: union, intersection, left, right, append")
 	f.ShowConflicts = flag.Bool("show-conflicts", false, "Show detailed conflict information for merge")
 
 	// Misc
+	f.Plugins = flag.String("plugins", "", "Directory with external adapter plugins (default: $TDTP_PLUGIN_DIR)")
+	f.IsolateAdapters = flag.String("isolate-adapters", "", "Comma-separated adapter types to run in restartable child processes (default: $TDTP_ISOLATE_ADAPTERS)")
+	f.IsolateRecycle = flag.Int("isolate-recycle", 0, "With --isolate-adapters: replace the child process after this many calls (0 = never)")
 	f.Version = flag.Bool("version", false, "Show version information")
 	f.Help = flag.Bool("help", false, "Show detailed help with examples")
 	f.ShortHelp = flag.Bool("h", false, "Show brief help (commands and options)")
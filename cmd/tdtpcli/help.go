@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
 	versionpkg "github.com/ruslano69/tdtp-framework/pkg/core/version"
 )
 
@@ -17,11 +18,23 @@ var helpShortText string
 //go:embed help_full.txt
 var helpFullText string
 
-// PrintVersion prints version information
+// PrintVersion prints version information, the build parameters and the
+// database adapters compiled into this binary (see drivers_*.go build tags).
 func PrintVersion() {
 	fmt.Printf("tdtpcli version %s\n", version)
 	fmt.Println("TDTP Framework - Table Data Transfer Protocol")
 	fmt.Println("https://github.com/ruslano69/tdtp-framework")
+
+	build := adapters.Build()
+	fmt.Printf("\nBuild:    %s %s/%s, cgo=%t", build.GoVersion, build.GOOS, build.GOARCH, build.CGO)
+	if len(build.Tags) > 0 {
+		fmt.Printf(", tags=%s", strings.Join(build.Tags, ","))
+	}
+	fmt.Println()
+	fmt.Println("Adapters:")
+	for _, d := range adapters.Available() {
+		fmt.Printf("  %s\n", d)
+	}
 }
 
 // PrintShortHelp prints brief help information
@@ -4,6 +4,7 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -12,19 +13,20 @@ import (
 
 	"github.com/ruslano69/tdtp-framework/cmd/tdtpcli/commands"
 	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+	"github.com/ruslano69/tdtp-framework/pkg/adapters/plugin"
 	"github.com/ruslano69/tdtp-framework/pkg/audit"
 	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
 	"github.com/ruslano69/tdtp-framework/pkg/core/tdtql"
+	"github.com/ruslano69/tdtp-framework/pkg/history"
+	"github.com/ruslano69/tdtp-framework/pkg/operations"
+	"github.com/ruslano69/tdtp-framework/pkg/progress"
 	"github.com/ruslano69/tdtp-framework/pkg/storage"
-
-	// Database adapters - blank imports for init() registration
-	// SQLite is in a separate file (drivers_sqlite.go) with a build tag
-	// so it can be excluded in environments where modernc.org/sqlite is unavailable.
-	_ "github.com/ruslano69/tdtp-framework/pkg/adapters/mssql"
-	_ "github.com/ruslano69/tdtp-framework/pkg/adapters/mysql"
-	_ "github.com/ruslano69/tdtp-framework/pkg/adapters/postgres"
+	"github.com/ruslano69/tdtp-framework/pkg/sync"
 )
 
+// Database adapters are registered by blank imports in drivers_*.go, one
+// file per adapter with its own build tag (see "Release builds" in README).
+
 // routeCommand routes the command to the appropriate handler with production features
 func routeCommand(
 	ctx context.Context,
@@ -57,12 +59,28 @@ func routeCommand(
 		operation = audit.OpTransform
 		metadata = map[string]string{"command": "map", "mapping": *flags.Map, "input": *flags.MapInput}
 
+		approvalGate, gateErr := config.Approval.Open()
+		if gateErr != nil {
+			return gateErr
+		}
 		err = commands.RunMap(ctx, commands.MapOptions{
 			MappingFile: *flags.Map,
 			InputFile:   *flags.MapInput,
 			DryRun:      *flags.MapDryRun,
 			MercuryURL:  *flags.MercuryURL,
 			Listen:      *flags.Listen,
+			Approval:    approvalGate,
+		})
+
+	} else if *flags.TrainDict != "" {
+		operation = audit.OpTransform
+		metadata = map[string]string{"command": "train-dict", "output": *flags.TrainDict, "input": *flags.MapInput}
+
+		err = commands.TrainDict(commands.TrainDictOptions{
+			OutputFile: *flags.TrainDict,
+			Inputs:     *flags.MapInput,
+			Table:      *flags.Table,
+			MaxSizeKB:  *flags.DictSize,
 		})
 
 	} else if flags.List.IsSet {
@@ -81,6 +99,14 @@ func routeCommand(
 			return commands.ListViews(ctx, adapterConfig)
 		})
 
+	} else if *flags.ListSchemas {
+		operation = audit.OpQuery
+		metadata = map[string]string{"command": "list-schemas"}
+
+		err = prodFeatures.ExecuteWithResilience(ctx, "list-schemas", func() error {
+			return commands.ListSchemas(ctx, adapterConfig)
+		})
+
 	} else if *flags.ToCompact != "" {
 		operation = audit.OpTransform
 		outputCompact := determineOutputFile(*flags.Output, *flags.ToCompact, "xml")
@@ -117,6 +143,16 @@ func routeCommand(
 
 		outputFile := determineOutputFile(*flags.Output, *flags.Export, "tdtp.xml")
 
+		exclusions, exclErr := config.Export.Exclusions()
+		if exclErr != nil {
+			return exclErr
+		}
+
+		bundleKey, keyErr := commands.ReadBundleKeyFile(*flags.BundleKeyFile)
+		if keyErr != nil {
+			return keyErr
+		}
+
 		// Resolve storage target: s3:// URI → object storage; otherwise local file.
 		var exportStorageCfg *storage.Config
 		exportStorageKey := ""
@@ -138,6 +174,9 @@ func routeCommand(
 			"table":   *flags.Export,
 			"output":  determineOutputFile(*flags.Output, *flags.Export, "tdtp.xml"),
 		}
+		if *flags.Recipient != "" {
+			metadata["recipient"] = *flags.Recipient
+		}
 
 		err = prodFeatures.ExecuteWithResilience(ctx, "export-table", func() error {
 			return commands.ExportTable(ctx, adapterConfig, commands.ExportOptions{
@@ -146,6 +185,9 @@ func routeCommand(
 				Query:            query,
 				Fields:           splitCommaSeparated(*flags.Fields),
 				ProcessorMgr:     procMgr,
+				Recipient:        *flags.Recipient,
+				KeepProvenance:   *flags.KeepProvenance,
+				Exclusions:       exclusions,
 				Compress:         compress,
 				CompressLevel:    compressLevel,
 				CompressAlgo:     compressAlgo,
@@ -153,6 +195,8 @@ func routeCommand(
 				ReadOnlyFields:   *flags.ReadOnlyFields,
 				Fast:             *flags.Fast,
 				FallbackRowLimit: *flags.FallbackRowLimit,
+				Explain:          *flags.Explain,
+				TableQueries:     config.Export.TableQueries,
 				Compact:          *flags.Compact,
 				FixedFields:      splitCommaSeparated(*flags.FixedFields),
 				CompactTail:      *flags.CompactTail,
@@ -161,8 +205,11 @@ func routeCommand(
 				IntegrityV14:     *flags.Integrity,
 				MercuryURL:       *flags.MercuryURL,
 				MercuryCaller:    *flags.MercuryCaller,
+				RowChecksum:      *flags.RowChecksum,
 				Encrypt:          *flags.Encrypt || *flags.Enc13,
 				EncryptLegacy:    *flags.Enc13,
+				ManifestStats:    *flags.ManifestStats,
+				BundleKey:        bundleKey,
 			})
 		})
 
@@ -177,6 +224,24 @@ func routeCommand(
 
 		importFile := *flags.Import
 
+		bundleKey, keyErr := commands.ReadBundleKeyFile(*flags.BundleKeyFile)
+		if keyErr != nil {
+			return keyErr
+		}
+
+		var partition *adapters.PartitionRouting
+		if *flags.PartitionBy != "" {
+			partition = &adapters.PartitionRouting{
+				Column:     *flags.PartitionBy,
+				Interval:   adapters.PartitionInterval(*flags.PartitionInterval),
+				Pattern:    *flags.PartitionPattern,
+				AutoCreate: *flags.PartitionCreate,
+			}
+			if err := partition.Validate(); err != nil {
+				return fmt.Errorf("invalid --partition-by: %w", err)
+			}
+		}
+
 		// Resolve storage source: s3:// URI → object storage; otherwise local file.
 		var importStorageCfg *storage.Config
 		importStorageKey := ""
@@ -192,12 +257,20 @@ func routeCommand(
 			importFile = "" // not reading from local file
 		}
 
+		approvalGate, gateErr := config.Approval.Open()
+		if gateErr != nil {
+			return gateErr
+		}
+
 		operation = audit.OpImport
 		metadata = map[string]string{
 			"command":  "import",
 			"file":     *flags.Import,
 			"strategy": *flags.Strategy,
 		}
+		if *flags.VerifyManifest {
+			metadata["verify_manifest"] = "true"
+		}
 
 		err = prodFeatures.ExecuteWithResilience(ctx, "import-file", func() error {
 			return commands.ImportFile(ctx, adapterConfig, commands.ImportOptions{
@@ -212,6 +285,17 @@ func routeCommand(
 				SanitizeTranslit: *flags.Translit,
 				ExpectVars:       flags.ExpectVars,
 				MercuryURL:       *flags.MercuryURL,
+				Partition:        partition,
+				Provenance:       *flags.Provenance,
+				VerifyManifest:   *flags.VerifyManifest,
+				Analyze:          *flags.Analyze,
+				BundleKey:        bundleKey,
+				DryRun:           *flags.MapDryRun,
+				Simulate:         *flags.Simulate,
+				KeepScratch:      *flags.KeepScratch,
+				Approval:         approvalGate,
+				RequireApproval:  *flags.RequireApproval,
+				MergeColumns:     splitCommaSeparated(*flags.MergeColumns),
 			})
 		})
 
@@ -350,14 +434,23 @@ func routeCommand(
 
 		err = prodFeatures.ExecuteWithResilience(ctx, "xlsx-to-tdtp", func() error {
 			return commands.ConvertXLSXToTDTP(commands.XLSXOptions{
-				InputFile:  *flags.FromXLSX,
-				OutputFile: determineOutputFile(*flags.Output, *flags.FromXLSX, "tdtp.xml"),
-				SheetName:  *flags.Sheet,
+				InputFile:    *flags.FromXLSX,
+				OutputFile:   determineOutputFile(*flags.Output, *flags.FromXLSX, "tdtp.xml"),
+				SheetName:    *flags.Sheet,
+				OriginalFile: *flags.XLSXOriginal,
+				NumberFormat: *flags.NumberFormat,
+				Booleans:     *flags.Booleans,
+				DateFormats:  *flags.DateFormat,
 			})
 		})
 
 	} else if *flags.ExportXLSX != "" {
 		exXlsxOutputFile := determineOutputFile(*flags.Output, *flags.ExportXLSX, "xlsx")
+		exclusions, exclErr := config.Export.Exclusions()
+		if exclErr != nil {
+			return exclErr
+		}
+
 		var exXlsxStorageCfg *storage.Config
 		exXlsxStorageKey := ""
 		if storage.IsRemote(exXlsxOutputFile) {
@@ -388,6 +481,7 @@ func routeCommand(
 				ProcessorMgr: procMgr,
 				StorageCfg:   exXlsxStorageCfg,
 				StorageKey:   exXlsxStorageKey,
+				Exclusions:   exclusions,
 			})
 		})
 
@@ -410,6 +504,10 @@ func routeCommand(
 				SheetName:    *flags.Sheet,
 				Strategy:     strategy,
 				ProcessorMgr: procMgr,
+				OriginalFile: *flags.XLSXOriginal,
+				NumberFormat: *flags.NumberFormat,
+				Booleans:     *flags.Booleans,
+				DateFormats:  *flags.DateFormat,
 			})
 		})
 
@@ -448,9 +546,51 @@ func routeCommand(
 			"queue":   brokerCfg.Queue,
 		}
 
-		err = prodFeatures.ExecuteWithResilience(ctx, "export-to-broker", func() error {
+		switch brokerCfg.RowChecksum = *flags.RowChecksum; brokerCfg.RowChecksum {
+		case packet.ChecksumOff, packet.ChecksumPacket, packet.ChecksumRows:
+		default:
+			return fmt.Errorf("invalid --row-checksum %q (expected packet or rows)", brokerCfg.RowChecksum)
+		}
+
+		// Квота очереди проверяется до экспорта и вне resilience: повтор
+		// исчерпанную квоту не вернёт
+		if brokerCfg.Quota, err = config.Quota.Open(); err != nil {
+			return err
+		}
+		if brokerCfg.Quota != nil {
+			if _, err := brokerCfg.Quota.Check(brokerCfg.Queue, time.Now()); err != nil {
+				return err
+			}
+		}
+
+		// С историей доставка отслеживается по частям
+		historyStore, histErr := config.History.Open()
+		if histErr != nil {
+			return histErr
+		}
+		if historyStore != nil {
+			defer func() { _ = historyStore.Close() }()
+		} else if *flags.RetryParts != "" || *flags.PartsArchive != "" {
+			return fmt.Errorf("--retry-failed-parts and --parts-archive require a history: section in --config")
+		}
+		brokerCfg.History, brokerCfg.ArchiveDir, brokerCfg.RetryRun = historyStore, *flags.PartsArchive, *flags.RetryParts
+		if brokerCfg.Exclusions, err = config.Export.Exclusions(); err != nil {
+			return err
+		}
+		if brokerCfg.RetryRun != "" {
+			metadata["retry_of"] = brokerCfg.RetryRun
+		}
+
+		exportFn := func() error {
 			return commands.ExportToBroker(ctx, adapterConfig, &brokerCfg, *flags.ExportBroker, query, compress, compressLevel, brokerCompressAlgo, procMgr, *flags.PacketSize, *flags.MercuryURL, *flags.Encrypt || *flags.Enc13, *flags.Enc13)
-		})
+		}
+		if historyStore != nil {
+			// Повтор всей выгрузки отправил бы доставленные части ещё раз:
+			// недоставленные повторяет --retry-failed-parts
+			err = exportFn()
+		} else {
+			err = prodFeatures.ExecuteWithResilience(ctx, "export-to-broker", exportFn)
+		}
 
 	} else if *flags.ImportBroker {
 		// Design: target table name comes from the packet header (pkt.Header.TableName),
@@ -484,6 +624,46 @@ func routeCommand(
 			})
 		})
 
+		// Scheduled incremental sync daemon
+	} else if *flags.Daemon {
+		operation = audit.OpSync
+		metadata = map[string]string{
+			"command":     "daemon",
+			"sync_config": *flags.SyncConfig,
+		}
+		if *flags.SyncConfig == "" {
+			return commands.UsageErrorf("--daemon requires --sync-config <file>")
+		}
+
+		historyStore, histErr := config.History.Open()
+		if histErr != nil {
+			return histErr
+		}
+		if historyStore != nil {
+			defer func() { _ = historyStore.Close() }()
+		}
+
+		daemonOpts := commands.DaemonOptions{
+			ConfigFile:   *flags.SyncConfig,
+			ProcessorMgr: procMgr,
+			History:      historyStore,
+			TableQueries: config.Export.TableQueries,
+		}
+		if daemonOpts.Exclusions, err = config.Export.Exclusions(); err != nil {
+			return err
+		}
+		if prodFeatures.AuditLogger != nil {
+			daemonOpts.Audit = prodFeatures.AuditLogger
+		}
+		if *flags.TargetConfig != "" {
+			targetAdapterConfig, terr := loadTargetAdapterConfig(*flags.TargetConfig)
+			if terr != nil {
+				return terr
+			}
+			daemonOpts.Target = &targetAdapterConfig
+		}
+		err = commands.RunDaemon(ctx, adapterConfig, daemonOpts)
+
 		// Incremental Sync command
 	} else if *flags.SyncIncr != "" {
 		operation = audit.OpExport
@@ -495,18 +675,176 @@ func routeCommand(
 			"output":          determineOutputFile(*flags.Output, *flags.SyncIncr, "xml"),
 		}
 
+		deletes, derr := sync.ParseDeleteDetection(*flags.SyncDeletes)
+		if derr != nil {
+			return fmt.Errorf("--sync-deletes: %w", derr)
+		}
+		if deletes.Strategy != sync.DeleteNone {
+			metadata["sync_deletes"] = *flags.SyncDeletes
+		}
+		if *flags.SyncCDC != "" {
+			delete(metadata, "tracking_field")
+			metadata["sync_cdc"] = *flags.SyncCDC
+		}
+		if *flags.SyncCT {
+			delete(metadata, "tracking_field")
+			metadata["sync_change_tracking"] = "true"
+		}
+		schemaDrift, perr := sync.ParseSchemaDriftPolicy(*flags.SyncDrift)
+		if perr != nil {
+			return fmt.Errorf("--sync-schema-drift: %w", perr)
+		}
+		metadata["sync_schema_drift"] = string(schemaDrift)
+		var syncTarget *adapters.Config
+		if *flags.TargetConfig != "" {
+			targetAdapterConfig, terr := loadTargetAdapterConfig(*flags.TargetConfig)
+			if terr != nil {
+				return terr
+			}
+			syncTarget = &targetAdapterConfig
+		} else if schemaDrift == sync.DriftMigrate {
+			return commands.UsageErrorf("--sync-schema-drift migrate requires --target-config <file>")
+		}
+		syncOpts := commands.SyncOptions{
+			TableName:      *flags.SyncIncr,
+			OutputFile:     determineOutputFile(*flags.Output, *flags.SyncIncr, "xml"),
+			TrackingField:  *flags.TrackingField,
+			CheckpointFile: *flags.CheckpointFile,
+			BatchSize:      *flags.BatchSize,
+			Fields:         splitCommaSeparated(*flags.Fields),
+			ProcessorMgr:   procMgr,
+			TableQueries:   config.Export.TableQueries,
+			Deletes:        deletes,
+			CDCSlot:        *flags.SyncCDC,
+			ChangeTracking: *flags.SyncCT,
+			SchemaDrift:    schemaDrift,
+			Target:         syncTarget,
+		}
+		if syncOpts.Exclusions, err = config.Export.Exclusions(); err != nil {
+			return err
+		}
+		if prodFeatures.AuditLogger != nil {
+			syncOpts.Audit = prodFeatures.AuditLogger
+		}
+
+		historyStore, histErr := config.History.Open()
+		if histErr != nil {
+			return histErr
+		}
+		if historyStore != nil {
+			defer func() { _ = historyStore.Close() }()
+		}
+
 		err = prodFeatures.ExecuteWithResilience(ctx, "incremental-sync", func() error {
-			return commands.IncrementalSync(ctx, adapterConfig, commands.SyncOptions{
-				TableName:      *flags.SyncIncr,
-				OutputFile:     determineOutputFile(*flags.Output, *flags.SyncIncr, "xml"),
-				TrackingField:  *flags.TrackingField,
-				CheckpointFile: *flags.CheckpointFile,
-				BatchSize:      *flags.BatchSize,
-				Fields:         splitCommaSeparated(*flags.Fields),
-				ProcessorMgr:   procMgr,
+			syncOpts.History = historyStore
+			return commands.IncrementalSync(ctx, adapterConfig, syncOpts)
+		})
+
+		// Merkle reconciliation command
+	} else if *flags.Reconcile != "" {
+		operation = audit.OpQuery
+		metadata = map[string]string{
+			"command":       "reconcile",
+			"table":         *flags.Reconcile,
+			"target_config": *flags.TargetConfig,
+		}
+		if *flags.TargetConfig == "" {
+			return commands.UsageErrorf("--reconcile requires --target-config <file> (the source is --config)")
+		}
+		targetAdapterConfig, cerr := loadTargetAdapterConfig(*flags.TargetConfig)
+		if cerr != nil {
+			return cerr
+		}
+
+		err = prodFeatures.ExecuteWithResilience(ctx, "reconcile", func() error {
+			return commands.Reconcile(ctx, *adapterConfig, targetAdapterConfig, commands.ReconcileOptions{
+				TableName:     *flags.Reconcile,
+				KeyFields:     splitCommaSeparated(*flags.KeyFields),
+				Depth:         *flags.MerkleDepth,
+				OutputFile:    *flags.Output,
+				Apply:         *flags.ReconcileApply,
+				StateDir:      *flags.ReconcileState,
+				TrackingField: *flags.TrackingField,
+			})
+		})
+
+		// GDPR subject erasure command
+	} else if *flags.Erase != "" {
+		operation = audit.OpDelete
+		metadata = map[string]string{
+			"command":     "erase",
+			"table":       *flags.Erase,
+			"subject_ref": *flags.SubjectRef,
+			"targets":     *flags.EraseTargets,
+		}
+		subjectQuery := query
+		if subjectQuery != nil && subjectQuery.Filters == nil {
+			subjectQuery = nil // только --fields/--limit — не фильтр субъекта
+		}
+		if len(flags.EraseKeys) == 0 && subjectQuery == nil {
+			return commands.UsageErrorf("--erase requires --erase-key <value> or --where <filter> identifying the subject")
+		}
+		if len(flags.EraseKeys) > 0 && subjectQuery != nil {
+			return commands.UsageErrorf("--erase-key and --where are mutually exclusive")
+		}
+		keys := make([][]string, len(flags.EraseKeys))
+		for i, k := range flags.EraseKeys {
+			keys[i] = strings.Split(k, ",")
+		}
+
+		targets := []commands.EraseTarget{{Name: "source", Config: *adapterConfig}}
+		for _, file := range splitCommaSeparated(*flags.EraseTargets) {
+			targetConfig, cerr := LoadConfig(file)
+			if cerr != nil {
+				return commands.WithClass(commands.ClassConfig, fmt.Errorf("failed to load erase target %s: %w", file, cerr))
+			}
+			if err := commands.GateAdapter(targetConfig.Database.Type); err != nil {
+				return err
+			}
+			targetAdapterConfig, cerr := buildAdapterConfig(targetConfig)
+			if cerr != nil {
+				return commands.WithClass(commands.ClassConfig, fmt.Errorf("erase target %s: %w", file, cerr))
+			}
+			targets = append(targets, commands.EraseTarget{
+				Name:   strings.TrimSuffix(filepath.Base(file), filepath.Ext(file)),
+				Config: targetAdapterConfig,
 			})
+		}
+
+		var receipt *sync.ErasureReceipt
+		err = prodFeatures.ExecuteWithResilience(ctx, "erase", func() error {
+			var eraseErr error
+			receipt, eraseErr = commands.Erase(ctx, commands.EraseOptions{
+				TableName:   *flags.Erase,
+				Keys:        keys,
+				Query:       subjectQuery,
+				SubjectRef:  *flags.SubjectRef,
+				Reason:      *flags.EraseReason,
+				Targets:     targets,
+				PacketFile:  *flags.Output,
+				ReceiptFile: *flags.EraseReceipt,
+			})
+			return eraseErr
 		})
 
+		// Аудит-след по каждой копии: доказательство для регулятора.
+		if receipt != nil {
+			metadata["keys_digest"] = receipt.KeysDigest
+			for _, tr := range receipt.Targets {
+				var trErr error
+				if !tr.Verified {
+					trErr = fmt.Errorf("erasure not verified: %d row(s) remain %s", tr.Remaining, tr.Error)
+				}
+				prodFeatures.LogWithMetadata(ctx, audit.OpDelete, tr.Verified, trErr, map[string]string{
+					"command":     "erase",
+					"target":      tr.Name,
+					"subject_ref": receipt.SubjectRef,
+					"keys_digest": receipt.KeysDigest,
+					"proof":       tr.Proof,
+				}, receipt.Table, int64(tr.RowsDeleted), 0)
+			}
+		}
+
 		// ETL Pipeline command
 	} else if *flags.Pipeline != "" {
 		operation = audit.OpTransform
@@ -534,12 +872,51 @@ func routeCommand(
 			EncryptLegacy:  *flags.Enc13,
 			EncDev:         encDev,
 			Variables:      flags.PipelineVars,
+			StatsJSON:      *flags.StatsJSON,
+		}
+
+		historyStore, histErr := config.History.Open()
+		if histErr != nil {
+			return histErr
+		}
+		if historyStore != nil {
+			defer func() { _ = historyStore.Close() }()
+			pipelineOpts.History = historyStore
 		}
 
 		err = prodFeatures.ExecuteWithResilience(ctx, "etl-pipeline", func() error {
 			return commands.ExecutePipeline(ctx, *flags.Pipeline, pipelineOpts)
 		})
 
+		// Run history query
+	} else if *flags.History {
+		operation = audit.OpQuery
+		metadata = map[string]string{"command": "history"}
+
+		historyStore, histErr := config.History.Open()
+		if histErr != nil {
+			return histErr
+		}
+		if historyStore == nil {
+			return fmt.Errorf("--history requires a history: section in --config (type: sqlite|postgres, dsn: ...)")
+		}
+		defer func() { _ = historyStore.Close() }()
+
+		since, sinceErr := commands.ParseHistorySince(*flags.HistorySince)
+		if sinceErr != nil {
+			return sinceErr
+		}
+		err = commands.ShowHistory(ctx, historyStore, commands.HistoryOptions{
+			RunID: *flags.HistoryID,
+			Filter: history.Filter{
+				Kind:   history.Kind(*flags.HistoryKind),
+				Name:   *flags.HistoryName,
+				Status: history.Status(*flags.HistoryStatus),
+				Since:  since,
+				Limit:  *flags.Limit,
+			},
+		})
+
 		// Process Request command
 	} else if *flags.ProcessRequest != "" {
 		operation = audit.OpQuery
@@ -551,12 +928,23 @@ func routeCommand(
 		// Директория для поиска конфигов: рядом с файлом запроса, затем текущая директория
 		configsDir := filepath.Dir(*flags.ProcessRequest)
 
+		exportPolicy, policyErr := config.ExportPolicy.Policy()
+		if policyErr != nil {
+			return policyErr
+		}
+		exclusions, exclErr := config.Export.Exclusions()
+		if exclErr != nil {
+			return exclErr
+		}
+
 		err = prodFeatures.ExecuteWithResilience(ctx, "process-request", func() error {
 			return commands.ProcessRequest(ctx, commands.ProcessRequestOptions{
 				RequestFile:   *flags.ProcessRequest,
 				OutputFile:    *flags.Output,
 				ConfigsDir:    configsDir,
 				DefaultConfig: adapterConfig,
+				Policy:        exportPolicy,
+				Exclusions:    exclusions,
 			})
 		})
 
@@ -585,6 +973,7 @@ func routeCommand(
 				IgnoreFields:  splitCommaSeparated(*flags.IgnoreFields),
 				CaseSensitive: *flags.CaseSensitive,
 				OutputFormat:  "text",
+				DeltaOutput:   *flags.DeltaOutput,
 			})
 		})
 
@@ -632,6 +1021,24 @@ func routeCommand(
 		}
 		return commands.TestFile(ctx, *flags.Test, testStorageCfg)
 
+		// Lint command — deep spec validation of local files, no DB required
+	} else if *flags.Lint != "" {
+		return commands.LintFiles(ctx, splitCommaSeparated(*flags.Lint), *flags.LintFormat)
+
+		// Decrypt archive command — escrow private key, no DB or Mercury required
+	} else if *flags.DecryptArchive != "" {
+		operation = audit.OpTransform
+		metadata = map[string]string{
+			"command": "decrypt-archive",
+			"input":   *flags.DecryptArchive,
+			"output":  *flags.Output,
+		}
+		err = commands.DecryptArchive(ctx, commands.DecryptArchiveOptions{
+			Input:         *flags.DecryptArchive,
+			Output:        *flags.Output,
+			EscrowKeyFile: *flags.EscrowPrivateKey,
+		})
+
 		// Inspect command — no DB connection required, runs directly
 	} else if *flags.Inspect != "" {
 		var inspectStorageCfg *storage.Config
@@ -659,6 +1066,63 @@ func routeCommand(
 			return commands.InspectTable(ctx, adapterConfig, *flags.InspectTable)
 		})
 
+		// DDL generation — from a TDTP file needs no DB connection
+	} else if *flags.GenDDL != "" {
+		return commands.GenerateDDL(ctx, adapterConfig, commands.GenDDLOptions{
+			Source:     *flags.GenDDL,
+			Target:     *flags.GenDDLTarget,
+			Table:      *flags.Table,
+			OutputFile: *flags.Output,
+		})
+
+		// Sample export — consistent dev/test dataset from several tables
+	} else if *flags.ExportSample != "" {
+		operation = audit.OpExport
+		metadata = map[string]string{
+			"command": "export-sample",
+			"config":  *flags.ExportSample,
+			"output":  *flags.Output,
+		}
+
+		err = prodFeatures.ExecuteWithResilience(ctx, "export-sample", func() error {
+			return commands.ExportSample(ctx, adapterConfig, commands.SampleOptions{
+				ConfigFile:   *flags.ExportSample,
+				OutputDir:    *flags.Output,
+				ProcessorMgr: procMgr,
+			})
+		})
+
+		// Load test — runs for --duration, so it bypasses the resilience wrapper
+		// (a retry would silently double the measured run)
+	} else if *flags.Bench != "" {
+		strategy, stratErr := commands.ParseImportStrategy(*flags.Strategy)
+		if stratErr != nil {
+			return stratErr
+		}
+		brokerCfg := buildBrokerConfig(config)
+
+		operation = audit.OpImport
+		metadata = map[string]string{
+			"command":     "bench",
+			"path":        *flags.Bench,
+			"concurrency": fmt.Sprint(*flags.Concurrency),
+			"duration":    flags.Duration.String(),
+		}
+
+		err = commands.Bench(ctx, adapterConfig, commands.BenchOptions{
+			Path:          *flags.Bench,
+			Table:         *flags.Table,
+			Concurrency:   *flags.Concurrency,
+			Duration:      *flags.Duration,
+			RowsPerPacket: *flags.BenchRows,
+			Strategy:      strategy,
+			Compress:      *flags.Compress,
+			CompressLevel: *flags.CompressLevel,
+			CompressAlgo:  *flags.CompressAlgo,
+			ProcessorMgr:  procMgr,
+			BrokerCfg:     &brokerCfg,
+		})
+
 		// [BETA] Streaming consumer daemon — Kafka only
 	} else if *flags.Listen {
 		strategy, stratErr := commands.ParseImportStrategy(*flags.Strategy)
@@ -688,6 +1152,7 @@ func routeCommand(
 	// argument (entry.Duration) — not duplicated into metadata["duration_ms"],
 	// since the audit line and DB appender both read it from entry.Duration.
 	if metadata != nil {
+		maps.Copy(metadata, opMetrics.ApprovalMetadata())
 		elapsed := time.Since(startTime)
 		prodFeatures.LogWithMetadata(ctx, operation, err == nil, err, metadata,
 			opMetrics.Resource, opMetrics.RecordsAffected, elapsed)
@@ -697,10 +1162,24 @@ func routeCommand(
 }
 
 func main() {
+	// A child process of an isolated adapter serves it and exits here
+	plugin.ServeIsolated()
+
 	ctx := context.Background()
 
 	// Parse flags
 	flags := ParseFlags()
+	switch *flags.ErrorFormat {
+	case "text", "json":
+		errorFormat = *flags.ErrorFormat
+	default:
+		fatalClass(commands.ClassUsage, "invalid --error-format %q (valid: text, json)", *flags.ErrorFormat)
+	}
+
+	// External adapter plugins register before anything lists or creates
+	// adapters, so --version and database.type see them too
+	loadPlugins(ctx, *flags.Plugins)
+	isolateAdapters(*flags.IsolateAdapters, *flags.IsolateRecycle)
 
 	// Handle version
 	if *flags.Version {
@@ -737,18 +1216,48 @@ func main() {
 		createConfigTemplate("mysql")
 		return
 	}
+	if *flags.CreateConfigOracle {
+		createConfigTemplate("oracle")
+		return
+	}
+
+	// Operations registry: list / cancel in-flight runs of any process on
+	// this host — no config or database needed
+	if *flags.Operations {
+		if err := commands.ListOperations(operations.DefaultDir()); err != nil {
+			fatal("%w", err)
+		}
+		return
+	}
+	if *flags.Cancel != "" {
+		if err := commands.CancelOperation(operations.DefaultDir(), *flags.Cancel); err != nil {
+			fatal("%w", err)
+		}
+		return
+	}
 
 	// If no command was specified, show help before attempting to load config
 	if !commandWasSpecified(flags) {
 		PrintHelp()
-		os.Exit(1)
+		os.Exit(commands.ClassUsage.ExitCode())
+	}
+
+	// --progress-format json: stdout отдаётся NDJSON-событиям, весь
+	// человекочитаемый вывод (fmt.Printf) переезжает в stderr
+	switch *flags.ProgressFormat {
+	case "text":
+	case "json":
+		ctx = progress.WithReporter(ctx, progress.NewJSONReporter(os.Stdout))
+		os.Stdout = os.Stderr
+	default:
+		fatalClass(commands.ClassUsage, "invalid --progress-format %q (valid: text, json)", *flags.ProgressFormat)
 	}
 
 	// Resolve and verify the license (offline). A present-but-invalid license
 	// is fatal; absent license → community floor (sqlite only, no enc/unsafe).
 	lic, err := commands.ResolveLicense(*flags.License)
 	if err != nil {
-		fatal("%v", err)
+		fatal("%w", err)
 	}
 	if !lic.IsCommunity() {
 		fmt.Printf("License: %s\n", lic.Summary())
@@ -757,27 +1266,32 @@ func main() {
 	// Feature gates: refuse licensed-only flags up front (before any DB work).
 	if *flags.Encrypt || *flags.Enc13 {
 		if err := commands.GateFeature("enc"); err != nil {
-			fatal("%v", err)
+			fatal("%w", err)
 		}
 	}
 	if *flags.Unsafe {
 		if err := commands.GateFeature("unsafe"); err != nil {
-			fatal("%v", err)
+			fatal("%w", err)
 		}
 	}
 
 	// Commands that operate on files only and never connect to a database
 	// can run without a config file — skip loading entirely.
 	noDBRequired := *flags.Pipeline != "" ||
+		*flags.History || // history store has its own dsn (history: section)
 		*flags.Steps != "" || // --steps launches sub-processes that each load their own config
 		*flags.Inspect != "" ||
+		*flags.DecryptArchive != "" ||
 		*flags.Test != "" ||
+		*flags.Lint != "" ||
 		*flags.Diff != "" ||
 		*flags.Merge != "" ||
 		*flags.ToHTML != "" ||
 		*flags.ToCSV != "" ||
 		*flags.ToCompact != "" ||
 		*flags.Map != "" || // --map uses its own target DSN from mapping.yaml, not config.yaml
+		*flags.TrainDict != "" ||
+		(*flags.GenDDL != "" && fileExists(*flags.GenDDL)) || // schema from a TDTP file
 		(*flags.ImportBroker && *flags.Output != "") || // save-to-file mode: no DB needed
 		(*flags.ImportBroker && *flags.RawBroker) // raw mode: no DB needed
 
@@ -792,7 +1306,7 @@ func main() {
 	} else {
 		cfg, err := LoadConfig(*flags.Config)
 		if err != nil {
-			fatal("Failed to load config: %v", err)
+			fatalClass(commands.ClassConfig, "Failed to load config: %w", err)
 		}
 		config = cfg
 	}
@@ -800,7 +1314,7 @@ func main() {
 	// Initialize production features (Circuit Breaker, Audit, Retry)
 	prodFeatures, err := InitProductionFeatures(config)
 	if err != nil {
-		fatal("Failed to initialize production features: %v", err)
+		fatalClass(commands.ClassConfig, "Failed to initialize production features: %w", err)
 	}
 	// Не используем defer т.к. os.Exit() не вызовет defer
 	// Close() вызывается явно в конце функции
@@ -811,39 +1325,68 @@ func main() {
 	// Configure processors from flags
 	if *flags.Mask != "" {
 		if err := procMgr.AddMaskProcessor(*flags.Mask); err != nil {
-			fatal("Failed to configure mask processor: %v", err)
+			fatalClass(commands.ClassUsage, "Failed to configure mask processor: %w", err)
 		}
 	}
 	if *flags.Validate != "" {
 		if err := procMgr.AddValidateProcessor(*flags.Validate); err != nil {
-			fatal("Failed to configure validate processor: %v", err)
+			fatalClass(commands.ClassUsage, "Failed to configure validate processor: %w", err)
 		}
 	}
 	if *flags.Normalize != "" {
 		if err := procMgr.AddNormalizeProcessor(*flags.Normalize); err != nil {
-			fatal("Failed to configure normalize processor: %v", err)
+			fatalClass(commands.ClassUsage, "Failed to configure normalize processor: %w", err)
+		}
+	}
+	if *flags.Tokenize != "" {
+		if err := procMgr.AddTokenizeProcessor(*flags.Tokenize); err != nil {
+			fatalClass(commands.ClassUsage, "Failed to configure tokenize processor: %w", err)
 		}
 	}
 
+	// Compression dictionaries: flag takes precedence, then config
+	dictDir := *flags.DictDir
+	if dictDir == "" {
+		dictDir = config.Export.DictDir
+	}
+	if err := commands.LoadCompressionDicts(dictDir, *flags.EmbedDict || config.Export.EmbedDict); err != nil {
+		fatalClass(commands.ClassConfig, "%w", err)
+	}
+
 	// Build adapter config
-	adapterConfig := adapters.Config{
-		Type:    config.Database.Type,
-		DSN:     config.Database.BuildDSN(),
-		Charset: config.Database.Charset,
+	adapterConfig, err := buildAdapterConfig(config)
+	if err != nil {
+		fatalClass(commands.ClassConfig, "%w", err)
+	}
+	adapterConfig.CreateSchemas = *flags.CreateSchema
+
+	// Value mapping may read reference tables from the configured database
+	if *flags.MapValues != "" {
+		if err := procMgr.AddValueMapProcessor(*flags.MapValues, adapterConfig); err != nil {
+			fatal("Failed to configure value mapper: %w", err)
+		}
 	}
 
 	// License gate: the configured DB adapter must be permitted.
 	// Empty type (file-only commands without a real DB) is not gated here.
 	if config.Database.Type != "" {
 		if err := commands.GateAdapter(config.Database.Type); err != nil {
-			fatal("%v", err)
+			fatal("%w", err)
 		}
 	}
 
 	// Build TDTQL query from flags
 	query, err := BuildTDTQLQuery([]string(flags.Where), *flags.OrderBy, *flags.Limit, *flags.Offset)
 	if err != nil {
-		fatal("Failed to build query: %v", err)
+		fatalClass(commands.ClassUsage, "Failed to build query: %w", err)
+	}
+
+	// Keyset cursor (--after): page after the given primary key, see base.keysetQuery.
+	if *flags.After != "" {
+		if query == nil {
+			query = packet.NewQuery()
+		}
+		query.After = strings.Split(*flags.After, ",")
 	}
 
 	// Inject column projection into query when --fields is specified.
@@ -857,13 +1400,32 @@ func main() {
 		query.Fields = splitCommaSeparated(*flags.Fields)
 	}
 
+	// Register long-running commands so they can be listed (--operations,
+	// tdtpserve /api/operations) and cancelled without killing the process
+	var op *operations.Operation
+	if command, name := operationCommand(flags); command != "" {
+		if reg, regErr := operations.Open(operations.DefaultDir()); regErr != nil {
+			fmt.Printf("  ⚠ Operations registry unavailable: %v\n", regErr)
+		} else if opCtx, started, startErr := reg.Start(ctx, command, name); startErr != nil {
+			fmt.Printf("  ⚠ Operations registry unavailable: %v\n", startErr)
+		} else {
+			ctx, op = opCtx, started
+		}
+	}
+
 	// Route commands with production features and processors
 	cmdErr := routeCommand(ctx, flags, config, &adapterConfig, query, prodFeatures, procMgr)
+	op.End()
 
 	// Handle errors
 	if cmdErr != nil {
-		fatal("Command failed: %v", cmdErr)
+		// fatal exits without defers: flush the failed run's spans first
+		if err := prodFeatures.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to close production features: %v\n", err)
+		}
+		fatal("Command failed: %w", cmdErr)
 	}
+	procMgr.PrintUnmappedValues()
 
 	// Close production features before normal exit
 	if err := prodFeatures.Close(); err != nil {
@@ -876,7 +1438,7 @@ func createConfigTemplate(dbType string) {
 	config := CreateSampleConfig(dbType)
 
 	if err := SaveConfig("config.yaml", config); err != nil {
-		fatal("Failed to save config: %v", err)
+		fatal("Failed to save config: %w", err)
 	}
 
 	fmt.Printf("✓ Created sample %s config: config.yaml\n", dbType)
@@ -905,6 +1467,8 @@ func buildBrokerConfig(config *Config) commands.BrokerConfig {
 		QueuePath:      config.Broker.QueuePath,
 		Brokers:        config.Broker.Brokers,
 		ConsumerGroup:  config.Broker.ConsumerGroup,
+		Dir:            config.Broker.Dir,
+		AutoAck:        config.Broker.AutoAck,
 	}
 }
 
@@ -927,11 +1491,49 @@ func splitCommaSeparated(s string) []string {
 	return tdtql.SplitFieldList(s)
 }
 
+// operationCommand returns the operations-registry command and name for
+// long-running commands; empty command means the run is not registered.
+func operationCommand(flags *Flags) (command, name string) {
+	switch {
+	case *flags.Export != "":
+		return "export", *flags.Export
+	case *flags.Import != "":
+		return "import", *flags.Import
+	case *flags.ExportXLSX != "":
+		return "export-xlsx", *flags.ExportXLSX
+	case *flags.ImportXLSX != "":
+		return "import-xlsx", *flags.ImportXLSX
+	case *flags.ExportBroker != "":
+		return "export-broker", *flags.ExportBroker
+	case *flags.ImportBroker:
+		return "import-broker", ""
+	case *flags.SyncIncr != "":
+		return "sync-incremental", *flags.SyncIncr
+	case *flags.Reconcile != "":
+		return "reconcile", *flags.Reconcile
+	case *flags.Pipeline != "":
+		return "pipeline", *flags.Pipeline
+	case *flags.Steps != "":
+		return "steps", *flags.Steps
+	case *flags.Map != "":
+		return "map", *flags.Map
+	case *flags.Daemon:
+		return "daemon", ""
+	case *flags.Listen:
+		return "listen", ""
+	case *flags.Bench != "":
+		return "bench", *flags.Bench
+	}
+	return "", ""
+}
+
 // commandWasSpecified checks if any command was specified
 func commandWasSpecified(flags *Flags) bool {
 	return *flags.Test != "" ||
+		*flags.Lint != "" ||
 		flags.List.IsSet ||
 		*flags.ListViews ||
+		*flags.ListSchemas ||
 		*flags.Export != "" ||
 		*flags.Import != "" ||
 		*flags.ToCompact != "" ||
@@ -944,19 +1546,109 @@ func commandWasSpecified(flags *Flags) bool {
 		*flags.ExportBroker != "" ||
 		*flags.ImportBroker ||
 		*flags.SyncIncr != "" ||
+		*flags.Daemon ||
+		*flags.Reconcile != "" ||
+		*flags.Erase != "" ||
+		*flags.History ||
 		*flags.Pipeline != "" ||
 		*flags.ProcessRequest != "" ||
 		*flags.Diff != "" ||
 		*flags.Merge != "" ||
 		*flags.Inspect != "" ||
+		*flags.DecryptArchive != "" ||
 		*flags.InspectTable != "" ||
+		*flags.GenDDL != "" ||
+		*flags.ExportSample != "" ||
+		*flags.Bench != "" ||
 		*flags.Listen ||
 		*flags.Map != "" ||
+		*flags.TrainDict != "" ||
 		*flags.Steps != ""
 }
 
-// fatal prints error and exits
+// fileExists reports whether path is an existing regular file
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
+
+// errorFormat is --error-format: text ("Error: ...") or json (commands.ErrorReport).
+var errorFormat = "text"
+
+// fatal prints the error to stderr and exits with the code of its class
+// (commands.Classify); wrap causes with %w so their class is preserved.
 func fatal(format string, args ...any) {
-	fmt.Fprintf(os.Stderr, "Error: "+format+"\n", args...)
-	os.Exit(1)
+	os.Exit(commands.WriteError(os.Stderr, errorFormat, fmt.Errorf(format, args...)))
+}
+
+// fatalClass is fatal for errors of a known class (config, usage, ...).
+func fatalClass(class commands.ErrorClass, format string, args ...any) {
+	os.Exit(commands.WriteError(os.Stderr, errorFormat, commands.WithClass(class, fmt.Errorf(format, args...))))
+}
+
+// buildAdapterConfig строит конфигурацию адаптера из секции database.
+func buildAdapterConfig(config *Config) (adapters.Config, error) {
+	key, err := config.Database.Encryption.ResolveKey(config.Database.Type)
+	if err != nil {
+		return adapters.Config{}, err
+	}
+	queryLog, err := config.Database.QueryLog.ToAdapterConfig()
+	if err != nil {
+		return adapters.Config{}, err
+	}
+	maintenance, err := config.Database.Maintenance.ToAdapterConfig()
+	if err != nil {
+		return adapters.Config{}, err
+	}
+	columnCipher, err := config.ColumnEncryption.Cipher()
+	if err != nil {
+		return adapters.Config{}, err
+	}
+	exportPolicy, err := config.ExportPolicy.Policy()
+	if err != nil {
+		return adapters.Config{}, err
+	}
+	cfg := adapters.Config{
+		Type:              config.Database.Type,
+		DSN:               config.Database.BuildDSN(),
+		Charset:           config.Database.Charset,
+		CompatibilityMode: config.Database.CompatibilityMode,
+
+		ImportLimits:   config.Database.ImportLimits.ToAdapterLimits(),
+		EncryptionKey:  key,
+		Booleans:       config.Database.Booleans,
+		ColumnBooleans: config.Database.ColumnBooleans,
+		QueryLog:       queryLog,
+		TableLock:      config.Database.TableLock.ToAdapterConfig(),
+		Maintenance:    maintenance,
+		Duplicates:     adapters.DuplicateMode(config.Database.Duplicates),
+		Columns:        adapters.ColumnMatching(config.Database.Columns),
+		RowErrors:      config.Database.RowErrors.ToAdapterConfig(),
+		Packets:        config.Database.Packets.ToAdapterConfig(),
+		ColumnCipher:   columnCipher,
+		ExportPolicy:   exportPolicy,
+	}
+	// PostgreSQL получает схему через search_path в DSN; Oracle — владелец
+	// таблиц по умолчанию, в DSN его не передать
+	if config.Database.Type == "oracle" {
+		cfg.Schema = config.Database.Schema
+	}
+	return cfg, nil
+}
+
+// loadTargetAdapterConfig читает --target-config: приёмник сверки и
+// миграции схемы синхронизации.
+func loadTargetAdapterConfig(path string) (adapters.Config, error) {
+	targetConfig, err := LoadConfig(path)
+	if err != nil {
+		return adapters.Config{}, commands.WithClass(commands.ClassConfig, fmt.Errorf("failed to load target config: %w", err))
+	}
+	if err := commands.GateAdapter(targetConfig.Database.Type); err != nil {
+		return adapters.Config{}, err
+	}
+	cfg, err := buildAdapterConfig(targetConfig)
+	if err != nil {
+		return adapters.Config{}, commands.WithClass(commands.ClassConfig, fmt.Errorf("target config: %w", err))
+	}
+	return cfg, nil
 }
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters/plugin"
+)
+
+// loadPlugins registers external adapters (pkg/adapters/plugin) from dir or,
+// when dir is empty, from TDTP_PLUGIN_DIR. A broken plugin is reported and
+// skipped: it must not block commands that never use its adapter type.
+// Warnings go to stderr, stdout may carry --progress-format json events.
+func loadPlugins(ctx context.Context, dir string) {
+	if dir == "" {
+		dir = plugin.DefaultDir()
+	}
+	if dir == "" {
+		return
+	}
+	if _, err := plugin.LoadDir(ctx, dir); err != nil {
+		fmt.Fprintf(os.Stderr, "  ⚠ plugins: %v\n", err)
+	}
+}
+
+// isolateAdapters moves the listed compiled-in adapters (or, when list is
+// empty, TDTP_ISOLATE_ADAPTERS) into child processes, so a crashing driver
+// costs a restart of that process instead of the whole run. Unknown types
+// are reported and skipped like broken plugins.
+func isolateAdapters(list string, recycleAfter int) {
+	if list == "" {
+		list = plugin.DefaultIsolated()
+	}
+	types := plugin.ParseIsolated(list)
+	if len(types) == 0 {
+		return
+	}
+	if err := plugin.Isolate(plugin.IsolateOptions{RecycleAfter: recycleAfter}, types...); err != nil {
+		fmt.Fprintf(os.Stderr, "  ⚠ isolate-adapters: %v\n", err)
+	}
+}
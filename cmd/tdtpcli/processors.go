@@ -4,8 +4,10 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
 	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
 	"github.com/ruslano69/tdtp-framework/pkg/processors"
 	"gopkg.in/yaml.v3"
@@ -145,6 +147,109 @@ func (pm *ProcessorManager) AddNormalizeProcessor(rulesFile string) error {
 	return nil
 }
 
+// AddTokenizeProcessor adds field tokenization processor from YAML file.
+// Format: --tokenize tokenizer.yaml
+//
+// YAML structure:
+//
+//	fields: [card_number, pan]
+//	url: https://tokenizer.internal
+//	auth_token_env: TOKENIZER_TOKEN   # optional, Authorization: Bearer
+//	batch_size: 500                   # optional
+//	cache_size: 100000                # optional
+//	timeout_ms: 5000                  # optional
+func (pm *ProcessorManager) AddTokenizeProcessor(rulesFile string) error {
+	if rulesFile == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(rulesFile)
+	if err != nil {
+		return fmt.Errorf("failed to read tokenize config %q: %w", rulesFile, err)
+	}
+
+	var params map[string]any
+	if err := yaml.Unmarshal(data, &params); err != nil {
+		return fmt.Errorf("failed to parse tokenize config %q: %w", rulesFile, err)
+	}
+
+	tokenizer, err := processors.NewFieldTokenizerFromConfig(params)
+	if err != nil {
+		return fmt.Errorf("failed to create tokenizer from %q: %w", rulesFile, err)
+	}
+
+	pm.chain.Add(tokenizer)
+	fmt.Printf("✓ Added field tokenizer from: %s\n", rulesFile)
+
+	return nil
+}
+
+// AddValueMapProcessor adds value translation (enum/lookup codes) from YAML file.
+// Format: --map-values mapping.yaml
+//
+// YAML structure:
+//
+//	unmapped: fail            # pass (default) | null | fail
+//	fields:
+//	  status:
+//	    values: {"10": ACTIVE, "20": BLOCKED}
+//	  region_id:              # reference table, read from the command's database
+//	    table: regions        # unless a lookup: {type, dsn} section is given
+//	    key: id
+//	    value: name
+//	    reverse: true         # name → id
+//
+// Unmapped values are reported by PrintUnmappedValues.
+func (pm *ProcessorManager) AddValueMapProcessor(rulesFile string, db adapters.Config) error {
+	if rulesFile == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(rulesFile)
+	if err != nil {
+		return fmt.Errorf("failed to read value mapping %q: %w", rulesFile, err)
+	}
+
+	var params map[string]any
+	if err := yaml.Unmarshal(data, &params); err != nil {
+		return fmt.Errorf("failed to parse value mapping %q: %w", rulesFile, err)
+	}
+
+	mapper, err := processors.NewFieldValueMapperFromConfig(params)
+	if err != nil {
+		return fmt.Errorf("failed to create value mapper from %q: %w", rulesFile, err)
+	}
+	if db.Type != "" {
+		mapper.WithLookupConfig(db)
+	}
+
+	pm.chain.Add(mapper)
+	fmt.Printf("✓ Added value mapper from: %s\n", rulesFile)
+
+	return nil
+}
+
+// PrintUnmappedValues prints values that value mappers could not translate.
+func (pm *ProcessorManager) PrintUnmappedValues() {
+	unmapped := pm.chain.UnmappedValues()
+	fields := make([]string, 0, len(unmapped))
+	for field := range unmapped {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		values := make([]string, 0, len(unmapped[field]))
+		for v := range unmapped[field] {
+			values = append(values, v)
+		}
+		sort.Strings(values)
+		fmt.Fprintf(os.Stderr, "⚠ value_mapper: %s: %d unmapped value(s)\n", field, len(values))
+		for _, v := range values {
+			fmt.Fprintf(os.Stderr, "    %q: %d\n", v, unmapped[field][v])
+		}
+	}
+}
+
 // Name implements processors.PacketProcessor.
 func (pm *ProcessorManager) Name() string { return "row-chain" }
 
@@ -212,6 +317,7 @@ func convertPacketToMatrix(pkt *packet.DataPacket) [][]string {
 
 // updatePacketFromMatrix updates packet data from processed matrix
 func updatePacketFromMatrix(pkt *packet.DataPacket, matrix [][]string) {
+	pkt.Header.Checksum = nil // строки изменены — сумма источника к ним не относится
 	for i, row := range matrix {
 		if i < len(pkt.Data.Rows) {
 			// Join values back with delimiter
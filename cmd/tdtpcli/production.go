@@ -11,6 +11,7 @@ import (
 	"github.com/ruslano69/tdtp-framework/pkg/audit"
 	"github.com/ruslano69/tdtp-framework/pkg/resilience"
 	"github.com/ruslano69/tdtp-framework/pkg/retry"
+	"github.com/ruslano69/tdtp-framework/pkg/tracing"
 )
 
 // auditDBDriverNames maps the audit.database.type config value to the
@@ -33,12 +34,23 @@ type ProductionFeatures struct {
 	// statement, not the *sql.DB itself — whoever opens the connection owns
 	// closing it, same as every other adapter in this binary.
 	auditDB *sql.DB
+
+	// shutdownTracing flushes buffered spans to the collector (tracing:
+	// section or OTEL_EXPORTER_OTLP_ENDPOINT); no-op when tracing is off.
+	shutdownTracing func(context.Context) error
 }
 
 // InitProductionFeatures initializes all production features from config
 func InitProductionFeatures(config *Config) (*ProductionFeatures, error) {
 	pf := &ProductionFeatures{}
 
+	// Initialize Tracing
+	shutdown, err := tracing.Init(context.Background(), tracing.ConfigFromEnv(config.Tracing.Config))
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize tracing: %w", err)
+	}
+	pf.shutdownTracing = shutdown
+
 	// Initialize Audit Logger
 	if config.Audit.Enabled {
 		auditLogger, auditDB, err := initAuditLogger(config.Audit)
@@ -72,6 +84,15 @@ func InitProductionFeatures(config *Config) (*ProductionFeatures, error) {
 
 // Close closes all production features
 func (pf *ProductionFeatures) Close() error {
+	if pf.shutdownTracing != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		err := pf.shutdownTracing(ctx)
+		cancel()
+		pf.shutdownTracing = nil
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to flush traces: %v\n", err)
+		}
+	}
 	if pf.RetryManager != nil {
 		if err := pf.RetryManager.Close(); err != nil {
 			return fmt.Errorf("failed to close retry manager: %w", err)
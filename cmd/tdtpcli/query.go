@@ -41,6 +41,10 @@ func FormatTDTQLQuery(query *packet.Query) string {
 		parts = append(parts, fmt.Sprintf("OFFSET: %d", query.Offset))
 	}
 
+	if len(query.After) > 0 {
+		parts = append(parts, fmt.Sprintf("AFTER: %s", strings.Join(query.After, ",")))
+	}
+
 	if len(parts) == 0 {
 		return "No filters"
 	}
@@ -84,11 +88,18 @@ func formatFilter(f packet.Filter) string {
 		return fmt.Sprintf("%s %s", f.Field, strings.ReplaceAll(strings.ToUpper(f.Operator), "_", " "))
 	}
 
+	field := f.Field
+	if f.Trunc != "" {
+		field = fmt.Sprintf("DATE_TRUNC('%s', %s)", f.Trunc, f.Field)
+	}
+	cond := fmt.Sprintf("%s %s %s", field, f.Operator, f.Value)
 	if f.Operator == "between" {
-		return fmt.Sprintf("%s BETWEEN %s AND %s", f.Field, f.Value, f.Value2)
+		cond = fmt.Sprintf("%s BETWEEN %s AND %s", field, f.Value, f.Value2)
 	}
-
-	return fmt.Sprintf("%s %s %s", f.Field, f.Operator, f.Value)
+	if f.Collation != "" {
+		cond += fmt.Sprintf(" COLLATE '%s'", f.Collation)
+	}
+	return cond
 }
 
 // formatOrderBy formats ORDER BY for display
@@ -7,9 +7,8 @@ package main
 // years — no new serialization logic, just a different response writer.
 //
 // Kept under its own path prefix rather than a ?format=json param on the
-// existing HTML routes specifically so auth/rate-limiting can be added to
-// /api/* alone later, the same way cmd/orchestrator scopes its own
-// authMiddleware to one route group without touching public endpoints.
+// existing HTML routes so rate limiting (quota.go) applies to /api/* alone.
+// Authentication and per-dataset ACLs (auth.go) cover both.
 
 import (
 	"context"
@@ -41,6 +40,9 @@ func (s *Server) handleAPIData(w http.ResponseWriter, r *http.Request) {
 		writeAPIError(w, http.StatusBadRequest, "dataset name required: /api/data/<name>")
 		return
 	}
+	if !s.authorize(w, r, permRead, name) {
+		return
+	}
 
 	res, ok := s.queryDataset(name, r.URL.Query())
 	if !ok {
@@ -48,6 +50,8 @@ func (s *Server) handleAPIData(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	noteRows(r, len(res.Rows))
+	s.auditServed(r, permRead, name, len(res.Rows), "")
 	writeAPIJSON(w, http.StatusOK, apiDataResponse{
 		Name:        res.Dataset.Name,
 		IsView:      res.Dataset.IsView,
@@ -70,7 +74,8 @@ type apiDatasetSummary struct {
 }
 
 // handleAPIDatasets serves GET /api/datasets — the JSON counterpart of the
-// HTML index (/), one summary per loaded source/view.
+// HTML index (/), one summary per loaded source/view the caller may read or
+// query.
 func (s *Server) handleAPIDatasets(w http.ResponseWriter, r *http.Request) {
 	if r.URL.Path != "/api/datasets" && r.URL.Path != "/api/datasets/" {
 		writeAPIError(w, http.StatusNotFound, "not found")
@@ -80,6 +85,9 @@ func (s *Server) handleAPIDatasets(w http.ResponseWriter, r *http.Request) {
 	s.mu.RLock()
 	out := make([]apiDatasetSummary, 0, len(s.order))
 	for _, name := range s.order {
+		if !s.allows(r, permRead, name) && !s.allows(r, permQuery, name) {
+			continue
+		}
 		ds := s.datasets[name]
 		out = append(out, apiDatasetSummary{
 			Name:       ds.Name,
@@ -118,6 +126,9 @@ func (s *Server) handleAPIRefresh(w http.ResponseWriter, r *http.Request) {
 		writeAPIError(w, http.StatusMethodNotAllowed, "POST required")
 		return
 	}
+	if !s.authorize(w, r, permAdmin, "") {
+		return
+	}
 
 	if !s.refreshMu.TryLock() {
 		writeAPIError(w, http.StatusConflict, "refresh already in progress")
@@ -127,7 +138,10 @@ func (s *Server) handleAPIRefresh(w http.ResponseWriter, r *http.Request) {
 
 	// Deliberately not r.Context(): a reload the caller triggered should
 	// finish and take effect even if their connection drops mid-request.
-	datasets, order, err := loadDatasets(context.Background(), s.cfg)
+	// It is still cancellable via POST /api/operations/<id>/cancel.
+	ctx, op := s.startOperation(context.Background(), "refresh", s.cfg.Server.Name)
+	datasets, order, err := loadDatasets(ctx, s.cfg, s.pool)
+	op.End()
 	if err != nil {
 		writeAPIError(w, http.StatusInternalServerError, "refresh failed: "+err.Error())
 		return
@@ -0,0 +1,357 @@
+package main
+
+// auth.go — аутентификация вызывающих и ACL датасетов.
+//
+// Вызывающий предъявляет API-ключ (X-API-Key или Authorization: Bearer
+// <ключ>) либо JWT (Authorization: Bearer <token>), подписанный общим
+// секретом (HS256) или ключом издателя (RS256/ES256). Ключ или claim
+// principal_claim дают имя principal — оно же user записей аудита (см.
+// audit.go) и получатель квот, если quotas.keys не знает ключа. Подписка
+// /api/feed из браузера (EventSource, WebSocket) заголовков не задаёт —
+// для неё токен принимается и в ?access_token=.
+//
+// acl — права principal на датасеты:
+//
+//	read      — /data, /api/data, /api/lookup
+//	query     — /api/query, POST /query
+//	subscribe — /api/feed
+//	admin     — /api/refresh, /api/operations, /api/pool (датасеты правила не учитываются)
+//
+// Датасеты правила — шаблоны path.Match имён источников, видов, лукапов и
+// лент; живой запрос POST /query к таблице server.query_tables проверяется
+// как "<источник>.<таблица>". Без auth сервер открыт, как прежде; auth без
+// acl пускает любого аутентифицированного ко всему; с acl запрещено всё,
+// что не разрешено правилом. Списки (/, /api/datasets, /api/feeds)
+// показывают только доступное вызывающему.
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Права правил acl.
+const (
+	permRead      = "read"
+	permQuery     = "query"
+	permSubscribe = "subscribe"
+	permAdmin     = "admin"
+)
+
+var validPermissions = map[string]bool{permRead: true, permQuery: true, permSubscribe: true, permAdmin: true}
+
+// jwtLeeway — допуск расхождения часов сервера и издателя для exp/nbf.
+const jwtLeeway = time.Minute
+
+var (
+	errNoCredentials      = errors.New("authentication required: X-API-Key or Authorization: Bearer")
+	errInvalidCredentials = errors.New("invalid credentials")
+)
+
+// principal — аутентифицированный вызывающий.
+type principal struct {
+	Name   string
+	Method string // api_key | jwt | anonymous
+}
+
+type principalKey struct{}
+
+// principalFrom — principal запроса; nil, если auth не настроен.
+func principalFrom(r *http.Request) *principal {
+	p, _ := r.Context().Value(principalKey{}).(*principal)
+	return p
+}
+
+// authenticator проверяет API-ключи и JWT из auth.
+type authenticator struct {
+	keys      map[string]string
+	jwt       *jwtVerifier // nil — JWT не принимаются
+	anonymous string
+}
+
+func newAuthenticator(cfg *AuthConfig) (*authenticator, error) {
+	a := &authenticator{keys: cfg.APIKeys, anonymous: cfg.Anonymous}
+	if cfg.JWT != nil {
+		v, err := newJWTVerifier(cfg.JWT)
+		if err != nil {
+			return nil, fmt.Errorf("auth: jwt: %w", err)
+		}
+		a.jwt = v
+	}
+	return a, nil
+}
+
+// authenticate определяет principal запроса по предъявленному ключу или JWT.
+func (a *authenticator) authenticate(r *http.Request) (*principal, error) {
+	key := r.Header.Get("X-API-Key")
+	bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok && strings.HasPrefix(r.URL.Path, "/api/feed/") {
+		bearer = r.URL.Query().Get("access_token")
+	}
+	bearer = strings.TrimSpace(bearer)
+
+	switch {
+	case key != "":
+		if name, ok := a.lookupKey(key); ok {
+			return &principal{Name: name, Method: "api_key"}, nil
+		}
+		return nil, errInvalidCredentials
+	case bearer != "":
+		if name, ok := a.lookupKey(bearer); ok {
+			return &principal{Name: name, Method: "api_key"}, nil
+		}
+		if a.jwt != nil && strings.Count(bearer, ".") == 2 {
+			name, err := a.jwt.verify(bearer, time.Now())
+			if err != nil {
+				return nil, fmt.Errorf("%w: %w", errInvalidCredentials, err)
+			}
+			return &principal{Name: name, Method: "jwt"}, nil
+		}
+		return nil, errInvalidCredentials
+	case a.anonymous != "":
+		return &principal{Name: a.anonymous, Method: "anonymous"}, nil
+	}
+	return nil, errNoCredentials
+}
+
+// lookupKey — principal API-ключа; ключи сравниваются за постоянное время.
+func (a *authenticator) lookupKey(key string) (string, bool) {
+	var name string
+	for k, p := range a.keys {
+		if subtle.ConstantTimeCompare([]byte(k), []byte(key)) == 1 {
+			name = p
+		}
+	}
+	return name, name != ""
+}
+
+// withAuth аутентифицирует каждый запрос и кладёт principal в контекст.
+// Без auth — обработчик как есть.
+func (s *Server) withAuth(next http.Handler) http.Handler {
+	if s.auth == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p, err := s.auth.authenticate(r)
+		if err != nil {
+			s.auditDenied(r, "", "", err)
+			w.Header().Set("WWW-Authenticate", `Bearer realm="tdtpserve"`)
+			writeAPIError(w, http.StatusUnauthorized, err.Error())
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), principalKey{}, p)))
+	})
+}
+
+// allows — acl разрешает вызывающему perm над датасетом name. Без auth или
+// без правил разрешено всё.
+func (s *Server) allows(r *http.Request, perm, name string) bool {
+	p := principalFrom(r)
+	if p == nil || len(s.cfg.ACL) == 0 {
+		return true
+	}
+	for _, rule := range s.cfg.ACL {
+		if rule.Principal != "*" && rule.Principal != p.Name {
+			continue
+		}
+		if !slices.Contains(rule.Permissions, perm) {
+			continue
+		}
+		if perm == permAdmin {
+			return true
+		}
+		for _, pattern := range rule.Datasets {
+			if ok, _ := matchDataset(pattern, name); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matchDataset — имя датасета name подходит под шаблон правила acl.
+func matchDataset(pattern, name string) (bool, error) {
+	return path.Match(pattern, name)
+}
+
+// authorize — allows с ответом 403 и записью отказа в аудит.
+func (s *Server) authorize(w http.ResponseWriter, r *http.Request, perm, name string) bool {
+	if s.allows(r, perm, name) {
+		return true
+	}
+	err := fmt.Errorf("%s permission required", perm)
+	s.auditDenied(r, perm, name, err)
+	if name != "" {
+		err = fmt.Errorf("%s: %w", name, err)
+	}
+	writeAPIError(w, http.StatusForbidden, err.Error())
+	return false
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// JWT (RFC 7519) — только проверка подписи и claims, без выпуска токенов
+// ─────────────────────────────────────────────────────────────────────────────
+
+// jwtVerifier проверяет JWT одним ключом: alg токена должен совпасть с
+// типом ключа (HS256 — секрет, RS256 — RSA, ES256 — ECDSA P-256), "none" и
+// прочие алгоритмы отвергаются.
+type jwtVerifier struct {
+	alg    string
+	secret []byte
+	key    crypto.PublicKey
+
+	issuer, audience, claim string
+}
+
+func newJWTVerifier(cfg *JWTConfig) (*jwtVerifier, error) {
+	v := &jwtVerifier{issuer: cfg.Issuer, audience: cfg.Audience, claim: cfg.PrincipalClaim}
+	if v.claim == "" {
+		v.claim = "sub"
+	}
+
+	switch {
+	case cfg.Secret != "":
+		v.alg, v.secret = "HS256", []byte(cfg.Secret)
+	case cfg.SecretEnv != "":
+		secret := os.Getenv(cfg.SecretEnv)
+		if secret == "" {
+			return nil, fmt.Errorf("environment variable %s is empty", cfg.SecretEnv)
+		}
+		v.alg, v.secret = "HS256", []byte(secret)
+	default:
+		data, err := os.ReadFile(cfg.PublicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		block, _ := pem.Decode(data)
+		if block == nil {
+			return nil, fmt.Errorf("%s: no PEM block", cfg.PublicKeyFile)
+		}
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", cfg.PublicKeyFile, err)
+		}
+		switch k := key.(type) {
+		case *rsa.PublicKey:
+			v.alg = "RS256"
+		case *ecdsa.PublicKey:
+			if k.Curve != elliptic.P256() {
+				return nil, fmt.Errorf("%s: ECDSA key must use P-256 (ES256)", cfg.PublicKeyFile)
+			}
+			v.alg = "ES256"
+		default:
+			return nil, fmt.Errorf("%s: unsupported key type %T (RSA or ECDSA P-256)", cfg.PublicKeyFile, key)
+		}
+		v.key = key
+	}
+	return v, nil
+}
+
+// verify проверяет подпись, exp/nbf, iss и aud токена и возвращает имя principal.
+func (v *jwtVerifier) verify(token string, now time.Time) (string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", errors.New("malformed token")
+	}
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := decodeJWTPart(parts[0], &header); err != nil {
+		return "", fmt.Errorf("header: %w", err)
+	}
+	if header.Alg != v.alg {
+		return "", fmt.Errorf("unexpected alg %q (want %s)", header.Alg, v.alg)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", fmt.Errorf("signature: %w", err)
+	}
+	if err := v.verifySignature(parts[0]+"."+parts[1], sig); err != nil {
+		return "", err
+	}
+
+	var claims map[string]any
+	if err := decodeJWTPart(parts[1], &claims); err != nil {
+		return "", fmt.Errorf("claims: %w", err)
+	}
+	if exp, ok := claims["exp"].(float64); ok && now.After(time.Unix(int64(exp), 0).Add(jwtLeeway)) {
+		return "", errors.New("token expired")
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now.Add(jwtLeeway).Before(time.Unix(int64(nbf), 0)) {
+		return "", errors.New("token not yet valid")
+	}
+	if v.issuer != "" && claims["iss"] != v.issuer {
+		return "", fmt.Errorf("unexpected issuer %v", claims["iss"])
+	}
+	if v.audience != "" && !jwtAudience(claims["aud"], v.audience) {
+		return "", fmt.Errorf("token is not issued for audience %s", v.audience)
+	}
+	name, _ := claims[v.claim].(string)
+	if name == "" {
+		return "", fmt.Errorf("claim %s is missing", v.claim)
+	}
+	return name, nil
+}
+
+func (v *jwtVerifier) verifySignature(signed string, sig []byte) error {
+	digest := sha256.Sum256([]byte(signed))
+	switch v.alg {
+	case "HS256":
+		mac := hmac.New(sha256.New, v.secret)
+		mac.Write([]byte(signed))
+		if !hmac.Equal(sig, mac.Sum(nil)) {
+			return errors.New("invalid signature")
+		}
+	case "RS256":
+		if err := rsa.VerifyPKCS1v15(v.key.(*rsa.PublicKey), crypto.SHA256, digest[:], sig); err != nil {
+			return errors.New("invalid signature")
+		}
+	case "ES256":
+		// JWS: подпись ECDSA — r||s по 32 байта, не ASN.1
+		if len(sig) != 64 {
+			return errors.New("invalid signature")
+		}
+		r, s := new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])
+		if !ecdsa.Verify(v.key.(*ecdsa.PublicKey), digest[:], r, s) {
+			return errors.New("invalid signature")
+		}
+	}
+	return nil
+}
+
+func decodeJWTPart(part string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// jwtAudience — aud (строка или массив строк) содержит audience.
+func jwtAudience(aud any, audience string) bool {
+	switch a := aud.(type) {
+	case string:
+		return a == audience
+	case []any:
+		return slices.Contains(a, any(audience))
+	}
+	return false
+}
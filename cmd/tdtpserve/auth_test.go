@@ -0,0 +1,233 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ruslano69/tdtp-framework/pkg/audit"
+)
+
+// signJWT — токен alg с claims, подписанный sign.
+func signJWT(t *testing.T, alg string, claims map[string]any, sign func(signed string) []byte) string {
+	t.Helper()
+	header, _ := json.Marshal(map[string]string{"alg": alg, "typ": "JWT"})
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sign(signed))
+}
+
+func hs256(secret string) func(string) []byte {
+	return func(signed string) []byte {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(signed))
+		return mac.Sum(nil)
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	a, err := newAuthenticator(&AuthConfig{
+		APIKeys: map[string]string{"key-ana": "ana"},
+		JWT:     &JWTConfig{Secret: "s3cret", Issuer: "sso", Audience: "tdtpserve"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := time.Now().Add(time.Hour).Unix()
+	valid := signJWT(t, "HS256", map[string]any{"sub": "bob", "iss": "sso", "aud": []string{"tdtpserve"}, "exp": exp}, hs256("s3cret"))
+
+	tests := []struct {
+		name    string
+		header  map[string]string
+		url     string
+		want    string
+		wantErr error
+	}{
+		{"api key header", map[string]string{"X-API-Key": "key-ana"}, "/api/data/Users", "ana", nil},
+		{"api key bearer", map[string]string{"Authorization": "Bearer key-ana"}, "/api/data/Users", "ana", nil},
+		{"jwt", map[string]string{"Authorization": "Bearer " + valid}, "/api/data/Users", "bob", nil},
+		{"feed access_token", nil, "/api/feed/orders?access_token=" + valid, "bob", nil},
+		{"access_token outside feeds", nil, "/api/data/Users?access_token=" + valid, "", errNoCredentials},
+		{"unknown key", map[string]string{"X-API-Key": "key-eve"}, "/", "", errInvalidCredentials},
+		{"no credentials", nil, "/", "", errNoCredentials},
+		{"wrong secret", map[string]string{"Authorization": "Bearer " + signJWT(t, "HS256", map[string]any{"sub": "bob", "iss": "sso", "aud": "tdtpserve", "exp": exp}, hs256("guess"))}, "/", "", errInvalidCredentials},
+		{"alg none", map[string]string{"Authorization": "Bearer " + signJWT(t, "none", map[string]any{"sub": "bob", "iss": "sso", "aud": "tdtpserve"}, func(string) []byte { return nil })}, "/", "", errInvalidCredentials},
+		{"expired", map[string]string{"Authorization": "Bearer " + signJWT(t, "HS256", map[string]any{"sub": "bob", "iss": "sso", "aud": "tdtpserve", "exp": time.Now().Add(-time.Hour).Unix()}, hs256("s3cret"))}, "/", "", errInvalidCredentials},
+		{"wrong audience", map[string]string{"Authorization": "Bearer " + signJWT(t, "HS256", map[string]any{"sub": "bob", "iss": "sso", "aud": "billing", "exp": exp}, hs256("s3cret"))}, "/", "", errInvalidCredentials},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tt.url, nil)
+			for k, v := range tt.header {
+				r.Header.Set(k, v)
+			}
+			p, err := a.authenticate(r)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && p.Name != tt.want {
+				t.Errorf("principal = %q, want %q", p.Name, tt.want)
+			}
+		})
+	}
+
+	anon := &authenticator{anonymous: "guest"}
+	if p, err := anon.authenticate(httptest.NewRequest(http.MethodGet, "/", nil)); err != nil || p.Name != "guest" {
+		t.Errorf("anonymous = %v, %v", p, err)
+	}
+}
+
+// ES256: открытый ключ издателя из PEM, подпись r||s.
+func TestJWTVerifier_ES256(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyFile := filepath.Join(t.TempDir(), "issuer.pem")
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	v, err := newJWTVerifier(&JWTConfig{PublicKeyFile: keyFile, PrincipalClaim: "preferred_username"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	es256 := func(signed string) []byte {
+		digest := sha256.Sum256([]byte(signed))
+		r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig := make([]byte, 64)
+		r.FillBytes(sig[:32])
+		s.FillBytes(sig[32:])
+		return sig
+	}
+	token := signJWT(t, "ES256", map[string]any{"preferred_username": "ana"}, es256)
+	if name, err := v.verify(token, time.Now()); err != nil || name != "ana" {
+		t.Errorf("verify = %q, %v", name, err)
+	}
+	// HS256 с открытым ключом в роли секрета — классическая подмена alg
+	forged := signJWT(t, "HS256", map[string]any{"preferred_username": "ana"}, hs256(string(der)))
+	if _, err := v.verify(forged, time.Now()); err == nil {
+		t.Error("HS256 token accepted by ES256 verifier")
+	}
+}
+
+// recordingAppender собирает записи аудита.
+type recordingAppender struct {
+	mu      sync.Mutex
+	entries []*audit.Entry
+}
+
+func (a *recordingAppender) Append(_ context.Context, e *audit.Entry) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.entries = append(a.entries, e)
+	return nil
+}
+
+func (a *recordingAppender) Close() error { return nil }
+
+func (a *recordingAppender) last() *audit.Entry {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if len(a.entries) == 0 {
+		return nil
+	}
+	return a.entries[len(a.entries)-1]
+}
+
+// ACL по principal и датасетам, отфильтрованный список датасетов и записи
+// аудита выдачи и отказов.
+func TestACL_DatasetsAndAudit(t *testing.T) {
+	srv := newCursorTestServer(t)
+	srv.datasets["Orders"] = &Dataset{Name: "Orders", Packet: srv.datasets["Users"].Packet}
+	srv.order = []string{"Users", "Orders"}
+	srv.cfg = &ServeConfig{
+		Auth: &AuthConfig{APIKeys: map[string]string{"key-ana": "ana", "key-bob": "bob"}},
+		ACL: []ACLRule{
+			{Principal: "ana", Datasets: []string{"*"}, Permissions: []string{"read", "query"}},
+			{Principal: "*", Datasets: []string{"Ord*"}, Permissions: []string{"read"}},
+		},
+	}
+	srv.auth, _ = newAuthenticator(srv.cfg.Auth)
+	rec := &recordingAppender{}
+	srv.audit = audit.NewLogger(audit.SyncConfig(), rec)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/data/", srv.handleAPIData)
+	mux.HandleFunc("/api/datasets", srv.handleAPIDatasets)
+	mux.HandleFunc("/api/refresh", srv.handleAPIRefresh)
+	handler := srv.withAuth(mux)
+
+	do := func(method, url, key string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, url, nil)
+		if key != "" {
+			r.Header.Set("X-API-Key", key)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	if w := do(http.MethodGet, "/api/data/Users", ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("no key: status %d", w.Code)
+	}
+	if e := rec.last(); e == nil || e.Operation != audit.OpAuthenticate || e.Status != audit.StatusFailure {
+		t.Errorf("401 audit entry = %+v", e)
+	}
+
+	if w := do(http.MethodGet, "/api/data/Users?limit=3", "key-ana"); w.Code != http.StatusOK {
+		t.Fatalf("ana: status %d: %s", w.Code, w.Body.String())
+	}
+	if e := rec.last(); e.User != "ana" || e.Resource != "Users" || e.RecordsAffected != 3 || e.Status != audit.StatusSuccess {
+		t.Errorf("served audit entry = %+v", e)
+	}
+
+	if w := do(http.MethodGet, "/api/data/Users", "key-bob"); w.Code != http.StatusForbidden {
+		t.Errorf("bob Users: status %d", w.Code)
+	}
+	if e := rec.last(); e.User != "bob" || e.Operation != audit.OpExport || e.Status != audit.StatusFailure {
+		t.Errorf("403 audit entry = %+v", e)
+	}
+	if w := do(http.MethodGet, "/api/data/Orders", "key-bob"); w.Code != http.StatusOK {
+		t.Errorf("bob Orders: status %d", w.Code)
+	}
+
+	w := do(http.MethodGet, "/api/datasets", "key-bob")
+	var list []apiDatasetSummary
+	if err := json.NewDecoder(w.Body).Decode(&list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].Name != "Orders" {
+		t.Errorf("bob datasets = %+v", list)
+	}
+
+	if w := do(http.MethodPost, "/api/refresh", "key-ana"); w.Code != http.StatusForbidden || !strings.Contains(w.Body.String(), "admin") {
+		t.Errorf("ana refresh: status %d: %s", w.Code, w.Body.String())
+	}
+}
@@ -4,8 +4,11 @@ package main
 import (
 	"fmt"
 	"os"
+	"slices"
 
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
 	"github.com/ruslano69/tdtp-framework/pkg/etl"
+	"github.com/ruslano69/tdtp-framework/pkg/quota"
 	"gopkg.in/yaml.v3"
 )
 
@@ -15,12 +18,32 @@ type ServeConfig struct {
 	Sources []etl.SourceConfig `yaml:"sources"` // те же типы что и в ETL: tdtp, postgres, mssql, mysql, sqlite
 	Views   []ViewConfig       `yaml:"views"`
 	Lookups []LookupConfig     `yaml:"lookups,omitempty"` // параметризованные live-запросы по требованию (см. lookup.go)
+	Quotas  *QuotaConfig       `yaml:"quotas,omitempty"`  // лимиты объёма /api/* по получателям (см. quota.go)
+	Pool    PoolConfig         `yaml:"pool,omitempty"`    // тёплый пул адаптеров DB-источников (см. pool.go)
+	Feeds   []FeedConfig       `yaml:"feeds,omitempty"`   // ленты изменений таблиц для подписчиков SSE/WebSocket (см. feed.go)
+	Auth    *AuthConfig        `yaml:"auth,omitempty"`    // API-ключи и JWT вызывающих (nil — сервер открыт, см. auth.go)
+	ACL     []ACLRule          `yaml:"acl,omitempty"`     // права principal на датасеты (пусто — всё разрешено, см. auth.go)
+	Audit   *AuditConfig       `yaml:"audit,omitempty"`   // журнал выданных пакетов (nil — без аудита, см. audit.go)
 }
 
 // ServerSection — параметры HTTP сервера
 type ServerSection struct {
 	Name string `yaml:"name"` // заголовок в UI
 	Port int    `yaml:"port"` // HTTP порт, по умолчанию 8080
+
+	// Continuation-токены /api/query (см. cursor.go)
+	CursorSecret     string `yaml:"cursor_secret,omitempty"`      // HMAC-секрет; пусто = случайный на время жизни процесса
+	CursorTTLSeconds int    `yaml:"cursor_ttl_seconds,omitempty"` // срок действия токена, по умолчанию 900
+
+	// Реестр операций /api/operations (см. operations.go); пусто —
+	// TDTP_OPERATIONS_DIR или $TMPDIR/tdtp-operations, общий с tdtpcli
+	OperationsDir string `yaml:"operations_dir,omitempty"`
+
+	// Таблицы DB-источников, доступные request-пакетам POST /query вживую
+	// (см. request.go): имя источника (Header.Recipient) → таблицы. Без
+	// записи источника /query выполняет запросы только над загруженными
+	// датасетами.
+	QueryTables map[string][]string `yaml:"query_tables,omitempty"`
 }
 
 // ViewConfig — SQL-вид поверх загруженных источников
@@ -50,6 +73,103 @@ type LookupConfig struct {
 	ContentType string   `yaml:"content_type,omitempty"` // обязателен для result: binary
 }
 
+// FeedConfig — лента изменений таблицы DB-источника: сервер опрашивает
+// таблицу инкрементально (стратегии tdtpcli --sync-incremental) и рассылает
+// новые и изменённые строки подписчикам GET /api/feed/<name>.
+type FeedConfig struct {
+	Name          string `yaml:"name"`                     // имя ленты в URL
+	Source        string `yaml:"source"`                   // DB-источник из sources
+	Table         string `yaml:"table"`                    // таблица в БД источника
+	Strategy      string `yaml:"strategy,omitempty"`       // timestamp (по умолчанию) | sequence | version | cdc | changetable
+	TrackingField string `yaml:"tracking_field,omitempty"` // обязателен для timestamp/sequence/version
+	Slot          string `yaml:"slot,omitempty"`           // слот логической репликации, обязателен для cdc
+	StartFrom     string `yaml:"start_from,omitempty"`     // начальная контрольная точка; пусто — изменения после старта сервера
+	PollSeconds   int    `yaml:"poll_seconds,omitempty"`   // интервал опроса, по умолчанию 10
+	BatchSize     int    `yaml:"batch_size,omitempty"`     // строк за опрос (0 — без предела), остальные — следующим опросом
+}
+
+// PoolConfig — тёплый пул адаптеров DB-источников. Нули — значения по
+// умолчанию; sources переопределяет max_conns/min_idle для отдельных
+// источников.
+type PoolConfig struct {
+	MaxConns           int                   `yaml:"max_conns,omitempty"`            // одновременно занятых адаптеров на источник, по умолчанию 4
+	MinIdle            int                   `yaml:"min_idle,omitempty"`             // тёплых адаптеров на источник, по умолчанию 1
+	IdleTimeoutSeconds int                   `yaml:"idle_timeout_seconds,omitempty"` // простаивающий дольше закрывается и заменяется, по умолчанию 300
+	PingTimeoutSeconds int                   `yaml:"ping_timeout_seconds,omitempty"` // health check перед выдачей адаптера, по умолчанию 5
+	Sources            map[string]PoolLimits `yaml:"sources,omitempty"`              // имя источника → свои лимиты
+}
+
+// PoolLimits — лимиты пула одного источника (0 — общее значение pool).
+type PoolLimits struct {
+	MaxConns int `yaml:"max_conns,omitempty"`
+	MinIdle  int `yaml:"min_idle,omitempty"`
+}
+
+// limits — max_conns и min_idle источника с учётом переопределений и умолчаний.
+func (c PoolConfig) limits(source string) (maxConns, minIdle int) {
+	maxConns, minIdle = c.MaxConns, c.MinIdle
+	if l, ok := c.Sources[source]; ok {
+		if l.MaxConns > 0 {
+			maxConns = l.MaxConns
+		}
+		if l.MinIdle > 0 {
+			minIdle = l.MinIdle
+		}
+	}
+	if maxConns <= 0 {
+		maxConns = defaultPoolMaxConns
+	}
+	if minIdle <= 0 {
+		minIdle = defaultPoolMinIdle
+	}
+	return maxConns, min(minIdle, maxConns)
+}
+
+// QuotaConfig — квоты получателей /api/data, /api/query, /query, /api/feed и /api/lookup.
+// Получатель определяется по заголовку X-API-Key (keys: ключ → получатель);
+// запрос без известного ключа идёт под quotas.default, а без него
+// отклоняется.
+type QuotaConfig struct {
+	quota.Config `yaml:",inline"`
+
+	Keys map[string]string `yaml:"keys"` // X-API-Key → имя получателя из recipients
+}
+
+// AuthConfig — аутентификация вызывающих. Principal определяется по
+// API-ключу (X-API-Key или Authorization: Bearer) или по claim JWT.
+type AuthConfig struct {
+	APIKeys   map[string]string `yaml:"api_keys,omitempty"`  // API-ключ → principal
+	JWT       *JWTConfig        `yaml:"jwt,omitempty"`       // проверка Bearer JWT (nil — JWT не принимаются)
+	Anonymous string            `yaml:"anonymous,omitempty"` // principal запросов без ключа; пусто — 401
+}
+
+// JWTConfig — проверка JWT: HS256 по общему секрету либо RS256/ES256 по
+// открытому ключу издателя (PEM, PKIX).
+type JWTConfig struct {
+	Secret         string `yaml:"secret,omitempty"`          // секрет HS256
+	SecretEnv      string `yaml:"secret_env,omitempty"`      // переменная окружения с секретом HS256
+	PublicKeyFile  string `yaml:"public_key_file,omitempty"` // PEM открытого ключа RS256/ES256
+	Issuer         string `yaml:"issuer,omitempty"`          // ожидаемый iss; пусто — не проверяется
+	Audience       string `yaml:"audience,omitempty"`        // ожидаемый aud; пусто — не проверяется
+	PrincipalClaim string `yaml:"principal_claim,omitempty"` // claim с именем principal, по умолчанию sub
+}
+
+// ACLRule — права principal на датасеты. Правила складываются: запрос
+// разрешён, если его разрешает хотя бы одно правило.
+type ACLRule struct {
+	Principal   string   `yaml:"principal"`          // имя principal или "*" — любой аутентифицированный
+	Datasets    []string `yaml:"datasets,omitempty"` // шаблоны path.Match имён источников, видов, лукапов и лент
+	Permissions []string `yaml:"permissions"`        // read | query | subscribe | admin
+}
+
+// AuditConfig — журнал аудита выданных данных (pkg/audit).
+type AuditConfig struct {
+	Level   string `yaml:"level,omitempty"`       // minimal | standard (по умолчанию) | full
+	File    string `yaml:"file,omitempty"`        // файл журнала (JSON-строки)
+	MaxSize int    `yaml:"max_size_mb,omitempty"` // размер файла до ротации, по умолчанию 100
+	Console bool   `yaml:"console,omitempty"`     // дублировать записи в stdout
+}
+
 // loadConfig читает и валидирует YAML конфиг
 func loadConfig(path string) (*ServeConfig, error) {
 	data, err := os.ReadFile(path)
@@ -77,8 +197,11 @@ func loadConfig(path string) (*ServeConfig, error) {
 			return nil, fmt.Errorf("source %q: dsn is required", src.Name)
 		}
 		validTypes := map[string]bool{"postgres": true, "mssql": true, "mysql": true, "sqlite": true, "tdtp": true, "tdtp-enc": true}
-		if !validTypes[src.Type] {
-			return nil, fmt.Errorf("source %q: unknown type %q (postgres/mssql/mysql/sqlite/tdtp/tdtp-enc)", src.Name, src.Type)
+		if !validTypes[src.Type] && !adapters.IsRegistered(src.Type) { // или адаптер плагина (--plugins)
+			return nil, fmt.Errorf("source %q: unknown type %q (postgres/mssql/mysql/sqlite/tdtp/tdtp-enc or a loaded plugin)", src.Name, src.Type)
+		}
+		if isDBSource(src.Type) && !adapters.IsRegistered(src.Type) {
+			return nil, fmt.Errorf("source %q: adapter %q is not compiled into this build (see tdtpserve --version)", src.Name, src.Type)
 		}
 		if src.Type != "tdtp" && src.Type != "tdtp-enc" && src.Query == "" {
 			return nil, fmt.Errorf("source %q: query is required for type %q", src.Name, src.Type)
@@ -106,6 +229,9 @@ func loadConfig(path string) (*ServeConfig, error) {
 		if !validLookupTypes[lk.Type] {
 			return nil, fmt.Errorf("lookup %q: unknown type %q (sqlite/mysql/mssql/postgres)", lk.Name, lk.Type)
 		}
+		if !adapters.IsRegistered(lk.Type) {
+			return nil, fmt.Errorf("lookup %q: adapter %q is not compiled into this build (see tdtpserve --version)", lk.Name, lk.Type)
+		}
 		if lk.DSN == "" {
 			return nil, fmt.Errorf("lookup %q: dsn is required", lk.Name)
 		}
@@ -126,6 +252,121 @@ func loadConfig(path string) (*ServeConfig, error) {
 		}
 	}
 
+	if cfg.Pool.MaxConns < 0 || cfg.Pool.MinIdle < 0 || cfg.Pool.IdleTimeoutSeconds < 0 || cfg.Pool.PingTimeoutSeconds < 0 {
+		return nil, fmt.Errorf("pool: values must not be negative")
+	}
+	for name := range cfg.Pool.Sources {
+		found := false
+		for _, src := range cfg.Sources {
+			if src.Name == name && isDBSource(src.Type) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("pool: sources: %q is not a database source", name)
+		}
+	}
+	for name, tables := range cfg.Server.QueryTables {
+		if !slices.ContainsFunc(cfg.Sources, func(src etl.SourceConfig) bool { return src.Name == name && isDBSource(src.Type) }) {
+			return nil, fmt.Errorf("server.query_tables: %q is not a database source", name)
+		}
+		if len(tables) == 0 {
+			return nil, fmt.Errorf("server.query_tables: %q: at least one table is required", name)
+		}
+	}
+
+	feedNames := make(map[string]bool, len(cfg.Feeds))
+	for i, fc := range cfg.Feeds {
+		if fc.Name == "" {
+			return nil, fmt.Errorf("feed[%d]: name is required", i)
+		}
+		if feedNames[fc.Name] {
+			return nil, fmt.Errorf("feed %q: duplicate name", fc.Name)
+		}
+		feedNames[fc.Name] = true
+		if !slices.ContainsFunc(cfg.Sources, func(src etl.SourceConfig) bool { return src.Name == fc.Source && isDBSource(src.Type) }) {
+			return nil, fmt.Errorf("feed %q: source %q is not a database source", fc.Name, fc.Source)
+		}
+		if fc.Table == "" {
+			return nil, fmt.Errorf("feed %q: table is required", fc.Name)
+		}
+		if fc.PollSeconds < 0 || fc.BatchSize < 0 {
+			return nil, fmt.Errorf("feed %q: poll_seconds and batch_size must not be negative", fc.Name)
+		}
+		ic := feedIncrementalConfig(fc, fc.StartFrom, fc.BatchSize)
+		if err := ic.Validate(); err != nil {
+			return nil, fmt.Errorf("feed %q: %w", fc.Name, err)
+		}
+	}
+
+	if q := cfg.Quotas; q != nil {
+		if len(q.Keys) == 0 && q.Default == nil {
+			return nil, fmt.Errorf("quotas: keys or default is required")
+		}
+		for key, recipient := range q.Keys {
+			if _, ok := q.Recipients[recipient]; !ok {
+				return nil, fmt.Errorf("quotas: key %q… refers to unknown recipient %q", key[:min(len(key), 4)], recipient)
+			}
+		}
+	}
+
+	if a := cfg.Auth; a != nil {
+		if len(a.APIKeys) == 0 && a.JWT == nil {
+			return nil, fmt.Errorf("auth: api_keys or jwt is required")
+		}
+		for key, name := range a.APIKeys {
+			if key == "" || name == "" {
+				return nil, fmt.Errorf("auth: api_keys: empty key or principal")
+			}
+		}
+		if j := a.JWT; j != nil {
+			secrets := 0
+			for _, v := range []string{j.Secret, j.SecretEnv, j.PublicKeyFile} {
+				if v != "" {
+					secrets++
+				}
+			}
+			if secrets != 1 {
+				return nil, fmt.Errorf("auth: jwt: exactly one of secret, secret_env, public_key_file is required")
+			}
+		}
+	}
+	if len(cfg.ACL) > 0 && cfg.Auth == nil {
+		return nil, fmt.Errorf("acl: requires auth (principals are unknown without it)")
+	}
+	for i, rule := range cfg.ACL {
+		if rule.Principal == "" {
+			return nil, fmt.Errorf("acl[%d]: principal is required", i)
+		}
+		if len(rule.Permissions) == 0 {
+			return nil, fmt.Errorf("acl[%d]: permissions are required", i)
+		}
+		for _, perm := range rule.Permissions {
+			if !validPermissions[perm] {
+				return nil, fmt.Errorf("acl[%d]: unknown permission %q (read/query/subscribe/admin)", i, perm)
+			}
+		}
+		if len(rule.Datasets) == 0 && slices.ContainsFunc(rule.Permissions, func(p string) bool { return p != permAdmin }) {
+			return nil, fmt.Errorf("acl[%d]: datasets are required for %v", i, rule.Permissions)
+		}
+		for _, pattern := range rule.Datasets {
+			if _, err := matchDataset(pattern, ""); err != nil {
+				return nil, fmt.Errorf("acl[%d]: dataset pattern %q: %w", i, pattern, err)
+			}
+		}
+	}
+	if a := cfg.Audit; a != nil {
+		switch a.Level {
+		case "", "minimal", "standard", "full":
+		default:
+			return nil, fmt.Errorf("audit: unknown level %q (minimal/standard/full)", a.Level)
+		}
+		if a.MaxSize < 0 {
+			return nil, fmt.Errorf("audit: max_size_mb must not be negative")
+		}
+	}
+
 	if cfg.Server.Port == 0 {
 		cfg.Server.Port = 8080
 	}
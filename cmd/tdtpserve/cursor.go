@@ -0,0 +1,341 @@
+package main
+
+// cursor.go — GET /api/query/<name>: постраничный обход датасета по
+// непрозрачным continuation-токенам вместо limit/offset.
+//
+// Токен хранит keyset-позицию (значения ключей сортировки последней
+// отданной строки), хэш запроса (where + order_by) и срок действия, и
+// подписан HMAC-SHA256 секретом сервера. Следующая страница — строки,
+// строго идущие после этой позиции в порядке сортировки, поэтому обход
+// не теряет и не дублирует строки, даже если между запросами прошёл
+// POST /api/refresh и строки добавились/удалились выше по списку —
+// в отличие от offset, который после refresh указывает «не туда».
+//
+// Порядок всегда делается однозначным: к order_by дописываются ключевые
+// поля схемы (Key), а если их нет — все остальные поля. У датасета без
+// ключей полностью совпадающие строки на границе страниц неразличимы.
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+	"github.com/ruslano69/tdtp-framework/pkg/core/schema"
+	"github.com/ruslano69/tdtp-framework/pkg/core/tdtql"
+)
+
+const (
+	cursorVersion        = 1
+	defaultCursorTTL     = 15 * time.Minute
+	defaultQueryPageSize = 100
+	maxQueryPageSize     = 10_000
+)
+
+var (
+	errCursorInvalid  = errors.New("invalid cursor")
+	errCursorExpired  = errors.New("cursor expired")
+	errCursorMismatch = errors.New("cursor does not match this dataset/query — resend the same where/order_by")
+)
+
+// cursorToken — содержимое continuation-токена (до base64 и подписи).
+type cursorToken struct {
+	V         int      `json:"v"`
+	Dataset   string   `json:"ds"`
+	QueryHash string   `json:"qh"`
+	Keys      []string `json:"k"` // значения полей сортировки последней отданной строки
+	Expires   int64    `json:"exp"`
+}
+
+// cursorCodec подписывает и проверяет токены.
+type cursorCodec struct {
+	secret []byte
+	ttl    time.Duration
+}
+
+// newCursorCodec берёт секрет из конфига (нужно, если несколько экземпляров
+// за балансировщиком должны принимать токены друг друга), иначе генерирует
+// случайный — токены тогда живут до рестарта процесса.
+func newCursorCodec(secret string, ttlSeconds int) (*cursorCodec, error) {
+	c := &cursorCodec{secret: []byte(secret), ttl: defaultCursorTTL}
+	if ttlSeconds > 0 {
+		c.ttl = time.Duration(ttlSeconds) * time.Second
+	}
+	if len(c.secret) == 0 {
+		c.secret = make([]byte, 32)
+		if _, err := rand.Read(c.secret); err != nil {
+			return nil, fmt.Errorf("generate cursor secret: %w", err)
+		}
+	}
+	return c, nil
+}
+
+func (c *cursorCodec) sign(payload string) string {
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// encode возвращает токен вида base64url(json) + "." + base64url(hmac).
+func (c *cursorCodec) encode(dataset, queryHash string, keys []string, now time.Time) (string, error) {
+	data, err := json.Marshal(cursorToken{
+		V:         cursorVersion,
+		Dataset:   dataset,
+		QueryHash: queryHash,
+		Keys:      keys,
+		Expires:   now.Add(c.ttl).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+	payload := base64.RawURLEncoding.EncodeToString(data)
+	return payload + "." + c.sign(payload), nil
+}
+
+// decode проверяет подпись, срок действия и принадлежность токена запросу.
+func (c *cursorCodec) decode(token, dataset, queryHash string, now time.Time) (*cursorToken, error) {
+	payload, sig, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(sig), []byte(c.sign(payload))) {
+		return nil, errCursorInvalid
+	}
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, errCursorInvalid
+	}
+	var tok cursorToken
+	if err := json.Unmarshal(data, &tok); err != nil || tok.V != cursorVersion {
+		return nil, errCursorInvalid
+	}
+	if now.Unix() > tok.Expires {
+		return nil, errCursorExpired
+	}
+	if tok.Dataset != dataset || tok.QueryHash != queryHash {
+		return nil, errCursorMismatch
+	}
+	return &tok, nil
+}
+
+// queryHash связывает токен с запросом: продолжать обход с другим where/
+// order_by бессмысленно — keyset-позиция относится к другому порядку строк.
+func queryHash(where, orderBy string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(where) + "\x00" + strings.TrimSpace(orderBy)))
+	return hex.EncodeToString(sum[:8])
+}
+
+// keysetOrder дополняет сортировку до однозначной: ключевые поля схемы
+// (или все поля, если ключей нет), которых ещё нет в order_by, по возрастанию.
+// Имена полей приводятся к каноническим из схемы.
+func keysetOrder(ob *packet.OrderBy, sch packet.Schema) ([]packet.OrderField, error) {
+	var fields []packet.OrderField
+	if ob != nil {
+		if ob.Field != "" {
+			fields = append(fields, packet.OrderField{Name: ob.Field, Direction: ob.Direction})
+		}
+		fields = append(fields, ob.Fields...)
+	}
+
+	v := schema.NewValidator()
+	seen := make(map[string]bool, len(fields))
+	for i := range fields {
+		f, err := v.GetFieldByName(sch, fields[i].Name)
+		if err != nil {
+			return nil, fmt.Errorf("ORDER BY: field '%s' not found in schema", fields[i].Name)
+		}
+		fields[i].Name = f.Name
+		seen[strings.ToLower(f.Name)] = true
+	}
+
+	hasKey := false
+	for _, f := range sch.Fields {
+		if f.Key {
+			hasKey = true
+			break
+		}
+	}
+	for _, f := range sch.Fields {
+		if (hasKey && !f.Key) || seen[strings.ToLower(f.Name)] {
+			continue
+		}
+		fields = append(fields, packet.OrderField{Name: f.Name, Direction: "ASC"})
+	}
+	return fields, nil
+}
+
+// keysetFilter строит условие «строка после keys в порядке order»:
+// (f1 > v1) OR (f1 = v1 AND f2 > v2) OR … — для DESC-полей «<».
+//
+// TEXT-поля сравниваются с явным cast TEXT: иначе числовое значение ключа
+// включило бы автоприведение к REAL (tdtql.Executor.CoerceTextFilters), и порядок
+// фильтра разошёлся бы со строковой сортировкой.
+func keysetFilter(order []packet.OrderField, keys []string, sch packet.Schema) packet.LogicalGroup {
+	types := make(map[string]string, len(sch.Fields))
+	for _, f := range sch.Fields {
+		types[f.Name] = f.Type
+	}
+	castFor := func(name string) string {
+		if schema.NormalizeType(schema.DataType(types[name])) == schema.TypeText {
+			return string(schema.TypeText)
+		}
+		return ""
+	}
+
+	var after packet.LogicalGroup // OR-группа
+	for i := range order {
+		var branch packet.LogicalGroup // AND-группа
+		for j := 0; j < i; j++ {
+			branch.Filters = append(branch.Filters, packet.Filter{
+				Field: order[j].Name, Operator: "eq", Value: keys[j], Cast: castFor(order[j].Name),
+			})
+		}
+		op := "gt"
+		if strings.EqualFold(order[i].Direction, "DESC") {
+			op = "lt"
+		}
+		branch.Filters = append(branch.Filters, packet.Filter{
+			Field: order[i].Name, Operator: op, Value: keys[i], Cast: castFor(order[i].Name),
+		})
+		after.And = append(after.And, branch)
+	}
+	return after
+}
+
+// withKeyset объединяет пользовательские фильтры с keyset-условием через AND.
+func withKeyset(user *packet.Filters, after packet.LogicalGroup) *packet.Filters {
+	top := &packet.LogicalGroup{Or: []packet.LogicalGroup{after}}
+	if user != nil {
+		if user.And != nil {
+			top.And = append(top.And, *user.And)
+		}
+		if user.Or != nil {
+			top.Or = append(top.Or, *user.Or)
+		}
+	}
+	return &packet.Filters{And: top}
+}
+
+// apiQueryResponse is the JSON shape for GET /api/query/<name>.
+type apiQueryResponse struct {
+	Name       string        `json:"name"`
+	Schema     packet.Schema `json:"schema"`
+	Rows       [][]string    `json:"rows"`
+	RowCount   int           `json:"row_count"`
+	HasMore    bool          `json:"has_more"`
+	NextCursor string        `json:"next_cursor,omitempty"`
+}
+
+// handleAPIQuery serves GET /api/query/<name>?where=&order_by=&limit=&cursor=.
+// limit — размер страницы (по умолчанию 100, максимум 10000). Первая
+// страница — без cursor; следующие — с next_cursor из предыдущего ответа и
+// теми же where/order_by. Невалидный токен → 400, просроченный → 410.
+func (s *Server) handleAPIQuery(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, "/api/query/")
+	name = strings.TrimSuffix(name, "/")
+	if name == "" {
+		writeAPIError(w, http.StatusBadRequest, "dataset name required: /api/query/<name>")
+		return
+	}
+	if !s.authorize(w, r, permQuery, name) {
+		return
+	}
+
+	s.mu.RLock()
+	ds, found := s.datasets[name]
+	s.mu.RUnlock()
+	if !found {
+		writeAPIError(w, http.StatusNotFound, "dataset not found: "+name)
+		return
+	}
+
+	q := r.URL.Query()
+	where, orderBy := q.Get("where"), q.Get("order_by")
+	pageSize := defaultQueryPageSize
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			writeAPIError(w, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+		pageSize = min(n, maxQueryPageSize)
+	}
+
+	base, err := buildQuery(where, orderBy, 0, 0)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	query := packet.NewQuery()
+	var userOrder *packet.OrderBy
+	if base != nil {
+		query.Filters = base.Filters
+		userOrder = base.OrderBy
+	}
+	sch := ds.Packet.Schema
+	order, err := keysetOrder(userOrder, sch)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	query.OrderBy = &packet.OrderBy{Fields: order}
+	query.Limit = pageSize
+
+	qh := queryHash(where, orderBy)
+	now := time.Now()
+	if token := q.Get("cursor"); token != "" {
+		tok, err := s.cursors.decode(token, name, qh, now)
+		if err != nil {
+			status := http.StatusBadRequest
+			if errors.Is(err, errCursorExpired) {
+				status = http.StatusGone
+			}
+			writeAPIError(w, status, err.Error())
+			return
+		}
+		if len(tok.Keys) != len(order) {
+			writeAPIError(w, http.StatusBadRequest, errCursorMismatch.Error())
+			return
+		}
+		query.Filters = withKeyset(query.Filters, keysetFilter(order, tok.Keys, sch))
+	}
+
+	result, err := tdtql.NewExecutor().Execute(query, extractRows(ds.Packet), sch)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp := apiQueryResponse{
+		Name:     ds.Name,
+		Schema:   sch,
+		Rows:     result.FilteredRows,
+		RowCount: len(result.FilteredRows),
+		HasMore:  result.MoreAvailable,
+	}
+	if resp.HasMore {
+		last := result.FilteredRows[len(result.FilteredRows)-1]
+		idx := make(map[string]int, len(sch.Fields))
+		for i, f := range sch.Fields {
+			idx[f.Name] = i
+		}
+		keys := make([]string, len(order))
+		for i, f := range order {
+			keys[i] = last[idx[f.Name]]
+		}
+		resp.NextCursor, err = s.cursors.encode(name, qh, keys, now)
+		if err != nil {
+			writeAPIError(w, http.StatusInternalServerError, "encode cursor: "+err.Error())
+			return
+		}
+	}
+	noteRows(r, resp.RowCount)
+	s.auditServed(r, permQuery, name, resp.RowCount, "")
+	writeAPIJSON(w, http.StatusOK, resp)
+}
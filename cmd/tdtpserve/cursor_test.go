@@ -0,0 +1,137 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+func TestCursorCodec(t *testing.T) {
+	c, err := newCursorCodec("secret", 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	qh := queryHash("Balance > 10", "Name")
+
+	token, err := c.encode("Users", qh, []string{"Ann", "7"}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tok, err := c.decode(token, "Users", qh, now)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(tok.Keys) != 2 || tok.Keys[0] != "Ann" || tok.Keys[1] != "7" {
+		t.Errorf("keys = %v", tok.Keys)
+	}
+
+	if _, err := c.decode(token+"x", "Users", qh, now); !errors.Is(err, errCursorInvalid) {
+		t.Errorf("tampered token: err = %v, want errCursorInvalid", err)
+	}
+	other, _ := newCursorCodec("other", 60)
+	if _, err := other.decode(token, "Users", qh, now); !errors.Is(err, errCursorInvalid) {
+		t.Errorf("foreign secret: err = %v, want errCursorInvalid", err)
+	}
+	if _, err := c.decode(token, "Users", qh, now.Add(2*time.Minute)); !errors.Is(err, errCursorExpired) {
+		t.Errorf("expired token: err = %v, want errCursorExpired", err)
+	}
+	if _, err := c.decode(token, "Users", queryHash("", "Name"), now); !errors.Is(err, errCursorMismatch) {
+		t.Errorf("changed query: err = %v, want errCursorMismatch", err)
+	}
+	if _, err := c.decode(token, "Orders", qh, now); !errors.Is(err, errCursorMismatch) {
+		t.Errorf("other dataset: err = %v, want errCursorMismatch", err)
+	}
+}
+
+func newCursorTestServer(t *testing.T) *Server {
+	t.Helper()
+	pkt := &packet.DataPacket{
+		Schema: packet.Schema{Fields: []packet.Field{
+			{Name: "ID", Type: "INTEGER", Key: true},
+			{Name: "City", Type: "TEXT"},
+		}},
+	}
+	cities := []string{"Kazan", "Moscow", "Omsk"}
+	for i := 1; i <= 25; i++ {
+		pkt.Data.Rows = append(pkt.Data.Rows, packet.Row{Value: fmt.Sprintf("%d|%s", i, cities[i%3])})
+	}
+	codec, err := newCursorCodec("", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Server{
+		datasets: map[string]*Dataset{"Users": {Name: "Users", Packet: pkt}},
+		cursors:  codec,
+	}
+}
+
+// Обход по страницам возвращает каждую строку ровно один раз в порядке
+// order_by + ключ, даже при повторяющихся значениях сортировки.
+func TestHandleAPIQuery_WalksAllPages(t *testing.T) {
+	srv := newCursorTestServer(t)
+
+	params := url.Values{"order_by": {"City DESC"}, "limit": {"4"}, "where": {"ID > 2"}}
+	var seen []string
+	for page := 0; page < 20; page++ {
+		rec := httptest.NewRecorder()
+		srv.handleAPIQuery(rec, httptest.NewRequest(http.MethodGet, "/api/query/Users?"+params.Encode(), nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("page %d: status %d: %s", page, rec.Code, rec.Body.String())
+		}
+		var resp apiQueryResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		for _, row := range resp.Rows {
+			seen = append(seen, row[1]+"/"+row[0])
+		}
+		if !resp.HasMore {
+			break
+		}
+		params.Set("cursor", resp.NextCursor)
+	}
+
+	if len(seen) != 23 {
+		t.Fatalf("expected 23 rows across pages, got %d: %v", len(seen), seen)
+	}
+	unique := make(map[string]bool)
+	for _, s := range seen {
+		if unique[s] {
+			t.Errorf("row %s returned twice", s)
+		}
+		unique[s] = true
+	}
+	if seen[0] != "Omsk/5" || seen[len(seen)-1] != "Kazan/24" {
+		t.Errorf("unexpected order: first=%s last=%s", seen[0], seen[len(seen)-1])
+	}
+}
+
+func TestHandleAPIQuery_RejectsChangedQuery(t *testing.T) {
+	srv := newCursorTestServer(t)
+
+	rec := httptest.NewRecorder()
+	srv.handleAPIQuery(rec, httptest.NewRequest(http.MethodGet, "/api/query/Users?limit=5", nil))
+	var resp apiQueryResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.NextCursor == "" {
+		t.Fatal("expected next_cursor on first page")
+	}
+
+	q := url.Values{"limit": {"5"}, "where": {"ID > 10"}, "cursor": {resp.NextCursor}}
+	rec = httptest.NewRecorder()
+	srv.handleAPIQuery(rec, httptest.NewRequest(http.MethodGet, "/api/query/Users?"+q.Encode(), nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("changed where with old cursor: status %d, want 400", rec.Code)
+	}
+}
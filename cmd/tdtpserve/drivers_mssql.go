@@ -0,0 +1,6 @@
+//go:build !nomssql && !minimal
+
+package main
+
+// MS SQL Server (go-mssqldb, pure Go). Исключается тегом -tags nomssql или -tags minimal.
+import _ "github.com/ruslano69/tdtp-framework/pkg/adapters/mssql"
@@ -0,0 +1,6 @@
+//go:build !nomysql && !minimal
+
+package main
+
+// MySQL (go-sql-driver/mysql, pure Go). Исключается тегом -tags nomysql или -tags minimal.
+import _ "github.com/ruslano69/tdtp-framework/pkg/adapters/mysql"
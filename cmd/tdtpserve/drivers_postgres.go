@@ -0,0 +1,6 @@
+//go:build !nopostgres && !minimal
+
+package main
+
+// PostgreSQL (pgx, pure Go). Исключается тегом -tags nopostgres или -tags minimal.
+import _ "github.com/ruslano69/tdtp-framework/pkg/adapters/postgres"
@@ -0,0 +1,6 @@
+//go:build !nosqlite
+
+package main
+
+// SQLite (modernc.org/sqlite, pure Go), входит в minimal. Исключается тегом -tags nosqlite.
+import _ "github.com/ruslano69/tdtp-framework/pkg/adapters/sqlite"
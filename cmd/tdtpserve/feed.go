@@ -0,0 +1,518 @@
+package main
+
+// feed.go — ленты изменений: подписка на новые и изменённые строки таблицы.
+//
+// Лента (feeds: в конфиге) — таблица DB-источника и способ отслеживания
+// изменений, те же стратегии, что у tdtpcli --sync-incremental: timestamp,
+// sequence, version по tracking_field, cdc и changetable — по журналу
+// источника. Раз в poll_seconds сервер читает изменения после контрольной
+// точки (ExportTableIncremental на адаптере из пула) и рассылает их
+// подписчикам ленты.
+//
+// Подписка — GET /api/feed/<name>?where=...: Server-Sent Events, а с
+// заголовком Upgrade: websocket — WebSocket. where — фильтр TDTQL в
+// синтаксисе /api/data; подписчик получает reference-пакеты только со
+// строками, прошедшими его фильтр. Пакеты удаления (cdc/changetable) несут
+// одни ключи и уходят всем подписчикам ленты.
+//
+// Контрольная точка живёт в памяти. Лента начинается с изменений после
+// старта сервера: первый опрос только фиксирует точку (для timestamp/
+// sequence/version — максимум tracking_field одним запросом), start_from
+// задаёт её явно. Переподключившийся клиент получает изменения с момента
+// переподключения — пропущенное он дочитывает через /api/query или tdtpcli.
+// Подписчик, не успевающий читать (очередь feedQueueSize пакетов),
+// отключается с ошибкой: медленный клиент не задерживает остальных.
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/websocket"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+	"github.com/ruslano69/tdtp-framework/pkg/core/tdtql"
+	"github.com/ruslano69/tdtp-framework/pkg/etl"
+	tdtpsync "github.com/ruslano69/tdtp-framework/pkg/sync"
+)
+
+const (
+	defaultFeedPollSeconds = 10
+	feedQueueSize          = 64               // пакетов в очереди подписчика
+	feedKeepAlive          = 30 * time.Second // комментарий SSE, чтобы прокси не закрывали соединение
+)
+
+// errFeedLagging — подписчик не успевал читать, очередь переполнилась.
+var errFeedLagging = errors.New("subscriber is too slow: queue overflow, resubscribe")
+
+// feed — лента изменений одной таблицы и её подписчики.
+type feed struct {
+	cfg FeedConfig
+	src etl.SourceConfig
+
+	mu         sync.Mutex
+	subs       map[*feedSubscriber]struct{}
+	checkpoint string
+	primed     bool
+	lastPoll   time.Time
+	lastErr    string
+	delivered  int64 // пакетов, отправленных подписчикам
+}
+
+// feedSubscriber — подписка: фильтр и очередь пакетов. Лента закрывает ch,
+// отключая подписчика; причина — в err.
+type feedSubscriber struct {
+	filters *packet.Filters // nil — все строки
+	ch      chan *packet.DataPacket
+	err     error
+}
+
+// newFeeds создаёт ленты из конфига (источники уже проверены loadConfig).
+func newFeeds(cfg *ServeConfig) map[string]*feed {
+	feeds := make(map[string]*feed, len(cfg.Feeds))
+	for _, fc := range cfg.Feeds {
+		f := &feed{cfg: fc, subs: make(map[*feedSubscriber]struct{}), checkpoint: fc.StartFrom, primed: fc.StartFrom != ""}
+		for _, src := range cfg.Sources {
+			if src.Name == fc.Source {
+				f.src = src
+				break
+			}
+		}
+		feeds[fc.Name] = f
+	}
+	return feeds
+}
+
+// feedIncrementalConfig — параметры инкрементального чтения ленты от checkpoint.
+func feedIncrementalConfig(fc FeedConfig, checkpoint string, batchSize int) tdtpsync.IncrementalConfig {
+	strategy := tdtpsync.TrackingStrategy(fc.Strategy)
+	if strategy == "" {
+		strategy = tdtpsync.TrackingTimestamp
+	}
+	return tdtpsync.IncrementalConfig{
+		Enabled:       true,
+		Mode:          tdtpsync.SyncModeIncremental,
+		Strategy:      strategy,
+		TrackingField: fc.TrackingField,
+		Slot:          fc.Slot,
+		InitialValue:  checkpoint,
+		BatchSize:     batchSize,
+		OrderBy:       "ASC",
+	}
+}
+
+// isStream — изменения приходят из журнала источника (cdc, changetable).
+func (f *feed) isStream() bool {
+	s := tdtpsync.TrackingStrategy(f.cfg.Strategy)
+	return s == tdtpsync.TrackingCDC || s == tdtpsync.TrackingChangeTable
+}
+
+func (f *feed) interval() time.Duration {
+	if f.cfg.PollSeconds > 0 {
+		return time.Duration(f.cfg.PollSeconds) * time.Second
+	}
+	return defaultFeedPollSeconds * time.Second
+}
+
+// startFeeds запускает опрос всех лент; возвращает функцию остановки.
+func (s *Server) startFeeds() (stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	for _, f := range s.feeds {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.runFeed(ctx, f)
+		}()
+	}
+	return func() {
+		cancel()
+		wg.Wait()
+	}
+}
+
+// runFeed опрашивает ленту до отмены ctx. Ошибка опроса не останавливает
+// ленту: следующий опрос повторяет чтение от той же контрольной точки.
+func (s *Server) runFeed(ctx context.Context, f *feed) {
+	ticker := time.NewTicker(f.interval())
+	defer ticker.Stop()
+	for {
+		if err := s.pollFeed(ctx, f); err != nil && ctx.Err() == nil {
+			fmt.Printf("  ⚠ feed %s: %v\n", f.cfg.Name, err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// pollFeed — один опрос: первый фиксирует контрольную точку, следующие
+// читают изменения после неё и рассылают подписчикам.
+func (s *Server) pollFeed(ctx context.Context, f *feed) error {
+	f.mu.Lock()
+	checkpoint, primed := f.checkpoint, f.primed
+	f.mu.Unlock()
+
+	a, release, err := s.pool.Acquire(ctx, f.src)
+	if err != nil {
+		f.fail(err)
+		return fmt.Errorf("source %q: %w", f.src.Name, err)
+	}
+	var packets []*packet.DataPacket
+	var last string
+	if primed {
+		packets, last, err = a.ExportTableIncremental(ctx, f.cfg.Table, feedIncrementalConfig(f.cfg, checkpoint, f.cfg.BatchSize))
+	} else {
+		last, err = f.currentCheckpoint(ctx, a)
+	}
+	release(err)
+	if err != nil {
+		f.fail(err)
+		return fmt.Errorf("%s.%s: %w", f.src.Name, f.cfg.Table, err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if last != "" {
+		f.checkpoint = last
+	}
+	f.primed = true
+	f.lastPoll = time.Now()
+	f.lastErr = ""
+	f.publishLocked(packets)
+	return nil
+}
+
+// currentCheckpoint — контрольная точка «сейчас»: максимум tracking_field
+// (одна строка по убыванию) либо позиция журнала источника, с которой
+// следующий опрос начнёт читать.
+func (f *feed) currentCheckpoint(ctx context.Context, a adapters.Adapter) (string, error) {
+	if f.isStream() {
+		_, last, err := a.ExportTableIncremental(ctx, f.cfg.Table, feedIncrementalConfig(f.cfg, "", 0))
+		return last, err
+	}
+	q := packet.NewQuery()
+	q.Fields = []string{f.cfg.TrackingField}
+	q.OrderBy = &packet.OrderBy{Field: f.cfg.TrackingField, Direction: "DESC"}
+	q.Limit = 1
+	packets, err := a.ExportTableWithQuery(ctx, f.cfg.Table, q, "tdtpserve", "")
+	if err != nil {
+		return "", err
+	}
+	for _, pkt := range packets {
+		idx := -1
+		for i, field := range pkt.Schema.Fields {
+			if strings.EqualFold(field.Name, f.cfg.TrackingField) {
+				idx = i
+				break
+			}
+		}
+		if rows := pkt.GetRows(); idx >= 0 && len(rows) > 0 && idx < len(rows[0]) {
+			return rows[0][idx], nil
+		}
+	}
+	return "", nil // пустая таблица: следующий опрос прочитает всё, что появится
+}
+
+func (f *feed) fail(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.lastPoll = time.Now()
+	f.lastErr = err.Error()
+}
+
+// subscribe регистрирует подписчика с фильтром filters (nil — все строки).
+func (f *feed) subscribe(filters *packet.Filters) *feedSubscriber {
+	sub := &feedSubscriber{filters: filters, ch: make(chan *packet.DataPacket, feedQueueSize)}
+	f.mu.Lock()
+	f.subs[sub] = struct{}{}
+	f.mu.Unlock()
+	return sub
+}
+
+// unsubscribe снимает подписку (повторный вызов и снятие лентой безопасны).
+func (f *feed) unsubscribe(sub *feedSubscriber) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.dropLocked(sub, nil)
+}
+
+func (f *feed) dropLocked(sub *feedSubscriber, err error) {
+	if _, ok := f.subs[sub]; !ok {
+		return
+	}
+	delete(f.subs, sub)
+	sub.err = err
+	close(sub.ch)
+}
+
+// publishLocked рассылает пакеты подписчикам, каждому — со строками его
+// фильтра. Вызывается под f.mu.
+func (f *feed) publishLocked(packets []*packet.DataPacket) {
+	for sub := range f.subs {
+		for _, pkt := range packets {
+			out, err := sub.match(pkt)
+			if err != nil {
+				f.dropLocked(sub, fmt.Errorf("filter: %w", err))
+				break
+			}
+			if !f.sendLocked(sub, out) {
+				break
+			}
+		}
+	}
+}
+
+// sendLocked кладёт пакеты в очередь подписчика; при переполнении
+// отключает его и возвращает false.
+func (f *feed) sendLocked(sub *feedSubscriber, packets []*packet.DataPacket) bool {
+	for _, pkt := range packets {
+		select {
+		case sub.ch <- pkt:
+			f.delivered++
+		default:
+			f.dropLocked(sub, errFeedLagging)
+			return false
+		}
+	}
+	return true
+}
+
+// match — пакеты со строками pkt, прошедшими фильтр подписчика. Пакет без
+// фильтра и пакет удаления (одни ключи) передаются как есть.
+func (sub *feedSubscriber) match(pkt *packet.DataPacket) ([]*packet.DataPacket, error) {
+	if sub.filters == nil || pkt.Data.Delete {
+		return []*packet.DataPacket{pkt}, nil
+	}
+	rows, err := tdtql.NewExecutor().ExecuteWhere(sub.filters, pkt.GetRows(), pkt.Schema)
+	if err != nil || len(rows) == 0 {
+		return nil, err
+	}
+	return packet.NewGenerator().GenerateReference(pkt.Header.TableName, pkt.Schema, rows)
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// HTTP: GET /api/feed/<name> (SSE / WebSocket), GET /api/feeds
+// ─────────────────────────────────────────────────────────────────────────────
+
+// handleAPIFeed serves GET /api/feed/<name>?where=...&format=json|xml.
+func (s *Server) handleAPIFeed(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeAPIError(w, http.StatusMethodNotAllowed, "GET required")
+		return
+	}
+	name := strings.TrimPrefix(r.URL.Path, "/api/feed/")
+	if !s.authorize(w, r, permSubscribe, name) {
+		return
+	}
+	f, ok := s.feeds[name]
+	if !ok {
+		writeAPIError(w, http.StatusNotFound, fmt.Sprintf("feed not found: %s", name))
+		return
+	}
+	var filters *packet.Filters
+	if where := r.URL.Query().Get("where"); where != "" {
+		var err error
+		if filters, err = parseWhere(where); err != nil {
+			writeAPIError(w, http.StatusBadRequest, "WHERE: "+err.Error())
+			return
+		}
+	}
+	format := r.URL.Query().Get("format")
+	switch format {
+	case "":
+		format = "json"
+	case "json", "xml":
+	default:
+		writeAPIError(w, http.StatusBadRequest, fmt.Sprintf("unknown format %q (json/xml)", format))
+		return
+	}
+
+	if strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+		s.serveFeedWebSocket(w, r, f, filters, format)
+		return
+	}
+	s.serveFeedSSE(w, r, f, filters, format)
+}
+
+// encodeFeedPacket сериализует пакет для подписчика.
+func encodeFeedPacket(pkt *packet.DataPacket, format string) ([]byte, error) {
+	if format == "xml" {
+		return packet.NewGenerator().ToXML(pkt, false)
+	}
+	return packet.NewGenerator().ToJSON(pkt)
+}
+
+// feedHello — первое сообщение подписки.
+type feedHello struct {
+	Feed   string `json:"feed"`
+	Source string `json:"source"`
+	Table  string `json:"table"`
+}
+
+// serveFeedSSE: событие subscribed, затем событие packet на пакет (id —
+// MessageID); при отключении лентой — событие error.
+func (s *Server) serveFeedSSE(w http.ResponseWriter, r *http.Request, f *feed, filters *packet.Filters, format string) {
+	sub := f.subscribe(filters)
+	defer f.unsubscribe(sub)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	flusher, _ := w.(http.Flusher)
+	flush := func() {
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+	hello, _ := json.Marshal(feedHello{Feed: f.cfg.Name, Source: f.cfg.Source, Table: f.cfg.Table})
+	writeSSE(w, "subscribed", "", hello)
+	flush()
+
+	keepAlive := time.NewTicker(feedKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			_, _ = io.WriteString(w, ": keepalive\n\n")
+			flush()
+		case pkt, ok := <-sub.ch:
+			if !ok {
+				if sub.err != nil {
+					msg, _ := json.Marshal(map[string]string{"error": sub.err.Error()})
+					writeSSE(w, "error", "", msg)
+					flush()
+				}
+				return
+			}
+			data, err := encodeFeedPacket(pkt, format)
+			if err != nil {
+				fmt.Printf("  ⚠ feed %s: %v\n", f.cfg.Name, err)
+				continue
+			}
+			writeSSE(w, "packet", pkt.Header.MessageID, data)
+			flush()
+			noteRows(r, pkt.Header.RecordsInPart)
+			s.auditServed(r, permSubscribe, f.cfg.Name, pkt.Header.RecordsInPart, pkt.Header.MessageID)
+		}
+	}
+}
+
+// writeSSE пишет событие; многострочные данные (XML) — строкой data: на
+// каждую строку, клиент склеивает их через \n.
+func writeSSE(w io.Writer, event, id string, data []byte) {
+	var b strings.Builder
+	b.WriteString("event: " + event + "\n")
+	if id != "" {
+		b.WriteString("id: " + id + "\n")
+	}
+	for _, line := range strings.Split(strings.TrimRight(string(data), "\n"), "\n") {
+		b.WriteString("data: " + strings.TrimRight(line, "\r") + "\n")
+	}
+	b.WriteString("\n")
+	_, _ = io.WriteString(w, b.String())
+}
+
+// serveFeedWebSocket: первое сообщение — feedHello, затем текстовое
+// сообщение на пакет; при отключении лентой — {"error": ...} и закрытие.
+// Сообщения клиента не ожидаются и читаются только до закрытия соединения.
+func (s *Server) serveFeedWebSocket(w http.ResponseWriter, r *http.Request, f *feed, filters *packet.Filters, format string) {
+	websocket.Server{
+		// Подписчики — клиенты API (ключ или токен, см. auth.go), а не
+		// страницы браузера: Origin не проверяется, как и у остальных /api/*.
+		Handshake: func(*websocket.Config, *http.Request) error { return nil },
+		Handler: func(ws *websocket.Conn) {
+			defer ws.Close()
+			sub := f.subscribe(filters)
+			defer f.unsubscribe(sub)
+
+			closed := make(chan struct{})
+			go func() {
+				_, _ = io.Copy(io.Discard, ws)
+				close(closed)
+			}()
+
+			if err := websocket.JSON.Send(ws, feedHello{Feed: f.cfg.Name, Source: f.cfg.Source, Table: f.cfg.Table}); err != nil {
+				return
+			}
+			for {
+				select {
+				case <-closed:
+					return
+				case pkt, ok := <-sub.ch:
+					if !ok {
+						if sub.err != nil {
+							_ = websocket.JSON.Send(ws, map[string]string{"error": sub.err.Error()})
+						}
+						return
+					}
+					data, err := encodeFeedPacket(pkt, format)
+					if err != nil {
+						fmt.Printf("  ⚠ feed %s: %v\n", f.cfg.Name, err)
+						continue
+					}
+					if err := websocket.Message.Send(ws, string(data)); err != nil {
+						return
+					}
+					noteRows(r, pkt.Header.RecordsInPart)
+					s.auditServed(r, permSubscribe, f.cfg.Name, pkt.Header.RecordsInPart, pkt.Header.MessageID)
+				}
+			}
+		},
+	}.ServeHTTP(w, r)
+}
+
+// feedStatus is one entry in GET /api/feeds.
+type feedStatus struct {
+	Name        string     `json:"name"`
+	Source      string     `json:"source"`
+	Table       string     `json:"table"`
+	Strategy    string     `json:"strategy"`
+	Subscribers int        `json:"subscribers"`
+	Checkpoint  string     `json:"checkpoint,omitempty"`
+	LastPoll    *time.Time `json:"last_poll,omitempty"`
+	LastError   string     `json:"last_error,omitempty"`
+	Delivered   int64      `json:"delivered"`
+}
+
+// handleAPIFeeds serves GET /api/feeds — состояние лент, на которые
+// вызывающий может подписаться.
+func (s *Server) handleAPIFeeds(w http.ResponseWriter, r *http.Request) {
+	out := make([]feedStatus, 0, len(s.feeds))
+	for _, f := range s.feeds {
+		if !s.allows(r, permSubscribe, f.cfg.Name) {
+			continue
+		}
+		f.mu.Lock()
+		st := feedStatus{
+			Name:        f.cfg.Name,
+			Source:      f.cfg.Source,
+			Table:       f.cfg.Table,
+			Strategy:    string(feedIncrementalConfig(f.cfg, "", 0).Strategy),
+			Subscribers: len(f.subs),
+			Checkpoint:  f.checkpoint,
+			LastError:   f.lastErr,
+			Delivered:   f.delivered,
+		}
+		if !f.lastPoll.IsZero() {
+			lastPoll := f.lastPoll
+			st.LastPoll = &lastPoll
+		}
+		f.mu.Unlock()
+		out = append(out, st)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	writeAPIJSON(w, http.StatusOK, out)
+}
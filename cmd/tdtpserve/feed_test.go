@@ -0,0 +1,303 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"golang.org/x/net/websocket"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+	"github.com/ruslano69/tdtp-framework/pkg/etl"
+)
+
+// feedAdapter — таблица Orders (ID, City, Updated) в памяти: инкрементальное
+// чтение по Updated > InitialValue, как у SQL-адаптеров.
+type feedAdapter struct {
+	adapters.Adapter
+	mu   sync.Mutex
+	rows [][]string
+}
+
+var feedSchema = packet.Schema{Fields: []packet.Field{
+	{Name: "ID", Type: "INTEGER", Key: true},
+	{Name: "City", Type: "TEXT"},
+	{Name: "Updated", Type: "TEXT"},
+}}
+
+func (a *feedAdapter) Ping(context.Context) error  { return nil }
+func (a *feedAdapter) Close(context.Context) error { return nil }
+
+func (a *feedAdapter) add(id int, city string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.rows = append(a.rows, []string{fmt.Sprint(id), city, fmt.Sprintf("%03d", id)})
+}
+
+func (a *feedAdapter) ExportTableWithQuery(_ context.Context, table string, q *packet.Query, _, _ string) ([]*packet.DataPacket, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if q.OrderBy == nil || q.OrderBy.Direction != "DESC" || q.Limit != 1 {
+		return nil, fmt.Errorf("unexpected query")
+	}
+	if len(a.rows) == 0 {
+		return nil, nil
+	}
+	last := a.rows[len(a.rows)-1]
+	return packet.NewGenerator().GenerateReference(table, packet.Schema{Fields: feedSchema.Fields[2:]}, [][]string{{last[2]}})
+}
+
+func (a *feedAdapter) ExportTableIncremental(_ context.Context, table string, cfg adapters.IncrementalConfig) ([]*packet.DataPacket, string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	var rows [][]string
+	last := cfg.InitialValue
+	for _, row := range a.rows {
+		if row[2] > cfg.InitialValue {
+			rows = append(rows, row)
+			last = row[2]
+		}
+	}
+	if len(rows) == 0 {
+		return nil, last, nil
+	}
+	packets, err := packet.NewGenerator().GenerateReference(table, feedSchema, rows)
+	return packets, last, err
+}
+
+func newFeedTestServer(t *testing.T) (*Server, *feedAdapter) {
+	t.Helper()
+	a := &feedAdapter{}
+	for id := 1; id <= 5; id++ {
+		a.add(id, "Kazan")
+	}
+	cfg := &ServeConfig{
+		Sources: []etl.SourceConfig{{Name: "shop", Type: "postgres"}},
+		Feeds:   []FeedConfig{{Name: "orders", Source: "shop", Table: "Orders", TrackingField: "Updated"}},
+	}
+	srv := &Server{cfg: cfg, feeds: newFeeds(cfg)}
+	srv.pool = newAdapterPool(cfg, func(context.Context, etl.SourceConfig) (adapters.Adapter, error) { return a, nil })
+	return srv, a
+}
+
+func ids(packets ...*packet.DataPacket) []string {
+	var out []string
+	for _, pkt := range packets {
+		for _, row := range pkt.GetRows() {
+			out = append(out, row[0])
+		}
+	}
+	return out
+}
+
+// Первый опрос только фиксирует контрольную точку; следующие рассылают
+// новые строки, каждому подписчику — по его фильтру.
+func TestFeed_PollPublishesFilteredChanges(t *testing.T) {
+	srv, a := newFeedTestServer(t)
+	f := srv.feeds["orders"]
+	ctx := context.Background()
+
+	all := f.subscribe(nil)
+	filters, err := parseWhere("City = 'Omsk'")
+	if err != nil {
+		t.Fatal(err)
+	}
+	omsk := f.subscribe(filters)
+
+	if err := srv.pollFeed(ctx, f); err != nil {
+		t.Fatal(err)
+	}
+	if f.checkpoint != "005" || len(all.ch) != 0 {
+		t.Fatalf("prime: checkpoint %q, %d packet(s) queued; want 005 and none", f.checkpoint, len(all.ch))
+	}
+
+	a.add(6, "Omsk")
+	a.add(7, "Kazan")
+	a.add(8, "Omsk")
+	if err := srv.pollFeed(ctx, f); err != nil {
+		t.Fatal(err)
+	}
+	if f.checkpoint != "008" {
+		t.Errorf("checkpoint = %q, want 008", f.checkpoint)
+	}
+	if got := ids(<-all.ch); strings.Join(got, ",") != "6,7,8" {
+		t.Errorf("unfiltered subscriber got %v", got)
+	}
+	if got := ids(<-omsk.ch); strings.Join(got, ",") != "6,8" {
+		t.Errorf("filtered subscriber got %v", got)
+	}
+
+	// Без изменений — ничего не рассылается
+	if err := srv.pollFeed(ctx, f); err != nil {
+		t.Fatal(err)
+	}
+	if len(all.ch) != 0 || len(omsk.ch) != 0 {
+		t.Error("poll without changes must not publish")
+	}
+}
+
+// Переполненная очередь отключает подписчика с errFeedLagging, остальные
+// продолжают получать пакеты.
+func TestFeed_SlowSubscriberDropped(t *testing.T) {
+	srv, _ := newFeedTestServer(t)
+	f := srv.feeds["orders"]
+	slow, fast := f.subscribe(nil), f.subscribe(nil)
+
+	pkt := &packet.DataPacket{Schema: feedSchema}
+	f.mu.Lock()
+	for range feedQueueSize + 1 {
+		f.publishLocked([]*packet.DataPacket{pkt})
+		<-fast.ch
+	}
+	f.mu.Unlock()
+
+	for range slow.ch {
+	}
+	if slow.err != errFeedLagging {
+		t.Errorf("slow subscriber err = %v, want errFeedLagging", slow.err)
+	}
+	if _, ok := f.subs[fast]; !ok {
+		t.Error("fast subscriber must stay subscribed")
+	}
+	f.unsubscribe(slow) // повторное снятие безопасно
+}
+
+// SSE: событие subscribed, затем событие packet с JSON-пакетом.
+func TestHandleAPIFeed_SSE(t *testing.T) {
+	srv, a := newFeedTestServer(t)
+	f := srv.feeds["orders"]
+	if err := srv.pollFeed(context.Background(), f); err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(http.HandlerFunc(srv.handleAPIFeed))
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/api/feed/orders?where=" + url.QueryEscape("City = 'Omsk'"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+	events := readSSE(bufio.NewReader(resp.Body))
+
+	if ev := <-events; ev.name != "subscribed" || !strings.Contains(ev.data, `"table":"Orders"`) {
+		t.Fatalf("first event = %+v", ev)
+	}
+	a.add(6, "Omsk")
+	a.add(7, "Kazan")
+	if err := srv.pollFeed(context.Background(), f); err != nil {
+		t.Fatal(err)
+	}
+	ev := <-events
+	if ev.name != "packet" || ev.id == "" {
+		t.Fatalf("event = %+v", ev)
+	}
+	pkt, err := packet.NewParser().ParseJSON([]byte(ev.data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(pkt); strings.Join(got, ",") != "6" {
+		t.Errorf("rows = %v, want [6]", got)
+	}
+}
+
+type sseEvent struct{ name, id, data string }
+
+func readSSE(r *bufio.Reader) <-chan sseEvent {
+	out := make(chan sseEvent)
+	go func() {
+		defer close(out)
+		var ev sseEvent
+		var data []string
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\n")
+			switch {
+			case line == "":
+				if ev.name != "" {
+					ev.data = strings.Join(data, "\n")
+					out <- ev
+				}
+				ev, data = sseEvent{}, nil
+			case strings.HasPrefix(line, "event: "):
+				ev.name = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "id: "):
+				ev.id = strings.TrimPrefix(line, "id: ")
+			case strings.HasPrefix(line, "data: "):
+				data = append(data, strings.TrimPrefix(line, "data: "))
+			}
+		}
+	}()
+	return out
+}
+
+// WebSocket: приветствие, затем XML-пакет текстовым сообщением.
+func TestHandleAPIFeed_WebSocket(t *testing.T) {
+	srv, a := newFeedTestServer(t)
+	f := srv.feeds["orders"]
+	if err := srv.pollFeed(context.Background(), f); err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(http.HandlerFunc(srv.handleAPIFeed))
+	defer ts.Close()
+
+	ws, err := websocket.Dial("ws"+strings.TrimPrefix(ts.URL, "http")+"/api/feed/orders?format=xml", "", ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ws.Close()
+
+	var hello feedHello
+	if err := websocket.JSON.Receive(ws, &hello); err != nil {
+		t.Fatal(err)
+	}
+	if hello.Feed != "orders" || hello.Table != "Orders" {
+		t.Fatalf("hello = %+v", hello)
+	}
+	a.add(6, "Omsk")
+	if err := srv.pollFeed(context.Background(), f); err != nil {
+		t.Fatal(err)
+	}
+	var msg string
+	if err := websocket.Message.Receive(ws, &msg); err != nil {
+		t.Fatal(err)
+	}
+	pkt, err := packet.NewParser().ParseBytes([]byte(msg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(pkt); strings.Join(got, ",") != "6" {
+		t.Errorf("rows = %v, want [6]", got)
+	}
+}
+
+func TestHandleAPIFeed_Errors(t *testing.T) {
+	srv, _ := newFeedTestServer(t)
+	tests := []struct {
+		name, target string
+		status       int
+	}{
+		{"unknown feed", "/api/feed/missing", http.StatusNotFound},
+		{"bad where", "/api/feed/orders?where=" + url.QueryEscape("City"), http.StatusBadRequest},
+		{"bad format", "/api/feed/orders?format=csv", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		srv.handleAPIFeed(rec, httptest.NewRequest(http.MethodGet, tt.target, nil))
+		if rec.Code != tt.status {
+			t.Errorf("%s: status %d, want %d: %s", tt.name, rec.Code, tt.status, rec.Body.String())
+		}
+	}
+}
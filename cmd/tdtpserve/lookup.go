@@ -66,6 +66,9 @@ func loadLookups(cfgs []LookupConfig) (map[string]*Lookup, error) {
 func (s *Server) handleAPILookup(w http.ResponseWriter, r *http.Request) {
 	name := strings.TrimPrefix(r.URL.Path, "/api/lookup/")
 	name = strings.TrimSuffix(name, "/")
+	if !s.authorize(w, r, permRead, name) {
+		return
+	}
 
 	lk, ok := s.lookups[name]
 	if !ok {
@@ -91,13 +94,17 @@ func (s *Server) handleAPILookup(w http.ResponseWriter, r *http.Request) {
 	}
 	defer func() { _ = rows.Close() }()
 
+	var served int
 	switch lk.cfg.Result {
 	case "binary":
-		serveBinaryLookup(w, rows, lk.cfg)
+		served, ok = serveBinaryLookup(w, rows, lk.cfg)
 	case "row":
-		serveRowLookup(w, rows, false)
+		served, ok = serveRowLookup(w, rows, false)
 	default: // "rows"
-		serveRowLookup(w, rows, true, withMaxRows(lk.cfg.MaxRows))
+		served, ok = serveRowLookup(w, rows, true, withMaxRows(lk.cfg.MaxRows))
+	}
+	if ok {
+		s.auditServed(r, permRead, name, served, "")
 	}
 }
 
@@ -133,8 +140,9 @@ type rowLookupOpts struct{ maxRows int }
 func withMaxRows(n int) func(*rowLookupOpts) { return func(o *rowLookupOpts) { o.maxRows = n } }
 
 // serveRowLookup handles result: row (many=false, exactly one row expected)
-// and result: rows (many=true, 0..maxRows rows).
-func serveRowLookup(w http.ResponseWriter, rows *sql.Rows, many bool, opts ...func(*rowLookupOpts)) {
+// and result: rows (many=true, 0..maxRows rows). ok is false when an error
+// response was written instead.
+func serveRowLookup(w http.ResponseWriter, rows *sql.Rows, many bool, opts ...func(*rowLookupOpts)) (served int, ok bool) {
 	o := rowLookupOpts{maxRows: 100}
 	for _, fn := range opts {
 		fn(&o)
@@ -143,7 +151,7 @@ func serveRowLookup(w http.ResponseWriter, rows *sql.Rows, many bool, opts ...fu
 	cols, err := rows.Columns()
 	if err != nil {
 		writeAPIError(w, http.StatusInternalServerError, "reading columns: "+err.Error())
-		return
+		return 0, false
 	}
 
 	results := make([]map[string]any, 0, 1)
@@ -151,7 +159,7 @@ func serveRowLookup(w http.ResponseWriter, rows *sql.Rows, many bool, opts ...fu
 		row, err := scanColumns(rows, cols)
 		if err != nil {
 			writeAPIError(w, http.StatusInternalServerError, "scanning row: "+err.Error())
-			return
+			return 0, false
 		}
 		results = append(results, row)
 		if many && len(results) >= o.maxRows {
@@ -159,56 +167,58 @@ func serveRowLookup(w http.ResponseWriter, rows *sql.Rows, many bool, opts ...fu
 		}
 		if !many && len(results) > 1 {
 			writeAPIError(w, http.StatusInternalServerError, "result: row expects exactly one row, got more than one")
-			return
+			return 0, false
 		}
 	}
 	if err := rows.Err(); err != nil {
 		writeAPIError(w, http.StatusInternalServerError, "iterating rows: "+err.Error())
-		return
+		return 0, false
 	}
 
 	if !many {
 		if len(results) == 0 {
 			writeAPIError(w, http.StatusNotFound, "no matching row")
-			return
+			return 0, false
 		}
 		writeAPIJSON(w, http.StatusOK, results[0])
-		return
+		return 1, true
 	}
 	writeAPIJSON(w, http.StatusOK, results)
+	return len(results), true
 }
 
 // serveBinaryLookup handles result: binary — exactly one row, one column,
-// written as a raw byte response instead of JSON.
-func serveBinaryLookup(w http.ResponseWriter, rows *sql.Rows, cfg LookupConfig) {
+// written as a raw byte response instead of JSON. ok as for serveRowLookup.
+func serveBinaryLookup(w http.ResponseWriter, rows *sql.Rows, cfg LookupConfig) (served int, ok bool) {
 	cols, err := rows.Columns()
 	if err != nil {
 		writeAPIError(w, http.StatusInternalServerError, "reading columns: "+err.Error())
-		return
+		return 0, false
 	}
 	if len(cols) != 1 {
 		writeAPIError(w, http.StatusInternalServerError, fmt.Sprintf("result: binary expects exactly one column, got %d", len(cols)))
-		return
+		return 0, false
 	}
 
 	if !rows.Next() {
 		writeAPIError(w, http.StatusNotFound, "no matching row")
-		return
+		return 0, false
 	}
 	var payload []byte
 	if err := rows.Scan(&payload); err != nil {
 		writeAPIError(w, http.StatusInternalServerError, "scanning binary column: "+err.Error())
-		return
+		return 0, false
 	}
 	if rows.Next() {
 		writeAPIError(w, http.StatusInternalServerError, "result: binary expects exactly one row, got more than one")
-		return
+		return 0, false
 	}
 	if err := rows.Err(); err != nil {
 		writeAPIError(w, http.StatusInternalServerError, "iterating rows: "+err.Error())
-		return
+		return 0, false
 	}
 
 	w.Header().Set("Content-Type", cfg.ContentType)
 	_, _ = w.Write(payload)
+	return 1, true
 }
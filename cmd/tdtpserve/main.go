@@ -1,28 +1,59 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"os"
 
-	// DB adapter registrations — подключить достаточно, остальное уже написано
-	_ "github.com/ruslano69/tdtp-framework/pkg/adapters/mssql"
-	_ "github.com/ruslano69/tdtp-framework/pkg/adapters/mysql"
-	_ "github.com/ruslano69/tdtp-framework/pkg/adapters/postgres"
-	_ "github.com/ruslano69/tdtp-framework/pkg/adapters/sqlite"
+	"github.com/ruslano69/tdtp-framework/pkg/adapters/plugin"
 )
 
+// DB adapter registrations — в drivers_*.go, по файлу на адаптер со своим
+// build-тегом (no<adapter>, minimal); состав сборки — tdtpserve --version.
+// Внешние адаптеры подключаются в рантайме через --plugins (pkg/adapters/plugin).
+
 func main() {
+	// A child process of an isolated adapter serves it and exits here
+	plugin.ServeIsolated()
+
 	configFile := flag.String("config", "", "path to server config YAML (required)")
 	port := flag.Int("port", 0, "HTTP port, overrides config value")
+	showVersion := flag.Bool("version", false, "print build info and compiled-in adapters, then exit")
+	pluginDir := flag.String("plugins", plugin.DefaultDir(), "directory with external adapter plugins (default: $TDTP_PLUGIN_DIR)")
+	isolate := flag.String("isolate-adapters", plugin.DefaultIsolated(), "comma-separated adapter types to run in restartable child processes (default: $TDTP_ISOLATE_ADAPTERS)")
+	isolateRecycle := flag.Int("isolate-recycle", 0, "replace an isolated adapter's child process after this many calls (0 = never)")
 	flag.Parse()
 
+	// Adapters of plugins must be registered before loadConfig checks source types
+	if *pluginDir != "" {
+		if _, err := plugin.LoadDir(context.Background(), *pluginDir); err != nil {
+			fmt.Fprintf(os.Stderr, "  ⚠ plugins: %v\n", err)
+		}
+	}
+	// A crashing driver of an isolated adapter restarts its child process,
+	// not the server
+	if types := plugin.ParseIsolated(*isolate); len(types) > 0 {
+		if err := plugin.Isolate(plugin.IsolateOptions{RecycleAfter: *isolateRecycle}, types...); err != nil {
+			fmt.Fprintf(os.Stderr, "  ⚠ isolate-adapters: %v\n", err)
+		}
+	}
+
+	if *showVersion {
+		printVersion(os.Stdout)
+		return
+	}
+
 	if *configFile == "" {
 		fmt.Fprintln(os.Stderr, "Usage: tdtpserve --config <name>.yaml [--port 8080]")
 		fmt.Fprintln(os.Stderr, "")
 		fmt.Fprintln(os.Stderr, "Flags:")
 		fmt.Fprintln(os.Stderr, "  --config  path to YAML config file (required)")
 		fmt.Fprintln(os.Stderr, "  --port    HTTP port, overrides config (default: 8080)")
+		fmt.Fprintln(os.Stderr, "  --version print build info and compiled-in adapters")
+		fmt.Fprintln(os.Stderr, "  --plugins directory with external adapter plugins (default: $TDTP_PLUGIN_DIR)")
+		fmt.Fprintln(os.Stderr, "  --isolate-adapters  adapter types run in restartable child processes (default: $TDTP_ISOLATE_ADAPTERS)")
+		fmt.Fprintln(os.Stderr, "  --isolate-recycle   replace an isolated adapter's process after N calls")
 		os.Exit(1)
 	}
 
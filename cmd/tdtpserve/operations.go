@@ -0,0 +1,101 @@
+package main
+
+// operations.go — выполняющиеся операции хоста и их отмена.
+//
+// Реестр (pkg/operations) — каталог, общий для всех процессов хоста:
+// GET /api/operations показывает и запуски tdtpcli (--export, --import,
+// --sync-incremental, --pipeline...), и собственные refresh сервера.
+// POST /api/operations/<id>/cancel отменяет операцию любого из них — без
+// убийства процесса: её context отменяется, импорт откатывает транзакцию.
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/ruslano69/tdtp-framework/pkg/operations"
+)
+
+// openOperations открывает реестр. Недоступный каталог не фатален —
+// сервер работает, /api/operations отвечает 503.
+func openOperations(cfg *ServeConfig) *operations.Registry {
+	dir := cfg.Server.OperationsDir
+	if dir == "" {
+		dir = operations.DefaultDir()
+	}
+	reg, err := operations.Open(dir)
+	if err != nil {
+		fmt.Printf("  ⚠ %v — /api/operations disabled\n", err)
+		return nil
+	}
+	return reg
+}
+
+// startOperation регистрирует операцию сервера; без реестра возвращает ctx
+// как есть и nil (End у nil — no-op).
+func (s *Server) startOperation(ctx context.Context, command, name string) (context.Context, *operations.Operation) {
+	if s.ops == nil {
+		return ctx, nil
+	}
+	opCtx, op, err := s.ops.Start(ctx, command, name)
+	if err != nil {
+		fmt.Printf("  ⚠ %v\n", err)
+		return ctx, nil
+	}
+	return opCtx, op
+}
+
+// handleAPIOperations serves GET /api/operations.
+func (s *Server) handleAPIOperations(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeAPIError(w, http.StatusMethodNotAllowed, "GET required")
+		return
+	}
+	if !s.authorize(w, r, permAdmin, "") {
+		return
+	}
+	if s.ops == nil {
+		writeAPIError(w, http.StatusServiceUnavailable, "operations registry unavailable")
+		return
+	}
+	ops, err := s.ops.List()
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if ops == nil {
+		ops = []operations.Info{}
+	}
+	writeAPIJSON(w, http.StatusOK, ops)
+}
+
+// handleAPIOperationCancel serves POST /api/operations/<id>/cancel.
+func (s *Server) handleAPIOperationCancel(w http.ResponseWriter, r *http.Request) {
+	id, ok := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, "/api/operations/"), "/cancel")
+	if !ok || id == "" || strings.Contains(id, "/") {
+		writeAPIError(w, http.StatusNotFound, "expected /api/operations/<id>/cancel")
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeAPIError(w, http.StatusMethodNotAllowed, "POST required")
+		return
+	}
+	if !s.authorize(w, r, permAdmin, "") {
+		return
+	}
+	if s.ops == nil {
+		writeAPIError(w, http.StatusServiceUnavailable, "operations registry unavailable")
+		return
+	}
+	if err := s.ops.Cancel(id); err != nil {
+		if errors.Is(err, operations.ErrNotFound) {
+			writeAPIError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		writeAPIError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeAPIJSON(w, http.StatusAccepted, map[string]string{"status": "cancelling", "id": id})
+}
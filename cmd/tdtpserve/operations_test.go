@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ruslano69/tdtp-framework/pkg/operations"
+)
+
+func TestAPIOperations_ListAndCancel(t *testing.T) {
+	reg, err := operations.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Server{cfg: &ServeConfig{}, ops: reg}
+	ctx, op := s.startOperation(context.Background(), "refresh", "serve")
+	defer op.End()
+
+	rec := httptest.NewRecorder()
+	s.handleAPIOperations(rec, httptest.NewRequest(http.MethodGet, "/api/operations", nil))
+	var ops []operations.Info
+	if err := json.Unmarshal(rec.Body.Bytes(), &ops); err != nil {
+		t.Fatalf("body %q: %v", rec.Body, err)
+	}
+	if len(ops) != 1 || ops[0].ID != op.ID() || ops[0].Command != "refresh" {
+		t.Fatalf("operations = %+v", ops)
+	}
+
+	rec = httptest.NewRecorder()
+	s.handleAPIOperationCancel(rec, httptest.NewRequest(http.MethodPost, "/api/operations/"+op.ID()+"/cancel", nil))
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("cancel: %d %s", rec.Code, rec.Body)
+	}
+	<-ctx.Done()
+}
+
+func TestAPIOperationCancel_NotFound(t *testing.T) {
+	reg, err := operations.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Server{cfg: &ServeConfig{}, ops: reg}
+	for _, path := range []string{"/api/operations/op-0123456789ab/cancel", "/api/operations/op-01/stop"} {
+		rec := httptest.NewRecorder()
+		s.handleAPIOperationCancel(rec, httptest.NewRequest(http.MethodPost, path, nil))
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("%s: status %d, want 404", path, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,352 @@
+package main
+
+// pool.go — тёплый пул адаптеров БД-источников.
+//
+// Без пула etl.Loader открывает адаптер на каждую загрузку источника:
+// подключение, TLS, аутентификация и Ping — секунды на каждый
+// POST /api/refresh. Пул держит по источнику min_idle открытых адаптеров,
+// проверяет адаптер Ping перед выдачей (pre-flight), закрывает
+// простаивающие дольше idle_timeout и открывает им замену, а max_conns
+// ограничивает число одновременно занятых адаптеров источника — лишние
+// запросы ждут свободного, а не открывают новые соединения к проду.
+// Состояние пула — GET /api/pool.
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+	"github.com/ruslano69/tdtp-framework/pkg/etl"
+)
+
+const (
+	defaultPoolMaxConns    = 4
+	defaultPoolMinIdle     = 1
+	defaultPoolIdleTimeout = 5 * time.Minute
+	defaultPoolPingTimeout = 5 * time.Second
+)
+
+// adapterOpener открывает адаптер источника (etl.NewSourceAdapter; в тестах — фейк).
+type adapterOpener func(ctx context.Context, src etl.SourceConfig) (adapters.Adapter, error)
+
+type idleAdapter struct {
+	adapter adapters.Adapter
+	since   time.Time
+}
+
+// sourcePool — адаптеры одного источника.
+type sourcePool struct {
+	src     etl.SourceConfig
+	minIdle int
+	slots   chan struct{} // семафор занятых адаптеров, ёмкость — max_conns
+
+	mu             sync.Mutex
+	idle           []idleAdapter // LIFO: последним вернули — первым выдадут
+	opened         int64
+	recycled       int64
+	healthFailures int64
+}
+
+// adapterPool реализует etl.AdapterPool.
+type adapterPool struct {
+	open        adapterOpener
+	idleTimeout time.Duration
+	pingTimeout time.Duration
+	sources     map[string]*sourcePool
+	now         func() time.Time
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// newAdapterPool готовит пул для DB-источников конфига (tdtp-файлам
+// адаптер не нужен). Соединения не открываются до warm/Acquire.
+func newAdapterPool(cfg *ServeConfig, open adapterOpener) *adapterPool {
+	pc := cfg.Pool
+	p := &adapterPool{
+		open:        open,
+		idleTimeout: defaultPoolIdleTimeout,
+		pingTimeout: defaultPoolPingTimeout,
+		sources:     make(map[string]*sourcePool),
+		now:         time.Now,
+	}
+	if pc.IdleTimeoutSeconds > 0 {
+		p.idleTimeout = time.Duration(pc.IdleTimeoutSeconds) * time.Second
+	}
+	if pc.PingTimeoutSeconds > 0 {
+		p.pingTimeout = time.Duration(pc.PingTimeoutSeconds) * time.Second
+	}
+	for _, src := range cfg.Sources {
+		if !isDBSource(src.Type) {
+			continue
+		}
+		maxConns, minIdle := pc.limits(src.Name)
+		p.sources[src.Name] = &sourcePool{
+			src:     src,
+			minIdle: minIdle,
+			slots:   make(chan struct{}, maxConns),
+		}
+	}
+	return p
+}
+
+// isDBSource — источник читается через адаптер БД, а не из файла.
+func isDBSource(typ string) bool {
+	switch typ {
+	case "tdtp", "tdtp-enc", "tdtp-s3", etl.SourceTypePipeline:
+		return false
+	}
+	return true
+}
+
+// warm открывает min_idle адаптеров каждого источника. Ошибка на старте
+// фатальна — как и недоступный источник при первой загрузке.
+func (p *adapterPool) warm(ctx context.Context) error {
+	for _, name := range p.names() {
+		sp := p.sources[name]
+		if err := p.topUp(ctx, sp); err != nil {
+			return fmt.Errorf("pool %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// Acquire выдаёт проверенный адаптер источника, дожидаясь свободного слота
+// max_conns (или отмены ctx). release(err != nil) закрывает адаптер вместо
+// возврата в пул.
+func (p *adapterPool) Acquire(ctx context.Context, src etl.SourceConfig) (adapters.Adapter, func(error), error) {
+	sp, ok := p.sources[src.Name]
+	if !ok {
+		// Источник вне пула — открываем на одну загрузку
+		a, err := p.open(ctx, src)
+		if err != nil {
+			return nil, nil, err
+		}
+		return a, func(error) { _ = a.Close(context.Background()) }, nil
+	}
+
+	select {
+	case sp.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, nil, fmt.Errorf("source %q: all %d connection(s) busy: %w", src.Name, cap(sp.slots), ctx.Err())
+	}
+
+	a, err := p.checkout(ctx, sp)
+	if err != nil {
+		<-sp.slots
+		return nil, nil, err
+	}
+	release := func(err error) {
+		if err != nil {
+			_ = a.Close(context.Background())
+		} else {
+			sp.mu.Lock()
+			sp.idle = append(sp.idle, idleAdapter{adapter: a, since: p.now()})
+			sp.mu.Unlock()
+		}
+		<-sp.slots
+	}
+	return a, release, nil
+}
+
+// checkout берёт простаивающий адаптер, прошедший проверку, иначе открывает новый.
+func (p *adapterPool) checkout(ctx context.Context, sp *sourcePool) (adapters.Adapter, error) {
+	for {
+		sp.mu.Lock()
+		if len(sp.idle) == 0 {
+			sp.mu.Unlock()
+			return p.dial(ctx, sp)
+		}
+		ia := sp.idle[len(sp.idle)-1]
+		sp.idle = sp.idle[:len(sp.idle)-1]
+		stale := p.now().Sub(ia.since) > p.idleTimeout
+		if stale {
+			sp.recycled++
+		}
+		sp.mu.Unlock()
+
+		if stale {
+			_ = ia.adapter.Close(ctx)
+			continue
+		}
+		if err := p.ping(ctx, ia.adapter); err != nil {
+			sp.mu.Lock()
+			sp.healthFailures++
+			sp.mu.Unlock()
+			_ = ia.adapter.Close(ctx)
+			continue
+		}
+		return ia.adapter, nil
+	}
+}
+
+// dial открывает и проверяет новый адаптер.
+func (p *adapterPool) dial(ctx context.Context, sp *sourcePool) (adapters.Adapter, error) {
+	a, err := p.open(ctx, sp.src)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create adapter: %w", err)
+	}
+	if err := p.ping(ctx, a); err != nil {
+		_ = a.Close(ctx)
+		sp.mu.Lock()
+		sp.healthFailures++
+		sp.mu.Unlock()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+	sp.mu.Lock()
+	sp.opened++
+	sp.mu.Unlock()
+	return a, nil
+}
+
+func (p *adapterPool) ping(ctx context.Context, a adapters.Adapter) error {
+	ctx, cancel := context.WithTimeout(ctx, p.pingTimeout)
+	defer cancel()
+	return a.Ping(ctx)
+}
+
+// topUp доводит число простаивающих адаптеров до min_idle, не превышая
+// max_conns вместе с занятыми.
+func (p *adapterPool) topUp(ctx context.Context, sp *sourcePool) error {
+	for {
+		sp.mu.Lock()
+		need := len(sp.idle) < sp.minIdle && len(sp.idle)+len(sp.slots) < cap(sp.slots)
+		sp.mu.Unlock()
+		if !need {
+			return nil
+		}
+		a, err := p.dial(ctx, sp)
+		if err != nil {
+			return err
+		}
+		sp.mu.Lock()
+		sp.idle = append(sp.idle, idleAdapter{adapter: a, since: p.now()})
+		sp.mu.Unlock()
+	}
+}
+
+// recycle закрывает адаптеры, простаивающие дольше idle_timeout, и
+// заменяет их свежими до min_idle. Ошибка открытия замены не фатальна —
+// следующий Acquire откроет адаптер сам.
+func (p *adapterPool) recycle(ctx context.Context) {
+	for _, name := range p.names() {
+		sp := p.sources[name]
+		now := p.now()
+
+		sp.mu.Lock()
+		var stale []adapters.Adapter
+		kept := sp.idle[:0]
+		for _, ia := range sp.idle {
+			if now.Sub(ia.since) > p.idleTimeout {
+				stale = append(stale, ia.adapter)
+			} else {
+				kept = append(kept, ia)
+			}
+		}
+		sp.idle = kept
+		sp.recycled += int64(len(stale))
+		sp.mu.Unlock()
+
+		for _, a := range stale {
+			_ = a.Close(ctx)
+		}
+		if err := p.topUp(ctx, sp); err != nil {
+			fmt.Printf("  ⚠ pool %q: %v\n", name, err)
+		}
+	}
+}
+
+// start запускает фоновый recycle раз в половину idle_timeout.
+func (p *adapterPool) start() {
+	p.stop = make(chan struct{})
+	p.done = make(chan struct{})
+	go func() {
+		defer close(p.done)
+		ticker := time.NewTicker(max(p.idleTimeout/2, time.Second))
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				p.recycle(context.Background())
+			case <-p.stop:
+				return
+			}
+		}
+	}()
+}
+
+// close останавливает recycle и закрывает простаивающие адаптеры.
+func (p *adapterPool) close(ctx context.Context) {
+	if p.stop != nil {
+		close(p.stop)
+		<-p.done
+	}
+	for _, sp := range p.sources {
+		sp.mu.Lock()
+		idle := sp.idle
+		sp.idle = nil
+		sp.mu.Unlock()
+		for _, ia := range idle {
+			_ = ia.adapter.Close(ctx)
+		}
+	}
+}
+
+func (p *adapterPool) names() []string {
+	names := make([]string, 0, len(p.sources))
+	for name := range p.sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// poolStats — состояние пула одного источника (GET /api/pool).
+type poolStats struct {
+	Source         string `json:"source"`
+	Type           string `json:"type"`
+	Idle           int    `json:"idle"`
+	InUse          int    `json:"in_use"`
+	MaxConns       int    `json:"max_conns"`
+	MinIdle        int    `json:"min_idle"`
+	Opened         int64  `json:"opened"`
+	Recycled       int64  `json:"recycled"`
+	HealthFailures int64  `json:"health_failures"`
+}
+
+func (p *adapterPool) stats() []poolStats {
+	out := make([]poolStats, 0, len(p.sources))
+	for _, name := range p.names() {
+		sp := p.sources[name]
+		sp.mu.Lock()
+		out = append(out, poolStats{
+			Source:         name,
+			Type:           sp.src.Type,
+			Idle:           len(sp.idle),
+			InUse:          len(sp.slots),
+			MaxConns:       cap(sp.slots),
+			MinIdle:        sp.minIdle,
+			Opened:         sp.opened,
+			Recycled:       sp.recycled,
+			HealthFailures: sp.healthFailures,
+		})
+		sp.mu.Unlock()
+	}
+	return out
+}
+
+// handleAPIPool serves GET /api/pool.
+func (s *Server) handleAPIPool(w http.ResponseWriter, r *http.Request) {
+	if !s.authorize(w, r, permAdmin, "") {
+		return
+	}
+	if s.pool == nil {
+		writeAPIJSON(w, http.StatusOK, []poolStats{})
+		return
+	}
+	writeAPIJSON(w, http.StatusOK, s.pool.stats())
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+	"github.com/ruslano69/tdtp-framework/pkg/etl"
+)
+
+// fakeAdapter — adapters.Adapter с управляемым Ping; остальные методы не нужны.
+type fakeAdapter struct {
+	adapters.Adapter
+	id      int
+	pingErr error
+	closed  bool
+}
+
+func (a *fakeAdapter) Ping(context.Context) error  { return a.pingErr }
+func (a *fakeAdapter) Close(context.Context) error { a.closed = true; return nil }
+
+type fakeOpener struct{ opened []*fakeAdapter }
+
+func (o *fakeOpener) open(context.Context, etl.SourceConfig) (adapters.Adapter, error) {
+	a := &fakeAdapter{id: len(o.opened) + 1}
+	o.opened = append(o.opened, a)
+	return a, nil
+}
+
+func newTestPool(pc PoolConfig) (*adapterPool, *fakeOpener, *time.Time) {
+	cfg := &ServeConfig{
+		Sources: []etl.SourceConfig{
+			{Name: "users", Type: "postgres"},
+			{Name: "ref", Type: "tdtp"},
+		},
+		Pool: pc,
+	}
+	o := &fakeOpener{}
+	p := newAdapterPool(cfg, o.open)
+	now := time.Unix(1_700_000_000, 0)
+	p.now = func() time.Time { return now }
+	return p, o, &now
+}
+
+var usersSource = etl.SourceConfig{Name: "users", Type: "postgres"}
+
+func TestAdapterPool_WarmAndReuse(t *testing.T) {
+	p, o, _ := newTestPool(PoolConfig{MinIdle: 2})
+	if len(p.sources) != 1 {
+		t.Fatalf("pooled sources = %d, want only the DB source", len(p.sources))
+	}
+	if err := p.warm(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(o.opened) != 2 {
+		t.Fatalf("warm opened %d adapters, want 2", len(o.opened))
+	}
+
+	a, release, err := p.Acquire(context.Background(), usersSource)
+	if err != nil {
+		t.Fatal(err)
+	}
+	release(nil)
+	b, release, err := p.Acquire(context.Background(), usersSource)
+	if err != nil {
+		t.Fatal(err)
+	}
+	release(nil)
+	if a != b || len(o.opened) != 2 {
+		t.Errorf("released adapter must be reused, opened %d", len(o.opened))
+	}
+}
+
+func TestAdapterPool_HealthCheck(t *testing.T) {
+	p, o, _ := newTestPool(PoolConfig{})
+	if err := p.warm(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	o.opened[0].pingErr = errors.New("connection reset")
+
+	a, release, err := p.Acquire(context.Background(), usersSource)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release(nil)
+	if a == adapters.Adapter(o.opened[0]) || !o.opened[0].closed {
+		t.Error("broken adapter must be closed and replaced")
+	}
+	if st := p.stats()[0]; st.HealthFailures != 1 || st.Opened != 2 {
+		t.Errorf("stats = %+v", st)
+	}
+}
+
+func TestAdapterPool_ReleaseWithError(t *testing.T) {
+	p, o, _ := newTestPool(PoolConfig{})
+	_, release, err := p.Acquire(context.Background(), usersSource)
+	if err != nil {
+		t.Fatal(err)
+	}
+	release(errors.New("query failed"))
+	if !o.opened[0].closed {
+		t.Error("adapter released with error must be closed")
+	}
+	if st := p.stats()[0]; st.Idle != 0 || st.InUse != 0 {
+		t.Errorf("stats = %+v", st)
+	}
+}
+
+func TestAdapterPool_ConcurrencyCap(t *testing.T) {
+	p, _, _ := newTestPool(PoolConfig{Sources: map[string]PoolLimits{"users": {MaxConns: 1}}})
+	_, release, err := p.Acquire(context.Background(), usersSource)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, _, err := p.Acquire(ctx, usersSource); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("second Acquire: err = %v, want busy/deadline", err)
+	}
+
+	release(nil)
+	if _, release, err := p.Acquire(context.Background(), usersSource); err != nil {
+		t.Errorf("Acquire after release: %v", err)
+	} else {
+		release(nil)
+	}
+}
+
+func TestAdapterPool_Recycle(t *testing.T) {
+	p, o, now := newTestPool(PoolConfig{IdleTimeoutSeconds: 60})
+	if err := p.warm(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	*now = now.Add(2 * time.Minute)
+	p.recycle(context.Background())
+
+	if !o.opened[0].closed || len(o.opened) != 2 {
+		t.Errorf("idle adapter must be closed and replaced: opened %d", len(o.opened))
+	}
+	if st := p.stats()[0]; st.Idle != 1 || st.Recycled != 1 {
+		t.Errorf("stats = %+v", st)
+	}
+}
+
+func TestPoolConfigLimits(t *testing.T) {
+	pc := PoolConfig{MaxConns: 8, Sources: map[string]PoolLimits{"hr": {MaxConns: 2, MinIdle: 5}}}
+	if maxConns, minIdle := pc.limits("users"); maxConns != 8 || minIdle != 1 {
+		t.Errorf("users = %d/%d, want 8/1", maxConns, minIdle)
+	}
+	if maxConns, minIdle := pc.limits("hr"); maxConns != 2 || minIdle != 2 {
+		t.Errorf("hr = %d/%d, want 2/2 (min_idle capped by max_conns)", maxConns, minIdle)
+	}
+}
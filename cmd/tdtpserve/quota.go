@@ -0,0 +1,175 @@
+package main
+
+// quota.go — квоты получателей /api/*: сколько строк, байт и запросов
+// каждая команда-потребитель забирает за час и за сутки (pkg/quota).
+// Лимиты проверяются до выполнения запроса; исчерпанная квота — 429 с
+// состоянием квоты в теле. Каждый ответ несёт остаток в заголовках
+// X-Quota-*, полное состояние — GET /api/quota.
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/ruslano69/tdtp-framework/pkg/quota"
+)
+
+type quotaRowsKey struct{}
+
+// noteRows сообщает учёту квот число строк ответа. Без квот — no-op.
+func noteRows(r *http.Request, n int) {
+	if rows, ok := r.Context().Value(quotaRowsKey{}).(*atomic.Int64); ok {
+		rows.Add(int64(n))
+	}
+}
+
+// countingWriter считает байты тела ответа и запоминает статус.
+type countingWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (w *countingWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(p)
+	w.bytes += int64(n)
+	return n, err
+}
+
+// Flush и Hijack пробрасываются потоковым ответам (/query, /api/feed):
+// байты WebSocket после Hijack не считаются, строки — по noteRows.
+func (w *countingWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *countingWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response writer does not support hijacking")
+	}
+	if w.status == 0 {
+		w.status = http.StatusSwitchingProtocols
+	}
+	return h.Hijack()
+}
+
+// recipient — получатель запроса по X-API-Key, иначе principal (см.
+// auth.go), если он есть в quotas.recipients ("" — действует
+// quotas.default).
+func (s *Server) recipient(r *http.Request) string {
+	if recipient, ok := s.cfg.Quotas.Keys[r.Header.Get("X-API-Key")]; ok {
+		return recipient
+	}
+	if p := principalFrom(r); p != nil {
+		if _, ok := s.cfg.Quotas.Recipients[p.Name]; ok {
+			return p.Name
+		}
+	}
+	return ""
+}
+
+// withQuota оборачивает обработчик экспорта учётом квот. Неудачные ответы
+// (4xx/5xx) в расход не идут.
+func (s *Server) withQuota(h http.HandlerFunc) http.HandlerFunc {
+	if s.quotas == nil {
+		return h
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		recipient := s.recipient(r)
+		st, err := s.quotas.Check(recipient, time.Now())
+		switch {
+		case errors.Is(err, quota.ErrUnknownRecipient):
+			writeAPIError(w, http.StatusUnauthorized, "valid X-API-Key required")
+			return
+		case errors.Is(err, quota.ErrExceeded):
+			setQuotaHeaders(w, st)
+			w.Header().Set("Retry-After", strconv.Itoa(retryAfter(st)))
+			writeAPIJSON(w, http.StatusTooManyRequests, map[string]any{"error": err.Error(), "quota": st})
+			return
+		case err != nil:
+			writeAPIError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		setQuotaHeaders(w, st)
+
+		rows := new(atomic.Int64)
+		cw := &countingWriter{ResponseWriter: w}
+		h(cw, r.WithContext(context.WithValue(r.Context(), quotaRowsKey{}, rows)))
+		if cw.status >= http.StatusBadRequest {
+			return
+		}
+		if _, err := s.quotas.Record(recipient, rows.Load(), cw.bytes, time.Now()); err != nil {
+			fmt.Printf("tdtpserve: quota: %v\n", err)
+		}
+	}
+}
+
+// handleAPIQuota serves GET /api/quota — состояние квоты вызывающего.
+func (s *Server) handleAPIQuota(w http.ResponseWriter, r *http.Request) {
+	if s.quotas == nil {
+		writeAPIError(w, http.StatusNotFound, "quotas are not configured")
+		return
+	}
+	st, err := s.quotas.Status(s.recipient(r), time.Now())
+	if errors.Is(err, quota.ErrUnknownRecipient) {
+		writeAPIError(w, http.StatusUnauthorized, "valid X-API-Key required")
+		return
+	}
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeAPIJSON(w, http.StatusOK, st)
+}
+
+// setQuotaHeaders пишет остаток квоты до запроса — меньший из часового и
+// суточного; ресурсы без лимита не пишутся.
+func setQuotaHeaders(w http.ResponseWriter, st quota.Status) {
+	w.Header().Set("X-Quota-Recipient", st.Recipient)
+	for _, res := range []struct {
+		name          string
+		hourly, daily int64
+		hUsed, dUsed  int64
+	}{
+		{"Requests", st.Hourly.Limits.Requests, st.Daily.Limits.Requests, st.Hourly.Used.Requests, st.Daily.Used.Requests},
+		{"Rows", st.Hourly.Limits.Rows, st.Daily.Limits.Rows, st.Hourly.Used.Rows, st.Daily.Used.Rows},
+		{"Bytes", st.Hourly.Limits.Bytes, st.Daily.Limits.Bytes, st.Hourly.Used.Bytes, st.Daily.Used.Bytes},
+	} {
+		remaining := int64(-1)
+		if res.hourly > 0 {
+			remaining = max(res.hourly-res.hUsed, 0)
+		}
+		if res.daily > 0 && (remaining < 0 || res.daily-res.dUsed < remaining) {
+			remaining = max(res.daily-res.dUsed, 0)
+		}
+		if remaining >= 0 {
+			w.Header().Set("X-Quota-Remaining-"+res.name, strconv.FormatInt(remaining, 10))
+		}
+	}
+}
+
+// retryAfter — секунд до сброса исчерпанного окна (часового, если исчерпан
+// он, иначе суточного).
+func retryAfter(st quota.Status) int {
+	reset := st.Daily.ResetAt
+	if st.Hourly.Exhausted() {
+		reset = st.Hourly.ResetAt
+	}
+	return max(int(time.Until(reset).Seconds()), 1)
+}
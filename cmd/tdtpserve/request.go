@@ -0,0 +1,292 @@
+package main
+
+// request.go — POST /query: обмен TDTP request/response по HTTP.
+//
+// Тело — request-пакет (XML или JSON, см. packet.Generator.ToJSON) с
+// Query в секции <Query>. Header.TableName — датасет сервера (источник или
+// вид): запрос выполняется в памяти над загруженными строками. Если
+// Header.Recipient — DB-источник, а таблица перечислена для него в
+// server.query_tables, запрос уходит в БД через ExportTableWithQuery на
+// адаптере из тёплого пула (pushdown фильтров, как у tdtpcli
+// --process-request), без предзагрузки.
+//
+// Ответ — response-пакеты (InReplyTo = MessageID запроса, QueryContext в
+// первой части) в формате запроса: JSON — NDJSON, пакет на строку; XML —
+// multipart/mixed, пакет на часть. Каждый пакет отправляется сразу, как
+// сериализован. Если MoreDataAvailable, последним идёт ссылка на следующую
+// страницу — готовый request-пакет (Offset = NextOffset, либо After =
+// NextAfter для keyset-запроса), который клиент отправляет на /query как есть.
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"slices"
+	"strings"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+	"github.com/ruslano69/tdtp-framework/pkg/core/tdtql"
+	"github.com/ruslano69/tdtp-framework/pkg/etl"
+)
+
+// maxRequestPacketSize — предел тела POST /query (request-пакет без данных).
+const maxRequestPacketSize = 1 << 20
+
+// handleQueryPacket serves POST /query.
+func (s *Server) handleQueryPacket(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeAPIError(w, http.StatusMethodNotAllowed, "POST required")
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxRequestPacketSize+1))
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "read request: "+err.Error())
+		return
+	}
+	if len(body) > maxRequestPacketSize {
+		writeAPIError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("request packet exceeds %d bytes", maxRequestPacketSize))
+		return
+	}
+
+	asJSON := isJSONRequest(r.Header.Get("Content-Type"), body)
+	var req *packet.DataPacket
+	if asJSON {
+		req, err = packet.NewParser().ParseJSON(body)
+	} else {
+		req, err = packet.NewParser().ParseBytes(body)
+	}
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid request packet: "+err.Error())
+		return
+	}
+	if req.Header.Type != packet.TypeRequest {
+		writeAPIError(w, http.StatusBadRequest, fmt.Sprintf("expected request packet, got: %s", req.Header.Type))
+		return
+	}
+	if req.Header.TableName == "" {
+		writeAPIError(w, http.StatusBadRequest, "request packet has no TableName")
+		return
+	}
+	if req.Query == nil {
+		req.Query = packet.NewQuery()
+	}
+
+	// ACL: датасет сервера или "<источник>.<таблица>" живого запроса;
+	// таблица JOIN живого запроса проверяется так же
+	resource := req.Header.TableName
+	src, live := s.querySource(req.Header.Recipient, req.Header.TableName)
+	if live {
+		resource = src.Name + "." + req.Header.TableName
+	}
+	if !s.authorize(w, r, permQuery, resource) {
+		return
+	}
+	if join := req.Query.Join; live && join != nil && !s.authorize(w, r, permQuery, src.Name+"."+join.Table) {
+		return
+	}
+
+	var packets []*packet.DataPacket
+	if live {
+		packets, err = s.queryAdapter(r.Context(), src, req)
+	} else {
+		packets, err = s.queryLoaded(req)
+	}
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, errDatasetNotFound) {
+			status = http.StatusNotFound
+		}
+		writeAPIError(w, status, err.Error())
+		return
+	}
+
+	rows := 0
+	for _, pkt := range packets {
+		pkt.Header.InReplyTo = req.Header.MessageID
+		pkt.Header.Sender, pkt.Header.Recipient = req.Header.Recipient, req.Header.Sender
+		rows += pkt.Header.RecordsInPart
+	}
+	noteRows(r, rows)
+
+	next, err := nextRequest(req, packets)
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	for _, pkt := range packets {
+		s.auditServed(r, permQuery, resource, pkt.Header.RecordsInPart, pkt.Header.MessageID)
+	}
+	if asJSON {
+		writePacketsNDJSON(w, packets, next)
+	} else {
+		writePacketsMultipart(w, packets, next)
+	}
+}
+
+// errDatasetNotFound — таблица запроса не датасет сервера и не таблица query_tables.
+var errDatasetNotFound = errors.New("dataset not found")
+
+// isJSONRequest — тело в формате JSON: по Content-Type, иначе по первому символу.
+func isJSONRequest(contentType string, body []byte) bool {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		switch {
+		case strings.HasSuffix(mediaType, "json"):
+			return true
+		case strings.HasSuffix(mediaType, "xml"):
+			return false
+		}
+	}
+	return bytes.HasPrefix(bytes.TrimSpace(body), []byte("{"))
+}
+
+// querySource — DB-источник recipient, если table открыта ему в
+// server.query_tables (имена таблиц без учёта регистра).
+func (s *Server) querySource(recipient, table string) (etl.SourceConfig, bool) {
+	if !s.queryTableOpen(recipient, table) {
+		return etl.SourceConfig{}, false
+	}
+	for _, src := range s.cfg.Sources {
+		if src.Name == recipient && isDBSource(src.Type) {
+			return src, true
+		}
+	}
+	return etl.SourceConfig{}, false
+}
+
+// queryTableOpen — table перечислена для источника в server.query_tables.
+func (s *Server) queryTableOpen(source, table string) bool {
+	tables, ok := s.cfg.Server.QueryTables[source]
+	return ok && slices.ContainsFunc(tables, func(t string) bool { return strings.EqualFold(t, table) })
+}
+
+// queryAdapter выполняет запрос в БД источника: адаптер из пула,
+// операция видна в /api/operations и отменяется вместе с запросом клиента.
+// Таблица JOIN тоже должна быть открыта в query_tables: иначе запрос к
+// открытой таблице читал бы любую таблицу источника.
+func (s *Server) queryAdapter(ctx context.Context, src etl.SourceConfig, req *packet.DataPacket) ([]*packet.DataPacket, error) {
+	if join := req.Query.Join; join != nil && !s.queryTableOpen(src.Name, join.Table) {
+		return nil, fmt.Errorf("%w: join table %s.%s", errDatasetNotFound, src.Name, join.Table)
+	}
+	ctx, op := s.startOperation(ctx, "query", src.Name+"."+req.Header.TableName)
+	defer op.End()
+
+	a, release, err := s.pool.Acquire(ctx, src)
+	if err != nil {
+		return nil, fmt.Errorf("source %q: %w", src.Name, err)
+	}
+	packets, err := a.ExportTableWithQuery(ctx, req.Header.TableName, req.Query, req.Header.Recipient, req.Header.Sender)
+	release(err)
+	if err != nil {
+		return nil, fmt.Errorf("query %s.%s: %w", src.Name, req.Header.TableName, err)
+	}
+	return packets, nil
+}
+
+// queryLoaded выполняет запрос над строками загруженного датасета.
+func (s *Server) queryLoaded(req *packet.DataPacket) ([]*packet.DataPacket, error) {
+	name := req.Header.TableName
+	s.mu.RLock()
+	ds, found := s.datasets[name]
+	s.mu.RUnlock()
+	if !found {
+		return nil, fmt.Errorf("%w: %s", errDatasetNotFound, name)
+	}
+	if req.Query.Join != nil {
+		return nil, fmt.Errorf("joins are not supported for loaded datasets: define a view instead")
+	}
+
+	result, err := tdtql.NewExecutor().Execute(req.Query, extractRows(ds.Packet), ds.Packet.Schema)
+	if err != nil {
+		return nil, err
+	}
+	return packet.NewGenerator().GenerateResponse(name, req.Header.MessageID, result.Schema, result.Rows,
+		result.QueryContext, req.Header.Recipient, req.Header.Sender)
+}
+
+// nextRequest — request-пакет следующей страницы, если ответ неполный.
+func nextRequest(req *packet.DataPacket, packets []*packet.DataPacket) (*packet.DataPacket, error) {
+	var results *packet.ExecutionResults
+	for _, pkt := range packets {
+		if pkt.QueryContext != nil {
+			results = &pkt.QueryContext.ExecutionResults
+			break
+		}
+	}
+	if results == nil || !results.MoreDataAvailable {
+		return nil, nil
+	}
+
+	query := *req.Query
+	if len(query.After) > 0 && len(results.NextAfter) > 0 {
+		query.After = results.NextAfter
+	} else {
+		query.Offset = results.NextOffset
+	}
+	return packet.NewGenerator().GenerateRequest(req.Header.TableName, &query, req.Header.Sender, req.Header.Recipient)
+}
+
+// writePacketsNDJSON пишет пакеты JSON-строками, сбрасывая каждую клиенту.
+func writePacketsNDJSON(w http.ResponseWriter, packets []*packet.DataPacket, next *packet.DataPacket) {
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	flusher, _ := w.(http.Flusher)
+	gen := packet.NewGenerator()
+	for _, pkt := range append(packets, next) {
+		if pkt == nil {
+			continue
+		}
+		data, err := gen.ToJSON(pkt)
+		if err != nil {
+			fmt.Printf("  ⚠ /query: %v\n", err)
+			return
+		}
+		_, _ = w.Write(append(data, '\n'))
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+}
+
+// writePacketsMultipart пишет пакеты частями multipart/mixed; часть со
+// ссылкой на следующую страницу помечена Content-Disposition: inline; name="next".
+func writePacketsMultipart(w http.ResponseWriter, packets []*packet.DataPacket, next *packet.DataPacket) {
+	mw := multipart.NewWriter(w)
+	w.Header().Set("Content-Type", "multipart/mixed; boundary="+mw.Boundary())
+	flusher, _ := w.(http.Flusher)
+	gen := packet.NewGenerator()
+
+	write := func(pkt *packet.DataPacket, disposition string) bool {
+		data, err := gen.ToXML(pkt, true)
+		if err != nil {
+			fmt.Printf("  ⚠ /query: %v\n", err)
+			return false
+		}
+		part, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":        {"application/xml; charset=utf-8"},
+			"Content-Disposition": {disposition},
+		})
+		if err != nil {
+			return false
+		}
+		_, _ = part.Write(data)
+		if flusher != nil {
+			flusher.Flush()
+		}
+		return true
+	}
+
+	for _, pkt := range packets {
+		if !write(pkt, fmt.Sprintf(`inline; name="part"; filename="%s.xml"`, pkt.Header.MessageID)) {
+			return
+		}
+	}
+	if next != nil && !write(next, `inline; name="next"`) {
+		return
+	}
+	_ = mw.Close()
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+	"github.com/ruslano69/tdtp-framework/pkg/etl"
+)
+
+func newRequestTestServer(t *testing.T) *Server {
+	t.Helper()
+	srv := newCursorTestServer(t)
+	srv.cfg = &ServeConfig{}
+	return srv
+}
+
+// queryPacket — request-пакет к Users: ID > 20, по 2 строки.
+func queryPacket(t *testing.T) *packet.DataPacket {
+	t.Helper()
+	q := packet.NewQuery()
+	q.Filters = &packet.Filters{And: &packet.LogicalGroup{Filters: []packet.Filter{{Field: "ID", Operator: "gt", Value: "20"}}}}
+	q.OrderBy = &packet.OrderBy{Fields: []packet.OrderField{{Name: "ID", Direction: "ASC"}}}
+	q.Limit = 2
+	req, err := packet.NewGenerator().GenerateRequest("Users", q, "client", "tdtpserve")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return req
+}
+
+// JSON-запрос: NDJSON из response-пакетов и request-пакета следующей
+// страницы; обход по ссылкам возвращает все 5 строк.
+func TestHandleQueryPacket_JSONPages(t *testing.T) {
+	srv := newRequestTestServer(t)
+	gen, parser := packet.NewGenerator(), packet.NewParser()
+
+	req := queryPacket(t)
+	var ids []string
+	for page := 0; req != nil && page < 10; page++ {
+		body, err := gen.ToJSON(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		httpReq := httptest.NewRequest(http.MethodPost, "/query", bytes.NewReader(body))
+		httpReq.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		srv.handleQueryPacket(rec, httpReq)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("page %d: status %d: %s", page, rec.Code, rec.Body.String())
+		}
+
+		var next *packet.DataPacket
+		sc := bufio.NewScanner(rec.Body)
+		for sc.Scan() {
+			pkt, err := parser.ParseJSON(sc.Bytes())
+			if err != nil {
+				t.Fatalf("page %d: %v", page, err)
+			}
+			switch pkt.Header.Type {
+			case packet.TypeResponse:
+				if pkt.Header.InReplyTo != req.Header.MessageID {
+					t.Errorf("InReplyTo = %q, want %q", pkt.Header.InReplyTo, req.Header.MessageID)
+				}
+				if pkt.Header.PartNumber == 1 && pkt.QueryContext == nil {
+					t.Error("first response part has no QueryContext")
+				}
+				for _, row := range pkt.GetRows() {
+					ids = append(ids, row[0])
+				}
+			case packet.TypeRequest:
+				next = pkt
+			}
+		}
+		req = next
+	}
+
+	want := []string{"21", "22", "23", "24", "25"}
+	if len(ids) != len(want) {
+		t.Fatalf("ids = %v, want %v", ids, want)
+	}
+	for i := range want {
+		if ids[i] != want[i] {
+			t.Fatalf("ids = %v, want %v", ids, want)
+		}
+	}
+}
+
+// XML-запрос: multipart/mixed, ссылка на следующую страницу — часть "next".
+func TestHandleQueryPacket_XMLMultipart(t *testing.T) {
+	srv := newRequestTestServer(t)
+	body, err := packet.NewGenerator().ToXML(queryPacket(t), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := httptest.NewRecorder()
+	srv.handleQueryPacket(rec, httptest.NewRequest(http.MethodPost, "/query", bytes.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body.String())
+	}
+
+	mediaType, params, err := mime.ParseMediaType(rec.Header().Get("Content-Type"))
+	if err != nil || mediaType != "multipart/mixed" {
+		t.Fatalf("Content-Type = %q", rec.Header().Get("Content-Type"))
+	}
+	mr := multipart.NewReader(rec.Body, params["boundary"])
+	var names []string
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		// FormName читает только form-data, части здесь inline
+		_, disp, _ := mime.ParseMediaType(part.Header.Get("Content-Disposition"))
+		name := disp["name"]
+		data, _ := io.ReadAll(part)
+		pkt, err := packet.NewParser().ParseBytes(data)
+		if err != nil {
+			t.Fatalf("part %q: %v", name, err)
+		}
+		names = append(names, name)
+		if name == "next" && pkt.Query.Offset != 2 {
+			t.Errorf("next offset = %d, want 2", pkt.Query.Offset)
+		}
+	}
+	if len(names) != 2 || names[0] != "part" || names[1] != "next" {
+		t.Errorf("parts = %v, want [part next]", names)
+	}
+}
+
+func TestHandleQueryPacket_Errors(t *testing.T) {
+	srv := newRequestTestServer(t)
+
+	unknown := queryPacket(t)
+	unknown.Header.TableName = "Missing"
+	body, _ := packet.NewGenerator().ToJSON(unknown)
+
+	tests := []struct {
+		name   string
+		method string
+		body   []byte
+		status int
+	}{
+		{"GET", http.MethodGet, nil, http.StatusMethodNotAllowed},
+		{"garbage", http.MethodPost, []byte("not a packet"), http.StatusBadRequest},
+		{"unknown dataset", http.MethodPost, body, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		srv.handleQueryPacket(rec, httptest.NewRequest(tt.method, "/query", bytes.NewReader(tt.body)))
+		if rec.Code != tt.status {
+			t.Errorf("%s: status %d, want %d: %s", tt.name, rec.Code, tt.status, rec.Body.String())
+		}
+	}
+}
+
+// liveJoinPacket — живой запрос к crm.Users с JOIN на table.
+func liveJoinPacket(t *testing.T, table string) []byte {
+	t.Helper()
+	req := queryPacket(t)
+	req.Header.Recipient = "crm"
+	req.Query.Join = &packet.Join{Table: table, On: []packet.JoinOn{{Left: "ID", Right: "UserID"}}}
+	body, err := packet.NewGenerator().ToJSON(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return body
+}
+
+// JOIN на таблицу вне query_tables отвергается до обращения к БД.
+func TestHandleQueryPacket_JoinOutsideQueryTables(t *testing.T) {
+	srv := newRequestTestServer(t)
+	srv.cfg.Sources = []etl.SourceConfig{{Name: "crm", Type: "sqlite", DSN: ":memory:"}}
+	srv.cfg.Server.QueryTables = map[string][]string{"crm": {"Users"}}
+
+	rec := httptest.NewRecorder()
+	srv.handleQueryPacket(rec, httptest.NewRequest(http.MethodPost, "/query", bytes.NewReader(liveJoinPacket(t, "Secrets"))))
+	if rec.Code != http.StatusNotFound || !strings.Contains(rec.Body.String(), "Secrets") {
+		t.Errorf("status %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+// Право query на основную таблицу не открывает таблицу JOIN.
+func TestHandleQueryPacket_JoinRequiresACL(t *testing.T) {
+	srv := newRequestTestServer(t)
+	srv.cfg.Sources = []etl.SourceConfig{{Name: "crm", Type: "sqlite", DSN: ":memory:"}}
+	srv.cfg.Server.QueryTables = map[string][]string{"crm": {"Users", "Secrets"}}
+	srv.cfg.Auth = &AuthConfig{APIKeys: map[string]string{"key-ana": "ana"}}
+	srv.cfg.ACL = []ACLRule{{Principal: "ana", Datasets: []string{"crm.Users"}, Permissions: []string{"query"}}}
+	srv.auth, _ = newAuthenticator(srv.cfg.Auth)
+	handler := srv.withAuth(http.HandlerFunc(srv.handleQueryPacket))
+
+	r := httptest.NewRequest(http.MethodPost, "/query", bytes.NewReader(liveJoinPacket(t, "Secrets")))
+	r.Header.Set("X-API-Key", "key-ana")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, r)
+	if rec.Code != http.StatusForbidden || !strings.Contains(rec.Body.String(), "crm.Secrets") {
+		t.Errorf("status %d: %s", rec.Code, rec.Body.String())
+	}
+}
@@ -11,9 +11,12 @@ import (
 	"sync"
 	"time"
 
+	"github.com/ruslano69/tdtp-framework/pkg/audit"
 	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
 	"github.com/ruslano69/tdtp-framework/pkg/core/tdtql"
 	"github.com/ruslano69/tdtp-framework/pkg/etl"
+	"github.com/ruslano69/tdtp-framework/pkg/operations"
+	"github.com/ruslano69/tdtp-framework/pkg/quota"
 )
 
 // ─────────────────────────────────────────────────────────────────────────────
@@ -32,7 +35,14 @@ type Dataset struct {
 // Server — HTTP сервер tdtpserve
 type Server struct {
 	cfg     *ServeConfig
-	lookups map[string]*Lookup // не под mu — каждое соединение открывается один раз и переживает refresh неизменным
+	lookups map[string]*Lookup   // не под mu — каждое соединение открывается один раз и переживает refresh неизменным
+	cursors *cursorCodec         // подпись continuation-токенов /api/query (см. cursor.go)
+	quotas  *quota.Tracker       // квоты получателей /api/* (nil — без квот, см. quota.go)
+	pool    *adapterPool         // тёплые адаптеры DB-источников для загрузки и refresh (см. pool.go)
+	ops     *operations.Registry // выполняющиеся операции хоста (nil — реестр недоступен, см. operations.go)
+	feeds   map[string]*feed     // ленты изменений для подписчиков /api/feed (см. feed.go)
+	auth    *authenticator       // API-ключи и JWT вызывающих (nil — сервер открыт, см. auth.go)
+	audit   audit.Logger         // журнал выданных пакетов (nil — без аудита, см. audit.go)
 
 	// mu guards datasets/order/lastRefresh: handleAPIRefresh replaces them
 	// wholesale on a successful reload, while every read handler
@@ -57,13 +67,14 @@ type Server struct {
 // Startup: load all sources and views
 // ─────────────────────────────────────────────────────────────────────────────
 
-// loadDatasets runs cfg.Sources through etl.Loader and cfg.Views through a
+// loadDatasets runs cfg.Sources through etl.Loader (DB sources borrow
+// adapters from pool) and cfg.Views through a
 // fresh SQLite :memory: workspace, returning the resulting Dataset map and
 // display order. Used by newServer (initial load) and handleAPIRefresh
 // (reload) — both build a complete, independent map before touching
 // anything the server is already serving, so a failed reload never
 // corrupts a working one.
-func loadDatasets(ctx context.Context, cfg *ServeConfig) (map[string]*Dataset, []string, error) {
+func loadDatasets(ctx context.Context, cfg *ServeConfig, pool *adapterPool) (map[string]*Dataset, []string, error) {
 	datasets := make(map[string]*Dataset)
 	var order []string
 
@@ -71,6 +82,9 @@ func loadDatasets(ctx context.Context, cfg *ServeConfig) (map[string]*Dataset, [
 
 	// 1. Load all sources via etl.Loader (handles tdtp files + DB adapters)
 	loader := etl.NewLoader(cfg.Sources, etl.ErrorHandlingConfig{OnSourceError: "fail"})
+	if pool != nil {
+		loader.SetAdapterPool(pool)
+	}
 	sourcesData, err := loader.LoadAll(ctx)
 	if err != nil {
 		return nil, nil, fmt.Errorf("loading sources: %w", err)
@@ -149,10 +163,50 @@ func loadDatasets(ctx context.Context, cfg *ServeConfig) (map[string]*Dataset, [
 }
 
 func newServer(ctx context.Context, cfg *ServeConfig) (*Server, error) {
-	srv := &Server{cfg: cfg, startedAt: time.Now()}
+	srv := &Server{cfg: cfg, startedAt: time.Now(), feeds: newFeeds(cfg)}
+
+	cursors, err := newCursorCodec(cfg.Server.CursorSecret, cfg.Server.CursorTTLSeconds)
+	if err != nil {
+		return nil, err
+	}
+	srv.cursors = cursors
+
+	if cfg.Quotas != nil {
+		srv.quotas, err = quota.Open(cfg.Quotas.Config)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	srv.ops = openOperations(cfg)
+
+	if cfg.Auth != nil {
+		srv.auth, err = newAuthenticator(cfg.Auth)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if cfg.Audit != nil {
+		srv.audit, err = newAuditLogger(cfg.Audit)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Warm pool before the first load: startup pays the connection cost once,
+	// later refreshes reuse these adapters (see pool.go)
+	srv.pool = newAdapterPool(cfg, etl.NewSourceAdapter)
+	if len(srv.pool.sources) > 0 {
+		fmt.Printf("tdtpserve: warming adapter pool for %d source(s)...\n", len(srv.pool.sources))
+		if err := srv.pool.warm(ctx); err != nil {
+			srv.pool.close(ctx)
+			return nil, err
+		}
+	}
 
-	datasets, order, err := loadDatasets(ctx, cfg)
+	datasets, order, err := loadDatasets(ctx, cfg, srv.pool)
 	if err != nil {
+		srv.pool.close(ctx)
 		return nil, err
 	}
 	srv.datasets = datasets
@@ -186,33 +240,66 @@ func runServer(cfg *ServeConfig) error {
 	if err != nil {
 		return err
 	}
+	srv.pool.start()
+	defer srv.pool.close(ctx)
+	stopFeeds := srv.startFeeds()
+	defer stopFeeds()
+	if srv.audit != nil {
+		defer srv.audit.Close() //nolint:errcheck
+	}
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("/", srv.handleIndex)
 	mux.HandleFunc("/data/", srv.handleData)
 
-	// JSON API — deliberately a separate prefix from the HTML routes above,
-	// so access control (auth, rate limiting) can be added to /api/* alone
-	// later without touching the browser-facing views. See api.go.
+	// JSON API — a separate prefix from the HTML routes above, so rate
+	// limiting applies to /api/* alone. See api.go.
 	mux.HandleFunc("/api/datasets", srv.handleAPIDatasets)
-	mux.HandleFunc("/api/data/", srv.handleAPIData)
+	mux.HandleFunc("/api/data/", srv.withQuota(srv.handleAPIData))
+	// Keyset-пагинация по continuation-токенам для больших датасетов. See cursor.go.
+	mux.HandleFunc("/api/query/", srv.withQuota(srv.handleAPIQuery))
 	// Lookups (live per-request queries, e.g. photo-by-code) — an even
 	// narrower surface than /api/data, worth locking down separately still.
 	// See lookup.go.
-	mux.HandleFunc("/api/lookup/", srv.handleAPILookup)
+	mux.HandleFunc("/api/lookup/", srv.withQuota(srv.handleAPILookup))
+	// TDTP request/response exchange: a request packet in, response packets
+	// with QueryContext and a next-page request out. See request.go.
+	mux.HandleFunc("/query", srv.withQuota(srv.handleQueryPacket))
+	// Change feeds: new/changed rows of a table pushed to subscribers over
+	// SSE or WebSocket as TDTP packets. See feed.go.
+	mux.HandleFunc("/api/feed/", srv.withQuota(srv.handleAPIFeed))
+	mux.HandleFunc("/api/feeds", srv.handleAPIFeeds)
+	// Per-recipient volume quotas on the export routes above. See quota.go.
+	mux.HandleFunc("/api/quota", srv.handleAPIQuota)
 	// Reload sources/views from the current config without a restart.
 	mux.HandleFunc("/api/refresh", srv.handleAPIRefresh)
+	// Warm adapter pool state per DB source. See pool.go.
+	mux.HandleFunc("/api/pool", srv.handleAPIPool)
+	// Build parameters and compiled-in adapters. See version.go.
+	mux.HandleFunc("/api/drivers", srv.handleAPIDrivers)
+	// In-flight operations of this host (tdtpcli runs, refreshes) and their
+	// cancellation. See operations.go.
+	mux.HandleFunc("/api/operations", srv.handleAPIOperations)
+	mux.HandleFunc("/api/operations/", srv.handleAPIOperationCancel)
 
 	addr := fmt.Sprintf(":%d", cfg.Server.Port)
 	fmt.Printf("\ntdtpserve ready → http://localhost%s\n", addr)
 	fmt.Printf("  %d source(s), %d view(s)\n", srv.sourceCount(), srv.viewCount())
+	for _, fc := range cfg.Feeds {
+		fmt.Printf("  [feed] %s ← %s.%s\n", fc.Name, fc.Source, fc.Table)
+	}
+
+	if cfg.Auth != nil {
+		fmt.Printf("  auth: %d API key(s), jwt: %t, %d ACL rule(s)\n", len(cfg.Auth.APIKeys), cfg.Auth.JWT != nil, len(cfg.ACL))
+	}
 
-	return http.ListenAndServe(addr, mux) //nolint:gosec // G114: timeout configured via server middleware
+	// Authentication and ACLs cover every route, HTML included. See auth.go.
+	return http.ListenAndServe(addr, srv.withAuth(mux)) //nolint:gosec // G114: timeout configured via server middleware
 }
 
 // sourceCount/viewCount read s.datasets without locking — callers already
-// holding s.mu (renderIndex) must keep doing so; callers before the server
-// starts serving requests (runServer's startup log) don't need to.
+// holding s.mu must keep doing so; callers before the server starts serving
+// requests (runServer's startup log) don't need to.
 func (s *Server) sourceCount() int {
 	n := 0
 	for _, d := range s.datasets {
@@ -245,7 +332,7 @@ func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	s.renderIndex(w)
+	s.renderIndex(w, r)
 }
 
 func (s *Server) handleData(w http.ResponseWriter, r *http.Request) {
@@ -256,12 +343,18 @@ func (s *Server) handleData(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !s.allows(r, permRead, name) {
+		s.auditDenied(r, permRead, name, fmt.Errorf("%s permission required", permRead))
+		http.Error(w, "forbidden: "+name, http.StatusForbidden)
+		return
+	}
 	res, ok := s.queryDataset(name, r.URL.Query())
 	if !ok {
 		http.Error(w, "dataset not found: "+name, http.StatusNotFound)
 		return
 	}
 
+	s.auditServed(r, permRead, name, len(res.Rows), "")
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	s.renderData(w, res.Dataset, res.Rows, res.Where, res.OrderBy, res.Limit, res.Offset, res.FilterErr)
 }
@@ -397,7 +490,8 @@ func parseWhere(where string) (*packet.Filters, error) {
 
 func parseSimpleFilter(cond string) (packet.Filter, error) {
 	cond = strings.TrimSpace(cond)
-	ops := []string{">=", "<=", "!=", "=", ">", "<", " LIKE ", " IN ", " BETWEEN ", " IS NOT NULL", " IS NULL"}
+	ops := []string{" NOT REGEXP ", " REGEXP ", " STARTS WITH ", " ENDS WITH ", " LAST ",
+		">=", "<=", "!=", "=", ">", "<", " LIKE ", " IN ", " BETWEEN ", " IS NOT NULL", " IS NULL"}
 
 	for _, op := range ops {
 		idx := strings.Index(strings.ToUpper(cond), op)
@@ -425,6 +519,8 @@ func parseSimpleFilter(cond string) (packet.Filter, error) {
 			">=": "gte", "<=": "lte", "LIKE": "like",
 			"IN": "in", "BETWEEN": "between",
 			"IS NULL": "is_null", "IS NOT NULL": "is_not_null",
+			"REGEXP": "regex", "NOT REGEXP": "not_regex",
+			"STARTS WITH": "starts_with", "ENDS WITH": "ends_with", "LAST": "last",
 		}[strings.TrimSpace(op)]
 		if tdtpOp == "" {
 			tdtpOp = strings.ToLower(strings.TrimSpace(op))
@@ -470,8 +566,8 @@ func parseOrderBy(orderBy string) (*packet.OrderBy, error) {
 // ─────────────────────────────────────────────────────────────────────────────
 
 // renderIndex reads s.datasets/s.order — caller (handleIndex) must hold
-// s.mu for reading.
-func (s *Server) renderIndex(w http.ResponseWriter) {
+// s.mu for reading. Lists only the datasets r's principal may read.
+func (s *Server) renderIndex(w http.ResponseWriter, r *http.Request) {
 	var b strings.Builder
 
 	b.WriteString(`<!DOCTYPE html>
@@ -523,18 +619,14 @@ func (s *Server) renderIndex(w http.ResponseWriter) {
 	// Navbar
 	writeNavbar(&b, s.cfg.Server.Name, "")
 
-	// Stats row
-	b.WriteString(`<div class="meta-grid" style="margin-bottom:24px;">`)
-	writeMetaItem(&b, "Sources", strconv.Itoa(s.sourceCount()))
-	writeMetaItem(&b, "Views", strconv.Itoa(s.viewCount()))
-	writeMetaItem(&b, "Started", s.startedAt.Format("2006-01-02 15:04:05"))
-	b.WriteString(`</div>`)
-
-	// Sources
+	// Sources — only those the caller may read (see auth.go)
 	sources := make([]*Dataset, 0)
 	views := make([]*Dataset, 0)
 	for _, name := range s.order {
 		d := s.datasets[name]
+		if !s.allows(r, permRead, name) {
+			continue
+		}
 		if d.IsView {
 			views = append(views, d)
 		} else {
@@ -542,6 +634,13 @@ func (s *Server) renderIndex(w http.ResponseWriter) {
 		}
 	}
 
+	// Stats row
+	b.WriteString(`<div class="meta-grid" style="margin-bottom:24px;">`)
+	writeMetaItem(&b, "Sources", strconv.Itoa(len(sources)))
+	writeMetaItem(&b, "Views", strconv.Itoa(len(views)))
+	writeMetaItem(&b, "Started", s.startedAt.Format("2006-01-02 15:04:05"))
+	b.WriteString(`</div>`)
+
 	if len(sources) > 0 {
 		b.WriteString(`<div class="section-title">Sources</div><div class="grid">`)
 		for _, d := range sources {
@@ -0,0 +1,44 @@
+package main
+
+// version.go — состав сборки: --version и GET /api/drivers.
+//
+// Набор адаптеров задаётся build-тегами drivers_*.go (no<adapter>, minimal),
+// поэтому бинарник сообщает, что в него вкомпилировано, — конфиг с
+// источником, чей адаптер исключён, отвергается ещё в loadConfig.
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+	"github.com/ruslano69/tdtp-framework/pkg/core/version"
+)
+
+// apiDriversResponse is the JSON shape for GET /api/drivers.
+type apiDriversResponse struct {
+	Build   adapters.BuildInfo    `json:"build"`
+	Drivers []adapters.DriverInfo `json:"drivers"`
+}
+
+// handleAPIDrivers serves GET /api/drivers: build parameters and the
+// adapters compiled into this binary.
+func (s *Server) handleAPIDrivers(w http.ResponseWriter, _ *http.Request) {
+	writeAPIJSON(w, http.StatusOK, apiDriversResponse{Build: adapters.Build(), Drivers: adapters.Available()})
+}
+
+// printVersion prints the build and the adapters compiled into it.
+func printVersion(w io.Writer) {
+	build := adapters.Build()
+	fmt.Fprintf(w, "tdtpserve version %s\n", version.Version)
+	fmt.Fprintf(w, "Build:    %s %s/%s, cgo=%t", build.GoVersion, build.GOOS, build.GOARCH, build.CGO)
+	if len(build.Tags) > 0 {
+		fmt.Fprintf(w, ", tags=%s", strings.Join(build.Tags, ","))
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Adapters:")
+	for _, d := range adapters.Available() {
+		fmt.Fprintf(w, "  %s\n", d)
+	}
+}
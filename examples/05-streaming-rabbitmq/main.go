@@ -9,6 +9,7 @@ import (
 
 	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
 	"github.com/ruslano69/tdtp-framework/pkg/etl"
+	"github.com/ruslano69/tdtp-framework/pkg/fake"
 )
 
 // Этот пример демонстрирует:
@@ -202,7 +203,11 @@ func createWorkspaceWithData(ctx context.Context) (*etl.Workspace, error) {
 
 	// Генерируем тестовые данные (10000 строк для демонстрации streaming)
 	schema := packet.Schema{Fields: fields}
-	rows := generateTestData(10000)
+	rows, err := generateTestData(schema, 10000)
+	if err != nil {
+		workspace.Close(ctx)
+		return nil, err
+	}
 
 	// Создаем пакет и загружаем данные
 	dataPacket := packet.NewDataPacket(packet.TypeReference, "users")
@@ -218,15 +223,16 @@ func createWorkspaceWithData(ctx context.Context) (*etl.Workspace, error) {
 }
 
 // generateTestData генерирует тестовые данные
-func generateTestData(count int) [][]string {
-	rows := make([][]string, count)
-	for i := 0; i < count; i++ {
-		rows[i] = []string{
-			fmt.Sprintf("%d", i+1),                 // id
-			fmt.Sprintf("User %d", i+1),            // name
-			fmt.Sprintf("user%d@example.com", i+1), // email
-			fmt.Sprintf("%d", 20+(i%50)),           // age
-		}
-	}
-	return rows
+func generateTestData(schema packet.Schema, count int) ([][]string, error) {
+	gen, err := fake.New(schema, fake.Config{
+		Seed: 1,
+		Hints: map[string]fake.Hint{
+			"id":  {Kind: fake.KindSequence},
+			"age": {Min: "20", Max: "69"},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return gen.Rows(count), nil
 }
@@ -0,0 +1,31 @@
+// Пример внешнего адаптера-плагина (pkg/adapters/plugin).
+//
+// Адаптер внутренней СУБД компании живёт в своём репозитории и собирается
+// в отдельный исполняемый файл; tdtpcli и tdtpserve подхватывают его без
+// пересборки. Здесь роль «внутреннего» адаптера играет SQLite под типом
+// acme:
+//
+//	go build -o /opt/tdtp/plugins/acme ./examples/adapters/plugin
+//	tdtpcli --plugins /opt/tdtp/plugins --version        # acme (modernc.org/sqlite, plugin /opt/tdtp/plugins/acme)
+//	TDTP_PLUGIN_DIR=/opt/tdtp/plugins tdtpcli --list     # config.yaml: database.type: acme
+//
+// Stdout плагина занят протоколом: журнал — только в stderr.
+package main
+
+import (
+	"log"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+	"github.com/ruslano69/tdtp-framework/pkg/adapters/plugin"
+	"github.com/ruslano69/tdtp-framework/pkg/adapters/sqlite"
+)
+
+func main() {
+	info := plugin.Info{Type: "acme", Driver: "modernc.org/sqlite"}
+	err := plugin.Serve(info, func() adapters.Adapter {
+		return &sqlite.Adapter{}
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/ruslano69/tdtp-framework/pkg/adapters"
 	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
 	"github.com/ruslano69/tdtp-framework/pkg/core/schema"
+	"github.com/ruslano69/tdtp-framework/pkg/fake"
 
 	_ "github.com/ruslano69/tdtp-framework/pkg/adapters/sqlite"
 )
@@ -275,28 +276,35 @@ func createDemoDatabase(ctx context.Context, dbPath string) error {
 	const recordCount = 10000
 	batchSize := 1000
 
-	fmt.Printf("   Generating %d records...\n", recordCount)
-
-	for batch := 0; batch < recordCount/batchSize; batch++ {
-		rows := make([]packet.Row, batchSize)
+	gen, err := fake.New(schemaObj, fake.Config{
+		Seed: 1,
+		Hints: map[string]fake.Hint{
+			"age":     {Min: "18", Max: "67"},
+			"balance": {Min: "0", Max: "1000000", Distribution: fake.DistZipf},
+		},
+	})
+	if err != nil {
+		return err
+	}
 
-		for i := 0; i < batchSize; i++ {
-			id := batch*batchSize + i + 1
-			rows[i] = packet.Row{
-				Value: fmt.Sprintf("%d|User_%d|user_%d@example.com|Address line %d, Building %d|City_%d|Country_%d|%d|%.2f|This is a longer description field to increase row size. Record ID: %d. Lorem ipsum dolor sit amet, consectetur adipiscing elit. Sed do eiusmod tempor incididunt ut labore et dolore magna aliqua.",
-					id, id, id, id, id%100, id%50, id%200, 18+(id%50), float64(id)*123.45, id),
-			}
-		}
+	fmt.Printf("   Generating %d records...\n", recordCount)
 
+	batch := 0
+	err = gen.Each(recordCount, batchSize, func(rows [][]string) error {
 		testPacket := packet.NewDataPacket(packet.TypeReference, "demo_users")
 		testPacket.Schema = schemaObj
-		testPacket.Data = packet.Data{Rows: rows}
+		testPacket.Data = packet.RowsToData(rows)
 
 		if err := adapter.ImportPacket(ctx, testPacket, adapters.StrategyReplace); err != nil {
 			return fmt.Errorf("failed to import batch %d: %w", batch, err)
 		}
+		batch++
 
-		fmt.Printf("   Progress: %d/%d records\r", (batch+1)*batchSize, recordCount)
+		fmt.Printf("   Progress: %d/%d records\r", batch*batchSize, recordCount)
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
 	fmt.Printf("\n   ✅ Created demo table 'demo_users' with %d records\n\n", recordCount)
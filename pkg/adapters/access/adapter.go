@@ -32,6 +32,7 @@ func init() {
 	adapters.Register("access", func() adapters.Adapter {
 		return &Adapter{}
 	})
+	adapters.Describe(adapters.DriverInfo{Type: "access", Driver: "github.com/alexbrainman/odbc"})
 }
 
 // Adapter implements adapters.Adapter for Microsoft Access via ODBC.
@@ -43,6 +44,8 @@ type Adapter struct {
 	exportHelper *base.ExportHelper
 	converter    *base.UniversalTypeConverter
 	decoder      *encoding.Decoder // non-nil when charset conversion needed (e.g. windows-1251)
+
+	queryLog *adapters.QueryLogger // SQL statement log (Config.QueryLog)
 }
 
 // resolveDecoder returns a charmap decoder for the given charset name, or nil for UTF-8/empty.
@@ -88,7 +91,8 @@ func (a *Adapter) Connect(ctx context.Context, cfg adapters.Config) error {
 		return fmt.Errorf("access: DSN (connection string) is required")
 	}
 
-	db, err := sql.Open("odbc", dsn)
+	a.queryLog = adapters.NewQueryLogger(cfg.QueryLog)
+	db, err := base.OpenDB("odbc", dsn, a.queryLog)
 	if err != nil {
 		return fmt.Errorf("access: failed to open: %w", err)
 	}
@@ -103,11 +107,39 @@ func (a *Adapter) Connect(ctx context.Context, cfg adapters.Config) error {
 
 	// Init converter and export helper
 	a.converter = base.NewUniversalTypeConverter()
+	if cfg.SourceTimezone != "" {
+		if err := a.converter.SetSourceTimezone(cfg.SourceTimezone); err != nil {
+			_ = db.Close()
+			return err
+		}
+	}
+	if err := a.converter.SetBoolMappings(cfg.Booleans, cfg.ColumnBooleans); err != nil {
+		_ = db.Close()
+		return err
+	}
 	a.exportHelper = base.NewExportHelper(a, a, a.converter, nil)
+	if err := a.exportHelper.SetPacketSizing(cfg.Packets); err != nil {
+		_ = db.Close()
+		return err
+	}
 
 	return nil
 }
 
+// TimezoneReport реализует adapters.TimezoneReporter: отчёт о переводе
+// naive datetime из Config.SourceTimezone в UTC (nil — пояс не задан).
+func (a *Adapter) TimezoneReport() *adapters.TimezoneReport {
+	if a.converter == nil {
+		return nil
+	}
+	return a.converter.TimezoneReport()
+}
+
+// QueryLog implements adapters.QueryLogged.
+func (a *Adapter) QueryLog() *adapters.QueryLogger {
+	return a.queryLog
+}
+
 func (a *Adapter) Close(ctx context.Context) error {
 	if a.db != nil {
 		return a.db.Close()
@@ -222,6 +254,12 @@ func (a *Adapter) ExportTableWithQuery(ctx context.Context, tableName string, qu
 	return a.exportHelper.ExportTableWithQuery(ctx, tableName, query, sender, recipient)
 }
 
+// ExplainQuery returns the plan of ExportTableWithQuery without reading rows
+// (adapters.QueryExplainer).
+func (a *Adapter) ExplainQuery(ctx context.Context, tableName string, query *packet.Query) (*packet.ExecutionPlan, error) {
+	return a.exportHelper.Explain(ctx, tableName, query)
+}
+
 // ExportTableIncremental is not implemented for Access.
 func (a *Adapter) ExportTableIncremental(ctx context.Context, tableName string, cfg adapters.IncrementalConfig) ([]*packet.DataPacket, string, error) {
 	return nil, "", fmt.Errorf("access: incremental export not supported")
@@ -73,7 +73,7 @@ func (a *Adapter) InspectTable(ctx context.Context, tableName string) (*adapters
 			return nil, fmt.Errorf("access: failed to get columns: %w", err)
 		}
 		if len(colOrder) == 0 {
-			return nil, fmt.Errorf("access: table %q not found or has no columns", tableName)
+			return nil, fmt.Errorf("access: table %q %w", tableName, adapters.ErrTableNotFound)
 		}
 
 		vals := make([]any, len(colOrder))
@@ -99,7 +99,7 @@ func (a *Adapter) InspectTable(ctx context.Context, tableName string) (*adapters
 	}
 
 	if len(report.Columns) == 0 {
-		return nil, fmt.Errorf("access: table %q not found or has no columns", tableName)
+		return nil, fmt.Errorf("access: table %q %w", tableName, adapters.ErrTableNotFound)
 	}
 
 	// ---- Row count ----
@@ -0,0 +1,109 @@
+package adapters
+
+import (
+	"runtime"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// DriverInfo описывает адаптер, вкомпилированный в бинарник или
+// загруженный плагином.
+type DriverInfo struct {
+	Type     string   `json:"type"`               // тип адаптера (Config.Type)
+	Driver   string   `json:"driver,omitempty"`   // Go-модуль драйвера БД
+	CGO      bool     `json:"cgo"`                // драйвер требует cgo (сборка не статическая)
+	Features []string `json:"features,omitempty"` // дополнительные возможности, например "sqlcipher"
+	Plugin   string   `json:"plugin,omitempty"`   // путь к исполняемому файлу внешнего адаптера (pkg/adapters/plugin)
+	Isolated bool     `json:"isolated,omitempty"` // экземпляры работают в дочерних процессах (plugin.Isolate)
+}
+
+var (
+	driversMu sync.RWMutex
+	drivers   = map[string]DriverInfo{}
+)
+
+// Describe сохраняет сведения о драйвере адаптера для Available.
+// Вызывается в init() адаптера рядом с Register; повторный вызов для
+// того же типа заменяет описание (например, при подключении SQLCipher).
+func Describe(info DriverInfo) {
+	driversMu.Lock()
+	defer driversMu.Unlock()
+	drivers[info.Type] = info
+}
+
+// Available возвращает адаптеры, вкомпилированные в бинарник (набор
+// зависит от build-тегов сборки) и загруженные плагинами, отсортированные
+// по типу. Адаптер, зарегистрированный без Describe, описан только типом.
+func Available() []DriverInfo {
+	driversMu.RLock()
+	defer driversMu.RUnlock()
+
+	types := GetRegisteredTypes()
+	slices.Sort(types)
+	result := make([]DriverInfo, 0, len(types))
+	for _, t := range types {
+		info, ok := drivers[t]
+		if !ok {
+			info = DriverInfo{Type: t}
+		}
+		info.Features = slices.Clone(info.Features)
+		result = append(result, info)
+	}
+	return result
+}
+
+// BuildInfo — параметры сборки бинарника, от которых зависит набор драйверов.
+type BuildInfo struct {
+	GoVersion string   `json:"go_version"`
+	GOOS      string   `json:"goos"`
+	GOARCH    string   `json:"goarch"`
+	CGO       bool     `json:"cgo"`
+	Tags      []string `json:"tags,omitempty"`
+}
+
+// Build читает параметры сборки из runtime/debug.ReadBuildInfo.
+// В тестах и при сборке без модульной информации CGO и Tags пусты.
+func Build() BuildInfo {
+	info := BuildInfo{GoVersion: runtime.Version(), GOOS: runtime.GOOS, GOARCH: runtime.GOARCH}
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "CGO_ENABLED":
+			info.CGO = s.Value == "1"
+		case "-tags":
+			for _, tag := range strings.Split(s.Value, ",") {
+				if tag = strings.TrimSpace(tag); tag != "" {
+					info.Tags = append(info.Tags, tag)
+				}
+			}
+		}
+	}
+	return info
+}
+
+// String — строка отчёта --version: "postgres (github.com/jackc/pgx/v5)".
+func (d DriverInfo) String() string {
+	var extras []string
+	if d.Driver != "" {
+		extras = append(extras, d.Driver)
+	}
+	extras = append(extras, d.Features...)
+	if d.CGO {
+		extras = append(extras, "cgo")
+	}
+	if d.Plugin != "" {
+		extras = append(extras, "plugin "+d.Plugin)
+	}
+	if d.Isolated {
+		extras = append(extras, "isolated")
+	}
+	if len(extras) == 0 {
+		return d.Type
+	}
+	return d.Type + " (" + strings.Join(extras, ", ") + ")"
+}
@@ -0,0 +1,45 @@
+package adapters
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// TestAvailable проверяет отчёт о вкомпилированных адаптерах: сортировку,
+// описание по Describe и адаптер без описания.
+func TestAvailable(t *testing.T) {
+	for _, typ := range []string{"zz-described", "zz-bare"} {
+		Register(typ, func() Adapter { return nil })
+		t.Cleanup(func() { Unregister(typ) })
+	}
+	Describe(DriverInfo{Type: "zz-described", Driver: "example.com/driver", CGO: true, Features: []string{"sqlcipher"}})
+	t.Cleanup(func() {
+		driversMu.Lock()
+		delete(drivers, "zz-described")
+		driversMu.Unlock()
+	})
+
+	got := Available()
+	if !slices.IsSortedFunc(got, func(a, b DriverInfo) int { return strings.Compare(a.Type, b.Type) }) {
+		t.Errorf("Available() is not sorted by type: %v", got)
+	}
+
+	byType := map[string]DriverInfo{}
+	for _, d := range got {
+		byType[d.Type] = d
+	}
+	if d := byType["zz-described"]; d.String() != "zz-described (example.com/driver, sqlcipher, cgo)" {
+		t.Errorf("described = %q", d.String())
+	}
+	if d, ok := byType["zz-bare"]; !ok || d.String() != "zz-bare" {
+		t.Errorf("bare = %q (present %v)", d.String(), ok)
+	}
+
+	Unregister("zz-bare")
+	for _, d := range Available() {
+		if d.Type == "zz-bare" {
+			t.Error("unregistered adapter is still reported")
+		}
+	}
+}
@@ -0,0 +1,261 @@
+package base
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+	tdtpcrypto "github.com/ruslano69/tdtp-framework/pkg/crypto"
+)
+
+// ColumnCipher шифрует выбранные колонки перед вставкой и расшифровывает
+// их при экспорте (encryption at rest для PII). Реализует
+// adapters.ColumnCipher: адаптер с Config.ColumnCipher вызывает его при
+// открытии импортируемых пакетов (PrepareImport) и в SealPackets.
+//
+// В целевой БД колонка хранит enc:v1:<key_id>:... как TEXT; key_id и
+// исходный тип переносятся в схеме пакета (Field.Encryption). При экспорте
+// значения расшифровываются, только если у потребителя есть ключ: без
+// ключа они уходят как есть, а схема помечается Field.Encryption —
+// авторизованный получатель расшифрует их позже. Методы nil-безопасны.
+type ColumnCipher struct {
+	columns map[string]string // "table.column" или "column" (в нижнем регистре) → key_id
+	keys    tdtpcrypto.KeyProvider
+}
+
+// ColumnEncryptionConfig — секция column_encryption конфигурации
+// (tdtpcli, источники ETL и tdtpserve):
+//
+//	column_encryption:
+//	  keyring: /etc/tdtp/keyring        # строки "key_id = key"
+//	  keys:
+//	    pii-2025: env:TDTP_PII_KEY       # base64 / hex / env:NAME
+//	  columns:
+//	    customers.ssn: pii-2025          # "table.column" или "column"
+//	    email: pii-2025
+type ColumnEncryptionConfig struct {
+	Keyring string            `yaml:"keyring,omitempty"`
+	Keys    map[string]string `yaml:"keys,omitempty"`
+	Columns map[string]string `yaml:"columns,omitempty"`
+}
+
+// Cipher собирает шифратор колонок для adapters.Config.ColumnCipher
+// (nil — шифрование не настроено).
+func (c ColumnEncryptionConfig) Cipher() (adapters.ColumnCipher, error) {
+	if len(c.Columns) == 0 {
+		return nil, nil
+	}
+	keys := make(tdtpcrypto.StaticKeys)
+	if c.Keyring != "" {
+		ring, err := tdtpcrypto.LoadKeyring(c.Keyring)
+		if err != nil {
+			return nil, err
+		}
+		maps.Copy(keys, ring)
+	}
+	for id, value := range c.Keys {
+		key, err := tdtpcrypto.ParseKey(value)
+		if err != nil {
+			return nil, fmt.Errorf("column_encryption key %s: %w", id, err)
+		}
+		keys[id] = key
+	}
+	return NewColumnCipher(c.Columns, keys), nil
+}
+
+// NewColumnCipher создаёт шифратор колонок. columns — "table.column" или
+// "column" (для всех таблиц) → key_id. Наличие ключей не проверяется: при
+// экспорте отсутствие ключа — штатный случай (потребитель не авторизован),
+// а при импорте EncryptPacket вернёт ошибку до записи в БД.
+func NewColumnCipher(columns map[string]string, keys tdtpcrypto.KeyProvider) *ColumnCipher {
+	c := &ColumnCipher{columns: make(map[string]string, len(columns)), keys: keys}
+	for name, keyID := range columns {
+		c.columns[strings.ToLower(name)] = keyID
+	}
+	return c
+}
+
+// keyFor возвращает key_id колонки таблицы ("" — колонка не шифруется).
+func (c *ColumnCipher) keyFor(table, column string) string {
+	if keyID, ok := c.columns[strings.ToLower(table+"."+column)]; ok {
+		return keyID
+	}
+	return c.columns[strings.ToLower(column)]
+}
+
+// EncryptPacket шифрует назначенные колонки пакета перед импортом и
+// возвращает число зашифрованных значений. Поле становится TEXT, исходный
+// тип с длиной, точностью и подтипом сохраняется в Field.Encryption.
+//
+// Повторно не шифруются только значения, которые действительно зашифрованы:
+// тег GCM сходится с ключом из keyring (повторный импорт того же пакета)
+// либо ключа нет, но отправитель пометил поле Field.Encryption (пакет
+// экспортирован без ключа). Текст, лишь похожий на шифртекст, шифруется.
+func (c *ColumnCipher) EncryptPacket(pkt *packet.DataPacket) (int, error) {
+	if c == nil {
+		return 0, nil
+	}
+	cols, err := c.packetColumns(pkt)
+	if err != nil || len(cols) == 0 {
+		return 0, err
+	}
+	marked := make(map[int]bool, len(cols))
+	for _, i := range cols {
+		f := &pkt.Schema.Fields[i]
+		if f.Key {
+			// Шифртекст случаен (nonce): ключ перестал бы находить строку.
+			return 0, fmt.Errorf("key field %s cannot be encrypted", f.Name)
+		}
+		marked[i] = f.Encryption != nil
+		if f.Encryption == nil {
+			f.Encryption = &packet.FieldEncryption{
+				KeyID:     c.keyFor(pkt.Header.TableName, f.Name),
+				Algorithm: tdtpcrypto.ColumnAlgorithm,
+				Type:      f.Type,
+				Length:    f.Length,
+				Precision: f.Precision,
+				Scale:     f.Scale,
+				Subtype:   f.Subtype,
+			}
+		}
+		f.Type, f.Length, f.Precision, f.Scale, f.Subtype = "TEXT", 0, 0, 0, ""
+	}
+
+	n := 0
+	err = c.rewriteRows(pkt, cols, func(i int, value string) (string, error) {
+		if tdtpcrypto.IsEncryptedValue(value) {
+			_, _, err := tdtpcrypto.DecryptColumnValue(c.keys, value)
+			switch {
+			case err == nil:
+				return value, nil
+			case marked[i] && errors.Is(err, tdtpcrypto.ErrKeyNotFound):
+				return value, nil
+			case marked[i]:
+				return "", fmt.Errorf("field %s: %w", pkt.Schema.Fields[i].Name, err)
+			}
+		}
+		enc, err := tdtpcrypto.EncryptColumnValue(c.keys, pkt.Schema.Fields[i].Encryption.KeyID, value)
+		if err != nil {
+			return "", err
+		}
+		n++
+		return enc, nil
+	})
+	return n, err
+}
+
+// DecryptPacket расшифровывает назначенные колонки и колонки с
+// Field.Encryption. Значения, ключей которых нет, остаются зашифрованными;
+// их число возвращается вторым результатом.
+func (c *ColumnCipher) DecryptPacket(pkt *packet.DataPacket) (decrypted, locked int, err error) {
+	if c == nil {
+		return 0, 0, nil
+	}
+	cols, err := c.packetColumns(pkt)
+	if err != nil {
+		return 0, 0, err
+	}
+	for i, f := range pkt.Schema.Fields {
+		if f.Encryption != nil && c.keyFor(pkt.Header.TableName, f.Name) == "" {
+			cols = append(cols, i)
+		}
+	}
+	if len(cols) == 0 {
+		return 0, 0, nil
+	}
+
+	lockedKeys := make(map[int]string)
+	err = c.rewriteRows(pkt, cols, func(i int, value string) (string, error) {
+		if !tdtpcrypto.IsEncryptedValue(value) {
+			return value, nil
+		}
+		plain, keyID, err := tdtpcrypto.DecryptColumnValue(c.keys, value)
+		if err != nil {
+			if errors.Is(err, tdtpcrypto.ErrKeyNotFound) {
+				lockedKeys[i] = keyID
+				locked++
+				return value, nil
+			}
+			return "", fmt.Errorf("field %s: %w", pkt.Schema.Fields[i].Name, err)
+		}
+		decrypted++
+		return plain, nil
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for _, i := range cols {
+		f := &pkt.Schema.Fields[i]
+		if keyID, ok := lockedKeys[i]; ok {
+			if f.Encryption == nil {
+				f.Encryption = &packet.FieldEncryption{KeyID: keyID, Algorithm: tdtpcrypto.ColumnAlgorithm}
+			}
+			continue
+		}
+		if e := f.Encryption; e != nil {
+			if e.Type != "" {
+				f.Type, f.Length, f.Precision, f.Scale, f.Subtype = e.Type, e.Length, e.Precision, e.Scale, e.Subtype
+			}
+			f.Encryption = nil
+		}
+	}
+	return decrypted, locked, nil
+}
+
+// packetColumns возвращает индексы назначенных колонок пакета.
+func (c *ColumnCipher) packetColumns(pkt *packet.DataPacket) ([]int, error) {
+	var cols []int
+	for i, f := range pkt.Schema.Fields {
+		if c.keyFor(pkt.Header.TableName, f.Name) != "" {
+			cols = append(cols, i)
+		}
+	}
+	if len(cols) > 0 {
+		if pkt.Data.Compression != "" {
+			return nil, fmt.Errorf("packet must be decompressed before column encryption")
+		}
+		if pkt.Data.Delta {
+			return nil, fmt.Errorf("column encryption is not supported for delta packets")
+		}
+	}
+	return cols, nil
+}
+
+// rewriteRows применяет fn к непустым значениям колонок cols (NULL-маркер
+// и пустая строка не шифруются).
+func (c *ColumnCipher) rewriteRows(pkt *packet.DataPacket, cols []int, fn func(i int, value string) (string, error)) error {
+	pkt.MaterializeRows()
+	nullMarkers := make(map[int]string, len(cols))
+	for _, i := range cols {
+		if sv := pkt.Schema.Fields[i].SpecialValues; sv != nil && sv.Null != nil {
+			nullMarkers[i] = sv.Null.Marker
+		}
+	}
+
+	parser := packet.NewParser()
+	for r, row := range pkt.Data.Rows {
+		values := parser.GetRowValues(row)
+		changed := false
+		for _, i := range cols {
+			if i >= len(values) || values[i] == "" || values[i] == nullMarkers[i] {
+				continue
+			}
+			v, err := fn(i, values[i])
+			if err != nil {
+				return fmt.Errorf("row %d: %w", r, err)
+			}
+			if v != values[i] {
+				values[i] = v
+				changed = true
+			}
+		}
+		if changed {
+			pkt.Data.Rows[r] = packet.Row{Value: packet.JoinRowEscaped(values)}
+		}
+	}
+	return nil
+}
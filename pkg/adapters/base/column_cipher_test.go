@@ -0,0 +1,138 @@
+package base
+
+import (
+	"encoding/base64"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+	tdtpcrypto "github.com/ruslano69/tdtp-framework/pkg/crypto"
+)
+
+func testCipherPacket(t *testing.T) *packet.DataPacket {
+	t.Helper()
+	schema := packet.Schema{Fields: []packet.Field{
+		{Name: "id", Type: "INTEGER", Key: true},
+		{Name: "ssn", Type: "VARCHAR", Length: 11},
+		{Name: "salary", Type: "DECIMAL", Precision: 10, Scale: 2},
+	}}
+	packets, err := packet.NewGenerator().GenerateReference("people", schema, [][]string{
+		{"1", "123-45-6789", "1000.50"},
+		{"2", "", "2000.00"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return packets[0]
+}
+
+func TestColumnCipher_RoundTrip(t *testing.T) {
+	keys := tdtpcrypto.StaticKeys{"pii": make([]byte, 32)}
+	c := NewColumnCipher(map[string]string{"ssn": "pii", "people.salary": "pii"}, keys)
+
+	pkt := testCipherPacket(t)
+	n, err := c.EncryptPacket(pkt)
+	if err != nil {
+		t.Fatalf("EncryptPacket: %v", err)
+	}
+	if n != 3 { // пустой ssn второй строки не шифруется
+		t.Errorf("encrypted %d values, want 3", n)
+	}
+	ssn := pkt.Schema.Fields[1]
+	if ssn.Type != "TEXT" || ssn.Encryption == nil || ssn.Encryption.KeyID != "pii" || ssn.Encryption.Type != "VARCHAR" {
+		t.Errorf("unexpected field after encryption: %+v", ssn)
+	}
+	values := packet.NewParser().GetRowValues(pkt.Data.Rows[0])
+	if values[0] != "1" || !strings.HasPrefix(values[1], "enc:v1:pii:") {
+		t.Errorf("row = %v", values)
+	}
+
+	// Повторное шифрование не меняет уже зашифрованные значения.
+	if n, _ := c.EncryptPacket(pkt); n != 0 {
+		t.Errorf("re-encrypted %d values", n)
+	}
+
+	// Без ключа повторно не шифруются только помеченные отправителем поля.
+	foreign := NewColumnCipher(map[string]string{"ssn": "other"}, tdtpcrypto.StaticKeys{"other": make([]byte, 32)})
+	marked := *pkt
+	marked.Schema.Fields = slices.Clone(pkt.Schema.Fields)
+	if n, err := foreign.EncryptPacket(&marked); err != nil || n != 0 {
+		t.Errorf("marked packet: encrypted %d values, err %v", n, err)
+	}
+
+	// Атрибуты исходного типа переживают пакет в XML
+	data, err := packet.NewGenerator().ToXML(pkt, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pkt, err = packet.NewParser().ParseBytes(data); err != nil {
+		t.Fatal(err)
+	}
+
+	// Потребитель без ключа: значения остаются зашифрованными, схема помечена.
+	locked := NewColumnCipher(nil, tdtpcrypto.StaticKeys{})
+	if dec, lk, err := locked.DecryptPacket(pkt); err != nil || dec != 0 || lk != 3 {
+		t.Fatalf("DecryptPacket without key = %d, %d, %v", dec, lk, err)
+	}
+
+	dec, lk, err := c.DecryptPacket(pkt)
+	if err != nil || dec != 3 || lk != 0 {
+		t.Fatalf("DecryptPacket = %d, %d, %v", dec, lk, err)
+	}
+	values = packet.NewParser().GetRowValues(pkt.Data.Rows[0])
+	if values[1] != "123-45-6789" || values[2] != "1000.50" {
+		t.Errorf("decrypted row = %v", values)
+	}
+	if f := pkt.Schema.Fields[2]; f.Encryption != nil || f.Type != "DECIMAL" || f.Precision != 10 || f.Scale != 2 {
+		t.Errorf("field not restored: %+v", f)
+	}
+	if f := pkt.Schema.Fields[1]; f.Type != "VARCHAR" || f.Length != 11 {
+		t.Errorf("field not restored: %+v", f)
+	}
+}
+
+// Значение в формате шифртекста, тег которого не сходится, шифруется как
+// обычный текст; в поле, помеченном Field.Encryption, — ошибка.
+func TestColumnCipher_LookAlikePlaintext(t *testing.T) {
+	keys := tdtpcrypto.StaticKeys{"pii": make([]byte, 32)}
+	c := NewColumnCipher(map[string]string{"ssn": "pii"}, keys)
+	forged := "enc:v1:pii:" + base64.StdEncoding.EncodeToString(make([]byte, 40))
+
+	pkt := testCipherPacket(t)
+	pkt.MaterializeRows()
+	pkt.Data.Rows[0] = packet.Row{Value: "1|" + forged + "|1000.50"}
+	if n, err := c.EncryptPacket(pkt); err != nil || n != 1 {
+		t.Fatalf("EncryptPacket = %d, %v", n, err)
+	}
+	if _, _, err := c.DecryptPacket(pkt); err != nil {
+		t.Fatal(err)
+	}
+	if v := packet.NewParser().GetRowValues(pkt.Data.Rows[0])[1]; v != forged {
+		t.Errorf("round trip = %q", v)
+	}
+
+	pkt = testCipherPacket(t)
+	pkt.MaterializeRows()
+	pkt.Schema.Fields[1].Encryption = &packet.FieldEncryption{KeyID: "pii", Algorithm: tdtpcrypto.ColumnAlgorithm}
+	pkt.Data.Rows[0] = packet.Row{Value: "1|" + forged + "|1000.50"}
+	if _, err := c.EncryptPacket(pkt); err == nil {
+		t.Error("expected authentication error for a marked field")
+	}
+
+	var none *ColumnCipher
+	if n, err := none.EncryptPacket(pkt); n != 0 || err != nil {
+		t.Errorf("nil cipher = %d, %v", n, err)
+	}
+}
+
+func TestColumnCipher_Errors(t *testing.T) {
+	missing := NewColumnCipher(map[string]string{"ssn": "missing"}, tdtpcrypto.StaticKeys{})
+	if _, err := missing.EncryptPacket(testCipherPacket(t)); err == nil {
+		t.Error("expected error for unknown key id")
+	}
+	c := NewColumnCipher(map[string]string{"id": "pii"}, tdtpcrypto.StaticKeys{"pii": make([]byte, 32)})
+	if _, err := c.EncryptPacket(testCipherPacket(t)); err == nil {
+		t.Error("expected error for key field")
+	}
+}
@@ -0,0 +1,228 @@
+package base
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+// TableColumnSource — целевая БД, по колонкам которой ColumnMatcher
+// сопоставляет поля пакетов.
+type TableColumnSource interface {
+	TableExists(ctx context.Context, tableName string) (bool, error)
+	SchemaReader
+}
+
+// ColumnMatcher сопоставляет поля пакетов с колонками их таблиц
+// (MatchColumns). Схемы существующих таблиц кэшируются: импорт пакет за
+// пакетом (broker, listen) не читает схему на каждый пакет. При
+// расхождении схема перечитывается один раз — колонку могли добавить
+// после кэширования (sync.EnsureColumns, ALTER TABLE). Таблицу, которую
+// импорт заменяет, вызывающий код сбрасывает из кэша (Forget).
+type ColumnMatcher struct {
+	src  TableColumnSource
+	mode adapters.ColumnMatching
+
+	mu     sync.Mutex
+	tables map[string]packet.Schema
+}
+
+// NewColumnMatcher создаёт сопоставление полей пакетов с колонками src.
+func NewColumnMatcher(src TableColumnSource, mode adapters.ColumnMatching) *ColumnMatcher {
+	return &ColumnMatcher{src: src, mode: mode, tables: make(map[string]packet.Schema)}
+}
+
+// Mode возвращает режим сопоставления (nil — ColumnsByName).
+func (m *ColumnMatcher) Mode() adapters.ColumnMatching {
+	if m == nil {
+		return adapters.ColumnsByName
+	}
+	return m.mode
+}
+
+// Forget сбрасывает кэшированные схемы таблиц.
+func (m *ColumnMatcher) Forget(tables ...string) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, name := range tables {
+		delete(m.tables, name)
+	}
+}
+
+// Match возвращает пакеты, приведённые к колонкам их таблиц; пакеты
+// вызывающего кода не меняются. Пакеты новых таблиц, delta-пакеты и пакеты
+// удаления возвращаются как есть; nil-матчер возвращает packets. Не
+// вызывается для StrategyCopy через временную таблицу — та создаётся по
+// схеме пакета.
+func (m *ColumnMatcher) Match(ctx context.Context, packets []*packet.DataPacket) ([]*packet.DataPacket, error) {
+	if m == nil {
+		return packets, nil
+	}
+	matched := make([]*packet.DataPacket, len(packets))
+	for i, pkt := range packets {
+		matched[i] = pkt
+		if pkt == nil || pkt.Data.Delta || pkt.Data.Delete {
+			continue
+		}
+		name := pkt.Header.TableName
+		table, cached, err := m.schema(ctx, name, false)
+		if err != nil {
+			return nil, err
+		}
+		if table == nil {
+			continue
+		}
+		out, err := MatchColumns(pkt, *table, m.mode)
+		if cached && errors.Is(err, adapters.ErrColumnMismatch) {
+			if table, _, err = m.schema(ctx, name, true); err != nil {
+				return nil, err
+			}
+			if table == nil {
+				continue
+			}
+			out, err = MatchColumns(pkt, *table, m.mode)
+		}
+		if err != nil {
+			return nil, err
+		}
+		matched[i] = out
+	}
+	return matched, nil
+}
+
+// schema возвращает схему таблицы name из кэша или из БД (reload — всегда
+// из БД); nil — таблицы нет. Отсутствие таблицы не кэшируется: её создаст
+// импорт. cached сообщает, что схема взята из кэша.
+func (m *ColumnMatcher) schema(ctx context.Context, name string, reload bool) (table *packet.Schema, cached bool, err error) {
+	m.mu.Lock()
+	s, ok := m.tables[name]
+	m.mu.Unlock()
+	if ok && !reload {
+		return &s, true, nil
+	}
+	exists, err := m.src.TableExists(ctx, name)
+	if err != nil {
+		return nil, false, err
+	}
+	if !exists {
+		m.Forget(name)
+		return nil, false, nil
+	}
+	s, err = m.src.GetTableSchema(ctx, name)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read schema of %s: %w", name, err)
+	}
+	m.mu.Lock()
+	m.tables[name] = s
+	m.mu.Unlock()
+	return &s, false, nil
+}
+
+// MatchPacketColumns — ColumnMatcher.Match без кэша между вызовами: схема
+// каждой таблицы читается один раз за вызов.
+func MatchPacketColumns(ctx context.Context, src TableColumnSource, packets []*packet.DataPacket, mode adapters.ColumnMatching) ([]*packet.DataPacket, error) {
+	return NewColumnMatcher(src, mode).Match(ctx, packets)
+}
+
+// MatchColumns возвращает копию пакета, приведённую к колонкам таблицы
+// table: имена полей заменяются именами колонок (сравнение без учёта
+// регистра). Колонки таблицы без поля в пакете в INSERT не попадают — СУБД
+// заполняет их DEFAULT или NULL. Поле, которого нет в таблице, — ошибка
+// *adapters.ColumnMismatchError; ColumnsSkipExtra убирает такие поля из
+// схемы и строк с предупреждением (ключевое поле — всегда ошибка),
+// ColumnsStrict — ошибка и при колонке таблицы без поля в пакете.
+// pkt не меняется.
+func MatchColumns(pkt *packet.DataPacket, table packet.Schema, mode adapters.ColumnMatching) (*packet.DataPacket, error) {
+	used := make([]bool, len(table.Fields))
+	fields := make([]packet.Field, 0, len(pkt.Schema.Fields))
+	keep := make([]int, 0, len(pkt.Schema.Fields))
+	var extra []string
+	keyMissing := false
+	for i, f := range pkt.Schema.Fields {
+		c := columnIndex(table, f.Name)
+		if c < 0 || used[c] {
+			extra = append(extra, f.Name)
+			keyMissing = keyMissing || f.Key
+			continue
+		}
+		used[c] = true
+		f.Name = table.Fields[c].Name
+		fields = append(fields, f)
+		keep = append(keep, i)
+	}
+	var missing []string
+	for c, col := range table.Fields {
+		if !used[c] {
+			missing = append(missing, col.Name)
+		}
+	}
+
+	mismatch := &adapters.ColumnMismatchError{
+		Table:     pkt.Header.TableName,
+		MessageID: pkt.Header.MessageID,
+		Extra:     extra,
+	}
+	if mode == adapters.ColumnsStrict && (len(extra) > 0 || len(missing) > 0) {
+		mismatch.Missing = missing
+		return nil, mismatch
+	}
+	if len(extra) > 0 && (mode != adapters.ColumnsSkipExtra || keyMissing) || len(fields) == 0 {
+		return nil, mismatch
+	}
+
+	out := *pkt
+	out.Schema.Fields = fields
+	if len(extra) == 0 {
+		return &out, nil
+	}
+	log.Printf("WARNING: %s: packet columns not in the table are skipped: %s",
+		pkt.Header.TableName, strings.Join(extra, ", "))
+
+	// Строки проецируются в новый срез: out делит Data.Rows с pkt
+	out.Schema.Fields = pkt.Schema.Fields
+	out.MaterializeRows()
+	if err := packet.ExpandCompactRows(&out); err != nil {
+		return nil, err
+	}
+	rows := make([]packet.Row, len(out.Data.Rows))
+	for r, row := range out.Data.Rows {
+		values := ParseRowValues(row)
+		if len(values) != len(pkt.Schema.Fields) {
+			return nil, fmt.Errorf("%s: packet %s: row %d: expected %d values, got %d",
+				pkt.Header.TableName, pkt.Header.MessageID, r+1, len(pkt.Schema.Fields), len(values))
+		}
+		projected := make([]string, len(keep))
+		for j, i := range keep {
+			projected[j] = values[i]
+		}
+		rows[r] = packet.Row{Value: packet.JoinRowEscaped(projected)}
+	}
+	out.Data.Rows = rows
+	out.Schema.Fields = fields
+	out.Header.Checksum = nil // строки сверены в OpenPacket, сумма больше не про них
+	return &out, nil
+}
+
+// columnIndex — позиция колонки name в table: сначала точное совпадение,
+// затем без учёта регистра; -1, если колонки нет.
+func columnIndex(table packet.Schema, name string) int {
+	folded := -1
+	for i, col := range table.Fields {
+		if col.Name == name {
+			return i
+		}
+		if folded < 0 && strings.EqualFold(col.Name, name) {
+			folded = i
+		}
+	}
+	return folded
+}
@@ -0,0 +1,159 @@
+package base
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+func columnsPacket(t *testing.T) *packet.DataPacket {
+	t.Helper()
+	schema := packet.Schema{Fields: []packet.Field{
+		{Name: "ID", Type: "INTEGER", Key: true},
+		{Name: "legacy", Type: "TEXT"},
+		{Name: "Name", Type: "TEXT"},
+	}}
+	packets, err := packet.NewGenerator().GenerateReference("users", schema, [][]string{
+		{"1", "x", "a|b"}, {"2", "y", "c"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return packets[0]
+}
+
+var columnsTable = packet.Schema{Fields: []packet.Field{
+	{Name: "name", Type: "TEXT"},
+	{Name: "created", Type: "TIMESTAMP"},
+	{Name: "id", Type: "INTEGER", Key: true},
+}}
+
+func TestMatchColumns_SkipExtra(t *testing.T) {
+	pkt := columnsPacket(t)
+	out, err := MatchColumns(pkt, columnsTable, adapters.ColumnsSkipExtra)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, f := range out.Schema.Fields {
+		names = append(names, f.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"id", "name"}) || !out.Schema.Fields[0].Key {
+		t.Errorf("fields %v", out.Schema.Fields)
+	}
+	if got := out.GetRows(); !reflect.DeepEqual(got, [][]string{{"1", "a|b"}, {"2", "c"}}) {
+		t.Errorf("rows %v", got)
+	}
+	if out.Header.Checksum != nil {
+		t.Error("checksum kept after projection")
+	}
+
+	// Пакет вызывающего кода не меняется
+	if len(pkt.Schema.Fields) != 3 || pkt.Schema.Fields[0].Name != "ID" {
+		t.Errorf("caller's schema changed: %v", pkt.Schema.Fields)
+	}
+	if got := pkt.GetRows(); !reflect.DeepEqual(got, [][]string{{"1", "x", "a|b"}, {"2", "y", "c"}}) {
+		t.Errorf("caller's rows changed: %v", got)
+	}
+}
+
+func TestMatchColumns_ByName(t *testing.T) {
+	// Лишнее поле по умолчанию — ошибка
+	pkt := columnsPacket(t)
+	_, err := MatchColumns(pkt, columnsTable, adapters.ColumnsByName)
+	var mismatch *adapters.ColumnMismatchError
+	if !errors.As(err, &mismatch) || !reflect.DeepEqual(mismatch.Extra, []string{"legacy"}) || mismatch.Missing != nil {
+		t.Fatalf("got %v", err)
+	}
+
+	// Без лишних полей — только имена колонок
+	pkt.Schema.Fields = []packet.Field{{Name: "ID", Type: "INTEGER", Key: true}, {Name: "NAME", Type: "TEXT"}}
+	out, err := MatchColumns(pkt, columnsTable, adapters.ColumnsByName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Schema.Fields[0].Name != "id" || out.Schema.Fields[1].Name != "name" || pkt.Schema.Fields[1].Name != "NAME" {
+		t.Errorf("fields %v, caller's %v", out.Schema.Fields, pkt.Schema.Fields)
+	}
+}
+
+func TestMatchColumns_Mismatch(t *testing.T) {
+	pkt := columnsPacket(t)
+	_, err := MatchColumns(pkt, columnsTable, adapters.ColumnsStrict)
+	var mismatch *adapters.ColumnMismatchError
+	if !errors.As(err, &mismatch) || !errors.Is(err, adapters.ErrColumnMismatch) {
+		t.Fatalf("got %v", err)
+	}
+	if !reflect.DeepEqual(mismatch.Extra, []string{"legacy"}) || !reflect.DeepEqual(mismatch.Missing, []string{"created"}) {
+		t.Errorf("extra %v, missing %v", mismatch.Extra, mismatch.Missing)
+	}
+	if len(pkt.Schema.Fields) != 3 {
+		t.Errorf("strict mismatch changed the packet: %v", pkt.Schema.Fields)
+	}
+
+	// Ключевое поле без колонки не пропускается и в skip-extra
+	pkt = columnsPacket(t)
+	pkt.Schema.Fields[1].Key = true
+	if _, err := MatchColumns(pkt, columnsTable, adapters.ColumnsSkipExtra); !errors.Is(err, adapters.ErrColumnMismatch) {
+		t.Errorf("missing key column: %v", err)
+	}
+}
+
+func TestMatchPacketColumns_NewTable(t *testing.T) {
+	pkt := columnsPacket(t)
+	src := &mockDryRunTarget{}
+	matched, err := MatchPacketColumns(context.Background(), src, []*packet.DataPacket{pkt, nil}, adapters.ColumnsStrict)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if matched[0] != pkt || matched[1] != nil {
+		t.Errorf("packet for a new table changed: %v", matched)
+	}
+}
+
+// countingColumnSource считает чтения схемы.
+type countingColumnSource struct {
+	mockDryRunTarget
+	reads int
+}
+
+func (c *countingColumnSource) GetTableSchema(ctx context.Context, name string) (packet.Schema, error) {
+	c.reads++
+	return c.mockDryRunTarget.GetTableSchema(ctx, name)
+}
+
+func TestColumnMatcher_CachesSchema(t *testing.T) {
+	ctx := context.Background()
+	src := &countingColumnSource{mockDryRunTarget: mockDryRunTarget{schema: columnsTable}}
+	m := NewColumnMatcher(src, adapters.ColumnsByName)
+	pkt := columnsPacket(t)
+	pkt.Schema.Fields = pkt.Schema.Fields[:1]
+
+	for range 3 {
+		if _, err := m.Match(ctx, []*packet.DataPacket{pkt}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if src.reads != 1 {
+		t.Errorf("schema read %d times, want 1", src.reads)
+	}
+
+	// Колонку добавили после кэширования — схема перечитывается
+	src.schema.Fields = append(slices.Clone(columnsTable.Fields), packet.Field{Name: "legacy", Type: "TEXT"})
+	if _, err := m.Match(ctx, []*packet.DataPacket{columnsPacket(t)}); err != nil {
+		t.Fatalf("added column not picked up: %v", err)
+	}
+	if src.reads != 2 {
+		t.Errorf("schema read %d times, want 2", src.reads)
+	}
+
+	m.Forget("users")
+	if _, err := m.Match(ctx, []*packet.DataPacket{pkt}); err != nil || src.reads != 3 {
+		t.Errorf("Forget: reads %d, err %v", src.reads, err)
+	}
+}
@@ -0,0 +1,117 @@
+package base
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+// DeleteApplier — адаптер, умеющий применять пакеты удаления
+// (Data delete="true"): DELETE по первичному ключу.
+type DeleteApplier interface {
+	// ApplyDelete применяет все пакеты в одной транзакции и возвращает
+	// число удалённых строк.
+	ApplyDelete(ctx context.Context, packets []*packet.DataPacket) (int, error)
+}
+
+// SplitDeletePackets делит пачку на пакеты с данными и пакеты удаления.
+// Инкрементальная выгрузка с переносом удалений (sync.DeleteDetection)
+// даёт смешанную пачку: импорт применяет сначала данные, затем удаления —
+// строка, изменённая и удалённая в одном окне, в приёмнике не остаётся.
+// Это две транзакции, но повтор пачки безопасен: UPSERT и DELETE по ключу
+// идемпотентны.
+func SplitDeletePackets(packets []*packet.DataPacket) (data, deletes []*packet.DataPacket) {
+	for _, pkt := range packets {
+		if pkt != nil && pkt.Data.Delete {
+			deletes = append(deletes, pkt)
+		} else {
+			data = append(data, pkt)
+		}
+	}
+	return data, deletes
+}
+
+// ApplyDeletePacket удаляет из quotedTable строки с ключами пакета.
+// Отсутствующие строки не ошибка: удаление идемпотентно. Возвращает число
+// удалённых строк. dialect.ChangedRowsOnly не влияет — для DELETE
+// RowsAffected точен во всех СУБД.
+func ApplyDeletePacket(
+	ctx context.Context,
+	quotedTable string,
+	pkt *packet.DataPacket,
+	dialect DeltaDialect,
+	exec DeltaExecFunc,
+) (int, error) {
+	keys, err := pkt.DeleteKeys()
+	if err != nil {
+		return 0, err
+	}
+	query := BuildDeleteSQL(quotedTable, pkt.Schema.Fields, dialect)
+
+	deleted := 0
+	for i, key := range keys {
+		args := make([]any, len(key))
+		for j, v := range key {
+			if args[j], err = dialect.Convert(v, pkt.Schema.Fields[j]); err != nil {
+				return deleted, fmt.Errorf("delete key %d: field %s: %w", i, pkt.Schema.Fields[j].Name, err)
+			}
+		}
+		n, err := exec(ctx, query, args)
+		if err != nil {
+			return deleted, fmt.Errorf("delete key %d (%s): %w", i, strings.Join(key, ", "), err)
+		}
+		deleted += int(n)
+	}
+	return deleted, nil
+}
+
+// ApplyDeletePacketsSQL применяет пакеты удаления через database/sql в одной
+// транзакции. quoteTable строит экранированное имя таблицы пакета.
+func ApplyDeletePacketsSQL(
+	ctx context.Context,
+	db *sql.DB,
+	packets []*packet.DataPacket,
+	quoteTable func(tableName string) string,
+	dialect DeltaDialect,
+) (int, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	exec := func(ctx context.Context, query string, args []any) (int64, error) {
+		res, err := tx.ExecContext(ctx, query, args...)
+		if err != nil {
+			return 0, err
+		}
+		return res.RowsAffected()
+	}
+
+	total := 0
+	for i, pkt := range packets {
+		n, err := ApplyDeletePacket(ctx, quoteTable(pkt.Header.TableName), pkt, dialect, exec)
+		if err != nil {
+			return 0, fmt.Errorf("failed to apply delete packet %d: %w", i, err)
+		}
+		total += n
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	fmt.Printf("✅ Delete applied: %d row(s) removed\n", total)
+	return total, nil
+}
+
+// BuildDeleteSQL строит DELETE FROM t WHERE k1=? AND …
+func BuildDeleteSQL(quotedTable string, keys []packet.Field, dialect DeltaDialect) string {
+	conds := make([]string, len(keys))
+	for i, f := range keys {
+		conds[i] = dialect.Quote(f.Name) + " = " + dialect.Placeholder(i+1)
+	}
+	return fmt.Sprintf("DELETE FROM %s WHERE %s", quotedTable, strings.Join(conds, " AND "))
+}
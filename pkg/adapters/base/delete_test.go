@@ -0,0 +1,61 @@
+package base
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+func TestApplyDeletePacket(t *testing.T) {
+	schema := packet.Schema{Fields: []packet.Field{
+		{Name: "id", Type: "INTEGER", Key: true},
+		{Name: "region", Type: "TEXT", Key: true},
+		{Name: "email", Type: "TEXT"},
+	}}
+	pkt, err := packet.NewDeletePacket("customers", schema, [][]string{{"1", "EU"}, {"2", "US"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var calls []deltaCall
+	exec := func(_ context.Context, query string, args []any) (int64, error) {
+		calls = append(calls, deltaCall{query, args})
+		if args[0] == "2" {
+			return 0, nil // строки уже нет — не ошибка
+		}
+		return 1, nil
+	}
+	n, err := ApplyDeletePacket(context.Background(), `"customers"`, pkt, testDeltaDialect(), exec)
+	if err != nil {
+		t.Fatalf("ApplyDeletePacket: %v", err)
+	}
+	if n != 1 || len(calls) != 2 {
+		t.Fatalf("deleted = %d, calls = %d", n, len(calls))
+	}
+	if want := `DELETE FROM "customers" WHERE "id" = $1 AND "region" = $2`; calls[0].query != want {
+		t.Errorf("query = %q, want %q", calls[0].query, want)
+	}
+	if fmt.Sprint(calls[1].args) != "[2 US]" {
+		t.Errorf("args = %v", calls[1].args)
+	}
+}
+
+func TestSplitDeletePackets(t *testing.T) {
+	del, _ := packet.NewDeletePacket("t", packet.Schema{Fields: []packet.Field{{Name: "id", Key: true}}}, [][]string{{"1"}})
+	full := packet.NewDataPacket(packet.TypeReference, "t")
+
+	data, deletes := SplitDeletePackets([]*packet.DataPacket{del, del})
+	if len(data) != 0 || len(deletes) != 2 {
+		t.Errorf("delete batch: %d data, %d delete", len(data), len(deletes))
+	}
+	data, deletes = SplitDeletePackets([]*packet.DataPacket{full})
+	if len(data) != 1 || len(deletes) != 0 {
+		t.Errorf("data batch: %d data, %d delete", len(data), len(deletes))
+	}
+	data, deletes = SplitDeletePackets([]*packet.DataPacket{del, full})
+	if len(data) != 1 || data[0] != full || len(deletes) != 1 || deletes[0] != del {
+		t.Errorf("mixed batch: %v, %v", data, deletes)
+	}
+}
@@ -0,0 +1,189 @@
+package base
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+// DeltaApplier — адаптер, умеющий применять delta-пакеты (Data delta="true")
+// точечными UPDATE по ключу. ImportHelper проверяет его на dataInserter:
+// delta-пакет нельзя вставить через InsertRows — строки содержат только ключ
+// и изменённые поля.
+type DeltaApplier interface {
+	// ApplyDelta применяет все пакеты в одной транзакции.
+	ApplyDelta(ctx context.Context, packets []*packet.DataPacket, strategy adapters.ImportStrategy) error
+}
+
+// DeltaDialect описывает SQL-диалект для построения UPDATE.
+type DeltaDialect struct {
+	Quote       func(name string) string // экранирование идентификатора
+	Placeholder func(n int) string       // плейсхолдер n-го аргумента (с 1)
+
+	// Convert переводит значение TDTP в SQL-аргумент (с учётом SpecialValues).
+	Convert func(value string, field packet.Field) (any, error)
+
+	// ChangedRowsOnly — RowsAffected считает только фактически изменённые
+	// строки (MySQL без clientFoundRows): 0 не означает, что строки нет,
+	// поэтому проверка «строка не найдена» отключается.
+	ChangedRowsOnly bool
+}
+
+// DeltaExecFunc выполняет UPDATE и возвращает число затронутых строк.
+type DeltaExecFunc func(ctx context.Context, query string, args []any) (int64, error)
+
+// SQLValueConverter — DeltaDialect.Convert на основе ConvertRowToSQLValues.
+func SQLValueConverter(converter *UniversalTypeConverter, dbType string) func(string, packet.Field) (any, error) {
+	return func(value string, field packet.Field) (any, error) {
+		args, err := ConvertRowToSQLValues([]string{value}, packet.Schema{Fields: []packet.Field{field}}, converter, dbType)
+		if err != nil {
+			return nil, err
+		}
+		return args[0], nil
+	}
+}
+
+// IsDeltaBatch проверяет, что пачка пакетов — только delta или только
+// полные пакеты: смешанные пачки не применяются атомарно одним способом.
+func IsDeltaBatch(packets []*packet.DataPacket) (bool, error) {
+	delta := 0
+	for _, pkt := range packets {
+		if pkt != nil && pkt.Data.Delta {
+			delta++
+		}
+	}
+	if delta > 0 && delta != len(packets) {
+		return false, fmt.Errorf("cannot import delta and full packets in one batch (%d of %d are delta)", delta, len(packets))
+	}
+	return delta > 0, nil
+}
+
+// ApplyDeltaPacket применяет delta-пакет к таблице quotedTable: для каждой
+// строки — UPDATE изменённых полей по ключу. Строки, которых нет в таблице,
+// при StrategyFail — ошибка, иначе пропускаются с предупреждением.
+// Возвращает число обновлённых строк.
+func ApplyDeltaPacket(
+	ctx context.Context,
+	quotedTable string,
+	pkt *packet.DataPacket,
+	strategy adapters.ImportStrategy,
+	dialect DeltaDialect,
+	exec DeltaExecFunc,
+) (int, error) {
+	rows, err := pkt.DeltaRows()
+	if err != nil {
+		return 0, err
+	}
+
+	fields := make(map[string]packet.Field, len(pkt.Schema.Fields))
+	for _, f := range pkt.Schema.Fields {
+		fields[strings.ToLower(f.Name)] = f
+	}
+	keys := packet.ExtractKeyFields(pkt.Schema)
+	keyFields := make([]packet.Field, len(keys))
+	for i, k := range keys {
+		keyFields[i] = fields[strings.ToLower(k)]
+	}
+
+	updated, missing := 0, 0
+	for i, r := range rows {
+		changed := make([]packet.Field, len(r.Changes))
+		values := make([]string, 0, len(r.Changes)+len(r.Key))
+		for j, c := range r.Changes {
+			changed[j] = fields[strings.ToLower(c.Field)]
+			values = append(values, c.New)
+		}
+		values = append(values, r.Key...)
+		argFields := append(changed[:len(changed):len(changed)], keyFields...)
+
+		query := BuildDeltaUpdateSQL(quotedTable, changed, keyFields, dialect)
+		args := make([]any, len(values))
+		for j, v := range values {
+			if args[j], err = dialect.Convert(v, argFields[j]); err != nil {
+				return updated, fmt.Errorf("delta row %d: field %s: %w", i, argFields[j].Name, err)
+			}
+		}
+
+		n, err := exec(ctx, query, args)
+		if err != nil {
+			return updated, fmt.Errorf("delta row %d (key %s): %w", i, strings.Join(r.Key, ", "), err)
+		}
+		if n == 0 && !dialect.ChangedRowsOnly {
+			if strategy == adapters.StrategyFail {
+				return updated, fmt.Errorf("delta row %d: no row with key %s in %s", i, strings.Join(r.Key, ", "), pkt.Header.TableName)
+			}
+			missing++
+			continue
+		}
+		updated++
+	}
+
+	if missing > 0 {
+		fmt.Printf("⚠️  Delta %s: %d row(s) not found by key, skipped\n", pkt.Header.TableName, missing)
+	}
+	return updated, nil
+}
+
+// ApplyDeltaPacketsSQL применяет delta-пакеты через database/sql в одной
+// транзакции. quoteTable строит экранированное имя таблицы пакета.
+func ApplyDeltaPacketsSQL(
+	ctx context.Context,
+	db *sql.DB,
+	packets []*packet.DataPacket,
+	strategy adapters.ImportStrategy,
+	quoteTable func(tableName string) string,
+	dialect DeltaDialect,
+) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	exec := func(ctx context.Context, query string, args []any) (int64, error) {
+		res, err := tx.ExecContext(ctx, query, args...)
+		if err != nil {
+			return 0, err
+		}
+		return res.RowsAffected()
+	}
+
+	total := 0
+	for i, pkt := range packets {
+		n, err := ApplyDeltaPacket(ctx, quoteTable(pkt.Header.TableName), pkt, strategy, dialect, exec)
+		if err != nil {
+			return fmt.Errorf("failed to apply delta packet %d: %w", i, err)
+		}
+		total += n
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	fmt.Printf("✅ Delta applied: %d row(s) updated\n", total)
+	return nil
+}
+
+// BuildDeltaUpdateSQL строит UPDATE t SET c1=?, … WHERE k1=? AND …;
+// аргументы — новые значения changed, затем значения keys.
+func BuildDeltaUpdateSQL(quotedTable string, changed, keys []packet.Field, dialect DeltaDialect) string {
+	n := 0
+	next := func() string {
+		n++
+		return dialect.Placeholder(n)
+	}
+
+	sets := make([]string, len(changed))
+	for i, f := range changed {
+		sets[i] = dialect.Quote(f.Name) + " = " + next()
+	}
+	conds := make([]string, len(keys))
+	for i, f := range keys {
+		conds[i] = dialect.Quote(f.Name) + " = " + next()
+	}
+	return fmt.Sprintf("UPDATE %s SET %s WHERE %s", quotedTable, strings.Join(sets, ", "), strings.Join(conds, " AND "))
+}
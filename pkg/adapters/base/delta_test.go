@@ -0,0 +1,104 @@
+package base
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+type deltaCall struct {
+	query string
+	args  []any
+}
+
+func testDeltaDialect() DeltaDialect {
+	return DeltaDialect{
+		Quote:       func(name string) string { return `"` + name + `"` },
+		Placeholder: func(n int) string { return fmt.Sprintf("$%d", n) },
+		Convert:     func(value string, _ packet.Field) (any, error) { return value, nil },
+	}
+}
+
+func testDeltaPacket(t *testing.T) *packet.DataPacket {
+	t.Helper()
+	schema := packet.Schema{Fields: []packet.Field{
+		{Name: "id", Type: "INTEGER", Key: true},
+		{Name: "name", Type: "TEXT"},
+		{Name: "price", Type: "DECIMAL"},
+	}}
+	pkt, err := packet.NewDeltaPacket("items", schema, []packet.DeltaRow{
+		{Key: []string{"1"}, Changes: []packet.DeltaChange{{Field: "price", Old: "10", New: "12"}}},
+		{Key: []string{"2"}, Changes: []packet.DeltaChange{{Field: "name", Old: "a", New: "b"}, {Field: "price", Old: "1", New: "2"}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pkt
+}
+
+func TestApplyDeltaPacket(t *testing.T) {
+	var calls []deltaCall
+	exec := func(_ context.Context, query string, args []any) (int64, error) {
+		calls = append(calls, deltaCall{query, args})
+		return 1, nil
+	}
+
+	n, err := ApplyDeltaPacket(context.Background(), `"items"`, testDeltaPacket(t), adapters.StrategyReplace, testDeltaDialect(), exec)
+	if err != nil {
+		t.Fatalf("ApplyDeltaPacket: %v", err)
+	}
+	if n != 2 || len(calls) != 2 {
+		t.Fatalf("updated = %d, calls = %d", n, len(calls))
+	}
+	if want := `UPDATE "items" SET "name" = $1, "price" = $2 WHERE "id" = $3`; calls[1].query != want {
+		t.Errorf("query = %q, want %q", calls[1].query, want)
+	}
+	if fmt.Sprint(calls[1].args) != "[b 2 2]" {
+		t.Errorf("args = %v", calls[1].args)
+	}
+}
+
+func TestApplyDeltaPacket_MissingRows(t *testing.T) {
+	exec := func(_ context.Context, query string, _ []any) (int64, error) {
+		if strings.HasSuffix(query, "$2") { // первая строка: одно изменённое поле
+			return 0, nil
+		}
+		return 1, nil
+	}
+
+	n, err := ApplyDeltaPacket(context.Background(), `"items"`, testDeltaPacket(t), adapters.StrategyReplace, testDeltaDialect(), exec)
+	if err != nil || n != 1 {
+		t.Errorf("StrategyReplace: updated = %d, err = %v", n, err)
+	}
+
+	_, err = ApplyDeltaPacket(context.Background(), `"items"`, testDeltaPacket(t), adapters.StrategyFail, testDeltaDialect(), exec)
+	if err == nil || !strings.Contains(err.Error(), "no row with key 1") {
+		t.Errorf("StrategyFail: err = %v, want missing row error", err)
+	}
+
+	d := testDeltaDialect()
+	d.ChangedRowsOnly = true
+	n, err = ApplyDeltaPacket(context.Background(), `"items"`, testDeltaPacket(t), adapters.StrategyFail, d, exec)
+	if err != nil || n != 2 {
+		t.Errorf("ChangedRowsOnly: updated = %d, err = %v", n, err)
+	}
+}
+
+func TestIsDeltaBatch(t *testing.T) {
+	delta := testDeltaPacket(t)
+	full := packet.NewDataPacket(packet.TypeReference, "items")
+
+	if ok, err := IsDeltaBatch([]*packet.DataPacket{delta, delta}); !ok || err != nil {
+		t.Errorf("delta batch: ok=%v err=%v", ok, err)
+	}
+	if ok, err := IsDeltaBatch([]*packet.DataPacket{full}); ok || err != nil {
+		t.Errorf("full batch: ok=%v err=%v", ok, err)
+	}
+	if _, err := IsDeltaBatch([]*packet.DataPacket{full, delta}); err == nil {
+		t.Error("expected error for mixed batch")
+	}
+}
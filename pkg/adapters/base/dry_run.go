@@ -0,0 +1,344 @@
+package base
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+	"github.com/ruslano69/tdtp-framework/pkg/core/schema"
+)
+
+// dryRunKeyChunk — сколько ключей проверяется одним запросом к таблице.
+const dryRunKeyChunk = 200
+
+// DryRun — общая реализация adapters.DryRunImporter: пакеты открываются
+// (OpenPacket, DedupPacket) и проверяются против Target, в БД ничего не
+// пишется. Суррогатные ключи KeyMapper не выдаются — выдача пишет в
+// хранилище ключей; колонки шифруются Cipher, как при импорте. С
+// adapters.WithProvenance пакеты проверяются с колонками происхождения;
+// недостающие в таблице — предупреждение.
+type DryRun struct {
+	Target     adapters.Adapter
+	Converter  *UniversalTypeConverter
+	DBType     string // как в ConvertRowToSQLValues
+	Keys       PacketKeyProvider
+	Duplicates adapters.DuplicateMode
+	Columns    adapters.ColumnMatching
+	Cipher     adapters.ColumnCipher
+}
+
+// DryRun возвращает пробный импорт с ключами пакетов, обработкой
+// повторяющихся ключей и сопоставлением колонок helper'а.
+func (h *ImportHelper) DryRun(target adapters.Adapter, converter *UniversalTypeConverter, dbType string) *DryRun {
+	return &DryRun{Target: target, Converter: converter, DBType: dbType, Keys: h.packetKeys, Duplicates: h.duplicates, Columns: h.columns.Mode(), Cipher: h.cipher}
+}
+
+// Import проверяет пакеты по таблицам (в порядке первого появления):
+// существование таблицы, колонки и типы схемы пакета против схемы
+// таблицы, преобразование каждого значения к типу колонки таблицы и
+// строки, ключ которых в таблице уже есть. StrategyCopy заменяет таблицу —
+// значения проверяются по схеме пакета, ключи не сверяются; StrategyTruncate
+// очищает таблицу — ключи тоже не сверяются.
+func (d *DryRun) Import(ctx context.Context, packets []*packet.DataPacket, strategy adapters.ImportStrategy) (*adapters.ImportReport, error) {
+	report := &adapters.ImportReport{Strategy: strategy}
+	groups := make(map[string][]*packet.DataPacket)
+	for _, pkt := range packets {
+		if pkt == nil {
+			continue
+		}
+		if err := OpenPacket(ctx, pkt, d.Keys); err != nil {
+			return nil, err
+		}
+		if err := encryptColumns(pkt, d.Cipher); err != nil {
+			return nil, err
+		}
+		table := pkt.Header.TableName
+		if _, ok := groups[table]; !ok {
+			report.Tables = append(report.Tables, &adapters.TableImportReport{Table: table})
+		}
+		groups[table] = append(groups[table], pkt)
+	}
+	for _, t := range report.Tables {
+		if err := d.checkTable(ctx, t, groups[t.Table], strategy); err != nil {
+			return nil, fmt.Errorf("%s: %w", t.Table, err)
+		}
+	}
+	return report, nil
+}
+
+func (d *DryRun) checkTable(ctx context.Context, rep *adapters.TableImportReport, packets []*packet.DataPacket, strategy adapters.ImportStrategy) error {
+	exists, err := d.Target.TableExists(ctx, rep.Table)
+	if err != nil {
+		return err
+	}
+	rep.Exists = exists
+	var target *packet.Schema
+	switch {
+	case !exists:
+		rep.Warnings = append(rep.Warnings, "table does not exist and will be created from the packet schema")
+	case strategy == adapters.StrategyCopy:
+		rep.Warnings = append(rep.Warnings, "table will be replaced (strategy copy)")
+	default:
+		s, err := d.Target.GetTableSchema(ctx, rep.Table)
+		if err != nil {
+			return err
+		}
+		target = &s
+		if strategy == adapters.StrategyTruncate {
+			rep.Warnings = append(rep.Warnings, "existing rows will be deleted (strategy truncate)")
+		}
+	}
+
+	seen := make(map[string]bool) // сообщения о схеме — по одному на таблицу
+	note := func(list *[]string, msg string) {
+		if !seen[msg] {
+			seen[msg] = true
+			*list = append(*list, msg)
+		}
+	}
+	keys := &existingKeys{rows: make(map[string]int)}
+	skipped := 0
+	for i, pkt := range packets {
+		rep.Packets++
+		if pkt.Data.Delta || pkt.Data.Delete {
+			skipped++
+			continue
+		}
+
+		// Строки, которые импорт запишет: после DedupPacket
+		rows := *pkt
+		mode := d.Duplicates
+		if mode == adapters.DuplicatesOff {
+			mode = adapters.DuplicatesFail
+		}
+		if _, err := DedupPacket(&rows, mode); err != nil {
+			if d.Duplicates == adapters.DuplicatesFail {
+				rep.Errors = append(rep.Errors, err.Error())
+			} else {
+				rep.Warnings = append(rep.Warnings, err.Error()+" (the last written row wins)")
+			}
+		}
+		rep.Rows += len(rows.Data.Rows)
+
+		// Импорт с adapters.WithProvenance дописывает колонки происхождения
+		if importedAt, ok := adapters.ProvenanceFromContext(ctx); ok {
+			rows.Schema.Fields = slices.Clone(rows.Schema.Fields)
+			rows.Data.Rows = slices.Clone(rows.Data.Rows)
+			if err := adapters.AddProvenance(&rows, importedAt); err != nil {
+				return err
+			}
+		}
+
+		fields := d.checkFields(rep, &rows.Schema, target, note)
+		keyIdx := keyIndexes(&rows.Schema, fields)
+		for r, row := range rows.Data.Rows {
+			values := ParseRowValues(row)
+			if len(values) != len(fields) {
+				rep.AddRowError(adapters.RowError{Packet: i + 1, Row: r + 1,
+					Err: fmt.Sprintf("expected %d values, got %d", len(fields), len(values))})
+				continue
+			}
+			for j, f := range fields {
+				if f == nil {
+					continue // колонки нет в таблице — уже в Errors
+				}
+				if msg := valueError(values[j], *f, d.Converter, d.DBType); msg != "" {
+					rep.AddRowError(adapters.RowError{Packet: i + 1, Row: r + 1, Field: f.Name, Value: values[j], Err: msg})
+				}
+			}
+			if target != nil && len(keyIdx) > 0 {
+				keys.add(values, keyIdx, fields)
+			}
+		}
+	}
+	if skipped > 0 {
+		rep.Warnings = append(rep.Warnings, fmt.Sprintf("%d delta/delete packet(s) not checked", skipped))
+	}
+	if target == nil || len(keys.order) == 0 || strategy == adapters.StrategyTruncate {
+		return nil
+	}
+	return keys.lookup(ctx, d.Target, rep)
+}
+
+// checkFields сопоставляет поля пакета с колонками таблицы и возвращает,
+// по чему проверять значения: колонку таблицы (с маркерами SpecialValues
+// пакета), поле пакета, если таблицы нет, или nil, если колонки нет.
+func (d *DryRun) checkFields(rep *adapters.TableImportReport, pktSchema, target *packet.Schema, note func(*[]string, string)) []*packet.Field {
+	fields := make([]*packet.Field, len(pktSchema.Fields))
+	for i := range pktSchema.Fields {
+		pf := pktSchema.Fields[i]
+		if target == nil {
+			fields[i] = &pf
+			continue
+		}
+		col := findField(target, pf.Name)
+		if col == nil && adapters.IsProvenanceColumn(pf.Name) {
+			note(&rep.Warnings, fmt.Sprintf("provenance column %s will be added to the table", pf.Name))
+			fields[i] = &pf
+			continue
+		}
+		if col == nil {
+			if pf.Key || d.Columns != adapters.ColumnsSkipExtra {
+				note(&rep.Errors, fmt.Sprintf("column %s is missing in the table", pf.Name))
+			} else {
+				note(&rep.Warnings, fmt.Sprintf("column %s is missing in the table and will be skipped", pf.Name))
+			}
+			continue
+		}
+		if schema.NormalizeType(schema.DataType(pf.Type)) != schema.NormalizeType(schema.DataType(col.Type)) {
+			note(&rep.Warnings, fmt.Sprintf("column %s: packet type %s, table type %s", pf.Name, pf.Type, col.Type))
+		}
+		f := *col
+		f.SpecialValues = pf.SpecialValues
+		f.Key = pf.Key
+		fields[i] = &f
+	}
+	if target != nil && d.Columns == adapters.ColumnsStrict {
+		for i := range target.Fields {
+			if findField(pktSchema, target.Fields[i].Name) == nil {
+				note(&rep.Errors, fmt.Sprintf("table column %s is missing in the packet", target.Fields[i].Name))
+			}
+		}
+	}
+	if target != nil {
+		pktKeys, tableKeys := keyNames(pktSchema), keyNames(target)
+		if len(tableKeys) > 0 && !strings.EqualFold(strings.Join(pktKeys, ","), strings.Join(tableKeys, ",")) {
+			note(&rep.Warnings, fmt.Sprintf("packet key (%s) differs from the table primary key (%s)",
+				strings.Join(pktKeys, ", "), strings.Join(tableKeys, ", ")))
+		}
+	}
+	return fields
+}
+
+func findField(s *packet.Schema, name string) *packet.Field {
+	for i := range s.Fields {
+		if strings.EqualFold(s.Fields[i].Name, name) {
+			return &s.Fields[i]
+		}
+	}
+	return nil
+}
+
+func keyNames(s *packet.Schema) []string {
+	var names []string
+	for _, f := range s.Fields {
+		if f.Key {
+			names = append(names, f.Name)
+		}
+	}
+	return names
+}
+
+// keyIndexes — позиции ключевых полей пакета; nil, если какой-то ключевой
+// колонки нет в таблице.
+func keyIndexes(pktSchema *packet.Schema, fields []*packet.Field) []int {
+	var idx []int
+	for i, f := range pktSchema.Fields {
+		if f.Key {
+			if fields[i] == nil {
+				return nil
+			}
+			idx = append(idx, i)
+		}
+	}
+	return idx
+}
+
+// existingKeys — ключи строк пакетов для сверки с таблицей.
+type existingKeys struct {
+	fields []*packet.Field     // ключевые колонки таблицы
+	order  [][]string          // различные ключи в порядке появления
+	rows   map[string]int      // канонический ключ → число строк
+	values map[string][]string // канонический ключ → значения из пакета
+}
+
+func (k *existingKeys) add(values []string, keyIdx []int, fields []*packet.Field) {
+	if k.fields == nil {
+		for _, i := range keyIdx {
+			k.fields = append(k.fields, fields[i])
+		}
+		k.values = make(map[string][]string)
+	}
+	key := make([]string, len(keyIdx))
+	for j, i := range keyIdx {
+		if sv := fields[i].SpecialValues; sv != nil && sv.Null != nil && values[i] == sv.Null.Marker {
+			return // NULL в ключе не совпадает ни с одной строкой
+		}
+		key[j] = values[i]
+	}
+	ck := k.canonical(key)
+	if _, ok := k.rows[ck]; !ok {
+		k.order = append(k.order, key)
+		k.values[ck] = key
+	}
+	k.rows[ck]++
+}
+
+// canonical приводит ключ к виду, не зависящему от записи чисел
+// ("1.50" и "1.5" экспорта — один ключ).
+func (k *existingKeys) canonical(key []string) string {
+	parts := make([]string, len(key))
+	for i, v := range key {
+		parts[i] = v
+		if schema.IsNumericType(schema.NormalizeType(schema.DataType(k.fields[i].Type))) {
+			if f, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+				parts[i] = strconv.FormatFloat(f, 'f', -1, 64)
+			}
+		}
+	}
+	return strings.Join(parts, "\x00")
+}
+
+// lookup выбирает из таблицы ключевые колонки строк с ключами пакетов
+// (TDTQL-запрос пачками по dryRunKeyChunk) и учитывает найденные как конфликты.
+func (k *existingKeys) lookup(ctx context.Context, target adapters.Adapter, rep *adapters.TableImportReport) error {
+	names := make([]string, len(k.fields))
+	for i, f := range k.fields {
+		names[i] = f.Name
+	}
+	for start := 0; start < len(k.order); start += dryRunKeyChunk {
+		chunk := k.order[start:min(start+dryRunKeyChunk, len(k.order))]
+		groups := make([]packet.LogicalGroup, len(chunk))
+		for i, key := range chunk {
+			for j, v := range key {
+				groups[i].Filters = append(groups[i].Filters, packet.Filter{Field: names[j], Operator: "eq", Value: v})
+			}
+		}
+		query := packet.NewQuery()
+		query.Fields = names
+		query.Filters = &packet.Filters{Or: &packet.LogicalGroup{And: groups}}
+		found, err := target.ExportTableWithQuery(ctx, rep.Table, query, "dry-run", "")
+		if err != nil {
+			return fmt.Errorf("failed to look up existing keys: %w", err)
+		}
+		for _, pkt := range found {
+			idx := make([]int, len(names))
+			for j, name := range names {
+				idx[j] = -1
+				for c, f := range pkt.Schema.Fields {
+					if strings.EqualFold(f.Name, name) {
+						idx[j] = c
+					}
+				}
+			}
+			for _, row := range pkt.GetRows() {
+				key := make([]string, len(idx))
+				for j, c := range idx {
+					if c >= 0 && c < len(row) {
+						key[j] = row[c]
+					}
+				}
+				ck := k.canonical(key)
+				for n := k.rows[ck]; n > 0; n-- {
+					rep.AddConflict(k.values[ck])
+				}
+				delete(k.rows, ck) // строка таблицы с тем же ключом — один раз
+			}
+		}
+	}
+	return nil
+}
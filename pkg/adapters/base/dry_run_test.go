@@ -0,0 +1,147 @@
+package base
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+// mockDryRunTarget — таблица в памяти; ExportTableWithQuery понимает только
+// OR-группы eq-условий, которые строит DryRun.
+type mockDryRunTarget struct {
+	adapters.Adapter
+	schema  packet.Schema
+	rows    [][]string
+	queries int
+}
+
+func (m *mockDryRunTarget) TableExists(context.Context, string) (bool, error) {
+	return m.schema.Fields != nil, nil
+}
+
+func (m *mockDryRunTarget) GetTableSchema(context.Context, string) (packet.Schema, error) {
+	return m.schema, nil
+}
+
+func (m *mockDryRunTarget) column(name string) int {
+	for i, f := range m.schema.Fields {
+		if f.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func (m *mockDryRunTarget) ExportTableWithQuery(_ context.Context, table string, q *packet.Query, _, _ string) ([]*packet.DataPacket, error) {
+	m.queries++
+	var fields []packet.Field
+	for _, name := range q.Fields {
+		fields = append(fields, m.schema.Fields[m.column(name)])
+	}
+	var out [][]string
+	for _, row := range m.rows {
+		for _, g := range q.Filters.Or.And {
+			match := true
+			for _, f := range g.Filters {
+				match = match && row[m.column(f.Field)] == f.Value
+			}
+			if match {
+				proj := make([]string, len(q.Fields))
+				for j, name := range q.Fields {
+					proj[j] = row[m.column(name)]
+				}
+				out = append(out, proj)
+				break
+			}
+		}
+	}
+	return packet.NewGenerator().GenerateReference(table, packet.Schema{Fields: fields}, out)
+}
+
+func TestDryRun_Report(t *testing.T) {
+	target := &mockDryRunTarget{
+		schema: packet.Schema{Fields: []packet.Field{
+			{Name: "id", Type: "INTEGER", Key: true},
+			{Name: "name", Type: "TEXT", Length: 5},
+			{Name: "born", Type: "DATE"},
+		}},
+		rows: [][]string{{"2", "old", "2000-01-01"}, {"9", "x", ""}},
+	}
+	pktSchema := packet.Schema{Fields: []packet.Field{
+		{Name: "id", Type: "INTEGER", Key: true},
+		{Name: "name", Type: "TEXT"},
+		{Name: "born", Type: "TEXT"},
+		{Name: "extra", Type: "TEXT"},
+	}}
+	packets, err := packet.NewGenerator().GenerateReference("users", pktSchema, [][]string{
+		{"1", "ann", "1990-05-01", ""},
+		{"2", "too long", "1990-05-01", ""},
+		{"3", "bob", "31.02.1990", ""},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := &DryRun{Target: target, Converter: NewUniversalTypeConverter(), DBType: "mssql", Columns: adapters.ColumnsStrict}
+	report, err := d.Import(context.Background(), packets, adapters.StrategyFail)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Tables) != 1 {
+		t.Fatalf("tables: %d", len(report.Tables))
+	}
+	rep := report.Tables[0]
+	if !rep.Exists || rep.Packets != 1 || rep.Rows != 3 {
+		t.Errorf("report: %+v", rep)
+	}
+	if !reflect.DeepEqual(rep.Errors, []string{"column extra is missing in the table"}) {
+		t.Errorf("errors: %v", rep.Errors)
+	}
+	if len(rep.Warnings) != 1 || !strings.Contains(rep.Warnings[0], "column born") {
+		t.Errorf("warnings: %v", rep.Warnings)
+	}
+	if rep.RowErrorCount != 2 || rep.RowErrors[0].Field != "name" || rep.RowErrors[0].Row != 2 ||
+		rep.RowErrors[1].Field != "born" || rep.RowErrors[1].Value != "31.02.1990" {
+		t.Errorf("row errors: %+v", rep.RowErrors)
+	}
+	if rep.Conflicts != 1 || !reflect.DeepEqual(rep.ConflictKeys, [][]string{{"2"}}) || target.queries != 1 {
+		t.Errorf("conflicts %d %v, %d queries", rep.Conflicts, rep.ConflictKeys, target.queries)
+	}
+	err = report.Err()
+	if err == nil || !strings.Contains(err.Error(), "1 row(s) conflict with existing keys") {
+		t.Errorf("Err: %v", err)
+	}
+
+	// replace обновит существующие строки — конфликт не ошибка
+	report.Strategy = adapters.StrategyReplace
+	rep.Errors, rep.RowErrorCount = nil, 0
+	if err := report.Err(); err != nil {
+		t.Errorf("replace: %v", err)
+	}
+}
+
+func TestDryRun_NewTable(t *testing.T) {
+	target := &mockDryRunTarget{}
+	schema := packet.Schema{Fields: []packet.Field{{Name: "id", Type: "INTEGER", Key: true}}}
+	packets, err := packet.NewGenerator().GenerateReference("users", schema, [][]string{{"1"}, {"x"}, {"1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := &DryRun{Target: target, Converter: NewUniversalTypeConverter(), DBType: "sqlite", Duplicates: adapters.DuplicatesKeepFirst}
+	report, err := d.Import(context.Background(), packets, adapters.StrategyReplace)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rep := report.Tables[0]
+	if rep.Exists || rep.Rows != 2 || rep.RowErrorCount != 1 || rep.Conflicts != 0 || target.queries != 0 {
+		t.Errorf("report: %+v, %d queries", rep, target.queries)
+	}
+	if len(packets[0].Data.Rows) != 3 {
+		t.Errorf("dry run changed the packet: %d rows", len(packets[0].Data.Rows))
+	}
+}
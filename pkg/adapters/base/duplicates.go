@@ -0,0 +1,95 @@
+package base
+
+import (
+	"strings"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+// DedupPacket убирает из пакета строки с повторяющимся первичным ключом
+// (поля схемы с Key) по режиму mode и возвращает число убранных строк.
+// DuplicatesFail оставляет пакет как есть и возвращает *adapters.DuplicateKeysError.
+// Пакеты без ключа, delta-пакеты и пакеты удаления не меняются. Вызывается
+// после PrepareImport — до разбиения строк на батчи вставки.
+func DedupPacket(pkt *packet.DataPacket, mode adapters.DuplicateMode) (int, error) {
+	if mode == adapters.DuplicatesOff || pkt.Data.Delta || pkt.Data.Delete {
+		return 0, nil
+	}
+	var keyIdx []int
+	var keyFields []string
+	for i, f := range pkt.Schema.Fields {
+		if f.Key {
+			keyIdx = append(keyIdx, i)
+			keyFields = append(keyFields, f.Name)
+		}
+	}
+	if len(keyIdx) == 0 {
+		return 0, nil
+	}
+	pkt.MaterializeRows()
+	if err := packet.ExpandCompactRows(pkt); err != nil {
+		return 0, err
+	}
+
+	// Номера строк (с 0) каждого ключа в порядке первого появления
+	keys := make([][]string, 0, len(pkt.Data.Rows))
+	positions := make(map[string][]int, len(pkt.Data.Rows))
+	var order []string
+	parser := packet.NewParser()
+	for i, row := range pkt.Data.Rows {
+		values := parser.GetRowValues(row)
+		key := make([]string, len(keyIdx))
+		for j, idx := range keyIdx {
+			if idx < len(values) {
+				key[j] = values[idx]
+			}
+		}
+		k := strings.Join(key, "\x00")
+		if _, seen := positions[k]; !seen {
+			order = append(order, k)
+		}
+		positions[k] = append(positions[k], i)
+		keys = append(keys, key)
+	}
+	if len(positions) == len(pkt.Data.Rows) {
+		return 0, nil
+	}
+
+	if mode == adapters.DuplicatesFail {
+		report := &adapters.DuplicateKeysError{
+			Table:     pkt.Header.TableName,
+			MessageID: pkt.Header.MessageID,
+			KeyFields: keyFields,
+		}
+		for _, k := range order {
+			rows := positions[k]
+			if len(rows) < 2 {
+				continue
+			}
+			dup := adapters.DuplicateKey{Key: keys[rows[0]], Rows: make([]int, len(rows))}
+			for j, r := range rows {
+				dup.Rows[j] = r + 1
+			}
+			report.Keys = append(report.Keys, dup)
+		}
+		return 0, report
+	}
+
+	kept := make([]packet.Row, 0, len(positions))
+	for i, row := range pkt.Data.Rows {
+		rows := positions[strings.Join(keys[i], "\x00")]
+		keep := rows[0]
+		if mode == adapters.DuplicatesKeepLast {
+			keep = rows[len(rows)-1]
+		}
+		if keep == i {
+			kept = append(kept, row)
+		}
+	}
+	removed := len(pkt.Data.Rows) - len(kept)
+	pkt.Data.Rows = kept
+	pkt.Header.RecordsInPart = len(kept)
+	pkt.Header.Checksum = nil // строки сверены в OpenPacket, сумма больше не про них
+	return removed, nil
+}
@@ -0,0 +1,72 @@
+package base
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+func dupPacket(t *testing.T) *packet.DataPacket {
+	t.Helper()
+	schema := packet.Schema{Fields: []packet.Field{
+		{Name: "id", Type: "INTEGER", Key: true},
+		{Name: "name", Type: "TEXT"},
+	}}
+	packets, err := packet.NewGenerator().GenerateReference("orders", schema, [][]string{
+		{"1", "a"}, {"2", "b"}, {"1", "c"}, {"3", "d"}, {"2", "e"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return packets[0]
+}
+
+func TestDedupPacket(t *testing.T) {
+	tests := []struct {
+		mode adapters.DuplicateMode
+		want [][]string
+	}{
+		{adapters.DuplicatesKeepFirst, [][]string{{"1", "a"}, {"2", "b"}, {"3", "d"}}},
+		{adapters.DuplicatesKeepLast, [][]string{{"1", "c"}, {"3", "d"}, {"2", "e"}}},
+	}
+	for _, tt := range tests {
+		pkt := dupPacket(t)
+		removed, err := DedupPacket(pkt, tt.mode)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.mode, err)
+		}
+		if removed != 2 || pkt.Header.RecordsInPart != 3 {
+			t.Errorf("%s: removed %d, RecordsInPart %d", tt.mode, removed, pkt.Header.RecordsInPart)
+		}
+		if got := pkt.GetRows(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: rows %v, want %v", tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestDedupPacket_FailReport(t *testing.T) {
+	pkt := dupPacket(t)
+	_, err := DedupPacket(pkt, adapters.DuplicatesFail)
+	var report *adapters.DuplicateKeysError
+	if !errors.As(err, &report) || !errors.Is(err, adapters.ErrDuplicateKeys) {
+		t.Fatalf("got %v", err)
+	}
+	want := []adapters.DuplicateKey{{Key: []string{"1"}, Rows: []int{1, 3}}, {Key: []string{"2"}, Rows: []int{2, 5}}}
+	if !reflect.DeepEqual(report.Keys, want) {
+		t.Errorf("keys %+v", report.Keys)
+	}
+	if !strings.Contains(err.Error(), "[1] rows [1 3]") || len(pkt.Data.Rows) != 5 {
+		t.Errorf("error %q, %d rows left", err, len(pkt.Data.Rows))
+	}
+
+	// Без ключевых полей пакет не проверяется
+	pkt = dupPacket(t)
+	pkt.Schema.Fields[0].Key = false
+	if removed, err := DedupPacket(pkt, adapters.DuplicatesFail); err != nil || removed != 0 {
+		t.Errorf("keyless packet: %d, %v", removed, err)
+	}
+}
@@ -0,0 +1,127 @@
+package base
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+	"github.com/ruslano69/tdtp-framework/pkg/core/tdtql"
+)
+
+// Explain возвращает план ExportTableWithQuery без чтения строк: какие
+// фильтры уйдут в SQL, а какие выполнятся в памяти, стратегию и стадии с
+// оценками (ActualRows = -1). План с фактическими строками и временем
+// стадий приходит в QueryContext.ExecutionPlan экспорта.
+//
+// Как и экспорт, Explain проверяет и нормализует запрос и запрашивает
+// COUNT(*), если от него зависит стратегия (эта стадия фактическая). При
+// pushdown plan.SQL — текст запроса к источнику. Провал SQL на стороне СУБД
+// (и источник без SQL, ErrSQLUnsupported) заранее не виден: экспорт в этом
+// случае уходит в память с причиной в плане.
+func (h *ExportHelper) Explain(ctx context.Context, tableName string, query *packet.Query) (*packet.ExecutionPlan, error) {
+	if query == nil {
+		query = packet.NewQuery()
+	}
+	if query.Join != nil {
+		return h.explainJoin(ctx, tableName, query)
+	}
+	if customSQL, ok := h.tableQuery(tableName); ok {
+		return h.explainTableQuery(ctx, tableName, customSQL, query)
+	}
+
+	fullSchema, err := h.schemaReader.GetTableSchema(ctx, tableName)
+	if err != nil {
+		return nil, err
+	}
+	if len(query.Fields) > 0 {
+		if _, _, err := filterSchemaByFields(fullSchema, query.Fields); err != nil {
+			return nil, err
+		}
+	}
+	executor := tdtql.NewExecutor()
+	if err := executor.ValidateQuery(query, fullSchema); err != nil {
+		return nil, err
+	}
+	executor.NormalizeQueryFields(query, fullSchema)
+	query, implicitCast := executor.CoerceQuery(query, fullSchema)
+	query, _, err = keysetQuery(query, fullSchema)
+	if err != nil {
+		return nil, err
+	}
+
+	selectivity := tdtql.EstimateSelectivity(query.Filters)
+	sqlGenerator := h.newSQLGenerator()
+	reason := "query is not translatable to SQL; "
+	if implicitCast {
+		reason = implicitCastReason + "; "
+	} else if sqlGenerator.CanTranslateToSQL(query) {
+		standardSQL, err := sqlGenerator.GenerateSQL(tableName, query)
+		if err == nil {
+			plan := pushdownPlan(query, -1, selectivity)
+			plan.SQL = standardSQL
+			if h.sqlAdapter != nil {
+				plan.SQL = h.sqlAdapter.AdaptSQL(standardSQL, tableName, fullSchema, query)
+			}
+			if query.Limit > 0 {
+				plan.Stages = append(plan.Stages, packet.PlanStage{Name: packet.StageCount, Where: packet.ExecSQL, EstimatedRows: -1, ActualRows: -1})
+			}
+			return plan, nil
+		}
+		reason = fmt.Sprintf("SQL generation failed (%v); ", err)
+	}
+
+	_, canStream := h.dataReader.(RowStreamer)
+	rowCount, count := h.countRows(ctx, tableName, canStream)
+	plan := memoryPlan(query, canStream, rowCount, selectivity, count)
+	plan.Reason = reason + plan.Reason
+	if h.maxFallbackRows > 0 && rowCount > h.maxFallbackRows {
+		plan.Reason += fmt.Sprintf("; table exceeds the fallback row limit %d, export would abort", h.maxFallbackRows)
+	}
+	plan.Stages = append(plan.Stages, memoryStages(plan, query)...)
+	return plan, nil
+}
+
+// explainJoin — план запроса с Join (см. exportJoin).
+func (h *ExportHelper) explainJoin(ctx context.Context, tableName string, query *packet.Query) (*packet.ExecutionPlan, error) {
+	jp, err := h.prepareJoin(ctx, tableName, query)
+	if err != nil {
+		return nil, err
+	}
+	sql, reason, pushdown := h.joinSQL(tableName, jp)
+	if pushdown {
+		plan := pushdownPlan(jp.query, -1, jp.selectivity)
+		plan.SQL = sql
+		return plan, nil
+	}
+
+	plan := joinMemoryPlan(jp, -1, reason)
+	rest := *jp.query
+	rest.Join = nil
+	plan.Stages = append(plan.Stages,
+		packet.PlanStage{Name: packet.StageRead, Where: packet.ExecSQL, EstimatedRows: -1, ActualRows: -1},
+		packet.PlanStage{Name: packet.StageRead, Where: packet.ExecSQL, EstimatedRows: -1, ActualRows: -1},
+		packet.PlanStage{Name: packet.StageJoin, Where: packet.ExecMemory, EstimatedRows: -1, ActualRows: -1},
+	)
+	plan.Stages = append(plan.Stages, tdtql.PlanStages(&rest, -1)...)
+	return plan, nil
+}
+
+// explainTableQuery — план таблицы с собственным запросом: он читается
+// целиком, TDTQL выполняется в памяти.
+func (h *ExportHelper) explainTableQuery(ctx context.Context, tableName, customSQL string, query *packet.Query) (*packet.ExecutionPlan, error) {
+	schema, err := h.querySchema(ctx, tableName, customSQL)
+	if err != nil {
+		return nil, err
+	}
+	executor := tdtql.NewExecutor()
+	if err := executor.ValidateQuery(query, schema); err != nil {
+		return nil, err
+	}
+	executor.NormalizeQueryFields(query, schema)
+
+	plan := tableQueryPlan(query, -1)
+	plan.SQL = customSQL
+	plan.Stages = append(plan.Stages, packet.PlanStage{Name: packet.StageRead, Where: packet.ExecSQL, EstimatedRows: -1, ActualRows: -1})
+	plan.Stages = append(plan.Stages, tdtql.PlanStages(query, -1)...)
+	return plan, nil
+}
@@ -2,13 +2,18 @@ package base
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"strings"
+	"time"
 
 	"github.com/ruslano69/tdtp-framework/pkg/adapters"
 	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
 	"github.com/ruslano69/tdtp-framework/pkg/core/tdtql"
+	"github.com/ruslano69/tdtp-framework/pkg/security"
+	"github.com/ruslano69/tdtp-framework/pkg/tracing"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // SchemaReader предоставляет методы для чтения схемы таблицы
@@ -28,6 +33,11 @@ type DataReader interface {
 	GetRowCount(ctx context.Context, tableName string) (int64, error)
 }
 
+// ErrSQLUnsupported возвращает ReadRowsWithSQL адаптера без SQL (документные
+// БД, например MongoDB): ExportHelper фильтрует такой источник в памяти
+// (потоком, если DataReader реализует RowStreamer), не считая это провалом pushdown.
+var ErrSQLUnsupported = errors.New("data source does not support SQL")
+
 // ValueConverter предоставляет методы для конвертации значений
 type ValueConverter interface {
 	// ConvertValueToTDTP конвертирует значение из БД в TDTP формат
@@ -40,6 +50,13 @@ type SQLAdapter interface {
 	AdaptSQL(standardSQL string, tableName string, schema packet.Schema, query *packet.Query) string
 }
 
+// ILikeSQLAdapter — опциональный интерфейс SQLAdapter для СУБД с ILIKE
+// (PostgreSQL): LIKE без учёта регистра (коллация фильтра "ci") уходит в
+// SQL как ILIKE, а не как LOWER(field) LIKE LOWER(pattern).
+type ILikeSQLAdapter interface {
+	SupportsILike() bool
+}
+
 // RowPostProcessor — опциональный интерфейс для постобработки строк после чтения.
 // Адаптеры реализуют его когда нужна специфичная фильтрация столбцов
 // (например, MSSQL фильтрует read-only поля: identity, computed, timestamp).
@@ -56,9 +73,19 @@ type ExportHelper struct {
 	dataReader        DataReader
 	valueConverter    ValueConverter
 	sqlAdapter        SQLAdapter
-	maxMessageSize    int   // 0 = use generator default
-	skipSpecialValues bool  // --fast: skip DetectAndApply
-	maxFallbackRows   int64 // 0 = unlimited; > 0 = abort fallback path if table has more rows
+	maxMessageSize    int                   // 0 = use generator default
+	packetSizing      adapters.PacketSizing // пределы частей, см. SetPacketSizing
+	skipSpecialValues bool                  // --fast: skip DetectAndApply
+	maxFallbackRows   int64                 // 0 = unlimited; > 0 = abort fallback path if table has more rows
+	tableQueries      map[string]string     // имя таблицы (lower) → собственный SELECT, см. SetTableQueries
+	joinPushdown      bool                  // запросы с Join транслируются в SQL, см. SetJoinPushdown
+	regexDialect      tdtql.RegexDialect    // синтаксис regex СУБД, см. SetRegexPushdown
+	asciiLower        bool                  // LOWER СУБД — только ASCII, см. SetASCIILower
+
+	compression packet.CompressionOptions // сжатие Data пакетов, см. SetCompression
+	packetKeys  PacketKeyProvider         // шифрование секций пакетов, см. SetPacketKeys
+	cipher      adapters.ColumnCipher     // расшифровка колонок at rest, см. SetColumnCipher
+	policy      *security.ExportPolicy    // колонки по получателям, см. SetExportPolicy
 }
 
 // NewExportHelper создает новый ExportHelper
@@ -82,6 +109,17 @@ func (h *ExportHelper) SetMaxMessageSize(size int) {
 	h.maxMessageSize = size
 }
 
+// SetPacketSizing задаёт размер пакетов экспорта: предел байт, строк и
+// режим оценки (adapters.Config.Packets). SetMaxMessageSize, если задан,
+// переопределяет MaxBytes.
+func (h *ExportHelper) SetPacketSizing(s adapters.PacketSizing) error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+	h.packetSizing = s
+	return nil
+}
+
 // SetSkipSpecialValues включает режим --fast: DetectAndApply пропускается.
 // NULL/NaN/Inf не получат canonical markers. Применять только для источников
 // без спецзначений или когда скорость важнее полноты метаданных.
@@ -89,6 +127,19 @@ func (h *ExportHelper) SetSkipSpecialValues(skip bool) {
 	h.skipSpecialValues = skip
 }
 
+// SetRegexPushdown задаёт синтаксис регулярных выражений СУБД: условия
+// regex / not_regex транслируются в SQL. Без него запрос с regex
+// фильтруется в памяти.
+func (h *ExportHelper) SetRegexPushdown(dialect tdtql.RegexDialect) {
+	h.regexDialect = dialect
+}
+
+// SetASCIILower сообщает, что LOWER СУБД меняет регистр только у ASCII
+// (SQLite): условия ci с не-ASCII значениями фильтруются в памяти.
+func (h *ExportHelper) SetASCIILower(enabled bool) {
+	h.asciiLower = enabled
+}
+
 // SetMaxFallbackRows задаёт лимит строк для in-memory fallback при провале SQL pushdown.
 // При 0 — без лимита (текущее поведение). При > 0 — если таблица больше лимита,
 // возвращается ошибка вместо чтения всей таблицы в RAM. Защищает прод-БД от 17 GB сканов.
@@ -96,9 +147,59 @@ func (h *ExportHelper) SetMaxFallbackRows(n int64) {
 	h.maxFallbackRows = n
 }
 
+// SetCompression включает сжатие Data экспортируемых пакетов (Compression="zstd"
+// в <Data>). Реализации алгоритмов регистрирует пакет processors — его нужно
+// импортировать в приложении. ImportHelper распаковывает такие пакеты сам.
+func (h *ExportHelper) SetCompression(opts packet.CompressionOptions) {
+	h.compression = opts
+}
+
+// SetPacketKeys включает шифрование экспортируемых пакетов TDTP v1.5
+// (AES-256-GCM, packet.EncryptSections) ключами keys; nil — выключить.
+// Перед шифрованием пакеты получают xxh3-хеши и сжимаются (SetCompression).
+func (h *ExportHelper) SetPacketKeys(keys PacketKeyProvider) {
+	h.packetKeys = keys
+}
+
+// SetColumnCipher включает расшифровку колонок, зашифрованных at rest
+// (ImportHelper.SetColumnCipher), в каждом экспортируемом пакете; nil — выключить.
+func (h *ExportHelper) SetColumnCipher(c adapters.ColumnCipher) {
+	h.cipher = c
+}
+
+// SetExportPolicy включает политику выдачи колонок получателям
+// (adapters.Config.ExportPolicy): ExportTableWithQuery отклоняет запрос
+// запрещённых получателю колонок до обращения к БД, seal удаляет или
+// маскирует остальные в каждом пакете; nil — выключить.
+func (h *ExportHelper) SetExportPolicy(p *security.ExportPolicy) {
+	h.policy = p
+}
+
+// seal сжимает и шифрует сгенерированные пакеты согласно настройкам (SealPackets).
+// Заголовки пакетов получают контекст трассы экспорта (tracing.InjectPackets).
+func (h *ExportHelper) seal(ctx context.Context, packets []*packet.DataPacket) ([]*packet.DataPacket, error) {
+	tracing.InjectPackets(ctx, packets)
+	if err := SealPackets(ctx, packets, SealOptions{
+		Compression: h.compression,
+		Keys:        h.packetKeys,
+		Cipher:      h.cipher,
+		Policy:      h.policy,
+	}); err != nil {
+		return nil, err
+	}
+	return packets, nil
+}
+
+// Seal — финальный шаг экспорта (seal) для пакетов, собранных адаптером
+// без ExportHelper (инкрементальный экспорт, CDC).
+func (h *ExportHelper) Seal(ctx context.Context, packets []*packet.DataPacket) ([]*packet.DataPacket, error) {
+	return h.seal(ctx, packets)
+}
+
 // newGenerator возвращает генератор с учётом всех настроек ExportHelper.
 func (h *ExportHelper) newGenerator() *packet.Generator {
 	g := packet.NewGenerator()
+	_ = h.packetSizing.Apply(g) // проверено в SetPacketSizing
 	if h.maxMessageSize > 0 {
 		g.SetMaxMessageSize(h.maxMessageSize)
 	}
@@ -108,9 +209,47 @@ func (h *ExportHelper) newGenerator() *packet.Generator {
 	return g
 }
 
+// newSQLGenerator возвращает генератор SQL с учётом диалекта SQLAdapter.
+func (h *ExportHelper) newSQLGenerator() *tdtql.SQLGenerator {
+	g := tdtql.NewSQLGenerator()
+	if a, ok := h.sqlAdapter.(ILikeSQLAdapter); ok {
+		g.SetILike(a.SupportsILike())
+	}
+	g.SetRegex(h.regexDialect)
+	g.SetASCIILower(h.asciiLower)
+	return g
+}
+
+// startExportSpan начинает спан tdtp.export таблицы.
+func startExportSpan(ctx context.Context, tableName string) (context.Context, trace.Span) {
+	return tracing.Start(ctx, "tdtp.export", tracing.AttrTable.String(tableName))
+}
+
+// endExportSpan завершает спан экспорта: число пакетов и строк, план запроса.
+func endExportSpan(span trace.Span, packets []*packet.DataPacket, err error) {
+	if err == nil {
+		tracing.SetPacketStats(span, packets)
+		if len(packets) > 0 && packets[0].QueryContext != nil && packets[0].QueryContext.ExecutionPlan != nil {
+			span.SetAttributes(tracing.AttrPlan.String(packets[0].QueryContext.ExecutionPlan.Strategy))
+		}
+	}
+	tracing.End(span, err)
+}
+
 // ExportTable экспортирует всю таблицу в TDTP reference пакеты
 // Общая реализация для всех адаптеров
 func (h *ExportHelper) ExportTable(ctx context.Context, tableName string) ([]*packet.DataPacket, error) {
+	ctx, span := startExportSpan(ctx, tableName)
+	packets, err := h.exportTable(ctx, tableName)
+	endExportSpan(span, packets, err)
+	return packets, err
+}
+
+func (h *ExportHelper) exportTable(ctx context.Context, tableName string) ([]*packet.DataPacket, error) {
+	if customSQL, ok := h.tableQuery(tableName); ok {
+		return h.exportTableQuery(ctx, tableName, customSQL, nil, "", "")
+	}
+
 	// 1. Получаем схему
 	schema, err := h.schemaReader.GetTableSchema(ctx, tableName)
 	if err != nil {
@@ -130,7 +269,11 @@ func (h *ExportHelper) ExportTable(ctx context.Context, tableName string) ([]*pa
 
 	// 4. Генерируем reference пакеты
 	generator := h.newGenerator()
-	return generator.GenerateReference(tableName, schema, rows)
+	packets, err := generator.GenerateReference(tableName, schema, rows)
+	if err != nil {
+		return nil, err
+	}
+	return h.seal(ctx, packets)
 }
 
 // ExportTableWithQuery экспортирует таблицу с фильтрацией через TDTQL
@@ -143,7 +286,31 @@ func (h *ExportHelper) ExportTableWithQuery(
 ) ([]*packet.DataPacket, error) {
 	// query == nil означает полный экспорт без фильтрации — делегируем в ExportTable
 	if query == nil {
-		return h.ExportTable(ctx, tableName)
+		return h.ExportTable(adapters.WithRecipient(ctx, recipient), tableName)
+	}
+	// Явный запрос запрещённой получателю колонки — отказ до обращения к БД
+	if h.policy != nil {
+		if err := h.policy.CheckQuery(tableName, recipient, query); err != nil {
+			return nil, err
+		}
+	}
+	ctx, span := startExportSpan(ctx, tableName)
+	packets, err := h.exportTableWithQuery(ctx, tableName, query, sender, recipient)
+	endExportSpan(span, packets, err)
+	return packets, err
+}
+
+func (h *ExportHelper) exportTableWithQuery(
+	ctx context.Context,
+	tableName string,
+	query *packet.Query,
+	sender, recipient string,
+) ([]*packet.DataPacket, error) {
+	if query.Join != nil {
+		return h.exportJoin(ctx, tableName, query, sender, recipient)
+	}
+	if customSQL, ok := h.tableQuery(tableName); ok {
+		return h.exportTableQuery(ctx, tableName, customSQL, query, sender, recipient)
 	}
 
 	// 1. Получаем полную схему таблицы
@@ -154,9 +321,8 @@ func (h *ExportHelper) ExportTableWithQuery(
 
 	// 2. Если задана проекция колонок — валидируем и строим filtered schema
 	pkgSchema := fullSchema
-	var fieldIndices []int // позиции выбранных полей в полной схеме (для fallback)
 	if len(query.Fields) > 0 {
-		pkgSchema, fieldIndices, err = filterSchemaByFields(fullSchema, query.Fields)
+		pkgSchema, _, err = filterSchemaByFields(fullSchema, query.Fields)
 		if err != nil {
 			return nil, err
 		}
@@ -170,9 +336,26 @@ func (h *ExportHelper) ExportTableWithQuery(
 	// Нормализация имён полей к каноническим из схемы (критично для PostgreSQL quoted identifiers)
 	executor.NormalizeQueryFields(query, fullSchema)
 
-	// 4. Пробуем транслировать TDTQL → SQL для оптимизации (pushdown filtering)
-	sqlGenerator := tdtql.NewSQLGenerator()
-	if sqlGenerator.CanTranslateToSQL(query) {
+	// Постраничный запрос — keyset по первичному ключу (см. keysetQuery);
+	// в QueryContext.OriginalQuery остаётся запрос клиента
+	original := query
+
+	// Числа в TEXT-колонках сравниваем как числа; автоприведённые условия
+	// фильтруются в памяти (см. tdtql.Executor.CoerceTextFilters)
+	query, implicitCast := executor.CoerceQuery(query, fullSchema)
+	query, keys, err := keysetQuery(query, fullSchema)
+	if err != nil {
+		return nil, err
+	}
+
+	// 4. Cost model: pushdown если запрос транслируется в SQL, иначе —
+	// потоковая фильтрация или чтение всей таблицы (см. choosePlan).
+	// Решение и оценки попадают в QueryContext.ExecutionPlan.
+	sqlGenerator := h.newSQLGenerator()
+	selectivity := tdtql.EstimateSelectivity(query.Filters)
+	pushdownFailed := false
+	noSQL := false // источник без SQL — фильтрация в памяти штатная
+	if !implicitCast && sqlGenerator.CanTranslateToSQL(query) {
 		// Оптимизированный путь: фильтрация на уровне SQL
 		standardSQL, err := sqlGenerator.GenerateSQL(tableName, query)
 		if err == nil {
@@ -183,76 +366,129 @@ func (h *ExportHelper) ExportTableWithQuery(
 			}
 
 			// Выполняем SQL запрос с filtered schema (количество колонок совпадает)
+			sqlStart := time.Now()
 			rows, err := h.dataReader.ReadRowsWithSQL(ctx, adaptedSQL, pkgSchema)
 			if err == nil {
+				sqlStage := packet.PlanStage{Name: packet.StageSQL, Where: packet.ExecSQL}
+				sqlStage.Done(len(rows), sqlStart)
+
 				// Постобработка (опционально): фильтрация read-only полей и т.п.
 				if pp, ok := h.dataReader.(RowPostProcessor); ok {
 					pkgSchema, rows = pp.PostProcessRows(ctx, pkgSchema, rows)
 				}
 
+				countStart := time.Now()
 				queryContext := h.createQueryContextForSQL(ctx, query, rows, tableName)
+				queryContext.OriginalQuery = *original
+				if queryContext.ExecutionResults.MoreDataAvailable {
+					queryContext.ExecutionResults.NextAfter = nextAfter(keys, pkgSchema, rows)
+				}
+				rowCount := int64(-1) // COUNT(*) запрашивается только для пагинации
+				if query.Limit > 0 {
+					rowCount = int64(queryContext.ExecutionResults.TotalRecordsInTable)
+				}
+				plan := pushdownPlan(query, rowCount, selectivity)
+				sqlStage.EstimatedRows = plan.Stages[0].EstimatedRows
+				plan.Stages[0] = sqlStage
+				if query.Limit > 0 {
+					plan.AddStage(packet.StageCount, packet.ExecSQL, -1, int(rowCount), countStart)
+				}
+				queryContext.ExecutionPlan = plan
 
-				generator := h.newGenerator()
-				return generator.GenerateResponse(
-					tableName,
-					packet.InReplyToDirectExport,
-					pkgSchema,
-					rows,
-					queryContext,
-					sender,
-					recipient,
-				)
+				packets, err := h.generateResponse(tableName, pkgSchema, rows, queryContext, sender, recipient)
+				if err != nil {
+					return nil, err
+				}
+				return h.seal(ctx, packets)
+			}
+			if errors.Is(err, ErrSQLUnsupported) {
+				noSQL = true
+			} else {
+				log.Printf("WARNING: SQL pushdown failed for table %q: %v\nSQL: %s\n— falling back to full table scan (may use significant memory)", tableName, err, adaptedSQL)
 			}
-			log.Printf("WARNING: SQL pushdown failed for table %q: %v\nSQL: %s\n— falling back to full table scan (may use significant memory)", tableName, err, adaptedSQL)
 		}
+		pushdownFailed = !noSQL
 	}
 
+	// Размер таблицы — для safety-net и выбора между потоком и чтением целиком.
+	// Без лимита и без RowStreamer выбирать нечего — лишний COUNT(*) не делаем.
+	streamer, canStream := h.dataReader.(RowStreamer)
+	rowCount, countStage := h.countRows(ctx, tableName, canStream)
+
 	// Safety-net: проверяем размер таблицы до in-memory сканирования.
 	// Защищает прод-БД от обвала при WHERE/проекции которые не транслировались в SQL.
-	if h.maxFallbackRows > 0 {
-		if count, cntErr := h.dataReader.GetRowCount(ctx, tableName); cntErr == nil && count > h.maxFallbackRows {
-			return nil, fmt.Errorf("fallback aborted: table %q has %d rows (limit %d). "+
-				"SQL pushdown failed — fix the query or raise --fallback-row-limit (0 = unlimited)",
-				tableName, count, h.maxFallbackRows)
-		}
+	if h.maxFallbackRows > 0 && rowCount > h.maxFallbackRows {
+		return nil, fmt.Errorf("fallback aborted: table %q has %d rows (limit %d). "+
+			"SQL pushdown failed — fix the query or raise --fallback-row-limit (0 = unlimited)",
+			tableName, rowCount, h.maxFallbackRows)
 	}
 
-	// Fallback путь: in-memory фильтрация (для сложных запросов или если SQL не удался)
-	allRows, err := h.dataReader.ReadAllRows(ctx, tableName, fullSchema)
-	if err != nil {
-		return nil, err
+	plan := memoryPlan(query, canStream, rowCount, selectivity, countStage)
+	if pushdownFailed {
+		plan.Reason = "SQL pushdown failed; " + plan.Reason
+	} else if implicitCast {
+		plan.Reason = implicitCastReason + "; " + plan.Reason
 	}
 
-	// Применяем TDTQL фильтрацию в памяти (по полной схеме)
-	result, err := executor.Execute(query, allRows, fullSchema)
-	if err != nil {
-		return nil, fmt.Errorf("failed to execute query: %w", err)
-	}
+	var result *tdtql.ExecutionResult
+	readStart := time.Now()
+	if plan.Strategy == packet.PlanStreamFilter {
+		// Потоковая фильтрация: в памяти только совпавшие строки
+		matched, total, err := streamFilter(ctx, streamer, executor, tableName, fullSchema, query.Filters)
+		if err != nil {
+			return nil, err
+		}
+		plan.AddStage(packet.StageStream, packet.ExecMemory, plan.EstimatedMatches, len(matched), readStart)
 
-	// Применяем проекцию колонок если задана (после фильтрации)
-	filteredRows := result.FilteredRows
-	filteredSchema := fullSchema
-	if len(fieldIndices) > 0 {
-		filteredRows = projectRows(filteredRows, fieldIndices)
-		filteredSchema = pkgSchema
+		// Сортировка и пагинация — по уже отфильтрованным строкам
+		rest := *query
+		rest.Filters = nil
+		result, err = executor.ExecuteContext(ctx, &rest, matched, fullSchema)
+		if err != nil {
+			return nil, fmt.Errorf("failed to execute query: %w", err)
+		}
+		result.TotalRows = total
+		result.QueryContext.ExecutionResults.TotalRecordsInTable = total
+	} else {
+		// Fallback путь: in-memory фильтрация (для сложных запросов или если SQL не удался)
+		allRows, err := h.dataReader.ReadAllRows(ctx, tableName, fullSchema)
+		if err != nil {
+			return nil, err
+		}
+		plan.AddStage(packet.StageRead, packet.ExecSQL, rowCount, len(allRows), readStart)
+
+		// Применяем TDTQL фильтрацию в памяти (по полной схеме)
+		result, err = executor.ExecuteContext(ctx, query, allRows, fullSchema)
+		if err != nil {
+			return nil, fmt.Errorf("failed to execute query: %w", err)
+		}
+	}
+	plan.Stages = append(plan.Stages, result.Stages...)
+	result.QueryContext.ExecutionPlan = plan
+	result.QueryContext.OriginalQuery = *original
+	if len(query.After) > 0 {
+		// Страница после After: смещение внутри остатка клиенту не нужно
+		result.QueryContext.ExecutionResults.NextOffset = 0
+	}
+	if result.QueryContext.ExecutionResults.MoreDataAvailable {
+		result.QueryContext.ExecutionResults.NextAfter = nextAfter(keys, fullSchema, result.FilteredRows)
 	}
 
+	// Проекция колонок применена executor'ом после фильтрации
+	filteredRows := result.Rows
+	filteredSchema := result.Schema
+
 	// Постобработка (опционально): фильтрация read-only полей и т.п.
 	if pp, ok := h.dataReader.(RowPostProcessor); ok {
 		filteredSchema, filteredRows = pp.PostProcessRows(ctx, filteredSchema, filteredRows)
 	}
 
 	// Генерируем Response пакеты с QueryContext
-	generator := h.newGenerator()
-	return generator.GenerateResponse(
-		tableName,
-		packet.InReplyToDirectExport,
-		filteredSchema,
-		filteredRows,
-		result.QueryContext,
-		sender,
-		recipient,
-	)
+	packets, err := h.generateResponse(tableName, filteredSchema, filteredRows, result.QueryContext, sender, recipient)
+	if err != nil {
+		return nil, err
+	}
+	return h.seal(ctx, packets)
 }
 
 // FilterSchemaByFields возвращает схему только с запрошенными полями и их индексы
@@ -312,6 +548,20 @@ func (h *ExportHelper) ExportTableIncremental(
 	incrementalConfig adapters.IncrementalConfig,
 	buildIncrementalSQL func(tableName string, config adapters.IncrementalConfig) (string, []any),
 	executeIncrementalQuery func(ctx context.Context, sql string, args []any, schema packet.Schema) ([][]string, string, error),
+) ([]*packet.DataPacket, string, error) {
+	ctx, span := startExportSpan(ctx, tableName)
+	span.SetAttributes(tracing.AttrTrackingField.String(incrementalConfig.TrackingField))
+	packets, lastValue, err := h.exportTableIncremental(ctx, tableName, incrementalConfig, buildIncrementalSQL, executeIncrementalQuery)
+	endExportSpan(span, packets, err)
+	return packets, lastValue, err
+}
+
+func (h *ExportHelper) exportTableIncremental(
+	ctx context.Context,
+	tableName string,
+	incrementalConfig adapters.IncrementalConfig,
+	buildIncrementalSQL func(tableName string, config adapters.IncrementalConfig) (string, []any),
+	executeIncrementalQuery func(ctx context.Context, sql string, args []any, schema packet.Schema) ([][]string, string, error),
 ) ([]*packet.DataPacket, string, error) {
 	// Валидация конфигурации
 	if err := incrementalConfig.Validate(); err != nil {
@@ -357,6 +607,9 @@ func (h *ExportHelper) ExportTableIncremental(
 	if err != nil {
 		return nil, "", fmt.Errorf("failed to generate packets: %w", err)
 	}
+	if packets, err = h.seal(ctx, packets); err != nil {
+		return nil, "", err
+	}
 
 	return packets, lastTrackingValue, nil
 }
@@ -379,9 +632,13 @@ func (h *ExportHelper) createQueryContextForSQL(
 		if count, err := h.dataReader.GetRowCount(ctx, tableName); err == nil {
 			totalCount = count
 		}
-		// Проверяем есть ли еще данные: offset + returned < total
+		// Проверяем есть ли еще данные: offset + returned < total.
+		// Для страницы после After позиция в таблице неизвестна — полная
+		// страница значит, что данные могут быть дальше.
 		currentPosition := query.Offset + recordsReturned
-		if currentPosition < int(totalCount) {
+		if len(query.After) > 0 {
+			moreDataAvailable = recordsReturned == query.Limit
+		} else if currentPosition < int(totalCount) {
 			moreDataAvailable = true
 			nextOffset = query.Offset + recordsReturned
 		}
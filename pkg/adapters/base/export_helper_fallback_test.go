@@ -29,6 +29,7 @@ type mockDataReader struct {
 	readAllRowsCalls int
 	getRowCountCalls int
 	readSQLCalls     int
+	lastSQL          string
 }
 
 func (m *mockDataReader) ReadAllRows(_ context.Context, _ string, _ packet.Schema) ([][]string, error) {
@@ -36,8 +37,9 @@ func (m *mockDataReader) ReadAllRows(_ context.Context, _ string, _ packet.Schem
 	return m.rowsFromAll, nil
 }
 
-func (m *mockDataReader) ReadRowsWithSQL(_ context.Context, _ string, _ packet.Schema) ([][]string, error) {
+func (m *mockDataReader) ReadRowsWithSQL(_ context.Context, sql string, _ packet.Schema) ([][]string, error) {
 	m.readSQLCalls++
+	m.lastSQL = sql
 	if m.sqlErr != nil {
 		return nil, m.sqlErr
 	}
@@ -191,3 +193,29 @@ func TestExportHelper_Fallback_RowCountError_DoesNotAbort(t *testing.T) {
 		t.Errorf("ReadAllRows must still run when row count is unknown, got %d calls", reader.readAllRowsCalls)
 	}
 }
+
+// Проекция в fallback: фильтр по полю вне проекции, в пакете — только
+// выбранные колонки и урезанная схема.
+func TestExportHelper_Fallback_FieldsProjection(t *testing.T) {
+	reader := &mockDataReader{
+		sqlErr:      errors.New("mssql: Conversion failed"),
+		rowsFromAll: [][]string{{"42", "Alice"}, {"7", "Bob"}},
+	}
+	helper := buildFallbackTestHelper(reader)
+
+	query := buildEqQuery()
+	query.Fields = []string{"name"}
+	packets, err := helper.ExportTableWithQuery(context.Background(), "Users", query, "test", "test")
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if !strings.HasPrefix(reader.lastSQL, "SELECT Name FROM") {
+		t.Errorf("pushdown SQL must select the projection: %s", reader.lastSQL)
+	}
+	if f := packets[0].Schema.Fields; len(f) != 1 || f[0].Name != "Name" {
+		t.Errorf("schema = %+v, want only Name", f)
+	}
+	if rows := packets[0].GetRows(); len(rows) != 1 || len(rows[0]) != 1 || rows[0][0] != "Alice" {
+		t.Errorf("rows = %v, want [[Alice]]", rows)
+	}
+}
@@ -9,6 +9,8 @@ import (
 	"github.com/ruslano69/tdtp-framework/pkg/adapters"
 	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
 	"github.com/ruslano69/tdtp-framework/pkg/core/schema"
+	"github.com/ruslano69/tdtp-framework/pkg/tracing"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // isDateFieldType reports whether a TDTP field type can carry NoDate or date-Infinity.
@@ -55,6 +57,26 @@ type ImportHelper struct {
 	dataInserter       DataInserter
 	transactionManager TransactionManager
 	useTemporaryTables bool // Использовать ли временные таблицы для атомарной замены
+	governor           *adapters.Governor
+	streamBatchPackets int // пакетов в транзакции ImportPacketStream (0 — по умолчанию)
+
+	packetKeys  PacketKeyProvider     // расшифровка пакетов TDTP v1.5, см. SetPacketKeys
+	keyMapper   KeyMapper             // суррогатные ключи хранилища, см. SetKeyMapper
+	cipher      adapters.ColumnCipher // шифрование колонок at rest, см. SetColumnCipher
+	tableLock   adapters.TableLocking
+	maintenance *adapters.MaintenanceWindows // см. SetMaintenanceWindows
+	duplicates  adapters.DuplicateMode
+	columns     *ColumnMatcher // см. SetColumnMatching
+
+	rowErrors adapters.RowErrorConfig // см. SetRowErrorPolicy
+	converter *UniversalTypeConverter
+	dbType    string
+}
+
+// KeyMapper дописывает в пакет импорта суррогатные ключи по бизнес-ключам
+// (keymap.Mapper). Повторный вызов для того же пакета не должен его менять.
+type KeyMapper interface {
+	MapPacket(ctx context.Context, pkt *packet.DataPacket) error
 }
 
 // NewImportHelper создает новый ImportHelper
@@ -64,53 +86,331 @@ func NewImportHelper(
 	transactionManager TransactionManager,
 	useTemporaryTables bool,
 ) *ImportHelper {
-	return &ImportHelper{
+	h := &ImportHelper{
 		tableManager:       tableManager,
 		dataInserter:       dataInserter,
 		transactionManager: transactionManager,
 		useTemporaryTables: useTemporaryTables,
 	}
+	h.SetColumnMatching(adapters.ColumnsByName)
+	return h
+}
+
+// SetGovernor включает ограничение скорости/параллелизма импорта
+// (nil — без ограничений). Каждый вызов ImportPacket/ImportPackets — одна
+// транзакция для Governor.
+func (h *ImportHelper) SetGovernor(g *adapters.Governor) {
+	h.governor = g
+}
+
+// SetPacketKeys задаёт источник ключей для расшифровки пакетов TDTP v1.5
+// (ExportHelper.SetPacketKeys). Без него зашифрованный пакет — ошибка импорта.
+func (h *ImportHelper) SetPacketKeys(keys PacketKeyProvider) {
+	h.packetKeys = keys
+}
+
+// SetColumnCipher задаёт шифрование колонок at rest: назначенные колонки
+// пакетов шифруются после их открытия, до записи (nil — выключено).
+func (h *ImportHelper) SetColumnCipher(c adapters.ColumnCipher) {
+	h.cipher = c
+}
+
+// SetTableLocking включает блокировку целевых таблиц на время импорта
+// (tableManager должен реализовать TableLocker, иначе настройка не действует).
+func (h *ImportHelper) SetTableLocking(cfg adapters.TableLocking) {
+	h.tableLock = cfg
+}
+
+// SetMaintenanceWindows ограничивает разрушающие импорты — замену таблицы
+// через временную (StrategyCopy) и очистку (StrategyTruncate) — окнами
+// обслуживания целевых таблиц (nil — без ограничений).
+func (h *ImportHelper) SetMaintenanceWindows(m *adapters.MaintenanceWindows) {
+	h.maintenance = m
+}
+
+// SetDuplicateMode задаёт обработку строк пакета с одинаковым первичным
+// ключом (DedupPacket) перед записью.
+func (h *ImportHelper) SetDuplicateMode(mode adapters.DuplicateMode) {
+	h.duplicates = mode
+}
+
+// SetColumnMatching задаёт сопоставление полей пакета с колонками
+// существующей таблицы (ColumnMatcher; tableManager должен реализовать
+// SchemaReader, иначе поля пишутся как есть).
+func (h *ImportHelper) SetColumnMatching(mode adapters.ColumnMatching) {
+	if src, ok := h.tableManager.(TableColumnSource); ok {
+		h.columns = NewColumnMatcher(src, mode)
+	}
+}
+
+// matchColumns — ColumnMatcher.Match для пакетов, которые пишутся в
+// существующую таблицу (не через временную таблицу StrategyCopy).
+func (h *ImportHelper) matchColumns(ctx context.Context, packets []*packet.DataPacket, strategy adapters.ImportStrategy) ([]*packet.DataPacket, error) {
+	if h.useTemporaryTables && strategy == adapters.StrategyCopy {
+		return packets, nil
+	}
+	return h.columns.Match(ctx, packets)
+}
+
+// SetKeyMapper задаёт выдачу суррогатных ключей: пакеты дополняются
+// суррогатами после расшифровки и распаковки, до записи в БД (nil — выключено).
+func (h *ImportHelper) SetKeyMapper(m KeyMapper) {
+	h.keyMapper = m
+}
+
+func (h *ImportHelper) openPacket(ctx context.Context, pkt *packet.DataPacket) error {
+	if err := PrepareImport(ctx, pkt, h.packetKeys, h.keyMapper, h.cipher); err != nil {
+		return err
+	}
+	_, err := DedupPacket(pkt, h.duplicates)
+	return err
+}
+
+// PrepareImport готовит пакет к записи в БД: OpenPacket, затем суррогатные
+// ключи mapper и шифрование колонок cipher (nil — без них). Для адаптеров
+// с собственным импортом.
+func PrepareImport(ctx context.Context, pkt *packet.DataPacket, keys PacketKeyProvider, mapper KeyMapper, cipher adapters.ColumnCipher) error {
+	if err := OpenPacket(ctx, pkt, keys); err != nil {
+		return err
+	}
+	if mapper != nil {
+		if err := mapper.MapPacket(ctx, pkt); err != nil {
+			return fmt.Errorf("packet %s: %w", pkt.Header.MessageID, err)
+		}
+	}
+	return encryptColumns(pkt, cipher)
+}
+
+// encryptColumns шифрует колонки открытого пакета (cipher == nil — ничего).
+func encryptColumns(pkt *packet.DataPacket, cipher adapters.ColumnCipher) error {
+	if cipher == nil {
+		return nil
+	}
+	if _, err := cipher.EncryptPacket(pkt); err != nil {
+		return fmt.Errorf("packet %s: column encryption: %w", pkt.Header.MessageID, err)
+	}
+	return nil
+}
+
+// ApplyImportProvenance добавляет в пакеты колонки происхождения, если
+// импорт идёт с adapters.WithProvenance (adapters.ApplyProvenance: таблица
+// target дополняется недостающими колонками). Пакеты должны быть открыты
+// (PrepareImport). Для адаптеров с собственным импортом.
+func ApplyImportProvenance(ctx context.Context, target adapters.ProvenanceTarget, packets []*packet.DataPacket) error {
+	importedAt, ok := adapters.ProvenanceFromContext(ctx)
+	if !ok {
+		return nil
+	}
+	return adapters.ApplyProvenance(ctx, target, packets, importedAt)
+}
+
+// applyProvenance — ApplyImportProvenance с tableManager в роли целевой БД.
+func (h *ImportHelper) applyProvenance(ctx context.Context, packets []*packet.DataPacket) error {
+	if _, ok := adapters.ProvenanceFromContext(ctx); !ok {
+		return nil
+	}
+	target, ok := h.tableManager.(adapters.ProvenanceTarget)
+	if !ok {
+		return fmt.Errorf("provenance columns require a table manager that reads table schemas")
+	}
+	return ApplyImportProvenance(ctx, target, packets)
 }
 
 // ImportPacket импортирует один TDTP пакет в БД
 // StrategyCopy (и useTemporaryTables=true): атомарная замена через temp-таблицу.
-// StrategyReplace/Ignore/Fail: прямой UPSERT в существующую таблицу.
+// StrategyReplace/Merge/Ignore/Fail: прямой UPSERT в существующую таблицу
+// (Merge обновляет только колонки UpdateFields).
+// StrategyAppend: вставка без обработки конфликтов.
+// StrategyTruncate: очистка таблицы (TableTruncater) и вставка в одной
+// транзакции, переданной адаптеру через WithImportTx.
+// Delta-пакеты (Data delta="true"): точечные UPDATE через DeltaApplier.
+// Пакеты удаления (Data delete="true"): DELETE по ключу через DeleteApplier.
+// Спан tdtp.import продолжает трассу отправителя пакета (tracing.StartFromPacket).
 // Общая реализация для всех адаптеров
-func (h *ImportHelper) ImportPacket(ctx context.Context, pkt *packet.DataPacket, strategy adapters.ImportStrategy) error {
-	// Материализуем rawRows → Data.Rows если пакет пришёл из GenerateReference (fast-path).
-	pkt.MaterializeRows()
+func (h *ImportHelper) ImportPacket(ctx context.Context, pkt *packet.DataPacket, strategy adapters.ImportStrategy) (err error) {
+	ctx, span := tracing.StartFromPacket(ctx, "tdtp.import", &pkt.Header, trace.SpanKindInternal, tracing.AttrStrategy.String(string(strategy)))
+	defer func() { tracing.End(span, err) }()
+
+	ctx = adapters.WithMessageID(ctx, pkt.Header.MessageID) // msg= в журнале выражений
+	// Зашифрованные и сжатые пакеты (ExportHelper.SetPacketKeys/SetCompression)
+	// расшифровываются и распаковываются прозрачно.
+	if err := h.openPacket(ctx, pkt); err != nil {
+		return err
+	}
+	tracing.SetPacketStats(span, []*packet.DataPacket{pkt})
 
 	// Проверяем тип пакета
 	if pkt.Header.Type != packet.TypeReference && pkt.Header.Type != packet.TypeResponse {
 		return fmt.Errorf("can only import reference or response packets, got: %s", pkt.Header.Type)
 	}
+	if err := h.applyProvenance(ctx, []*packet.DataPacket{pkt}); err != nil {
+		return err
+	}
 
-	tableName := pkt.Header.TableName
+	// Окно обслуживания — до блокировки: ожидание не держит таблицу
+	if err := h.awaitMaintenance(ctx, strategy, []*packet.DataPacket{pkt}); err != nil {
+		return err
+	}
 
-	// Временные таблицы используем только для StrategyCopy
-	if h.useTemporaryTables && strategy == adapters.StrategyCopy {
-		return h.importWithTemporaryTable(ctx, pkt, strategy)
+	ctx, unlock, err := h.lockTables(ctx, pkt.Header.TableName)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if pkt.Data.Delta {
+		return h.applyDelta(ctx, []*packet.DataPacket{pkt}, strategy)
+	}
+	if pkt.Data.Delete {
+		return h.applyDelete(ctx, []*packet.DataPacket{pkt})
+	}
+
+	matched, err := h.matchColumns(ctx, []*packet.DataPacket{pkt}, strategy)
+	if err != nil {
+		return err
+	}
+	pkt = matched[0]
+	if err := h.rejectRows([]*packet.DataPacket{pkt}, &adapters.ImportReport{}); err != nil {
+		return err
+	}
+	if err := checkMerge(ctx, []*packet.DataPacket{pkt}, strategy); err != nil {
+		return err
 	}
+	tableName := pkt.Header.TableName
 
-	// Для всех остальных стратегий — прямая вставка (UPSERT/INSERT/etc.)
-	return h.importDirect(ctx, tableName, pkt.Schema, pkt.Data.Rows, strategy)
+	return h.governor.Do(ctx, len(pkt.Data.Rows), func() error {
+		// Временные таблицы используем только для StrategyCopy
+		if h.useTemporaryTables && strategy == adapters.StrategyCopy {
+			return h.importWithTemporaryTable(ctx, pkt, strategy)
+		}
+		// Очистка и загрузка — в одной транзакции
+		if strategy == adapters.StrategyTruncate {
+			return h.importPacketsTx(ctx, []*packet.DataPacket{pkt}, tableName, pkt.Schema, strategy)
+		}
+
+		// Для всех остальных стратегий — прямая вставка (UPSERT/INSERT/etc.)
+		return h.importDirect(ctx, tableName, pkt.Schema, pkt.Data.Rows, strategy)
+	})
 }
 
 // ImportPackets импортирует несколько пакетов атомарно (в одной транзакции)
 // Общая реализация для всех адаптеров
 func (h *ImportHelper) ImportPackets(ctx context.Context, packets []*packet.DataPacket, strategy adapters.ImportStrategy) error {
+	return h.importPackets(ctx, packets, strategy, &adapters.ImportReport{Strategy: strategy})
+}
+
+// importPackets — тело ImportPackets/ImportPacketsReport; report заполняется
+// пакетами данных по мере записи.
+func (h *ImportHelper) importPackets(ctx context.Context, packets []*packet.DataPacket, strategy adapters.ImportStrategy, report *adapters.ImportReport) (err error) {
 	if len(packets) == 0 {
 		return nil
 	}
+	ctx, span := tracing.StartFromPacket(ctx, "tdtp.import", &packets[0].Header, trace.SpanKindInternal, tracing.AttrStrategy.String(string(strategy)))
+	defer func() { tracing.End(span, err) }()
+
+	// Расшифровываем, распаковываем и материализуем rawRows → Data.Rows для всех пакетов
+	tables := make([]string, 0, len(packets))
+	for _, pkt := range packets {
+		if err := h.openPacket(ctx, pkt); err != nil {
+			return err
+		}
+		tables = append(tables, pkt.Header.TableName)
+	}
+	tracing.SetPacketStats(span, packets)
+	if err := h.applyProvenance(ctx, packets); err != nil {
+		return err
+	}
 
-	tableName := packets[0].Header.TableName
-	canonicalSchema := packets[0].Schema
+	// Окно обслуживания — до блокировки: ожидание не держит таблицы
+	if err := h.awaitMaintenance(ctx, strategy, packets); err != nil {
+		return err
+	}
 
-	// Материализуем rawRows → Data.Rows для всех пакетов
+	ctx, unlock, err := h.lockTables(ctx, tables...)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	delta, err := IsDeltaBatch(packets)
+	if err != nil {
+		return err
+	}
+	if delta {
+		return h.applyDelta(ctx, packets, strategy)
+	}
+
+	// Tombstones инкрементальной выгрузки: сначала данные, затем удаления
+	packets, deletes := SplitDeletePackets(packets)
+	if packets, err = h.matchColumns(ctx, packets, strategy); err != nil {
+		return err
+	}
+	if err := h.rejectRows(packets, report); err != nil {
+		return err
+	}
+	if err := checkMerge(ctx, packets, strategy); err != nil {
+		return err
+	}
+	if len(packets) > 0 {
+		totalRows := 0
+		for _, pkt := range packets {
+			totalRows += len(pkt.Data.Rows)
+		}
+		err := h.governor.Do(ctx, totalRows, func() error {
+			if h.rowErrors.PartialRollback && !(h.useTemporaryTables && strategy == adapters.StrategyCopy) {
+				return h.importPacketsNested(ctx, packets, packets[0].Header.TableName, packets[0].Schema, strategy, report)
+			}
+			return h.importPacketsTx(ctx, packets, packets[0].Header.TableName, packets[0].Schema, strategy)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if len(deletes) > 0 {
+		return h.applyDelete(ctx, deletes)
+	}
+	return nil
+}
+
+// applyDelta применяет delta-пакеты через DeltaApplier адаптера.
+func (h *ImportHelper) applyDelta(ctx context.Context, packets []*packet.DataPacket, strategy adapters.ImportStrategy) error {
+	applier, ok := h.dataInserter.(DeltaApplier)
+	if !ok {
+		return fmt.Errorf("adapter does not support delta packets")
+	}
+	rows := 0
 	for _, pkt := range packets {
-		pkt.MaterializeRows()
+		rows += len(pkt.Data.Rows)
 	}
+	return h.governor.Do(ctx, rows, func() error {
+		return applier.ApplyDelta(ctx, packets, strategy)
+	})
+}
 
+// applyDelete применяет пакеты удаления через DeleteApplier адаптера.
+func (h *ImportHelper) applyDelete(ctx context.Context, packets []*packet.DataPacket) error {
+	applier, ok := h.dataInserter.(DeleteApplier)
+	if !ok {
+		return fmt.Errorf("adapter does not support delete packets")
+	}
+	rows := 0
+	for _, pkt := range packets {
+		rows += len(pkt.Data.Rows)
+	}
+	return h.governor.Do(ctx, rows, func() error {
+		_, err := applier.ApplyDelete(ctx, packets)
+		return err
+	})
+}
+
+// importPacketsTx — тело ImportPackets: все пакеты в одной транзакции.
+func (h *ImportHelper) importPacketsTx(
+	ctx context.Context,
+	packets []*packet.DataPacket,
+	tableName string,
+	canonicalSchema packet.Schema,
+	strategy adapters.ImportStrategy,
+) error {
 	// Начинаем транзакцию
 	tx, err := h.transactionManager.BeginTx(ctx)
 	if err != nil {
@@ -142,7 +442,8 @@ func (h *ImportHelper) ImportPackets(ctx context.Context, packets []*packet.Data
 
 			fmt.Printf("  📦 Importing packet %d/%d\n", i+1, len(packets))
 
-			if err = h.dataInserter.InsertRows(ctx, tempTableName, pkt.Schema, pkt.Data.Rows, strategy); err != nil {
+			pktCtx := adapters.WithMessageID(ctx, pkt.Header.MessageID)
+			if err = h.dataInserter.InsertRows(pktCtx, tempTableName, pkt.Schema, pkt.Data.Rows, strategy); err != nil {
 				_ = h.tableManager.DropTable(ctx, tempTableName)
 				return fmt.Errorf("failed to import packet %d: %w", i+1, err)
 			}
@@ -156,6 +457,14 @@ func (h *ImportHelper) ImportPackets(ctx context.Context, packets []*packet.Data
 			return fmt.Errorf("failed to replace tables: %w", err)
 		}
 	} else {
+		// StrategyTruncate: очистка и вставка идут через транзакцию tx
+		if strategy == adapters.StrategyTruncate {
+			ctx = WithImportTx(ctx, tx)
+			if err = h.truncateTable(ctx, tableName, canonicalSchema); err != nil {
+				return err
+			}
+		}
+
 		// Прямая вставка: UPSERT/INSERT в целевую таблицу
 		for i, pkt := range packets {
 			if !packet.SchemaEquals(canonicalSchema, pkt.Schema) {
@@ -166,7 +475,8 @@ func (h *ImportHelper) ImportPackets(ctx context.Context, packets []*packet.Data
 
 			fmt.Printf("  📦 Importing packet %d/%d\n", i+1, len(packets))
 
-			if err = h.importDirect(ctx, tableName, pkt.Schema, pkt.Data.Rows, strategy); err != nil {
+			pktCtx := adapters.WithMessageID(ctx, pkt.Header.MessageID)
+			if err = h.importDirect(pktCtx, tableName, pkt.Schema, pkt.Data.Rows, strategy); err != nil {
 				return fmt.Errorf("failed to import packet %d: %w", i+1, err)
 			}
 		}
@@ -182,6 +492,75 @@ func (h *ImportHelper) ImportPackets(ctx context.Context, packets []*packet.Data
 	return nil
 }
 
+// importPacketsNested — importPacketsTx с частичным откатом
+// (RowErrorConfig.PartialRollback): каждый пакет пишется во вложенной
+// транзакции (adapters.Nested), ошибка СУБД откатывает только его, и пакет
+// попадает в report. Запись идёт через транзакцию импорта (ImportTx).
+func (h *ImportHelper) importPacketsNested(
+	ctx context.Context,
+	packets []*packet.DataPacket,
+	tableName string,
+	canonicalSchema packet.Schema,
+	strategy adapters.ImportStrategy,
+	report *adapters.ImportReport,
+) (err error) {
+	tx, err := h.transactionManager.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx) // игнорируем ошибку rollback при ошибке импорта
+		}
+	}()
+
+	ctx = WithImportTx(ctx, tx)
+	if strategy == adapters.StrategyTruncate {
+		if err = h.truncateTable(ctx, tableName, canonicalSchema); err != nil {
+			return err
+		}
+	}
+
+	for i, pkt := range packets {
+		if !packet.SchemaEquals(canonicalSchema, pkt.Schema) {
+			fmt.Printf("  ⚠️  Skipping packet %d/%d: schema mismatch (expected %d fields, got %d)\n",
+				i+1, len(packets), len(canonicalSchema.Fields), len(pkt.Schema.Fields))
+			continue
+		}
+
+		fmt.Printf("  📦 Importing packet %d/%d\n", i+1, len(packets))
+
+		var insertErr error
+		pktCtx := adapters.WithMessageID(ctx, pkt.Header.MessageID)
+		err = adapters.Nested(pktCtx, tx, fmt.Sprintf("tdtp_packet_%d", i+1), func(ctx context.Context) error {
+			insertErr = h.importDirect(ctx, tableName, pkt.Schema, pkt.Data.Rows, strategy)
+			return insertErr
+		})
+		if err == nil {
+			continue
+		}
+		if insertErr == nil || err != insertErr {
+			// Точку сохранения не создать или не откатить: только вся транзакция
+			return fmt.Errorf("failed to import packet %d: %w", i+1, err)
+		}
+		err = nil
+
+		fmt.Printf("  ⚠ packet %d/%d rolled back: %v\n", i+1, len(packets), insertErr)
+		rep := reportTable(report, pkt.Header.TableName)
+		rep.Rows -= len(pkt.Data.Rows)
+		rep.RolledBackRows += len(pkt.Data.Rows)
+		rep.RolledBack = append(rep.RolledBack, fmt.Sprintf("%s: %v", pkt.Header.MessageID, insertErr))
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	fmt.Printf("✅ Import completed successfully\n")
+
+	return nil
+}
+
 // importWithTemporaryTable импортирует данные через временную таблицу (атомарная замена)
 func (h *ImportHelper) importWithTemporaryTable(ctx context.Context, pkt *packet.DataPacket, strategy adapters.ImportStrategy) error {
 	tableName := pkt.Header.TableName
@@ -235,11 +614,36 @@ func (h *ImportHelper) importDirect(ctx context.Context, tableName string, pkgSc
 	return h.dataInserter.InsertRows(ctx, tableName, pkgSchema, rows, strategy)
 }
 
+// truncateTable готовит таблицу к загрузке StrategyTruncate: очищает её
+// (TableTruncater) или создаёт по схеме пакета, если таблицы нет.
+func (h *ImportHelper) truncateTable(ctx context.Context, tableName string, pkgSchema packet.Schema) error {
+	truncater, ok := h.tableManager.(TableTruncater)
+	if !ok {
+		return fmt.Errorf("adapter does not support strategy %s", adapters.StrategyTruncate)
+	}
+	exists, err := h.tableManager.TableExists(ctx, tableName)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		if err := h.tableManager.CreateTable(ctx, tableName, pkgSchema); err != nil {
+			return fmt.Errorf("failed to create table: %w", err)
+		}
+		return nil
+	}
+	fmt.Printf("🧹 Truncating table: %s\n", tableName)
+	if err := truncater.TruncateTable(ctx, tableName); err != nil {
+		return fmt.Errorf("failed to truncate table %s: %w", tableName, err)
+	}
+	return nil
+}
+
 // replaceTables заменяет продакшен таблицу временной (атомарная операция)
 // Общая логика для всех адаптеров:
 // 1. Если prod таблица существует: old_table ← prod_table, prod_table ← temp_table, DROP old_table
 // 2. Если prod таблицы нет: prod_table ← temp_table
 func (h *ImportHelper) replaceTables(ctx context.Context, targetTable, tempTable string) error {
+	defer h.columns.Forget(targetTable) // у новой таблицы схема пакета
 	// Проверяем существует ли целевая таблица
 	exists, err := h.tableManager.TableExists(ctx, targetTable)
 	if err != nil {
@@ -376,6 +780,14 @@ func ConvertRowToSQLValues(
 			return nil, fmt.Errorf("field %s: %w", fieldDef.Name, err)
 		}
 
+		// BOOLEAN приёмника, хранимый текстом (Y/N, Да/Нет)
+		if typedValue.BoolValue != nil {
+			if m := converter.BoolMapping(field.Name); m != nil {
+				args[i] = m.Format(*typedValue.BoolValue)
+				continue
+			}
+		}
+
 		// Конвертируем в SQL значение
 		args[i] = converter.TypedValueToSQL(*typedValue, dbType)
 	}
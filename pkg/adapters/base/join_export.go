@@ -0,0 +1,215 @@
+package base
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+	"github.com/ruslano69/tdtp-framework/pkg/core/tdtql"
+)
+
+// SetJoinPushdown включает трансляцию запросов с Join в SQL. Адаптер
+// включает его, если СУБД выполнит сгенерированный JOIN, а SQLAdapter
+// (если есть) квалифицирует обе таблицы. Без него обе таблицы читаются
+// целиком и соединяются в памяти (tdtql.Executor.ExecuteJoin).
+func (h *ExportHelper) SetJoinPushdown(enabled bool) {
+	h.joinPushdown = enabled
+}
+
+// joinPlan — подготовленный запрос с Join: схемы таблиц и результата.
+type joinPlan struct {
+	executor    *tdtql.Executor
+	leftSchema  packet.Schema
+	rightSchema packet.Schema
+	pkgSchema   packet.Schema // схема пакета (с проекцией Fields)
+	selectivity float64
+
+	// query — запрос с приведёнными условиями (tdtql.Executor.CoerceQuery);
+	// implicitCast — автоприведение к REAL, такой запрос соединяется в памяти.
+	query        *packet.Query
+	implicitCast bool
+}
+
+// prepareJoin проверяет запрос с Join по схемам обеих таблиц и нормализует
+// его (общая часть exportJoin и Explain).
+func (h *ExportHelper) prepareJoin(ctx context.Context, tableName string, query *packet.Query) (*joinPlan, error) {
+	join := query.Join
+	if len(query.After) > 0 {
+		return nil, fmt.Errorf("keyset pagination (After) is not supported with Join")
+	}
+	for _, t := range []string{tableName, join.Table} {
+		if _, ok := h.tableQuery(t); ok {
+			return nil, fmt.Errorf("join: table %q is exported with a custom query, Join is not supported", t)
+		}
+	}
+
+	jp := &joinPlan{executor: tdtql.NewExecutor()}
+	var err error
+	if jp.leftSchema, err = h.schemaReader.GetTableSchema(ctx, tableName); err != nil {
+		return nil, err
+	}
+	if jp.rightSchema, err = h.schemaReader.GetTableSchema(ctx, join.Table); err != nil {
+		return nil, fmt.Errorf("join %s: %w", join.Table, err)
+	}
+
+	if err := jp.executor.PrepareJoin(join, jp.leftSchema, jp.rightSchema); err != nil {
+		return nil, err
+	}
+	fullSchema := tdtql.JoinSchema(jp.leftSchema, join, jp.rightSchema)
+
+	jp.pkgSchema = fullSchema
+	if len(query.Fields) > 0 {
+		jp.pkgSchema, _, err = filterSchemaByFields(fullSchema, query.Fields)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := jp.executor.ValidateQuery(query, fullSchema); err != nil {
+		return nil, err
+	}
+	jp.executor.NormalizeQueryFields(query, fullSchema)
+	jp.query, jp.implicitCast = jp.executor.CoerceQuery(query, fullSchema)
+	jp.selectivity = tdtql.EstimateSelectivity(jp.query.Filters)
+	return jp, nil
+}
+
+// joinSQL — SQL запроса с Join, если адаптер выполняет JOIN в СУБД и запрос
+// транслируется; иначе ok = false и причина соединения в памяти.
+func (h *ExportHelper) joinSQL(tableName string, jp *joinPlan) (sql, reason string, ok bool) {
+	query := jp.query
+	reason = "adapter does not push joins down"
+	if jp.implicitCast {
+		return "", implicitCastReason, false
+	}
+	sqlGenerator := h.newSQLGenerator()
+	if !h.joinPushdown || !sqlGenerator.CanTranslateToSQL(query) {
+		return "", reason, false
+	}
+	standardSQL, err := sqlGenerator.GenerateSQL(tableName, query)
+	if err != nil {
+		return "", "join is not translatable to SQL", false
+	}
+	if h.sqlAdapter != nil {
+		return h.sqlAdapter.AdaptSQL(standardSQL, tableName, jp.leftSchema, query), "", true
+	}
+	return standardSQL, "", true
+}
+
+// joinMemoryPlan — план соединения в памяти: обе таблицы читаются целиком.
+func joinMemoryPlan(jp *joinPlan, rowCount int64, reason string) *packet.ExecutionPlan {
+	plan := choosePlan(false, false, rowCount, jp.selectivity)
+	plan.Reason = "join in memory: " + reason
+	plan.Filters = tdtql.PlanFilters(jp.query.Filters, packet.ExecMemory)
+	return plan
+}
+
+// exportJoin экспортирует результат запроса с Join: строки основной
+// таблицы с полями присоединённой (tdtql.JoinSchema).
+func (h *ExportHelper) exportJoin(
+	ctx context.Context,
+	tableName string,
+	query *packet.Query,
+	sender, recipient string,
+) ([]*packet.DataPacket, error) {
+	join := query.Join
+	jp, err := h.prepareJoin(ctx, tableName, query)
+	if err != nil {
+		return nil, err
+	}
+
+	adaptedSQL, reason, pushdown := h.joinSQL(tableName, jp)
+	if pushdown {
+		start := time.Now()
+		rows, err := h.dataReader.ReadRowsWithSQL(ctx, adaptedSQL, jp.pkgSchema)
+		if err == nil {
+			queryContext := joinQueryContext(query, len(rows))
+			queryContext.ExecutionPlan = pushdownPlan(jp.query, -1, jp.selectivity)
+			queryContext.ExecutionPlan.Stages[0].Done(len(rows), start)
+			return h.joinResponse(ctx, tableName, jp.pkgSchema, rows, queryContext, sender, recipient)
+		}
+		reason = "SQL pushdown failed"
+		if !errors.Is(err, ErrSQLUnsupported) {
+			log.Printf("WARNING: join pushdown failed for %q JOIN %q: %v\nSQL: %s\n— falling back to joining both tables in memory", tableName, join.Table, err, adaptedSQL)
+		}
+	}
+
+	// Соединение в памяти: обе таблицы читаются целиком
+	rowCount := int64(-1)
+	if h.maxFallbackRows > 0 {
+		rowCount = 0
+		for _, t := range []string{tableName, join.Table} {
+			count, err := h.dataReader.GetRowCount(ctx, t)
+			if err != nil {
+				continue
+			}
+			if count > h.maxFallbackRows {
+				return nil, fmt.Errorf("fallback aborted: table %q has %d rows (limit %d). "+
+					"Join is executed in memory — narrow the query or raise --fallback-row-limit (0 = unlimited)",
+					t, count, h.maxFallbackRows)
+			}
+			rowCount += count
+		}
+	}
+	plan := joinMemoryPlan(jp, rowCount, reason)
+	start := time.Now()
+	leftRows, err := h.dataReader.ReadAllRows(ctx, tableName, jp.leftSchema)
+	if err != nil {
+		return nil, err
+	}
+	plan.AddStage(packet.StageRead, packet.ExecSQL, -1, len(leftRows), start)
+	start = time.Now()
+	rightRows, err := h.dataReader.ReadAllRows(ctx, join.Table, jp.rightSchema)
+	if err != nil {
+		return nil, fmt.Errorf("join %s: %w", join.Table, err)
+	}
+	plan.AddStage(packet.StageRead, packet.ExecSQL, -1, len(rightRows), start)
+	result, _, err := jp.executor.ExecuteJoin(jp.query, leftRows, jp.leftSchema, rightRows, jp.rightSchema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	plan.Stages = append(plan.Stages, result.Stages...)
+	result.QueryContext.ExecutionPlan = plan
+	result.QueryContext.OriginalQuery = *query
+
+	return h.joinResponse(ctx, tableName, result.Schema, result.Rows, result.QueryContext, sender, recipient)
+}
+
+// joinQueryContext — QueryContext результата pushdown: число строк
+// соединения заранее неизвестно, полная страница значит, что данные могут
+// быть дальше.
+func joinQueryContext(query *packet.Query, returned int) *packet.QueryContext {
+	qc := &packet.QueryContext{
+		OriginalQuery: *query,
+		ExecutionResults: packet.ExecutionResults{
+			RecordsAfterFilters: returned,
+			RecordsReturned:     returned,
+		},
+	}
+	if query.Limit > 0 && returned == query.Limit {
+		qc.ExecutionResults.MoreDataAvailable = true
+		qc.ExecutionResults.NextOffset = query.Offset + returned
+	}
+	return qc
+}
+
+// joinResponse собирает Response пакеты результата соединения.
+func (h *ExportHelper) joinResponse(
+	ctx context.Context,
+	tableName string,
+	schema packet.Schema,
+	rows [][]string,
+	queryContext *packet.QueryContext,
+	sender, recipient string,
+) ([]*packet.DataPacket, error) {
+	if pp, ok := h.dataReader.(RowPostProcessor); ok {
+		schema, rows = pp.PostProcessRows(ctx, schema, rows)
+	}
+	packets, err := h.generateResponse(tableName, schema, rows, queryContext, sender, recipient)
+	if err != nil {
+		return nil, err
+	}
+	return h.seal(ctx, packets)
+}
@@ -0,0 +1,158 @@
+package base
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+	"github.com/ruslano69/tdtp-framework/pkg/core/schema"
+)
+
+// tablesReader — источник из нескольких таблиц (схема и строки по имени)
+type tablesReader struct {
+	schemas map[string]packet.Schema
+	rows    map[string][][]string
+
+	sqlRows  [][]string
+	sqlErr   error
+	lastSQL  string
+	readAll  []string
+	rowCount int64
+}
+
+func (r *tablesReader) GetTableSchema(_ context.Context, table string) (packet.Schema, error) {
+	s, ok := r.schemas[table]
+	if !ok {
+		return packet.Schema{}, fmt.Errorf("table %s not found", table)
+	}
+	return s, nil
+}
+
+func (r *tablesReader) ReadAllRows(_ context.Context, table string, _ packet.Schema) ([][]string, error) {
+	r.readAll = append(r.readAll, table)
+	return r.rows[table], nil
+}
+
+func (r *tablesReader) ReadRowsWithSQL(_ context.Context, sql string, _ packet.Schema) ([][]string, error) {
+	r.lastSQL = sql
+	return r.sqlRows, r.sqlErr
+}
+
+func (r *tablesReader) GetRowCount(_ context.Context, table string) (int64, error) {
+	if r.rowCount > 0 {
+		return r.rowCount, nil
+	}
+	return int64(len(r.rows[table])), nil
+}
+
+func newJoinTestReader() *tablesReader {
+	return &tablesReader{
+		schemas: map[string]packet.Schema{
+			"orders":    schema.NewBuilder().AddInteger("id", true).AddInteger("customer_id", false).Build(),
+			"customers": schema.NewBuilder().AddInteger("id", true).AddText("name", 50).Build(),
+		},
+		rows: map[string][][]string{
+			"orders":    {{"1", "10"}, {"2", "20"}, {"3", "30"}},
+			"customers": {{"10", "Alice"}, {"20", "Bob"}},
+		},
+	}
+}
+
+func joinTestQuery() *packet.Query {
+	q := packet.NewQuery()
+	q.Join = &packet.Join{Table: "customers", On: []packet.JoinOn{{Left: "customer_id", Right: "id"}}}
+	q.Fields = []string{"id", "customers.name"}
+	return q
+}
+
+func TestExportJoin_Pushdown(t *testing.T) {
+	reader := newJoinTestReader()
+	reader.sqlRows = [][]string{{"1", "Alice"}, {"2", "Bob"}}
+	h := NewExportHelper(reader, reader, &mockValueConverter{}, NewPostgreSQLSchemaAdapter("sales"))
+	h.SetJoinPushdown(true)
+
+	packets, err := h.ExportTableWithQuery(context.Background(), "orders", joinTestQuery(), "", "")
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	want := `SELECT orders.id, customers.name FROM "sales"."orders" INNER JOIN "sales"."customers" ON orders.customer_id = customers.id`
+	if reader.lastSQL != want {
+		t.Errorf("SQL:\n%s\nwant:\n%s", reader.lastSQL, want)
+	}
+	if len(reader.readAll) != 0 {
+		t.Errorf("pushdown must not read tables: %v", reader.readAll)
+	}
+	qc := packets[0].QueryContext
+	if qc.ExecutionPlan.Strategy != packet.PlanPushdown || qc.ExecutionResults.RecordsReturned != 2 {
+		t.Errorf("plan %+v, results %+v", qc.ExecutionPlan, qc.ExecutionResults)
+	}
+	if f := packets[0].Schema.Fields; len(f) != 2 || f[1].Name != "customers.name" {
+		t.Errorf("schema = %+v", f)
+	}
+}
+
+func TestExportJoin_InMemory(t *testing.T) {
+	reader := newJoinTestReader()
+	h := NewExportHelper(reader, reader, &mockValueConverter{}, nil) // без SetJoinPushdown
+
+	query := joinTestQuery()
+	query.Join.Type = packet.JoinLeft
+	packets, err := h.ExportTableWithQuery(context.Background(), "orders", query, "", "")
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if reader.lastSQL != "" {
+		t.Errorf("SQL must not be executed: %s", reader.lastSQL)
+	}
+	rows := packets[0].GetRows()
+	want := [][]string{{"1", "Alice"}, {"2", "Bob"}, {"3", ""}}
+	if fmt.Sprint(rows) != fmt.Sprint(want) {
+		t.Errorf("rows = %v, want %v", rows, want)
+	}
+	plan := packets[0].QueryContext.ExecutionPlan
+	if plan.Strategy != packet.PlanMaterialize || !strings.HasPrefix(plan.Reason, "join in memory") {
+		t.Errorf("plan = %+v", plan)
+	}
+}
+
+func TestExportJoin_PushdownFailureFallsBack(t *testing.T) {
+	reader := newJoinTestReader()
+	reader.sqlErr = fmt.Errorf("syntax error")
+	h := NewExportHelper(reader, reader, &mockValueConverter{}, nil)
+	h.SetJoinPushdown(true)
+
+	packets, err := h.ExportTableWithQuery(context.Background(), "orders", joinTestQuery(), "", "")
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if len(packets[0].GetRows()) != 2 || len(reader.readAll) != 2 {
+		t.Errorf("rows %v, tables read %v", packets[0].GetRows(), reader.readAll)
+	}
+	if reason := packets[0].QueryContext.ExecutionPlan.Reason; !strings.Contains(reason, "SQL pushdown failed") {
+		t.Errorf("reason = %q", reason)
+	}
+}
+
+func TestExportJoin_Limits(t *testing.T) {
+	reader := newJoinTestReader()
+	reader.rowCount = 1000
+	h := NewExportHelper(reader, reader, &mockValueConverter{}, nil)
+	h.SetMaxFallbackRows(100)
+	if _, err := h.ExportTableWithQuery(context.Background(), "orders", joinTestQuery(), "", ""); err == nil || !strings.Contains(err.Error(), "fallback aborted") {
+		t.Errorf("err = %v, want fallback limit", err)
+	}
+
+	query := joinTestQuery()
+	query.After = []string{"1"}
+	if _, err := h.ExportTableWithQuery(context.Background(), "orders", query, "", ""); err == nil {
+		t.Error("After with Join must fail")
+	}
+
+	query = joinTestQuery()
+	query.Join.Table = "missing"
+	if _, err := h.ExportTableWithQuery(context.Background(), "orders", query, "", ""); err == nil || !strings.Contains(err.Error(), "join missing") {
+		t.Errorf("err = %v", err)
+	}
+}
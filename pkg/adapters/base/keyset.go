@@ -0,0 +1,123 @@
+package base
+
+import (
+	"fmt"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+// keysetQuery готовит постраничный запрос (Limit > 0) к keyset-пагинации.
+//
+// Запрос без ORDER BY по таблице с ключом сортируется по ключевым полям —
+// иначе порядок строк между страницами не определён и OFFSET-окна
+// перекрываются. Если задан query.After (ключ последней строки предыдущей
+// страницы, ExecutionResults.NextAfter), к фильтрам добавляется условие
+// «ключ больше After»: следующая страница начинается строго после уже
+// выданных строк, и вставки/удаления между запросами не сдвигают её,
+// в отличие от OFFSET.
+//
+// Возвращает запрос для выполнения (копию, исходный не меняется) и имена
+// ключевых полей; keys == nil — keyset не применяется. After без ключа,
+// вместе с ORDER BY, Offset или без Limit — ошибка.
+func keysetQuery(query *packet.Query, schema packet.Schema) (*packet.Query, []string, error) {
+	var keys []string
+	for _, f := range schema.Fields {
+		if f.Key {
+			keys = append(keys, f.Name)
+		}
+	}
+
+	if len(query.After) > 0 {
+		switch {
+		case query.Limit <= 0:
+			return nil, nil, fmt.Errorf("keyset pagination (After) requires a positive Limit")
+		case query.Offset > 0:
+			return nil, nil, fmt.Errorf("keyset pagination (After) cannot be combined with Offset")
+		case query.OrderBy != nil:
+			return nil, nil, fmt.Errorf("keyset pagination (After) orders by the primary key and cannot be combined with OrderBy")
+		case len(keys) == 0:
+			return nil, nil, fmt.Errorf("keyset pagination (After) requires a table with a primary key")
+		case len(query.After) != len(keys):
+			return nil, nil, fmt.Errorf("keyset pagination: After has %d value(s), primary key has %d field(s) %v",
+				len(query.After), len(keys), keys)
+		}
+	}
+	if query.Limit <= 0 || query.OrderBy != nil || len(keys) == 0 {
+		return query, nil, nil
+	}
+
+	q := *query
+	q.OrderBy = &packet.OrderBy{}
+	for _, k := range keys {
+		q.OrderBy.Fields = append(q.OrderBy.Fields, packet.OrderField{Name: k, Direction: "ASC"})
+	}
+	if len(q.After) > 0 {
+		q.Filters = andFilters(q.Filters, keysetCondition(keys, q.After))
+	}
+	return &q, keys, nil
+}
+
+// keysetCondition строит (k1, k2, ...) > (a1, a2, ...) без row value
+// сравнения (его нет в MSSQL и Oracle):
+// k1 > a1 OR (k1 = a1 AND k2 > a2) OR ...
+func keysetCondition(keys, after []string) packet.LogicalGroup {
+	var cond packet.LogicalGroup
+	for i := range keys {
+		step := packet.LogicalGroup{}
+		for j := 0; j < i; j++ {
+			step.Filters = append(step.Filters, packet.Filter{Field: keys[j], Operator: "eq", Value: after[j]})
+		}
+		step.Filters = append(step.Filters, packet.Filter{Field: keys[i], Operator: "gt", Value: after[i]})
+		if i == 0 {
+			cond.Filters = step.Filters
+		} else {
+			cond.And = append(cond.And, step)
+		}
+	}
+	return cond
+}
+
+// andFilters объединяет фильтры запроса с OR-группой cond через AND.
+func andFilters(filters *packet.Filters, cond packet.LogicalGroup) *packet.Filters {
+	root := packet.LogicalGroup{}
+	if len(cond.And) == 0 {
+		// Одно условие (ключ из одного поля) — без лишней группы
+		root.Filters = cond.Filters
+	} else {
+		root.Or = []packet.LogicalGroup{cond}
+	}
+	if filters != nil {
+		if filters.And != nil {
+			root.And = append(root.And, *filters.And)
+		}
+		if filters.Or != nil {
+			root.Or = append(root.Or, *filters.Or)
+		}
+	}
+	return &packet.Filters{And: &root}
+}
+
+// nextAfter — значения ключевых полей последней строки страницы
+// (ExecutionResults.NextAfter). nil — страница пуста или ключевых полей
+// нет среди выгруженных колонок (проекция Fields без ключа).
+func nextAfter(keys []string, schema packet.Schema, rows [][]string) []string {
+	if len(keys) == 0 || len(rows) == 0 {
+		return nil
+	}
+	last := rows[len(rows)-1]
+	values := make([]string, 0, len(keys))
+	for _, k := range keys {
+		idx := -1
+		for i, f := range schema.Fields {
+			if f.Name == k {
+				idx = i
+				break
+			}
+		}
+		if idx < 0 || idx >= len(last) {
+			return nil
+		}
+		values = append(values, last[idx])
+	}
+	return values
+}
@@ -0,0 +1,179 @@
+package base
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+	"github.com/ruslano69/tdtp-framework/pkg/core/schema"
+	"github.com/ruslano69/tdtp-framework/pkg/core/tdtql"
+)
+
+func pageQuery(limit int, after ...string) *packet.Query {
+	q := packet.NewQuery()
+	q.Limit = limit
+	q.After = after
+	return q
+}
+
+func TestKeysetQuery_OrdersByKey(t *testing.T) {
+	s := schema.NewBuilder().AddInteger("Region", true).AddInteger("ID", true).AddText("Name", 100).Build()
+	q, keys, err := keysetQuery(pageQuery(10, "2", "7"), s)
+	if err != nil {
+		t.Fatalf("keysetQuery: %v", err)
+	}
+	if !slices.Equal(keys, []string{"Region", "ID"}) {
+		t.Errorf("keys = %v", keys)
+	}
+
+	sql, err := tdtql.NewSQLGenerator().GenerateSQL("Users", q)
+	if err != nil {
+		t.Fatalf("GenerateSQL: %v", err)
+	}
+	want := "SELECT * FROM Users WHERE (Region > 2 OR (Region = 2 AND ID > 7)) ORDER BY Region ASC, ID ASC LIMIT 10"
+	if sql != want {
+		t.Errorf("SQL =\n%s\nwant\n%s", sql, want)
+	}
+}
+
+func TestKeysetQuery_KeepsFilters(t *testing.T) {
+	s := schema.NewBuilder().AddInteger("ID", true).AddText("Name", 100).Build()
+	query := pageQuery(5, "42")
+	query.Filters = &packet.Filters{Or: &packet.LogicalGroup{Filters: []packet.Filter{
+		{Field: "Name", Operator: "eq", Value: "a"},
+		{Field: "Name", Operator: "eq", Value: "b"},
+	}}}
+
+	q, _, err := keysetQuery(query, s)
+	if err != nil {
+		t.Fatalf("keysetQuery: %v", err)
+	}
+	sql, err := tdtql.NewSQLGenerator().GenerateSQL("Users", q)
+	if err != nil {
+		t.Fatalf("GenerateSQL: %v", err)
+	}
+	if !strings.Contains(sql, "WHERE ID > 42 AND (Name = 'a' OR Name = 'b')") {
+		t.Errorf("SQL = %s", sql)
+	}
+	if query.OrderBy != nil || query.Filters.And != nil {
+		t.Error("keysetQuery must not modify the caller's query")
+	}
+}
+
+func TestKeysetQuery_NotApplied(t *testing.T) {
+	withKey := schema.NewBuilder().AddInteger("ID", true).Build()
+	noKey := schema.NewBuilder().AddInteger("ID", false).Build()
+	ordered := pageQuery(10)
+	ordered.OrderBy = &packet.OrderBy{Field: "ID", Direction: "DESC"}
+
+	tests := []struct {
+		name   string
+		query  *packet.Query
+		schema packet.Schema
+	}{
+		{"no limit", pageQuery(0), withKey},
+		{"tail", pageQuery(-5), withKey},
+		{"custom order", ordered, withKey},
+		{"no key", pageQuery(10), noKey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, keys, err := keysetQuery(tt.query, tt.schema)
+			if err != nil {
+				t.Fatalf("keysetQuery: %v", err)
+			}
+			if q != tt.query || keys != nil {
+				t.Errorf("query rewritten: keys=%v", keys)
+			}
+		})
+	}
+}
+
+func TestKeysetQuery_InvalidAfter(t *testing.T) {
+	withKey := schema.NewBuilder().AddInteger("ID", true).Build()
+	withOffset := pageQuery(10, "1")
+	withOffset.Offset = 10
+	ordered := pageQuery(10, "1")
+	ordered.OrderBy = &packet.OrderBy{Field: "ID", Direction: "ASC"}
+
+	tests := []struct {
+		name   string
+		query  *packet.Query
+		schema packet.Schema
+	}{
+		{"no limit", pageQuery(0, "1"), withKey},
+		{"offset", withOffset, withKey},
+		{"order by", ordered, withKey},
+		{"no key", pageQuery(10, "1"), schema.NewBuilder().AddInteger("ID", false).Build()},
+		{"arity", pageQuery(10, "1", "2"), withKey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := keysetQuery(tt.query, tt.schema); err == nil {
+				t.Error("want error")
+			}
+		})
+	}
+}
+
+// Pushdown: страница после After — keyset-условие в SQL, NextAfter из
+// последней строки, MoreDataAvailable по полноте страницы, а не по COUNT(*).
+func TestExportHelper_KeysetPushdown(t *testing.T) {
+	reader := &mockDataReader{
+		rowsFromSQL: [][]string{{"11", "k"}, {"12", "l"}},
+		rowCount:    3, // COUNT(*) меньше Offset+returned — для keyset не важен
+	}
+	helper := buildFallbackTestHelper(reader)
+
+	packets, err := helper.ExportTableWithQuery(context.Background(), "Users", pageQuery(2, "10"), "test", "test")
+	if err != nil {
+		t.Fatalf("ExportTableWithQuery: %v", err)
+	}
+	if !strings.Contains(reader.lastSQL, "WHERE ID > 10 ORDER BY ID ASC LIMIT 2") {
+		t.Errorf("SQL = %s", reader.lastSQL)
+	}
+
+	qc := packets[0].QueryContext
+	res := qc.ExecutionResults
+	if !res.MoreDataAvailable || res.NextOffset != 0 || !slices.Equal(res.NextAfter, []string{"12"}) {
+		t.Errorf("results = %+v, want more data after [12]", res)
+	}
+	if qc.OriginalQuery.OrderBy != nil || qc.OriginalQuery.Filters != nil {
+		t.Errorf("OriginalQuery must be the client query, got %+v", qc.OriginalQuery)
+	}
+}
+
+// Источник без SQL: те же страницы в памяти — строки,
+// добавленные перед уже выданными ключами, не сдвигают следующую страницу.
+func TestExportHelper_KeysetFallback(t *testing.T) {
+	reader := &mockDataReader{
+		sqlErr:      ErrSQLUnsupported,
+		rowsFromAll: [][]string{{"3", "c"}, {"1", "a"}, {"2", "b"}, {"4", "d"}},
+	}
+	helper := buildFallbackTestHelper(reader)
+
+	packets, err := helper.ExportTableWithQuery(context.Background(), "Users", pageQuery(2), "test", "test")
+	if err != nil {
+		t.Fatalf("first page: %v", err)
+	}
+	res := packets[0].QueryContext.ExecutionResults
+	if !slices.Equal(res.NextAfter, []string{"2"}) {
+		t.Fatalf("NextAfter = %v, want [2]", res.NextAfter)
+	}
+
+	// Между запросами вставлена строка с меньшим ключом: OFFSET 2 вернул бы "2" повторно
+	reader.rowsFromAll = append(reader.rowsFromAll, []string{"0", "z"})
+	packets, err = helper.ExportTableWithQuery(context.Background(), "Users", pageQuery(2, res.NextAfter...), "test", "test")
+	if err != nil {
+		t.Fatalf("second page: %v", err)
+	}
+	rows := packets[0].GetRows()
+	if len(rows) != 2 || rows[0][0] != "3" || rows[1][0] != "4" {
+		t.Errorf("second page = %v, want ids 3, 4", rows)
+	}
+	if res := packets[0].QueryContext.ExecutionResults; res.MoreDataAvailable || res.NextAfter != nil {
+		t.Errorf("last page results = %+v", res)
+	}
+}
@@ -0,0 +1,33 @@
+package base
+
+import (
+	"context"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+// AwaitMaintenance ждёт окон обслуживания (adapters.MaintenanceWindows)
+// таблиц packets, если импорт разрушающий (destructive): заменяет или
+// очищает целевые таблицы. Delta-пакеты и пакеты удаления точечные и окна
+// не ждут. Для адаптеров с собственным импортом; вызывается до LockTables,
+// чтобы ожидание не держало блокировки.
+func AwaitMaintenance(ctx context.Context, windows *adapters.MaintenanceWindows, destructive bool, packets []*packet.DataPacket) error {
+	if windows == nil || !destructive {
+		return nil
+	}
+	var tables []string
+	for _, pkt := range packets {
+		if pkt != nil && !pkt.Data.Delta && !pkt.Data.Delete {
+			tables = append(tables, pkt.Header.TableName)
+		}
+	}
+	return windows.Await(ctx, tables...)
+}
+
+// awaitMaintenance — AwaitMaintenance с окнами ImportHelper: разрушающие
+// стратегии — замена через временную таблицу и StrategyTruncate.
+func (h *ImportHelper) awaitMaintenance(ctx context.Context, strategy adapters.ImportStrategy, packets []*packet.DataPacket) error {
+	destructive := strategy == adapters.StrategyTruncate || (h.useTemporaryTables && strategy == adapters.StrategyCopy)
+	return AwaitMaintenance(ctx, h.maintenance, destructive, packets)
+}
@@ -0,0 +1,74 @@
+package base
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+// closedWindows — окно sales открывается только 29 февраля в полночь.
+func closedWindows(t *testing.T) *adapters.MaintenanceWindows {
+	t.Helper()
+	m, err := adapters.NewMaintenanceWindows(map[string][]adapters.MaintenanceWindow{
+		"sales": {{Start: "0 0 29 2 *", Duration: time.Minute}},
+	}, adapters.MaintenanceFail, 0, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestImportHelper_MaintenanceWindow(t *testing.T) {
+	target := &truncateTarget{exists: true}
+	h := NewImportHelper(target, target, target, true)
+	h.SetMaintenanceWindows(closedWindows(t))
+
+	// Очистка вне окна — отказ до каких-либо операций с таблицей
+	err := h.ImportPackets(context.Background(), truncatePackets(), adapters.StrategyTruncate)
+	if !errors.Is(err, adapters.ErrOutsideMaintenanceWindow) {
+		t.Fatalf("err = %v, want ErrOutsideMaintenanceWindow", err)
+	}
+	if len(target.ops) != 0 {
+		t.Errorf("ops = %v, want none", target.ops)
+	}
+	if err := h.ImportPacket(context.Background(), truncatePackets()[0], adapters.StrategyCopy); !errors.Is(err, adapters.ErrOutsideMaintenanceWindow) {
+		t.Errorf("copy through a temporary table: err = %v, want ErrOutsideMaintenanceWindow", err)
+	}
+
+	// UPSERT таблицу не заменяет — окно не нужно
+	if err := h.ImportPackets(context.Background(), truncatePackets(), adapters.StrategyReplace); err != nil {
+		t.Fatalf("replace: %v", err)
+	}
+}
+
+func TestAwaitMaintenance(t *testing.T) {
+	m := closedWindows(t)
+	ctx := context.Background()
+	other := packet.NewDataPacket(packet.TypeReference, "customers")
+	deleted := truncatePackets()[0]
+	deleted.Data.Delete = true
+
+	if err := AwaitMaintenance(ctx, m, false, truncatePackets()); err != nil {
+		t.Errorf("non-destructive import: %v", err)
+	}
+	if err := AwaitMaintenance(ctx, m, true, []*packet.DataPacket{other, deleted}); err != nil {
+		t.Errorf("table without windows and delete packets: %v", err)
+	}
+	if err := AwaitMaintenance(ctx, nil, true, truncatePackets()); err != nil {
+		t.Errorf("no windows: %v", err)
+	}
+
+	open, err := adapters.NewMaintenanceWindows(map[string][]adapters.MaintenanceWindow{
+		"*": {{Start: "* * * * *", Duration: time.Hour}},
+	}, adapters.MaintenanceFail, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := AwaitMaintenance(ctx, open, true, truncatePackets()); err != nil {
+		t.Errorf("open window: %v", err)
+	}
+}
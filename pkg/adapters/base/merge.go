@@ -0,0 +1,62 @@
+package base
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+// UpdateFields возвращает имена полей схемы, которые UPSERT стратегии
+// strategy обновляет у существующей строки: StrategyReplace — все
+// неключевые поля, StrategyMerge — неключевые поля из
+// adapters.MergeColumnsFromContext (без списка — все неключевые), для
+// остальных стратегий — nil. Колонка списка, которой нет в пакете или
+// которая входит в ключ, — ошибка: молча пропущенное обновление хуже.
+func UpdateFields(ctx context.Context, s packet.Schema, strategy adapters.ImportStrategy) ([]string, error) {
+	if strategy != adapters.StrategyReplace && strategy != adapters.StrategyMerge {
+		return nil, nil
+	}
+	var allow []string
+	if strategy == adapters.StrategyMerge {
+		allow = adapters.MergeColumnsFromContext(ctx)
+	}
+	if len(allow) == 0 {
+		var names []string
+		for _, f := range s.Fields {
+			if !f.Key {
+				names = append(names, f.Name)
+			}
+		}
+		return names, nil
+	}
+
+	names := make([]string, 0, len(allow))
+	for _, col := range allow {
+		f := findField(&s, col)
+		switch {
+		case f == nil:
+			return nil, fmt.Errorf("merge column %s is not in the packet", col)
+		case f.Key:
+			return nil, fmt.Errorf("merge column %s is a key field", col)
+		}
+		names = append(names, f.Name)
+	}
+	return names, nil
+}
+
+// checkMerge проверяет список колонок StrategyMerge по схемам пакетов
+// данных до записи: ошибка в списке не должна обнаруживаться посреди
+// транзакции.
+func checkMerge(ctx context.Context, packets []*packet.DataPacket, strategy adapters.ImportStrategy) error {
+	if strategy != adapters.StrategyMerge {
+		return nil
+	}
+	for _, pkt := range packets {
+		if _, err := UpdateFields(ctx, pkt.Schema, strategy); err != nil {
+			return fmt.Errorf("%s: %w", pkt.Header.TableName, err)
+		}
+	}
+	return nil
+}
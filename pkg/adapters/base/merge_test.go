@@ -0,0 +1,42 @@
+package base
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+func TestUpdateFields(t *testing.T) {
+	schema := packet.Schema{Fields: []packet.Field{
+		{Name: "id", Type: "INTEGER", Key: true},
+		{Name: "Name", Type: "TEXT"},
+		{Name: "price", Type: "DECIMAL"},
+	}}
+	ctx := context.Background()
+
+	for _, strategy := range []adapters.ImportStrategy{adapters.StrategyReplace, adapters.StrategyMerge} {
+		got, err := UpdateFields(ctx, schema, strategy)
+		if err != nil || !reflect.DeepEqual(got, []string{"Name", "price"}) {
+			t.Errorf("%s: %v, %v", strategy, got, err)
+		}
+	}
+	if got, _ := UpdateFields(ctx, schema, adapters.StrategyIgnore); got != nil {
+		t.Errorf("ignore: %v", got)
+	}
+
+	merge := adapters.WithMergeColumns(ctx, "PRICE")
+	if got, err := UpdateFields(merge, schema, adapters.StrategyMerge); err != nil || !reflect.DeepEqual(got, []string{"price"}) {
+		t.Errorf("allowlist: %v, %v", got, err)
+	}
+	if got, _ := UpdateFields(merge, schema, adapters.StrategyReplace); len(got) != 2 {
+		t.Errorf("replace ignores the allowlist: %v", got)
+	}
+	for _, col := range []string{"qty", "id"} {
+		if _, err := UpdateFields(adapters.WithMergeColumns(ctx, col), schema, adapters.StrategyMerge); err == nil {
+			t.Errorf("merge column %s accepted", col)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package base
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+	"github.com/ruslano69/tdtp-framework/pkg/security"
+)
+
+// PacketKeyProvider выдаёт AES-256 ключи шифрования пакетов TDTP v1.5
+// (packet.EncryptSections) по Header.MessageID: у каждой части multi-part
+// экспорта свой MessageID и свой ключ. mercury.PacketKeys получает ключи
+// через xZMercury (BindKey/RetrieveKey), StaticPacketKey — общий ключ.
+type PacketKeyProvider interface {
+	// EncryptionKey возвращает ключ для шифрования пакета messageID.
+	EncryptionKey(ctx context.Context, messageID string) ([]byte, error)
+	// DecryptionKey возвращает ключ для расшифровки пакета messageID.
+	DecryptionKey(ctx context.Context, messageID string) ([]byte, error)
+}
+
+// StaticPacketKey — один 32-байтный ключ для всех пакетов (закрытый контур,
+// тесты). Ключ берётся из конфигурации, например crypto.ParseKey.
+type StaticPacketKey []byte
+
+// EncryptionKey реализует PacketKeyProvider.
+func (k StaticPacketKey) EncryptionKey(context.Context, string) ([]byte, error) {
+	return k, nil
+}
+
+// DecryptionKey реализует PacketKeyProvider.
+func (k StaticPacketKey) DecryptionKey(context.Context, string) ([]byte, error) {
+	return k, nil
+}
+
+// SealOptions — настройки финального шага экспорта (SealPackets).
+type SealOptions struct {
+	Compression packet.CompressionOptions // Enabled == false — без сжатия
+	Keys        PacketKeyProvider         // nil — без шифрования секций
+	Cipher      adapters.ColumnCipher     // nil — без расшифровки колонок at rest
+	Policy      *security.ExportPolicy    // nil — без политики выдачи колонок
+}
+
+// SealPackets готовит пакеты экспорта к передаче в фиксированном порядке
+// протокола: расшифровка колонок at rest (Cipher) → политика выдачи
+// колонок получателю (Policy; получатель — Header.Recipient, иначе
+// adapters.WithRecipient) → исключение колонок происхождения
+// (adapters.StripProvenance, кроме adapters.WithKeepProvenance) →
+// xxh3-хеши (только при шифровании) → сжатие → шифрование секций (Keys).
+func SealPackets(ctx context.Context, packets []*packet.DataPacket, opts SealOptions) error {
+	keepProvenance := adapters.KeepProvenance(ctx)
+	recipient := adapters.RecipientFromContext(ctx)
+	keys := opts.Keys
+	for _, pkt := range packets {
+		if pkt.Header.Recipient == "" {
+			pkt.Header.Recipient = recipient
+		}
+		changed := false
+		if opts.Cipher != nil {
+			decrypted, _, err := opts.Cipher.DecryptPacket(pkt)
+			if err != nil {
+				return fmt.Errorf("packet %s: column decryption: %w", pkt.Header.MessageID, err)
+			}
+			changed = decrypted > 0
+		}
+		if opts.Policy != nil {
+			var report security.PolicyReport
+			if err := opts.Policy.Apply(pkt, &report); err != nil {
+				return fmt.Errorf("packet %s: %w", pkt.Header.MessageID, err)
+			}
+			if len(report.Dropped) > 0 || report.MaskedValues > 0 {
+				changed = true
+			}
+		}
+		if !keepProvenance && adapters.StripProvenance(pkt) > 0 {
+			changed = true
+		}
+		if changed && pkt.Header.Checksum != nil {
+			// Сумма описывала строки до расшифровки, политики и с колонками происхождения
+			if err := packet.StampRowChecksum(pkt, pkt.Header.Checksum.Rows != ""); err != nil {
+				return err
+			}
+		}
+		if keys != nil {
+			// v1.5: потребитель сверяет хеши расшифрованных данных
+			if _, err := packet.ComputeIntegrity(pkt); err != nil {
+				return fmt.Errorf("packet %s: compute integrity: %w", pkt.Header.MessageID, err)
+			}
+		}
+		if err := packet.CompressPacketData(ctx, pkt, opts.Compression); err != nil {
+			return fmt.Errorf("packet %s: %w", pkt.Header.MessageID, err)
+		}
+		if keys == nil {
+			continue
+		}
+		key, err := keys.EncryptionKey(ctx, pkt.Header.MessageID)
+		if err != nil {
+			return fmt.Errorf("packet %s: encryption key: %w", pkt.Header.MessageID, err)
+		}
+		if err := packet.EncryptSections(pkt, key); err != nil {
+			return fmt.Errorf("packet %s: %w", pkt.Header.MessageID, err)
+		}
+	}
+	return nil
+}
+
+// OpenPacket обращает SealPackets для импорта: расшифровывает секции
+// (keys обязателен для зашифрованного пакета), сверяет xxh3-хеши
+// расшифрованного пакета, распаковывает данные, материализует строки и
+// сверяет их с Header.Checksum.
+func OpenPacket(ctx context.Context, pkt *packet.DataPacket, keys PacketKeyProvider) error {
+	encrypted := packet.IsEncrypted(pkt)
+	if encrypted {
+		if keys == nil {
+			return fmt.Errorf("packet %s is encrypted: no packet key provider configured", pkt.Header.MessageID)
+		}
+		key, err := keys.DecryptionKey(ctx, pkt.Header.MessageID)
+		if err != nil {
+			return fmt.Errorf("packet %s: decryption key: %w", pkt.Header.MessageID, err)
+		}
+		if err := packet.DecryptSections(pkt, key); err != nil {
+			return fmt.Errorf("packet %s: %w", pkt.Header.MessageID, err)
+		}
+	}
+
+	compressed := pkt.Data.Compression != ""
+	if err := packet.DecompressPacketData(ctx, pkt); err != nil {
+		return fmt.Errorf("packet %s: %w", pkt.Header.MessageID, err)
+	}
+	// Материализуем rawRows → Data.Rows если пакет пришёл из GenerateReference (fast-path).
+	pkt.MaterializeRows()
+
+	// Сумму строк сжатого пакета сверил DecompressPacketData
+	if !compressed && !pkt.Data.Compact {
+		if err := packet.VerifyRowChecksum(pkt); err != nil {
+			return err
+		}
+	}
+
+	if encrypted {
+		if err := packet.VerifyIntegrity(pkt); err != nil {
+			return fmt.Errorf("packet %s: %w", pkt.Header.MessageID, err)
+		}
+	}
+	return nil
+}
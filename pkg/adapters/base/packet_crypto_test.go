@@ -0,0 +1,91 @@
+package base
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+func TestSealOpenPackets_RoundTrip(t *testing.T) {
+	rows := [][]string{{"1", "Alice", "a@x", "10.50", "active"}, {"2", "Bob|Jr", "b@x", "0.00", ""}}
+	gen := packet.NewGenerator()
+	gen.SetMaxMessageSize(5100) // по пакету на строку: у каждой части свой ключ
+	packets, err := gen.GenerateReference("users", buildTestSchema(), rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(packets) != 2 {
+		t.Fatalf("expected 2 parts, got %d", len(packets))
+	}
+
+	keys := &recordingKeys{key: bytes.Repeat([]byte{7}, 32)}
+	ctx := context.Background()
+	if err := SealPackets(ctx, packets, SealOptions{Keys: keys}); err != nil {
+		t.Fatal(err)
+	}
+	if len(keys.bound) != 2 || keys.bound[0] == keys.bound[1] {
+		t.Errorf("expected one key per part, got %v", keys.bound)
+	}
+
+	var got [][]string
+	for _, pkt := range packets {
+		if !packet.IsEncrypted(pkt) || pkt.Version != "1.5" || pkt.XXH3 == "" {
+			t.Fatalf("packet not sealed: version=%s xxh3=%q", pkt.Version, pkt.XXH3)
+		}
+		xmlData, err := gen.ToXML(pkt, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bytes.Contains(xmlData, []byte("Alice")) {
+			t.Fatal("plaintext leaked into encrypted packet")
+		}
+		parsed, err := packet.NewParser().ParseBytes(xmlData)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := OpenPacket(ctx, parsed, nil); err == nil || !strings.Contains(err.Error(), "key provider") {
+			t.Fatalf("expected missing key provider error, got %v", err)
+		}
+		if err := OpenPacket(ctx, parsed, keys); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, parsed.GetRows()...)
+	}
+	if !reflect.DeepEqual(got, rows) {
+		t.Errorf("rows = %q, want %q", got, rows)
+	}
+}
+
+func TestOpenPacket_WrongKey(t *testing.T) {
+	packets, err := packet.NewGenerator().GenerateReference("users", buildTestSchema(), [][]string{{"1", "a", "b", "1.00", "x"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if err := SealPackets(ctx, packets, SealOptions{Keys: StaticPacketKey(bytes.Repeat([]byte{1}, 32))}); err != nil {
+		t.Fatal(err)
+	}
+	if err := OpenPacket(ctx, packets[0], StaticPacketKey(bytes.Repeat([]byte{2}, 32))); err == nil {
+		t.Error("expected error for wrong key")
+	}
+}
+
+// recordingKeys — PacketKeyProvider, запоминающий MessageID привязанных ключей.
+type recordingKeys struct {
+	key   []byte
+	bound []string
+}
+
+func (k *recordingKeys) EncryptionKey(_ context.Context, messageID string) ([]byte, error) {
+	k.bound = append(k.bound, messageID)
+	return k.key, nil
+}
+
+func (k *recordingKeys) DecryptionKey(context.Context, string) ([]byte, error) {
+	return k.key, nil
+}
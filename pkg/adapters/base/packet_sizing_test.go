@@ -0,0 +1,40 @@
+package base
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+)
+
+func TestExportHelper_PacketSizing(t *testing.T) {
+	reader := &mockDataReader{}
+	for i := range 25 {
+		reader.rowsFromAll = append(reader.rowsFromAll, []string{fmt.Sprint(i), "name"})
+	}
+	h := buildFallbackTestHelper(reader)
+	if err := h.SetPacketSizing(adapters.PacketSizing{MaxRows: 10, Estimate: "xml"}); err != nil {
+		t.Fatal(err)
+	}
+
+	packets, err := h.ExportTable(context.Background(), "users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(packets) != 3 {
+		t.Fatalf("got %d packets, want 3 (10+10+5 rows)", len(packets))
+	}
+	if n := len(packets[2].GetRows()); n != 5 {
+		t.Errorf("last packet has %d rows, want 5", n)
+	}
+}
+
+func TestExportHelper_PacketSizingInvalid(t *testing.T) {
+	h := buildFallbackTestHelper(&mockDataReader{})
+	for _, s := range []adapters.PacketSizing{{MaxBytes: -1}, {MaxRows: -5}, {Estimate: "utf8"}} {
+		if err := h.SetPacketSizing(s); err == nil {
+			t.Errorf("SetPacketSizing(%+v) = nil, want error", s)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package base
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+	"github.com/ruslano69/tdtp-framework/pkg/core/tdtql"
+)
+
+// RowStreamer — опциональный интерфейс DataReader для построчного чтения таблицы.
+// Если адаптер его реализует, запросы, не транслируемые в SQL, на больших
+// таблицах фильтруются потоком: в памяти остаются только совпавшие строки,
+// а не вся таблица. fn получает новый срез на каждую строку; ошибка из fn
+// прерывает чтение.
+type RowStreamer interface {
+	StreamAllRows(ctx context.Context, tableName string, schema packet.Schema, fn func(row []string) error) error
+}
+
+const (
+	// streamThresholdRows — таблицы не больше этого размера читаются целиком:
+	// выигрыш по памяти от потоковой фильтрации на них несущественен.
+	streamThresholdRows int64 = 50_000
+
+	// streamMaxSelectivity — при оценке «фильтры оставят ≥90% строк»
+	// потоковая фильтрация памяти почти не экономит.
+	streamMaxSelectivity = 0.9
+
+	// streamBatchRows — размер пачки строк, фильтруемой за один вызов executor'а.
+	streamBatchRows = 4096
+)
+
+// choosePlan — cost model ExportTableWithQuery.
+//
+// Pushdown всегда дешевле: фильтрация в СУБД, по сети идут только нужные
+// строки. Если запрос не транслируется, выбор между потоковой фильтрацией и
+// чтением всей таблицы зависит от размера таблицы (rowCount, -1 = неизвестен)
+// и оценки селективности: поток выгоден, когда таблица большая, а фильтры
+// отсекают заметную часть строк.
+func choosePlan(translatable, canStream bool, rowCount int64, selectivity float64) *packet.ExecutionPlan {
+	plan := &packet.ExecutionPlan{
+		EstimatedRows:    rowCount,
+		Selectivity:      selectivity,
+		EstimatedMatches: -1,
+	}
+	if rowCount >= 0 {
+		plan.EstimatedMatches = int64(float64(rowCount) * selectivity)
+	}
+
+	switch {
+	case translatable:
+		plan.Strategy = packet.PlanPushdown
+		plan.Reason = "query translates to SQL"
+	case !canStream:
+		plan.Strategy = packet.PlanMaterialize
+		plan.Reason = "adapter does not support row streaming"
+	case rowCount < 0:
+		plan.Strategy = packet.PlanStreamFilter
+		plan.Reason = "row count unknown"
+	case rowCount <= streamThresholdRows:
+		plan.Strategy = packet.PlanMaterialize
+		plan.Reason = fmt.Sprintf("small table (<= %d rows)", streamThresholdRows)
+	case selectivity >= streamMaxSelectivity:
+		plan.Strategy = packet.PlanMaterialize
+		plan.Reason = "filters keep most rows"
+	default:
+		plan.Strategy = packet.PlanStreamFilter
+		plan.Reason = "large table, selective filters"
+	}
+	return plan
+}
+
+// streamFilter читает таблицу через RowStreamer и оставляет только строки,
+// прошедшие фильтры запроса. Возвращает совпавшие строки и общее число
+// прочитанных строк.
+func streamFilter(
+	ctx context.Context,
+	streamer RowStreamer,
+	executor *tdtql.Executor,
+	tableName string,
+	schema packet.Schema,
+	filters *packet.Filters,
+) ([][]string, int, error) {
+	var matched [][]string
+	total := 0
+	batch := make([][]string, 0, streamBatchRows)
+
+	flush := func() error {
+		rows, err := executor.ExecuteWhere(filters, batch, schema)
+		if err != nil {
+			return fmt.Errorf("failed to execute query: %w", err)
+		}
+		matched = append(matched, rows...)
+		batch = batch[:0]
+		return nil
+	}
+
+	err := streamer.StreamAllRows(ctx, tableName, schema, func(row []string) error {
+		total++
+		batch = append(batch, row)
+		if len(batch) == streamBatchRows {
+			return flush()
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(batch) > 0 {
+		if err := flush(); err != nil {
+			return nil, 0, err
+		}
+	}
+	return matched, total, nil
+}
+
+// pushdownPlan — план pushdown: все фильтры в SQL, одна стадия sql с
+// оценкой строк ответа (совпадения, не больше Limit).
+func pushdownPlan(query *packet.Query, rowCount int64, selectivity float64) *packet.ExecutionPlan {
+	plan := choosePlan(true, false, rowCount, selectivity)
+	plan.Filters = tdtql.PlanFilters(query.Filters, packet.ExecSQL)
+	estimated := plan.EstimatedMatches
+	if query.Limit > 0 && (estimated < 0 || estimated > int64(query.Limit)) {
+		estimated = int64(query.Limit)
+	}
+	plan.Stages = []packet.PlanStage{{Name: packet.StageSQL, Where: packet.ExecSQL, EstimatedRows: estimated, ActualRows: -1}}
+	return plan
+}
+
+// implicitCastReason — причина фильтрации в памяти запроса с автоприведением
+// TEXT к числу (tdtql.Executor.CoerceTextFilters): CAST в SQL обработал бы
+// нечисловой текст иначе, чем фильтр в памяти.
+const implicitCastReason = "numeric comparison on a TEXT column"
+
+// memoryPlan — план выполнения в памяти (поток или чтение целиком); count —
+// стадия COUNT(*), если он выполнялся.
+func memoryPlan(query *packet.Query, canStream bool, rowCount int64, selectivity float64, count *packet.PlanStage) *packet.ExecutionPlan {
+	plan := choosePlan(false, canStream, rowCount, selectivity)
+	plan.Filters = tdtql.PlanFilters(query.Filters, packet.ExecMemory)
+	if count != nil {
+		plan.Stages = append(plan.Stages, *count)
+	}
+	return plan
+}
+
+// memoryStages — стадии плана в памяти без выполнения: чтение (поток с
+// фильтрами или вся таблица) и стадии tdtql.Executor.
+func memoryStages(plan *packet.ExecutionPlan, query *packet.Query) []packet.PlanStage {
+	if plan.Strategy == packet.PlanStreamFilter {
+		rest := *query
+		rest.Filters = nil
+		stream := packet.PlanStage{Name: packet.StageStream, Where: packet.ExecMemory, EstimatedRows: plan.EstimatedMatches, ActualRows: -1}
+		return append([]packet.PlanStage{stream}, tdtql.PlanStages(&rest, plan.EstimatedMatches)...)
+	}
+	read := packet.PlanStage{Name: packet.StageRead, Where: packet.ExecSQL, EstimatedRows: plan.EstimatedRows, ActualRows: -1}
+	return append([]packet.PlanStage{read}, tdtql.PlanStages(query, plan.EstimatedRows)...)
+}
+
+// countRows запрашивает размер таблицы, если он нужен для safety-net или
+// выбора между потоком и чтением целиком (-1 — не запрашивался или
+// неизвестен); count — стадия COUNT(*).
+func (h *ExportHelper) countRows(ctx context.Context, tableName string, canStream bool) (rowCount int64, count *packet.PlanStage) {
+	if h.maxFallbackRows <= 0 && !canStream {
+		return -1, nil
+	}
+	start := time.Now()
+	n, err := h.dataReader.GetRowCount(ctx, tableName)
+	if err != nil {
+		return -1, nil
+	}
+	count = &packet.PlanStage{Name: packet.StageCount, Where: packet.ExecSQL, EstimatedRows: -1}
+	count.Done(int(n), start)
+	return n, count
+}
+
+// generateResponse собирает Response пакеты и добавляет в план запроса
+// (если он есть) стадию generate.
+func (h *ExportHelper) generateResponse(
+	tableName string,
+	schema packet.Schema,
+	rows [][]string,
+	queryContext *packet.QueryContext,
+	sender, recipient string,
+) ([]*packet.DataPacket, error) {
+	start := time.Now()
+	packets, err := h.newGenerator().GenerateResponse(
+		tableName,
+		packet.InReplyToDirectExport,
+		schema,
+		rows,
+		queryContext,
+		sender,
+		recipient,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if queryContext != nil && queryContext.ExecutionPlan != nil {
+		queryContext.ExecutionPlan.AddStage(packet.StageGenerate, packet.ExecMemory, int64(len(rows)), len(rows), start)
+	}
+	return packets, nil
+}
@@ -0,0 +1,232 @@
+package base
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+	"github.com/ruslano69/tdtp-framework/pkg/core/schema"
+)
+
+// mockStreamingReader — DataReader с поддержкой RowStreamer.
+type mockStreamingReader struct {
+	mockDataReader
+	streamCalls int
+}
+
+func (m *mockStreamingReader) StreamAllRows(_ context.Context, _ string, _ packet.Schema, fn func(row []string) error) error {
+	m.streamCalls++
+	for _, row := range m.rowsFromAll {
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestChoosePlan(t *testing.T) {
+	tests := []struct {
+		name         string
+		translatable bool
+		canStream    bool
+		rowCount     int64
+		selectivity  float64
+		want         string
+	}{
+		{"translatable", true, true, 10_000_000, 0.01, packet.PlanPushdown},
+		{"no streamer", false, false, 10_000_000, 0.01, packet.PlanMaterialize},
+		{"small table", false, true, 1_000, 0.01, packet.PlanMaterialize},
+		{"large selective", false, true, 10_000_000, 0.01, packet.PlanStreamFilter},
+		{"large unselective", false, true, 10_000_000, 0.95, packet.PlanMaterialize},
+		{"unknown count", false, true, -1, 0.5, packet.PlanStreamFilter},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := choosePlan(tt.translatable, tt.canStream, tt.rowCount, tt.selectivity)
+			if plan.Strategy != tt.want {
+				t.Errorf("strategy = %s, want %s (reason: %s)", plan.Strategy, tt.want, plan.Reason)
+			}
+			if plan.Reason == "" {
+				t.Error("plan must explain its decision")
+			}
+		})
+	}
+}
+
+// Pushdown упал на большой таблице, адаптер умеет стримить → строки
+// фильтруются потоком, ReadAllRows не зовётся, решение видно в QueryContext.
+func TestExportHelper_StreamFilter_LargeTable(t *testing.T) {
+	reader := &mockStreamingReader{mockDataReader: mockDataReader{
+		sqlErr:      errors.New("mssql: Conversion failed"),
+		rowCount:    1_000_000,
+		rowsFromAll: [][]string{{"7", "Bob"}, {"42", "Alice"}, {"9", "Eve"}},
+	}}
+	s := schema.NewBuilder().AddInteger("ID", true).AddText("Name", 100).Build()
+	helper := NewExportHelper(&mockSchemaReader{schema: s}, reader, &mockValueConverter{}, nil)
+
+	packets, err := helper.ExportTableWithQuery(context.Background(), "Users", buildEqQuery(), "test", "test")
+	if err != nil {
+		t.Fatalf("ExportTableWithQuery failed: %v", err)
+	}
+	if reader.streamCalls != 1 || reader.readAllRowsCalls != 0 {
+		t.Fatalf("expected streaming read only, got stream=%d readAll=%d", reader.streamCalls, reader.readAllRowsCalls)
+	}
+
+	qc := packets[0].QueryContext
+	if qc == nil || qc.ExecutionPlan == nil {
+		t.Fatal("QueryContext must carry ExecutionPlan")
+	}
+	if qc.ExecutionPlan.Strategy != packet.PlanStreamFilter {
+		t.Errorf("strategy = %s, want %s", qc.ExecutionPlan.Strategy, packet.PlanStreamFilter)
+	}
+	if qc.ExecutionPlan.EstimatedRows != 1_000_000 {
+		t.Errorf("EstimatedRows = %d, want 1000000", qc.ExecutionPlan.EstimatedRows)
+	}
+	if qc.ExecutionResults.TotalRecordsInTable != 3 || qc.ExecutionResults.RecordsAfterFilters != 1 {
+		t.Errorf("unexpected execution results: %+v", qc.ExecutionResults)
+	}
+	if len(qc.OriginalQuery.Filters.And.Filters) != 1 {
+		t.Error("OriginalQuery must keep the filters applied while streaming")
+	}
+	if len(packets[0].Data.Rows) != 1 {
+		t.Errorf("expected 1 row, got %d", len(packets[0].Data.Rows))
+	}
+}
+
+// Pushdown успешен → план записан, COUNT(*) не запрашивался.
+func TestExportHelper_Pushdown_RecordsPlan(t *testing.T) {
+	reader := &mockDataReader{rowsFromSQL: [][]string{{"42", "Alice"}}}
+	helper := buildFallbackTestHelper(reader)
+
+	packets, err := helper.ExportTableWithQuery(context.Background(), "Users", buildEqQuery(), "test", "test")
+	if err != nil {
+		t.Fatalf("ExportTableWithQuery failed: %v", err)
+	}
+	plan := packets[0].QueryContext.ExecutionPlan
+	if plan == nil || plan.Strategy != packet.PlanPushdown {
+		t.Fatalf("expected pushdown plan, got %+v", plan)
+	}
+	if plan.EstimatedRows != -1 {
+		t.Errorf("EstimatedRows = %d, want -1 (not counted)", plan.EstimatedRows)
+	}
+}
+
+// Источник без SQL (ErrSQLUnsupported) фильтруется в памяти штатно —
+// без пометки о провале pushdown.
+func TestExportHelper_NoSQLSource_FiltersInMemory(t *testing.T) {
+	reader := &mockDataReader{
+		sqlErr:      ErrSQLUnsupported,
+		rowsFromAll: [][]string{{"42", "Alice"}, {"7", "Bob"}},
+	}
+	helper := buildFallbackTestHelper(reader)
+
+	packets, err := helper.ExportTableWithQuery(context.Background(), "users", buildEqQuery(), "test", "test")
+	if err != nil {
+		t.Fatalf("ExportTableWithQuery failed: %v", err)
+	}
+	if got := len(packets[0].Data.Rows); got != 1 {
+		t.Errorf("rows = %d, want 1", got)
+	}
+	plan := packets[0].QueryContext.ExecutionPlan
+	if plan == nil || plan.Strategy != packet.PlanMaterialize {
+		t.Fatalf("expected materialize plan, got %+v", plan)
+	}
+	if strings.Contains(plan.Reason, "pushdown failed") {
+		t.Errorf("plan reason %q reports a pushdown failure for a source without SQL", plan.Reason)
+	}
+}
+
+func planStageNames(plan *packet.ExecutionPlan) string {
+	names := make([]string, 0, len(plan.Stages))
+	for _, s := range plan.Stages {
+		names = append(names, s.Name+"@"+s.Where)
+	}
+	return strings.Join(names, ",")
+}
+
+// План экспорта фиксирует место каждого фильтра и фактические стадии.
+func TestExportHelper_PlanStages(t *testing.T) {
+	reader := &mockStreamingReader{mockDataReader: mockDataReader{
+		sqlErr:      errors.New("mssql: Conversion failed"),
+		rowCount:    1_000_000,
+		rowsFromAll: [][]string{{"7", "Bob"}, {"42", "Alice"}, {"9", "Eve"}},
+	}}
+	s := schema.NewBuilder().AddInteger("ID", true).AddText("Name", 100).Build()
+	helper := NewExportHelper(&mockSchemaReader{schema: s}, reader, &mockValueConverter{}, nil)
+
+	packets, err := helper.ExportTableWithQuery(context.Background(), "Users", buildEqQuery(), "test", "test")
+	if err != nil {
+		t.Fatalf("ExportTableWithQuery failed: %v", err)
+	}
+	plan := packets[0].QueryContext.ExecutionPlan
+	if len(plan.Filters) != 1 || plan.Filters[0].Where != packet.ExecMemory || plan.Filters[0].Field != "ID" {
+		t.Errorf("filters = %+v, want ID in memory", plan.Filters)
+	}
+	if got, want := planStageNames(plan), "count@sql,stream@memory,generate@memory"; got != want {
+		t.Fatalf("stages = %s, want %s", got, want)
+	}
+	if plan.Stages[0].ActualRows != 1_000_000 || plan.Stages[1].ActualRows != 1 {
+		t.Errorf("actual rows: %+v", plan.Stages)
+	}
+
+	// Pushdown: фильтр в SQL, стадия sql с фактическим числом строк.
+	sqlReader := &mockDataReader{rowsFromSQL: [][]string{{"42", "Alice"}}}
+	packets, err = buildFallbackTestHelper(sqlReader).ExportTableWithQuery(context.Background(), "Users", buildEqQuery(), "test", "test")
+	if err != nil {
+		t.Fatalf("ExportTableWithQuery failed: %v", err)
+	}
+	plan = packets[0].QueryContext.ExecutionPlan
+	if len(plan.Filters) != 1 || plan.Filters[0].Where != packet.ExecSQL {
+		t.Errorf("filters = %+v, want pushed down", plan.Filters)
+	}
+	if got, want := planStageNames(plan), "sql@sql,generate@memory"; got != want {
+		t.Fatalf("stages = %s, want %s", got, want)
+	}
+	if plan.Stages[0].ActualRows != 1 || plan.SQL != "" {
+		t.Errorf("sql stage %+v, plan SQL %q", plan.Stages[0], plan.SQL)
+	}
+}
+
+// Explain не читает строк: для pushdown — SQL запроса, для запроса, не
+// транслируемого в SQL, — стратегия в памяти и оценки стадий.
+func TestExportHelper_Explain(t *testing.T) {
+	reader := &mockDataReader{rowCount: 5000}
+	helper := buildFallbackTestHelper(reader)
+	helper.SetMaxFallbackRows(1000)
+
+	plan, err := helper.Explain(context.Background(), "Users", buildEqQuery())
+	if err != nil {
+		t.Fatalf("Explain: %v", err)
+	}
+	if plan.Strategy != packet.PlanPushdown || !strings.Contains(plan.SQL, "WHERE") {
+		t.Errorf("plan = %+v, want pushdown with SQL", plan)
+	}
+	if reader.readSQLCalls+reader.readAllRowsCalls+reader.getRowCountCalls != 0 {
+		t.Errorf("Explain must not read the table: %+v", reader)
+	}
+
+	// CAST к TEXT в SQL не транслируется.
+	query := packet.NewQuery()
+	query.Filters = &packet.Filters{And: &packet.LogicalGroup{
+		Filters: []packet.Filter{{Field: "ID", Operator: "eq", Value: "42", Cast: "TEXT"}},
+	}}
+	query.Limit = 10
+	plan, err = helper.Explain(context.Background(), "Users", query)
+	if err != nil {
+		t.Fatalf("Explain: %v", err)
+	}
+	if plan.Strategy != packet.PlanMaterialize || !strings.Contains(plan.Reason, "fallback row limit") {
+		t.Errorf("plan = %+v, want materialize over the fallback limit", plan)
+	}
+	if got, want := planStageNames(plan), "count@sql,read@sql,filter@memory,sort@memory,paginate@memory"; got != want {
+		t.Errorf("stages = %s, want %s", got, want)
+	}
+	if last := plan.Stages[len(plan.Stages)-1]; last.EstimatedRows != 10 || last.ActualRows != -1 {
+		t.Errorf("paginate stage = %+v", last)
+	}
+	if reader.readAllRowsCalls != 0 {
+		t.Error("Explain must not read rows")
+	}
+}
@@ -0,0 +1,385 @@
+package base
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+	"time"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+)
+
+// OpenDB открывает пул драйвера driverName как sql.Open, но все выражения
+// пула проходят через журнал log (adapters.Config.QueryLog). Выключенный
+// журнал стоит одной атомарной загрузки на выражение.
+func OpenDB(driverName, dsn string, log *adapters.QueryLogger) (*sql.DB, error) {
+	probe, err := sql.Open(driverName, dsn)
+	if err != nil {
+		return nil, err
+	}
+	drv := probe.Driver()
+	_ = probe.Close()
+
+	var connector driver.Connector = dsnConnector{dsn: dsn, drv: drv}
+	if dc, ok := drv.(driver.DriverContext); ok {
+		if connector, err = dc.OpenConnector(dsn); err != nil {
+			return nil, err
+		}
+	}
+	return sql.OpenDB(LogConnector(connector, log)), nil
+}
+
+// LogConnector оборачивает connector журналом log (nil — без журнала).
+func LogConnector(connector driver.Connector, log *adapters.QueryLogger) driver.Connector {
+	if log == nil {
+		return connector
+	}
+	return &loggedConnector{connector: connector, log: log}
+}
+
+// dsnConnector — driver.Connector для драйверов без driver.DriverContext.
+type dsnConnector struct {
+	dsn string
+	drv driver.Driver
+}
+
+func (c dsnConnector) Connect(context.Context) (driver.Conn, error) { return c.drv.Open(c.dsn) }
+func (c dsnConnector) Driver() driver.Driver                        { return c.drv }
+
+type loggedConnector struct {
+	connector driver.Connector
+	log       *adapters.QueryLogger
+}
+
+func (c *loggedConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &loggedConn{conn: conn, log: c.log}, nil
+}
+
+func (c *loggedConnector) Driver() driver.Driver {
+	return c.connector.Driver()
+}
+
+// Close закрывает исходный connector, если он это умеет (sql.DB.Close).
+func (c *loggedConnector) Close() error {
+	if closer, ok := c.connector.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// loggedConn пропускает вызовы к соединению драйвера, замеряя выражения.
+// Необязательные интерфейсы драйвера, которых у соединения нет, ведут себя
+// как их отсутствие (driver.ErrSkip и значения database/sql по умолчанию).
+type loggedConn struct {
+	conn driver.Conn
+	log  *adapters.QueryLogger
+}
+
+func (c *loggedConn) Prepare(query string) (driver.Stmt, error) {
+	stmt, err := c.conn.Prepare(query)
+	if err != nil {
+		return nil, err
+	}
+	return &loggedStmt{stmt: stmt, conn: c, query: query}, nil
+}
+
+func (c *loggedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var stmt driver.Stmt
+	var err error
+	if pc, ok := c.conn.(driver.ConnPrepareContext); ok {
+		stmt, err = pc.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &loggedStmt{stmt: stmt, conn: c, query: query}, nil
+}
+
+func (c *loggedConn) Close() error {
+	return c.conn.Close()
+}
+
+func (c *loggedConn) Begin() (driver.Tx, error) {
+	return c.conn.Begin() //nolint:staticcheck // driver.Conn требует Begin
+}
+
+func (c *loggedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if bt, ok := c.conn.(driver.ConnBeginTx); ok {
+		return bt.BeginTx(ctx, opts)
+	}
+	// Как database/sql для драйверов без ConnBeginTx
+	if opts.Isolation != driver.IsolationLevel(sql.LevelDefault) {
+		return nil, errors.New("sql: driver does not support non-default isolation level")
+	}
+	if opts.ReadOnly {
+		return nil, errors.New("sql: driver does not support read-only transactions")
+	}
+	return c.conn.Begin() //nolint:staticcheck // драйвер без ConnBeginTx
+}
+
+func (c *loggedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	ex, ok := c.conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	if !c.log.Enabled() {
+		return ex.ExecContext(ctx, query, args)
+	}
+	start := time.Now()
+	res, err := ex.ExecContext(ctx, query, args)
+	if errors.Is(err, driver.ErrSkip) {
+		return nil, err // database/sql повторит через Prepare — замер там
+	}
+	c.log.Log(ctx, adapters.QueryEvent{Statement: query, Args: namedArgs(args), Duration: time.Since(start), Rows: affected(res), Err: err})
+	return res, err
+}
+
+func (c *loggedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	q, ok := c.conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	if !c.log.Enabled() {
+		return q.QueryContext(ctx, query, args)
+	}
+	start := time.Now()
+	rows, err := q.QueryContext(ctx, query, args)
+	if errors.Is(err, driver.ErrSkip) {
+		return nil, err
+	}
+	return c.loggedRows(ctx, rows, query, namedArgs(args), start, err)
+}
+
+// loggedRows пишет запрос в журнал при закрытии курсора: время экспорта —
+// это в основном чтение строк, а не ответ на запрос.
+func (c *loggedConn) loggedRows(ctx context.Context, rows driver.Rows, query string, args []any, start time.Time, err error) (driver.Rows, error) {
+	if err != nil {
+		c.log.Log(ctx, adapters.QueryEvent{Statement: query, Args: args, Duration: time.Since(start), Rows: -1, Err: err})
+		return nil, err
+	}
+	return &loggedRowsCursor{rows: rows, ctx: ctx, log: c.log, query: query, args: args, start: start}, nil
+}
+
+func (c *loggedConn) Ping(ctx context.Context) error {
+	if p, ok := c.conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *loggedConn) ResetSession(ctx context.Context) error {
+	if r, ok := c.conn.(driver.SessionResetter); ok {
+		return r.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *loggedConn) IsValid() bool {
+	if v, ok := c.conn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+	return true
+}
+
+func (c *loggedConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if nvc, ok := c.conn.(driver.NamedValueChecker); ok {
+		return nvc.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+type loggedStmt struct {
+	stmt  driver.Stmt
+	conn  *loggedConn
+	query string
+}
+
+func (s *loggedStmt) Close() error  { return s.stmt.Close() }
+func (s *loggedStmt) NumInput() int { return s.stmt.NumInput() }
+
+func (s *loggedStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.stmt.Exec(args) //nolint:staticcheck // driver.Stmt требует Exec
+}
+
+func (s *loggedStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.stmt.Query(args) //nolint:staticcheck // driver.Stmt требует Query
+}
+
+func (s *loggedStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	exec := func() (driver.Result, error) {
+		if se, ok := s.stmt.(driver.StmtExecContext); ok {
+			return se.ExecContext(ctx, args)
+		}
+		values, err := plainValues(args)
+		if err != nil {
+			return nil, err
+		}
+		return s.stmt.Exec(values) //nolint:staticcheck // драйвер без StmtExecContext
+	}
+	if !s.conn.log.Enabled() {
+		return exec()
+	}
+	start := time.Now()
+	res, err := exec()
+	s.conn.log.Log(ctx, adapters.QueryEvent{Statement: s.query, Args: namedArgs(args), Duration: time.Since(start), Rows: affected(res), Err: err})
+	return res, err
+}
+
+func (s *loggedStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	query := func() (driver.Rows, error) {
+		if sq, ok := s.stmt.(driver.StmtQueryContext); ok {
+			return sq.QueryContext(ctx, args)
+		}
+		values, err := plainValues(args)
+		if err != nil {
+			return nil, err
+		}
+		return s.stmt.Query(values) //nolint:staticcheck // драйвер без StmtQueryContext
+	}
+	if !s.conn.log.Enabled() {
+		return query()
+	}
+	start := time.Now()
+	rows, err := query()
+	return s.conn.loggedRows(ctx, rows, s.query, namedArgs(args), start, err)
+}
+
+// CheckNamedValue — проверка выражения, иначе соединения: обёртка выражения
+// реализует интерфейс всегда, и database/sql не спросит соединение сам.
+func (s *loggedStmt) CheckNamedValue(nv *driver.NamedValue) error {
+	if nvc, ok := s.stmt.(driver.NamedValueChecker); ok {
+		return nvc.CheckNamedValue(nv)
+	}
+	return s.conn.CheckNamedValue(nv)
+}
+
+func (s *loggedStmt) ColumnConverter(idx int) driver.ValueConverter {
+	if cc, ok := s.stmt.(driver.ColumnConverter); ok { //nolint:staticcheck // старые драйверы
+		return cc.ColumnConverter(idx)
+	}
+	return driver.DefaultParameterConverter
+}
+
+// loggedRowsCursor считает прочитанные строки и пишет запрос в журнал при Close.
+type loggedRowsCursor struct {
+	rows  driver.Rows
+	ctx   context.Context
+	log   *adapters.QueryLogger
+	query string
+	args  []any
+	start time.Time
+	n     int64
+	err   error
+	done  bool
+}
+
+func (r *loggedRowsCursor) Columns() []string { return r.rows.Columns() }
+
+func (r *loggedRowsCursor) Next(dest []driver.Value) error {
+	err := r.rows.Next(dest)
+	switch {
+	case err == nil:
+		r.n++
+	case !errors.Is(err, io.EOF):
+		r.err = err
+	}
+	return err
+}
+
+func (r *loggedRowsCursor) Close() error {
+	err := r.rows.Close()
+	if !r.done {
+		r.done = true
+		r.log.Log(r.ctx, adapters.QueryEvent{Statement: r.query, Args: r.args, Duration: time.Since(r.start), Rows: r.n, Err: r.err})
+	}
+	return err
+}
+
+func (r *loggedRowsCursor) HasNextResultSet() bool {
+	if rs, ok := r.rows.(driver.RowsNextResultSet); ok {
+		return rs.HasNextResultSet()
+	}
+	return false
+}
+
+func (r *loggedRowsCursor) NextResultSet() error {
+	if rs, ok := r.rows.(driver.RowsNextResultSet); ok {
+		return rs.NextResultSet()
+	}
+	return io.EOF
+}
+
+func (r *loggedRowsCursor) ColumnTypeScanType(index int) reflect.Type {
+	if ct, ok := r.rows.(driver.RowsColumnTypeScanType); ok {
+		return ct.ColumnTypeScanType(index)
+	}
+	return reflect.TypeFor[any]()
+}
+
+func (r *loggedRowsCursor) ColumnTypeDatabaseTypeName(index int) string {
+	if ct, ok := r.rows.(driver.RowsColumnTypeDatabaseTypeName); ok {
+		return ct.ColumnTypeDatabaseTypeName(index)
+	}
+	return ""
+}
+
+func (r *loggedRowsCursor) ColumnTypeLength(index int) (int64, bool) {
+	if ct, ok := r.rows.(driver.RowsColumnTypeLength); ok {
+		return ct.ColumnTypeLength(index)
+	}
+	return 0, false
+}
+
+func (r *loggedRowsCursor) ColumnTypeNullable(index int) (nullable, ok bool) {
+	if ct, has := r.rows.(driver.RowsColumnTypeNullable); has {
+		return ct.ColumnTypeNullable(index)
+	}
+	return false, false
+}
+
+func (r *loggedRowsCursor) ColumnTypePrecisionScale(index int) (precision, scale int64, ok bool) {
+	if ct, has := r.rows.(driver.RowsColumnTypePrecisionScale); has {
+		return ct.ColumnTypePrecisionScale(index)
+	}
+	return 0, 0, false
+}
+
+func namedArgs(args []driver.NamedValue) []any {
+	out := make([]any, len(args))
+	for i, a := range args {
+		out[i] = a.Value
+	}
+	return out
+}
+
+func plainValues(args []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(args))
+	for i, a := range args {
+		if a.Name != "" {
+			return nil, errors.New("sql: driver does not support the use of Named Parameters")
+		}
+		values[i] = a.Value
+	}
+	return values, nil
+}
+
+// affected — число затронутых строк для журнала (-1 — неизвестно).
+func affected(res driver.Result) int64 {
+	if res == nil {
+		return -1
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return -1
+	}
+	return n
+}
@@ -0,0 +1,105 @@
+package base
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+)
+
+func init() {
+	sql.Register("querylog-fake", fakeDriver{})
+}
+
+// fakeDriver — драйвер без необязательных интерфейсов выполнения: всё идёт
+// через Prepare, как у старых ODBC-драйверов.
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) { return fakeConn{}, nil }
+
+type fakeConn struct{}
+
+func (fakeConn) Prepare(query string) (driver.Stmt, error) { return fakeStmt{query: query}, nil }
+func (fakeConn) Close() error                              { return nil }
+func (fakeConn) Begin() (driver.Tx, error)                 { return fakeTx{}, nil }
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+type fakeStmt struct{ query string }
+
+func (s fakeStmt) Close() error  { return nil }
+func (s fakeStmt) NumInput() int { return -1 }
+
+func (s fakeStmt) Exec([]driver.Value) (driver.Result, error) {
+	return driver.RowsAffected(3), nil
+}
+
+func (s fakeStmt) Query([]driver.Value) (driver.Rows, error) {
+	return &fakeRows{left: 2}, nil
+}
+
+type fakeRows struct{ left int }
+
+func (r *fakeRows) Columns() []string { return []string{"id"} }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.left == 0 {
+		return io.EOF
+	}
+	r.left--
+	dest[0] = int64(r.left)
+	return nil
+}
+
+func TestOpenDB_LogsStatements(t *testing.T) {
+	var buf bytes.Buffer
+	log := adapters.NewQueryLogger(adapters.QueryLogConfig{})
+	db, err := OpenDB("querylog-fake", "", log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	ctx := adapters.WithMessageID(context.Background(), "MSG-7")
+
+	// Выключенный журнал ничего не пишет
+	if _, err := db.ExecContext(ctx, "DELETE FROM t"); err != nil {
+		t.Fatal(err)
+	}
+	log.SetConfig(adapters.QueryLogConfig{Enabled: true, Output: &buf})
+
+	if _, err := db.ExecContext(ctx, "UPDATE t SET name = ?", "secret"); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := db.QueryContext(ctx, "SELECT id FROM t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := 0
+	for rows.Next() {
+		n++
+	}
+	_ = rows.Close()
+	if n != 2 {
+		t.Fatalf("read %d rows, want 2", n)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 log lines, got %q", buf.String())
+	}
+	if !strings.Contains(lines[0], "msg=MSG-7 rows=3 args=1(redacted) | UPDATE t SET name = ?") || strings.Contains(lines[0], "secret") {
+		t.Errorf("exec line = %q", lines[0])
+	}
+	if !strings.Contains(lines[1], "msg=MSG-7 rows=2 | SELECT id FROM t") {
+		t.Errorf("query line = %q", lines[1])
+	}
+}
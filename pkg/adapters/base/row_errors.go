@@ -0,0 +1,185 @@
+package base
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+	"github.com/ruslano69/tdtp-framework/pkg/core/schema"
+)
+
+// SetRowErrorPolicy задаёт политику ошибок строк: с RowErrorsSkip и
+// RowErrorsDeadLetter строки, значения которых converter не приводит к
+// типам полей (dbType — как в ConvertRowToSQLValues), убираются из пакета
+// до записи и попадают в отчёт ImportPacketsReport.
+func (h *ImportHelper) SetRowErrorPolicy(cfg adapters.RowErrorConfig, converter *UniversalTypeConverter, dbType string) {
+	h.rowErrors = cfg
+	h.converter = converter
+	h.dbType = dbType
+}
+
+// rejectRows учитывает пакеты данных в отчёте и по политике ошибок строк
+// убирает из них строки, которые не пройдут преобразование в InsertRows.
+func (h *ImportHelper) rejectRows(packets []*packet.DataPacket, report *adapters.ImportReport) error {
+	return RejectPacketRows(packets, h.rowErrors, h.converter, h.dbType, report)
+}
+
+// RejectPacketRows — политика ошибок строк cfg для адаптеров с собственным
+// импортом (postgres, mssql): учитывает пакеты данных в report и убирает из
+// них строки, которые converter не приводит к типам полей; с
+// RowErrorsDeadLetter отклонённые строки пишутся в карантин.
+func RejectPacketRows(packets []*packet.DataPacket, cfg adapters.RowErrorConfig, converter *UniversalTypeConverter, dbType string, report *adapters.ImportReport) error {
+	check := cfg.Policy != adapters.RowErrorsFailFast && converter != nil
+	for _, pkt := range packets {
+		if pkt == nil {
+			continue
+		}
+		table := pkt.Header.TableName
+		rep := reportTable(report, table)
+		rep.Packets++
+		if !check {
+			rep.Rows += len(pkt.Data.Rows)
+			continue
+		}
+
+		rejected, err := RejectRows(pkt, rep.Packets, converter, dbType, rep)
+		if err != nil {
+			return err
+		}
+		rep.Rows += len(pkt.Data.Rows)
+		if len(rejected) == 0 {
+			continue
+		}
+		fmt.Printf("  ⚠ %s: packet %s: %d row(s) rejected\n", table, pkt.Header.MessageID, len(rejected))
+		if cfg.Policy == adapters.RowErrorsDeadLetter {
+			path, err := WriteDeadLetter(cfg.DeadLetterDir, pkt, rejected)
+			if err != nil {
+				return err
+			}
+			rep.DeadLetters = append(rep.DeadLetters, path)
+		}
+	}
+	return nil
+}
+
+// reportTable — отчёт таблицы table (создаётся при первом обращении).
+func reportTable(report *adapters.ImportReport, table string) *adapters.TableImportReport {
+	for _, t := range report.Tables {
+		if t.Table == table {
+			return t
+		}
+	}
+	t := &adapters.TableImportReport{Table: table}
+	report.Tables = append(report.Tables, t)
+	return t
+}
+
+// RejectRows убирает из пакета строки, значения которых не приводятся к
+// типам полей схемы пакета, и возвращает их; причины — в rep (pktNum —
+// номер пакета для RowError). Delta-пакеты и пакеты удаления не меняются.
+func RejectRows(pkt *packet.DataPacket, pktNum int, converter *UniversalTypeConverter, dbType string, rep *adapters.TableImportReport) ([]packet.Row, error) {
+	if pkt.Data.Delta || pkt.Data.Delete {
+		return nil, nil
+	}
+	pkt.MaterializeRows()
+	if err := packet.ExpandCompactRows(pkt); err != nil {
+		return nil, err
+	}
+	kept := pkt.Data.Rows[:0:0]
+	var rejected []packet.Row
+	for r, row := range pkt.Data.Rows {
+		values := ParseRowValues(row)
+		if _, err := ConvertRowToSQLValues(values, pkt.Schema, converter, dbType); err == nil {
+			kept = append(kept, row)
+			continue
+		}
+		rejected = append(rejected, row)
+		rep.AddRowError(rowError(values, pkt.Schema, converter, dbType, pktNum, r+1))
+	}
+	if len(rejected) > 0 {
+		pkt.Data.Rows = kept
+		pkt.Header.RecordsInPart = len(kept)
+		pkt.Header.Checksum = nil // строки сверены в OpenPacket, сумма больше не про них
+	}
+	return rejected, nil
+}
+
+// rowError — первое значение строки, не прошедшее преобразование.
+func rowError(values []string, s packet.Schema, converter *UniversalTypeConverter, dbType string, pktNum, rowNum int) adapters.RowError {
+	e := adapters.RowError{Packet: pktNum, Row: rowNum}
+	if len(values) != len(s.Fields) {
+		e.Err = fmt.Sprintf("expected %d values, got %d", len(s.Fields), len(values))
+		return e
+	}
+	for i, f := range s.Fields {
+		if msg := valueError(values[i], f, converter, dbType); msg != "" {
+			e.Field, e.Value, e.Err = f.Name, values[i], msg
+			return e
+		}
+	}
+	e.Err = "row does not convert to column types"
+	return e
+}
+
+// valueError — почему value не приводится к типу поля f ("" — приводится).
+func valueError(value string, f packet.Field, converter *UniversalTypeConverter, dbType string) string {
+	one := packet.Schema{Fields: []packet.Field{f}}
+	_, err := ConvertRowToSQLValues([]string{value}, one, converter, dbType)
+	if err == nil {
+		return ""
+	}
+	var verr *schema.ValidationError
+	if errors.As(err, &verr) {
+		return verr.Message // поле и значение уже в RowError
+	}
+	return err.Error()
+}
+
+// WriteDeadLetter пишет строки rows пакета pkt карантинным пакетом
+// <таблица>_<MessageID>.rejected.tdtp.xml в каталог dir и возвращает путь
+// (первой части, если строк больше, чем помещается в один пакет).
+// Схема — схема пакета, строки — как в исходном пакете: после исправления
+// пакет импортируется обычным образом.
+func WriteDeadLetter(dir string, pkt *packet.DataPacket, rows []packet.Row) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create dead-letter directory: %w", err)
+	}
+	values := make([][]string, len(rows))
+	for i, row := range rows {
+		values[i] = ParseRowValues(row)
+	}
+	parts, err := packet.NewGenerator().GenerateReference(pkt.Header.TableName, pkt.Schema, values)
+	if err != nil {
+		return "", fmt.Errorf("failed to build dead-letter packet: %w", err)
+	}
+	name := strings.NewReplacer("/", "_", "\\", "_", ":", "_").
+		Replace(pkt.Header.TableName + "_" + pkt.Header.MessageID)
+	var first string
+	for i, part := range parts {
+		path := filepath.Join(dir, name+".rejected.tdtp.xml")
+		if len(parts) > 1 {
+			path = filepath.Join(dir, fmt.Sprintf("%s.rejected.tdtp_part_%d_of_%d.xml", name, i+1, len(parts)))
+		}
+		if err := packet.NewGenerator().WriteToFile(part, path); err != nil {
+			return "", fmt.Errorf("failed to write dead-letter packet: %w", err)
+		}
+		if i == 0 {
+			first = path
+		}
+	}
+	return first, nil
+}
+
+// ImportPacketsReport — ImportPackets с отчётом по таблицам: число пакетов
+// и записанных строк, строки, отклонённые политикой SetRowErrorPolicy, и
+// карантинные пакеты. Реализует adapters.ReportingImporter для адаптеров
+// на ImportHelper.
+func (h *ImportHelper) ImportPacketsReport(ctx context.Context, packets []*packet.DataPacket, strategy adapters.ImportStrategy) (*adapters.ImportReport, error) {
+	report := &adapters.ImportReport{Strategy: strategy}
+	return report, h.importPackets(ctx, packets, strategy, report)
+}
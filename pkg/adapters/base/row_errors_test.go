@@ -0,0 +1,92 @@
+package base
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+func rowErrorsPacket(t *testing.T) *packet.DataPacket {
+	t.Helper()
+	schema := packet.Schema{Fields: []packet.Field{
+		{Name: "id", Type: "INTEGER", Key: true},
+		{Name: "born", Type: "DATE"},
+	}}
+	packets, err := packet.NewGenerator().GenerateReference("users", schema, [][]string{
+		{"1", "1990-05-01"}, {"x", "1990-05-01"}, {"3", "31.02.1990"}, {"4", ""},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	packets[0].MaterializeRows() // как после OpenPacket
+	return packets[0]
+}
+
+func TestRejectRows_DeadLetter(t *testing.T) {
+	dir := t.TempDir()
+	h := &ImportHelper{}
+	h.SetRowErrorPolicy(adapters.RowErrorConfig{Policy: adapters.RowErrorsDeadLetter, DeadLetterDir: dir},
+		NewUniversalTypeConverter(), "sqlite")
+
+	pkt := rowErrorsPacket(t)
+	report := &adapters.ImportReport{}
+	if err := h.rejectRows([]*packet.DataPacket{pkt}, report); err != nil {
+		t.Fatal(err)
+	}
+	if got := pkt.GetRows(); !reflect.DeepEqual(got, [][]string{{"1", "1990-05-01"}, {"4", ""}}) {
+		t.Errorf("kept rows %v", got)
+	}
+	rep := report.Tables[0]
+	if rep.Packets != 1 || rep.Rows != 2 || rep.RowErrorCount != 2 || pkt.Header.RecordsInPart != 2 {
+		t.Errorf("report %+v", rep)
+	}
+	if e := rep.RowErrors[0]; e.Row != 2 || e.Field != "id" || e.Value != "x" {
+		t.Errorf("row error %+v", e)
+	}
+	if e := rep.RowErrors[1]; e.Row != 3 || e.Field != "born" {
+		t.Errorf("row error %+v", e)
+	}
+
+	if len(rep.DeadLetters) != 1 {
+		t.Fatalf("dead letters %v", rep.DeadLetters)
+	}
+	quarantined, err := packet.NewParser().ParseFile(rep.DeadLetters[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := quarantined.GetRows(); !reflect.DeepEqual(got, [][]string{{"x", "1990-05-01"}, {"3", "31.02.1990"}}) ||
+		quarantined.Header.TableName != "users" || len(quarantined.Schema.Fields) != 2 {
+		t.Errorf("quarantine packet %s: %v", quarantined.Header.TableName, got)
+	}
+}
+
+func TestRejectRows_FailFast(t *testing.T) {
+	h := &ImportHelper{}
+	pkt := rowErrorsPacket(t)
+	report := &adapters.ImportReport{}
+	if err := h.rejectRows([]*packet.DataPacket{pkt}, report); err != nil {
+		t.Fatal(err)
+	}
+	if len(pkt.Data.Rows) != 4 || report.Tables[0].Rows != 4 || report.Tables[0].RowErrorCount != 0 {
+		t.Errorf("fail-fast changed the packet: %d rows, report %+v", len(pkt.Data.Rows), report.Tables[0])
+	}
+	if err := (adapters.RowErrorConfig{Policy: adapters.RowErrorsDeadLetter}).Validate(); err == nil {
+		t.Error("dead-letter without a directory accepted")
+	}
+}
+
+// RejectPacketRows — та же политика для адаптеров без ImportHelper
+// (postgres, mssql); nil-пакеты пропускаются, как в их ImportPackets.
+func TestRejectPacketRows_Skip(t *testing.T) {
+	pkt := rowErrorsPacket(t)
+	report := &adapters.ImportReport{}
+	cfg := adapters.RowErrorConfig{Policy: adapters.RowErrorsSkip}
+	if err := RejectPacketRows([]*packet.DataPacket{nil, pkt}, cfg, NewUniversalTypeConverter(), "mssql", report); err != nil {
+		t.Fatal(err)
+	}
+	if rep := report.Tables[0]; len(report.Tables) != 1 || rep.Rows != 2 || rep.RowErrorCount != 2 || len(rep.DeadLetters) != 0 {
+		t.Errorf("report %+v", rep)
+	}
+}
@@ -0,0 +1,72 @@
+package base
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+)
+
+// SQLExecer — *sql.Tx (или обёртка над транзакцией другого драйвера),
+// в которой выполняются операторы точек сохранения.
+type SQLExecer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// SavepointDialect — SQL точек сохранения диалекта (%s — имя точки), общий
+// для реализаций adapters.SavepointTx в адаптерах. Без release —
+// точки освобождаются только вместе с транзакцией: ReleaseSavepoint
+// ничего не выполняет.
+type SavepointDialect struct {
+	save, rollbackTo, release string
+}
+
+var (
+	// StandardSavepoints — SAVEPOINT по стандарту SQL: SQLite, PostgreSQL, MySQL
+	StandardSavepoints = SavepointDialect{
+		save:       "SAVEPOINT %s",
+		rollbackTo: "ROLLBACK TO SAVEPOINT %s",
+		release:    "RELEASE SAVEPOINT %s",
+	}
+
+	// MSSQLSavepoints — SAVE TRANSACTION / ROLLBACK TRANSACTION (MS SQL Server)
+	MSSQLSavepoints = SavepointDialect{
+		save:       "SAVE TRANSACTION %s",
+		rollbackTo: "ROLLBACK TRANSACTION %s",
+	}
+
+	// OracleSavepoints — Oracle не знает RELEASE SAVEPOINT
+	OracleSavepoints = SavepointDialect{
+		save:       "SAVEPOINT %s",
+		rollbackTo: "ROLLBACK TO SAVEPOINT %s",
+	}
+)
+
+// Savepoint создаёт точку сохранения name в транзакции tx.
+func (d SavepointDialect) Savepoint(ctx context.Context, tx SQLExecer, name string) error {
+	return d.exec(ctx, tx, d.save, "create", name)
+}
+
+// RollbackTo откатывает транзакцию tx к точке сохранения name.
+func (d SavepointDialect) RollbackTo(ctx context.Context, tx SQLExecer, name string) error {
+	return d.exec(ctx, tx, d.rollbackTo, "roll back to", name)
+}
+
+// ReleaseSavepoint освобождает точку сохранения name.
+func (d SavepointDialect) ReleaseSavepoint(ctx context.Context, tx SQLExecer, name string) error {
+	return d.exec(ctx, tx, d.release, "release", name)
+}
+
+func (d SavepointDialect) exec(ctx context.Context, tx SQLExecer, stmt, op, name string) error {
+	if err := adapters.ValidateSavepointName(name); err != nil {
+		return err
+	}
+	if stmt == "" {
+		return nil
+	}
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf(stmt, name)); err != nil {
+		return fmt.Errorf("failed to %s savepoint %s: %w", op, name, err)
+	}
+	return nil
+}
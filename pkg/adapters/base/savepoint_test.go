@@ -0,0 +1,144 @@
+package base
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+// recordingTx — adapters.SavepointTx на диалекте d, запоминающий SQL.
+type recordingTx struct {
+	d    SavepointDialect
+	sql  []string
+	fail string // оператор, который завершается ошибкой
+}
+
+func (t *recordingTx) ExecContext(_ context.Context, query string, _ ...any) (sql.Result, error) {
+	t.sql = append(t.sql, query)
+	if query == t.fail {
+		return nil, errors.New("exec failed")
+	}
+	return nil, nil
+}
+
+func (t *recordingTx) Commit(context.Context) error {
+	t.sql = append(t.sql, "COMMIT")
+	return nil
+}
+func (t *recordingTx) Rollback(context.Context) error {
+	t.sql = append(t.sql, "ROLLBACK")
+	return nil
+}
+func (t *recordingTx) Savepoint(ctx context.Context, name string) error {
+	return t.d.Savepoint(ctx, t, name)
+}
+func (t *recordingTx) RollbackTo(ctx context.Context, name string) error {
+	return t.d.RollbackTo(ctx, t, name)
+}
+func (t *recordingTx) ReleaseSavepoint(ctx context.Context, name string) error {
+	return t.d.ReleaseSavepoint(ctx, t, name)
+}
+
+func TestNested(t *testing.T) {
+	ctx := context.Background()
+	fnErr := errors.New("packet rejected")
+
+	tx := &recordingTx{d: StandardSavepoints}
+	if err := adapters.Nested(ctx, tx, "pkt_1", func(context.Context) error { return fnErr }); !errors.Is(err, fnErr) {
+		t.Fatalf("err = %v", err)
+	}
+	if err := adapters.Nested(ctx, tx, "pkt_2", func(context.Context) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"SAVEPOINT pkt_1", "ROLLBACK TO SAVEPOINT pkt_1", "RELEASE SAVEPOINT pkt_1",
+		"SAVEPOINT pkt_2", "RELEASE SAVEPOINT pkt_2",
+	}
+	if !reflect.DeepEqual(tx.sql, want) {
+		t.Errorf("standard: %q", tx.sql)
+	}
+
+	// MS SQL Server: точки освобождаются только с транзакцией
+	tx = &recordingTx{d: MSSQLSavepoints, fail: "ROLLBACK TRANSACTION pkt_1"}
+	err := adapters.Nested(ctx, tx, "pkt_1", func(context.Context) error { return fnErr })
+	if !errors.Is(err, fnErr) || err.Error() == fnErr.Error() {
+		t.Errorf("rollback failure not reported: %v", err)
+	}
+	if want := []string{"SAVE TRANSACTION pkt_1", "ROLLBACK TRANSACTION pkt_1"}; !reflect.DeepEqual(tx.sql, want) {
+		t.Errorf("mssql: %q", tx.sql)
+	}
+
+	if err := tx.Savepoint(ctx, "x; DROP TABLE t"); err == nil || len(tx.sql) != 2 {
+		t.Errorf("unsafe name executed: %v %q", err, tx.sql)
+	}
+	if err := adapters.Savepoint(ctx, noSavepointTx{}, "a"); !errors.Is(err, adapters.ErrSavepointsUnsupported) {
+		t.Errorf("plain Tx: %v", err)
+	}
+}
+
+type noSavepointTx struct{}
+
+func (noSavepointTx) Commit(context.Context) error   { return nil }
+func (noSavepointTx) Rollback(context.Context) error { return nil }
+
+// nestedTarget — цель ImportHelper с транзакцией на точках сохранения:
+// InsertRows пишет в транзакцию импорта и отвергает пакет со строкой "bad".
+type nestedTarget struct {
+	tx *recordingTx
+}
+
+func (f *nestedTarget) TableExists(context.Context, string) (bool, error)        { return true, nil }
+func (f *nestedTarget) CreateTable(context.Context, string, packet.Schema) error { return nil }
+func (f *nestedTarget) DropTable(context.Context, string) error                  { return nil }
+func (f *nestedTarget) RenameTable(context.Context, string, string) error        { return nil }
+func (f *nestedTarget) BeginTx(context.Context) (adapters.Tx, error)             { return f.tx, nil }
+func (f *nestedTarget) InsertRows(ctx context.Context, _ string, _ packet.Schema, rows []packet.Row, _ adapters.ImportStrategy) error {
+	if ImportTx(ctx) != f.tx {
+		return errors.New("insert outside the import transaction")
+	}
+	f.tx.sql = append(f.tx.sql, "INSERT "+rows[0].Value)
+	if rows[0].Value == "bad" {
+		return errors.New("constraint violated")
+	}
+	return nil
+}
+
+// С PartialRollback ImportPackets откатывает только пакет с ошибкой СУБД.
+func TestImportPackets_PartialRollback(t *testing.T) {
+	target := &nestedTarget{tx: &recordingTx{d: StandardSavepoints}}
+	h := NewImportHelper(target, target, target, false)
+	h.SetRowErrorPolicy(adapters.RowErrorConfig{PartialRollback: true}, nil, "sqlite")
+
+	packets := truncatePackets()
+	packets[1].Data.Rows = []packet.Row{{Value: "bad"}}
+	packets = append(packets, truncatePackets()[0])
+	report, err := h.ImportPacketsReport(context.Background(), packets, adapters.StrategyReplace)
+	if err != nil {
+		t.Fatalf("ImportPacketsReport: %v", err)
+	}
+	want := []string{
+		"SAVEPOINT tdtp_packet_1", "INSERT 1", "RELEASE SAVEPOINT tdtp_packet_1",
+		"SAVEPOINT tdtp_packet_2", "INSERT bad", "ROLLBACK TO SAVEPOINT tdtp_packet_2", "RELEASE SAVEPOINT tdtp_packet_2",
+		"SAVEPOINT tdtp_packet_3", "INSERT 1", "RELEASE SAVEPOINT tdtp_packet_3",
+		"COMMIT",
+	}
+	if !reflect.DeepEqual(target.tx.sql, want) {
+		t.Errorf("sql = %q", target.tx.sql)
+	}
+	rep := report.Tables[0]
+	if rep.Rows != 2 || rep.RolledBackRows != 1 || len(rep.RolledBack) != 1 {
+		t.Errorf("report = %+v", rep)
+	}
+
+	// Без точек сохранения импорт откатывается целиком
+	h = NewImportHelper(&truncateTarget{exists: true}, &truncateTarget{}, &truncateTarget{}, false)
+	h.SetRowErrorPolicy(adapters.RowErrorConfig{PartialRollback: true}, nil, "sqlite")
+	if err := h.ImportPackets(context.Background(), truncatePackets(), adapters.StrategyReplace); !errors.Is(err, adapters.ErrSavepointsUnsupported) {
+		t.Errorf("plain Tx: err = %v", err)
+	}
+}
@@ -12,14 +12,30 @@ import (
 // dbType must match the converter's dbType parameter (e.g. "mssql", "sqlite", "mysql").
 // This eliminates the duplicated scanRows pattern across sql-based adapters.
 func ScanSQLRows(rows *sql.Rows, schema packet.Schema, converter *UniversalTypeConverter, dbType string) ([][]string, error) {
+	var result [][]string
+	err := StreamSQLRows(rows, schema, converter, dbType, func(row []string) error {
+		result = append(result, row)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// StreamSQLRows is the row-at-a-time form of ScanSQLRows: each converted row is
+// passed to fn instead of being accumulated. fn owns the row slice (a fresh one
+// per row). A non-nil error from fn stops the scan and is returned as is.
+func StreamSQLRows(rows *sql.Rows, schema packet.Schema, converter *UniversalTypeConverter, dbType string, fn func(row []string) error) error {
 	columnCount := len(schema.Fields)
 	values := make([]any, columnCount)
 	valuePtrs := make([]any, columnCount)
 
-	// For SQLite DATE/DATETIME/TIMESTAMP columns scan into *string to skip
-	// modernc.parseTime (iterates format list per cell, ~450ms for 100k rows).
-	// Python sqlite3 returns raw strings the same way — no format guessing.
-	strBufs := make([]string, columnCount)
+	// For SQLite DATE/DATETIME/TIMESTAMP columns scan into *sql.NullString to
+	// skip modernc.parseTime (iterates format list per cell, ~450ms for 100k
+	// rows). Python sqlite3 returns raw strings the same way — no format
+	// guessing. NULL cells go through the converter like any other NULL.
+	strBufs := make([]sql.NullString, columnCount)
 	dtMask := make([]bool, columnCount) // true = scan as string, skip parseTime
 	if dbType == "sqlite" {
 		for i, f := range schema.Fields {
@@ -36,23 +52,26 @@ func ScanSQLRows(rows *sql.Rows, schema packet.Schema, converter *UniversalTypeC
 		}
 	}
 
-	var result [][]string
 	for rows.Next() {
 		if err := rows.Scan(valuePtrs...); err != nil {
-			return nil, fmt.Errorf("failed to scan row: %w", err)
+			return fmt.Errorf("failed to scan row: %w", err)
 		}
 		row := make([]string, columnCount)
 		for i, field := range schema.Fields {
-			if dtMask[i] {
-				row[i] = normalizeSQLiteDateTime(strBufs[i], field.Type)
+			if dtMask[i] && strBufs[i].Valid {
+				row[i] = normalizeSQLiteDateTime(strBufs[i].String, field.Type)
+			} else if dtMask[i] {
+				row[i] = converter.ConvertValueToTDTP(field, converter.DBValueToString(nil, field, dbType))
 			} else {
 				raw := converter.DBValueToString(values[i], field, dbType)
 				row[i] = converter.ConvertValueToTDTP(field, raw)
 			}
 		}
-		result = append(result, row)
+		if err := fn(row); err != nil {
+			return err
+		}
 	}
-	return result, rows.Err()
+	return rows.Err()
 }
 
 // isSQLiteDateType returns true for SQLite date/time column types.
@@ -52,9 +52,10 @@ func (a *StandardSQLAdapter) AdaptSQL(standardSQL, tableName string, schema pack
 	return sql
 }
 
-// PostgreSQLSchemaAdapter реализует SQLAdapter для PostgreSQL с non-public схемой.
-// Квалифицирует имя таблицы: "schema"."table".
-// Используется когда schema != "public" (т.к. SQLGenerator генерирует только имя таблицы).
+// PostgreSQLSchemaAdapter реализует SQLAdapter для PostgreSQL со схемами.
+// Квалифицирует имя таблицы: "schema"."table". Схема берётся из имени
+// ("sales.orders" — экспорт таблицы другой схемы), иначе — схема адаптера.
+// Неквалифицированная таблица в public остаётся как есть (search_path).
 type PostgreSQLSchemaAdapter struct {
 	schema string
 }
@@ -64,10 +65,81 @@ func NewPostgreSQLSchemaAdapter(schema string) *PostgreSQLSchemaAdapter {
 	return &PostgreSQLSchemaAdapter{schema: schema}
 }
 
-// AdaptSQL квалифицирует имя таблицы в FROM clause добавляя schema prefix с quoted identifiers.
+// AdaptSQL квалифицирует имя таблицы в FROM clause (и присоединённой таблицы
+// в JOIN) добавляя schema prefix с quoted identifiers.
 func (a *PostgreSQLSchemaAdapter) AdaptSQL(standardSQL, tableName string, schema packet.Schema, query *packet.Query) string {
-	quotedTable := fmt.Sprintf(`"%s"."%s"`, a.schema, tableName) //nolint:gocritic // SQL identifier quoting, not Go string quoting
-	return strings.Replace(standardSQL, " FROM "+tableName+" ", " FROM "+quotedTable+" ", 1)
+	sql := standardSQL
+	if quotedTable, ok := a.qualify(tableName); ok {
+		sql = replaceFromTable(sql, tdtql.QuoteTableName(tableName), quotedTable)
+	}
+	if query != nil && query.Join != nil {
+		if quotedTable, ok := a.qualify(query.Join.Table); ok {
+			sql = replaceTableAfter(sql, " JOIN ", tdtql.QuoteTableName(query.Join.Table), quotedTable)
+		}
+	}
+	return sql
+}
+
+// SupportsILike — LIKE без учёта регистра транслируется в ILIKE.
+func (a *PostgreSQLSchemaAdapter) SupportsILike() bool {
+	return true
+}
+
+// qualify возвращает "schema"."table"; ok == false — имя остаётся как есть
+// (неквалифицированная таблица в public).
+func (a *PostgreSQLSchemaAdapter) qualify(tableName string) (string, bool) {
+	schemaName, table, qualified := SplitQualifiedName(tableName)
+	if !qualified {
+		schemaName = a.schema
+	}
+	if schemaName == "" || (schemaName == "public" && !qualified) {
+		return "", false
+	}
+	return QuotePGIdentifier(schemaName) + "." + QuotePGIdentifier(table), true
+}
+
+// SplitQualifiedName разбирает "schema.table" (MSSQL-скобки и ANSI-кавычки
+// снимаются). qualified == false — схема в имени не указана.
+func SplitQualifiedName(name string) (schema, table string, qualified bool) {
+	name = tdtql.StripBrackets(name)
+	if s, t, ok := strings.Cut(name, "."); ok && s != "" && t != "" {
+		return unquoteIdent(s), unquoteIdent(t), true
+	}
+	return "", unquoteIdent(name), false
+}
+
+// unquoteIdent снимает ANSI-кавычки с идентификатора ("a""b" → a"b).
+func unquoteIdent(s string) string {
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		return strings.ReplaceAll(s[1:len(s)-1], `""`, `"`)
+	}
+	return s
+}
+
+// QuotePGIdentifier квотирует идентификатор PostgreSQL (регистр сохраняется).
+func QuotePGIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// replaceFromTable заменяет таблицу в первом FROM clause — только целым
+// токеном, чтобы "orders" не задел "orders_archive".
+func replaceFromTable(sql, from, to string) string {
+	return replaceTableAfter(sql, " FROM ", from, to)
+}
+
+// replaceTableAfter заменяет таблицу после первого keyword (" FROM ",
+// " JOIN ") — только целым токеном.
+func replaceTableAfter(sql, keyword, from, to string) string {
+	needle := keyword + from
+	idx := strings.Index(sql, needle)
+	if idx < 0 {
+		return sql
+	}
+	end := idx + len(needle)
+	if end < len(sql) && sql[end] != ' ' {
+		return sql
+	}
+	return sql[:idx] + keyword + to + sql[end:]
 }
 
 // MSSQLAdapter реализует SQLAdapter для MS SQL Server
@@ -94,23 +166,36 @@ func NewMSSQLAdapter(schemaName string) *MSSQLAdapter {
 // 4. Квалифицирует имена таблиц: [schema].[table]  (поддержка "schema.table" формата)
 // 5. Квалифицирует имена полей: [field]
 func (a *MSSQLAdapter) AdaptSQL(standardSQL, tableName string, schema packet.Schema, query *packet.Query) string {
-	// Поддержка формата "schema.table" в tableName (например, "dbo.Users")
+	// Поддержка формата "schema.table" в tableName (например, "dbo.Users"),
+	// а также "db.schema.table", "db..table" и "server.db.schema.table"
+	// (другая БД, linked server, синонимы на них)
 	tableName = tdtql.StripBrackets(tableName)
-	schemaName := a.schemaName
-	table := tableName
-	if parts := strings.SplitN(tableName, ".", 2); len(parts) == 2 {
-		schemaName = parts[0]
-		table = parts[1]
-	}
-
-	// Квалифицируем имя таблицы: [schema].[table]
-	// GenerateSQL wraps names with special chars in ANSI double-quotes (e.g. "ZTR$Timesheet Line").
-	// Replace the ANSI-quoted form first to avoid partial substring corruption.
-	fullTableName := fmt.Sprintf("[%s].[%s]", schemaName, table)
-	ansiTable := `"` + strings.ReplaceAll(table, `"`, `""`) + `"`
-	sql := strings.Replace(standardSQL, ansiTable, fullTableName, 1)
+	parts := strings.Split(tableName, ".")
+	if len(parts) == 1 {
+		parts = []string{a.schemaName, tableName}
+	}
+	table := parts[len(parts)-1]
+
+	// Квалифицируем имя таблицы: [schema].[table], [db].[schema].[table], ...
+	// Пустая часть ("db..table" — схема по умолчанию) остаётся пустой.
+	quoted := make([]string, len(parts))
+	for i, p := range parts {
+		if p != "" {
+			quoted[i] = "[" + strings.ReplaceAll(p, "]", "]]") + "]"
+		}
+	}
+	fullTableName := strings.Join(quoted, ".")
+
+	// GenerateSQL квотирует имя через QuoteTableName (части со спецсимволами —
+	// ANSI double-quotes, e.g. "ZTR$Timesheet Line"). Заменяем эту форму целиком
+	// во FROM, чтобы не задеть подстроки; иначе — прежний поиск по имени.
+	sql := replaceFromTable(standardSQL, tdtql.QuoteTableName(tableName), fullTableName)
 	if sql == standardSQL {
-		sql = strings.Replace(standardSQL, tableName, fullTableName, 1)
+		ansiTable := `"` + strings.ReplaceAll(table, `"`, `""`) + `"`
+		sql = strings.Replace(standardSQL, ansiTable, fullTableName, 1)
+		if sql == standardSQL {
+			sql = strings.Replace(standardSQL, tableName, fullTableName, 1)
+		}
 	}
 
 	// Квалифицируем имена полей квадратными скобками.
@@ -297,3 +382,208 @@ func firstProjectedColumn(sql string) string {
 	}
 	return first
 }
+
+// isoDatetimeOracle matches ISO 8601 datetime literals ('2024-01-15T10:30:00Z',
+// fractional seconds and 'Z' optional). Oracle parses string literals through
+// NLS_DATE_FORMAT, so they are rewritten as ANSI TIMESTAMP literals.
+var isoDatetimeOracle = regexp.MustCompile(`'(\d{4}-\d{2}-\d{2})T(\d{2}:\d{2}:\d{2}(?:\.\d+)?)Z?'`)
+
+// OracleSQLAdapter реализует SQLAdapter для Oracle Database.
+// Пагинация зависит от режима совместимости: OFFSET/FETCH (12c+)
+// или вложенные запросы с ROWNUM (11g).
+type OracleSQLAdapter struct {
+	owner       string // схема (владелец) по умолчанию
+	offsetFetch bool   // true — OFFSET/FETCH (12c+), false — ROWNUM (11g)
+}
+
+// NewOracleSQLAdapter создает OracleSQLAdapter для схемы owner.
+func NewOracleSQLAdapter(owner string, offsetFetch bool) *OracleSQLAdapter {
+	return &OracleSQLAdapter{owner: owner, offsetFetch: offsetFetch}
+}
+
+// AdaptSQL адаптирует стандартный SQL под Oracle:
+// 1. Квалифицирует таблицу: "OWNER"."TABLE"
+// 2. Квотирует поля схемы со строчными буквами (иначе Oracle переведёт их в верхний регистр)
+// 3. ISO datetime литералы → TIMESTAMP 'YYYY-MM-DD HH:MM:SS'
+// 4. LIMIT/OFFSET → OFFSET/FETCH (12c+) или ROWNUM (11g)
+// 5. "AS _tail" → "tail_": AS перед псевдонимом таблицы и "_" в начале
+// имени Oracle не принимает
+func (a *OracleSQLAdapter) AdaptSQL(standardSQL, tableName string, schema packet.Schema, query *packet.Query) string {
+	owner, table := SplitOracleName(tableName, a.owner)
+	fullTableName := QuoteOracleIdentifier(table)
+	if owner != "" {
+		fullTableName = QuoteOracleIdentifier(owner) + "." + fullTableName
+	}
+	sql := replaceFromTable(standardSQL, tdtql.QuoteTableName(tableName), fullTableName)
+
+	sql = quoteOracleFields(sql, schema)
+	sql = isoDatetimeOracle.ReplaceAllString(sql, "TIMESTAMP '$1 $2'")
+	sql = strings.Replace(sql, ") AS _tail ", ") tail_ ", 1)
+
+	if query == nil || (query.Limit == 0 && query.Offset == 0) {
+		return sql
+	}
+
+	limit := query.Limit
+	if limit < 0 {
+		limit = -limit // tail mode: sql_generator уже развернул сортировку
+	}
+	limitPattern := fmt.Sprintf(" LIMIT %d", limit)
+
+	// Tail с ORDER BY: SELECT * FROM (<inner> LIMIT n) tail_ ORDER BY ...
+	// Ограничивается только внутренний запрос.
+	const tailPrefix = "SELECT * FROM ("
+	if end := strings.LastIndex(sql, ") tail_ "); query.Limit < 0 && end > 0 && strings.HasPrefix(sql, tailPrefix) {
+		inner := strings.Replace(sql[len(tailPrefix):end], limitPattern, "", 1)
+		return tailPrefix + a.paginate(inner, limit, 0, schema) + sql[end:]
+	}
+
+	if limit > 0 {
+		sql = strings.Replace(sql, limitPattern, "", 1)
+	}
+	if query.Offset > 0 {
+		sql = strings.Replace(sql, fmt.Sprintf(" OFFSET %d", query.Offset), "", 1)
+	}
+	return a.paginate(sql, limit, query.Offset, schema)
+}
+
+// paginate ограничивает результат запроса: limit строк (0 — все) после offset.
+func (a *OracleSQLAdapter) paginate(sql string, limit, offset int, schema packet.Schema) string {
+	if a.offsetFetch {
+		if offset > 0 {
+			sql += fmt.Sprintf(" OFFSET %d ROWS", offset)
+			if limit > 0 {
+				sql += fmt.Sprintf(" FETCH NEXT %d ROWS ONLY", limit)
+			}
+		} else if limit > 0 {
+			sql += fmt.Sprintf(" FETCH FIRST %d ROWS ONLY", limit)
+		}
+		return sql
+	}
+
+	// 11g: ROWNUM присваивается до ORDER BY, поэтому запрос оборачивается
+	if offset == 0 {
+		return fmt.Sprintf("SELECT * FROM (%s) WHERE ROWNUM <= %d", sql, limit)
+	}
+	inner := fmt.Sprintf("SELECT q_.*, ROWNUM rn_ FROM (%s) q_", sql)
+	if limit > 0 {
+		inner += fmt.Sprintf(" WHERE ROWNUM <= %d", offset+limit)
+	}
+	// Внешний SELECT перечисляет колонки явно — служебная rn_ в результат не попадает
+	return fmt.Sprintf("SELECT %s FROM (%s) WHERE rn_ > %d ORDER BY rn_", oracleProjection(sql, schema), inner, offset)
+}
+
+// oracleProjection возвращает список колонок запроса для внешнего SELECT.
+// "*" раскрывается по схеме.
+func oracleProjection(sql string, schema packet.Schema) string {
+	projection := ""
+	if from := strings.Index(sql, " FROM "); strings.HasPrefix(sql, "SELECT ") && from > 0 {
+		projection = strings.TrimPrefix(strings.TrimSpace(sql[len("SELECT "):from]), "DISTINCT ")
+	}
+	if projection != "" && projection != "*" {
+		return projection
+	}
+	if len(schema.Fields) == 0 {
+		return "*"
+	}
+	cols := make([]string, len(schema.Fields))
+	for i, f := range schema.Fields {
+		cols[i] = QuoteOracleIdentifier(f.Name)
+	}
+	return strings.Join(cols, ", ")
+}
+
+// quoteOracleFields квотирует поля схемы, которые Oracle без кавычек перевёл
+// бы в верхний регистр ("OrderDate" → ORDERDATE). Строковые литералы и уже
+// квотированные имена не затрагиваются.
+func quoteOracleFields(sql string, schema packet.Schema) string {
+	names := make(map[string]bool)
+	for _, f := range schema.Fields {
+		if f.Name != strings.ToUpper(f.Name) {
+			names[f.Name] = true
+		}
+	}
+	if len(names) == 0 {
+		return sql
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(sql); {
+		c := sql[i]
+		switch {
+		case c == '\'' || c == '"':
+			// Литерал или квотированное имя целиком, удвоенная кавычка — экранирование
+			j := i + 1
+			for j < len(sql) {
+				if sql[j] == c {
+					if j+1 < len(sql) && sql[j+1] == c {
+						j += 2
+						continue
+					}
+					break
+				}
+				j++
+			}
+			j = min(j+1, len(sql))
+			b.WriteString(sql[i:j])
+			i = j
+		case isOracleIdentChar(c) && (c < '0' || c > '9'):
+			j := i + 1
+			for j < len(sql) && isOracleIdentChar(sql[j]) {
+				j++
+			}
+			if word := sql[i:j]; names[word] {
+				b.WriteString(QuoteOracleIdentifier(word))
+			} else {
+				b.WriteString(word)
+			}
+			i = j
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	return b.String()
+}
+
+func isOracleIdentChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '$' || c == '#'
+}
+
+// OracleName приводит имя к виду словаря Oracle: имя без кавычек и без
+// заглавных букв ("orders") — к верхнему регистру, как Oracle поступает с
+// unquoted identifiers. Имя в кавычках или со смешанным регистром
+// ("OrderLines") сохраняется как есть.
+func OracleName(name string) string {
+	name = strings.TrimSpace(name)
+	if len(name) >= 2 && name[0] == '"' && name[len(name)-1] == '"' {
+		return unquoteIdent(name)
+	}
+	if strings.ToLower(name) == name {
+		return strings.ToUpper(name)
+	}
+	return name
+}
+
+// SplitOracleName разбирает "owner.table" (точка в кавычках — часть имени)
+// и приводит части через OracleName. Без владельца — defaultOwner.
+func SplitOracleName(name, defaultOwner string) (owner, table string) {
+	name = strings.TrimSpace(name)
+	inQuote := false
+	for i := 0; i < len(name); i++ {
+		switch name[i] {
+		case '"':
+			inQuote = !inQuote
+		case '.':
+			if !inQuote {
+				return OracleName(name[:i]), OracleName(name[i+1:])
+			}
+		}
+	}
+	return defaultOwner, OracleName(name)
+}
+
+// QuoteOracleIdentifier квотирует идентификатор Oracle (регистр сохраняется).
+func QuoteOracleIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+	"github.com/ruslano69/tdtp-framework/pkg/core/tdtql"
 )
 
 func TestMSSQLAdapter_AdaptSQL_ANSIQuotedTableName(t *testing.T) {
@@ -243,3 +244,141 @@ func TestMSSQLAdapter_AdaptSQL_LeavesNonDatetimeStringsAlone(t *testing.T) {
 		t.Errorf("regex damaged code-style value '24626-1': %s", got)
 	}
 }
+
+// Имена из 3–4 частей (другая БД, linked server) квалифицируются по частям:
+// раньше "Archive.dbo.Orders" превращалось в [Archive].[dbo.Orders].
+func TestMSSQLAdapter_AdaptSQL_MultipartTableName(t *testing.T) {
+	adapter := NewMSSQLAdapter("dbo")
+	schema := packet.Schema{Fields: []packet.Field{{Name: "id"}}}
+
+	tests := []struct {
+		table, standardSQL, want string
+	}{
+		{"Archive.dbo.Orders", `SELECT * FROM Archive.dbo.Orders WHERE id = 1`,
+			`SELECT * FROM [Archive].[dbo].[Orders] WHERE [id] = 1`},
+		{"Archive..Orders", `SELECT * FROM Archive..Orders`,
+			`SELECT * FROM [Archive]..[Orders]`},
+		{"[SRV01].[ERP].[dbo].[ZTR$Item]", `SELECT * FROM SRV01.ERP.dbo."ZTR$Item"`,
+			`SELECT * FROM [SRV01].[ERP].[dbo].[ZTR$Item]`},
+		{"custom.ZTR$Item", `SELECT * FROM custom."ZTR$Item"`,
+			`SELECT * FROM [custom].[ZTR$Item]`},
+	}
+	for _, tt := range tests {
+		if got := adapter.AdaptSQL(tt.standardSQL, tt.table, schema, nil); got != tt.want {
+			t.Errorf("AdaptSQL(%q):\n got %s\nwant %s", tt.table, got, tt.want)
+		}
+	}
+}
+
+func TestPostgreSQLSchemaAdapter_AdaptSQL(t *testing.T) {
+	tests := []struct {
+		name      string
+		schema    string
+		table     string
+		sql       string
+		wantTable string
+	}{
+		{"public unqualified untouched", "public", "orders", `SELECT * FROM orders WHERE id > 1`, ` FROM orders WHERE`},
+		{"adapter schema, no trailing clause", "sales", "orders", `SELECT * FROM orders`, ` FROM "sales"."orders"`},
+		{"qualified name overrides adapter schema", "public", "archive.Orders", `SELECT * FROM archive.Orders LIMIT 10`, ` FROM "archive"."Orders" LIMIT`},
+		{"ANSI-quoted special table", "sales", "ZTR$Line", `SELECT * FROM "ZTR$Line" WHERE x = 1`, ` FROM "sales"."ZTR$Line" WHERE`},
+		{"prefix of another table not replaced", "sales", "orders", `SELECT * FROM orders_archive`, ` FROM orders_archive`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewPostgreSQLSchemaAdapter(tt.schema).AdaptSQL(tt.sql, tt.table, packet.Schema{}, nil)
+			if !strings.Contains(got, tt.wantTable) {
+				t.Errorf("AdaptSQL(%q) = %q, want %q", tt.sql, got, tt.wantTable)
+			}
+		})
+	}
+}
+
+func TestSplitQualifiedName(t *testing.T) {
+	tests := []struct {
+		in                string
+		schema, table     string
+		wantQualification bool
+	}{
+		{"orders", "", "orders", false},
+		{"sales.orders", "sales", "orders", true},
+		{`"Sales"."Order Lines"`, "Sales", "Order Lines", true},
+		{"[dbo].[Users]", "dbo", "Users", true},
+		{".orders", "", ".orders", false},
+	}
+	for _, tt := range tests {
+		schema, table, qualified := SplitQualifiedName(tt.in)
+		if schema != tt.schema || table != tt.table || qualified != tt.wantQualification {
+			t.Errorf("SplitQualifiedName(%q) = %q, %q, %v", tt.in, schema, table, qualified)
+		}
+	}
+}
+
+func TestOracleSQLAdapter_AdaptSQL(t *testing.T) {
+	schema := packet.Schema{Fields: []packet.Field{{Name: "ID"}, {Name: "OrderDate"}}}
+	filter := &packet.Filters{And: &packet.LogicalGroup{Filters: []packet.Filter{
+		{Field: "OrderDate", Operator: "gte", Value: "2024-01-15T10:30:00Z"},
+	}}}
+	byID := &packet.OrderBy{Field: "ID", Direction: "ASC"}
+
+	tests := []struct {
+		name        string
+		offsetFetch bool
+		table       string
+		query       *packet.Query
+		want        string
+	}{
+		{"no query", true, "orders", nil,
+			`SELECT * FROM "HR"."ORDERS"`},
+		{"filter: mixed-case field quoted, ISO literal", true, "orders", &packet.Query{Filters: filter},
+			`SELECT * FROM "HR"."ORDERS" WHERE "OrderDate" >= TIMESTAMP '2024-01-15 10:30:00'`},
+		{"qualified and mixed-case table", true, `sales."OrderLines"`, &packet.Query{},
+			`SELECT * FROM "SALES"."OrderLines"`},
+		{"12c limit", true, "orders", &packet.Query{Limit: 10},
+			`SELECT * FROM "HR"."ORDERS" FETCH FIRST 10 ROWS ONLY`},
+		{"12c limit offset", true, "orders", &packet.Query{OrderBy: byID, Limit: 10, Offset: 20},
+			`SELECT * FROM "HR"."ORDERS" ORDER BY ID ASC OFFSET 20 ROWS FETCH NEXT 10 ROWS ONLY`},
+		{"12c tail", true, "orders", &packet.Query{OrderBy: byID, Limit: -5},
+			`SELECT * FROM (SELECT * FROM "HR"."ORDERS" ORDER BY ID DESC FETCH FIRST 5 ROWS ONLY) tail_ ORDER BY ID ASC`},
+		{"11g limit", false, "orders", &packet.Query{OrderBy: byID, Limit: 10},
+			`SELECT * FROM (SELECT * FROM "HR"."ORDERS" ORDER BY ID ASC) WHERE ROWNUM <= 10`},
+		{"11g limit offset expands star", false, "orders", &packet.Query{Limit: 10, Offset: 20},
+			`SELECT "ID", "OrderDate" FROM (SELECT q_.*, ROWNUM rn_ FROM (SELECT * FROM "HR"."ORDERS") q_ WHERE ROWNUM <= 30) WHERE rn_ > 20 ORDER BY rn_`},
+		{"11g offset with projection", false, "orders", &packet.Query{Fields: []string{"OrderDate"}, Offset: 5},
+			`SELECT "OrderDate" FROM (SELECT q_.*, ROWNUM rn_ FROM (SELECT "OrderDate" FROM "HR"."ORDERS") q_) WHERE rn_ > 5 ORDER BY rn_`},
+		{"11g tail", false, "orders", &packet.Query{OrderBy: byID, Limit: -5},
+			`SELECT * FROM (SELECT * FROM (SELECT * FROM "HR"."ORDERS" ORDER BY ID DESC) WHERE ROWNUM <= 5) tail_ ORDER BY ID ASC`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			standard, err := tdtql.NewSQLGenerator().GenerateSQL(tt.table, tt.query)
+			if err != nil {
+				t.Fatalf("GenerateSQL: %v", err)
+			}
+			got := NewOracleSQLAdapter("HR", tt.offsetFetch).AdaptSQL(standard, tt.table, schema, tt.query)
+			if got != tt.want {
+				t.Errorf("AdaptSQL(%q)\n got %s\nwant %s", standard, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitOracleName(t *testing.T) {
+	tests := []struct {
+		in           string
+		owner, table string
+	}{
+		{"orders", "HR", "ORDERS"},
+		{"ORDERS", "HR", "ORDERS"},
+		{"OrderLines", "HR", "OrderLines"},
+		{`"orders"`, "HR", "orders"},
+		{"sales.orders", "SALES", "ORDERS"},
+		{`"Sales"."a.b"`, "Sales", "a.b"},
+	}
+	for _, tt := range tests {
+		owner, table := SplitOracleName(tt.in, "HR")
+		if owner != tt.owner || table != tt.table {
+			t.Errorf("SplitOracleName(%q) = %q, %q, want %q, %q", tt.in, owner, table, tt.owner, tt.table)
+		}
+	}
+}
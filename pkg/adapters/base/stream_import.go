@@ -0,0 +1,164 @@
+package base
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+// SetStreamBatchPackets задаёт, сколько пакетов ImportPacketStream фиксирует
+// одной транзакцией (0 — adapters.DefaultStreamBatchPackets).
+func (h *ImportHelper) SetStreamBatchPackets(n int) {
+	h.streamBatchPackets = n
+}
+
+// ImportPacketStream импортирует пакеты из канала пачками по
+// streamBatchPackets, каждая пачка — отдельная транзакция
+// (см. adapters.PacketStreamImporter).
+//
+// StrategyCopy (и useTemporaryTables=true): весь поток таблицы грузится во
+// временную таблицу, целевая заменяется после закрытия канала — атомарно,
+// как в ImportPackets, но без всех частей в памяти. При ошибке временные
+// таблицы удаляются, целевые не меняются.
+func (h *ImportHelper) ImportPacketStream(ctx context.Context, packets <-chan *packet.DataPacket, strategy adapters.ImportStrategy) error {
+	if !h.useTemporaryTables || strategy != adapters.StrategyCopy {
+		return adapters.ImportPacketBatches(ctx, packets, strategy, h.streamBatchPackets, h.ImportPackets)
+	}
+
+	type tempTable struct {
+		name   string
+		schema packet.Schema
+	}
+	temps := make(map[string]*tempTable)
+	var order []string // порядок замены — порядок первых пакетов таблиц
+	dropTemps := func() {
+		for _, t := range temps {
+			_ = h.tableManager.DropTable(ctx, t.name) // игнорируем ошибку cleanup
+		}
+	}
+
+	// Блокировки таблиц держатся до замены всех таблиц
+	var unlocks []func()
+	defer func() {
+		for _, unlock := range unlocks {
+			unlock()
+		}
+	}()
+
+	batchNum := 0
+	err := adapters.ReadPacketBatches(ctx, packets, h.streamBatchPackets, func(batch []*packet.DataPacket, _ bool) error {
+		// Схема временной таблицы берётся из пакета — сначала расшифровка
+		// и суррогатные ключи
+		for _, pkt := range batch {
+			if err := h.openPacket(ctx, pkt); err != nil {
+				return err
+			}
+		}
+		if err := h.applyProvenance(ctx, batch); err != nil {
+			return err
+		}
+
+		// Delta и удаление применяются к целевой таблице как обычно
+		if batch[0].Data.Delta || batch[0].Data.Delete {
+			return h.ImportPackets(ctx, batch, strategy)
+		}
+		if err := h.rejectRows(batch, &adapters.ImportReport{}); err != nil {
+			return err
+		}
+
+		tableName := batch[0].Header.TableName
+		temp, ok := temps[tableName]
+		if !ok {
+			lockCtx, unlock, err := h.lockTables(ctx, tableName)
+			if err != nil {
+				return err
+			}
+			unlocks = append(unlocks, unlock)
+			ctx = lockCtx
+
+			temp = &tempTable{name: GenerateTempTableName(tableName), schema: batch[0].Schema}
+			fmt.Printf("📋 Streaming import to temporary table: %s\n", temp.name)
+			if err := h.tableManager.CreateTable(ctx, temp.name, temp.schema); err != nil {
+				return fmt.Errorf("failed to create temporary table: %w", err)
+			}
+			temps[tableName] = temp
+			order = append(order, tableName)
+		}
+
+		batchNum++
+		rows := 0
+		for _, pkt := range batch {
+			rows += len(pkt.Data.Rows)
+		}
+		fmt.Printf("  📦 Importing batch %d (%d packets, %d rows)\n", batchNum, len(batch), rows)
+
+		return h.governor.Do(ctx, rows, func() error {
+			return h.insertBatchTx(ctx, temp.name, temp.schema, batch, strategy)
+		})
+	})
+	if err != nil {
+		dropTemps()
+		return err
+	}
+
+	// Загрузка во временные таблицы не разрушает целевые — окна
+	// обслуживания ждёт только замена
+	if err := h.maintenance.Await(ctx, order...); err != nil {
+		dropTemps()
+		return err
+	}
+
+	for i, tableName := range order {
+		temp := temps[tableName]
+		fmt.Printf("🔄 Replacing production table: %s\n", tableName)
+		if err := h.replaceTables(ctx, tableName, temp.name); err != nil {
+			// Уже заменённые таблицы остаются, незаменённые временные удаляются
+			for _, rest := range order[i:] {
+				_ = h.tableManager.DropTable(ctx, temps[rest].name)
+			}
+			return fmt.Errorf("table %s: failed to replace tables: %w", tableName, err)
+		}
+	}
+
+	fmt.Printf("✅ Streaming import completed successfully\n")
+	return nil
+}
+
+// insertBatchTx вставляет пачку пакетов в таблицу в одной транзакции.
+// Пакеты со схемой, отличной от schema таблицы, пропускаются — как в ImportPackets.
+func (h *ImportHelper) insertBatchTx(
+	ctx context.Context,
+	tableName string,
+	schema packet.Schema,
+	batch []*packet.DataPacket,
+	strategy adapters.ImportStrategy,
+) (err error) {
+	tx, err := h.transactionManager.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx) // игнорируем ошибку rollback при ошибке импорта
+		}
+	}()
+
+	for _, pkt := range batch {
+		if !packet.SchemaEquals(schema, pkt.Schema) {
+			fmt.Printf("  ⚠️  Skipping packet %d: schema mismatch (expected %d fields, got %d)\n",
+				pkt.Header.PartNumber, len(schema.Fields), len(pkt.Schema.Fields))
+			continue
+		}
+		pktCtx := adapters.WithMessageID(ctx, pkt.Header.MessageID)
+		if err = h.dataInserter.InsertRows(pktCtx, tableName, pkt.Schema, pkt.Data.Rows, strategy); err != nil {
+			return fmt.Errorf("failed to import packet %d: %w", pkt.Header.PartNumber, err)
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
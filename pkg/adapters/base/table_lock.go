@@ -0,0 +1,122 @@
+package base
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+)
+
+// TableLocker — кооперативная блокировка целевой таблицы между процессами
+// (adapters.TableLocking). ImportHelper проверяет его на tableManager.
+type TableLocker interface {
+	// TryLockTable захватывает блокировку table без ожидания. ok == false —
+	// блокировку держит другой импорт. unlock освобождает её.
+	TryLockTable(ctx context.Context, table string) (unlock func() error, ok bool, err error)
+}
+
+// Интервалы повторных попыток в режиме wait: от первого до последнего ×2.
+const (
+	tableLockRetryMin = 100 * time.Millisecond
+	tableLockRetryMax = 2 * time.Second
+)
+
+type heldTablesKey struct{}
+
+// LockTables захватывает блокировки tables по правилам cfg (в алфавитном
+// порядке — два импорта одних и тех же таблиц не заблокируют друг друга
+// навсегда). Возвращённый контекст помнит захваченные таблицы: вложенный
+// вызов с ним (потоковый импорт → ImportPackets) их не перезахватывает.
+// release освобождает захваченное; вызывается всегда.
+func LockTables(ctx context.Context, locker TableLocker, cfg adapters.TableLocking, tables ...string) (context.Context, func(), error) {
+	if cfg.Mode == adapters.TableLockOff || locker == nil {
+		return ctx, func() {}, nil
+	}
+
+	held, _ := ctx.Value(heldTablesKey{}).(map[string]bool)
+	var todo []string
+	for _, t := range tables {
+		if t != "" && !held[t] && !slices.Contains(todo, t) {
+			todo = append(todo, t)
+		}
+	}
+	if len(todo) == 0 {
+		return ctx, func() {}, nil
+	}
+	slices.Sort(todo)
+
+	var unlocks []func() error
+	release := func() {
+		for i := len(unlocks) - 1; i >= 0; i-- {
+			_ = unlocks[i]() // ошибку освобождения некуда вернуть: сессия закрывается вместе с ней
+		}
+	}
+	for _, table := range todo {
+		unlock, err := lockTable(ctx, locker, cfg, table)
+		if err != nil {
+			release()
+			return ctx, func() {}, err
+		}
+		unlocks = append(unlocks, unlock)
+	}
+
+	next := make(map[string]bool, len(held)+len(todo))
+	for t := range held {
+		next[t] = true
+	}
+	for _, t := range todo {
+		next[t] = true
+	}
+	return context.WithValue(ctx, heldTablesKey{}, next), release, nil
+}
+
+func lockTable(ctx context.Context, locker TableLocker, cfg adapters.TableLocking, table string) (func() error, error) {
+	var deadline time.Time
+	if cfg.Timeout > 0 {
+		deadline = time.Now().Add(cfg.Timeout)
+	}
+	retry := tableLockRetryMin
+	waiting := false
+	for {
+		unlock, ok, err := locker.TryLockTable(ctx, table)
+		if err != nil {
+			return nil, fmt.Errorf("failed to lock table %s: %w", table, err)
+		}
+		if ok {
+			return unlock, nil
+		}
+		if cfg.Mode == adapters.TableLockFail {
+			return nil, fmt.Errorf("%s: %w", table, adapters.ErrTableLocked)
+		}
+		if !deadline.IsZero() && time.Now().Add(retry).After(deadline) {
+			return nil, fmt.Errorf("%s: %w (waited %s)", table, adapters.ErrTableLocked, cfg.Timeout)
+		}
+		if !waiting {
+			fmt.Printf("⏳ Table %s is being imported by another job, waiting...\n", table)
+			waiting = true
+		}
+
+		t := time.NewTimer(retry)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return nil, ctx.Err()
+		case <-t.C:
+		}
+		retry = min(retry*2, tableLockRetryMax)
+	}
+}
+
+// TableLockName — имя блокировки таблицы для СУБД с именованными
+// блокировками (pg_advisory_lock по хешу, sp_getapplock, GET_LOCK).
+func TableLockName(table string) string {
+	return "tdtp:import:" + table
+}
+
+// lockTables — LockTables с блокировщиком и настройками ImportHelper.
+func (h *ImportHelper) lockTables(ctx context.Context, tables ...string) (context.Context, func(), error) {
+	locker, _ := h.tableManager.(TableLocker)
+	return LockTables(ctx, locker, h.tableLock, tables...)
+}
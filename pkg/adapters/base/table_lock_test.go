@@ -0,0 +1,130 @@
+package base
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+)
+
+// memLocker — блокировки в памяти; held — таблицы, занятые «другим импортом».
+type memLocker struct {
+	mu    sync.Mutex
+	held  map[string]bool
+	calls []string
+}
+
+func (l *memLocker) TryLockTable(_ context.Context, table string) (func() error, bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.calls = append(l.calls, table)
+	if l.held[table] {
+		return nil, false, nil
+	}
+	l.held[table] = true
+	return func() error {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		delete(l.held, table)
+		return nil
+	}, true, nil
+}
+
+func (l *memLocker) release(table string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.held, table)
+}
+
+func TestLockTables_SortedAndReleased(t *testing.T) {
+	l := &memLocker{held: map[string]bool{}}
+	cfg := adapters.TableLocking{Mode: adapters.TableLockFail}
+
+	_, release, err := LockTables(context.Background(), l, cfg, "orders", "customers", "orders")
+	if err != nil {
+		t.Fatalf("LockTables: %v", err)
+	}
+	if len(l.calls) != 2 || l.calls[0] != "customers" || l.calls[1] != "orders" {
+		t.Errorf("lock order = %v, want [customers orders]", l.calls)
+	}
+	release()
+	if len(l.held) != 0 {
+		t.Errorf("held after release = %v", l.held)
+	}
+}
+
+func TestLockTables_Fail(t *testing.T) {
+	l := &memLocker{held: map[string]bool{"orders": true}}
+	cfg := adapters.TableLocking{Mode: adapters.TableLockFail}
+
+	_, _, err := LockTables(context.Background(), l, cfg, "customers", "orders")
+	if !errors.Is(err, adapters.ErrTableLocked) {
+		t.Fatalf("err = %v, want ErrTableLocked", err)
+	}
+	if l.held["customers"] {
+		t.Error("customers lock must be released when orders is busy")
+	}
+}
+
+func TestLockTables_WaitTimeout(t *testing.T) {
+	l := &memLocker{held: map[string]bool{"orders": true}}
+	cfg := adapters.TableLocking{Mode: adapters.TableLockWait, Timeout: 250 * time.Millisecond}
+
+	start := time.Now()
+	_, _, err := LockTables(context.Background(), l, cfg, "orders")
+	if !errors.Is(err, adapters.ErrTableLocked) {
+		t.Fatalf("err = %v, want ErrTableLocked", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Errorf("waited %s, timeout is %s", time.Since(start), cfg.Timeout)
+	}
+}
+
+func TestLockTables_WaitAcquires(t *testing.T) {
+	l := &memLocker{held: map[string]bool{"orders": true}}
+	cfg := adapters.TableLocking{Mode: adapters.TableLockWait, Timeout: 5 * time.Second}
+
+	time.AfterFunc(150*time.Millisecond, func() { l.release("orders") })
+	_, release, err := LockTables(context.Background(), l, cfg, "orders")
+	if err != nil {
+		t.Fatalf("LockTables: %v", err)
+	}
+	defer release()
+	if len(l.calls) < 2 {
+		t.Errorf("calls = %v, want retries", l.calls)
+	}
+}
+
+func TestLockTables_Reentrant(t *testing.T) {
+	l := &memLocker{held: map[string]bool{}}
+	cfg := adapters.TableLocking{Mode: adapters.TableLockFail}
+
+	ctx, release, err := LockTables(context.Background(), l, cfg, "orders")
+	if err != nil {
+		t.Fatalf("LockTables: %v", err)
+	}
+	defer release()
+
+	// Вложенный импорт той же таблицы с контекстом внешнего не блокирует себя
+	_, inner, err := LockTables(ctx, l, cfg, "orders")
+	if err != nil {
+		t.Fatalf("nested LockTables: %v", err)
+	}
+	inner()
+	if !l.held["orders"] {
+		t.Error("nested release must not free the outer lock")
+	}
+}
+
+func TestLockTables_Off(t *testing.T) {
+	l := &memLocker{held: map[string]bool{"orders": true}}
+	if _, _, err := LockTables(context.Background(), l, adapters.TableLocking{}, "orders"); err != nil {
+		t.Fatalf("LockTables with locking off: %v", err)
+	}
+	if len(l.calls) != 0 {
+		t.Errorf("calls = %v, want none", l.calls)
+	}
+}
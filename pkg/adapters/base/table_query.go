@@ -0,0 +1,239 @@
+package base
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+	"github.com/ruslano69/tdtp-framework/pkg/core/tdtql"
+	"github.com/ruslano69/tdtp-framework/pkg/security"
+)
+
+// QuerySchemaReader — опциональный интерфейс SchemaReader: схема результата
+// произвольного SELECT по метаданным драйвера, по возможности без выполнения
+// запроса (prepare, describe или обёртка WHERE 1=0). Нужен для экспорта
+// таблиц с собственным запросом (SetTableQueries).
+type QuerySchemaReader interface {
+	GetQuerySchema(ctx context.Context, query string) (packet.Schema, error)
+}
+
+// MetadataQuery оборачивает запрос так, чтобы СУБД вернула только колонки
+// результата без строк. Подходит для СУБД, где подзапрос в FROM может
+// содержать ORDER BY (SQLite, MySQL, Oracle).
+func MetadataQuery(query string) string {
+	return fmt.Sprintf("SELECT * FROM (%s) tdtp_q WHERE 1=0", trimQuery(query))
+}
+
+// trimQuery убирает завершающую ';' — валидатор её допускает, а в подзапросе
+// и при повторном выполнении она лишняя
+func trimQuery(query string) string {
+	return strings.TrimRight(strings.TrimSpace(query), "; \t\r\n")
+}
+
+// SetTableQueries задаёт собственные SELECT для таблиц: вместо SELECT * FROM
+// таблицы экспорт выполняет запрос из карты (ключ — имя таблицы, без учёта
+// регистра). Запросы проверяются SQLValidator в safe mode: только SELECT/WITH,
+// одна команда, без комментариев.
+//
+// Схема пакета строится по метаданным результата (QuerySchemaReader);
+// колонки, совпавшие по имени с колонками таблицы, берут определение из
+// схемы таблицы (ключ, точность, subtype), поэтому пакет остаётся пригодным
+// для импорта с UPSERT. TDTQL фильтры к такой таблице применяются в памяти:
+// тяжёлую фильтрацию стоит писать в самом запросе.
+func (h *ExportHelper) SetTableQueries(queries map[string]string) error {
+	validator := security.NewSQLValidator(true)
+	tableQueries := make(map[string]string, len(queries))
+	for table, query := range queries {
+		if strings.TrimSpace(query) == "" {
+			return fmt.Errorf("table query for %s is empty", table)
+		}
+		if err := validator.Validate(query); err != nil {
+			return fmt.Errorf("table query for %s: %w", table, err)
+		}
+		tableQueries[strings.ToLower(table)] = trimQuery(query)
+	}
+	h.tableQueries = tableQueries
+	return nil
+}
+
+// tableQuery возвращает собственный запрос таблицы, если он задан
+func (h *ExportHelper) tableQuery(tableName string) (string, bool) {
+	if len(h.tableQueries) == 0 {
+		return "", false
+	}
+	query, ok := h.tableQueries[strings.ToLower(tableName)]
+	return query, ok
+}
+
+// querySchema строит схему результата собственного запроса таблицы
+func (h *ExportHelper) querySchema(ctx context.Context, tableName, query string) (packet.Schema, error) {
+	if _, ok := h.schemaReader.(QuerySchemaReader); !ok {
+		return packet.Schema{}, fmt.Errorf("table %s: adapter does not support custom table queries", tableName)
+	}
+	result, err := h.describeQuery(ctx, query)
+	if err != nil {
+		return packet.Schema{}, fmt.Errorf("table %s: custom query: %w", tableName, err)
+	}
+
+	// Имя может не быть таблицей (витрина из JOIN) — тогда только метаданные запроса
+	if table, err := h.schemaReader.GetTableSchema(ctx, tableName); err == nil {
+		result = mergeQuerySchema(result, table)
+	}
+	return result, nil
+}
+
+// describeQuery возвращает схему результата запроса по метаданным драйвера
+func (h *ExportHelper) describeQuery(ctx context.Context, query string) (packet.Schema, error) {
+	reader, ok := h.schemaReader.(QuerySchemaReader)
+	if !ok {
+		return packet.Schema{}, fmt.Errorf("adapter does not support query schema inference")
+	}
+	result, err := reader.GetQuerySchema(ctx, query)
+	if err != nil {
+		return packet.Schema{}, fmt.Errorf("failed to describe query: %w", err)
+	}
+	if len(result.Fields) == 0 {
+		return packet.Schema{}, fmt.Errorf("query returned no columns")
+	}
+	return result, nil
+}
+
+// mergeQuerySchema дополняет схему результата запроса определениями колонок
+// таблицы: метаданные драйвера не знают первичного ключа, subtype и часто
+// точности. Порядок и имена колонок остаются как в запросе.
+func mergeQuerySchema(result, table packet.Schema) packet.Schema {
+	byName := make(map[string]packet.Field, len(table.Fields))
+	for _, f := range table.Fields {
+		byName[strings.ToLower(f.Name)] = f
+	}
+
+	merged := packet.Schema{Fields: make([]packet.Field, len(result.Fields))}
+	for i, f := range result.Fields {
+		if tf, ok := byName[strings.ToLower(f.Name)]; ok {
+			tf.Name = f.Name
+			f = tf
+		}
+		merged.Fields[i] = f
+	}
+	return merged
+}
+
+// exportTableQuery — экспорт таблицы с собственным запросом: строки читаются
+// запросом целиком, TDTQL (если задан) применяется в памяти.
+func (h *ExportHelper) exportTableQuery(
+	ctx context.Context,
+	tableName, customSQL string,
+	query *packet.Query,
+	sender, recipient string,
+) ([]*packet.DataPacket, error) {
+	schema, err := h.querySchema(ctx, tableName, customSQL)
+	if err != nil {
+		return nil, err
+	}
+
+	if query == nil {
+		rows, err := h.dataReader.ReadRowsWithSQL(ctx, customSQL, schema)
+		if err != nil {
+			return nil, fmt.Errorf("table %s: failed to execute custom query: %w", tableName, err)
+		}
+		if pp, ok := h.dataReader.(RowPostProcessor); ok {
+			schema, rows = pp.PostProcessRows(ctx, schema, rows)
+		}
+		packets, err := h.newGenerator().GenerateReference(tableName, schema, rows)
+		if err != nil {
+			return nil, err
+		}
+		return h.seal(ctx, packets)
+	}
+
+	executor := tdtql.NewExecutor()
+	if err := executor.ValidateQuery(query, schema); err != nil {
+		return nil, err
+	}
+	executor.NormalizeQueryFields(query, schema)
+
+	start := time.Now()
+	rows, err := h.dataReader.ReadRowsWithSQL(ctx, customSQL, schema)
+	if err != nil {
+		return nil, fmt.Errorf("table %s: failed to execute custom query: %w", tableName, err)
+	}
+
+	result, err := executor.ExecuteContext(ctx, query, rows, schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	plan := tableQueryPlan(query, int64(len(rows)))
+	plan.EstimatedMatches = int64(len(result.FilteredRows))
+	plan.AddStage(packet.StageRead, packet.ExecSQL, -1, len(rows), start)
+	plan.Stages = append(plan.Stages, result.Stages...)
+	result.QueryContext.ExecutionPlan = plan
+
+	pkgSchema, filteredRows := result.Schema, result.Rows
+	if pp, ok := h.dataReader.(RowPostProcessor); ok {
+		pkgSchema, filteredRows = pp.PostProcessRows(ctx, pkgSchema, filteredRows)
+	}
+
+	packets, err := h.generateResponse(tableName, pkgSchema, filteredRows, result.QueryContext, sender, recipient)
+	if err != nil {
+		return nil, err
+	}
+	return h.seal(ctx, packets)
+}
+
+// tableQueryPlan — план экспорта с собственным запросом: результат запроса
+// читается целиком, TDTQL выполняется в памяти.
+func tableQueryPlan(query *packet.Query, rowCount int64) *packet.ExecutionPlan {
+	plan := &packet.ExecutionPlan{
+		Strategy:         packet.PlanMaterialize,
+		EstimatedRows:    rowCount,
+		Selectivity:      tdtql.EstimateSelectivity(query.Filters),
+		EstimatedMatches: -1,
+		Reason:           "custom table query",
+		Filters:          tdtql.PlanFilters(query.Filters, packet.ExecMemory),
+	}
+	if rowCount >= 0 {
+		plan.EstimatedMatches = int64(float64(rowCount) * plan.Selectivity)
+	}
+	return plan
+}
+
+// ExportQuery экспортирует результат произвольного read-only SQL в reference
+// пакеты таблицы adapters.QueryResultTable (см. adapters.QueryExporter).
+// Запрос проверяется SQLValidator в safe mode, схема выводится по
+// метаданным результата (QuerySchemaReader), строки читаются тем же путём
+// конвертации, что и при экспорте таблицы. С политикой выдачи колонок
+// (SetExportPolicy) запрос отклоняется.
+func (h *ExportHelper) ExportQuery(ctx context.Context, sql string) ([]*packet.DataPacket, error) {
+	if strings.TrimSpace(sql) == "" {
+		return nil, fmt.Errorf("query is empty")
+	}
+	// Колонки результата произвольного SQL политика к таблицам не отнесёт
+	if h.policy != nil && len(h.policy.Tables) > 0 {
+		return nil, fmt.Errorf("%w: query export is not allowed under an export policy", security.ErrPolicyViolation)
+	}
+	if err := security.NewSQLValidator(true).Validate(sql); err != nil {
+		return nil, fmt.Errorf("query rejected: %w", err)
+	}
+	sql = trimQuery(sql)
+
+	schema, err := h.describeQuery(ctx, sql)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := h.dataReader.ReadRowsWithSQL(ctx, sql, schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	if pp, ok := h.dataReader.(RowPostProcessor); ok {
+		schema, rows = pp.PostProcessRows(ctx, schema, rows)
+	}
+	packets, err := h.newGenerator().GenerateReference(adapters.QueryResultTable, schema, rows)
+	if err != nil {
+		return nil, err
+	}
+	return h.seal(ctx, packets)
+}
@@ -0,0 +1,187 @@
+package base
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+	"github.com/ruslano69/tdtp-framework/pkg/core/schema"
+)
+
+// mockQuerySchemaReader — SchemaReader с поддержкой собственных запросов
+type mockQuerySchemaReader struct {
+	table  packet.Schema
+	result packet.Schema
+}
+
+func (m *mockQuerySchemaReader) GetTableSchema(_ context.Context, _ string) (packet.Schema, error) {
+	return m.table, nil
+}
+
+func (m *mockQuerySchemaReader) GetQuerySchema(_ context.Context, _ string) (packet.Schema, error) {
+	return m.result, nil
+}
+
+// sqlRecordingReader запоминает последний выполненный SQL
+type sqlRecordingReader struct {
+	mockDataReader
+	lastSQL string
+}
+
+func (r *sqlRecordingReader) ReadRowsWithSQL(ctx context.Context, sql string, s packet.Schema) ([][]string, error) {
+	r.lastSQL = sql
+	return r.mockDataReader.ReadRowsWithSQL(ctx, sql, s)
+}
+
+func buildTableQueryHelper(reader DataReader) *ExportHelper {
+	table := schema.NewBuilder().AddInteger("ID", true).AddText("Name", 100).AddText("Secret", 100).Build()
+	result := packet.Schema{Fields: []packet.Field{
+		{Name: "id", Type: "INTEGER"},
+		{Name: "name", Type: "TEXT", Length: 1000},
+		{Name: "total", Type: "REAL"},
+	}}
+	return NewExportHelper(&mockQuerySchemaReader{table: table, result: result}, reader, &mockValueConverter{}, nil)
+}
+
+func TestSetTableQueries_Validation(t *testing.T) {
+	helper := buildTableQueryHelper(&mockDataReader{})
+
+	for _, query := range []string{
+		"DELETE FROM Users",
+		"SELECT * FROM Users; DROP TABLE Users",
+		"SELECT * FROM Users -- hidden",
+		"   ",
+	} {
+		if err := helper.SetTableQueries(map[string]string{"Users": query}); err == nil {
+			t.Errorf("expected error for %q", query)
+		}
+	}
+
+	if err := helper.SetTableQueries(map[string]string{"Users": "SELECT id, name FROM Users;"}); err != nil {
+		t.Fatalf("valid query rejected: %v", err)
+	}
+	if q, ok := helper.tableQuery("users"); !ok || q != "SELECT id, name FROM Users" {
+		t.Errorf("tableQuery(users) = %q, %v; want trimmed query, case-insensitive lookup", q, ok)
+	}
+}
+
+func TestExportTable_CustomQuery(t *testing.T) {
+	reader := &sqlRecordingReader{mockDataReader: mockDataReader{
+		rowsFromSQL: [][]string{{"1", "Alice", "10.5"}, {"2", "Bob", "3"}},
+	}}
+	helper := buildTableQueryHelper(reader)
+	const customSQL = "SELECT id, name, SUM(amount) AS total FROM Users JOIN Orders USING (id) GROUP BY id, name"
+	if err := helper.SetTableQueries(map[string]string{"Users": customSQL}); err != nil {
+		t.Fatal(err)
+	}
+
+	packets, err := helper.ExportTable(context.Background(), "Users")
+	if err != nil {
+		t.Fatalf("ExportTable: %v", err)
+	}
+	if reader.lastSQL != customSQL {
+		t.Errorf("executed SQL = %q, want custom query", reader.lastSQL)
+	}
+	if reader.readAllRowsCalls != 0 {
+		t.Errorf("ReadAllRows must not be called for custom query, got %d calls", reader.readAllRowsCalls)
+	}
+
+	// Колонки таблицы берут определение из схемы таблицы, имена — из запроса
+	fields := packets[0].Schema.Fields
+	if len(fields) != 3 {
+		t.Fatalf("expected 3 fields, got %d", len(fields))
+	}
+	if fields[0].Name != "id" || !fields[0].Key {
+		t.Errorf("field 0 = %+v, want key column id", fields[0])
+	}
+	if fields[1].Length != 100 {
+		t.Errorf("field name length = %d, want 100 from table schema", fields[1].Length)
+	}
+	if fields[2].Name != "total" || fields[2].Type != "REAL" {
+		t.Errorf("field 2 = %+v, want computed column from query metadata", fields[2])
+	}
+	if got := len(packets[0].GetRows()); got != 2 {
+		t.Errorf("expected 2 rows, got %d", got)
+	}
+}
+
+func TestExportTableWithQuery_CustomQueryFiltersInMemory(t *testing.T) {
+	reader := &sqlRecordingReader{mockDataReader: mockDataReader{
+		rowsFromSQL: [][]string{{"1", "Alice", "10.5"}, {"42", "Bob", "3"}},
+	}}
+	helper := buildTableQueryHelper(reader)
+	if err := helper.SetTableQueries(map[string]string{"Users": "SELECT id, name, 1.0 AS total FROM Users"}); err != nil {
+		t.Fatal(err)
+	}
+
+	query := buildEqQuery()
+	query.Fields = []string{"name"}
+	packets, err := helper.ExportTableWithQuery(context.Background(), "Users", query, "test", "test")
+	if err != nil {
+		t.Fatalf("ExportTableWithQuery: %v", err)
+	}
+	if strings.Contains(reader.lastSQL, "WHERE") {
+		t.Errorf("filters must not be pushed into custom query, got SQL %q", reader.lastSQL)
+	}
+
+	pkt := packets[0]
+	if rows := pkt.GetRows(); len(rows) != 1 || rows[0][0] != "Bob" {
+		t.Errorf("rows = %v, want only Bob", rows)
+	}
+	plan := pkt.QueryContext.ExecutionPlan
+	if plan == nil || plan.Strategy != packet.PlanMaterialize || plan.Reason != "custom table query" {
+		t.Errorf("plan = %+v, want materialize / custom table query", plan)
+	}
+}
+
+func TestExportTable_CustomQueryRequiresQuerySchemaReader(t *testing.T) {
+	helper := buildFallbackTestHelper(&mockDataReader{})
+	if err := helper.SetTableQueries(map[string]string{"Users": "SELECT ID, Name FROM Users"}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := helper.ExportTable(context.Background(), "Users")
+	if err == nil || !strings.Contains(err.Error(), "does not support custom table queries") {
+		t.Errorf("expected unsupported error, got %v", err)
+	}
+}
+
+func TestExportQuery(t *testing.T) {
+	reader := &sqlRecordingReader{mockDataReader: mockDataReader{
+		rowsFromSQL: [][]string{{"1", "Alice", "10.5"}},
+	}}
+	helper := buildTableQueryHelper(reader)
+
+	packets, err := helper.ExportQuery(context.Background(), "SELECT id, name, SUM(amount) AS total FROM Orders GROUP BY id, name;")
+	if err != nil {
+		t.Fatalf("ExportQuery: %v", err)
+	}
+	if strings.HasSuffix(reader.lastSQL, ";") {
+		t.Errorf("executed SQL = %q, want trailing ';' trimmed", reader.lastSQL)
+	}
+
+	pkt := packets[0]
+	if pkt.Header.TableName != adapters.QueryResultTable {
+		t.Errorf("table name = %q, want %q", pkt.Header.TableName, adapters.QueryResultTable)
+	}
+	// Схема — только из метаданных результата, без схемы таблицы
+	if fields := pkt.Schema.Fields; len(fields) != 3 || fields[0].Key || fields[1].Length != 1000 {
+		t.Errorf("fields = %+v, want query metadata as is", fields)
+	}
+	if got := len(pkt.GetRows()); got != 1 {
+		t.Errorf("expected 1 row, got %d", got)
+	}
+
+	for _, query := range []string{"DELETE FROM Orders", "SELECT 1; SELECT 2", ""} {
+		if _, err := helper.ExportQuery(context.Background(), query); err == nil {
+			t.Errorf("expected error for %q", query)
+		}
+	}
+
+	noMeta := buildFallbackTestHelper(&mockDataReader{})
+	if _, err := noMeta.ExportQuery(context.Background(), "SELECT 1"); err == nil {
+		t.Error("expected error for adapter without QuerySchemaReader")
+	}
+}
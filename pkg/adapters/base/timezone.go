@@ -0,0 +1,116 @@
+package base
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+	"github.com/ruslano69/tdtp-framework/pkg/core/schema"
+)
+
+// naiveDatetimeFormats — строки datetime без смещения (SQLite, MySQL без parseTime)
+var naiveDatetimeFormats = []string{
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+}
+
+// SetSourceTimezone задаёт часовой пояс источника (adapters.Config.SourceTimezone).
+// Naive datetime значения интерпретируются в нём и переводятся в UTC,
+// статистика доступна через TimezoneReport.
+func (c *UniversalTypeConverter) SetSourceTimezone(name string) error {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return fmt.Errorf("invalid source timezone %q: %w", name, err)
+	}
+	c.tzMu.Lock()
+	defer c.tzMu.Unlock()
+	c.sourceLoc = loc
+	c.tzReport = &adapters.TimezoneReport{Location: loc.String()}
+	return nil
+}
+
+// TimezoneReport возвращает копию отчёта о переводе в UTC
+// (nil — часовой пояс источника не задан).
+func (c *UniversalTypeConverter) TimezoneReport() *adapters.TimezoneReport {
+	c.tzMu.Lock()
+	defer c.tzMu.Unlock()
+	if c.tzReport == nil {
+		return nil
+	}
+	r := *c.tzReport
+	r.Issues = make(map[string]int64, len(c.tzReport.Issues))
+	for k, v := range c.tzReport.Issues {
+		r.Issues[k] = v
+	}
+	r.Samples = append([]adapters.TimezoneIssue(nil), c.tzReport.Samples...)
+	return &r
+}
+
+// sourceToUTC интерпретирует показания часов v (смещение v игнорируется —
+// драйверы отдают naive значения как UTC) в поясе источника и переводит в UTC.
+// Переходы на летнее/зимнее время и сдвиг полуночи попадают в отчёт.
+func (c *UniversalTypeConverter) sourceToUTC(field string, v time.Time) time.Time {
+	y, mo, d := v.Date()
+	h, mi, s := v.Clock()
+	local := time.Date(y, mo, d, h, mi, s, v.Nanosecond(), c.sourceLoc)
+	utc := local.UTC()
+
+	var kind string
+	switch {
+	case local.Hour() != h || local.Minute() != mi:
+		kind = adapters.TZIssueNonexistent
+	case isAmbiguousLocal(local):
+		kind = adapters.TZIssueAmbiguous
+	case h == 0 && mi == 0 && s == 0 && v.Nanosecond() == 0 && utc.Day() != d:
+		kind = adapters.TZIssueDateShift
+	}
+
+	c.tzMu.Lock()
+	c.tzReport.Add(field, kind, v.Format("2006-01-02 15:04:05"), utc.Format(time.RFC3339))
+	c.tzMu.Unlock()
+	return utc
+}
+
+// isAmbiguousLocal — те же показания часов встречаются в поясе дважды
+// (переход на зимнее время): проверяем смещения соседних часов.
+func isAmbiguousLocal(local time.Time) bool {
+	_, offset := local.Zone()
+	for _, probe := range []time.Duration{-3 * time.Hour, 3 * time.Hour} {
+		_, other := local.Add(probe).Zone()
+		if other == offset {
+			continue
+		}
+		alt := time.Date(local.Year(), local.Month(), local.Day(), local.Hour(), local.Minute(),
+			local.Second(), local.Nanosecond(), time.FixedZone("", other)).In(local.Location())
+		if !alt.Equal(local) && alt.Hour() == local.Hour() && alt.Minute() == local.Minute() && alt.Day() == local.Day() {
+			return true
+		}
+	}
+	return false
+}
+
+// isDatetimeField — DATETIME/TIMESTAMP с датой и временем (не TIME)
+func isDatetimeField(field packet.Field) bool {
+	switch schema.NormalizeType(schema.DataType(field.Type)) {
+	case schema.TypeDatetime, schema.TypeTimestamp:
+		return field.Subtype != "time"
+	}
+	return false
+}
+
+// isNaiveDatetimeField — колонка хранит datetime без смещения. Колонки с
+// Timezone (timestamptz, MySQL TIMESTAMP) и DATETIMEOFFSET хранят момент времени.
+func isNaiveDatetimeField(field packet.Field) bool {
+	return isDatetimeField(field) && field.Timezone == "" && field.Subtype != "datetimeoffset"
+}
+
+// parseNaiveDatetime разбирает строку datetime без смещения
+func parseNaiveDatetime(value string) (time.Time, bool) {
+	for _, layout := range naiveDatetimeFormats {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,97 @@
+package base
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+func tzConverter(t *testing.T, name string) *UniversalTypeConverter {
+	t.Helper()
+	c := NewUniversalTypeConverter()
+	if err := c.SetSourceTimezone(name); err != nil {
+		t.Fatalf("SetSourceTimezone(%q): %v", name, err)
+	}
+	return c
+}
+
+func TestSourceTimezone_NaiveValues(t *testing.T) {
+	c := tzConverter(t, "Europe/Berlin")
+	naive := packet.Field{Name: "created", Type: "DATETIME"}
+	wall := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC) // драйвер отдаёт naive как UTC
+
+	tests := []struct {
+		name   string
+		got    string
+		expect string
+	}{
+		{"mssql DATETIME", c.DBValueToString(wall, naive, "mssql"), "2024-01-15T09:30:00Z"},
+		{"mysql DATETIME", c.DBValueToString(wall, naive, "mysql"), "2024-01-15T09:30:00Z"},
+		{"pg timestamp", c.DBValueToString(pgtype.Timestamp{Time: wall, Valid: true}, packet.Field{Name: "ts", Type: "TIMESTAMP"}, "postgres"), "2024-01-15T09:30:00Z"},
+		{"sqlite string", c.ConvertValueToTDTP(packet.Field{Name: "ts", Type: "DATETIME", Timezone: "UTC"}, "2024-07-01 12:00:00"), "2024-07-01T10:00:00Z"},
+		{"string with offset", c.ConvertValueToTDTP(naive, "2024-07-01T12:00:00+03:00"), "2024-07-01T12:00:00+03:00"},
+		{"timestamptz", c.DBValueToString(wall, packet.Field{Name: "ts", Type: "TIMESTAMP", Timezone: "UTC"}, "postgres"), "2024-01-15T10:30:00Z"},
+		{"datetimeoffset", c.DBValueToString(wall, packet.Field{Name: "ts", Type: "TIMESTAMP", Subtype: "datetimeoffset"}, "mssql"), "2024-01-15T10:30:00Z"},
+		{"DATE", c.ConvertValueToTDTP(packet.Field{Name: "d", Type: "DATE"}, "2024-01-15"), "2024-01-15"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.expect {
+			t.Errorf("%s: got %q, want %q", tt.name, tt.got, tt.expect)
+		}
+	}
+
+	if r := c.TimezoneReport(); r == nil || r.Location != "Europe/Berlin" || r.Converted != 4 || r.Suspicious() != 0 {
+		t.Errorf("report = %+v, want 4 converted, none suspicious", r)
+	}
+}
+
+func TestSourceTimezone_SuspiciousValues(t *testing.T) {
+	c := tzConverter(t, "Europe/Berlin")
+	field := packet.Field{Name: "ts", Type: "DATETIME"}
+
+	// 2024-03-31 02:30 не существует (02:00 → 03:00), 2024-10-27 02:30 — дважды
+	c.ConvertValueToTDTP(field, "2024-03-31 02:30:00")
+	c.ConvertValueToTDTP(field, "2024-10-27 02:30:00")
+	// Полночь по Берлину — 23:00 предыдущего дня в UTC
+	if got := c.ConvertValueToTDTP(field, "2024-05-01 00:00:00"); got != "2024-04-30T22:00:00Z" {
+		t.Errorf("midnight = %q", got)
+	}
+	c.ConvertValueToTDTP(field, "2024-05-01 12:00:00")
+
+	r := c.TimezoneReport()
+	want := map[string]int64{
+		adapters.TZIssueNonexistent: 1,
+		adapters.TZIssueAmbiguous:   1,
+		adapters.TZIssueDateShift:   1,
+	}
+	for kind, n := range want {
+		if r.Issues[kind] != n {
+			t.Errorf("issues[%s] = %d, want %d", kind, r.Issues[kind], n)
+		}
+	}
+	if r.Converted != 4 || len(r.Samples) != 3 || r.Samples[0].Local != "2024-03-31 02:30:00" {
+		t.Errorf("report = %+v", r)
+	}
+
+	// Отчёт — копия
+	r.Issues[adapters.TZIssueAmbiguous] = 100
+	if c.TimezoneReport().Issues[adapters.TZIssueAmbiguous] != 1 {
+		t.Error("TimezoneReport must return a copy")
+	}
+}
+
+func TestSourceTimezone_Unset(t *testing.T) {
+	c := NewUniversalTypeConverter()
+	if got := c.ConvertValueToTDTP(packet.Field{Name: "ts", Type: "DATETIME"}, "2024-07-01 12:00:00"); got != "2024-07-01T12:00:00Z" {
+		t.Errorf("got %q, want value kept as UTC", got)
+	}
+	if c.TimezoneReport() != nil {
+		t.Error("report should be nil without source timezone")
+	}
+	if err := c.SetSourceTimezone("Mars/Olympus"); err == nil {
+		t.Error("expected error for unknown timezone")
+	}
+}
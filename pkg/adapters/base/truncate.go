@@ -0,0 +1,39 @@
+package base
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+)
+
+// TableTruncater очищает таблицу перед загрузкой StrategyTruncate.
+// ImportHelper вызывает TruncateTable в транзакции импорта (ImportTx),
+// поэтому очистка откатывается вместе с неудачной загрузкой.
+type TableTruncater interface {
+	TruncateTable(ctx context.Context, tableName string) error
+}
+
+// SQLConn — *sql.DB или *sql.Tx: соединение, в котором адаптер выполняет
+// запросы импорта (см. ImportTx).
+type SQLConn interface {
+	SQLExecer
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+}
+
+type importTxKey struct{}
+
+// WithImportTx передаёт транзакцию импорта в TruncateTable и InsertRows
+// адаптера: StrategyTruncate очищает таблицу и вставляет строки в одной
+// транзакции.
+func WithImportTx(ctx context.Context, tx adapters.Tx) context.Context {
+	return context.WithValue(ctx, importTxKey{}, tx)
+}
+
+// ImportTx возвращает транзакцию из WithImportTx (nil — не задана).
+// Адаптер приводит её к своему типу транзакции; чужую транзакцию
+// (или nil) он не использует и выполняет запросы вне транзакции.
+func ImportTx(ctx context.Context) adapters.Tx {
+	tx, _ := ctx.Value(importTxKey{}).(adapters.Tx)
+	return tx
+}
@@ -0,0 +1,138 @@
+package base
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+// truncateTarget — TableManager, DataInserter, TransactionManager и
+// TableTruncater, записывающий операции; TruncateTable и InsertRows
+// отмечают, получили ли они транзакцию импорта.
+type truncateTarget struct {
+	exists  bool
+	failAt  string // операция, которая вернёт ошибку
+	ops     []string
+	tx      *importTx
+	inserts []adapters.ImportStrategy
+}
+
+func (f *truncateTarget) op(ctx context.Context, name string) error {
+	if ImportTx(ctx) == f.tx && f.tx != nil {
+		name += " in tx"
+	}
+	f.ops = append(f.ops, name)
+	if name == f.failAt {
+		return errors.New(name + " failed")
+	}
+	return nil
+}
+
+func (f *truncateTarget) TableExists(context.Context, string) (bool, error) { return f.exists, nil }
+func (f *truncateTarget) CreateTable(ctx context.Context, _ string, _ packet.Schema) error {
+	f.exists = true
+	return f.op(ctx, "create")
+}
+func (f *truncateTarget) DropTable(context.Context, string) error           { return nil }
+func (f *truncateTarget) RenameTable(context.Context, string, string) error { return nil }
+func (f *truncateTarget) TruncateTable(ctx context.Context, _ string) error {
+	return f.op(ctx, "truncate")
+}
+
+func (f *truncateTarget) InsertRows(ctx context.Context, _ string, _ packet.Schema, _ []packet.Row, strategy adapters.ImportStrategy) error {
+	f.inserts = append(f.inserts, strategy)
+	return f.op(ctx, "insert")
+}
+
+func (f *truncateTarget) BeginTx(context.Context) (adapters.Tx, error) {
+	f.tx = &importTx{}
+	return f.tx, nil
+}
+
+// importTx запоминает, чем закончилась транзакция.
+type importTx struct{ done []string }
+
+func (t *importTx) Commit(context.Context) error {
+	t.done = append(t.done, "commit")
+	return nil
+}
+
+func (t *importTx) Rollback(context.Context) error {
+	t.done = append(t.done, "rollback")
+	return nil
+}
+
+func truncatePackets() []*packet.DataPacket {
+	var packets []*packet.DataPacket
+	for range 2 {
+		pkt := packet.NewDataPacket(packet.TypeReference, "sales")
+		pkt.Schema = packet.Schema{Fields: []packet.Field{{Name: "id", Type: "INTEGER", Key: true}}}
+		pkt.Data.Rows = []packet.Row{{Value: "1"}}
+		packets = append(packets, pkt)
+	}
+	return packets
+}
+
+func TestImportPackets_Truncate(t *testing.T) {
+	target := &truncateTarget{exists: true}
+	h := NewImportHelper(target, target, target, false)
+	if err := h.ImportPackets(context.Background(), truncatePackets(), adapters.StrategyTruncate); err != nil {
+		t.Fatalf("ImportPackets: %v", err)
+	}
+
+	// Очистка один раз, затем вставка каждого пакета — всё в транзакции импорта
+	want := []string{"truncate in tx", "insert in tx", "insert in tx"}
+	if !slices.Equal(target.ops, want) {
+		t.Errorf("ops = %v, want %v", target.ops, want)
+	}
+	if !slices.Equal(target.tx.done, []string{"commit"}) {
+		t.Errorf("tx = %v, want commit", target.tx.done)
+	}
+	for _, s := range target.inserts {
+		if s != adapters.StrategyTruncate {
+			t.Errorf("InsertRows strategy = %s, want truncate", s)
+		}
+	}
+}
+
+func TestImportPackets_TruncateNewTable(t *testing.T) {
+	// Таблицы нет — создаётся, очищать нечего
+	target := &truncateTarget{}
+	h := NewImportHelper(target, target, target, false)
+	if err := h.ImportPackets(context.Background(), truncatePackets(), adapters.StrategyTruncate); err != nil {
+		t.Fatalf("ImportPackets: %v", err)
+	}
+	want := []string{"create in tx", "insert in tx", "insert in tx"}
+	if !slices.Equal(target.ops, want) {
+		t.Errorf("ops = %v, want %v", target.ops, want)
+	}
+}
+
+func TestImportPackets_TruncateRollback(t *testing.T) {
+	// Ошибка загрузки откатывает и очистку
+	target := &truncateTarget{exists: true, failAt: "insert in tx"}
+	h := NewImportHelper(target, target, target, false)
+	if err := h.ImportPackets(context.Background(), truncatePackets(), adapters.StrategyTruncate); err == nil {
+		t.Fatal("ImportPackets: want error")
+	}
+	if !slices.Equal(target.tx.done, []string{"rollback"}) {
+		t.Errorf("tx = %v, want rollback", target.tx.done)
+	}
+}
+
+func TestImportPackets_Append(t *testing.T) {
+	// Append — обычная вставка без очистки и без транзакции импорта в ctx
+	target := &truncateTarget{exists: true}
+	h := NewImportHelper(target, target, target, false)
+	if err := h.ImportPackets(context.Background(), truncatePackets(), adapters.StrategyAppend); err != nil {
+		t.Fatalf("ImportPackets: %v", err)
+	}
+	want := []string{"insert", "insert"}
+	if !slices.Equal(target.ops, want) {
+		t.Errorf("ops = %v, want %v", target.ops, want)
+	}
+}
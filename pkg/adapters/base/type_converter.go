@@ -10,9 +10,11 @@ import (
 	"math"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
 	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
 	"github.com/ruslano69/tdtp-framework/pkg/core/schema"
 )
@@ -27,6 +29,13 @@ const NullSentinel = "\x00"
 type UniversalTypeConverter struct {
 	converter       *schema.Converter
 	noDateSentinels map[string]bool // "1900-01-01", "1753-01-01" etc — MSSQL configured sentinels
+
+	boolDefault *schema.BoolMapping           // текстовое представление BOOLEAN (nil — 1/0)
+	boolColumns map[string]schema.BoolMapping // по колонкам, ключ в нижнем регистре
+
+	sourceLoc *time.Location // часовой пояс naive datetime источника (nil — UTC)
+	tzMu      sync.Mutex
+	tzReport  *adapters.TimezoneReport
 }
 
 // NewUniversalTypeConverter создает новый UniversalTypeConverter
@@ -46,6 +55,36 @@ func (c *UniversalTypeConverter) SetNoDateSentinels(dates []string) {
 	}
 }
 
+// SetBoolMappings задаёт текстовое представление BOOLEAN (adapters.Config.Booleans
+// и ColumnBooleans): при экспорте "Y"/"Да" → "1", при импорте 1 → TrueValues[0].
+func (c *UniversalTypeConverter) SetBoolMappings(def *schema.BoolMapping, columns map[string]schema.BoolMapping) error {
+	if def != nil {
+		if err := def.Validate(); err != nil {
+			return err
+		}
+	}
+	c.boolDefault = def
+	c.boolColumns = make(map[string]schema.BoolMapping, len(columns))
+	for name, m := range columns {
+		if err := m.Validate(); err != nil {
+			return fmt.Errorf("column %s: %w", name, err)
+		}
+		c.boolColumns[strings.ToLower(name)] = m
+	}
+	return nil
+}
+
+// BoolMapping возвращает маппинг BOOLEAN для колонки (nil — стандартные 1/0)
+func (c *UniversalTypeConverter) BoolMapping(column string) *schema.BoolMapping {
+	if c == nil {
+		return nil
+	}
+	if m, ok := c.boolColumns[strings.ToLower(column)]; ok {
+		return &m
+	}
+	return c.boolDefault
+}
+
 // ConvertValueToTDTP конвертирует значение из БД в TDTP формат
 // Общая реализация (вместо 4 копий в адаптерах)
 func (c *UniversalTypeConverter) ConvertValueToTDTP(field packet.Field, value string) string {
@@ -54,6 +93,21 @@ func (c *UniversalTypeConverter) ConvertValueToTDTP(field packet.Field, value st
 		return NullSentinel
 	}
 
+	// BOOLEAN, хранимый текстом (Y/N, Да/Нет) → "1"/"0"
+	if value != "1" && value != "0" && schema.NormalizeType(schema.DataType(field.Type)) == schema.TypeBoolean {
+		if m := c.BoolMapping(field.Name); m != nil {
+			b, err := m.Parse(value)
+			if err != nil {
+				log.Printf("Failed to parse field %s (type %s): %v", field.Name, field.Type, err)
+				return value
+			}
+			if b {
+				return "1"
+			}
+			return "0"
+		}
+	}
+
 	// Fast path: типы, для которых ParseValue→FormatValue — холостой ход.
 	// DBValueToString уже выдал корректную строку через strconv/time.Format,
 	// повторный round-trip (string→TypedValue→string) ничего не меняет.
@@ -65,6 +119,13 @@ func (c *UniversalTypeConverter) ConvertValueToTDTP(field packet.Field, value st
 		return value
 	}
 
+	// Строка без смещения (SQLite, текстовые колонки) — местное время источника
+	if c.sourceLoc != nil && isDatetimeField(field) {
+		if t, ok := parseNaiveDatetime(value); ok {
+			return c.sourceToUTC(field.Name, t).Format(time.RFC3339)
+		}
+	}
+
 	// Создаем FieldDef для использования converter
 	fieldDef := schema.FieldDef{
 		Name:      field.Name,
@@ -101,7 +162,7 @@ func (c *UniversalTypeConverter) DBValueToString(value any, field packet.Field,
 		return c.pgValueToString(value, field)
 	case "mssql":
 		return c.mssqlValueToString(value, field)
-	case "sqlite", "mysql", "access":
+	case "sqlite", "mysql", "access", "oracle":
 		return c.genericValueToString(value, field)
 	default:
 		// Логируем неизвестный dbType для debugging
@@ -213,6 +274,9 @@ func (c *UniversalTypeConverter) pgValueToString(val any, field packet.Field) st
 		if field.Subtype == "time" {
 			return v.Format("15:04:05") // HH:MM:SS
 		}
+		if c.sourceLoc != nil && isNaiveDatetimeField(field) {
+			v = c.sourceToUTC(field.Name, v)
+		}
 		// Timestamp в RFC3339 формате (TDTP стандарт)
 		// Нормализуем в UTC для consistency
 		return v.UTC().Format(time.RFC3339)
@@ -252,6 +316,10 @@ func (c *UniversalTypeConverter) pgValueToString(val any, field packet.Field) st
 		case pgtype.NegativeInfinity:
 			return "-Infinity"
 		}
+		// timestamp without time zone — всегда naive
+		if c.sourceLoc != nil {
+			return c.sourceToUTC(field.Name, v.Time).Format(time.RFC3339)
+		}
 		return v.Time.UTC().Format(time.RFC3339)
 
 	case pgtype.Timestamptz:
@@ -397,6 +465,9 @@ func (c *UniversalTypeConverter) mssqlValueToString(val any, field packet.Field)
 		if c.noDateSentinels[dateOnly] {
 			return packet.SpecNoDateMarker
 		}
+		if c.sourceLoc != nil && isNaiveDatetimeField(field) {
+			v = c.sourceToUTC(field.Name, v)
+		}
 		// DATETIME, DATETIME2, DATETIMEOFFSET - конвертируем в RFC3339 для TDTP
 		// ВАЖНО: нормализуем в UTC для консистентности
 		return v.UTC().Format(time.RFC3339)
@@ -466,6 +537,9 @@ func (c *UniversalTypeConverter) genericValueToString(val any, field packet.Fiel
 		if v.IsZero() {
 			return packet.SpecNoDateMarker
 		}
+		if c.sourceLoc != nil && isNaiveDatetimeField(field) {
+			v = c.sourceToUTC(field.Name, v)
+		}
 		// Конвертируем в RFC3339 для TDTP (консистентность с MSSQL и PostgreSQL)
 		return v.UTC().Format(time.RFC3339)
 
@@ -544,8 +618,8 @@ func (c *UniversalTypeConverter) TypedValueToSQL(tv schema.TypedValue, dbType st
 
 	case schema.TypeBoolean, schema.TypeBool:
 		if tv.BoolValue != nil {
-			// SQLite использует 1/0 для boolean
-			if dbType == "sqlite" {
+			// SQLite и Oracle (NUMBER(1)) используют 1/0 для boolean
+			if dbType == "sqlite" || dbType == "oracle" {
 				if *tv.BoolValue {
 					return 1
 				}
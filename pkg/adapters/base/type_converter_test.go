@@ -0,0 +1,64 @@
+package base
+
+import (
+	"testing"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+	"github.com/ruslano69/tdtp-framework/pkg/core/schema"
+)
+
+func TestBoolMappings_Export(t *testing.T) {
+	c := NewUniversalTypeConverter()
+	err := c.SetBoolMappings(
+		&schema.BoolMapping{TrueValues: []string{"Y"}, FalseValues: []string{"N"}},
+		map[string]schema.BoolMapping{"IS_ACTIVE": {TrueValues: []string{"Да"}, FalseValues: []string{"Нет"}}},
+	)
+	if err != nil {
+		t.Fatalf("SetBoolMappings: %v", err)
+	}
+
+	tests := []struct {
+		column, value, want string
+	}{
+		{"deleted", "Y", "1"},
+		{"deleted", "n", "0"},
+		{"deleted", "1", "1"}, // драйвер вернул bool/int
+		{"is_active", "да", "1"},
+		{"is_active", "Нет", "0"},
+		{"is_active", "Y", "Y"}, // не из маппинга колонки — как есть
+	}
+	for _, tt := range tests {
+		got := c.ConvertValueToTDTP(packet.Field{Name: tt.column, Type: "BOOLEAN"}, tt.value)
+		if got != tt.want {
+			t.Errorf("%s=%q: got %q, want %q", tt.column, tt.value, got, tt.want)
+		}
+	}
+
+	// TEXT не затрагивается
+	if got := c.ConvertValueToTDTP(packet.Field{Name: "flag", Type: "TEXT"}, "Y"); got != "Y" {
+		t.Errorf("TEXT value changed: %q", got)
+	}
+}
+
+func TestBoolMappings_Import(t *testing.T) {
+	c := NewUniversalTypeConverter()
+	if err := c.SetBoolMappings(nil, map[string]schema.BoolMapping{"active": {TrueValues: []string{"T"}, FalseValues: []string{"F"}}}); err != nil {
+		t.Fatalf("SetBoolMappings: %v", err)
+	}
+
+	s := packet.Schema{Fields: []packet.Field{
+		{Name: "Active", Type: "BOOLEAN"},
+		{Name: "verified", Type: "BOOLEAN"},
+	}}
+	args, err := ConvertRowToSQLValues([]string{"0", "1"}, s, c, "sqlite")
+	if err != nil {
+		t.Fatalf("ConvertRowToSQLValues: %v", err)
+	}
+	if args[0] != "F" || args[1] != 1 {
+		t.Errorf("args = %#v, want [\"F\" 1]", args)
+	}
+
+	if err := c.SetBoolMappings(&schema.BoolMapping{TrueValues: []string{"Y"}}, nil); err == nil {
+		t.Error("expected error for mapping without false values")
+	}
+}
@@ -0,0 +1,17 @@
+package adapters
+
+import "github.com/ruslano69/tdtp-framework/pkg/core/packet"
+
+// ColumnCipher — шифрование выбранных колонок at rest (base.ColumnCipher).
+// Адаптер с Config.ColumnCipher шифрует колонки каждого импортируемого
+// пакета после его открытия (расшифровка секций, распаковка) и
+// расшифровывает их в каждом экспортируемом пакете до сжатия и шифрования
+// секций — одинаково для CLI, брокера, синхронизации, ETL и tdtpserve.
+type ColumnCipher interface {
+	// EncryptPacket шифрует назначенные колонки открытого пакета и
+	// возвращает число зашифрованных значений.
+	EncryptPacket(pkt *packet.DataPacket) (int, error)
+	// DecryptPacket расшифровывает колонки пакета, ключи которых есть;
+	// остальные остаются зашифрованными (locked) и помечаются Field.Encryption.
+	DecryptPacket(pkt *packet.DataPacket) (decrypted, locked int, err error)
+}
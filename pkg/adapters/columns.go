@@ -0,0 +1,61 @@
+package adapters
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ColumnMatching — как поля пакета сопоставляются с колонками существующей
+// таблицы при импорте. Значения строк всегда пишутся по именам колонок,
+// порядок колонок таблицы значения не имеет.
+type ColumnMatching string
+
+const (
+	// ColumnsByName — поля сопоставляются с колонками по имени без учёта
+	// регистра (по умолчанию). Поле, которого нет в таблице, — ошибка
+	// *ColumnMismatchError; колонки, которых нет в пакете, получают
+	// DEFAULT или NULL.
+	ColumnsByName ColumnMatching = ""
+	// ColumnsSkipExtra — как ColumnsByName, но поля, которых нет в таблице,
+	// импорт пропускает с предупреждением (кроме ключевых).
+	ColumnsSkipExtra ColumnMatching = "skip-extra"
+	// ColumnsStrict — набор полей пакета должен совпадать с колонками
+	// таблицы, иначе импорт завершается ошибкой *ColumnMismatchError.
+	ColumnsStrict ColumnMatching = "strict"
+)
+
+// ErrColumnMismatch — поля пакета не совпадают с колонками таблицы.
+var ErrColumnMismatch = errors.New("packet columns do not match the table")
+
+// Validate проверяет режим.
+func (m ColumnMatching) Validate() error {
+	switch m {
+	case ColumnsByName, ColumnsSkipExtra, ColumnsStrict:
+		return nil
+	}
+	return fmt.Errorf("invalid columns mode %q (expected skip-extra, strict or empty)", m)
+}
+
+// ColumnMismatchError — расхождение полей пакета и колонок таблицы.
+// Сопоставляется с ErrColumnMismatch через errors.Is.
+type ColumnMismatchError struct {
+	Table     string
+	MessageID string
+	Extra     []string // поля пакета, которых нет в таблице
+	Missing   []string // колонки таблицы, которых нет в пакете
+}
+
+func (e *ColumnMismatchError) Error() string {
+	var parts []string
+	if len(e.Extra) > 0 {
+		parts = append(parts, "not in table: "+strings.Join(e.Extra, ", "))
+	}
+	if len(e.Missing) > 0 {
+		parts = append(parts, "not in packet: "+strings.Join(e.Missing, ", "))
+	}
+	return fmt.Sprintf("%s: packet %s: columns do not match the table (%s)",
+		e.Table, e.MessageID, strings.Join(parts, "; "))
+}
+
+func (e *ColumnMismatchError) Unwrap() error { return ErrColumnMismatch }
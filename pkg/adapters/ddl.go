@@ -0,0 +1,79 @@
+package adapters
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+// ========== Генерация DDL без подключения ==========
+
+// DDLGenerator — адаптер, строящий DDL таблицы по TDTP схеме без выполнения
+// и без подключения к БД. CreateTable адаптера выполняет тот же DDL, поэтому
+// сгенерированный текст совпадает с тем, что создал бы импорт.
+type DDLGenerator interface {
+	// GenerateDDL возвращает CREATE TABLE и сопутствующие операторы
+	// (COMMENT ON COLUMN, ...) без завершающих ';'
+	GenerateDDL(tableName string, schema packet.Schema) []string
+}
+
+// GenerateDDL строит DDL таблицы в диалекте dbType: адаптер создаётся без
+// подключения (NewWithoutConnect), операторы разделяются ";\n\n".
+// Удобно для подготовки целевой БД и ревью перед автоматическим созданием.
+//
+// Имена длиннее ограничения СУБД укорачиваются так же, как при импорте
+// (FitIdentifiers); переименования перечислены комментариями "--" в начале.
+//
+//	ddl, err := adapters.GenerateDDL("mssql", "orders", pkt.Schema)
+func GenerateDDL(dbType, tableName string, schema packet.Schema) (string, error) {
+	adapter, err := NewWithoutConnect(dbType)
+	if err != nil {
+		return "", err
+	}
+	gen, ok := adapter.(DDLGenerator)
+	if !ok {
+		return "", fmt.Errorf("adapter %s does not support DDL generation", dbType)
+	}
+	if len(schema.Fields) == 0 {
+		return "", fmt.Errorf("schema of %s has no fields", tableName)
+	}
+
+	var header strings.Builder
+	if limiter, ok := adapter.(IdentifierLimiter); ok {
+		// Копия полей: схема вызывающего не меняется
+		schema.Fields = append([]packet.Field(nil), schema.Fields...)
+		limit := limiter.IdentifierLimit()
+		var renames []IdentifierRename
+		tableName, renames, err = FitIdentifiers(tableName, &schema, limit)
+		if err != nil {
+			return "", err
+		}
+		for _, r := range renames {
+			kind := "column"
+			if r.Table {
+				kind = "table"
+			}
+			fmt.Fprintf(&header, "-- %s %s shortened to %s (%s limit %d)\n", kind, r.Original, r.Short, dbType, limit.MaxLength)
+		}
+		if header.Len() > 0 {
+			header.WriteString("\n")
+		}
+	}
+
+	return header.String() + strings.Join(gen.GenerateDDL(tableName, schema), ";\n\n") + ";\n", nil
+}
+
+// ColumnComment возвращает текст комментария колонки для DDL: описание из
+// источника (Field.Description) и исходное имя поля, переименованного
+// санитизацией ("original: X"). Пустая строка — комментарий не нужен.
+func ColumnComment(field packet.Field) string {
+	if field.OriginalName == "" {
+		return field.Description
+	}
+	original := "original: " + field.OriginalName
+	if field.Description == "" {
+		return original
+	}
+	return field.Description + "\n" + original
+}
@@ -0,0 +1,180 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+// ========== Пробный импорт ==========
+
+// MaxReportedRowErrors и MaxReportedConflicts — сколько строк с ошибками
+// преобразования и конфликтующих ключей перечисляет TableImportReport
+// (счётчики — по всем строкам).
+const (
+	MaxReportedRowErrors = 20
+	MaxReportedConflicts = 20
+)
+
+// DryRunImporter — адаптер умеет пробный импорт: пакеты проверяются против
+// целевой БД (совместимость схемы, преобразование каждого значения к типу
+// колонки, конфликты первичного ключа с существующими строками), в БД
+// ничего не пишется.
+type DryRunImporter interface {
+	DryRunImport(ctx context.Context, packets []*packet.DataPacket, strategy ImportStrategy) (*ImportReport, error)
+}
+
+// ImportReport — отчёт импорта по таблицам: пробного (DryRunImporter) или
+// настоящего с политикой ошибок строк (ReportingImporter).
+type ImportReport struct {
+	Strategy ImportStrategy
+	Tables   []*TableImportReport
+}
+
+// TableImportReport — что импорт сделал бы (или сделал) с одной таблицей.
+type TableImportReport struct {
+	Table   string
+	Exists  bool // таблица есть; иначе импорт создаст её по схеме пакета
+	Packets int
+	Rows    int
+
+	// Errors — проблемы, на которых импорт остановится (ключевые колонки
+	// пакета, которых нет в таблице, любое расхождение колонок в режиме
+	// strict, повторяющиеся ключи в режиме fail).
+	Errors []string
+	// Warnings — расхождения, которые импорт переживёт (пропускаемые
+	// колонки пакета, другой тип колонки, другой первичный ключ,
+	// непроверенные delta-пакеты).
+	Warnings []string
+
+	// RowErrors — значения, не приводимые к типу колонки таблицы (первые
+	// MaxReportedRowErrors); RowErrorCount — по всем строкам. При импорте
+	// с RowErrorsSkip/RowErrorsDeadLetter — отклонённые строки, а Rows —
+	// записанные.
+	RowErrors     []RowError
+	RowErrorCount int
+
+	// DeadLetters — карантинные пакеты с отклонёнными строками
+	// (RowErrorsDeadLetter).
+	DeadLetters []string
+
+	// RolledBack — пакеты, откатанные по ошибке СУБД при
+	// RowErrorConfig.PartialRollback ("<MessageID>: <ошибка>");
+	// RolledBackRows — их строки, в Rows они не входят.
+	RolledBack     []string
+	RolledBackRows int
+
+	// Conflicts — строки, ключ которых уже есть в таблице: replace их
+	// обновит, ignore пропустит, fail завершится ошибкой.
+	// ConflictKeys — первые MaxReportedConflicts ключей.
+	Conflicts    int
+	ConflictKeys [][]string
+}
+
+// RowError — значение строки пакета, не прошедшее преобразование типа.
+type RowError struct {
+	Packet int // номер пакета таблицы (с 1)
+	Row    int // номер строки в пакете (с 1)
+	Field  string
+	Value  string
+	Err    string
+}
+
+// AddRowError учитывает ошибку преобразования (в списке — только первые
+// MaxReportedRowErrors).
+func (r *TableImportReport) AddRowError(e RowError) {
+	r.RowErrorCount++
+	if len(r.RowErrors) < MaxReportedRowErrors {
+		r.RowErrors = append(r.RowErrors, e)
+	}
+}
+
+// AddConflict учитывает строку с уже существующим ключом.
+func (r *TableImportReport) AddConflict(key []string) {
+	r.Conflicts++
+	if len(r.ConflictKeys) < MaxReportedConflicts {
+		r.ConflictKeys = append(r.ConflictKeys, key)
+	}
+}
+
+// problems — причины, по которым импорт таблицы со стратегией strategy не пройдёт.
+func (r *TableImportReport) problems(strategy ImportStrategy) []string {
+	problems := append([]string(nil), r.Errors...)
+	if r.RowErrorCount > 0 {
+		problems = append(problems, fmt.Sprintf("%d value(s) do not convert to column types", r.RowErrorCount))
+	}
+	if (strategy == StrategyFail || strategy == StrategyAppend) && r.Conflicts > 0 {
+		problems = append(problems, fmt.Sprintf("%d row(s) conflict with existing keys (strategy %s)", r.Conflicts, strategy))
+	}
+	return problems
+}
+
+// Err возвращает nil, если импорт по отчёту прошёл бы, иначе ошибку с
+// перечнем проблем по таблицам.
+func (r *ImportReport) Err() error {
+	var msgs []string
+	for _, t := range r.Tables {
+		if problems := t.problems(r.Strategy); len(problems) > 0 {
+			msgs = append(msgs, t.Table+": "+strings.Join(problems, "; "))
+		}
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("dry run: import would fail: %s", strings.Join(msgs, "; "))
+}
+
+// DryRunImport выполняет пробный импорт пакетов адаптером (DryRunImporter).
+func DryRunImport(ctx context.Context, a Adapter, packets []*packet.DataPacket, strategy ImportStrategy) (*ImportReport, error) {
+	importer, ok := a.(DryRunImporter)
+	if !ok {
+		return nil, fmt.Errorf("adapter %s does not support dry-run import", a.GetDatabaseType())
+	}
+	return importer.DryRunImport(ctx, packets, strategy)
+}
+
+// ImportWithOptions импортирует пакеты по opts: DryRun — только отчёт
+// (DryRunImport), Partition — ImportPartitionedReport, иначе ImportPackets
+// (ImportPacketsReport у ReportingImporter); Provenance передаётся
+// импорту через WithProvenance; с UpdateStatistics — обновление
+// статистики таблицы. Отчёт возвращается для DryRun и от
+// ReportingImporter.
+func ImportWithOptions(ctx context.Context, a Adapter, packets []*packet.DataPacket, opts ImportOptions) (*ImportReport, error) {
+	if len(opts.MergeColumns) > 0 {
+		ctx = WithMergeColumns(ctx, opts.MergeColumns...)
+	}
+	if opts.Provenance {
+		ctx = WithProvenance(ctx, time.Now())
+	}
+	if opts.DryRun {
+		if opts.Partition != nil {
+			return nil, fmt.Errorf("dry-run import cannot be combined with partition routing")
+		}
+		return DryRunImport(ctx, a, packets, opts.Strategy)
+	}
+	if opts.Partition != nil {
+		return ImportPartitionedReport(ctx, a, packets, opts)
+	}
+	if len(packets) == 0 {
+		return nil, nil
+	}
+	var report *ImportReport
+	var err error
+	if importer, ok := a.(ReportingImporter); ok {
+		report, err = importer.ImportPacketsReport(ctx, packets, opts.Strategy)
+	} else {
+		err = a.ImportPackets(ctx, packets, opts.Strategy)
+	}
+	if err != nil {
+		return report, err
+	}
+	if opts.UpdateStatistics {
+		if _, err := UpdateStatistics(ctx, a, packets[0].Header.TableName); err != nil {
+			fmt.Printf("  ⚠ %v\n", err)
+		}
+	}
+	return report, nil
+}
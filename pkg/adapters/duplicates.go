@@ -0,0 +1,70 @@
+package adapters
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// DuplicateMode — что делать со строками одного пакета с одинаковым
+// первичным ключом. Без обработки UPSERT таких строк недетерминирован:
+// результат зависит от того, как СУБД разложит их по батчам.
+type DuplicateMode string
+
+const (
+	// DuplicatesOff — строки пишутся как есть (по умолчанию).
+	DuplicatesOff DuplicateMode = ""
+	// DuplicatesKeepFirst — остаётся первая строка с ключом.
+	DuplicatesKeepFirst DuplicateMode = "keep-first"
+	// DuplicatesKeepLast — остаётся последняя строка с ключом.
+	DuplicatesKeepLast DuplicateMode = "keep-last"
+	// DuplicatesFail — импорт пакета завершается ошибкой *DuplicateKeysError.
+	DuplicatesFail DuplicateMode = "fail"
+)
+
+// ErrDuplicateKeys — в пакете есть строки с одинаковым первичным ключом
+// (режим DuplicatesFail).
+var ErrDuplicateKeys = errors.New("duplicate primary keys in packet")
+
+// Validate проверяет режим.
+func (m DuplicateMode) Validate() error {
+	switch m {
+	case DuplicatesOff, DuplicatesKeepFirst, DuplicatesKeepLast, DuplicatesFail:
+		return nil
+	}
+	return fmt.Errorf("invalid duplicates mode %q (expected keep-first, keep-last or fail)", m)
+}
+
+// DuplicateKey — значение ключа и номера строк пакета (с 1), где оно встретилось.
+type DuplicateKey struct {
+	Key  []string
+	Rows []int
+}
+
+// DuplicateKeysError — отчёт о повторяющихся ключах пакета. Сопоставляется
+// с ErrDuplicateKeys через errors.Is.
+type DuplicateKeysError struct {
+	Table     string
+	MessageID string
+	KeyFields []string
+	Keys      []DuplicateKey
+}
+
+// maxReportedDuplicates — сколько ключей перечисляет текст ошибки.
+const maxReportedDuplicates = 10
+
+func (e *DuplicateKeysError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: packet %s: %d key(s) of (%s) repeat:",
+		e.Table, e.MessageID, len(e.Keys), strings.Join(e.KeyFields, ", "))
+	for i, k := range e.Keys {
+		if i == maxReportedDuplicates {
+			b.WriteString(" ...")
+			break
+		}
+		fmt.Fprintf(&b, " [%s] rows %v;", strings.Join(k.Key, ", "), k.Rows)
+	}
+	return strings.TrimSuffix(b.String(), ";")
+}
+
+func (e *DuplicateKeysError) Unwrap() error { return ErrDuplicateKeys }
@@ -0,0 +1,146 @@
+package adapters
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+// ColumnExclusions — служебные колонки, которые не выдаются при экспорте
+// (rowversion, артефакты репликации, аудит). Правила задаются по таблицам,
+// "*" — для всех таблиц:
+//
+//	exclude_columns:
+//	  "*": [rowversion, "^_repl_"]
+//	  orders: [internal_note, "^audit_"]
+//
+// Правило без метасимволов регулярных выражений — точное имя колонки,
+// иначе — регулярное выражение. Имена таблиц и колонок сравниваются без
+// учёта регистра. Исключённые колонки удаляются из схемы и строк пакета
+// (Apply) до контрольных сумм и сжатия, поэтому пакет остаётся
+// согласованным.
+type ColumnExclusions struct {
+	tables map[string][]*regexp.Regexp // ключ — имя таблицы в нижнем регистре или "*"
+}
+
+// NewColumnExclusions разбирает правила; пустые правила — nil.
+func NewColumnExclusions(rules map[string][]string) (*ColumnExclusions, error) {
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	e := &ColumnExclusions{tables: make(map[string][]*regexp.Regexp, len(rules))}
+	for table, patterns := range rules {
+		key := strings.ToLower(strings.TrimSpace(table))
+		for _, p := range patterns {
+			re, err := compileExclusion(p)
+			if err != nil {
+				return nil, fmt.Errorf("exclude_columns %s: %w", table, err)
+			}
+			e.tables[key] = append(e.tables[key], re)
+		}
+	}
+	return e, nil
+}
+
+// compileExclusion — точное имя или регулярное выражение без учёта регистра.
+func compileExclusion(pattern string) (*regexp.Regexp, error) {
+	pattern = strings.TrimSpace(pattern)
+	if pattern == "" {
+		return nil, fmt.Errorf("empty column pattern")
+	}
+	if !strings.ContainsAny(pattern, `^$*+?()[]{}|\`) {
+		pattern = "^" + regexp.QuoteMeta(pattern) + "$"
+	}
+	re, err := regexp.Compile("(?i)" + pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid column pattern %q: %w", pattern, err)
+	}
+	return re, nil
+}
+
+// Excluded сообщает, исключена ли колонка таблицы.
+func (e *ColumnExclusions) Excluded(table, column string) bool {
+	if e == nil {
+		return false
+	}
+	for _, key := range []string{strings.ToLower(table), "*"} {
+		for _, re := range e.tables[key] {
+			if re.MatchString(column) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// CheckQuery отклоняет запрос, проекция которого явно называет исключённую
+// колонку: молча выдать пакет без запрошенной колонки хуже, чем отказать.
+// Фильтры и сортировка по исключённым колонкам допустимы — их значения в
+// пакет не попадают.
+func (e *ColumnExclusions) CheckQuery(table string, query *packet.Query) error {
+	if e == nil || query == nil {
+		return nil
+	}
+	for _, name := range query.Fields {
+		if e.Excluded(table, name) {
+			return fmt.Errorf("column %s.%s is excluded from export (export.exclude_columns)", table, name)
+		}
+	}
+	return nil
+}
+
+// Apply удаляет исключённые колонки из схемы и строк пакета и возвращает
+// их имена. Пакет должен быть несжатым; ключевые колонки не исключаются —
+// без них приёмник не сможет применить пакет.
+func (e *ColumnExclusions) Apply(pkt *packet.DataPacket) ([]string, error) {
+	if e == nil {
+		return nil, nil
+	}
+	var keep []int
+	var removed []string
+	for i, f := range pkt.Schema.Fields {
+		if !e.Excluded(pkt.Header.TableName, f.Name) {
+			keep = append(keep, i)
+			continue
+		}
+		if f.Key {
+			return nil, fmt.Errorf("exclude_columns: %s.%s is a key column and cannot be excluded", pkt.Header.TableName, f.Name)
+		}
+		removed = append(removed, f.Name)
+	}
+	if len(removed) == 0 {
+		return nil, nil
+	}
+	if pkt.Data.Compression != "" {
+		return nil, fmt.Errorf("exclude_columns: packet must be decompressed before filtering")
+	}
+	if pkt.Data.Delta || pkt.Data.Delete {
+		return nil, fmt.Errorf("exclude_columns is not supported for delta and delete packets")
+	}
+	keepColumns(pkt, keep)
+	return removed, nil
+}
+
+// keepColumns оставляет в схеме и строках пакета только колонки keep
+// (индексы по возрастанию).
+func keepColumns(pkt *packet.DataPacket, keep []int) {
+	pkt.MaterializeRows()
+	parser := packet.NewParser()
+	for r, row := range pkt.Data.Rows {
+		values := parser.GetRowValues(row)
+		out := make([]string, 0, len(keep))
+		for _, i := range keep {
+			if i < len(values) {
+				out = append(out, values[i])
+			}
+		}
+		pkt.Data.Rows[r] = packet.Row{Value: packet.JoinRowEscaped(out)}
+	}
+	fields := make([]packet.Field, 0, len(keep))
+	for _, i := range keep {
+		fields = append(fields, pkt.Schema.Fields[i])
+	}
+	pkt.Schema.Fields = fields
+}
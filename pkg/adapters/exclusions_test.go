@@ -0,0 +1,81 @@
+package adapters
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+func ordersPacket(t *testing.T) *packet.DataPacket {
+	t.Helper()
+	schema := packet.Schema{Fields: []packet.Field{
+		{Name: "id", Type: "INTEGER", Key: true},
+		{Name: "total", Type: "DECIMAL", Precision: 10, Scale: 2},
+		{Name: "RowVersion", Type: "BLOB"},
+		{Name: "audit_user", Type: "TEXT", Length: 50},
+		{Name: "_repl_origin", Type: "TEXT", Length: 20},
+	}}
+	rows := [][]string{{"1", "10.00", "AAE=", "bob", "a"}, {"2", "5.50", "AAI=", "eve", "b"}}
+	packets, err := packet.NewGenerator().GenerateReference("orders", schema, rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return packets[0]
+}
+
+func TestColumnExclusions_Apply(t *testing.T) {
+	e, err := NewColumnExclusions(map[string][]string{
+		"*":      {"rowversion", "^_repl_"},
+		"ORDERS": {"^audit_"},
+		"events": {"event_time"},
+	})
+	if err != nil {
+		t.Fatalf("NewColumnExclusions: %v", err)
+	}
+	pkt := ordersPacket(t)
+	removed, err := e.Apply(pkt)
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if want := []string{"RowVersion", "audit_user", "_repl_origin"}; !reflect.DeepEqual(removed, want) {
+		t.Errorf("removed = %v, want %v", removed, want)
+	}
+	if len(pkt.Schema.Fields) != 2 || pkt.Data.Rows[1].Value != "2|5.50" {
+		t.Errorf("schema %+v, row %q", pkt.Schema.Fields, pkt.Data.Rows[1].Value)
+	}
+
+	// Правило другой таблицы не применяется; точное имя — не префикс.
+	if e.Excluded("orders", "event_time") || e.Excluded("orders", "rowversion_old") {
+		t.Error("unexpected exclusion")
+	}
+	var none *ColumnExclusions
+	if removed, err := none.Apply(ordersPacket(t)); removed != nil || err != nil {
+		t.Errorf("nil exclusions: %v, %v", removed, err)
+	}
+}
+
+func TestColumnExclusions_Errors(t *testing.T) {
+	if _, err := NewColumnExclusions(map[string][]string{"orders": {"^audit_("}}); err == nil {
+		t.Error("invalid regexp must fail")
+	}
+	if _, err := NewColumnExclusions(map[string][]string{"orders": {" "}}); err == nil {
+		t.Error("empty pattern must fail")
+	}
+
+	e, _ := NewColumnExclusions(map[string][]string{"orders": {"id"}})
+	if _, err := e.Apply(ordersPacket(t)); err == nil {
+		t.Error("key column must not be excluded")
+	}
+
+	e, _ = NewColumnExclusions(map[string][]string{"orders": {"total"}})
+	query := packet.NewQuery()
+	query.Fields = []string{"id", "TOTAL"}
+	if err := e.CheckQuery("orders", query); err == nil {
+		t.Error("projection of an excluded column must fail")
+	}
+	query.Fields = nil
+	if err := e.CheckQuery("orders", query); err != nil {
+		t.Errorf("CheckQuery: %v", err)
+	}
+}
@@ -0,0 +1,22 @@
+package adapters
+
+import "context"
+
+type recipientKey struct{}
+
+// WithRecipient задаёт получателя экспорта для методов без параметра
+// recipient (ExportTable, ExportTableIncremental): пакеты получают его в
+// Header.Recipient, и по нему применяется Config.ExportPolicy. Пустой
+// получатель ctx не меняет.
+func WithRecipient(ctx context.Context, recipient string) context.Context {
+	if recipient == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, recipientKey{}, recipient)
+}
+
+// RecipientFromContext возвращает получателя из WithRecipient ("" — нет).
+func RecipientFromContext(ctx context.Context) string {
+	recipient, _ := ctx.Value(recipientKey{}).(string)
+	return recipient
+}
@@ -2,10 +2,14 @@ package adapters
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 )
 
+// ErrConnect — адаптер не смог подключиться к БД (Factory.Create).
+var ErrConnect = errors.New("failed to connect")
+
 // AdapterConstructor - функция-конструктор адаптера
 // Возвращает новый экземпляр адаптера (еще не подключенный к БД)
 type AdapterConstructor func() Adapter
@@ -90,7 +94,7 @@ func (f *Factory) Create(ctx context.Context, cfg Config) (Adapter, error) {
 
 	// Подключаемся к БД
 	if err := adapter.Connect(ctx, cfg); err != nil {
-		return nil, fmt.Errorf("failed to connect to %s: %w", cfg.Type, err)
+		return nil, fmt.Errorf("%w to %s: %w", ErrConnect, cfg.Type, err)
 	}
 
 	return adapter, nil
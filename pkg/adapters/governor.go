@@ -0,0 +1,172 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ImportLimits — ограничения нагрузки импорта на целевую БД.
+// Нулевое значение — без ограничений.
+type ImportLimits struct {
+	// MaxRowsPerSec — средняя скорость записи, строк/с (token bucket с
+	// запасом на одну секунду). 0 — без лимита.
+	MaxRowsPerSec float64
+
+	// MaxConcurrentTx — сколько транзакций импорта этого адаптера могут
+	// выполняться одновременно. 0 — без лимита.
+	MaxConcurrentTx int
+
+	// TargetBatchLatency включает адаптацию скорости (AIMD, работает вместе с
+	// MaxRowsPerSec): транзакция дольше цели — признак того, что БД занята
+	// OLTP-нагрузкой, и лимит скорости уменьшается вдвое; быстрее цели —
+	// лимит растёт на 5% от MaxRowsPerSec, но не выше него.
+	TargetBatchLatency time.Duration
+
+	// MinRowsPerSec — нижняя граница адаптивного лимита
+	// (по умолчанию 5% от MaxRowsPerSec).
+	MinRowsPerSec float64
+}
+
+// Enabled сообщает, задано ли хотя бы одно ограничение.
+func (l ImportLimits) Enabled() bool {
+	return l.MaxRowsPerSec > 0 || l.MaxConcurrentTx > 0
+}
+
+// Governor ограничивает темп импорта, чтобы поток пакетов не вытеснял
+// рабочую нагрузку продуктивной БД. Безопасен для параллельного использования;
+// nil-Governor ничего не ограничивает.
+type Governor struct {
+	limits ImportLimits
+	slots  chan struct{} // семафор транзакций; nil — без лимита
+
+	mu     sync.Mutex
+	rate   float64   // текущий лимит строк/с (≤ MaxRowsPerSec)
+	tokens float64   // может уходить в минус: долг оплачивается ожиданием
+	last   time.Time // момент последнего пополнения
+
+	now   func() time.Time
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// NewGovernor создаёт Governor; для пустых limits возвращает nil.
+func NewGovernor(limits ImportLimits) (*Governor, error) {
+	if limits.MaxRowsPerSec < 0 || limits.MaxConcurrentTx < 0 || limits.MinRowsPerSec < 0 {
+		return nil, fmt.Errorf("import limits must not be negative")
+	}
+	if !limits.Enabled() {
+		return nil, nil
+	}
+	if limits.TargetBatchLatency > 0 && limits.MaxRowsPerSec == 0 {
+		return nil, fmt.Errorf("target batch latency requires max rows per second")
+	}
+	if limits.MinRowsPerSec == 0 {
+		limits.MinRowsPerSec = limits.MaxRowsPerSec * 0.05
+	}
+	g := &Governor{
+		limits: limits,
+		rate:   limits.MaxRowsPerSec,
+		tokens: limits.MaxRowsPerSec, // полный запас на старте
+		now:    time.Now,
+		sleep:  sleepCtx,
+	}
+	g.last = g.now()
+	if limits.MaxConcurrentTx > 0 {
+		g.slots = make(chan struct{}, limits.MaxConcurrentTx)
+	}
+	return g, nil
+}
+
+// Do выполняет одну транзакцию импорта rows строк: ждёт свободный слот
+// транзакции и «оплачивает» строки по лимиту скорости, затем вызывает fn и
+// по её длительности подстраивает лимит.
+func (g *Governor) Do(ctx context.Context, rows int, fn func() error) error {
+	if g == nil {
+		return fn()
+	}
+	if g.slots != nil {
+		select {
+		case g.slots <- struct{}{}:
+			defer func() { <-g.slots }()
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	if err := g.waitRows(ctx, rows); err != nil {
+		return err
+	}
+
+	start := g.now()
+	err := fn()
+	if err == nil {
+		g.observe(g.now().Sub(start))
+	}
+	return err
+}
+
+// Rate возвращает текущий лимит скорости (строк/с); 0 — без лимита.
+func (g *Governor) Rate() float64 {
+	if g == nil {
+		return 0
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.rate
+}
+
+// waitRows резервирует rows строк и ждёт, пока долг не будет оплачен.
+// Резервирование до ожидания делает очередь справедливой: параллельные
+// транзакции выстраиваются друг за другом, а не соревнуются за токены.
+func (g *Governor) waitRows(ctx context.Context, rows int) error {
+	if g.limits.MaxRowsPerSec == 0 || rows <= 0 {
+		return nil
+	}
+	g.mu.Lock()
+	now := g.now()
+	g.tokens = min(g.tokens+now.Sub(g.last).Seconds()*g.rate, g.rate)
+	g.last = now
+	g.tokens -= float64(rows)
+	var wait time.Duration
+	if g.tokens < 0 {
+		wait = time.Duration(-g.tokens / g.rate * float64(time.Second))
+	}
+	g.mu.Unlock()
+
+	if wait == 0 {
+		return nil
+	}
+	if err := g.sleep(ctx, wait); err != nil {
+		// Отменённое ожидание возвращает резерв.
+		g.mu.Lock()
+		g.tokens += float64(rows)
+		g.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// observe — AIMD-подстройка лимита по длительности транзакции.
+func (g *Governor) observe(latency time.Duration) {
+	if g.limits.TargetBatchLatency <= 0 {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if latency > g.limits.TargetBatchLatency {
+		g.rate = max(g.rate/2, g.limits.MinRowsPerSec)
+	} else {
+		g.rate = min(g.rate+g.limits.MaxRowsPerSec*0.05, g.limits.MaxRowsPerSec)
+	}
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
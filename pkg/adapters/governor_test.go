@@ -0,0 +1,195 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeClock — управляемое время для Governor: sleep продвигает часы.
+type fakeClock struct {
+	mu    sync.Mutex
+	t     time.Time
+	slept time.Duration
+}
+
+func (c *fakeClock) now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.t
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.mu.Lock()
+	c.t = c.t.Add(d)
+	c.mu.Unlock()
+}
+
+func (c *fakeClock) sleep(ctx context.Context, d time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	c.t = c.t.Add(d)
+	c.slept += d
+	c.mu.Unlock()
+	return nil
+}
+
+func newTestGovernor(t *testing.T, limits ImportLimits) (*Governor, *fakeClock) {
+	t.Helper()
+	g, err := NewGovernor(limits)
+	if err != nil {
+		t.Fatalf("NewGovernor: %v", err)
+	}
+	clock := &fakeClock{t: time.Unix(1_700_000_000, 0)}
+	g.now, g.sleep, g.last = clock.now, clock.sleep, clock.now()
+	return g, clock
+}
+
+func TestNewGovernor(t *testing.T) {
+	if g, err := NewGovernor(ImportLimits{}); g != nil || err != nil {
+		t.Errorf("empty limits: got %v, %v; want nil, nil", g, err)
+	}
+	bad := []ImportLimits{
+		{MaxRowsPerSec: -1},
+		{MaxConcurrentTx: -2},
+		{MaxConcurrentTx: 1, TargetBatchLatency: time.Second},
+	}
+	for _, l := range bad {
+		if _, err := NewGovernor(l); err == nil {
+			t.Errorf("NewGovernor(%+v): expected error", l)
+		}
+	}
+}
+
+func TestGovernor_NilPassesThrough(t *testing.T) {
+	var g *Governor
+	called := false
+	if err := g.Do(context.Background(), 1000, func() error { called = true; return nil }); err != nil {
+		t.Fatal(err)
+	}
+	if !called || g.Rate() != 0 {
+		t.Errorf("called=%v rate=%v", called, g.Rate())
+	}
+}
+
+func TestGovernor_RateLimit(t *testing.T) {
+	g, clock := newTestGovernor(t, ImportLimits{MaxRowsPerSec: 1000})
+	noop := func() error { return nil }
+
+	// Стартовый запас — одна секунда: первая 1000 строк без ожидания.
+	if err := g.Do(context.Background(), 1000, noop); err != nil {
+		t.Fatal(err)
+	}
+	if clock.slept != 0 {
+		t.Fatalf("first batch slept %v, want 0", clock.slept)
+	}
+	// Следующие 500 строк ждут 0.5 с.
+	if err := g.Do(context.Background(), 500, noop); err != nil {
+		t.Fatal(err)
+	}
+	if clock.slept != 500*time.Millisecond {
+		t.Errorf("slept %v, want 500ms", clock.slept)
+	}
+	// Простой пополняет запас не больше чем на секунду.
+	clock.advance(10 * time.Second)
+	clock.slept = 0
+	if err := g.Do(context.Background(), 1500, noop); err != nil {
+		t.Fatal(err)
+	}
+	if clock.slept != 500*time.Millisecond {
+		t.Errorf("after idle slept %v, want 500ms", clock.slept)
+	}
+}
+
+func TestGovernor_CancelledWait(t *testing.T) {
+	g, _ := newTestGovernor(t, ImportLimits{MaxRowsPerSec: 100})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	called := false
+	err := g.Do(ctx, 500, func() error { called = true; return nil })
+	if !errors.Is(err, context.Canceled) || called {
+		t.Fatalf("err=%v called=%v", err, called)
+	}
+	if g.tokens != 100 {
+		t.Errorf("tokens=%v, reserve must be refunded", g.tokens)
+	}
+}
+
+func TestGovernor_MaxConcurrentTx(t *testing.T) {
+	g, err := NewGovernor(ImportLimits{MaxConcurrentTx: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var active, peak atomic.Int32
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = g.Do(context.Background(), 10, func() error {
+				n := active.Add(1)
+				for {
+					p := peak.Load()
+					if n <= p || peak.CompareAndSwap(p, n) {
+						break
+					}
+				}
+				time.Sleep(5 * time.Millisecond)
+				active.Add(-1)
+				return nil
+			})
+		}()
+	}
+	wg.Wait()
+	if peak.Load() > 2 {
+		t.Errorf("peak concurrency %d, want <= 2", peak.Load())
+	}
+}
+
+func TestGovernor_AIMD(t *testing.T) {
+	g, clock := newTestGovernor(t, ImportLimits{
+		MaxRowsPerSec:      1000,
+		MinRowsPerSec:      100,
+		TargetBatchLatency: 100 * time.Millisecond,
+	})
+	batch := func(latency time.Duration) func() error {
+		return func() error { clock.advance(latency); return nil }
+	}
+
+	// Медленные транзакции: мультипликативное снижение до нижней границы.
+	for _, want := range []float64{500, 250, 125, 100, 100} {
+		if err := g.Do(context.Background(), 1, batch(time.Second)); err != nil {
+			t.Fatal(err)
+		}
+		if got := g.Rate(); got != want {
+			t.Fatalf("rate after slow batch = %v, want %v", got, want)
+		}
+	}
+	// Быстрые: аддитивный рост на 5% от максимума, не выше максимума.
+	if err := g.Do(context.Background(), 1, batch(10*time.Millisecond)); err != nil {
+		t.Fatal(err)
+	}
+	if got := g.Rate(); got != 150 {
+		t.Fatalf("rate after fast batch = %v, want 150", got)
+	}
+	for range 30 {
+		_ = g.Do(context.Background(), 1, batch(10*time.Millisecond))
+	}
+	if got := g.Rate(); got != 1000 {
+		t.Errorf("rate = %v, want capped at 1000", got)
+	}
+
+	// Ошибка транзакции не влияет на лимит.
+	failing := func() error { clock.advance(time.Second); return errors.New("boom") }
+	if err := g.Do(context.Background(), 1, failing); err == nil {
+		t.Fatal("expected error")
+	}
+	if got := g.Rate(); got != 1000 {
+		t.Errorf("rate after failed batch = %v, want 1000", got)
+	}
+}
@@ -0,0 +1,152 @@
+package adapters
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+// ========== Ограничения длины идентификаторов ==========
+
+// IdentifierLimit — максимальная длина имени таблицы/колонки целевой СУБД.
+// Длинные имена источника СУБД обрезает молча (PostgreSQL) или отвергает
+// (Oracle, MSSQL), а обрезанные имена могут совпасть друг с другом.
+type IdentifierLimit struct {
+	MaxLength int  // 0 — без ограничения
+	Bytes     bool // длина в байтах UTF-8 (PostgreSQL, Oracle), иначе в символах
+}
+
+// IdentifierLimiter — адаптер сообщает ограничение длины идентификаторов
+// (с учётом режима совместимости: Oracle 11g/12c — 30 байт, 19c — 128).
+type IdentifierLimiter interface {
+	IdentifierLimit() IdentifierLimit
+}
+
+// IdentifierRename — идентификатор, укороченный под ограничение СУБД
+type IdentifierRename struct {
+	Table    bool   // true — имя таблицы, иначе колонка
+	Original string // имя в пакете
+	Short    string // имя в целевой СУБД
+}
+
+// shortHashLen — длина суффикса "_" + 8 hex символов FNV-1a
+const shortHashLen = 9
+
+// length возвращает длину имени в единицах ограничения
+func (l IdentifierLimit) length(name string) int {
+	if l.Bytes {
+		return len(name)
+	}
+	return utf8.RuneCountInString(name)
+}
+
+// ShortenIdentifier укорачивает имя под ограничение детерминированно:
+// префикс имени + "_" + 8 hex символов хеша FNV-1a полного имени.
+// Одно и то же имя всегда даёт один и тот же результат, поэтому повторный
+// импорт и все части multi-part пакета попадают в те же колонки.
+//
+//	ShortenIdentifier("customer_billing_address_line_2", {MaxLength: 30, Bytes: true})
+//	→ "customer_billing_addr_df07f3d0"
+func ShortenIdentifier(name string, limit IdentifierLimit) string {
+	if limit.MaxLength <= shortHashLen || limit.length(name) <= limit.MaxLength {
+		return name
+	}
+
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(name))
+	suffix := fmt.Sprintf("_%08x", h.Sum32())
+
+	// Префикс режется по границе символа: в байтовом режиме — не более
+	// MaxLength-9 байт, в символьном — не более MaxLength-9 символов
+	budget := limit.MaxLength - shortHashLen
+	var prefix strings.Builder
+	used := 0
+	for _, r := range name {
+		size := 1
+		if limit.Bytes {
+			size = utf8.RuneLen(r)
+		}
+		if used+size > budget {
+			break
+		}
+		prefix.WriteRune(r)
+		used += size
+	}
+	return strings.TrimRight(prefix.String(), "_") + suffix
+}
+
+// FitIdentifiers укорачивает имя таблицы и колонки схемы под ограничение.
+// Укороченные колонки получают OriginalName (если он ещё не задан
+// санитизацией), и адаптеры сохраняют исходное имя в комментарии колонки.
+// Схема меняется на месте; квалификатор схемы БД ("sales.") не трогается.
+//
+// Возвращает новое имя таблицы и список переименований. Ошибка — если
+// укороченное имя совпало с другой колонкой (без учёта регистра).
+func FitIdentifiers(tableName string, schema *packet.Schema, limit IdentifierLimit) (string, []IdentifierRename, error) {
+	if limit.MaxLength == 0 {
+		return tableName, nil, nil
+	}
+
+	var renames []IdentifierRename
+
+	qualifier, table := "", tableName
+	if i := strings.LastIndex(tableName, "."); i >= 0 {
+		qualifier, table = tableName[:i+1], tableName[i+1:]
+	}
+	if short := ShortenIdentifier(table, limit); short != table {
+		renames = append(renames, IdentifierRename{Table: true, Original: table, Short: short})
+		tableName = qualifier + short
+	}
+
+	seen := make(map[string]string, len(schema.Fields))
+	for _, field := range schema.Fields {
+		seen[strings.ToLower(field.Name)] = field.Name
+	}
+	for i := range schema.Fields {
+		field := &schema.Fields[i]
+		short := ShortenIdentifier(field.Name, limit)
+		if short == field.Name {
+			continue
+		}
+		if other, ok := seen[strings.ToLower(short)]; ok {
+			return "", nil, fmt.Errorf("column %q shortened to %q collides with column %q", field.Name, short, other)
+		}
+		seen[strings.ToLower(short)] = field.Name
+
+		renames = append(renames, IdentifierRename{Original: field.Name, Short: short})
+		if field.OriginalName == "" {
+			field.OriginalName = field.Name
+		}
+		field.Name = short
+	}
+
+	return tableName, renames, nil
+}
+
+// FitPacketIdentifiers укорачивает идентификаторы всех пакетов под
+// ограничение адаптера (IdentifierLimiter); адаптеры без ограничения
+// пакеты не меняют. Переименования возвращаются по первому пакету —
+// у частей одной выгрузки они одинаковые.
+func FitPacketIdentifiers(adapter Adapter, packets []*packet.DataPacket) ([]IdentifierRename, error) {
+	limiter, ok := adapter.(IdentifierLimiter)
+	if !ok {
+		return nil, nil
+	}
+	limit := limiter.IdentifierLimit()
+
+	var renames []IdentifierRename
+	for i, pkt := range packets {
+		tableName, r, err := FitIdentifiers(pkt.Header.TableName, &pkt.Schema, limit)
+		if err != nil {
+			return nil, fmt.Errorf("table %s: %w", pkt.Header.TableName, err)
+		}
+		pkt.Header.TableName = tableName
+		if i == 0 {
+			renames = r
+		}
+	}
+	return renames, nil
+}
@@ -0,0 +1,84 @@
+package adapters
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+func TestShortenIdentifier(t *testing.T) {
+	oracle := IdentifierLimit{MaxLength: 30, Bytes: true}
+
+	if got := ShortenIdentifier("customer_id", oracle); got != "customer_id" {
+		t.Errorf("short name changed: %s", got)
+	}
+
+	long := "customer_billing_address_line_2"
+	got := ShortenIdentifier(long, oracle)
+	if len(got) > 30 || !strings.HasPrefix(got, "customer_billing_add") {
+		t.Errorf("ShortenIdentifier(%s) = %s (%d bytes)", long, got, len(got))
+	}
+	if again := ShortenIdentifier(long, oracle); again != got {
+		t.Errorf("not deterministic: %s vs %s", got, again)
+	}
+	if other := ShortenIdentifier(long+"x", oracle); other == got {
+		t.Errorf("different names shortened to the same %s", got)
+	}
+
+	// Кириллица: 2 байта на символ — байтовый режим режет по границе символа
+	cyr := strings.Repeat("адрес_", 10)
+	got = ShortenIdentifier(cyr, oracle)
+	if len(got) > 30 || !strings.HasPrefix(got, "адрес_") {
+		t.Errorf("ShortenIdentifier(cyrillic, bytes) = %s (%d bytes)", got, len(got))
+	}
+	// Символьный режим (MSSQL): 60 символов в 128 помещаются
+	if got := ShortenIdentifier(cyr, IdentifierLimit{MaxLength: 128}); got != cyr {
+		t.Errorf("ShortenIdentifier(cyrillic, chars) = %s, want unchanged", got)
+	}
+}
+
+func TestFitIdentifiers(t *testing.T) {
+	limit := IdentifierLimit{MaxLength: 30, Bytes: true}
+	long := "customer_billing_address_line_2"
+	schema := packet.Schema{Fields: []packet.Field{
+		{Name: "id"},
+		{Name: long},
+		{Name: "Adres_dostavki_polnyi_s_indeksom", OriginalName: "Адрес доставки полный с индексом"},
+	}}
+
+	table, renames, err := FitIdentifiers("sales.customer_billing_addresses_archive", &schema, limit)
+	if err != nil {
+		t.Fatalf("FitIdentifiers: %v", err)
+	}
+	if !strings.HasPrefix(table, "sales.") || len(table) > len("sales.")+30 {
+		t.Errorf("table = %s", table)
+	}
+	if len(renames) != 3 || !renames[0].Table {
+		t.Fatalf("renames = %+v", renames)
+	}
+	if schema.Fields[0].Name != "id" || schema.Fields[0].OriginalName != "" {
+		t.Errorf("short field changed: %+v", schema.Fields[0])
+	}
+	if f := schema.Fields[1]; f.Name != ShortenIdentifier(long, limit) || f.OriginalName != long {
+		t.Errorf("long field: %+v", f)
+	}
+	// Исходное имя санитизации сохраняется
+	if f := schema.Fields[2]; f.OriginalName != "Адрес доставки полный с индексом" {
+		t.Errorf("sanitized field lost OriginalName: %+v", f)
+	}
+
+	// Укороченное имя совпало с существующей колонкой — ошибка, а не молчаливая перезапись
+	taken := packet.Schema{Fields: []packet.Field{
+		{Name: strings.ToUpper(ShortenIdentifier(long, limit))},
+		{Name: long},
+	}}
+	if _, _, err := FitIdentifiers("t", &taken, limit); err == nil {
+		t.Error("collision: want error")
+	}
+
+	// Без ограничения ничего не меняется
+	if _, renames, _ := FitIdentifiers("t", &packet.Schema{Fields: []packet.Field{{Name: long}}}, IdentifierLimit{}); renames != nil {
+		t.Errorf("no limit: renames = %+v", renames)
+	}
+}
@@ -1,5 +1,11 @@
 package adapters
 
+import "errors"
+
+// ErrTableNotFound — таблицы нет в БД (или у неё нет столбцов): ошибки
+// InspectTable и чтения схемы при экспорте оборачивают его через %w.
+var ErrTableNotFound = errors.New("not found or has no columns")
+
 // ColumnReport describes a single column in a live DB table.
 type ColumnReport struct {
 	Name       string `yaml:"name"`
@@ -0,0 +1,169 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// MaintenanceMode — поведение разрушающего импорта вне окна обслуживания.
+type MaintenanceMode string
+
+const (
+	// MaintenanceFail — сразу завершать импорт ошибкой ErrOutsideMaintenanceWindow
+	// (по умолчанию).
+	MaintenanceFail MaintenanceMode = "fail"
+	// MaintenanceWait — ждать открытия окна (не дольше MaxWait).
+	MaintenanceWait MaintenanceMode = "wait"
+)
+
+// ErrOutsideMaintenanceWindow — разрушающий импорт вне окна обслуживания
+// целевой таблицы.
+var ErrOutsideMaintenanceWindow = errors.New("outside maintenance window")
+
+// MaintenanceWindow — окно обслуживания: открывается по расписанию cron
+// (5 полей или @daily/@weekly) и длится Duration.
+//
+//	{Start: "0 2 * * *", Duration: 3 * time.Hour}   // ежедневно 02:00–05:00
+//	{Start: "0 22 * * 6", Duration: 8 * time.Hour}  // суббота 22:00 – воскресенье 06:00
+type MaintenanceWindow struct {
+	Start    string
+	Duration time.Duration
+}
+
+// MaintenanceWindows — окна, в которые разрешены разрушающие импорты:
+// замена таблицы через временную (StrategyCopy с DROP/RENAME) и очистка
+// (StrategyTruncate). Окна задаются по таблицам, "*" — для всех таблиц;
+// таблица без окон (и без "*") не ограничена. Окно проверяется на старте
+// импорта: начатый в окне импорт не прерывается при его закрытии.
+type MaintenanceWindows struct {
+	tables   map[string][]maintenanceWindow // ключ — имя таблицы в нижнем регистре или "*"
+	mode     MaintenanceMode
+	maxWait  time.Duration
+	location *time.Location
+	now      func() time.Time
+}
+
+type maintenanceWindow struct {
+	schedule cron.Schedule
+	duration time.Duration
+}
+
+// NewMaintenanceWindows разбирает окна по таблицам. mode "" — fail;
+// maxWait ограничивает ожидание в режиме wait (0 — до открытия окна);
+// расписания cron — во времени location (nil — локальное). Пустые
+// правила — nil (без ограничений).
+func NewMaintenanceWindows(rules map[string][]MaintenanceWindow, mode MaintenanceMode, maxWait time.Duration, location *time.Location) (*MaintenanceWindows, error) {
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	switch mode {
+	case "":
+		mode = MaintenanceFail
+	case MaintenanceFail, MaintenanceWait:
+	default:
+		return nil, fmt.Errorf("invalid maintenance window mode %q (expected fail or wait)", mode)
+	}
+	if location == nil {
+		location = time.Local
+	}
+	m := &MaintenanceWindows{
+		tables:   make(map[string][]maintenanceWindow, len(rules)),
+		mode:     mode,
+		maxWait:  maxWait,
+		location: location,
+		now:      time.Now,
+	}
+	for table, windows := range rules {
+		key := strings.ToLower(strings.TrimSpace(table))
+		for _, w := range windows {
+			schedule, err := cron.ParseStandard(w.Start)
+			if err != nil {
+				return nil, fmt.Errorf("maintenance window %s: invalid start %q: %w", table, w.Start, err)
+			}
+			if w.Duration <= 0 {
+				return nil, fmt.Errorf("maintenance window %s (%s): duration must be positive", table, w.Start)
+			}
+			m.tables[key] = append(m.tables[key], maintenanceWindow{schedule: schedule, duration: w.Duration})
+		}
+	}
+	return m, nil
+}
+
+// windows — окна таблицы: собственные, иначе "*".
+func (m *MaintenanceWindows) windows(table string) []maintenanceWindow {
+	if w, ok := m.tables[strings.ToLower(table)]; ok {
+		return w
+	}
+	return m.tables["*"]
+}
+
+// Open сообщает, открыто ли окно таблицы в момент now. Для закрытого окна
+// next — ближайшее открытие; таблица без окон всегда открыта.
+func (m *MaintenanceWindows) Open(table string, now time.Time) (open bool, next time.Time) {
+	if m == nil {
+		return true, time.Time{}
+	}
+	windows := m.windows(table)
+	if len(windows) == 0 {
+		return true, time.Time{}
+	}
+	now = now.In(m.location)
+	for _, w := range windows {
+		// Последний старт окна, который ещё не закончился, — первый старт
+		// после now-duration; он не позже now, если окно открыто.
+		start := w.schedule.Next(now.Add(-w.duration))
+		if !start.After(now) {
+			return true, time.Time{}
+		}
+		if next.IsZero() || start.Before(next) {
+			next = start
+		}
+	}
+	return false, next
+}
+
+// Await проверяет, что окна всех tables открыты. Вне окна в режиме fail —
+// ErrOutsideMaintenanceWindow, в режиме wait — ожидание открытия (не
+// дольше MaxWait; отмена ctx прерывает ожидание). nil — без ограничений.
+func (m *MaintenanceWindows) Await(ctx context.Context, tables ...string) error {
+	if m == nil {
+		return nil
+	}
+	var deadline time.Time
+	if m.maxWait > 0 {
+		deadline = m.now().Add(m.maxWait)
+	}
+	for {
+		now := m.now()
+		table, next := "", time.Time{}
+		for _, t := range tables {
+			if open, n := m.Open(t, now); !open {
+				table, next = t, n
+				break
+			}
+		}
+		if table == "" {
+			return nil
+		}
+		if m.mode != MaintenanceWait {
+			return fmt.Errorf("%s: %w (next window opens %s)", table, ErrOutsideMaintenanceWindow, next.Format(time.RFC3339))
+		}
+		if !deadline.IsZero() && next.After(deadline) {
+			return fmt.Errorf("%s: %w (next window opens %s, after max wait %s)", table, ErrOutsideMaintenanceWindow, next.Format(time.RFC3339), m.maxWait)
+		}
+		fmt.Printf("⏳ Table %s: destructive import waits for the maintenance window at %s\n", table, next.Format(time.RFC3339))
+
+		t := time.NewTimer(next.Sub(now))
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
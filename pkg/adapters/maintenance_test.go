@@ -0,0 +1,96 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMaintenanceWindows_Open(t *testing.T) {
+	m, err := NewMaintenanceWindows(map[string][]MaintenanceWindow{
+		"*":      {{Start: "0 2 * * *", Duration: 3 * time.Hour}},
+		"ORDERS": {{Start: "0 22 * * 6", Duration: 8 * time.Hour}},
+	}, "", 0, time.UTC)
+	if err != nil {
+		t.Fatalf("NewMaintenanceWindows: %v", err)
+	}
+	at := func(s string) time.Time {
+		ts, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ts
+	}
+	tests := []struct {
+		table, now string
+		open       bool
+		next       string
+	}{
+		{"sales", "2026-10-16T03:30:00Z", true, ""},
+		{"sales", "2026-10-16T05:00:00Z", false, "2026-10-17T02:00:00Z"},
+		{"sales", "2026-10-16T01:59:00Z", false, "2026-10-16T02:00:00Z"},
+		// Собственное окно таблицы заменяет "*"; окно переходит через полночь
+		{"orders", "2026-10-16T03:30:00Z", false, "2026-10-17T22:00:00Z"},
+		{"orders", "2026-10-18T05:59:00Z", true, ""},
+		{"orders", "2026-10-18T06:00:00Z", false, "2026-10-24T22:00:00Z"},
+	}
+	for _, tt := range tests {
+		open, next := m.Open(tt.table, at(tt.now))
+		if open != tt.open || (!open && !next.Equal(at(tt.next))) {
+			t.Errorf("Open(%s, %s) = %v, %s; want %v, %s", tt.table, tt.now, open, next, tt.open, tt.next)
+		}
+	}
+
+	var none *MaintenanceWindows
+	if open, _ := none.Open("sales", time.Now()); !open {
+		t.Error("nil windows must be open")
+	}
+}
+
+func TestMaintenanceWindows_Await(t *testing.T) {
+	rules := map[string][]MaintenanceWindow{"sales": {{Start: "0 2 * * *", Duration: time.Hour}}}
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	m, _ := NewMaintenanceWindows(rules, MaintenanceFail, 0, time.UTC)
+	m.now = func() time.Time { return now }
+	if err := m.Await(context.Background(), "customers", "sales"); !errors.Is(err, ErrOutsideMaintenanceWindow) {
+		t.Errorf("fail mode: err = %v", err)
+	}
+	if err := m.Await(context.Background(), "customers"); err != nil {
+		t.Errorf("table without windows: %v", err)
+	}
+
+	// Окно откроется через 14 часов — дольше max_wait
+	m, _ = NewMaintenanceWindows(rules, MaintenanceWait, time.Hour, time.UTC)
+	m.now = func() time.Time { return now }
+	if err := m.Await(context.Background(), "sales"); !errors.Is(err, ErrOutsideMaintenanceWindow) {
+		t.Errorf("wait beyond max_wait: err = %v", err)
+	}
+
+	// Ожидание прерывается отменой контекста
+	m, _ = NewMaintenanceWindows(rules, MaintenanceWait, 0, time.UTC)
+	m.now = func() time.Time { return now }
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := m.Await(ctx, "sales"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("canceled wait: err = %v", err)
+	}
+}
+
+func TestMaintenanceWindows_Invalid(t *testing.T) {
+	for name, rules := range map[string]map[string][]MaintenanceWindow{
+		"bad cron":      {"*": {{Start: "0 25 * * *", Duration: time.Hour}}},
+		"zero duration": {"*": {{Start: "0 2 * * *"}}},
+	} {
+		if _, err := NewMaintenanceWindows(rules, "", 0, nil); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+	if _, err := NewMaintenanceWindows(map[string][]MaintenanceWindow{"*": {{Start: "@daily", Duration: time.Hour}}}, "queue", 0, nil); err == nil {
+		t.Error("unknown mode must fail")
+	}
+	if m, err := NewMaintenanceWindows(nil, "", 0, nil); m != nil || err != nil {
+		t.Errorf("empty rules: %v, %v", m, err)
+	}
+}
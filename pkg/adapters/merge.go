@@ -0,0 +1,21 @@
+package adapters
+
+import "context"
+
+type mergeColumnsKey struct{}
+
+// WithMergeColumns задаёт колонки, которые импорт со StrategyMerge
+// обновляет у существующих строк (имена без учёта регистра). Пустой
+// список — все неключевые поля пакета.
+func WithMergeColumns(ctx context.Context, columns ...string) context.Context {
+	if len(columns) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, mergeColumnsKey{}, columns)
+}
+
+// MergeColumnsFromContext возвращает колонки из WithMergeColumns (nil — не заданы).
+func MergeColumnsFromContext(ctx context.Context) []string {
+	columns, _ := ctx.Value(mergeColumnsKey{}).([]string)
+	return columns
+}
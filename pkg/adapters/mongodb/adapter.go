@@ -0,0 +1,279 @@
+// Package mongodb реализует adapters.Adapter для MongoDB: коллекции
+// отображаются на таблицы, документы — на строки TDTP пакетов.
+//
+// Схемы у коллекции нет, поэтому GetTableSchema выводит её по выборке
+// документов (см. SetSampleSize): поля — объединение полей верхнего уровня,
+// BSON типы — в TDTP типы, вложенные документы и массивы — TEXT с
+// subtype "json" (relaxed Extended JSON), ObjectID — TEXT с subtype
+// "objectid". Subtype-ы позволяют вернуть исходные BSON типы при импорте
+// пакета обратно в MongoDB.
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/connstring"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+	"github.com/ruslano69/tdtp-framework/pkg/adapters/base"
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+// AdapterType идентификатор MongoDB адаптера
+const AdapterType = "mongodb"
+
+// defaultSampleSize — документов в выборке для вывода схемы коллекции
+const defaultSampleSize = 1000
+
+// Adapter реализует adapters.Adapter для MongoDB
+type Adapter struct {
+	client *mongo.Client
+	db     *mongo.Database
+	config adapters.Config
+
+	sampleSize  int  // выборка для вывода схемы (GetTableSchema)
+	txSupported bool // replica set / mongos: многодокументные транзакции
+	warnedNoTx  bool // предупреждение о неатомарной записи уже выведено
+
+	exportHelper *base.ExportHelper
+	converter    *base.UniversalTypeConverter
+	governor     *adapters.Governor
+	packetKeys   base.PacketKeyProvider // шифрование пакетов TDTP v1.5
+	keyMapper    base.KeyMapper         // суррогатные ключи при импорте
+	columnCipher adapters.ColumnCipher  // шифрование колонок at rest (Config.ColumnCipher)
+	queryLog     *adapters.QueryLogger  // журнал команд (Config.QueryLog)
+	duplicates   adapters.DuplicateMode // повторяющиеся ключи в пакете (base.DedupPacket)
+
+	// Настройки генератора для пакетов, которые адаптер собирает сам
+	// (инкрементальный экспорт)
+	maxMessageSize    int
+	skipSpecialValues bool
+}
+
+func init() {
+	adapters.Register(AdapterType, func() adapters.Adapter {
+		return &Adapter{}
+	})
+	adapters.Describe(adapters.DriverInfo{Type: AdapterType, Driver: "go.mongodb.org/mongo-driver"})
+}
+
+// Connect подключается к MongoDB.
+// БД берётся из пути DSN (mongodb://host:27017/shop); Config.Schema,
+// если задан, её переопределяет.
+func (a *Adapter) Connect(ctx context.Context, cfg adapters.Config) error {
+	governor, err := adapters.NewGovernor(cfg.ImportLimits)
+	if err != nil {
+		return fmt.Errorf("invalid import limits: %w", err)
+	}
+	if err := cfg.Duplicates.Validate(); err != nil {
+		return err
+	}
+	if err := cfg.Packets.Validate(); err != nil {
+		return err
+	}
+
+	cs, err := connstring.ParseAndValidate(cfg.DSN)
+	if err != nil {
+		return fmt.Errorf("invalid MongoDB connection string: %w", err)
+	}
+	dbName := cs.Database
+	if cfg.Schema != "" {
+		dbName = cfg.Schema
+	}
+	if dbName == "" {
+		return fmt.Errorf("database name is required: set it in the DSN path (mongodb://host/dbname) or in schema")
+	}
+
+	opts := options.Client().ApplyURI(cfg.DSN)
+	if cfg.MaxConns > 0 {
+		opts.SetMaxPoolSize(uint64(cfg.MaxConns))
+	}
+	if cfg.MinConns > 0 {
+		opts.SetMinPoolSize(uint64(cfg.MinConns))
+	}
+	if cfg.Timeout > 0 {
+		opts.SetTimeout(cfg.Timeout)
+	}
+	// Журнал команд: монитор ставится всегда, включается на лету
+	a.queryLog = adapters.NewQueryLogger(cfg.QueryLog)
+	opts.SetMonitor((&commandLog{log: a.queryLog}).monitor())
+
+	client, err := mongo.Connect(ctx, opts)
+	if err != nil {
+		return fmt.Errorf("failed to connect: %w", err)
+	}
+	if err := client.Ping(ctx, readpref.Primary()); err != nil {
+		_ = client.Disconnect(ctx)
+		return fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	a.client = client
+	a.db = client.Database(dbName)
+	a.config = cfg
+	a.governor = governor
+	a.duplicates = cfg.Duplicates
+	a.columnCipher = cfg.ColumnCipher
+	if a.sampleSize == 0 {
+		a.sampleSize = defaultSampleSize
+	}
+	a.txSupported = a.detectTransactions(ctx)
+
+	a.converter = base.NewUniversalTypeConverter()
+	a.exportHelper = base.NewExportHelper(
+		a,           // SchemaReader (вывод схемы по выборке)
+		a,           // DataReader + RowStreamer (курсор по коллекции)
+		a.converter, // ValueConverter
+		nil,         // SQL нет: TDTQL фильтруется в памяти
+	)
+	_ = a.exportHelper.SetPacketSizing(cfg.Packets) // проверено выше
+	a.exportHelper.SetColumnCipher(cfg.ColumnCipher)
+	a.exportHelper.SetExportPolicy(cfg.ExportPolicy)
+
+	return nil
+}
+
+// detectTransactions проверяет, поддерживает ли развёртывание
+// многодокументные транзакции: replica set или шардированный кластер
+// (mongos). Standalone сервер их не поддерживает.
+func (a *Adapter) detectTransactions(ctx context.Context) bool {
+	var hello struct {
+		SetName string `bson:"setName"`
+		Msg     string `bson:"msg"`
+	}
+	err := a.client.Database("admin").RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello)
+	return err == nil && (hello.SetName != "" || hello.Msg == "isdbgrid")
+}
+
+// SetSampleSize задаёт число документов, по которым выводится схема
+// коллекции. Поля, встречающиеся только за пределами выборки, в пакет не попадут.
+func (a *Adapter) SetSampleSize(n int) {
+	if n > 0 {
+		a.sampleSize = n
+	}
+}
+
+// QueryLog реализует adapters.QueryLogged: журнал команд адаптера.
+func (a *Adapter) QueryLog() *adapters.QueryLogger {
+	return a.queryLog
+}
+
+// Close закрывает соединение
+func (a *Adapter) Close(ctx context.Context) error {
+	if a.client != nil {
+		return a.client.Disconnect(ctx)
+	}
+	return nil
+}
+
+// Ping проверяет соединение
+func (a *Adapter) Ping(ctx context.Context) error {
+	return a.client.Ping(ctx, readpref.Primary())
+}
+
+// GetDatabaseType возвращает тип базы данных
+func (a *Adapter) GetDatabaseType() string {
+	return AdapterType
+}
+
+// GetDatabaseVersion возвращает версию MongoDB
+func (a *Adapter) GetDatabaseVersion(ctx context.Context) (string, error) {
+	var info struct {
+		Version string `bson:"version"`
+	}
+	if err := a.db.RunCommand(ctx, bson.D{{Key: "buildInfo", Value: 1}}).Decode(&info); err != nil {
+		return "", fmt.Errorf("failed to get version: %w", err)
+	}
+	return info.Version, nil
+}
+
+// TableExists проверяет существование коллекции (или view)
+func (a *Adapter) TableExists(ctx context.Context, tableName string) (bool, error) {
+	names, err := a.db.ListCollectionNames(ctx, bson.D{{Key: "name", Value: tableName}})
+	if err != nil {
+		return false, fmt.Errorf("failed to check collection: %w", err)
+	}
+	return len(names) > 0, nil
+}
+
+// AddColumns реализует adapters.ColumnAdder: у коллекции нет схемы, новые
+// поля появляются в документах при вставке.
+func (a *Adapter) AddColumns(context.Context, string, []packet.Field) error {
+	return nil
+}
+
+// GetTableNames возвращает список коллекций (без системных и views)
+func (a *Adapter) GetTableNames(ctx context.Context) ([]string, error) {
+	names, err := a.db.ListCollectionNames(ctx, bson.D{{Key: "type", Value: "collection"}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list collections: %w", err)
+	}
+
+	tables := names[:0]
+	for _, name := range names {
+		if !strings.HasPrefix(name, "system.") {
+			tables = append(tables, name)
+		}
+	}
+	slices.Sort(tables)
+	return tables, nil
+}
+
+// GetViewNames возвращает views: в MongoDB они всегда read-only
+func (a *Adapter) GetViewNames(ctx context.Context) ([]adapters.ViewInfo, error) {
+	names, err := a.db.ListCollectionNames(ctx, bson.D{{Key: "type", Value: "view"}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list views: %w", err)
+	}
+	slices.Sort(names)
+
+	views := make([]adapters.ViewInfo, 0, len(names))
+	for _, name := range names {
+		views = append(views, adapters.ViewInfo{Name: name, IsUpdatable: false})
+	}
+	return views, nil
+}
+
+// BeginTx начинает транзакцию в сессии. Операции транзакции выполняются
+// с контекстом сессии (mongoTx.Context); standalone сервер транзакций
+// не поддерживает.
+func (a *Adapter) BeginTx(ctx context.Context) (adapters.Tx, error) {
+	if !a.txSupported {
+		return nil, fmt.Errorf("transactions require a replica set or sharded cluster")
+	}
+	session, err := a.client.StartSession()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start session: %w", err)
+	}
+	if err := session.StartTransaction(); err != nil {
+		session.EndSession(ctx)
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	return &mongoTx{session: session}, nil
+}
+
+// mongoTx - обертка для mongo.Session для реализации adapters.Tx
+type mongoTx struct {
+	session mongo.Session
+}
+
+// Context возвращает контекст, операции в котором выполняются в транзакции
+func (t *mongoTx) Context(ctx context.Context) context.Context {
+	return mongo.NewSessionContext(ctx, t.session)
+}
+
+func (t *mongoTx) Commit(ctx context.Context) error {
+	defer t.session.EndSession(ctx)
+	return t.session.CommitTransaction(ctx)
+}
+
+func (t *mongoTx) Rollback(ctx context.Context) error {
+	defer t.session.EndSession(ctx)
+	return t.session.AbortTransaction(ctx)
+}
@@ -0,0 +1,269 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+	"github.com/ruslano69/tdtp-framework/pkg/adapters/base"
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+	"github.com/ruslano69/tdtp-framework/pkg/sync"
+)
+
+// ========== Публичные методы (делегируют в ExportHelper) ==========
+
+// SetSkipSpecialValues включает режим --fast: DetectAndApply пропускается.
+func (a *Adapter) SetSkipSpecialValues(skip bool) {
+	a.skipSpecialValues = skip
+	a.exportHelper.SetSkipSpecialValues(skip)
+}
+
+// SetMaxFallbackRows задаёт лимит документов для фильтрации TDTQL в памяти.
+func (a *Adapter) SetMaxFallbackRows(n int64) {
+	a.exportHelper.SetMaxFallbackRows(n)
+}
+
+// SetMaxMessageSize задаёт максимальный размер одного TDTP пакета в байтах.
+func (a *Adapter) SetMaxMessageSize(size int) {
+	a.maxMessageSize = size
+	a.exportHelper.SetMaxMessageSize(size)
+}
+
+// SetCompression включает zstd-сжатие Data экспортируемых пакетов
+// (см. base.ExportHelper.SetCompression).
+func (a *Adapter) SetCompression(opts packet.CompressionOptions) {
+	a.exportHelper.SetCompression(opts)
+}
+
+// SetPacketKeys включает шифрование пакетов TDTP v1.5 при экспорте и их
+// расшифровку при импорте (см. base.ExportHelper.SetPacketKeys).
+func (a *Adapter) SetPacketKeys(keys base.PacketKeyProvider) {
+	a.packetKeys = keys
+	a.exportHelper.SetPacketKeys(keys)
+}
+
+// SetKeyMapper задаёт выдачу суррогатных ключей при импорте (keymap.Mapper).
+func (a *Adapter) SetKeyMapper(m base.KeyMapper) {
+	a.keyMapper = m
+}
+
+// ExportTable экспортирует всю коллекцию - просто делегируем
+func (a *Adapter) ExportTable(ctx context.Context, tableName string) ([]*packet.DataPacket, error) {
+	return a.exportHelper.ExportTable(ctx, tableName)
+}
+
+// ExportTableWithQuery экспортирует с TDTQL фильтрацией. SQL у MongoDB нет:
+// фильтры применяются в памяти, на больших коллекциях — потоком по курсору.
+func (a *Adapter) ExportTableWithQuery(ctx context.Context, tableName string, query *packet.Query, sender, recipient string) ([]*packet.DataPacket, error) {
+	return a.exportHelper.ExportTableWithQuery(ctx, tableName, query, sender, recipient)
+}
+
+// ExportTableIncremental экспортирует документы с tracking полем больше
+// InitialValue, по возрастанию (OrderBy DESC — по убыванию), не больше
+// BatchSize. Возвращает значение tracking поля последнего документа.
+func (a *Adapter) ExportTableIncremental(ctx context.Context, tableName string, incrementalConfig adapters.IncrementalConfig) ([]*packet.DataPacket, string, error) {
+	if err := incrementalConfig.Validate(); err != nil {
+		return nil, "", fmt.Errorf("invalid incremental config: %w", err)
+	}
+	if incrementalConfig.Deletes.Strategy == sync.DeleteLog {
+		return nil, "", fmt.Errorf("delete log is read separately with its own checkpoint (sync.ReadDeleteLog)")
+	}
+	if incrementalConfig.Strategy == sync.TrackingCDC || incrementalConfig.Strategy == sync.TrackingChangeTable {
+		return nil, "", fmt.Errorf("%s tracking is not supported for MongoDB adapter", incrementalConfig.Strategy)
+	}
+
+	schema, err := a.GetTableSchema(ctx, tableName)
+	if err != nil {
+		return nil, "", err
+	}
+	trackingIdx := -1
+	for i, field := range schema.Fields {
+		if field.Name == incrementalConfig.TrackingField {
+			trackingIdx = i
+			break
+		}
+	}
+	if trackingIdx < 0 {
+		return nil, "", fmt.Errorf("tracking field '%s' not found in collection schema", incrementalConfig.TrackingField)
+	}
+	trackingField := schema.Fields[trackingIdx]
+
+	filter := bson.D{}
+	if incrementalConfig.InitialValue != "" {
+		last, err := a.rowToDocument([]string{incrementalConfig.InitialValue}, packet.Schema{Fields: []packet.Field{trackingField}})
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid initial value: %w", err)
+		}
+		if len(last) > 0 {
+			filter = bson.D{{Key: trackingField.Name, Value: bson.D{{Key: "$gt", Value: last[0].Value}}}}
+		}
+	}
+
+	order := 1
+	if strings.EqualFold(incrementalConfig.OrderBy, "DESC") {
+		order = -1
+	}
+	opts := options.Find().SetSort(bson.D{{Key: trackingField.Name, Value: order}})
+	if incrementalConfig.BatchSize > 0 {
+		opts.SetLimit(int64(incrementalConfig.BatchSize))
+	}
+
+	var rows [][]string
+	err = a.streamDocuments(ctx, tableName, schema, filter, opts, func(row []string) error {
+		rows = append(rows, row)
+		return nil
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read incremental data: %w", err)
+	}
+	if len(rows) == 0 {
+		return []*packet.DataPacket{}, incrementalConfig.InitialValue, nil
+	}
+	lastValue := rows[len(rows)-1][trackingIdx]
+
+	// Мягко удалённые документы уходят ключами в пакеты удаления (tombstones)
+	var deletedKeys [][]string
+	if incrementalConfig.Deletes.Strategy == sync.DeleteSoft {
+		rows, deletedKeys, err = sync.SplitSoftDeleted(schema, rows, incrementalConfig.Deletes)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	var packets []*packet.DataPacket
+	if len(rows) > 0 {
+		generator := packet.NewGenerator()
+		_ = a.config.Packets.Apply(generator) // проверено в Connect
+		if a.maxMessageSize > 0 {
+			generator.SetMaxMessageSize(a.maxMessageSize)
+		}
+		if a.skipSpecialValues {
+			generator.SetSkipSpecialValues(true)
+		}
+		packets, err = generator.GenerateReference(tableName, schema, rows)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to generate packets: %w", err)
+		}
+	}
+	tombstones, err := sync.TombstonePackets(tableName, schema, deletedKeys)
+	if err != nil {
+		return nil, "", err
+	}
+	packets = append(packets, tombstones...)
+	if packets, err = a.exportHelper.Seal(ctx, packets); err != nil {
+		return nil, "", err
+	}
+	return packets, lastValue, nil
+}
+
+// ========== base.SchemaReader interface ==========
+
+// GetTableSchema выводит схему коллекции по первым SetSampleSize документам
+func (a *Adapter) GetTableSchema(ctx context.Context, tableName string) (packet.Schema, error) {
+	inferrer, err := a.sample(ctx, tableName)
+	if err != nil {
+		return packet.Schema{}, err
+	}
+	if inferrer.docs == 0 {
+		exists, err := a.TableExists(ctx, tableName)
+		if err != nil {
+			return packet.Schema{}, err
+		}
+		if !exists {
+			return packet.Schema{}, fmt.Errorf("collection %s not found", tableName)
+		}
+	}
+	return inferrer.schema(), nil
+}
+
+// sample читает выборку документов коллекции для вывода схемы.
+func (a *Adapter) sample(ctx context.Context, tableName string) (*schemaInferrer, error) {
+	cursor, err := a.db.Collection(tableName).Find(ctx, bson.D{}, options.Find().SetLimit(int64(a.sampleSize)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to sample collection %s: %w", tableName, err)
+	}
+	defer func() { _ = cursor.Close(ctx) }()
+
+	inferrer := newSchemaInferrer()
+	for cursor.Next(ctx) {
+		if err := inferrer.add(cursor.Current); err != nil {
+			return nil, err
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("failed to sample collection %s: %w", tableName, err)
+	}
+	return inferrer, nil
+}
+
+// ========== base.DataReader interface ==========
+
+// ReadAllRows читает все документы коллекции
+func (a *Adapter) ReadAllRows(ctx context.Context, tableName string, schema packet.Schema) ([][]string, error) {
+	var rows [][]string
+	err := a.StreamAllRows(ctx, tableName, schema, func(row []string) error {
+		rows = append(rows, row)
+		return nil
+	})
+	return rows, err
+}
+
+// ReadRowsWithSQL — SQL у MongoDB нет: ExportHelper фильтрует в памяти
+func (a *Adapter) ReadRowsWithSQL(ctx context.Context, sql string, schema packet.Schema) ([][]string, error) {
+	return nil, base.ErrSQLUnsupported
+}
+
+// GetRowCount возвращает количество документов в коллекции
+func (a *Adapter) GetRowCount(ctx context.Context, tableName string) (int64, error) {
+	n, err := a.db.Collection(tableName).CountDocuments(ctx, bson.D{})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count documents: %w", err)
+	}
+	return n, nil
+}
+
+// StreamAllRows читает коллекцию курсором, документ за документом.
+// Реализует base.RowStreamer
+func (a *Adapter) StreamAllRows(ctx context.Context, tableName string, schema packet.Schema, fn func(row []string) error) error {
+	return a.streamDocuments(ctx, tableName, schema, bson.D{}, options.Find(), fn)
+}
+
+// streamDocuments выполняет find и отдаёт документы строками TDTP в порядке полей схемы.
+func (a *Adapter) streamDocuments(
+	ctx context.Context,
+	tableName string,
+	schema packet.Schema,
+	filter bson.D,
+	opts *options.FindOptions,
+	fn func(row []string) error,
+) error {
+	cursor, err := a.db.Collection(tableName).Find(ctx, filter, opts)
+	if err != nil {
+		return fmt.Errorf("failed to query collection %s: %w", tableName, err)
+	}
+	defer func() { _ = cursor.Close(ctx) }()
+
+	for cursor.Next(ctx) {
+		if err := fn(documentToRow(cursor.Current, schema)); err != nil {
+			return err
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return fmt.Errorf("failed to read collection %s: %w", tableName, err)
+	}
+	return nil
+}
+
+// documentToRow раскладывает документ по полям схемы.
+func documentToRow(doc bson.Raw, schema packet.Schema) []string {
+	row := make([]string, len(schema.Fields))
+	for i, field := range schema.Fields {
+		v, err := doc.LookupErr(field.Name)
+		row[i] = valueToTDTP(v, err == nil, field)
+	}
+	return row
+}
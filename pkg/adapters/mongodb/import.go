@@ -0,0 +1,337 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+	"github.com/ruslano69/tdtp-framework/pkg/adapters/base"
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+// Compile-time check: пакеты удаления (Data delete="true")
+var _ base.DeleteApplier = (*Adapter)(nil)
+
+// importBatchSize — документов в одном InsertMany/BulkWrite
+const importBatchSize = 1000
+
+// ImportPacket импортирует один пакет в коллекцию TableName.
+// Коллекция создаётся MongoDB при первой вставке.
+//
+// Стратегии:
+//
+//	replace — upsert по ключевым полям (ReplaceOne); без ключей — вставка
+//	ignore  — вставка, документы с существующим ключом пропускаются
+//	fail    — вставка, дубликат ключа — ошибка
+//	copy    — как fail (массовая вставка)
+//	append  — как fail
+//	truncate — deleteMany всей коллекции и вставка в одной транзакции
+func (a *Adapter) ImportPacket(ctx context.Context, pkt *packet.DataPacket, strategy adapters.ImportStrategy) error {
+	ctx = adapters.WithMessageID(ctx, pkt.Header.MessageID) // msg= в журнале выражений
+	if err := base.PrepareImport(ctx, pkt, a.packetKeys, a.keyMapper, a.columnCipher); err != nil {
+		return err
+	}
+	if _, err := base.DedupPacket(pkt, a.duplicates); err != nil {
+		return err
+	}
+	if pkt.Header.Type != packet.TypeReference && pkt.Header.Type != packet.TypeResponse {
+		return fmt.Errorf("can only import reference or response packets, got: %s", pkt.Header.Type)
+	}
+	if err := base.ApplyImportProvenance(ctx, a, []*packet.DataPacket{pkt}); err != nil {
+		return err
+	}
+	if pkt.Data.Delta {
+		return fmt.Errorf("mongodb adapter does not support delta packets")
+	}
+	if pkt.Data.Delete {
+		_, err := a.ApplyDelete(ctx, []*packet.DataPacket{pkt})
+		return err
+	}
+
+	if strategy == adapters.StrategyTruncate {
+		// Очистка и вставка — в одной транзакции
+		return a.writePackets(ctx, []*packet.DataPacket{pkt}, strategy)
+	}
+	return a.governor.Do(ctx, len(pkt.Data.Rows), func() error {
+		return a.writePacket(ctx, pkt, strategy)
+	})
+}
+
+// ImportPackets импортирует пакеты атомарно в одной транзакции, если
+// развёртывание их поддерживает (replica set, mongos). На standalone
+// сервере пакеты пишутся последовательно без атомарности.
+func (a *Adapter) ImportPackets(ctx context.Context, packets []*packet.DataPacket, strategy adapters.ImportStrategy) error {
+	if len(packets) == 0 {
+		return nil
+	}
+
+	for _, pkt := range packets {
+		if err := base.PrepareImport(ctx, pkt, a.packetKeys, a.keyMapper, a.columnCipher); err != nil {
+			return err
+		}
+		if _, err := base.DedupPacket(pkt, a.duplicates); err != nil {
+			return err
+		}
+	}
+	if err := base.ApplyImportProvenance(ctx, a, packets); err != nil {
+		return err
+	}
+
+	for _, pkt := range packets {
+		if pkt.Data.Delta {
+			return fmt.Errorf("mongodb adapter does not support delta packets")
+		}
+	}
+
+	// Tombstones инкрементальной выгрузки: сначала данные, затем удаления
+	packets, deletes := base.SplitDeletePackets(packets)
+	if err := a.writePackets(ctx, packets, strategy); err != nil {
+		return err
+	}
+	if len(deletes) > 0 {
+		_, err := a.ApplyDelete(ctx, deletes)
+		return err
+	}
+	return nil
+}
+
+// writePackets пишет пакеты с данными в одной транзакции (если развёртывание
+// её поддерживает). StrategyTruncate сначала очищает коллекции пакетов.
+func (a *Adapter) writePackets(ctx context.Context, packets []*packet.DataPacket, strategy adapters.ImportStrategy) error {
+	if len(packets) == 0 {
+		return nil
+	}
+	totalRows := 0
+	for _, pkt := range packets {
+		totalRows += len(pkt.Data.Rows)
+	}
+	return a.governor.Do(ctx, totalRows, func() error {
+		return a.inTransaction(ctx, func(ctx context.Context) error {
+			if strategy == adapters.StrategyTruncate {
+				if err := a.truncateCollections(ctx, packets); err != nil {
+					return err
+				}
+			}
+			for i, pkt := range packets {
+				if err := a.writePacket(adapters.WithMessageID(ctx, pkt.Header.MessageID), pkt, strategy); err != nil {
+					return fmt.Errorf("packet %d: %w", i+1, err)
+				}
+			}
+			return nil
+		})
+	})
+}
+
+// truncateCollections удаляет все документы коллекций пакетов
+// (каждую коллекцию — один раз).
+func (a *Adapter) truncateCollections(ctx context.Context, packets []*packet.DataPacket) error {
+	done := make(map[string]bool)
+	for _, pkt := range packets {
+		name := pkt.Header.TableName
+		if done[name] {
+			continue
+		}
+		done[name] = true
+		if _, err := a.db.Collection(name).DeleteMany(ctx, bson.D{}); err != nil {
+			return fmt.Errorf("failed to truncate collection %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// ImportPacketStream импортирует пакеты из канала пачками по
+// Config.StreamBatchPackets, каждая пачка — ImportPackets в своей транзакции.
+// Реализует adapters.PacketStreamImporter.
+func (a *Adapter) ImportPacketStream(ctx context.Context, packets <-chan *packet.DataPacket, strategy adapters.ImportStrategy) error {
+	return adapters.ImportPacketBatches(ctx, packets, strategy, a.config.StreamBatchPackets, a.ImportPackets)
+}
+
+// ApplyDelete удаляет документы по ключам пакетов удаления.
+// Реализует base.DeleteApplier
+func (a *Adapter) ApplyDelete(ctx context.Context, packets []*packet.DataPacket) (int, error) {
+	deleted := 0
+	err := a.inTransaction(ctx, func(ctx context.Context) error {
+		for _, pkt := range packets {
+			keys, err := pkt.DeleteKeys()
+			if err != nil {
+				return err
+			}
+			coll := a.db.Collection(pkt.Header.TableName)
+			for i, key := range keys {
+				filter, err := a.rowToDocument(key, pkt.Schema)
+				if err != nil {
+					return fmt.Errorf("delete key %d: %w", i, err)
+				}
+				res, err := coll.DeleteOne(ctx, filter)
+				if err != nil {
+					return fmt.Errorf("delete key %d: %w", i, err)
+				}
+				deleted += int(res.DeletedCount)
+			}
+		}
+		return nil
+	})
+	return deleted, err
+}
+
+// inTransaction выполняет fn в транзакции, если она поддерживается.
+func (a *Adapter) inTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if !a.txSupported {
+		if !a.warnedNoTx {
+			a.warnedNoTx = true
+			log.Printf("WARNING: MongoDB server is standalone — batch writes are not atomic (transactions require a replica set)")
+		}
+		return fn(ctx)
+	}
+
+	session, err := a.client.StartSession()
+	if err != nil {
+		return fmt.Errorf("failed to start session: %w", err)
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (any, error) {
+		return nil, fn(sc)
+	})
+	return err
+}
+
+// writePacket записывает строки пакета в коллекцию по стратегии.
+func (a *Adapter) writePacket(ctx context.Context, pkt *packet.DataPacket, strategy adapters.ImportStrategy) error {
+	coll := a.db.Collection(pkt.Header.TableName)
+	keys := keyFields(pkt.Schema)
+	updates, err := base.UpdateFields(ctx, pkt.Schema, strategy)
+	if err != nil {
+		return err
+	}
+
+	docs := make([]any, 0, min(len(pkt.Data.Rows), importBatchSize))
+	flush := func() error {
+		if len(docs) == 0 {
+			return nil
+		}
+		err := a.writeBatch(ctx, coll, docs, keys, updates, strategy)
+		docs = docs[:0]
+		return err
+	}
+
+	for i, row := range pkt.Data.Rows {
+		doc, err := a.rowToDocument(base.ParseRowValues(row), pkt.Schema)
+		if err != nil {
+			return fmt.Errorf("row %d: %w", i+1, err)
+		}
+		docs = append(docs, doc)
+		if len(docs) == importBatchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	return flush()
+}
+
+// writeBatch записывает пачку документов. Без ключевых полей сопоставлять
+// документы не с чем — replace, merge и ignore вставляют.
+func (a *Adapter) writeBatch(ctx context.Context, coll *mongo.Collection, docs []any, keys, updates []string, strategy adapters.ImportStrategy) error {
+	switch strategy {
+	case adapters.StrategyReplace, adapters.StrategyMerge, adapters.StrategyIgnore:
+		if len(keys) == 0 {
+			break
+		}
+		models := make([]mongo.WriteModel, len(docs))
+		for i, doc := range docs {
+			models[i] = upsertModel(doc.(bson.D), keys, updates, strategy)
+		}
+		if _, err := coll.BulkWrite(ctx, models); err != nil {
+			return fmt.Errorf("failed to upsert documents: %w", err)
+		}
+		return nil
+
+	case adapters.StrategyFail, adapters.StrategyCopy, adapters.StrategyAppend, adapters.StrategyTruncate:
+		// обычная вставка: дубликат _id или уникального индекса — ошибка
+
+	default:
+		return fmt.Errorf("unsupported import strategy: %v", strategy)
+	}
+
+	if _, err := coll.InsertMany(ctx, docs); err != nil {
+		return fmt.Errorf("failed to insert documents: %w", err)
+	}
+	return nil
+}
+
+// upsertModel — запись документа по ключу: replace заменяет существующий
+// документ, merge ($set) обновляет только поля updates, ignore
+// ($setOnInsert) оставляет его нетронутым. В отличие от вставки с
+// пропуском ошибок дубликата, upsert не прерывает транзакцию.
+func upsertModel(doc bson.D, keys, updates []string, strategy adapters.ImportStrategy) mongo.WriteModel {
+	filter := keyFilter(doc, keys)
+
+	if strategy == adapters.StrategyIgnore || strategy == adapters.StrategyMerge {
+		// Ключевые поля документ получает из фильтра
+		var set, rest bson.D
+		for _, e := range doc {
+			switch {
+			case slices.Contains(keys, e.Key):
+			case strategy == adapters.StrategyMerge && slices.Contains(updates, e.Key):
+				set = append(set, e)
+			default:
+				rest = append(rest, e)
+			}
+		}
+		var update bson.D
+		if len(set) > 0 {
+			update = append(update, bson.E{Key: "$set", Value: set})
+		}
+		if len(rest) > 0 {
+			update = append(update, bson.E{Key: "$setOnInsert", Value: rest})
+		}
+		if len(update) > 0 {
+			return mongo.NewUpdateOneModel().
+				SetFilter(filter).
+				SetUpdate(update).
+				SetUpsert(true)
+		}
+		// Документ из одних ключей: замена идентичным документом = пропуск
+	}
+
+	return mongo.NewReplaceOneModel().
+		SetFilter(filter).
+		SetReplacement(doc).
+		SetUpsert(true)
+}
+
+// keyFields — ключевые поля схемы; _id — ключ всегда.
+func keyFields(schema packet.Schema) []string {
+	var keys []string
+	for _, field := range schema.Fields {
+		if field.Name == "_id" {
+			return []string{"_id"}
+		}
+		if field.Key {
+			keys = append(keys, field.Name)
+		}
+	}
+	return keys
+}
+
+// keyFilter — фильтр по значениям ключевых полей документа.
+func keyFilter(doc bson.D, keys []string) bson.D {
+	filter := make(bson.D, 0, len(keys))
+	for _, key := range keys {
+		var value any
+		for _, e := range doc {
+			if e.Key == key {
+				value = e.Value
+				break
+			}
+		}
+		filter = append(filter, bson.E{Key: key, Value: value})
+	}
+	return filter
+}
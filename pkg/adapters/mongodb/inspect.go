@@ -0,0 +1,92 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+	"github.com/ruslano69/tdtp-framework/pkg/adapters/base"
+)
+
+// InspectTable returns extended metadata for a live MongoDB collection.
+// Columns are inferred from a document sample: NativeType lists the BSON
+// types seen for the field ("int|double"), Nullable means the field is
+// missing or null in some sampled documents.
+// Implements adapters.Adapter.
+func (a *Adapter) InspectTable(ctx context.Context, tableName string) (*adapters.TableReport, error) {
+	dbVersion, err := a.GetDatabaseVersion(ctx)
+	if err != nil {
+		dbVersion = "MongoDB (unknown version)"
+	}
+
+	report := &adapters.TableReport{
+		Table:     tableName,
+		DBType:    AdapterType,
+		DBVersion: dbVersion,
+		Schema:    a.db.Name(),
+	}
+
+	// ---- Columns from a document sample ----
+	inferrer, err := a.sample(ctx, tableName)
+	if err != nil {
+		return nil, err
+	}
+	schema := inferrer.schema()
+	for i, fs := range inferrer.fields {
+		field := schema.Fields[i]
+
+		var native []string
+		for t := range fs.types {
+			native = append(native, bsonTypeName(t))
+		}
+		slices.Sort(native)
+
+		tdtpType := field.Type
+		if field.Subtype != "" {
+			tdtpType += "(" + field.Subtype + ")"
+		}
+		report.Columns = append(report.Columns, adapters.ColumnReport{
+			Name:       field.Name,
+			NativeType: strings.Join(native, "|"),
+			TDTPType:   tdtpType,
+			Nullable:   fs.present < inferrer.docs,
+			PrimaryKey: field.Key,
+			Length:     field.Length,
+			Precision:  field.Precision,
+			Scale:      field.Scale,
+		})
+	}
+
+	// ---- Document count ----
+	totalRows, err := a.GetRowCount(ctx, tableName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count documents: %w", err)
+	}
+	report.Stats.TotalRows = totalRows
+
+	// ---- Sample: last document by _id ----
+	if totalRows > 0 {
+		raw, err := a.db.Collection(tableName).
+			FindOne(ctx, bson.D{}, options.FindOne().SetSort(bson.D{{Key: "_id", Value: -1}})).
+			Raw()
+		if err == nil {
+			row := documentToRow(raw, schema)
+			sample := make(map[string]string, len(row))
+			for i, field := range schema.Fields {
+				if row[i] == base.NullSentinel {
+					sample[field.Name] = "NULL"
+				} else {
+					sample[field.Name] = row[i]
+				}
+			}
+			report.Sample = sample
+		}
+	}
+
+	return report, nil
+}
@@ -0,0 +1,74 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+)
+
+// commandLog пишет команды MongoDB в журнал adapters.QueryLogger через
+// event.CommandMonitor. Без ShowArgs в журнал попадают имя команды,
+// коллекция и ключи команды — фильтры и документы содержат данные строк.
+type commandLog struct {
+	log     *adapters.QueryLogger
+	started sync.Map // RequestID → текст команды
+}
+
+// служебные поля команды, которые не несут смысла в журнале
+var commandNoise = map[string]bool{"$db": true, "lsid": true, "$clusterTime": true, "txnNumber": true, "$readPreference": true}
+
+func (c *commandLog) monitor() *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Started: func(_ context.Context, ev *event.CommandStartedEvent) {
+			if c.log.Enabled() {
+				c.started.Store(ev.RequestID, c.statement(ev.Command))
+			}
+		},
+		Succeeded: func(ctx context.Context, ev *event.CommandSucceededEvent) {
+			rows := int64(-1)
+			if n, ok := ev.Reply.Lookup("n").AsInt64OK(); ok {
+				rows = n
+			}
+			c.finish(ctx, ev.CommandFinishedEvent, rows, nil)
+		},
+		Failed: func(ctx context.Context, ev *event.CommandFailedEvent) {
+			c.finish(ctx, ev.CommandFinishedEvent, -1, errors.New(ev.Failure))
+		},
+	}
+}
+
+func (c *commandLog) finish(ctx context.Context, ev event.CommandFinishedEvent, rows int64, err error) {
+	statement, ok := c.started.LoadAndDelete(ev.RequestID)
+	if !ok {
+		return
+	}
+	c.log.Log(ctx, adapters.QueryEvent{Statement: statement.(string), Duration: ev.Duration, Rows: rows, Err: err})
+}
+
+func (c *commandLog) statement(cmd bson.Raw) string {
+	if c.log.Config().ShowArgs {
+		return cmd.String()
+	}
+	elems, err := cmd.Elements()
+	if err != nil || len(elems) == 0 {
+		return "<command>"
+	}
+	// {find: "users", filter: {...}} → find users {filter}
+	name := elems[0].Key()
+	if coll, ok := elems[0].Value().StringValueOK(); ok {
+		name += " " + coll
+	}
+	var keys []string
+	for _, e := range elems[1:] {
+		if !commandNoise[e.Key()] {
+			keys = append(keys, e.Key())
+		}
+	}
+	return name + " {" + strings.Join(keys, ", ") + "}"
+}
@@ -0,0 +1,266 @@
+package mongodb
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters/base"
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+// Subtype-ы TEXT полей, которые нельзя потерять при обратном импорте в MongoDB
+const (
+	// SubtypeObjectID — ObjectID в виде 24 hex-символов
+	SubtypeObjectID = "objectid"
+
+	// SubtypeJSON — вложенный документ или массив в relaxed Extended JSON
+	SubtypeJSON = "json"
+)
+
+// Decimal128 хранит до 34 значащих цифр; масштаб в коллекции не фиксирован,
+// поэтому DECIMAL получает запас под дробную часть.
+const (
+	decimalPrecision = 34
+	decimalScale     = 10
+)
+
+// fieldStats — наблюдения по одному полю в выборке документов.
+type fieldStats struct {
+	name    string
+	types   map[bsontype.Type]int // BSON типы непустых значений
+	present int                   // документы, где поле есть и не null
+}
+
+// schemaInferrer строит TDTP схему коллекции по выборке документов:
+// объединение полей в порядке первого появления, _id первым и ключом.
+type schemaInferrer struct {
+	fields []*fieldStats
+	index  map[string]*fieldStats
+	docs   int
+}
+
+func newSchemaInferrer() *schemaInferrer {
+	return &schemaInferrer{index: make(map[string]*fieldStats)}
+}
+
+// add учитывает один документ выборки.
+func (s *schemaInferrer) add(doc bson.Raw) error {
+	elements, err := doc.Elements()
+	if err != nil {
+		return fmt.Errorf("invalid document: %w", err)
+	}
+	s.docs++
+	for _, el := range elements {
+		name := el.Key()
+		fs, ok := s.index[name]
+		if !ok {
+			fs = &fieldStats{name: name, types: make(map[bsontype.Type]int)}
+			s.index[name] = fs
+			if name == "_id" {
+				s.fields = append([]*fieldStats{fs}, s.fields...)
+			} else {
+				s.fields = append(s.fields, fs)
+			}
+		}
+		v := el.Value()
+		if v.Type == bsontype.Null || v.Type == bsontype.Undefined {
+			continue
+		}
+		fs.types[v.Type]++
+		fs.present++
+	}
+	return nil
+}
+
+// schema возвращает выведенную схему.
+func (s *schemaInferrer) schema() packet.Schema {
+	var result packet.Schema
+	for _, fs := range s.fields {
+		field := resolveField(fs.name, fs.types)
+		field.Key = fs.name == "_id"
+		result.Fields = append(result.Fields, field)
+	}
+	return result
+}
+
+// resolveField выбирает TDTP тип по набору BSON типов значений поля.
+// Смешанные числовые типы сводятся к INTEGER/REAL; прочие смеси — TEXT.
+func resolveField(name string, types map[bsontype.Type]int) packet.Field {
+	field := packet.Field{Name: name}
+
+	has := func(t bsontype.Type) bool { return types[t] > 0 }
+	only := func(allowed ...bsontype.Type) bool {
+		n := 0
+		for _, t := range allowed {
+			if has(t) {
+				n++
+			}
+		}
+		return n == len(types)
+	}
+
+	switch {
+	case len(types) == 0:
+		field.Type = "TEXT"
+	case only(bsontype.Int32, bsontype.Int64):
+		field.Type = "INTEGER"
+	case only(bsontype.Int32, bsontype.Int64, bsontype.Double):
+		field.Type = "REAL"
+	case only(bsontype.Decimal128, bsontype.Int32, bsontype.Int64):
+		field.Type = "DECIMAL"
+		field.Precision = decimalPrecision
+		field.Scale = decimalScale
+	case only(bsontype.String):
+		field.Type = "TEXT"
+	case only(bsontype.Boolean):
+		field.Type = "BOOLEAN"
+	case only(bsontype.DateTime):
+		field.Type = "TIMESTAMP"
+		field.Timezone = "UTC"
+	case only(bsontype.ObjectID):
+		field.Type = "TEXT"
+		field.Length = 24
+		field.Subtype = SubtypeObjectID
+	case only(bsontype.Binary):
+		field.Type = "BLOB"
+	case only(bsontype.EmbeddedDocument, bsontype.Array):
+		field.Type = "TEXT"
+		field.Length = -1
+		field.Subtype = SubtypeJSON
+	default:
+		// Смесь типов (например, строка и число в разных документах):
+		// значения выгружаются строками
+		field.Type = "TEXT"
+	}
+	return field
+}
+
+// bsonTypeName — имя BSON типа для InspectTable (native_type).
+func bsonTypeName(t bsontype.Type) string {
+	switch t {
+	case bsontype.Int32:
+		return "int"
+	case bsontype.Int64:
+		return "long"
+	case bsontype.Decimal128:
+		return "decimal"
+	case bsontype.EmbeddedDocument:
+		return "object"
+	case bsontype.DateTime:
+		return "date"
+	case bsontype.ObjectID:
+		return "objectId"
+	case bsontype.Boolean:
+		return "bool"
+	case bsontype.Binary:
+		return "binData"
+	}
+	return t.String()
+}
+
+// valueToTDTP конвертирует значение поля документа в строку TDTP.
+// Отсутствующее поле и null — base.NullSentinel.
+func valueToTDTP(v bson.RawValue, found bool, field packet.Field) string {
+	if !found || v.Type == bsontype.Null || v.Type == bsontype.Undefined {
+		return base.NullSentinel
+	}
+	if field.Subtype == SubtypeJSON {
+		return extJSON(v)
+	}
+
+	switch v.Type {
+	case bsontype.String:
+		return v.StringValue()
+	case bsontype.Int32:
+		return strconv.FormatInt(int64(v.Int32()), 10)
+	case bsontype.Int64:
+		return strconv.FormatInt(v.Int64(), 10)
+	case bsontype.Double:
+		return strconv.FormatFloat(v.Double(), 'g', -1, 64)
+	case bsontype.Decimal128:
+		return v.Decimal128().String()
+	case bsontype.Boolean:
+		if v.Boolean() {
+			return "1"
+		}
+		return "0"
+	case bsontype.DateTime:
+		return time.UnixMilli(v.DateTime()).UTC().Format(time.RFC3339Nano)
+	case bsontype.ObjectID:
+		return v.ObjectID().Hex()
+	case bsontype.Binary:
+		_, data := v.Binary()
+		return base64.StdEncoding.EncodeToString(data)
+	}
+	return extJSON(v)
+}
+
+// extJSON — значение в relaxed Extended JSON (документ, массив, прочие типы BSON).
+func extJSON(v bson.RawValue) string {
+	data, err := bson.MarshalExtJSON(bson.D{{Key: "v", Value: v}}, false, false)
+	if err != nil {
+		return v.String()
+	}
+	s := strings.TrimPrefix(string(data), `{"v":`)
+	return strings.TrimSuffix(s, "}")
+}
+
+// parseExtJSON разбирает значение relaxed Extended JSON (обратное extJSON).
+func parseExtJSON(s string) (any, error) {
+	var doc bson.D
+	if err := bson.UnmarshalExtJSON([]byte(`{"v":`+s+`}`), false, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc) != 1 {
+		return nil, fmt.Errorf("invalid JSON value")
+	}
+	return doc[0].Value, nil
+}
+
+// rowToDocument строит документ из строки TDTP в порядке полей схемы.
+// NULL не записывается: в MongoDB отсутствующее поле и null равнозначны
+// для схемы, а документы остаются компактными.
+func (a *Adapter) rowToDocument(values []string, schema packet.Schema) (bson.D, error) {
+	args, err := base.ConvertRowToSQLValues(values, schema, a.converter, AdapterType)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := make(bson.D, 0, len(args))
+	for i, arg := range args {
+		if arg == nil {
+			continue
+		}
+		field := schema.Fields[i]
+		v, err := bsonValue(arg, values[i], field)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", field.Name, err)
+		}
+		doc = append(doc, bson.E{Key: field.Name, Value: v})
+	}
+	return doc, nil
+}
+
+// bsonValue восстанавливает BSON тип, который SQL-конвертация не различает:
+// ObjectID, Decimal128 и вложенные документы/массивы. arg — значение после
+// base.ConvertRowToSQLValues, raw — исходная строка TDTP.
+func bsonValue(arg any, raw string, field packet.Field) (any, error) {
+	switch {
+	case field.Subtype == SubtypeObjectID:
+		return primitive.ObjectIDFromHex(raw)
+	case field.Subtype == SubtypeJSON:
+		return parseExtJSON(raw)
+	case strings.EqualFold(field.Type, "DECIMAL"):
+		if _, ok := arg.(float64); ok {
+			return primitive.ParseDecimal128(raw)
+		}
+	}
+	return arg, nil
+}
@@ -0,0 +1,153 @@
+package mongodb
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters/base"
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+func mustRaw(t *testing.T, doc bson.D) bson.Raw {
+	t.Helper()
+	data, err := bson.Marshal(doc)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	return bson.Raw(data)
+}
+
+func TestSchemaInferrer(t *testing.T) {
+	id := primitive.NewObjectID()
+	docs := []bson.D{
+		{{Key: "name", Value: "Alice"}, {Key: "_id", Value: id}, {Key: "age", Value: int32(30)},
+			{Key: "score", Value: int32(5)}, {Key: "tags", Value: bson.A{"a", "b"}}},
+		{{Key: "_id", Value: primitive.NewObjectID()}, {Key: "name", Value: nil}, {Key: "age", Value: int64(41)},
+			{Key: "score", Value: 4.5}, {Key: "created", Value: time.Now()}, {Key: "mixed", Value: "x"}},
+		{{Key: "_id", Value: primitive.NewObjectID()}, {Key: "mixed", Value: int32(1)},
+			{Key: "price", Value: primitive.NewDecimal128(0, 1999)}, {Key: "active", Value: true}},
+	}
+
+	inferrer := newSchemaInferrer()
+	for _, d := range docs {
+		if err := inferrer.add(mustRaw(t, d)); err != nil {
+			t.Fatalf("add: %v", err)
+		}
+	}
+	schema := inferrer.schema()
+
+	want := []struct {
+		name, typ, subtype string
+		key                bool
+	}{
+		{"_id", "TEXT", SubtypeObjectID, true},
+		{"name", "TEXT", "", false},
+		{"age", "INTEGER", "", false},
+		{"score", "REAL", "", false},
+		{"tags", "TEXT", SubtypeJSON, false},
+		{"created", "TIMESTAMP", "", false},
+		{"mixed", "TEXT", "", false},
+		{"price", "DECIMAL", "", false},
+		{"active", "BOOLEAN", "", false},
+	}
+	if len(schema.Fields) != len(want) {
+		t.Fatalf("fields = %d, want %d: %+v", len(schema.Fields), len(want), schema.Fields)
+	}
+	for i, w := range want {
+		f := schema.Fields[i]
+		if f.Name != w.name || f.Type != w.typ || f.Subtype != w.subtype || f.Key != w.key {
+			t.Errorf("field %d = %s %s/%s key=%v, want %s %s/%s key=%v",
+				i, f.Name, f.Type, f.Subtype, f.Key, w.name, w.typ, w.subtype, w.key)
+		}
+	}
+	if inferrer.index["name"].present != 1 {
+		t.Errorf("name present = %d, want 1 (null is not a value)", inferrer.index["name"].present)
+	}
+}
+
+func TestDocumentRoundTrip(t *testing.T) {
+	a := &Adapter{converter: base.NewUniversalTypeConverter()}
+	id := primitive.NewObjectID()
+	created := time.Date(2024, 3, 15, 10, 30, 0, 123e6, time.UTC)
+	price, _ := primitive.ParseDecimal128("19.99")
+
+	doc := bson.D{
+		{Key: "_id", Value: id},
+		{Key: "name", Value: "Alice"},
+		{Key: "age", Value: int64(30)},
+		{Key: "score", Value: 4.5},
+		{Key: "active", Value: true},
+		{Key: "created", Value: primitive.NewDateTimeFromTime(created)},
+		{Key: "price", Value: price},
+		{Key: "address", Value: bson.D{{Key: "city", Value: "Riga"}, {Key: "zip", Value: int32(1010)}}},
+		{Key: "tags", Value: bson.A{"a", "b"}},
+	}
+	raw := mustRaw(t, doc)
+
+	inferrer := newSchemaInferrer()
+	if err := inferrer.add(raw); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	schema := inferrer.schema()
+	schema.Fields = append(schema.Fields, packet.Field{Name: "missing", Type: "TEXT"})
+
+	row := documentToRow(raw, schema)
+	wantRow := []string{
+		id.Hex(), "Alice", "30", "4.5", "1", "2024-03-15T10:30:00.123Z", "19.99",
+		`{"city":"Riga","zip":1010}`, `["a","b"]`, base.NullSentinel,
+	}
+	for i, w := range wantRow {
+		if row[i] != w {
+			t.Errorf("%s = %q, want %q", schema.Fields[i].Name, row[i], w)
+		}
+	}
+
+	// NULL → поле не пишется; остальное — исходные BSON типы
+	schema.Fields[len(row)-1].SpecialValues = &packet.SpecialValues{Null: &packet.MarkerValue{Marker: "[NULL]"}}
+	row[len(row)-1] = "[NULL]"
+	back, err := a.rowToDocument(row, schema)
+	if err != nil {
+		t.Fatalf("rowToDocument: %v", err)
+	}
+	gotRaw := mustRaw(t, back)
+	if !bytes.Equal(raw, gotRaw) {
+		t.Errorf("round trip mismatch:\n got %s\nwant %s", gotRaw, raw)
+	}
+}
+
+func TestUpsertModel(t *testing.T) {
+	doc := bson.D{{Key: "id", Value: int64(7)}, {Key: "name", Value: "Bob"}}
+	keys := keyFields(packet.Schema{Fields: []packet.Field{{Name: "id", Key: true}, {Name: "name"}}})
+
+	m := upsertModel(doc, keys, nil, "ignore")
+	update, ok := m.(*mongo.UpdateOneModel)
+	if !ok {
+		t.Fatalf("ignore model = %T, want *mongo.UpdateOneModel", m)
+	}
+	wantUpdate := bson.D{{Key: "$setOnInsert", Value: bson.D{{Key: "name", Value: "Bob"}}}}
+	if !bytes.Equal(mustRaw(t, update.Update.(bson.D)), mustRaw(t, wantUpdate)) {
+		t.Errorf("update = %v, want %v", update.Update, wantUpdate)
+	}
+
+	if _, ok := upsertModel(doc, keys, []string{"name"}, "replace").(*mongo.ReplaceOneModel); !ok {
+		t.Error("replace model is not *mongo.ReplaceOneModel")
+	}
+
+	doc = append(doc, bson.E{Key: "age", Value: int64(30)})
+	merge := upsertModel(doc, keys, []string{"age"}, "merge").(*mongo.UpdateOneModel)
+	wantUpdate = bson.D{
+		{Key: "$set", Value: bson.D{{Key: "age", Value: int64(30)}}},
+		{Key: "$setOnInsert", Value: bson.D{{Key: "name", Value: "Bob"}}},
+	}
+	if !bytes.Equal(mustRaw(t, merge.Update.(bson.D)), mustRaw(t, wantUpdate)) {
+		t.Errorf("merge update = %v, want %v", merge.Update, wantUpdate)
+	}
+	if got := keyFields(packet.Schema{Fields: []packet.Field{{Name: "code", Key: true}, {Name: "_id"}}}); len(got) != 1 || got[0] != "_id" {
+		t.Errorf("keyFields with _id = %v, want [_id]", got)
+	}
+}
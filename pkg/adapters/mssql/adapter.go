@@ -35,6 +35,18 @@ type Adapter struct {
 	exportHelper *base.ExportHelper
 	converter    *base.UniversalTypeConverter
 	sqlAdapter   *base.MSSQLAdapter
+	governor     *adapters.Governor // ограничение темпа импорта (nil — без ограничений)
+	streamBatch  int                // пакетов в транзакции ImportPacketStream
+
+	packetKeys  base.PacketKeyProvider       // расшифровка пакетов TDTP v1.5 при импорте
+	keyMapper   base.KeyMapper               // суррогатные ключи при импорте
+	cipher      adapters.ColumnCipher        // шифрование колонок at rest (Config.ColumnCipher)
+	queryLog    *adapters.QueryLogger        // журнал SQL-выражений (Config.QueryLog)
+	tableLock   adapters.TableLocking        // блокировка целевых таблиц (TryLockTable)
+	maintenance *adapters.MaintenanceWindows // окна разрушающих импортов (Config.Maintenance)
+	duplicates  adapters.DuplicateMode       // повторяющиеся ключи в пакете (base.DedupPacket)
+	columns     *base.ColumnMatcher          // поля пакета → колонки таблицы
+	rowErrors   adapters.RowErrorConfig      // строки, не приводимые к типам колонок (base.RejectPacketRows)
 }
 
 // Compatibility levels
@@ -52,13 +64,43 @@ func init() {
 	adapters.Register(AdapterType, func() adapters.Adapter {
 		return &Adapter{}
 	})
+	adapters.Describe(adapters.DriverInfo{Type: AdapterType, Driver: "github.com/denisenkom/go-mssqldb"})
 }
 
 // Connect implements adapters.Adapter interface.
 // Connects to MS SQL Server and performs feature detection.
 func (a *Adapter) Connect(ctx context.Context, cfg adapters.Config) error {
+	governor, err := adapters.NewGovernor(cfg.ImportLimits)
+	if err != nil {
+		return fmt.Errorf("invalid import limits: %w", err)
+	}
+	a.governor = governor
+	a.streamBatch = cfg.StreamBatchPackets
+	if err := cfg.TableLock.Validate(); err != nil {
+		return err
+	}
+	a.tableLock = cfg.TableLock
+	a.maintenance = cfg.Maintenance
+	if err := cfg.Duplicates.Validate(); err != nil {
+		return err
+	}
+	a.duplicates = cfg.Duplicates
+	if err := cfg.Columns.Validate(); err != nil {
+		return err
+	}
+	a.columns = base.NewColumnMatcher(a, cfg.Columns)
+	a.cipher = cfg.ColumnCipher
+	if err := cfg.RowErrors.Validate(); err != nil {
+		return err
+	}
+	if cfg.RowErrors.PartialRollback {
+		return fmt.Errorf("row error partial rollback is not supported by the mssql adapter")
+	}
+	a.rowErrors = cfg.RowErrors
+
 	// Open database connection
-	db, err := sql.Open("mssql", cfg.DSN)
+	a.queryLog = adapters.NewQueryLogger(cfg.QueryLog)
+	db, err := base.OpenDB("mssql", cfg.DSN, a.queryLog)
 	if err != nil {
 		return fmt.Errorf("failed to open database: %w", err)
 	}
@@ -88,10 +130,35 @@ func (a *Adapter) Connect(ctx context.Context, cfg adapters.Config) error {
 
 	// Initialize base helpers (added in refactoring)
 	a.initHelpers()
+	if cfg.SourceTimezone != "" {
+		if err := a.converter.SetSourceTimezone(cfg.SourceTimezone); err != nil {
+			_ = db.Close()
+			return err
+		}
+	}
+	if err := a.converter.SetBoolMappings(cfg.Booleans, cfg.ColumnBooleans); err != nil {
+		_ = db.Close()
+		return err
+	}
+	a.exportHelper.SetColumnCipher(cfg.ColumnCipher)
+	a.exportHelper.SetExportPolicy(cfg.ExportPolicy)
+	if err := a.exportHelper.SetPacketSizing(cfg.Packets); err != nil {
+		_ = db.Close()
+		return err
+	}
 
 	return nil
 }
 
+// TimezoneReport реализует adapters.TimezoneReporter: отчёт о переводе
+// naive datetime из Config.SourceTimezone в UTC (nil — пояс не задан).
+func (a *Adapter) TimezoneReport() *adapters.TimezoneReport {
+	if a.converter == nil {
+		return nil
+	}
+	return a.converter.TimezoneReport()
+}
+
 // initHelpers initializes base package helpers for common operations
 // Added during refactoring to eliminate code duplication
 func (a *Adapter) initHelpers() {
@@ -280,6 +347,11 @@ func (a *Adapter) SupportsMerge() bool {
 
 // Interface implementation
 
+// QueryLog реализует adapters.QueryLogged: журнал выражений адаптера.
+func (a *Adapter) QueryLog() *adapters.QueryLogger {
+	return a.queryLog
+}
+
 // Close closes the database connection.
 func (a *Adapter) Close(ctx context.Context) error {
 	if a.db != nil {
@@ -298,6 +370,11 @@ func (a *Adapter) GetDatabaseType() string {
 	return AdapterType
 }
 
+// IdentifierLimit implements adapters.IdentifierLimiter: sysname, 128 characters.
+func (a *Adapter) IdentifierLimit() adapters.IdentifierLimit {
+	return adapters.IdentifierLimit{MaxLength: 128}
+}
+
 // GetDatabaseVersion returns the SQL Server version string.
 func (a *Adapter) GetDatabaseVersion(ctx context.Context) (string, error) {
 	return fmt.Sprintf("%s (compatibility level %d)", a.serverVersionStr, a.compatLevel), nil
@@ -418,6 +495,8 @@ type transaction struct {
 	tx *sql.Tx
 }
 
+var _ adapters.SavepointTx = (*transaction)(nil)
+
 func (t *transaction) Commit(ctx context.Context) error {
 	return t.tx.Commit()
 }
@@ -426,6 +505,21 @@ func (t *transaction) Rollback(ctx context.Context) error {
 	return t.tx.Rollback()
 }
 
+// Savepoint создаёт точку сохранения (adapters.SavepointTx)
+func (t *transaction) Savepoint(ctx context.Context, name string) error {
+	return base.MSSQLSavepoints.Savepoint(ctx, t.tx, name)
+}
+
+// RollbackTo откатывает транзакцию к точке сохранения
+func (t *transaction) RollbackTo(ctx context.Context, name string) error {
+	return base.MSSQLSavepoints.RollbackTo(ctx, t.tx, name)
+}
+
+// ReleaseSavepoint освобождает точку сохранения
+func (t *transaction) ReleaseSavepoint(ctx context.Context, name string) error {
+	return base.MSSQLSavepoints.ReleaseSavepoint(ctx, t.tx, name)
+}
+
 // Export, Import, and Schema methods are implemented in export.go and import.go
 
 // ExecuteRawQuery выполняет произвольный SQL SELECT запрос и возвращает результат как DataPacket.
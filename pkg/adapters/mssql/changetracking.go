@@ -0,0 +1,201 @@
+package mssql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+	"github.com/ruslano69/tdtp-framework/pkg/sync"
+)
+
+// Отслеживание изменений SQL Server (sync.TrackingChangeTable).
+//
+// Change Tracking (SQL Server 2008+, все редакции — подходит для всех
+// режимов совместимости адаптера) хранит для каждой изменённой строки
+// первичный ключ и последнюю операцию, поэтому колонка updated_at в таблице
+// не нужна. Изменения после версии читаются CHANGETABLE(CHANGES ...),
+// текущие значения строк — соединением с таблицей. Требования:
+// ALTER DATABASE ... SET CHANGE_TRACKING = ON, ALTER TABLE ... ENABLE
+// CHANGE_TRACKING, первичный ключ, право VIEW CHANGE TRACKING на таблицу.
+//
+// Контрольная точка — версия отслеживания изменений. Первая синхронизация
+// (без контрольной точки) выгружает таблицу целиком с текущей версией.
+// Версия старше CHANGE_TRACKING_MIN_VALID_VERSION (изменения удалены
+// очисткой по CHANGE_RETENTION) — ошибка: нужна полная синхронизация.
+
+// exportTableChangeTracking читает изменения таблицы после версии
+// cfg.InitialValue и возвращает пакеты (sync.ChangePackets) и версию новой
+// контрольной точки. При cfg.BatchSize > 0 пачка обрезается по границе
+// версии, т.е. целыми транзакциями.
+func (a *Adapter) exportTableChangeTracking(ctx context.Context, tableName string, cfg adapters.IncrementalConfig) ([]*packet.DataPacket, string, error) {
+	obj, err := a.resolveObject(ctx, tableName)
+	if err != nil {
+		return nil, "", err
+	}
+	if obj.catalog() != "" {
+		// Функции CHANGE_TRACKING_* работают только в текущей БД
+		return nil, "", fmt.Errorf("change tracking of %s: the table must be in the connected database", obj)
+	}
+
+	pkgSchema, err := a.GetTableSchema(ctx, tableName)
+	if err != nil {
+		return nil, "", err
+	}
+	var keys []int
+	for i, f := range pkgSchema.Fields {
+		if f.Key {
+			keys = append(keys, i)
+		}
+	}
+	if len(keys) == 0 {
+		return nil, "", fmt.Errorf("change tracking of %s requires a primary key", obj)
+	}
+
+	var current, minValid sql.NullInt64
+	err = a.db.QueryRowContext(ctx,
+		`SELECT CHANGE_TRACKING_CURRENT_VERSION(), CHANGE_TRACKING_MIN_VALID_VERSION(OBJECT_ID(?))`,
+		obj.quoted()).Scan(&current, &minValid)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read change tracking version: %w", err)
+	}
+	if !current.Valid {
+		return nil, "", fmt.Errorf("change tracking is not enabled for the database (ALTER DATABASE ... SET CHANGE_TRACKING = ON)")
+	}
+	if !minValid.Valid {
+		return nil, "", fmt.Errorf("change tracking is not enabled for table %s (ALTER TABLE %s ENABLE CHANGE_TRACKING)", obj, obj.quoted())
+	}
+	checkpoint := strconv.FormatInt(current.Int64, 10)
+
+	// Первая синхронизация: вся таблица; изменения во время чтения
+	// придут повторно со следующей пачкой (upsert идемпотентен)
+	if cfg.InitialValue == "" {
+		rows, err := a.readAllRows(ctx, tableName, pkgSchema)
+		if err != nil {
+			return nil, "", err
+		}
+		if len(rows) == 0 {
+			return []*packet.DataPacket{}, checkpoint, nil
+		}
+		packets, err := packet.NewGenerator().GenerateReference(tableName, pkgSchema, rows)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to generate packets: %w", err)
+		}
+		return packets, checkpoint, nil
+	}
+
+	last, err := strconv.ParseInt(cfg.InitialValue, 10, 64)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid change tracking version %q: %w", cfg.InitialValue, err)
+	}
+	if last < minValid.Int64 {
+		return nil, "", fmt.Errorf("change tracking version %d of %s is older than the minimum valid version %d (changes were cleaned up): run a full sync",
+			last, obj, minValid.Int64)
+	}
+
+	rows, err := a.db.QueryContext(ctx, changeTrackingSQL(obj, pkgSchema, keys), last)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read changes of %s: %w", obj, err)
+	}
+	defer func() { _ = rows.Close() }()
+	data, err := a.scanRows(rows, changeTrackingScanSchema(pkgSchema, keys))
+	if err != nil {
+		return nil, "", err
+	}
+
+	changes, checkpoint := changeTrackingChanges(data, pkgSchema, keys, cfg.BatchSize, checkpoint)
+	if len(changes) == 0 {
+		return []*packet.DataPacket{}, checkpoint, nil
+	}
+	packets, err := sync.ChangePackets(tableName, pkgSchema, changes)
+	if err != nil {
+		return nil, "", err
+	}
+	return packets, checkpoint, nil
+}
+
+// changeTrackingSQL строит запрос изменений после версии (параметр):
+// версия, операция, признак наличия строки, ключ из CHANGETABLE, затем
+// текущие значения всех полей схемы. Удалённая строка — NULL-значения.
+func changeTrackingSQL(obj objectName, schema packet.Schema, keys []int) string {
+	keyCols := make([]string, len(keys))
+	joins := make([]string, len(keys))
+	for j, i := range keys {
+		name := quoteMSSQLIdent(schema.Fields[i].Name)
+		keyCols[j] = "ct." + name
+		joins[j] = fmt.Sprintf("t.%s = ct.%s", name, name)
+	}
+	columns := make([]string, len(schema.Fields))
+	for i, f := range schema.Fields {
+		columns[i] = "t." + quoteMSSQLIdent(f.Name)
+	}
+	firstKey := quoteMSSQLIdent(schema.Fields[keys[0]].Name)
+
+	return fmt.Sprintf(`SELECT ct.SYS_CHANGE_VERSION, ct.SYS_CHANGE_OPERATION,
+	CASE WHEN t.%s IS NULL THEN 0 ELSE 1 END,
+	%s,
+	%s
+FROM CHANGETABLE(CHANGES %s, ?) AS ct
+LEFT JOIN %s AS t ON %s
+ORDER BY ct.SYS_CHANGE_VERSION`,
+		firstKey,
+		strings.Join(keyCols, ", "),
+		strings.Join(columns, ", "),
+		obj.quoted(), obj.quoted(), strings.Join(joins, " AND "))
+}
+
+// changeTrackingScanSchema — схема колонок changeTrackingSQL для base.ScanSQLRows.
+func changeTrackingScanSchema(schema packet.Schema, keys []int) packet.Schema {
+	fields := make([]packet.Field, 0, 3+len(keys)+len(schema.Fields))
+	fields = append(fields,
+		packet.Field{Name: "SYS_CHANGE_VERSION", Type: "INTEGER"},
+		packet.Field{Name: "SYS_CHANGE_OPERATION", Type: "TEXT"},
+		packet.Field{Name: "present", Type: "INTEGER"},
+	)
+	for _, i := range keys {
+		fields = append(fields, schema.Fields[i])
+	}
+	return packet.Schema{Fields: append(fields, schema.Fields...)}
+}
+
+// changeTrackingChanges переводит строки changeTrackingSQL (по возрастанию
+// версии) в изменения: существующая строка — insert/update с текущими
+// значениями, отсутствующая — удаление по ключу из CHANGETABLE. При
+// batchSize > 0 после batchSize строк пачка заканчивается на границе
+// версии, и контрольной точкой становится последняя включённая версия;
+// иначе — current.
+func changeTrackingChanges(rows [][]string, schema packet.Schema, keys []int, batchSize int, current string) ([]sync.Change, string) {
+	const fixed = 3 // версия, операция, признак наличия строки
+	changes := make([]sync.Change, 0, len(rows))
+	for n, row := range rows {
+		version := row[0]
+		if batchSize > 0 && n >= batchSize && version != rows[n-1][0] {
+			return changes, rows[n-1][0]
+		}
+
+		change := sync.Change{LSN: version}
+		if row[2] == "1" {
+			change.Op = sync.ChangeUpdate
+			if strings.TrimSpace(row[1]) == "I" {
+				change.Op = sync.ChangeInsert
+			}
+			values := row[fixed+len(keys):]
+			change.Columns = make([]sync.ChangeColumn, len(schema.Fields))
+			for i, f := range schema.Fields {
+				change.Columns[i] = sync.ChangeColumn{Name: f.Name, Value: values[i]}
+			}
+		} else {
+			// Удалена (операция D или удаление после insert/update)
+			change.Op = sync.ChangeDelete
+			change.Identity = make([]sync.ChangeColumn, len(keys))
+			for j, i := range keys {
+				change.Identity[j] = sync.ChangeColumn{Name: schema.Fields[i].Name, Value: row[fixed+j]}
+			}
+		}
+		changes = append(changes, change)
+	}
+	return changes, current
+}
@@ -0,0 +1,78 @@
+package mssql
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+	"github.com/ruslano69/tdtp-framework/pkg/sync"
+)
+
+func TestChangeTrackingSQL(t *testing.T) {
+	schema := packet.Schema{Fields: []packet.Field{
+		{Name: "id", Type: "INTEGER", Key: true},
+		{Name: "name", Type: "TEXT"},
+	}}
+	got := changeTrackingSQL(objectName{Schema: "sales", Name: "orders"}, schema, []int{0})
+	for _, want := range []string{
+		"CASE WHEN t.[id] IS NULL THEN 0 ELSE 1 END",
+		"ct.[id],\n\tt.[id], t.[name]",
+		"FROM CHANGETABLE(CHANGES [sales].[orders], ?) AS ct",
+		"LEFT JOIN [sales].[orders] AS t ON t.[id] = ct.[id]",
+		"ORDER BY ct.SYS_CHANGE_VERSION",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("changeTrackingSQL missing %q:\n%s", want, got)
+		}
+	}
+	if n := len(changeTrackingScanSchema(schema, []int{0}).Fields); n != 6 {
+		t.Errorf("scan schema: %d fields, want 6", n)
+	}
+}
+
+func TestChangeTrackingChanges(t *testing.T) {
+	schema := packet.Schema{Fields: []packet.Field{
+		{Name: "id", Type: "INTEGER", Key: true},
+		{Name: "name", Type: "TEXT"},
+	}}
+	// версия, операция, признак строки, ct.id, t.id, t.name
+	rows := [][]string{
+		{"11", "I", "1", "1", "1", "Ann"},
+		{"12", "U", "1", "2", "2", "Bob"},
+		{"12", "D", "0", "3", "", ""},
+		{"14", "I", "0", "4", "", ""}, // вставлена и удалена после
+	}
+
+	changes, checkpoint := changeTrackingChanges(rows, schema, []int{0}, 0, "20")
+	if checkpoint != "20" || len(changes) != 4 {
+		t.Fatalf("got %d changes, checkpoint %s; want 4, 20", len(changes), checkpoint)
+	}
+	if c := changes[0]; c.Op != sync.ChangeInsert || c.LSN != "11" || c.Columns[1].Value != "Ann" {
+		t.Errorf("insert: %+v", c)
+	}
+	if c := changes[1]; c.Op != sync.ChangeUpdate || c.Columns[0].Value != "2" {
+		t.Errorf("update: %+v", c)
+	}
+	for _, c := range changes[2:] {
+		if c.Op != sync.ChangeDelete || len(c.Identity) != 1 || c.Identity[0].Name != "id" {
+			t.Errorf("delete: %+v", c)
+		}
+	}
+
+	packets, err := sync.ChangePackets("orders", schema, changes)
+	if err != nil {
+		t.Fatalf("ChangePackets: %v", err)
+	}
+	if len(packets) != 2 || len(packets[0].GetRows()) != 2 || !packets[1].Data.Delete {
+		t.Fatalf("packets: got %d", len(packets))
+	}
+	if keys, _ := packets[1].DeleteKeys(); len(keys) != 2 {
+		t.Errorf("delete keys: %v", keys)
+	}
+
+	// Пачка режется на границе версии: обе строки версии 12 входят
+	changes, checkpoint = changeTrackingChanges(rows, schema, []int{0}, 2, "20")
+	if len(changes) != 3 || checkpoint != "12" {
+		t.Errorf("batch: got %d changes, checkpoint %s; want 3, 12", len(changes), checkpoint)
+	}
+}
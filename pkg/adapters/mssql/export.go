@@ -3,6 +3,7 @@ package mssql
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"strings"
 
@@ -10,40 +11,38 @@ import (
 	"github.com/ruslano69/tdtp-framework/pkg/adapters/base"
 	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
 	"github.com/ruslano69/tdtp-framework/pkg/core/tdtql"
+	"github.com/ruslano69/tdtp-framework/pkg/sync"
 )
 
-// Context key для передачи флага includeReadOnly через контекст
-type contextKey string
-
-const includeReadOnlyFieldsKey contextKey = "includeReadOnlyFields"
-
-// WithIncludeReadOnlyFields добавляет флаг includeReadOnly в контекст
-// Используется CLI для передачи флага --readonly-fields в адаптер
+// WithIncludeReadOnlyFields добавляет флаг includeReadOnly в контекст.
+// Оставлен для совместимости — см. adapters.WithIncludeReadOnlyFields.
 func WithIncludeReadOnlyFields(ctx context.Context, include bool) context.Context {
-	return context.WithValue(ctx, includeReadOnlyFieldsKey, include)
+	return adapters.WithIncludeReadOnlyFields(ctx, include)
 }
 
 // getIncludeReadOnlyFromContext извлекает флаг includeReadOnly из контекста
 // По умолчанию возвращает false (не экспортировать read-only поля)
 func getIncludeReadOnlyFromContext(ctx context.Context) bool {
-	if val := ctx.Value(includeReadOnlyFieldsKey); val != nil {
-		if boolVal, ok := val.(bool); ok {
-			return boolVal
-		}
-	}
-	return false // По умолчанию НЕ экспортируем read-only поля
+	return adapters.IncludeReadOnlyFieldsFromContext(ctx)
 }
 
 // ========== Schema Operations ==========
 
 // GetTableSchema возвращает схему таблицы в формате TDTP
-// Читает метаданные из INFORMATION_SCHEMA
+// Читает метаданные из INFORMATION_SCHEMA БД объекта. Синонимы раскрываются
+// в базовый объект, имена из 3–4 частей (db.schema.table, linked server)
+// читают каталог своей БД.
 func (a *Adapter) GetTableSchema(ctx context.Context, tableName string) (packet.Schema, error) {
-	schemaName, tableName := a.parseTableName(tableName)
+	obj, err := a.resolveObject(ctx, tableName)
+	if err != nil {
+		return packet.Schema{}, err
+	}
 
 	// SQL Server 2012+ compatible query
-	// Enhanced to detect read-only fields: timestamp, computed, identity
-	query := `
+	// Enhanced to detect read-only fields: timestamp, computed, identity.
+	// Флаги computed/identity берутся из sys.columns БД объекта:
+	// COLUMNPROPERTY/OBJECT_ID работают только в текущей БД.
+	query := fmt.Sprintf(`
 		SELECT
 			c.COLUMN_NAME,
 			c.DATA_TYPE,
@@ -55,13 +54,14 @@ func (a *Adapter) GetTableSchema(ctx context.Context, tableName string) (packet.
 				WHEN pk.COLUMN_NAME IS NOT NULL THEN 1
 				ELSE 0
 			END AS IS_PRIMARY_KEY,
-			COLUMNPROPERTY(OBJECT_ID(c.TABLE_SCHEMA + '.' + c.TABLE_NAME), c.COLUMN_NAME, 'IsComputed') AS IS_COMPUTED,
-			COLUMNPROPERTY(OBJECT_ID(c.TABLE_SCHEMA + '.' + c.TABLE_NAME), c.COLUMN_NAME, 'IsIdentity') AS IS_IDENTITY
-		FROM INFORMATION_SCHEMA.COLUMNS c
+			CAST(sc.is_computed AS INT) AS IS_COMPUTED,
+			CAST(sc.is_identity AS INT) AS IS_IDENTITY,
+			CAST(ep.value AS NVARCHAR(MAX)) AS DESCRIPTION
+		FROM %[1]sINFORMATION_SCHEMA.COLUMNS c
 		LEFT JOIN (
 			SELECT ku.TABLE_SCHEMA, ku.TABLE_NAME, ku.COLUMN_NAME
-			FROM INFORMATION_SCHEMA.TABLE_CONSTRAINTS tc
-			INNER JOIN INFORMATION_SCHEMA.KEY_COLUMN_USAGE ku
+			FROM %[1]sINFORMATION_SCHEMA.TABLE_CONSTRAINTS tc
+			INNER JOIN %[1]sINFORMATION_SCHEMA.KEY_COLUMN_USAGE ku
 				ON tc.CONSTRAINT_TYPE = 'PRIMARY KEY'
 				AND tc.CONSTRAINT_NAME = ku.CONSTRAINT_NAME
 				AND tc.TABLE_SCHEMA = ku.TABLE_SCHEMA
@@ -69,11 +69,23 @@ func (a *Adapter) GetTableSchema(ctx context.Context, tableName string) (packet.
 		) pk ON c.TABLE_SCHEMA = pk.TABLE_SCHEMA
 			AND c.TABLE_NAME = pk.TABLE_NAME
 			AND c.COLUMN_NAME = pk.COLUMN_NAME
+		LEFT JOIN (
+			SELECT s.name AS schema_name, o.name AS object_name, col.object_id, col.column_id,
+				col.name, col.is_computed, col.is_identity
+			FROM %[1]ssys.columns col
+			INNER JOIN %[1]ssys.objects o ON col.object_id = o.object_id
+			INNER JOIN %[1]ssys.schemas s ON o.schema_id = s.schema_id
+		) sc ON sc.schema_name = c.TABLE_SCHEMA
+			AND sc.object_name = c.TABLE_NAME
+			AND sc.name = c.COLUMN_NAME
+		LEFT JOIN %[1]ssys.extended_properties ep
+			ON ep.class = 1 AND ep.major_id = sc.object_id AND ep.minor_id = sc.column_id
+			AND ep.name = 'MS_Description'
 		WHERE c.TABLE_SCHEMA = ? AND c.TABLE_NAME = ?
 		ORDER BY c.ORDINAL_POSITION
-	`
+	`, obj.catalog())
 
-	rows, err := a.db.QueryContext(ctx, query, schemaName, tableName)
+	rows, err := a.db.QueryContext(ctx, query, obj.Schema, obj.Name)
 	if err != nil {
 		return packet.Schema{}, fmt.Errorf("failed to query table schema: %w", err)
 	}
@@ -92,6 +104,7 @@ func (a *Adapter) GetTableSchema(ctx context.Context, tableName string) (packet.
 			isPrimaryKey int
 			isComputed   sql.NullInt64
 			isIdentity   sql.NullInt64
+			description  sql.NullString
 		)
 
 		err := rows.Scan(
@@ -104,6 +117,7 @@ func (a *Adapter) GetTableSchema(ctx context.Context, tableName string) (packet.
 			&isPrimaryKey,
 			&isComputed,
 			&isIdentity,
+			&description,
 		)
 		if err != nil {
 			return packet.Schema{}, fmt.Errorf("failed to scan column info: %w", err)
@@ -136,6 +150,7 @@ func (a *Adapter) GetTableSchema(ctx context.Context, tableName string) (packet.
 		isIdentityBool := isIdentity.Valid && isIdentity.Int64 == 1
 
 		field.ReadOnly = isReadOnlyField(isTimestamp, isComputedBool, isIdentityBool)
+		field.Description = description.String
 
 		fields = append(fields, field)
 	}
@@ -145,11 +160,26 @@ func (a *Adapter) GetTableSchema(ctx context.Context, tableName string) (packet.
 	}
 
 	if len(fields) == 0 {
-		return packet.Schema{}, fmt.Errorf("table %s.%s not found or has no columns", schemaName, tableName)
+		return packet.Schema{}, fmt.Errorf("table %s %w", obj, adapters.ErrTableNotFound)
+	}
+
+	// Описание таблицы (extended property MS_Description)
+	var tableDescription sql.NullString
+	err = a.db.QueryRowContext(ctx, fmt.Sprintf(`
+		SELECT CAST(ep.value AS NVARCHAR(MAX))
+		FROM %[1]ssys.extended_properties ep
+		INNER JOIN %[1]ssys.objects o ON ep.major_id = o.object_id
+		INNER JOIN %[1]ssys.schemas s ON o.schema_id = s.schema_id
+		WHERE ep.class = 1 AND ep.minor_id = 0 AND ep.name = 'MS_Description'
+			AND s.name = ? AND o.name = ?
+	`, obj.catalog()), obj.Schema, obj.Name).Scan(&tableDescription)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return packet.Schema{}, fmt.Errorf("failed to query table description: %w", err)
 	}
 
 	return packet.Schema{
-		Fields: fields,
+		Fields:      fields,
+		Description: tableDescription.String,
 	}, nil
 }
 
@@ -224,6 +254,76 @@ func filterReadOnlyFields(schema packet.Schema, rows [][]string, includeReadOnly
 	return filteredSchema, filteredRows
 }
 
+// GetQuerySchema возвращает схему результата SELECT без его выполнения через
+// sys.dm_exec_describe_first_result_set (SQL Server 2012+). В отличие от
+// обёртки в подзапрос, допускает ORDER BY и CTE в самом запросе.
+// Реализует base.QuerySchemaReader для собственных запросов таблиц.
+func (a *Adapter) GetQuerySchema(ctx context.Context, query string) (packet.Schema, error) {
+	rows, err := a.db.QueryContext(ctx, `
+		SELECT
+			name,
+			TYPE_NAME(system_type_id),
+			max_length,
+			precision,
+			scale,
+			CAST(is_computed_column AS INT),
+			CAST(is_identity_column AS INT),
+			error_message
+		FROM sys.dm_exec_describe_first_result_set(?, NULL, 0)
+		ORDER BY column_ordinal
+	`, query)
+	if err != nil {
+		return packet.Schema{}, fmt.Errorf("failed to describe query: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var fields []packet.Field
+	for rows.Next() {
+		var (
+			columnName sql.NullString
+			dataType   sql.NullString
+			maxLength  sql.NullInt64
+			precision  sql.NullInt64
+			scale      sql.NullInt64
+			isComputed sql.NullInt64
+			isIdentity sql.NullInt64
+			errMessage sql.NullString
+		)
+		if err := rows.Scan(&columnName, &dataType, &maxLength, &precision, &scale, &isComputed, &isIdentity, &errMessage); err != nil {
+			return packet.Schema{}, fmt.Errorf("failed to scan column info: %w", err)
+		}
+		if errMessage.Valid {
+			return packet.Schema{}, fmt.Errorf("failed to describe query: %s", errMessage.String)
+		}
+
+		// max_length — в байтах и для всех типов; длину передаём только
+		// строкам и binary (nchar/nvarchar — 2 байта на символ, -1 — MAX)
+		var lenInt, precInt, scaleInt int
+		typeName := strings.ToLower(dataType.String)
+		switch typeName {
+		case "char", "varchar", "binary", "varbinary":
+			lenInt = int(max(maxLength.Int64, 0))
+		case "nchar", "nvarchar":
+			lenInt = int(max(maxLength.Int64, 0) / 2)
+		case "decimal", "numeric":
+			precInt, scaleInt = int(precision.Int64), int(scale.Int64)
+		}
+
+		field := BuildFieldFromColumn(columnName.String, typeName, lenInt, precInt, scaleInt, false)
+		field.ReadOnly = isReadOnlyField(
+			typeName == "timestamp",
+			isComputed.Valid && isComputed.Int64 == 1,
+			isIdentity.Valid && isIdentity.Int64 == 1,
+		)
+		fields = append(fields, field)
+	}
+	if err := rows.Err(); err != nil {
+		return packet.Schema{}, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return packet.Schema{Fields: fields}, nil
+}
+
 // GetTableNames and TableExists are implemented in adapter.go
 
 // ========== Export Operations ==========
@@ -248,12 +348,48 @@ func (a *Adapter) SetSkipSpecialValues(skip bool) {
 	a.exportHelper.SetSkipSpecialValues(skip)
 }
 
+// SetCompression включает zstd-сжатие Data экспортируемых пакетов
+// (см. base.ExportHelper.SetCompression).
+func (a *Adapter) SetCompression(opts packet.CompressionOptions) {
+	a.exportHelper.SetCompression(opts)
+}
+
+// SetPacketKeys включает шифрование пакетов TDTP v1.5 при экспорте и их
+// расшифровку при импорте (см. base.ExportHelper.SetPacketKeys).
+func (a *Adapter) SetPacketKeys(keys base.PacketKeyProvider) {
+	a.packetKeys = keys
+	a.exportHelper.SetPacketKeys(keys)
+}
+
+// SetKeyMapper задаёт выдачу суррогатных ключей при импорте (keymap.Mapper).
+func (a *Adapter) SetKeyMapper(m base.KeyMapper) {
+	a.keyMapper = m
+}
+
 // SetMaxFallbackRows задаёт лимит строк для in-memory fallback при провале SQL pushdown.
 // Вызывается из CLI при указании --fallback-row-limit.
 func (a *Adapter) SetMaxFallbackRows(n int64) {
 	a.exportHelper.SetMaxFallbackRows(n)
 }
 
+// SetTableQueries задаёт собственные SELECT для таблиц вместо SELECT *
+// (см. base.ExportHelper.SetTableQueries).
+func (a *Adapter) SetTableQueries(queries map[string]string) error {
+	return a.exportHelper.SetTableQueries(queries)
+}
+
+// ExportQuery экспортирует результат произвольного read-only SQL
+// (adapters.QueryExporter, см. base.ExportHelper.ExportQuery).
+func (a *Adapter) ExportQuery(ctx context.Context, sql string) ([]*packet.DataPacket, error) {
+	return a.exportHelper.ExportQuery(ctx, sql)
+}
+
+// ExplainQuery возвращает план ExportTableWithQuery без чтения строк
+// (adapters.QueryExplainer, см. base.ExportHelper.Explain).
+func (a *Adapter) ExplainQuery(ctx context.Context, tableName string, query *packet.Query) (*packet.ExecutionPlan, error) {
+	return a.exportHelper.Explain(ctx, tableName, query)
+}
+
 // ExportTable экспортирует всю таблицу в TDTP reference пакеты
 // Делегирует в base.ExportHelper для устранения дублирования кода
 func (a *Adapter) ExportTable(ctx context.Context, tableName string) ([]*packet.DataPacket, error) {
@@ -303,17 +439,10 @@ func (a *Adapter) ReadAllRows(ctx context.Context, tableName string, pkgSchema p
 
 // readAllRows читает все строки из таблицы
 func (a *Adapter) readAllRows(ctx context.Context, tableName string, pkgSchema packet.Schema) ([][]string, error) {
-	schemaName, table := a.parseTableName(tableName)
-	fullTableName := fmt.Sprintf("[%s].[%s]", schemaName, table)
-
-	// Формируем список полей для SELECT
-	columns := make([]string, 0, len(pkgSchema.Fields))
-	for _, field := range pkgSchema.Fields {
-		columns = append(columns, fmt.Sprintf("[%s]", field.Name))
+	query, err := a.selectAllSQL(tableName, pkgSchema)
+	if err != nil {
+		return nil, err
 	}
-
-	query := fmt.Sprintf("SELECT %s FROM %s", strings.Join(columns, ", "), fullTableName)
-
 	rows, err := a.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query table: %w", err)
@@ -323,6 +452,39 @@ func (a *Adapter) readAllRows(ctx context.Context, tableName string, pkgSchema p
 	return a.scanRows(rows, pkgSchema)
 }
 
+// StreamAllRows implements base.RowStreamer interface
+// Reads a table row by row without materializing it
+func (a *Adapter) StreamAllRows(ctx context.Context, tableName string, pkgSchema packet.Schema, fn func(row []string) error) error {
+	query, err := a.selectAllSQL(tableName, pkgSchema)
+	if err != nil {
+		return err
+	}
+	rows, err := a.db.QueryContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to query table: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	return base.StreamSQLRows(rows, pkgSchema, a.converter, "mssql", fn)
+}
+
+// selectAllSQL формирует SELECT всех полей схемы из таблицы.
+// Имя квотируется целиком (до 4 частей); синоним SQL Server раскрывает сам.
+func (a *Adapter) selectAllSQL(tableName string, pkgSchema packet.Schema) (string, error) {
+	obj, err := a.parseObjectName(tableName)
+	if err != nil {
+		return "", err
+	}
+
+	// Формируем список полей для SELECT
+	columns := make([]string, 0, len(pkgSchema.Fields))
+	for _, field := range pkgSchema.Fields {
+		columns = append(columns, fmt.Sprintf("[%s]", field.Name))
+	}
+
+	return fmt.Sprintf("SELECT %s FROM %s", strings.Join(columns, ", "), obj.quoted()), nil
+}
+
 // ReadRowsWithSQL implements base.DataReader interface
 // Executes a SQL query and returns rows
 func (a *Adapter) ReadRowsWithSQL(ctx context.Context, sqlQuery string, pkgSchema packet.Schema) ([][]string, error) {
@@ -346,21 +508,25 @@ func (a *Adapter) scanRows(rows *sql.Rows, pkgSchema packet.Schema) ([][]string,
 }
 
 // GetRowCount implements base.DataReader interface
+// Считает по sys.partitions БД объекта (синоним раскрывается в базовую таблицу).
 func (a *Adapter) GetRowCount(ctx context.Context, tableName string) (int64, error) {
-	schemaName, table := a.parseTableName(tableName)
+	obj, err := a.resolveObject(ctx, tableName)
+	if err != nil {
+		return 0, err
+	}
 
-	query := `
+	query := fmt.Sprintf(`
 		SELECT SUM(p.rows)
-		FROM sys.tables t
-		INNER JOIN sys.schemas s ON t.schema_id = s.schema_id
-		INNER JOIN sys.partitions p ON t.object_id = p.object_id
+		FROM %[1]ssys.tables t
+		INNER JOIN %[1]ssys.schemas s ON t.schema_id = s.schema_id
+		INNER JOIN %[1]ssys.partitions p ON t.object_id = p.object_id
 		WHERE s.name = ?
 			AND t.name = ?
 			AND p.index_id IN (0, 1)
-	`
+	`, obj.catalog())
 
 	var count sql.NullInt64
-	err := a.db.QueryRowContext(ctx, query, schemaName, table).Scan(&count)
+	err = a.db.QueryRowContext(ctx, query, obj.Schema, obj.Name).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("failed to get row count: %w", err)
 	}
@@ -372,8 +538,36 @@ func (a *Adapter) GetRowCount(ctx context.Context, tableName string) (int64, err
 	return count.Int64, nil
 }
 
+// TableStatistics implements adapters.StatisticsReader: the approximate row
+// count from sys.partitions (histograms stay in DBCC SHOW_STATISTICS).
+func (a *Adapter) TableStatistics(ctx context.Context, tableName string) (*adapters.TableStatistics, error) {
+	count, err := a.GetRowCount(ctx, tableName)
+	if err != nil {
+		return nil, err
+	}
+	return &adapters.TableStatistics{RowCount: count}, nil
+}
+
 // ExportTableIncremental экспортирует только измененные записи с момента последней синхронизации
-// Реализует интерфейс adapters.Adapter
+// Реализует интерфейс adapters.Adapter. Пакеты проходят тот же финальный
+// шаг, что и полный экспорт (base.ExportHelper.Seal).
 func (a *Adapter) ExportTableIncremental(ctx context.Context, tableName string, incrementalConfig adapters.IncrementalConfig) ([]*packet.DataPacket, string, error) {
-	return nil, "", fmt.Errorf("incremental export not yet implemented for MS SQL adapter")
+	packets, last, err := a.exportTableIncremental(ctx, tableName, incrementalConfig)
+	if err != nil {
+		return nil, "", err
+	}
+	if packets, err = a.exportHelper.Seal(ctx, packets); err != nil {
+		return nil, "", err
+	}
+	return packets, last, nil
+}
+
+func (a *Adapter) exportTableIncremental(ctx context.Context, tableName string, incrementalConfig adapters.IncrementalConfig) ([]*packet.DataPacket, string, error) {
+	if err := incrementalConfig.Validate(); err != nil {
+		return nil, "", fmt.Errorf("invalid incremental config: %w", err)
+	}
+	if incrementalConfig.Strategy == sync.TrackingChangeTable {
+		return a.exportTableChangeTracking(ctx, tableName, incrementalConfig)
+	}
+	return nil, "", fmt.Errorf("incremental export by %s tracking not yet implemented for MS SQL adapter (use change tracking)", incrementalConfig.Strategy)
 }
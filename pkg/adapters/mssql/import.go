@@ -4,10 +4,12 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"slices"
 	"strings"
 
 	mssql "github.com/denisenkom/go-mssqldb"
 	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+	"github.com/ruslano69/tdtp-framework/pkg/adapters/base"
 	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
 	"github.com/ruslano69/tdtp-framework/pkg/core/schema"
 )
@@ -20,9 +22,124 @@ var (
 
 // ========== Import Operations ==========
 
-// ImportPacket импортирует один TDTP пакет в БД
+// ImportPacket импортирует один TDTP пакет в БД под ограничениями
+// ImportLimits (см. adapters.Governor).
 func (a *Adapter) ImportPacket(ctx context.Context, pkt *packet.DataPacket, strategy adapters.ImportStrategy) error {
+	ctx = adapters.WithMessageID(ctx, pkt.Header.MessageID) // msg= в журнале выражений
+	if err := base.PrepareImport(ctx, pkt, a.packetKeys, a.keyMapper, a.cipher); err != nil {
+		return err
+	}
+	if _, err := base.DedupPacket(pkt, a.duplicates); err != nil {
+		return err
+	}
+	if err := base.ApplyImportProvenance(ctx, a, []*packet.DataPacket{pkt}); err != nil {
+		return err
+	}
+	matched, err := a.columns.Match(ctx, []*packet.DataPacket{pkt})
+	if err != nil {
+		return err
+	}
+	pkt = matched[0]
+	if err := base.RejectPacketRows([]*packet.DataPacket{pkt}, a.rowErrors, a.converter, "mssql", &adapters.ImportReport{}); err != nil {
+		return err
+	}
+	// Очистка таблицы (StrategyTruncate) — только в окне обслуживания
+	destructive := strategy == adapters.StrategyTruncate
+	if err := base.AwaitMaintenance(ctx, a.maintenance, destructive, []*packet.DataPacket{pkt}); err != nil {
+		return err
+	}
+	ctx, release, err := base.LockTables(ctx, a, a.tableLock, pkt.Header.TableName)
+	if err != nil {
+		return err
+	}
+	defer release()
+	return a.governor.Do(ctx, len(pkt.Data.Rows), func() error {
+		return a.importPacket(ctx, pkt, strategy)
+	})
+}
+
+// ImportPackets импортирует множество пакетов атомарно под ограничениями
+// ImportLimits: вся пачка — одна транзакция для Governor.
+func (a *Adapter) ImportPackets(ctx context.Context, packets []*packet.DataPacket, strategy adapters.ImportStrategy) error {
+	return a.importPacketsReport(ctx, packets, strategy, &adapters.ImportReport{Strategy: strategy})
+}
+
+// ImportPacketsReport реализует adapters.ReportingImporter: ImportPackets
+// с отчётом об отклонённых строках (Config.RowErrors).
+func (a *Adapter) ImportPacketsReport(ctx context.Context, packets []*packet.DataPacket, strategy adapters.ImportStrategy) (*adapters.ImportReport, error) {
+	report := &adapters.ImportReport{Strategy: strategy}
+	return report, a.importPacketsReport(ctx, packets, strategy, report)
+}
+
+// importPacketsReport — тело ImportPackets/ImportPacketsReport.
+func (a *Adapter) importPacketsReport(ctx context.Context, packets []*packet.DataPacket, strategy adapters.ImportStrategy, report *adapters.ImportReport) error {
+	var tables []string
+	for _, pkt := range packets {
+		if pkt != nil {
+			if err := base.PrepareImport(ctx, pkt, a.packetKeys, a.keyMapper, a.cipher); err != nil {
+				return err
+			}
+			if _, err := base.DedupPacket(pkt, a.duplicates); err != nil {
+				return err
+			}
+			tables = append(tables, pkt.Header.TableName)
+		}
+	}
+	if err := base.ApplyImportProvenance(ctx, a, packets); err != nil {
+		return err
+	}
+	packets, err := a.columns.Match(ctx, packets)
+	if err != nil {
+		return err
+	}
+	if err := base.RejectPacketRows(packets, a.rowErrors, a.converter, "mssql", report); err != nil {
+		return err
+	}
+	rows := 0
+	for _, pkt := range packets {
+		if pkt != nil {
+			rows += len(pkt.Data.Rows)
+		}
+	}
+	// Очистка таблицы (StrategyTruncate) — только в окне обслуживания
+	destructive := strategy == adapters.StrategyTruncate
+	if err := base.AwaitMaintenance(ctx, a.maintenance, destructive, packets); err != nil {
+		return err
+	}
+	ctx, release, err := base.LockTables(ctx, a, a.tableLock, tables...)
+	if err != nil {
+		return err
+	}
+	defer release()
+	return a.governor.Do(ctx, rows, func() error {
+		return a.importPackets(ctx, packets, strategy)
+	})
+}
+
+// ImportPacketStream импортирует пакеты из канала пачками по
+// Config.StreamBatchPackets, каждая пачка — ImportPackets в своей транзакции.
+// Реализует adapters.PacketStreamImporter.
+func (a *Adapter) ImportPacketStream(ctx context.Context, packets <-chan *packet.DataPacket, strategy adapters.ImportStrategy) error {
+	return adapters.ImportPacketBatches(ctx, packets, strategy, a.streamBatch, a.ImportPackets)
+}
+
+// DryRunImport реализует adapters.DryRunImporter: проверка пакетов против
+// БД без записи (base.DryRun).
+func (a *Adapter) DryRunImport(ctx context.Context, packets []*packet.DataPacket, strategy adapters.ImportStrategy) (*adapters.ImportReport, error) {
+	d := &base.DryRun{Target: a, Converter: a.converter, DBType: "mssql", Keys: a.packetKeys, Duplicates: a.duplicates, Columns: a.columns.Mode(), Cipher: a.cipher}
+	return d.Import(ctx, packets, strategy)
+}
+
+// importPacket импортирует один TDTP пакет в БД
+func (a *Adapter) importPacket(ctx context.Context, pkt *packet.DataPacket, strategy adapters.ImportStrategy) error {
 	pkt.MaterializeRows()
+	if pkt.Data.Delta {
+		return a.ApplyDelta(ctx, []*packet.DataPacket{pkt}, strategy)
+	}
+	if pkt.Data.Delete {
+		_, err := a.ApplyDelete(ctx, []*packet.DataPacket{pkt})
+		return err
+	}
 	// DDL вне транзакции — чтобы не блокироваться на Sch-M lock
 	tableName := pkt.Header.TableName
 	exists, err := a.TableExists(ctx, tableName)
@@ -42,14 +159,19 @@ func (a *Adapter) ImportPacket(ctx context.Context, pkt *packet.DataPacket, stra
 	}
 	defer func() { _ = tx.Rollback() }()
 
+	if strategy == adapters.StrategyTruncate && exists {
+		if err := a.truncateInTx(ctx, tx, tableName); err != nil {
+			return err
+		}
+	}
 	if err := a.importPacketDataInTx(ctx, tx, pkt, strategy); err != nil {
 		return err
 	}
 	return tx.Commit()
 }
 
-// ImportPackets импортирует множество пакетов атомарно (в одной транзакции)
-func (a *Adapter) ImportPackets(ctx context.Context, packets []*packet.DataPacket, strategy adapters.ImportStrategy) error {
+// importPackets импортирует множество пакетов атомарно (в одной транзакции)
+func (a *Adapter) importPackets(ctx context.Context, packets []*packet.DataPacket, strategy adapters.ImportStrategy) error {
 	if len(packets) == 0 {
 		return nil
 	}
@@ -59,9 +181,26 @@ func (a *Adapter) ImportPackets(ctx context.Context, packets []*packet.DataPacke
 		pkt.MaterializeRows()
 	}
 
+	delta, err := base.IsDeltaBatch(packets)
+	if err != nil {
+		return err
+	}
+	if delta {
+		return a.ApplyDelta(ctx, packets, strategy)
+	}
+	if data, deletes := base.SplitDeletePackets(packets); len(deletes) > 0 {
+		// Tombstones инкрементальной выгрузки: сначала данные, затем удаления
+		if err := a.importPackets(ctx, data, strategy); err != nil {
+			return err
+		}
+		_, err := a.ApplyDelete(ctx, deletes)
+		return err
+	}
+
 	// DDL (CREATE TABLE) выполняем ВНЕ транзакции.
 	// Внутри транзакции DDL берёт Sch-M lock и блокируется если другое соединение
 	// (например BC) держит Sch-S lock на схему — это причина зависания.
+	var truncate []string // существовавшие таблицы StrategyTruncate
 	for i, pkt := range packets {
 		if pkt == nil {
 			return fmt.Errorf("packet %d is nil", i)
@@ -75,6 +214,8 @@ func (a *Adapter) ImportPackets(ctx context.Context, packets []*packet.DataPacke
 			if err := a.CreateTable(ctx, tableName, pkt.Schema); err != nil {
 				return fmt.Errorf("failed to create table %s: %w", tableName, err)
 			}
+		} else if strategy == adapters.StrategyTruncate && !slices.Contains(truncate, tableName) {
+			truncate = append(truncate, tableName)
 		}
 	}
 
@@ -87,8 +228,14 @@ func (a *Adapter) ImportPackets(ctx context.Context, packets []*packet.DataPacke
 		_ = tx.Rollback()
 	}()
 
+	for _, tableName := range truncate {
+		if err := a.truncateInTx(ctx, tx, tableName); err != nil {
+			return err
+		}
+	}
+
 	for i, pkt := range packets {
-		if err := a.importPacketDataInTx(ctx, tx, pkt, strategy); err != nil {
+		if err := a.importPacketDataInTx(adapters.WithMessageID(ctx, pkt.Header.MessageID), tx, pkt, strategy); err != nil {
 			return fmt.Errorf("failed to import packet %d: %w", i, err)
 		}
 	}
@@ -100,8 +247,72 @@ func (a *Adapter) ImportPackets(ctx context.Context, packets []*packet.DataPacke
 	return nil
 }
 
+// ApplyDelta применяет delta-пакеты точечными UPDATE в одной транзакции.
+// Таблица должна существовать: delta-пакет не создаёт строк.
+func (a *Adapter) ApplyDelta(ctx context.Context, packets []*packet.DataPacket, strategy adapters.ImportStrategy) error {
+	return base.ApplyDeltaPacketsSQL(ctx, a.db, packets, strategy, a.quoteTable, a.deltaDialect())
+}
+
+// ApplyDelete удаляет строки по ключам пакетов удаления в одной транзакции.
+// Реализует base.DeleteApplier
+func (a *Adapter) ApplyDelete(ctx context.Context, packets []*packet.DataPacket) (int, error) {
+	return base.ApplyDeletePacketsSQL(ctx, a.db, packets, a.quoteTable, a.deltaDialect())
+}
+
+// deltaDialect — SQL-диалект MS SQL для delta- и delete-пакетов.
+func (a *Adapter) deltaDialect() base.DeltaDialect {
+	return base.DeltaDialect{
+		Quote:       quoteMSSQLIdent,
+		Placeholder: func(int) string { return "?" },
+		Convert: func(value string, field packet.Field) (any, error) {
+			return a.stringToValue(value, field), nil
+		},
+	}
+}
+
+// quoteTable возвращает [schema].[table] для имени таблицы.
+func (a *Adapter) quoteTable(tableName string) string {
+	schemaName, table := a.parseTableName(tableName)
+	return quoteMSSQLIdent(schemaName) + "." + quoteMSSQLIdent(table)
+}
+
+func quoteMSSQLIdent(name string) string { return "[" + strings.ReplaceAll(name, "]", "]]") + "]" }
+
 // ========== Table Creation ==========
 
+// GenerateDDL возвращает CREATE TABLE для TDTP схемы и описания таблицы и
+// колонок — extended property MS_Description (adapters.DDLGenerator).
+// Без подключения схема по умолчанию — Config.Schema или dbo.
+func (a *Adapter) GenerateDDL(tableName string, pktSchema packet.Schema) []string {
+	ddl := []string{a.buildCreateTableSQL(tableName, pktSchema)}
+
+	schemaName, table := a.parseTableName(tableName)
+	if pktSchema.Description != "" {
+		ddl = append(ddl, descriptionSQL(pktSchema.Description, schemaName, table, ""))
+	}
+	for _, field := range pktSchema.Fields {
+		if comment := adapters.ColumnComment(field); comment != "" {
+			ddl = append(ddl, descriptionSQL(comment, schemaName, table, field.Name))
+		}
+	}
+	return ddl
+}
+
+// descriptionSQL строит sp_addextendedproperty MS_Description для таблицы
+// (column пустой) или колонки.
+func descriptionSQL(description, schemaName, table, column string) string {
+	sqlStr := fmt.Sprintf("EXEC sys.sp_addextendedproperty @name = N'MS_Description', @value = %s, "+
+		"@level0type = N'SCHEMA', @level0name = %s, @level1type = N'TABLE', @level1name = %s",
+		nstring(description), nstring(schemaName), nstring(table))
+	if column != "" {
+		sqlStr += ", @level2type = N'COLUMN', @level2name = " + nstring(column)
+	}
+	return sqlStr
+}
+
+// nstring экранирует Unicode-литерал N'...'
+func nstring(value string) string { return "N'" + strings.ReplaceAll(value, "'", "''") + "'" }
+
 // buildCreateTableSQL строит CREATE TABLE запрос
 func (a *Adapter) buildCreateTableSQL(tableName string, pktSchema packet.Schema) string {
 	schemaName, table := a.parseTableName(tableName)
@@ -138,6 +349,17 @@ func (a *Adapter) buildCreateTableSQL(tableName string, pktSchema packet.Schema)
 
 // ========== Data Import ==========
 
+// truncateInTx очищает таблицу StrategyTruncate в транзакции импорта:
+// TRUNCATE TABLE в SQL Server транзакционен и откатывается вместе с
+// неудачной загрузкой. Только что созданную таблицу очищать незачем.
+func (a *Adapter) truncateInTx(ctx context.Context, tx *sql.Tx, tableName string) error {
+	fmt.Printf("🧹 Truncating table: %s\n", tableName)
+	if _, err := tx.ExecContext(ctx, "TRUNCATE TABLE "+a.quoteTable(tableName)); err != nil {
+		return fmt.Errorf("failed to truncate table %s: %w", tableName, err)
+	}
+	return nil
+}
+
 // importPacketDataInTx импортирует данные пакета в рамках транзакции
 func (a *Adapter) importPacketDataInTx(
 	ctx context.Context,
@@ -150,19 +372,22 @@ func (a *Adapter) importPacketDataInTx(
 	}
 
 	switch strategy {
-	case adapters.StrategyReplace:
-		return a.importWithMerge(ctx, tx, pkt)
+	case adapters.StrategyReplace, adapters.StrategyMerge:
+		updates, err := base.UpdateFields(ctx, pkt.Schema, strategy)
+		if err != nil {
+			return err
+		}
+		return a.importWithMerge(ctx, tx, pkt, updates)
 
 	case adapters.StrategyIgnore:
 		return a.importWithIgnore(ctx, tx, pkt)
 
-	case adapters.StrategyFail:
+	case adapters.StrategyFail, adapters.StrategyAppend, adapters.StrategyTruncate:
 		return a.importWithInsert(ctx, tx, pkt)
 
+	// Bulk copy пропускает CHECK и триггеры — только по явному StrategyCopy
 	case adapters.StrategyCopy:
-		// Bulk insert для SQL Server можно реализовать через Table-Valued Parameters
-		// Пока используем обычный INSERT (TODO: оптимизация)
-		return a.importWithInsert(ctx, tx, pkt)
+		return a.importWithBulkCopy(ctx, tx, pkt)
 
 	default:
 		return fmt.Errorf("unsupported import strategy: %s", strategy)
@@ -171,9 +396,10 @@ func (a *Adapter) importPacketDataInTx(
 
 // ========== MERGE Strategy (UPSERT) ==========
 
-// importWithMerge использует MERGE для UPSERT операций
+// importWithMerge использует MERGE для UPSERT операций: у совпавших
+// строк обновляются колонки updates (base.UpdateFields)
 // SQL Server 2012+ compatible
-func (a *Adapter) importWithMerge(ctx context.Context, tx *sql.Tx, pkt *packet.DataPacket) error {
+func (a *Adapter) importWithMerge(ctx context.Context, tx *sql.Tx, pkt *packet.DataPacket, updates []string) error {
 	// Находим primary key колонки
 	var pkFields []packet.Field
 	for _, field := range pkt.Schema.Fields {
@@ -226,7 +452,7 @@ func (a *Adapter) importWithMerge(ctx context.Context, tx *sql.Tx, pkt *packet.D
 		if end > len(rows) {
 			end = len(rows)
 		}
-		if err := a.executeBatchMerge(ctx, tx, fullTableName, pkt.Schema, pkFields, rows[i:end]); err != nil {
+		if err := a.executeBatchMerge(ctx, tx, fullTableName, pkt.Schema, pkFields, updates, rows[i:end]); err != nil {
 			return err
 		}
 	}
@@ -242,6 +468,7 @@ func (a *Adapter) executeBatchMerge(
 	fullTableName string,
 	pktSchema packet.Schema,
 	pkFields []packet.Field,
+	updates []string,
 	rows []packet.Row,
 ) error {
 	if len(rows) == 0 {
@@ -276,20 +503,11 @@ func (a *Adapter) executeBatchMerge(
 		pkConds = append(pkConds, fmt.Sprintf("t.%s = s.%s", col, col))
 	}
 
-	// UPDATE SET для non-PK колонок
-	updateSets := make([]string, 0, numCols)
-	for _, f := range pktSchema.Fields {
-		isPK := false
-		for _, pk := range pkFields {
-			if pk.Name == f.Name {
-				isPK = true
-				break
-			}
-		}
-		if !isPK {
-			col := fmt.Sprintf("[%s]", f.Name)
-			updateSets = append(updateSets, fmt.Sprintf("t.%s = s.%s", col, col))
-		}
+	// UPDATE SET для колонок updates (non-PK колонки пакета или список merge)
+	updateSets := make([]string, 0, len(updates))
+	for _, name := range updates {
+		col := fmt.Sprintf("[%s]", name)
+		updateSets = append(updateSets, fmt.Sprintf("t.%s = s.%s", col, col))
 	}
 
 	srcCols := strings.Join(colNames, ",")
@@ -312,7 +530,7 @@ func (a *Adapter) executeBatchMerge(
 			strings.Join(insertVals, ","),
 		)
 	} else {
-		// Все колонки — PK: только INSERT
+		// Обновлять нечего (все колонки — PK): только INSERT
 		mergeSQL = fmt.Sprintf(
 			"MERGE INTO %s AS t USING (VALUES %s) AS s(%s) ON %s WHEN NOT MATCHED THEN INSERT (%s) VALUES (%s);",
 			fullTableName,
@@ -484,6 +702,49 @@ func (a *Adapter) importWithInsert(ctx context.Context, tx *sql.Tx, pkt *packet.
 	return nil
 }
 
+// ========== BULK COPY Strategy ==========
+
+// importWithBulkCopy загружает строки протоколом bulk copy (INSERT BULK
+// через mssql.CopyIn) в транзакции tx — на порядок быстрее построчного
+// INSERT. CHECK-ограничения и триггеры не выполняются (как BULK INSERT по
+// умолчанию), ключи и уникальные индексы проверяются. Таблицу с IDENTITY
+// колонкой грузит importWithInsert: bulk copy драйвера не поддерживает
+// KEEPIDENTITY, и SQL Server заменил бы значения колонки своими.
+func (a *Adapter) importWithBulkCopy(ctx context.Context, tx *sql.Tx, pkt *packet.DataPacket) error {
+	if a.tableHasIdentityColumn(ctx, pkt.Header.TableName) {
+		return a.importWithInsert(ctx, tx, pkt)
+	}
+
+	columns := make([]string, len(pkt.Schema.Fields))
+	for i, field := range pkt.Schema.Fields {
+		columns[i] = field.Name // CopyIn сопоставляет колонки по имени без скобок
+	}
+
+	stmt, err := tx.PrepareContext(ctx, mssql.CopyIn(
+		a.quoteTable(pkt.Header.TableName), mssql.BulkOptions{KeepNulls: true}, columns...))
+	if err != nil {
+		return fmt.Errorf("failed to prepare bulk copy: %w", err)
+	}
+	defer func() { _ = stmt.Close() }()
+
+	for i, row := range pkt.Data.Rows {
+		args := a.rowToArgs(a.parseRow(row, pkt.Schema), pkt.Schema)
+		if _, err := stmt.ExecContext(ctx, args...); err != nil {
+			return fmt.Errorf("row %d: failed to buffer bulk copy row: %w", i+1, err)
+		}
+	}
+
+	// Exec без аргументов отправляет накопленные строки серверу
+	res, err := stmt.ExecContext(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to bulk copy rows: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && int(n) != len(pkt.Data.Rows) {
+		return fmt.Errorf("expected to copy %d rows, but copied %d", len(pkt.Data.Rows), n)
+	}
+	return nil
+}
+
 // buildInsertSQL строит INSERT запрос
 func (a *Adapter) buildInsertSQL(tableName string, pktSchema packet.Schema) string {
 	columns := make([]string, 0, len(pktSchema.Fields))
@@ -593,6 +854,9 @@ func (a *Adapter) stringToValue(str string, field packet.Field) any {
 		}
 	case schema.TypeBoolean:
 		if typedValue.BoolValue != nil {
+			if m := a.converter.BoolMapping(field.Name); m != nil {
+				return m.Format(*typedValue.BoolValue)
+			}
 			return *typedValue.BoolValue
 		}
 	case schema.TypeDate, schema.TypeDatetime, schema.TypeTimestamp:
@@ -651,10 +915,29 @@ func (a *Adapter) CreateTable(ctx context.Context, tableName string, pktSchema p
 	if exists {
 		return nil
 	}
-	sqlCreate := a.buildCreateTableSQL(tableName, pktSchema)
-	_, err = a.db.ExecContext(ctx, sqlCreate)
+	// CREATE TABLE — ошибка; описания (MS_Description) — только предупреждение
+	ddl := a.GenerateDDL(tableName, pktSchema)
+	_, err = a.db.ExecContext(ctx, ddl[0])
 	if err != nil {
-		return fmt.Errorf("failed to execute CREATE TABLE: %w\nSQL: %s", err, sqlCreate)
+		return fmt.Errorf("failed to execute CREATE TABLE: %w\nSQL: %s", err, ddl[0])
+	}
+	for _, descSQL := range ddl[1:] {
+		if _, derr := a.db.ExecContext(ctx, descSQL); derr != nil {
+			fmt.Printf("warning: could not add description: %v\n", derr)
+		}
+	}
+	return nil
+}
+
+// AddColumns добавляет колонки в существующую таблицу (adapters.ColumnAdder)
+func (a *Adapter) AddColumns(ctx context.Context, tableName string, fields []packet.Field) error {
+	columns := make([]string, len(fields))
+	for i, field := range fields {
+		columns[i] = fmt.Sprintf("%s %s NULL", quoteMSSQLIdent(field.Name), TDTPToMSSQL(field))
+	}
+	sqlAlter := fmt.Sprintf("ALTER TABLE %s ADD %s", a.quoteTable(tableName), strings.Join(columns, ", "))
+	if _, err := a.db.ExecContext(ctx, sqlAlter); err != nil {
+		return fmt.Errorf("failed to add columns: %w", err)
 	}
 	return nil
 }
@@ -681,6 +964,15 @@ func (a *Adapter) RenameTable(ctx context.Context, oldName, newName string) erro
 	return nil
 }
 
+// UpdateStatistics implements adapters.StatisticsUpdater: UPDATE STATISTICS
+// refreshes all statistics of the table with the default sample.
+func (a *Adapter) UpdateStatistics(ctx context.Context, tableName string) error {
+	if _, err := a.db.ExecContext(ctx, "UPDATE STATISTICS "+a.quoteTable(tableName)); err != nil {
+		return fmt.Errorf("failed to update statistics: %w", err)
+	}
+	return nil
+}
+
 // ========== base.DataInserter interface methods ==========
 
 // InsertRows implements base.DataInserter interface
@@ -114,7 +114,7 @@ func (a *Adapter) InspectTable(ctx context.Context, tableName string) (*adapters
 		return nil, fmt.Errorf("iterate columns: %w", err)
 	}
 	if len(report.Columns) == 0 {
-		return nil, fmt.Errorf("table [%s].[%s] not found or has no columns", schemaName, tableName)
+		return nil, fmt.Errorf("table [%s].[%s] %w", schemaName, tableName, adapters.ErrTableNotFound)
 	}
 
 	// ---- Foreign keys via sys.foreign_keys ----
@@ -0,0 +1,177 @@
+package mssql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// objectName — имя объекта SQL Server из 1–4 частей:
+//
+//	[server].[database].[schema].[object]
+//
+// Server — linked server, Database — другая БД того же сервера.
+// Пустые Server/Database — текущая БД подключения.
+type objectName struct {
+	Server   string
+	Database string
+	Schema   string
+	Name     string
+}
+
+// splitMultipartName разбирает имя на части по точкам вне [скобок].
+// Скобки снимаются, "]]" внутри скобок → "]". Пустая часть ("db..table")
+// сохраняется пустой строкой.
+func splitMultipartName(name string) []string {
+	var parts []string
+	var cur strings.Builder
+	inBracket := false
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		switch {
+		case inBracket && c == ']':
+			if i+1 < len(name) && name[i+1] == ']' {
+				cur.WriteByte(']')
+				i++
+			} else {
+				inBracket = false
+			}
+		case inBracket:
+			cur.WriteByte(c)
+		case c == '[':
+			inBracket = true
+		case c == '.':
+			parts = append(parts, strings.TrimSpace(cur.String()))
+			cur.Reset()
+		default:
+			cur.WriteByte(c)
+		}
+	}
+	return append(parts, strings.TrimSpace(cur.String()))
+}
+
+// parseObjectName разбирает имя объекта из 1–4 частей.
+// Схема по умолчанию — Config.Schema или dbo.
+// Примеры:
+//
+//	"Users"                   → dbo.Users
+//	"sales.Orders"            → sales.Orders
+//	"Archive.dbo.Orders"      → БД Archive
+//	"Archive..Orders"         → БД Archive, схема по умолчанию
+//	"[SRV01].ERP.dbo.Orders"  → linked server SRV01
+func (a *Adapter) parseObjectName(fullName string) (objectName, error) {
+	parts := splitMultipartName(fullName)
+	if len(parts) > 4 {
+		return objectName{}, fmt.Errorf("invalid object name %q: more than 4 parts", fullName)
+	}
+	if parts[len(parts)-1] == "" {
+		return objectName{}, fmt.Errorf("invalid object name %q: empty object name", fullName)
+	}
+
+	// Части выравниваются справа: object, schema, database, server
+	padded := make([]string, 4)
+	copy(padded[4-len(parts):], parts)
+	obj := objectName{Server: padded[0], Database: padded[1], Schema: padded[2], Name: padded[3]}
+
+	if obj.Server != "" && obj.Database == "" {
+		return objectName{}, fmt.Errorf("invalid object name %q: linked server name requires a database", fullName)
+	}
+	if obj.Schema == "" {
+		obj.Schema = a.defaultSchema()
+	}
+	return obj, nil
+}
+
+// defaultSchema возвращает схему для имён без схемы.
+func (a *Adapter) defaultSchema() string {
+	if a.config.Schema != "" {
+		return a.config.Schema
+	}
+	return "dbo"
+}
+
+// catalog возвращает префикс для каталожных представлений БД объекта:
+// "" для текущей БД, "[db]." или "[server].[db]." для остальных.
+func (o objectName) catalog() string {
+	switch {
+	case o.Server != "":
+		return quoteMSSQLIdent(o.Server) + "." + quoteMSSQLIdent(o.Database) + "."
+	case o.Database != "":
+		return quoteMSSQLIdent(o.Database) + "."
+	default:
+		return ""
+	}
+}
+
+// quoted возвращает полностью квотированное имя для FROM.
+func (o objectName) quoted() string {
+	return o.catalog() + quoteMSSQLIdent(o.Schema) + "." + quoteMSSQLIdent(o.Name)
+}
+
+// String возвращает имя для сообщений: [db.]schema.object
+func (o objectName) String() string {
+	name := o.Schema + "." + o.Name
+	if o.Database != "" {
+		name = o.Database + "." + name
+	}
+	if o.Server != "" {
+		name = o.Server + "." + name
+	}
+	return name
+}
+
+// resolveObject разбирает имя и раскрывает синоним в объект, на который он
+// ссылается (в т.ч. в другой БД или на linked server). Синоним не может
+// ссылаться на синоним, поэтому достаточно одного шага.
+// Используется для метаданных: INFORMATION_SCHEMA синонимов не видит,
+// а SELECT из синонима SQL Server выполняет сам.
+func (a *Adapter) resolveObject(ctx context.Context, fullName string) (objectName, error) {
+	obj, err := a.parseObjectName(fullName)
+	if err != nil {
+		return objectName{}, err
+	}
+
+	// sys.synonyms БД объекта; только каталожные представления 2005+,
+	// без функций текущей БД (OBJECT_ID не принимает 4-part имена)
+	query := fmt.Sprintf(`
+		SELECT sn.base_object_name
+		FROM %[1]ssys.synonyms sn
+		INNER JOIN %[1]ssys.schemas s ON sn.schema_id = s.schema_id
+		WHERE s.name = ? AND sn.name = ?
+	`, obj.catalog())
+
+	var base string
+	err = a.db.QueryRowContext(ctx, query, obj.Schema, obj.Name).Scan(&base)
+	if errors.Is(err, sql.ErrNoRows) {
+		return obj, nil
+	}
+	if err != nil {
+		return objectName{}, fmt.Errorf("failed to resolve synonym %s: %w", obj, err)
+	}
+
+	return resolveSynonymTarget(obj, base)
+}
+
+// resolveSynonymTarget строит имя объекта по base_object_name синонима.
+// Недостающие части берутся от синонима: "[T]" — схема синонима,
+// "[dbo].[T]" — БД синонима.
+func resolveSynonymTarget(synonym objectName, base string) (objectName, error) {
+	parts := splitMultipartName(base)
+	if len(parts) > 4 || parts[len(parts)-1] == "" {
+		return objectName{}, fmt.Errorf("synonym %s has invalid base object %q", synonym, base)
+	}
+
+	padded := make([]string, 4)
+	copy(padded[4-len(parts):], parts)
+	target := objectName{Server: padded[0], Database: padded[1], Schema: padded[2], Name: padded[3]}
+
+	if target.Schema == "" {
+		target.Schema = synonym.Schema
+	}
+	if len(parts) <= 2 {
+		target.Server, target.Database = synonym.Server, synonym.Database
+	}
+	return target, nil
+}
@@ -0,0 +1,122 @@
+package mssql
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+func TestSplitMultipartName(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"Users", []string{"Users"}},
+		{"dbo.Users", []string{"dbo", "Users"}},
+		{"[ZTR$Employee]", []string{"ZTR$Employee"}},
+		{"Archive..Orders", []string{"Archive", "", "Orders"}},
+		{"[SRV01].[ERP].[dbo].[Item.Ledger]", []string{"SRV01", "ERP", "dbo", "Item.Ledger"}},
+		{"[a]]b].[c d]", []string{"a]b", "c d"}},
+	}
+	for _, tt := range tests {
+		if got := splitMultipartName(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitMultipartName(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseObjectName(t *testing.T) {
+	a := &Adapter{config: adapters.Config{Schema: "sales"}}
+
+	tests := []struct {
+		in      string
+		want    objectName
+		catalog string
+	}{
+		{"Orders", objectName{Schema: "sales", Name: "Orders"}, ""},
+		{"dbo.Orders", objectName{Schema: "dbo", Name: "Orders"}, ""},
+		{"Archive.dbo.Orders", objectName{Database: "Archive", Schema: "dbo", Name: "Orders"}, "[Archive]."},
+		{"Archive..Orders", objectName{Database: "Archive", Schema: "sales", Name: "Orders"}, "[Archive]."},
+		{"SRV01.ERP.dbo.Orders", objectName{Server: "SRV01", Database: "ERP", Schema: "dbo", Name: "Orders"}, "[SRV01].[ERP]."},
+	}
+	for _, tt := range tests {
+		got, err := a.parseObjectName(tt.in)
+		if err != nil {
+			t.Fatalf("parseObjectName(%q): %v", tt.in, err)
+		}
+		if got != tt.want {
+			t.Errorf("parseObjectName(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+		if got.catalog() != tt.catalog {
+			t.Errorf("catalog(%q) = %q, want %q", tt.in, got.catalog(), tt.catalog)
+		}
+	}
+
+	for _, bad := range []string{"a.b.c.d.e", "dbo.", "SRV01..dbo.Orders"} {
+		if _, err := a.parseObjectName(bad); err == nil {
+			t.Errorf("parseObjectName(%q): expected error", bad)
+		}
+	}
+}
+
+func TestResolveSynonymTarget(t *testing.T) {
+	synonym := objectName{Database: "Reports", Schema: "rpt", Name: "Orders"}
+
+	tests := []struct {
+		base string
+		want objectName
+	}{
+		{"[Orders_2024]", objectName{Database: "Reports", Schema: "rpt", Name: "Orders_2024"}},
+		{"[dbo].[Orders]", objectName{Database: "Reports", Schema: "dbo", Name: "Orders"}},
+		{"[ERP].[dbo].[Orders]", objectName{Database: "ERP", Schema: "dbo", Name: "Orders"}},
+		{"[SRV01].[ERP].[dbo].[Orders]", objectName{Server: "SRV01", Database: "ERP", Schema: "dbo", Name: "Orders"}},
+	}
+	for _, tt := range tests {
+		got, err := resolveSynonymTarget(synonym, tt.base)
+		if err != nil {
+			t.Fatalf("resolveSynonymTarget(%q): %v", tt.base, err)
+		}
+		if got != tt.want {
+			t.Errorf("resolveSynonymTarget(%q) = %+v, want %+v", tt.base, got, tt.want)
+		}
+	}
+
+	if got := (objectName{Server: "SRV01", Database: "ERP", Schema: "dbo", Name: "Item"}).quoted(); got != "[SRV01].[ERP].[dbo].[Item]" {
+		t.Errorf("quoted = %s", got)
+	}
+}
+
+func TestGenerateDDLDescriptions(t *testing.T) {
+	schema := packet.Schema{
+		Description: "Заказы",
+		Fields: []packet.Field{
+			{Name: "id", Type: "INTEGER", Key: true},
+			{Name: "note", Type: "TEXT", Description: "Customer's note"},
+		},
+	}
+	a := &Adapter{config: adapters.Config{Schema: "sales"}}
+	ddl := a.GenerateDDL("orders", schema)
+	want := []string{
+		"EXEC sys.sp_addextendedproperty @name = N'MS_Description', @value = N'Заказы', " +
+			"@level0type = N'SCHEMA', @level0name = N'sales', @level1type = N'TABLE', @level1name = N'orders'",
+		"EXEC sys.sp_addextendedproperty @name = N'MS_Description', @value = N'Customer''s note', " +
+			"@level0type = N'SCHEMA', @level0name = N'sales', @level1type = N'TABLE', @level1name = N'orders', " +
+			"@level2type = N'COLUMN', @level2name = N'note'",
+	}
+	if !reflect.DeepEqual(ddl[1:], want) {
+		t.Errorf("descriptions:\n got %q\nwant %q", ddl[1:], want)
+	}
+}
+
+// Имя цели bulk copy экранируется, как в остальном SQL адаптера.
+func TestQuoteTable(t *testing.T) {
+	a := &Adapter{}
+	if got := a.quoteTable("o]rders"); got != "[dbo].[o]]rders]" {
+		t.Errorf("quoteTable = %s", got)
+	}
+	if got := a.quoteTable("sa]les.x"); got != "[sa]]les].[x]" {
+		t.Errorf("quoteTable = %s", got)
+	}
+}
@@ -0,0 +1,92 @@
+package mssql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+// Compile-time check: симуляция импорта в scratch-схеме
+var _ adapters.ScratchCreator = (*Adapter)(nil)
+
+// CreateScratch реализует adapters.ScratchCreator. Снимок БД (database
+// snapshot) доступен только на чтение и импорт в него невозможен, поэтому
+// копия — схема name в той же БД: SELECT ... INTO переносит колонки и
+// строки (с IDENTITY), первичный ключ восстанавливается по схеме таблицы.
+// Индексы, умолчания и ограничения, кроме ключа, не копируются.
+func (a *Adapter) CreateScratch(ctx context.Context, name string, tables []string) (*adapters.Scratch, error) {
+	for _, table := range tables {
+		if len(splitMultipartName(table)) != 1 {
+			return nil, fmt.Errorf("schema-qualified table %s cannot be simulated: set the schema in the connection config", table)
+		}
+	}
+	// CREATE SCHEMA должен быть единственным оператором пакета
+	if _, err := a.db.ExecContext(ctx, "EXEC('CREATE SCHEMA "+strings.ReplaceAll(quoteMSSQLIdent(name), "'", "''")+"')"); err != nil {
+		return nil, fmt.Errorf("failed to create schema %s: %w", name, err)
+	}
+	scratch := &adapters.Scratch{Location: "schema " + name, Schema: name}
+	for _, table := range tables {
+		if err := a.copyScratchTable(ctx, name, table); err != nil {
+			_ = a.DropScratch(ctx, scratch)
+			return nil, fmt.Errorf("failed to copy table %s: %w", table, err)
+		}
+	}
+	return scratch, nil
+}
+
+// copyScratchTable копирует таблицу в схему scratch (если она есть в цели).
+func (a *Adapter) copyScratchTable(ctx context.Context, scratch, table string) error {
+	exists, err := a.TableExists(ctx, table)
+	if err != nil || !exists {
+		return err
+	}
+	schema, err := a.GetTableSchema(ctx, table)
+	if err != nil {
+		return err
+	}
+	name := splitMultipartName(table)[0]
+	src := quoteMSSQLIdent(a.defaultSchema()) + "." + quoteMSSQLIdent(name)
+	dst := quoteMSSQLIdent(scratch) + "." + quoteMSSQLIdent(name)
+	if _, err := a.db.ExecContext(ctx, fmt.Sprintf("SELECT * INTO %s FROM %s", dst, src)); err != nil {
+		return err
+	}
+	keys := packet.ExtractKeyFields(schema)
+	if len(keys) == 0 {
+		return nil
+	}
+	quoted := make([]string, len(keys))
+	for i, k := range keys {
+		quoted[i] = quoteMSSQLIdent(k)
+	}
+	_, err = a.db.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s ADD PRIMARY KEY (%s)", dst, strings.Join(quoted, ", ")))
+	return err
+}
+
+// DropScratch реализует adapters.ScratchCreator: удаляет таблицы схемы
+// (в том числе созданные импортом) и саму схему.
+func (a *Adapter) DropScratch(ctx context.Context, scratch *adapters.Scratch) error {
+	rows, err := a.db.QueryContext(ctx, "SELECT name FROM sys.tables WHERE schema_id = SCHEMA_ID(@p1)", scratch.Schema)
+	if err != nil {
+		return err
+	}
+	var tables []string
+	for rows.Next() {
+		var t string
+		if err := rows.Scan(&t); err != nil {
+			_ = rows.Close()
+			return err
+		}
+		tables = append(tables, t)
+	}
+	_ = rows.Close()
+	for _, t := range tables {
+		if _, err := a.db.ExecContext(ctx, "DROP TABLE "+quoteMSSQLIdent(scratch.Schema)+"."+quoteMSSQLIdent(t)); err != nil {
+			return err
+		}
+	}
+	_, err = a.db.ExecContext(ctx, "DROP SCHEMA "+quoteMSSQLIdent(scratch.Schema))
+	return err
+}
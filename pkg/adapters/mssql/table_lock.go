@@ -0,0 +1,39 @@
+package mssql
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters/base"
+)
+
+// TryLockTable реализует base.TableLocker: sp_getapplock с владельцем
+// Session и нулевым ожиданием. Блокировка принадлежит сессии, поэтому
+// соединение изымается из пула до освобождения.
+func (a *Adapter) TryLockTable(ctx context.Context, table string) (func() error, bool, error) {
+	conn, err := a.db.Conn(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	name := base.TableLockName(table)
+	var status int
+	err = conn.QueryRowContext(ctx, `
+		DECLARE @status int;
+		EXEC @status = sp_getapplock @Resource = @name, @LockMode = 'Exclusive',
+			@LockOwner = 'Session', @LockTimeout = 0;
+		SELECT @status;`, sql.Named("name", name)).Scan(&status)
+	if err != nil {
+		_ = conn.Close()
+		return nil, false, err
+	}
+	if status < 0 { // -1 — таймаут: блокировку держит другая сессия
+		_ = conn.Close()
+		return nil, false, nil
+	}
+	return func() error {
+		defer conn.Close()
+		_, err := conn.ExecContext(context.Background(),
+			"EXEC sp_releaseapplock @Resource = @name, @LockOwner = 'Session'", sql.Named("name", name))
+		return err
+	}, true, nil
+}
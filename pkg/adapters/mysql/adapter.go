@@ -12,6 +12,7 @@ import (
 	"github.com/ruslano69/tdtp-framework/pkg/adapters"
 	"github.com/ruslano69/tdtp-framework/pkg/adapters/base"
 	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+	"github.com/ruslano69/tdtp-framework/pkg/core/tdtql"
 )
 
 // AdapterType идентификатор MySQL адаптера
@@ -27,17 +28,26 @@ type Adapter struct {
 	exportHelper *base.ExportHelper
 	importHelper *base.ImportHelper
 	converter    *base.UniversalTypeConverter
+
+	queryLog *adapters.QueryLogger // журнал SQL-выражений (Config.QueryLog)
 }
 
 func init() {
 	adapters.Register(AdapterType, func() adapters.Adapter {
 		return &Adapter{}
 	})
+	adapters.Describe(adapters.DriverInfo{Type: AdapterType, Driver: "github.com/go-sql-driver/mysql"})
 }
 
 // Connect подключается к MySQL и инициализирует base helpers
 func (a *Adapter) Connect(ctx context.Context, cfg adapters.Config) error {
-	db, err := sql.Open("mysql", cfg.DSN)
+	governor, err := adapters.NewGovernor(cfg.ImportLimits)
+	if err != nil {
+		return fmt.Errorf("invalid import limits: %w", err)
+	}
+
+	a.queryLog = adapters.NewQueryLogger(cfg.QueryLog)
+	db, err := base.OpenDB("mysql", cfg.DSN, a.queryLog)
 	if err != nil {
 		return fmt.Errorf("failed to open database: %w", err)
 	}
@@ -52,10 +62,59 @@ func (a *Adapter) Connect(ctx context.Context, cfg adapters.Config) error {
 
 	// Инициализируем base helpers - вся магия здесь!
 	a.initHelpers()
+	if cfg.SourceTimezone != "" {
+		if err := a.converter.SetSourceTimezone(cfg.SourceTimezone); err != nil {
+			_ = db.Close()
+			return err
+		}
+	}
+	if err := a.converter.SetBoolMappings(cfg.Booleans, cfg.ColumnBooleans); err != nil {
+		_ = db.Close()
+		return err
+	}
+	if err := a.exportHelper.SetPacketSizing(cfg.Packets); err != nil {
+		_ = db.Close()
+		return err
+	}
+	a.importHelper.SetGovernor(governor)
+	a.importHelper.SetStreamBatchPackets(cfg.StreamBatchPackets)
+	if err := cfg.TableLock.Validate(); err != nil {
+		_ = db.Close()
+		return err
+	}
+	a.importHelper.SetTableLocking(cfg.TableLock)
+	a.importHelper.SetMaintenanceWindows(cfg.Maintenance)
+	if err := cfg.Duplicates.Validate(); err != nil {
+		_ = db.Close()
+		return err
+	}
+	a.importHelper.SetDuplicateMode(cfg.Duplicates)
+	if err := cfg.Columns.Validate(); err != nil {
+		_ = db.Close()
+		return err
+	}
+	a.importHelper.SetColumnMatching(cfg.Columns)
+	a.importHelper.SetColumnCipher(cfg.ColumnCipher)
+	a.exportHelper.SetColumnCipher(cfg.ColumnCipher)
+	a.exportHelper.SetExportPolicy(cfg.ExportPolicy)
+	if err := cfg.RowErrors.Validate(); err != nil {
+		_ = db.Close()
+		return err
+	}
+	a.importHelper.SetRowErrorPolicy(cfg.RowErrors, a.converter, AdapterType)
 
 	return nil
 }
 
+// TimezoneReport реализует adapters.TimezoneReporter: отчёт о переводе
+// naive datetime из Config.SourceTimezone в UTC (nil — пояс не задан).
+func (a *Adapter) TimezoneReport() *adapters.TimezoneReport {
+	if a.converter == nil {
+		return nil
+	}
+	return a.converter.TimezoneReport()
+}
+
 // initHelpers - единственное место где мы настраиваем поведение
 func (a *Adapter) initHelpers() {
 	a.converter = base.NewUniversalTypeConverter()
@@ -70,6 +129,8 @@ func (a *Adapter) initHelpers() {
 		a.converter, // ValueConverter
 		nil,         // SQLAdapter не нужен для MySQL (простые типы)
 	)
+	a.exportHelper.SetJoinPushdown(true)
+	a.exportHelper.SetRegexPushdown(tdtql.RegexMySQL)
 
 	// ImportHelper делает всю работу импорта с temporary tables
 	a.importHelper = base.NewImportHelper(
@@ -80,6 +141,11 @@ func (a *Adapter) initHelpers() {
 	)
 }
 
+// QueryLog реализует adapters.QueryLogged: журнал выражений адаптера.
+func (a *Adapter) QueryLog() *adapters.QueryLogger {
+	return a.queryLog
+}
+
 // Close закрывает соединение
 func (a *Adapter) Close(ctx context.Context) error {
 	if a.db != nil {
@@ -98,6 +164,12 @@ func (a *Adapter) GetDatabaseType() string {
 	return AdapterType
 }
 
+// IdentifierLimit — имена таблиц и колонок MySQL до 64 символов
+// (adapters.IdentifierLimiter)
+func (a *Adapter) IdentifierLimit() adapters.IdentifierLimit {
+	return adapters.IdentifierLimit{MaxLength: 64}
+}
+
 // GetDatabaseVersion возвращает версию MySQL
 func (a *Adapter) GetDatabaseVersion(ctx context.Context) (string, error) {
 	var version string
@@ -173,6 +245,8 @@ type mysqlTx struct {
 	tx *sql.Tx
 }
 
+var _ adapters.SavepointTx = (*mysqlTx)(nil)
+
 func (t *mysqlTx) Commit(ctx context.Context) error {
 	return t.tx.Commit()
 }
@@ -181,52 +255,35 @@ func (t *mysqlTx) Rollback(ctx context.Context) error {
 	return t.tx.Rollback()
 }
 
-// ExecuteRawQuery выполняет произвольный SQL запрос
+// Savepoint создаёт точку сохранения (adapters.SavepointTx)
+func (t *mysqlTx) Savepoint(ctx context.Context, name string) error {
+	return base.StandardSavepoints.Savepoint(ctx, t.tx, name)
+}
+
+// RollbackTo откатывает транзакцию к точке сохранения
+func (t *mysqlTx) RollbackTo(ctx context.Context, name string) error {
+	return base.StandardSavepoints.RollbackTo(ctx, t.tx, name)
+}
+
+// ReleaseSavepoint освобождает точку сохранения
+func (t *mysqlTx) ReleaseSavepoint(ctx context.Context, name string) error {
+	return base.StandardSavepoints.ReleaseSavepoint(ctx, t.tx, name)
+}
+
+// ExecuteRawQuery выполняет произвольный SQL запрос.
+// Схема результата — по метаданным драйвера (GetQuerySchema), строки
+// конвертируются тем же путём, что и при экспорте таблицы.
 func (a *Adapter) ExecuteRawQuery(ctx context.Context, query string) (*packet.DataPacket, error) {
-	// Простая реализация через ReadRowsWithSQL
-	rows, err := a.db.QueryContext(ctx, query)
+	schema, err := a.GetQuerySchema(ctx, query)
 	if err != nil {
 		return nil, err
 	}
-	defer func() { _ = rows.Close() }()
 
-	// Получаем колонки
-	columns, err := rows.Columns()
+	dataRows, err := a.ReadRowsWithSQL(ctx, query, schema)
 	if err != nil {
 		return nil, err
 	}
 
-	// Создаем простую схему
-	schema := packet.Schema{
-		Fields: make([]packet.Field, len(columns)),
-	}
-	for i, col := range columns {
-		schema.Fields[i] = packet.Field{
-			Name: col,
-			Type: "text", // Упрощенно
-		}
-	}
-
-	// Читаем данные
-	var dataRows [][]string
-	for rows.Next() {
-		values := make([]any, len(columns))
-		valuePtrs := make([]any, len(columns))
-		for i := range values {
-			valuePtrs[i] = &values[i]
-		}
-
-		if err := rows.Scan(valuePtrs...); err != nil {
-			return nil, err
-		}
-
-		row := make([]string, len(columns))
-		for i, val := range values {
-			row[i] = a.converter.DBValueToString(val, schema.Fields[i], "mysql")
-		}
-		dataRows = append(dataRows, row)
-	}
-
 	// Генерируем пакет
 	generator := packet.NewGenerator()
 	packets, err := generator.GenerateReference("result", schema, dataRows)
@@ -3,6 +3,7 @@ package mysql
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"strings"
 
@@ -14,16 +15,58 @@ import (
 
 // ========== Публичные методы (делегируют в ExportHelper) ==========
 
+// SetMaxMessageSize задаёт максимальный размер одного TDTP пакета (в байтах).
+// Используется CLI для передачи --packet-size.
+func (a *Adapter) SetMaxMessageSize(size int) {
+	a.exportHelper.SetMaxMessageSize(size)
+}
+
 // SetSkipSpecialValues включает режим --fast: DetectAndApply пропускается.
 func (a *Adapter) SetSkipSpecialValues(skip bool) {
 	a.exportHelper.SetSkipSpecialValues(skip)
 }
 
+// SetCompression включает zstd-сжатие Data экспортируемых пакетов
+// (см. base.ExportHelper.SetCompression).
+func (a *Adapter) SetCompression(opts packet.CompressionOptions) {
+	a.exportHelper.SetCompression(opts)
+}
+
+// SetPacketKeys включает шифрование пакетов TDTP v1.5 при экспорте и их
+// расшифровку при импорте (см. base.ExportHelper.SetPacketKeys).
+func (a *Adapter) SetPacketKeys(keys base.PacketKeyProvider) {
+	a.exportHelper.SetPacketKeys(keys)
+	a.importHelper.SetPacketKeys(keys)
+}
+
+// SetKeyMapper задаёт выдачу суррогатных ключей при импорте (keymap.Mapper).
+func (a *Adapter) SetKeyMapper(m base.KeyMapper) {
+	a.importHelper.SetKeyMapper(m)
+}
+
 // SetMaxFallbackRows задаёт лимит строк для in-memory fallback при провале SQL pushdown.
 func (a *Adapter) SetMaxFallbackRows(n int64) {
 	a.exportHelper.SetMaxFallbackRows(n)
 }
 
+// SetTableQueries задаёт собственные SELECT для таблиц вместо SELECT *
+// (см. base.ExportHelper.SetTableQueries).
+func (a *Adapter) SetTableQueries(queries map[string]string) error {
+	return a.exportHelper.SetTableQueries(queries)
+}
+
+// ExportQuery экспортирует результат произвольного read-only SQL
+// (adapters.QueryExporter, см. base.ExportHelper.ExportQuery).
+func (a *Adapter) ExportQuery(ctx context.Context, sql string) ([]*packet.DataPacket, error) {
+	return a.exportHelper.ExportQuery(ctx, sql)
+}
+
+// ExplainQuery возвращает план ExportTableWithQuery без чтения строк
+// (adapters.QueryExplainer, см. base.ExportHelper.Explain).
+func (a *Adapter) ExplainQuery(ctx context.Context, tableName string, query *packet.Query) (*packet.ExecutionPlan, error) {
+	return a.exportHelper.Explain(ctx, tableName, query)
+}
+
 // ExportTable экспортирует всю таблицу - просто делегируем
 func (a *Adapter) ExportTable(ctx context.Context, tableName string) ([]*packet.DataPacket, error) {
 	return a.exportHelper.ExportTable(ctx, tableName)
@@ -52,7 +95,10 @@ func (a *Adapter) GetTableSchema(ctx context.Context, tableName string) (packet.
 			numeric_precision,
 			numeric_scale,
 			is_nullable,
-			column_key
+			column_key,
+			extra,
+			COALESCE(generation_expression, ''),
+			column_comment
 		FROM information_schema.columns
 		WHERE table_schema = DATABASE() AND table_name = ?
 		ORDER BY ordinal_position
@@ -74,9 +120,12 @@ func (a *Adapter) GetTableSchema(ctx context.Context, tableName string) (packet.
 			numScale   sql.NullInt64
 			isNullable string
 			columnKey  string
+			extra      string
+			genExpr    string
+			comment    string
 		)
 
-		if err := rows.Scan(&columnName, &dataType, &charLength, &numPrec, &numScale, &isNullable, &columnKey); err != nil {
+		if err := rows.Scan(&columnName, &dataType, &charLength, &numPrec, &numScale, &isNullable, &columnKey, &extra, &genExpr, &comment); err != nil {
 			return packet.Schema{}, err
 		}
 
@@ -87,20 +136,89 @@ func (a *Adapter) GetTableSchema(ctx context.Context, tableName string) (packet.
 			return packet.Schema{}, err
 		}
 
+		// Вычисляемая колонка (VIRTUAL/STORED): значение экспортируется,
+		// при импорте не вставляется, в DDL воссоздаётся по выражению
+		if gen := generatedColumn(extra, genExpr); gen != nil {
+			field.Generated = gen
+			field.ReadOnly = true
+		}
+		field.Description = comment
+
 		fields = append(fields, field)
 	}
+	if err := rows.Err(); err != nil {
+		return packet.Schema{}, err
+	}
 
 	if len(fields) == 0 {
-		return packet.Schema{}, fmt.Errorf("table %s not found or has no columns", tableName)
+		return packet.Schema{}, fmt.Errorf("table %s %w", tableName, adapters.ErrTableNotFound)
+	}
+
+	// Комментарий таблицы; у представлений table_comment = 'VIEW'
+	var tableComment string
+	err = a.db.QueryRowContext(ctx, `
+		SELECT CASE WHEN table_type = 'VIEW' THEN '' ELSE table_comment END
+		FROM information_schema.tables
+		WHERE table_schema = DATABASE() AND table_name = ?
+	`, tableName).Scan(&tableComment)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return packet.Schema{}, fmt.Errorf("failed to query table comment: %w", err)
 	}
 
-	return packet.Schema{Fields: fields}, rows.Err()
+	return packet.Schema{Fields: fields, Description: tableComment}, nil
+}
+
+// GetQuerySchema возвращает схему результата SELECT по метаданным драйвера
+// (строки не читаются: запрос обёрнут в WHERE 1=0). Типы, которых нет в
+// маппинге, экспортируются как TEXT.
+// Реализует base.QuerySchemaReader для собственных запросов таблиц.
+func (a *Adapter) GetQuerySchema(ctx context.Context, query string) (packet.Schema, error) {
+	rows, err := a.db.QueryContext(ctx, base.MetadataQuery(query))
+	if err != nil {
+		return packet.Schema{}, fmt.Errorf("failed to describe query: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return packet.Schema{}, fmt.Errorf("failed to get column types: %w", err)
+	}
+	schema := packet.Schema{Fields: make([]packet.Field, len(columnTypes))}
+	for i, ct := range columnTypes {
+		// Драйвер отдаёт "UNSIGNED INT", "DECIMAL" без параметров и т.п.
+		dataType := strings.TrimPrefix(ct.DatabaseTypeName(), "UNSIGNED ")
+		if p, sc, ok := ct.DecimalSize(); ok && p > 0 {
+			dataType = fmt.Sprintf("%s(%d,%d)", dataType, p, sc)
+		}
+		field, err := BuildFieldFromColumn(ct.Name(), dataType, false)
+		if err != nil {
+			field = packet.Field{Name: ct.Name(), Type: "TEXT"}
+		}
+		schema.Fields[i] = field
+	}
+	return schema, nil
 }
 
 // ========== base.DataReader interface ==========
 
 // ReadAllRows читает все строки из таблицы
 func (a *Adapter) ReadAllRows(ctx context.Context, tableName string, pkgSchema packet.Schema) ([][]string, error) {
+	return a.ReadRowsWithSQL(ctx, selectAllSQL(tableName, pkgSchema), pkgSchema)
+}
+
+// StreamAllRows читает таблицу построчно (base.RowStreamer)
+func (a *Adapter) StreamAllRows(ctx context.Context, tableName string, pkgSchema packet.Schema, fn func(row []string) error) error {
+	rows, err := a.db.QueryContext(ctx, selectAllSQL(tableName, pkgSchema))
+	if err != nil {
+		return fmt.Errorf("failed to execute SQL: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	return base.StreamSQLRows(rows, pkgSchema, a.converter, "mysql", fn)
+}
+
+// selectAllSQL формирует SELECT всех полей схемы из таблицы
+func selectAllSQL(tableName string, pkgSchema packet.Schema) string {
 	tableName = tdtql.StripBrackets(tableName)
 	// Формируем список колонок с backtick quoting
 	columns := make([]string, 0, len(pkgSchema.Fields))
@@ -108,8 +226,7 @@ func (a *Adapter) ReadAllRows(ctx context.Context, tableName string, pkgSchema p
 		columns = append(columns, fmt.Sprintf("`%s`", field.Name))
 	}
 
-	query := fmt.Sprintf("SELECT %s FROM `%s`", strings.Join(columns, ", "), tableName)
-	return a.ReadRowsWithSQL(ctx, query, pkgSchema)
+	return fmt.Sprintf("SELECT %s FROM `%s`", strings.Join(columns, ", "), tableName)
 }
 
 // ReadRowsWithSQL выполняет SQL и возвращает строки
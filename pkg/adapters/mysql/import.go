@@ -2,6 +2,7 @@ package mysql
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"strings"
 
@@ -22,17 +23,71 @@ func (a *Adapter) ImportPackets(ctx context.Context, packets []*packet.DataPacke
 	return a.importHelper.ImportPackets(ctx, packets, strategy)
 }
 
+// ImportPacketStream импортирует пакеты из канала пачками транзакций
+// (adapters.PacketStreamImporter) - просто делегируем
+func (a *Adapter) ImportPacketStream(ctx context.Context, packets <-chan *packet.DataPacket, strategy adapters.ImportStrategy) error {
+	return a.importHelper.ImportPacketStream(ctx, packets, strategy)
+}
+
+// DryRunImport реализует adapters.DryRunImporter: проверка пакетов против
+// БД без записи (base.DryRun).
+func (a *Adapter) DryRunImport(ctx context.Context, packets []*packet.DataPacket, strategy adapters.ImportStrategy) (*adapters.ImportReport, error) {
+	return a.importHelper.DryRun(a, a.converter, AdapterType).Import(ctx, packets, strategy)
+}
+
+// ImportPacketsReport реализует adapters.ReportingImporter: ImportPackets
+// с отчётом об отклонённых строках (Config.RowErrors).
+func (a *Adapter) ImportPacketsReport(ctx context.Context, packets []*packet.DataPacket, strategy adapters.ImportStrategy) (*adapters.ImportReport, error) {
+	return a.importHelper.ImportPacketsReport(ctx, packets, strategy)
+}
+
+// ApplyDelta применяет delta-пакеты точечными UPDATE в одной транзакции.
+// Реализует base.DeltaApplier
+func (a *Adapter) ApplyDelta(ctx context.Context, packets []*packet.DataPacket, strategy adapters.ImportStrategy) error {
+	dialect := a.deltaDialect()
+	return base.ApplyDeltaPacketsSQL(ctx, a.db, packets, strategy, dialect.Quote, dialect)
+}
+
+// ApplyDelete удаляет строки по ключам пакетов удаления в одной транзакции.
+// Реализует base.DeleteApplier
+func (a *Adapter) ApplyDelete(ctx context.Context, packets []*packet.DataPacket) (int, error) {
+	dialect := a.deltaDialect()
+	return base.ApplyDeletePacketsSQL(ctx, a.db, packets, dialect.Quote, dialect)
+}
+
+// deltaDialect — SQL-диалект MySQL для delta- и delete-пакетов.
+func (a *Adapter) deltaDialect() base.DeltaDialect {
+	return base.DeltaDialect{
+		Quote:           func(name string) string { return "`" + strings.ReplaceAll(name, "`", "``") + "`" },
+		Placeholder:     func(int) string { return "?" },
+		Convert:         base.SQLValueConverter(a.converter, "mysql"),
+		ChangedRowsOnly: true, // RowsAffected в MySQL — только реально изменённые строки
+	}
+}
+
 // ========== base.TableManager interface ==========
 
 // CreateTable создает таблицу из TDTP схемы
 func (a *Adapter) CreateTable(ctx context.Context, tableName string, schema packet.Schema) error {
+	_, err := a.db.ExecContext(ctx, a.GenerateDDL(tableName, schema)[0])
+	if err != nil {
+		return fmt.Errorf("failed to create table: %w", err)
+	}
+
+	return nil
+}
+
+// GenerateDDL возвращает CREATE TABLE для TDTP схемы (adapters.DDLGenerator).
+// Описания из источника и исходные имена полей сохраняются в COMMENT
+// таблицы и колонок.
+func (a *Adapter) GenerateDDL(tableName string, schema packet.Schema) []string {
 	columns := make([]string, 0, len(schema.Fields))
 	var pkColumns []string
 
 	for _, field := range schema.Fields {
 		// Конвертируем TDTP тип в MySQL тип через types.go
 		mysqlType := TDTPToMySQL(field)
-		column := fmt.Sprintf("`%s` %s", field.Name, mysqlType)
+		column := fmt.Sprintf("`%s` %s%s", field.Name, mysqlType, generatedClause(field))
 
 		// NOT NULL для primary key
 		if field.Key {
@@ -40,10 +95,9 @@ func (a *Adapter) CreateTable(ctx context.Context, tableName string, schema pack
 			pkColumns = append(pkColumns, fmt.Sprintf("`%s`", field.Name))
 		}
 
-		// Preserve original name as column COMMENT when field was sanitized
-		if field.OriginalName != "" {
-			escaped := strings.ReplaceAll(field.OriginalName, "'", "\\'")
-			column += fmt.Sprintf(" COMMENT 'original: %s'", escaped)
+		// Описание колонки и исходное имя санитизированного поля
+		if comment := adapters.ColumnComment(field); comment != "" {
+			column += " COMMENT " + commentLiteral(comment, maxColumnComment)
 		}
 
 		columns = append(columns, column)
@@ -56,13 +110,28 @@ func (a *Adapter) CreateTable(ctx context.Context, tableName string, schema pack
 
 	quotedTable := "`" + strings.ReplaceAll(tableName, "`", "``") + "`"
 	createSQL := fmt.Sprintf("CREATE TABLE %s (%s)", quotedTable, strings.Join(columns, ", "))
-
-	_, err := a.db.ExecContext(ctx, createSQL)
-	if err != nil {
-		return fmt.Errorf("failed to create table: %w", err)
+	if schema.Description != "" {
+		createSQL += " COMMENT = " + commentLiteral(schema.Description, maxTableComment)
 	}
 
-	return nil
+	return []string{createSQL}
+}
+
+// Ограничения длины комментариев MySQL (символов): длиннее — ошибка в
+// строгом режиме, поэтому текст укорачивается.
+const (
+	maxColumnComment = 1024
+	maxTableComment  = 2048
+)
+
+// commentLiteral экранирует строковый литерал COMMENT, укорачивая текст
+// до limit символов.
+func commentLiteral(comment string, limit int) string {
+	if runes := []rune(comment); len(runes) > limit {
+		comment = string(runes[:limit])
+	}
+	escaped := strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(comment)
+	return "'" + escaped + "'"
 }
 
 // DropTable удаляет таблицу
@@ -71,31 +140,108 @@ func (a *Adapter) DropTable(ctx context.Context, tableName string) error {
 	return err
 }
 
+// TruncateTable удаляет все строки таблицы в транзакции импорта
+// (base.ImportTx). DELETE, а не TRUNCATE: TRUNCATE в MySQL — DDL с неявным
+// COMMIT, очистку нельзя было бы откатить. Реализует base.TableTruncater
+func (a *Adapter) TruncateTable(ctx context.Context, tableName string) error {
+	_, err := a.importConn(ctx).ExecContext(ctx, fmt.Sprintf("DELETE FROM `%s`", strings.ReplaceAll(tableName, "`", "``")))
+	return err
+}
+
+// importConn — транзакция импорта из base.ImportTx или, без неё, a.db
+func (a *Adapter) importConn(ctx context.Context) base.SQLConn {
+	if t, ok := base.ImportTx(ctx).(*mysqlTx); ok {
+		return t.tx
+	}
+	return a.db
+}
+
 // RenameTable переименовывает таблицу
 func (a *Adapter) RenameTable(ctx context.Context, oldName, newName string) error {
 	_, err := a.db.ExecContext(ctx, fmt.Sprintf("RENAME TABLE `%s` TO `%s`", oldName, newName))
 	return err
 }
 
+// AddColumns добавляет колонки в существующую таблицу (adapters.ColumnAdder)
+func (a *Adapter) AddColumns(ctx context.Context, tableName string, fields []packet.Field) error {
+	clauses := make([]string, len(fields))
+	for i, field := range fields {
+		clauses[i] = fmt.Sprintf("ADD COLUMN `%s` %s%s", field.Name, TDTPToMySQL(field), generatedClause(field))
+	}
+	quotedTable := "`" + strings.ReplaceAll(tableName, "`", "``") + "`"
+	if _, err := a.db.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s %s", quotedTable, strings.Join(clauses, ", "))); err != nil {
+		return fmt.Errorf("failed to add columns: %w", err)
+	}
+	return nil
+}
+
+// UpdateStatistics пересчитывает статистику таблицы (ANALYZE TABLE).
+// Ошибки ANALYZE TABLE приходят строкой результата, а не ошибкой запроса.
+// Реализует adapters.StatisticsUpdater.
+func (a *Adapter) UpdateStatistics(ctx context.Context, tableName string) error {
+	quotedTable := "`" + strings.ReplaceAll(tableName, "`", "``") + "`"
+	rows, err := a.db.QueryContext(ctx, "ANALYZE TABLE "+quotedTable)
+	if err != nil {
+		return fmt.Errorf("failed to analyze table: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		var table, op, msgType, msgText string
+		if err := rows.Scan(&table, &op, &msgType, &msgText); err != nil {
+			return fmt.Errorf("failed to read ANALYZE TABLE result: %w", err)
+		}
+		if strings.EqualFold(msgType, "error") {
+			return fmt.Errorf("failed to analyze table: %s", msgText)
+		}
+	}
+	return rows.Err()
+}
+
 // ========== base.DataInserter interface ==========
 
+// bulkBatchBytes — примерный объём данных одного INSERT массовой загрузки:
+// с запасом меньше max_allowed_packet по умолчанию (4 МБ в MySQL 5.7).
+const bulkBatchBytes = 2 << 20
+
 // InsertRows вставляет строки с учетом strategy
 // Это ЕДИНСТВЕННОЕ место где MySQL-специфичная логика!
-func (a *Adapter) InsertRows(ctx context.Context, tableName string, schema packet.Schema, rows []packet.Row, strategy adapters.ImportStrategy) error {
+//
+// copy, append и truncate — массовая загрузка: multi-row INSERT пачками до
+// предела плейсхолдеров (65535) и bulkBatchBytes в одной транзакции (своей
+// или транзакции импорта base.ImportTx) — без фиксации каждой пачки.
+func (a *Adapter) InsertRows(ctx context.Context, tableName string, schema packet.Schema, rows []packet.Row, strategy adapters.ImportStrategy) (err error) {
 	if len(rows) == 0 {
 		return nil
 	}
 
+	// Вычисляемые колонки вставлять нельзя — их значения считает MySQL
+	schema, keep := insertableFields(schema)
+	if len(schema.Fields) == 0 {
+		return nil
+	}
+
 	// Строим префикс INSERT и (опционально) суффикс ON DUPLICATE KEY UPDATE
 	var insertPrefix, insertSuffix string
+	bulk := false
 	switch strategy {
-	case adapters.StrategyReplace:
+	case adapters.StrategyReplace, adapters.StrategyMerge:
+		updates, err := base.UpdateFields(ctx, schema, strategy)
+		if err != nil {
+			return err
+		}
 		insertPrefix = a.buildInsertPrefix(tableName, schema)
-		insertSuffix = a.buildOnDuplicateKeySuffix(schema)
+		insertSuffix = a.buildOnDuplicateKeySuffix(updates)
+		if insertSuffix == "" && strategy == adapters.StrategyMerge {
+			// Обновлять нечего — существующие строки остаются как есть
+			insertPrefix = a.buildInsertIgnorePrefix(tableName, schema)
+		}
 	case adapters.StrategyIgnore:
 		insertPrefix = a.buildInsertIgnorePrefix(tableName, schema)
 	case adapters.StrategyFail:
 		insertPrefix = a.buildInsertPrefix(tableName, schema)
+	case adapters.StrategyCopy, adapters.StrategyAppend, adapters.StrategyTruncate:
+		insertPrefix = a.buildInsertPrefix(tableName, schema)
+		bulk = true
 	default:
 		return fmt.Errorf("unsupported import strategy: %v", strategy)
 	}
@@ -110,46 +256,104 @@ func (a *Adapter) InsertRows(ctx context.Context, tableName string, schema packe
 	if batchSize < 1 {
 		batchSize = 1
 	}
-	if batchSize > 1000 {
+	if batchSize > 1000 && !bulk {
 		batchSize = 1000
 	}
 
-	for i := 0; i < len(rows); i += batchSize {
-		end := i + batchSize
-		if end > len(rows) {
-			end = len(rows)
+	conn := a.importConn(ctx)
+	if _, inTx := conn.(*sql.Tx); bulk && !inTx {
+		tx, err := a.db.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("failed to begin transaction: %w", err)
 		}
-		batch := rows[i:end]
+		defer func() {
+			if err != nil {
+				_ = tx.Rollback()
+				return
+			}
+			err = tx.Commit()
+		}()
+		conn = tx
+	}
 
-		// Строим multi-row INSERT: INSERT ... (cols) VALUES (?,?,...),(?,?,...) [ON DUPLICATE...]
-		valuePlaceholders := make([]string, len(batch))
-		for j := range batch {
-			valuePlaceholders[j] = rowPH
-		}
-		batchSQL := insertPrefix + " VALUES " + strings.Join(valuePlaceholders, ", ")
+	// Multi-row INSERT: INSERT ... (cols) VALUES (?,?,...),(?,?,...) [ON DUPLICATE...]
+	flush := func(n int, args []any) error {
+		batchSQL := insertPrefix + " VALUES " + strings.Repeat(rowPH+", ", n-1) + rowPH
 		if insertSuffix != "" {
 			batchSQL += " " + insertSuffix
 		}
+		if _, err := conn.ExecContext(ctx, batchSQL, args...); err != nil {
+			return fmt.Errorf("failed to insert batch: %w", err)
+		}
+		return nil
+	}
 
-		// Собираем аргументы для всех строк батча
-		args := make([]any, 0, len(batch)*numFields)
-		for _, row := range batch {
-			rowValues := base.ParseRowValues(row)
-			sqlValues, err := base.ConvertRowToSQLValues(rowValues, schema, a.converter, "mysql")
-			if err != nil {
-				return fmt.Errorf("failed to convert row values: %w", err)
+	// Аргументы пачки; буфер переиспользуется между пачками
+	args := make([]any, 0, min(len(rows), batchSize)*numFields)
+	n, size := 0, 0
+	for _, row := range rows {
+		rowValues := base.ParseRowValues(row)
+		if keep != nil {
+			rowValues = projectValues(rowValues, keep)
+		}
+		sqlValues, err := base.ConvertRowToSQLValues(rowValues, schema, a.converter, "mysql")
+		if err != nil {
+			return fmt.Errorf("failed to convert row values: %w", err)
+		}
+		for j, field := range schema.Fields {
+			// JSON не принимает пустую строку: "" в JSON поле — NULL без маркера
+			if field.Subtype == SubtypeJSON && sqlValues[j] == "" {
+				sqlValues[j] = nil
 			}
-			args = append(args, sqlValues...)
 		}
+		args = append(args, sqlValues...)
+		n++
+		size += len(row.Value)
 
-		if _, err := a.db.ExecContext(ctx, batchSQL, args...); err != nil {
-			return fmt.Errorf("failed to insert batch: %w", err)
+		if n == batchSize || (bulk && size >= bulkBatchBytes) {
+			if err := flush(n, args); err != nil {
+				return err
+			}
+			args, n, size = args[:0], 0, 0
 		}
 	}
-
+	if n > 0 {
+		return flush(n, args)
+	}
 	return nil
 }
 
+// insertableFields убирает из схемы вычисляемые колонки. keep — индексы
+// оставшихся полей в исходной схеме; nil, если убирать нечего.
+func insertableFields(schema packet.Schema) (packet.Schema, []int) {
+	var keep []int
+	for i, field := range schema.Fields {
+		if field.Generated == nil {
+			keep = append(keep, i)
+		}
+	}
+	if len(keep) == len(schema.Fields) {
+		return schema, nil
+	}
+
+	fields := make([]packet.Field, len(keep))
+	for i, idx := range keep {
+		fields[i] = schema.Fields[idx]
+	}
+	return packet.Schema{Fields: fields}, keep
+}
+
+// projectValues оставляет значения строки по индексам keep.
+func projectValues(values []string, keep []int) []string {
+	projected := make([]string, len(keep))
+	for i, idx := range keep {
+		if idx < len(values) {
+			projected[i] = values[idx]
+		}
+	}
+	return projected
+}
+
 // ========== MySQL-специфичные SQL builders ==========
 
 // buildInsertPrefix возвращает "INSERT INTO `table` (`col1`, `col2`, ...)" без VALUES
@@ -173,16 +377,14 @@ func (a *Adapter) buildInsertIgnorePrefix(tableName string, schema packet.Schema
 }
 
 // buildOnDuplicateKeySuffix возвращает "ON DUPLICATE KEY UPDATE `col` = VALUES(`col`), ..."
-// только для non-PK колонок
-func (a *Adapter) buildOnDuplicateKeySuffix(schema packet.Schema) string {
-	var updates []string
-	for _, field := range schema.Fields {
-		if !field.Key {
-			updates = append(updates, fmt.Sprintf("`%s` = VALUES(`%s`)", field.Name, field.Name))
-		}
-	}
-	if len(updates) == 0 {
+// для колонок columns (base.UpdateFields: non-PK колонки пакета)
+func (a *Adapter) buildOnDuplicateKeySuffix(columns []string) string {
+	if len(columns) == 0 {
 		return ""
 	}
+	updates := make([]string, len(columns))
+	for i, name := range columns {
+		updates[i] = fmt.Sprintf("`%s` = VALUES(`%s`)", name, name)
+	}
 	return "ON DUPLICATE KEY UPDATE " + strings.Join(updates, ", ")
 }
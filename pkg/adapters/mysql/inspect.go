@@ -109,7 +109,7 @@ func (a *Adapter) InspectTable(ctx context.Context, tableName string) (*adapters
 		return nil, fmt.Errorf("iterate columns: %w", err)
 	}
 	if len(report.Columns) == 0 {
-		return nil, fmt.Errorf("table %q not found or has no columns", tableName)
+		return nil, fmt.Errorf("table %q %w", tableName, adapters.ErrTableNotFound)
 	}
 
 	// ---- Foreign keys from information_schema ----
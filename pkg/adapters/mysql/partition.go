@@ -0,0 +1,37 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+// EnsurePartition создаёт таблицу-партицию (events_2025_06), если её нет:
+// копией структуры родительской таблицы (CREATE TABLE … LIKE) или, если
+// родителя нет, по схеме пакета. Реализует adapters.PartitionManager.
+// from/to не используются: в MySQL партиции — отдельные таблицы.
+func (a *Adapter) EnsurePartition(ctx context.Context, parentTable, partitionTable string, schema packet.Schema, _, _ time.Time) error {
+	exists, err := a.TableExists(ctx, partitionTable)
+	if err != nil || exists {
+		return err
+	}
+
+	parentExists, err := a.TableExists(ctx, parentTable)
+	if err != nil {
+		return err
+	}
+	if !parentExists {
+		return a.CreateTable(ctx, partitionTable, schema)
+	}
+
+	quote := func(name string) string { return "`" + strings.ReplaceAll(name, "`", "``") + "`" }
+	sql := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s LIKE %s", quote(partitionTable), quote(parentTable))
+	if _, err := a.db.ExecContext(ctx, sql); err != nil {
+		return fmt.Errorf("failed to create partition: %w", err)
+	}
+	fmt.Printf("📅 Created partition %s\n", partitionTable)
+	return nil
+}
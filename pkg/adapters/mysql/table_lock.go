@@ -0,0 +1,42 @@
+package mysql
+
+import (
+	"context"
+	"crypto/sha1"
+	"database/sql"
+	"encoding/hex"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters/base"
+)
+
+// maxLockName — предел длины имени GET_LOCK (MySQL 5.7+).
+const maxLockName = 64
+
+// TryLockTable реализует base.TableLocker: GET_LOCK с нулевым ожиданием.
+// Блокировка принадлежит сессии, поэтому соединение изымается из пула до
+// освобождения.
+func (a *Adapter) TryLockTable(ctx context.Context, table string) (func() error, bool, error) {
+	conn, err := a.db.Conn(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	name := base.TableLockName(table)
+	if len(name) > maxLockName {
+		sum := sha1.Sum([]byte(name))
+		name = "tdtp:import:" + hex.EncodeToString(sum[:])
+	}
+	var got sql.NullInt64
+	if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, 0)", name).Scan(&got); err != nil {
+		_ = conn.Close()
+		return nil, false, err
+	}
+	if !got.Valid || got.Int64 != 1 {
+		_ = conn.Close()
+		return nil, false, nil
+	}
+	return func() error {
+		defer conn.Close()
+		_, err := conn.ExecContext(context.Background(), "SELECT RELEASE_LOCK(?)", name)
+		return err
+	}, true, nil
+}
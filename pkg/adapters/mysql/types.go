@@ -10,8 +10,16 @@ import (
 	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
 )
 
+// SubtypeJSON — subtype TEXT поля для колонок JSON (как у PostgreSQL json)
+const SubtypeJSON = "json"
+
 // TDTPToMySQL конвертирует TDTP тип в MySQL тип
 func TDTPToMySQL(field packet.Field) string {
+	// JSON (MySQL JSON, PostgreSQL json/jsonb) — нативный тип
+	if field.Subtype == SubtypeJSON || field.Subtype == "jsonb" {
+		return "JSON"
+	}
+
 	switch strings.ToUpper(field.Type) {
 	// Целочисленные типы
 	case "INTEGER", "INT":
@@ -186,6 +194,10 @@ func BuildFieldFromColumn(columnName, dataType string, isPrimaryKey bool) (packe
 	case "BOOLEAN", "BOOL":
 		field.Type = "BOOLEAN"
 
+	case "JSON":
+		field.Type = "TEXT"
+		field.Subtype = SubtypeJSON
+
 	default:
 		return field, fmt.Errorf("unsupported MySQL type: %s", baseType)
 	}
@@ -193,6 +205,37 @@ func BuildFieldFromColumn(columnName, dataType string, isPrimaryKey bool) (packe
 	return field, nil
 }
 
+// generatedColumn разбирает information_schema.columns.extra и
+// generation_expression. Для обычных колонок возвращает nil
+// (DEFAULT_GENERATED — выражение по умолчанию, а не вычисляемая колонка).
+func generatedColumn(extra, expression string) *packet.GeneratedColumn {
+	extra = strings.ToUpper(extra)
+	var stored bool
+	switch {
+	case strings.Contains(extra, "STORED GENERATED"):
+		stored = true
+	case strings.Contains(extra, "VIRTUAL GENERATED"):
+	default:
+		return nil
+	}
+	// MySQL 8 экранирует кавычки строковых литералов: _utf8mb4\'a\'
+	expression = strings.ReplaceAll(expression, `\'`, `'`)
+	return &packet.GeneratedColumn{Expression: expression, Stored: stored}
+}
+
+// generatedClause возвращает " GENERATED ALWAYS AS (expr) STORED|VIRTUAL"
+// для вычисляемой колонки, иначе "".
+func generatedClause(field packet.Field) string {
+	if field.Generated == nil {
+		return ""
+	}
+	kind := "VIRTUAL"
+	if field.Generated.Stored {
+		kind = "STORED"
+	}
+	return fmt.Sprintf(" GENERATED ALWAYS AS (%s) %s", field.Generated.Expression, kind)
+}
+
 // parseDataType парсит MySQL тип данных вида "TYPE(params)"
 // Возвращает базовый тип и массив параметров
 func parseDataType(dataType string) (string, []string) {
@@ -0,0 +1,92 @@
+package mysql
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+func TestJSONTypeMapping(t *testing.T) {
+	field, err := BuildFieldFromColumn("payload", "json", false)
+	if err != nil {
+		t.Fatalf("BuildFieldFromColumn(json): %v", err)
+	}
+	if field.Type != "TEXT" || field.Subtype != SubtypeJSON {
+		t.Errorf("json → %s/%s, want TEXT/json", field.Type, field.Subtype)
+	}
+	if got := TDTPToMySQL(field); got != "JSON" {
+		t.Errorf("TDTPToMySQL(json) = %s, want JSON", got)
+	}
+	// PostgreSQL jsonb → MySQL JSON
+	if got := TDTPToMySQL(packet.Field{Type: "TEXT", Subtype: "jsonb"}); got != "JSON" {
+		t.Errorf("TDTPToMySQL(jsonb) = %s, want JSON", got)
+	}
+}
+
+func TestGeneratedColumn(t *testing.T) {
+	tests := []struct {
+		extra, expr string
+		want        *packet.GeneratedColumn
+	}{
+		{"VIRTUAL GENERATED", "(`price` * `qty`)", &packet.GeneratedColumn{Expression: "(`price` * `qty`)"}},
+		{"STORED GENERATED", `concat(_utf8mb4\'#\',` + "`id`)", &packet.GeneratedColumn{Expression: "concat(_utf8mb4'#',`id`)", Stored: true}},
+		{"DEFAULT_GENERATED", "", nil},
+		{"auto_increment", "", nil},
+		{"", "", nil},
+	}
+	for _, tt := range tests {
+		if got := generatedColumn(tt.extra, tt.expr); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("generatedColumn(%q, %q) = %+v, want %+v", tt.extra, tt.expr, got, tt.want)
+		}
+	}
+
+	field := packet.Field{Name: "total", Type: "DECIMAL", Generated: &packet.GeneratedColumn{Expression: "`price` * `qty`", Stored: true}}
+	if got := generatedClause(field); got != " GENERATED ALWAYS AS (`price` * `qty`) STORED" {
+		t.Errorf("generatedClause = %q", got)
+	}
+}
+
+func TestGenerateDDLComments(t *testing.T) {
+	schema := packet.Schema{
+		Description: "Orders",
+		Fields: []packet.Field{
+			{Name: "id", Type: "INTEGER", Key: true, Description: `Order's id \ key`},
+			{Name: "Imia", Type: "TEXT", Length: 50, OriginalName: "Имя"},
+		},
+	}
+	want := "CREATE TABLE `orders` (" +
+		"`id` " + TDTPToMySQL(schema.Fields[0]) + ` NOT NULL COMMENT 'Order\'s id \\ key', ` +
+		"`Imia` " + TDTPToMySQL(schema.Fields[1]) + " COMMENT 'original: Имя', " +
+		"PRIMARY KEY (`id`)) COMMENT = 'Orders'"
+	if got := (&Adapter{}).GenerateDDL("orders", schema); len(got) != 1 || got[0] != want {
+		t.Errorf("GenerateDDL:\n got %v\nwant %s", got, want)
+	}
+
+	long := strings.Repeat("я", maxColumnComment+10)
+	if got := commentLiteral(long, maxColumnComment); len([]rune(got)) != maxColumnComment+2 {
+		t.Errorf("commentLiteral: %d runes, want %d", len([]rune(got)), maxColumnComment+2)
+	}
+}
+
+func TestInsertableFields(t *testing.T) {
+	schema := packet.Schema{Fields: []packet.Field{
+		{Name: "id", Key: true},
+		{Name: "total", Generated: &packet.GeneratedColumn{Expression: "price * qty"}},
+		{Name: "price"},
+	}}
+
+	got, keep := insertableFields(schema)
+	if len(got.Fields) != 2 || got.Fields[0].Name != "id" || got.Fields[1].Name != "price" {
+		t.Fatalf("insertableFields = %+v", got.Fields)
+	}
+	if values := projectValues([]string{"1", "20", "10"}, keep); !reflect.DeepEqual(values, []string{"1", "10"}) {
+		t.Errorf("projectValues = %q, want [1 10]", values)
+	}
+
+	plain := packet.Schema{Fields: []packet.Field{{Name: "id"}}}
+	if _, keep := insertableFields(plain); keep != nil {
+		t.Errorf("keep = %v for schema without generated columns, want nil", keep)
+	}
+}
@@ -0,0 +1,501 @@
+package oracle
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	_ "github.com/sijms/go-ora/v2" // Oracle driver (pure Go)
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+	"github.com/ruslano69/tdtp-framework/pkg/adapters/base"
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+	"github.com/ruslano69/tdtp-framework/pkg/core/tdtql"
+)
+
+// AdapterType идентификатор Oracle адаптера
+const AdapterType = "oracle"
+
+// Compatibility levels (major version of Oracle Database)
+const (
+	CompatOracle11g = 11 // Oracle 11g
+	CompatOracle12c = 12 // Oracle 12c
+	CompatOracle19c = 19 // Oracle 19c
+)
+
+// Adapter implements the adapters.Adapter interface for Oracle Database.
+type Adapter struct {
+	db     *sql.DB
+	config adapters.Config
+
+	// Version information
+	serverVersion    int    // Major version: 11, 12, 18, 19, 21, 23
+	serverVersionStr string // Full version string ("19.0.0.0.0")
+
+	// Effective compatibility (min of config and server)
+	effectiveCompat int
+
+	// Compatibility mode settings
+	strictMode bool // Error if requested mode is newer than the server
+	warnMode   bool // Warn if requested mode is newer than the server
+
+	// owner — схема для имён без владельца (Config.Schema или CURRENT_SCHEMA)
+	owner string
+
+	// Base helpers
+	exportHelper *base.ExportHelper
+	importHelper *base.ImportHelper
+	converter    *base.UniversalTypeConverter
+
+	queryLog *adapters.QueryLogger // журнал SQL-выражений (Config.QueryLog)
+}
+
+func init() {
+	adapters.Register(AdapterType, func() adapters.Adapter {
+		return &Adapter{}
+	})
+	adapters.Describe(adapters.DriverInfo{Type: AdapterType, Driver: "github.com/sijms/go-ora/v2"})
+}
+
+// Connect подключается к Oracle, определяет версию сервера и режим совместимости.
+func (a *Adapter) Connect(ctx context.Context, cfg adapters.Config) error {
+	governor, err := adapters.NewGovernor(cfg.ImportLimits)
+	if err != nil {
+		return fmt.Errorf("invalid import limits: %w", err)
+	}
+
+	a.queryLog = adapters.NewQueryLogger(cfg.QueryLog)
+	db, err := base.OpenDB("oracle", cfg.DSN, a.queryLog)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+
+	if err := db.PingContext(ctx); err != nil {
+		_ = db.Close()
+		return fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	a.db = db
+	a.config = cfg
+	a.strictMode = cfg.StrictCompatibility
+	a.warnMode = cfg.WarnOnIncompatible
+
+	if err := a.detectCompatibility(ctx); err != nil {
+		_ = db.Close()
+		return fmt.Errorf("failed to detect compatibility: %w", err)
+	}
+
+	if err := a.applyCompatibilityMode(cfg.CompatibilityMode); err != nil {
+		_ = db.Close()
+		return err
+	}
+
+	if cfg.Schema != "" {
+		a.owner = base.OracleName(cfg.Schema)
+	} else if err := db.QueryRowContext(ctx,
+		"SELECT SYS_CONTEXT('USERENV', 'CURRENT_SCHEMA') FROM dual").Scan(&a.owner); err != nil {
+		_ = db.Close()
+		return fmt.Errorf("failed to get current schema: %w", err)
+	}
+
+	a.initHelpers()
+	if cfg.SourceTimezone != "" {
+		if err := a.converter.SetSourceTimezone(cfg.SourceTimezone); err != nil {
+			_ = db.Close()
+			return err
+		}
+	}
+	if err := a.converter.SetBoolMappings(cfg.Booleans, cfg.ColumnBooleans); err != nil {
+		_ = db.Close()
+		return err
+	}
+	if err := a.exportHelper.SetPacketSizing(cfg.Packets); err != nil {
+		_ = db.Close()
+		return err
+	}
+	a.importHelper.SetGovernor(governor)
+	a.importHelper.SetStreamBatchPackets(cfg.StreamBatchPackets)
+	a.importHelper.SetMaintenanceWindows(cfg.Maintenance)
+	if cfg.TableLock.Mode != adapters.TableLockOff {
+		// DBMS_LOCK требует отдельного гранта — блокировка таблиц не поддерживается
+		_ = db.Close()
+		return fmt.Errorf("table locking is not supported by oracle adapter")
+	}
+	if err := cfg.Duplicates.Validate(); err != nil {
+		_ = db.Close()
+		return err
+	}
+	a.importHelper.SetDuplicateMode(cfg.Duplicates)
+	if err := cfg.Columns.Validate(); err != nil {
+		_ = db.Close()
+		return err
+	}
+	a.importHelper.SetColumnMatching(cfg.Columns)
+	a.importHelper.SetColumnCipher(cfg.ColumnCipher)
+	a.exportHelper.SetColumnCipher(cfg.ColumnCipher)
+	a.exportHelper.SetExportPolicy(cfg.ExportPolicy)
+	if err := cfg.RowErrors.Validate(); err != nil {
+		_ = db.Close()
+		return err
+	}
+	a.importHelper.SetRowErrorPolicy(cfg.RowErrors, a.converter, AdapterType)
+
+	return nil
+}
+
+// TimezoneReport реализует adapters.TimezoneReporter: отчёт о переводе
+// naive datetime из Config.SourceTimezone в UTC (nil — пояс не задан).
+func (a *Adapter) TimezoneReport() *adapters.TimezoneReport {
+	if a.converter == nil {
+		return nil
+	}
+	return a.converter.TimezoneReport()
+}
+
+// initHelpers настраивает base helpers под effective compatibility
+func (a *Adapter) initHelpers() {
+	a.converter = base.NewUniversalTypeConverter()
+	if len(a.config.NoDateSentinels) > 0 {
+		a.converter.SetNoDateSentinels(a.config.NoDateSentinels)
+	}
+
+	a.exportHelper = base.NewExportHelper(
+		a,           // SchemaReader
+		a,           // DataReader
+		a.converter, // ValueConverter
+		base.NewOracleSQLAdapter(a.owner, a.SupportsOffsetFetch()), // OFFSET/FETCH или ROWNUM
+	)
+	a.exportHelper.SetRegexPushdown(tdtql.RegexOracle)
+
+	// Временная таблица copy-импорта: {table}_tmp_YYYYMMDD_HHMMSS (+20 символов).
+	// С лимитом имён в 30 байт (до 12.2) такое имя почти всегда не помещается —
+	// copy выполняется прямой вставкой, как fail.
+	a.importHelper = base.NewImportHelper(
+		a, // TableManager
+		a, // DataInserter
+		a, // TransactionManager
+		a.SupportsLongIdentifiers(),
+	)
+}
+
+// detectCompatibility определяет версию Oracle Database.
+func (a *Adapter) detectCompatibility(ctx context.Context) error {
+	var version string
+	err := a.db.QueryRowContext(ctx, `
+		SELECT VERSION
+		FROM PRODUCT_COMPONENT_VERSION
+		WHERE PRODUCT LIKE 'Oracle Database%' AND ROWNUM = 1
+	`).Scan(&version)
+	if err != nil {
+		return fmt.Errorf("failed to get server version: %w", err)
+	}
+
+	a.serverVersionStr = version
+	a.serverVersion = parseServerVersion(version)
+	a.effectiveCompat = a.serverVersion
+
+	return nil
+}
+
+// parseServerVersion parses Oracle version string to major version number.
+// Examples:
+//   - "11.2.0.4.0" → 11
+//   - "12.1.0.2.0" → 12
+//   - "19.0.0.0.0" → 19
+func parseServerVersion(version string) int {
+	major, _, _ := strings.Cut(strings.TrimSpace(version), ".")
+	v, err := strconv.Atoi(major)
+	if err != nil {
+		return 0
+	}
+	return v
+}
+
+// applyCompatibilityMode applies explicit compatibility mode from config.
+func (a *Adapter) applyCompatibilityMode(mode string) error {
+	if mode == "" || mode == "auto" {
+		return nil
+	}
+
+	explicitCompat := parseCompatibilityMode(mode)
+	if explicitCompat == 0 {
+		return fmt.Errorf("invalid compatibility mode: %s (expected: 11g, 12c, 19c, or auto)", mode)
+	}
+
+	if explicitCompat > a.effectiveCompat {
+		msg := fmt.Sprintf("requested Oracle %s compatibility, but server is %s",
+			mode, a.getServerVersionName())
+
+		if a.strictMode {
+			return fmt.Errorf("strict mode: %s", msg)
+		}
+
+		if a.warnMode {
+			fmt.Printf("WARNING: %s\n", msg)
+		}
+	}
+
+	// Use minimum of explicit and detected for safety
+	if explicitCompat < a.effectiveCompat {
+		a.effectiveCompat = explicitCompat
+	}
+
+	return nil
+}
+
+// parseCompatibilityMode converts mode string to compatibility level.
+func parseCompatibilityMode(mode string) int {
+	switch strings.ToLower(mode) {
+	case "11g":
+		return CompatOracle11g
+	case "12c":
+		return CompatOracle12c
+	case "19c":
+		return CompatOracle19c
+	default:
+		return 0
+	}
+}
+
+// getServerVersionName returns human-readable server version name.
+func (a *Adapter) getServerVersionName() string {
+	switch {
+	case a.serverVersion == 0:
+		return "Oracle (unknown version)"
+	case a.serverVersion <= CompatOracle11g:
+		return fmt.Sprintf("Oracle %dg", a.serverVersion)
+	default:
+		return fmt.Sprintf("Oracle %dc", a.serverVersion)
+	}
+}
+
+// Feature detection methods
+
+// SupportsOffsetFetch returns true if OFFSET/FETCH is available (12c+).
+func (a *Adapter) SupportsOffsetFetch() bool {
+	return a.effectiveCompat >= CompatOracle12c
+}
+
+// SupportsJSON returns true if the IS JSON condition is available (12c+).
+func (a *Adapter) SupportsJSON() bool {
+	return a.effectiveCompat >= CompatOracle12c
+}
+
+// SupportsLongIdentifiers returns true if identifiers may be up to 128 bytes.
+// Available since 12.2 (COMPATIBLE >= 12.2); mode 12c also covers 12.1,
+// so only 19c+ is treated as safe.
+func (a *Adapter) SupportsLongIdentifiers() bool {
+	return a.effectiveCompat >= CompatOracle19c
+}
+
+// IdentifierLimit — 128 байт в режиме 19c, иначе 30 байт; без подключения
+// режим неизвестен, берётся 30 (adapters.IdentifierLimiter)
+func (a *Adapter) IdentifierLimit() adapters.IdentifierLimit {
+	if a.SupportsLongIdentifiers() {
+		return adapters.IdentifierLimit{MaxLength: 128, Bytes: true}
+	}
+	return adapters.IdentifierLimit{MaxLength: 30, Bytes: true}
+}
+
+// QueryLog реализует adapters.QueryLogged: журнал выражений адаптера.
+func (a *Adapter) QueryLog() *adapters.QueryLogger {
+	return a.queryLog
+}
+
+// Close закрывает соединение
+func (a *Adapter) Close(ctx context.Context) error {
+	if a.db != nil {
+		return a.db.Close()
+	}
+	return nil
+}
+
+// Ping проверяет соединение
+func (a *Adapter) Ping(ctx context.Context) error {
+	return a.db.PingContext(ctx)
+}
+
+// GetDatabaseType возвращает тип базы данных
+func (a *Adapter) GetDatabaseType() string {
+	return AdapterType
+}
+
+// GetDatabaseVersion возвращает версию Oracle и режим совместимости
+func (a *Adapter) GetDatabaseVersion(ctx context.Context) (string, error) {
+	return fmt.Sprintf("%s (compatibility %s)", a.serverVersionStr, compatName(a.effectiveCompat)), nil
+}
+
+// compatName — имя режима совместимости для уровня: 11g, 12c, 19c.
+func compatName(level int) string {
+	switch {
+	case level >= CompatOracle19c:
+		return "19c"
+	case level >= CompatOracle12c:
+		return "12c"
+	default:
+		return "11g"
+	}
+}
+
+// splitName разбирает имя таблицы: владелец (по умолчанию — схема адаптера) и имя.
+func (a *Adapter) splitName(tableName string) (owner, table string) {
+	return base.SplitOracleName(tableName, a.owner)
+}
+
+// quoteTable возвращает "OWNER"."TABLE"; без владельца (нет подключения) — "TABLE"
+func (a *Adapter) quoteTable(tableName string) string {
+	owner, table := a.splitName(tableName)
+	if owner == "" {
+		return base.QuoteOracleIdentifier(table)
+	}
+	return base.QuoteOracleIdentifier(owner) + "." + base.QuoteOracleIdentifier(table)
+}
+
+// quoteColumn квотирует колонку по тем же правилам регистра, что и таблицы
+func quoteColumn(name string) string {
+	return base.QuoteOracleIdentifier(base.OracleName(name))
+}
+
+// TableExists проверяет существование таблицы или представления
+func (a *Adapter) TableExists(ctx context.Context, tableName string) (bool, error) {
+	owner, table := a.splitName(tableName)
+	var count int
+	err := a.db.QueryRowContext(ctx, `
+		SELECT COUNT(*)
+		FROM ALL_OBJECTS
+		WHERE OWNER = :1 AND OBJECT_NAME = :2 AND OBJECT_TYPE IN ('TABLE', 'VIEW')
+	`, owner, table).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// GetTableNames возвращает таблицы схемы адаптера (без временных, вложенных
+// и удалённых в корзину)
+func (a *Adapter) GetTableNames(ctx context.Context) ([]string, error) {
+	rows, err := a.db.QueryContext(ctx, `
+		SELECT TABLE_NAME
+		FROM ALL_TABLES
+		WHERE OWNER = :1
+			AND TEMPORARY = 'N'
+			AND NESTED = 'NO'
+			AND SECONDARY = 'N'
+			AND DROPPED = 'NO'
+		ORDER BY TABLE_NAME
+	`, a.owner)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var tables []string
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			return nil, err
+		}
+		tables = append(tables, table)
+	}
+	return tables, rows.Err()
+}
+
+// GetViewNames возвращает представления схемы. Представление считается
+// updatable, если в него можно вставлять хотя бы одну колонку.
+func (a *Adapter) GetViewNames(ctx context.Context) ([]adapters.ViewInfo, error) {
+	rows, err := a.db.QueryContext(ctx, `
+		SELECT v.VIEW_NAME,
+			CASE WHEN EXISTS (
+				SELECT 1 FROM ALL_UPDATABLE_COLUMNS u
+				WHERE u.OWNER = v.OWNER AND u.TABLE_NAME = v.VIEW_NAME AND u.INSERTABLE = 'YES'
+			) THEN 1 ELSE 0 END
+		FROM ALL_VIEWS v
+		WHERE v.OWNER = :1
+		ORDER BY v.VIEW_NAME
+	`, a.owner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query views: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var views []adapters.ViewInfo
+	for rows.Next() {
+		var name string
+		var updatable int
+		if err := rows.Scan(&name, &updatable); err != nil {
+			return nil, fmt.Errorf("failed to scan view info: %w", err)
+		}
+		views = append(views, adapters.ViewInfo{
+			Name:        name,
+			IsUpdatable: updatable == 1,
+		})
+	}
+
+	return views, rows.Err()
+}
+
+// BeginTx начинает транзакцию (для ImportHelper)
+func (a *Adapter) BeginTx(ctx context.Context) (adapters.Tx, error) {
+	tx, err := a.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &oracleTx{tx: tx}, nil
+}
+
+// oracleTx - обертка для *sql.Tx для реализации adapters.Tx
+type oracleTx struct {
+	tx *sql.Tx
+}
+
+var _ adapters.SavepointTx = (*oracleTx)(nil)
+
+func (t *oracleTx) Commit(ctx context.Context) error {
+	return t.tx.Commit()
+}
+
+func (t *oracleTx) Rollback(ctx context.Context) error {
+	return t.tx.Rollback()
+}
+
+// Savepoint создаёт точку сохранения (adapters.SavepointTx)
+func (t *oracleTx) Savepoint(ctx context.Context, name string) error {
+	return base.OracleSavepoints.Savepoint(ctx, t.tx, name)
+}
+
+// RollbackTo откатывает транзакцию к точке сохранения
+func (t *oracleTx) RollbackTo(ctx context.Context, name string) error {
+	return base.OracleSavepoints.RollbackTo(ctx, t.tx, name)
+}
+
+// ReleaseSavepoint освобождает точку сохранения
+func (t *oracleTx) ReleaseSavepoint(ctx context.Context, name string) error {
+	return base.OracleSavepoints.ReleaseSavepoint(ctx, t.tx, name)
+}
+
+// ExecuteRawQuery выполняет произвольный SQL запрос.
+// Схема результата — по метаданным драйвера (GetQuerySchema).
+func (a *Adapter) ExecuteRawQuery(ctx context.Context, query string) (*packet.DataPacket, error) {
+	schema, err := a.GetQuerySchema(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	dataRows, err := a.ReadRowsWithSQL(ctx, query, schema)
+	if err != nil {
+		return nil, err
+	}
+
+	generator := packet.NewGenerator()
+	packets, err := generator.GenerateReference("result", schema, dataRows)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(packets) > 0 {
+		return packets[0], nil
+	}
+	return &packet.DataPacket{Schema: schema}, nil
+}
@@ -0,0 +1,262 @@
+package oracle
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+	"github.com/ruslano69/tdtp-framework/pkg/adapters/base"
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+// ========== Публичные методы (делегируют в ExportHelper) ==========
+
+// SetMaxMessageSize задаёт максимальный размер одного TDTP пакета (в байтах).
+// Используется CLI для передачи --packet-size.
+func (a *Adapter) SetMaxMessageSize(size int) {
+	a.exportHelper.SetMaxMessageSize(size)
+}
+
+// SetSkipSpecialValues включает режим --fast: DetectAndApply пропускается.
+func (a *Adapter) SetSkipSpecialValues(skip bool) {
+	a.exportHelper.SetSkipSpecialValues(skip)
+}
+
+// SetCompression включает zstd-сжатие Data экспортируемых пакетов
+// (см. base.ExportHelper.SetCompression).
+func (a *Adapter) SetCompression(opts packet.CompressionOptions) {
+	a.exportHelper.SetCompression(opts)
+}
+
+// SetPacketKeys включает шифрование пакетов TDTP v1.5 при экспорте и их
+// расшифровку при импорте (см. base.ExportHelper.SetPacketKeys).
+func (a *Adapter) SetPacketKeys(keys base.PacketKeyProvider) {
+	a.exportHelper.SetPacketKeys(keys)
+	a.importHelper.SetPacketKeys(keys)
+}
+
+// SetKeyMapper задаёт выдачу суррогатных ключей при импорте (keymap.Mapper).
+func (a *Adapter) SetKeyMapper(m base.KeyMapper) {
+	a.importHelper.SetKeyMapper(m)
+}
+
+// SetMaxFallbackRows задаёт лимит строк для in-memory fallback при провале SQL pushdown.
+func (a *Adapter) SetMaxFallbackRows(n int64) {
+	a.exportHelper.SetMaxFallbackRows(n)
+}
+
+// SetTableQueries задаёт собственные SELECT для таблиц вместо SELECT *
+// (см. base.ExportHelper.SetTableQueries).
+func (a *Adapter) SetTableQueries(queries map[string]string) error {
+	return a.exportHelper.SetTableQueries(queries)
+}
+
+// ExportQuery экспортирует результат произвольного read-only SQL
+// (adapters.QueryExporter, см. base.ExportHelper.ExportQuery).
+func (a *Adapter) ExportQuery(ctx context.Context, sql string) ([]*packet.DataPacket, error) {
+	return a.exportHelper.ExportQuery(ctx, sql)
+}
+
+// ExplainQuery возвращает план ExportTableWithQuery без чтения строк
+// (adapters.QueryExplainer, см. base.ExportHelper.Explain).
+func (a *Adapter) ExplainQuery(ctx context.Context, tableName string, query *packet.Query) (*packet.ExecutionPlan, error) {
+	return a.exportHelper.Explain(ctx, tableName, query)
+}
+
+// ExportTable экспортирует всю таблицу - просто делегируем
+func (a *Adapter) ExportTable(ctx context.Context, tableName string) ([]*packet.DataPacket, error) {
+	return a.exportHelper.ExportTable(ctx, tableName)
+}
+
+// ExportTableWithQuery экспортирует с TDTQL фильтрацией - просто делегируем
+func (a *Adapter) ExportTableWithQuery(ctx context.Context, tableName string, query *packet.Query, sender, recipient string) ([]*packet.DataPacket, error) {
+	return a.exportHelper.ExportTableWithQuery(ctx, tableName, query, sender, recipient)
+}
+
+// ExportTableIncremental - пока не реализовано
+func (a *Adapter) ExportTableIncremental(ctx context.Context, tableName string, incrementalConfig adapters.IncrementalConfig) ([]*packet.DataPacket, string, error) {
+	return nil, "", fmt.Errorf("incremental export not yet implemented for Oracle adapter")
+}
+
+// ========== base.SchemaReader interface ==========
+
+// GetTableSchema читает схему таблицы или представления из ALL_TAB_COLS.
+// Виртуальные колонки экспортируются как read-only и не вставляются при импорте.
+func (a *Adapter) GetTableSchema(ctx context.Context, tableName string) (packet.Schema, error) {
+	owner, table := a.splitName(tableName)
+	query := `
+		SELECT
+			c.COLUMN_NAME,
+			c.DATA_TYPE,
+			c.CHAR_LENGTH,
+			c.DATA_PRECISION,
+			c.DATA_SCALE,
+			c.VIRTUAL_COLUMN,
+			CASE WHEN EXISTS (
+				SELECT 1
+				FROM ALL_CONSTRAINTS k
+				JOIN ALL_CONS_COLUMNS kc
+					ON kc.OWNER = k.OWNER AND kc.CONSTRAINT_NAME = k.CONSTRAINT_NAME
+				WHERE k.OWNER = c.OWNER
+					AND k.TABLE_NAME = c.TABLE_NAME
+					AND k.CONSTRAINT_TYPE = 'P'
+					AND kc.COLUMN_NAME = c.COLUMN_NAME
+			) THEN 1 ELSE 0 END
+		FROM ALL_TAB_COLS c
+		WHERE c.OWNER = :1 AND c.TABLE_NAME = :2 AND c.HIDDEN_COLUMN = 'NO'
+		ORDER BY c.COLUMN_ID
+	`
+
+	rows, err := a.db.QueryContext(ctx, query, owner, table)
+	if err != nil {
+		return packet.Schema{}, fmt.Errorf("failed to query table schema: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var fields []packet.Field
+	for rows.Next() {
+		var (
+			col     ColumnInfo
+			charLen sql.NullInt64
+			virtual string
+			isPK    int
+		)
+		if err := rows.Scan(&col.Name, &col.DataType, &charLen, &col.Precision, &col.Scale, &virtual, &isPK); err != nil {
+			return packet.Schema{}, err
+		}
+		col.CharLength = int(charLen.Int64)
+
+		field := BuildFieldFromColumn(col, isPK == 1)
+		if virtual == "YES" {
+			field.ReadOnly = true
+		}
+		fields = append(fields, field)
+	}
+	if err := rows.Err(); err != nil {
+		return packet.Schema{}, err
+	}
+
+	if len(fields) == 0 {
+		return packet.Schema{}, fmt.Errorf("table %s.%s %w", owner, table, adapters.ErrTableNotFound)
+	}
+
+	return packet.Schema{Fields: fields}, nil
+}
+
+// GetQuerySchema возвращает схему результата SELECT по метаданным драйвера
+// (строки не читаются: запрос обёрнут в WHERE 1=0).
+// Реализует base.QuerySchemaReader для собственных запросов таблиц.
+func (a *Adapter) GetQuerySchema(ctx context.Context, query string) (packet.Schema, error) {
+	rows, err := a.db.QueryContext(ctx, base.MetadataQuery(query))
+	if err != nil {
+		return packet.Schema{}, fmt.Errorf("failed to describe query: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return packet.Schema{}, fmt.Errorf("failed to get column types: %w", err)
+	}
+	schema := packet.Schema{Fields: make([]packet.Field, len(columnTypes))}
+	for i, ct := range columnTypes {
+		col := ColumnInfo{Name: ct.Name(), DataType: driverColumnType(ct.DatabaseTypeName())}
+		if l, ok := ct.Length(); ok && l > 0 && l <= maxVarchar2 {
+			col.CharLength = int(l)
+		}
+		// NUMBER выражения (COUNT(*), SUM) приходят без точности
+		if p, sc, ok := ct.DecimalSize(); ok && p > 0 {
+			col.Precision = sql.NullInt64{Int64: p, Valid: true}
+			col.Scale = sql.NullInt64{Int64: sc, Valid: true}
+		}
+		schema.Fields[i] = BuildFieldFromColumn(col, false)
+	}
+	return schema, nil
+}
+
+// driverColumnType приводит имя типа go-ora ("TimeStampTZ_DTY",
+// "OCIClobLocator", "BDouble", ...) к DATA_TYPE словаря Oracle,
+// который понимает BuildFieldFromColumn.
+func driverColumnType(name string) string {
+	t := strings.ToUpper(strings.ReplaceAll(name, "_", ""))
+	switch {
+	case strings.Contains(t, "CLOB"):
+		return "CLOB"
+	case strings.Contains(t, "BLOB"):
+		return "BLOB"
+	case strings.HasPrefix(t, "TIMESTAMP"):
+		if strings.Contains(t, "TZ") || strings.Contains(t, "TIMEZONE") {
+			return "TIMESTAMP WITH TIME ZONE"
+		}
+		return "TIMESTAMP"
+	case strings.HasSuffix(t, "DOUBLE"):
+		return "BINARY_DOUBLE"
+	case t == "BFLOAT", t == "IBFLOAT", t == "BINARYFLOAT":
+		return "BINARY_FLOAT"
+	case t == "NUMBER", t == "VARNUM":
+		return "NUMBER"
+	case t == "FLOAT":
+		return "FLOAT"
+	case t == "CHAR", t == "NCHAR", t == "CHARZ", t == "VARCHAR", t == "VARCHAR2", t == "NVARCHAR2":
+		return "VARCHAR2"
+	case t == "RAW", t == "VARRAW", t == "LONGRAW", t == "LONGVARRAW":
+		return "RAW"
+	case t == "DATE", t == "OCIDATE":
+		return "DATE"
+	case t == "LONG", t == "LONGVARCHAR":
+		return "LONG"
+	case t == "JSON":
+		return "JSON"
+	default:
+		return t
+	}
+}
+
+// ========== base.DataReader interface ==========
+
+// ReadAllRows читает все строки из таблицы
+func (a *Adapter) ReadAllRows(ctx context.Context, tableName string, pkgSchema packet.Schema) ([][]string, error) {
+	return a.ReadRowsWithSQL(ctx, a.selectAllSQL(tableName, pkgSchema), pkgSchema)
+}
+
+// StreamAllRows читает таблицу построчно (base.RowStreamer)
+func (a *Adapter) StreamAllRows(ctx context.Context, tableName string, pkgSchema packet.Schema, fn func(row []string) error) error {
+	rows, err := a.db.QueryContext(ctx, a.selectAllSQL(tableName, pkgSchema))
+	if err != nil {
+		return fmt.Errorf("failed to execute SQL: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	return base.StreamSQLRows(rows, pkgSchema, a.converter, AdapterType, fn)
+}
+
+// selectAllSQL формирует SELECT всех полей схемы из таблицы.
+// Имена полей схемы — имена словаря, квотируются как есть.
+func (a *Adapter) selectAllSQL(tableName string, pkgSchema packet.Schema) string {
+	columns := make([]string, 0, len(pkgSchema.Fields))
+	for _, field := range pkgSchema.Fields {
+		columns = append(columns, base.QuoteOracleIdentifier(field.Name))
+	}
+
+	return fmt.Sprintf("SELECT %s FROM %s", strings.Join(columns, ", "), a.quoteTable(tableName))
+}
+
+// ReadRowsWithSQL выполняет SQL и возвращает строки
+func (a *Adapter) ReadRowsWithSQL(ctx context.Context, sqlQuery string, pkgSchema packet.Schema) ([][]string, error) {
+	rows, err := a.db.QueryContext(ctx, sqlQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute SQL: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	return base.ScanSQLRows(rows, pkgSchema, a.converter, AdapterType)
+}
+
+// GetRowCount возвращает количество строк в таблице
+func (a *Adapter) GetRowCount(ctx context.Context, tableName string) (int64, error) {
+	var count int64
+	query := fmt.Sprintf("SELECT COUNT(*) FROM %s", a.quoteTable(tableName))
+	err := a.db.QueryRowContext(ctx, query).Scan(&count)
+	return count, err
+}
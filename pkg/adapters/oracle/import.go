@@ -0,0 +1,353 @@
+package oracle
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+	"github.com/ruslano69/tdtp-framework/pkg/adapters/base"
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+// ========== Публичные методы (делегируют в ImportHelper) ==========
+
+// ImportPacket импортирует один пакет - просто делегируем
+func (a *Adapter) ImportPacket(ctx context.Context, pkt *packet.DataPacket, strategy adapters.ImportStrategy) error {
+	return a.importHelper.ImportPacket(ctx, pkt, strategy)
+}
+
+// ImportPackets импортирует несколько пакетов - просто делегируем
+func (a *Adapter) ImportPackets(ctx context.Context, packets []*packet.DataPacket, strategy adapters.ImportStrategy) error {
+	return a.importHelper.ImportPackets(ctx, packets, strategy)
+}
+
+// ImportPacketStream импортирует пакеты из канала пачками транзакций
+// (adapters.PacketStreamImporter) - просто делегируем
+func (a *Adapter) ImportPacketStream(ctx context.Context, packets <-chan *packet.DataPacket, strategy adapters.ImportStrategy) error {
+	return a.importHelper.ImportPacketStream(ctx, packets, strategy)
+}
+
+// DryRunImport реализует adapters.DryRunImporter: проверка пакетов против
+// БД без записи (base.DryRun).
+func (a *Adapter) DryRunImport(ctx context.Context, packets []*packet.DataPacket, strategy adapters.ImportStrategy) (*adapters.ImportReport, error) {
+	return a.importHelper.DryRun(a, a.converter, AdapterType).Import(ctx, packets, strategy)
+}
+
+// ImportPacketsReport реализует adapters.ReportingImporter: ImportPackets
+// с отчётом об отклонённых строках (Config.RowErrors).
+func (a *Adapter) ImportPacketsReport(ctx context.Context, packets []*packet.DataPacket, strategy adapters.ImportStrategy) (*adapters.ImportReport, error) {
+	return a.importHelper.ImportPacketsReport(ctx, packets, strategy)
+}
+
+// ApplyDelta применяет delta-пакеты точечными UPDATE в одной транзакции.
+// Реализует base.DeltaApplier
+func (a *Adapter) ApplyDelta(ctx context.Context, packets []*packet.DataPacket, strategy adapters.ImportStrategy) error {
+	return base.ApplyDeltaPacketsSQL(ctx, a.db, packets, strategy, a.quoteTable, a.deltaDialect())
+}
+
+// ApplyDelete удаляет строки по ключам пакетов удаления в одной транзакции.
+// Реализует base.DeleteApplier
+func (a *Adapter) ApplyDelete(ctx context.Context, packets []*packet.DataPacket) (int, error) {
+	return base.ApplyDeletePacketsSQL(ctx, a.db, packets, a.quoteTable, a.deltaDialect())
+}
+
+// deltaDialect — SQL-диалект Oracle для delta- и delete-пакетов.
+func (a *Adapter) deltaDialect() base.DeltaDialect {
+	return base.DeltaDialect{
+		Quote:       quoteColumn,
+		Placeholder: func(n int) string { return fmt.Sprintf(":%d", n) },
+		Convert:     base.SQLValueConverter(a.converter, AdapterType),
+	}
+}
+
+// ========== base.TableManager interface ==========
+
+// CreateTable создает таблицу из TDTP схемы.
+// JSON поля — CLOB с ограничением IS JSON (12c+).
+func (a *Adapter) CreateTable(ctx context.Context, tableName string, schema packet.Schema) error {
+	ddl := a.GenerateDDL(tableName, schema)
+	if _, err := a.db.ExecContext(ctx, ddl[0]); err != nil {
+		return fmt.Errorf("failed to create table: %w", err)
+	}
+
+	for _, comment := range ddl[1:] {
+		if _, err := a.db.ExecContext(ctx, comment); err != nil {
+			return fmt.Errorf("failed to add comment: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// GenerateDDL возвращает CREATE TABLE и COMMENT ON TABLE/COLUMN с описаниями
+// из источника и исходными именами санитизированных полей (adapters.DDLGenerator). Без подключения
+// режим совместимости неизвестен: IS JSON не добавляется, таблица без владельца.
+func (a *Adapter) GenerateDDL(tableName string, schema packet.Schema) []string {
+	columns := make([]string, 0, len(schema.Fields)+1)
+	var pkColumns []string
+	var comments []string
+
+	quotedTable := a.quoteTable(tableName)
+	for _, field := range schema.Fields {
+		name := quoteColumn(field.Name)
+		column := name + " " + TDTPToOracle(field)
+
+		if field.Key {
+			column += " NOT NULL"
+			pkColumns = append(pkColumns, name)
+		}
+		if isJSONField(field) && a.SupportsJSON() {
+			column += fmt.Sprintf(" CHECK (%s IS JSON)", name)
+		}
+
+		// Описание колонки и исходное имя санитизированного поля
+		if comment := adapters.ColumnComment(field); comment != "" {
+			comments = append(comments, fmt.Sprintf("COMMENT ON COLUMN %s.%s IS %s", quotedTable, name, quoteLiteral(comment)))
+		}
+
+		columns = append(columns, column)
+	}
+
+	if len(pkColumns) > 0 {
+		columns = append(columns, fmt.Sprintf("PRIMARY KEY (%s)", strings.Join(pkColumns, ", ")))
+	}
+
+	createSQL := fmt.Sprintf("CREATE TABLE %s (%s)", quotedTable, strings.Join(columns, ", "))
+	if schema.Description != "" {
+		comments = append([]string{fmt.Sprintf("COMMENT ON TABLE %s IS %s", quotedTable, quoteLiteral(schema.Description))}, comments...)
+	}
+	return append([]string{createSQL}, comments...)
+}
+
+// quoteLiteral экранирует строковый литерал для COMMENT ON
+func quoteLiteral(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+// DropTable удаляет таблицу; отсутствие таблицы (ORA-00942) не ошибка
+func (a *Adapter) DropTable(ctx context.Context, tableName string) error {
+	dropSQL := strings.ReplaceAll("DROP TABLE "+a.quoteTable(tableName)+" PURGE", "'", "''")
+	_, err := a.db.ExecContext(ctx, fmt.Sprintf(`
+		BEGIN
+			EXECUTE IMMEDIATE '%s';
+		EXCEPTION
+			WHEN OTHERS THEN
+				IF SQLCODE != -942 THEN
+					RAISE;
+				END IF;
+		END;`, dropSQL))
+	return err
+}
+
+// RenameTable переименовывает таблицу (владелец не меняется)
+func (a *Adapter) RenameTable(ctx context.Context, oldName, newName string) error {
+	_, newTable := a.splitName(newName)
+	_, err := a.db.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s RENAME TO %s",
+		a.quoteTable(oldName), base.QuoteOracleIdentifier(newTable)))
+	return err
+}
+
+// TruncateTable удаляет все строки таблицы в транзакции импорта
+// (base.ImportTx). DELETE, а не TRUNCATE: TRUNCATE в Oracle — DDL с
+// неявным COMMIT. Реализует base.TableTruncater
+func (a *Adapter) TruncateTable(ctx context.Context, tableName string) error {
+	var conn base.SQLExecer = a.db
+	if t, ok := base.ImportTx(ctx).(*oracleTx); ok {
+		conn = t.tx
+	}
+	_, err := conn.ExecContext(ctx, "DELETE FROM "+a.quoteTable(tableName))
+	return err
+}
+
+// AddColumns добавляет колонки в существующую таблицу (adapters.ColumnAdder)
+func (a *Adapter) AddColumns(ctx context.Context, tableName string, fields []packet.Field) error {
+	clauses := make([]string, len(fields))
+	for i, field := range fields {
+		clauses[i] = quoteColumn(field.Name) + " " + TDTPToOracle(field)
+	}
+	alterSQL := fmt.Sprintf("ALTER TABLE %s ADD (%s)", a.quoteTable(tableName), strings.Join(clauses, ", "))
+	if _, err := a.db.ExecContext(ctx, alterSQL); err != nil {
+		return fmt.Errorf("failed to add columns: %w", err)
+	}
+	return nil
+}
+
+// UpdateStatistics собирает статистику таблицы (DBMS_STATS.GATHER_TABLE_STATS).
+// Реализует adapters.StatisticsUpdater.
+func (a *Adapter) UpdateStatistics(ctx context.Context, tableName string) error {
+	owner, table := a.splitName(tableName)
+	_, err := a.db.ExecContext(ctx,
+		`BEGIN DBMS_STATS.GATHER_TABLE_STATS(ownname => :1, tabname => :2); END;`, owner, table)
+	if err != nil {
+		return fmt.Errorf("failed to gather statistics: %w", err)
+	}
+	return nil
+}
+
+// ========== base.DataInserter interface ==========
+
+// InsertRows вставляет строки с учетом strategy одной транзакцией,
+// подготовленным запросом на строку. В транзакции импорта (base.ImportTx,
+// StrategyTruncate) строки пишутся в неё, фиксирует её ImportHelper.
+//
+//	replace — MERGE: обновление по ключу или вставка
+//	merge   — MERGE с обновлением только колонок base.UpdateFields
+//	ignore  — MERGE только с WHEN NOT MATCHED: существующие строки не меняются
+//	fail    — INSERT: дубликат ключа — ошибка (ORA-00001)
+//	append, truncate — INSERT без обработки конфликтов
+//
+// Без ключевых полей replace, merge и ignore выполняют обычную вставку.
+func (a *Adapter) InsertRows(ctx context.Context, tableName string, schema packet.Schema, rows []packet.Row, strategy adapters.ImportStrategy) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	// Виртуальные и read-only колонки вставлять нельзя
+	schema, keep := insertableFields(schema)
+	if len(schema.Fields) == 0 {
+		return nil
+	}
+
+	var query string
+	switch strategy {
+	case adapters.StrategyReplace, adapters.StrategyMerge, adapters.StrategyIgnore:
+		updates, err := base.UpdateFields(ctx, schema, strategy)
+		if err != nil {
+			return err
+		}
+		query = buildMergeSQL(a.quoteTable(tableName), schema, updates)
+	case adapters.StrategyFail, adapters.StrategyCopy, adapters.StrategyAppend, adapters.StrategyTruncate:
+		query = buildInsertSQL(a.quoteTable(tableName), schema)
+	default:
+		return fmt.Errorf("unsupported import strategy: %v", strategy)
+	}
+
+	if t, ok := base.ImportTx(ctx).(*oracleTx); ok {
+		return a.insertRowsTx(ctx, t.tx, query, schema, keep, rows)
+	}
+	tx, err := a.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := a.insertRowsTx(ctx, tx, query, schema, keep, rows); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// insertRowsTx выполняет query для каждой строки в транзакции tx.
+func (a *Adapter) insertRowsTx(ctx context.Context, tx *sql.Tx, query string, schema packet.Schema, keep []int, rows []packet.Row) error {
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer func() { _ = stmt.Close() }()
+
+	for i, row := range rows {
+		rowValues := base.ParseRowValues(row)
+		if keep != nil {
+			rowValues = projectValues(rowValues, keep)
+		}
+		args, err := base.ConvertRowToSQLValues(rowValues, schema, a.converter, AdapterType)
+		if err != nil {
+			return fmt.Errorf("row %d: failed to convert values: %w", i+1, err)
+		}
+		if _, err := stmt.ExecContext(ctx, args...); err != nil {
+			return fmt.Errorf("row %d: failed to insert: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// insertableFields убирает из схемы колонки, которые нельзя вставлять:
+// read-only (виртуальные колонки Oracle, rowversion MSSQL) и вычисляемые.
+// keep — индексы оставшихся полей; nil, если убирать нечего.
+func insertableFields(schema packet.Schema) (packet.Schema, []int) {
+	var keep []int
+	for i, field := range schema.Fields {
+		if !field.ReadOnly && field.Generated == nil {
+			keep = append(keep, i)
+		}
+	}
+	if len(keep) == len(schema.Fields) {
+		return schema, nil
+	}
+
+	fields := make([]packet.Field, len(keep))
+	for i, idx := range keep {
+		fields[i] = schema.Fields[idx]
+	}
+	return packet.Schema{Fields: fields}, keep
+}
+
+// projectValues оставляет значения строки по индексам keep.
+func projectValues(values []string, keep []int) []string {
+	projected := make([]string, len(keep))
+	for i, idx := range keep {
+		if idx < len(values) {
+			projected[i] = values[idx]
+		}
+	}
+	return projected
+}
+
+// isJSONField — поле с JSON содержимым (JSON Oracle/MySQL, json/jsonb PostgreSQL)
+func isJSONField(field packet.Field) bool {
+	return field.Subtype == SubtypeJSON || field.Subtype == "jsonb"
+}
+
+// ========== Oracle-специфичные SQL builders ==========
+
+// buildInsertSQL возвращает INSERT INTO t ("A", "B") VALUES (:1, :2)
+func buildInsertSQL(quotedTable string, schema packet.Schema) string {
+	columns := make([]string, len(schema.Fields))
+	placeholders := make([]string, len(schema.Fields))
+	for i, field := range schema.Fields {
+		columns[i] = quoteColumn(field.Name)
+		placeholders[i] = fmt.Sprintf(":%d", i+1)
+	}
+	return fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)",
+		quotedTable, strings.Join(columns, ", "), strings.Join(placeholders, ", "))
+}
+
+// buildMergeSQL возвращает MERGE по ключевым полям схемы:
+//
+//	MERGE INTO t USING (SELECT :1 "ID", :2 "NAME" FROM dual) s ON (t."ID" = s."ID")
+//	WHEN MATCHED THEN UPDATE SET t."NAME" = s."NAME"          -- колонки updates
+//	WHEN NOT MATCHED THEN INSERT ("ID", "NAME") VALUES (s."ID", s."NAME")
+//
+// updates — колонки base.UpdateFields (nil — ignore, без WHEN MATCHED).
+// Без ключевых полей — обычный INSERT.
+func buildMergeSQL(quotedTable string, schema packet.Schema, updates []string) string {
+	var source, on, sets, columns, values []string
+	for i, field := range schema.Fields {
+		name := quoteColumn(field.Name)
+		source = append(source, fmt.Sprintf(":%d %s", i+1, name))
+		columns = append(columns, name)
+		values = append(values, "s."+name)
+		if field.Key {
+			on = append(on, fmt.Sprintf("t.%s = s.%s", name, name))
+		}
+	}
+	for _, u := range updates {
+		name := quoteColumn(u)
+		sets = append(sets, fmt.Sprintf("t.%s = s.%s", name, name))
+	}
+	if len(on) == 0 {
+		return buildInsertSQL(quotedTable, schema)
+	}
+
+	query := fmt.Sprintf("MERGE INTO %s t USING (SELECT %s FROM dual) s ON (%s)",
+		quotedTable, strings.Join(source, ", "), strings.Join(on, " AND "))
+	if len(sets) > 0 {
+		query += " WHEN MATCHED THEN UPDATE SET " + strings.Join(sets, ", ")
+	}
+	query += fmt.Sprintf(" WHEN NOT MATCHED THEN INSERT (%s) VALUES (%s)",
+		strings.Join(columns, ", "), strings.Join(values, ", "))
+	return query
+}
@@ -0,0 +1,220 @@
+package oracle
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+	"github.com/ruslano69/tdtp-framework/pkg/adapters/base"
+)
+
+// InspectTable returns extended metadata for a live Oracle table or view.
+// Implements adapters.Adapter.
+func (a *Adapter) InspectTable(ctx context.Context, tableName string) (*adapters.TableReport, error) {
+	owner, table := a.splitName(tableName)
+
+	dbVersion, err := a.GetDatabaseVersion(ctx)
+	if err != nil {
+		dbVersion = "Oracle (unknown version)"
+	}
+
+	report := &adapters.TableReport{
+		Table:     table,
+		DBType:    AdapterType,
+		DBVersion: dbVersion,
+		Schema:    owner,
+	}
+
+	// ---- Primary key columns ----
+	pkCols, err := a.getPrimaryKeyColumns(ctx, owner, table)
+	if err != nil {
+		pkCols = nil // non-fatal
+	}
+	pkSet := make(map[string]bool, len(pkCols))
+	for _, pk := range pkCols {
+		pkSet[pk] = true
+	}
+
+	// ---- Columns from ALL_TAB_COLS ----
+	// IDENTITY_COLUMN появился в 12c: словарь зависит от сервера, не от режима
+	identityExpr := "'NO'"
+	if a.serverVersion >= CompatOracle12c {
+		identityExpr = "IDENTITY_COLUMN"
+	}
+	colQuery := fmt.Sprintf(`
+		SELECT
+			COLUMN_NAME,
+			DATA_TYPE,
+			CHAR_LENGTH,
+			DATA_PRECISION,
+			DATA_SCALE,
+			NULLABLE,
+			VIRTUAL_COLUMN,
+			%s
+		FROM ALL_TAB_COLS
+		WHERE OWNER = :1 AND TABLE_NAME = :2 AND HIDDEN_COLUMN = 'NO'
+		ORDER BY COLUMN_ID
+	`, identityExpr)
+
+	rows, err := a.db.QueryContext(ctx, colQuery, owner, table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query columns: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var (
+			col                         ColumnInfo
+			charLen                     sql.NullInt64
+			nullable, virtual, identity string
+		)
+		if err := rows.Scan(&col.Name, &col.DataType, &charLen, &col.Precision, &col.Scale,
+			&nullable, &virtual, &identity); err != nil {
+			return nil, fmt.Errorf("scan column: %w", err)
+		}
+		col.CharLength = int(charLen.Int64)
+
+		tdtpField := BuildFieldFromColumn(col, pkSet[col.Name])
+		tdtpType := tdtpField.Type
+		if tdtpField.Subtype != "" {
+			tdtpType += "(" + tdtpField.Subtype + ")"
+		}
+
+		report.Columns = append(report.Columns, adapters.ColumnReport{
+			Name:       col.Name,
+			NativeType: nativeType(col),
+			TDTPType:   tdtpType,
+			Nullable:   nullable == "Y",
+			PrimaryKey: pkSet[col.Name],
+			Identity:   identity == "YES",
+			Computed:   virtual == "YES",
+			Length:     tdtpField.Length,
+			Precision:  int(col.Precision.Int64),
+			Scale:      int(col.Scale.Int64),
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate columns: %w", err)
+	}
+	if len(report.Columns) == 0 {
+		return nil, fmt.Errorf("table %s.%s %w", owner, table, adapters.ErrTableNotFound)
+	}
+
+	// ---- Foreign keys: колонка → колонка ключа, на который ссылается ограничение ----
+	fkQuery := `
+		SELECT
+			cc.COLUMN_NAME,
+			rc.TABLE_NAME,
+			rcc.COLUMN_NAME,
+			c.DELETE_RULE
+		FROM ALL_CONSTRAINTS c
+		JOIN ALL_CONS_COLUMNS cc
+			ON cc.OWNER = c.OWNER AND cc.CONSTRAINT_NAME = c.CONSTRAINT_NAME
+		JOIN ALL_CONSTRAINTS rc
+			ON rc.OWNER = c.R_OWNER AND rc.CONSTRAINT_NAME = c.R_CONSTRAINT_NAME
+		JOIN ALL_CONS_COLUMNS rcc
+			ON rcc.OWNER = rc.OWNER AND rcc.CONSTRAINT_NAME = rc.CONSTRAINT_NAME
+			AND rcc.POSITION = cc.POSITION
+		WHERE c.OWNER = :1 AND c.TABLE_NAME = :2 AND c.CONSTRAINT_TYPE = 'R'
+		ORDER BY c.CONSTRAINT_NAME, cc.POSITION
+	`
+	fkRows, err := a.db.QueryContext(ctx, fkQuery, owner, table)
+	if err == nil {
+		defer func() { _ = fkRows.Close() }()
+		for fkRows.Next() {
+			var col, refTable, refCol, onDelete string
+			if err := fkRows.Scan(&col, &refTable, &refCol, &onDelete); err != nil {
+				continue
+			}
+			report.ForeignKeys = append(report.ForeignKeys, adapters.ForeignKeyReport{
+				Column:           col,
+				ReferencesTable:  refTable,
+				ReferencesColumn: refCol,
+				OnDelete:         onDelete,
+			})
+		}
+	}
+
+	// ---- Row count ----
+	totalRows, _ := a.GetRowCount(ctx, tableName)
+	report.Stats.TotalRows = totalRows
+
+	// ---- Sample: last row by pk (ROWNUM работает во всех версиях) ----
+	if totalRows > 0 && len(pkCols) > 0 {
+		sampleQuery := fmt.Sprintf("SELECT * FROM (SELECT * FROM %s ORDER BY %s DESC) WHERE ROWNUM = 1",
+			a.quoteTable(tableName), base.QuoteOracleIdentifier(pkCols[0]))
+		sampleRows, err := a.db.QueryContext(ctx, sampleQuery)
+		if err == nil {
+			defer func() { _ = sampleRows.Close() }()
+			cols, _ := sampleRows.Columns()
+			if sampleRows.Next() {
+				values := make([]any, len(cols))
+				valuePtrs := make([]any, len(cols))
+				for i := range values {
+					valuePtrs[i] = &values[i]
+				}
+				if err := sampleRows.Scan(valuePtrs...); err == nil {
+					sample := make(map[string]string, len(cols))
+					for i, c := range cols {
+						if values[i] == nil {
+							sample[c] = "NULL"
+						} else {
+							sample[c] = fmt.Sprintf("%v", values[i])
+						}
+					}
+					report.Sample = sample
+				}
+			}
+		}
+	}
+
+	return report, nil
+}
+
+// getPrimaryKeyColumns returns the list of primary key column names for a table.
+func (a *Adapter) getPrimaryKeyColumns(ctx context.Context, owner, table string) ([]string, error) {
+	query := `
+		SELECT cc.COLUMN_NAME
+		FROM ALL_CONSTRAINTS c
+		JOIN ALL_CONS_COLUMNS cc
+			ON cc.OWNER = c.OWNER AND cc.CONSTRAINT_NAME = c.CONSTRAINT_NAME
+		WHERE c.OWNER = :1 AND c.TABLE_NAME = :2 AND c.CONSTRAINT_TYPE = 'P'
+		ORDER BY cc.POSITION
+	`
+	rows, err := a.db.QueryContext(ctx, query, owner, table)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var cols []string
+	for rows.Next() {
+		var col string
+		if err := rows.Scan(&col); err != nil {
+			return nil, err
+		}
+		cols = append(cols, col)
+	}
+	return cols, rows.Err()
+}
+
+// nativeType восстанавливает объявление типа колонки: NUMBER(10,2), VARCHAR2(100)
+func nativeType(col ColumnInfo) string {
+	switch col.DataType {
+	case "NUMBER":
+		switch {
+		case col.Precision.Valid && col.Scale.Valid && col.Scale.Int64 != 0:
+			return fmt.Sprintf("NUMBER(%d,%d)", col.Precision.Int64, col.Scale.Int64)
+		case col.Precision.Valid:
+			return fmt.Sprintf("NUMBER(%d)", col.Precision.Int64)
+		case col.Scale.Valid:
+			return fmt.Sprintf("NUMBER(*,%d)", col.Scale.Int64)
+		}
+	case "VARCHAR2", "NVARCHAR2", "CHAR", "NCHAR":
+		if col.CharLength > 0 {
+			return fmt.Sprintf("%s(%d)", col.DataType, col.CharLength)
+		}
+	}
+	return col.DataType
+}
@@ -0,0 +1,159 @@
+package oracle
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+// Subtype-ы TEXT/REAL полей
+const (
+	SubtypeJSON        = "json"        // JSON (21c) или CLOB с IS JSON
+	SubtypeTimestampTZ = "timestamptz" // TIMESTAMP WITH [LOCAL] TIME ZONE (как у PostgreSQL)
+	SubtypeNumber      = "number"      // NUMBER без точности и масштаба
+)
+
+// maxVarchar2 — максимальная длина VARCHAR2 при MAX_STRING_SIZE = STANDARD
+const maxVarchar2 = 4000
+
+// ColumnInfo — колонка из ALL_TAB_COLS
+type ColumnInfo struct {
+	Name       string
+	DataType   string        // DATA_TYPE: "NUMBER", "VARCHAR2", "TIMESTAMP(6) WITH TIME ZONE"
+	CharLength int           // CHAR_LENGTH (длина в символах для строк)
+	Precision  sql.NullInt64 // DATA_PRECISION
+	Scale      sql.NullInt64 // DATA_SCALE
+}
+
+// BuildFieldFromColumn создает packet.Field из колонки Oracle
+func BuildFieldFromColumn(col ColumnInfo, isPrimaryKey bool) packet.Field {
+	field := packet.Field{
+		Name: col.Name,
+		Key:  isPrimaryKey,
+	}
+
+	dataType := strings.ToUpper(strings.TrimSpace(col.DataType))
+	switch {
+	case dataType == "NUMBER":
+		precision, scale := int(col.Precision.Int64), int(col.Scale.Int64)
+		switch {
+		case !col.Precision.Valid && !col.Scale.Valid:
+			// NUMBER без ограничений: целое или дробное — неизвестно
+			field.Type = "REAL"
+			field.Subtype = SubtypeNumber
+		case scale == 0 && (!col.Precision.Valid || precision <= 18):
+			// NUMBER(p) и INTEGER (NUMBER(*,0)): помещается в int64
+			field.Type = "INTEGER"
+			field.Length = precision
+		default:
+			field.Type = "DECIMAL"
+			field.Precision = precision
+			if !col.Precision.Valid {
+				field.Precision = 38
+			}
+			field.Scale = scale
+		}
+
+	case dataType == "FLOAT", dataType == "BINARY_DOUBLE":
+		field.Type = "DOUBLE"
+
+	case dataType == "BINARY_FLOAT":
+		field.Type = "REAL"
+
+	case dataType == "VARCHAR2", dataType == "NVARCHAR2", dataType == "CHAR", dataType == "NCHAR":
+		field.Type = "TEXT"
+		field.Length = col.CharLength
+
+	case dataType == "CLOB", dataType == "NCLOB", dataType == "LONG":
+		field.Type = "TEXT"
+
+	case dataType == "JSON":
+		field.Type = "TEXT"
+		field.Subtype = SubtypeJSON
+
+	case dataType == "RAW", dataType == "LONG RAW", dataType == "BLOB":
+		field.Type = "BLOB"
+
+	case dataType == "DATE":
+		// DATE в Oracle хранит и время (до секунд)
+		field.Type = "DATETIME"
+
+	case strings.HasPrefix(dataType, "TIMESTAMP"):
+		field.Type = "TIMESTAMP"
+		if strings.Contains(dataType, "TIME ZONE") {
+			field.Subtype = SubtypeTimestampTZ
+		}
+
+	default:
+		// ROWID, UROWID, INTERVAL, XMLTYPE и прочие — строкой
+		field.Type = "TEXT"
+	}
+
+	return field
+}
+
+// TDTPToOracle конвертирует TDTP тип в тип Oracle для CREATE TABLE
+func TDTPToOracle(field packet.Field) string {
+	switch field.Subtype {
+	case SubtypeJSON, "jsonb":
+		return "CLOB" // IS JSON добавляется ограничением (12c+)
+	case SubtypeTimestampTZ:
+		return "TIMESTAMP WITH TIME ZONE"
+	case SubtypeNumber:
+		return "NUMBER"
+	}
+
+	switch strings.ToUpper(field.Type) {
+	case "INTEGER", "INT":
+		if field.Length > 0 && field.Length < 19 {
+			return fmt.Sprintf("NUMBER(%d)", field.Length)
+		}
+		return "NUMBER(19)" // весь диапазон int64
+
+	case "REAL", "FLOAT", "DOUBLE":
+		return "BINARY_DOUBLE"
+
+	case "DECIMAL":
+		if field.Precision == 0 {
+			return "NUMBER"
+		}
+		return fmt.Sprintf("NUMBER(%d,%d)", min(field.Precision, 38), field.Scale)
+
+	case "TEXT", "VARCHAR", "STRING":
+		switch {
+		case field.Length > 0 && field.Length <= maxVarchar2:
+			return fmt.Sprintf("VARCHAR2(%d CHAR)", field.Length)
+		case field.Length == 0 && field.Key:
+			return "VARCHAR2(255 CHAR)" // CLOB не может быть ключом
+		default:
+			return "CLOB"
+		}
+
+	case "CHAR":
+		length := field.Length
+		if length == 0 {
+			length = 1
+		}
+		if length > 2000 {
+			return fmt.Sprintf("VARCHAR2(%d CHAR)", min(length, maxVarchar2))
+		}
+		return fmt.Sprintf("CHAR(%d CHAR)", length)
+
+	case "BOOLEAN", "BOOL":
+		return "NUMBER(1)"
+
+	case "DATE":
+		return "DATE"
+
+	case "DATETIME", "TIMESTAMP":
+		return "TIMESTAMP"
+
+	case "BLOB":
+		return "BLOB"
+
+	default:
+		return "CLOB"
+	}
+}
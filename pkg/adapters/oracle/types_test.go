@@ -0,0 +1,170 @@
+package oracle
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+func num(v int64) sql.NullInt64 { return sql.NullInt64{Int64: v, Valid: true} }
+
+func TestBuildFieldFromColumn(t *testing.T) {
+	tests := []struct {
+		col                 ColumnInfo
+		typ, subtype        string
+		length, prec, scale int
+	}{
+		{ColumnInfo{DataType: "NUMBER", Precision: num(10), Scale: num(0)}, "INTEGER", "", 10, 0, 0},
+		{ColumnInfo{DataType: "NUMBER", Scale: num(0)}, "INTEGER", "", 0, 0, 0}, // INTEGER
+		{ColumnInfo{DataType: "NUMBER", Precision: num(20), Scale: num(0)}, "DECIMAL", "", 0, 20, 0},
+		{ColumnInfo{DataType: "NUMBER", Precision: num(12), Scale: num(2)}, "DECIMAL", "", 0, 12, 2},
+		{ColumnInfo{DataType: "NUMBER"}, "REAL", SubtypeNumber, 0, 0, 0},
+		{ColumnInfo{DataType: "BINARY_DOUBLE"}, "DOUBLE", "", 0, 0, 0},
+		{ColumnInfo{DataType: "VARCHAR2", CharLength: 100}, "TEXT", "", 100, 0, 0},
+		{ColumnInfo{DataType: "NCLOB"}, "TEXT", "", 0, 0, 0},
+		{ColumnInfo{DataType: "JSON"}, "TEXT", SubtypeJSON, 0, 0, 0},
+		{ColumnInfo{DataType: "RAW"}, "BLOB", "", 0, 0, 0},
+		{ColumnInfo{DataType: "DATE"}, "DATETIME", "", 0, 0, 0},
+		{ColumnInfo{DataType: "TIMESTAMP(6)"}, "TIMESTAMP", "", 0, 0, 0},
+		{ColumnInfo{DataType: "TIMESTAMP(6) WITH LOCAL TIME ZONE"}, "TIMESTAMP", SubtypeTimestampTZ, 0, 0, 0},
+		{ColumnInfo{DataType: "INTERVAL DAY(2) TO SECOND(6)"}, "TEXT", "", 0, 0, 0},
+	}
+	for _, tt := range tests {
+		f := BuildFieldFromColumn(tt.col, false)
+		if f.Type != tt.typ || f.Subtype != tt.subtype || f.Length != tt.length || f.Precision != tt.prec || f.Scale != tt.scale {
+			t.Errorf("%s(%v,%v) = %s/%s len=%d %d,%d, want %s/%s len=%d %d,%d",
+				tt.col.DataType, tt.col.Precision, tt.col.Scale, f.Type, f.Subtype, f.Length, f.Precision, f.Scale,
+				tt.typ, tt.subtype, tt.length, tt.prec, tt.scale)
+		}
+	}
+}
+
+func TestDriverColumnType(t *testing.T) {
+	tests := map[string]string{
+		"NUMBER":           "NUMBER",
+		"NCHAR":            "VARCHAR2",
+		"OCIClobLocator":   "CLOB",
+		"OCIBlobLocator":   "BLOB",
+		"TimeStampDTY":     "TIMESTAMP",
+		"TimeStampTZ_DTY":  "TIMESTAMP WITH TIME ZONE",
+		"TimeStampLTZ_DTY": "TIMESTAMP WITH TIME ZONE",
+		"IBDouble":         "BINARY_DOUBLE",
+		"LongRaw":          "RAW",
+		"DATE":             "DATE",
+		"ROWID":            "ROWID",
+	}
+	for name, want := range tests {
+		if got := driverColumnType(name); got != want {
+			t.Errorf("driverColumnType(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestTDTPToOracle(t *testing.T) {
+	tests := []struct {
+		field packet.Field
+		want  string
+	}{
+		{packet.Field{Type: "INTEGER"}, "NUMBER(19)"},
+		{packet.Field{Type: "INTEGER", Length: 10}, "NUMBER(10)"},
+		{packet.Field{Type: "DECIMAL", Precision: 12, Scale: 2}, "NUMBER(12,2)"},
+		{packet.Field{Type: "DECIMAL"}, "NUMBER"},
+		{packet.Field{Type: "REAL", Subtype: SubtypeNumber}, "NUMBER"},
+		{packet.Field{Type: "TEXT", Length: 100}, "VARCHAR2(100 CHAR)"},
+		{packet.Field{Type: "TEXT"}, "CLOB"},
+		{packet.Field{Type: "TEXT", Key: true}, "VARCHAR2(255 CHAR)"},
+		{packet.Field{Type: "TEXT", Length: 5000}, "CLOB"},
+		{packet.Field{Type: "TEXT", Subtype: "jsonb"}, "CLOB"},
+		{packet.Field{Type: "BOOLEAN"}, "NUMBER(1)"},
+		{packet.Field{Type: "DATETIME"}, "TIMESTAMP"},
+		{packet.Field{Type: "TIMESTAMP", Subtype: SubtypeTimestampTZ}, "TIMESTAMP WITH TIME ZONE"},
+		{packet.Field{Type: "BLOB"}, "BLOB"},
+	}
+	for _, tt := range tests {
+		if got := TDTPToOracle(tt.field); got != tt.want {
+			t.Errorf("TDTPToOracle(%s/%s len=%d) = %s, want %s", tt.field.Type, tt.field.Subtype, tt.field.Length, got, tt.want)
+		}
+	}
+}
+
+func TestBuildMergeSQL(t *testing.T) {
+	schema := packet.Schema{Fields: []packet.Field{{Name: "id", Key: true}, {Name: "Name"}}}
+
+	want := `MERGE INTO "HR"."USERS" t USING (SELECT :1 "ID", :2 "Name" FROM dual) s ON (t."ID" = s."ID")` +
+		` WHEN MATCHED THEN UPDATE SET t."Name" = s."Name"` +
+		` WHEN NOT MATCHED THEN INSERT ("ID", "Name") VALUES (s."ID", s."Name")`
+	if got := buildMergeSQL(`"HR"."USERS"`, schema, []string{"Name"}); got != want {
+		t.Errorf("replace:\n got %s\nwant %s", got, want)
+	}
+
+	want = `MERGE INTO "HR"."USERS" t USING (SELECT :1 "ID", :2 "Name" FROM dual) s ON (t."ID" = s."ID")` +
+		` WHEN NOT MATCHED THEN INSERT ("ID", "Name") VALUES (s."ID", s."Name")`
+	if got := buildMergeSQL(`"HR"."USERS"`, schema, nil); got != want {
+		t.Errorf("ignore:\n got %s\nwant %s", got, want)
+	}
+
+	noKeys := packet.Schema{Fields: []packet.Field{{Name: "a"}, {Name: "b"}}}
+	want = `INSERT INTO "T" ("A", "B") VALUES (:1, :2)`
+	if got := buildMergeSQL(`"T"`, noKeys, []string{"a", "b"}); got != want {
+		t.Errorf("no keys:\n got %s\nwant %s", got, want)
+	}
+}
+
+func TestGenerateDDL(t *testing.T) {
+	schema := packet.Schema{Fields: []packet.Field{
+		{Name: "id", Type: "INTEGER", Length: 10, Key: true},
+		{Name: "doc", Type: "TEXT", Subtype: SubtypeJSON},
+	}}
+
+	a := &Adapter{owner: "HR", effectiveCompat: CompatOracle19c}
+	want := `CREATE TABLE "HR"."ORDERS" ("ID" NUMBER(10) NOT NULL, "DOC" CLOB CHECK ("DOC" IS JSON), PRIMARY KEY ("ID"))`
+	if got := a.GenerateDDL("orders", schema); len(got) != 1 || got[0] != want {
+		t.Errorf("19c:\n got %v\nwant %s", got, want)
+	}
+
+	// Без подключения: владелец и режим неизвестны
+	want = `CREATE TABLE "ORDERS" ("ID" NUMBER(10) NOT NULL, "DOC" CLOB, PRIMARY KEY ("ID"))`
+	if got := (&Adapter{}).GenerateDDL("orders", schema); len(got) != 1 || got[0] != want {
+		t.Errorf("offline:\n got %v\nwant %s", got, want)
+	}
+}
+
+func TestCompatibilityMode(t *testing.T) {
+	tests := []struct {
+		server      int
+		mode        string
+		strict      bool
+		wantCompat  int
+		wantErr     bool
+		offsetFetch bool
+		longIdents  bool
+	}{
+		{19, "auto", false, 19, false, true, true},
+		{19, "11g", false, CompatOracle11g, false, false, false},
+		{19, "12c", false, CompatOracle12c, false, true, false},
+		{11, "19c", false, 11, false, false, false},
+		{11, "19c", true, 0, true, false, false},
+		{19, "10g", false, 0, true, false, false},
+	}
+	for _, tt := range tests {
+		a := &Adapter{serverVersion: tt.server, effectiveCompat: tt.server, strictMode: tt.strict, config: adapters.Config{}}
+		err := a.applyCompatibilityMode(tt.mode)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("server %d mode %s: err = %v, wantErr %v", tt.server, tt.mode, err, tt.wantErr)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+		if a.effectiveCompat != tt.wantCompat || a.SupportsOffsetFetch() != tt.offsetFetch || a.SupportsLongIdentifiers() != tt.longIdents {
+			t.Errorf("server %d mode %s: compat %d offsetFetch %v longIdents %v", tt.server, tt.mode,
+				a.effectiveCompat, a.SupportsOffsetFetch(), a.SupportsLongIdentifiers())
+		}
+	}
+
+	if v := parseServerVersion("11.2.0.4.0"); v != 11 {
+		t.Errorf("parseServerVersion = %d, want 11", v)
+	}
+}
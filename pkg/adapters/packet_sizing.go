@@ -0,0 +1,53 @@
+package adapters
+
+import (
+	"fmt"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+// PacketSizing — как экспорт режет строки на пакеты (части). Нулевое
+// значение — прежнее поведение: ~3.8MB оценки UTF-16 (~1.9MB XML), под
+// лимит сообщения MSMQ. Kafka (1MB на сообщение по умолчанию) — например
+// {MaxBytes: 900_000, Estimate: packet.SizeEstimateXML}; файлы — десятки
+// и сотни мегабайт на пакет.
+type PacketSizing struct {
+	// MaxBytes — предел размера части в единицах Estimate (0 —
+	// packet.DefaultMaxMessageSize).
+	MaxBytes int
+
+	// MaxRows — предел строк в части (0 — без лимита). Часть закрывается по
+	// первому из пределов.
+	MaxRows int
+
+	// Estimate — оценка размера: packet.SizeEstimateUTF16 (по умолчанию,
+	// длина значений ×2) или packet.SizeEstimateXML (байты несжатого XML —
+	// MaxBytes соответствует реальному размеру пакета).
+	Estimate string
+}
+
+// IsZero — размеры по умолчанию.
+func (s PacketSizing) IsZero() bool {
+	return s == PacketSizing{}
+}
+
+// Validate проверяет пределы и режим оценки.
+func (s PacketSizing) Validate() error {
+	if s.MaxBytes < 0 || s.MaxRows < 0 {
+		return fmt.Errorf("invalid packet sizing: max_bytes and max_rows must be >= 0")
+	}
+	switch s.Estimate {
+	case "", packet.SizeEstimateUTF16, packet.SizeEstimateXML:
+		return nil
+	}
+	return fmt.Errorf("invalid packet size estimate %q (expected utf16 or xml)", s.Estimate)
+}
+
+// Apply настраивает генератор пакетов.
+func (s PacketSizing) Apply(g *packet.Generator) error {
+	if s.MaxBytes > 0 {
+		g.SetMaxMessageSize(s.MaxBytes)
+	}
+	g.SetMaxRowsPerPacket(s.MaxRows)
+	return g.SetSizeEstimate(s.Estimate)
+}
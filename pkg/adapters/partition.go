@@ -0,0 +1,288 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+// ========== Маршрутизация по таблицам-партициям ==========
+
+// PartitionInterval — гранулярность таблиц-партиций.
+type PartitionInterval string
+
+const (
+	// PartitionDaily — таблица на день: events_2025_06_01
+	PartitionDaily PartitionInterval = "day"
+	// PartitionMonthly — таблица на месяц: events_2025_06
+	PartitionMonthly PartitionInterval = "month"
+)
+
+// PartitionRouting направляет строки пакета в таблицы-партиции по значению
+// колонки даты/времени. Имя партиции строится по шаблону с подстановками
+// {table}, {yyyy}, {mm}, {dd}.
+type PartitionRouting struct {
+	Column   string            // колонка даты/времени
+	Interval PartitionInterval // day | month (по умолчанию day)
+	Pattern  string            // пусто — {table}_{yyyy}_{mm}_{dd} (day) / {table}_{yyyy}_{mm} (month)
+
+	// AutoCreate — создавать недостающие партиции (PartitionManager адаптера).
+	// Без него импорт в несуществующую партицию — ошибка: иначе адаптер
+	// молча создал бы обычную таблицу по схеме пакета.
+	AutoCreate bool
+}
+
+// PartitionManager — адаптер, умеющий создавать партиции (PostgreSQL, MySQL).
+type PartitionManager interface {
+	// EnsurePartition создаёт таблицу-партицию partitionTable для диапазона
+	// [from, to), если её нет. Структура берётся у parentTable, если она
+	// существует, иначе — из schema.
+	EnsurePartition(ctx context.Context, parentTable, partitionTable string, schema packet.Schema, from, to time.Time) error
+}
+
+// PartitionPacket — строки одной партиции.
+type PartitionPacket struct {
+	Table  string
+	From   time.Time
+	To     time.Time
+	Packet *packet.DataPacket
+}
+
+// partitionTimeFormats — форматы значений даты/времени в TDTP.
+var partitionTimeFormats = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// Validate проверяет правило маршрутизации.
+func (r PartitionRouting) Validate() error {
+	if r.Column == "" {
+		return fmt.Errorf("partition column is required")
+	}
+	switch r.Interval {
+	case "", PartitionDaily, PartitionMonthly:
+	default:
+		return fmt.Errorf("unknown partition interval %q (supported: day, month)", r.Interval)
+	}
+	if r.Pattern != "" && !strings.Contains(r.Pattern, "{yyyy}") {
+		return fmt.Errorf("partition pattern %q must contain {yyyy}", r.Pattern)
+	}
+	if r.interval() == PartitionDaily && r.Pattern != "" && !strings.Contains(r.Pattern, "{dd}") {
+		return fmt.Errorf("daily partition pattern %q must contain {dd}", r.Pattern)
+	}
+	return nil
+}
+
+func (r PartitionRouting) interval() PartitionInterval {
+	if r.Interval == "" {
+		return PartitionDaily
+	}
+	return r.Interval
+}
+
+// Bounds возвращает диапазон [from, to) партиции, содержащей t.
+func (r PartitionRouting) Bounds(t time.Time) (from, to time.Time) {
+	if r.interval() == PartitionMonthly {
+		from = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return from, from.AddDate(0, 1, 0)
+	}
+	from = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return from, from.AddDate(0, 0, 1)
+}
+
+// PartitionName возвращает имя партиции таблицы table для момента t.
+func (r PartitionRouting) PartitionName(table string, t time.Time) string {
+	pattern := r.Pattern
+	if pattern == "" {
+		pattern = "{table}_{yyyy}_{mm}_{dd}"
+		if r.interval() == PartitionMonthly {
+			pattern = "{table}_{yyyy}_{mm}"
+		}
+	}
+	return strings.NewReplacer(
+		"{table}", table,
+		"{yyyy}", fmt.Sprintf("%04d", t.Year()),
+		"{mm}", fmt.Sprintf("%02d", int(t.Month())),
+		"{dd}", fmt.Sprintf("%02d", t.Day()),
+	).Replace(pattern)
+}
+
+// SplitByPartition раскладывает строки пакета по партициям (в порядке имён).
+// Пакет должен быть распакован; значение колонки обязательно в каждой строке.
+func SplitByPartition(pkt *packet.DataPacket, r PartitionRouting) ([]PartitionPacket, error) {
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	pkt.MaterializeRows()
+	if pkt.Data.Delta {
+		return nil, fmt.Errorf("delta packets cannot be routed to partitions")
+	}
+	if pkt.Data.Compression != "" {
+		return nil, fmt.Errorf("packet must be decompressed before partition routing")
+	}
+
+	col := -1
+	for i, f := range pkt.Schema.Fields {
+		if strings.EqualFold(f.Name, r.Column) {
+			col = i
+			break
+		}
+	}
+	if col < 0 {
+		return nil, fmt.Errorf("partition column %s not found in schema of %s", r.Column, pkt.Header.TableName)
+	}
+	var nullMarker string
+	if sv := pkt.Schema.Fields[col].SpecialValues; sv != nil && sv.Null != nil {
+		nullMarker = sv.Null.Marker
+	}
+
+	parser := packet.NewParser()
+	groups := make(map[string]*PartitionPacket)
+	for i, row := range pkt.Data.Rows {
+		values := parser.GetRowValues(row)
+		if col >= len(values) || values[col] == "" || (nullMarker != "" && values[col] == nullMarker) {
+			return nil, fmt.Errorf("row %d: empty partition column %s", i, r.Column)
+		}
+		t, err := parsePartitionTime(values[col])
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", i, err)
+		}
+		name := r.PartitionName(pkt.Header.TableName, t)
+		g, ok := groups[name]
+		if !ok {
+			from, to := r.Bounds(t)
+			part := *pkt
+			part.Header.TableName = name
+			part.Data.Rows = nil
+			g = &PartitionPacket{Table: name, From: from, To: to, Packet: &part}
+			groups[name] = g
+		}
+		g.Packet.Data.Rows = append(g.Packet.Data.Rows, row)
+	}
+
+	result := make([]PartitionPacket, 0, len(groups))
+	for _, g := range groups {
+		g.Packet.Header.RecordsInPart = len(g.Packet.Data.Rows)
+		result = append(result, *g)
+	}
+	slices.SortFunc(result, func(a, b PartitionPacket) int { return strings.Compare(a.Table, b.Table) })
+	return result, nil
+}
+
+func parsePartitionTime(value string) (time.Time, error) {
+	for _, layout := range partitionTimeFormats {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid partition column value %q: expected date or RFC3339 timestamp", value)
+}
+
+// ImportPartitioned импортирует пакеты таблицы с маршрутизацией строк по
+// партициям (opts.Partition). Каждая партиция импортируется отдельным
+// ImportPackets: атомарность — в пределах партиции, не всего набора.
+func ImportPartitioned(ctx context.Context, a Adapter, packets []*packet.DataPacket, opts ImportOptions) error {
+	_, err := ImportPartitionedReport(ctx, a, packets, opts)
+	return err
+}
+
+// ImportPartitionedReport — ImportPartitioned с отчётом по партициям:
+// ReportingImporter импортирует каждую партицию ImportPacketsReport, и
+// строки, отклонённые политикой ошибок строк, попадают в отчёт. Для
+// остальных адаптеров отчёт nil.
+func ImportPartitionedReport(ctx context.Context, a Adapter, packets []*packet.DataPacket, opts ImportOptions) (*ImportReport, error) {
+	if opts.Partition == nil {
+		return nil, fmt.Errorf("partition routing is not configured")
+	}
+	r := *opts.Partition
+	if opts.Strategy == StrategyCopy {
+		return nil, fmt.Errorf("strategy copy replaces whole tables and cannot be combined with partition routing")
+	}
+	var report *ImportReport
+	reporting, canReport := a.(ReportingImporter)
+	if canReport {
+		report = &ImportReport{Strategy: opts.Strategy}
+	}
+
+	var manager PartitionManager
+	if r.AutoCreate {
+		m, ok := a.(PartitionManager)
+		if !ok {
+			return nil, fmt.Errorf("adapter %T cannot create partitions (supported: postgres, mysql)", a)
+		}
+		manager = m
+	}
+
+	var (
+		order  []string
+		groups = make(map[string][]PartitionPacket)
+	)
+	for _, pkt := range packets {
+		parts, err := SplitByPartition(pkt, r)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range parts {
+			if _, ok := groups[p.Table]; !ok {
+				order = append(order, p.Table)
+			}
+			groups[p.Table] = append(groups[p.Table], p)
+		}
+	}
+	slices.Sort(order)
+
+	for _, table := range order {
+		parts := groups[table]
+		first := parts[0]
+		if manager != nil {
+			parent := packets[0].Header.TableName
+			if err := manager.EnsurePartition(ctx, parent, table, first.Packet.Schema, first.From, first.To); err != nil {
+				return report, fmt.Errorf("failed to create partition %s: %w", table, err)
+			}
+		} else {
+			exists, err := a.TableExists(ctx, table)
+			if err != nil {
+				return report, err
+			}
+			if !exists {
+				return report, fmt.Errorf("partition %s does not exist (enable auto-create)", table)
+			}
+		}
+
+		pkts := make([]*packet.DataPacket, len(parts))
+		for i, p := range parts {
+			pkts[i] = p.Packet
+		}
+		var err error
+		if canReport {
+			var rep *ImportReport
+			rep, err = reporting.ImportPacketsReport(ctx, pkts, opts.Strategy)
+			if rep != nil {
+				report.Tables = append(report.Tables, rep.Tables...)
+			}
+		} else {
+			err = a.ImportPackets(ctx, pkts, opts.Strategy)
+		}
+		if err != nil {
+			return report, fmt.Errorf("partition %s: %w", table, err)
+		}
+		rows := 0
+		for _, pkt := range pkts {
+			rows += len(pkt.Data.Rows) // без строк, отклонённых политикой ошибок строк
+		}
+		// Данные уже записаны: ошибка статистики — только предупреждение
+		if opts.UpdateStatistics {
+			if _, err := UpdateStatistics(ctx, a, table); err != nil {
+				fmt.Printf("  ⚠ %v\n", err)
+			}
+		}
+		fmt.Printf("  📅 %s: %d row(s)\n", table, rows)
+	}
+	return report, nil
+}
@@ -0,0 +1,194 @@
+package adapters
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+// partitionAdapter — Adapter, записывающий импорт по таблицам (остальные методы не нужны).
+type partitionAdapter struct {
+	Adapter
+	existing map[string]bool
+	created  []string
+	imported map[string]int
+	analyzed []string
+}
+
+func (a *partitionAdapter) TableExists(_ context.Context, table string) (bool, error) {
+	return a.existing[table], nil
+}
+
+func (a *partitionAdapter) ImportPackets(_ context.Context, packets []*packet.DataPacket, _ ImportStrategy) error {
+	for _, p := range packets {
+		a.imported[p.Header.TableName] += len(p.Data.Rows)
+	}
+	return nil
+}
+
+func (a *partitionAdapter) EnsurePartition(_ context.Context, _, partition string, _ packet.Schema, _, _ time.Time) error {
+	a.created = append(a.created, partition)
+	a.existing[partition] = true
+	return nil
+}
+
+func (a *partitionAdapter) UpdateStatistics(_ context.Context, table string) error {
+	a.analyzed = append(a.analyzed, table)
+	return nil
+}
+
+// reportingPartitionAdapter отклоняет первую строку каждой партиции, как
+// политика ошибок строк.
+type reportingPartitionAdapter struct{ *partitionAdapter }
+
+func (a reportingPartitionAdapter) ImportPacketsReport(ctx context.Context, packets []*packet.DataPacket, strategy ImportStrategy) (*ImportReport, error) {
+	rep := &TableImportReport{Table: packets[0].Header.TableName, Packets: len(packets)}
+	packets[0].Data.Rows = packets[0].Data.Rows[1:]
+	rep.AddRowError(RowError{Packet: 1, Row: 1, Err: "bad value"})
+	for _, p := range packets {
+		rep.Rows += len(p.Data.Rows)
+	}
+	return &ImportReport{Strategy: strategy, Tables: []*TableImportReport{rep}}, a.ImportPackets(ctx, packets, strategy)
+}
+
+func eventsPacket(t *testing.T, times ...string) *packet.DataPacket {
+	t.Helper()
+	schema := packet.Schema{Fields: []packet.Field{
+		{Name: "id", Type: "INTEGER", Key: true},
+		{Name: "event_time", Type: "TIMESTAMP"},
+	}}
+	rows := make([][]string, len(times))
+	for i, ts := range times {
+		rows[i] = []string{string(rune('1' + i)), ts}
+	}
+	packets, err := packet.NewGenerator().GenerateReference("events", schema, rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return packets[0]
+}
+
+func TestPartitionRouting_Name(t *testing.T) {
+	ts := time.Date(2025, 6, 3, 15, 4, 5, 0, time.UTC)
+
+	daily := PartitionRouting{Column: "event_time"}
+	if got := daily.PartitionName("events", ts); got != "events_2025_06_03" {
+		t.Errorf("daily name = %s", got)
+	}
+	from, to := daily.Bounds(ts)
+	if !from.Equal(time.Date(2025, 6, 3, 0, 0, 0, 0, time.UTC)) || !to.Equal(from.AddDate(0, 0, 1)) {
+		t.Errorf("daily bounds = %v..%v", from, to)
+	}
+
+	monthly := PartitionRouting{Column: "event_time", Interval: PartitionMonthly, Pattern: "{table}_p{yyyy}{mm}"}
+	if got := monthly.PartitionName("events", ts); got != "events_p202506" {
+		t.Errorf("monthly name = %s", got)
+	}
+	if _, to := monthly.Bounds(ts); !to.Equal(time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("monthly upper bound = %v", to)
+	}
+
+	for _, bad := range []PartitionRouting{
+		{},
+		{Column: "c", Interval: "week"},
+		{Column: "c", Pattern: "{table}_{mm}"},
+		{Column: "c", Pattern: "{table}_{yyyy}_{mm}"}, // daily без {dd}
+	} {
+		if err := bad.Validate(); err == nil {
+			t.Errorf("expected validation error for %+v", bad)
+		}
+	}
+}
+
+func TestSplitByPartition(t *testing.T) {
+	pkt := eventsPacket(t, "2025-06-02T10:00:00Z", "2025-06-01 23:59:59", "2025-06-02")
+	parts, err := SplitByPartition(pkt, PartitionRouting{Column: "EVENT_TIME"})
+	if err != nil {
+		t.Fatalf("SplitByPartition: %v", err)
+	}
+	var names []string
+	for _, p := range parts {
+		names = append(names, p.Table)
+		if p.Packet.Header.RecordsInPart != len(p.Packet.Data.Rows) {
+			t.Errorf("%s: RecordsInPart %d, rows %d", p.Table, p.Packet.Header.RecordsInPart, len(p.Packet.Data.Rows))
+		}
+	}
+	if !slices.Equal(names, []string{"events_2025_06_01", "events_2025_06_02"}) || len(parts[1].Packet.Data.Rows) != 2 {
+		t.Errorf("unexpected partitions: %v", names)
+	}
+
+	if _, err := SplitByPartition(eventsPacket(t, "yesterday"), PartitionRouting{Column: "event_time"}); err == nil {
+		t.Error("expected error for unparsable value")
+	}
+	if _, err := SplitByPartition(eventsPacket(t, ""), PartitionRouting{Column: "event_time"}); err == nil {
+		t.Error("expected error for empty value")
+	}
+	if _, err := SplitByPartition(eventsPacket(t, "2025-06-01"), PartitionRouting{Column: "missing"}); err == nil {
+		t.Error("expected error for unknown column")
+	}
+}
+
+func TestImportPartitioned(t *testing.T) {
+	ctx := context.Background()
+	packets := []*packet.DataPacket{
+		eventsPacket(t, "2025-06-01T01:00:00Z", "2025-06-02T01:00:00Z"),
+		eventsPacket(t, "2025-06-02T02:00:00Z"),
+	}
+
+	a := &partitionAdapter{existing: map[string]bool{"events_2025_06_01": true}, imported: map[string]int{}}
+	routing := &PartitionRouting{Column: "event_time"}
+	if err := ImportPartitioned(ctx, a, packets, ImportOptions{Strategy: StrategyReplace, Partition: routing}); err == nil {
+		t.Fatal("expected error for missing partition without auto-create")
+	}
+
+	routing.AutoCreate = true
+	a.imported = map[string]int{}
+	if err := ImportPartitioned(ctx, a, packets, ImportOptions{Strategy: StrategyReplace, Partition: routing}); err != nil {
+		t.Fatalf("ImportPartitioned: %v", err)
+	}
+	if a.imported["events_2025_06_01"] != 1 || a.imported["events_2025_06_02"] != 2 {
+		t.Errorf("imported = %v", a.imported)
+	}
+	if !slices.Equal(a.created, []string{"events_2025_06_01", "events_2025_06_02"}) {
+		t.Errorf("created = %v", a.created)
+	}
+	if len(a.analyzed) != 0 {
+		t.Errorf("analyzed without UpdateStatistics: %v", a.analyzed)
+	}
+
+	if err := ImportPartitioned(ctx, a, packets, ImportOptions{Strategy: StrategyReplace, Partition: routing, UpdateStatistics: true}); err != nil {
+		t.Fatalf("ImportPartitioned with statistics: %v", err)
+	}
+	if !slices.Equal(a.analyzed, []string{"events_2025_06_01", "events_2025_06_02"}) {
+		t.Errorf("analyzed = %v", a.analyzed)
+	}
+
+	if err := ImportPartitioned(ctx, a, packets, ImportOptions{Strategy: StrategyCopy, Partition: routing}); err == nil {
+		t.Error("expected error for strategy copy")
+	}
+}
+
+// Отчёт ReportingImporter собирается по партициям.
+func TestImportPartitionedReport(t *testing.T) {
+	packets := []*packet.DataPacket{eventsPacket(t, "2025-06-01T01:00:00Z", "2025-06-02T01:00:00Z", "2025-06-02T03:00:00Z")}
+	a := reportingPartitionAdapter{&partitionAdapter{existing: map[string]bool{}, imported: map[string]int{}}}
+	routing := &PartitionRouting{Column: "event_time", AutoCreate: true}
+
+	report, err := ImportPartitionedReport(context.Background(), a, packets, ImportOptions{Strategy: StrategyReplace, Partition: routing})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Tables) != 2 || report.Tables[0].Table != "events_2025_06_01" || report.Tables[1].Rows != 1 || report.Tables[1].RowErrorCount != 1 {
+		t.Fatalf("report = %+v", report.Tables)
+	}
+	if a.imported["events_2025_06_01"] != 0 || a.imported["events_2025_06_02"] != 1 {
+		t.Errorf("imported = %v", a.imported)
+	}
+
+	if report, err := ImportPartitionedReport(context.Background(), a.partitionAdapter, packets, ImportOptions{Strategy: StrategyReplace, Partition: routing}); err != nil || report != nil {
+		t.Errorf("plain adapter: report %v, err %v", report, err)
+	}
+}
@@ -0,0 +1,421 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"os"
+	"os/exec"
+	"sync"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+// ErrNotConnected — вызов адаптера-плагина до Connect или после Close.
+var ErrNotConnected = errors.New("plugin adapter is not connected")
+
+// ErrProcessLost — связь с процессом адаптера потеряна: процесс упал,
+// нарушил протокол или завершён отменой вызова. Следующий вызов запускает
+// процесс заново, а если с процессом потеряна открытая транзакция — только
+// после её Commit/Rollback.
+var ErrProcessLost = errors.New("adapter process is gone")
+
+// Adapter — adapters.Adapter хоста, который выполняет вызовы в отдельном
+// процессе плагина (или изолированного адаптера, см. Isolate). Процесс
+// запускается в Connect и завершается в Close; упавший процесс
+// перезапускается и переподключается при следующем вызове, вызовы только
+// на чтение (Export*, схемы, Ping) повторяются один раз сразу. Импорт и
+// транзакции не повторяются: открытая транзакция теряется с процессом, и
+// до её Commit/Rollback все вызовы возвращают ErrProcessLost — работа
+// после потери не уходит молча в новый процесс вне транзакции.
+//
+// Прервать вызов внутри плагина нельзя, поэтому отмена контекста вызова
+// завершает процесс; значения контекста (WithIncludeReadOnlyFields
+// и т.п.) в плагин не передаются. Необязательные интерфейсы адаптеров
+// (SchemaLister, QueryLogged, ...) не поддерживаются.
+type Adapter struct {
+	cmd          command
+	info         Info
+	recycleAfter int // вызовов до плановой замены процесса (0 — без замены)
+
+	mu     sync.Mutex
+	cfg    *adapters.Config // nil — не подключён
+	proc   *process         // nil при cfg != nil — процесс упал, запуск при следующем вызове
+	calls  int              // вызовов текущего процесса
+	active int              // выполняющихся вызовов
+	inTx   bool             // открыта транзакция BeginTx
+	txLost bool             // процесс с открытой транзакцией потерян, ждём Commit/Rollback
+}
+
+// Compile-time interface check
+var _ adapters.Adapter = (*Adapter)(nil)
+
+// command — исполняемый файл процесса адаптера.
+type command struct {
+	path string
+	env  []string // добавляется к окружению хоста
+	name string   // в сообщениях об ошибках: "plugin <path>", "isolated adapter <type>"
+}
+
+// process — запущенный процесс адаптера.
+type process struct {
+	cmd    *exec.Cmd
+	client *rpc.Client
+}
+
+// newAdapter — конструктор фабрики для плагина path.
+func newAdapter(path string, info Info) adapters.AdapterConstructor {
+	return func() adapters.Adapter {
+		return &Adapter{cmd: command{path: path, name: "plugin " + path}, info: info}
+	}
+}
+
+// retryable — методы только на чтение: после перезапуска упавшего процесса
+// их безопасно повторить.
+var retryable = map[string]bool{
+	"Ping": true, "ExportTable": true, "ExportTableWithQuery": true, "ExportTableIncremental": true,
+	"GetTableSchema": true, "GetTableNames": true, "GetViewNames": true, "TableExists": true,
+	"GetDatabaseVersion": true, "InspectTable": true,
+}
+
+// start запускает процесс адаптера и проверяет версию протокола.
+func start(ctx context.Context, c command) (*exec.Cmd, *rpc.Client, Info, error) {
+	cmd := exec.Command(c.path)
+	if len(c.env) > 0 {
+		cmd.Env = append(os.Environ(), c.env...)
+	}
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, nil, Info{}, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, nil, Info{}, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, nil, Info{}, fmt.Errorf("failed to start %s: %w", c.name, err)
+	}
+
+	client := jsonrpc.NewClient(pipeConn{stdout, stdin})
+	var info Info
+	if err := call(ctx, client, c.name, "Info", Empty{}, &info); err != nil {
+		abort(cmd, client)
+		return nil, nil, Info{}, err
+	}
+	if info.Protocol != ProtocolVersion {
+		abort(cmd, client)
+		return nil, nil, Info{}, fmt.Errorf("%s speaks protocol %d, expected %d", c.name, info.Protocol, ProtocolVersion)
+	}
+	if info.Type == "" {
+		abort(cmd, client)
+		return nil, nil, Info{}, fmt.Errorf("%s reported an empty adapter type", c.name)
+	}
+	return cmd, client, info, nil
+}
+
+// stop закрывает stdin плагина (Serve завершается) и ждёт выхода процесса.
+func stop(cmd *exec.Cmd, client *rpc.Client) error {
+	_ = client.Close()
+	return cmd.Wait()
+}
+
+// abort завершает процесс плагина, не дожидаясь ответа (ошибка запуска,
+// отменённый вызов, потерянная связь).
+func abort(cmd *exec.Cmd, client *rpc.Client) {
+	_ = cmd.Process.Kill()
+	_ = stop(cmd, client)
+}
+
+// pipeConn — stdout/stdin процесса плагина как соединение RPC.
+type pipeConn struct {
+	io.Reader
+	io.WriteCloser
+}
+
+// call выполняет RPC-метод процесса name, ожидая ответ не дольше ctx.
+// Ошибка адаптера возвращается как есть, ошибка связи и отмена ctx —
+// ErrProcessLost: процесс с брошенным вызовом вызывающий завершает.
+func call(ctx context.Context, client *rpc.Client, name, method string, args, reply any) error {
+	c := client.Go(serviceName+"."+method, args, reply, make(chan *rpc.Call, 1))
+	select {
+	case <-c.Done:
+	case <-ctx.Done():
+		return fmt.Errorf("%s: %s: %w: %w", name, method, ErrProcessLost, ctx.Err())
+	}
+	var serverErr rpc.ServerError
+	switch {
+	case c.Error == nil:
+		return nil
+	case errors.As(c.Error, &serverErr):
+		return errors.New(string(serverErr))
+	default:
+		return fmt.Errorf("%s: %s: %w: %w", name, method, ErrProcessLost, c.Error)
+	}
+}
+
+// call выполняет метод адаптера в процессе; метод на чтение, прерванный
+// падением процесса, повторяется в перезапущенном.
+func (a *Adapter) call(ctx context.Context, method string, args, reply any) error {
+	err := a.callOnce(ctx, method, args, reply)
+	if errors.Is(err, ErrProcessLost) && retryable[method] && ctx.Err() == nil {
+		if retryErr := a.callOnce(ctx, method, args, reply); !errors.Is(retryErr, ErrNotConnected) {
+			return retryErr
+		}
+	}
+	return err
+}
+
+func (a *Adapter) callOnce(ctx context.Context, method string, args, reply any) error {
+	p, err := a.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	err = call(ctx, p.client, a.cmd.name, method, args, reply)
+	a.release(p, err)
+	return err
+}
+
+// acquire — процесс для очередного вызова: запускает заново упавший и
+// заменяет отработавший recycleAfter вызовов, когда он простаивает.
+// Пока потерянная транзакция не завершена, процесс не запускается.
+func (a *Adapter) acquire(ctx context.Context) (*process, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.cfg == nil {
+		return nil, ErrNotConnected
+	}
+	if a.txLost {
+		return nil, fmt.Errorf("%s: %w with an open transaction, Commit or Rollback it first", a.cmd.name, ErrProcessLost)
+	}
+	if a.proc != nil && a.recycleAfter > 0 && a.calls >= a.recycleAfter && a.active == 0 && !a.inTx {
+		_ = a.shutdown(ctx) // утечки старого процесса уходят вместе с ним
+	}
+	if a.proc == nil {
+		p, err := a.spawn(ctx, *a.cfg)
+		if err != nil {
+			return nil, fmt.Errorf("restart %s: %w", a.cmd.name, err)
+		}
+		a.proc, a.calls = p, 0
+	}
+	a.calls++
+	a.active++
+	return a.proc, nil
+}
+
+// release завершает вызов; процесс, с которым потеряна связь, добивается —
+// следующий acquire запустит новый (после конца потерянной транзакции).
+func (a *Adapter) release(p *process, err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.active--
+	if errors.Is(err, ErrProcessLost) && a.proc == p {
+		abort(p.cmd, p.client)
+		a.proc = nil
+		a.txLost = a.inTx
+	}
+}
+
+// spawn запускает процесс адаптера и подключает его к БД.
+func (a *Adapter) spawn(ctx context.Context, cfg adapters.Config) (*process, error) {
+	cmd, client, info, err := start(ctx, a.cmd)
+	if err != nil {
+		return nil, err
+	}
+	if info.Type != a.info.Type {
+		abort(cmd, client)
+		return nil, fmt.Errorf("%s now reports adapter type %q, was loaded as %q", a.cmd.name, info.Type, a.info.Type)
+	}
+	if err := call(ctx, client, a.cmd.name, "Connect", newConnectArgs(cfg), &Empty{}); err != nil {
+		abort(cmd, client)
+		return nil, err
+	}
+	return &process{cmd: cmd, client: client}, nil
+}
+
+// shutdown закрывает адаптер текущего процесса и ждёт выхода процесса.
+// Вызывается под a.mu.
+func (a *Adapter) shutdown(ctx context.Context) error {
+	p := a.proc
+	a.proc = nil
+	err := call(ctx, p.client, a.cmd.name, "Close", Empty{}, &Empty{})
+	if err != nil {
+		abort(p.cmd, p.client)
+	} else if waitErr := stop(p.cmd, p.client); waitErr != nil {
+		err = fmt.Errorf("%s: %w", a.cmd.name, waitErr)
+	}
+	return err
+}
+
+// Connect запускает процесс плагина и подключает его адаптер к БД.
+func (a *Adapter) Connect(ctx context.Context, cfg adapters.Config) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.cfg != nil {
+		return errors.New("plugin adapter is already connected")
+	}
+	p, err := a.spawn(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	a.cfg, a.proc, a.calls = &cfg, p, 0
+	return nil
+}
+
+// Close закрывает адаптер плагина и завершает процесс.
+func (a *Adapter) Close(ctx context.Context) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.cfg == nil {
+		return nil
+	}
+	a.cfg, a.inTx, a.txLost = nil, false, false
+	if a.proc == nil {
+		return nil
+	}
+	return a.shutdown(ctx)
+}
+
+func (a *Adapter) Ping(ctx context.Context) error {
+	return a.call(ctx, "Ping", Empty{}, &Empty{})
+}
+
+func (a *Adapter) ExportTable(ctx context.Context, tableName string) ([]*packet.DataPacket, error) {
+	var reply PacketsReply
+	if err := a.call(ctx, "ExportTable", TableArgs{Table: tableName}, &reply); err != nil {
+		return nil, err
+	}
+	return decodePackets(reply.Packets)
+}
+
+func (a *Adapter) ExportTableWithQuery(ctx context.Context, tableName string, query *packet.Query, sender, recipient string) ([]*packet.DataPacket, error) {
+	var reply PacketsReply
+	args := QueryArgs{Table: tableName, Query: query, Sender: sender, Recipient: recipient}
+	if err := a.call(ctx, "ExportTableWithQuery", args, &reply); err != nil {
+		return nil, err
+	}
+	return decodePackets(reply.Packets)
+}
+
+func (a *Adapter) ExportTableIncremental(ctx context.Context, tableName string, incrementalConfig adapters.IncrementalConfig) ([]*packet.DataPacket, string, error) {
+	var reply PacketsReply
+	args := IncrementalArgs{Table: tableName, Config: incrementalConfig}
+	if err := a.call(ctx, "ExportTableIncremental", args, &reply); err != nil {
+		return nil, "", err
+	}
+	packets, err := decodePackets(reply.Packets)
+	return packets, reply.Checkpoint, err
+}
+
+// ExecuteRawQuery выполняет SQL источника ETL в плагине; плагин, чей
+// адаптер этот метод не реализует, возвращает ошибку.
+func (a *Adapter) ExecuteRawQuery(ctx context.Context, query string) (*packet.DataPacket, error) {
+	var reply PacketsReply
+	if err := a.call(ctx, "ExecuteRawQuery", RawQueryArgs{Query: query}, &reply); err != nil {
+		return nil, err
+	}
+	packets, err := decodePackets(reply.Packets)
+	if err != nil {
+		return nil, err
+	}
+	if len(packets) != 1 {
+		return nil, fmt.Errorf("%s: ExecuteRawQuery returned %d packets, expected 1", a.cmd.name, len(packets))
+	}
+	return packets[0], nil
+}
+
+func (a *Adapter) ImportPacket(ctx context.Context, pkt *packet.DataPacket, strategy adapters.ImportStrategy) error {
+	raw, err := encodePackets([]*packet.DataPacket{pkt})
+	if err != nil {
+		return err
+	}
+	return a.call(ctx, "ImportPacket", ImportArgs{Packets: raw, Strategy: strategy}, &Empty{})
+}
+
+func (a *Adapter) ImportPackets(ctx context.Context, packets []*packet.DataPacket, strategy adapters.ImportStrategy) error {
+	raw, err := encodePackets(packets)
+	if err != nil {
+		return err
+	}
+	return a.call(ctx, "ImportPackets", ImportArgs{Packets: raw, Strategy: strategy}, &Empty{})
+}
+
+func (a *Adapter) GetTableSchema(ctx context.Context, tableName string) (packet.Schema, error) {
+	var schema packet.Schema
+	err := a.call(ctx, "GetTableSchema", TableArgs{Table: tableName}, &schema)
+	return schema, err
+}
+
+func (a *Adapter) GetTableNames(ctx context.Context) ([]string, error) {
+	var names []string
+	err := a.call(ctx, "GetTableNames", Empty{}, &names)
+	return names, err
+}
+
+func (a *Adapter) GetViewNames(ctx context.Context) ([]adapters.ViewInfo, error) {
+	var views []adapters.ViewInfo
+	err := a.call(ctx, "GetViewNames", Empty{}, &views)
+	return views, err
+}
+
+func (a *Adapter) TableExists(ctx context.Context, tableName string) (bool, error) {
+	var exists bool
+	err := a.call(ctx, "TableExists", TableArgs{Table: tableName}, &exists)
+	return exists, err
+}
+
+// BeginTx открывает транзакцию в плагине; одновременно — не больше одной.
+// Пока она открыта, процесс не заменяется по recycleAfter; если процесс
+// потерян, Commit/Rollback возвращают ErrProcessLost и снимают блокировку
+// вызовов.
+func (a *Adapter) BeginTx(ctx context.Context) (adapters.Tx, error) {
+	if err := a.call(ctx, "BeginTx", Empty{}, &Empty{}); err != nil {
+		return nil, err
+	}
+	a.setInTx(true)
+	return tx{a}, nil
+}
+
+func (a *Adapter) setInTx(inTx bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.inTx = inTx
+	if !inTx {
+		a.txLost = false
+	}
+}
+
+type tx struct{ a *Adapter }
+
+func (t tx) Commit(ctx context.Context) error {
+	defer t.a.setInTx(false)
+	return t.a.call(ctx, "Commit", Empty{}, &Empty{})
+}
+
+func (t tx) Rollback(ctx context.Context) error {
+	defer t.a.setInTx(false)
+	return t.a.call(ctx, "Rollback", Empty{}, &Empty{})
+}
+
+func (a *Adapter) GetDatabaseVersion(ctx context.Context) (string, error) {
+	var version string
+	err := a.call(ctx, "GetDatabaseVersion", Empty{}, &version)
+	return version, err
+}
+
+// GetDatabaseType — тип, под которым адаптер зарегистрирован.
+func (a *Adapter) GetDatabaseType() string {
+	return a.info.Type
+}
+
+func (a *Adapter) InspectTable(ctx context.Context, tableName string) (*adapters.TableReport, error) {
+	var report adapters.TableReport
+	if err := a.call(ctx, "InspectTable", TableArgs{Table: tableName}, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
@@ -0,0 +1,119 @@
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+)
+
+// isolateEnv — переменная окружения дочернего процесса изолированного
+// адаптера: тип адаптера, который процесс обслуживает вместо своей работы.
+const isolateEnv = "TDTP_ISOLATED_ADAPTER"
+
+// IsolateOptions — параметры изолированных адаптеров.
+type IsolateOptions struct {
+	// RecycleAfter — вызовов адаптера до плановой замены процесса (0 — без
+	// замены): утечки памяти и дескрипторов драйвера не копятся в долгих
+	// пайплайнах и в пуле tdtpserve. Процесс меняется между вызовами, вне
+	// транзакции.
+	RecycleAfter int
+}
+
+// DefaultIsolated — типы адаптеров из TDTP_ISOLATE_ADAPTERS (через запятую).
+func DefaultIsolated() string {
+	return os.Getenv("TDTP_ISOLATE_ADAPTERS")
+}
+
+// Isolate переводит вкомпилированные адаптеры types в дочерние процессы:
+// каждый экземпляр адаптера работает в копии текущего исполняемого файла,
+// запущенной в режиме ServeIsolated, и говорит с хостом по протоколу
+// плагинов. Падение драйвера (ODBC, клиент Oracle) обрывает только этот
+// процесс: адаптер перезапускает его при следующем вызове, а хост —
+// tdtpserve, долгий пайплайн — продолжает работу.
+//
+// Вызывается при старте, до создания адаптеров; main хоста обязан первой
+// строкой вызывать ServeIsolated.
+func Isolate(opts IsolateOptions, types ...string) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("isolate adapters: %w", err)
+	}
+	var errs []error
+	for _, typ := range types {
+		driver, ok := describe(typ)
+		switch {
+		case !ok:
+			errs = append(errs, fmt.Errorf("isolate adapter %s: not compiled into this build", typ))
+			continue
+		case driver.Plugin != "":
+			errs = append(errs, fmt.Errorf("isolate adapter %s: plugin adapters already run out of process", typ))
+			continue
+		case driver.Isolated:
+			continue
+		}
+		info := Info{Type: typ, Driver: driver.Driver, Features: driver.Features}
+		cmd := command{path: exe, env: []string{isolateEnv + "=" + typ}, name: "isolated adapter " + typ}
+		adapters.Register(typ, func() adapters.Adapter {
+			return &Adapter{cmd: cmd, info: info, recycleAfter: opts.RecycleAfter}
+		})
+		driver.Isolated = true
+		adapters.Describe(driver)
+	}
+	return errors.Join(errs...)
+}
+
+// ParseIsolated — типы адаптеров из списка через запятую (флаг
+// --isolate-adapters, TDTP_ISOLATE_ADAPTERS).
+func ParseIsolated(list string) []string {
+	var types []string
+	for _, typ := range strings.Split(list, ",") {
+		if typ = strings.TrimSpace(typ); typ != "" {
+			types = append(types, typ)
+		}
+	}
+	return types
+}
+
+// ServeIsolated — первая строка main хоста (tdtpcli, tdtpserve). В дочернем
+// процессе изолированного адаптера обслуживает его вкомпилированную
+// реализацию на stdin/stdout и завершает процесс; в обычном запуске ничего
+// не делает.
+func ServeIsolated() {
+	typ := os.Getenv(isolateEnv)
+	if typ == "" {
+		return
+	}
+	if err := serveIsolated(typ); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+func serveIsolated(typ string) error {
+	driver, ok := describe(typ)
+	if !ok {
+		return fmt.Errorf("isolated adapter %s is not compiled into %s", typ, os.Args[0])
+	}
+	info := Info{Type: typ, Driver: driver.Driver, Features: driver.Features}
+	return Serve(info, func() adapters.Adapter {
+		adapter, _ := adapters.NewWithoutConnect(typ) // тип проверен выше
+		return adapter
+	})
+}
+
+// describe — сведения о зарегистрированном адаптере typ.
+func describe(typ string) (adapters.DriverInfo, bool) {
+	if !adapters.IsRegistered(typ) {
+		return adapters.DriverInfo{}, false
+	}
+	for _, driver := range adapters.Available() {
+		if driver.Type == typ {
+			return driver, true
+		}
+	}
+	return adapters.DriverInfo{Type: typ}, true
+}
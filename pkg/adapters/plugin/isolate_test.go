@@ -0,0 +1,148 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+// sandboxAdapter — «вкомпилированный» адаптер с ненадёжным драйвером:
+// версия БД — pid процесса, экспорт таблицы crash роняет процесс.
+type sandboxAdapter struct{ memAdapter }
+
+func (a *sandboxAdapter) GetDatabaseVersion(context.Context) (string, error) {
+	return strconv.Itoa(os.Getpid()), nil
+}
+
+func (a *sandboxAdapter) ExportTable(ctx context.Context, table string) ([]*packet.DataPacket, error) {
+	switch table {
+	case "crash":
+		os.Exit(3)
+	case "hang":
+		select {}
+	}
+	return a.ExportTableWithQuery(ctx, table, nil, "", "")
+}
+
+func (a *sandboxAdapter) BeginTx(context.Context) (adapters.Tx, error) { return sandboxTx{}, nil }
+
+type sandboxTx struct{}
+
+func (sandboxTx) Commit(context.Context) error   { return nil }
+func (sandboxTx) Rollback(context.Context) error { return nil }
+
+func registerSandbox() {
+	adapters.Register("sandbox", func() adapters.Adapter { return &sandboxAdapter{} })
+	adapters.Describe(adapters.DriverInfo{Type: "sandbox", Driver: "example.com/flaky"})
+}
+
+// isolateSandbox изолирует sandbox на время теста.
+func isolateSandbox(t *testing.T, opts IsolateOptions) adapters.Adapter {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("test binary as isolated adapter host is not set up for Windows")
+	}
+	if err := Isolate(opts, "sandbox"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(registerSandbox)
+	a, err := adapters.New(context.Background(), adapters.Config{Type: "sandbox", DSN: "flaky"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = a.Close(context.Background()) })
+	return a
+}
+
+func pid(t *testing.T, a adapters.Adapter) string {
+	t.Helper()
+	v, err := a.GetDatabaseVersion(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v == strconv.Itoa(os.Getpid()) {
+		t.Fatal("adapter runs in the host process")
+	}
+	return v
+}
+
+// Падение драйвера обрывает только дочерний процесс: вызов получает
+// ErrProcessLost, следующий работает в перезапущенном процессе.
+func TestIsolate_RestartsCrashedProcess(t *testing.T) {
+	a := isolateSandbox(t, IsolateOptions{})
+	if !strings.Contains(describeString("sandbox"), "isolated") {
+		t.Errorf("driver = %s, want isolated", describeString("sandbox"))
+	}
+
+	before := pid(t, a)
+	if _, err := a.ExportTable(context.Background(), "crash"); !errors.Is(err, ErrProcessLost) {
+		t.Fatalf("crash err = %v, want ErrProcessLost", err)
+	}
+	if after := pid(t, a); after == before {
+		t.Errorf("process was not restarted: pid %s", after)
+	}
+}
+
+// Процесс, упавший с открытой транзакцией, не подменяется молча: до
+// Rollback все вызовы получают ErrProcessLost.
+func TestIsolate_CrashInTransaction(t *testing.T) {
+	a := isolateSandbox(t, IsolateOptions{})
+	ctx := context.Background()
+	tx, err := a.BeginTx(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.ExportTable(ctx, "crash"); !errors.Is(err, ErrProcessLost) {
+		t.Fatalf("crash err = %v, want ErrProcessLost", err)
+	}
+	if _, err := a.GetDatabaseVersion(ctx); !errors.Is(err, ErrProcessLost) {
+		t.Errorf("call after lost transaction: err = %v, want ErrProcessLost", err)
+	}
+	if err := tx.Rollback(ctx); !errors.Is(err, ErrProcessLost) {
+		t.Errorf("Rollback err = %v, want ErrProcessLost", err)
+	}
+	pid(t, a)
+}
+
+// Отменённый вызов завершает процесс: плагин не продолжает работу, о
+// которой вызывающий уже забыл.
+func TestIsolate_CancelAbortsProcess(t *testing.T) {
+	a := isolateSandbox(t, IsolateOptions{})
+	before := pid(t, a)
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	if _, err := a.ExportTable(ctx, "hang"); !errors.Is(err, context.DeadlineExceeded) || !errors.Is(err, ErrProcessLost) {
+		t.Fatalf("err = %v, want DeadlineExceeded and ErrProcessLost", err)
+	}
+	if after := pid(t, a); after == before {
+		t.Errorf("cancelled call left process %s running", after)
+	}
+}
+
+// RecycleAfter заменяет процесс после заданного числа вызовов.
+func TestIsolate_Recycle(t *testing.T) {
+	a := isolateSandbox(t, IsolateOptions{RecycleAfter: 2})
+	first, second, third := pid(t, a), pid(t, a), pid(t, a)
+	if first != second || third == second {
+		t.Errorf("pids = %s, %s, %s; want the process replaced after 2 calls", first, second, third)
+	}
+}
+
+func TestIsolate_UnknownType(t *testing.T) {
+	if err := Isolate(IsolateOptions{}, "nosuchdb"); err == nil {
+		t.Error("Isolate(nosuchdb) succeeded")
+	}
+}
+
+func describeString(typ string) string {
+	driver, _ := describe(typ)
+	return driver.String()
+}
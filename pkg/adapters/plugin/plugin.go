@@ -0,0 +1,122 @@
+// Package plugin подключает внешние адаптеры БД без пересборки tdtpcli и
+// tdtpserve: адаптер, который нельзя отдать в upstream (внутренняя СУБД
+// компании), собирается в отдельный исполняемый файл-плагин, а хост
+// регистрирует его в фабрике adapters при запуске.
+//
+// Плагин — отдельный процесс, а не Go plugin (.so): релизные бинарники
+// статические (CGO_ENABLED=0), а .so требует cgo и совпадения версий Go и
+// всех общих зависимостей с хостом. Процесс плагина собирается своим
+// toolchain, падение драйвера не роняет хост.
+//
+// Протокол — JSON-RPC 1.0 (net/rpc/jsonrpc) на stdin/stdout процесса:
+// хост вызывает методы "Adapter.<Метод>" интерфейса adapters.Adapter,
+// пакеты передаются в JSON-формате TDTP (Generator.ToJSON). Плагин на Go
+// пишется вызовом Serve; плагин на другом языке реализует те же методы
+// (аргументы и ответы — типы protocol.go).
+//
+// Жизненный цикл: Load запускает плагин, читает Info (тип адаптера) и
+// завершает процесс; каждый adapters.New для этого типа запускает
+// собственный процесс плагина (Connect) и завершает его в Close. Упавший
+// процесс перезапускается при следующем вызове адаптера.
+//
+// Тот же протокол изолирует вкомпилированные адаптеры с ненадёжными
+// драйверами (Isolate): экземпляр адаптера работает в дочерней копии
+// исполняемого файла хоста (ServeIsolated), и падение или утечка драйвера
+// не затрагивает tdtpserve или долгий пайплайн.
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+)
+
+// LoadTimeout — ожидание ответа плагина на Info при загрузке.
+const LoadTimeout = 10 * time.Second
+
+// ErrTypeRegistered — тип адаптера плагина уже зарегистрирован
+// (вкомпилированным адаптером или другим плагином). Плагин не может
+// подменить встроенный адаптер.
+var ErrTypeRegistered = errors.New("adapter type is already registered")
+
+// DefaultDir — каталог плагинов из TDTP_PLUGIN_DIR; пусто — плагины не
+// загружаются.
+func DefaultDir() string {
+	return os.Getenv("TDTP_PLUGIN_DIR")
+}
+
+// Load запускает исполняемый файл плагина path, проверяет протокол и
+// регистрирует адаптер в глобальной фабрике под типом из Info.
+func Load(ctx context.Context, path string) (adapters.DriverInfo, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return adapters.DriverInfo{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, LoadTimeout)
+	defer cancel()
+	cmd, client, info, err := start(ctx, command{path: abs, name: "plugin " + abs})
+	if err != nil {
+		return adapters.DriverInfo{}, err
+	}
+	if err := stop(cmd, client); err != nil {
+		return adapters.DriverInfo{}, fmt.Errorf("plugin %s: %w", abs, err)
+	}
+
+	if adapters.IsRegistered(info.Type) {
+		return adapters.DriverInfo{}, fmt.Errorf("plugin %s: %w: %s", abs, ErrTypeRegistered, info.Type)
+	}
+	adapters.Register(info.Type, newAdapter(abs, info))
+	driver := adapters.DriverInfo{Type: info.Type, Driver: info.Driver, Features: info.Features, Plugin: abs}
+	adapters.Describe(driver)
+	return driver, nil
+}
+
+// LoadDir загружает все исполняемые файлы каталога dir (на Windows — *.exe),
+// кроме скрытых. Ошибка одного плагина не мешает загрузке остальных:
+// возвращаются загруженные адаптеры и объединённые ошибки.
+func LoadDir(ctx context.Context, dir string) ([]adapters.DriverInfo, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("plugin directory: %w", err)
+	}
+
+	var loaded []adapters.DriverInfo
+	var errs []error
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		if !isPlugin(path) {
+			continue
+		}
+		driver, err := Load(ctx, path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		loaded = append(loaded, driver)
+	}
+	return loaded, errors.Join(errs...)
+}
+
+// isPlugin — файл похож на исполняемый файл плагина (символические ссылки
+// разыменовываются).
+func isPlugin(path string) bool {
+	if strings.HasPrefix(filepath.Base(path), ".") {
+		return false
+	}
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		return strings.EqualFold(filepath.Ext(path), ".exe")
+	}
+	return info.Mode().Perm()&0o111 != 0
+}
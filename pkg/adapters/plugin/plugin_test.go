@@ -0,0 +1,211 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+// Тестовый бинарник сам служит плагином: с TDTP_PLUGIN_TEST_TYPE в
+// окружении он обслуживает memAdapter вместо запуска тестов, а в роли
+// дочернего процесса изолированного адаптера — sandboxAdapter.
+func TestMain(m *testing.M) {
+	registerSandbox()
+	ServeIsolated()
+	if typ := os.Getenv("TDTP_PLUGIN_TEST_TYPE"); typ != "" {
+		err := Serve(Info{Type: typ, Driver: "example.com/memdb"}, func() adapters.Adapter { return &memAdapter{} })
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// memAdapter — таблицы в памяти процесса плагина.
+type memAdapter struct {
+	adapters.Adapter
+	dsn    string
+	tables map[string]*packet.DataPacket
+}
+
+func (a *memAdapter) Connect(_ context.Context, cfg adapters.Config) error {
+	if cfg.DSN == "" {
+		return errors.New("memdb: empty DSN")
+	}
+	a.dsn, a.tables = cfg.DSN, map[string]*packet.DataPacket{}
+	return nil
+}
+
+func (a *memAdapter) Close(context.Context) error { return nil }
+func (a *memAdapter) GetDatabaseVersion(context.Context) (string, error) {
+	return "memdb " + a.dsn, nil
+}
+
+func (a *memAdapter) ImportPacket(_ context.Context, pkt *packet.DataPacket, _ adapters.ImportStrategy) error {
+	a.tables[pkt.Header.TableName] = pkt
+	return nil
+}
+
+func (a *memAdapter) ExportTableWithQuery(_ context.Context, table string, q *packet.Query, _, _ string) ([]*packet.DataPacket, error) {
+	pkt, ok := a.tables[table]
+	if !ok {
+		return nil, fmt.Errorf("memdb: table %s not found", table)
+	}
+	rows := pkt.GetRows()
+	if q != nil && q.Limit > 0 && q.Limit < len(rows) {
+		rows = rows[:q.Limit]
+	}
+	return packet.NewGenerator().GenerateReference(table, pkt.Schema, rows)
+}
+
+func (a *memAdapter) ExecuteRawQuery(ctx context.Context, query string) (*packet.DataPacket, error) {
+	packets, err := a.ExportTableWithQuery(ctx, strings.TrimPrefix(query, "SELECT * FROM "), nil, "", "")
+	if err != nil {
+		return nil, err
+	}
+	return packets[0], nil
+}
+
+func (a *memAdapter) GetTableNames(context.Context) ([]string, error) {
+	var names []string
+	for name := range a.tables {
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// loadTestPlugin регистрирует тестовый бинарник как плагин типа typ.
+func loadTestPlugin(t *testing.T, typ string) adapters.DriverInfo {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("test binary as plugin is not set up for Windows")
+	}
+	t.Setenv("TDTP_PLUGIN_TEST_TYPE", typ)
+	driver, err := Load(context.Background(), os.Args[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { adapters.Unregister(typ) })
+	return driver
+}
+
+// Адаптер плагина создаётся фабрикой, экспортирует и импортирует пакеты
+// через свой процесс и отдаёт ошибки адаптера хосту.
+func TestPlugin_RoundTrip(t *testing.T) {
+	driver := loadTestPlugin(t, "memdb")
+	if driver.Driver != "example.com/memdb" || !strings.Contains(driver.String(), "plugin ") {
+		t.Errorf("driver = %s", driver)
+	}
+
+	ctx := context.Background()
+	a, err := adapters.New(ctx, adapters.Config{Type: "memdb", DSN: "orders"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close(ctx)
+
+	if a.GetDatabaseType() != "memdb" {
+		t.Errorf("GetDatabaseType() = %q", a.GetDatabaseType())
+	}
+	if v, err := a.GetDatabaseVersion(ctx); err != nil || v != "memdb orders" {
+		t.Errorf("GetDatabaseVersion() = %q, %v", v, err)
+	}
+
+	schema := packet.Schema{Fields: []packet.Field{{Name: "ID", Type: "INTEGER", Key: true}, {Name: "City", Type: "TEXT"}}}
+	packets, err := packet.NewGenerator().GenerateReference("Orders", schema, [][]string{{"1", "Kazan"}, {"2", "Omsk|Tara"}, {"3", ""}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := a.ImportPacket(ctx, packets[0], adapters.StrategyReplace); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := a.ExportTableWithQuery(ctx, "Orders", &packet.Query{Limit: 2}, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("got %d packets, want 1", len(got))
+	}
+	rows := got[0].GetRows()
+	if len(rows) != 2 || rows[1][1] != "Omsk|Tara" || got[0].Schema.Fields[0].Key != true {
+		t.Errorf("rows = %v, schema = %+v", rows, got[0].Schema)
+	}
+
+	// SQL источников ETL — необязательный ExecuteRawQuery адаптера плагина
+	raw, err := a.(interface {
+		ExecuteRawQuery(context.Context, string) (*packet.DataPacket, error)
+	}).ExecuteRawQuery(ctx, "SELECT * FROM Orders")
+	if err != nil || len(raw.GetRows()) != 3 {
+		t.Errorf("ExecuteRawQuery() = %v, %v", raw, err)
+	}
+
+	if _, err := a.ExportTableWithQuery(ctx, "Missing", nil, "", ""); err == nil || err.Error() != "memdb: table Missing not found" {
+		t.Errorf("missing table err = %v", err)
+	}
+
+	if err := a.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.GetTableNames(ctx); !errors.Is(err, ErrNotConnected) {
+		t.Errorf("after Close err = %v, want ErrNotConnected", err)
+	}
+}
+
+func TestPlugin_ConnectError(t *testing.T) {
+	loadTestPlugin(t, "memdb")
+	_, err := adapters.New(context.Background(), adapters.Config{Type: "memdb"})
+	if !errors.Is(err, adapters.ErrConnect) || !strings.Contains(err.Error(), "memdb: empty DSN") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+// Плагин не может занять тип уже зарегистрированного адаптера.
+func TestLoad_TypeRegistered(t *testing.T) {
+	adapters.Register("builtin", func() adapters.Adapter { return nil })
+	t.Cleanup(func() { adapters.Unregister("builtin") })
+	if runtime.GOOS == "windows" {
+		t.Skip("test binary as plugin is not set up for Windows")
+	}
+	t.Setenv("TDTP_PLUGIN_TEST_TYPE", "builtin")
+	if _, err := Load(context.Background(), os.Args[0]); !errors.Is(err, ErrTypeRegistered) {
+		t.Errorf("err = %v, want ErrTypeRegistered", err)
+	}
+}
+
+// LoadDir пропускает неисполняемые и скрытые файлы и собирает ошибки
+// плагинов, не останавливаясь на первой.
+func TestLoadDir(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("executable bit is not used on Windows")
+	}
+	dir := t.TempDir()
+	for name, mode := range map[string]os.FileMode{"readme.txt": 0o644, ".hidden": 0o755, "broken": 0o755} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\nexit 3\n"), mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(os.Args[0], filepath.Join(dir, "memdb")); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TDTP_PLUGIN_TEST_TYPE", "memdb")
+	t.Cleanup(func() { adapters.Unregister("memdb") })
+
+	loaded, err := LoadDir(context.Background(), dir)
+	if len(loaded) != 1 || loaded[0].Type != "memdb" {
+		t.Errorf("loaded = %v", loaded)
+	}
+	if err == nil || !strings.Contains(err.Error(), "broken") || strings.Contains(err.Error(), "readme") {
+		t.Errorf("err = %v, want only the broken plugin", err)
+	}
+}
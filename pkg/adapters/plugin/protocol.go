@@ -0,0 +1,153 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+	"github.com/ruslano69/tdtp-framework/pkg/core/schema"
+)
+
+// ProtocolVersion — версия протокола плагинов. Хост отказывается загружать
+// плагин, сообщивший в Info другую версию.
+const ProtocolVersion = 1
+
+// serviceName — имя RPC-сервиса плагина: методы вызываются как
+// "Adapter.<Метод>".
+const serviceName = "Adapter"
+
+// Info — сведения о плагине, ответ метода Adapter.Info.
+type Info struct {
+	Protocol int      `json:"protocol"`           // ProtocolVersion плагина (Serve заполняет сам)
+	Type     string   `json:"type"`               // тип адаптера (Config.Type), под которым плагин регистрируется
+	Driver   string   `json:"driver,omitempty"`   // драйвер БД внутри плагина, для --version
+	Features []string `json:"features,omitempty"` // дополнительные возможности
+}
+
+// Empty — аргумент и ответ методов без параметров.
+type Empty struct{}
+
+// ConnectArgs — параметры Adapter.Connect: переносимая часть
+// adapters.Config. Настройки импорта на стороне хоста (блокировки, окна
+// обслуживания, дубликаты, журнал запросов) плагин не получает.
+type ConnectArgs struct {
+	Type              string                        `json:"type"`
+	DSN               string                        `json:"dsn"`
+	Schema            string                        `json:"schema,omitempty"`
+	Timeout           time.Duration                 `json:"timeout,omitempty"` // наносекунды
+	MaxConns          int                           `json:"max_conns,omitempty"`
+	MinConns          int                           `json:"min_conns,omitempty"`
+	SSL               adapters.SSLConfig            `json:"ssl"`
+	CompatibilityMode string                        `json:"compatibility_mode,omitempty"`
+	NoDateSentinels   []string                      `json:"no_date_sentinels,omitempty"`
+	SourceTimezone    string                        `json:"source_timezone,omitempty"`
+	Booleans          *schema.BoolMapping           `json:"booleans,omitempty"`
+	ColumnBooleans    map[string]schema.BoolMapping `json:"column_booleans,omitempty"`
+	Charset           string                        `json:"charset,omitempty"`
+}
+
+// newConnectArgs выбирает из cfg поля, передаваемые плагину.
+func newConnectArgs(cfg adapters.Config) ConnectArgs {
+	return ConnectArgs{
+		Type:              cfg.Type,
+		DSN:               cfg.DSN,
+		Schema:            cfg.Schema,
+		Timeout:           cfg.Timeout,
+		MaxConns:          cfg.MaxConns,
+		MinConns:          cfg.MinConns,
+		SSL:               cfg.SSL,
+		CompatibilityMode: cfg.CompatibilityMode,
+		NoDateSentinels:   cfg.NoDateSentinels,
+		SourceTimezone:    cfg.SourceTimezone,
+		Booleans:          cfg.Booleans,
+		ColumnBooleans:    cfg.ColumnBooleans,
+		Charset:           cfg.Charset,
+	}
+}
+
+// config восстанавливает adapters.Config на стороне плагина.
+func (a ConnectArgs) config() adapters.Config {
+	return adapters.Config{
+		Type:              a.Type,
+		DSN:               a.DSN,
+		Schema:            a.Schema,
+		Timeout:           a.Timeout,
+		MaxConns:          a.MaxConns,
+		MinConns:          a.MinConns,
+		SSL:               a.SSL,
+		CompatibilityMode: a.CompatibilityMode,
+		NoDateSentinels:   a.NoDateSentinels,
+		SourceTimezone:    a.SourceTimezone,
+		Booleans:          a.Booleans,
+		ColumnBooleans:    a.ColumnBooleans,
+		Charset:           a.Charset,
+	}
+}
+
+// TableArgs — методы с одним именем таблицы.
+type TableArgs struct {
+	Table string `json:"table"`
+}
+
+// QueryArgs — Adapter.ExportTableWithQuery.
+type QueryArgs struct {
+	Table     string        `json:"table"`
+	Query     *packet.Query `json:"query,omitempty"`
+	Sender    string        `json:"sender,omitempty"`
+	Recipient string        `json:"recipient,omitempty"`
+}
+
+// IncrementalArgs — Adapter.ExportTableIncremental; поля Config — как в
+// sync.IncrementalConfig.
+type IncrementalArgs struct {
+	Table  string                     `json:"table"`
+	Config adapters.IncrementalConfig `json:"config"`
+}
+
+// RawQueryArgs — Adapter.ExecuteRawQuery: SQL источника ETL-пайплайна и
+// tdtpserve.
+type RawQueryArgs struct {
+	Query string `json:"query"`
+}
+
+// PacketsReply — пакеты экспорта в JSON-формате TDTP (Generator.ToJSON).
+type PacketsReply struct {
+	Packets    []json.RawMessage `json:"packets"`
+	Checkpoint string            `json:"checkpoint,omitempty"` // ExportTableIncremental: последнее значение tracking-поля
+}
+
+// ImportArgs — Adapter.ImportPackets: пакеты в JSON-формате TDTP.
+type ImportArgs struct {
+	Packets  []json.RawMessage       `json:"packets"`
+	Strategy adapters.ImportStrategy `json:"strategy"`
+}
+
+// encodePackets сериализует пакеты для передачи через RPC.
+func encodePackets(packets []*packet.DataPacket) ([]json.RawMessage, error) {
+	gen := packet.NewGenerator()
+	out := make([]json.RawMessage, 0, len(packets))
+	for _, pkt := range packets {
+		data, err := gen.ToJSON(pkt)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, data)
+	}
+	return out, nil
+}
+
+// decodePackets разбирает пакеты, полученные через RPC.
+func decodePackets(raw []json.RawMessage) ([]*packet.DataPacket, error) {
+	parser := packet.NewParser()
+	out := make([]*packet.DataPacket, 0, len(raw))
+	for i, data := range raw {
+		pkt, err := parser.ParseJSON(data)
+		if err != nil {
+			return nil, fmt.Errorf("packet %d: %w", i+1, err)
+		}
+		out = append(out, pkt)
+	}
+	return out, nil
+}
@@ -0,0 +1,219 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"os"
+	"sync"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+// Serve — точка входа исполняемого файла плагина: обслуживает адаптер,
+// созданный newAdapter, по протоколу плагинов на stdin/stdout до закрытия
+// stdin хостом. Stdout занят протоколом — журнал плагина пишется в stderr
+// (хост передаёт его в свой stderr).
+//
+//	func main() {
+//	    err := plugin.Serve(plugin.Info{Type: "acme", Driver: "example.com/acme/driver"},
+//	        func() adapters.Adapter { return &acme.Adapter{} })
+//	    if err != nil {
+//	        log.Fatal(err)
+//	    }
+//	}
+func Serve(info Info, newAdapter adapters.AdapterConstructor) error {
+	return serveConn(stdio{}, info, newAdapter)
+}
+
+// serveConn обслуживает одно соединение: один процесс плагина — один
+// экземпляр адаптера хоста.
+func serveConn(conn io.ReadWriteCloser, info Info, newAdapter adapters.AdapterConstructor) error {
+	if info.Type == "" {
+		return errors.New("plugin: Info.Type is required")
+	}
+	info.Protocol = ProtocolVersion
+	srv := rpc.NewServer()
+	if err := srv.RegisterName(serviceName, &service{info: info, adapter: newAdapter()}); err != nil {
+		return fmt.Errorf("plugin: %w", err)
+	}
+	srv.ServeCodec(jsonrpc.NewServerCodec(conn))
+	return nil
+}
+
+// stdio — stdin/stdout процесса как соединение.
+type stdio struct{}
+
+func (stdio) Read(p []byte) (int, error)  { return os.Stdin.Read(p) }
+func (stdio) Write(p []byte) (int, error) { return os.Stdout.Write(p) }
+func (stdio) Close() error                { return os.Stdout.Close() }
+
+// service — RPC-методы плагина поверх его адаптера. net/rpc обслуживает
+// запросы параллельно, как хост вызывает адаптер из нескольких горутин.
+type service struct {
+	info    Info
+	adapter adapters.Adapter
+
+	mu sync.Mutex
+	tx adapters.Tx // открытая BeginTx транзакция
+}
+
+func (s *service) Info(_ Empty, reply *Info) error {
+	*reply = s.info
+	return nil
+}
+
+func (s *service) Connect(args ConnectArgs, _ *Empty) error {
+	return s.adapter.Connect(context.Background(), args.config())
+}
+
+func (s *service) Close(_ Empty, _ *Empty) error {
+	return s.adapter.Close(context.Background())
+}
+
+func (s *service) Ping(_ Empty, _ *Empty) error {
+	return s.adapter.Ping(context.Background())
+}
+
+func (s *service) ExportTable(args TableArgs, reply *PacketsReply) error {
+	packets, err := s.adapter.ExportTable(context.Background(), args.Table)
+	if err != nil {
+		return err
+	}
+	reply.Packets, err = encodePackets(packets)
+	return err
+}
+
+func (s *service) ExportTableWithQuery(args QueryArgs, reply *PacketsReply) error {
+	packets, err := s.adapter.ExportTableWithQuery(context.Background(), args.Table, args.Query, args.Sender, args.Recipient)
+	if err != nil {
+		return err
+	}
+	reply.Packets, err = encodePackets(packets)
+	return err
+}
+
+func (s *service) ExportTableIncremental(args IncrementalArgs, reply *PacketsReply) error {
+	packets, checkpoint, err := s.adapter.ExportTableIncremental(context.Background(), args.Table, args.Config)
+	if err != nil {
+		return err
+	}
+	reply.Checkpoint = checkpoint
+	reply.Packets, err = encodePackets(packets)
+	return err
+}
+
+// rawQueryExecutor — необязательный метод адаптеров для SQL источников
+// ETL (как в etl.Loader).
+type rawQueryExecutor interface {
+	ExecuteRawQuery(ctx context.Context, query string) (*packet.DataPacket, error)
+}
+
+func (s *service) ExecuteRawQuery(args RawQueryArgs, reply *PacketsReply) error {
+	executor, ok := s.adapter.(rawQueryExecutor)
+	if !ok {
+		return fmt.Errorf("adapter %s does not support ExecuteRawQuery", s.info.Type)
+	}
+	pkt, err := executor.ExecuteRawQuery(context.Background(), args.Query)
+	if err != nil {
+		return err
+	}
+	reply.Packets, err = encodePackets([]*packet.DataPacket{pkt})
+	return err
+}
+
+func (s *service) ImportPacket(args ImportArgs, _ *Empty) error {
+	if len(args.Packets) != 1 {
+		return fmt.Errorf("ImportPacket expects exactly one packet, got %d", len(args.Packets))
+	}
+	packets, err := decodePackets(args.Packets)
+	if err != nil {
+		return err
+	}
+	return s.adapter.ImportPacket(context.Background(), packets[0], args.Strategy)
+}
+
+func (s *service) ImportPackets(args ImportArgs, _ *Empty) error {
+	packets, err := decodePackets(args.Packets)
+	if err != nil {
+		return err
+	}
+	return s.adapter.ImportPackets(context.Background(), packets, args.Strategy)
+}
+
+func (s *service) GetTableSchema(args TableArgs, reply *packet.Schema) error {
+	schema, err := s.adapter.GetTableSchema(context.Background(), args.Table)
+	*reply = schema
+	return err
+}
+
+func (s *service) GetTableNames(_ Empty, reply *[]string) error {
+	names, err := s.adapter.GetTableNames(context.Background())
+	*reply = names
+	return err
+}
+
+func (s *service) GetViewNames(_ Empty, reply *[]adapters.ViewInfo) error {
+	views, err := s.adapter.GetViewNames(context.Background())
+	*reply = views
+	return err
+}
+
+func (s *service) TableExists(args TableArgs, reply *bool) error {
+	exists, err := s.adapter.TableExists(context.Background(), args.Table)
+	*reply = exists
+	return err
+}
+
+func (s *service) BeginTx(_ Empty, _ *Empty) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.tx != nil {
+		return errors.New("transaction already in progress")
+	}
+	tx, err := s.adapter.BeginTx(context.Background())
+	if err != nil {
+		return err
+	}
+	s.tx = tx
+	return nil
+}
+
+func (s *service) Commit(_ Empty, _ *Empty) error {
+	return s.endTx(adapters.Tx.Commit)
+}
+
+func (s *service) Rollback(_ Empty, _ *Empty) error {
+	return s.endTx(adapters.Tx.Rollback)
+}
+
+// endTx завершает открытую транзакцию commit или rollback.
+func (s *service) endTx(end func(adapters.Tx, context.Context) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.tx == nil {
+		return errors.New("no transaction in progress")
+	}
+	tx := s.tx
+	s.tx = nil
+	return end(tx, context.Background())
+}
+
+func (s *service) GetDatabaseVersion(_ Empty, reply *string) error {
+	version, err := s.adapter.GetDatabaseVersion(context.Background())
+	*reply = version
+	return err
+}
+
+func (s *service) InspectTable(args TableArgs, reply *adapters.TableReport) error {
+	report, err := s.adapter.InspectTable(context.Background(), args.Table)
+	if err != nil {
+		return err
+	}
+	*reply = *report
+	return nil
+}
@@ -3,6 +3,7 @@ package postgres
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"math"
 	"strings"
@@ -12,6 +13,7 @@ import (
 	"github.com/ruslano69/tdtp-framework/pkg/adapters"
 	"github.com/ruslano69/tdtp-framework/pkg/adapters/base"
 	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+	"github.com/ruslano69/tdtp-framework/pkg/core/tdtql"
 )
 
 // Compile-time check: Adapter должен реализовывать интерфейс adapters.Adapter
@@ -22,6 +24,7 @@ func init() {
 	adapters.Register("postgres", func() adapters.Adapter {
 		return &Adapter{}
 	})
+	adapters.Describe(adapters.DriverInfo{Type: "postgres", Driver: "github.com/jackc/pgx/v5"})
 }
 
 // Adapter представляет адаптер для работы с PostgreSQL
@@ -30,21 +33,75 @@ type Adapter struct {
 	pool   *pgxpool.Pool
 	schema string // public, custom, etc.
 
+	createSchemas bool // Config.CreateSchemas: CREATE SCHEMA при импорте "schema.table"
+
 	// Base helpers (added in refactoring)
 	exportHelper *base.ExportHelper
 	importHelper *base.ImportHelper
 	converter    *base.UniversalTypeConverter
+	governor     *adapters.Governor // ограничение темпа импорта (nil — без ограничений)
+	streamBatch  int                // пакетов в транзакции ImportPacketStream
+
+	packetKeys  base.PacketKeyProvider       // расшифровка пакетов TDTP v1.5 при импорте
+	keyMapper   base.KeyMapper               // суррогатные ключи при импорте
+	cipher      adapters.ColumnCipher        // шифрование колонок at rest (Config.ColumnCipher)
+	queryLog    *adapters.QueryLogger        // журнал SQL-выражений (Config.QueryLog)
+	tableLock   adapters.TableLocking        // блокировка целевых таблиц (TryLockTable)
+	maintenance *adapters.MaintenanceWindows // окна разрушающих импортов (Config.Maintenance)
+	duplicates  adapters.DuplicateMode       // повторяющиеся ключи в пакете (base.DedupPacket)
+	columns     *base.ColumnMatcher          // поля пакета → колонки таблицы
+	rowErrors   adapters.RowErrorConfig      // строки, не приводимые к типам колонок (base.RejectPacketRows)
 }
 
 // Connect устанавливает подключение к PostgreSQL
 // Реализует интерфейс adapters.Adapter
 func (a *Adapter) Connect(ctx context.Context, cfg adapters.Config) error {
+	governor, err := adapters.NewGovernor(cfg.ImportLimits)
+	if err != nil {
+		return fmt.Errorf("invalid import limits: %w", err)
+	}
+	a.governor = governor
+	a.streamBatch = cfg.StreamBatchPackets
+	if err := cfg.TableLock.Validate(); err != nil {
+		return err
+	}
+	a.tableLock = cfg.TableLock
+	a.maintenance = cfg.Maintenance
+	if err := cfg.Duplicates.Validate(); err != nil {
+		return err
+	}
+	a.duplicates = cfg.Duplicates
+	if err := cfg.Columns.Validate(); err != nil {
+		return err
+	}
+	a.columns = base.NewColumnMatcher(a, cfg.Columns)
+	a.cipher = cfg.ColumnCipher
+	if err := cfg.RowErrors.Validate(); err != nil {
+		return err
+	}
+	if cfg.RowErrors.PartialRollback {
+		return fmt.Errorf("row error partial rollback is not supported by the postgres adapter")
+	}
+	a.rowErrors = cfg.RowErrors
+
 	// Парсим connection string
 	config, err := pgxpool.ParseConfig(cfg.DSN)
 	if err != nil {
 		return fmt.Errorf("failed to parse connection string: %w", err)
 	}
 
+	// search_path: неквалифицированные имена — в схеме адаптера (если DSN
+	// не задаёт search_path сам)
+	if sp := searchPath(cfg.Schema); sp != "" {
+		if _, ok := config.ConnConfig.RuntimeParams["search_path"]; !ok {
+			config.ConnConfig.RuntimeParams["search_path"] = sp
+		}
+	}
+
+	// Журнал выражений: трассировщик ставится всегда, включается на лету
+	a.queryLog = adapters.NewQueryLogger(cfg.QueryLog)
+	config.ConnConfig.Tracer = &queryTracer{log: a.queryLog}
+
 	// Настраиваем pool из конфига
 	if cfg.MaxConns > 0 && cfg.MaxConns <= math.MaxInt32 {
 		config.MaxConns = int32(cfg.MaxConns) //nolint:gosec
@@ -71,6 +128,7 @@ func (a *Adapter) Connect(ctx context.Context, cfg adapters.Config) error {
 	}
 
 	a.pool = pool
+	a.createSchemas = cfg.CreateSchemas
 	a.schema = cfg.Schema
 	if a.schema == "" {
 		a.schema = "public" // default schema
@@ -78,10 +136,35 @@ func (a *Adapter) Connect(ctx context.Context, cfg adapters.Config) error {
 
 	// Initialize base helpers (added in refactoring)
 	a.initHelpers(cfg.NoDateSentinels)
+	if cfg.SourceTimezone != "" {
+		if err := a.converter.SetSourceTimezone(cfg.SourceTimezone); err != nil {
+			pool.Close()
+			return err
+		}
+	}
+	if err := a.converter.SetBoolMappings(cfg.Booleans, cfg.ColumnBooleans); err != nil {
+		pool.Close()
+		return err
+	}
+	a.exportHelper.SetColumnCipher(cfg.ColumnCipher)
+	a.exportHelper.SetExportPolicy(cfg.ExportPolicy)
+	if err := a.exportHelper.SetPacketSizing(cfg.Packets); err != nil {
+		pool.Close()
+		return err
+	}
 
 	return nil
 }
 
+// TimezoneReport реализует adapters.TimezoneReporter: отчёт о переводе
+// naive datetime из Config.SourceTimezone в UTC (nil — пояс не задан).
+func (a *Adapter) TimezoneReport() *adapters.TimezoneReport {
+	if a.converter == nil {
+		return nil
+	}
+	return a.converter.TimezoneReport()
+}
+
 // initHelpers initializes base package helpers for common operations
 // Added during refactoring to eliminate code duplication
 func (a *Adapter) initHelpers(noDateSentinels []string) {
@@ -92,17 +175,16 @@ func (a *Adapter) initHelpers(noDateSentinels []string) {
 	}
 
 	// Initialize export helper with PostgreSQL-specific components.
-	// For non-public schemas, SQLAdapter qualifies table names: "schema"."table".
-	var sqlAdapter base.SQLAdapter
-	if a.schema != "public" && a.schema != "" {
-		sqlAdapter = base.NewPostgreSQLSchemaAdapter(a.schema)
-	}
+	// SQLAdapter qualifies table names: "schema"."table" (non-public schema
+	// or a qualified "schema.table" name).
 	a.exportHelper = base.NewExportHelper(
 		a,           // SchemaReader
 		a,           // DataReader
 		a.converter, // ValueConverter
-		sqlAdapter,  // nil for public schema, PostgreSQLSchemaAdapter otherwise
+		base.NewPostgreSQLSchemaAdapter(a.schema),
 	)
+	a.exportHelper.SetJoinPushdown(true) // JOIN-таблицу квалифицирует тот же SQLAdapter
+	a.exportHelper.SetRegexPushdown(tdtql.RegexPostgres)
 
 	// Initialize import helper with temporary tables for atomic replace
 	a.importHelper = base.NewImportHelper(
@@ -136,6 +218,11 @@ func NewAdapterWithSchema(connString, schema string) (*Adapter, error) {
 	return adapter, nil
 }
 
+// QueryLog реализует adapters.QueryLogged: журнал выражений адаптера.
+func (a *Adapter) QueryLog() *adapters.QueryLogger {
+	return a.queryLog
+}
+
 // Close закрывает connection pool
 // Реализует интерфейс adapters.Adapter
 func (a *Adapter) Close(ctx context.Context) error {
@@ -187,8 +274,9 @@ func (a *Adapter) TableExists(ctx context.Context, tableName string) (bool, erro
 		)
 	`
 
+	schema, table := a.splitTable(tableName)
 	var exists bool
-	err := a.pool.QueryRow(ctx, query, a.schema, tableName).Scan(&exists)
+	err := a.pool.QueryRow(ctx, query, schema, table).Scan(&exists)
 	if err != nil {
 		return false, fmt.Errorf("failed to check table existence: %w", err)
 	}
@@ -279,6 +367,8 @@ type postgresTx struct {
 	tx pgx.Tx
 }
 
+var _ adapters.SavepointTx = (*postgresTx)(nil)
+
 func (t *postgresTx) Commit(ctx context.Context) error {
 	return t.tx.Commit(ctx)
 }
@@ -287,6 +377,31 @@ func (t *postgresTx) Rollback(ctx context.Context) error {
 	return t.tx.Rollback(ctx)
 }
 
+// Savepoint создаёт точку сохранения (adapters.SavepointTx)
+func (t *postgresTx) Savepoint(ctx context.Context, name string) error {
+	return base.StandardSavepoints.Savepoint(ctx, pgxExecer{t.tx}, name)
+}
+
+// RollbackTo откатывает транзакцию к точке сохранения
+func (t *postgresTx) RollbackTo(ctx context.Context, name string) error {
+	return base.StandardSavepoints.RollbackTo(ctx, pgxExecer{t.tx}, name)
+}
+
+// ReleaseSavepoint освобождает точку сохранения
+func (t *postgresTx) ReleaseSavepoint(ctx context.Context, name string) error {
+	return base.StandardSavepoints.ReleaseSavepoint(ctx, pgxExecer{t.tx}, name)
+}
+
+// pgxExecer — pgx.Tx как base.SQLExecer
+type pgxExecer struct {
+	tx pgx.Tx
+}
+
+func (e pgxExecer) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	_, err := e.tx.Exec(ctx, query, args...)
+	return nil, err
+}
+
 // Exec выполняет SQL команду (helper метод)
 func (a *Adapter) Exec(ctx context.Context, sql string, args ...any) error {
 	_, err := a.pool.Exec(ctx, sql, args...)
@@ -321,12 +436,14 @@ func (a *Adapter) GetDatabaseVersion(ctx context.Context) (string, error) {
 	return version, nil
 }
 
-// GetSchemas возвращает список всех схем в БД (helper метод)
+// GetSchemas возвращает список пользовательских схем БД.
+// Реализует adapters.SchemaLister
 func (a *Adapter) GetSchemas(ctx context.Context) ([]string, error) {
 	query := `
 		SELECT schema_name
 		FROM information_schema.schemata
 		WHERE schema_name NOT IN ('pg_catalog', 'information_schema')
+		  AND schema_name NOT LIKE 'pg\_%'
 		ORDER BY schema_name
 	`
 
@@ -387,6 +504,30 @@ func (a *Adapter) ExecuteRawQuery(ctx context.Context, query string) (*packet.Da
 	return dataPacket, nil
 }
 
+// queryResultField строит поле схемы по описанию колонки результата
+// (RowDescription): OID типа и typmod. typmod хранит длину varchar/char
+// и точность numeric со смещением 4 (VARHDRSZ); -1 — не задан.
+func queryResultField(name string, oid uint32, typmod int32) packet.Field {
+	tdtpType, length := convertPostgresTypeToTDTP(oid)
+	field := packet.Field{Name: name, Type: tdtpType, Length: length}
+
+	switch oid {
+	case 1042, 1043: // BPCHAR, VARCHAR
+		if typmod > 4 {
+			field.Length = int(typmod - 4)
+		}
+	case 1700: // NUMERIC
+		if typmod >= 4 {
+			field.Type = "DECIMAL"
+			field.Precision = int((typmod - 4) >> 16 & 0xffff)
+			field.Scale = int((typmod - 4) & 0xffff)
+		}
+	case 1184: // TIMESTAMPTZ
+		field.Timezone = "UTC"
+	}
+	return field
+}
+
 // convertPostgresTypeToTDTP конвертирует PostgreSQL OID тип в TDTP тип
 func convertPostgresTypeToTDTP(oid uint32) (string, int) {
 	// PostgreSQL OID константы из pgtype
@@ -0,0 +1,56 @@
+package postgres
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters/base"
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+	"github.com/ruslano69/tdtp-framework/pkg/sync"
+)
+
+func TestDecodeWal2JSON(t *testing.T) {
+	a := &Adapter{schema: "public", converter: base.NewUniversalTypeConverter()}
+	schema := packet.Schema{Fields: []packet.Field{
+		{Name: "id", Type: "INTEGER", Key: true},
+		{Name: "name", Type: "TEXT"},
+		{Name: "active", Type: "BOOLEAN"},
+		{Name: "amount", Type: "DECIMAL"},
+	}}
+
+	change, ok, err := a.decodeWal2JSON("0/16B3748",
+		`{"action":"U","schema":"public","table":"users","columns":[{"name":"id","type":"integer","value":7},{"name":"name","type":"text","value":"Иван"},{"name":"active","type":"boolean","value":true},{"name":"amount","type":"numeric","value":null},{"name":"added_later","type":"text","value":"x"}],"identity":[{"name":"id","type":"integer","value":5}]}`,
+		schema)
+	if err != nil || !ok {
+		t.Fatalf("decodeWal2JSON() ok = %v, err = %v", ok, err)
+	}
+	want := sync.Change{
+		LSN: "0/16B3748",
+		Op:  sync.ChangeUpdate,
+		Columns: []sync.ChangeColumn{
+			{Name: "id", Value: "7"}, {Name: "name", Value: "Иван"},
+			{Name: "active", Value: "1"}, {Name: "amount", Value: base.NullSentinel},
+		},
+		Identity: []sync.ChangeColumn{{Name: "id", Value: "5"}},
+	}
+	if !reflect.DeepEqual(change, want) {
+		t.Errorf("change = %+v, want %+v", change, want)
+	}
+
+	if _, ok, err := a.decodeWal2JSON("0/1", `{"action":"C"}`, schema); ok || err != nil {
+		t.Errorf("commit record: ok = %v, err = %v", ok, err)
+	}
+	if _, _, err := a.decodeWal2JSON("0/1", `{"action":"T","schema":"public","table":"users"}`, schema); err == nil {
+		t.Error("TRUNCATE: expected error")
+	}
+}
+
+func TestCDCTableFilter(t *testing.T) {
+	a := &Adapter{schema: "sales"}
+	if got := a.cdcTableFilter("orders"); got != "sales.orders" {
+		t.Errorf("cdcTableFilter(orders) = %q", got)
+	}
+	if got := a.cdcTableFilter("public.my table"); got != `public.my\ table` {
+		t.Errorf("cdcTableFilter(public.my table) = %q", got)
+	}
+}
@@ -6,8 +6,10 @@ import (
 
 	"github.com/jackc/pgx/v5"
 	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+	"github.com/ruslano69/tdtp-framework/pkg/adapters/base"
 	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
 	"github.com/ruslano69/tdtp-framework/pkg/core/tdtql"
+	"github.com/ruslano69/tdtp-framework/pkg/sync"
 )
 
 // GetTableSchema читает схему таблицы из PostgreSQL через information_schema
@@ -22,14 +24,16 @@ func (a *Adapter) GetTableSchema(ctx context.Context, tableName string) (packet.
 			numeric_precision,
 			numeric_scale,
 			is_nullable,
-			column_default
+			column_default,
+			col_description((quote_ident(table_schema) || '.' || quote_ident(table_name))::regclass, ordinal_position::int)
 		FROM information_schema.columns
 		WHERE table_schema = $1
 		  AND table_name = $2
 		ORDER BY ordinal_position
 	`
 
-	rows, err := a.pool.Query(ctx, query, a.schema, tableName)
+	schemaName, table := a.splitTable(tableName)
+	rows, err := a.pool.Query(ctx, query, schemaName, table)
 	if err != nil {
 		return packet.Schema{}, fmt.Errorf("failed to get table schema: %w", err)
 	}
@@ -52,9 +56,10 @@ func (a *Adapter) GetTableSchema(ctx context.Context, tableName string) (packet.
 			numScale     *int
 			isNullable   string
 			columnDef    *string
+			comment      *string
 		)
 
-		if err := rows.Scan(&columnName, &dataType, &charMaxLen, &numPrecision, &numScale, &isNullable, &columnDef); err != nil {
+		if err := rows.Scan(&columnName, &dataType, &charMaxLen, &numPrecision, &numScale, &isNullable, &columnDef, &comment); err != nil {
 			return packet.Schema{}, fmt.Errorf("failed to scan column info: %w", err)
 		}
 
@@ -80,6 +85,9 @@ func (a *Adapter) GetTableSchema(ctx context.Context, tableName string) (packet.
 		if err != nil {
 			return packet.Schema{}, fmt.Errorf("failed to build field %s: %w", columnName, err)
 		}
+		if comment != nil {
+			field.Description = *comment
+		}
 
 		fields = append(fields, field)
 	}
@@ -89,10 +97,61 @@ func (a *Adapter) GetTableSchema(ctx context.Context, tableName string) (packet.
 	}
 
 	if len(fields) == 0 {
-		return packet.Schema{}, fmt.Errorf("table %s.%s not found or has no columns", a.schema, tableName)
+		return packet.Schema{}, fmt.Errorf("table %s.%s %w", schemaName, table, adapters.ErrTableNotFound)
+	}
+
+	// Комментарий таблицы (COMMENT ON TABLE)
+	var tableComment *string
+	if err := a.pool.QueryRow(ctx,
+		`SELECT obj_description((quote_ident($1) || '.' || quote_ident($2))::regclass, 'pg_class')`,
+		schemaName, table).Scan(&tableComment); err != nil {
+		return packet.Schema{}, fmt.Errorf("failed to get table comment: %w", err)
 	}
 
-	return packet.Schema{Fields: fields}, nil
+	pkgSchema := packet.Schema{Fields: fields}
+	if tableComment != nil {
+		pkgSchema.Description = *tableComment
+	}
+	return pkgSchema, nil
+}
+
+// TableStatistics возвращает оценки статистики таблицы из каталога:
+// pg_class.reltuples и pg_stats (доля NULL, n_distinct, границы гистограммы).
+// Реализует adapters.StatisticsReader.
+func (a *Adapter) TableStatistics(ctx context.Context, tableName string) (*adapters.TableStatistics, error) {
+	schemaName, table := a.splitTable(tdtql.StripBrackets(tableName))
+
+	var reltuples float64
+	err := a.pool.QueryRow(ctx,
+		`SELECT reltuples::float8 FROM pg_class WHERE oid = (quote_ident($1) || '.' || quote_ident($2))::regclass`,
+		schemaName, table).Scan(&reltuples)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read table statistics: %w", err)
+	}
+	stats := &adapters.TableStatistics{RowCount: int64(reltuples)}
+
+	rows, err := a.pool.Query(ctx, `
+		SELECT s.attname, s.null_frac::float8, s.n_distinct::float8, s.histogram_bounds::text::text[]
+		FROM pg_stats s
+		JOIN pg_attribute a ON a.attrelid = (quote_ident($1) || '.' || quote_ident($2))::regclass AND a.attname = s.attname
+		WHERE s.schemaname = $1 AND s.tablename = $2 AND NOT s.inherited
+		ORDER BY a.attnum
+	`, schemaName, table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read column statistics: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var col adapters.ColumnStatistics
+		if err := rows.Scan(&col.Name, &col.NullFraction, &col.Distinct, &col.Histogram); err != nil {
+			return nil, fmt.Errorf("failed to scan column statistics: %w", err)
+		}
+		stats.Columns = append(stats.Columns, col)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read column statistics: %w", err)
+	}
+	return stats, nil
 }
 
 // getPrimaryKeyColumns возвращает список колонок в Primary Key
@@ -106,7 +165,8 @@ func (a *Adapter) getPrimaryKeyColumns(ctx context.Context, tableName string) ([
 		ORDER BY array_position(i.indkey, a.attnum)
 	`
 
-	rows, err := a.pool.Query(ctx, query, a.schema, tableName)
+	schema, table := a.splitTable(tableName)
+	rows, err := a.pool.Query(ctx, query, schema, table)
 	if err != nil {
 		// Если таблица не найдена, возвращаем пустой список
 		return []string{}, nil
@@ -125,16 +185,83 @@ func (a *Adapter) getPrimaryKeyColumns(ctx context.Context, tableName string) ([
 	return pkColumns, rows.Err()
 }
 
+// GetQuerySchema возвращает схему результата SELECT без его выполнения:
+// запрос только подготавливается (Parse/Describe), типы колонок — по OID,
+// длина varchar и точность numeric — по typmod.
+// Реализует base.QuerySchemaReader для собственных запросов таблиц.
+func (a *Adapter) GetQuerySchema(ctx context.Context, query string) (packet.Schema, error) {
+	conn, err := a.pool.Acquire(ctx)
+	if err != nil {
+		return packet.Schema{}, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	desc, err := conn.Conn().PgConn().Prepare(ctx, "", query, nil)
+	if err != nil {
+		return packet.Schema{}, fmt.Errorf("failed to prepare query: %w", err)
+	}
+
+	schema := packet.Schema{Fields: make([]packet.Field, len(desc.Fields))}
+	for i, fd := range desc.Fields {
+		schema.Fields[i] = queryResultField(fd.Name, fd.DataTypeOID, fd.TypeModifier)
+	}
+	return schema, nil
+}
+
+// SetMaxMessageSize задаёт максимальный размер одного TDTP пакета (в байтах).
+// Используется CLI для передачи --packet-size.
+func (a *Adapter) SetMaxMessageSize(size int) {
+	a.exportHelper.SetMaxMessageSize(size)
+}
+
 // SetSkipSpecialValues включает режим --fast: DetectAndApply пропускается.
 func (a *Adapter) SetSkipSpecialValues(skip bool) {
 	a.exportHelper.SetSkipSpecialValues(skip)
 }
 
+// SetCompression включает zstd-сжатие Data экспортируемых пакетов
+// (см. base.ExportHelper.SetCompression).
+func (a *Adapter) SetCompression(opts packet.CompressionOptions) {
+	a.exportHelper.SetCompression(opts)
+}
+
+// SetPacketKeys включает шифрование пакетов TDTP v1.5 при экспорте и их
+// расшифровку при импорте (см. base.ExportHelper.SetPacketKeys).
+func (a *Adapter) SetPacketKeys(keys base.PacketKeyProvider) {
+	a.packetKeys = keys
+	a.exportHelper.SetPacketKeys(keys)
+	a.importHelper.SetPacketKeys(keys)
+}
+
+// SetKeyMapper задаёт выдачу суррогатных ключей при импорте (keymap.Mapper).
+func (a *Adapter) SetKeyMapper(m base.KeyMapper) {
+	a.keyMapper = m
+	a.importHelper.SetKeyMapper(m)
+}
+
 // SetMaxFallbackRows задаёт лимит строк для in-memory fallback при провале SQL pushdown.
 func (a *Adapter) SetMaxFallbackRows(n int64) {
 	a.exportHelper.SetMaxFallbackRows(n)
 }
 
+// SetTableQueries задаёт собственные SELECT для таблиц вместо SELECT *
+// (см. base.ExportHelper.SetTableQueries).
+func (a *Adapter) SetTableQueries(queries map[string]string) error {
+	return a.exportHelper.SetTableQueries(queries)
+}
+
+// ExportQuery экспортирует результат произвольного read-only SQL
+// (adapters.QueryExporter, см. base.ExportHelper.ExportQuery).
+func (a *Adapter) ExportQuery(ctx context.Context, sql string) ([]*packet.DataPacket, error) {
+	return a.exportHelper.ExportQuery(ctx, sql)
+}
+
+// ExplainQuery возвращает план ExportTableWithQuery без чтения строк
+// (adapters.QueryExplainer, см. base.ExportHelper.Explain).
+func (a *Adapter) ExplainQuery(ctx context.Context, tableName string, query *packet.Query) (*packet.ExecutionPlan, error) {
+	return a.exportHelper.Explain(ctx, tableName, query)
+}
+
 // ExportTable экспортирует таблицу в TDTP reference пакеты
 // Делегирует в base.ExportHelper для устранения дублирования кода
 func (a *Adapter) ExportTable(ctx context.Context, tableName string) ([]*packet.DataPacket, error) {
@@ -188,12 +315,33 @@ func (a *Adapter) convertValueToTDTP(field packet.Field, value string) string {
 }
 
 // ExportTableIncremental экспортирует только измененные записи с момента последней синхронизации
-// Реализует интерфейс adapters.Adapter
+// Реализует интерфейс adapters.Adapter. Пакеты проходят тот же финальный
+// шаг, что и полный экспорт (base.ExportHelper.Seal).
 func (a *Adapter) ExportTableIncremental(ctx context.Context, tableName string, incrementalConfig adapters.IncrementalConfig) ([]*packet.DataPacket, string, error) {
+	packets, last, err := a.exportTableIncremental(ctx, tableName, incrementalConfig)
+	if err != nil {
+		return nil, "", err
+	}
+	if packets, err = a.exportHelper.Seal(ctx, packets); err != nil {
+		return nil, "", err
+	}
+	return packets, last, nil
+}
+
+func (a *Adapter) exportTableIncremental(ctx context.Context, tableName string, incrementalConfig adapters.IncrementalConfig) ([]*packet.DataPacket, string, error) {
 	// Валидация конфигурации
 	if err := incrementalConfig.Validate(); err != nil {
 		return nil, "", fmt.Errorf("invalid incremental config: %w", err)
 	}
+	if incrementalConfig.Deletes.Strategy == sync.DeleteLog {
+		return nil, "", fmt.Errorf("delete log is read separately with its own checkpoint (sync.ReadDeleteLog)")
+	}
+	if incrementalConfig.Strategy == sync.TrackingCDC {
+		return a.exportTableCDC(ctx, tableName, incrementalConfig)
+	}
+	if incrementalConfig.Strategy == sync.TrackingChangeTable {
+		return nil, "", fmt.Errorf("changetable tracking is SQL Server change tracking: use cdc tracking for PostgreSQL")
+	}
 
 	// Получаем схему
 	pkgSchema, err := a.GetTableSchema(ctx, tableName)
@@ -217,10 +365,7 @@ func (a *Adapter) ExportTableIncremental(ctx context.Context, tableName string,
 	}
 
 	// Формируем SQL запрос с WHERE условием для инкрементальной выгрузки
-	quotedTable := QuoteIdentifier(tableName)
-	if a.schema != "public" {
-		quotedTable = QuoteIdentifier(a.schema) + "." + quotedTable
-	}
+	quotedTable := a.qualify(tableName)
 
 	quotedTrackingField := QuoteIdentifier(incrementalConfig.TrackingField)
 
@@ -299,14 +444,30 @@ func (a *Adapter) ExportTableIncremental(ctx context.Context, tableName string,
 		return []*packet.DataPacket{}, incrementalConfig.InitialValue, nil
 	}
 
+	// Мягко удалённые строки уходят ключами в пакеты удаления (tombstones)
+	var deletedKeys [][]string
+	if incrementalConfig.Deletes.Strategy == sync.DeleteSoft {
+		dataRows, deletedKeys, err = sync.SplitSoftDeleted(pkgSchema, dataRows, incrementalConfig.Deletes)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
 	// Генерируем пакеты
-	generator := packet.NewGenerator()
-	packets, err := generator.GenerateReference(tableName, pkgSchema, dataRows)
+	var packets []*packet.DataPacket
+	if len(dataRows) > 0 {
+		generator := packet.NewGenerator()
+		packets, err = generator.GenerateReference(tableName, pkgSchema, dataRows)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to generate packets: %w", err)
+		}
+	}
+	tombstones, err := sync.TombstonePackets(tableName, pkgSchema, deletedKeys)
 	if err != nil {
-		return nil, "", fmt.Errorf("failed to generate packets: %w", err)
+		return nil, "", err
 	}
 
-	return packets, lastTrackingValue, nil
+	return append(packets, tombstones...), lastTrackingValue, nil
 }
 
 // ========== base.DataReader interface methods ==========
@@ -316,10 +477,7 @@ func (a *Adapter) ExportTableIncremental(ctx context.Context, tableName string,
 // Note: must NOT call ExportTable (avoids circular call via exportHelper.ExportTable → ReadAllRows).
 func (a *Adapter) ReadAllRows(ctx context.Context, tableName string, pkgSchema packet.Schema) ([][]string, error) {
 	tableName = tdtql.StripBrackets(tableName)
-	quotedTable := QuoteIdentifier(tableName)
-	if a.schema != "public" {
-		quotedTable = QuoteIdentifier(a.schema) + "." + quotedTable
-	}
+	quotedTable := a.qualify(tableName)
 	sql := fmt.Sprintf("SELECT * FROM %s", quotedTable)
 	return a.readRowsWithSQL(ctx, sql, pkgSchema)
 }
@@ -334,10 +492,7 @@ func (a *Adapter) ReadRowsWithSQL(ctx context.Context, sqlQuery string, pkgSchem
 // Returns the number of rows in a table
 func (a *Adapter) GetRowCount(ctx context.Context, tableName string) (int64, error) {
 	tableName = tdtql.StripBrackets(tableName)
-	quotedTable := QuoteIdentifier(tableName)
-	if a.schema != "public" {
-		quotedTable = QuoteIdentifier(a.schema) + "." + quotedTable
-	}
+	quotedTable := a.qualify(tableName)
 
 	countSQL := fmt.Sprintf("SELECT COUNT(*) FROM %s", quotedTable)
 	var count int64
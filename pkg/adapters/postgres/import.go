@@ -8,6 +8,7 @@ import (
 
 	"github.com/jackc/pgx/v5"
 	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+	"github.com/ruslano69/tdtp-framework/pkg/adapters/base"
 	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
 	"github.com/ruslano69/tdtp-framework/pkg/core/schema"
 )
@@ -20,12 +21,136 @@ var sharedSchemaConverter = schema.NewConverter()
 // экранирования \|, \\, \n). UTF-8-safe, в отличие от прежнего локального parseRow.
 var sharedRowParser = packet.NewParser()
 
-// ImportPacket импортирует один TDTP пакет в PostgreSQL.
-// StrategyCopy: атомарная замена таблицы через временную (temp → rename).
-// StrategyReplace/Ignore/Fail: прямой INSERT с ON CONFLICT в существующую таблицу.
-// Реализует интерфейс adapters.Adapter
+// ImportPacket импортирует один TDTP пакет в БД под ограничениями
+// ImportLimits (см. adapters.Governor).
 func (a *Adapter) ImportPacket(ctx context.Context, pkt *packet.DataPacket, strategy adapters.ImportStrategy) error {
+	ctx = adapters.WithMessageID(ctx, pkt.Header.MessageID) // msg= в журнале выражений
+	if err := base.PrepareImport(ctx, pkt, a.packetKeys, a.keyMapper, a.cipher); err != nil {
+		return err
+	}
+	if _, err := base.DedupPacket(pkt, a.duplicates); err != nil {
+		return err
+	}
+	if err := base.ApplyImportProvenance(ctx, a, []*packet.DataPacket{pkt}); err != nil {
+		return err
+	}
+	// StrategyCopy заменяет таблицу новой по схеме пакета
+	if strategy != adapters.StrategyCopy {
+		matched, err := a.columns.Match(ctx, []*packet.DataPacket{pkt})
+		if err != nil {
+			return err
+		}
+		pkt = matched[0]
+	} else {
+		defer a.columns.Forget(pkt.Header.TableName) // у новой таблицы схема пакета
+	}
+	if err := base.RejectPacketRows([]*packet.DataPacket{pkt}, a.rowErrors, a.converter, "postgres", &adapters.ImportReport{}); err != nil {
+		return err
+	}
+	// Замена (StrategyCopy) и очистка таблицы — только в окне обслуживания
+	destructive := strategy == adapters.StrategyCopy || strategy == adapters.StrategyTruncate
+	if err := base.AwaitMaintenance(ctx, a.maintenance, destructive, []*packet.DataPacket{pkt}); err != nil {
+		return err
+	}
+	ctx, release, err := base.LockTables(ctx, a, a.tableLock, pkt.Header.TableName)
+	if err != nil {
+		return err
+	}
+	defer release()
+	return a.governor.Do(ctx, len(pkt.Data.Rows), func() error {
+		return a.importPacket(ctx, pkt, strategy)
+	})
+}
+
+// ImportPackets импортирует множество пакетов атомарно под ограничениями
+// ImportLimits: вся пачка — одна транзакция для Governor.
+func (a *Adapter) ImportPackets(ctx context.Context, packets []*packet.DataPacket, strategy adapters.ImportStrategy) error {
+	return a.importPacketsReport(ctx, packets, strategy, &adapters.ImportReport{Strategy: strategy})
+}
+
+// ImportPacketsReport реализует adapters.ReportingImporter: ImportPackets
+// с отчётом об отклонённых строках (Config.RowErrors).
+func (a *Adapter) ImportPacketsReport(ctx context.Context, packets []*packet.DataPacket, strategy adapters.ImportStrategy) (*adapters.ImportReport, error) {
+	report := &adapters.ImportReport{Strategy: strategy}
+	return report, a.importPacketsReport(ctx, packets, strategy, report)
+}
+
+// importPacketsReport — тело ImportPackets/ImportPacketsReport.
+func (a *Adapter) importPacketsReport(ctx context.Context, packets []*packet.DataPacket, strategy adapters.ImportStrategy, report *adapters.ImportReport) error {
+	var tables []string
+	for _, pkt := range packets {
+		if pkt != nil {
+			if err := base.PrepareImport(ctx, pkt, a.packetKeys, a.keyMapper, a.cipher); err != nil {
+				return err
+			}
+			if _, err := base.DedupPacket(pkt, a.duplicates); err != nil {
+				return err
+			}
+			tables = append(tables, pkt.Header.TableName)
+		}
+	}
+	if err := base.ApplyImportProvenance(ctx, a, packets); err != nil {
+		return err
+	}
+	if strategy != adapters.StrategyCopy {
+		var err error
+		if packets, err = a.columns.Match(ctx, packets); err != nil {
+			return err
+		}
+	} else {
+		defer a.columns.Forget(tables...) // у новых таблиц схемы пакетов
+	}
+	if err := base.RejectPacketRows(packets, a.rowErrors, a.converter, "postgres", report); err != nil {
+		return err
+	}
+	rows := 0
+	for _, pkt := range packets {
+		if pkt != nil {
+			rows += len(pkt.Data.Rows)
+		}
+	}
+	// Замена (StrategyCopy) и очистка таблицы — только в окне обслуживания
+	destructive := strategy == adapters.StrategyCopy || strategy == adapters.StrategyTruncate
+	if err := base.AwaitMaintenance(ctx, a.maintenance, destructive, packets); err != nil {
+		return err
+	}
+	ctx, release, err := base.LockTables(ctx, a, a.tableLock, tables...)
+	if err != nil {
+		return err
+	}
+	defer release()
+	return a.governor.Do(ctx, rows, func() error {
+		return a.importPackets(ctx, packets, strategy)
+	})
+}
+
+// ImportPacketStream импортирует пакеты из канала пачками по
+// Config.StreamBatchPackets, каждая пачка — ImportPackets в своей транзакции.
+// Реализует adapters.PacketStreamImporter.
+func (a *Adapter) ImportPacketStream(ctx context.Context, packets <-chan *packet.DataPacket, strategy adapters.ImportStrategy) error {
+	return adapters.ImportPacketBatches(ctx, packets, strategy, a.streamBatch, a.ImportPackets)
+}
+
+// DryRunImport реализует adapters.DryRunImporter: проверка пакетов против
+// БД без записи (base.DryRun).
+func (a *Adapter) DryRunImport(ctx context.Context, packets []*packet.DataPacket, strategy adapters.ImportStrategy) (*adapters.ImportReport, error) {
+	d := &base.DryRun{Target: a, Converter: a.converter, DBType: "postgres", Keys: a.packetKeys, Duplicates: a.duplicates, Columns: a.columns.Mode(), Cipher: a.cipher}
+	return d.Import(ctx, packets, strategy)
+}
+
+// importPacket импортирует один TDTP пакет в PostgreSQL.
+// StrategyCopy: атомарная замена таблицы через временную (temp → rename).
+// StrategyReplace/Merge/Ignore/Fail: прямой INSERT с ON CONFLICT в существующую таблицу.
+// StrategyAppend/Truncate: COPY в целевую таблицу (bulkLoad).
+func (a *Adapter) importPacket(ctx context.Context, pkt *packet.DataPacket, strategy adapters.ImportStrategy) error {
 	pkt.MaterializeRows()
+	if pkt.Data.Delta {
+		return a.ApplyDelta(ctx, []*packet.DataPacket{pkt}, strategy)
+	}
+	if pkt.Data.Delete {
+		_, err := a.ApplyDelete(ctx, []*packet.DataPacket{pkt})
+		return err
+	}
 	tableName := pkt.Header.TableName
 
 	switch strategy {
@@ -43,7 +168,7 @@ func (a *Adapter) ImportPacket(ctx context.Context, pkt *packet.DataPacket, stra
 		tempPacket := *pkt
 		tempPacket.Header.TableName = tempTableName
 
-		if err = a.importWithCopy(ctx, &tempPacket); err != nil {
+		if err = a.importWithCopy(ctx, a.pool, &tempPacket); err != nil {
 			_ = a.dropTable(ctx, tempTableName)
 			return fmt.Errorf("failed to import to temporary table: %w", err)
 		}
@@ -59,25 +184,132 @@ func (a *Adapter) ImportPacket(ctx context.Context, pkt *packet.DataPacket, stra
 		fmt.Printf("✅ Production table replaced successfully\n")
 		return nil
 
-	case adapters.StrategyReplace, adapters.StrategyIgnore, adapters.StrategyFail:
+	case adapters.StrategyReplace, adapters.StrategyMerge, adapters.StrategyIgnore, adapters.StrategyFail:
 		// Убеждаемся что таблица существует, затем INSERT с ON CONFLICT
 		if err := a.createTableFromSchema(ctx, tableName, pkt.Schema); err != nil {
 			return fmt.Errorf("failed to create table: %w", err)
 		}
 		return a.importWithInsert(ctx, pkt, strategy)
 
+	case adapters.StrategyAppend, adapters.StrategyTruncate:
+		return a.bulkLoad(ctx, []*packet.DataPacket{pkt}, strategy)
+
 	default:
 		return fmt.Errorf("unknown import strategy: %s", strategy)
 	}
 }
 
-// ImportPackets импортирует множество пакетов атомарно через временную таблицу
+// bulkLoad загружает пакеты COPY прямо в целевую таблицу одной
+// транзакцией, без ON CONFLICT: дубликат ключа — ошибка COPY.
+// StrategyTruncate сначала очищает таблицу TRUNCATE в той же транзакции.
+func (a *Adapter) bulkLoad(ctx context.Context, packets []*packet.DataPacket, strategy adapters.ImportStrategy) error {
+	tableName := packets[0].Header.TableName
+	if err := a.createTableFromSchema(ctx, tableName, packets[0].Schema); err != nil {
+		return fmt.Errorf("failed to create table: %w", err)
+	}
+
+	tx, err := a.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if strategy == adapters.StrategyTruncate {
+		fmt.Printf("🧹 Truncating table: %s\n", tableName)
+		if _, err := tx.Exec(ctx, "TRUNCATE TABLE "+a.qualify(tableName)); err != nil {
+			return fmt.Errorf("failed to truncate table %s: %w", tableName, err)
+		}
+	}
+	for i, pkt := range packets {
+		if err := a.importWithCopy(adapters.WithMessageID(ctx, pkt.Header.MessageID), tx, pkt); err != nil {
+			return fmt.Errorf("failed to import packet %d: %w", i+1, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// ApplyDelta применяет delta-пакеты точечными UPDATE в одной транзакции.
+// Таблица должна существовать: delta-пакет не создаёт строк.
+func (a *Adapter) ApplyDelta(ctx context.Context, packets []*packet.DataPacket, strategy adapters.ImportStrategy) error {
+	total, err := a.applyKeyedPackets(ctx, packets, "delta", func(quotedTable string, pkt *packet.DataPacket, dialect base.DeltaDialect, exec base.DeltaExecFunc) (int, error) {
+		return base.ApplyDeltaPacket(ctx, quotedTable, pkt, strategy, dialect, exec)
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("✅ Delta applied: %d row(s) updated\n", total)
+	return nil
+}
+
+// ApplyDelete удаляет строки по ключам пакетов удаления в одной транзакции.
+// Реализует base.DeleteApplier
+func (a *Adapter) ApplyDelete(ctx context.Context, packets []*packet.DataPacket) (int, error) {
+	total, err := a.applyKeyedPackets(ctx, packets, "delete", func(quotedTable string, pkt *packet.DataPacket, dialect base.DeltaDialect, exec base.DeltaExecFunc) (int, error) {
+		return base.ApplyDeletePacket(ctx, quotedTable, pkt, dialect, exec)
+	})
+	if err != nil {
+		return 0, err
+	}
+	fmt.Printf("✅ Delete applied: %d row(s) removed\n", total)
+	return total, nil
+}
+
+// applyKeyedPackets выполняет apply для каждого пакета в одной pgx-транзакции
+// (delta- и delete-пакеты: операции по первичному ключу).
+func (a *Adapter) applyKeyedPackets(
+	ctx context.Context,
+	packets []*packet.DataPacket,
+	kind string,
+	apply func(quotedTable string, pkt *packet.DataPacket, dialect base.DeltaDialect, exec base.DeltaExecFunc) (int, error),
+) (int, error) {
+	tx, err := a.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	dialect := base.DeltaDialect{
+		Quote:       QuoteIdentifier,
+		Placeholder: func(n int) string { return fmt.Sprintf("$%d", n) },
+		Convert: func(value string, field packet.Field) (any, error) {
+			return a.convertValue(value, field), nil
+		},
+	}
+	exec := func(ctx context.Context, query string, args []any) (int64, error) {
+		tag, err := tx.Exec(ctx, query, append([]any{pgx.QueryExecModeSimpleProtocol}, args...)...)
+		if err != nil {
+			return 0, err
+		}
+		return tag.RowsAffected(), nil
+	}
+
+	total := 0
+	for i, pkt := range packets {
+		quotedTable := a.qualify(pkt.Header.TableName)
+		n, err := apply(quotedTable, pkt, dialect, exec)
+		if err != nil {
+			return 0, fmt.Errorf("failed to apply %s packet %d: %w", kind, i, err)
+		}
+		total += n
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return total, nil
+}
+
+// importPackets импортирует множество пакетов атомарно через временную таблицу
 // ImportPackets импортирует множество пакетов атомарно.
 // StrategyCopy: атомарная замена таблицы через временную (temp → rename).
-// StrategyReplace/Ignore/Fail: прямой INSERT с ON CONFLICT в существующую таблицу,
+// StrategyReplace/Merge/Ignore/Fail: прямой INSERT с ON CONFLICT в существующую таблицу,
 // что позволяет накапливать данные из нескольких источников/файлов без затирания.
-// Реализует интерфейс adapters.Adapter
-func (a *Adapter) ImportPackets(ctx context.Context, packets []*packet.DataPacket, strategy adapters.ImportStrategy) error {
+// StrategyAppend/Truncate: COPY в целевую таблицу (bulkLoad).
+func (a *Adapter) importPackets(ctx context.Context, packets []*packet.DataPacket, strategy adapters.ImportStrategy) error {
 	if len(packets) == 0 {
 		return nil
 	}
@@ -86,6 +318,22 @@ func (a *Adapter) ImportPackets(ctx context.Context, packets []*packet.DataPacke
 		pkt.MaterializeRows()
 	}
 
+	delta, err := base.IsDeltaBatch(packets)
+	if err != nil {
+		return err
+	}
+	if delta {
+		return a.ApplyDelta(ctx, packets, strategy)
+	}
+	if data, deletes := base.SplitDeletePackets(packets); len(deletes) > 0 {
+		// Tombstones инкрементальной выгрузки: сначала данные, затем удаления
+		if err := a.importPackets(ctx, data, strategy); err != nil {
+			return err
+		}
+		_, err := a.ApplyDelete(ctx, deletes)
+		return err
+	}
+
 	tableName := packets[0].Header.TableName
 
 	switch strategy {
@@ -111,7 +359,7 @@ func (a *Adapter) ImportPackets(ctx context.Context, packets []*packet.DataPacke
 			tempPacket := *pkt
 			tempPacket.Header.TableName = tempTableName
 
-			if err = a.importWithCopy(ctx, &tempPacket); err != nil {
+			if err = a.importWithCopy(adapters.WithMessageID(ctx, pkt.Header.MessageID), a.pool, &tempPacket); err != nil {
 				_ = a.dropTable(ctx, tempTableName)
 				return fmt.Errorf("failed to import packet %d: %w", i+1, err)
 			}
@@ -132,7 +380,7 @@ func (a *Adapter) ImportPackets(ctx context.Context, packets []*packet.DataPacke
 		fmt.Printf("✅ Production table replaced successfully\n")
 		return nil
 
-	case adapters.StrategyReplace, adapters.StrategyIgnore, adapters.StrategyFail:
+	case adapters.StrategyReplace, adapters.StrategyMerge, adapters.StrategyIgnore, adapters.StrategyFail:
 		// Убеждаемся что таблица существует, затем INSERT с ON CONFLICT для каждого пакета
 		if err := a.createTableFromSchema(ctx, tableName, packets[0].Schema); err != nil {
 			return fmt.Errorf("failed to create table: %w", err)
@@ -147,7 +395,7 @@ func (a *Adapter) ImportPackets(ctx context.Context, packets []*packet.DataPacke
 		for i, pkt := range packets {
 			fmt.Printf("  📦 Importing packet %d/%d\n", i+1, len(packets))
 
-			if err := a.importWithInsert(ctx, pkt, strategy); err != nil {
+			if err := a.importWithInsert(adapters.WithMessageID(ctx, pkt.Header.MessageID), pkt, strategy); err != nil {
 				return fmt.Errorf("failed to import packet %d: %w", i+1, err)
 			}
 		}
@@ -159,6 +407,13 @@ func (a *Adapter) ImportPackets(ctx context.Context, packets []*packet.DataPacke
 		fmt.Printf("✅ All %d packets imported successfully\n", len(packets))
 		return nil
 
+	case adapters.StrategyAppend, adapters.StrategyTruncate:
+		if err := a.bulkLoad(ctx, packets, strategy); err != nil {
+			return err
+		}
+		fmt.Printf("✅ All %d packets imported successfully\n", len(packets))
+		return nil
+
 	default:
 		return fmt.Errorf("unknown import strategy: %s", strategy)
 	}
@@ -172,15 +427,13 @@ func generateTempTableName(baseName string) string {
 
 // replaceTables заменяет продакшен таблицу временной (атомарная операция)
 func (a *Adapter) replaceTables(ctx context.Context, targetTable, tempTable string) error {
-	quotedTarget := QuoteIdentifier(targetTable)
-	quotedTemp := QuoteIdentifier(tempTable)
-	quotedOld := QuoteIdentifier(targetTable + "_old")
-
-	if a.schema != "public" {
-		quotedTarget = QuoteIdentifier(a.schema) + "." + quotedTarget
-		quotedTemp = QuoteIdentifier(a.schema) + "." + quotedTemp
-		quotedOld = QuoteIdentifier(a.schema) + "." + quotedOld
-	}
+	quotedTarget := a.qualify(targetTable)
+	quotedTemp := a.qualify(tempTable)
+	quotedOld := a.qualify(targetTable + "_old")
+	// RENAME TO принимает только имя — таблица остаётся в своей схеме
+	_, targetName := a.splitTable(targetTable)
+	_, oldName := a.splitTable(targetTable + "_old")
+	renamedTarget := QuoteIdentifier(targetName)
 
 	// Проверяем существует ли целевая таблица
 	exists, err := a.TableExists(ctx, targetTable)
@@ -191,16 +444,16 @@ func (a *Adapter) replaceTables(ctx context.Context, targetTable, tempTable stri
 	if exists {
 		// Если таблица существует - делаем атомарную замену
 		// 1. Переименовываем старую таблицу в _old
-		sql := fmt.Sprintf("ALTER TABLE %s RENAME TO %s", quotedTarget, quotedOld)
+		sql := fmt.Sprintf("ALTER TABLE %s RENAME TO %s", quotedTarget, QuoteIdentifier(oldName))
 		if err := a.Exec(ctx, sql); err != nil {
 			return fmt.Errorf("failed to rename old table: %w", err)
 		}
 
 		// 2. Переименовываем временную таблицу в продакшен
-		sql = fmt.Sprintf("ALTER TABLE %s RENAME TO %s", quotedTemp, quotedTarget)
+		sql = fmt.Sprintf("ALTER TABLE %s RENAME TO %s", quotedTemp, renamedTarget)
 		if err := a.Exec(ctx, sql); err != nil {
 			// Откатываем - возвращаем старое имя
-			rollbackSQL := fmt.Sprintf("ALTER TABLE %s RENAME TO %s", quotedOld, quotedTarget)
+			rollbackSQL := fmt.Sprintf("ALTER TABLE %s RENAME TO %s", quotedOld, renamedTarget)
 			_ = a.Exec(ctx, rollbackSQL)
 			return fmt.Errorf("failed to rename temp table: %w", err)
 		}
@@ -212,7 +465,7 @@ func (a *Adapter) replaceTables(ctx context.Context, targetTable, tempTable stri
 		}
 	} else {
 		// Если таблицы нет - просто переименовываем временную
-		sql := fmt.Sprintf("ALTER TABLE %s RENAME TO %s", quotedTemp, quotedTarget)
+		sql := fmt.Sprintf("ALTER TABLE %s RENAME TO %s", quotedTemp, renamedTarget)
 		if err := a.Exec(ctx, sql); err != nil {
 			return fmt.Errorf("failed to rename temp table: %w", err)
 		}
@@ -223,10 +476,7 @@ func (a *Adapter) replaceTables(ctx context.Context, targetTable, tempTable stri
 
 // dropTable удаляет таблицу
 func (a *Adapter) dropTable(ctx context.Context, tableName string) error {
-	quotedTable := QuoteIdentifier(tableName)
-	if a.schema != "public" {
-		quotedTable = QuoteIdentifier(a.schema) + "." + quotedTable
-	}
+	quotedTable := a.qualify(tableName)
 
 	sql := fmt.Sprintf("DROP TABLE IF EXISTS %s CASCADE", quotedTable)
 	return a.Exec(ctx, sql)
@@ -234,11 +484,6 @@ func (a *Adapter) dropTable(ctx context.Context, tableName string) error {
 
 // createTableFromSchema создает таблицу на основе TDTP схемы
 func (a *Adapter) createTableFromSchema(ctx context.Context, tableName string, pktSchema packet.Schema) error {
-	quotedTable := QuoteIdentifier(tableName)
-	if a.schema != "public" {
-		quotedTable = QuoteIdentifier(a.schema) + "." + quotedTable
-	}
-
 	// Проверяем существование таблицы
 	exists, err := a.TableExists(ctx, tableName)
 	if err != nil {
@@ -249,7 +494,31 @@ func (a *Adapter) createTableFromSchema(ctx context.Context, tableName string, p
 		return nil // Таблица уже существует
 	}
 
-	// Строим CREATE TABLE запрос
+	// Пакет "schema.table" — схема должна существовать (или создаётся)
+	if err := a.ensureSchema(ctx, tableName); err != nil {
+		return err
+	}
+
+	// CREATE TABLE — ошибка; COMMENT ON — только предупреждение
+	ddl := a.GenerateDDL(tableName, pktSchema)
+	if err := a.Exec(ctx, ddl[0]); err != nil {
+		return fmt.Errorf("failed to execute CREATE TABLE: %w\nSQL: %s", err, ddl[0])
+	}
+	for _, commentSQL := range ddl[1:] {
+		if cerr := a.Exec(ctx, commentSQL); cerr != nil {
+			fmt.Printf("warning: could not add comment: %v\n", cerr)
+		}
+	}
+
+	return nil
+}
+
+// GenerateDDL возвращает CREATE TABLE и COMMENT ON TABLE/COLUMN с
+// описаниями из источника и исходными именами санитизированных полей
+// (adapters.DDLGenerator).
+func (a *Adapter) GenerateDDL(tableName string, pktSchema packet.Schema) []string {
+	quotedTable := a.qualify(tableName)
+
 	columns := make([]string, 0, len(pktSchema.Fields))
 	var pkColumns []string
 
@@ -270,30 +539,27 @@ func (a *Adapter) createTableFromSchema(ctx context.Context, tableName string, p
 	}
 
 	createSQL += "\n)"
+	ddl := []string{createSQL}
 
-	// Выполняем CREATE TABLE
-	err = a.Exec(ctx, createSQL)
-	if err != nil {
-		return fmt.Errorf("failed to execute CREATE TABLE: %w\nSQL: %s", err, createSQL)
+	// Документация источника (Description) и исходные имена полей,
+	// переименованных санитизацией (OriginalName)
+	if pktSchema.Description != "" {
+		ddl = append(ddl, fmt.Sprintf("COMMENT ON TABLE %s IS %s", quotedTable, quoteLiteral(pktSchema.Description)))
 	}
-
-	// Add COMMENT ON COLUMN for fields that were sanitized (OriginalName is set)
 	for _, field := range pktSchema.Fields {
-		if field.OriginalName == "" {
+		comment := adapters.ColumnComment(field)
+		if comment == "" {
 			continue
 		}
-		commentSQL := fmt.Sprintf(
-			"COMMENT ON COLUMN %s.%s IS 'original: %s'",
+		ddl = append(ddl, fmt.Sprintf(
+			"COMMENT ON COLUMN %s.%s IS %s",
 			quotedTable,
 			QuoteIdentifier(field.Name),
-			strings.ReplaceAll(field.OriginalName, "'", "''"), // escape single quotes
-		)
-		if cerr := a.Exec(ctx, commentSQL); cerr != nil {
-			fmt.Printf("warning: could not add column comment for '%s': %v\n", field.Name, cerr)
-		}
+			quoteLiteral(comment),
+		))
 	}
 
-	return nil
+	return ddl
 }
 
 // buildColumnDefinition строит определение колонки для CREATE TABLE
@@ -304,16 +570,26 @@ func (a *Adapter) buildColumnDefinition(field packet.Field) string {
 	return fmt.Sprintf("%s %s", quotedName, pgType)
 }
 
+// AddColumns добавляет колонки в существующую таблицу (adapters.ColumnAdder)
+func (a *Adapter) AddColumns(ctx context.Context, tableName string, fields []packet.Field) error {
+	clauses := make([]string, len(fields))
+	for i, field := range fields {
+		clauses[i] = "ADD COLUMN IF NOT EXISTS " + a.buildColumnDefinition(field)
+	}
+	sql := fmt.Sprintf("ALTER TABLE %s %s", a.qualify(tableName), strings.Join(clauses, ", "))
+	if _, err := a.pool.Exec(ctx, sql); err != nil {
+		return fmt.Errorf("failed to add columns: %w", err)
+	}
+	return nil
+}
+
 // importWithInsert импортирует данные через INSERT
 func (a *Adapter) importWithInsert(ctx context.Context, pkt *packet.DataPacket, strategy adapters.ImportStrategy) error {
 	if len(pkt.Data.Rows) == 0 {
 		return nil
 	}
 
-	quotedTable := QuoteIdentifier(pkt.Header.TableName)
-	if a.schema != "public" {
-		quotedTable = QuoteIdentifier(a.schema) + "." + quotedTable
-	}
+	quotedTable := a.qualify(pkt.Header.TableName)
 
 	// Строим список колонок
 	columns := make([]string, 0, len(pkt.Schema.Fields))
@@ -325,7 +601,11 @@ func (a *Adapter) importWithInsert(ctx context.Context, pkt *packet.DataPacket,
 	insertSQL := fmt.Sprintf("INSERT INTO %s (%s) VALUES ", quotedTable, strings.Join(columns, ", "))
 
 	// Добавляем ON CONFLICT в зависимости от стратегии
-	onConflict := a.buildOnConflictClause(pkt.Schema, strategy)
+	updates, err := base.UpdateFields(ctx, pkt.Schema, strategy)
+	if err != nil {
+		return err
+	}
+	onConflict := a.buildOnConflictClause(pkt.Schema, strategy, updates)
 
 	// Вставляем батчами по 1000 строк
 	batchSize := 1000
@@ -388,23 +668,24 @@ func (a *Adapter) importWithInsert(ctx context.Context, pkt *packet.DataPacket,
 	return nil
 }
 
-// buildOnConflictClause строит ON CONFLICT клаузу
-func (a *Adapter) buildOnConflictClause(pktSchema packet.Schema, strategy adapters.ImportStrategy) string {
+// buildOnConflictClause строит ON CONFLICT клаузу; updates — колонки
+// DO UPDATE SET (base.UpdateFields) для replace и merge
+func (a *Adapter) buildOnConflictClause(pktSchema packet.Schema, strategy adapters.ImportStrategy, updates []string) string {
 	if strategy == adapters.StrategyFail {
 		return ""
 	}
 
 	// Получаем Primary Key колонки
 	var pkColumns []string
-	var updateColumns []string
-
 	for _, field := range pktSchema.Fields {
 		if field.Key {
 			pkColumns = append(pkColumns, QuoteIdentifier(field.Name))
-		} else {
-			updateColumns = append(updateColumns, QuoteIdentifier(field.Name))
 		}
 	}
+	updateColumns := make([]string, len(updates))
+	for i, name := range updates {
+		updateColumns[i] = QuoteIdentifier(name)
+	}
 
 	if len(pkColumns) == 0 {
 		return "" // Нет PK - не можем использовать ON CONFLICT
@@ -416,24 +697,29 @@ func (a *Adapter) buildOnConflictClause(pktSchema packet.Schema, strategy adapte
 		return conflict + " DO NOTHING"
 	}
 
-	if strategy == adapters.StrategyReplace {
+	if strategy == adapters.StrategyReplace || strategy == adapters.StrategyMerge {
 		if len(updateColumns) == 0 {
 			return conflict + " DO NOTHING"
 		}
 
-		var updates []string
-		for _, col := range updateColumns {
-			updates = append(updates, fmt.Sprintf("%s = EXCLUDED.%s", col, col))
+		sets := make([]string, len(updateColumns))
+		for i, col := range updateColumns {
+			sets[i] = fmt.Sprintf("%s = EXCLUDED.%s", col, col)
 		}
 
-		return conflict + " DO UPDATE SET " + strings.Join(updates, ", ")
+		return conflict + " DO UPDATE SET " + strings.Join(sets, ", ")
 	}
 
 	return ""
 }
 
+// copier — *pgxpool.Pool или pgx.Tx: куда importWithCopy пишет COPY
+type copier interface {
+	CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error)
+}
+
 // importWithCopy импортирует данные через COPY (самый быстрый метод)
-func (a *Adapter) importWithCopy(ctx context.Context, pkt *packet.DataPacket) error {
+func (a *Adapter) importWithCopy(ctx context.Context, db copier, pkt *packet.DataPacket) error {
 	if len(pkt.Data.Rows) == 0 {
 		return nil
 	}
@@ -457,15 +743,10 @@ func (a *Adapter) importWithCopy(ctx context.Context, pkt *packet.DataPacket) er
 		rows = append(rows, rowData)
 	}
 
-	// Выполняем COPY
-	tableName := pkt.Header.TableName
-	if a.schema != "public" {
-		tableName = a.schema + "." + tableName
-	}
-
-	count, err := a.pool.CopyFrom(
+	// Выполняем COPY: pgx.Identifier квотирует схему и таблицу по отдельности
+	count, err := db.CopyFrom(
 		ctx,
-		pgx.Identifier{tableName},
+		a.identifier(pkt.Header.TableName),
 		columnNames,
 		pgx.CopyFromRows(rows),
 	)
@@ -554,6 +835,9 @@ func (a *Adapter) convertValue(value string, field packet.Field) any {
 		}
 	case schema.TypeBoolean:
 		if typedValue.BoolValue != nil {
+			if m := a.converter.BoolMapping(field.Name); m != nil {
+				return m.Format(*typedValue.BoolValue)
+			}
 			return *typedValue.BoolValue
 		}
 	case schema.TypeDate, schema.TypeDatetime, schema.TypeTimestamp:
@@ -584,15 +868,18 @@ func (a *Adapter) DropTable(ctx context.Context, tableName string) error {
 
 // RenameTable implements base.TableManager interface
 func (a *Adapter) RenameTable(ctx context.Context, oldName, newName string) error {
-	quotedOld := QuoteIdentifier(oldName)
-	if a.schema != "public" {
-		quotedOld = QuoteIdentifier(a.schema) + "." + quotedOld
-	}
-	quotedNew := QuoteIdentifier(newName)
-	sql := fmt.Sprintf("ALTER TABLE %s RENAME TO %s", quotedOld, quotedNew)
+	quotedOld := a.qualify(oldName)
+	_, newTable := a.splitTable(newName)
+	sql := fmt.Sprintf("ALTER TABLE %s RENAME TO %s", quotedOld, QuoteIdentifier(newTable))
 	return a.Exec(ctx, sql)
 }
 
+// UpdateStatistics пересчитывает статистику планировщика таблицы (ANALYZE).
+// Реализует adapters.StatisticsUpdater.
+func (a *Adapter) UpdateStatistics(ctx context.Context, tableName string) error {
+	return a.Exec(ctx, "ANALYZE "+a.qualify(tableName))
+}
+
 // ========== base.DataInserter interface methods ==========
 
 // InsertRows implements base.DataInserter interface
@@ -609,7 +896,7 @@ func (a *Adapter) InsertRows(ctx context.Context, tableName string, pktSchema pa
 	pkt.Data.Rows = rows
 
 	// Use COPY for fast bulk insert
-	return a.importWithCopy(ctx, pkt)
+	return a.importWithCopy(ctx, a.pool, pkt)
 }
 
 // ========== base.TransactionManager interface methods ==========
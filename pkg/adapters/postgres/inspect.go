@@ -14,6 +14,7 @@ import (
 func (a *Adapter) InspectTable(ctx context.Context, tableName string) (*adapters.TableReport, error) {
 	// Strip bracket-quoting if present
 	tableName = tdtql.StripBrackets(tableName)
+	schema, table := a.splitTable(tableName)
 
 	dbVersion, err := a.GetDatabaseVersion(ctx)
 	if err != nil {
@@ -24,7 +25,7 @@ func (a *Adapter) InspectTable(ctx context.Context, tableName string) (*adapters
 		Table:     tableName,
 		DBType:    "postgres",
 		DBVersion: dbVersion,
-		Schema:    a.schema,
+		Schema:    schema,
 	}
 
 	// ---- Columns from information_schema.columns ----
@@ -54,7 +55,7 @@ func (a *Adapter) InspectTable(ctx context.Context, tableName string) (*adapters
 		pkSet[pk] = true
 	}
 
-	rows, err := a.pool.Query(ctx, colQuery, a.schema, tableName)
+	rows, err := a.pool.Query(ctx, colQuery, schema, table)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query columns: %w", err)
 	}
@@ -125,7 +126,7 @@ func (a *Adapter) InspectTable(ctx context.Context, tableName string) (*adapters
 		return nil, fmt.Errorf("iterate columns: %w", err)
 	}
 	if len(report.Columns) == 0 {
-		return nil, fmt.Errorf("table %q not found or has no columns in schema %q", tableName, a.schema)
+		return nil, fmt.Errorf("table %q %w in schema %q", table, adapters.ErrTableNotFound, schema)
 	}
 
 	// ---- Foreign keys via information_schema.referential_constraints + key_column_usage ----
@@ -145,7 +146,7 @@ func (a *Adapter) InspectTable(ctx context.Context, tableName string) (*adapters
 		WHERE kcu.table_schema = $1 AND kcu.table_name = $2
 		ORDER BY kcu.ordinal_position
 	`
-	fkRows, err := a.pool.Query(ctx, fkQuery, a.schema, tableName)
+	fkRows, err := a.pool.Query(ctx, fkQuery, schema, table)
 	if err == nil {
 		defer fkRows.Close()
 		for fkRows.Next() {
@@ -165,7 +166,7 @@ func (a *Adapter) InspectTable(ctx context.Context, tableName string) (*adapters
 	// ---- Row count ----
 	var totalRows int64
 	countRow := a.pool.QueryRow(ctx, fmt.Sprintf(`SELECT COUNT(*) FROM %s.%s`,
-		quoteIdent(a.schema), quoteIdent(tableName)))
+		quoteIdent(schema), quoteIdent(table)))
 	_ = countRow.Scan(&totalRows)
 	report.Stats.TotalRows = totalRows
 
@@ -173,7 +174,7 @@ func (a *Adapter) InspectTable(ctx context.Context, tableName string) (*adapters
 	if totalRows > 0 && len(pkCols) > 0 {
 		orderClause := quoteIdent(pkCols[0]) + " DESC"
 		sampleQuery := fmt.Sprintf(`SELECT * FROM %s.%s ORDER BY %s LIMIT 1`,
-			quoteIdent(a.schema), quoteIdent(tableName), orderClause)
+			quoteIdent(schema), quoteIdent(table), orderClause)
 		sampleRows, err := a.pool.Query(ctx, sampleQuery)
 		if err == nil {
 			defer sampleRows.Close()
@@ -0,0 +1,70 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+// EnsurePartition создаёт таблицу-партицию, если её нет.
+// Реализует adapters.PartitionManager:
+//   - родитель — декларативно партиционированная таблица: PARTITION OF с
+//     диапазоном [from, to) (строки по-прежнему видны через родителя);
+//   - родитель — обычная таблица: копия структуры (LIKE … INCLUDING ALL);
+//   - родителя нет: таблица по схеме пакета.
+func (a *Adapter) EnsurePartition(ctx context.Context, parentTable, partitionTable string, schema packet.Schema, from, to time.Time) error {
+	exists, err := a.TableExists(ctx, partitionTable)
+	if err != nil || exists {
+		return err
+	}
+
+	parentExists, err := a.TableExists(ctx, parentTable)
+	if err != nil {
+		return err
+	}
+	if !parentExists {
+		return a.createTableFromSchema(ctx, partitionTable, schema)
+	}
+
+	quotedPart, quotedParent := a.qualify(partitionTable), a.qualify(parentTable)
+	partitioned, err := a.isPartitionedTable(ctx, parentTable)
+	if err != nil {
+		return err
+	}
+
+	var sql string
+	if partitioned {
+		sql = fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s PARTITION OF %s FOR VALUES FROM ('%s') TO ('%s')",
+			quotedPart, quotedParent, from.Format("2006-01-02"), to.Format("2006-01-02"))
+	} else {
+		sql = fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (LIKE %s INCLUDING ALL)", quotedPart, quotedParent)
+	}
+	if err := a.Exec(ctx, sql); err != nil {
+		return fmt.Errorf("failed to create partition: %w", err)
+	}
+	fmt.Printf("📅 Created partition %s\n", partitionTable)
+	return nil
+}
+
+// isPartitionedTable проверяет, что таблица — декларативно партиционированная.
+func (a *Adapter) isPartitionedTable(ctx context.Context, tableName string) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM pg_partitioned_table pt
+			JOIN pg_class c ON c.oid = pt.partrelid
+			JOIN pg_namespace n ON n.oid = c.relnamespace
+			WHERE n.nspname = $1
+			  AND c.relname = $2
+		)
+	`
+
+	schema, table := a.splitTable(tableName)
+	var partitioned bool
+	if err := a.pool.QueryRow(ctx, query, schema, table).Scan(&partitioned); err != nil {
+		return false, fmt.Errorf("failed to check partitioning: %w", err)
+	}
+	return partitioned, nil
+}
@@ -0,0 +1,59 @@
+package postgres
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+)
+
+// queryTracer пишет выражения пула pgx в журнал adapters.QueryLogger
+// (pgx.QueryTracer и pgx.CopyFromTracer). Время запроса pgx фиксирует при
+// закрытии Rows — вместе с чтением строк.
+type queryTracer struct {
+	log *adapters.QueryLogger
+}
+
+type traceStartKey struct{}
+
+type traceStart struct {
+	statement string
+	args      []any
+	at        time.Time
+}
+
+func (t *queryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	if !t.log.Enabled() {
+		return ctx
+	}
+	return context.WithValue(ctx, traceStartKey{}, &traceStart{statement: data.SQL, args: data.Args, at: time.Now()})
+}
+
+func (t *queryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	if start, ok := ctx.Value(traceStartKey{}).(*traceStart); ok {
+		t.log.Log(ctx, adapters.QueryEvent{
+			Statement: start.statement, Args: start.args, Duration: time.Since(start.at),
+			Rows: data.CommandTag.RowsAffected(), Err: data.Err,
+		})
+	}
+}
+
+func (t *queryTracer) TraceCopyFromStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceCopyFromStartData) context.Context {
+	if !t.log.Enabled() {
+		return ctx
+	}
+	statement := "COPY " + data.TableName.Sanitize() + " (" + strings.Join(data.ColumnNames, ", ") + ") FROM STDIN"
+	return context.WithValue(ctx, traceStartKey{}, &traceStart{statement: statement, at: time.Now()})
+}
+
+func (t *queryTracer) TraceCopyFromEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceCopyFromEndData) {
+	if start, ok := ctx.Value(traceStartKey{}).(*traceStart); ok {
+		t.log.Log(ctx, adapters.QueryEvent{
+			Statement: start.statement, Duration: time.Since(start.at),
+			Rows: data.CommandTag.RowsAffected(), Err: data.Err,
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+	"github.com/ruslano69/tdtp-framework/pkg/adapters/base"
+)
+
+// Compile-time check: несколько схем (namespaces) в одной БД
+var _ adapters.SchemaLister = (*Adapter)(nil)
+
+// splitTable разбирает имя таблицы: "sales.orders" — таблица схемы sales
+// (экспорт квалифицированных таблиц, импорт пакета с TableName
+// "schema.table"), иначе — схема адаптера (Config.Schema).
+func (a *Adapter) splitTable(tableName string) (schema, table string) {
+	schema, table, qualified := base.SplitQualifiedName(tableName)
+	if !qualified {
+		schema = a.schema
+	}
+	return schema, table
+}
+
+// IdentifierLimit — NAMEDATALEN-1: длиннее 63 байт PostgreSQL обрезает
+// имена молча (adapters.IdentifierLimiter)
+func (a *Adapter) IdentifierLimit() adapters.IdentifierLimit {
+	return adapters.IdentifierLimit{MaxLength: 63, Bytes: true}
+}
+
+// qualify экранирует имя таблицы с учётом схемы: "schema"."table".
+// Неквалифицированная таблица в public остаётся "table" (search_path),
+// как и без подключения (схема адаптера не задана — GenerateDDL).
+func (a *Adapter) qualify(tableName string) string {
+	schema, table, qualified := base.SplitQualifiedName(tableName)
+	if !qualified {
+		schema = a.schema
+	}
+	if (schema == "public" || schema == "") && !qualified {
+		return QuoteIdentifier(table)
+	}
+	return QuoteIdentifier(schema) + "." + QuoteIdentifier(table)
+}
+
+// identifier — имя таблицы для pgx.CopyFrom (квотирует каждую часть сам).
+func (a *Adapter) identifier(tableName string) pgx.Identifier {
+	schema, table, qualified := base.SplitQualifiedName(tableName)
+	if !qualified {
+		schema = a.schema
+	}
+	if schema == "public" && !qualified {
+		return pgx.Identifier{table}
+	}
+	return pgx.Identifier{schema, table}
+}
+
+// GetSchemaTableNames возвращает таблицы схемы schema (без префикса схемы).
+// Реализует adapters.SchemaLister
+func (a *Adapter) GetSchemaTableNames(ctx context.Context, schema string) ([]string, error) {
+	query := `
+		SELECT table_name
+		FROM information_schema.tables
+		WHERE table_schema = $1
+		  AND table_type = 'BASE TABLE'
+		ORDER BY table_name
+	`
+
+	rows, err := a.pool.Query(ctx, query, schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get table names of schema %s: %w", schema, err)
+	}
+	defer rows.Close()
+
+	var tables []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to scan table name: %w", err)
+		}
+		tables = append(tables, name)
+	}
+	return tables, rows.Err()
+}
+
+// ensureSchema создаёт схему таблицы перед CREATE TABLE, если это разрешено
+// (Config.CreateSchemas); иначе отсутствующая схема — понятная ошибка
+// вместо "relation does not exist" из CREATE TABLE.
+func (a *Adapter) ensureSchema(ctx context.Context, tableName string) error {
+	schema, _ := a.splitTable(tableName)
+	if schema == "public" {
+		return nil
+	}
+	if a.createSchemas {
+		if err := a.Exec(ctx, "CREATE SCHEMA IF NOT EXISTS "+QuoteIdentifier(schema)); err != nil {
+			return fmt.Errorf("failed to create schema %s: %w", schema, err)
+		}
+		return nil
+	}
+
+	var exists bool
+	err := a.pool.QueryRow(ctx,
+		"SELECT EXISTS (SELECT 1 FROM pg_namespace WHERE nspname = $1)", schema).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to check schema %s: %w", schema, err)
+	}
+	if !exists {
+		return fmt.Errorf("schema %q does not exist (enable schema auto-create to create it on import)", schema)
+	}
+	return nil
+}
+
+// searchPath — search_path сессии для Config.Schema: неквалифицированные
+// имена в SQL пользователя (pipeline-источники, --export с raw SQL)
+// разрешаются в схеме адаптера, затем в public.
+func searchPath(schema string) string {
+	if schema == "" || schema == "public" {
+		return ""
+	}
+	return QuoteIdentifier(schema) + ", public"
+}
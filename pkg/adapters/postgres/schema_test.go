@@ -0,0 +1,104 @@
+package postgres
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+func TestAdapter_QualifyTable(t *testing.T) {
+	tests := []struct {
+		schema, table string
+		wantQuoted    string
+		wantIdent     []string
+	}{
+		{"public", "orders", `"orders"`, []string{"orders"}},
+		{"sales", "orders", `"sales"."orders"`, []string{"sales", "orders"}},
+		{"public", "archive.Orders", `"archive"."Orders"`, []string{"archive", "Orders"}},
+		{"sales", "public.orders", `"public"."orders"`, []string{"public", "orders"}},
+		{"sales", `odd"name`, `"sales"."odd""name"`, []string{"sales", `odd"name`}},
+	}
+	for _, tt := range tests {
+		a := &Adapter{schema: tt.schema}
+		if got := a.qualify(tt.table); got != tt.wantQuoted {
+			t.Errorf("schema %s: qualify(%q) = %s, want %s", tt.schema, tt.table, got, tt.wantQuoted)
+		}
+		if got := a.identifier(tt.table); !slices.Equal(got, tt.wantIdent) {
+			t.Errorf("schema %s: identifier(%q) = %v, want %v", tt.schema, tt.table, got, tt.wantIdent)
+		}
+	}
+}
+
+func TestSearchPath(t *testing.T) {
+	if got := searchPath("public"); got != "" {
+		t.Errorf("searchPath(public) = %q, want empty", got)
+	}
+	if got := searchPath("Sales"); got != `"Sales", public` {
+		t.Errorf("searchPath(Sales) = %q", got)
+	}
+}
+
+func TestGenerateDDL(t *testing.T) {
+	schema := packet.Schema{Fields: []packet.Field{
+		{Name: "id", Type: "INTEGER", Key: true},
+		{Name: "Imia", Type: "TEXT", Length: 100, OriginalName: "Имя"},
+	}}
+
+	// Без подключения схема адаптера не задана: таблица остаётся неквалифицированной
+	got, err := adapters.GenerateDDL("postgres", "users", schema)
+	if err != nil {
+		t.Fatalf("GenerateDDL: %v", err)
+	}
+	want := "CREATE TABLE \"users\" (\n" +
+		"  \"id\" " + TDTPToPostgreSQL(schema.Fields[0]) + ",\n" +
+		"  \"Imia\" " + TDTPToPostgreSQL(schema.Fields[1]) + ",\n" +
+		"  PRIMARY KEY (\"id\")\n" +
+		");\n\n" +
+		"COMMENT ON COLUMN \"users\".\"Imia\" IS 'original: Имя';\n"
+	if got != want {
+		t.Errorf("GenerateDDL:\n got %s\nwant %s", got, want)
+	}
+
+	// Описания источника: COMMENT ON TABLE и COMMENT ON COLUMN вместе с исходным именем
+	schema.Description = "Пользователи"
+	schema.Fields[0].Description = "It's the key"
+	schema.Fields[1].Description = "Имя пользователя"
+	ddl := (&Adapter{}).GenerateDDL("users", schema)
+	wantComments := []string{
+		`COMMENT ON TABLE "users" IS 'Пользователи'`,
+		`COMMENT ON COLUMN "users"."id" IS 'It''s the key'`,
+		"COMMENT ON COLUMN \"users\".\"Imia\" IS 'Имя пользователя\noriginal: Имя'",
+	}
+	if !slices.Equal(ddl[1:], wantComments) {
+		t.Errorf("comments:\n got %q\nwant %q", ddl[1:], wantComments)
+	}
+
+	if _, err := adapters.GenerateDDL("postgres", "users", packet.Schema{}); err == nil {
+		t.Error("GenerateDDL with empty schema: want error")
+	}
+	if _, err := adapters.GenerateDDL("nosuchdb", "users", schema); err == nil {
+		t.Error("GenerateDDL for unknown type: want error")
+	}
+}
+
+func TestQueryResultField(t *testing.T) {
+	tests := []struct {
+		name   string
+		oid    uint32
+		typmod int32
+		want   packet.Field
+	}{
+		{"numeric(12,3)", 1700, 12<<16 | 3 + 4, packet.Field{Name: "c", Type: "DECIMAL", Precision: 12, Scale: 3}},
+		{"numeric", 1700, -1, packet.Field{Name: "c", Type: "REAL"}},
+		{"varchar(40)", 1043, 44, packet.Field{Name: "c", Type: "TEXT", Length: 40}},
+		{"text", 25, -1, packet.Field{Name: "c", Type: "TEXT", Length: 1000}},
+		{"timestamptz", 1184, -1, packet.Field{Name: "c", Type: "DATETIME", Timezone: "UTC"}},
+	}
+	for _, tt := range tests {
+		if got := queryResultField("c", tt.oid, tt.typmod); got != tt.want {
+			t.Errorf("%s: got %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+	"github.com/ruslano69/tdtp-framework/pkg/adapters/base"
+)
+
+// Compile-time check: симуляция импорта в scratch-схеме
+var _ adapters.ScratchCreator = (*Adapter)(nil)
+
+// CreateScratch реализует adapters.ScratchCreator: схема name в той же БД,
+// таблицы — CREATE TABLE ... (LIKE ... INCLUDING ALL) (колонки, ключи,
+// индексы, умолчания) и копия строк. Внешние ключи не копируются: в
+// scratch только таблицы импорта.
+func (a *Adapter) CreateScratch(ctx context.Context, name string, tables []string) (*adapters.Scratch, error) {
+	for _, table := range tables {
+		if _, _, qualified := base.SplitQualifiedName(table); qualified {
+			return nil, fmt.Errorf("schema-qualified table %s cannot be simulated: set the schema in the connection config", table)
+		}
+	}
+	if err := a.Exec(ctx, "CREATE SCHEMA "+QuoteIdentifier(name)); err != nil {
+		return nil, fmt.Errorf("failed to create schema %s: %w", name, err)
+	}
+	scratch := &adapters.Scratch{Location: "schema " + name, Schema: name}
+	for _, table := range tables {
+		exists, err := a.TableExists(ctx, table)
+		if err != nil {
+			_ = a.DropScratch(ctx, scratch)
+			return nil, err
+		}
+		if !exists {
+			continue
+		}
+		src := QuoteIdentifier(a.schema) + "." + QuoteIdentifier(table)
+		dst := QuoteIdentifier(name) + "." + QuoteIdentifier(table)
+		for _, stmt := range []string{
+			fmt.Sprintf("CREATE TABLE %s (LIKE %s INCLUDING ALL)", dst, src),
+			fmt.Sprintf("INSERT INTO %s SELECT * FROM %s", dst, src),
+		} {
+			if err := a.Exec(ctx, stmt); err != nil {
+				_ = a.DropScratch(ctx, scratch)
+				return nil, fmt.Errorf("failed to copy table %s: %w", table, err)
+			}
+		}
+	}
+	return scratch, nil
+}
+
+// DropScratch реализует adapters.ScratchCreator: DROP SCHEMA ... CASCADE.
+func (a *Adapter) DropScratch(ctx context.Context, scratch *adapters.Scratch) error {
+	return a.Exec(ctx, "DROP SCHEMA IF EXISTS "+QuoteIdentifier(scratch.Schema)+" CASCADE")
+}
@@ -0,0 +1,32 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters/base"
+)
+
+// TryLockTable реализует base.TableLocker: сессионная рекомендательная
+// блокировка pg_try_advisory_lock по хешу имени. Блокировка живёт в сессии,
+// поэтому соединение изымается из пула до освобождения.
+func (a *Adapter) TryLockTable(ctx context.Context, table string) (func() error, bool, error) {
+	conn, err := a.pool.Acquire(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	name := base.TableLockName(table)
+	var ok bool
+	if err := conn.QueryRow(ctx, "SELECT pg_try_advisory_lock(hashtext($1))", name).Scan(&ok); err != nil {
+		conn.Release()
+		return nil, false, err
+	}
+	if !ok {
+		conn.Release()
+		return nil, false, nil
+	}
+	return func() error {
+		defer conn.Release()
+		_, err := conn.Exec(context.Background(), "SELECT pg_advisory_unlock(hashtext($1))", name)
+		return err
+	}, true, nil
+}
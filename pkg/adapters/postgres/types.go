@@ -266,3 +266,8 @@ func QuoteIdentifier(identifier string) string {
 	escaped := strings.ReplaceAll(identifier, `"`, `""`)
 	return `"` + escaped + `"` //nolint:gocritic // SQL identifier quoting, not Go string quoting
 }
+
+// quoteLiteral экранирует строковый литерал для DDL (COMMENT ON ...)
+func quoteLiteral(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
@@ -0,0 +1,196 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+// Стандартные колонки происхождения строки (record-level provenance).
+//
+// При импорте с ImportOptions.Provenance (WithProvenance) каждая строка получает:
+//
+//	_tdtp_source       — Header.Sender пакета (система-источник)
+//	_tdtp_message_id   — Header.MessageID пакета
+//	_tdtp_imported_at  — момент импорта (UTC)
+//	_tdtp_part         — Header.PartNumber
+//
+// При повторном экспорте эти колонки исключаются (StripProvenance, кроме
+// WithKeepProvenance): иначе следующий приёмник получил бы происхождение
+// предыдущего звена, а не своё.
+const (
+	ProvenanceSource     = "_tdtp_source"
+	ProvenanceMessageID  = "_tdtp_message_id"
+	ProvenanceImportedAt = "_tdtp_imported_at"
+	ProvenancePart       = "_tdtp_part"
+)
+
+// ProvenanceFields возвращает описание колонок происхождения.
+func ProvenanceFields() []packet.Field {
+	return []packet.Field{
+		{Name: ProvenanceSource, Type: "TEXT", Length: 255},
+		{Name: ProvenanceMessageID, Type: "TEXT", Length: 255},
+		{Name: ProvenanceImportedAt, Type: "TIMESTAMP"},
+		{Name: ProvenancePart, Type: "INTEGER"},
+	}
+}
+
+// IsProvenanceColumn сообщает, является ли колонка колонкой происхождения.
+func IsProvenanceColumn(name string) bool {
+	switch strings.ToLower(name) {
+	case ProvenanceSource, ProvenanceMessageID, ProvenanceImportedAt, ProvenancePart:
+		return true
+	}
+	return false
+}
+
+type provenanceKey struct{}
+
+type keepProvenanceKey struct{}
+
+// WithProvenance включает колонки происхождения для импорта с ctx:
+// ImportHelper и адаптеры с собственным импортом добавляют их в таблицы и
+// заполняют (ApplyProvenance) с моментом импорта importedAt; пробный импорт
+// проверяет пакеты с ними. ImportWithOptions вызывает его по
+// ImportOptions.Provenance.
+func WithProvenance(ctx context.Context, importedAt time.Time) context.Context {
+	return context.WithValue(ctx, provenanceKey{}, importedAt)
+}
+
+// ProvenanceFromContext возвращает момент импорта из WithProvenance
+// (ok == false — колонки происхождения не пишутся).
+func ProvenanceFromContext(ctx context.Context) (importedAt time.Time, ok bool) {
+	importedAt, ok = ctx.Value(provenanceKey{}).(time.Time)
+	return importedAt, ok
+}
+
+// WithKeepProvenance оставляет колонки происхождения в пакетах экспорта
+// с ctx. По умолчанию экспорт их исключает (StripProvenance).
+func WithKeepProvenance(ctx context.Context) context.Context {
+	return context.WithValue(ctx, keepProvenanceKey{}, true)
+}
+
+// KeepProvenance сообщает, задан ли для экспорта WithKeepProvenance.
+func KeepProvenance(ctx context.Context) bool {
+	keep, _ := ctx.Value(keepProvenanceKey{}).(bool)
+	return keep
+}
+
+// ProvenanceTarget — целевая БД импорта с колонками происхождения:
+// существующая таблица дополняется ими через ColumnAdder.
+type ProvenanceTarget interface {
+	TableExists(ctx context.Context, tableName string) (bool, error)
+	GetTableSchema(ctx context.Context, tableName string) (packet.Schema, error)
+}
+
+// ColumnAdder — адаптер, умеющий добавлять колонки в существующую таблицу
+// (ALTER TABLE ... ADD COLUMN).
+type ColumnAdder interface {
+	AddColumns(ctx context.Context, tableName string, fields []packet.Field) error
+}
+
+// AddProvenance добавляет в пакет колонки происхождения и заполняет их.
+// Колонки, пришедшие в пакете (экспорт без StripProvenance), заменяются.
+func AddProvenance(pkt *packet.DataPacket, importedAt time.Time) error {
+	if pkt.Data.Compression != "" {
+		return fmt.Errorf("packet must be decompressed before adding provenance")
+	}
+	if pkt.Data.Delta || pkt.Data.Delete {
+		return nil // строки не вставляются — происхождение не пишется
+	}
+	StripProvenance(pkt)
+
+	values := []string{
+		pkt.Header.Sender,
+		pkt.Header.MessageID,
+		importedAt.UTC().Format(time.RFC3339),
+		strconv.Itoa(pkt.Header.PartNumber),
+	}
+	pkt.MaterializeRows()
+	parser := packet.NewParser()
+	for i, row := range pkt.Data.Rows {
+		pkt.Data.Rows[i] = packet.Row{Value: packet.JoinRowEscaped(append(parser.GetRowValues(row), values...))}
+	}
+	pkt.Schema.Fields = append(pkt.Schema.Fields, ProvenanceFields()...)
+	return nil
+}
+
+// StripProvenance удаляет колонки происхождения из схемы и строк пакета и
+// возвращает число удалённых колонок.
+func StripProvenance(pkt *packet.DataPacket) int {
+	var keep []int
+	for i, f := range pkt.Schema.Fields {
+		if !IsProvenanceColumn(f.Name) {
+			keep = append(keep, i)
+		}
+	}
+	removed := len(pkt.Schema.Fields) - len(keep)
+	if removed == 0 || pkt.Data.Compression != "" {
+		return 0
+	}
+
+	keepColumns(pkt, keep)
+	return removed
+}
+
+// EnsureProvenanceColumns добавляет недостающие колонки происхождения в
+// существующую таблицу. Отсутствующая таблица будет создана импортом по
+// схеме пакета — уже с этими колонками.
+func EnsureProvenanceColumns(ctx context.Context, a ProvenanceTarget, tableName string) error {
+	exists, err := a.TableExists(ctx, tableName)
+	if err != nil || !exists {
+		return err
+	}
+	schema, err := a.GetTableSchema(ctx, tableName)
+	if err != nil {
+		return fmt.Errorf("failed to read schema of %s: %w", tableName, err)
+	}
+	present := make(map[string]bool)
+	for _, f := range schema.Fields {
+		present[strings.ToLower(f.Name)] = true
+	}
+	var missing []packet.Field
+	for _, f := range ProvenanceFields() {
+		if !present[f.Name] {
+			missing = append(missing, f)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	adder, ok := a.(ColumnAdder)
+	if !ok {
+		return fmt.Errorf("table %s has no provenance columns and adapter %T cannot add them", tableName, a)
+	}
+	if err := adder.AddColumns(ctx, tableName, missing); err != nil {
+		return fmt.Errorf("failed to add provenance columns to %s: %w", tableName, err)
+	}
+	fmt.Printf("  🏷  Added %d provenance column(s) to %s\n", len(missing), tableName)
+	return nil
+}
+
+// ApplyProvenance готовит импорт с ImportOptions.Provenance: добавляет
+// колонки в целевые таблицы и заполняет их в пакетах.
+func ApplyProvenance(ctx context.Context, a ProvenanceTarget, packets []*packet.DataPacket, importedAt time.Time) error {
+	seen := make(map[string]bool)
+	for _, pkt := range packets {
+		if pkt == nil || pkt.Data.Delta || pkt.Data.Delete {
+			continue
+		}
+		if table := pkt.Header.TableName; !seen[table] {
+			seen[table] = true
+			if err := EnsureProvenanceColumns(ctx, a, table); err != nil {
+				return err
+			}
+		}
+		if err := AddProvenance(pkt, importedAt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package adapters
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+// provenanceAdapter — Adapter со схемой существующей таблицы и ALTER TABLE.
+type provenanceAdapter struct {
+	Adapter
+	schema packet.Schema
+	added  []string
+}
+
+func (a *provenanceAdapter) TableExists(context.Context, string) (bool, error) {
+	return len(a.schema.Fields) > 0, nil
+}
+
+func (a *provenanceAdapter) GetTableSchema(context.Context, string) (packet.Schema, error) {
+	return a.schema, nil
+}
+
+func (a *provenanceAdapter) AddColumns(_ context.Context, _ string, fields []packet.Field) error {
+	for _, f := range fields {
+		a.added = append(a.added, f.Name)
+		a.schema.Fields = append(a.schema.Fields, f)
+	}
+	return nil
+}
+
+func TestAddStripProvenance(t *testing.T) {
+	pkt := eventsPacket(t, "2025-06-01", "2025-06-02")
+	pkt.Header.Sender = "crm"
+	pkt.Header.PartNumber = 2
+	importedAt := time.Date(2025, 6, 3, 10, 0, 0, 0, time.UTC)
+
+	if err := AddProvenance(pkt, importedAt); err != nil {
+		t.Fatalf("AddProvenance: %v", err)
+	}
+	if len(pkt.Schema.Fields) != 6 || pkt.Schema.Fields[5].Name != ProvenancePart {
+		t.Fatalf("schema = %+v", pkt.Schema.Fields)
+	}
+	want := "1|2025-06-01|crm|" + pkt.Header.MessageID + "|2025-06-03T10:00:00Z|2"
+	if pkt.Data.Rows[0].Value != want {
+		t.Errorf("row = %q, want %q", pkt.Data.Rows[0].Value, want)
+	}
+
+	// Повторный импорт того же пакета не дублирует колонки.
+	pkt.Header.Sender = "dwh"
+	if err := AddProvenance(pkt, importedAt); err != nil {
+		t.Fatal(err)
+	}
+	if len(pkt.Schema.Fields) != 6 || pkt.Data.Rows[1].Value[:17] != "2|2025-06-02|dwh|" {
+		t.Errorf("re-added provenance: %d fields, row %q", len(pkt.Schema.Fields), pkt.Data.Rows[1].Value)
+	}
+
+	if n := StripProvenance(pkt); n != 4 {
+		t.Errorf("stripped %d columns", n)
+	}
+	if len(pkt.Schema.Fields) != 2 || pkt.Data.Rows[0].Value != "1|2025-06-01" {
+		t.Errorf("after strip: %+v %v", pkt.Schema.Fields, pkt.Data.Rows)
+	}
+}
+
+func TestApplyProvenance(t *testing.T) {
+	a := &provenanceAdapter{schema: packet.Schema{Fields: []packet.Field{
+		{Name: "id", Type: "INTEGER", Key: true},
+		{Name: "event_time", Type: "TIMESTAMP"},
+		{Name: "_TDTP_SOURCE", Type: "TEXT"},
+	}}}
+	packets := []*packet.DataPacket{eventsPacket(t, "2025-06-01"), eventsPacket(t, "2025-06-02")}
+	if err := ApplyProvenance(context.Background(), a, packets, time.Now()); err != nil {
+		t.Fatalf("ApplyProvenance: %v", err)
+	}
+	if len(a.added) != 3 || a.added[0] != ProvenanceMessageID {
+		t.Errorf("added columns = %v", a.added)
+	}
+	for _, p := range packets {
+		if len(p.Schema.Fields) != 6 {
+			t.Errorf("packet schema has %d fields", len(p.Schema.Fields))
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+// ========== Экспорт произвольного запроса ==========
+
+// QueryResultTable — имя таблицы в заголовке пакетов ExportQuery (как у
+// ExecuteRawQuery): у результата произвольного запроса своей таблицы нет.
+const QueryResultTable = "query_result"
+
+// QueryExporter — адаптер экспортирует результат произвольного read-only
+// SQL (SELECT / WITH) без существующей таблицы. Схема пакета выводится из
+// метаданных результата (тип, длина, точность, read-only колонки); первичного
+// ключа у результата нет, поэтому пакеты годятся для импорта стратегиями
+// replace/copy, но не для UPSERT по ключу.
+//
+// Запрос проверяется SQLValidator в safe mode: одна команда, без
+// комментариев, только чтение. Пакеты — reference с таблицей
+// QueryResultTable, разбиты по размеру как при ExportTable.
+type QueryExporter interface {
+	ExportQuery(ctx context.Context, sql string) ([]*packet.DataPacket, error)
+}
+
+// ExportQuery экспортирует результат произвольного SQL адаптером
+// (см. QueryExporter).
+func ExportQuery(ctx context.Context, adapter Adapter, sql string) ([]*packet.DataPacket, error) {
+	exporter, ok := adapter.(QueryExporter)
+	if !ok {
+		return nil, fmt.Errorf("adapter %s does not support query export", adapter.GetDatabaseType())
+	}
+	return exporter.ExportQuery(ctx, sql)
+}
+
+// ========== План запроса ==========
+
+// QueryExplainer — адаптер показывает план ExportTableWithQuery без чтения
+// строк: какие фильтры уйдут в SQL, какие выполнятся в памяти, стадии и их
+// оценки (base.ExportHelper.Explain). План выполненного запроса — с
+// фактическим числом строк и временем стадий — приходит в
+// QueryContext.ExecutionPlan пакетов экспорта.
+type QueryExplainer interface {
+	ExplainQuery(ctx context.Context, tableName string, query *packet.Query) (*packet.ExecutionPlan, error)
+}
+
+// ExplainQuery возвращает план запроса к таблице (см. QueryExplainer).
+func ExplainQuery(ctx context.Context, adapter Adapter, tableName string, query *packet.Query) (*packet.ExecutionPlan, error) {
+	explainer, ok := adapter.(QueryExplainer)
+	if !ok {
+		return nil, fmt.Errorf("adapter %s does not support query plans", adapter.GetDatabaseType())
+	}
+	return explainer.ExplainQuery(ctx, tableName, query)
+}
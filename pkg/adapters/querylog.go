@@ -0,0 +1,185 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// QueryLogConfig — журнал SQL-выражений адаптера (slow query log).
+// Нулевое значение — журнал выключен.
+type QueryLogConfig struct {
+	// Enabled включает журнал.
+	Enabled bool
+
+	// SlowThreshold — писать только выражения не быстрее порога
+	// (0 — все выражения). Время запроса — до закрытия курсора, то есть
+	// вместе с чтением строк.
+	SlowThreshold time.Duration
+
+	// ShowArgs — писать значения параметров. По умолчанию параметры
+	// скрываются (в них персональные данные импортируемых строк), в журнал
+	// попадает только их число.
+	ShowArgs bool
+
+	// MaxStatementLen — обрезать текст выражения до стольких символов
+	// (0 — DefaultMaxStatementLen): многострочный INSERT импорта может
+	// занимать мегабайты.
+	MaxStatementLen int
+
+	// Output — куда писать журнал (nil — os.Stderr).
+	Output io.Writer
+}
+
+// DefaultMaxStatementLen — длина текста выражения в журнале по умолчанию.
+const DefaultMaxStatementLen = 2000
+
+// maxArgLen — длина значения параметра в журнале при ShowArgs.
+const maxArgLen = 64
+
+// QueryLogger пишет выполненные адаптером выражения: длительность, число
+// строк, ошибку и MessageID пакета, ради которого выполнялся запрос
+// (WithMessageID). Настройки меняются на лету (SetConfig) — включить журнал
+// можно у работающего адаптера, не переподключаясь. Безопасен для
+// параллельного использования; nil-QueryLogger ничего не пишет.
+type QueryLogger struct {
+	cfg atomic.Pointer[QueryLogConfig]
+	mu  sync.Mutex // сериализует запись строк журнала
+}
+
+// NewQueryLogger создаёт журнал с настройками cfg.
+func NewQueryLogger(cfg QueryLogConfig) *QueryLogger {
+	l := &QueryLogger{}
+	l.SetConfig(cfg)
+	return l
+}
+
+// SetConfig заменяет настройки журнала.
+func (l *QueryLogger) SetConfig(cfg QueryLogConfig) {
+	l.cfg.Store(&cfg)
+}
+
+// Config возвращает текущие настройки журнала.
+func (l *QueryLogger) Config() QueryLogConfig {
+	if l == nil {
+		return QueryLogConfig{}
+	}
+	return *l.cfg.Load()
+}
+
+// Enabled сообщает, включён ли журнал: адаптеры пропускают замер времени
+// выключенного журнала.
+func (l *QueryLogger) Enabled() bool {
+	return l != nil && l.cfg.Load().Enabled
+}
+
+// QueryEvent — одно выполненное выражение.
+type QueryEvent struct {
+	Statement string
+	Args      []any
+	Duration  time.Duration
+	Rows      int64 // прочитано или затронуто строк; -1 — неизвестно
+	Err       error
+}
+
+// Log пишет событие, если журнал включён и выражение не быстрее порога.
+func (l *QueryLogger) Log(ctx context.Context, ev QueryEvent) {
+	if !l.Enabled() {
+		return
+	}
+	cfg := l.cfg.Load()
+	if ev.Duration < cfg.SlowThreshold {
+		return
+	}
+
+	var b strings.Builder
+	b.WriteString(time.Now().Format("2006/01/02 15:04:05.000"))
+	if cfg.SlowThreshold > 0 {
+		b.WriteString(" [slow-sql]")
+	} else {
+		b.WriteString(" [sql]")
+	}
+	fmt.Fprintf(&b, " %s", ev.Duration.Round(time.Microsecond))
+	if id := MessageIDFromContext(ctx); id != "" {
+		fmt.Fprintf(&b, " msg=%s", id)
+	}
+	if ev.Rows >= 0 {
+		fmt.Fprintf(&b, " rows=%d", ev.Rows)
+	}
+	if len(ev.Args) > 0 {
+		if cfg.ShowArgs {
+			fmt.Fprintf(&b, " args=%s", formatArgs(ev.Args))
+		} else {
+			fmt.Fprintf(&b, " args=%d(redacted)", len(ev.Args))
+		}
+	}
+	if ev.Err != nil {
+		fmt.Fprintf(&b, " err=%q", ev.Err.Error())
+	}
+	maxLen := cfg.MaxStatementLen
+	if maxLen <= 0 {
+		maxLen = DefaultMaxStatementLen
+	}
+	fmt.Fprintf(&b, " | %s\n", truncate(strings.Join(strings.Fields(ev.Statement), " "), maxLen))
+
+	out := cfg.Output
+	if out == nil {
+		out = os.Stderr
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, _ = io.WriteString(out, b.String())
+}
+
+func formatArgs(args []any) string {
+	parts := make([]string, len(args))
+	for i, a := range args {
+		switch v := a.(type) {
+		case nil:
+			parts[i] = "NULL"
+		case []byte:
+			parts[i] = fmt.Sprintf("<%d bytes>", len(v))
+		case string:
+			parts[i] = fmt.Sprintf("%q", truncate(v, maxArgLen))
+		default:
+			parts[i] = truncate(fmt.Sprintf("%v", v), maxArgLen)
+		}
+	}
+	return "[" + strings.Join(parts, ", ") + "]"
+}
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n]) + fmt.Sprintf("…(+%d)", len(r)-n)
+}
+
+// QueryLogged — адаптер с журналом SQL-выражений (Config.QueryLog):
+// у работающего адаптера журнал включается QueryLog().SetConfig.
+type QueryLogged interface {
+	QueryLog() *QueryLogger
+}
+
+type messageIDKey struct{}
+
+// WithMessageID помечает ctx MessageID пакета: выражения, выполненные с этим
+// контекстом, попадают в журнал с msg=<id>.
+func WithMessageID(ctx context.Context, messageID string) context.Context {
+	if messageID == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, messageIDKey{}, messageID)
+}
+
+// MessageIDFromContext возвращает MessageID из WithMessageID ("" — нет).
+func MessageIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(messageIDKey{}).(string)
+	return id
+}
@@ -0,0 +1,56 @@
+package adapters
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestQueryLogger_ThresholdAndRedaction(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewQueryLogger(QueryLogConfig{Enabled: true, SlowThreshold: 100 * time.Millisecond, Output: &buf})
+	ctx := WithMessageID(context.Background(), "MSG-1")
+
+	l.Log(ctx, QueryEvent{Statement: "SELECT 1", Duration: time.Millisecond, Rows: 1})
+	if buf.Len() != 0 {
+		t.Fatalf("fast statement logged: %s", buf.String())
+	}
+
+	l.Log(ctx, QueryEvent{
+		Statement: "INSERT INTO users\n  (name) VALUES (?)", Args: []any{"Alice"},
+		Duration: 150 * time.Millisecond, Rows: 1, Err: errors.New("duplicate key"),
+	})
+	line := buf.String()
+	for _, want := range []string{"[slow-sql]", "150ms", "msg=MSG-1", "rows=1", "args=1(redacted)", `err="duplicate key"`, "| INSERT INTO users (name) VALUES (?)"} {
+		if !strings.Contains(line, want) {
+			t.Errorf("log line %q lacks %q", line, want)
+		}
+	}
+	if strings.Contains(line, "Alice") {
+		t.Error("parameter value leaked into the log")
+	}
+}
+
+func TestQueryLogger_SetConfigAtRuntime(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewQueryLogger(QueryLogConfig{})
+	ev := QueryEvent{Statement: "SELECT * FROM t WHERE id = $1", Args: []any{42, nil}, Rows: -1}
+
+	l.Log(context.Background(), ev)
+	l.SetConfig(QueryLogConfig{Enabled: true, ShowArgs: true, MaxStatementLen: 10, Output: &buf})
+	l.Log(context.Background(), ev)
+
+	line := buf.String()
+	if strings.Count(line, "\n") != 1 {
+		t.Fatalf("expected one line after enabling, got %q", line)
+	}
+	if !strings.Contains(line, "args=[42, NULL]") || !strings.Contains(line, "| SELECT * F…(+19)") || strings.Contains(line, "rows=") {
+		t.Errorf("unexpected log line %q", line)
+	}
+
+	var nilLogger *QueryLogger
+	nilLogger.Log(context.Background(), ev) // nil-журнал ничего не пишет
+}
@@ -0,0 +1,19 @@
+package adapters
+
+import "context"
+
+type includeReadOnlyFieldsKey struct{}
+
+// WithIncludeReadOnlyFields задаёт, экспортировать ли read-only поля
+// (computed, timestamp/rowversion) — флаг CLI --readonly-fields. Учитывают
+// адаптеры, у которых такие поля есть (MS SQL Server).
+func WithIncludeReadOnlyFields(ctx context.Context, include bool) context.Context {
+	return context.WithValue(ctx, includeReadOnlyFieldsKey{}, include)
+}
+
+// IncludeReadOnlyFieldsFromContext возвращает флаг из WithIncludeReadOnlyFields
+// (по умолчанию false — read-only поля не экспортируются).
+func IncludeReadOnlyFieldsFromContext(ctx context.Context) bool {
+	include, _ := ctx.Value(includeReadOnlyFieldsKey{}).(bool)
+	return include
+}
@@ -0,0 +1,64 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+// RowErrorPolicy — что импорт делает со строкой пакета, значения которой
+// не приводятся к типам колонок (неверное число значений, не разбираемая
+// дата, число, превышение длины).
+type RowErrorPolicy string
+
+const (
+	// RowErrorsFailFast — первая такая строка останавливает импорт пакета
+	// (по умолчанию).
+	RowErrorsFailFast RowErrorPolicy = ""
+	// RowErrorsSkip — строка пропускается и попадает в ImportReport.
+	RowErrorsSkip RowErrorPolicy = "skip"
+	// RowErrorsDeadLetter — как RowErrorsSkip, и отклонённые строки
+	// пакета пишутся в карантинный пакет в RowErrorConfig.DeadLetterDir.
+	RowErrorsDeadLetter RowErrorPolicy = "dead-letter"
+)
+
+// RowErrorConfig — политика ошибок на уровне строк при импорте.
+// Ошибки, которые возвращает сама СУБД (ограничения, конфликт ключей),
+// останавливают импорт, если не включён PartialRollback.
+type RowErrorConfig struct {
+	Policy RowErrorPolicy
+
+	// PartialRollback — ImportPackets пишет каждый пакет во вложенной
+	// транзакции (Nested): пакет, на котором СУБД вернула ошибку,
+	// откатывается один, остальные фиксируются, а откат попадает в
+	// ImportReport (TableImportReport.RolledBack). Нужна транзакция с
+	// точками сохранения; действует в адаптерах на base.ImportHelper
+	// (SQLite, MySQL, Oracle), кроме замены таблицы через временную.
+	PartialRollback bool
+
+	// DeadLetterDir — каталог карантинных пакетов (RowErrorsDeadLetter):
+	// <таблица>_<MessageID>.rejected.tdtp.xml с исходной схемой, готовый
+	// к исправлению и повторному импорту.
+	DeadLetterDir string
+}
+
+// Validate проверяет политику.
+func (c RowErrorConfig) Validate() error {
+	switch c.Policy {
+	case RowErrorsFailFast, RowErrorsSkip:
+		return nil
+	case RowErrorsDeadLetter:
+		if c.DeadLetterDir == "" {
+			return fmt.Errorf("row error policy dead-letter requires a dead-letter directory")
+		}
+		return nil
+	}
+	return fmt.Errorf("invalid row error policy %q (expected skip or dead-letter)", c.Policy)
+}
+
+// ReportingImporter — адаптер возвращает отчёт импорта: строки,
+// отклонённые политикой RowErrorConfig, с ошибками и карантинными пакетами.
+type ReportingImporter interface {
+	ImportPacketsReport(ctx context.Context, packets []*packet.DataPacket, strategy ImportStrategy) (*ImportReport, error)
+}
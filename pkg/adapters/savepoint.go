@@ -0,0 +1,92 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// SavepointTx — транзакция с точками сохранения: часть работы откатывается
+// без отката всей транзакции (вложенная транзакция, см. Nested).
+// Реализуют транзакции SQLite, PostgreSQL, MySQL, MS SQL Server и Oracle;
+// MongoDB и Access — нет. Проверяется приведением типа (Savepoint,
+// RollbackTo, ReleaseSavepoint делают это сами).
+type SavepointTx interface {
+	Tx
+
+	// Savepoint создаёт точку сохранения name
+	Savepoint(ctx context.Context, name string) error
+
+	// RollbackTo откатывает изменения после точки name; транзакция и
+	// точка остаются (PostgreSQL: транзакция выходит из состояния ошибки)
+	RollbackTo(ctx context.Context, name string) error
+
+	// ReleaseSavepoint освобождает точку name, изменения остаются в
+	// транзакции. MS SQL Server и Oracle освобождают точки только вместе
+	// с транзакцией — для них это no-op.
+	ReleaseSavepoint(ctx context.Context, name string) error
+}
+
+// ErrSavepointsUnsupported — транзакция адаптера не реализует SavepointTx.
+var ErrSavepointsUnsupported = errors.New("transaction does not support savepoints")
+
+// savepointNameRe — имя точки подставляется в SQL как есть, поэтому только
+// идентификатор; 30 символов — предел Oracle (MS SQL Server — 32).
+var savepointNameRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]{0,29}$`)
+
+// ValidateSavepointName проверяет имя точки сохранения.
+func ValidateSavepointName(name string) error {
+	if !savepointNameRe.MatchString(name) {
+		return fmt.Errorf("invalid savepoint name %q (letters, digits and _, up to 30 characters)", name)
+	}
+	return nil
+}
+
+// Savepoint создаёт точку сохранения name в транзакции tx.
+func Savepoint(ctx context.Context, tx Tx, name string) error {
+	sp, ok := tx.(SavepointTx)
+	if !ok {
+		return ErrSavepointsUnsupported
+	}
+	return sp.Savepoint(ctx, name)
+}
+
+// RollbackTo откатывает транзакцию tx к точке сохранения name.
+func RollbackTo(ctx context.Context, tx Tx, name string) error {
+	sp, ok := tx.(SavepointTx)
+	if !ok {
+		return ErrSavepointsUnsupported
+	}
+	return sp.RollbackTo(ctx, name)
+}
+
+// ReleaseSavepoint освобождает точку сохранения name транзакции tx.
+func ReleaseSavepoint(ctx context.Context, tx Tx, name string) error {
+	sp, ok := tx.(SavepointTx)
+	if !ok {
+		return ErrSavepointsUnsupported
+	}
+	return sp.ReleaseSavepoint(ctx, name)
+}
+
+// Nested выполняет fn как вложенную транзакцию tx: перед fn создаётся
+// точка сохранения name, ошибка fn откатывает работу fn (остальная
+// транзакция сохраняется и может продолжаться), успех освобождает точку.
+// Возвращает ошибку fn (вместе с ошибкой отката, если откатить не удалось —
+// тогда транзакцию остаётся только откатить целиком).
+func Nested(ctx context.Context, tx Tx, name string, fn func(ctx context.Context) error) error {
+	if err := Savepoint(ctx, tx, name); err != nil {
+		return err
+	}
+	if err := fn(ctx); err != nil {
+		if rbErr := RollbackTo(ctx, tx, name); rbErr != nil {
+			return errors.Join(err, rbErr)
+		}
+		if relErr := ReleaseSavepoint(ctx, tx, name); relErr != nil {
+			return errors.Join(err, relErr)
+		}
+		return err
+	}
+	return ReleaseSavepoint(ctx, tx, name)
+}
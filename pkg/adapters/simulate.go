@@ -0,0 +1,191 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+	"github.com/ruslano69/tdtp-framework/pkg/diff"
+)
+
+// ========== Симуляция импорта ==========
+
+// ScratchPrefix — префикс имён scratch-схем и файлов симуляции импорта.
+const ScratchPrefix = "tdtp_scratch_"
+
+// Scratch — копия целевых таблиц для симуляции импорта (ScratchCreator):
+// отдельная схема той же БД (PostgreSQL, MS SQL) или копия файла БД (SQLite).
+type Scratch struct {
+	// Location — где лежит копия, для отчёта: схема или путь к файлу.
+	Location string
+
+	// Schema и DSN — Config.Schema и Config.DSN подключения к копии
+	// (пусто — как у цели).
+	Schema string
+	DSN    string
+}
+
+// ScratchCreator — адаптер умеет создать scratch-копию таблиц: структура
+// (с первичным ключом) и строки tables. Таблица, которой в цели нет, не
+// копируется — импорт в scratch создаст её, как создал бы в цели.
+type ScratchCreator interface {
+	CreateScratch(ctx context.Context, name string, tables []string) (*Scratch, error)
+	DropScratch(ctx context.Context, scratch *Scratch) error
+}
+
+// SimulateOptions — параметры SimulateImport.
+type SimulateOptions struct {
+	Strategy ImportStrategy
+
+	// KeepScratch — не удалять scratch после отчёта (для ручной проверки);
+	// удалить её потом можно через DropScratch или средствами СУБД.
+	KeepScratch bool
+}
+
+// SimulationReport — результат симуляции: изменения, которые импорт внёс
+// бы в каждую таблицу цели.
+type SimulationReport struct {
+	Strategy ImportStrategy
+	Scratch  string // Scratch.Location
+	Kept     bool   // scratch не удалена (SimulateOptions.KeepScratch)
+	Tables   []*TableSimulation
+}
+
+// TableSimulation — изменения одной таблицы: строки цели против строк
+// scratch после импорта (Added — новые, Removed — удалённые, Modified —
+// изменённые).
+type TableSimulation struct {
+	Table   string
+	Created bool // таблицы в цели нет: импорт создаст её
+	Diff    *diff.DiffResult
+}
+
+// Changed сообщает, изменил бы импорт хотя бы одну таблицу.
+func (r *SimulationReport) Changed() bool {
+	for _, t := range r.Tables {
+		if t.Created || !t.Diff.IsEqual() {
+			return true
+		}
+	}
+	return false
+}
+
+// SimulateImport применяет пакеты не к цели, а к её scratch-копии и
+// сравнивает таблицы цели с результатом: отчёт показывает, какие строки
+// импорт добавил бы, удалил или изменил, до того как изменения одобрены.
+// cfg — конфигурация подключения адаптера a; копия подключается по ней
+// (с Schema/DSN копии) без окон обслуживания: цель импорт не затрагивает.
+//
+// Таблицы цели и копии читаются целиком — режим рассчитан на справочники
+// и другие таблицы, изменения которых просматривают перед применением.
+func SimulateImport(ctx context.Context, a Adapter, cfg Config, packets []*packet.DataPacket, opts SimulateOptions) (*SimulationReport, error) {
+	creator, ok := a.(ScratchCreator)
+	if !ok {
+		return nil, fmt.Errorf("adapter %s does not support import simulation", a.GetDatabaseType())
+	}
+	if len(packets) == 0 {
+		return nil, fmt.Errorf("no packets to simulate")
+	}
+	var tables []string
+	seen := make(map[string]bool)
+	for _, pkt := range packets {
+		if name := pkt.Header.TableName; !seen[name] {
+			seen[name] = true
+			tables = append(tables, name)
+		}
+	}
+
+	name := ScratchPrefix + time.Now().UTC().Format("20060102_150405")
+	scratch, err := creator.CreateScratch(ctx, name, tables)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create scratch copy: %w", err)
+	}
+	report := &SimulationReport{Strategy: opts.Strategy, Scratch: scratch.Location, Kept: opts.KeepScratch}
+	defer func() {
+		if opts.KeepScratch {
+			return
+		}
+		if err := creator.DropScratch(context.WithoutCancel(ctx), scratch); err != nil {
+			fmt.Printf("  ⚠ failed to drop scratch %s: %v\n", scratch.Location, err)
+		}
+	}()
+
+	scratchCfg := cfg
+	if scratch.Schema != "" {
+		scratchCfg.Schema = scratch.Schema
+	}
+	if scratch.DSN != "" {
+		scratchCfg.DSN = scratch.DSN
+	}
+	scratchCfg.Maintenance = nil
+	target, err := New(ctx, scratchCfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to scratch %s: %w", scratch.Location, err)
+	}
+	defer func() { _ = target.Close(ctx) }()
+
+	if err := target.ImportPackets(ctx, packets, opts.Strategy); err != nil {
+		return nil, fmt.Errorf("simulated import into %s failed: %w", scratch.Location, err)
+	}
+
+	for _, table := range tables {
+		t, err := simulateTable(ctx, a, target, table, packets)
+		if err != nil {
+			return nil, fmt.Errorf("table %s: %w", table, err)
+		}
+		report.Tables = append(report.Tables, t)
+	}
+	return report, nil
+}
+
+// simulateTable сравнивает таблицу цели со scratch-копией после импорта.
+func simulateTable(ctx context.Context, a, scratch Adapter, table string, packets []*packet.DataPacket) (*TableSimulation, error) {
+	after, err := exportWhole(ctx, scratch, table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scratch copy: %w", err)
+	}
+	exists, err := a.TableExists(ctx, table)
+	if err != nil {
+		return nil, err
+	}
+	before := &packet.DataPacket{Header: after.Header, Schema: after.Schema}
+	if exists {
+		if before, err = exportWhole(ctx, a, table); err != nil {
+			return nil, fmt.Errorf("failed to read target: %w", err)
+		}
+	}
+
+	// Ключ сравнения — ключ пакетов, иначе первичный ключ таблицы.
+	var keys []string
+	for _, pkt := range packets {
+		if pkt.Header.TableName == table {
+			keys = packet.ExtractKeyFields(pkt.Schema)
+			break
+		}
+	}
+	d, err := diff.NewDiffer(diff.DiffOptions{KeyFields: keys, CaseSensitive: true}).Compare(before, after)
+	if err != nil {
+		return nil, err
+	}
+	return &TableSimulation{Table: table, Created: !exists, Diff: d}, nil
+}
+
+// exportWhole читает таблицу одним пакетом: строки всех частей экспорта.
+func exportWhole(ctx context.Context, a Adapter, table string) (*packet.DataPacket, error) {
+	parts, err := a.ExportTable(ctx, table)
+	if err != nil {
+		return nil, err
+	}
+	if len(parts) == 0 {
+		return nil, fmt.Errorf("export returned no packets")
+	}
+	var rows [][]string
+	for _, p := range parts {
+		rows = append(rows, p.GetRows()...)
+	}
+	whole := &packet.DataPacket{Header: parts[0].Header, Schema: parts[0].Schema}
+	whole.Header.TableName = table
+	whole.SetRows(rows)
+	return whole, nil
+}
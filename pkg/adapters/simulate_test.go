@@ -0,0 +1,75 @@
+package adapters
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+// tableAdapter — Adapter с одной таблицей в памяти (остальные методы не нужны).
+type tableAdapter struct {
+	Adapter
+	rows [][]string // nil — таблицы нет
+}
+
+var simulateSchema = packet.Schema{Fields: []packet.Field{
+	{Name: "code", Type: "TEXT", Length: 3, Key: true},
+	{Name: "name", Type: "TEXT", Length: 50},
+}}
+
+func (a *tableAdapter) TableExists(context.Context, string) (bool, error) {
+	return a.rows != nil, nil
+}
+
+func (a *tableAdapter) ExportTable(_ context.Context, table string) ([]*packet.DataPacket, error) {
+	return packet.NewGenerator().GenerateReference(table, simulateSchema, a.rows)
+}
+
+func (a *tableAdapter) GetDatabaseType() string { return "memory" }
+
+func TestSimulateTable(t *testing.T) {
+	target := &tableAdapter{rows: [][]string{{"USD", "Dollar"}, {"EUR", "Euro"}, {"RUB", "Ruble"}}}
+	scratch := &tableAdapter{rows: [][]string{{"USD", "US Dollar"}, {"EUR", "Euro"}, {"GBP", "Pound"}}}
+	packets, err := packet.NewGenerator().GenerateReference("currencies", simulateSchema, scratch.rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sim, err := simulateTable(context.Background(), target, scratch, "currencies", packets)
+	if err != nil {
+		t.Fatalf("simulateTable: %v", err)
+	}
+	s := sim.Diff.Stats
+	if sim.Created || s.AddedCount != 1 || s.RemovedCount != 1 || s.ModifiedCount != 1 || s.UnchangedCount != 1 {
+		t.Errorf("got created=%v stats %+v, want 1 added, 1 removed, 1 modified, 1 unchanged", sim.Created, s)
+	}
+	report := &SimulationReport{Tables: []*TableSimulation{sim}}
+	if !report.Changed() {
+		t.Error("Changed() = false, want true")
+	}
+
+	// Таблицы в цели нет: все строки scratch — добавленные
+	sim, err = simulateTable(context.Background(), &tableAdapter{}, scratch, "currencies", packets)
+	if err != nil {
+		t.Fatalf("simulateTable (new table): %v", err)
+	}
+	if !sim.Created || sim.Diff.Stats.AddedCount != 3 {
+		t.Errorf("got created=%v added=%d, want created table with 3 added rows", sim.Created, sim.Diff.Stats.AddedCount)
+	}
+
+	// Импорт ничего не меняет
+	sim, err = simulateTable(context.Background(), scratch, scratch, "currencies", packets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report := (&SimulationReport{Tables: []*TableSimulation{sim}}); report.Changed() {
+		t.Errorf("Changed() = true for identical tables: %+v", sim.Diff.Stats)
+	}
+}
+
+func TestSimulateImport_Unsupported(t *testing.T) {
+	if _, err := SimulateImport(context.Background(), &tableAdapter{}, Config{}, nil, SimulateOptions{}); err == nil {
+		t.Error("expected error for adapter without ScratchCreator")
+	}
+}
@@ -23,6 +23,7 @@ func init() {
 	adapters.Register("sqlite", func() adapters.Adapter {
 		return &Adapter{}
 	})
+	adapters.Describe(driverInfo())
 }
 
 // Adapter представляет адаптер для работы с SQLite
@@ -30,24 +31,47 @@ func init() {
 type Adapter struct {
 	db *sql.DB
 
+	// cipherDSN — DSN БД, открытой через SQLCipher ("" — без шифрования);
+	// нужен Rekey, чтобы переоткрыть пул с новым ключом.
+	cipherDSN string
+
 	// Base helpers (added in refactoring to eliminate code duplication)
 	exportHelper *base.ExportHelper
 	importHelper *base.ImportHelper
 	converter    *base.UniversalTypeConverter
+
+	queryLog *adapters.QueryLogger // журнал SQL-выражений (Config.QueryLog)
+	lockBase string                // префикс lock-файлов таблиц (TryLockTable)
 }
 
 // Connect устанавливает подключение к SQLite
 // Реализует интерфейс adapters.Adapter
 func (a *Adapter) Connect(ctx context.Context, cfg adapters.Config) error {
-	db, err := sql.Open(driverSqlite, cfg.DSN)
+	governor, err := adapters.NewGovernor(cfg.ImportLimits)
 	if err != nil {
-		return fmt.Errorf("failed to open database: %w", err)
+		return fmt.Errorf("invalid import limits: %w", err)
 	}
 
-	// Проверяем подключение
-	if err := db.PingContext(ctx); err != nil {
-		_ = db.Close()
-		return fmt.Errorf("failed to ping database: %w", err)
+	a.queryLog = adapters.NewQueryLogger(cfg.QueryLog)
+	var db *sql.DB
+	if len(cfg.EncryptionKey) > 0 {
+		// SQLCipher: ключ и его проверка — в openEncrypted
+		db, err = openEncrypted(ctx, cfg.DSN, cfg.EncryptionKey, a.queryLog)
+		if err != nil {
+			return err
+		}
+		a.cipherDSN = cfg.DSN
+	} else {
+		db, err = base.OpenDB(driverSqlite, cfg.DSN, a.queryLog)
+		if err != nil {
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+
+		// Проверяем подключение
+		if err := db.PingContext(ctx); err != nil {
+			_ = db.Close()
+			return fmt.Errorf("failed to ping database: %w", err)
+		}
 	}
 
 	a.db = db
@@ -57,10 +81,60 @@ func (a *Adapter) Connect(ctx context.Context, cfg adapters.Config) error {
 
 	// Инициализируем base helpers
 	a.initHelpers(cfg.NoDateSentinels)
+	if cfg.SourceTimezone != "" {
+		if err := a.converter.SetSourceTimezone(cfg.SourceTimezone); err != nil {
+			_ = db.Close()
+			return err
+		}
+	}
+	if err := a.converter.SetBoolMappings(cfg.Booleans, cfg.ColumnBooleans); err != nil {
+		_ = db.Close()
+		return err
+	}
+	if err := a.exportHelper.SetPacketSizing(cfg.Packets); err != nil {
+		_ = db.Close()
+		return err
+	}
+	a.importHelper.SetGovernor(governor)
+	a.importHelper.SetStreamBatchPackets(cfg.StreamBatchPackets)
+	if err := cfg.TableLock.Validate(); err != nil {
+		_ = db.Close()
+		return err
+	}
+	a.importHelper.SetTableLocking(cfg.TableLock)
+	a.importHelper.SetMaintenanceWindows(cfg.Maintenance)
+	if err := cfg.Duplicates.Validate(); err != nil {
+		_ = db.Close()
+		return err
+	}
+	a.importHelper.SetDuplicateMode(cfg.Duplicates)
+	if err := cfg.Columns.Validate(); err != nil {
+		_ = db.Close()
+		return err
+	}
+	a.importHelper.SetColumnMatching(cfg.Columns)
+	a.importHelper.SetColumnCipher(cfg.ColumnCipher)
+	a.exportHelper.SetColumnCipher(cfg.ColumnCipher)
+	a.exportHelper.SetExportPolicy(cfg.ExportPolicy)
+	if err := cfg.RowErrors.Validate(); err != nil {
+		_ = db.Close()
+		return err
+	}
+	a.importHelper.SetRowErrorPolicy(cfg.RowErrors, a.converter, "sqlite")
+	a.lockBase = lockBasePath(cfg.DSN)
 
 	return nil
 }
 
+// TimezoneReport реализует adapters.TimezoneReporter: отчёт о переводе
+// naive datetime из Config.SourceTimezone в UTC (nil — пояс не задан).
+func (a *Adapter) TimezoneReport() *adapters.TimezoneReport {
+	if a.converter == nil {
+		return nil
+	}
+	return a.converter.TimezoneReport()
+}
+
 // NewAdapter создает новый адаптер для SQLite (legacy)
 //
 // Deprecated: используйте adapters.New() с фабрикой
@@ -76,6 +150,11 @@ func NewAdapter(filePath string) (*Adapter, error) {
 	return adapter, nil
 }
 
+// QueryLog реализует adapters.QueryLogged: журнал выражений адаптера.
+func (a *Adapter) QueryLog() *adapters.QueryLogger {
+	return a.queryLog
+}
+
 // Close закрывает соединение с БД
 // Реализует интерфейс adapters.Adapter
 func (a *Adapter) Close(ctx context.Context) error {
@@ -128,6 +207,8 @@ func (a *Adapter) initHelpers(noDateSentinels []string) {
 	// self реализует SchemaReader и DataReader интерфейсы
 	// nil = не нужна адаптация SQL для SQLite (стандартный LIMIT/OFFSET)
 	a.exportHelper = base.NewExportHelper(a, a, a.converter, nil)
+	a.exportHelper.SetJoinPushdown(true)
+	a.exportHelper.SetASCIILower(true) // LOWER в SQLite не знает кириллицы
 
 	// Создаем import helper
 	// self реализует TableManager, DataInserter, TransactionManager интерфейсы
@@ -292,6 +373,8 @@ type sqliteTx struct {
 	tx *sql.Tx
 }
 
+var _ adapters.SavepointTx = (*sqliteTx)(nil)
+
 func (t *sqliteTx) Commit(ctx context.Context) error {
 	return t.tx.Commit()
 }
@@ -300,6 +383,21 @@ func (t *sqliteTx) Rollback(ctx context.Context) error {
 	return t.tx.Rollback()
 }
 
+// Savepoint создаёт точку сохранения (adapters.SavepointTx)
+func (t *sqliteTx) Savepoint(ctx context.Context, name string) error {
+	return base.StandardSavepoints.Savepoint(ctx, t.tx, name)
+}
+
+// RollbackTo откатывает транзакцию к точке сохранения
+func (t *sqliteTx) RollbackTo(ctx context.Context, name string) error {
+	return base.StandardSavepoints.RollbackTo(ctx, t.tx, name)
+}
+
+// ReleaseSavepoint освобождает точку сохранения
+func (t *sqliteTx) ReleaseSavepoint(ctx context.Context, name string) error {
+	return base.StandardSavepoints.ReleaseSavepoint(ctx, t.tx, name)
+}
+
 // ExecuteRawQuery выполняет произвольный SQL SELECT запрос и возвращает результат как DataPacket.
 // Используется ETL pipeline для загрузки данных из источников.
 // Использует тот же путь что и ExportTable: ReadRowsWithSQL → scanRows → RowsToData.
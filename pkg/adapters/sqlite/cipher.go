@@ -0,0 +1,190 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+	"github.com/ruslano69/tdtp-framework/pkg/adapters/base"
+)
+
+// Шифрование БД at rest (SQLCipher): реплики SQLite на ноутбуках полевых
+// сотрудников не должны читаться без ключа.
+//
+// modernc.org/sqlite (pure Go) SQLCipher не поддерживает, поэтому
+// зашифрованная БД открывается через отдельный cgo-драйвер с SQLCipher.
+// Сборка с тегом sqlcipher (CGO_ENABLED=1) подключает
+// github.com/mutecomm/go-sqlcipher/v4 сама (cipher_sqlcipher.go); другой
+// драйвер сборка регистрирует в database/sql и сообщает адаптеру:
+//
+//	sqlite.RegisterCipherDriver("sqlcipher", "example.com/my-sqlcipher")
+//
+// Без такого драйвера Connect с EncryptionKey возвращает
+// ErrCipherUnsupported — молча писать открытую БД вместо зашифрованной
+// нельзя.
+
+var (
+	// ErrCipherUnsupported — драйвер SQLCipher не зарегистрирован или
+	// зарегистрированный драйвер не поддерживает шифрование.
+	ErrCipherUnsupported = errors.New("SQLCipher is not available")
+
+	// ErrWrongKey — ключ не подходит к БД (или файл не зашифрован).
+	ErrWrongKey = errors.New("wrong encryption key or database is not encrypted")
+
+	// ErrNotEncrypted — Rekey для адаптера, открытого без ключа.
+	ErrNotEncrypted = errors.New("database was opened without an encryption key")
+)
+
+// cipherDriver — имя драйвера database/sql с поддержкой SQLCipher,
+// cipherModule — его Go-модуль (для adapters.Describe).
+var cipherDriver, cipherModule string
+
+// RegisterCipherDriver задаёт драйвер database/sql name (модуль module),
+// через который открываются зашифрованные БД (Config.EncryptionKey).
+// Вызывается при инициализации сборки, в которую слинкован SQLCipher.
+func RegisterCipherDriver(name, module string) {
+	cipherDriver, cipherModule = name, module
+	adapters.Describe(driverInfo())
+}
+
+// driverInfo — описание адаптера для adapters.Available: открытые БД
+// работают через modernc.org/sqlite, зашифрованные — через cgo-обёртку
+// над libsqlcipher.
+func driverInfo() adapters.DriverInfo {
+	info := adapters.DriverInfo{Type: "sqlite", Driver: "modernc.org/sqlite"}
+	if cipherDriver != "" {
+		info.Driver += ", " + cipherModule
+		info.CGO = true
+		info.Features = []string{"sqlcipher"}
+	}
+	return info
+}
+
+// keyConnector открывает соединения драйвера SQLCipher и первым делом
+// выполняет PRAGMA key: ключ действует на соединение, а database/sql
+// создаёт соединения пула по мере надобности.
+type keyConnector struct {
+	drv driver.Driver
+	dsn string
+	key []byte
+}
+
+// Connect реализует driver.Connector.
+func (c *keyConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.drv.Open(c.dsn)
+	if err != nil {
+		return nil, err
+	}
+	if err := execRaw(ctx, conn, keyPragma("key", c.key)); err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("failed to set encryption key: %w", err)
+	}
+	return conn, nil
+}
+
+// Driver реализует driver.Connector.
+func (c *keyConnector) Driver() driver.Driver {
+	return c.drv
+}
+
+// execRaw выполняет запрос на соединении драйвера в обход пула.
+func execRaw(ctx context.Context, conn driver.Conn, query string) error {
+	execer, ok := conn.(driver.ExecerContext)
+	if !ok {
+		return fmt.Errorf("driver %T does not support ExecContext", conn)
+	}
+	_, err := execer.ExecContext(ctx, query, nil)
+	return err
+}
+
+// keyPragma — PRAGMA key/rekey с сырым 256-битным ключом (x'..'): SQLCipher
+// использует его напрямую, без PBKDF2 — ключ уже случайный.
+func keyPragma(name string, key []byte) string {
+	return fmt.Sprintf(`PRAGMA %s = "x'%s'"`, name, hex.EncodeToString(key))
+}
+
+// openEncrypted открывает БД через драйвер SQLCipher с ключом key и
+// проверяет, что ключ подходит. Выражения пула пишутся в журнал log.
+func openEncrypted(ctx context.Context, dsn string, key []byte, log *adapters.QueryLogger) (*sql.DB, error) {
+	if cipherDriver == "" || !slices.Contains(sql.Drivers(), cipherDriver) {
+		return nil, fmt.Errorf("%w: rebuild with CGO_ENABLED=1 -tags sqlcipher (or register a driver with sqlite.RegisterCipherDriver)", ErrCipherUnsupported)
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("encryption key must be 32 bytes, got %d", len(key))
+	}
+
+	probe, err := sql.Open(cipherDriver, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	drv := probe.Driver()
+	_ = probe.Close()
+
+	db := sql.OpenDB(base.LogConnector(&keyConnector{drv: drv, dsn: dsn, key: key}, log))
+	if err := verifyKey(ctx, db); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// verifyKey проверяет на подключении, что драйвер действительно шифрует
+// (PRAGMA cipher_version есть только в SQLCipher) и что ключ подходит:
+// с неверным ключом первое чтение страницы падает "file is not a database".
+func verifyKey(ctx context.Context, db *sql.DB) error {
+	var version string
+	err := db.QueryRowContext(ctx, "PRAGMA cipher_version").Scan(&version)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && version == "") {
+		return fmt.Errorf("%w: driver %q is plain SQLite", ErrCipherUnsupported, cipherDriver)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrWrongKey, err)
+	}
+
+	var n int
+	if err := db.QueryRowContext(ctx, "SELECT count(*) FROM sqlite_master").Scan(&n); err != nil {
+		return fmt.Errorf("%w: %v", ErrWrongKey, err)
+	}
+	return nil
+}
+
+// Encrypted сообщает, открыта ли БД с ключом SQLCipher.
+func (a *Adapter) Encrypted() bool {
+	return a.cipherDSN != ""
+}
+
+// Rekey перешифровывает БД новым ключом (PRAGMA rekey) и переоткрывает
+// пул с ним: соединения, открытые со старым ключом, после rekey читать
+// файл уже не могут. Вызывать без параллельных операций на адаптере.
+func (a *Adapter) Rekey(ctx context.Context, newKey []byte) error {
+	if !a.Encrypted() {
+		return ErrNotEncrypted
+	}
+	if len(newKey) != 32 {
+		return fmt.Errorf("encryption key must be 32 bytes, got %d", len(newKey))
+	}
+
+	conn, err := a.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get connection: %w", err)
+	}
+	_, err = conn.ExecContext(ctx, keyPragma("rekey", newKey))
+	_ = conn.Close()
+	if err != nil {
+		return fmt.Errorf("failed to rekey database: %w", err)
+	}
+
+	_ = a.db.Close()
+	db, err := openEncrypted(ctx, a.cipherDSN, newKey, a.queryLog)
+	if err != nil {
+		return fmt.Errorf("failed to reopen database with the new key: %w", err)
+	}
+	a.db = db
+	a.applyPragmaOptimizations(ctx)
+	return nil
+}
@@ -0,0 +1,12 @@
+//go:build sqlcipher && cgo
+
+package sqlite
+
+import (
+	_ "github.com/mutecomm/go-sqlcipher/v4" // драйвер "sqlite3" с SQLCipher (cgo)
+)
+
+// Сборка с тегом sqlcipher открывает зашифрованные БД через go-sqlcipher.
+func init() {
+	RegisterCipherDriver("sqlite3", "github.com/mutecomm/go-sqlcipher/v4")
+}
@@ -0,0 +1,43 @@
+//go:build sqlcipher && cgo
+
+package sqlite
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+)
+
+func TestSQLCipher_EncryptsDatabase(t *testing.T) {
+	ctx := context.Background()
+	dsn := filepath.Join(t.TempDir(), "replica.db")
+
+	a := &Adapter{}
+	if err := a.Connect(ctx, adapters.Config{Type: "sqlite", DSN: dsn, EncryptionKey: testKey(1)}); err != nil {
+		t.Fatalf("Connect via built-in SQLCipher: %v", err)
+	}
+	if _, err := a.db.ExecContext(ctx, "CREATE TABLE secrets (v TEXT); INSERT INTO secrets VALUES ('top-secret-value')"); err != nil {
+		t.Fatal(err)
+	}
+	_ = a.Close(ctx)
+
+	raw, err := os.ReadFile(dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(raw, []byte("top-secret-value")) || bytes.HasPrefix(raw, []byte("SQLite format 3")) {
+		t.Fatal("database file is not encrypted")
+	}
+
+	if err := (&Adapter{}).Connect(ctx, adapters.Config{Type: "sqlite", DSN: dsn, EncryptionKey: testKey(2)}); !errors.Is(err, ErrWrongKey) {
+		t.Errorf("Connect with a wrong key = %v, want ErrWrongKey", err)
+	}
+	if info := driverInfo(); !info.CGO || info.Driver != "modernc.org/sqlite, github.com/mutecomm/go-sqlcipher/v4" {
+		t.Errorf("driverInfo() = %+v", info)
+	}
+}
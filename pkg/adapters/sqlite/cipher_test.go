@@ -0,0 +1,198 @@
+package sqlite
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+)
+
+// fakeCipherDriver эмулирует SQLCipher: файл (dsn) зашифрован ключом,
+// соединение читает его, только если PRAGMA key совпал. plain — драйвер
+// без SQLCipher (PRAGMA cipher_version ничего не возвращает).
+type fakeCipherDriver struct {
+	mu    sync.Mutex
+	plain bool
+	keys  map[string]string // dsn → PRAGMA-литерал ключа файла
+}
+
+type fakeCipherConn struct {
+	drv *fakeCipherDriver
+	dsn string
+	key string
+}
+
+func (d *fakeCipherDriver) Open(dsn string) (driver.Conn, error) {
+	return &fakeCipherConn{drv: d, dsn: dsn}, nil
+}
+
+func (c *fakeCipherConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("not supported")
+}
+func (c *fakeCipherConn) Close() error              { return nil }
+func (c *fakeCipherConn) Begin() (driver.Tx, error) { return nil, errors.New("not supported") }
+
+func (c *fakeCipherConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	c.drv.mu.Lock()
+	defer c.drv.mu.Unlock()
+	switch {
+	case strings.HasPrefix(query, "PRAGMA key = "):
+		c.key = strings.TrimPrefix(query, "PRAGMA key = ")
+	case strings.HasPrefix(query, "PRAGMA rekey = "):
+		if c.key != c.drv.keys[c.dsn] {
+			return nil, errors.New("file is not a database")
+		}
+		c.key = strings.TrimPrefix(query, "PRAGMA rekey = ")
+		c.drv.keys[c.dsn] = c.key
+	}
+	return driver.RowsAffected(0), nil
+}
+
+func (c *fakeCipherConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	c.drv.mu.Lock()
+	defer c.drv.mu.Unlock()
+	switch query {
+	case "PRAGMA cipher_version":
+		if c.drv.plain {
+			return &fakeRows{}, nil
+		}
+		return &fakeRows{values: []driver.Value{"4.6.1 community"}}, nil
+	case "SELECT count(*) FROM sqlite_master":
+		if c.key != c.drv.keys[c.dsn] {
+			return nil, errors.New("file is not a database")
+		}
+		return &fakeRows{values: []driver.Value{int64(0)}}, nil
+	}
+	return nil, errors.New("unexpected query: " + query)
+}
+
+type fakeRows struct {
+	values []driver.Value
+	done   bool
+}
+
+func (r *fakeRows) Columns() []string { return make([]string, len(r.values)) }
+func (r *fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.done || r.values == nil {
+		return io.EOF
+	}
+	r.done = true
+	copy(dest, r.values)
+	return nil
+}
+
+var (
+	fakeCipher      = &fakeCipherDriver{keys: map[string]string{}}
+	fakePlainCipher = &fakeCipherDriver{plain: true, keys: map[string]string{}}
+)
+
+func init() {
+	sql.Register("tdtp-fake-sqlcipher", fakeCipher)
+	sql.Register("tdtp-fake-plain", fakePlainCipher)
+}
+
+// useCipherDriver регистрирует драйвер на время теста.
+func useCipherDriver(t *testing.T, name string) {
+	t.Helper()
+	prevName, prevModule := cipherDriver, cipherModule
+	RegisterCipherDriver(name, "example.com/"+name)
+	t.Cleanup(func() { RegisterCipherDriver(prevName, prevModule) })
+}
+
+// encryptFakeFile «шифрует» файл dsn ключом key.
+func encryptFakeFile(dsn string, key []byte) {
+	fakeCipher.mu.Lock()
+	defer fakeCipher.mu.Unlock()
+	fakeCipher.keys[dsn] = strings.TrimPrefix(keyPragma("key", key), "PRAGMA key = ")
+}
+
+func testKey(b byte) []byte {
+	return bytes.Repeat([]byte{b}, 32)
+}
+
+func TestConnect_EncryptionKeyWithoutCipherDriver(t *testing.T) {
+	useCipherDriver(t, "")
+	a := &Adapter{}
+	err := a.Connect(context.Background(), adapters.Config{Type: "sqlite", DSN: "replica.db", EncryptionKey: testKey(1)})
+	if !errors.Is(err, ErrCipherUnsupported) {
+		t.Fatalf("Connect = %v, want ErrCipherUnsupported", err)
+	}
+}
+
+func TestOpenEncrypted_VerifiesKey(t *testing.T) {
+	ctx := context.Background()
+	useCipherDriver(t, "tdtp-fake-sqlcipher")
+	encryptFakeFile("verify.db", testKey(1))
+
+	db, err := openEncrypted(ctx, "verify.db", testKey(1), nil)
+	if err != nil {
+		t.Fatalf("openEncrypted with the right key: %v", err)
+	}
+	_ = db.Close()
+
+	if _, err := openEncrypted(ctx, "verify.db", testKey(2), nil); !errors.Is(err, ErrWrongKey) {
+		t.Errorf("openEncrypted with a wrong key = %v, want ErrWrongKey", err)
+	}
+	if _, err := openEncrypted(ctx, "verify.db", []byte("short"), nil); err == nil {
+		t.Error("openEncrypted accepted a 5-byte key")
+	}
+
+	// Драйвер без SQLCipher записал бы открытую БД — отказ
+	useCipherDriver(t, "tdtp-fake-plain")
+	if _, err := openEncrypted(ctx, "verify.db", testKey(1), nil); !errors.Is(err, ErrCipherUnsupported) {
+		t.Errorf("openEncrypted via plain driver = %v, want ErrCipherUnsupported", err)
+	}
+}
+
+func TestAdapter_Rekey(t *testing.T) {
+	ctx := context.Background()
+	useCipherDriver(t, "tdtp-fake-sqlcipher")
+	encryptFakeFile("rekey.db", testKey(1))
+
+	a := &Adapter{}
+	if err := a.Connect(ctx, adapters.Config{Type: "sqlite", DSN: "rekey.db", EncryptionKey: testKey(1)}); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer func() { _ = a.Close(ctx) }()
+	if !a.Encrypted() {
+		t.Fatal("Encrypted() = false for a keyed connection")
+	}
+
+	if err := a.Rekey(ctx, testKey(2)); err != nil {
+		t.Fatalf("Rekey: %v", err)
+	}
+	// Пул переоткрыт с новым ключом, старый ключ файл больше не открывает
+	if err := verifyKey(ctx, a.db); err != nil {
+		t.Errorf("verifyKey after Rekey: %v", err)
+	}
+	if _, err := openEncrypted(ctx, "rekey.db", testKey(1), nil); !errors.Is(err, ErrWrongKey) {
+		t.Errorf("old key after Rekey = %v, want ErrWrongKey", err)
+	}
+
+	if err := (&Adapter{}).Rekey(ctx, testKey(3)); !errors.Is(err, ErrNotEncrypted) {
+		t.Errorf("Rekey of an unencrypted adapter = %v, want ErrNotEncrypted", err)
+	}
+}
+
+func TestRegisterCipherDriver_DescribesDriver(t *testing.T) {
+	useCipherDriver(t, "tdtp-fake-sqlcipher")
+	info := driverInfo()
+	if !info.CGO || !slices.Contains(info.Features, "sqlcipher") ||
+		info.Driver != "modernc.org/sqlite, example.com/tdtp-fake-sqlcipher" {
+		t.Errorf("driverInfo() with SQLCipher = %+v", info)
+	}
+
+	useCipherDriver(t, "")
+	if info := driverInfo(); info.CGO || len(info.Features) != 0 || info.Driver != "modernc.org/sqlite" {
+		t.Errorf("driverInfo() without SQLCipher = %+v", info)
+	}
+}
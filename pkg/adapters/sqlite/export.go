@@ -14,16 +14,58 @@ import (
 
 // ========== Делегирование в ExportHelper ==========
 
+// SetMaxMessageSize задаёт максимальный размер одного TDTP пакета (в байтах).
+// Используется CLI для передачи --packet-size.
+func (a *Adapter) SetMaxMessageSize(size int) {
+	a.exportHelper.SetMaxMessageSize(size)
+}
+
 // SetSkipSpecialValues включает режим --fast: DetectAndApply пропускается.
 func (a *Adapter) SetSkipSpecialValues(skip bool) {
 	a.exportHelper.SetSkipSpecialValues(skip)
 }
 
+// SetCompression включает zstd-сжатие Data экспортируемых пакетов
+// (см. base.ExportHelper.SetCompression).
+func (a *Adapter) SetCompression(opts packet.CompressionOptions) {
+	a.exportHelper.SetCompression(opts)
+}
+
+// SetPacketKeys включает шифрование пакетов TDTP v1.5 при экспорте и их
+// расшифровку при импорте (см. base.ExportHelper.SetPacketKeys).
+func (a *Adapter) SetPacketKeys(keys base.PacketKeyProvider) {
+	a.exportHelper.SetPacketKeys(keys)
+	a.importHelper.SetPacketKeys(keys)
+}
+
+// SetKeyMapper задаёт выдачу суррогатных ключей при импорте (keymap.Mapper).
+func (a *Adapter) SetKeyMapper(m base.KeyMapper) {
+	a.importHelper.SetKeyMapper(m)
+}
+
 // SetMaxFallbackRows задаёт лимит строк для in-memory fallback при провале SQL pushdown.
 func (a *Adapter) SetMaxFallbackRows(n int64) {
 	a.exportHelper.SetMaxFallbackRows(n)
 }
 
+// SetTableQueries задаёт собственные SELECT для таблиц вместо SELECT *
+// (см. base.ExportHelper.SetTableQueries).
+func (a *Adapter) SetTableQueries(queries map[string]string) error {
+	return a.exportHelper.SetTableQueries(queries)
+}
+
+// ExportQuery экспортирует результат произвольного read-only SQL
+// (adapters.QueryExporter, см. base.ExportHelper.ExportQuery).
+func (a *Adapter) ExportQuery(ctx context.Context, sql string) ([]*packet.DataPacket, error) {
+	return a.exportHelper.ExportQuery(ctx, sql)
+}
+
+// ExplainQuery возвращает план ExportTableWithQuery без чтения строк
+// (adapters.QueryExplainer, см. base.ExportHelper.Explain).
+func (a *Adapter) ExplainQuery(ctx context.Context, tableName string, query *packet.Query) (*packet.ExecutionPlan, error) {
+	return a.exportHelper.Explain(ctx, tableName, query)
+}
+
 // ExportTable экспортирует всю таблицу в TDTP reference пакеты
 // Делегирует выполнение в base.ExportHelper
 func (a *Adapter) ExportTable(ctx context.Context, tableName string) ([]*packet.DataPacket, error) {
@@ -88,15 +130,67 @@ func (a *Adapter) GetTableSchema(ctx context.Context, tableName string) (packet.
 	}
 
 	if len(fields) == 0 {
-		return packet.Schema{}, fmt.Errorf("table %s not found or has no columns", tableName)
+		return packet.Schema{}, fmt.Errorf("table %s %w", tableName, adapters.ErrTableNotFound)
 	}
 
 	return packet.Schema{Fields: fields}, nil
 }
 
+// GetQuerySchema возвращает схему результата SELECT по объявленным типам
+// колонок (строки не читаются: запрос обёрнут в WHERE 1=0). Объявленный тип
+// разбирается как в GetTableSchema, поэтому длина и точность сохраняются.
+// Реализует base.QuerySchemaReader для собственных запросов таблиц.
+func (a *Adapter) GetQuerySchema(ctx context.Context, query string) (packet.Schema, error) {
+	rows, err := a.db.QueryContext(ctx, base.MetadataQuery(query))
+	if err != nil {
+		return packet.Schema{}, fmt.Errorf("failed to describe query: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return packet.Schema{}, fmt.Errorf("failed to get column types: %w", err)
+	}
+	schema := packet.Schema{Fields: make([]packet.Field, len(columnTypes))}
+	for i, ct := range columnTypes {
+		// Колонка таблицы несёт объявленный тип (длина, точность DECIMAL);
+		// у выражений он пуст — тогда тип по классу значения
+		field, err := BuildFieldFromColumn(ct.Name(), ct.DatabaseTypeName(), false)
+		if err != nil || ct.DatabaseTypeName() == "" {
+			tdtpType, length := convertSQLiteTypeToTDTP(ct.DatabaseTypeName())
+			field = packet.Field{Name: ct.Name(), Type: tdtpType, Length: length}
+		}
+		schema.Fields[i] = field
+	}
+	return schema, nil
+}
+
 // ReadAllRows читает все строки из таблицы
 // Реализует base.DataReader интерфейс
 func (a *Adapter) ReadAllRows(ctx context.Context, tableName string, schema packet.Schema) ([][]string, error) {
+	rows, err := a.db.QueryContext(ctx, selectAllSQL(tableName, schema))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query table: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	return a.scanRows(rows, schema)
+}
+
+// StreamAllRows читает таблицу построчно, не накапливая её в памяти
+// Реализует base.RowStreamer интерфейс
+func (a *Adapter) StreamAllRows(ctx context.Context, tableName string, schema packet.Schema, fn func(row []string) error) error {
+	rows, err := a.db.QueryContext(ctx, selectAllSQL(tableName, schema))
+	if err != nil {
+		return fmt.Errorf("failed to query table: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	return base.StreamSQLRows(rows, schema, a.converter, "sqlite", fn)
+}
+
+// selectAllSQL формирует SELECT всех полей схемы из таблицы
+func selectAllSQL(tableName string, schema packet.Schema) string {
 	tableName = tdtql.StripBrackets(tableName)
 	// Формируем список полей для SELECT — квотируем каждое имя на случай пробелов
 	fieldNames := make([]string, len(schema.Fields))
@@ -105,17 +199,9 @@ func (a *Adapter) ReadAllRows(ctx context.Context, tableName string, schema pack
 	}
 
 	quotedTable := fmt.Sprintf("\"%s\"", tableName) //nolint:gocritic // SQL identifier quoting, not Go string quoting
-	query := fmt.Sprintf("SELECT %s FROM %s",
+	return fmt.Sprintf("SELECT %s FROM %s",
 		strings.Join(fieldNames, ", "),
 		quotedTable)
-
-	rows, err := a.db.QueryContext(ctx, query)
-	if err != nil {
-		return nil, fmt.Errorf("failed to query table: %w", err)
-	}
-	defer func() { _ = rows.Close() }()
-
-	return a.scanRows(rows, schema)
 }
 
 // ReadRowsWithSQL читает строки используя произвольный SQL запрос
@@ -24,11 +24,62 @@ func (a *Adapter) ImportPackets(ctx context.Context, packets []*packet.DataPacke
 	return a.importHelper.ImportPackets(ctx, packets, strategy)
 }
 
+// ImportPacketStream импортирует пакеты из канала пачками транзакций
+// (adapters.PacketStreamImporter) - просто делегируем
+func (a *Adapter) ImportPacketStream(ctx context.Context, packets <-chan *packet.DataPacket, strategy adapters.ImportStrategy) error {
+	return a.importHelper.ImportPacketStream(ctx, packets, strategy)
+}
+
+// DryRunImport реализует adapters.DryRunImporter: проверка пакетов против
+// БД без записи (base.DryRun).
+func (a *Adapter) DryRunImport(ctx context.Context, packets []*packet.DataPacket, strategy adapters.ImportStrategy) (*adapters.ImportReport, error) {
+	return a.importHelper.DryRun(a, a.converter, "sqlite").Import(ctx, packets, strategy)
+}
+
+// ImportPacketsReport реализует adapters.ReportingImporter: ImportPackets
+// с отчётом об отклонённых строках (Config.RowErrors).
+func (a *Adapter) ImportPacketsReport(ctx context.Context, packets []*packet.DataPacket, strategy adapters.ImportStrategy) (*adapters.ImportReport, error) {
+	return a.importHelper.ImportPacketsReport(ctx, packets, strategy)
+}
+
+// ApplyDelta применяет delta-пакеты точечными UPDATE в одной транзакции.
+// Реализует base.DeltaApplier
+func (a *Adapter) ApplyDelta(ctx context.Context, packets []*packet.DataPacket, strategy adapters.ImportStrategy) error {
+	dialect := a.deltaDialect()
+	return base.ApplyDeltaPacketsSQL(ctx, a.db, packets, strategy, dialect.Quote, dialect)
+}
+
+// ApplyDelete удаляет строки по ключам пакетов удаления в одной транзакции.
+// Реализует base.DeleteApplier
+func (a *Adapter) ApplyDelete(ctx context.Context, packets []*packet.DataPacket) (int, error) {
+	dialect := a.deltaDialect()
+	return base.ApplyDeletePacketsSQL(ctx, a.db, packets, dialect.Quote, dialect)
+}
+
+// deltaDialect — SQL-диалект SQLite для delta- и delete-пакетов.
+func (a *Adapter) deltaDialect() base.DeltaDialect {
+	return base.DeltaDialect{
+		Quote:       func(name string) string { return `"` + strings.ReplaceAll(name, `"`, `""`) + `"` },
+		Placeholder: func(int) string { return "?" },
+		Convert:     base.SQLValueConverter(a.converter, "sqlite"),
+	}
+}
+
 // ========== Реализация интерфейсов для ImportHelper ==========
 
 // CreateTable создает таблицу по TDTP схеме
 // Реализует base.TableManager интерфейс
 func (a *Adapter) CreateTable(ctx context.Context, tableName string, schema packet.Schema) error {
+	_, err := a.db.ExecContext(ctx, a.GenerateDDL(tableName, schema)[0])
+	if err != nil {
+		return fmt.Errorf("failed to create table: %w", err)
+	}
+
+	return nil
+}
+
+// GenerateDDL возвращает CREATE TABLE для TDTP схемы (adapters.DDLGenerator)
+func (a *Adapter) GenerateDDL(tableName string, schema packet.Schema) []string {
 	columns := make([]string, 0, len(schema.Fields))
 	var pkColumns []string
 
@@ -56,12 +107,7 @@ func (a *Adapter) CreateTable(ctx context.Context, tableName string, schema pack
 		quotedTable,
 		strings.Join(columns, ",\n  "))
 
-	_, err := a.db.ExecContext(ctx, query)
-	if err != nil {
-		return fmt.Errorf("failed to create table: %w", err)
-	}
-
-	return nil
+	return []string{query}
 }
 
 // DropTable удаляет таблицу
@@ -73,6 +119,14 @@ func (a *Adapter) DropTable(ctx context.Context, tableName string) error {
 	return err
 }
 
+// UpdateStatistics собирает статистику таблицы для планировщика (ANALYZE).
+// Реализует adapters.StatisticsUpdater.
+func (a *Adapter) UpdateStatistics(ctx context.Context, tableName string) error {
+	quotedTable := `"` + strings.ReplaceAll(tableName, `"`, `""`) + `"`
+	_, err := a.db.ExecContext(ctx, "ANALYZE "+quotedTable)
+	return err
+}
+
 // RenameTable переименовывает таблицу
 // Реализует base.TableManager интерфейс
 func (a *Adapter) RenameTable(ctx context.Context, oldName, newName string) error {
@@ -83,6 +137,38 @@ func (a *Adapter) RenameTable(ctx context.Context, oldName, newName string) erro
 	return err
 }
 
+// AddColumns добавляет колонки в существующую таблицу.
+// Реализует adapters.ColumnAdder
+func (a *Adapter) AddColumns(ctx context.Context, tableName string, fields []packet.Field) error {
+	quotedTable := fmt.Sprintf("\"%s\"", tableName) //nolint:gocritic // SQL identifier quoting, not Go string quoting
+	for _, field := range fields {
+		// SQLite: одна колонка на ALTER TABLE
+		query := fmt.Sprintf("ALTER TABLE %s ADD COLUMN \"%s\" %s", quotedTable, field.Name, TDTPToSQLite(field)) //nolint:gocritic // SQL identifier quoting
+		if _, err := a.db.ExecContext(ctx, query); err != nil {
+			return fmt.Errorf("failed to add column %s: %w", field.Name, err)
+		}
+	}
+	return nil
+}
+
+// TruncateTable удаляет все строки таблицы в транзакции импорта
+// (base.ImportTx). SQLite не знает TRUNCATE — DELETE без WHERE
+// (truncate optimization SQLite).
+// Реализует base.TableTruncater интерфейс
+func (a *Adapter) TruncateTable(ctx context.Context, tableName string) error {
+	quotedTable := `"` + strings.ReplaceAll(tableName, `"`, `""`) + `"`
+	_, err := a.importConn(ctx).ExecContext(ctx, "DELETE FROM "+quotedTable)
+	return err
+}
+
+// importConn — транзакция импорта из base.ImportTx или, без неё, a.db.
+func (a *Adapter) importConn(ctx context.Context) base.SQLConn {
+	if t, ok := base.ImportTx(ctx).(*sqliteTx); ok {
+		return t.tx
+	}
+	return a.db
+}
+
 // InsertRows вставляет строки данных с использованием стратегии
 // Реализует base.DataInserter интерфейс
 // Оптимизировано: использует батчинг для INSERT (500 строк за раз)
@@ -91,14 +177,21 @@ func (a *Adapter) InsertRows(ctx context.Context, tableName string, pkgSchema pa
 		return nil
 	}
 
-	// Формируем INSERT команду
-	var insertCmd string
+	// Формируем INSERT команду (и для merge — ON CONFLICT после VALUES)
+	var insertCmd, onConflict string
 	switch strategy {
+	case adapters.StrategyMerge:
+		insertCmd = "INSERT"
+		clause, err := buildOnConflictClause(ctx, pkgSchema)
+		if err != nil {
+			return err
+		}
+		onConflict = clause
 	case adapters.StrategyReplace:
 		insertCmd = "INSERT OR REPLACE"
 	case adapters.StrategyIgnore:
 		insertCmd = "INSERT OR IGNORE"
-	case adapters.StrategyFail:
+	case adapters.StrategyFail, adapters.StrategyAppend, adapters.StrategyTruncate:
 		insertCmd = "INSERT"
 	case adapters.StrategyCopy:
 		// SQLite не поддерживает COPY, используем REPLACE
@@ -132,10 +225,11 @@ func (a *Adapter) InsertRows(ctx context.Context, tableName string, pkgSchema pa
 	// Строим запросы для полного батча и неполного последнего батча.
 	fullBatchValues := strings.Repeat(rowPH+", ", batchSize-1) + rowPH
 	quotedTable := fmt.Sprintf("\"%s\"", strings.ReplaceAll(tableName, `"`, `""`)) //nolint:gocritic // SQL identifier quoting
-	fullBatchQuery := fmt.Sprintf("%s INTO %s (%s) VALUES %s", insertCmd, quotedTable, columnList, fullBatchValues)
+	fullBatchQuery := fmt.Sprintf("%s INTO %s (%s) VALUES %s%s", insertCmd, quotedTable, columnList, fullBatchValues, onConflict)
 
 	// Prepare полного батча один раз — SQLite не будет парсить запрос повторно.
-	fullStmt, err := a.db.PrepareContext(ctx, fullBatchQuery)
+	conn := a.importConn(ctx)
+	fullStmt, err := conn.PrepareContext(ctx, fullBatchQuery)
 	if err != nil {
 		return fmt.Errorf("failed to prepare batch insert: %w", err)
 	}
@@ -169,8 +263,8 @@ func (a *Adapter) InsertRows(ctx context.Context, tableName string, pkgSchema pa
 		} else {
 			// Последний неполный батч — строим и выполняем отдельно.
 			partValues := strings.Repeat(rowPH+", ", len(batch)-1) + rowPH
-			partQuery := fmt.Sprintf("%s INTO %s (%s) VALUES %s", insertCmd, quotedTable, columnList, partValues)
-			if _, err := a.db.ExecContext(ctx, partQuery, args[:len(batch)*numFields]...); err != nil {
+			partQuery := fmt.Sprintf("%s INTO %s (%s) VALUES %s%s", insertCmd, quotedTable, columnList, partValues, onConflict)
+			if _, err := conn.ExecContext(ctx, partQuery, args[:len(batch)*numFields]...); err != nil {
 				return fmt.Errorf("failed to insert last batch at row %d: %w", i, err)
 			}
 		}
@@ -178,3 +272,31 @@ func (a *Adapter) InsertRows(ctx context.Context, tableName string, pkgSchema pa
 
 	return nil
 }
+
+// buildOnConflictClause возвращает " ON CONFLICT (ключ) DO UPDATE SET c = excluded.c, ..."
+// для StrategyMerge: обновляются только колонки base.UpdateFields. Без
+// ключевых полей — обычная вставка.
+func buildOnConflictClause(ctx context.Context, pkgSchema packet.Schema) (string, error) {
+	updates, err := base.UpdateFields(ctx, pkgSchema, adapters.StrategyMerge)
+	if err != nil {
+		return "", err
+	}
+	var keys []string
+	for _, field := range pkgSchema.Fields {
+		if field.Key {
+			keys = append(keys, fmt.Sprintf("\"%s\"", field.Name)) //nolint:gocritic // SQL identifier quoting
+		}
+	}
+	if len(keys) == 0 {
+		return "", nil
+	}
+	clause := " ON CONFLICT (" + strings.Join(keys, ", ") + ")"
+	if len(updates) == 0 {
+		return clause + " DO NOTHING", nil
+	}
+	sets := make([]string, len(updates))
+	for i, name := range updates {
+		sets[i] = fmt.Sprintf("\"%s\" = excluded.\"%s\"", name, name) //nolint:gocritic // SQL identifier quoting
+	}
+	return clause + " DO UPDATE SET " + strings.Join(sets, ", "), nil
+}
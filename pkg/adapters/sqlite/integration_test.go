@@ -3,13 +3,21 @@ package sqlite
 import (
 	"context"
 	"database/sql"
+	"encoding/base64"
+	"errors"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+	"github.com/ruslano69/tdtp-framework/pkg/adapters/base"
 	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
 	"github.com/ruslano69/tdtp-framework/pkg/core/schema"
 	"github.com/ruslano69/tdtp-framework/pkg/core/tdtql"
+	tdtpcrypto "github.com/ruslano69/tdtp-framework/pkg/crypto"
+	"github.com/ruslano69/tdtp-framework/pkg/security"
 )
 
 // TestIntegration_ExportTableWithQuery тестирует полный цикл с TDTQL
@@ -478,3 +486,314 @@ func TestIntegration_FieldsProjection_FullCycle(t *testing.T) {
 		t.Errorf("Expected 2 fields in target schema, got %d", len(targetSchema.Fields))
 	}
 }
+
+// TestIntegration_CollationPushdownParity — ci через SQLite даёт те же строки,
+// что и фильтрация в памяти (LOWER SQLite не знает кириллицы).
+func TestIntegration_CollationPushdownParity(t *testing.T) {
+	ctx := context.Background()
+	adapter, err := NewAdapter(filepath.Join(t.TempDir(), "collation.db"))
+	if err != nil {
+		t.Fatalf("Failed to create adapter: %v", err)
+	}
+	defer adapter.Close(ctx)
+
+	schemaObj := schema.NewBuilder().AddInteger("ID", true).AddText("City", 50).Build()
+	rows := [][]string{{"1", "Москва"}, {"2", "МОСКВА"}, {"3", "Moscow"}, {"4", "MOSCOW"}, {"5", "Казань"}}
+	pkt := packet.NewDataPacket(packet.TypeReference, "Cities")
+	pkt.Schema = schemaObj
+	for _, r := range rows {
+		pkt.Data.Rows = append(pkt.Data.Rows, packet.Row{Value: strings.Join(r, "|")})
+	}
+	if err := adapter.ImportPacket(ctx, pkt, adapters.StrategyReplace); err != nil {
+		t.Fatalf("ImportPacket: %v", err)
+	}
+
+	for _, tc := range []struct {
+		sql      string
+		pushdown bool
+	}{
+		{"SELECT * FROM Cities WHERE City = 'Москва' COLLATE ci", false},
+		{"SELECT * FROM Cities WHERE City LIKE 'моск%' COLLATE ci", false},
+		{"SELECT * FROM Cities WHERE City = 'moscow' COLLATE ci", true},
+	} {
+		query, err := tdtql.NewTranslator().Translate(tc.sql)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.sql, err)
+		}
+		memory, err := tdtql.NewExecutor().Execute(query, rows, schemaObj)
+		if err != nil {
+			t.Fatalf("%s: in-memory: %v", tc.sql, err)
+		}
+		var want []string
+		for _, r := range memory.FilteredRows {
+			want = append(want, r[0])
+		}
+
+		packets, err := adapter.ExportTableWithQuery(ctx, "Cities", query, "App", "Receiver")
+		if err != nil {
+			t.Fatalf("%s: export: %v", tc.sql, err)
+		}
+		var got []string
+		for _, p := range packets {
+			for _, row := range p.Data.Rows {
+				got = append(got, strings.SplitN(row.Value, "|", 2)[0])
+			}
+		}
+		if strings.Join(got, ",") != strings.Join(want, ",") || len(want) != 2 {
+			t.Errorf("%s: SQLite returned %v, in-memory %v", tc.sql, got, want)
+		}
+		plan := packets[0].QueryContext.ExecutionPlan
+		if pushed := plan.Strategy == packet.PlanPushdown; pushed != tc.pushdown {
+			t.Errorf("%s: strategy %s, want pushdown=%v", tc.sql, plan.Strategy, tc.pushdown)
+		}
+	}
+}
+
+// Числовое сравнение по TEXT-колонке с нечисловыми значениями: SQLite
+// приводит 'abc' к 0, поэтому автоприведённое условие фильтруется в памяти.
+func TestIntegration_ImplicitCastParity(t *testing.T) {
+	ctx := context.Background()
+	adapter, err := NewAdapter(filepath.Join(t.TempDir(), "cast.db"))
+	if err != nil {
+		t.Fatalf("Failed to create adapter: %v", err)
+	}
+	defer adapter.Close(ctx)
+
+	schemaObj := schema.NewBuilder().AddInteger("ID", true).AddText("Balance", 20).Build()
+	rows := [][]string{{"1", "1500"}, {"2", "abc"}, {"3", "-5"}, {"4", "900.5"}, {"5", ""}}
+	pkt := packet.NewDataPacket(packet.TypeReference, "Accounts")
+	pkt.Schema = schemaObj
+	for _, r := range rows {
+		pkt.Data.Rows = append(pkt.Data.Rows, packet.Row{Value: strings.Join(r, "|")})
+	}
+	if err := adapter.ImportPacket(ctx, pkt, adapters.StrategyReplace); err != nil {
+		t.Fatalf("ImportPacket: %v", err)
+	}
+
+	for _, sql := range []string{
+		"SELECT * FROM Accounts WHERE Balance > -1",
+		"SELECT * FROM Accounts WHERE Balance BETWEEN -10 AND 1000",
+	} {
+		query, err := tdtql.NewTranslator().Translate(sql)
+		if err != nil {
+			t.Fatalf("%s: %v", sql, err)
+		}
+		memory, err := tdtql.NewExecutor().Execute(query, rows, schemaObj)
+		if err != nil {
+			t.Fatalf("%s: in-memory: %v", sql, err)
+		}
+		var want []string
+		for _, r := range memory.FilteredRows {
+			want = append(want, r[0])
+		}
+
+		packets, err := adapter.ExportTableWithQuery(ctx, "Accounts", query, "App", "Receiver")
+		if err != nil {
+			t.Fatalf("%s: export: %v", sql, err)
+		}
+		var got []string
+		for _, p := range packets {
+			for _, row := range p.Data.Rows {
+				got = append(got, strings.SplitN(row.Value, "|", 2)[0])
+			}
+		}
+		if strings.Join(got, ",") != strings.Join(want, ",") || slices.Contains(got, "2") {
+			t.Errorf("%s: SQLite returned %v, in-memory %v", sql, got, want)
+		}
+		if plan := packets[0].QueryContext.ExecutionPlan; plan.Strategy == packet.PlanPushdown {
+			t.Errorf("%s: implicit cast pushed down to SQL", sql)
+		}
+		for _, f := range query.Filters.And.Filters {
+			if f.Cast != "" {
+				t.Errorf("%s: caller's query mutated: Cast=%q", sql, f.Cast)
+			}
+		}
+	}
+}
+
+// Колонки происхождения — в общем пути импорта и экспорта, без CLI:
+// ImportWithOptions добавляет их в существующую таблицу, экспорт исключает,
+// пробный импорт проверяет пакет с ними.
+func TestIntegration_ProvenanceRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	adapter, err := NewAdapter(filepath.Join(t.TempDir(), "provenance.db"))
+	if err != nil {
+		t.Fatalf("Failed to create adapter: %v", err)
+	}
+	defer adapter.Close(ctx)
+
+	schemaObj := schema.NewBuilder().AddInteger("ID", true).AddText("Name", 50).Build()
+	newPacket := func(rows ...string) *packet.DataPacket {
+		pkt := packet.NewDataPacket(packet.TypeReference, "Clients")
+		pkt.Header.Sender = "crm"
+		pkt.Schema = schemaObj
+		for _, r := range rows {
+			pkt.Data.Rows = append(pkt.Data.Rows, packet.Row{Value: r})
+		}
+		return pkt
+	}
+	if err := adapter.ImportPacket(ctx, newPacket("1|Ann"), adapters.StrategyReplace); err != nil {
+		t.Fatalf("ImportPacket: %v", err)
+	}
+
+	opts := adapters.ImportOptions{Strategy: adapters.StrategyReplace, Provenance: true, DryRun: true}
+	report, err := adapters.ImportWithOptions(ctx, adapter, []*packet.DataPacket{newPacket("2|Bob")}, opts)
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if err := report.Err(); err != nil || !strings.Contains(strings.Join(report.Tables[0].Warnings, "\n"), adapters.ProvenanceSource) {
+		t.Errorf("dry run: err %v, warnings %v", err, report.Tables[0].Warnings)
+	}
+
+	opts.DryRun = false
+	if _, err := adapters.ImportWithOptions(ctx, adapter, []*packet.DataPacket{newPacket("2|Bob")}, opts); err != nil {
+		t.Fatalf("ImportWithOptions: %v", err)
+	}
+	tableSchema, err := adapter.GetTableSchema(ctx, "Clients")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tableSchema.Fields) != 6 {
+		t.Fatalf("table columns %v, want provenance columns added", tableSchema.Fields)
+	}
+
+	packets, err := adapter.ExportTable(ctx, "Clients")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(packets[0].Schema.Fields); got != 2 {
+		t.Errorf("export kept provenance columns: %v", packets[0].Schema.Fields)
+	}
+	packets, err = adapter.ExportTable(adapters.WithKeepProvenance(ctx), "Clients")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows := packets[0].GetRows()
+	if len(packets[0].Schema.Fields) != 6 || rows[1][2] != "crm" {
+		t.Errorf("WithKeepProvenance: fields %v, rows %v", packets[0].Schema.Fields, rows)
+	}
+}
+
+// TestIntegration_ColumnEncryption — шифрование колонок задаётся конфигурацией
+// адаптера и действует в общем пути импорта и экспорта, без CLI.
+func TestIntegration_ColumnEncryption(t *testing.T) {
+	ctx := context.Background()
+	dbFile := filepath.Join(t.TempDir(), "column_encryption.db")
+	keys := tdtpcrypto.StaticKeys{"pii": make([]byte, 32)}
+	connect := func(cipher adapters.ColumnCipher) *Adapter {
+		t.Helper()
+		a := &Adapter{}
+		if err := a.Connect(ctx, adapters.Config{Type: "sqlite", DSN: dbFile, ColumnCipher: cipher}); err != nil {
+			t.Fatalf("Connect: %v", err)
+		}
+		t.Cleanup(func() { _ = a.Close(ctx) })
+		return a
+	}
+	adapter := connect(base.NewColumnCipher(map[string]string{"people.ssn": "pii"}, keys))
+
+	// Текст в формате шифртекста, тег которого не сходится, — открытое значение
+	forged := "enc:v1:pii:" + base64.StdEncoding.EncodeToString(make([]byte, 40))
+	pkt := packet.NewDataPacket(packet.TypeReference, "People")
+	pkt.Schema = schema.NewBuilder().AddInteger("ID", true).AddText("SSN", 20).Build()
+	pkt.Data.Rows = []packet.Row{{Value: "1|123-45-6789"}, {Value: "2|" + forged}}
+	if err := adapter.ImportPackets(ctx, []*packet.DataPacket{pkt}, adapters.StrategyReplace); err != nil {
+		t.Fatalf("ImportPackets: %v", err)
+	}
+
+	raw, err := adapter.ExecuteRawQuery(ctx, "SELECT SSN FROM People ORDER BY ID")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, row := range raw.GetRows() {
+		if !tdtpcrypto.IsEncryptedValue(row[0]) || row[0] == forged {
+			t.Errorf("stored value %q is not encrypted", row[0])
+		}
+	}
+
+	packets, err := adapter.ExportTable(ctx, "People")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows := packets[0].GetRows()
+	if rows[0][1] != "123-45-6789" || rows[1][1] != forged {
+		t.Errorf("exported rows = %v", rows)
+	}
+
+	// Потребитель без ключа получает шифртекст и пометку в схеме
+	locked := connect(base.NewColumnCipher(map[string]string{"people.ssn": "pii"}, tdtpcrypto.StaticKeys{}))
+	packets, err = locked.ExportTableWithQuery(ctx, "People", packet.NewQuery(), "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f := packets[0].Schema.Fields[1]; f.Encryption == nil || f.Encryption.KeyID != "pii" {
+		t.Errorf("locked field = %+v", f)
+	}
+	if v := packets[0].GetRows()[0][1]; !tdtpcrypto.IsEncryptedValue(v) {
+		t.Errorf("locked value = %q", v)
+	}
+}
+
+// TestIntegration_ExportPolicy — политика выдачи колонок применяется самим
+// адаптером (Config.ExportPolicy), без участия CLI.
+func TestIntegration_ExportPolicy(t *testing.T) {
+	ctx := context.Background()
+	adapter := &Adapter{}
+	policy := &security.ExportPolicy{Tables: map[string]security.TableExportPolicy{
+		"customers": {Columns: map[string]security.ColumnExportPolicy{
+			"email": {Allow: []string{"crm"}},
+			"phone": {Allow: []string{"crm"}, Action: security.PolicyMask},
+		}},
+	}}
+	dbFile := filepath.Join(t.TempDir(), "export_policy.db")
+	if err := adapter.Connect(ctx, adapters.Config{Type: "sqlite", DSN: dbFile, ExportPolicy: policy}); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer adapter.Close(ctx)
+
+	pkt := packet.NewDataPacket(packet.TypeReference, "Customers")
+	pkt.Schema = schema.NewBuilder().AddInteger("ID", true).AddText("Email", 100).AddText("Phone", 20).Build()
+	pkt.Data.Rows = []packet.Row{{Value: "1|a@example.com|555-0100"}}
+	if err := adapter.ImportPackets(ctx, []*packet.DataPacket{pkt}, adapters.StrategyReplace); err != nil {
+		t.Fatalf("ImportPackets: %v", err)
+	}
+
+	// Явный запрос запрещённой колонки отклоняется до обращения к БД
+	query := packet.NewQuery()
+	query.Fields = []string{"ID", "Email"}
+	if _, err := adapter.ExportTableWithQuery(ctx, "Customers", query, "", "billing"); !errors.Is(err, security.ErrPolicyViolation) {
+		t.Fatalf("ExportTableWithQuery(billing) err = %v, want ErrPolicyViolation", err)
+	}
+	if _, err := adapter.ExportTableWithQuery(ctx, "Customers", query, "", "crm"); err != nil {
+		t.Fatalf("ExportTableWithQuery(crm): %v", err)
+	}
+
+	// Получатель из контекста: crm видит всё
+	packets, err := adapter.ExportTable(adapters.WithRecipient(ctx, "crm"), "Customers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := packets[0].Header.Recipient; got != "crm" {
+		t.Errorf("Header.Recipient = %q, want crm", got)
+	}
+	if rows := packets[0].GetRows(); len(packets[0].Schema.Fields) != 3 || rows[0][1] != "a@example.com" || rows[0][2] != "555-0100" {
+		t.Errorf("crm packet = %v %v", packets[0].Schema.Fields, rows)
+	}
+
+	// Без получателя email удаляется, phone маскируется
+	packets, err = adapter.ExportTable(ctx, "Customers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := packets[0].Schema.Fields
+	if len(fields) != 2 || fields[1].Name != "Phone" {
+		t.Fatalf("fields = %+v, want ID, Phone", fields)
+	}
+	if v := packets[0].GetRows()[0][1]; v != security.PolicyMaskValue {
+		t.Errorf("phone = %q, want masked", v)
+	}
+
+	// Сырой SQL обходил бы политику — отклоняется
+	if _, err := adapter.ExportQuery(ctx, "SELECT Email FROM Customers"); !errors.Is(err, security.ErrPolicyViolation) {
+		t.Errorf("ExportQuery err = %v, want ErrPolicyViolation", err)
+	}
+}
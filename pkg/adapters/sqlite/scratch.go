@@ -0,0 +1,38 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+)
+
+// Compile-time check: симуляция импорта в копии файла БД
+var _ adapters.ScratchCreator = (*Adapter)(nil)
+
+// CreateScratch реализует adapters.ScratchCreator: копия — файл
+// <tmp>/name.db, снятый VACUUM INTO (вся БД, согласованный снимок).
+// Зашифрованную БД (SQLCipher) VACUUM INTO скопировал бы без ключа,
+// поэтому для неё симуляция не поддерживается.
+func (a *Adapter) CreateScratch(ctx context.Context, name string, tables []string) (*adapters.Scratch, error) {
+	if a.cipherDSN != "" {
+		return nil, fmt.Errorf("import simulation is not supported for encrypted databases")
+	}
+	path := filepath.Join(os.TempDir(), name+".db")
+	if _, err := a.db.ExecContext(ctx, "VACUUM INTO '"+strings.ReplaceAll(path, "'", "''")+"'"); err != nil {
+		return nil, fmt.Errorf("failed to copy database to %s: %w", path, err)
+	}
+	return &adapters.Scratch{Location: path, DSN: path}, nil
+}
+
+// DropScratch реализует adapters.ScratchCreator: удаляет файл копии
+// (и журналы WAL, если они остались).
+func (a *Adapter) DropScratch(ctx context.Context, scratch *adapters.Scratch) error {
+	for _, suffix := range []string{"-wal", "-shm"} {
+		_ = os.Remove(scratch.DSN + suffix)
+	}
+	return os.Remove(scratch.DSN)
+}
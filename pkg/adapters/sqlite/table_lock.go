@@ -0,0 +1,79 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"time"
+)
+
+// Блокировка таблиц на время импорта (adapters.TableLocking): у SQLite нет
+// именованных блокировок, поэтому импорт создаёт lock-файл рядом с БД
+// (O_EXCL — как seq.lock файловой очереди). Пока импорт идёт, файл
+// обновляется; файл, не обновлявшийся tableLockStale, брошен упавшим
+// процессом и удаляется.
+const (
+	tableLockStale     = 2 * time.Minute
+	tableLockHeartbeat = 30 * time.Second
+)
+
+// TryLockTable реализует base.TableLocker.
+func (a *Adapter) TryLockTable(_ context.Context, table string) (func() error, bool, error) {
+	if a.lockBase == "" {
+		return func() error { return nil }, true, nil // in-memory БД не разделяется между процессами
+	}
+	path := fmt.Sprintf("%s.%s.tdtp-lock", a.lockBase, table)
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if errors.Is(err, fs.ErrExist) {
+		info, serr := os.Stat(path)
+		if serr != nil || time.Since(info.ModTime()) < tableLockStale {
+			return nil, false, nil
+		}
+		_ = os.Remove(path)
+		f, err = os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if errors.Is(err, fs.ErrExist) {
+			return nil, false, nil // брошенный файл успел занять другой импорт
+		}
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	host, _ := os.Hostname()
+	_, _ = fmt.Fprintf(f, "%s %d\n", host, os.Getpid())
+	_ = f.Close()
+
+	stop := make(chan struct{})
+	go func() {
+		t := time.NewTicker(tableLockHeartbeat)
+		defer t.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case now := <-t.C:
+				_ = os.Chtimes(path, now, now)
+			}
+		}
+	}()
+	return func() error {
+		close(stop)
+		return os.Remove(path)
+	}, true, nil
+}
+
+// lockBasePath — путь файла БД из DSN ("file:app.db?mode=rwc" → app.db);
+// "" для in-memory БД.
+func lockBasePath(dsn string) string {
+	path := strings.TrimPrefix(dsn, "file:")
+	if i := strings.IndexByte(path, '?'); i >= 0 {
+		path = path[:i]
+	}
+	if path == "" || path == ":memory:" || strings.Contains(dsn, "mode=memory") {
+		return ""
+	}
+	return path
+}
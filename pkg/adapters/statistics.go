@@ -0,0 +1,64 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+)
+
+// ========== Статистика планировщика ==========
+
+// StatisticsUpdater — адаптер, обновляющий статистику планировщика таблицы
+// (PostgreSQL ANALYZE, MS SQL UPDATE STATISTICS, MySQL ANALYZE TABLE,
+// Oracle DBMS_STATS, SQLite ANALYZE). После большой загрузки статистика
+// приёмника устаревает, и до автоматического пересчёта планы запросов к
+// новым данным строятся по старым оценкам.
+type StatisticsUpdater interface {
+	UpdateStatistics(ctx context.Context, tableName string) error
+}
+
+// StatisticsReader — адаптер, отдающий оценки статистики таблицы из
+// каталога СУБД без сканирования данных (для мониторинга: манифест
+// выгрузки, сравнение источника и приёмника).
+type StatisticsReader interface {
+	TableStatistics(ctx context.Context, tableName string) (*TableStatistics, error)
+}
+
+// TableStatistics — оценки статистики таблицы из каталога СУБД.
+type TableStatistics struct {
+	// RowCount — приблизительное число строк (pg_class.reltuples,
+	// sys.partitions); -1 — статистика ещё не собиралась
+	RowCount int64 `json:"row_count"`
+
+	// Columns — статистика колонок, если СУБД её хранит (PostgreSQL pg_stats)
+	Columns []ColumnStatistics `json:"columns,omitempty"`
+}
+
+// ColumnStatistics — статистика колонки.
+type ColumnStatistics struct {
+	Name string `json:"name"`
+
+	// NullFraction — доля NULL (0..1)
+	NullFraction float64 `json:"null_fraction"`
+
+	// Distinct — оценка числа различных значений; отрицательное значение —
+	// доля от числа строк со знаком минус (как n_distinct PostgreSQL)
+	Distinct float64 `json:"distinct,omitempty"`
+
+	// Histogram — границы корзин гистограммы равной высоты (текстом)
+	Histogram []string `json:"histogram,omitempty"`
+}
+
+// UpdateStatistics обновляет статистику таблиц, если адаптер реализует
+// StatisticsUpdater; иначе возвращает false без ошибки.
+func UpdateStatistics(ctx context.Context, a Adapter, tables ...string) (bool, error) {
+	updater, ok := a.(StatisticsUpdater)
+	if !ok {
+		return false, nil
+	}
+	for _, table := range tables {
+		if err := updater.UpdateStatistics(ctx, table); err != nil {
+			return true, fmt.Errorf("failed to update statistics of %s: %w", table, err)
+		}
+	}
+	return true, nil
+}
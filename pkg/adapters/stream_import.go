@@ -0,0 +1,137 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+// ========== Потоковый импорт ==========
+
+// DefaultStreamBatchPackets — сколько пакетов ImportPacketStream фиксирует
+// одной транзакцией, если Config.StreamBatchPackets не задан (~15 MB XML
+// при размере части по умолчанию).
+const DefaultStreamBatchPackets = 8
+
+// PacketStreamImporter — адаптер импортирует пакеты по мере поступления из
+// канала, не дожидаясь всех частей: пакеты фиксируются пачками по
+// Config.StreamBatchPackets, каждая пачка — отдельная транзакция (и одна
+// транзакция для Governor). В памяти только текущая пачка, поэтому
+// multi-GB перенос не упирается в RAM.
+//
+// Импорт заканчивается, когда канал закрыт. При ошибке или отмене ctx
+// канал не дочитывается: отправитель должен прекращать отправку по ctx.
+// Уже зафиксированные пачки не откатываются.
+type PacketStreamImporter interface {
+	ImportPacketStream(ctx context.Context, packets <-chan *packet.DataPacket, strategy ImportStrategy) error
+}
+
+// ImportPacketStream импортирует поток пакетов адаптером: через
+// PacketStreamImporter, если адаптер его реализует, иначе пачками по
+// DefaultStreamBatchPackets через ImportPackets.
+func ImportPacketStream(ctx context.Context, adapter Adapter, packets <-chan *packet.DataPacket, strategy ImportStrategy) error {
+	if importer, ok := adapter.(PacketStreamImporter); ok {
+		return importer.ImportPacketStream(ctx, packets, strategy)
+	}
+	return ImportPacketBatches(ctx, packets, strategy, DefaultStreamBatchPackets, adapter.ImportPackets)
+}
+
+// ImportPacketBatches — общая реализация ImportPacketStream поверх
+// ImportPackets адаптера (одна пачка — одна транзакция).
+//
+// StrategyCopy заменяет таблицу только первой пачкой таблицы, следующие
+// пачки дописываются обычной вставкой (StrategyFail): замена всей таблицы
+// на каждой пачке оставила бы только последнюю. Адаптеры с base.ImportHelper
+// вместо этого грузят весь поток во временную таблицу и заменяют
+// целевую атомарно в конце. StrategyTruncate так же очищает таблицу только
+// первой пачкой, следующие дописываются StrategyAppend; атомарна каждая
+// пачка, а не весь поток.
+func ImportPacketBatches(
+	ctx context.Context,
+	packets <-chan *packet.DataPacket,
+	strategy ImportStrategy,
+	batchPackets int,
+	importPackets func(ctx context.Context, packets []*packet.DataPacket, strategy ImportStrategy) error,
+) error {
+	return ReadPacketBatches(ctx, packets, batchPackets, func(batch []*packet.DataPacket, first bool) error {
+		s := strategy
+		if !first && !batch[0].Data.Delta && !batch[0].Data.Delete {
+			switch s {
+			case StrategyCopy:
+				s = StrategyFail
+			case StrategyTruncate:
+				s = StrategyAppend
+			}
+		}
+		return importPackets(ctx, batch, s)
+	})
+}
+
+// ReadPacketBatches читает пакеты из канала и передаёт их в fn пачками не
+// больше batchPackets (<= 0 — DefaultStreamBatchPackets). Пачка
+// закрывается раньше, если меняется таблица, схема или вид пакетов
+// (данные / delta / удаление): ImportPackets ждёт однородную пачку.
+// first — первая пачка данных своей таблицы в потоке. nil-пакеты пропускаются.
+func ReadPacketBatches(
+	ctx context.Context,
+	packets <-chan *packet.DataPacket,
+	batchPackets int,
+	fn func(batch []*packet.DataPacket, first bool) error,
+) error {
+	if batchPackets <= 0 {
+		batchPackets = DefaultStreamBatchPackets
+	}
+
+	var batch []*packet.DataPacket
+	started := make(map[string]bool) // таблицы, пачки данных которых уже импортированы
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		table := batch[0].Header.TableName
+		plain := !batch[0].Data.Delta && !batch[0].Data.Delete
+		first := plain && !started[table]
+		if err := fn(batch, first); err != nil {
+			return fmt.Errorf("table %s: %w", table, err)
+		}
+		if plain {
+			started[table] = true
+		}
+		batch = nil
+		return nil
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case pkt, ok := <-packets:
+			if !ok {
+				return flush()
+			}
+			if pkt == nil {
+				continue
+			}
+			if len(batch) > 0 && !sameBatch(batch[0], pkt) {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+			batch = append(batch, pkt)
+			if len(batch) >= batchPackets {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+// sameBatch — пакет можно импортировать в одной пачке с head
+func sameBatch(head, pkt *packet.DataPacket) bool {
+	return head.Header.TableName == pkt.Header.TableName &&
+		head.Data.Delta == pkt.Data.Delta &&
+		head.Data.Delete == pkt.Data.Delete &&
+		packet.SchemaEquals(head.Schema, pkt.Schema)
+}
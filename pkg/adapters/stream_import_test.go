@@ -0,0 +1,121 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+// streamAdapter — Adapter, записывающий пачки ImportPackets (остальные методы не нужны).
+type streamAdapter struct {
+	Adapter
+	batches    [][]string // имена таблиц пакетов каждой пачки
+	strategies []ImportStrategy
+	failAt     int // номер пачки (с 1), на которой ImportPackets вернёт ошибку
+}
+
+func (a *streamAdapter) ImportPackets(_ context.Context, packets []*packet.DataPacket, strategy ImportStrategy) error {
+	if len(a.batches)+1 == a.failAt {
+		return errors.New("insert failed")
+	}
+	var tables []string
+	for _, p := range packets {
+		tables = append(tables, p.Header.TableName)
+	}
+	a.batches = append(a.batches, tables)
+	a.strategies = append(a.strategies, strategy)
+	return nil
+}
+
+func streamPacket(table string) *packet.DataPacket {
+	pkt := packet.NewDataPacket(packet.TypeReference, table)
+	pkt.Schema = packet.Schema{Fields: []packet.Field{{Name: "id", Type: "INTEGER", Key: true}}}
+	return pkt
+}
+
+func sendPackets(packets ...*packet.DataPacket) <-chan *packet.DataPacket {
+	ch := make(chan *packet.DataPacket, len(packets))
+	for _, p := range packets {
+		ch <- p
+	}
+	close(ch)
+	return ch
+}
+
+func TestImportPacketBatches(t *testing.T) {
+	delta := streamPacket("orders")
+	delta.Data.Delta = true
+
+	a := &streamAdapter{}
+	ch := sendPackets(
+		streamPacket("orders"), streamPacket("orders"), streamPacket("orders"), // полная пачка + остаток
+		delta,                 // смена вида пакетов закрывает пачку
+		streamPacket("items"), // смена таблицы закрывает пачку
+		nil,
+		streamPacket("orders"),
+	)
+	if err := ImportPacketBatches(context.Background(), ch, StrategyCopy, 2, a.ImportPackets); err != nil {
+		t.Fatalf("ImportPacketBatches: %v", err)
+	}
+
+	wantBatches := [][]string{{"orders", "orders"}, {"orders"}, {"orders"}, {"items"}, {"orders"}}
+	if !slices.EqualFunc(a.batches, wantBatches, slices.Equal[[]string]) {
+		t.Errorf("batches = %v, want %v", a.batches, wantBatches)
+	}
+	// Copy заменяет таблицу только первой пачкой таблицы; delta — как есть
+	wantStrategies := []ImportStrategy{StrategyCopy, StrategyFail, StrategyCopy, StrategyCopy, StrategyFail}
+	if !slices.Equal(a.strategies, wantStrategies) {
+		t.Errorf("strategies = %v, want %v", a.strategies, wantStrategies)
+	}
+}
+
+func TestImportPacketBatches_Truncate(t *testing.T) {
+	// Truncate очищает таблицу только первой пачкой, дальше — дописывание
+	a := &streamAdapter{}
+	ch := sendPackets(streamPacket("orders"), streamPacket("orders"), streamPacket("orders"), streamPacket("items"))
+	if err := ImportPacketBatches(context.Background(), ch, StrategyTruncate, 1, a.ImportPackets); err != nil {
+		t.Fatalf("ImportPacketBatches: %v", err)
+	}
+	want := []ImportStrategy{StrategyTruncate, StrategyAppend, StrategyAppend, StrategyTruncate}
+	if !slices.Equal(a.strategies, want) {
+		t.Errorf("strategies = %v, want %v", a.strategies, want)
+	}
+}
+
+func TestImportPacketStream_Fallback(t *testing.T) {
+	// Адаптер без PacketStreamImporter — пачки по DefaultStreamBatchPackets через ImportPackets
+	a := &streamAdapter{}
+	ch := sendPackets(streamPacket("orders"), streamPacket("orders"), streamPacket("orders"))
+	if err := ImportPacketStream(context.Background(), a, ch, StrategyReplace); err != nil {
+		t.Fatalf("ImportPacketStream: %v", err)
+	}
+	if len(a.batches) != 1 || len(a.batches[0]) != 3 {
+		t.Errorf("batches = %v, want one batch of 3", a.batches)
+	}
+}
+
+func TestImportPacketBatches_StopsOnError(t *testing.T) {
+	a := &streamAdapter{failAt: 2}
+	ch := sendPackets(streamPacket("orders"), streamPacket("orders"), streamPacket("orders"))
+	err := ImportPacketBatches(context.Background(), ch, StrategyReplace, 1, a.ImportPackets)
+	if err == nil {
+		t.Fatal("expected error from second batch")
+	}
+	if len(a.batches) != 1 {
+		t.Errorf("import must stop after failed batch, got %d committed batches", len(a.batches))
+	}
+}
+
+func TestReadPacketBatches_ContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	ch := make(chan *packet.DataPacket) // никто не пишет и не закрывает
+	err := ReadPacketBatches(ctx, ch, 1, func([]*packet.DataPacket, bool) error { return nil })
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+}
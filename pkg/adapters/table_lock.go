@@ -0,0 +1,44 @@
+package adapters
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// TableLockMode — поведение импорта, когда целевую таблицу уже импортирует
+// другой процесс.
+type TableLockMode string
+
+const (
+	// TableLockOff — без блокировки (по умолчанию).
+	TableLockOff TableLockMode = ""
+	// TableLockWait — ждать освобождения таблицы (не дольше TableLocking.Timeout).
+	TableLockWait TableLockMode = "wait"
+	// TableLockFail — сразу завершать импорт ошибкой ErrTableLocked.
+	TableLockFail TableLockMode = "fail"
+)
+
+// ErrTableLocked — целевую таблицу импортирует другой процесс.
+var ErrTableLocked = errors.New("table is locked by another import")
+
+// TableLocking — кооперативная блокировка целевой таблицы на время импорта:
+// два задания, одновременно пишущие в одну таблицу, ломают друг другу
+// замену через временную таблицу. Блокировка рекомендательная — её
+// соблюдают только импорты TDTP (pg_advisory_lock, sp_getapplock, GET_LOCK,
+// lock-файл рядом с БД SQLite).
+type TableLocking struct {
+	Mode TableLockMode
+
+	// Timeout — сколько ждать в режиме wait (0 — без ограничения).
+	Timeout time.Duration
+}
+
+// Validate проверяет режим.
+func (l TableLocking) Validate() error {
+	switch l.Mode {
+	case TableLockOff, TableLockWait, TableLockFail:
+		return nil
+	}
+	return fmt.Errorf("invalid table lock mode %q (expected wait or fail)", l.Mode)
+}
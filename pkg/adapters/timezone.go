@@ -0,0 +1,69 @@
+package adapters
+
+// Виды подозрительных значений при переводе naive datetime (без смещения)
+// из часового пояса источника (Config.SourceTimezone) в UTC.
+const (
+	// TZIssueNonexistent — местное время попадает в "дыру" перехода на
+	// летнее время (02:30 в ночь перевода часов вперёд): такого момента нет,
+	// значение сдвинуто вперёд на величину перехода.
+	TZIssueNonexistent = "nonexistent"
+
+	// TZIssueAmbiguous — местное время повторяется при переходе на зимнее
+	// время (01:30 дважды): выбран один из двух моментов.
+	TZIssueAmbiguous = "ambiguous"
+
+	// TZIssueDateShift — полночь после перевода в UTC оказалась в других
+	// сутках. Обычно это дата, хранимая в DATETIME-колонке: получатель
+	// увидит предыдущий (или следующий) день.
+	TZIssueDateShift = "date_shift"
+)
+
+// MaxTimezoneSamples — сколько примеров подозрительных значений хранит отчёт
+const MaxTimezoneSamples = 20
+
+// TimezoneIssue — пример подозрительного значения
+type TimezoneIssue struct {
+	Field string
+	Kind  string // TZIssue*
+	Local string // исходное значение, местное время источника
+	UTC   string // записанное в пакет значение (RFC3339)
+}
+
+// TimezoneReport — отчёт о переводе naive datetime источника в UTC:
+// сколько значений переведено и какие из них подозрительны.
+type TimezoneReport struct {
+	Location  string           // часовой пояс источника (IANA)
+	Converted int64            // переведено значений
+	Issues    map[string]int64 // Kind → количество
+	Samples   []TimezoneIssue  // первые MaxTimezoneSamples подозрительных значений
+}
+
+// Add учитывает переведённое значение; kind "" — значение не подозрительно.
+func (r *TimezoneReport) Add(field, kind, local, utc string) {
+	r.Converted++
+	if kind == "" {
+		return
+	}
+	if r.Issues == nil {
+		r.Issues = make(map[string]int64)
+	}
+	r.Issues[kind]++
+	if len(r.Samples) < MaxTimezoneSamples {
+		r.Samples = append(r.Samples, TimezoneIssue{Field: field, Kind: kind, Local: local, UTC: utc})
+	}
+}
+
+// Suspicious возвращает общее количество подозрительных значений
+func (r *TimezoneReport) Suspicious() int64 {
+	var n int64
+	for _, c := range r.Issues {
+		n += c
+	}
+	return n
+}
+
+// TimezoneReporter — адаптер с заданным Config.SourceTimezone, отдающий
+// отчёт о переводе значений в UTC (nil — часовой пояс не задан).
+type TimezoneReporter interface {
+	TimezoneReport() *TimezoneReport
+}
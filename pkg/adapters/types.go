@@ -138,6 +138,27 @@ type ImportOptions struct {
 
 	// ContinueOnError - продолжать при ошибках (не рекомендуется)
 	ContinueOnError bool
+
+	// Partition - маршрутизация строк по таблицам-партициям (nil - без маршрутизации),
+	// см. ImportPartitioned
+	Partition *PartitionRouting
+
+	// Provenance - добавить и заполнить колонки происхождения строк
+	// (_tdtp_source, _tdtp_message_id, _tdtp_imported_at, _tdtp_part),
+	// см. ApplyProvenance
+	Provenance bool
+
+	// UpdateStatistics - обновить статистику планировщика каждой таблицы
+	// после импорта (StatisticsUpdater; без поддержки - пропускается)
+	UpdateStatistics bool
+
+	// DryRun - только проверить пакеты против целевой БД и вернуть отчёт,
+	// ничего не записывая, см. ImportWithOptions и DryRunImporter
+	DryRun bool
+
+	// MergeColumns - колонки, которые StrategyMerge обновляет у существующих
+	// строк (пусто - все неключевые поля пакета), см. WithMergeColumns
+	MergeColumns []string
 }
 
 // DefaultExportOptions возвращает опции экспорта по умолчанию
@@ -0,0 +1,302 @@
+// Package approval согласует чувствительные операции перед записью: импорт
+// в справочники production, mapping-пайплайны в чужие системы. Gate
+// публикует запрос на согласование (webhook) и блокирует операцию до
+// решения approve/reject; решение и согласующий попадают в аудит.
+//
+// Протокол webhook:
+//
+//	POST <url>            тело — Request (JSON); ответ — Decision
+//	GET  <status_url>     статус запроса (status_url из ответа на POST,
+//	                      по умолчанию <url>/<id>); ответ — Decision
+//
+// Decision.Status — pending, approved или rejected. Ответ без тела (202
+// Accepted) — pending. Пока запрос pending, Gate опрашивает статус раз в
+// PollInterval; по истечении Timeout действует OnTimeout.
+package approval
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/user"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrRejected — согласующий отклонил операцию (или истёк таймаут при
+// OnTimeout: reject).
+var ErrRejected = errors.New("approval rejected")
+
+// ErrTimeout — решение не принято за Timeout (оборачивается вместе с
+// ErrRejected при OnTimeout: reject).
+var ErrTimeout = errors.New("approval timed out")
+
+// Status — состояние запроса на согласование.
+type Status string
+
+// Значения Status.
+const (
+	StatusPending  Status = "pending"
+	StatusApproved Status = "approved"
+	StatusRejected Status = "rejected"
+)
+
+// TimeoutPolicy — решение, если согласующий не ответил за Timeout.
+type TimeoutPolicy string
+
+// Значения TimeoutPolicy.
+const (
+	TimeoutReject  TimeoutPolicy = "reject"  // операция отклоняется (по умолчанию)
+	TimeoutApprove TimeoutPolicy = "approve" // операция выполняется, в аудите — согласование по таймауту
+)
+
+// Значения по умолчанию.
+const (
+	DefaultTimeout      = time.Hour
+	DefaultPollInterval = 10 * time.Second
+)
+
+// TimeoutApprover — Decision.Approver операции, согласованной по таймауту.
+const TimeoutApprover = "timeout-policy"
+
+// Config — согласование чувствительных операций.
+type Config struct {
+	URL     string            `yaml:"url"`               // webhook согласования
+	Headers map[string]string `yaml:"headers,omitempty"` // заголовки запросов (Authorization и т.п.)
+
+	// Tables — чувствительные таблицы: импорт в них требует согласования.
+	// Имена без учёта регистра, шаблоны path.Match ("ref_*"), "*" — все.
+	Tables []string `yaml:"tables,omitempty"`
+
+	Timeout      time.Duration `yaml:"timeout,omitempty"`       // ожидание решения (по умолчанию 1h)
+	PollInterval time.Duration `yaml:"poll_interval,omitempty"` // период опроса статуса (по умолчанию 10s)
+	OnTimeout    TimeoutPolicy `yaml:"on_timeout,omitempty"`    // reject (по умолчанию) | approve
+}
+
+// Request — запрос на согласование операции.
+type Request struct {
+	ID          string    `json:"id"`
+	Operation   string    `json:"operation"`          // import, map
+	Target      string    `json:"target"`             // таблица (или таблицы через запятую)
+	Source      string    `json:"source,omitempty"`   // файл, объект S3, mapping
+	Strategy    string    `json:"strategy,omitempty"` // стратегия импорта
+	Rows        int64     `json:"rows"`
+	Requester   string    `json:"requester"` // пользователь ОС
+	Host        string    `json:"host"`
+	RequestedAt time.Time `json:"requested_at"`
+}
+
+// Decision — решение по запросу.
+type Decision struct {
+	RequestID string    `json:"request_id,omitempty"`
+	Status    Status    `json:"status"`
+	Approver  string    `json:"approver,omitempty"`
+	Comment   string    `json:"comment,omitempty"`
+	DecidedAt time.Time `json:"decided_at,omitempty"`
+
+	// StatusURL — адрес опроса статуса (только в ответе на POST).
+	StatusURL string `json:"status_url,omitempty"`
+
+	// TimedOut — решение принято политикой OnTimeout, а не согласующим.
+	TimedOut bool `json:"-"`
+}
+
+// Gate — согласование операций по Config.
+type Gate struct {
+	cfg    Config
+	client *http.Client
+	now    func() time.Time
+}
+
+// New создаёт Gate; nil без Config.URL (согласование не настроено).
+func New(cfg Config) (*Gate, error) {
+	if cfg.URL == "" {
+		if len(cfg.Tables) > 0 {
+			return nil, fmt.Errorf("approval: tables are set but url is empty")
+		}
+		return nil, nil
+	}
+	switch cfg.OnTimeout {
+	case "":
+		cfg.OnTimeout = TimeoutReject
+	case TimeoutReject, TimeoutApprove:
+	default:
+		return nil, fmt.Errorf("approval: invalid on_timeout %q (expected reject or approve)", cfg.OnTimeout)
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = DefaultPollInterval
+	}
+	for _, pattern := range cfg.Tables {
+		if _, err := path.Match(strings.ToLower(pattern), ""); err != nil {
+			return nil, fmt.Errorf("approval: invalid table pattern %q: %w", pattern, err)
+		}
+	}
+	return &Gate{cfg: cfg, client: &http.Client{Timeout: 30 * time.Second}, now: time.Now}, nil
+}
+
+// Sensitive сообщает, требует ли импорт в table согласования (Config.Tables).
+func (g *Gate) Sensitive(table string) bool {
+	if g == nil {
+		return false
+	}
+	table = strings.ToLower(table)
+	for _, pattern := range g.cfg.Tables {
+		if ok, _ := path.Match(strings.ToLower(pattern), table); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// NewRequest заполняет запрос: ID, пользователь, хост и время.
+func NewRequest(operation, target, source string) *Request {
+	req := &Request{
+		ID:          uuid.NewString(),
+		Operation:   operation,
+		Target:      target,
+		Source:      source,
+		RequestedAt: time.Now().UTC(),
+	}
+	if u, err := user.Current(); err == nil {
+		req.Requester = u.Username
+	} else {
+		req.Requester = os.Getenv("USER")
+	}
+	req.Host, _ = os.Hostname()
+	return req
+}
+
+// Await публикует запрос и ждёт решения. Одобрение — Decision без ошибки;
+// отказ — Decision и ErrRejected; таймаут — по OnTimeout (отказ оборачивает
+// ErrRejected и ErrTimeout). Отмена ctx прерывает ожидание.
+func (g *Gate) Await(ctx context.Context, req *Request) (*Decision, error) {
+	d, err := g.submit(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("approval request failed: %w", err)
+	}
+	statusURL := d.StatusURL
+	if statusURL == "" {
+		statusURL = strings.TrimRight(g.cfg.URL, "/") + "/" + req.ID
+	}
+	deadline := g.now().Add(g.cfg.Timeout)
+	if d.Status == StatusPending {
+		fmt.Printf("⏳ Waiting for approval of %s %s (request %s, timeout %s)\n", req.Operation, req.Target, req.ID, g.cfg.Timeout)
+	}
+	for d.Status == StatusPending {
+		wait := g.cfg.PollInterval
+		if left := deadline.Sub(g.now()); left < wait {
+			wait = left
+		}
+		if wait <= 0 {
+			return g.timedOut(req)
+		}
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return nil, ctx.Err()
+		case <-t.C:
+		}
+		next, err := g.status(ctx, statusURL)
+		if err != nil {
+			// Сбой опроса не решение: ждём дальше до таймаута
+			fmt.Printf("  ⚠ approval status check failed: %v\n", err)
+			continue
+		}
+		d = next
+	}
+
+	d.RequestID = req.ID
+	switch d.Status {
+	case StatusApproved:
+		return d, nil
+	case StatusRejected:
+		msg := fmt.Sprintf("%s %s", req.Operation, req.Target)
+		if d.Approver != "" {
+			msg += " by " + d.Approver
+		}
+		if d.Comment != "" {
+			msg += ": " + d.Comment
+		}
+		return d, fmt.Errorf("%w: %s", ErrRejected, msg)
+	default:
+		return nil, fmt.Errorf("approval request %s: unknown status %q", req.ID, d.Status)
+	}
+}
+
+// timedOut — решение по OnTimeout.
+func (g *Gate) timedOut(req *Request) (*Decision, error) {
+	d := &Decision{RequestID: req.ID, DecidedAt: g.now().UTC(), TimedOut: true}
+	if g.cfg.OnTimeout == TimeoutApprove {
+		d.Status, d.Approver = StatusApproved, TimeoutApprover
+		fmt.Printf("  ⚠ approval of %s %s timed out after %s: approved by on_timeout policy\n", req.Operation, req.Target, g.cfg.Timeout)
+		return d, nil
+	}
+	d.Status = StatusRejected
+	return d, fmt.Errorf("%w: %w after %s (%s %s)", ErrRejected, ErrTimeout, g.cfg.Timeout, req.Operation, req.Target)
+}
+
+// submit — POST запроса на webhook.
+func (g *Gate) submit(ctx context.Context, req *Request) (*Decision, error) {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, g.cfg.URL, bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	return g.do(httpReq)
+}
+
+// status — GET статуса запроса.
+func (g *Gate) status(ctx context.Context, url string) (*Decision, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	return g.do(httpReq)
+}
+
+// do выполняет запрос и разбирает Decision; пустой ответ — pending.
+func (g *Gate) do(httpReq *http.Request) (*Decision, error) {
+	for k, v := range g.cfg.Headers {
+		httpReq.Header.Set(k, v)
+	}
+	resp, err := g.client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	d := &Decision{Status: StatusPending}
+	if len(bytes.TrimSpace(body)) == 0 {
+		return d, nil
+	}
+	if err := json.Unmarshal(body, d); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+	if d.Status == "" {
+		d.Status = StatusPending
+	}
+	return d, nil
+}
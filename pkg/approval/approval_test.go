@@ -0,0 +1,128 @@
+package approval
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// approvalServer — webhook, отвечающий pending первые pending опросов,
+// затем final.
+func approvalServer(t *testing.T, pending int32, final Decision) (*httptest.Server, *int32) {
+	t.Helper()
+	var polls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Method == http.MethodPost {
+			var req Request
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.ID == "" || req.Target != "currencies" {
+				t.Errorf("bad request %+v: %v", req, err)
+			}
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		if atomic.AddInt32(&polls, 1) <= pending {
+			_ = json.NewEncoder(w).Encode(Decision{Status: StatusPending})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(final)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &polls
+}
+
+func testGate(t *testing.T, url string, timeout time.Duration, onTimeout TimeoutPolicy) *Gate {
+	t.Helper()
+	g, err := New(Config{
+		URL:          url + "/approvals",
+		Headers:      map[string]string{"Authorization": "Bearer secret"},
+		Tables:       []string{"currencies", "ref_*"},
+		Timeout:      timeout,
+		PollInterval: 10 * time.Millisecond,
+		OnTimeout:    onTimeout,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+func TestGate_Approved(t *testing.T) {
+	srv, polls := approvalServer(t, 2, Decision{Status: StatusApproved, Approver: "ivanov", Comment: "CR-1042"})
+	g := testGate(t, srv.URL, time.Minute, "")
+
+	d, err := g.Await(context.Background(), NewRequest("import", "currencies", "currencies.tdtp.xml"))
+	if err != nil {
+		t.Fatalf("Await: %v", err)
+	}
+	if d.Status != StatusApproved || d.Approver != "ivanov" || d.RequestID == "" || d.TimedOut {
+		t.Errorf("decision = %+v", d)
+	}
+	if *polls != 3 {
+		t.Errorf("polls = %d, want 3", *polls)
+	}
+}
+
+func TestGate_Rejected(t *testing.T) {
+	srv, _ := approvalServer(t, 0, Decision{Status: StatusRejected, Approver: "petrov", Comment: "not in the change window"})
+	g := testGate(t, srv.URL, time.Minute, "")
+
+	d, err := g.Await(context.Background(), NewRequest("import", "currencies", ""))
+	if !errors.Is(err, ErrRejected) || errors.Is(err, ErrTimeout) {
+		t.Fatalf("err = %v, want ErrRejected", err)
+	}
+	if d == nil || d.Approver != "petrov" || !strings.Contains(err.Error(), "not in the change window") {
+		t.Errorf("decision = %+v, err = %v", d, err)
+	}
+}
+
+func TestGate_Timeout(t *testing.T) {
+	srv, _ := approvalServer(t, 1000, Decision{Status: StatusApproved})
+
+	_, err := testGate(t, srv.URL, 50*time.Millisecond, "").Await(context.Background(), NewRequest("import", "currencies", ""))
+	if !errors.Is(err, ErrRejected) || !errors.Is(err, ErrTimeout) {
+		t.Fatalf("reject policy: err = %v, want ErrRejected and ErrTimeout", err)
+	}
+
+	d, err := testGate(t, srv.URL, 50*time.Millisecond, TimeoutApprove).Await(context.Background(), NewRequest("import", "currencies", ""))
+	if err != nil {
+		t.Fatalf("approve policy: %v", err)
+	}
+	if d.Status != StatusApproved || !d.TimedOut || d.Approver != TimeoutApprover {
+		t.Errorf("approve policy: decision = %+v", d)
+	}
+}
+
+func TestGate_Sensitive(t *testing.T) {
+	g := testGate(t, "http://approvals.local", time.Minute, "")
+	for table, want := range map[string]bool{"currencies": true, "CURRENCIES": true, "ref_countries": true, "orders": false} {
+		if got := g.Sensitive(table); got != want {
+			t.Errorf("Sensitive(%q) = %v, want %v", table, got, want)
+		}
+	}
+	var none *Gate
+	if none.Sensitive("currencies") {
+		t.Error("nil gate: Sensitive = true")
+	}
+}
+
+func TestNew_Validation(t *testing.T) {
+	if g, err := New(Config{}); g != nil || err != nil {
+		t.Errorf("empty config: gate %v, err %v", g, err)
+	}
+	if _, err := New(Config{Tables: []string{"currencies"}}); err == nil {
+		t.Error("tables without url: expected error")
+	}
+	if _, err := New(Config{URL: "http://x", OnTimeout: "wait"}); err == nil {
+		t.Error("invalid on_timeout: expected error")
+	}
+}
@@ -52,7 +52,8 @@ const (
 	OpMask         Operation = "mask"
 	OpNormalize    Operation = "normalize"
 	OpTransform    Operation = "transform"
-	OpSync         Operation = "sync" // Синхронизация данных
+	OpSync         Operation = "sync"          // Синхронизация данных
+	OpSchemaChange Operation = "schema_change" // Изменение схемы источника во время синхронизации
 	OpAuthenticate Operation = "authenticate"
 )
 
@@ -64,6 +65,7 @@ const (
 	StatusSuccess Status = "success"
 	StatusFailure Status = "failure"
 	StatusPartial Status = "partial"
+	StatusSkipped Status = "skipped" // Операция не запускалась (например, предыдущая ещё идёт)
 )
 
 // Entry - запись в audit логе
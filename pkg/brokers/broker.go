@@ -7,7 +7,7 @@ import (
 )
 
 // MessageBroker представляет универсальный интерфейс для работы с очередями сообщений
-// Поддерживает RabbitMQ, MSMQ и Apache Kafka
+// Поддерживает RabbitMQ, MSMQ, Apache Kafka и файловую очередь (filequeue)
 type MessageBroker interface {
 	// Connect устанавливает соединение с брокером
 	Connect(ctx context.Context) error
@@ -30,13 +30,13 @@ type MessageBroker interface {
 	// Ping проверяет доступность брокера
 	Ping(ctx context.Context) error
 
-	// GetBrokerType возвращает тип брокера (rabbitmq, msmq, kafka)
+	// GetBrokerType возвращает тип брокера (rabbitmq, msmq, kafka, filequeue)
 	GetBrokerType() string
 }
 
 // Config содержит параметры подключения к message broker
 type Config struct {
-	Type          string `yaml:"type"`                      // rabbitmq, msmq, kafka
+	Type          string `yaml:"type"`                      // rabbitmq, msmq, kafka, filequeue
 	Host          string `yaml:"host,omitempty"`            // Хост (для RabbitMQ)
 	Port          int    `yaml:"port,omitempty"`            // Порт (для RabbitMQ)
 	User          string `yaml:"user,omitempty"`            // Пользователь (для RabbitMQ)
@@ -53,6 +53,9 @@ type Config struct {
 	AutoDelete     bool `yaml:"auto_delete,omitempty"`     // Очередь удаляется когда нет consumer'ов
 	Exclusive      bool `yaml:"exclusive,omitempty"`       // Очередь доступна только одному соединению
 	PassiveDeclare bool `yaml:"passive_declare,omitempty"` // Не создавать очередь — только проверить
+	// MaxPriority > 0 объявляет priority queue (аргумент x-max-priority).
+	// Как и durable, должен совпадать с существующей очередью — иначе 406 PRECONDITION_FAILED.
+	MaxPriority int `yaml:"max_priority,omitempty"`
 
 	// MSMQ специфичные параметры (Windows only)
 	QueuePath string `yaml:"queue_path,omitempty"` // Путь к очереди MSMQ
@@ -61,6 +64,40 @@ type Config struct {
 	Brokers       []string `yaml:"brokers,omitempty"`        // Список Kafka brokers
 	Topic         string   `yaml:"topic,omitempty"`          // Имя Kafka topic
 	ConsumerGroup string   `yaml:"consumer_group,omitempty"` // Consumer group ID
+	// UrgentTopic — отдельный topic для срочных пакетов (priority >= packet.PriorityUrgent).
+	// Kafka не имеет приоритетов внутри partition, поэтому срочные пакеты
+	// обгоняют bulk-загрузку через собственный topic. Consumer читает оба topic.
+	UrgentTopic string `yaml:"urgent_topic,omitempty"`
+
+	// FileQueue специфичные параметры (Queue — необязательный подкаталог)
+	Dir     string `yaml:"dir,omitempty"`      // Каталог очереди (общая папка, съёмный носитель)
+	AutoAck bool   `yaml:"auto_ack,omitempty"` // Подтверждать сообщение сразу при Receive
+}
+
+// PrioritySender — опциональное расширение MessageBroker для брокеров,
+// умеющих доставлять срочные сообщения раньше обычных.
+// RabbitMQ: AMQP priority (требует очередь с max_priority).
+// Kafka: публикация в UrgentTopic для срочных пакетов.
+type PrioritySender interface {
+	SendWithPriority(ctx context.Context, message []byte, priority int) error
+}
+
+// SendWithPriority отправляет сообщение с приоритетом, если брокер это поддерживает,
+// иначе — обычным Send (приоритет игнорируется, порядок доставки FIFO).
+func SendWithPriority(ctx context.Context, broker MessageBroker, message []byte, priority int) error {
+	if ps, ok := broker.(PrioritySender); ok && priority > 0 {
+		return ps.SendWithPriority(ctx, message, priority)
+	}
+	return broker.Send(ctx, message)
+}
+
+// DepthReporter — опциональное расширение MessageBroker: сколько сообщений
+// ждут потребителя. Потоковый экспорт опрашивает глубину и притормаживает
+// отправку, пока потребитель не разберёт очередь (etl backpressure).
+// RabbitMQ: сообщения в очереди (passive declare), Kafka: lag consumer group
+// ConsumerGroup по Topic, filequeue: сообщения в ready/.
+type DepthReporter interface {
+	QueueDepth(ctx context.Context) (int64, error)
 }
 
 // New создает новый MessageBroker на основе конфигурации
@@ -72,7 +109,9 @@ func New(cfg Config) (MessageBroker, error) {
 		return NewMSMQ(cfg)
 	case "kafka":
 		return NewKafka(cfg)
+	case "filequeue":
+		return NewFileQueue(cfg)
 	default:
-		return nil, fmt.Errorf("unsupported broker type: %s (supported: rabbitmq, msmq, kafka)", cfg.Type)
+		return nil, fmt.Errorf("unsupported broker type: %s (supported: rabbitmq, msmq, kafka, filequeue)", cfg.Type)
 	}
 }
@@ -0,0 +1,420 @@
+package brokers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+// FileQueue реализует MessageBroker на каталоге файловой системы — для
+// изолированных (air-gapped) контуров, где брокера нет, а пакеты переносятся
+// через общую папку или съёмный носитель.
+//
+// Структура каталога <Dir>/<Queue>:
+//
+//	ready/       сообщения, ожидающие чтения: <9-priority>-<seq>.msg
+//	processing/  взятые консьюмером (Receive), но ещё не подтверждённые
+//	done/        подтверждённые (AckLast) — ack перемещением, а не удалением
+//	failed/      отклонённые без повтора (NackLast(false))
+//	tmp/         недописанные сообщения
+//	seq, seq.lock  счётчик последовательности и lock-файл продюсеров
+//
+// Сообщение пишется в tmp/ и попадает в ready/ атомарным rename под
+// seq.lock, поэтому консьюмер никогда не видит недописанный файл. Консьюмер
+// забирает сообщение rename'ом ready/ → processing/: из нескольких
+// конкурирующих консьюмеров rename удаётся ровно одному. Имена сортируются
+// сначала по приоритету, затем по номеру — срочные пакеты обгоняют bulk.
+//
+// Семантика подтверждений повторяет RabbitMQ: неподтверждённые сообщения
+// при Close возвращаются в ready/. Сообщения, застрявшие в processing/
+// после падения процесса дольше fileQueueStaleClaim, возвращаются при Connect.
+type FileQueue struct {
+	config Config
+	root   string
+
+	mu      sync.Mutex
+	claimed map[string]struct{} // взятые этим экземпляром, не подтверждённые
+	last    string              // последнее полученное сообщение (для AckLast/NackLast)
+}
+
+const (
+	fileQueuePoll       = 200 * time.Millisecond
+	fileQueueLockRetry  = 20 * time.Millisecond
+	fileQueueStaleLock  = 30 * time.Second // seq.lock старше — продюсер упал, lock снимается
+	fileQueueStaleClaim = 30 * time.Minute
+	fileQueueExt        = ".msg"
+)
+
+// Подкаталоги очереди.
+const (
+	fqReady      = "ready"
+	fqProcessing = "processing"
+	fqDone       = "done"
+	fqFailed     = "failed"
+	fqTmp        = "tmp"
+)
+
+// NewFileQueue создает файловую очередь в каталоге cfg.Dir (подкаталог cfg.Queue, если задан).
+func NewFileQueue(cfg Config) (*FileQueue, error) {
+	if cfg.Dir == "" {
+		return nil, fmt.Errorf("dir is required for filequeue")
+	}
+	root := cfg.Dir
+	if cfg.Queue != "" {
+		if strings.ContainsAny(cfg.Queue, `/\`) || cfg.Queue == ".." {
+			return nil, fmt.Errorf("invalid filequeue queue name: %q", cfg.Queue)
+		}
+		root = filepath.Join(cfg.Dir, cfg.Queue)
+	}
+	return &FileQueue{
+		config:  cfg,
+		root:    root,
+		claimed: make(map[string]struct{}),
+	}, nil
+}
+
+func (q *FileQueue) path(sub string, name ...string) string {
+	return filepath.Join(append([]string{q.root, sub}, name...)...)
+}
+
+// Connect создаёт структуру каталогов и возвращает в ready/ сообщения,
+// оставшиеся в processing/ после аварийного завершения консьюмера.
+func (q *FileQueue) Connect(ctx context.Context) error {
+	for _, sub := range []string{fqReady, fqProcessing, fqDone, fqFailed, fqTmp} {
+		if err := os.MkdirAll(q.path(sub), 0o755); err != nil {
+			return fmt.Errorf("failed to create filequeue directory: %w", err)
+		}
+	}
+	return q.recoverStale(time.Now().Add(-fileQueueStaleClaim))
+}
+
+// recoverStale возвращает в ready/ сообщения из processing/, взятые раньше before.
+func (q *FileQueue) recoverStale(before time.Time) error {
+	entries, err := os.ReadDir(q.path(fqProcessing))
+	if err != nil {
+		return fmt.Errorf("failed to read filequeue: %w", err)
+	}
+	for _, e := range entries {
+		info, err := e.Info()
+		if err != nil || !info.ModTime().Before(before) {
+			continue
+		}
+		err = os.Rename(q.path(fqProcessing, e.Name()), q.path(fqReady, e.Name()))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("failed to recover stale message %s: %w", e.Name(), err)
+		}
+	}
+	return nil
+}
+
+// Close возвращает неподтверждённые сообщения в ready/.
+func (q *FileQueue) Close() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var errs []error
+	for name := range q.claimed {
+		if err := os.Rename(q.path(fqProcessing, name), q.path(fqReady, name)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			errs = append(errs, err)
+		}
+		delete(q.claimed, name)
+	}
+	q.last = ""
+	return errors.Join(errs...)
+}
+
+// Send помещает сообщение в очередь
+func (q *FileQueue) Send(ctx context.Context, message []byte) error {
+	return q.SendWithPriority(ctx, message, 0)
+}
+
+// SendWithPriority помещает сообщение в очередь; сообщения с большим
+// приоритетом (0..9) читаются раньше.
+func (q *FileQueue) SendWithPriority(ctx context.Context, message []byte, priority int) error {
+	priority = min(max(priority, 0), packet.MaxPriority)
+
+	tmp, err := q.writeTmp(message)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp) }() // no-op после успешного rename
+
+	unlock, err := q.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	seq, err := q.nextSeq()
+	if err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%d-%020d%s", packet.MaxPriority-priority, seq, fileQueueExt)
+	if err := os.Rename(tmp, q.path(fqReady, name)); err != nil {
+		return fmt.Errorf("failed to enqueue message: %w", err)
+	}
+	return nil
+}
+
+// SendBatch помещает сообщения в очередь последовательно.
+func (q *FileQueue) SendBatch(ctx context.Context, messages [][]byte) error {
+	for i, msg := range messages {
+		if err := q.Send(ctx, msg); err != nil {
+			return fmt.Errorf("filequeue batch message %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// writeTmp записывает сообщение во временный файл и сбрасывает его на диск:
+// на съёмном носителе rename без fsync может пережить извлечение раньше данных.
+func (q *FileQueue) writeTmp(message []byte) (string, error) {
+	var suffix [8]byte
+	if _, err := rand.Read(suffix[:]); err != nil {
+		return "", err
+	}
+	path := q.path(fqTmp, hex.EncodeToString(suffix[:])+".tmp")
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if err != nil {
+		return "", fmt.Errorf("failed to write filequeue message: %w", err)
+	}
+	_, err = f.Write(message)
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		_ = os.Remove(path)
+		return "", fmt.Errorf("failed to write filequeue message: %w", err)
+	}
+	return path, nil
+}
+
+// lock захватывает seq.lock (O_EXCL-создание работает и на сетевых папках,
+// где flock ненадёжен). Lock старше fileQueueStaleLock считается брошенным.
+func (q *FileQueue) lock(ctx context.Context) (func(), error) {
+	path := filepath.Join(q.root, "seq.lock")
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if err == nil {
+			host, _ := os.Hostname()
+			_, _ = fmt.Fprintf(f, "%s %d\n", host, os.Getpid())
+			_ = f.Close()
+			return func() { _ = os.Remove(path) }, nil
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return nil, fmt.Errorf("failed to acquire filequeue lock: %w", err)
+		}
+		if info, serr := os.Stat(path); serr == nil && time.Since(info.ModTime()) > fileQueueStaleLock {
+			_ = os.Remove(path)
+			continue
+		}
+		if err := sleepCtx(ctx, fileQueueLockRetry); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// nextSeq увеличивает счётчик (вызывается под lock). Если файл seq потерян,
+// счёт продолжается от максимального номера среди сообщений очереди.
+func (q *FileQueue) nextSeq() (uint64, error) {
+	path := filepath.Join(q.root, "seq")
+	var seq uint64
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		seq, err = strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("corrupted filequeue sequence file %s: %w", path, err)
+		}
+	case errors.Is(err, fs.ErrNotExist):
+		seq = q.maxSeq()
+	default:
+		return 0, fmt.Errorf("failed to read filequeue sequence: %w", err)
+	}
+	seq++
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(strconv.FormatUint(seq, 10)), 0o644); err != nil {
+		return 0, fmt.Errorf("failed to write filequeue sequence: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return 0, fmt.Errorf("failed to write filequeue sequence: %w", err)
+	}
+	return seq, nil
+}
+
+func (q *FileQueue) maxSeq() uint64 {
+	var maxSeq uint64
+	for _, sub := range []string{fqReady, fqProcessing, fqDone, fqFailed} {
+		entries, _ := os.ReadDir(q.path(sub))
+		for _, e := range entries {
+			if seq, ok := parseFileQueueSeq(e.Name()); ok && seq > maxSeq {
+				maxSeq = seq
+			}
+		}
+	}
+	return maxSeq
+}
+
+func parseFileQueueSeq(name string) (uint64, bool) {
+	_, rest, ok := strings.Cut(strings.TrimSuffix(name, fileQueueExt), "-")
+	if !ok {
+		return 0, false
+	}
+	seq, err := strconv.ParseUint(rest, 10, 64)
+	return seq, err == nil
+}
+
+// Receive забирает следующее сообщение из очереди.
+// Блокирующий вызов: опрашивает ready/, пока не появится сообщение или не
+// отменят контекст. Сообщение остаётся в processing/ до AckLast/NackLast
+// (или сразу уходит в done/ при Config.AutoAck).
+func (q *FileQueue) Receive(ctx context.Context) ([]byte, error) {
+	for {
+		data, ok, err := q.tryReceive()
+		if err != nil || ok {
+			return data, err
+		}
+		if err := sleepCtx(ctx, fileQueuePoll); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (q *FileQueue) tryReceive() ([]byte, bool, error) {
+	entries, err := os.ReadDir(q.path(fqReady))
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read filequeue: %w", err)
+	}
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), fileQueueExt) {
+			names = append(names, e.Name())
+		}
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		claimed := q.path(fqProcessing, name)
+		if err := os.Rename(q.path(fqReady, name), claimed); err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue // забрал другой консьюмер
+			}
+			return nil, false, fmt.Errorf("failed to claim message %s: %w", name, err)
+		}
+		now := time.Now()
+		_ = os.Chtimes(claimed, now, now) // отсчёт fileQueueStaleClaim от момента взятия
+
+		data, err := os.ReadFile(claimed)
+		if err != nil {
+			_ = os.Rename(claimed, q.path(fqReady, name))
+			return nil, false, fmt.Errorf("failed to read message %s: %w", name, err)
+		}
+
+		q.mu.Lock()
+		q.claimed[name] = struct{}{}
+		q.last = name
+		q.mu.Unlock()
+
+		if q.config.AutoAck {
+			if err := q.AckLast(); err != nil {
+				return nil, false, err
+			}
+		}
+		return data, true, nil
+	}
+	return nil, false, nil
+}
+
+// AckLast подтверждает последнее полученное сообщение (перемещает в done/).
+func (q *FileQueue) AckLast() error {
+	return q.settleLast(fqDone, "no message to acknowledge")
+}
+
+// CommitLast — AckLast под именем, которое используют потоковые консьюмеры
+// (Kafka-совместимая семантика «подтвердить после успешного импорта»).
+func (q *FileQueue) CommitLast(ctx context.Context) error {
+	return q.AckLast()
+}
+
+// NackLast отклоняет последнее полученное сообщение: requeue — обратно в
+// ready/, иначе в failed/ для ручного разбора.
+func (q *FileQueue) NackLast(requeue bool) error {
+	target := fqFailed
+	if requeue {
+		target = fqReady
+	}
+	return q.settleLast(target, "no message to reject")
+}
+
+func (q *FileQueue) settleLast(target, emptyMsg string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.last == "" {
+		return errors.New(emptyMsg)
+	}
+	name := q.last
+	if err := os.Rename(q.path(fqProcessing, name), q.path(target, name)); err != nil {
+		return fmt.Errorf("failed to move message %s to %s: %w", name, target, err)
+	}
+	delete(q.claimed, name)
+	q.last = ""
+	return nil
+}
+
+// Ping проверяет доступность каталога очереди
+func (q *FileQueue) Ping(ctx context.Context) error {
+	info, err := os.Stat(q.path(fqReady))
+	if err != nil {
+		return fmt.Errorf("filequeue is not available: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("filequeue is not available: %s is not a directory", q.path(fqReady))
+	}
+	return nil
+}
+
+// QueueDepth возвращает число сообщений в ready/, ещё не взятых консьюмером.
+func (q *FileQueue) QueueDepth(_ context.Context) (int64, error) {
+	entries, err := os.ReadDir(q.path(fqReady))
+	if err != nil {
+		return 0, fmt.Errorf("failed to read filequeue: %w", err)
+	}
+	var n int64
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), fileQueueExt) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// GetBrokerType возвращает тип брокера
+func (q *FileQueue) GetBrokerType() string {
+	return "filequeue"
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
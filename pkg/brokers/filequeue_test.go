@@ -0,0 +1,269 @@
+package brokers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newTestFileQueue(t *testing.T, dir string, autoAck bool) *FileQueue {
+	t.Helper()
+	b, err := New(Config{Type: "filequeue", Dir: dir, Queue: "tdtp_in", AutoAck: autoAck})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	q := b.(*FileQueue)
+	if err := q.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	return q
+}
+
+func receiveNow(t *testing.T, q *FileQueue) string {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	data, err := q.Receive(ctx)
+	if err != nil {
+		t.Fatalf("Receive: %v", err)
+	}
+	return string(data)
+}
+
+func countFiles(t *testing.T, q *FileQueue, sub string) int {
+	t.Helper()
+	entries, err := os.ReadDir(q.path(sub))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(entries)
+}
+
+func TestFileQueue_OrderAndPriority(t *testing.T) {
+	q := newTestFileQueue(t, t.TempDir(), true)
+	ctx := context.Background()
+
+	if err := q.SendBatch(ctx, [][]byte{[]byte("a"), []byte("b")}); err != nil {
+		t.Fatal(err)
+	}
+	if err := SendWithPriority(ctx, q, []byte("urgent"), 9); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Send(ctx, []byte("c")); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{"urgent", "a", "b", "c"} {
+		if got := receiveNow(t, q); got != want {
+			t.Fatalf("got %q, want %q", got, want)
+		}
+	}
+	if n := countFiles(t, q, fqDone); n != 4 {
+		t.Errorf("done/ has %d files, want 4 (auto ack)", n)
+	}
+	if n := countFiles(t, q, fqTmp); n != 0 {
+		t.Errorf("tmp/ has %d leftover files", n)
+	}
+}
+
+func TestFileQueue_AckNackClose(t *testing.T) {
+	dir := t.TempDir()
+	q := newTestFileQueue(t, dir, false)
+	ctx := context.Background()
+	for _, m := range []string{"m1", "m2", "m3"} {
+		if err := q.Send(ctx, []byte(m)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := receiveNow(t, q); got != "m1" {
+		t.Fatalf("got %q", got)
+	}
+	if err := q.AckLast(); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.AckLast(); err == nil {
+		t.Error("second AckLast must fail")
+	}
+
+	if got := receiveNow(t, q); got != "m2" {
+		t.Fatalf("got %q", got)
+	}
+	if err := q.NackLast(true); err != nil {
+		t.Fatal(err)
+	}
+	if got := receiveNow(t, q); got != "m2" {
+		t.Fatalf("requeued message: got %q, want m2", got)
+	}
+	if err := q.NackLast(false); err != nil {
+		t.Fatal(err)
+	}
+
+	// m3 не подтверждён — Close возвращает его в очередь.
+	if got := receiveNow(t, q); got != "m3" {
+		t.Fatalf("got %q", got)
+	}
+	if err := q.Close(); err != nil {
+		t.Fatal(err)
+	}
+	for sub, want := range map[string]int{fqReady: 1, fqProcessing: 0, fqDone: 1, fqFailed: 1} {
+		if n := countFiles(t, q, sub); n != want {
+			t.Errorf("%s/ has %d files, want %d", sub, n, want)
+		}
+	}
+
+	q2 := newTestFileQueue(t, dir, true)
+	if got := receiveNow(t, q2); got != "m3" {
+		t.Errorf("after reconnect got %q, want m3", got)
+	}
+}
+
+func TestFileQueue_ReceiveBlocksUntilContextDone(t *testing.T) {
+	q := newTestFileQueue(t, t.TempDir(), true)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := q.Receive(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want deadline exceeded", err)
+	}
+}
+
+func TestFileQueue_ConcurrentProducersAndConsumers(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	const producers, perProducer = 4, 25
+
+	var wg sync.WaitGroup
+	for p := range producers {
+		q := newTestFileQueue(t, dir, false)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range perProducer {
+				if err := q.Send(ctx, fmt.Appendf(nil, "%d-%d", p, i)); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	var mu sync.Mutex
+	seen := make(map[string]int)
+	for range 3 {
+		q := newTestFileQueue(t, dir, true)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				data, ok, err := q.tryReceive()
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if !ok {
+					return
+				}
+				mu.Lock()
+				seen[string(data)]++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(seen) != producers*perProducer {
+		t.Fatalf("received %d distinct messages, want %d", len(seen), producers*perProducer)
+	}
+	for msg, n := range seen {
+		if n != 1 {
+			t.Errorf("message %s delivered %d times", msg, n)
+		}
+	}
+}
+
+func TestFileQueue_SequenceRecoveryAndStaleLock(t *testing.T) {
+	q := newTestFileQueue(t, t.TempDir(), false)
+	ctx := context.Background()
+	for range 3 {
+		if err := q.Send(ctx, []byte("x")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Потерянный seq и брошенный lock упавшего продюсера.
+	if err := os.Remove(filepath.Join(q.root, "seq")); err != nil {
+		t.Fatal(err)
+	}
+	lock := filepath.Join(q.root, "seq.lock")
+	if err := os.WriteFile(lock, []byte("crashed 1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(lock, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := q.Send(ctx, []byte("x")); err != nil {
+		t.Fatal(err)
+	}
+	if got := q.maxSeq(); got != 4 {
+		t.Errorf("max seq = %d, want 4", got)
+	}
+	if _, err := os.Stat(lock); !os.IsNotExist(err) {
+		t.Errorf("lock must be released, stat err = %v", err)
+	}
+}
+
+func TestFileQueue_RecoverStaleClaims(t *testing.T) {
+	dir := t.TempDir()
+	q := newTestFileQueue(t, dir, false)
+	if err := q.Send(context.Background(), []byte("lost")); err != nil {
+		t.Fatal(err)
+	}
+	_ = receiveNow(t, q) // консьюмер «упал» без ack и Close
+
+	old := time.Now().Add(-2 * fileQueueStaleClaim)
+	entries, _ := os.ReadDir(q.path(fqProcessing))
+	for _, e := range entries {
+		if err := os.Chtimes(q.path(fqProcessing, e.Name()), old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	q2 := newTestFileQueue(t, dir, true)
+	if got := receiveNow(t, q2); got != "lost" {
+		t.Errorf("got %q, want recovered message", got)
+	}
+}
+
+func TestNewFileQueue_Validation(t *testing.T) {
+	if _, err := NewFileQueue(Config{Type: "filequeue"}); err == nil {
+		t.Error("expected error for empty dir")
+	}
+	if _, err := NewFileQueue(Config{Type: "filequeue", Dir: t.TempDir(), Queue: "../x"}); err == nil {
+		t.Error("expected error for queue with path separator")
+	}
+}
+
+func TestFileQueue_QueueDepth(t *testing.T) {
+	q := newTestFileQueue(t, t.TempDir(), false)
+	ctx := context.Background()
+	var _ DepthReporter = q
+
+	if err := q.SendBatch(ctx, [][]byte{[]byte("a"), []byte("b"), []byte("c")}); err != nil {
+		t.Fatal(err)
+	}
+	if depth, err := q.QueueDepth(ctx); err != nil || depth != 3 {
+		t.Fatalf("QueueDepth = %d, %v; want 3", depth, err)
+	}
+	receiveNow(t, q) // взятое консьюмером сообщение очередь не занимает
+	if depth, _ := q.QueueDepth(ctx); depth != 2 {
+		t.Errorf("QueueDepth after Receive = %d, want 2", depth)
+	}
+}
@@ -1,12 +1,14 @@
-//go:build !nokafka
+//go:build !nokafka && !minimal
 
 package brokers
 
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"time"
 
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
 	"github.com/segmentio/kafka-go"
 )
 
@@ -14,6 +16,7 @@ import (
 type Kafka struct {
 	config      Config
 	writer      *kafka.Writer
+	urgent      *kafka.Writer // writer для UrgentTopic (nil если не задан)
 	reader      *kafka.Reader
 	lastMessage *kafka.Message // Последнее полученное сообщение (для manual commit)
 }
@@ -39,9 +42,20 @@ func NewKafka(cfg Config) (*Kafka, error) {
 // Reader создаётся лениво при первом вызове Receive() —
 // это убирает ~3-секундный блок в Close() когда брокер используется только для записи.
 func (k *Kafka) Connect(ctx context.Context) error {
-	k.writer = &kafka.Writer{
+	k.writer = k.newWriter(k.config.Topic)
+	if k.config.UrgentTopic != "" {
+		k.urgent = k.newWriter(k.config.UrgentTopic)
+	}
+
+	// Проверяем подключение без создания Reader
+	return k.Ping(ctx)
+}
+
+// newWriter создаёт синхронный writer для указанного topic.
+func (k *Kafka) newWriter(topic string) *kafka.Writer {
+	return &kafka.Writer{
 		Addr:         kafka.TCP(k.config.Brokers...),
-		Topic:        k.config.Topic,
+		Topic:        topic,
 		Balancer:     &kafka.LeastBytes{},
 		RequiredAcks: kafka.RequireOne,
 		Async:        false,
@@ -51,9 +65,6 @@ func (k *Kafka) Connect(ctx context.Context) error {
 		BatchBytes:   100 * 1024 * 1024,    // 100MB — поддержка крупных TDTP-пакетов
 		BatchTimeout: 5 * time.Millisecond, // Не ждать накопления — отправлять сразу
 	}
-
-	// Проверяем подключение без создания Reader
-	return k.Ping(ctx)
 }
 
 // ensureReader создаёт Reader при первом обращении к Receive().
@@ -61,7 +72,7 @@ func (k *Kafka) ensureReader() {
 	if k.reader != nil {
 		return
 	}
-	k.reader = kafka.NewReader(kafka.ReaderConfig{
+	cfg := kafka.ReaderConfig{
 		Brokers:           k.config.Brokers,
 		GroupID:           k.config.ConsumerGroup,
 		Topic:             k.config.Topic,
@@ -74,7 +85,14 @@ func (k *Kafka) ensureReader() {
 		ReadBackoffMax:    1 * time.Second,
 		HeartbeatInterval: 200 * time.Millisecond, // Close() ждёт не более одного интервала
 		SessionTimeout:    6 * time.Second,        // брокер считает консьюмера мёртвым через 6с
-	})
+	}
+	// С UrgentTopic читаем оба topic одной consumer group — приоритизацию
+	// выполняет потребитель (ParallelImporter) по Header.Priority.
+	if k.config.UrgentTopic != "" {
+		cfg.Topic = ""
+		cfg.GroupTopics = []string{k.config.Topic, k.config.UrgentTopic}
+	}
+	k.reader = kafka.NewReader(cfg)
 }
 
 // Close закрывает соединение с Kafka.
@@ -89,6 +107,11 @@ func (k *Kafka) Close() error {
 			errs = append(errs, fmt.Errorf("failed to close writer: %w", err))
 		}
 	}
+	if k.urgent != nil {
+		if err := k.urgent.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close urgent writer: %w", err))
+		}
+	}
 
 	if k.reader != nil {
 		done := make(chan error, 1)
@@ -112,21 +135,37 @@ func (k *Kafka) Close() error {
 
 // Send отправляет сообщение в Kafka topic
 func (k *Kafka) Send(ctx context.Context, message []byte) error {
+	return k.SendWithPriority(ctx, message, 0)
+}
+
+// SendWithPriority отправляет сообщение с заголовком priority.
+// Срочные сообщения (priority >= packet.PriorityUrgent) уходят в UrgentTopic,
+// если он задан; иначе — в основной topic (приоритет остаётся только в заголовке).
+func (k *Kafka) SendWithPriority(ctx context.Context, message []byte, priority int) error {
 	if k.writer == nil {
 		return fmt.Errorf("not connected to Kafka")
 	}
 
+	headers := []kafka.Header{
+		{Key: "content-type", Value: []byte("application/xml")},
+		{Key: "protocol", Value: []byte("tdtp")},
+	}
+	if priority > 0 {
+		headers = append(headers, kafka.Header{Key: "priority", Value: []byte(strconv.Itoa(priority))})
+	}
+
 	msg := kafka.Message{
-		Key:   []byte(fmt.Sprintf("tdtp-%d", time.Now().UnixNano())),
-		Value: message,
-		Time:  time.Now(),
-		Headers: []kafka.Header{
-			{Key: "content-type", Value: []byte("application/xml")},
-			{Key: "protocol", Value: []byte("tdtp")},
-		},
+		Key:     []byte(fmt.Sprintf("tdtp-%d", time.Now().UnixNano())),
+		Value:   message,
+		Time:    time.Now(),
+		Headers: headers,
 	}
 
-	if err := k.writer.WriteMessages(ctx, msg); err != nil {
+	writer := k.writer
+	if priority >= packet.PriorityUrgent && k.urgent != nil {
+		writer = k.urgent
+	}
+	if err := writer.WriteMessages(ctx, msg); err != nil {
 		return fmt.Errorf("failed to write message to Kafka: %w", err)
 	}
 	return nil
@@ -209,6 +248,67 @@ func (k *Kafka) Ping(ctx context.Context) error {
 	return nil
 }
 
+// QueueDepth возвращает lag consumer group ConsumerGroup по Topic: сколько
+// записанных сообщений группа ещё не подтвердила. Партиция, которую группа
+// не читала, учитывается целиком.
+func (k *Kafka) QueueDepth(ctx context.Context) (int64, error) {
+	conn, err := kafka.DialContext(ctx, "tcp", k.config.Brokers[0])
+	if err != nil {
+		return 0, fmt.Errorf("failed to dial Kafka broker: %w", err)
+	}
+	partitions, err := conn.ReadPartitions(k.config.Topic)
+	_ = conn.Close()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read topic partitions: %w", err)
+	}
+
+	ids := make([]int, 0, len(partitions))
+	requests := make([]kafka.OffsetRequest, 0, 2*len(partitions))
+	for _, p := range partitions {
+		ids = append(ids, p.ID)
+		requests = append(requests, kafka.FirstOffsetOf(p.ID), kafka.LastOffsetOf(p.ID))
+	}
+
+	client := &kafka.Client{Addr: kafka.TCP(k.config.Brokers...)}
+	offsets, err := client.ListOffsets(ctx, &kafka.ListOffsetsRequest{
+		Topics: map[string][]kafka.OffsetRequest{k.config.Topic: requests},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to list offsets: %w", err)
+	}
+	committed, err := client.OffsetFetch(ctx, &kafka.OffsetFetchRequest{
+		GroupID: k.config.ConsumerGroup,
+		Topics:  map[string][]int{k.config.Topic: ids},
+	})
+	if err == nil {
+		err = committed.Error
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch offsets of group %s: %w", k.config.ConsumerGroup, err)
+	}
+
+	groupOffsets := make(map[int]int64, len(ids))
+	for _, p := range committed.Topics[k.config.Topic] {
+		if p.Error == nil && p.CommittedOffset >= 0 {
+			groupOffsets[p.Partition] = p.CommittedOffset
+		}
+	}
+	var lag int64
+	for _, p := range offsets.Topics[k.config.Topic] {
+		if p.Error != nil {
+			return 0, fmt.Errorf("failed to list offsets of partition %d: %w", p.Partition, p.Error)
+		}
+		from, ok := groupOffsets[p.Partition]
+		if !ok {
+			from = p.FirstOffset
+		}
+		if p.LastOffset > from {
+			lag += p.LastOffset - from
+		}
+	}
+	return lag, nil
+}
+
 // GetBrokerType возвращает тип брокера
 func (k *Kafka) GetBrokerType() string {
 	return "kafka"
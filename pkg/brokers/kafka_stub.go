@@ -1,4 +1,4 @@
-//go:build nokafka
+//go:build nokafka || minimal
 
 // Stub used when building without the kafka-go dependency (e.g. offline builds).
 // Provides the same Kafka type so the rest of the package compiles, but all
@@ -30,6 +30,11 @@ func (k *Kafka) Send(_ context.Context, _ []byte) error {
 	return fmt.Errorf("kafka not available")
 }
 
+// SendWithPriority always returns an error in nokafka builds.
+func (k *Kafka) SendWithPriority(_ context.Context, _ []byte, _ int) error {
+	return fmt.Errorf("kafka not available")
+}
+
 // Receive always returns an error in nokafka builds.
 func (k *Kafka) Receive(_ context.Context) ([]byte, error) {
 	return nil, fmt.Errorf("kafka not available")
@@ -51,3 +56,8 @@ func (k *Kafka) SetOffset(_ int64) error { return fmt.Errorf("kafka not availabl
 func (k *Kafka) SendBatch(_ context.Context, _ [][]byte) error {
 	return fmt.Errorf("kafka not available")
 }
+
+// QueueDepth always returns an error in nokafka builds.
+func (k *Kafka) QueueDepth(_ context.Context) (int64, error) {
+	return 0, fmt.Errorf("kafka not available")
+}
@@ -116,7 +116,7 @@ func (r *RabbitMQ) Connect(ctx context.Context) error {
 			r.config.AutoDelete, // auto-delete
 			r.config.Exclusive,  // exclusive
 			false,               // no-wait
-			nil,                 // arguments
+			r.queueArgs(),       // arguments
 		)
 		if err != nil {
 			_ = r.channel.Close()
@@ -128,6 +128,14 @@ func (r *RabbitMQ) Connect(ctx context.Context) error {
 	return nil
 }
 
+// queueArgs возвращает аргументы QueueDeclare (nil если дополнительных нет).
+func (r *RabbitMQ) queueArgs() amqp.Table {
+	if r.config.MaxPriority <= 0 {
+		return nil
+	}
+	return amqp.Table{"x-max-priority": int32(r.config.MaxPriority)}
+}
+
 // startConsuming регистрирует consumer на канале — вызывается лениво при первом Receive.
 // Разделение Connect и Consume необходимо: если вызвать Consume при отправке,
 // RabbitMQ начинает пушить unacked deliveries обратно; никто их не читает,
@@ -184,6 +192,13 @@ func (r *RabbitMQ) Close() error {
 
 // Send отправляет сообщение в RabbitMQ очередь
 func (r *RabbitMQ) Send(ctx context.Context, message []byte) error {
+	return r.SendWithPriority(ctx, message, 0)
+}
+
+// SendWithPriority отправляет сообщение с AMQP priority.
+// Приоритет учитывается брокером только для очередей с x-max-priority
+// (Config.MaxPriority); значения выше MaxPriority RabbitMQ трактует как максимум.
+func (r *RabbitMQ) SendWithPriority(ctx context.Context, message []byte, priority int) error {
 	if r.channel == nil {
 		return fmt.Errorf("not connected to RabbitMQ")
 	}
@@ -208,6 +223,7 @@ func (r *RabbitMQ) Send(ctx context.Context, message []byte) error {
 			Body:         message,
 			DeliveryMode: amqp.Persistent, // Сообщения сохраняются на диск
 			Timestamp:    time.Now(),
+			Priority:     clampAMQPPriority(priority),
 		},
 	)
 
@@ -218,6 +234,17 @@ func (r *RabbitMQ) Send(ctx context.Context, message []byte) error {
 	return nil
 }
 
+// clampAMQPPriority приводит приоритет к диапазону AMQP (0..255).
+func clampAMQPPriority(priority int) uint8 {
+	if priority < 0 {
+		return 0
+	}
+	if priority > 255 {
+		return 255
+	}
+	return uint8(priority)
+}
+
 // SendBatch отправляет несколько сообщений последовательно.
 // RabbitMQ не имеет нативного batch API, поэтому это N вызовов Send.
 func (r *RabbitMQ) SendBatch(ctx context.Context, messages [][]byte) error {
@@ -302,6 +329,26 @@ func (r *RabbitMQ) Ping(ctx context.Context) error {
 	return nil
 }
 
+// QueueDepth возвращает число сообщений, ожидающих потребителя.
+// Passive declare выполняется на отдельном канале: ошибка закрывает канал,
+// и публикация на основном не должна от этого страдать.
+func (r *RabbitMQ) QueueDepth(_ context.Context) (int64, error) {
+	if r.conn == nil {
+		return 0, fmt.Errorf("not connected to RabbitMQ")
+	}
+	ch, err := r.conn.Channel()
+	if err != nil {
+		return 0, fmt.Errorf("failed to open channel: %w", err)
+	}
+	defer func() { _ = ch.Close() }()
+
+	q, err := ch.QueueDeclarePassive(r.config.Queue, false, false, false, false, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to inspect queue '%s': %w", r.config.Queue, err)
+	}
+	return int64(q.Messages), nil
+}
+
 // GetBrokerType возвращает тип брокера
 func (r *RabbitMQ) GetBrokerType() string {
 	return "rabbitmq"
@@ -18,6 +18,7 @@ type MappingConfig struct {
 	ID          string       `yaml:"id"`
 	Version     string       `yaml:"version"`
 	ApprovedBy  string       `yaml:"approved_by,omitempty"`
+	Sensitive   bool         `yaml:"sensitive,omitempty"` // each run waits for approval (tdtpcli approval: section)
 	LoopGuard   LoopGuard    `yaml:"loop_guard"`
 	InputSource *InputSource `yaml:"input_source,omitempty"`
 	TargetConn  ConnConfig   `yaml:"target_connection"`
@@ -0,0 +1,287 @@
+package packet
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/ruslano69/tdtp-framework/pkg/crypto"
+)
+
+// Bundle (.tdtpz) — все части одной передачи таблицы в одном zip-архиве:
+//
+//	manifest.json        — BundleManifest: таблица, части, SHA-256 каждой части
+//	parts/000001.xml     — пакеты TDTP в порядке частей
+//
+// Части сжимаются deflate. С ключом (BundleOptions.Key) каждая часть
+// шифруется AES-256-GCM (crypto.EncryptSection) и хранится без сжатия —
+// шифротекст не сжимается; для сжатия зашифрованного бандла пакеты
+// сжимаются заранее (Data.Compression). Манифест не шифруется: состав
+// бандла виден без ключа, содержимое — нет. SHA-256 считается по открытому
+// XML части и проверяется при чтении.
+const (
+	BundleExt          = ".tdtpz"
+	BundleFormat       = "tdtpz"
+	BundleVersion      = 1
+	bundleManifestName = "manifest.json"
+)
+
+// BundleManifest — оглавление бандла.
+type BundleManifest struct {
+	Format      string       `json:"format"`
+	Version     int          `json:"version"`
+	Table       string       `json:"table"`
+	CreatedAt   time.Time    `json:"created_at"`
+	Compression string       `json:"compression"`          // "deflate" или "store" (зашифрованные части)
+	Encryption  string       `json:"encryption,omitempty"` // EncryptionAlgoAESGCM или ""
+	Parts       []BundlePart `json:"parts"`
+}
+
+// BundlePart — часть бандла.
+type BundlePart struct {
+	Name       string `json:"name"`
+	MessageID  string `json:"message_id"`
+	PartNumber int    `json:"part_number"`
+	Rows       int    `json:"rows"`
+	Size       int64  `json:"size"`   // байт открытого XML
+	SHA256     string `json:"sha256"` // hex SHA-256 открытого XML
+}
+
+// Rows — строк во всех частях.
+func (m *BundleManifest) Rows() int {
+	n := 0
+	for _, p := range m.Parts {
+		n += p.Rows
+	}
+	return n
+}
+
+// BundleOptions — параметры записи и чтения бандла.
+type BundleOptions struct {
+	// Key — 32-байтовый ключ AES-256 (nil — без шифрования). При чтении
+	// обязателен для зашифрованного бандла.
+	Key []byte
+}
+
+// IsBundlePath сообщает, указывает ли путь на бандл (.tdtpz).
+func IsBundlePath(path string) bool {
+	return strings.HasSuffix(strings.ToLower(path), BundleExt)
+}
+
+// BundleWriter пишет бандл потоково: каждая часть уходит в архив в Add,
+// манифест — в Close.
+type BundleWriter struct {
+	zw       *zip.Writer
+	key      []byte
+	gen      *Generator
+	manifest BundleManifest
+}
+
+// NewBundleWriter начинает бандл в w. Close обязателен.
+func NewBundleWriter(w io.Writer, opts BundleOptions) (*BundleWriter, error) {
+	bw := &BundleWriter{
+		zw:  zip.NewWriter(w),
+		gen: NewGenerator(),
+		manifest: BundleManifest{
+			Format:      BundleFormat,
+			Version:     BundleVersion,
+			CreatedAt:   time.Now().UTC(),
+			Compression: "deflate",
+		},
+	}
+	if opts.Key != nil {
+		if len(opts.Key) != 32 {
+			return nil, fmt.Errorf("bundle: key must be 32 bytes, got %d", len(opts.Key))
+		}
+		bw.key = opts.Key
+		bw.manifest.Compression = "store"
+		bw.manifest.Encryption = EncryptionAlgoAESGCM
+	}
+	return bw, nil
+}
+
+// Add добавляет пакет очередной частью бандла.
+func (bw *BundleWriter) Add(pkt *DataPacket) error {
+	data, err := bw.gen.ToXML(pkt, true)
+	if err != nil {
+		return fmt.Errorf("bundle: marshal part %d: %w", len(bw.manifest.Parts)+1, err)
+	}
+	sum := sha256.Sum256(data)
+	part := BundlePart{
+		Name:       fmt.Sprintf("parts/%06d.xml", len(bw.manifest.Parts)+1),
+		MessageID:  pkt.Header.MessageID,
+		PartNumber: pkt.Header.PartNumber,
+		Rows:       pkt.Header.RecordsInPart,
+		Size:       int64(len(data)),
+		SHA256:     hex.EncodeToString(sum[:]),
+	}
+
+	method := zip.Deflate
+	payload := data
+	if bw.key != nil {
+		encoded, err := crypto.EncryptSection(bw.key, data)
+		if err != nil {
+			return fmt.Errorf("bundle: encrypt %s: %w", part.Name, err)
+		}
+		method, payload = zip.Store, []byte(encoded)
+	}
+	w, err := bw.zw.CreateHeader(&zip.FileHeader{Name: part.Name, Method: method, Modified: bw.manifest.CreatedAt})
+	if err != nil {
+		return fmt.Errorf("bundle: %s: %w", part.Name, err)
+	}
+	if _, err := w.Write(payload); err != nil {
+		return fmt.Errorf("bundle: write %s: %w", part.Name, err)
+	}
+
+	if bw.manifest.Table == "" {
+		bw.manifest.Table = pkt.Header.TableName
+	}
+	bw.manifest.Parts = append(bw.manifest.Parts, part)
+	return nil
+}
+
+// Close записывает манифест и завершает архив. Возвращает манифест.
+func (bw *BundleWriter) Close() (*BundleManifest, error) {
+	data, err := json.MarshalIndent(bw.manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("bundle: manifest: %w", err)
+	}
+	w, err := bw.zw.CreateHeader(&zip.FileHeader{Name: bundleManifestName, Method: zip.Deflate, Modified: bw.manifest.CreatedAt})
+	if err != nil {
+		return nil, fmt.Errorf("bundle: manifest: %w", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, fmt.Errorf("bundle: manifest: %w", err)
+	}
+	if err := bw.zw.Close(); err != nil {
+		return nil, fmt.Errorf("bundle: %w", err)
+	}
+	return &bw.manifest, nil
+}
+
+// Bundle — открытый для чтения бандл.
+type Bundle struct {
+	Manifest BundleManifest
+
+	zr    *zip.ReadCloser
+	files map[string]*zip.File
+	key   []byte
+}
+
+// OpenBundle открывает бандл path и читает его манифест. Зашифрованный
+// бандл без opts.Key — ошибка до чтения частей.
+func OpenBundle(path string, opts BundleOptions) (*Bundle, error) {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, fmt.Errorf("bundle %s: %w", path, err)
+	}
+	b := &Bundle{zr: zr, files: make(map[string]*zip.File, len(zr.File)), key: opts.Key}
+	for _, f := range zr.File {
+		b.files[f.Name] = f
+	}
+	if err := b.readManifest(); err != nil {
+		_ = zr.Close()
+		return nil, fmt.Errorf("bundle %s: %w", path, err)
+	}
+	return b, nil
+}
+
+func (b *Bundle) readManifest() error {
+	data, err := b.readEntry(bundleManifestName)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, &b.Manifest); err != nil {
+		return fmt.Errorf("invalid manifest: %w", err)
+	}
+	m := &b.Manifest
+	switch {
+	case m.Format != BundleFormat:
+		return fmt.Errorf("not a %s bundle (format %q)", BundleFormat, m.Format)
+	case m.Version > BundleVersion:
+		return fmt.Errorf("bundle version %d is newer than supported %d", m.Version, BundleVersion)
+	case m.Encryption != "" && m.Encryption != EncryptionAlgoAESGCM:
+		return fmt.Errorf("unsupported bundle encryption %q", m.Encryption)
+	case m.Encryption != "" && b.key == nil:
+		return fmt.Errorf("bundle is encrypted, a key is required")
+	}
+	for _, p := range m.Parts {
+		if _, ok := b.files[p.Name]; !ok {
+			return fmt.Errorf("part %s listed in manifest is missing", p.Name)
+		}
+	}
+	return nil
+}
+
+func (b *Bundle) readEntry(name string) ([]byte, error) {
+	f, ok := b.files[name]
+	if !ok {
+		return nil, fmt.Errorf("entry %s not found", name)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("entry %s: %w", name, err)
+	}
+	defer func() { _ = rc.Close() }()
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, fmt.Errorf("entry %s: %w", name, err)
+	}
+	return data, nil
+}
+
+// ReadPart возвращает открытый XML части (расшифрованный, с проверенным
+// SHA-256).
+func (b *Bundle) ReadPart(part BundlePart) ([]byte, error) {
+	data, err := b.readEntry(part.Name)
+	if err != nil {
+		return nil, err
+	}
+	if b.Manifest.Encryption != "" {
+		if data, err = crypto.DecryptSection(b.key, string(data)); err != nil {
+			return nil, fmt.Errorf("decrypt %s: %w", part.Name, err)
+		}
+	}
+	sum := sha256.Sum256(data)
+	if int64(len(data)) != part.Size || hex.EncodeToString(sum[:]) != part.SHA256 {
+		return nil, fmt.Errorf("part %s: checksum mismatch, bundle is corrupted", part.Name)
+	}
+	return data, nil
+}
+
+// Packets разбирает все части бандла.
+func (b *Bundle) Packets() ([]*DataPacket, error) {
+	p := NewParser()
+	packets := make([]*DataPacket, 0, len(b.Manifest.Parts))
+	for _, part := range b.Manifest.Parts {
+		data, err := b.ReadPart(part)
+		if err != nil {
+			return nil, err
+		}
+		pkt, err := p.ParseBytes(data)
+		if err != nil {
+			return nil, fmt.Errorf("part %s: %w", part.Name, err)
+		}
+		packets = append(packets, pkt)
+	}
+	return packets, nil
+}
+
+// Close закрывает архив.
+func (b *Bundle) Close() error {
+	return b.zr.Close()
+}
+
+// ParseBundleKey разбирает ключ бандла: 64 hex-символа (32 байта).
+func ParseBundleKey(s string) ([]byte, error) {
+	key, err := hex.DecodeString(strings.TrimSpace(s))
+	if err != nil || len(key) != 32 {
+		return nil, fmt.Errorf("bundle key must be 64 hex characters (32 bytes)")
+	}
+	return key, nil
+}
@@ -0,0 +1,146 @@
+package packet
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// writeTestBundle пишет бандл из нескольких частей таблицы orders.
+func writeTestBundle(t *testing.T, key []byte) (string, []*DataPacket) {
+	t.Helper()
+	schema := Schema{Fields: []Field{{Name: "id", Type: "INTEGER", Key: true}, {Name: "note", Type: "TEXT"}}}
+	var rows [][]string
+	for i := 1; i <= 200; i++ {
+		rows = append(rows, []string{strconv.Itoa(i), strings.Repeat("x", 40)})
+	}
+	gen := NewGenerator()
+	gen.SetMaxMessageSize(4000)
+	packets, err := gen.GenerateReference("orders", schema, rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(packets) < 2 {
+		t.Fatalf("expected a multi-part set, got %d part(s)", len(packets))
+	}
+
+	path := filepath.Join(t.TempDir(), "orders"+BundleExt)
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bw, err := NewBundleWriter(f, BundleOptions{Key: key})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, pkt := range packets {
+		if err := bw.Add(pkt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := bw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	_ = f.Close()
+	return path, packets
+}
+
+func TestBundle_RoundTrip(t *testing.T) {
+	path, packets := writeTestBundle(t, nil)
+
+	b, err := OpenBundle(path, BundleOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = b.Close() }()
+	if b.Manifest.Table != "orders" || len(b.Manifest.Parts) != len(packets) || b.Manifest.Rows() != 200 {
+		t.Errorf("manifest = %+v", b.Manifest)
+	}
+	got, err := b.Packets()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows := 0
+	for i, pkt := range got {
+		if pkt.Header.MessageID != packets[i].Header.MessageID {
+			t.Errorf("part %d: message id %s, want %s", i+1, pkt.Header.MessageID, packets[i].Header.MessageID)
+		}
+		rows += len(pkt.Data.Rows)
+	}
+	if rows != 200 {
+		t.Errorf("rows = %d, want 200", rows)
+	}
+}
+
+func TestBundle_Encrypted(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, 32)
+	path, packets := writeTestBundle(t, key)
+
+	raw, _ := os.ReadFile(path)
+	if bytes.Contains(raw, []byte("xxxxxxxxxx")) {
+		t.Error("encrypted bundle contains plaintext rows")
+	}
+	if _, err := OpenBundle(path, BundleOptions{}); err == nil {
+		t.Error("encrypted bundle opened without a key")
+	}
+
+	b, err := OpenBundle(path, BundleOptions{Key: key})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = b.Close() }()
+	got, err := b.Packets()
+	if err != nil || len(got) != len(packets) {
+		t.Fatalf("Packets() = %d, %v", len(got), err)
+	}
+
+	wrong, _ := OpenBundle(path, BundleOptions{Key: bytes.Repeat([]byte{8}, 32)})
+	defer func() { _ = wrong.Close() }()
+	if _, err := wrong.Packets(); err == nil {
+		t.Error("bundle decrypted with a wrong key")
+	}
+}
+
+// Подменённая часть не проходит проверку SHA-256 манифеста.
+func TestBundle_Tampered(t *testing.T) {
+	path, _ := writeTestBundle(t, nil)
+	b, err := OpenBundle(path, BundleOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifest := b.Manifest
+	_ = b.Close()
+
+	zr, _ := zip.OpenReader(path)
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, f := range zr.File {
+		w, _ := zw.Create(f.Name)
+		rc, _ := f.Open()
+		data := new(bytes.Buffer)
+		_, _ = data.ReadFrom(rc)
+		_ = rc.Close()
+		if f.Name == manifest.Parts[0].Name {
+			data = bytes.NewBuffer(bytes.Replace(data.Bytes(), []byte("xxxx"), []byte("yyyy"), 1))
+		}
+		_, _ = w.Write(data.Bytes())
+	}
+	_ = zw.Close()
+	_ = zr.Close()
+	if err := os.WriteFile(path, buf.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err = OpenBundle(path, BundleOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = b.Close() }()
+	if _, err := b.Packets(); err == nil || !strings.Contains(err.Error(), "checksum") {
+		t.Errorf("expected checksum error, got %v", err)
+	}
+}
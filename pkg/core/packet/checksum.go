@@ -0,0 +1,126 @@
+package packet
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"strings"
+)
+
+// Контрольные суммы строк (Header.Checksum): SHA-256 канонизированных
+// строк пакета и, по желанию, короткий хеш каждой строки. Считаются по
+// значениям полей, а не по тексту <R>, поэтому не зависят от экранирования,
+// compact/columnar-представления и сжатия: пакет, прошедший брокер или файл
+// в любом из этих видов, проверяется после распаковки до записи в БД.
+//
+//	<Header>
+//	  ...
+//	  <Checksum algo="sha256" value="9f2c…">0a1b2c3d4e5f6071 …</Checksum>
+//	</Header>
+//
+// В отличие от XXH3 v1.4 (ComputeIntegrity) сумма не солится MessageID и
+// не регистрируется в xZMercury — это защита от порчи, а не от подмены.
+
+// ChecksumAlgoSHA256 — единственный поддерживаемый алгоритм Header.Checksum.
+const ChecksumAlgoSHA256 = "sha256"
+
+// Режимы Generator.SetRowChecksum.
+const (
+	ChecksumOff    = ""       // без контрольных сумм (по умолчанию)
+	ChecksumPacket = "packet" // сумма пакета
+	ChecksumRows   = "rows"   // сумма пакета и хеш каждой строки
+)
+
+// rowHashLen — байт SHA-256 строки в Checksum.Rows: достаточно, чтобы
+// указать испорченную строку, и в 4 раза короче полного хеша.
+const rowHashLen = 8
+
+// ErrChecksumMismatch — строки пакета не совпадают с Header.Checksum.
+var ErrChecksumMismatch = errors.New("row checksum mismatch")
+
+// RowChecksum — Header.Checksum.
+type RowChecksum struct {
+	Algo  string `xml:"algo,attr"     json:"algo"`
+	Value string `xml:"value,attr"    json:"value"`          // hex SHA-256 всех строк
+	Rows  string `xml:",chardata"     json:"rows,omitempty"` // hex-хеши строк через пробел (ChecksumRows)
+}
+
+// StampRowChecksum записывает в pkt.Header.Checksum сумму текущих строк
+// (perRow — и хеши строк). Строки должны быть развёрнуты: сжатый или
+// зашифрованный пакет — ошибка.
+func StampRowChecksum(pkt *DataPacket, perRow bool) error {
+	if pkt.Data.opaqueRows() {
+		return fmt.Errorf("packet %s: row checksum requires uncompressed, unencrypted rows", pkt.Header.MessageID)
+	}
+	sum, rows := rowChecksums(pkt.GetRows(), perRow)
+	pkt.Header.Checksum = &RowChecksum{Algo: ChecksumAlgoSHA256, Value: sum}
+	if perRow {
+		pkt.Header.Checksum.Rows = strings.Join(rows, " ")
+	}
+	return nil
+}
+
+// VerifyRowChecksum сверяет строки пакета с Header.Checksum. Пакет без
+// суммы проходит; строки должны быть развёрнуты (DecompressPacketData,
+// DecryptSections). Расхождение — ErrChecksumMismatch с номером первой
+// испорченной строки, если в пакете есть хеши строк.
+func VerifyRowChecksum(pkt *DataPacket) error {
+	cs := pkt.Header.Checksum
+	if cs == nil {
+		return nil
+	}
+	if cs.Algo != ChecksumAlgoSHA256 {
+		return fmt.Errorf("packet %s: unsupported checksum algorithm %q", pkt.Header.MessageID, cs.Algo)
+	}
+	if pkt.Data.opaqueRows() {
+		return fmt.Errorf("packet %s: row checksum requires uncompressed, unencrypted rows", pkt.Header.MessageID)
+	}
+
+	want := strings.Fields(cs.Rows)
+	sum, rows := rowChecksums(pkt.GetRows(), len(want) > 0)
+	if sum == cs.Value {
+		return nil
+	}
+	if len(want) > 0 && len(want) != len(rows) {
+		return fmt.Errorf("packet %s: %w: %d rows, checksum covers %d", pkt.Header.MessageID, ErrChecksumMismatch, len(rows), len(want))
+	}
+	for i := range want {
+		if want[i] != rows[i] {
+			return fmt.Errorf("packet %s: %w at row %d", pkt.Header.MessageID, ErrChecksumMismatch, i+1)
+		}
+	}
+	return fmt.Errorf("packet %s: %w", pkt.Header.MessageID, ErrChecksumMismatch)
+}
+
+// rowChecksums — SHA-256 канонического вида строк и (perRow) усечённые
+// хеши каждой строки. Канонический вид строки: число значений и каждое
+// значение с префиксом длины (uvarint) — границы полей однозначны без
+// экранирования.
+func rowChecksums(rows [][]string, perRow bool) (string, []string) {
+	total := sha256.New()
+	var row hash.Hash
+	var hashes []string
+	if perRow {
+		row = sha256.New()
+		hashes = make([]string, 0, len(rows))
+	}
+
+	var buf []byte
+	for _, values := range rows {
+		buf = binary.AppendUvarint(buf[:0], uint64(len(values)))
+		for _, v := range values {
+			buf = binary.AppendUvarint(buf, uint64(len(v)))
+			buf = append(buf, v...)
+		}
+		total.Write(buf)
+		if perRow {
+			row.Reset()
+			row.Write(buf)
+			hashes = append(hashes, hex.EncodeToString(row.Sum(nil)[:rowHashLen]))
+		}
+	}
+	return hex.EncodeToString(total.Sum(nil)), hashes
+}
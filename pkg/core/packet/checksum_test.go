@@ -0,0 +1,100 @@
+package packet
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+var checksumSchema = Schema{Fields: []Field{{Name: "id", Type: "INTEGER", Key: true}, {Name: "name", Type: "TEXT"}}}
+
+// TestRowChecksum_RoundTrip — сумма, поставленная генератором, проходит
+// проверку при разборе; испорченная строка находится по хешам строк.
+func TestRowChecksum_RoundTrip(t *testing.T) {
+	rows := [][]string{{"1", "Alice"}, {"2", "Bobby"}, {"3", ""}}
+	gen := NewGenerator()
+	if err := gen.SetRowChecksum(ChecksumRows); err != nil {
+		t.Fatal(err)
+	}
+	packets, err := gen.GenerateReference("users", checksumSchema, rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs := packets[0].Header.Checksum
+	if cs == nil || cs.Algo != ChecksumAlgoSHA256 || len(cs.Value) != 64 || len(strings.Fields(cs.Rows)) != len(rows) {
+		t.Fatalf("checksum = %+v", cs)
+	}
+
+	xmlData, err := gen.ToXML(packets[0], false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewParser().ParseBytes(xmlData); err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	corrupted := strings.Replace(string(xmlData), "Bobby", "Bobbi", 1)
+	_, err = NewParser().ParseBytes([]byte(corrupted))
+	if !errors.Is(err, ErrChecksumMismatch) || !strings.Contains(err.Error(), "row 2") {
+		t.Errorf("err = %v, want ErrChecksumMismatch at row 2", err)
+	}
+}
+
+// TestRowChecksum_FieldBoundaries — перенос символа между полями меняет сумму.
+func TestRowChecksum_FieldBoundaries(t *testing.T) {
+	a, _ := rowChecksums([][]string{{"ab", "c"}}, false)
+	b, _ := rowChecksums([][]string{{"a", "bc"}}, false)
+	if a == b {
+		t.Error("checksum does not depend on field boundaries")
+	}
+}
+
+// TestRowChecksum_Compressed — сумма считается до сжатия и проверяется
+// после DecompressPacketData.
+func TestRowChecksum_Compressed(t *testing.T) {
+	RegisterCompressor("mock", mockCompressor)
+	prev := codecs.decompressor
+	SetDecompressor(mockDecompressor)
+	t.Cleanup(func() { SetDecompressor(prev) })
+
+	gen := NewGenerator()
+	gen.SetCompression(CompressionOptions{Enabled: true, Algorithm: "mock"})
+	_ = gen.SetRowChecksum(ChecksumPacket)
+	packets, err := gen.GenerateReference("users", checksumSchema, [][]string{{"1", "Alice"}, {"2", "Bob"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkt := packets[0]
+	if pkt.Header.Checksum == nil || pkt.Header.Checksum.Rows != "" {
+		t.Fatalf("checksum = %+v", pkt.Header.Checksum)
+	}
+	if err := VerifyRowChecksum(pkt); err == nil {
+		t.Error("expected error verifying compressed rows")
+	}
+	if err := DecompressPacketData(context.Background(), pkt); err != nil {
+		t.Fatalf("decompress: %v", err)
+	}
+
+	pkt.Header.Checksum.Value = strings.Repeat("0", 64)
+	if err := VerifyRowChecksum(pkt); !errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("err = %v, want ErrChecksumMismatch", err)
+	}
+}
+
+// TestRowChecksum_SetRowsClears — замена строк снимает устаревшую сумму.
+func TestRowChecksum_SetRowsClears(t *testing.T) {
+	pkt := NewDataPacket(TypeReference, "users")
+	pkt.SetRows([][]string{{"1", "Alice"}})
+	if err := StampRowChecksum(pkt, false); err != nil {
+		t.Fatal(err)
+	}
+	pkt.SetRows([][]string{{"1", "Alicia"}})
+	if pkt.Header.Checksum != nil {
+		t.Error("SetRows kept a stale checksum")
+	}
+
+	if err := NewGenerator().SetRowChecksum("md5"); err == nil {
+		t.Error("expected error for unknown mode")
+	}
+}
@@ -0,0 +1,126 @@
+package packet
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/zeebo/xxh3"
+)
+
+// Compressor сжимает pipe-строки данных пакета в одну строку <R>.
+type Compressor func(ctx context.Context, rows []string, level int) (string, error)
+
+// Decompressor распаковывает данные, сжатые алгоритмом algo.
+type Decompressor func(ctx context.Context, compressed string, algo string) ([]string, error)
+
+// codecs — реализации алгоритмов сжатия (регистрируются пакетом processors —
+// core/packet не зависит от zstd/kanzi).
+var codecs = struct {
+	sync.RWMutex
+	compressors  map[string]Compressor
+	decompressor Decompressor
+}{
+	compressors: make(map[string]Compressor),
+}
+
+// RegisterCompressor регистрирует реализацию алгоритма сжатия algo.
+func RegisterCompressor(algo string, fn Compressor) {
+	codecs.Lock()
+	defer codecs.Unlock()
+	codecs.compressors[algo] = fn
+}
+
+// SetDecompressor устанавливает распаковщик, используемый DecompressPacketData.
+func SetDecompressor(fn Decompressor) {
+	codecs.Lock()
+	defer codecs.Unlock()
+	codecs.decompressor = fn
+}
+
+// CompressPacketData сжимает данные пакета согласно opts: все строки
+// упаковываются в одну <R>, в Data выставляются Compression и Checksum
+// (xxh3_64 сжатой строки). Ничего не делает, если сжатие выключено, данные
+// уже сжаты или зашифрованы, либо их объём меньше opts.MinSize.
+func CompressPacketData(ctx context.Context, pkt *DataPacket, opts CompressionOptions) error {
+	if !opts.Enabled || pkt.Data.Compression != "" || pkt.Data.Encryption != "" {
+		return nil
+	}
+	pkt.MaterializeRows()
+	if len(pkt.Data.Rows) == 0 {
+		return nil
+	}
+
+	rows := make([]string, len(pkt.Data.Rows))
+	totalSize := 0
+	for i, row := range pkt.Data.Rows {
+		rows[i] = row.Value
+		totalSize += len(row.Value)
+	}
+	if totalSize < opts.MinSize {
+		return nil
+	}
+
+	algo := opts.Algorithm
+	if algo == "" {
+		algo = "zstd"
+	}
+	codecs.RLock()
+	compressor := codecs.compressors[algo]
+	codecs.RUnlock()
+	if compressor == nil {
+		return fmt.Errorf("no compressor registered for %q (import pkg/processors)", algo)
+	}
+
+	compressed, err := compressor(ctx, rows, opts.Level)
+	if err != nil {
+		return fmt.Errorf("compression failed: %w", err)
+	}
+
+	pkt.Data.Compression = algo
+	pkt.Data.Checksum = dataChecksum(compressed)
+	pkt.Data.Rows = []Row{{Value: compressed}}
+	return nil
+}
+
+// DecompressPacketData распаковывает сжатые данные пакета: проверяет Checksum,
+// регистрирует встроенный словарь, восстанавливает строки, разворачивает
+// compact-формат и сверяет Header.Checksum. Для несжатого пакета ничего не
+// делает.
+func DecompressPacketData(ctx context.Context, pkt *DataPacket) error {
+	if pkt.Data.Compression == "" {
+		return nil
+	}
+	codecs.RLock()
+	decompressor := codecs.decompressor
+	codecs.RUnlock()
+	if decompressor == nil {
+		return fmt.Errorf("no decompressor registered for %q (import pkg/processors)", pkt.Data.Compression)
+	}
+
+	if pkt.Data.Checksum != "" && len(pkt.Data.Rows) == 1 {
+		if actual := dataChecksum(pkt.Data.Rows[0].Value); actual != pkt.Data.Checksum {
+			return fmt.Errorf("data corruption detected: checksum expected %s, got %s", pkt.Data.Checksum, actual)
+		}
+	}
+
+	if err := NewParser().DecompressData(ctx, pkt, decompressor); err != nil {
+		return err
+	}
+	pkt.Data.Checksum = ""
+
+	if declared := pkt.Header.RecordsInPart; declared > 0 && NeedsRowCountCheck(pkt.Version) && declared != len(pkt.Data.Rows) {
+		return fmt.Errorf("RecordsInPart mismatch after decompression: header declares %d rows, got %d",
+			declared, len(pkt.Data.Rows))
+	}
+
+	if err := ExpandCompactRows(pkt); err != nil {
+		return fmt.Errorf("compact expansion failed: %w", err)
+	}
+	return verifyParsedChecksum(pkt)
+}
+
+// dataChecksum — xxh3_64 сжатой строки в hex (формат Data.Checksum).
+func dataChecksum(s string) string {
+	return fmt.Sprintf("%016x", xxh3.HashString(s))
+}
@@ -0,0 +1,319 @@
+package packet
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+// LayoutColumnar — колоночный формат Data: вместо pipe-строк
+//
+//	<R>1|Иванов|a\|b</R>
+//
+// каждое поле пишется отдельной колонкой
+//
+//	<C name="id"><V>1</V></C><C name="name"><V>Иванов</V></C><C name="note"><V>a|b</V></C>
+//
+// Значения не требуют pipe-экранирования (только XML), а читатель может
+// разобрать лишь нужные колонки (Parser.ParseColumns). В памяти пакет
+// по-прежнему хранит строки: Layout влияет только на сериализацию, Parser
+// разворачивает колонки в Data.Rows, поэтому GetRows и импорт адаптеров
+// работают без изменений.
+//
+// Несовместим со сжатием, шифрованием, compact, delta и delete — эти
+// форматы работают с pipe-строками; при сжатии/шифровании writer
+// записывает строки <R> и не ставит атрибут layout.
+const LayoutColumnar = "columnar"
+
+var (
+	bTagVOpen  = []byte("<V>")
+	bTagVClose = []byte("</V>")
+	bTagCClose = []byte("</C>")
+	bEscCR     = []byte("&#xD;")
+)
+
+// writesColumnar сообщает, будут ли данные пакета записаны колонками.
+func writesColumnar(packet *DataPacket) bool {
+	return packet.Data.Layout == LayoutColumnar &&
+		packet.Data.Compression == "" &&
+		packet.Data.Encryption == "" &&
+		len(packet.Schema.Fields) > 0
+}
+
+// writeColumnarData пишет данные пакета колонками <C> в порядке полей схемы.
+func writeColumnarData(w *bufio.Writer, packet *DataPacket) {
+	rows := packet.GetRows()
+	for j, field := range packet.Schema.Fields {
+		w.WriteString(`<C`)
+		writeXMLAttr(w, "name", field.Name)
+		w.WriteByte('>')
+		for _, row := range rows {
+			w.Write(bTagVOpen)
+			if j < len(row) {
+				writeColumnValue(w, row[j])
+			}
+			w.Write(bTagVClose)
+		}
+		w.Write(bTagCClose)
+	}
+}
+
+// writeColumnValue пишет значение колонки с XML-экранированием (<>&).
+// CR экранируется ссылкой на символ: XML-парсер нормализует литеральные
+// \r\n и \r в \n, и значение не пережило бы round-trip.
+func writeColumnValue(w *bufio.Writer, s string) {
+	start := 0
+	for i := 0; i < len(s); i++ {
+		var esc []byte
+		switch s[i] {
+		case '<':
+			esc = bEscLt
+		case '>':
+			esc = bEscGt
+		case '&':
+			esc = bEscAmp
+		case '\r':
+			esc = bEscCR
+		default:
+			continue
+		}
+		w.WriteString(s[start:i])
+		w.Write(esc)
+		start = i + 1
+	}
+	w.WriteString(s[start:])
+}
+
+// columnarRowCount возвращает число строк в колоночных данных
+func columnarRowCount(data *Data) int {
+	if len(data.Columns) == 0 {
+		return 0
+	}
+	return len(data.Columns[0].Values)
+}
+
+// columnsToRows собирает строки из колонок в порядке fields. Все колонки
+// должны иметь одинаковое число значений.
+func columnsToRows(columns []Column, fields []string) ([][]string, error) {
+	byName := make(map[string]*Column, len(columns))
+	for i := range columns {
+		col := &columns[i]
+		if _, dup := byName[col.Name]; dup {
+			return nil, fmt.Errorf("duplicate column %q", col.Name)
+		}
+		if len(col.Values) != len(columns[0].Values) {
+			return nil, fmt.Errorf("column %q has %d values, column %q has %d",
+				col.Name, len(col.Values), columns[0].Name, len(columns[0].Values))
+		}
+		byName[col.Name] = col
+	}
+
+	ordered := make([]*Column, len(fields))
+	for j, name := range fields {
+		col, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("column %q not found in data", name)
+		}
+		ordered[j] = col
+	}
+
+	rows := make([][]string, columnarRowCount(&Data{Columns: columns}))
+	for i := range rows {
+		row := make([]string, len(ordered))
+		for j, col := range ordered {
+			row[j] = col.Values[i]
+		}
+		rows[i] = row
+	}
+	return rows, nil
+}
+
+// ExpandColumnarRows разворачивает колоночный формат в pipe-строки Data.Rows
+// в порядке полей схемы. Колонка на каждое поле обязательна, лишние колонки —
+// ошибка. После вызова Layout сброшен, Columns пуст.
+// Если Layout != LayoutColumnar — функция ничего не делает.
+func ExpandColumnarRows(packet *DataPacket) error {
+	if packet.Data.Layout != LayoutColumnar {
+		return nil
+	}
+
+	if len(packet.Data.Columns) > 0 {
+		if len(packet.Data.Columns) != len(packet.Schema.Fields) {
+			return fmt.Errorf("data has %d columns, schema has %d fields",
+				len(packet.Data.Columns), len(packet.Schema.Fields))
+		}
+		fields := make([]string, len(packet.Schema.Fields))
+		for i, f := range packet.Schema.Fields {
+			fields[i] = f.Name
+		}
+		rows, err := columnsToRows(packet.Data.Columns, fields)
+		if err != nil {
+			return err
+		}
+		packet.Data.Rows = RowsToData(rows).Rows
+	}
+
+	packet.Data.Layout = ""
+	packet.Data.Columns = nil
+	return nil
+}
+
+// projectedPacket — DataPacket, у которого Data разбирается с фильтром
+// колонок. Поле Data внешней структуры перекрывает вложенное (правила
+// встраивания encoding/xml).
+type projectedPacket struct {
+	DataPacket
+	Data projectedData `xml:"Data"`
+}
+
+// projectedData декодирует <Data>, пропуская колонки не из want без
+// разбора их значений.
+type projectedData struct {
+	Data
+	want map[string]bool
+}
+
+// UnmarshalXML реализует xml.Unmarshaler
+func (pd *projectedData) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	// Атрибуты <Data> разбирает штатный декодер на пустом элементе —
+	// список атрибутов не дублируется здесь.
+	var buf bytes.Buffer
+	enc := xml.NewEncoder(&buf)
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+	if err := enc.EncodeToken(start.End()); err != nil {
+		return err
+	}
+	if err := enc.Flush(); err != nil {
+		return err
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &pd.Data); err != nil {
+		return err
+	}
+
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "C":
+				if !pd.want[columnName(t)] {
+					if err := d.Skip(); err != nil {
+						return err
+					}
+					continue
+				}
+				var col Column
+				if err := d.DecodeElement(&col, &t); err != nil {
+					return err
+				}
+				pd.Columns = append(pd.Columns, col)
+			case "R":
+				var row Row
+				if err := d.DecodeElement(&row, &t); err != nil {
+					return err
+				}
+				pd.Rows = append(pd.Rows, row)
+			case "CompressionDict":
+				if err := d.DecodeElement(&pd.ZstdDict, &t); err != nil {
+					return err
+				}
+			default:
+				if err := d.Skip(); err != nil {
+					return err
+				}
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+func columnName(start xml.StartElement) string {
+	for _, a := range start.Attr {
+		if a.Name.Local == "name" {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// ParseColumns читает из пакета только поля fields (в указанном порядке):
+// схема и строки результата содержат только их. В колоночном пакете
+// остальные колонки пропускаются без разбора значений; построчный пакет
+// разбирается целиком и проецируется. Сжатые и зашифрованные данные не
+// поддерживаются — используйте Parse с распаковкой/расшифровкой.
+func (p *Parser) ParseColumns(r io.Reader, fields ...string) (*DataPacket, error) {
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("no fields requested")
+	}
+	want := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		want[f] = true
+	}
+
+	pp := projectedPacket{Data: projectedData{want: want}}
+	if err := xml.NewDecoder(r).Decode(&pp); err != nil {
+		return nil, fmt.Errorf("failed to decode XML: %w", err)
+	}
+	packet := pp.DataPacket
+	packet.Data = pp.Data.Data
+
+	if err := p.validatePacket(&packet); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+	if packet.Data.Compression != "" || packet.Data.Encryption != "" {
+		return nil, fmt.Errorf("column read of compressed or encrypted data is not supported")
+	}
+
+	projected := make([]Field, len(fields))
+	indices := make([]int, len(fields))
+	for j, name := range fields {
+		indices[j] = -1
+		for i, f := range packet.Schema.Fields {
+			if f.Name == name {
+				projected[j], indices[j] = f, i
+				break
+			}
+		}
+		if indices[j] < 0 {
+			return nil, fmt.Errorf("field %q not found in schema", name)
+		}
+	}
+
+	var rows [][]string
+	if packet.Data.Layout == LayoutColumnar {
+		var err error
+		if rows, err = columnsToRows(packet.Data.Columns, fields); err != nil {
+			return nil, fmt.Errorf("columnar data: %w", err)
+		}
+	} else {
+		if err := ExpandCompactRows(&packet); err != nil {
+			return nil, fmt.Errorf("compact expansion failed: %w", err)
+		}
+		rows = make([][]string, len(packet.Data.Rows))
+		for i, row := range packet.Data.Rows {
+			values := p.GetRowValues(row)
+			projectedRow := make([]string, len(indices))
+			for j, idx := range indices {
+				if idx < len(values) {
+					projectedRow[j] = values[idx]
+				}
+			}
+			rows[i] = projectedRow
+		}
+	}
+
+	// Хеши целостности относятся к полному пакету и к проекции неприменимы
+	packet.XXH3 = ""
+	packet.Schema.XXH3 = ""
+	packet.Schema.Fields = projected
+	packet.Data = RowsToData(rows)
+	return &packet, nil
+}
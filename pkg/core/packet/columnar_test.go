@@ -0,0 +1,180 @@
+package packet
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func columnarTestData() (Schema, [][]string) {
+	schema := Schema{Fields: []Field{
+		{Name: "id", Type: "INTEGER", Key: true},
+		{Name: "name", Type: "TEXT"},
+		{Name: "note", Type: "TEXT"},
+	}}
+	rows := [][]string{
+		{"1", "Иванов", `a|b\c`},
+		{"2", "<Tom & Jerry>", "line1\r\nline2"},
+		{"3", "", ""},
+	}
+	return schema, rows
+}
+
+func TestColumnar_XMLRoundTrip(t *testing.T) {
+	schema, rows := columnarTestData()
+	gen := NewGenerator()
+	gen.SetSkipSpecialValues(true)
+	if err := gen.SetDataLayout(LayoutColumnar); err != nil {
+		t.Fatal(err)
+	}
+
+	packets, err := gen.GenerateReference("users", schema, rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	xmlData, err := gen.ToXML(packets[0], false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := string(xmlData)
+	if !strings.Contains(s, `layout="columnar"`) || strings.Contains(s, "<R>") {
+		t.Fatalf("columnar layout not written: %s", s)
+	}
+	if !strings.Contains(s, `<C name="note"><V>a|b\c</V>`) {
+		t.Errorf("columnar values must not be pipe-escaped: %s", s)
+	}
+
+	for name, parse := range map[string]func([]byte) (*DataPacket, error){
+		"Parse":      func(b []byte) (*DataPacket, error) { return NewParser().Parse(bytes.NewReader(b)) },
+		"ParseBytes": NewParser().ParseBytes,
+	} {
+		parsed, err := parse(xmlData)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if parsed.Data.Layout != "" || parsed.Data.Columns != nil {
+			t.Errorf("%s: columns not expanded: %+v", name, parsed.Data)
+		}
+		if got := parsed.GetRows(); !reflect.DeepEqual(got, rows) {
+			t.Errorf("%s: rows = %q, want %q", name, got, rows)
+		}
+	}
+}
+
+func TestColumnar_ResponseAndMaterialize(t *testing.T) {
+	schema, rows := columnarTestData()
+	gen := NewGenerator()
+	if err := gen.SetDataLayout(LayoutColumnar); err != nil {
+		t.Fatal(err)
+	}
+
+	packets, err := gen.GenerateResponse("users", "REQ-1", schema, rows, nil, "a", "b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	packets[0].MaterializeRows()
+	xmlData, err := gen.ToXML(packets[0], false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(xmlData), `layout="columnar"`) {
+		t.Fatal("response packet not written in columnar layout")
+	}
+	parsed, err := NewParser().ParseBytes(xmlData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := parsed.GetRows(); !reflect.DeepEqual(got, rows) {
+		t.Errorf("rows = %q, want %q", got, rows)
+	}
+}
+
+func TestColumnar_CompressedFallsBackToRows(t *testing.T) {
+	schema, rows := columnarTestData()
+	pkt := NewDataPacket(TypeReference, "users")
+	pkt.Schema = schema
+	pkt.Data = RowsToData(rows)
+	pkt.Data.Layout = LayoutColumnar
+	pkt.Data.Compression = "zstd"
+	pkt.Data.Rows = []Row{{Value: "opaque"}}
+
+	xmlData, err := NewGenerator().ToXML(pkt, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := string(xmlData); strings.Contains(s, "layout=") || !strings.Contains(s, "<R>opaque</R>") {
+		t.Errorf("compressed data must be written as rows: %s", s)
+	}
+}
+
+func TestParseColumns(t *testing.T) {
+	schema, rows := columnarTestData()
+	for _, layout := range []string{"", LayoutColumnar} {
+		gen := NewGenerator()
+		gen.SetSkipSpecialValues(true)
+		if err := gen.SetDataLayout(layout); err != nil {
+			t.Fatal(err)
+		}
+		packets, err := gen.GenerateReference("users", schema, rows)
+		if err != nil {
+			t.Fatal(err)
+		}
+		xmlData, err := gen.ToXML(packets[0], false)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		parsed, err := NewParser().ParseColumns(bytes.NewReader(xmlData), "note", "id")
+		if err != nil {
+			t.Fatalf("layout %q: %v", layout, err)
+		}
+		if len(parsed.Schema.Fields) != 2 || parsed.Schema.Fields[0].Name != "note" || !parsed.Schema.Fields[1].Key {
+			t.Errorf("layout %q: schema = %+v", layout, parsed.Schema.Fields)
+		}
+		want := [][]string{{`a|b\c`, "1"}, {"line1\r\nline2", "2"}, {"", "3"}}
+		if layout == "" {
+			want[1][0] = "line1\nline2" // <R> не экранирует CR — XML-парсер нормализует \r\n
+		}
+		if got := parsed.GetRows(); !reflect.DeepEqual(got, want) {
+			t.Errorf("layout %q: rows = %q, want %q", layout, got, want)
+		}
+
+		if _, err := NewParser().ParseColumns(bytes.NewReader(xmlData), "missing"); err == nil {
+			t.Errorf("layout %q: expected error for unknown field", layout)
+		}
+	}
+}
+
+func TestColumnar_Validation(t *testing.T) {
+	if err := NewGenerator().SetDataLayout("tabular"); err == nil {
+		t.Error("expected error for unknown layout")
+	}
+
+	const head = `<?xml version="1.0"?><DataPacket protocol="TDTP" version="1.0">` +
+		`<Header><Type>reference</Type><TableName>t</TableName><MessageID>m</MessageID>` +
+		`<Timestamp>2024-01-01T00:00:00Z</Timestamp></Header>` +
+		`<Schema><Field name="id" type="INTEGER"></Field><Field name="name" type="TEXT"></Field></Schema>`
+	valid := `<Data layout="columnar"><C name="name"><V>a</V></C><C name="id"><V>1</V></C></Data>`
+	parsed, err := NewParser().ParseBytes([]byte(head + valid + `</DataPacket>`))
+	if err != nil {
+		t.Fatalf("valid packet: %v", err)
+	}
+	if got := parsed.GetRows(); !reflect.DeepEqual(got, [][]string{{"1", "a"}}) {
+		t.Errorf("columns must follow schema order, got %q", got)
+	}
+
+	cases := map[string]string{
+		"unknown layout":  `<Data layout="rowwise"></Data>`,
+		"with compact":    `<Data layout="columnar" compact="true"><C name="id"><V>1</V></C><C name="name"><V>a</V></C></Data>`,
+		"ragged columns":  `<Data layout="columnar"><C name="id"><V>1</V><V>2</V></C><C name="name"><V>a</V></C></Data>`,
+		"missing column":  `<Data layout="columnar"><C name="id"><V>1</V></C></Data>`,
+		"unknown column":  `<Data layout="columnar"><C name="id"><V>1</V></C><C name="email"><V>a</V></C></Data>`,
+		"rows and layout": `<Data layout="columnar"><R>1|a</R></Data>`,
+	}
+	for name, data := range cases {
+		if _, err := NewParser().ParseBytes([]byte(head + data + `</DataPacket>`)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
@@ -412,6 +412,90 @@ func TestRoundTripWithCompression(t *testing.T) {
 	}
 }
 
+// TestGeneratorCompressesPackets — пакеты генератора со включённым сжатием
+// сжимаются зарегистрированным компрессором и распаковываются DecompressPacketData.
+func TestGeneratorCompressesPackets(t *testing.T) {
+	RegisterCompressor("mock", mockCompressor)
+	prev := codecs.decompressor
+	SetDecompressor(mockDecompressor)
+	t.Cleanup(func() { SetDecompressor(prev) })
+
+	rows := [][]string{{"1", "Alice"}, {"2", "Bob|Jr"}, {"3", ""}}
+	schema := Schema{Fields: []Field{{Name: "id", Type: "INTEGER", Key: true}, {Name: "name", Type: "TEXT"}}}
+
+	gen := NewGenerator()
+	gen.SetCompression(CompressionOptions{Enabled: true, Algorithm: "mock"})
+	packets, err := gen.GenerateReference("users", schema, rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkt := packets[0]
+	if pkt.Data.Compression != "mock" || len(pkt.Data.Rows) != 1 || pkt.Data.Checksum == "" {
+		t.Fatalf("packet not compressed: %+v", pkt.Data)
+	}
+
+	xmlData, err := gen.ToXML(pkt, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := NewParser().ParseBytes(xmlData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := DecompressPacketData(context.Background(), parsed); err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Data.Compression != "" || parsed.Data.Checksum != "" {
+		t.Errorf("compression attributes not cleared: %+v", parsed.Data)
+	}
+	got := parsed.GetRows()
+	if len(got) != len(rows) || got[1][1] != "Bob|Jr" {
+		t.Errorf("rows = %q, want %q", got, rows)
+	}
+
+	// Повреждённые данные отклоняются по Checksum
+	parsed, _ = NewParser().ParseBytes(xmlData)
+	parsed.Data.Rows[0].Value += "x"
+	if err := DecompressPacketData(context.Background(), parsed); err == nil {
+		t.Error("expected checksum error")
+	}
+
+	// Алгоритм без реализации — ошибка генерации
+	gen.SetCompression(CompressionOptions{Enabled: true, Algorithm: "lz4"})
+	if _, err := gen.GenerateReference("users", schema, rows); err == nil {
+		t.Error("expected error for unregistered algorithm")
+	}
+}
+
+// TestWriteCompressesUncompressedPacket — ToXML/WriteToWriter сжимают пакет,
+// собранный не этим генератором (merge --compress).
+func TestWriteCompressesUncompressedPacket(t *testing.T) {
+	RegisterCompressor("mock", mockCompressor)
+
+	pkt := NewDataPacket(TypeReference, "users")
+	pkt.Schema = Schema{Fields: []Field{{Name: "id", Type: "INTEGER"}}}
+	pkt.Data = RowsToData([][]string{{"1"}, {"2"}})
+
+	gen := NewGenerator()
+	gen.SetCompression(CompressionOptions{Enabled: true, Algorithm: "mock", MinSize: 1024})
+	xmlData, err := gen.ToXML(pkt, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(xmlData), "compression=") {
+		t.Error("data below MinSize must not be compressed")
+	}
+
+	gen.SetCompression(CompressionOptions{Enabled: true, Algorithm: "mock"})
+	var buf strings.Builder
+	if err := gen.WriteToWriter(pkt, &buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `compression="mock"`) {
+		t.Errorf("packet not compressed on write: %s", buf.String())
+	}
+}
+
 // BenchmarkRowsToDataWithCompression бенчмарк генерации данных со сжатием
 func BenchmarkRowsToDataWithCompression(b *testing.B) {
 	gen := NewGenerator()
@@ -0,0 +1,68 @@
+package packet
+
+import "fmt"
+
+// Пакеты удаления (Data delete="true") переносят адресное удаление строк
+// по первичному ключу — например, стирание данных субъекта (GDPR) во всех
+// копиях таблицы.
+//
+// Schema пакета — только ключевые поля таблицы (Key=true), строки — их
+// значения в порядке схемы:
+//
+//	<R>key1|…|keyN</R>
+//
+// Значения кодируются как ячейки обычной строки (включая SpecialValues),
+// поэтому сжатие, шифрование и XXH3 работают без изменений. Применение —
+// DELETE по ключу (base.ApplyDeletePacket); строки, которых уже нет,
+// пропускаются — повторная доставка пакета безопасна.
+
+// NewDeletePacket создаёт пакет удаления. schema может быть полной схемой
+// таблицы — в пакет попадают только ключевые поля; keys — значения ключа.
+func NewDeletePacket(tableName string, schema Schema, keys [][]string) (*DataPacket, error) {
+	var keySchema Schema
+	for _, f := range schema.Fields {
+		if f.Key {
+			keySchema.Fields = append(keySchema.Fields, f)
+		}
+	}
+	if len(keySchema.Fields) == 0 {
+		return nil, fmt.Errorf("delete packet for %s requires key fields in schema", tableName)
+	}
+
+	pkt := NewDataPacket(TypeReference, tableName)
+	pkt.Schema = keySchema
+	pkt.Data.Delete = true
+	pkt.Data.Rows = make([]Row, len(keys))
+	for i, k := range keys {
+		if len(k) != len(keySchema.Fields) {
+			return nil, fmt.Errorf("delete key %d: expected %d value(s), got %d", i, len(keySchema.Fields), len(k))
+		}
+		pkt.Data.Rows[i] = Row{Value: JoinRowEscaped(k)}
+	}
+	pkt.Header.RecordsInPart = len(keys)
+	return pkt, nil
+}
+
+// DeleteKeys разбирает строки пакета удаления (после распаковки/расшифровки).
+func (p *DataPacket) DeleteKeys() ([][]string, error) {
+	if !p.Data.Delete {
+		return nil, fmt.Errorf("packet is not a delete packet")
+	}
+	if len(p.Schema.Fields) == 0 {
+		return nil, fmt.Errorf("delete packet requires key fields in schema")
+	}
+	for _, f := range p.Schema.Fields {
+		if !f.Key {
+			return nil, fmt.Errorf("delete packet schema must contain only key fields, got %s", f.Name)
+		}
+	}
+	parser := NewParser()
+	keys := make([][]string, len(p.Data.Rows))
+	for i, row := range p.Data.Rows {
+		keys[i] = parser.GetRowValues(row)
+		if len(keys[i]) != len(p.Schema.Fields) {
+			return nil, fmt.Errorf("row %d: expected %d key value(s), got %d", i, len(p.Schema.Fields), len(keys[i]))
+		}
+	}
+	return keys, nil
+}
@@ -0,0 +1,55 @@
+package packet
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDeletePacket_XMLRoundTrip(t *testing.T) {
+	schema := Schema{Fields: []Field{
+		{Name: "id", Type: "INTEGER", Key: true},
+		{Name: "region", Type: "TEXT", Key: true},
+		{Name: "email", Type: "TEXT"},
+	}}
+	pkt, err := NewDeletePacket("customers", schema, [][]string{{"1", "EU"}, {"2", "A|B"}})
+	if err != nil {
+		t.Fatalf("NewDeletePacket: %v", err)
+	}
+	if len(pkt.Schema.Fields) != 2 || pkt.Header.RecordsInPart != 2 {
+		t.Fatalf("schema = %+v, records = %d", pkt.Schema.Fields, pkt.Header.RecordsInPart)
+	}
+
+	xmlData, err := NewGenerator().ToXML(pkt, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(xmlData), `delete="true"`) {
+		t.Fatal("delete attribute not written")
+	}
+
+	parsed, err := NewParser().ParseBytes(xmlData)
+	if err != nil {
+		t.Fatalf("ParseBytes: %v", err)
+	}
+	keys, err := parsed.DeleteKeys()
+	if err != nil {
+		t.Fatalf("DeleteKeys: %v", err)
+	}
+	if len(keys) != 2 || keys[1][1] != "A|B" {
+		t.Errorf("unexpected keys: %v", keys)
+	}
+}
+
+func TestNewDeletePacket_Validation(t *testing.T) {
+	noKey := Schema{Fields: []Field{{Name: "email", Type: "TEXT"}}}
+	if _, err := NewDeletePacket("t", noKey, [][]string{{"a"}}); err == nil {
+		t.Error("expected error for schema without key")
+	}
+	withKey := Schema{Fields: []Field{{Name: "id", Type: "INTEGER", Key: true}}}
+	if _, err := NewDeletePacket("t", withKey, [][]string{{"1", "2"}}); err == nil {
+		t.Error("expected error for key width mismatch")
+	}
+	if _, err := NewDataPacket(TypeReference, "t").DeleteKeys(); err == nil {
+		t.Error("expected error for non-delete packet")
+	}
+}
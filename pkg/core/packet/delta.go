@@ -0,0 +1,149 @@
+package packet
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Delta-пакеты (Data delta="true") передают только изменившиеся колонки.
+//
+// Для широких таблиц, где между снимками меняются одна-две колонки, полные
+// строки — в основном повтор неизменных значений. Delta-строка содержит
+// первичный ключ и тройки «поле, старое значение, новое значение»:
+//
+//	<R>key1|…|keyN|field|old|new|field|old|new…</R>
+//
+// Ключевые поля — поля схемы с Key=true, в порядке схемы. Значения кодируются
+// так же, как ячейки обычной строки этого поля (включая маркеры
+// SpecialValues), и экранируются TDTP-правилами — поэтому сжатие,
+// шифрование и XXH3 работают с delta-строками без изменений.
+//
+// Schema delta-пакета — полная схема таблицы: получатель берёт из неё типы
+// изменённых полей. Применение — точечные UPDATE по ключу
+// (base.ApplyDeltaPacket); вставки и удаления delta-пакет не переносит.
+
+// DeltaChange — изменение одного поля.
+type DeltaChange struct {
+	Field string
+	Old   string
+	New   string
+}
+
+// DeltaRow — ключ строки и её изменённые поля.
+type DeltaRow struct {
+	Key     []string // значения ключевых полей в порядке схемы
+	Changes []DeltaChange
+}
+
+// EncodeDeltaRow кодирует delta-строку в Row.
+func EncodeDeltaRow(r DeltaRow) Row {
+	cells := make([]string, 0, len(r.Key)+3*len(r.Changes))
+	cells = append(cells, r.Key...)
+	for _, c := range r.Changes {
+		cells = append(cells, c.Field, c.Old, c.New)
+	}
+	return Row{Value: JoinRowEscaped(cells)}
+}
+
+// DecodeDeltaRow разбирает delta-строку; keyCount — число ключевых полей схемы.
+func DecodeDeltaRow(row Row, keyCount int) (DeltaRow, error) {
+	cells := NewParser().GetRowValues(row)
+	if len(cells) < keyCount || (len(cells)-keyCount)%3 != 0 {
+		return DeltaRow{}, fmt.Errorf("malformed delta row: %d cells for %d key field(s)", len(cells), keyCount)
+	}
+	r := DeltaRow{Key: cells[:keyCount]}
+	for i := keyCount; i < len(cells); i += 3 {
+		r.Changes = append(r.Changes, DeltaChange{Field: cells[i], Old: cells[i+1], New: cells[i+2]})
+	}
+	return r, nil
+}
+
+// DeltaFromRows сравнивает старую и новую версии строки (полные значения в
+// порядке схемы) и возвращает delta-строку; ok=false — изменений нет.
+// Источник пар — diff двух снимков или поток CDC (before/after образы).
+func DeltaFromRows(schema Schema, oldRow, newRow []string) (DeltaRow, bool, error) {
+	if len(oldRow) != len(schema.Fields) || len(newRow) != len(schema.Fields) {
+		return DeltaRow{}, false, fmt.Errorf("expected %d values, got %d (old) and %d (new)",
+			len(schema.Fields), len(oldRow), len(newRow))
+	}
+	var r DeltaRow
+	for i, f := range schema.Fields {
+		if f.Key {
+			if oldRow[i] != newRow[i] {
+				return DeltaRow{}, false, fmt.Errorf("key field %s changed (%q → %q): not representable as delta", f.Name, oldRow[i], newRow[i])
+			}
+			r.Key = append(r.Key, newRow[i])
+			continue
+		}
+		if oldRow[i] != newRow[i] {
+			r.Changes = append(r.Changes, DeltaChange{Field: f.Name, Old: oldRow[i], New: newRow[i]})
+		}
+	}
+	return r, len(r.Changes) > 0, nil
+}
+
+// NewDeltaPacket создаёт delta-пакет. Схема должна содержать ключевые поля;
+// изменённые поля должны быть в схеме и не могут быть ключевыми.
+func NewDeltaPacket(tableName string, schema Schema, rows []DeltaRow) (*DataPacket, error) {
+	if err := validateDeltaRows(schema, rows); err != nil {
+		return nil, err
+	}
+	pkt := NewDataPacket(TypeReference, tableName)
+	pkt.Schema = schema
+	pkt.Data.Delta = true
+	pkt.Data.Rows = make([]Row, len(rows))
+	for i, r := range rows {
+		pkt.Data.Rows[i] = EncodeDeltaRow(r)
+	}
+	pkt.Header.RecordsInPart = len(rows)
+	return pkt, nil
+}
+
+// DeltaRows разбирает строки delta-пакета (после распаковки/расшифровки).
+func (p *DataPacket) DeltaRows() ([]DeltaRow, error) {
+	if !p.Data.Delta {
+		return nil, fmt.Errorf("packet is not a delta packet")
+	}
+	keyCount := len(ExtractKeyFields(p.Schema))
+	rows := make([]DeltaRow, len(p.Data.Rows))
+	for i, row := range p.Data.Rows {
+		r, err := DecodeDeltaRow(row, keyCount)
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", i, err)
+		}
+		rows[i] = r
+	}
+	if err := validateDeltaRows(p.Schema, rows); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func validateDeltaRows(schema Schema, rows []DeltaRow) error {
+	keys := ExtractKeyFields(schema)
+	if len(keys) == 0 {
+		return fmt.Errorf("delta packet requires key fields in schema")
+	}
+	fields := make(map[string]Field, len(schema.Fields))
+	for _, f := range schema.Fields {
+		fields[strings.ToLower(f.Name)] = f
+	}
+	for i, r := range rows {
+		if len(r.Key) != len(keys) {
+			return fmt.Errorf("delta row %d: expected %d key value(s), got %d", i, len(keys), len(r.Key))
+		}
+		if len(r.Changes) == 0 {
+			return fmt.Errorf("delta row %d: no changed fields", i)
+		}
+		for _, c := range r.Changes {
+			f, ok := fields[strings.ToLower(c.Field)]
+			if !ok {
+				return fmt.Errorf("delta row %d: field %s not found in schema", i, c.Field)
+			}
+			if f.Key {
+				return fmt.Errorf("delta row %d: key field %s cannot be changed by delta", i, c.Field)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package packet
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func deltaTestSchema() Schema {
+	return Schema{Fields: []Field{
+		{Name: "id", Type: "INTEGER", Key: true},
+		{Name: "region", Type: "TEXT", Key: true},
+		{Name: "name", Type: "TEXT"},
+		{Name: "note", Type: "TEXT"},
+		{Name: "price", Type: "DECIMAL"},
+	}}
+}
+
+func TestDeltaRow_EncodeDecode(t *testing.T) {
+	r := DeltaRow{
+		Key: []string{"7", "EU|West"},
+		Changes: []DeltaChange{
+			{Field: "note", Old: `a\b`, New: "line1\nline2"},
+			{Field: "price", Old: "", New: "9.99"},
+		},
+	}
+	got, err := DecodeDeltaRow(EncodeDeltaRow(r), 2)
+	if err != nil {
+		t.Fatalf("DecodeDeltaRow: %v", err)
+	}
+	if !slices.Equal(got.Key, r.Key) || !slices.Equal(got.Changes, r.Changes) {
+		t.Errorf("round trip mismatch: %+v", got)
+	}
+
+	if _, err := DecodeDeltaRow(Row{Value: "7|EU|note|x"}, 2); err == nil {
+		t.Error("expected error for incomplete change triple")
+	}
+}
+
+func TestDeltaFromRows(t *testing.T) {
+	schema := deltaTestSchema()
+	r, ok, err := DeltaFromRows(schema,
+		[]string{"1", "EU", "Widget", "", "10"},
+		[]string{"1", "EU", "Widget", "new", "12"})
+	if err != nil || !ok {
+		t.Fatalf("DeltaFromRows: ok=%v err=%v", ok, err)
+	}
+	want := []DeltaChange{{Field: "note", Old: "", New: "new"}, {Field: "price", Old: "10", New: "12"}}
+	if !slices.Equal(r.Key, []string{"1", "EU"}) || !slices.Equal(r.Changes, want) {
+		t.Errorf("unexpected delta: %+v", r)
+	}
+
+	if _, ok, err := DeltaFromRows(schema, []string{"1", "EU", "a", "b", "c"}, []string{"1", "EU", "a", "b", "c"}); ok || err != nil {
+		t.Errorf("unchanged rows: ok=%v err=%v", ok, err)
+	}
+	if _, _, err := DeltaFromRows(schema, []string{"1", "EU", "a", "b", "c"}, []string{"2", "EU", "a", "b", "c"}); err == nil {
+		t.Error("expected error for changed key")
+	}
+}
+
+func TestNewDeltaPacket_Validation(t *testing.T) {
+	schema := deltaTestSchema()
+	tests := []struct {
+		name   string
+		schema Schema
+		row    DeltaRow
+	}{
+		{"no key fields", Schema{Fields: []Field{{Name: "name", Type: "TEXT"}}}, DeltaRow{Changes: []DeltaChange{{Field: "name"}}}},
+		{"key count", schema, DeltaRow{Key: []string{"1"}, Changes: []DeltaChange{{Field: "name"}}}},
+		{"no changes", schema, DeltaRow{Key: []string{"1", "EU"}}},
+		{"unknown field", schema, DeltaRow{Key: []string{"1", "EU"}, Changes: []DeltaChange{{Field: "missing"}}}},
+		{"key change", schema, DeltaRow{Key: []string{"1", "EU"}, Changes: []DeltaChange{{Field: "region"}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewDeltaPacket("items", tt.schema, []DeltaRow{tt.row}); err == nil {
+				t.Error("expected validation error")
+			}
+		})
+	}
+}
+
+func TestDeltaPacket_XMLRoundTrip(t *testing.T) {
+	rows := []DeltaRow{
+		{Key: []string{"1", "EU"}, Changes: []DeltaChange{{Field: "name", Old: "A|B", New: "C"}}},
+		{Key: []string{"2", "US"}, Changes: []DeltaChange{{Field: "note", Old: "x", New: "y"}, {Field: "price", Old: "1", New: "2"}}},
+	}
+	pkt, err := NewDeltaPacket("items", deltaTestSchema(), rows)
+	if err != nil {
+		t.Fatalf("NewDeltaPacket: %v", err)
+	}
+
+	xmlData, err := NewGenerator().ToXML(pkt, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(xmlData), `delta="true"`) {
+		t.Fatal("delta attribute not written")
+	}
+
+	parsed, err := NewParser().ParseBytes(xmlData)
+	if err != nil {
+		t.Fatalf("ParseBytes: %v", err)
+	}
+	if !parsed.Data.Delta {
+		t.Fatal("delta attribute lost after parsing")
+	}
+	got, err := parsed.DeltaRows()
+	if err != nil {
+		t.Fatalf("DeltaRows: %v", err)
+	}
+	if len(got) != 2 || got[0].Changes[0].Old != "A|B" || len(got[1].Changes) != 2 {
+		t.Errorf("unexpected rows: %+v", got)
+	}
+
+	full := NewDataPacket(TypeReference, "items")
+	if _, err := full.DeltaRows(); err == nil {
+		t.Error("expected error for non-delta packet")
+	}
+}
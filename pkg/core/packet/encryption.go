@@ -58,6 +58,12 @@ func EncryptSections(pkt *DataPacket, key []byte) error {
 
 	pkt.MaterializeRows()
 
+	// Header остаётся открытым, а SHA-256 строк позволяет проверить догадку
+	// о содержимом — целостность зашифрованного пакета даёт XXH3 v1.4.
+	pkt.Header.Checksum = nil
+	// Депонированный ключ относится к прежнему шифрованию
+	pkt.Header.KeyEscrow = nil
+
 	if pkt.QueryContext != nil && pkt.QueryContext.Encryption == "" {
 		plaintext, err := xml.Marshal(pkt.QueryContext)
 		if err != nil {
@@ -199,3 +205,48 @@ func parseDataRowsFragment(fragment []byte) ([]Row, error) {
 	}
 	return scratch.Rows, nil
 }
+
+// KeyEscrow is Header.KeyEscrow: the packet's AES-256 key wrapped to an
+// offline escrow public key. It sits in the plain Header next to
+// MessageID, so a security officer holding the escrow private key can
+// decrypt an archived packet long after xZMercury burned or expired the
+// original key.
+//
+//	<KeyEscrow algo="rsa-oaep-sha256" key_id="3f9a…">base64…</KeyEscrow>
+type KeyEscrow struct {
+	Algo  string `xml:"algo,attr"   json:"algo"`
+	KeyID string `xml:"key_id,attr" json:"key_id"` // crypto.EscrowKeyID of the escrow public key
+	Value string `xml:",chardata"   json:"value"`  // base64 wrapped key
+}
+
+// EscrowPacketKey wraps key (the one EncryptSections just used on pkt) to
+// escrow and stores it in pkt.Header.KeyEscrow. Call it after
+// EncryptSections, which clears any stale escrow entry.
+func EscrowPacketKey(pkt *DataPacket, key []byte, escrow *crypto.EscrowKey) error {
+	wrapped, err := escrow.Wrap(key)
+	if err != nil {
+		return fmt.Errorf("packet %s: %w", pkt.Header.MessageID, err)
+	}
+	pkt.Header.KeyEscrow = &KeyEscrow{Algo: crypto.EscrowAlgoRSAOAEP, KeyID: escrow.ID, Value: wrapped}
+	return nil
+}
+
+// RecoverPacketKey unwraps the escrowed key of pkt with the escrow private
+// key — the offline counterpart of xZMercury RetrieveKey.
+func RecoverPacketKey(pkt *DataPacket, escrow *crypto.EscrowPrivateKey) ([]byte, error) {
+	ke := pkt.Header.KeyEscrow
+	if ke == nil {
+		return nil, fmt.Errorf("packet %s has no escrowed key (exported without key escrow)", pkt.Header.MessageID)
+	}
+	if ke.Algo != crypto.EscrowAlgoRSAOAEP {
+		return nil, fmt.Errorf("packet %s: unsupported key escrow algorithm %q", pkt.Header.MessageID, ke.Algo)
+	}
+	if ke.KeyID != escrow.ID {
+		return nil, fmt.Errorf("packet %s: key escrowed to %s, escrow private key is %s", pkt.Header.MessageID, ke.KeyID, escrow.ID)
+	}
+	key, err := escrow.Unwrap(ke.Value)
+	if err != nil {
+		return nil, fmt.Errorf("packet %s: %w", pkt.Header.MessageID, err)
+	}
+	return key, nil
+}
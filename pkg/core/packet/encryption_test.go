@@ -1,7 +1,13 @@
 package packet
 
 import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
 	"testing"
+
+	"github.com/ruslano69/tdtp-framework/pkg/crypto"
 )
 
 func makeEncryptionTestPacket(t *testing.T) *DataPacket {
@@ -340,3 +346,64 @@ func TestRenderParseDataRowsFragment_RoundTrip(t *testing.T) {
 		}
 	}
 }
+
+// TestKeyEscrow_WireRoundTrip — ключ, депонированный в Header, переживает
+// сериализацию и открывает пакет без исходного ключа.
+func TestKeyEscrow_WireRoundTrip(t *testing.T) {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubDER, _ := x509.MarshalPKIXPublicKey(&priv.PublicKey)
+	escrow, err := crypto.ParseEscrowKey(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	officer, err := crypto.ParseEscrowPrivateKey(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(priv)}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pkt := makeEncryptionTestPacket(t)
+	wantRows := pkt.Data.Rows
+	key := testKey()
+	if err := EncryptSections(pkt, key); err != nil {
+		t.Fatal(err)
+	}
+	if err := EscrowPacketKey(pkt, key, escrow); err != nil {
+		t.Fatal(err)
+	}
+	xmlBytes, err := NewGenerator().ToXML(pkt, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := NewParser().ParseBytes(xmlBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ke := parsed.Header.KeyEscrow; ke == nil || ke.KeyID != escrow.ID || ke.Algo != crypto.EscrowAlgoRSAOAEP {
+		t.Fatalf("Header.KeyEscrow = %+v", parsed.Header.KeyEscrow)
+	}
+
+	recovered, err := RecoverPacketKey(parsed, officer)
+	if err != nil {
+		t.Fatalf("RecoverPacketKey: %v", err)
+	}
+	if err := DecryptSections(parsed, recovered); err != nil {
+		t.Fatalf("DecryptSections with recovered key: %v", err)
+	}
+	if len(parsed.Data.Rows) != len(wantRows) || parsed.Data.Rows[0].Value != wantRows[0].Value {
+		t.Errorf("decrypted rows = %v, want %v", parsed.Data.Rows, wantRows)
+	}
+
+	// Повторное шифрование новым ключом снимает устаревший депонированный ключ
+	if err := EncryptSections(parsed, testKey()); err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Header.KeyEscrow != nil {
+		t.Error("EncryptSections kept a stale KeyEscrow")
+	}
+	if _, err := RecoverPacketKey(parsed, officer); err == nil {
+		t.Error("RecoverPacketKey without KeyEscrow: expected error")
+	}
+}
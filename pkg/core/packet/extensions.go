@@ -0,0 +1,305 @@
+package packet
+
+import (
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// Extensions — расширения заголовка (Header.Extensions): метаданные
+// интегратора (correlation ID, арендатор, бизнес-процесс), которые
+// протокол переносит через сериализацию, разбор и брокеры, не интерпретируя.
+//
+//	<Header>
+//	  ...
+//	  <Extensions>
+//	    <Extension key="correlation-id">7f3c9a…</Extension>
+//	    <Extension key="tenant-id">acme</Extension>
+//	  </Extensions>
+//	</Header>
+//
+// В JSON — объект "extensions": {"correlation-id": "7f3c9a…"}.
+// Читатели без поддержки расширений пропускают элемент <Extensions>.
+type Extensions map[string]string
+
+// Ограничения расширений: заголовок должен оставаться маленьким.
+const (
+	MaxExtensionKeyLen   = 64
+	MaxExtensionValueLen = 1024
+)
+
+// extensionKeyPattern — допустимые ключи: буквы, цифры, '.', '_', '-'.
+var extensionKeyPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// ValidateExtensionKey проверяет синтаксис ключа расширения.
+func ValidateExtensionKey(key string) error {
+	if len(key) > MaxExtensionKeyLen || !extensionKeyPattern.MatchString(key) {
+		return fmt.Errorf("invalid extension key %q (letters, digits, '.', '_', '-'; up to %d characters)", key, MaxExtensionKeyLen)
+	}
+	return nil
+}
+
+// validateExtension проверяет ключ, длину значения и значение
+// зарегистрированного расширения.
+func validateExtension(key, value string) error {
+	if err := ValidateExtensionKey(key); err != nil {
+		return err
+	}
+	if len(value) > MaxExtensionValueLen {
+		return fmt.Errorf("extension %s: value exceeds %d bytes", key, MaxExtensionValueLen)
+	}
+	if def, ok := LookupExtension(key); ok && def.Validate != nil {
+		if err := def.Validate(value); err != nil {
+			return fmt.Errorf("extension %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// Validate проверяет все расширения (ключи и значения известных расширений).
+// Неизвестные ключи допустимы.
+func (e Extensions) Validate() error {
+	for _, key := range e.Keys() {
+		if err := validateExtension(key, e[key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Keys возвращает ключи в порядке сортировки.
+func (e Extensions) Keys() []string {
+	keys := make([]string, 0, len(e))
+	for k := range e {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Clone возвращает копию (nil для пустых расширений).
+func (e Extensions) Clone() Extensions {
+	if len(e) == 0 {
+		return nil
+	}
+	out := make(Extensions, len(e))
+	for k, v := range e {
+		out[k] = v
+	}
+	return out
+}
+
+// xmlExtension — элемент <Extension key="...">value</Extension>.
+type xmlExtension struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// MarshalXML пишет расширения элементами <Extension> в порядке ключей.
+func (e Extensions) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
+	items := make([]xmlExtension, 0, len(e))
+	for _, key := range e.Keys() {
+		items = append(items, xmlExtension{Key: key, Value: e[key]})
+	}
+	return enc.EncodeElement(struct {
+		Items []xmlExtension `xml:"Extension"`
+	}{items}, start)
+}
+
+// UnmarshalXML читает элементы <Extension>. Повтор ключа — ошибка.
+func (e *Extensions) UnmarshalXML(dec *xml.Decoder, start xml.StartElement) error {
+	var v struct {
+		Items []xmlExtension `xml:"Extension"`
+	}
+	if err := dec.DecodeElement(&v, &start); err != nil {
+		return err
+	}
+	out := make(Extensions, len(v.Items))
+	for _, item := range v.Items {
+		if _, dup := out[item.Key]; dup {
+			return fmt.Errorf("duplicate extension key %q", item.Key)
+		}
+		out[item.Key] = item.Value
+	}
+	*e = out
+	return nil
+}
+
+// Extension возвращает значение расширения заголовка.
+func (h *Header) Extension(key string) (string, bool) {
+	v, ok := h.Extensions[key]
+	return v, ok
+}
+
+// SetExtension устанавливает расширение заголовка. Значение
+// зарегистрированного расширения проверяется его Validate.
+func (h *Header) SetExtension(key, value string) error {
+	if err := validateExtension(key, value); err != nil {
+		return err
+	}
+	if h.Extensions == nil {
+		h.Extensions = make(Extensions)
+	}
+	h.Extensions[key] = value
+	return nil
+}
+
+// DeleteExtension удаляет расширение заголовка.
+func (h *Header) DeleteExtension(key string) {
+	delete(h.Extensions, key)
+	if len(h.Extensions) == 0 {
+		h.Extensions = nil
+	}
+}
+
+// ========== Реестр известных расширений ==========
+
+// ExtensionDef описывает известный ключ расширения.
+type ExtensionDef struct {
+	Key         string
+	Description string
+
+	// Validate проверяет значение (nil — любое значение)
+	Validate func(value string) error
+}
+
+var (
+	extensionRegistryMu sync.RWMutex
+	extensionRegistry   = make(map[string]ExtensionDef)
+)
+
+// RegisterExtension регистрирует известный ключ расширения: его значения
+// проверяются при SetExtension, генерации и разборе пакета. Повторная
+// регистрация ключа — ошибка.
+func RegisterExtension(def ExtensionDef) error {
+	if err := ValidateExtensionKey(def.Key); err != nil {
+		return err
+	}
+	extensionRegistryMu.Lock()
+	defer extensionRegistryMu.Unlock()
+	if _, exists := extensionRegistry[def.Key]; exists {
+		return fmt.Errorf("extension %s is already registered", def.Key)
+	}
+	extensionRegistry[def.Key] = def
+	return nil
+}
+
+// LookupExtension возвращает описание зарегистрированного расширения.
+func LookupExtension(key string) (ExtensionDef, bool) {
+	extensionRegistryMu.RLock()
+	defer extensionRegistryMu.RUnlock()
+	def, ok := extensionRegistry[key]
+	return def, ok
+}
+
+// RegisteredExtensions возвращает зарегистрированные расширения по ключу.
+func RegisteredExtensions() []ExtensionDef {
+	extensionRegistryMu.RLock()
+	defer extensionRegistryMu.RUnlock()
+	defs := make([]ExtensionDef, 0, len(extensionRegistry))
+	for _, def := range extensionRegistry {
+		defs = append(defs, def)
+	}
+	sort.Slice(defs, func(i, j int) bool { return defs[i].Key < defs[j].Key })
+	return defs
+}
+
+// ExtensionKey — типизированный доступ к зарегистрированному расширению.
+// Значение хранится строкой; format/parse переводят его в T и обратно.
+type ExtensionKey[T any] struct {
+	key    string
+	format func(T) string
+	parse  func(string) (T, error)
+}
+
+// NewExtensionKey регистрирует расширение и возвращает типизированный ключ.
+// Значение проверяется разбором parse и затем def.Validate. Предназначен для
+// объявления переменных пакета: ошибка регистрации вызывает panic.
+func NewExtensionKey[T any](def ExtensionDef, format func(T) string, parse func(string) (T, error)) ExtensionKey[T] {
+	validate := def.Validate
+	def.Validate = func(value string) error {
+		if _, err := parse(value); err != nil {
+			return err
+		}
+		if validate != nil {
+			return validate(value)
+		}
+		return nil
+	}
+	if err := RegisterExtension(def); err != nil {
+		panic(err)
+	}
+	return ExtensionKey[T]{key: def.Key, format: format, parse: parse}
+}
+
+// StringExtension регистрирует строковое расширение.
+func StringExtension(key, description string) ExtensionKey[string] {
+	return NewExtensionKey(ExtensionDef{Key: key, Description: description},
+		func(v string) string { return v },
+		func(s string) (string, error) { return s, nil })
+}
+
+// IntExtension регистрирует целочисленное расширение.
+func IntExtension(key, description string) ExtensionKey[int64] {
+	return NewExtensionKey(ExtensionDef{Key: key, Description: description},
+		func(v int64) string { return strconv.FormatInt(v, 10) },
+		func(s string) (int64, error) { return strconv.ParseInt(s, 10, 64) })
+}
+
+// Key возвращает ключ расширения.
+func (k ExtensionKey[T]) Key() string { return k.key }
+
+// Get читает расширение из заголовка; ok = false, если его нет.
+func (k ExtensionKey[T]) Get(h *Header) (value T, ok bool, err error) {
+	raw, ok := h.Extension(k.key)
+	if !ok {
+		return value, false, nil
+	}
+	value, err = k.parse(raw)
+	if err != nil {
+		return value, true, fmt.Errorf("extension %s: %w", k.key, err)
+	}
+	return value, true, nil
+}
+
+// Set записывает расширение в заголовок.
+func (k ExtensionKey[T]) Set(h *Header, value T) error {
+	return h.SetExtension(k.key, k.format(value))
+}
+
+// Стандартные расширения заголовка.
+var (
+	// ExtCorrelationID — сквозной идентификатор бизнес-операции
+	// (связывает пакеты и записи внешних систем)
+	ExtCorrelationID = StringExtension("correlation-id", "end-to-end business operation identifier")
+
+	// ExtTenantID — арендатор, которому принадлежат данные
+	ExtTenantID = StringExtension("tenant-id", "tenant the data belongs to")
+
+	// ExtBusinessProcess — бизнес-процесс (тег маршрутизации)
+	ExtBusinessProcess = StringExtension("business-process", "business process routing tag")
+
+	// ExtTraceParent, ExtTraceState — контекст распределённой трассировки
+	// W3C Trace Context (pkg/tracing): экспорт, брокер и импорт пакета
+	// попадают в одну трассу
+	ExtTraceParent = NewExtensionKey(ExtensionDef{
+		Key:         "traceparent",
+		Description: "W3C trace context of the span that produced the packet",
+		Validate:    validateTraceParent,
+	}, func(v string) string { return v }, func(s string) (string, error) { return s, nil })
+	ExtTraceState = StringExtension("tracestate", "W3C vendor-specific trace state")
+)
+
+// traceParentPattern — version-trace_id-parent_id-flags (W3C Trace Context);
+// версии новее 00 могут дописывать поля через '-'.
+var traceParentPattern = regexp.MustCompile(`^[0-9a-f]{2}-[0-9a-f]{32}-[0-9a-f]{16}-[0-9a-f]{2}(-.*)?$`)
+
+func validateTraceParent(value string) error {
+	if !traceParentPattern.MatchString(value) {
+		return fmt.Errorf("invalid W3C traceparent %q", value)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package packet
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var testShardExt = IntExtension("test.shard", "shard number (tests)")
+
+func TestExtensions_RoundTrip(t *testing.T) {
+	gen := NewGenerator()
+	if err := gen.SetExtensions(Extensions{"correlation-id": "op-42", "x.custom": "a <b> & \"c\""}); err != nil {
+		t.Fatalf("SetExtensions: %v", err)
+	}
+	packets, err := gen.GenerateReference("orders", Schema{Fields: []Field{{Name: "id", Type: "INTEGER"}}}, [][]string{{"1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkt := packets[0]
+	if err := testShardExt.Set(&pkt.Header, 7); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	want := Extensions{"correlation-id": "op-42", "x.custom": "a <b> & \"c\"", "test.shard": "7"}
+
+	xmlData, err := gen.ToXML(pkt, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(xmlData), `<Extension key="correlation-id">op-42</Extension>`) {
+		t.Errorf("XML lacks extension element:\n%s", xmlData)
+	}
+	fromXML, err := NewParser().ParseBytes(xmlData)
+	if err != nil {
+		t.Fatalf("ParseBytes: %v", err)
+	}
+	if !reflect.DeepEqual(fromXML.Header.Extensions, want) {
+		t.Errorf("XML round trip: %v, want %v", fromXML.Header.Extensions, want)
+	}
+
+	jsonData, err := gen.ToJSON(pkt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromJSON, err := NewParser().ParseJSON(jsonData)
+	if err != nil {
+		t.Fatalf("ParseJSON: %v", err)
+	}
+	if !reflect.DeepEqual(fromJSON.Header.Extensions, want) {
+		t.Errorf("JSON round trip: %v, want %v", fromJSON.Header.Extensions, want)
+	}
+
+	shard, ok, err := testShardExt.Get(&fromXML.Header)
+	if err != nil || !ok || shard != 7 {
+		t.Errorf("Get = %d, %v, %v", shard, ok, err)
+	}
+	if id, ok, _ := ExtCorrelationID.Get(&fromXML.Header); !ok || id != "op-42" {
+		t.Errorf("correlation id = %q, %v", id, ok)
+	}
+}
+
+func TestExtensions_Validation(t *testing.T) {
+	var h Header
+	if err := h.SetExtension("bad key", "v"); err == nil {
+		t.Error("expected error for key with space")
+	}
+	if err := h.SetExtension("k", strings.Repeat("x", MaxExtensionValueLen+1)); err == nil {
+		t.Error("expected error for oversized value")
+	}
+	// Значение зарегистрированного расширения проверяется
+	if err := h.SetExtension(testShardExt.Key(), "seven"); err == nil {
+		t.Error("expected error for non-integer shard")
+	}
+	if err := ExtTraceParent.Set(&h, "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"); err != nil {
+		t.Errorf("valid traceparent: %v", err)
+	}
+	if err := ExtTraceParent.Set(&h, "00-not-a-trace"); err == nil {
+		t.Error("expected error for malformed traceparent")
+	}
+	if err := RegisterExtension(ExtensionDef{Key: "correlation-id"}); err == nil {
+		t.Error("expected error for duplicate registration")
+	}
+
+	errEmpty := errors.New("empty")
+	if err := RegisterExtension(ExtensionDef{Key: "test.region", Validate: func(v string) error {
+		if v == "" {
+			return errEmpty
+		}
+		return nil
+	}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.SetExtension("test.region", ""); !errors.Is(err, errEmpty) {
+		t.Errorf("got %v, want errEmpty", err)
+	}
+
+	// Разбор отклоняет недопустимое значение известного расширения и повтор ключа
+	gen := NewGenerator()
+	packets, err := gen.GenerateReference("t", Schema{Fields: []Field{{Name: "id", Type: "INTEGER"}}}, [][]string{{"1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	packets[0].Header.Extensions = Extensions{"test.shard": "x"}
+	data, err := gen.ToXML(packets[0], false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewParser().ParseBytes(data); err == nil {
+		t.Error("expected parse error for invalid shard")
+	}
+	dup := strings.Replace(string(data), `<Extension key="test.shard">x</Extension>`,
+		`<Extension key="a">1</Extension><Extension key="a">2</Extension>`, 1)
+	if _, err := NewParser().ParseBytes([]byte(dup)); err == nil {
+		t.Error("expected parse error for duplicate key")
+	}
+
+	h.Extensions = Extensions{"a": "1"}
+	h.DeleteExtension("a")
+	if h.Extensions != nil {
+		t.Errorf("Extensions after delete = %v, want nil", h.Extensions)
+	}
+}
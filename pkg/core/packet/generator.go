@@ -13,7 +13,7 @@ type CompressionOptions struct {
 	Enabled   bool   // Включить сжатие
 	Level     int    // Уровень сжатия: 1 (fastest) - 19 (best), по умолчанию 3
 	MinSize   int    // Минимальный размер данных для сжатия (bytes), по умолчанию 1024
-	Algorithm string // Алгоритм сжатия: "zstd" (пока только он поддерживается)
+	Algorithm string // Алгоритм сжатия: "zstd" (по умолчанию) или "kanzi"
 }
 
 // DefaultCompressionOptions возвращает настройки сжатия по умолчанию
@@ -31,6 +31,16 @@ func DefaultCompressionOptions() CompressionOptions {
 // т.к. размер строк считается в UTF-16 единицах (MSMQ/COM-совместимость).
 const DefaultMaxMessageSize = 3_800_000
 
+// Режимы оценки размера строки при разбиении на части (SetSizeEstimate).
+const (
+	// SizeEstimateUTF16 — длина значений ×2, как в MSMQ/COM (по умолчанию):
+	// предел 3.8MB даёт ~1.9MB реального XML.
+	SizeEstimateUTF16 = "utf16"
+	// SizeEstimateXML — байты строки в XML (UTF-8, с экранированием):
+	// предел соответствует реальному размеру несжатого пакета.
+	SizeEstimateXML = "xml"
+)
+
 // packetOverheadSize is a conservative estimate of XML envelope bytes
 // (Schema, Header, attributes) subtracted from the part-size budget
 // when splitting rows into partitions.
@@ -38,9 +48,14 @@ const packetOverheadSize = 5000
 
 // Generator отвечает за генерацию TDTP пакетов
 type Generator struct {
-	maxMessageSize    int                // в байтах
+	maxMessageSize    int                // в байтах (оценка sizeEstimate)
+	maxRows           int                // строк в части, 0 — без лимита
+	sizeEstimate      string             // SizeEstimateUTF16 ("") или SizeEstimateXML
 	compression       CompressionOptions // настройки сжатия
 	skipSpecialValues bool               // --fast: пропустить DetectAndApply (без контроля NULL/NaN/Inf)
+	layout            string             // Data.Layout генерируемых пакетов: "" или LayoutColumnar
+	checksum          string             // Header.Checksum генерируемых пакетов: ChecksumOff/Packet/Rows
+	extensions        Extensions         // Header.Extensions генерируемых пакетов
 }
 
 // NewGenerator создает новый генератор
@@ -56,6 +71,27 @@ func (g *Generator) SetMaxMessageSize(size int) {
 	g.maxMessageSize = size
 }
 
+// SetMaxRowsPerPacket ограничивает число строк в одной части (0 — только
+// по размеру). Действует вместе с SetMaxMessageSize: часть закрывается по
+// первому из пределов.
+func (g *Generator) SetMaxRowsPerPacket(n int) {
+	g.maxRows = max(n, 0)
+}
+
+// SetSizeEstimate выбирает, как считается размер части для
+// SetMaxMessageSize: SizeEstimateUTF16 (по умолчанию) или SizeEstimateXML.
+func (g *Generator) SetSizeEstimate(mode string) error {
+	switch mode {
+	case "", SizeEstimateUTF16:
+		g.sizeEstimate = ""
+		return nil
+	case SizeEstimateXML:
+		g.sizeEstimate = mode
+		return nil
+	}
+	return fmt.Errorf("unknown size estimate mode: %s (expected utf16 or xml)", mode)
+}
+
 // SetCompression устанавливает настройки сжатия
 func (g *Generator) SetCompression(opts CompressionOptions) {
 	g.compression = opts
@@ -78,6 +114,56 @@ func (g *Generator) SetSkipSpecialValues(skip bool) {
 	g.skipSpecialValues = skip
 }
 
+// SetDataLayout выбирает представление строк в генерируемых пакетах:
+// "" — pipe-строки <R> (по умолчанию), LayoutColumnar — колонки <C>.
+// Колоночный формат не применяется к сжатым и зашифрованным данным.
+func (g *Generator) SetDataLayout(layout string) error {
+	switch layout {
+	case "", LayoutColumnar:
+		g.layout = layout
+		return nil
+	}
+	return fmt.Errorf("unknown data layout: %s", layout)
+}
+
+// SetRowChecksum включает контрольные суммы строк в генерируемых пакетах:
+// ChecksumPacket — сумма пакета, ChecksumRows — ещё и хеш каждой строки
+// (указывает испорченную строку ценой 17 байт на строку).
+func (g *Generator) SetRowChecksum(mode string) error {
+	switch mode {
+	case ChecksumOff, ChecksumPacket, ChecksumRows:
+		g.checksum = mode
+		return nil
+	}
+	return fmt.Errorf("unknown row checksum mode: %s", mode)
+}
+
+// SetExtensions задаёт расширения заголовка (Header.Extensions) всех
+// генерируемых пакетов.
+func (g *Generator) SetExtensions(ext Extensions) error {
+	if err := ext.Validate(); err != nil {
+		return err
+	}
+	g.extensions = ext.Clone()
+	return nil
+}
+
+// newPacket создаёт пакет с расширениями генератора.
+func (g *Generator) newPacket(msgType MessageType, tableName string) *DataPacket {
+	packet := NewDataPacket(msgType, tableName)
+	packet.Header.Extensions = g.extensions.Clone()
+	return packet
+}
+
+// stampChecksum ставит Header.Checksum, если он включён (SetRowChecksum).
+// Вызывается до сжатия — сумма считается по строкам.
+func (g *Generator) stampChecksum(packet *DataPacket) error {
+	if g.checksum == ChecksumOff {
+		return nil
+	}
+	return StampRowChecksum(packet, g.checksum == ChecksumRows)
+}
+
 // SetCompressionLevel устанавливает уровень сжатия (1-19)
 func (g *Generator) SetCompressionLevel(level int) {
 	if level < 1 {
@@ -112,7 +198,7 @@ func (g *Generator) GenerateReference(tableName string, schema Schema, rows [][]
 	messageIDBase := g.generateMessageID(TypeReference)
 
 	for i, partition := range partitions {
-		packet := NewDataPacket(TypeReference, tableName)
+		packet := g.newPacket(TypeReference, tableName)
 		// Bump protocol version when Dictionary is present.
 		// v1.3 readers ignore unknown <Dictionary> via xml.Decoder
 		// default, so this is forward-compatible.
@@ -131,6 +217,13 @@ func (g *Generator) GenerateReference(tableName string, schema Schema, rows [][]
 		// без pipe-join и промежуточных аллокаций (RowsToData не вызывается).
 		// Broker-путь (ToXML → компрессия) вызовет RowsToData сам если нужно.
 		packet.rawRows = partition
+		packet.Data.Layout = g.layout
+		if err := g.stampChecksum(packet); err != nil {
+			return nil, err
+		}
+		if err := g.compress(packet); err != nil {
+			return nil, err
+		}
 
 		packets = append(packets, packet)
 	}
@@ -140,7 +233,7 @@ func (g *Generator) GenerateReference(tableName string, schema Schema, rows [][]
 
 // GenerateRequest создает request пакет с запросом
 func (g *Generator) GenerateRequest(tableName string, query *Query, sender, recipient string) (*DataPacket, error) {
-	packet := NewDataPacket(TypeRequest, tableName)
+	packet := g.newPacket(TypeRequest, tableName)
 	packet.Header.MessageID = g.generateMessageID(TypeRequest)
 	packet.Header.Sender = sender
 	packet.Header.Recipient = recipient
@@ -173,7 +266,7 @@ func (g *Generator) GenerateResponse(
 	messageIDBase := g.generateMessageID(TypeResponse)
 
 	for i, partition := range partitions {
-		packet := NewDataPacket(TypeResponse, tableName)
+		packet := g.newPacket(TypeResponse, tableName)
 		packet.Header.MessageID = fmt.Sprintf("%s-P%d", messageIDBase, i+1)
 		packet.Header.InReplyTo = inReplyTo
 		packet.Header.PartNumber = i + 1
@@ -191,6 +284,13 @@ func (g *Generator) GenerateResponse(
 
 		mask := buildEscapeMask(schema)
 		packet.Data = rowsToDataMasked(partition, mask)
+		packet.Data.Layout = g.layout
+		if err := g.stampChecksum(packet); err != nil {
+			return nil, err
+		}
+		if err := g.compress(packet); err != nil {
+			return nil, err
+		}
 		packets = append(packets, packet)
 	}
 
@@ -201,7 +301,7 @@ func (g *Generator) GenerateResponse(
 // Используется когда pipeline не может завершиться штатно (например, xZMercury недоступен).
 // В отличие от alarm, error — стандартный DataPacket с Schema+Data, совместимый с любым consumer.
 func (g *Generator) GenerateError(packageUUID, pipeline, errorCode, errorMessage string) (*DataPacket, error) {
-	packet := NewDataPacket(TypeError, "tdtp_errors")
+	packet := g.newPacket(TypeError, "tdtp_errors")
 	packet.Header.MessageID = fmt.Sprintf("ERR-%d-%s-P1", time.Now().UTC().Year(), generateUUID()[:8])
 	packet.Header.PartNumber = 1
 	packet.Header.TotalParts = 1
@@ -233,7 +333,7 @@ func (g *Generator) GenerateAlarm(
 	schema Schema,
 	rows [][]string,
 ) (*DataPacket, error) {
-	packet := NewDataPacket(TypeAlarm, tableName)
+	packet := g.newPacket(TypeAlarm, tableName)
 	packet.Header.MessageID = g.generateMessageID(TypeAlarm)
 
 	packet.AlarmDetails = &AlarmDetails{
@@ -245,6 +345,7 @@ func (g *Generator) GenerateAlarm(
 
 	packet.Schema = schema
 	packet.Data = rowsToDataMasked(rows, buildEscapeMask(schema))
+	packet.Data.Layout = g.layout
 
 	return packet, nil
 }
@@ -255,11 +356,8 @@ func (g *Generator) GenerateAlarm(
 // ручной writer делает то же за ~15ms.
 // rawRows (если установлены) записываются напрямую без RowsToData.
 func (g *Generator) ToXML(packet *DataPacket, _ bool) ([]byte, error) {
-	// Broker/компрессия-путь: если сжатие включено, нужны Data.Rows (compressed string).
-	// В этом случае rawRows ещё не прошли через rowsToDataWithCompression.
-	if g.compression.Enabled && len(packet.rawRows) > 0 && len(packet.Data.Rows) == 0 {
-		packet.Data = rowsToDataMasked(packet.rawRows, buildEscapeMask(packet.Schema))
-		packet.rawRows = nil
+	if err := g.compress(packet); err != nil {
+		return nil, err
 	}
 	data, err := packetToBytes(packet)
 	if err != nil {
@@ -270,14 +368,32 @@ func (g *Generator) ToXML(packet *DataPacket, _ bool) ([]byte, error) {
 
 // WriteToFile записывает пакет прямо в файл без промежуточного []byte.
 func (g *Generator) WriteToFile(packet *DataPacket, filename string) error {
+	if err := g.compress(packet); err != nil {
+		return err
+	}
 	return g.WriteToFileFast(packet, filename)
 }
 
 // WriteToWriter записывает пакет в writer.
 func (g *Generator) WriteToWriter(packet *DataPacket, w io.Writer) error {
+	if err := g.compress(packet); err != nil {
+		return err
+	}
 	return writePacketTo(newPacketWriter(w), packet)
 }
 
+// compress сжимает данные пакета, если сжатие включено (SetCompression,
+// EnableCompression). Уже сжатые пакеты не трогает.
+func (g *Generator) compress(packet *DataPacket) error {
+	if !g.compression.Enabled {
+		return nil
+	}
+	if err := CompressPacketData(context.Background(), packet, g.compression); err != nil {
+		return fmt.Errorf("packet %s: %w", packet.Header.MessageID, err)
+	}
+	return nil
+}
+
 // partitionRows разбивает строки на части по размеру
 func (g *Generator) partitionRows(rows [][]string, _ Schema) [][][]string {
 	if len(rows) == 0 {
@@ -289,9 +405,9 @@ func (g *Generator) partitionRows(rows [][]string, _ Schema) [][][]string {
 	currentSize := 0
 
 	for _, row := range rows {
-		rowSize := estimateRowSize(row)
+		rowSize := g.rowSize(row)
 
-		if currentSize+rowSize+packetOverheadSize > g.maxMessageSize && len(currentPartition) > 0 {
+		if g.startsNewPart(len(currentPartition), currentSize, rowSize, g.maxMessageSize) {
 			partitions = append(partitions, currentPartition)
 			currentPartition = [][]string{}
 			currentSize = 0
@@ -440,6 +556,26 @@ func writeEscaped(sb *strings.Builder, value string) {
 	sb.WriteString(value[start:])
 }
 
+// startsNewPart — строка размера rowSize не помещается в текущую часть из
+// count строк оценкой size: превышен maxBytes или предел строк.
+func (g *Generator) startsNewPart(count, size, rowSize, maxBytes int) bool {
+	if count == 0 {
+		return false
+	}
+	if g.maxRows > 0 && count >= g.maxRows {
+		return true
+	}
+	return size+rowSize+packetOverheadSize > maxBytes
+}
+
+// rowSize оценивает размер строки в режиме sizeEstimate.
+func (g *Generator) rowSize(row []string) int {
+	if g.sizeEstimate == SizeEstimateXML {
+		return xmlRowSize(row)
+	}
+	return estimateRowSize(row)
+}
+
 // estimateRowSize примерно оценивает размер строки в байтах
 // Используется для партиционирования по MaxMessageSize
 func estimateRowSize(row []string) int {
@@ -451,6 +587,26 @@ func estimateRowSize(row []string) int {
 	return size * 2 // UTF-16 для MSMQ
 }
 
+// xmlRowSize — размер строки в XML пакета: значения с экранированием
+// TDTP (\\, \|, \n) и XML (&amp; &lt; &gt;), разделители и <R></R>.
+func xmlRowSize(row []string) int {
+	size := len("<R></R>\n") + len(row)
+	for _, value := range row {
+		size += len(value)
+		for i := 0; i < len(value); i++ {
+			switch value[i] {
+			case '\\', '|', '\n':
+				size++
+			case '&':
+				size += 4
+			case '<', '>':
+				size += 3
+			}
+		}
+	}
+	return size
+}
+
 // generateMessageID генерирует уникальный MessageID
 func (g *Generator) generateMessageID(msgType MessageType) string {
 	prefix := ""
@@ -0,0 +1,155 @@
+package packet
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// jsonPacket — JSON представление DataPacket (см. Generator.ToJSON).
+//
+// Секции и имена полей повторяют XML: header, query, query_context,
+// pipeline_context, schema, data (атрибуты Data — поля объекта data).
+// Строки — массивы строковых значений в формате TDTP, без экранирования
+// "|" и "\": "" — NULL/пусто, маркеры SpecialValues как есть. Сжатые и
+// зашифрованные данные — одна строка из одного значения (opaque blob, как
+// единственный <R> в XML).
+type jsonPacket struct {
+	Protocol        string           `json:"protocol"`
+	Version         string           `json:"version"`
+	XXH3            string           `json:"xxh3,omitempty"`
+	Header          Header           `json:"header"`
+	Query           *Query           `json:"query,omitempty"`
+	QueryContext    *QueryContext    `json:"query_context,omitempty"`
+	PipelineContext *PipelineContext `json:"pipeline_context,omitempty"`
+	Schema          Schema           `json:"schema"`
+	Data            jsonData         `json:"data"`
+	AlarmDetails    *AlarmDetails    `json:"alarm_details,omitempty"`
+}
+
+// jsonData — секция Data в JSON
+type jsonData struct {
+	Compression string     `json:"compression,omitempty"`
+	Checksum    string     `json:"checksum,omitempty"`
+	XXH3        string     `json:"xxh3,omitempty"`
+	Compact     bool       `json:"compact,omitempty"`
+	Tail        bool       `json:"tail,omitempty"`
+	Carry       string     `json:"carry,omitempty"`
+	Delta       bool       `json:"delta,omitempty"`
+	Delete      bool       `json:"delete,omitempty"`
+	Encryption  string     `json:"encryption,omitempty"`
+	ZstdDictID  string     `json:"dict,omitempty"`
+	ZstdDict    string     `json:"compression_dict,omitempty"`
+	Rows        [][]string `json:"rows"`
+}
+
+// opaqueRows — строки данных хранят blob, а не значения полей
+func (d Data) opaqueRows() bool {
+	return d.Compression != "" || d.Encryption != ""
+}
+
+// ToJSON сериализует пакет в JSON (формат см. jsonPacket).
+// Для REST-клиентов и Kafka-топиков, ожидающих JSON, а не XML-строку.
+// Обратная операция — Parser.ParseJSON; XML ↔ JSON преобразуются без потерь.
+func (g *Generator) ToJSON(packet *DataPacket) ([]byte, error) {
+	jp := jsonPacket{
+		Protocol:        packet.Protocol,
+		Version:         packet.Version,
+		XXH3:            packet.XXH3,
+		Header:          packet.Header,
+		Query:           packet.Query,
+		QueryContext:    packet.QueryContext,
+		PipelineContext: packet.PipelineContext,
+		Schema:          packet.Schema,
+		AlarmDetails:    packet.AlarmDetails,
+		Data: jsonData{
+			Compression: packet.Data.Compression,
+			Checksum:    packet.Data.Checksum,
+			XXH3:        packet.Data.XXH3,
+			Compact:     packet.Data.Compact,
+			Tail:        packet.Data.Tail,
+			Carry:       packet.Data.Carry,
+			Delta:       packet.Data.Delta,
+			Delete:      packet.Data.Delete,
+			Encryption:  packet.Data.Encryption,
+			ZstdDictID:  packet.Data.ZstdDictID,
+			ZstdDict:    packet.Data.ZstdDict,
+		},
+	}
+
+	switch {
+	case packet.Data.opaqueRows():
+		jp.Data.Rows = make([][]string, len(packet.Data.Rows))
+		for i, row := range packet.Data.Rows {
+			jp.Data.Rows[i] = []string{row.Value}
+		}
+	default:
+		jp.Data.Rows = packet.GetRows() // rawRows (GenerateReference) или разбор Data.Rows
+	}
+	if jp.Data.Rows == nil {
+		jp.Data.Rows = [][]string{}
+	}
+
+	data, err := json.Marshal(jp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+	return data, nil
+}
+
+// ParseJSON парсит пакет из JSON (формат Generator.ToJSON).
+// Валидация и разворачивание compact-строк — как в Parse.
+func (p *Parser) ParseJSON(data []byte) (*DataPacket, error) {
+	var jp jsonPacket
+	if err := json.Unmarshal(data, &jp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal JSON: %w", err)
+	}
+
+	packet := &DataPacket{
+		Protocol:        jp.Protocol,
+		Version:         jp.Version,
+		XXH3:            jp.XXH3,
+		Header:          jp.Header,
+		Query:           jp.Query,
+		QueryContext:    jp.QueryContext,
+		PipelineContext: jp.PipelineContext,
+		Schema:          jp.Schema,
+		AlarmDetails:    jp.AlarmDetails,
+		Data: Data{
+			Compression: jp.Data.Compression,
+			Checksum:    jp.Data.Checksum,
+			XXH3:        jp.Data.XXH3,
+			Compact:     jp.Data.Compact,
+			Tail:        jp.Data.Tail,
+			Carry:       jp.Data.Carry,
+			Delta:       jp.Data.Delta,
+			Delete:      jp.Data.Delete,
+			Encryption:  jp.Data.Encryption,
+			ZstdDictID:  jp.Data.ZstdDictID,
+			ZstdDict:    jp.Data.ZstdDict,
+		},
+	}
+
+	if packet.Data.opaqueRows() {
+		packet.Data.Rows = make([]Row, len(jp.Data.Rows))
+		for i, row := range jp.Data.Rows {
+			if len(row) != 1 {
+				return nil, fmt.Errorf("compressed/encrypted row %d must hold exactly one value, got %d", i, len(row))
+			}
+			packet.Data.Rows[i] = Row{Value: row[0]}
+		}
+	} else if len(jp.Data.Rows) > 0 {
+		packet.Data.Rows = RowsToData(jp.Data.Rows).Rows
+	}
+
+	if err := p.validatePacket(packet); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	if packet.Data.Compact && packet.Data.Compression == "" {
+		if err := ExpandCompactRows(packet); err != nil {
+			return nil, fmt.Errorf("compact expansion failed: %w", err)
+		}
+	}
+
+	return packet, nil
+}
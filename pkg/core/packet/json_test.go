@@ -0,0 +1,166 @@
+package packet
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func jsonTestPacket(t *testing.T) *DataPacket {
+	t.Helper()
+	schema := Schema{Fields: []Field{
+		{Name: "id", Type: "INTEGER", Key: true},
+		{Name: "name", Type: "TEXT", Length: 100, Collation: "ru"},
+		{Name: "price", Type: "DECIMAL", Precision: 10, Scale: 2,
+			SpecialValues: &SpecialValues{Null: &MarkerValue{Marker: "[NULL]"}}},
+		{Name: "created", Type: "TIMESTAMP", Timezone: "UTC"},
+	}}
+	rows := [][]string{
+		{"1", "pipe|and\\backslash", "10.50", "2024-01-15T10:30:00Z"},
+		{"2", "line1\nline2", "[NULL]", ""},
+		{"3", "", "0.00", "2024-02-01T00:00:00Z"},
+	}
+	packets, err := NewGenerator().GenerateReference("products", schema, rows)
+	if err != nil {
+		t.Fatalf("GenerateReference: %v", err)
+	}
+	pkt := packets[0]
+
+	query := NewQuery()
+	query.Filters = &Filters{And: &LogicalGroup{Filters: []Filter{{Field: "price", Operator: "gt", Value: "5", Cast: "DECIMAL"}}}}
+	query.OrderBy = &OrderBy{Fields: []OrderField{{Name: "id", Direction: "DESC"}}}
+	query.Limit = 10
+	pkt.Query = query
+	pkt.QueryContext = &QueryContext{
+		OriginalQuery:    *query,
+		ExecutionResults: ExecutionResults{TotalRecordsInTable: 3, RecordsAfterFilters: 3, RecordsReturned: 3},
+		ExecutionPlan:    &ExecutionPlan{Strategy: PlanMaterialize, EstimatedRows: 3, Selectivity: 0.5},
+	}
+	pkt.PipelineContext = &PipelineContext{
+		Pipeline:  PipelineInfo{Name: "nightly", Version: "2"},
+		Variables: []PipelineVar{{Name: "dept", Value: "sales"}},
+	}
+	pkt.Header.Priority = PriorityHigh
+	return pkt
+}
+
+func TestJSON_RoundTrip(t *testing.T) {
+	gen := NewGenerator()
+	original := jsonTestPacket(t)
+
+	data, err := gen.ToJSON(original)
+	if err != nil {
+		t.Fatalf("ToJSON: %v", err)
+	}
+	parsed, err := NewParser().ParseJSON(data)
+	if err != nil {
+		t.Fatalf("ParseJSON: %v", err)
+	}
+
+	if !slices.EqualFunc(parsed.GetRows(), original.GetRows(), slices.Equal[[]string]) {
+		t.Errorf("rows = %q, want %q", parsed.GetRows(), original.GetRows())
+	}
+	if !parsed.Header.Timestamp.Equal(original.Header.Timestamp) {
+		t.Errorf("timestamp = %v, want %v", parsed.Header.Timestamp, original.Header.Timestamp)
+	}
+
+	if !reflect.DeepEqual(parsed.Query, original.Query) ||
+		!reflect.DeepEqual(parsed.QueryContext, original.QueryContext) ||
+		!reflect.DeepEqual(parsed.PipelineContext, original.PipelineContext) ||
+		!reflect.DeepEqual(parsed.Schema, original.Schema) {
+		t.Errorf("sections differ after round trip:\n got: %+v\nwant: %+v", parsed, original)
+	}
+
+	// XML → JSON: пакет, прочитанный из XML, даёт тот же JSON
+	xmlData, err := gen.ToXML(original, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromXML, err := NewParser().ParseBytes(xmlData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := gen.ToJSON(fromXML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(again, data) {
+		t.Errorf("JSON of XML-parsed packet differs:\n got: %s\nwant: %s", again, data)
+	}
+}
+
+func TestJSON_Shape(t *testing.T) {
+	data, err := NewGenerator().ToJSON(jsonTestPacket(t))
+	if err != nil {
+		t.Fatalf("ToJSON: %v", err)
+	}
+
+	var doc struct {
+		Header struct {
+			TableName string `json:"table_name"`
+			Priority  int    `json:"priority"`
+		} `json:"header"`
+		Schema struct {
+			Fields []struct {
+				Name string `json:"name"`
+			} `json:"fields"`
+		} `json:"schema"`
+		Data struct {
+			Rows [][]string `json:"rows"`
+		} `json:"data"`
+		QueryContext struct {
+			ExecutionResults struct {
+				RecordsReturned int `json:"records_returned"`
+			} `json:"execution_results"`
+		} `json:"query_context"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if doc.Header.TableName != "products" || doc.Header.Priority != PriorityHigh {
+		t.Errorf("header = %+v", doc.Header)
+	}
+	if len(doc.Schema.Fields) != 4 || doc.QueryContext.ExecutionResults.RecordsReturned != 3 {
+		t.Errorf("schema/query_context not serialized: %s", data)
+	}
+	// Значения без TDTP-экранирования
+	if got := doc.Data.Rows[0][1]; got != "pipe|and\\backslash" {
+		t.Errorf("rows[0][1] = %q", got)
+	}
+}
+
+func TestJSON_OpaqueRows(t *testing.T) {
+	pkt := NewDataPacket(TypeReference, "products")
+	pkt.Schema = Schema{Fields: []Field{{Name: "id", Type: "INTEGER"}}}
+	pkt.Data = Data{Compression: "zstd", Checksum: "abc", Rows: []Row{{Value: `KLUv|/QBYAQ\`}}}
+
+	data, err := NewGenerator().ToJSON(pkt)
+	if err != nil {
+		t.Fatalf("ToJSON: %v", err)
+	}
+	parsed, err := NewParser().ParseJSON(data)
+	if err != nil {
+		t.Fatalf("ParseJSON: %v", err)
+	}
+	if len(parsed.Data.Rows) != 1 || parsed.Data.Rows[0] != pkt.Data.Rows[0] || parsed.Data.Checksum != "abc" {
+		t.Errorf("compressed data = %+v, want %+v", parsed.Data, pkt.Data)
+	}
+
+	bad := strings.Replace(string(data), `["KLUv|/QBYAQ\\"]`, `["a","b"]`, 1)
+	if _, err := NewParser().ParseJSON([]byte(bad)); err == nil {
+		t.Error("expected error for compressed row with two values")
+	}
+}
+
+func TestParseJSON_Validation(t *testing.T) {
+	parser := NewParser()
+	if _, err := parser.ParseJSON([]byte(`{"protocol":"TDTP"`)); err == nil {
+		t.Error("expected error for malformed JSON")
+	}
+	if _, err := parser.ParseJSON([]byte(`{"protocol":"TDTP","version":"1.0","header":{"type":"reference"}}`)); err == nil {
+		t.Error("expected validation error for missing table name")
+	}
+}
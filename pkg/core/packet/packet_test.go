@@ -1,6 +1,7 @@
 package packet
 
 import (
+	"bytes"
 	"fmt"
 	"strings"
 	"testing"
@@ -269,6 +270,69 @@ func TestPartitioning(t *testing.T) {
 	}
 }
 
+func TestPartitioning_MaxRows(t *testing.T) {
+	generator := NewGenerator()
+	generator.SetMaxRowsPerPacket(30)
+
+	schema := Schema{Fields: []Field{{Name: "ID", Type: "INTEGER"}}}
+	rows := make([][]string, 100)
+	for i := range rows {
+		rows[i] = []string{fmt.Sprintf("%d", i)}
+	}
+
+	packets, err := generator.GenerateReference("TestTable", schema, rows)
+	if err != nil {
+		t.Fatalf("GenerateReference failed: %v", err)
+	}
+	want := []int{30, 30, 30, 10}
+	if len(packets) != len(want) {
+		t.Fatalf("got %d packets, want %d", len(packets), len(want))
+	}
+	for i, p := range packets {
+		if n := len(p.GetRows()); n != want[i] {
+			t.Errorf("packet %d: %d rows, want %d", i+1, n, want[i])
+		}
+	}
+}
+
+func TestPartitioning_XMLEstimate(t *testing.T) {
+	const maxBytes = 200_000
+	generator := NewGenerator()
+	generator.SetMaxMessageSize(maxBytes)
+	if err := generator.SetSizeEstimate(SizeEstimateXML); err != nil {
+		t.Fatal(err)
+	}
+
+	schema := Schema{Fields: []Field{{Name: "ID", Type: "INTEGER"}, {Name: "Data", Type: "TEXT"}}}
+	rows := make([][]string, 10_000)
+	for i := range rows {
+		rows[i] = []string{fmt.Sprintf("%d", i), "a|b <&> " + strings.Repeat("x", 40)}
+	}
+
+	packets, err := generator.GenerateReference("TestTable", schema, rows)
+	if err != nil {
+		t.Fatalf("GenerateReference failed: %v", err)
+	}
+	if len(packets) < 2 {
+		t.Fatalf("expected several packets, got %d", len(packets))
+	}
+	for i, p := range packets[:len(packets)-1] {
+		var buf bytes.Buffer
+		if err := generator.WriteToWriter(p, &buf); err != nil {
+			t.Fatal(err)
+		}
+		// Оценка — реальный размер XML: часть не больше предела и не
+		// вдвое меньше (как дала бы UTF-16 оценка)
+		if buf.Len() > maxBytes || buf.Len() < maxBytes*3/4 {
+			t.Errorf("packet %d: %d bytes of XML, want close to %d", i+1, buf.Len(), maxBytes)
+		}
+	}
+
+	if err := generator.SetSizeEstimate("utf8"); err == nil {
+		t.Error("unknown size estimate mode must be rejected")
+	}
+}
+
 func TestValidation(t *testing.T) {
 	parser := NewParser()
 
@@ -285,3 +349,49 @@ func TestValidation(t *testing.T) {
 		t.Error("Expected validation error for missing TableName")
 	}
 }
+
+func TestPriorityRoundTrip(t *testing.T) {
+	pkt := NewDataPacket(TypeReference, "BlockedCustomers")
+	pkt.Header.Priority = PriorityUrgent
+	pkt.Schema = Schema{Fields: []Field{{Name: "CustomerID", Type: "INTEGER", Key: true}}}
+	pkt.SetRows([][]string{{"1"}})
+
+	xmlData, err := NewGenerator().ToXML(pkt, false)
+	if err != nil {
+		t.Fatalf("ToXML failed: %v", err)
+	}
+
+	if got := PeekPriority(xmlData); got != PriorityUrgent {
+		t.Errorf("PeekPriority = %d, want %d", got, PriorityUrgent)
+	}
+
+	parsed, err := NewParser().ParseBytes(xmlData)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if !parsed.Header.IsUrgent() {
+		t.Errorf("expected parsed header to be urgent, got priority %d", parsed.Header.Priority)
+	}
+}
+
+func TestPeekPriority(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want int
+	}{
+		{"no priority tag", `<DataPacket><Header><Type>reference</Type></Header></DataPacket>`, PriorityNormal},
+		{"explicit value", `<DataPacket><Header><Priority>5</Priority></Header></DataPacket>`, PriorityHigh},
+		{"clamped above max", `<DataPacket><Header><Priority>42</Priority></Header></DataPacket>`, MaxPriority},
+		{"garbage value", `<DataPacket><Header><Priority>x</Priority></Header></DataPacket>`, PriorityNormal},
+		{"tag outside header ignored", `<DataPacket><Header></Header><Data><R>&lt;Priority&gt;9</R></Data></DataPacket>`, PriorityNormal},
+		{"no header", `<DataPacket/>`, PriorityNormal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PeekPriority([]byte(tt.data)); got != tt.want {
+				t.Errorf("PeekPriority = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,239 @@
+package packet
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// PacketDB — пакеты, загруженные во временную SQL БД (см. OpenAsDB).
+// По таблице на каждое имя Header.TableName; части одной таблицы
+// дописываются в неё же. Закрытие DB уничтожает данные.
+type PacketDB struct {
+	DB     *sql.DB
+	Tables []PacketTable // в порядке первых пакетов таблиц
+}
+
+// PacketTable — таблица PacketDB: схема пакетов и число загруженных строк.
+type PacketTable struct {
+	Name   string
+	Schema Schema
+	Rows   int
+}
+
+// PacketDBConfig — СУБД для OpenAsDBWith. Драйвер регистрирует вызывающий
+// (import _ "modernc.org/sqlite" или _ "github.com/marcboeker/go-duckdb"):
+// пакет packet не тянет зависимостей от СУБД.
+type PacketDBConfig struct {
+	Driver string // имя драйвера database/sql (по умолчанию "sqlite")
+	DSN    string // по умолчанию ":memory:" для sqlite, "" для duckdb
+}
+
+// OpenAsDB загружает пакеты в in-memory SQLite (драйвер "sqlite") и
+// возвращает handle для обычных SQL-запросов по содержимому пакетов —
+// без ETL workspace и адаптеров. Закрывать через Close.
+func OpenAsDB(ctx context.Context, packets ...*DataPacket) (*PacketDB, error) {
+	return OpenAsDBWith(ctx, PacketDBConfig{}, packets...)
+}
+
+// OpenAsDBWith — OpenAsDB с явным драйвером и DSN.
+//
+// Типы колонок: INTEGER, REAL, NUMERIC (DECIMAL), INTEGER 0/1 (BOOLEAN),
+// DATE, TIMESTAMP (DATETIME), BLOB, остальное TEXT. Маркеры NULL и NoDate
+// из SpecialValues, а также пустые значения нетекстовых полей загружаются
+// как NULL. Compact-пакеты разворачиваются; сжатые, зашифрованные, delta и
+// delete пакеты не принимаются — их нужно сначала распаковать/применить.
+func OpenAsDBWith(ctx context.Context, cfg PacketDBConfig, packets ...*DataPacket) (*PacketDB, error) {
+	if cfg.Driver == "" {
+		cfg.Driver = "sqlite"
+	}
+	if cfg.DSN == "" && cfg.Driver == "sqlite" {
+		cfg.DSN = ":memory:"
+	}
+
+	db, err := sql.Open(cfg.Driver, cfg.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open packet database: %w", err)
+	}
+	// Каждое соединение к :memory: — отдельная пустая БД
+	db.SetMaxOpenConns(1)
+
+	pdb := &PacketDB{DB: db}
+	if err := pdb.load(ctx, packets); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return pdb, nil
+}
+
+// Close закрывает БД вместе с данными
+func (p *PacketDB) Close() error {
+	return p.DB.Close()
+}
+
+// Table возвращает таблицу по имени (без учёта регистра)
+func (p *PacketDB) Table(name string) (PacketTable, bool) {
+	for _, t := range p.Tables {
+		if strings.EqualFold(t.Name, name) {
+			return t, true
+		}
+	}
+	return PacketTable{}, false
+}
+
+// load создаёт таблицы и вставляет строки пакетов
+func (p *PacketDB) load(ctx context.Context, packets []*DataPacket) error {
+	index := make(map[string]int) // имя таблицы → индекс в p.Tables
+	for _, pkt := range packets {
+		if pkt == nil {
+			continue
+		}
+		name := pkt.Header.TableName
+		if err := checkPacketDBPacket(pkt); err != nil {
+			return fmt.Errorf("table %s, part %d: %w", name, pkt.Header.PartNumber, err)
+		}
+		if pkt.Data.Compact {
+			expanded := *pkt // ExpandCompactRows меняет пакет — разворачиваем копию
+			if err := ExpandCompactRows(&expanded); err != nil {
+				return fmt.Errorf("table %s, part %d: %w", name, pkt.Header.PartNumber, err)
+			}
+			pkt = &expanded
+		}
+
+		i, ok := index[name]
+		if !ok {
+			if _, err := p.DB.ExecContext(ctx, packetDBCreateTable(name, pkt.Schema)); err != nil {
+				return fmt.Errorf("failed to create table %s: %w", name, err)
+			}
+			i = len(p.Tables)
+			index[name] = i
+			p.Tables = append(p.Tables, PacketTable{Name: name, Schema: pkt.Schema})
+		} else if !SchemaEquals(p.Tables[i].Schema, pkt.Schema) {
+			return fmt.Errorf("table %s, part %d: schema differs from previous parts", name, pkt.Header.PartNumber)
+		}
+
+		n, err := p.insertRows(ctx, name, pkt.Schema, pkt.GetRows())
+		if err != nil {
+			return fmt.Errorf("table %s, part %d: %w", name, pkt.Header.PartNumber, err)
+		}
+		p.Tables[i].Rows += n
+	}
+	return nil
+}
+
+// checkPacketDBPacket отклоняет пакеты, строки которых нельзя вставить как есть
+func checkPacketDBPacket(pkt *DataPacket) error {
+	switch {
+	case pkt.Header.TableName == "":
+		return fmt.Errorf("packet has no table name")
+	case len(pkt.Schema.Fields) == 0:
+		return fmt.Errorf("packet has no schema")
+	case pkt.Schema.Encryption != "" || pkt.Data.Encryption != "":
+		return fmt.Errorf("packet is encrypted")
+	case pkt.Data.Compression != "":
+		return fmt.Errorf("packet is compressed (%s), decompress it first", pkt.Data.Compression)
+	case pkt.Data.Delta || pkt.Data.Delete:
+		return fmt.Errorf("delta and delete packets cannot be loaded")
+	}
+	return nil
+}
+
+// insertRows вставляет строки в одной транзакции
+func (p *PacketDB) insertRows(ctx context.Context, table string, schema Schema, rows [][]string) (int, error) {
+	if len(rows) == 0 {
+		return 0, nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(schema.Fields)), ", ")
+	insertSQL := fmt.Sprintf("INSERT INTO %q VALUES (%s)", table, placeholders)
+
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }() // no-op после Commit
+
+	stmt, err := tx.PrepareContext(ctx, insertSQL)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prepare insert: %w", err)
+	}
+	defer func() { _ = stmt.Close() }()
+
+	args := make([]any, len(schema.Fields))
+	for r, row := range rows {
+		if len(row) != len(schema.Fields) {
+			return 0, fmt.Errorf("row %d has %d values, expected %d", r, len(row), len(schema.Fields))
+		}
+		for i, f := range schema.Fields {
+			args[i] = packetDBValue(f, row[i])
+		}
+		if _, err := stmt.ExecContext(ctx, args...); err != nil {
+			return 0, fmt.Errorf("failed to insert row %d: %w", r, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit: %w", err)
+	}
+	return len(rows), nil
+}
+
+// packetDBCreateTable формирует CREATE TABLE по схеме пакета
+func packetDBCreateTable(table string, schema Schema) string {
+	columns := make([]string, len(schema.Fields))
+	for i, f := range schema.Fields {
+		columns[i] = fmt.Sprintf("%q %s", f.Name, packetDBColumnType(f.Type))
+	}
+	return fmt.Sprintf("CREATE TABLE %q (%s)", table, strings.Join(columns, ", "))
+}
+
+// packetDBColumnType — тип колонки для TDTP типа. Имена понимают и SQLite
+// (по type affinity), и DuckDB.
+func packetDBColumnType(tdtpType string) string {
+	switch strings.ToUpper(tdtpType) {
+	case "INTEGER", "INT":
+		return "INTEGER"
+	case "REAL", "FLOAT", "DOUBLE":
+		return "REAL"
+	case "DECIMAL", "NUMERIC":
+		return "NUMERIC"
+	case "BOOLEAN", "BOOL":
+		return "INTEGER"
+	case "DATE":
+		return "DATE"
+	case "DATETIME", "TIMESTAMP":
+		return "TIMESTAMP"
+	case "BLOB":
+		return "BLOB"
+	default:
+		return "TEXT"
+	}
+}
+
+// packetDBValue — значение ячейки для вставки: NULL по маркерам
+// SpecialValues и для пустых нетекстовых значений, BOOLEAN — 0/1
+func packetDBValue(f Field, v string) any {
+	if sv := f.SpecialValues; sv != nil {
+		if sv.Null != nil && v == sv.Null.Marker {
+			return nil
+		}
+		if sv.NoDate != nil && v == sv.NoDate.Marker {
+			return nil
+		}
+	}
+
+	columnType := packetDBColumnType(f.Type)
+	if v == "" && columnType != "TEXT" {
+		return nil
+	}
+	if strings.EqualFold(f.Type, "BOOLEAN") || strings.EqualFold(f.Type, "BOOL") {
+		switch strings.ToLower(v) {
+		case "1", "true", "t", "yes":
+			return 1
+		default:
+			return 0
+		}
+	}
+	return v
+}
@@ -0,0 +1,71 @@
+package packet
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestPacketDBCreateTable(t *testing.T) {
+	schema := Schema{Fields: []Field{
+		{Name: "id", Type: "INTEGER", Key: true},
+		{Name: "amount", Type: "DECIMAL", Precision: 12, Scale: 2},
+		{Name: "active", Type: "BOOLEAN"},
+		{Name: "created", Type: "DATETIME"},
+		{Name: "note", Type: "UUID"},
+	}}
+	got := packetDBCreateTable("orders", schema)
+	want := `CREATE TABLE "orders" ("id" INTEGER, "amount" NUMERIC, "active" INTEGER, "created" TIMESTAMP, "note" TEXT)`
+	if got != want {
+		t.Errorf("DDL = %s\nwant  %s", got, want)
+	}
+}
+
+func TestPacketDBValue(t *testing.T) {
+	withNull := Field{Name: "price", Type: "REAL", SpecialValues: &SpecialValues{
+		Null: &MarkerValue{Marker: SpecNullMarker},
+	}}
+	tests := []struct {
+		field Field
+		value string
+		want  any
+	}{
+		{withNull, SpecNullMarker, nil},
+		{withNull, "1.5", "1.5"},
+		{Field{Type: "INTEGER"}, "", nil},
+		{Field{Type: "TEXT"}, "", ""},
+		{Field{Type: "BOOLEAN"}, "true", 1},
+		{Field{Type: "BOOLEAN"}, "0", 0},
+		{Field{Type: "BOOLEAN"}, "", nil},
+	}
+	for _, tt := range tests {
+		if got := packetDBValue(tt.field, tt.value); got != tt.want {
+			t.Errorf("packetDBValue(%s, %q) = %v, want %v", tt.field.Type, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestOpenAsDB_RejectsUnloadablePackets(t *testing.T) {
+	schema := Schema{Fields: []Field{{Name: "id", Type: "INTEGER"}}}
+
+	compressed := NewDataPacket(TypeReference, "orders")
+	compressed.Schema = schema
+	compressed.Data.Compression = "zstd"
+
+	delta := NewDataPacket(TypeReference, "orders")
+	delta.Schema = schema
+	delta.Data.Delta = true
+
+	for _, pkt := range []*DataPacket{compressed, delta, NewDataPacket(TypeReference, "orders")} {
+		err := checkPacketDBPacket(pkt)
+		if err == nil {
+			t.Errorf("expected error for %+v", pkt.Data)
+		}
+	}
+
+	// Без зарегистрированного драйвера — ошибка открытия, а не panic
+	_, err := OpenAsDBWith(context.Background(), PacketDBConfig{Driver: "tdtp-no-such-driver"})
+	if err == nil || !strings.Contains(err.Error(), "packet database") {
+		t.Errorf("expected open error, got %v", err)
+	}
+}
@@ -52,6 +52,13 @@ func (p *Parser) Parse(r io.Reader) (*DataPacket, error) {
 		}
 	}
 
+	if err := ExpandColumnarRows(&packet); err != nil {
+		return nil, fmt.Errorf("columnar expansion failed: %w", err)
+	}
+	if err := verifyParsedChecksum(&packet); err != nil {
+		return nil, err
+	}
+
 	return &packet, nil
 }
 
@@ -66,9 +73,26 @@ func (p *Parser) ParseBytes(data []byte) (*DataPacket, error) {
 		return nil, fmt.Errorf("validation failed: %w", err)
 	}
 
+	if err := ExpandColumnarRows(&packet); err != nil {
+		return nil, fmt.Errorf("columnar expansion failed: %w", err)
+	}
+	if err := verifyParsedChecksum(&packet); err != nil {
+		return nil, err
+	}
+
 	return &packet, nil
 }
 
+// verifyParsedChecksum проверяет Header.Checksum разобранного пакета, если
+// строки уже развёрнуты. Сжатые, зашифрованные и compact-строки проверяются
+// после распаковки (DecompressPacketData, Parse*WithDecompression).
+func verifyParsedChecksum(packet *DataPacket) error {
+	if packet.Header.Checksum == nil || packet.Data.opaqueRows() || packet.Data.Compact {
+		return nil
+	}
+	return VerifyRowChecksum(packet)
+}
+
 // validatePacket выполняет базовую валидацию пакета
 func (p *Parser) validatePacket(packet *DataPacket) error {
 	// Проверка обязательных полей
@@ -97,6 +121,10 @@ func (p *Parser) validatePacket(packet *DataPacket) error {
 		return fmt.Errorf("header.Timestamp is required")
 	}
 
+	if err := packet.Header.Extensions.Validate(); err != nil {
+		return fmt.Errorf("header: %w", err)
+	}
+
 	// Проверка типа сообщения
 	switch packet.Header.Type {
 	case TypeReference, TypeRequest, TypeResponse, TypeAlarm, TypeError:
@@ -130,17 +158,44 @@ func (p *Parser) validatePacket(packet *DataPacket) error {
 	// v1.5: зашифрованная Schema/Data не содержит Fields до расшифровки —
 	// обе проверки ниже бессмысленны для ciphertext и пропускаются.
 	if packet.Schema.Encryption == "" && packet.Data.Encryption == "" {
-		if len(packet.Data.Rows) > 0 && len(packet.Schema.Fields) == 0 {
+		if (len(packet.Data.Rows) > 0 || len(packet.Data.Columns) > 0) && len(packet.Schema.Fields) == 0 {
 			return fmt.Errorf("schema is required when data is present")
 		}
 	}
 
+	// Delta-строки не совместимы с compact: пропуски fixed-полей
+	// не имеют смысла для троек «поле, old, new».
+	if packet.Data.Delta && packet.Data.Compact {
+		return fmt.Errorf("delta packets cannot use compact format")
+	}
+	if packet.Data.Delete && (packet.Data.Delta || packet.Data.Compact) {
+		return fmt.Errorf("delete packets cannot be delta or compact")
+	}
+
+	switch packet.Data.Layout {
+	case "":
+	case LayoutColumnar:
+		d := &packet.Data
+		if d.Compression != "" || d.Encryption != "" || d.Compact || d.Delta || d.Delete {
+			return fmt.Errorf("columnar layout cannot be combined with compression, encryption, compact, delta or delete")
+		}
+		if len(d.Rows) > 0 {
+			return fmt.Errorf("columnar layout cannot contain <R> rows")
+		}
+	default:
+		return fmt.Errorf("unknown data layout: %s", packet.Data.Layout)
+	}
+
 	// RecordsInPart должен точно совпадать с числом <R> строк.
 	// Для сжатых пакетов строки упакованы в blob — проверка невозможна без декомпрессии.
 	// Начиная с v1.4 целостность гарантируется XXH3 — проверка счётчика избыточна.
 	// v1.5: зашифрованные строки тоже упакованы в один opaque <R> — тот же случай.
 	if packet.Header.RecordsInPart > 0 && packet.Data.Compression == "" && packet.Data.Encryption == "" && NeedsRowCountCheck(packet.Version) {
-		if actual := len(packet.Data.Rows); actual != packet.Header.RecordsInPart {
+		actual := len(packet.Data.Rows)
+		if packet.Data.Layout == LayoutColumnar {
+			actual = columnarRowCount(&packet.Data)
+		}
+		if actual != packet.Header.RecordsInPart {
 			return fmt.Errorf("RecordsInPart mismatch: header declares %d rows, <Data> contains %d",
 				packet.Header.RecordsInPart, actual)
 		}
@@ -166,6 +221,16 @@ func (p *Parser) GetCompressionAlgorithm(packet *DataPacket) string {
 	return packet.Data.Compression
 }
 
+// dictionaryLoader регистрирует встроенный в пакет словарь сжатия
+// (устанавливается пакетом processors — core/packet не зависит от zstd).
+var dictionaryLoader func(encoded string) error
+
+// SetDictionaryLoader устанавливает обработчик встроенных словарей сжатия
+// (<Data><CompressionDict>), вызываемый DecompressData перед распаковкой.
+func SetDictionaryLoader(fn func(encoded string) error) {
+	dictionaryLoader = fn
+}
+
 // DecompressData распаковывает сжатые данные в пакете
 // decompressor - функция распаковки, принимает сжатую строку и algo ("zstd", "kanzi" и т.д.)
 // Если данные не сжаты, возвращает их как есть
@@ -185,6 +250,13 @@ func (p *Parser) DecompressData(ctx context.Context, packet *DataPacket, decompr
 		return fmt.Errorf("compressed data should have exactly 1 row, got %d", len(packet.Data.Rows))
 	}
 
+	// Встроенный словарь регистрируется до распаковки
+	if packet.Data.ZstdDict != "" && dictionaryLoader != nil {
+		if err := dictionaryLoader(packet.Data.ZstdDict); err != nil {
+			return fmt.Errorf("embedded dictionary: %w", err)
+		}
+	}
+
 	// Распаковываем
 	compressedData := packet.Data.Rows[0].Value
 	decompressedRows, err := decompressor(ctx, compressedData, packet.Data.Compression)
@@ -194,6 +266,8 @@ func (p *Parser) DecompressData(ctx context.Context, packet *DataPacket, decompr
 
 	// Восстанавливаем структуру Data
 	packet.Data.Compression = "" // Очищаем флаг сжатия
+	packet.Data.ZstdDictID = ""
+	packet.Data.ZstdDict = ""
 	packet.Data.Rows = make([]Row, len(decompressedRows))
 	for i, rowStr := range decompressedRows {
 		packet.Data.Rows[i] = Row{Value: rowStr}
@@ -221,6 +295,9 @@ func (p *Parser) ParseWithDecompression(r io.Reader, decompressor func(ctx conte
 				return nil, fmt.Errorf("compact expansion failed: %w", err)
 			}
 		}
+		if err := verifyParsedChecksum(packet); err != nil {
+			return nil, err
+		}
 	}
 
 	return packet, nil
@@ -245,6 +322,9 @@ func (p *Parser) ParseBytesWithDecompression(data []byte, decompressor func(ctx
 				return nil, fmt.Errorf("compact expansion failed: %w", err)
 			}
 		}
+		if err := verifyParsedChecksum(packet); err != nil {
+			return nil, err
+		}
 	}
 
 	return packet, nil
@@ -1,48 +1,133 @@
 package packet
 
+import "time"
+
 // Query представляет TDTQL запрос
 type Query struct {
-	Language string   `xml:"language,attr"`
-	Version  string   `xml:"version,attr"`
-	Fields   []string `xml:"Fields>Field,omitempty"` // column projection: nil/empty = SELECT *
-	Filters  *Filters `xml:"Filters,omitempty"`
-	OrderBy  *OrderBy `xml:"OrderBy,omitempty"`
-	Limit    int      `xml:"Limit,omitempty"`
-	Offset   int      `xml:"Offset,omitempty"`
+	Language string   `xml:"language,attr"          json:"language"`
+	Version  string   `xml:"version,attr"           json:"version"`
+	Fields   []string `xml:"Fields>Field,omitempty" json:"fields,omitempty"` // column projection: nil/empty = SELECT *
+	Filters  *Filters `xml:"Filters,omitempty"      json:"filters,omitempty"`
+	OrderBy  *OrderBy `xml:"OrderBy,omitempty"      json:"order_by,omitempty"`
+	Limit    int      `xml:"Limit,omitempty"        json:"limit,omitempty"`
+	Offset   int      `xml:"Offset,omitempty"       json:"offset,omitempty"`
+	After    []string `xml:"After>Value,omitempty"  json:"after,omitempty"` // keyset: ключ последней строки предыдущей страницы (ExecutionResults.NextAfter)
+	Join     *Join    `xml:"Join,omitempty"         json:"join,omitempty"`
+}
+
+// Типы соединения (Join.Type)
+const (
+	JoinInner = "inner"
+	JoinLeft  = "left"
+)
+
+// Join соединяет таблицу запроса с другой таблицей того же источника.
+//
+// Строка результата — поля основной таблицы под своими именами, за ними
+// поля присоединённой таблицы с префиксом "<Table>." ("customers.name"):
+// так на них ссылаются Fields, Filters и OrderBy. В LEFT-соединении поля
+// строки без пары пусты (NULL). Пустой Type — inner.
+type Join struct {
+	Table string   `xml:"table,attr"          json:"table"`
+	Type  string   `xml:"type,attr,omitempty" json:"type,omitempty"`
+	On    []JoinOn `xml:"On"                  json:"on"`
+}
+
+// JoinOn — условие равенства поля основной таблицы (Left) и поля
+// присоединённой (Right). Условия объединяются через AND.
+type JoinOn struct {
+	Left  string `xml:"left,attr"  json:"left"`
+	Right string `xml:"right,attr" json:"right"`
+}
+
+// JoinedField возвращает имя поля присоединённой таблицы в строке результата.
+func (j *Join) JoinedField(name string) string {
+	return j.Table + "." + name
 }
 
 // Filters содержит дерево условий фильтрации
+//
+// Collation — коллация по умолчанию для условий над текстовыми полями
+// (см. Filter.Collation); Filter.Collation условия её переопределяет.
 type Filters struct {
-	And *LogicalGroup `xml:"And,omitempty"`
-	Or  *LogicalGroup `xml:"Or,omitempty"`
+	And       *LogicalGroup `xml:"And,omitempty"            json:"and,omitempty"`
+	Or        *LogicalGroup `xml:"Or,omitempty"             json:"or,omitempty"`
+	Collation string        `xml:"collation,attr,omitempty" json:"collation,omitempty"`
 }
 
 // LogicalGroup представляет логическую группу условий
 type LogicalGroup struct {
-	Filters []Filter       `xml:"Filter,omitempty"`
-	And     []LogicalGroup `xml:"And,omitempty"`
-	Or      []LogicalGroup `xml:"Or,omitempty"`
+	Filters []Filter       `xml:"Filter,omitempty" json:"filters,omitempty"`
+	And     []LogicalGroup `xml:"And,omitempty"    json:"and,omitempty"`
+	Or      []LogicalGroup `xml:"Or,omitempty"     json:"or,omitempty"`
 }
 
 // Filter представляет одно условие фильтрации
+//
+// Cast — тип TDTP (INTEGER, REAL, DECIMAL, DATE, DATETIME, TIMESTAMP, TEXT),
+// в котором сравниваются значения вместо типа поля из схемы. Нужен для
+// таблиц, где числа и даты хранятся в TEXT: без приведения Balance > 1000
+// сравнивается лексикографически ("900" > "1000"). Строка, значение которой
+// не приводится к типу Cast, условию не удовлетворяет.
+//
+// Collation — правила сравнения строк для текстового поля (или Cast TEXT):
+// опции через запятую — "ci" (без учёта регистра), "trim" (без крайних
+// пробелов), BCP 47 тег ("ru", "de") для сравнения по правилам языка;
+// "binary" — побайтово, несмотря на Filters.Collation. Пример: "ci,trim".
+//
+// Trunc — единица усечения дат (day, week, month, quarter, year): значение
+// поля и значения условия сравниваются с точностью до периода, например
+// Trunc "month" с eq '2026-10-01' — все даты октября 2026. Применяется к
+// eq, ne, gt, gte, lt, lte, between, in и not_in над датами.
+//
+// Операторы сверх сравнений: regex / not_regex (регулярное выражение RE2),
+// starts_with / ends_with (префикс / суффикс строки) и last — дата в
+// последнем периоде относительно текущего времени, Value "7 days"
+// (единицы hours, days, weeks, months, years).
 type Filter struct {
-	Field    string `xml:"field,attr"`
-	Operator string `xml:"operator,attr"`
-	Value    string `xml:"value,attr"`
-	Value2   string `xml:"value2,attr,omitempty"` // для between
+	Field     string `xml:"field,attr"                json:"field"`
+	Operator  string `xml:"operator,attr"             json:"operator"`
+	Value     string `xml:"value,attr"                json:"value"`
+	Value2    string `xml:"value2,attr,omitempty"     json:"value2,omitempty"` // для between
+	Cast      string `xml:"cast,attr,omitempty"       json:"cast,omitempty"`
+	Collation string `xml:"collation,attr,omitempty"  json:"collation,omitempty"`
+	Trunc     string `xml:"trunc,attr,omitempty"      json:"trunc,omitempty"`
 }
 
 // OrderBy определяет сортировку
+//
+// Collation — BCP 47 тег ("ru", "de", "sv-u-ks-level2") для текстовых полей
+// при сортировке в памяти. Пусто = коллация из Field.Collation схемы, иначе
+// побайтовый порядок. Задаётся для всего запроса; OrderField.Collation
+// переопределяет её для отдельного поля.
 type OrderBy struct {
-	Field     string       `xml:"field,attr,omitempty"`
-	Direction string       `xml:"direction,attr,omitempty"`
-	Fields    []OrderField `xml:"Field,omitempty"` // множественная сортировка
+	Field     string       `xml:"field,attr,omitempty"     json:"field,omitempty"`
+	Direction string       `xml:"direction,attr,omitempty" json:"direction,omitempty"`
+	Collation string       `xml:"collation,attr,omitempty" json:"collation,omitempty"`
+	Fields    []OrderField `xml:"Field,omitempty"          json:"fields,omitempty"` // множественная сортировка
 }
 
 // OrderField для множественной сортировки
 type OrderField struct {
-	Name      string `xml:"name,attr"`
-	Direction string `xml:"direction,attr"`
+	Name      string `xml:"name,attr"                json:"name"`
+	Direction string `xml:"direction,attr"           json:"direction"`
+	Collation string `xml:"collation,attr,omitempty" json:"collation,omitempty"`
+}
+
+// HasCollation сообщает, запрошена ли явная коллация хотя бы для одного поля.
+func (o *OrderBy) HasCollation() bool {
+	if o == nil {
+		return false
+	}
+	if o.Collation != "" {
+		return true
+	}
+	for _, f := range o.Fields {
+		if f.Collation != "" {
+			return true
+		}
+	}
+	return false
 }
 
 // QueryContext содержит контекст выполнения запроса (в response)
@@ -53,40 +138,136 @@ type OrderField struct {
 // opaque ciphertext in Encrypted instead. Always check Encryption before
 // reading the other fields; see docs/tdtp-protocol-schema.md → "v1.5".
 type QueryContext struct {
-	OriginalQuery    Query             `xml:"OriginalQuery"`
-	ExecutionResults ExecutionResults  `xml:"ExecutionResults"`
-	FilterStatistics *FilterStatistics `xml:"FilterStatistics,omitempty"`
-	Encryption       string            `xml:"encryption,attr,omitempty"` // v1.5: "aes-256-gcm" if Encrypted holds ciphertext
-	Encrypted        string            `xml:",chardata"`                 // v1.5: base64(nonce||ciphertext) when Encryption != ""
+	OriginalQuery    Query             `xml:"OriginalQuery"              json:"original_query"`
+	ExecutionResults ExecutionResults  `xml:"ExecutionResults"           json:"execution_results"`
+	FilterStatistics *FilterStatistics `xml:"FilterStatistics,omitempty" json:"filter_statistics,omitempty"`
+	ExecutionPlan    *ExecutionPlan    `xml:"ExecutionPlan,omitempty"    json:"execution_plan,omitempty"`
+	Encryption       string            `xml:"encryption,attr,omitempty"  json:"encryption,omitempty"` // v1.5: "aes-256-gcm" if Encrypted holds ciphertext
+	Encrypted        string            `xml:",chardata"                  json:"encrypted,omitempty"`  // v1.5: base64(nonce||ciphertext) when Encryption != ""
 }
 
 // ExecutionResults содержит результаты выполнения
+//
+// NextAfter — значения ключевых полей последней строки страницы: передаются
+// в Query.After следующего запроса (keyset-пагинация). В отличие от
+// NextOffset, не сдвигается от вставок и удалений между запросами.
 type ExecutionResults struct {
-	TotalRecordsInTable int  `xml:"TotalRecordsInTable"`
-	RecordsAfterFilters int  `xml:"RecordsAfterFilters"`
-	RecordsReturned     int  `xml:"RecordsReturned"`
-	MoreDataAvailable   bool `xml:"MoreDataAvailable"`
-	NextOffset          int  `xml:"NextOffset,omitempty"`
+	TotalRecordsInTable int      `xml:"TotalRecordsInTable"        json:"total_records_in_table"`
+	RecordsAfterFilters int      `xml:"RecordsAfterFilters"        json:"records_after_filters"`
+	RecordsReturned     int      `xml:"RecordsReturned"            json:"records_returned"`
+	MoreDataAvailable   bool     `xml:"MoreDataAvailable"          json:"more_data_available"`
+	NextOffset          int      `xml:"NextOffset,omitempty"       json:"next_offset,omitempty"`
+	NextAfter           []string `xml:"NextAfter>Value,omitempty"  json:"next_after,omitempty"`
+}
+
+// Стратегии выполнения запроса (ExecutionPlan.Strategy)
+const (
+	PlanPushdown     = "pushdown"      // фильтры транслированы в SQL
+	PlanStreamFilter = "stream_filter" // таблица читается потоком, в памяти только совпавшие строки
+	PlanMaterialize  = "materialize"   // таблица читается целиком, фильтрация в памяти
+)
+
+// ExecutionPlan фиксирует, как был выполнен запрос и на каких оценках
+// основано решение. Информационная секция для отладки: на данные не влияет.
+//
+// EstimatedRows = -1 — число строк не запрашивалось (для pushdown COUNT(*)
+// не нужен). Selectivity — оценка доли строк, проходящих фильтры (0..1),
+// по эвристикам операторов, без статистики СУБД.
+//
+// Filters — где выполнен каждый фильтр запроса (в SQL или в памяти),
+// Stages — стадии выполнения по порядку: оценка и фактическое число строк
+// на выходе, время. SQL заполняется только планом без выполнения
+// (Explain): в пакеты текст запроса к источнику не попадает.
+type ExecutionPlan struct {
+	Strategy         string       `xml:"strategy,attr"         json:"strategy"`
+	EstimatedRows    int64        `xml:"estimatedRows,attr"    json:"estimated_rows"`
+	Selectivity      float64      `xml:"selectivity,attr"      json:"selectivity"`
+	EstimatedMatches int64        `xml:"estimatedMatches,attr" json:"estimated_matches"`
+	Reason           string       `xml:"reason,attr,omitempty" json:"reason,omitempty"`
+	Filters          []PlanFilter `xml:"Filter,omitempty"      json:"filters,omitempty"`
+	Stages           []PlanStage  `xml:"Stage,omitempty"       json:"stages,omitempty"`
+	SQL              string       `xml:"SQL,omitempty"         json:"sql,omitempty"`
+}
+
+// Где выполняется фильтр или стадия (PlanFilter.Where, PlanStage.Where)
+const (
+	ExecSQL    = "sql"    // в СУБД источника
+	ExecMemory = "memory" // в tdtql.Executor
+)
+
+// Стадии выполнения запроса (PlanStage.Name)
+const (
+	StageCount    = "count"    // COUNT(*) таблицы
+	StageSQL      = "sql"      // запрос с фильтрами, транслированными в SQL
+	StageRead     = "read"     // чтение всей таблицы
+	StageStream   = "stream"   // потоковое чтение с фильтрацией
+	StageJoin     = "join"     // соединение таблиц в памяти
+	StageFilter   = "filter"   // фильтры в памяти
+	StageSort     = "sort"     // сортировка в памяти
+	StagePaginate = "paginate" // OFFSET/LIMIT в памяти
+	StageProject  = "project"  // проекция Fields в памяти
+	StageGenerate = "generate" // сборка пакетов
+)
+
+// PlanFilter — фильтр запроса и место его выполнения.
+type PlanFilter struct {
+	Field       string  `xml:"field,attr"           json:"field"`
+	Operator    string  `xml:"operator,attr"        json:"operator"`
+	Value       string  `xml:"value,attr,omitempty" json:"value,omitempty"`
+	Where       string  `xml:"where,attr"           json:"where"`
+	Selectivity float64 `xml:"selectivity,attr"     json:"selectivity"`
+}
+
+// PlanStage — стадия выполнения. EstimatedRows = -1 — оценки нет,
+// ActualRows = -1 — стадия не выполнялась (план Explain).
+type PlanStage struct {
+	Name          string  `xml:"name,attr"          json:"name"`
+	Where         string  `xml:"where,attr"         json:"where"`
+	EstimatedRows int64   `xml:"estimatedRows,attr" json:"estimated_rows"`
+	ActualRows    int64   `xml:"actualRows,attr"    json:"actual_rows"`
+	ElapsedMs     float64 `xml:"elapsedMs,attr"     json:"elapsed_ms"`
+}
+
+// Done фиксирует результат стадии: строк на выходе и время с start.
+func (s *PlanStage) Done(rows int, start time.Time) {
+	s.ActualRows = int64(rows)
+	s.ElapsedMs = float64(time.Since(start).Microseconds()) / 1000
+}
+
+// AddStage добавляет выполненную стадию плана.
+func (p *ExecutionPlan) AddStage(name, where string, estimated int64, rows int, start time.Time) {
+	stage := PlanStage{Name: name, Where: where, EstimatedRows: estimated}
+	stage.Done(rows, start)
+	p.Stages = append(p.Stages, stage)
+}
+
+// Elapsed — суммарное время стадий, мс.
+func (p *ExecutionPlan) Elapsed() float64 {
+	var total float64
+	for _, s := range p.Stages {
+		total += s.ElapsedMs
+	}
+	return total
 }
 
 // FilterStatistics содержит статистику по фильтрам
 type FilterStatistics struct {
-	Filters []FilterStat `xml:"Filter,omitempty"`
-	Or      []OrStat     `xml:"Or,omitempty"`
+	Filters []FilterStat `xml:"Filter,omitempty" json:"filters,omitempty"`
+	Or      []OrStat     `xml:"Or,omitempty"     json:"or,omitempty"`
 }
 
 // FilterStat статистика одного фильтра
 type FilterStat struct {
-	Field          string `xml:"field,attr"`
-	Operator       string `xml:"operator,attr"`
-	Value          string `xml:"value,attr"`
-	RecordsMatched int    `xml:"recordsMatched,attr"`
+	Field          string `xml:"field,attr"          json:"field"`
+	Operator       string `xml:"operator,attr"       json:"operator"`
+	Value          string `xml:"value,attr"          json:"value"`
+	RecordsMatched int    `xml:"recordsMatched,attr" json:"records_matched"`
 }
 
 // OrStat статистика OR группы
 type OrStat struct {
-	RecordsMatched int          `xml:"recordsMatched,attr"`
-	Filters        []FilterStat `xml:"Filter,omitempty"`
+	RecordsMatched int          `xml:"recordsMatched,attr" json:"records_matched"`
+	Filters        []FilterStat `xml:"Filter,omitempty"    json:"filters,omitempty"`
 }
 
 // NewQuery создает новый TDTQL запрос
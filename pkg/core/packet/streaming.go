@@ -134,10 +134,10 @@ func (sg *StreamingGenerator) GeneratePartsStream(
 					return
 				}
 
-				rowSize := estimateRowSize(row)
+				rowSize := sg.rowSize(row)
 
 				// Проверяем нужно ли начать новую часть
-				if currentSize+rowSize+packetOverheadSize > sg.partSizeBytes && len(currentPartRows) > 0 {
+				if sg.startsNewPart(len(currentPartRows), currentSize, rowSize, sg.partSizeBytes) {
 					// Генерируем текущую часть
 					packet := sg.createPart(
 						messageIDBase,
@@ -181,7 +181,7 @@ func (sg *StreamingGenerator) createPart(
 	schema Schema,
 	rows [][]string,
 ) *DataPacket {
-	packet := NewDataPacket(msgType, tableName)
+	packet := sg.newPacket(msgType, tableName)
 	packet.Header.MessageID = fmt.Sprintf("%s-P%d", messageIDBase, partNum)
 	packet.Header.PartNumber = partNum
 	packet.Header.TotalParts = totalParts // 0 = unknown в streaming режиме
@@ -270,9 +270,9 @@ func (sg *StreamingGenerator) GeneratePartsStreamWithSender(
 					return
 				}
 
-				rowSize := estimateRowSize(row)
+				rowSize := sg.rowSize(row)
 
-				if currentSize+rowSize+packetOverheadSize > sg.partSizeBytes && len(currentPartRows) > 0 {
+				if sg.startsNewPart(len(currentPartRows), currentSize, rowSize, sg.partSizeBytes) {
 					packet := sg.createPartWithSender(
 						messageIDBase,
 						partNum,
@@ -21,20 +21,20 @@ const InReplyToDirectExport = "DirectExport"
 // PipelineContext содержит метаданные pipeline, встроенные в пакет при экспорте (v1.4).
 // Позволяет получателю проверить параметры источника через --expect-var.
 type PipelineContext struct {
-	Pipeline  PipelineInfo  `xml:"Pipeline"`
-	Variables []PipelineVar `xml:"Variables>Var,omitempty"`
+	Pipeline  PipelineInfo  `xml:"Pipeline"                json:"pipeline"`
+	Variables []PipelineVar `xml:"Variables>Var,omitempty" json:"variables,omitempty"`
 }
 
 // PipelineInfo описывает pipeline-источник: имя и версию конфига.
 type PipelineInfo struct {
-	Name    string `xml:"name,attr"`
-	Version string `xml:"version,attr,omitempty"`
+	Name    string `xml:"name,attr"              json:"name"`
+	Version string `xml:"version,attr,omitempty" json:"version,omitempty"`
 }
 
 // PipelineVar — одна переменная pipeline, использованная при экспорте.
 type PipelineVar struct {
-	Name  string `xml:"name,attr"`
-	Value string `xml:"value,attr"`
+	Name  string `xml:"name,attr"  json:"name"`
+	Value string `xml:"value,attr" json:"value"`
 }
 
 // DataPacket представляет корневой элемент TDTP сообщения
@@ -59,16 +59,60 @@ type DataPacket struct {
 
 // Header содержит метаданные сообщения
 type Header struct {
-	Type          MessageType `xml:"Type"`
-	TableName     string      `xml:"TableName"`
-	MessageID     string      `xml:"MessageID"`
-	InReplyTo     string      `xml:"InReplyTo,omitempty"`
-	PartNumber    int         `xml:"PartNumber,omitempty"`
-	TotalParts    int         `xml:"TotalParts,omitempty"`
-	RecordsInPart int         `xml:"RecordsInPart,omitempty"`
-	Timestamp     time.Time   `xml:"Timestamp"`
-	Sender        string      `xml:"Sender,omitempty"`
-	Recipient     string      `xml:"Recipient,omitempty"`
+	Type          MessageType `xml:"Type"                    json:"type"`
+	TableName     string      `xml:"TableName"               json:"table_name"`
+	MessageID     string      `xml:"MessageID"               json:"message_id"`
+	InReplyTo     string      `xml:"InReplyTo,omitempty"     json:"in_reply_to,omitempty"`
+	PartNumber    int         `xml:"PartNumber,omitempty"    json:"part_number,omitempty"`
+	TotalParts    int         `xml:"TotalParts,omitempty"    json:"total_parts,omitempty"`
+	RecordsInPart int         `xml:"RecordsInPart,omitempty" json:"records_in_part,omitempty"`
+	Timestamp     time.Time   `xml:"Timestamp"               json:"timestamp"`
+	Sender        string      `xml:"Sender,omitempty"        json:"sender,omitempty"`
+	Recipient     string      `xml:"Recipient,omitempty"     json:"recipient,omitempty"`
+	// Priority (0..MaxPriority) — приоритет доставки и обработки пакета.
+	// 0 = обычный поток (bulk backfill); PriorityUrgent = срочное обновление
+	// справочника, которое должно обогнать массовую загрузку.
+	// Учитывается брокерами (RabbitMQ priority queue, отдельный Kafka topic)
+	// и планировщиком ParallelImporter.
+	Priority int `xml:"Priority,omitempty" json:"priority,omitempty"`
+
+	// Checksum — SHA-256 канонизированных строк (Generator.SetRowChecksum,
+	// StampRowChecksum); проверяется при разборе и импорте (checksum.go).
+	Checksum *RowChecksum `xml:"Checksum,omitempty" json:"checksum,omitempty"`
+
+	// KeyEscrow — ключ шифрования v1.5, обёрнутый открытым ключом
+	// депозитария (EscrowPacketKey): архив читается и после того, как
+	// xZMercury сжёг ключ.
+	KeyEscrow *KeyEscrow `xml:"KeyEscrow,omitempty" json:"key_escrow,omitempty"`
+
+	// Extensions — метаданные интегратора (correlation ID, арендатор,
+	// бизнес-процесс), см. extensions.go.
+	Extensions Extensions `xml:"Extensions,omitempty" json:"extensions,omitempty"`
+}
+
+// Уровни приоритета пакета (Header.Priority).
+// Диапазон 0..9 совпадает с рекомендуемым диапазоном x-max-priority в RabbitMQ.
+const (
+	PriorityNormal = 0
+	PriorityHigh   = 5
+	PriorityUrgent = 9
+	MaxPriority    = 9
+)
+
+// IsUrgent сообщает, помечен ли пакет как срочный (Priority >= PriorityUrgent).
+func (h Header) IsUrgent() bool {
+	return h.Priority >= PriorityUrgent
+}
+
+// ClampPriority приводит значение к допустимому диапазону 0..MaxPriority.
+func ClampPriority(p int) int {
+	if p < PriorityNormal {
+		return PriorityNormal
+	}
+	if p > MaxPriority {
+		return MaxPriority
+	}
+	return p
 }
 
 // Schema описывает структуру таблицы.
@@ -93,6 +137,8 @@ type Schema struct {
 	XXH3       string      `xml:"xxh3,attr,omitempty"      json:"xxh3,omitempty"`        // v1.4: xxh3_128 of Schema content
 	Encryption string      `xml:"encryption,attr,omitempty" json:"encryption,omitempty"` // v1.5: "aes-256-gcm" if Encrypted holds ciphertext
 	Encrypted  string      `xml:",chardata"                 json:"encrypted,omitempty"`  // v1.5: base64(nonce||ciphertext) when Encryption != ""
+
+	Description string `xml:"description,attr,omitempty" json:"description,omitempty"` // комментарий таблицы источника (COMMENT ON TABLE, MS_Description)
 }
 
 // Dictionary — обёртка над []DictEntry, чтобы encoding/xml корректно
@@ -113,17 +159,21 @@ type DictEntry struct {
 
 // Field описывает одно поле таблицы
 type Field struct {
-	Name          string         `xml:"name,attr"                        json:"name"`
-	Type          string         `xml:"type,attr"                        json:"type"`
-	Length        int            `xml:"length,attr,omitempty"            json:"length"`
-	Precision     int            `xml:"precision,attr,omitempty"         json:"precision"`
-	Scale         int            `xml:"scale,attr,omitempty"             json:"scale"`
-	Key           bool           `xml:"key,attr,omitempty"               json:"key"`
-	Timezone      string         `xml:"timezone,attr,omitempty"          json:"timezone,omitempty"`
-	Subtype       string         `xml:"subtype,attr,omitempty"           json:"subtype,omitempty"`
-	ReadOnly      bool           `xml:"readonly,attr,omitempty"          json:"readonly,omitempty"`       // Read-only поля (timestamp, computed)
-	Fixed         bool           `xml:"fixed,attr,omitempty"             json:"fixed,omitempty"`          // v1.3.1: значение не меняется в пределах пакета
-	SpecialValues *SpecialValues `xml:"SpecialValues,omitempty"          json:"special_values,omitempty"` // v1.3.1: маркеры специальных значений
+	Name          string           `xml:"name,attr"                        json:"name"`
+	Type          string           `xml:"type,attr"                        json:"type"`
+	Length        int              `xml:"length,attr,omitempty"            json:"length"`
+	Precision     int              `xml:"precision,attr,omitempty"         json:"precision"`
+	Scale         int              `xml:"scale,attr,omitempty"             json:"scale"`
+	Key           bool             `xml:"key,attr,omitempty"               json:"key"`
+	Timezone      string           `xml:"timezone,attr,omitempty"          json:"timezone,omitempty"`
+	Subtype       string           `xml:"subtype,attr,omitempty"           json:"subtype,omitempty"`
+	Collation     string           `xml:"collation,attr,omitempty"         json:"collation,omitempty"`      // BCP 47 тег коллации для сортировки в памяти (например "ru")
+	ReadOnly      bool             `xml:"readonly,attr,omitempty"          json:"readonly,omitempty"`       // Read-only поля (timestamp, computed)
+	Fixed         bool             `xml:"fixed,attr,omitempty"             json:"fixed,omitempty"`          // v1.3.1: значение не меняется в пределах пакета
+	SpecialValues *SpecialValues   `xml:"SpecialValues,omitempty"          json:"special_values,omitempty"` // v1.3.1: маркеры специальных значений
+	Encryption    *FieldEncryption `xml:"Encryption,omitempty"             json:"encryption,omitempty"`     // значения зашифрованы на уровне колонки
+	Generated     *GeneratedColumn `xml:"Generated,omitempty"              json:"generated,omitempty"`      // вычисляемая колонка источника (GENERATED ALWAYS AS)
+	Description   string           `xml:"description,attr,omitempty"       json:"description,omitempty"`    // комментарий колонки источника; воссоздаётся при создании таблицы
 
 	// OriginalName is set by the sanitizer when Name is transformed into a safe
 	// SQL identifier. It is never serialized (xml:"-", json:"-") and carries the
@@ -131,6 +181,28 @@ type Field struct {
 	OriginalName string `xml:"-" json:"-"`
 }
 
+// FieldEncryption описывает колонку, значения которой зашифрованы
+// (encryption at rest): enc:<key_id>:base64(nonce||ciphertext).
+type FieldEncryption struct {
+	KeyID     string `xml:"key,attr"                 json:"key"`
+	Algorithm string `xml:"algorithm,attr,omitempty" json:"algorithm,omitempty"`
+	Type      string `xml:"type,attr,omitempty"      json:"type,omitempty"` // исходный тип поля (до шифрования)
+
+	// Атрибуты исходного типа: шифртекст хранится как TEXT без них
+	Length    int    `xml:"length,attr,omitempty"    json:"length,omitempty"`
+	Precision int    `xml:"precision,attr,omitempty" json:"precision,omitempty"`
+	Scale     int    `xml:"scale,attr,omitempty"     json:"scale,omitempty"`
+	Subtype   string `xml:"subtype,attr,omitempty"   json:"subtype,omitempty"`
+}
+
+// GeneratedColumn описывает вычисляемую колонку: значение считает БД
+// по выражению, вставлять его нельзя. Expression — в диалекте БД-источника:
+// MySQL адаптер воссоздаёт колонку в DDL, остальные создают обычную.
+type GeneratedColumn struct {
+	Expression string `xml:"expression,attr"       json:"expression"`
+	Stored     bool   `xml:"stored,attr,omitempty" json:"stored,omitempty"` // STORED (иначе VIRTUAL)
+}
+
 // SpecialValues содержит маркеры специальных значений для поля (v1.3.1)
 type SpecialValues struct {
 	Null        *MarkerValue `xml:"Null,omitempty"        json:"null,omitempty"`
@@ -153,13 +225,27 @@ type Data struct {
 	Compact     bool   `xml:"compact,attr,omitempty"`     // v1.3.1: compact format (пропуски для fixed полей)
 	Tail        bool   `xml:"tail,attr,omitempty"`        // v1.3.1: последняя строка явно повторяет все fixed-поля — для потокового восстановления и валидации
 	Carry       string `xml:"carry,attr,omitempty"`       // v1.3.1: начальное carry-состояние чанка (pipe-разделённые значения полей); позволяет декодировать чанки независимо друг от друга
+	Delta       bool   `xml:"delta,attr,omitempty"`       // строки — ключ + изменённые поля (old/new), см. delta.go
+	Delete      bool   `xml:"delete,attr,omitempty"`      // строки — ключи удаляемых строк, см. delete.go
 	// Encryption (since TDTP v1.5): "aes-256-gcm" if Rows holds exactly one
 	// opaque Row whose Value is base64(nonce||ciphertext) of the entire
 	// pre-encryption <R>...</R> fragment (whatever Compression already
 	// produced — one compressed row, or the plain N rows joined). Always
 	// check Encryption before treating Rows as plaintext row values.
 	Encryption string `xml:"encryption,attr,omitempty"`
-	Rows       []Row  `xml:"R"`
+	// Compression dictionary (since TDTP v1.5): ZstdDictID — ID словаря zstd,
+	// которым сжаты данные; ZstdDict — сам словарь (base64), если отправитель
+	// встроил его для получателей без общего каталога словарей. Не путать с
+	// Schema.Dictionary — таблицей сокращений значений.
+	ZstdDictID string `xml:"dict,attr,omitempty"`
+	ZstdDict   string `xml:"CompressionDict,omitempty"`
+	// Layout — представление строк на проводе: пусто — pipe-строки <R>,
+	// LayoutColumnar — колонки <C name="..."><V>...</V></C> (см. columnar.go).
+	// Parser разворачивает колонки обратно в Rows, Columns заполнены только
+	// у пакетов, прочитанных через ParseColumns.
+	Layout  string   `xml:"layout,attr,omitempty"`
+	Rows    []Row    `xml:"R"`
+	Columns []Column `xml:"C"`
 }
 
 // Row представляет одну строку данных
@@ -167,17 +253,24 @@ type Row struct {
 	Value string `xml:",chardata"`
 }
 
+// Column — одна колонка данных в колоночном формате: значения по строкам,
+// без pipe-экранирования
+type Column struct {
+	Name   string   `xml:"name,attr"`
+	Values []string `xml:"V"`
+}
+
 // AlarmDetails содержит информацию о тревоге
 type AlarmDetails struct {
-	Severity string `xml:"Severity"`
-	Code     string `xml:"Code"`
-	Message  string `xml:"Message"`
+	Severity string `xml:"Severity" json:"severity"`
+	Code     string `xml:"Code"     json:"code"`
+	Message  string `xml:"Message"  json:"message"`
 	// ServerMode — режим xZMercury-сервера, выпустившего или сжёгшего ключ.
 	// "dev"  → аварийная замена при отказе Redis-кластера (ложная тревога).
 	// "prod" → штатный прод; KEY_BURNED_BY_OTHER в prod — сигнал расследования.
 	// Пусто → ошибка не связана с ключом или сервер не передал режим.
-	ServerMode      string `xml:"ServerMode,omitempty"`
-	AffectedRecords int    `xml:"AffectedRecords,omitempty"`
+	ServerMode      string `xml:"ServerMode,omitempty"      json:"server_mode,omitempty"`
+	AffectedRecords int    `xml:"AffectedRecords,omitempty" json:"affected_records,omitempty"`
 }
 
 // NewDataPacket создает новый пакет с базовыми настройками
@@ -211,17 +304,20 @@ func (p *DataPacket) GetRows() [][]string {
 }
 
 // SetRows устанавливает данные в пакет из [][]string
-// Правильно экранирует специальные символы
+// Правильно экранирует специальные символы. Контрольная сумма прежних
+// строк (Header.Checksum) сбрасывается.
 func (p *DataPacket) SetRows(rows [][]string) {
 	p.Data = RowsToData(rows)
+	p.rawRows = nil
 	p.Header.RecordsInPart = len(rows)
+	p.Header.Checksum = nil
 }
 
 // MaterializeRows обеспечивает что Data.Rows заполнены из rawRows.
 // Вызывается перед передачей пакета в функции, работающие напрямую с Data.Rows (импорт, сжатие).
 func (p *DataPacket) MaterializeRows() {
 	if len(p.rawRows) > 0 && len(p.Data.Rows) == 0 {
-		p.Data = RowsToData(p.rawRows)
+		p.Data.Rows = RowsToData(p.rawRows).Rows
 		p.rawRows = nil
 	}
 }
@@ -1,6 +1,10 @@
 package packet
 
-import "time"
+import (
+	"bytes"
+	"strconv"
+	"time"
+)
 
 // NewErrorPacket creates a minimal valid TDTP error packet (Type="error") that
 // can be written to a file and imported by any TDTP consumer.
@@ -88,3 +92,30 @@ func ParseRows(rows []Row, parser *Parser) [][]string {
 	}
 	return result
 }
+
+// PeekPriority извлекает Header.Priority из сериализованного пакета без полного
+// разбора XML. Сканируется только секция <Header> — это дёшево даже для
+// многомегабайтных пакетов, поэтому функция подходит для планирования очереди
+// до того, как воркер потратит время на Parse.
+// Возвращает PriorityNormal если тег отсутствует или значение некорректно.
+func PeekPriority(data []byte) int {
+	end := bytes.Index(data, []byte("</Header>"))
+	if end < 0 {
+		return PriorityNormal
+	}
+	header := data[:end]
+	start := bytes.Index(header, []byte("<Priority>"))
+	if start < 0 {
+		return PriorityNormal
+	}
+	value := header[start+len("<Priority>"):]
+	stop := bytes.IndexByte(value, '<')
+	if stop < 0 {
+		return PriorityNormal
+	}
+	p, err := strconv.Atoi(string(bytes.TrimSpace(value[:stop])))
+	if err != nil {
+		return PriorityNormal
+	}
+	return ClampPriority(p)
+}
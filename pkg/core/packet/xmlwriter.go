@@ -57,6 +57,13 @@ func writePacketTo(w *bufio.Writer, packet *DataPacket) error {
 		}
 	}
 
+	// PipelineContext (omitempty)
+	if packet.PipelineContext != nil {
+		if err := marshalInto(w, packet.PipelineContext, "PipelineContext"); err != nil {
+			return err
+		}
+	}
+
 	// Schema — маленькая, xml.Marshal дешёв
 	if err := marshalInto(w, packet.Schema, "Schema"); err != nil {
 		return err
@@ -82,12 +89,33 @@ func writePacketTo(w *bufio.Writer, packet *DataPacket) error {
 	if packet.Data.Carry != "" {
 		writeXMLAttr(w, "carry", packet.Data.Carry)
 	}
+	if packet.Data.Delta {
+		w.WriteString(` delta="true"`)
+	}
+	if packet.Data.Delete {
+		w.WriteString(` delete="true"`)
+	}
 	if packet.Data.Encryption != "" {
 		writeXMLAttr(w, "encryption", packet.Data.Encryption)
 	}
+	if packet.Data.ZstdDictID != "" {
+		writeXMLAttr(w, "dict", packet.Data.ZstdDictID)
+	}
+	columnar := writesColumnar(packet)
+	if columnar {
+		writeXMLAttr(w, "layout", LayoutColumnar)
+	}
 	w.WriteByte('>')
+	if packet.Data.ZstdDict != "" {
+		w.WriteString(`<CompressionDict>`)
+		w.WriteString(packet.Data.ZstdDict) // base64 — экранирование не требуется
+		w.WriteString(`</CompressionDict>`)
+	}
 
-	if len(packet.rawRows) > 0 {
+	if columnar {
+		// Колоночный формат: значения без pipe-экранирования, см. columnar.go.
+		writeColumnarData(w, packet)
+	} else if len(packet.rawRows) > 0 {
 		// Fast path: rawRows установлены GenerateReference.
 		// Пишем значения напрямую — ни RowsToData, ни strings.Join не нужны.
 		// TDTP-экранирование (|→\|, \→\\) + XML-экранирование (<>&) — один проход.
@@ -0,0 +1,82 @@
+package schema
+
+import (
+	"fmt"
+	"strings"
+)
+
+// BoolMapping — представление BOOLEAN в источнике или приёмнике, хранящем
+// логические значения текстом: Y/N, T/F, Да/Нет. Сравнение без учёта
+// регистра и пробелов по краям; при записи используются первые значения
+// списков (TrueValues[0] / FalseValues[0]).
+//
+//	booleans:
+//	  true_values: [Y, Да]
+//	  false_values: [N, Нет]
+type BoolMapping struct {
+	TrueValues  []string `yaml:"true_values" json:"true_values"`
+	FalseValues []string `yaml:"false_values" json:"false_values"`
+}
+
+// ParseBoolMapping разбирает маппинг из строки "истина/ложь", значения через
+// запятую: "Y,Да/N,Нет" (формат флагов CLI).
+func ParseBoolMapping(s string) (BoolMapping, error) {
+	trueList, falseList, ok := strings.Cut(s, "/")
+	if !ok {
+		return BoolMapping{}, fmt.Errorf("invalid boolean mapping %q, expected \"true1,true2/false1,false2\"", s)
+	}
+	m := BoolMapping{TrueValues: splitBoolValues(trueList), FalseValues: splitBoolValues(falseList)}
+	if err := m.Validate(); err != nil {
+		return BoolMapping{}, err
+	}
+	return m, nil
+}
+
+func splitBoolValues(list string) []string {
+	var values []string
+	for _, v := range strings.Split(list, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// Validate проверяет, что оба списка заданы и не пересекаются
+func (m BoolMapping) Validate() error {
+	if len(m.TrueValues) == 0 || len(m.FalseValues) == 0 {
+		return fmt.Errorf("boolean mapping requires both true_values and false_values")
+	}
+	for _, t := range m.TrueValues {
+		for _, f := range m.FalseValues {
+			if strings.EqualFold(strings.TrimSpace(t), strings.TrimSpace(f)) {
+				return fmt.Errorf("boolean mapping: %q is both true and false", t)
+			}
+		}
+	}
+	return nil
+}
+
+// Parse разбирает значение по спискам маппинга
+func (m BoolMapping) Parse(value string) (bool, error) {
+	v := strings.TrimSpace(value)
+	for _, t := range m.TrueValues {
+		if strings.EqualFold(v, strings.TrimSpace(t)) {
+			return true, nil
+		}
+	}
+	for _, f := range m.FalseValues {
+		if strings.EqualFold(v, strings.TrimSpace(f)) {
+			return false, nil
+		}
+	}
+	return false, fmt.Errorf("value %q is neither %v nor %v", value, m.TrueValues, m.FalseValues)
+}
+
+// Format возвращает представление b для записи
+func (m BoolMapping) Format(b bool) string {
+	if b {
+		return m.TrueValues[0]
+	}
+	return m.FalseValues[0]
+}
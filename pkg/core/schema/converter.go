@@ -10,7 +10,10 @@ import (
 )
 
 // Converter отвечает за конвертацию значений
-type Converter struct{}
+type Converter struct {
+	numberFormat *NumberFormat // формат чисел текстового источника (SetNumberFormat)
+	dateFormats  *DateFormats  // форматы дат текстового источника (SetDateFormats)
+}
 
 // NewConverter создает новый конвертер
 func NewConverter() *Converter {
@@ -48,6 +51,33 @@ func (c *Converter) ParseValue(rawValue string, field FieldDef) (*TypedValue, er
 		return tv, nil
 	}
 
+	// Числа с разделителями локали ("1 234,56") → канонический вид
+	if c.numberFormat != nil && (normalized == TypeInteger || normalized == TypeReal || normalized == TypeDecimal) {
+		value, err := c.numberFormat.Normalize(rawValue)
+		if err != nil {
+			return nil, &ValidationError{
+				Field:   field.Name,
+				Message: err.Error(),
+				Value:   rawValue,
+			}
+		}
+		tv.RawValue = value
+	}
+
+	// Даты в порядке локали ("31.12.2024") → канонический вид; TIME не затрагивается
+	if c.dateFormats != nil && field.Subtype != "time" &&
+		(normalized == TypeDate || normalized == TypeDatetime || normalized == TypeTimestamp) {
+		value, err := c.localizedDate(rawValue, field)
+		if err != nil {
+			return nil, &ValidationError{
+				Field:   field.Name,
+				Message: err.Error(),
+				Value:   rawValue,
+			}
+		}
+		tv.RawValue = value
+	}
+
 	switch normalized {
 	case TypeInteger:
 		return c.parseInteger(tv, field)
@@ -0,0 +1,271 @@
+package schema
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DateFormat — порядок дня, месяца и года в текстовых датах источника (XLSX,
+// CSV, выгрузки 1С): "31.12.2024" (dmy), "12/31/2024" (mdy), "2024-12-31"
+// (ymd). Разделитель — точка, дробь или дефис, год — четыре цифры; время
+// после даты ("31.12.2024 15:04:05") допускается. Значение, начинающееся с
+// года, читается как ymd при любом формате.
+type DateFormat string
+
+const (
+	DateDMY DateFormat = "dmy"
+	DateMDY DateFormat = "mdy"
+	DateYMD DateFormat = "ymd"
+	// DateAuto — формат определяется по значениям колонки (DetectDateFormat).
+	DateAuto DateFormat = "auto"
+)
+
+// dateFormats — имена для LookupDateFormat: порядки и предустановки стран.
+var dateFormats = map[string]DateFormat{
+	"dmy": DateDMY, "ru": DateDMY, "de": DateDMY, "fr": DateDMY, "gb": DateDMY,
+	"mdy": DateMDY, "us": DateMDY,
+	"ymd": DateYMD, "iso": DateYMD,
+	"auto": DateAuto,
+}
+
+// LookupDateFormat возвращает формат по имени: dmy (ru, de, fr, gb), mdy (us),
+// ymd (iso) или auto.
+func LookupDateFormat(name string) (DateFormat, error) {
+	df, ok := dateFormats[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		names := make([]string, 0, len(dateFormats))
+		for n := range dateFormats {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return "", fmt.Errorf("unknown date format %q (supported: %s)", name, strings.Join(names, ", "))
+	}
+	return df, nil
+}
+
+// dateTimeLayouts — допустимая часть времени после даты.
+var dateTimeLayouts = []string{
+	"15:04:05Z07:00",
+	"15:04:05.999999999Z07:00",
+	"15:04:05",
+	"15:04:05.999999999",
+	"15:04",
+}
+
+// Parse разбирает дату (и время, если есть) в формате df; время без
+// смещения считается UTC. Несуществующая дата ("31.02.2024") — ошибка.
+func (df DateFormat) Parse(value string) (time.Time, error) {
+	s := strings.TrimSpace(value)
+	datePart, timePart, _ := strings.Cut(strings.Replace(s, "T", " ", 1), " ")
+
+	parts, ok := splitDateParts(datePart)
+	if !ok {
+		return time.Time{}, fmt.Errorf("invalid date %q", value)
+	}
+	var year, month, day string
+	switch {
+	case len(parts[0]) == 4:
+		year, month, day = parts[0], parts[1], parts[2]
+	case len(parts[2]) != 4:
+		return time.Time{}, fmt.Errorf("invalid date %q: expected a four-digit year", value)
+	case df == DateDMY:
+		day, month, year = parts[0], parts[1], parts[2]
+	case df == DateMDY:
+		month, day, year = parts[0], parts[1], parts[2]
+	default:
+		return time.Time{}, fmt.Errorf("date %q does not match format %s", value, df)
+	}
+
+	y, _ := strconv.Atoi(year)
+	m, _ := strconv.Atoi(month)
+	d, _ := strconv.Atoi(day)
+	t := time.Date(y, time.Month(m), d, 0, 0, 0, 0, time.UTC)
+	if len(month) > 2 || len(day) > 2 || t.Year() != y || int(t.Month()) != m || t.Day() != d {
+		return time.Time{}, fmt.Errorf("invalid date %q for format %s", value, df)
+	}
+
+	if timePart = strings.TrimSpace(timePart); timePart == "" {
+		return t, nil
+	}
+	for _, layout := range dateTimeLayouts {
+		if clock, err := time.Parse(layout, timePart); err == nil {
+			return time.Date(y, time.Month(m), d, clock.Hour(), clock.Minute(), clock.Second(),
+				clock.Nanosecond(), clock.Location()), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time in %q", value)
+}
+
+// Normalize переводит дату в формате df в канонический вид TDTP: "2006-01-02"
+// для DATE, RFC3339 в UTC для DATETIME/TIMESTAMP.
+func (df DateFormat) Normalize(value string, fieldType DataType) (string, error) {
+	t, err := df.Parse(value)
+	if err != nil {
+		return "", err
+	}
+	if NormalizeType(fieldType) == TypeDate {
+		return t.Format("2006-01-02"), nil
+	}
+	return t.UTC().Format(time.RFC3339), nil
+}
+
+// splitDateParts делит "31.12.2024" на три группы цифр по одному разделителю.
+func splitDateParts(s string) ([]string, bool) {
+	sep := strings.IndexAny(s, "./-")
+	if sep < 0 {
+		return nil, false
+	}
+	parts := strings.Split(s, s[sep:sep+1])
+	if len(parts) != 3 {
+		return nil, false
+	}
+	for _, p := range parts {
+		if p == "" || strings.Trim(p, "0123456789") != "" {
+			return nil, false
+		}
+	}
+	return parts, true
+}
+
+// DateDetection — результат DetectDateFormat.
+type DateDetection struct {
+	Format DateFormat
+	// Ambiguous — ни одно значение не отличает день от месяца ("01/02/2024"):
+	// Format — fallback, а не вывод из данных.
+	Ambiguous bool
+	Example   string // неоднозначное значение для предупреждения
+}
+
+// DetectDateFormat определяет формат колонки по выборке значений. Значение
+// "31/12/2024" возможно только как dmy, "12/31/2024" — только как mdy;
+// выборка с обоими — ошибка, а не молча испорченная половина дат. Если
+// различающих значений нет, возвращается fallback (dmy, если не задан) с
+// Ambiguous. Пустые и нераспознанные значения пропускаются: их отвергнет
+// Normalize.
+func DetectDateFormat(samples []string, fallback DateFormat) (DateDetection, error) {
+	if fallback == "" || fallback == DateAuto {
+		fallback = DateDMY
+	}
+	var dmyOnly, mdyOnly, ambiguous string
+	iso := false
+	for _, v := range samples {
+		if strings.TrimSpace(v) == "" {
+			continue
+		}
+		_, dmyErr := DateDMY.Parse(v)
+		_, mdyErr := DateMDY.Parse(v)
+		_, ymdErr := DateYMD.Parse(v)
+		switch {
+		case ymdErr == nil:
+			iso = true
+		case dmyErr == nil && mdyErr == nil:
+			if ambiguous == "" {
+				ambiguous = v
+			}
+		case dmyErr == nil:
+			if dmyOnly == "" {
+				dmyOnly = v
+			}
+		case mdyErr == nil:
+			if mdyOnly == "" {
+				mdyOnly = v
+			}
+		}
+	}
+
+	switch {
+	case dmyOnly != "" && mdyOnly != "":
+		return DateDetection{}, fmt.Errorf("mixed date formats: %q is day-first, %q is month-first", dmyOnly, mdyOnly)
+	case dmyOnly != "":
+		return DateDetection{Format: DateDMY}, nil
+	case mdyOnly != "":
+		return DateDetection{Format: DateMDY}, nil
+	case ambiguous != "":
+		return DateDetection{Format: fallback, Ambiguous: true, Example: ambiguous}, nil
+	case iso:
+		return DateDetection{Format: DateYMD}, nil
+	}
+	return DateDetection{Format: fallback}, nil
+}
+
+// DateFormats — форматы текстовых дат источника: Default для всех колонок,
+// Columns — для отдельных (имя без учёта регистра).
+//
+//	date_formats:
+//	  default: dmy
+//	  columns: {shipped_at: mdy, created: auto}
+type DateFormats struct {
+	Default DateFormat            `yaml:"default,omitempty" json:"default,omitempty"`
+	Columns map[string]DateFormat `yaml:"columns,omitempty" json:"columns,omitempty"`
+}
+
+// ParseDateFormats разбирает форматы из строки флага CLI: формат по
+// умолчанию и/или колонка=формат через запятую ("dmy,shipped_at=mdy").
+func ParseDateFormats(s string) (DateFormats, error) {
+	var dfs DateFormats
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		column, name, isColumn := strings.Cut(item, "=")
+		if !isColumn {
+			name = column
+		}
+		df, err := LookupDateFormat(name)
+		if err != nil {
+			return DateFormats{}, err
+		}
+		switch {
+		case !isColumn && dfs.Default != "":
+			return DateFormats{}, fmt.Errorf("date formats %q: more than one default format", s)
+		case !isColumn:
+			dfs.Default = df
+		default:
+			if dfs.Columns == nil {
+				dfs.Columns = make(map[string]DateFormat)
+			}
+			dfs.Columns[strings.TrimSpace(column)] = df
+		}
+	}
+	return dfs, nil
+}
+
+// For возвращает формат колонки column ("" — формат не задан).
+func (dfs DateFormats) For(column string) DateFormat {
+	for name, df := range dfs.Columns {
+		if strings.EqualFold(name, column) {
+			return df
+		}
+	}
+	return dfs.Default
+}
+
+// SetDateFormats включает разбор DATE/DATETIME/TIMESTAMP значений в форматах
+// dfs (nil — только канонический формат). DateAuto здесь распознаёт каждое
+// значение отдельно: неоднозначное ("01/02/2024") — ошибка; чтобы выбрать
+// формат по колонке целиком, вызывающий разрешает его через DetectDateFormat.
+func (c *Converter) SetDateFormats(dfs *DateFormats) {
+	c.dateFormats = dfs
+}
+
+// localizedDate — значение даты поля field в каноническом виде по c.dateFormats.
+func (c *Converter) localizedDate(rawValue string, field FieldDef) (string, error) {
+	df := c.dateFormats.For(field.Name)
+	if df == DateAuto {
+		detection, err := DetectDateFormat([]string{rawValue}, "")
+		if err != nil {
+			return "", err
+		}
+		if detection.Ambiguous {
+			return "", fmt.Errorf("ambiguous date %q: day and month can be swapped, set a date format", rawValue)
+		}
+		df = detection.Format
+	}
+	if df == "" {
+		return rawValue, nil
+	}
+	return df.Normalize(rawValue, field.Type)
+}
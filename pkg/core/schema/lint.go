@@ -0,0 +1,483 @@
+package schema
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+// LintSeverity — уровень замечания линтера.
+type LintSeverity string
+
+const (
+	// LintError — файл нарушает спецификацию: импорт упадёт или запишет не то
+	LintError LintSeverity = "error"
+	// LintWarning — файл корректен, но что-то в нём не проверено или подозрительно
+	LintWarning LintSeverity = "warning"
+)
+
+// Группы проверок (LintFinding.Check).
+const (
+	LintCheckXML    = "xml"
+	LintCheckHeader = "header"
+	LintCheckSchema = "schema"
+	LintCheckData   = "data"
+	LintCheckRow    = "row"
+	LintCheckValue  = "value"
+	LintCheckParts  = "parts"
+)
+
+// MaxLintRowFindings — сколько замечаний к строкам и значениям LintPacket
+// выдаёт на файл; дальше — одно итоговое замечание со счётчиком, чтобы
+// битый файл на миллион строк не давал миллион строк отчёта.
+const MaxLintRowFindings = 100
+
+// LintFinding — замечание линтера TDTP-файла в машиночитаемом виде
+// (JSON для CI). Row — номер строки данных с 1, 0 — замечание не к строке.
+type LintFinding struct {
+	Severity LintSeverity `json:"severity"`
+	Check    string       `json:"check"`
+	File     string       `json:"file,omitempty"`
+	Row      int          `json:"row,omitempty"`
+	Field    string       `json:"field,omitempty"`
+	Message  string       `json:"message"`
+}
+
+func (f LintFinding) String() string {
+	var loc []string
+	if f.File != "" {
+		loc = append(loc, f.File)
+	}
+	if f.Row > 0 {
+		loc = append(loc, fmt.Sprintf("row %d", f.Row))
+	}
+	if f.Field != "" {
+		loc = append(loc, "field "+f.Field)
+	}
+	prefix := ""
+	if len(loc) > 0 {
+		prefix = strings.Join(loc, ": ") + ": "
+	}
+	return fmt.Sprintf("%s [%s] %s%s", f.Severity, f.Check, prefix, f.Message)
+}
+
+// HasLintErrors — есть ли среди замечаний ошибки.
+func HasLintErrors(findings []LintFinding) bool {
+	for _, f := range findings {
+		if f.Severity == LintError {
+			return true
+		}
+	}
+	return false
+}
+
+// LintedPacket — разобранный LintPacket файл для сверки частей (LintParts).
+type LintedPacket struct {
+	File   string
+	Packet *packet.DataPacket
+}
+
+// linter собирает замечания одного файла.
+type linter struct {
+	file      string
+	findings  []LintFinding
+	rowIssues int
+}
+
+func (l *linter) add(sev LintSeverity, check string, row int, field, format string, args ...any) {
+	if check == LintCheckRow || check == LintCheckValue {
+		l.rowIssues++
+		if l.rowIssues > MaxLintRowFindings {
+			return
+		}
+	}
+	l.findings = append(l.findings, LintFinding{
+		Severity: sev, Check: check, File: l.file, Row: row, Field: field,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// LintPacket проверяет TDTP-файл глубже Parser: в отличие от разбора,
+// который останавливается на первой ошибке, собираются все замечания —
+// структура XML, обязательные поля заголовка, согласованность схемы
+// (ключ, параметры типов), число полей в строках, формат каждого значения
+// по типу поля, номера частей. Сжатые данные распаковываются
+// (packet.DecompressPacketData — нужен зарегистрированный кодек),
+// зашифрованные не проверяются. Возвращает пакет (nil, если XML не
+// разобран) для LintParts.
+func LintPacket(ctx context.Context, file string, data []byte) (*packet.DataPacket, []LintFinding) {
+	l := &linter{file: file}
+
+	var pkt packet.DataPacket
+	if err := xml.Unmarshal(data, &pkt); err != nil {
+		l.add(LintError, LintCheckXML, 0, "", "malformed XML: %v", err)
+		return nil, l.findings
+	}
+
+	l.header(&pkt)
+	if pkt.Schema.Encryption != "" || pkt.Data.Encryption != "" {
+		l.add(LintWarning, LintCheckData, 0, "", "encrypted packet: schema and rows are not checked")
+		return &pkt, l.findings
+	}
+	l.schema(pkt.Schema, len(pkt.Data.Rows) > 0 || len(pkt.Data.Columns) > 0)
+	if l.data(ctx, &pkt) {
+		l.rows(&pkt)
+	}
+	if l.rowIssues > MaxLintRowFindings {
+		l.findings = append(l.findings, LintFinding{
+			Severity: LintWarning, Check: LintCheckRow, File: file,
+			Message: fmt.Sprintf("%d more row finding(s) not shown", l.rowIssues-MaxLintRowFindings),
+		})
+	}
+	return &pkt, l.findings
+}
+
+// header — обязательные поля и согласованность заголовка.
+func (l *linter) header(pkt *packet.DataPacket) {
+	h := pkt.Header
+	if pkt.Protocol != "TDTP" {
+		l.add(LintError, LintCheckHeader, 0, "", "protocol is %q, expected TDTP", pkt.Protocol)
+	}
+	if pkt.Version == "" {
+		l.add(LintError, LintCheckHeader, 0, "", "version is required")
+	}
+	switch h.Type {
+	case "":
+		l.add(LintError, LintCheckHeader, 0, "", "Type is required")
+	case packet.TypeReference, packet.TypeRequest, packet.TypeResponse, packet.TypeAlarm, packet.TypeError:
+	default:
+		l.add(LintError, LintCheckHeader, 0, "", "unknown message type %s", h.Type)
+	}
+	if h.TableName == "" {
+		l.add(LintError, LintCheckHeader, 0, "", "TableName is required")
+	}
+	if h.MessageID == "" {
+		l.add(LintError, LintCheckHeader, 0, "", "MessageID is required")
+	}
+	if h.Timestamp.IsZero() {
+		l.add(LintError, LintCheckHeader, 0, "", "Timestamp is required")
+	}
+	if h.Type == packet.TypeResponse && h.InReplyTo == "" {
+		l.add(LintError, LintCheckHeader, 0, "", "InReplyTo is required for response messages")
+	}
+	if h.Priority < packet.PriorityNormal || h.Priority > packet.MaxPriority {
+		l.add(LintError, LintCheckHeader, 0, "", "Priority %d is out of range 0..%d", h.Priority, packet.MaxPriority)
+	}
+	if err := h.Extensions.Validate(); err != nil {
+		l.add(LintError, LintCheckHeader, 0, "", "%v", err)
+	}
+	if h.PartNumber > 0 || h.TotalParts > 0 {
+		switch {
+		case h.PartNumber < 1:
+			l.add(LintError, LintCheckParts, 0, "", "PartNumber %d must be >= 1", h.PartNumber)
+		case h.TotalParts < 1:
+			l.add(LintError, LintCheckParts, 0, "", "TotalParts %d must be >= 1", h.TotalParts)
+		case h.PartNumber > h.TotalParts:
+			l.add(LintError, LintCheckParts, 0, "", "PartNumber %d exceeds TotalParts %d", h.PartNumber, h.TotalParts)
+		}
+	}
+
+	d := pkt.Data
+	if d.Delta && d.Compact {
+		l.add(LintError, LintCheckData, 0, "", "delta packets cannot use compact format")
+	}
+	if d.Delete && (d.Delta || d.Compact) {
+		l.add(LintError, LintCheckData, 0, "", "delete packets cannot be delta or compact")
+	}
+	switch d.Layout {
+	case "":
+	case packet.LayoutColumnar:
+		if d.Compression != "" || d.Encryption != "" || d.Compact || d.Delta || d.Delete {
+			l.add(LintError, LintCheckData, 0, "", "columnar layout cannot be combined with compression, encryption, compact, delta or delete")
+		}
+		if len(d.Rows) > 0 {
+			l.add(LintError, LintCheckData, 0, "", "columnar layout cannot contain <R> rows")
+		}
+	default:
+		l.add(LintError, LintCheckData, 0, "", "unknown data layout %s", d.Layout)
+	}
+}
+
+// schema — имена, типы и их параметры, ключ.
+func (l *linter) schema(s packet.Schema, hasData bool) {
+	if len(s.Fields) == 0 {
+		if hasData {
+			l.add(LintError, LintCheckSchema, 0, "", "schema is required when data is present")
+		}
+		return
+	}
+
+	seen := make(map[string]string, len(s.Fields))
+	hasKey := false
+	for i, f := range s.Fields {
+		if f.Name == "" {
+			l.add(LintError, LintCheckSchema, 0, "", "field %d has an empty name", i+1)
+			continue
+		}
+		switch prev, dup := seen[strings.ToLower(f.Name)]; {
+		case dup && prev == f.Name:
+			l.add(LintError, LintCheckSchema, 0, f.Name, "duplicate field name")
+		case dup:
+			l.add(LintWarning, LintCheckSchema, 0, f.Name, "differs from field %s only by case: adapters match columns case-insensitively", prev)
+		}
+		seen[strings.ToLower(f.Name)] = f.Name
+		hasKey = hasKey || f.Key
+
+		dt := DataType(f.Type)
+		if !IsValidType(dt) {
+			l.add(LintError, LintCheckSchema, 0, f.Name, "unknown type %q", f.Type)
+			continue
+		}
+		switch NormalizeType(dt) {
+		case TypeText:
+			if f.Length < -1 {
+				l.add(LintError, LintCheckSchema, 0, f.Name, "length %d is invalid (0 or -1 means unlimited)", f.Length)
+			}
+		case TypeDecimal:
+			precision, scale := f.Precision, f.Scale
+			if precision == 0 {
+				precision = GetDefaultPrecision()
+			}
+			if scale == 0 {
+				scale = GetDefaultScale()
+			}
+			if precision < 1 || precision > 38 {
+				l.add(LintError, LintCheckSchema, 0, f.Name, "DECIMAL precision %d must be between 1 and 38", precision)
+			} else if scale < 0 || scale > precision {
+				l.add(LintError, LintCheckSchema, 0, f.Name, "DECIMAL scale %d must be between 0 and precision %d", scale, precision)
+			}
+		case TypeBlob:
+			if f.Key {
+				l.add(LintWarning, LintCheckSchema, 0, f.Name, "BLOB key field: most databases cannot index it")
+			}
+		}
+	}
+	if !hasKey {
+		l.add(LintWarning, LintCheckSchema, 0, "", "no key fields: replace, merge and ignore imports cannot match existing rows")
+	}
+	if s.Dictionary != nil {
+		if err := packet.ValidateDictionary(s.Dictionary.Entries); err != nil {
+			l.add(LintError, LintCheckSchema, 0, "", "dictionary: %v", err)
+		}
+	}
+}
+
+// data разворачивает строки (распаковка, compact, колонки) и сверяет их
+// число и контрольные суммы с заголовком. false — строки проверять нельзя.
+func (l *linter) data(ctx context.Context, pkt *packet.DataPacket) bool {
+	d := &pkt.Data
+	switch {
+	case d.Compression != "":
+		if len(d.Rows) != 1 {
+			l.add(LintError, LintCheckData, 0, "", "compressed data must be a single <R> blob, got %d", len(d.Rows))
+			return false
+		}
+		// распаковка разворачивает и compact-строки
+		if err := packet.DecompressPacketData(ctx, pkt); err != nil {
+			l.add(LintError, LintCheckData, 0, "", "%v", err)
+			return false
+		}
+
+	case d.Layout == packet.LayoutColumnar:
+		if err := packet.ExpandColumnarRows(pkt); err != nil {
+			l.add(LintError, LintCheckData, 0, "", "columnar data: %v", err)
+			return false
+		}
+
+	case d.Compact:
+		if err := packet.ExpandCompactRows(pkt); err != nil {
+			l.add(LintError, LintCheckData, 0, "", "compact data: %v", err)
+			return false
+		}
+	}
+
+	if declared := pkt.Header.RecordsInPart; declared > 0 && packet.NeedsRowCountCheck(pkt.Version) && declared != len(d.Rows) {
+		l.add(LintError, LintCheckData, 0, "", "RecordsInPart declares %d row(s), <Data> contains %d", declared, len(d.Rows))
+	}
+	if len(pkt.Schema.Fields) == 0 {
+		return false
+	}
+	if pkt.Header.Checksum != nil {
+		if err := packet.VerifyRowChecksum(pkt); err != nil {
+			l.add(LintError, LintCheckData, 0, "", "%v", err)
+		}
+	}
+	if err := packet.VerifyIntegrity(pkt); err != nil {
+		l.add(LintError, LintCheckData, 0, "", "%v", err)
+	}
+	return true
+}
+
+// rows — число полей в строках и формат значений по типу поля.
+func (l *linter) rows(pkt *packet.DataPacket) {
+	fields := make([]FieldDef, len(pkt.Schema.Fields))
+	byName := make(map[string]int, len(fields))
+	for i, f := range pkt.Schema.Fields {
+		fields[i] = FieldDef{
+			Name: f.Name, Type: DataType(f.Type), Subtype: f.Subtype, Length: f.Length,
+			Precision: f.Precision, Scale: f.Scale, Timezone: f.Timezone,
+			Key: f.Key, Nullable: !f.Key,
+		}
+		byName[strings.ToLower(f.Name)] = i
+	}
+	conv := NewConverter()
+	value := func(row, i int, v string) {
+		if !IsValidType(fields[i].Type) || isSpecialMarker(pkt.Schema.Fields[i], v) {
+			return
+		}
+		if _, err := conv.ParseValue(v, fields[i]); err != nil {
+			msg := err.Error()
+			if ve, ok := err.(*ValidationError); ok {
+				msg = fmt.Sprintf("%s: %q", ve.Message, ve.Value)
+			}
+			l.add(LintError, LintCheckValue, row, fields[i].Name, "%s", msg)
+		}
+	}
+
+	if pkt.Data.Delta {
+		deltas, err := pkt.DeltaRows()
+		if err != nil {
+			l.add(LintError, LintCheckRow, 0, "", "delta rows: %v", err)
+			return
+		}
+		keys := keyIndices(pkt.Schema)
+		for r, d := range deltas {
+			for k, v := range d.Key {
+				value(r+1, keys[k], v)
+			}
+			for _, c := range d.Changes {
+				i := byName[strings.ToLower(c.Field)]
+				value(r+1, i, c.Old)
+				value(r+1, i, c.New)
+			}
+		}
+		return
+	}
+
+	dict := packet.NewDictExpander(pkt.Schema.Dictionary)
+	parser := packet.NewParser()
+	keys := keyIndices(pkt.Schema)
+	seen := make(map[string]int)
+	for r, row := range pkt.Data.Rows {
+		row.Value = dict.ExpandRow(row.Value)
+		values := parser.GetRowValues(row)
+		if len(values) != len(fields) {
+			l.add(LintError, LintCheckRow, r+1, "", "row has %d value(s), schema has %d field(s)", len(values), len(fields))
+			continue
+		}
+		for i, v := range values {
+			value(r+1, i, v)
+		}
+		if len(keys) == 0 {
+			continue
+		}
+		key := make([]string, len(keys))
+		for k, i := range keys {
+			key[k] = values[i]
+		}
+		id := packet.JoinRowEscaped(key)
+		if first, dup := seen[id]; dup {
+			l.add(LintWarning, LintCheckRow, r+1, "", "duplicate key %v (first at row %d)", key, first)
+		} else {
+			seen[id] = r + 1
+		}
+	}
+}
+
+// keyIndices — позиции ключевых полей схемы.
+func keyIndices(s packet.Schema) []int {
+	var idx []int
+	for i, f := range s.Fields {
+		if f.Key {
+			idx = append(idx, i)
+		}
+	}
+	return idx
+}
+
+// isSpecialMarker — значение v является маркером SpecialValues поля.
+func isSpecialMarker(f packet.Field, v string) bool {
+	sv := f.SpecialValues
+	if sv == nil {
+		return false
+	}
+	for _, m := range []*packet.MarkerValue{sv.Null, sv.Infinity, sv.NegInfinity, sv.NaN, sv.NoDate} {
+		if m != nil && m.Marker == v {
+			return true
+		}
+	}
+	return false
+}
+
+// LintParts сверяет части многочастного набора между собой: одна таблица
+// и одно число частей, номера 1..TotalParts без пропусков и повторов,
+// уникальные MessageID, одинаковая схема. Один файл из набора — только
+// предупреждение: остальные части не проверены.
+func LintParts(parts []LintedPacket) []LintFinding {
+	var findings []LintFinding
+	add := func(sev LintSeverity, file, format string, args ...any) {
+		findings = append(findings, LintFinding{Severity: sev, Check: LintCheckParts, File: file, Message: fmt.Sprintf(format, args...)})
+	}
+	if len(parts) == 0 {
+		return nil
+	}
+
+	first := parts[0].Packet.Header
+	if len(parts) == 1 {
+		if first.TotalParts > 1 {
+			add(LintWarning, parts[0].File, "part %d of %d: the other parts are not checked", first.PartNumber, first.TotalParts)
+		}
+		return findings
+	}
+
+	numbers := make(map[int]string, len(parts))
+	ids := make(map[string]string, len(parts))
+	for _, p := range parts {
+		h := p.Packet.Header
+		if h.TableName != first.TableName {
+			add(LintError, p.File, "TableName %q differs from %q in %s", h.TableName, first.TableName, parts[0].File)
+		}
+		if h.TotalParts != first.TotalParts {
+			add(LintError, p.File, "TotalParts %d differs from %d in %s", h.TotalParts, first.TotalParts, parts[0].File)
+		}
+		if h.InReplyTo != first.InReplyTo {
+			add(LintError, p.File, "InReplyTo %q differs from %q in %s (mixed batches?)", h.InReplyTo, first.InReplyTo, parts[0].File)
+		}
+		if prev, dup := numbers[h.PartNumber]; dup {
+			add(LintError, p.File, "PartNumber %d repeats %s", h.PartNumber, prev)
+		}
+		numbers[h.PartNumber] = p.File
+		if prev, dup := ids[h.MessageID]; dup && h.MessageID != "" {
+			add(LintError, p.File, "MessageID %s repeats %s", h.MessageID, prev)
+		}
+		ids[h.MessageID] = p.File
+		if !sameFields(p.Packet.Schema, parts[0].Packet.Schema) {
+			add(LintError, p.File, "schema differs from %s", parts[0].File)
+		}
+	}
+	for n := 1; n <= first.TotalParts; n++ {
+		if _, ok := numbers[n]; !ok {
+			add(LintError, "", "part %d of %d is missing", n, first.TotalParts)
+		}
+	}
+	return findings
+}
+
+// sameFields — одинаковые имена и типы полей (зашифрованные схемы не сравниваются).
+func sameFields(a, b packet.Schema) bool {
+	if a.Encryption != "" || b.Encryption != "" {
+		return true
+	}
+	if len(a.Fields) != len(b.Fields) {
+		return false
+	}
+	for i := range a.Fields {
+		if a.Fields[i].Name != b.Fields[i].Name || a.Fields[i].Type != b.Fields[i].Type || a.Fields[i].Key != b.Fields[i].Key {
+			return false
+		}
+	}
+	return true
+}
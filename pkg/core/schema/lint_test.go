@@ -0,0 +1,98 @@
+package schema
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+const lintXML = `<?xml version="1.0" encoding="UTF-8"?>
+<DataPacket protocol="TDTP" version="1.0">
+  <Header>
+    <Type>reference</Type>
+    <TableName>orders</TableName>
+    <MessageID>REF-1-P2</MessageID>
+    <PartNumber>2</PartNumber>
+    <TotalParts>2</TotalParts>
+    <RecordsInPart>4</RecordsInPart>
+    <Timestamp>2025-11-13T10:00:00Z</Timestamp>
+  </Header>
+  <Schema>
+    <Field name="id" type="INTEGER" key="true"/>
+    <Field name="placed" type="DATE"/>
+    <Field name="amount" type="DECIMAL" precision="40"/>
+  </Schema>
+  <Data>
+    <R>1|2025-01-31|10.50</R>
+    <R>x|2025-02-30|1</R>
+    <R>1|2025-03-01</R>
+  </Data>
+</DataPacket>`
+
+// lintHas — есть ли замечание check (с полем field и строкой row, если заданы).
+func lintHas(findings []LintFinding, sev LintSeverity, check string, row int, field string) bool {
+	for _, f := range findings {
+		if f.Severity == sev && f.Check == check && (row == 0 || f.Row == row) && (field == "" || f.Field == field) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestLintPacket(t *testing.T) {
+	pkt, findings := LintPacket(context.Background(), "orders.xml", []byte(lintXML))
+	if pkt == nil {
+		t.Fatal("packet not returned")
+	}
+	for _, want := range []struct {
+		check string
+		row   int
+		field string
+	}{
+		{LintCheckSchema, 0, "amount"}, // precision 40
+		{LintCheckData, 0, ""},         // RecordsInPart 4, rows 3
+		{LintCheckValue, 2, "id"},
+		{LintCheckValue, 2, "placed"},
+		{LintCheckRow, 3, ""}, // 2 values of 3
+	} {
+		if !lintHas(findings, LintError, want.check, want.row, want.field) {
+			t.Errorf("no %s error at row %d field %q in %v", want.check, want.row, want.field, findings)
+		}
+	}
+	if lintHas(findings, LintError, LintCheckValue, 1, "") {
+		t.Errorf("valid row 1 reported: %v", findings)
+	}
+	if !HasLintErrors(findings) || findings[0].File != "orders.xml" {
+		t.Errorf("findings %v", findings)
+	}
+
+	if _, findings := LintPacket(context.Background(), "bad.xml", []byte("<DataPacket><Header>")); !lintHas(findings, LintError, LintCheckXML, 0, "") {
+		t.Errorf("malformed XML: %v", findings)
+	}
+}
+
+func TestLintParts(t *testing.T) {
+	part := func(n int, id string) LintedPacket {
+		pkt, _ := LintPacket(context.Background(), "p", []byte(strings.Replace(
+			strings.Replace(lintXML, "<PartNumber>2<", "<PartNumber>"+string(rune('0'+n))+"<", 1),
+			"REF-1-P2", id, 1)))
+		return LintedPacket{File: id, Packet: pkt}
+	}
+
+	findings := LintParts([]LintedPacket{part(2, "a"), part(2, "a")})
+	for _, msg := range []string{"PartNumber 2 repeats", "MessageID a repeats", "part 1 of 2 is missing"} {
+		found := false
+		for _, f := range findings {
+			found = found || strings.Contains(f.Message, msg)
+		}
+		if !found {
+			t.Errorf("no %q in %v", msg, findings)
+		}
+	}
+	if findings := LintParts([]LintedPacket{part(1, "a"), part(2, "b")}); HasLintErrors(findings) {
+		t.Errorf("complete set: %v", findings)
+	}
+	if findings := LintParts([]LintedPacket{part(2, "b")}); !lintHas(findings, LintWarning, LintCheckParts, 0, "") {
+		t.Errorf("single part of two: %v", findings)
+	}
+}
@@ -0,0 +1,100 @@
+package schema
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// NumberFormat — формат чисел в текстовых источниках (XLSX, CSV, выгрузки 1С),
+// где числа записаны с разделителями локали: "1 234,56", "1.234,56".
+// Normalize приводит такое значение к каноническому виду TDTP ("1234.56").
+type NumberFormat struct {
+	Decimal   rune   // десятичный разделитель (0 — '.')
+	Thousands string // допустимые разделители разрядов (каждый символ — разделитель)
+}
+
+// numberFormats — предустановленные форматы для LookupNumberFormat.
+// Пробелы включают неразрывный (U+00A0, 1С и Excel) и узкий неразрывный (U+202F).
+var numberFormats = map[string]NumberFormat{
+	"ru": {Decimal: ',', Thousands: " \u00a0\u202f"},
+	"fr": {Decimal: ',', Thousands: " \u00a0\u202f"},
+	"de": {Decimal: ',', Thousands: "."},
+	"en": {Decimal: '.', Thousands: ","},
+	"ch": {Decimal: '.', Thousands: "'\u2019"},
+}
+
+// LookupNumberFormat возвращает предустановленный формат: ru, fr ("1 234,56"),
+// de ("1.234,56"), en ("1,234.56"), ch ("1'234.56").
+func LookupNumberFormat(name string) (NumberFormat, error) {
+	nf, ok := numberFormats[strings.ToLower(name)]
+	if !ok {
+		names := make([]string, 0, len(numberFormats))
+		for n := range numberFormats {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return NumberFormat{}, fmt.Errorf("unknown number format %q (supported: %s)", name, strings.Join(names, ", "))
+	}
+	return nf, nil
+}
+
+// Normalize переводит число в формате nf в канонический вид ("1 234,56" → "1234.56").
+// Разделители разрядов допускаются только между группами из трёх цифр, а
+// десятичный — не более одного раза: иначе ошибка, а не молча искажённое
+// значение ("1.5" в формате de — не 15).
+func (nf NumberFormat) Normalize(value string) (string, error) {
+	s := strings.TrimSpace(value)
+	dec := nf.Decimal
+	if dec == 0 {
+		dec = '.'
+	}
+
+	sign := ""
+	if strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+") {
+		sign, s = s[:1], s[1:]
+	}
+
+	intPart, frac, hasFrac := strings.Cut(s, string(dec))
+	if strings.ContainsRune(frac, dec) {
+		return "", fmt.Errorf("invalid number %q: more than one decimal separator", value)
+	}
+	isSep := func(r rune) bool { return strings.ContainsRune(nf.Thousands, r) }
+	if strings.ContainsFunc(frac, isSep) {
+		return "", fmt.Errorf("invalid number %q: thousands separator after decimal separator", value)
+	}
+
+	if strings.ContainsFunc(intPart, isSep) {
+		var groups []string
+		start := 0
+		for i, r := range intPart {
+			if isSep(r) {
+				groups = append(groups, intPart[start:i])
+				start = i + len(string(r))
+			}
+		}
+		groups = append(groups, intPart[start:])
+		for i, g := range groups {
+			if (i == 0 && (g == "" || len(g) > 3)) || (i > 0 && len(g) != 3) {
+				return "", fmt.Errorf("invalid number %q: misplaced thousands separator", value)
+			}
+		}
+		intPart = strings.Join(groups, "")
+	}
+
+	result := sign + intPart
+	if hasFrac {
+		result += "." + frac
+	}
+	if _, err := strconv.ParseFloat(result, 64); err != nil {
+		return "", fmt.Errorf("invalid number %q", value)
+	}
+	return result, nil
+}
+
+// SetNumberFormat включает разбор INTEGER/REAL/DECIMAL значений в формате nf
+// (nil — только канонический формат).
+func (c *Converter) SetNumberFormat(nf *NumberFormat) {
+	c.numberFormat = nf
+}
@@ -1,6 +1,7 @@
 package schema
 
 import (
+	"strings"
 	"testing"
 	"time"
 
@@ -120,6 +121,209 @@ func TestConverterDecimal(t *testing.T) {
 	}
 }
 
+func TestNumberFormatNormalize(t *testing.T) {
+	ru, _ := LookupNumberFormat("ru")
+	de, _ := LookupNumberFormat("DE")
+	en, _ := LookupNumberFormat("en")
+
+	valid := []struct {
+		nf    NumberFormat
+		value string
+		want  string
+	}{
+		{ru, "1 234,56", "1234.56"},
+		{ru, "1\u00a0234\u202f567,5", "1234567.5"}, // неразрывные пробелы 1С/Excel
+		{ru, "-12,5", "-12.5"},
+		{ru, "1234", "1234"},
+		{de, "1.234,56", "1234.56"},
+		{en, "1,234.56", "1234.56"},
+		{en, "+0.5", "+0.5"},
+	}
+	for _, tt := range valid {
+		got, err := tt.nf.Normalize(tt.value)
+		if err != nil || got != tt.want {
+			t.Errorf("Normalize(%q) = %q, %v; want %q", tt.value, got, err, tt.want)
+		}
+	}
+
+	// Неоднозначные значения — ошибка, а не искажённое число
+	for _, value := range []string{"1.5", "1.23.4", "12.34,5.6", "1,2,3", "12 000", "1e"} {
+		if got, err := de.Normalize(value); err == nil {
+			t.Errorf("de.Normalize(%q) = %q, expected error", value, got)
+		}
+	}
+
+	if _, err := LookupNumberFormat("xx"); err == nil {
+		t.Error("Expected error for unknown number format")
+	}
+}
+
+func TestBoolMapping(t *testing.T) {
+	m, err := ParseBoolMapping("Y, Да / N,Нет")
+	if err != nil {
+		t.Fatalf("ParseBoolMapping failed: %v", err)
+	}
+	for value, want := range map[string]bool{"Y": true, "y": true, " да ": true, "N": false, "НЕТ": false} {
+		got, err := m.Parse(value)
+		if err != nil || got != want {
+			t.Errorf("Parse(%q) = %v, %v; want %v", value, got, err, want)
+		}
+	}
+	if _, err := m.Parse("maybe"); err == nil {
+		t.Error("Expected error for unmapped value")
+	}
+	if m.Format(true) != "Y" || m.Format(false) != "N" {
+		t.Errorf("Format: got %q/%q, want Y/N", m.Format(true), m.Format(false))
+	}
+
+	for _, bad := range []string{"Y", "Y/", "Y,N/N"} {
+		if _, err := ParseBoolMapping(bad); err == nil {
+			t.Errorf("ParseBoolMapping(%q) should fail", bad)
+		}
+	}
+}
+
+func TestConverterNumberFormat(t *testing.T) {
+	converter := NewConverter()
+	ru, _ := LookupNumberFormat("ru")
+	converter.SetNumberFormat(&ru)
+
+	decimal := FieldDef{Name: "Amount", Type: TypeDecimal, Precision: 10, Scale: 2, Nullable: true}
+	tv, err := converter.ParseValue("1 234,56", decimal)
+	if err != nil {
+		t.Fatalf("Failed to parse localized decimal: %v", err)
+	}
+	if got := converter.FormatValue(tv); got != "1234.56" {
+		t.Errorf("Expected 1234.56, got %s", got)
+	}
+
+	tv, err = converter.ParseValue("12 000", FieldDef{Name: "Qty", Type: TypeInteger, Nullable: true})
+	if err != nil || tv.IntValue == nil || *tv.IntValue != 12000 {
+		t.Errorf("Expected 12000, got %v (%v)", tv, err)
+	}
+
+	if _, err := converter.ParseValue("12 00", decimal); err == nil {
+		t.Error("Expected error for misplaced thousands separator")
+	}
+
+	// TEXT не затрагивается
+	tv, err = converter.ParseValue("1 234,56", FieldDef{Name: "Note", Type: TypeText})
+	if err != nil || converter.FormatValue(tv) != "1 234,56" {
+		t.Errorf("TEXT value changed: %v (%v)", tv, err)
+	}
+}
+
+func TestDateFormatNormalize(t *testing.T) {
+	tests := []struct {
+		df    DateFormat
+		value string
+		typ   DataType
+		want  string
+	}{
+		{DateDMY, "31.12.2024", TypeDate, "2024-12-31"},
+		{DateDMY, "1/2/2024", TypeDate, "2024-02-01"},
+		{DateMDY, "12/31/2024", TypeDate, "2024-12-31"},
+		{DateMDY, "1/2/2024", TypeDate, "2024-01-02"},
+		{DateDMY, "2024-12-31", TypeDate, "2024-12-31"}, // год впереди — ymd при любом формате
+		{DateDMY, "31.12.2024 15:04", TypeTimestamp, "2024-12-31T15:04:00Z"},
+		{DateMDY, "12/31/2024 15:04:05", TypeDatetime, "2024-12-31T15:04:05Z"},
+		{DateYMD, "2024-12-31T15:04:05+03:00", TypeTimestamp, "2024-12-31T12:04:05Z"},
+	}
+	for _, tt := range tests {
+		got, err := tt.df.Normalize(tt.value, tt.typ)
+		if err != nil || got != tt.want {
+			t.Errorf("%s.Normalize(%q) = %q, %v; want %q", tt.df, tt.value, got, err, tt.want)
+		}
+	}
+
+	for _, bad := range []struct {
+		df    DateFormat
+		value string
+	}{
+		{DateMDY, "31/12/2024"}, // месяца 31 нет
+		{DateDMY, "31.02.2024"}, // несуществующая дата
+		{DateYMD, "31.12.2024"}, // год не впереди
+		{DateDMY, "31.12.24"},   // двузначный год
+		{DateDMY, "31.12.2024 25:00"},
+	} {
+		if _, err := bad.df.Normalize(bad.value, TypeDate); err == nil {
+			t.Errorf("%s.Normalize(%q) should fail", bad.df, bad.value)
+		}
+	}
+
+	if df, err := LookupDateFormat("RU"); err != nil || df != DateDMY {
+		t.Errorf("LookupDateFormat(RU) = %q, %v", df, err)
+	}
+	if _, err := LookupDateFormat("xx"); err == nil {
+		t.Error("LookupDateFormat(xx) should fail")
+	}
+}
+
+func TestDetectDateFormat(t *testing.T) {
+	d, err := DetectDateFormat([]string{"01.02.2024", "", "31.12.2024"}, "")
+	if err != nil || d.Format != DateDMY || d.Ambiguous {
+		t.Errorf("day-first sample: %+v, %v", d, err)
+	}
+	d, err = DetectDateFormat([]string{"01/02/2024", "12/31/2024"}, DateDMY)
+	if err != nil || d.Format != DateMDY || d.Ambiguous {
+		t.Errorf("month-first sample: %+v, %v", d, err)
+	}
+	d, err = DetectDateFormat([]string{"2024-12-31"}, "")
+	if err != nil || d.Format != DateYMD || d.Ambiguous {
+		t.Errorf("ISO sample: %+v, %v", d, err)
+	}
+
+	// Ни одно значение не различает день и месяц — fallback с предупреждением
+	d, err = DetectDateFormat([]string{"01/02/2024", "03/04/2024"}, DateMDY)
+	if err != nil || d.Format != DateMDY || !d.Ambiguous || d.Example != "01/02/2024" {
+		t.Errorf("ambiguous sample: %+v, %v", d, err)
+	}
+
+	if _, err := DetectDateFormat([]string{"31/12/2024", "12/31/2024"}, ""); err == nil {
+		t.Error("mixed day-first and month-first sample should fail")
+	}
+}
+
+func TestParseDateFormats(t *testing.T) {
+	dfs, err := ParseDateFormats("dmy, Shipped=us,created=auto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dfs.For("amount") != DateDMY || dfs.For("shipped") != DateMDY || dfs.For("Created") != DateAuto {
+		t.Errorf("unexpected formats: %+v", dfs)
+	}
+	for _, bad := range []string{"dmy,mdy", "shipped=xx"} {
+		if _, err := ParseDateFormats(bad); err == nil {
+			t.Errorf("ParseDateFormats(%q) should fail", bad)
+		}
+	}
+}
+
+func TestConverterDateFormats(t *testing.T) {
+	converter := NewConverter()
+	converter.SetDateFormats(&DateFormats{Default: DateDMY, Columns: map[string]DateFormat{"shipped": DateAuto}})
+
+	tv, err := converter.ParseValue("31.12.2024", FieldDef{Name: "Created", Type: TypeDate, Nullable: true})
+	if err != nil || converter.FormatValue(tv) != "2024-12-31" {
+		t.Errorf("Expected 2024-12-31, got %v (%v)", tv, err)
+	}
+
+	shipped := FieldDef{Name: "Shipped", Type: TypeTimestamp, Nullable: true}
+	tv, err = converter.ParseValue("12/31/2024 10:30", shipped)
+	if err != nil || converter.FormatValue(tv) != "2024-12-31T10:30:00Z" {
+		t.Errorf("Expected 2024-12-31T10:30:00Z, got %v (%v)", tv, err)
+	}
+	if _, err := converter.ParseValue("01/02/2024", shipped); err == nil || !strings.Contains(err.Error(), "ambiguous") {
+		t.Errorf("Expected ambiguous date error, got %v", err)
+	}
+
+	// TIME не затрагивается
+	tv, err = converter.ParseValue("08:00:00", FieldDef{Name: "Opens", Type: TypeTimestamp, Subtype: "time"})
+	if err != nil || tv.TimeValue == nil {
+		t.Errorf("TIME value rejected: %v", err)
+	}
+}
+
 func TestConverterText(t *testing.T) {
 	converter := NewConverter()
 	field := FieldDef{
@@ -0,0 +1,142 @@
+package tdtql
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+	"github.com/ruslano69/tdtp-framework/pkg/core/schema"
+)
+
+// AggregateFunc агрегатная функция над колонкой
+type AggregateFunc string
+
+const (
+	AggCount AggregateFunc = "COUNT"
+	AggSum   AggregateFunc = "SUM"
+	AggAvg   AggregateFunc = "AVG"
+	AggMin   AggregateFunc = "MIN"
+	AggMax   AggregateFunc = "MAX"
+)
+
+// Aggregate вычисляет агрегат по колонке field на строках rows
+// (обычно — результат ExecuteWhere).
+//
+// Как в SQL, NULL не участвуют: пустые значения нетекстовых полей, маркеры
+// SpecialValues (Null, NaN, ±Infinity, NoDate) и NaN/±Inf. COUNT с field ""
+// или "*" считает все строки. SUM/AVG — только числовые поля; SUM INTEGER остаётся
+// целым, DECIMAL округляется до Scale. MIN/MAX сравнивают по типу поля и
+// возвращают исходное значение. Без значений результат "" (NULL), COUNT — "0".
+func (e *Executor) Aggregate(fn AggregateFunc, field string, rows [][]string, schemaObj packet.Schema) (string, error) {
+	fn = AggregateFunc(strings.ToUpper(string(fn)))
+	if fn == AggCount && (field == "" || field == "*") {
+		return strconv.Itoa(len(rows)), nil
+	}
+
+	idx := -1
+	for i, f := range schemaObj.Fields {
+		if strings.EqualFold(f.Name, field) {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		return "", fmt.Errorf("field '%s' not found in schema", field)
+	}
+	sf := schemaObj.Fields[idx]
+	fieldDef := schema.FieldDef{
+		Name:      sf.Name,
+		Type:      schema.DataType(sf.Type),
+		Length:    sf.Length,
+		Precision: sf.Precision,
+		Scale:     sf.Scale,
+		Timezone:  sf.Timezone,
+		Nullable:  true,
+	}
+	normalized := schema.NormalizeType(fieldDef.Type)
+
+	switch fn {
+	case AggCount, AggMin, AggMax:
+	case AggSum, AggAvg:
+		if normalized != schema.TypeInteger && normalized != schema.TypeReal && normalized != schema.TypeDecimal {
+			return "", fmt.Errorf("%s requires a numeric field, '%s' is %s", fn, sf.Name, sf.Type)
+		}
+	default:
+		return "", fmt.Errorf("unsupported aggregate function: %s", fn)
+	}
+
+	var (
+		count    int
+		intSum   int64
+		floatSum float64
+		best     *schema.TypedValue
+		bestRaw  string
+	)
+	for r, row := range rows {
+		if idx >= len(row) || isAggregateNull(sf, row[idx]) {
+			continue
+		}
+		tv, err := e.converter.ParseValue(row[idx], fieldDef)
+		if err != nil {
+			return "", fmt.Errorf("row %d, field '%s': %w", r, sf.Name, err)
+		}
+		if tv.IsNull || (tv.FloatValue != nil && (math.IsNaN(*tv.FloatValue) || math.IsInf(*tv.FloatValue, 0))) {
+			continue
+		}
+		count++
+
+		switch fn {
+		case AggSum, AggAvg:
+			if tv.IntValue != nil {
+				intSum += *tv.IntValue
+				floatSum += float64(*tv.IntValue)
+			} else if tv.FloatValue != nil {
+				floatSum += *tv.FloatValue
+			}
+		case AggMin, AggMax:
+			if best == nil {
+				best, bestRaw = tv, row[idx]
+				continue
+			}
+			cmp := compareTyped(normalized, tv, best, row[idx], bestRaw)
+			if (fn == AggMin && cmp < 0) || (fn == AggMax && cmp > 0) {
+				best, bestRaw = tv, row[idx]
+			}
+		}
+	}
+
+	switch fn {
+	case AggCount:
+		return strconv.Itoa(count), nil
+	case AggMin, AggMax:
+		return bestRaw, nil
+	}
+	if count == 0 {
+		return "", nil
+	}
+	if fn == AggAvg {
+		return strconv.FormatFloat(floatSum/float64(count), 'f', -1, 64), nil
+	}
+	switch {
+	case normalized == schema.TypeInteger:
+		return strconv.FormatInt(intSum, 10), nil
+	case normalized == schema.TypeDecimal && sf.Scale > 0:
+		return strconv.FormatFloat(floatSum, 'f', sf.Scale, 64), nil
+	default:
+		return strconv.FormatFloat(floatSum, 'f', -1, 64), nil
+	}
+}
+
+// isAggregateNull — значение не участвует в агрегатах
+func isAggregateNull(f packet.Field, v string) bool {
+	if sv := f.SpecialValues; sv != nil {
+		for _, m := range []*packet.MarkerValue{sv.Null, sv.NaN, sv.Infinity, sv.NegInfinity, sv.NoDate} {
+			if m != nil && v == m.Marker {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+package tdtql
+
+import (
+	"testing"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+func TestExecutor_Aggregate(t *testing.T) {
+	executor := NewExecutor()
+
+	schemaObj := packet.Schema{
+		Fields: []packet.Field{
+			{Name: "id", Type: "INTEGER", Key: true},
+			{Name: "qty", Type: "INTEGER"},
+			{Name: "amount", Type: "DECIMAL", Precision: 10, Scale: 2,
+				SpecialValues: &packet.SpecialValues{Null: &packet.MarkerValue{Marker: "N/A"}}},
+			{Name: "city", Type: "TEXT"},
+			{Name: "created", Type: "DATE"},
+		},
+	}
+	rows := [][]string{
+		{"1", "10", "100.50", "Moscow", "2024-03-01"},
+		{"2", "", "N/A", "Berlin", "2024-01-15"},
+		{"3", "5", "20.25", "Zurich", ""},
+		{"4", "7", "9.99", "Moscow", "2024-12-31"},
+	}
+
+	tests := []struct {
+		fn    AggregateFunc
+		field string
+		want  string
+	}{
+		{AggCount, "*", "4"},
+		{AggCount, "qty", "3"},
+		{AggCount, "amount", "3"}, // маркер NULL не считается
+		{AggSum, "qty", "22"},
+		{AggSum, "amount", "130.74"},
+		{AggAvg, "qty", "7.333333333333333"},
+		{AggMin, "amount", "9.99"}, // числовое сравнение, не строковое
+		{AggMax, "AMOUNT", "100.50"},
+		{AggMin, "city", "Berlin"},
+		{AggMax, "created", "2024-12-31"},
+		{"sum", "qty", "22"},
+	}
+	for _, tt := range tests {
+		got, err := executor.Aggregate(tt.fn, tt.field, rows, schemaObj)
+		if err != nil {
+			t.Errorf("%s(%s): %v", tt.fn, tt.field, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s(%s) = %q, want %q", tt.fn, tt.field, got, tt.want)
+		}
+	}
+}
+
+func TestExecutor_Aggregate_Errors(t *testing.T) {
+	executor := NewExecutor()
+	schemaObj := packet.Schema{Fields: []packet.Field{{Name: "name", Type: "TEXT"}}}
+	rows := [][]string{{"a"}}
+
+	if _, err := executor.Aggregate(AggSum, "name", rows, schemaObj); err == nil {
+		t.Error("SUM over TEXT should fail")
+	}
+	if _, err := executor.Aggregate(AggMax, "missing", rows, schemaObj); err == nil {
+		t.Error("unknown field should fail")
+	}
+	if _, err := executor.Aggregate("MEDIAN", "name", rows, schemaObj); err == nil {
+		t.Error("unknown function should fail")
+	}
+
+	got, err := executor.Aggregate(AggAvg, "n", nil, packet.Schema{Fields: []packet.Field{{Name: "n", Type: "REAL"}}})
+	if err != nil || got != "" {
+		t.Errorf("AVG of no rows = %q, %v; want NULL", got, err)
+	}
+}
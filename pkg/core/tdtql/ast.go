@@ -21,11 +21,14 @@ type Statement interface {
 
 // SelectStatement представляет SELECT запрос
 type SelectStatement struct {
-	TableName string
-	Where     Expression
-	OrderBy   []*OrderByClause
-	Limit     *int
-	Offset    *int
+	Fields     []string // список SELECT; пусто — SELECT *
+	TableName  string
+	TableAlias string
+	Join       *JoinClause
+	Where      Expression
+	OrderBy    []*OrderByClause
+	Limit      *int
+	Offset     *int
 }
 
 func (s *SelectStatement) node()      {}
@@ -34,10 +37,35 @@ func (s *SelectStatement) String() string {
 	return "SelectStatement"
 }
 
+// JoinClause представляет [INNER | LEFT [OUTER]] JOIN table [alias] ON ...
+type JoinClause struct {
+	Type  string // "INNER" или "LEFT"
+	Table string
+	Alias string
+	On    []*JoinCondition
+}
+
+func (j *JoinClause) node() {}
+func (j *JoinClause) String() string {
+	return "JoinClause: " + j.Type + " " + j.Table
+}
+
+// JoinCondition представляет равенство полей в ON: a.x = b.y
+type JoinCondition struct {
+	Left  string
+	Right string
+}
+
+func (j *JoinCondition) node() {}
+func (j *JoinCondition) String() string {
+	return "JoinCondition: " + j.Left + " = " + j.Right
+}
+
 // OrderByClause представляет элемент ORDER BY
 type OrderByClause struct {
 	Field     string
 	Direction string // "ASC" или "DESC"
+	Collation string // BCP 47 тег из COLLATE 'ru' (пусто = по умолчанию)
 }
 
 func (o *OrderByClause) node() {}
@@ -60,9 +88,12 @@ func (b *BinaryExpression) String() string {
 
 // ComparisonExpression представляет сравнение (=, !=, >, <, etc.)
 type ComparisonExpression struct {
-	Field    string
-	Operator string // "=", "!=", ">", "<", ">=", "<=", "LIKE", "NOT LIKE"
-	Value    any
+	Field     string
+	Operator  string // оператор TDTP: "eq", "ne", "gt", "like", "regex", "starts_with", "last"...
+	Value     any
+	Cast      string // CAST(field AS type): тип сравнения вместо типа поля
+	Collation string // cond COLLATE 'ci,trim' (ILIKE — 'ci'): правила сравнения строк
+	Trunc     string // DATE_TRUNC('month', field): единица усечения дат
 }
 
 func (c *ComparisonExpression) node()       {}
@@ -73,9 +104,12 @@ func (c *ComparisonExpression) String() string {
 
 // InExpression представляет IN оператор
 type InExpression struct {
-	Field  string
-	Values []string
-	Not    bool // для NOT IN
+	Field     string
+	Values    []string
+	Not       bool   // для NOT IN
+	Cast      string // CAST(field AS type)
+	Collation string // cond COLLATE 'ci'
+	Trunc     string // DATE_TRUNC('month', field)
 }
 
 func (i *InExpression) node()       {}
@@ -90,10 +124,13 @@ func (i *InExpression) String() string {
 
 // BetweenExpression представляет BETWEEN оператор
 type BetweenExpression struct {
-	Field string
-	Low   string
-	High  string
-	Not   bool // для NOT BETWEEN
+	Field     string
+	Low       string
+	High      string
+	Not       bool   // для NOT BETWEEN
+	Cast      string // CAST(field AS type)
+	Collation string // cond COLLATE 'ci'
+	Trunc     string // DATE_TRUNC('month', field)
 }
 
 func (b *BetweenExpression) node()       {}
@@ -0,0 +1,156 @@
+package tdtql
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+	"github.com/ruslano69/tdtp-framework/pkg/core/schema"
+)
+
+// ValidateCast проверяет, что строка — допустимый тип приведения в Filter.Cast
+// (пустая строка означает «тип поля из схемы»).
+func ValidateCast(cast string) error {
+	if cast == "" {
+		return nil
+	}
+	switch normalizeCast(cast) {
+	case schema.TypeInteger, schema.TypeReal, schema.TypeDecimal, schema.TypeText,
+		schema.TypeDate, schema.TypeDatetime, schema.TypeTimestamp:
+		return nil
+	default:
+		return fmt.Errorf("unsupported cast type %q", cast)
+	}
+}
+
+// normalizeCast приводит имя типа к каноническому TDTP (INT → INTEGER, FLOAT → REAL).
+func normalizeCast(cast string) schema.DataType {
+	return schema.NormalizeType(schema.DataType(strings.ToUpper(strings.TrimSpace(cast))))
+}
+
+// castFieldDef возвращает описание поля, по которому сравниваются значения
+// условия с заданным Cast.
+//
+// DECIMAL сравнивается как REAL: precision/scale из схемы к приведённому
+// значению не относятся, а проверка по умолчанию (18,2) отбросила бы
+// строки вроде "10.125".
+func castFieldDef(fieldDef schema.FieldDef, cast string) schema.FieldDef {
+	t := normalizeCast(cast)
+	if t == schema.TypeDecimal {
+		t = schema.TypeReal
+	}
+	fieldDef.Type = t
+	fieldDef.Precision = 0
+	fieldDef.Scale = 0
+	return fieldDef
+}
+
+// sqlCastType возвращает SQL-тип для CAST(field AS ...) при pushdown.
+// false — приведение не переносимо между СУБД и выполняется в памяти.
+//
+// Все числовые приведения транслируются в DECIMAL(38,10): это единственный
+// числовой тип CAST, одинаково понимаемый SQLite, PostgreSQL, MySQL и MSSQL
+// (MySQL не знает CAST AS INTEGER/REAL, у MSSQL DECIMAL без масштаба
+// отбрасывает дробную часть).
+func sqlCastType(cast string) (string, bool) {
+	switch normalizeCast(cast) {
+	case schema.TypeInteger, schema.TypeReal, schema.TypeDecimal:
+		return "DECIMAL(38,10)", true
+	case schema.TypeDate:
+		return "DATE", true
+	default:
+		return "", false
+	}
+}
+
+// coercibleOperators — операторы, результат которых зависит от порядка
+// значений и потому различается для лексикографического и числового сравнения.
+var coercibleOperators = map[string]bool{
+	"gt": true, "gte": true, "lt": true, "lte": true, "between": true,
+}
+
+// isNumberLiteral сообщает, является ли значение фильтра конечным числом.
+func isNumberLiteral(s string) bool {
+	f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	return err == nil && !math.IsNaN(f) && !math.IsInf(f, 0)
+}
+
+// CoerceTextFilters возвращает копию filters, в которой условиям >, >=, <,
+// <=, BETWEEN над TEXT-полями проставлен Cast=REAL, если все значения
+// условия — числа, а коллация запроса (Filters.Collation) перенесена в
+// условия над текстовыми значениями без своей; filters не меняется.
+//
+// Типичный случай — workspace-таблицы, созданные целиком из TEXT-колонок:
+// Balance > 1000 должно сравнивать числа, а не строки. Условия с явным Cast
+// или Collation и прочие операторы (=, IN, LIKE) не приводятся. Коллация
+// переносится в условия, чтобы её видел и SQL pushdown. Вызывается после
+// ValidateQuery, до выбора между SQL pushdown и фильтрацией в памяти.
+//
+// implicit сообщает, что хотя бы одно условие получило Cast автоматически.
+// Такой запрос фильтруется в памяти: нечисловой текст CAST в SQL приводит
+// по-разному (SQLite — к 0, и строка совпадает; PostgreSQL и MS SQL — с
+// ошибкой), а в памяти такая строка условию не удовлетворяет.
+func (e *Executor) CoerceTextFilters(filters *packet.Filters, schemaObj packet.Schema) (coerced *packet.Filters, implicit bool) {
+	if filters == nil {
+		return nil, false
+	}
+	coerced = &packet.Filters{Collation: filters.Collation}
+	coerced.And = e.coerceLogicalGroup(filters.And, schemaObj, filters.Collation, &implicit)
+	coerced.Or = e.coerceLogicalGroup(filters.Or, schemaObj, filters.Collation, &implicit)
+	return coerced, implicit
+}
+
+// CoerceQuery — CoerceTextFilters для запроса: копия query с приведёнными
+// условиями (query не меняется).
+func (e *Executor) CoerceQuery(query *packet.Query, schemaObj packet.Schema) (coerced *packet.Query, implicit bool) {
+	if query == nil || query.Filters == nil {
+		return query, false
+	}
+	q := *query
+	q.Filters, implicit = e.CoerceTextFilters(query.Filters, schemaObj)
+	return &q, implicit
+}
+
+// coerceLogicalGroup возвращает приведённую копию группы.
+func (e *Executor) coerceLogicalGroup(group *packet.LogicalGroup, schemaObj packet.Schema, collation string, implicit *bool) *packet.LogicalGroup {
+	if group == nil {
+		return nil
+	}
+	out := &packet.LogicalGroup{Filters: slices.Clone(group.Filters)}
+	for i := range out.Filters {
+		f := &out.Filters[i]
+		field, err := e.validator.GetFieldByName(schemaObj, f.Field)
+		if err != nil {
+			continue
+		}
+		isText := schema.NormalizeType(schema.DataType(field.Type)) == schema.TypeText
+		if f.Cast == "" && f.Collation == "" && isText && coercibleOperators[f.Operator] &&
+			isNumberLiteral(f.Value) && (f.Operator != "between" || isNumberLiteral(f.Value2)) {
+			f.Cast = string(schema.TypeReal)
+			*implicit = true
+		}
+		if f.Collation == "" && collation != "" && f.Operator != "is_null" && f.Operator != "is_not_null" &&
+			filterValueType(*f, field) == schema.TypeText {
+			f.Collation = collation
+		}
+	}
+	for i := range group.And {
+		out.And = append(out.And, *e.coerceLogicalGroup(&group.And[i], schemaObj, collation, implicit))
+	}
+	for i := range group.Or {
+		out.Or = append(out.Or, *e.coerceLogicalGroup(&group.Or[i], schemaObj, collation, implicit))
+	}
+	return out
+}
+
+// filterValueType — тип, в котором сравниваются значения условия: Cast,
+// иначе тип поля.
+func filterValueType(f packet.Filter, field *packet.Field) schema.DataType {
+	if f.Cast != "" {
+		return normalizeCast(f.Cast)
+	}
+	return schema.NormalizeType(schema.DataType(field.Type))
+}
@@ -0,0 +1,127 @@
+package tdtql
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+	"github.com/ruslano69/tdtp-framework/pkg/core/schema"
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+)
+
+// CollationBinary — явный запрос побайтового сравнения (поведение по умолчанию).
+const CollationBinary = "binary"
+
+// collatorSet лениво создаёт collate.Collator для каждого BCP 47 тега.
+//
+// Collator не безопасен для конкурентного использования (внутренние буферы),
+// поэтому набор создаётся на один вызов Sort и не разделяется между горутинами.
+type collatorSet map[string]*collate.Collator
+
+// get возвращает collator для тега; nil означает побайтовое сравнение.
+//
+// Тег — BCP 47 (например "ru", "de-u-co-phonebk", "sv"). Опции сравнения
+// задаются Unicode-расширением тега: "ru-u-ks-level2" — без учёта регистра,
+// "en-u-kn" — числа внутри строк сравниваются как числа.
+func (cs collatorSet) get(tag string) (*collate.Collator, error) {
+	if tag == "" || strings.EqualFold(tag, CollationBinary) {
+		return nil, nil
+	}
+	if c, ok := cs[tag]; ok {
+		return c, nil
+	}
+	lt, err := language.Parse(tag)
+	if err != nil {
+		return nil, fmt.Errorf("invalid collation %q: %w", tag, err)
+	}
+	c := collate.New(lt)
+	cs[tag] = c
+	return c, nil
+}
+
+// ValidateCollation проверяет, что строка — допустимая коллация
+// (пустая строка, "binary" или корректный BCP 47 тег).
+func ValidateCollation(tag string) error {
+	_, err := collatorSet{}.get(tag)
+	return err
+}
+
+// Опции коллации условий фильтра (Filter.Collation, Filters.Collation).
+const (
+	CollationCaseInsensitive = "ci"   // без учёта регистра
+	CollationTrim            = "trim" // без крайних пробелов
+)
+
+// filterCollation — разобранная коллация условия фильтра: опции через
+// запятую ("ci,trim", "ru,ci"). Нулевое значение — побайтовое сравнение.
+type filterCollation struct {
+	fold bool   // ci: оба значения в нижнем регистре
+	trim bool   // trim: крайние пробелы отбрасываются
+	tag  string // BCP 47 тег: сравнение по правилам языка
+}
+
+// parseFilterCollation разбирает коллацию условия. Пустая строка и "binary" —
+// побайтовое сравнение; тег языка допустим один.
+func parseFilterCollation(spec string) (filterCollation, error) {
+	var c filterCollation
+	for _, opt := range strings.Split(spec, ",") {
+		opt = strings.TrimSpace(opt)
+		switch {
+		case opt == "" || strings.EqualFold(opt, CollationBinary):
+		case strings.EqualFold(opt, CollationCaseInsensitive):
+			c.fold = true
+		case strings.EqualFold(opt, CollationTrim):
+			c.trim = true
+		case c.tag != "":
+			return filterCollation{}, fmt.Errorf("invalid collation %q: more than one language tag", spec)
+		default:
+			if _, err := language.Parse(opt); err != nil {
+				return filterCollation{}, fmt.Errorf("invalid collation %q: %w", spec, err)
+			}
+			c.tag = opt
+		}
+	}
+	return c, nil
+}
+
+// active сообщает, отличается ли коллация от побайтового сравнения.
+func (c filterCollation) active() bool {
+	return c.fold || c.trim || c.tag != ""
+}
+
+// normalize приводит значение к виду, в котором оно сравнивается.
+//
+// trim отбрасывает только пробелы, а ci — strings.ToLower (Unicode): так же
+// ведут себя TRIM и LOWER PostgreSQL, MS SQL и MySQL. LOWER SQLite меняет
+// регистр только у ASCII, поэтому там ci с не-ASCII значением остаётся в
+// памяти (SQLGenerator.SetASCIILower).
+func (c filterCollation) normalize(s string) string {
+	if c.trim {
+		s = strings.Trim(s, " ")
+	}
+	if c.fold {
+		s = strings.ToLower(s)
+	}
+	return s
+}
+
+// ValidateFilterCollation проверяет коллацию условия фильтра
+// (см. packet.Filter.Collation).
+func ValidateFilterCollation(spec string) error {
+	_, err := parseFilterCollation(spec)
+	return err
+}
+
+// validateFilterCollation проверяет Filter.Collation: допустимая коллация,
+// и не побайтовая — только для текстовых значений.
+func validateFilterCollation(f packet.Filter, field *packet.Field) error {
+	c, err := parseFilterCollation(f.Collation)
+	if err != nil {
+		return err
+	}
+	if c.active() && filterValueType(f, field) != schema.TypeText {
+		return fmt.Errorf("collation %q applies to text values only (field type %s)", f.Collation, field.Type)
+	}
+	return nil
+}
@@ -11,6 +11,8 @@ import (
 
 var likeRegexpCache sync.Map // pattern string → *regexp.Regexp
 
+var regexpCache sync.Map // шаблон оператора regex → *regexp.Regexp
+
 // inListCache caches the parsed elements of an IN/NOT IN list keyed by the
 // field discriminants + raw list string. The list is identical for every row
 // of a query, so parsing it once instead of once-per-row eliminates the bulk
@@ -256,3 +258,26 @@ func (c *Comparator) Like(rowValue, pattern string) (bool, error) {
 	}
 	return re.MatchString(rowValue), nil
 }
+
+// Regex проверяет соответствие регулярному выражению RE2 (без якорей:
+// совпадение в любом месте строки, как у ~ в PostgreSQL).
+func (c *Comparator) Regex(rowValue, pattern string) (bool, error) {
+	re, err := compileRegex(pattern)
+	if err != nil {
+		return false, err
+	}
+	return re.MatchString(rowValue), nil
+}
+
+// compileRegex компилирует шаблон оператора regex с кэшированием.
+func compileRegex(pattern string) (*regexp.Regexp, error) {
+	if v, ok := regexpCache.Load(pattern); ok {
+		return v.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	regexpCache.Store(pattern, re)
+	return re, nil
+}
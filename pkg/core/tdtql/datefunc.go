@@ -0,0 +1,158 @@
+package tdtql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/schema"
+)
+
+// timeNow — текущее время для оператора last (подменяется в тестах).
+var timeNow = time.Now
+
+// Единицы усечения дат (packet.Filter.Trunc).
+const (
+	TruncDay     = "day"
+	TruncWeek    = "week" // неделя начинается с понедельника
+	TruncMonth   = "month"
+	TruncQuarter = "quarter"
+	TruncYear    = "year"
+)
+
+// truncOperators — операторы, к которым применяется Filter.Trunc.
+var truncOperators = map[string]bool{
+	"eq": true, "ne": true, "gt": true, "gte": true, "lt": true, "lte": true,
+	"between": true, "in": true, "not_in": true,
+}
+
+// ValidateTrunc проверяет единицу усечения Filter.Trunc (пусто — без усечения).
+func ValidateTrunc(unit string) error {
+	switch normalizeTrunc(unit) {
+	case "", TruncDay, TruncWeek, TruncMonth, TruncQuarter, TruncYear:
+		return nil
+	default:
+		return fmt.Errorf("unsupported date_trunc unit %q (expected day, week, month, quarter or year)", unit)
+	}
+}
+
+func normalizeTrunc(unit string) string {
+	return strings.ToLower(strings.TrimSpace(unit))
+}
+
+// truncTime возвращает начало периода unit, содержащего t, в часовом поясе t.
+func truncTime(t time.Time, unit string) time.Time {
+	y, m, d := t.Date()
+	switch normalizeTrunc(unit) {
+	case TruncWeek:
+		shift := (int(t.Weekday()) + 6) % 7 // дней с понедельника
+		return time.Date(y, m, d-shift, 0, 0, 0, 0, t.Location())
+	case TruncMonth:
+		return time.Date(y, m, 1, 0, 0, 0, 0, t.Location())
+	case TruncQuarter:
+		return time.Date(y, m-(m-1)%3, 1, 0, 0, 0, 0, t.Location())
+	case TruncYear:
+		return time.Date(y, time.January, 1, 0, 0, 0, 0, t.Location())
+	default:
+		return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+	}
+}
+
+// nextPeriod возвращает начало периода unit, следующего за периодом t.
+func nextPeriod(t time.Time, unit string) time.Time {
+	start := truncTime(t, unit)
+	switch normalizeTrunc(unit) {
+	case TruncWeek:
+		return start.AddDate(0, 0, 7)
+	case TruncMonth:
+		return start.AddDate(0, 1, 0)
+	case TruncQuarter:
+		return start.AddDate(0, 3, 0)
+	case TruncYear:
+		return start.AddDate(1, 0, 0)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
+
+// dateValueFormats — форматы значений условий над датами: форматы
+// DATETIME/DATE конвертера и неполные даты для усечения ('2026-10', '2026').
+var dateValueFormats = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	"2006-01",
+	"2006",
+}
+
+// parseDateValue разбирает значение условия над датой.
+func parseDateValue(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range dateValueFormats {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date value %q (expected YYYY-MM-DD or RFC3339)", s)
+}
+
+// lastPeriod — значение оператора last: N единиц ("7 days", "1 month").
+type lastPeriod struct {
+	n    int
+	unit string // hour, day, week, month, year
+}
+
+// parseLastPeriod разбирает значение оператора last: "7 days", "24 hours",
+// "1 month" (единицы в единственном или множественном числе).
+func parseLastPeriod(value string) (lastPeriod, error) {
+	parts := strings.Fields(strings.ToLower(value))
+	if len(parts) != 2 {
+		return lastPeriod{}, fmt.Errorf("invalid period %q for last (expected e.g. '7 days')", value)
+	}
+	n, err := strconv.Atoi(parts[0])
+	if err != nil || n <= 0 {
+		return lastPeriod{}, fmt.Errorf("invalid period %q for last: count must be a positive integer", value)
+	}
+	unit := strings.TrimSuffix(parts[1], "s")
+	switch unit {
+	case "hour", "day", "week", "month", "year":
+		return lastPeriod{n: n, unit: unit}, nil
+	default:
+		return lastPeriod{}, fmt.Errorf("invalid period %q for last (units: hours, days, weeks, months, years)", value)
+	}
+}
+
+// window возвращает интервал [from, to) оператора last в момент now (UTC).
+// Часы — скользящее окно [now-N, now); дни и крупнее — календарное:
+// N единиц, заканчивающихся сегодняшним днём включительно, так что
+// "7 days" 16 октября — даты с 10 по 16 октября, "1 month" — с 17 сентября.
+// rolling сообщает, что границы — не полночь (SQL-литералы с временем).
+func (p lastPeriod) window(now time.Time) (from, to time.Time, rolling bool) {
+	now = now.UTC()
+	if p.unit == "hour" {
+		return now.Add(-time.Duration(p.n) * time.Hour), now, true
+	}
+	to = truncTime(now, TruncDay).AddDate(0, 0, 1)
+	switch p.unit {
+	case "week":
+		from = to.AddDate(0, 0, -7*p.n)
+	case "month":
+		from = to.AddDate(0, -p.n, 0)
+	case "year":
+		from = to.AddDate(-p.n, 0, 0)
+	default:
+		from = to.AddDate(0, 0, -p.n)
+	}
+	return from, to, false
+}
+
+// isDateType сообщает, что тип значения — дата или время.
+func isDateType(t schema.DataType) bool {
+	switch schema.NormalizeType(t) {
+	case schema.TypeDate, schema.TypeDatetime, schema.TypeTimestamp:
+		return true
+	}
+	return false
+}
@@ -1,15 +1,22 @@
 package tdtql
 
 import (
+	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
 	"github.com/ruslano69/tdtp-framework/pkg/core/schema"
+	"github.com/ruslano69/tdtp-framework/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // ExecutionResult результат выполнения запроса
 type ExecutionResult struct {
-	FilteredRows  [][]string           // отфильтрованные и отсортированные строки
+	FilteredRows  [][]string           // отфильтрованные и отсортированные строки (все поля схемы)
+	Rows          [][]string           // FilteredRows с проекцией Query.Fields
+	Schema        packet.Schema        // схема Rows: поля Query.Fields в их порядке
 	TotalRows     int                  // всего строк в исходных данных
 	MatchedRows   int                  // строк после фильтрации (до LIMIT)
 	ReturnedRows  int                  // строк возвращено (после LIMIT/OFFSET)
@@ -17,6 +24,7 @@ type ExecutionResult struct {
 	NextOffset    int                  // следующий offset для пагинации
 	FilterStats   map[string]int       // статистика по фильтрам
 	QueryContext  *packet.QueryContext // контекст для Response
+	Stages        []packet.PlanStage   // стадии выполнения: оценка, факт и время (см. Explain)
 }
 
 // Executor выполняет TDTQL запросы на данных
@@ -44,14 +52,21 @@ func (e *Executor) Execute(query *packet.Query, rows [][]string, schemaObj packe
 			TotalRows:    len(rows),
 			MatchedRows:  len(rows),
 			FilteredRows: rows,
+			Rows:         rows,
+			Schema:       schemaObj,
 			ReturnedRows: len(rows),
 			FilterStats:  make(map[string]int),
 		}, nil
 	}
 
+	if query.Join != nil {
+		return nil, fmt.Errorf("query joins table %s: use ExecuteJoin", query.Join.Table)
+	}
+
 	result := &ExecutionResult{
 		TotalRows:   len(rows),
 		FilterStats: make(map[string]int),
+		Stages:      PlanStages(query, int64(len(rows))),
 	}
 
 	// Валидация схемы и полей запроса (фильтры, ORDER BY)
@@ -59,27 +74,35 @@ func (e *Executor) Execute(query *packet.Query, rows [][]string, schemaObj packe
 		return nil, err
 	}
 
+	// Числовые сравнения над TEXT-полями — в числах, а не в строках
+	filters, _ := e.CoerceTextFilters(query.Filters, schemaObj)
+
 	// 1. Фильтрация
 	filteredRows := rows
-	if query.Filters != nil {
+	if filters != nil {
+		start := time.Now()
 		var err error
-		filteredRows, result.FilterStats, err = e.filter.ApplyFilters(query.Filters, rows, schemaObj, e.converter)
+		filteredRows, result.FilterStats, err = e.filter.ApplyFilters(filters, rows, schemaObj, e.converter)
 		if err != nil {
 			return nil, fmt.Errorf("filter error: %w", err)
 		}
+		planStage(result.Stages, packet.StageFilter).Done(len(filteredRows), start)
 	}
 	result.MatchedRows = len(filteredRows)
 
 	// 2. Сортировка
 	if query.OrderBy != nil {
+		start := time.Now()
 		var err error
 		filteredRows, err = e.sorter.Sort(filteredRows, query.OrderBy, schemaObj, e.converter)
 		if err != nil {
 			return nil, fmt.Errorf("sort error: %w", err)
 		}
+		planStage(result.Stages, packet.StageSort).Done(len(filteredRows), start)
 	}
 
 	// 3. Пагинация (OFFSET, LIMIT)
+	paginateStart := time.Now()
 	offset := query.Offset
 	limit := query.Limit
 
@@ -117,19 +140,71 @@ func (e *Executor) Execute(query *packet.Query, rows [][]string, schemaObj packe
 
 	result.FilteredRows = filteredRows
 	result.ReturnedRows = len(filteredRows)
+	if stage := planStage(result.Stages, packet.StagePaginate); stage != nil {
+		stage.Done(len(filteredRows), paginateStart)
+	}
+
+	// 4. Проекция — после фильтрации и сортировки: WHERE и ORDER BY могут
+	// ссылаться на поля, которых нет в SELECT
+	projectStart := time.Now()
+	result.Schema, result.Rows = e.Project(query.Fields, filteredRows, schemaObj)
+	if stage := planStage(result.Stages, packet.StageProject); stage != nil {
+		stage.Done(len(result.Rows), projectStart)
+	}
 
-	// 4. Создаем QueryContext для stateless
+	// 5. Создаем QueryContext для stateless
 	result.QueryContext = e.buildQueryContext(query, result)
 
 	return result, nil
 }
 
+// Project возвращает схему из полей fields (в их порядке) и строки только
+// с этими полями; пустой fields — схема и строки без изменений. Поля должны
+// быть проверены ValidateQuery.
+func (e *Executor) Project(fields []string, rows [][]string, schemaObj packet.Schema) (packet.Schema, [][]string) {
+	if len(fields) == 0 {
+		return schemaObj, rows
+	}
+	projected := packet.Schema{Fields: make([]packet.Field, 0, len(fields))}
+	indices := make([]int, 0, len(fields))
+	for _, name := range fields {
+		if i := fieldIndex(schemaObj, name); i >= 0 {
+			projected.Fields = append(projected.Fields, schemaObj.Fields[i])
+			indices = append(indices, i)
+		}
+	}
+	out := make([][]string, len(rows))
+	for r, row := range rows {
+		values := make([]string, len(indices))
+		for j, i := range indices {
+			if i < len(row) {
+				values[j] = row[i]
+			}
+		}
+		out[r] = values
+	}
+	return projected, out
+}
+
+// ExecuteContext — Execute со спаном трассировки tdtql.execute (pkg/tracing):
+// строки на входе, совпавшие и возвращённые.
+func (e *Executor) ExecuteContext(ctx context.Context, query *packet.Query, rows [][]string, schemaObj packet.Schema) (*ExecutionResult, error) {
+	_, span := tracing.Start(ctx, "tdtql.execute", attribute.Int("tdtql.input_rows", len(rows)))
+	result, err := e.Execute(query, rows, schemaObj)
+	if err == nil {
+		span.SetAttributes(attribute.Int("tdtql.matched_rows", result.MatchedRows), attribute.Int("tdtql.returned_rows", result.ReturnedRows))
+	}
+	tracing.End(span, err)
+	return result, err
+}
+
 // ExecuteWhere выполняет только фильтрацию (без сортировки и пагинации)
 func (e *Executor) ExecuteWhere(filters *packet.Filters, rows [][]string, schemaObj packet.Schema) ([][]string, error) {
 	if filters == nil {
 		return rows, nil
 	}
 
+	filters, _ = e.CoerceTextFilters(filters, schemaObj)
 	filteredRows, _, err := e.filter.ApplyFilters(filters, rows, schemaObj, e.converter)
 	return filteredRows, err
 }
@@ -156,6 +231,13 @@ func (e *Executor) ValidateQuery(query *packet.Query, schemaObj packet.Schema) e
 		return err
 	}
 
+	// Проверка полей проекции
+	for _, name := range query.Fields {
+		if _, err := e.validator.GetFieldByName(schemaObj, name); err != nil {
+			return err
+		}
+	}
+
 	// Проверка полей в фильтрах
 	if query.Filters != nil {
 		if err := e.validateFiltersFields(query.Filters, schemaObj); err != nil {
@@ -175,6 +257,9 @@ func (e *Executor) ValidateQuery(query *packet.Query, schemaObj packet.Schema) e
 
 // validateFiltersFields проверяет что все поля из фильтров есть в схеме
 func (e *Executor) validateFiltersFields(filters *packet.Filters, schemaObj packet.Schema) error {
+	if err := ValidateFilterCollation(filters.Collation); err != nil {
+		return fmt.Errorf("filters: %w", err)
+	}
 	if filters.And != nil {
 		if err := e.validateLogicalGroupFields(filters.And, schemaObj); err != nil {
 			return err
@@ -192,9 +277,19 @@ func (e *Executor) validateFiltersFields(filters *packet.Filters, schemaObj pack
 func (e *Executor) validateLogicalGroupFields(group *packet.LogicalGroup, schemaObj packet.Schema) error {
 	// Проверка фильтров
 	for _, filter := range group.Filters {
-		if _, err := e.validator.GetFieldByName(schemaObj, filter.Field); err != nil {
+		field, err := e.validator.GetFieldByName(schemaObj, filter.Field)
+		if err != nil {
 			return fmt.Errorf("field '%s' not found in schema", filter.Field)
 		}
+		if err := ValidateCast(filter.Cast); err != nil {
+			return fmt.Errorf("filter on '%s': %w", filter.Field, err)
+		}
+		if err := validateFilterCollation(filter, field); err != nil {
+			return fmt.Errorf("filter on '%s': %w", filter.Field, err)
+		}
+		if err := validateFilterFunction(filter, field); err != nil {
+			return fmt.Errorf("filter on '%s': %w", filter.Field, err)
+		}
 	}
 
 	// Рекурсивная проверка вложенных групп
@@ -212,6 +307,51 @@ func (e *Executor) validateLogicalGroupFields(group *packet.LogicalGroup, schema
 	return nil
 }
 
+// validateFilterFunction проверяет шаблон regex, период last и усечение
+// дат Filter.Trunc: last и Trunc — только над датами (или Cast к дате).
+func validateFilterFunction(f packet.Filter, field *packet.Field) error {
+	valueType := filterValueType(f, field)
+	switch f.Operator {
+	case "regex", "not_regex":
+		if _, err := compileRegex(f.Value); err != nil {
+			return fmt.Errorf("invalid regex %q: %w", f.Value, err)
+		}
+	case "last":
+		if !isDateType(valueType) {
+			return fmt.Errorf("operator last applies to dates only (field type %s)", field.Type)
+		}
+		if _, err := parseLastPeriod(f.Value); err != nil {
+			return err
+		}
+	}
+
+	if f.Trunc == "" {
+		return nil
+	}
+	if err := ValidateTrunc(f.Trunc); err != nil {
+		return err
+	}
+	if !truncOperators[f.Operator] {
+		return fmt.Errorf("date_trunc is not applicable to operator %s", f.Operator)
+	}
+	if !isDateType(valueType) {
+		return fmt.Errorf("date_trunc applies to dates only (field type %s)", field.Type)
+	}
+	values := []string{f.Value}
+	switch f.Operator {
+	case "between":
+		values = append(values, f.Value2)
+	case "in", "not_in":
+		values = strings.Split(f.Value, ",")
+	}
+	for _, v := range values {
+		if _, err := parseDateValue(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // validateOrderByFields проверяет поля в OrderBy
 func (e *Executor) validateOrderByFields(orderBy *packet.OrderBy, schemaObj packet.Schema) error {
 	if orderBy.Field != "" {
@@ -220,10 +360,17 @@ func (e *Executor) validateOrderByFields(orderBy *packet.OrderBy, schemaObj pack
 		}
 	}
 
+	if err := ValidateCollation(orderBy.Collation); err != nil {
+		return fmt.Errorf("order by: %w", err)
+	}
+
 	for _, field := range orderBy.Fields {
 		if _, err := e.validator.GetFieldByName(schemaObj, field.Name); err != nil {
 			return fmt.Errorf("order by field '%s' not found in schema", field.Name)
 		}
+		if err := ValidateCollation(field.Collation); err != nil {
+			return fmt.Errorf("order by field '%s': %w", field.Name, err)
+		}
 	}
 
 	return nil
@@ -233,6 +380,11 @@ func (e *Executor) validateOrderByFields(orderBy *packet.OrderBy, schemaObj pack
 // Должен вызываться после ValidateQuery - гарантирует что все поля существуют.
 // Это важно для PostgreSQL где кавычки в CREATE TABLE сохраняют регистр.
 func (e *Executor) NormalizeQueryFields(query *packet.Query, schemaObj packet.Schema) {
+	for i, name := range query.Fields {
+		if field, err := e.validator.GetFieldByName(schemaObj, name); err == nil {
+			query.Fields[i] = field.Name
+		}
+	}
 	if query.Filters != nil {
 		e.normalizeLogicalGroup(query.Filters.And, schemaObj)
 		e.normalizeLogicalGroup(query.Filters.Or, schemaObj)
@@ -1,6 +1,7 @@
 package tdtql
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
@@ -493,3 +494,297 @@ func TestExecutorQueryContext(t *testing.T) {
 		t.Error("MoreDataAvailable should be true")
 	}
 }
+
+func TestExecutorTextNumericCoercion(t *testing.T) {
+	executor := NewExecutor()
+
+	// Workspace-таблица: все колонки TEXT
+	schemaObj := schema.NewBuilder().
+		AddText("Name", 100).
+		AddText("Balance", 50).
+		Build()
+
+	rows := [][]string{
+		{"Alice", "900"},
+		{"Bob", "1500"},
+		{"Charlie", "12000.50"},
+		{"David", "n/a"},
+		{"Eve", ""},
+	}
+
+	query := packet.NewQuery()
+	query.Filters = &packet.Filters{
+		And: &packet.LogicalGroup{
+			Filters: []packet.Filter{
+				{Field: "Balance", Operator: "gt", Value: "1000"},
+			},
+		},
+	}
+
+	result, err := executor.Execute(query, rows, schemaObj)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	// Лексикографически "900" > "1000" и "12000.50" < "1500" — с приведением только Bob и Charlie
+	if result.MatchedRows != 2 {
+		t.Fatalf("Expected MatchedRows=2, got %d: %v", result.MatchedRows, result.FilteredRows)
+	}
+	if result.FilteredRows[0][0] != "Bob" || result.FilteredRows[1][0] != "Charlie" {
+		t.Errorf("Expected Bob, Charlie; got %v", result.FilteredRows)
+	}
+	coerced, implicit := executor.CoerceQuery(query, schemaObj)
+	if got := coerced.Filters.And.Filters[0].Cast; got != "REAL" || !implicit {
+		t.Errorf("Expected auto Cast=REAL, got %q (implicit=%v)", got, implicit)
+	}
+	if got := query.Filters.And.Filters[0].Cast; got != "" {
+		t.Errorf("caller's query must not be mutated, got Cast=%q", got)
+	}
+}
+
+func TestExecutorTextNumericCoercion_NotForEquality(t *testing.T) {
+	executor := NewExecutor()
+
+	schemaObj := schema.NewBuilder().
+		AddText("Code", 10).
+		Build()
+
+	rows := [][]string{{"007"}, {"7"}}
+
+	query := packet.NewQuery()
+	query.Filters = &packet.Filters{
+		And: &packet.LogicalGroup{
+			Filters: []packet.Filter{
+				{Field: "Code", Operator: "eq", Value: "007"},
+			},
+		},
+	}
+
+	result, err := executor.Execute(query, rows, schemaObj)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	// Равенство над TEXT остаётся строковым: "7" != "007"
+	if result.MatchedRows != 1 {
+		t.Errorf("Expected MatchedRows=1, got %d", result.MatchedRows)
+	}
+}
+
+func TestExecutorExplicitCast(t *testing.T) {
+	executor := NewExecutor()
+
+	schemaObj := schema.NewBuilder().
+		AddText("Name", 100).
+		AddText("Hired", 20).
+		Build()
+
+	rows := [][]string{
+		{"Alice", "2023-05-01"},
+		{"Bob", "2024-11-15"},
+		{"Charlie", "unknown"},
+	}
+
+	query := packet.NewQuery()
+	query.Filters = &packet.Filters{
+		And: &packet.LogicalGroup{
+			Filters: []packet.Filter{
+				{Field: "Hired", Operator: "gte", Value: "2024-01-01", Cast: "DATE"},
+			},
+		},
+	}
+
+	result, err := executor.Execute(query, rows, schemaObj)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if result.MatchedRows != 1 || result.FilteredRows[0][0] != "Bob" {
+		t.Errorf("Expected only Bob, got %v", result.FilteredRows)
+	}
+
+	// Неприводимое значение фильтра — ошибка, а не пустой результат
+	query.Filters.And.Filters[0].Value = "yesterday"
+	if _, err := executor.Execute(query, rows, schemaObj); err == nil {
+		t.Error("Expected error for filter value not matching cast type")
+	}
+
+	// Неизвестный тип приведения отклоняется на валидации
+	query.Filters.And.Filters[0].Cast = "GEOMETRY"
+	if _, err := executor.Execute(query, rows, schemaObj); err == nil {
+		t.Error("Expected validation error for unsupported cast type")
+	}
+}
+
+func TestExecutorFieldsProjection(t *testing.T) {
+	executor := NewExecutor()
+
+	schemaObj := schema.NewBuilder().
+		AddInteger("ID", true).
+		AddText("Name", 100).
+		AddInteger("Age", false).
+		Build()
+
+	rows := [][]string{
+		{"1", "Alice", "25"},
+		{"2", "Bob", "30"},
+		{"3", "Charlie", "35"},
+	}
+
+	// Фильтр и сортировка по полю, которого нет в проекции
+	query := packet.NewQuery()
+	query.Fields = []string{"name", "ID"}
+	query.Filters = &packet.Filters{
+		And: &packet.LogicalGroup{
+			Filters: []packet.Filter{{Field: "Age", Operator: "gte", Value: "30"}},
+		},
+	}
+	query.OrderBy = &packet.OrderBy{Field: "Age", Direction: "DESC"}
+
+	result, err := executor.Execute(query, rows, schemaObj)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if len(result.Schema.Fields) != 2 || result.Schema.Fields[0].Name != "Name" || !result.Schema.Fields[1].Key {
+		t.Errorf("Schema = %+v, want Name, ID", result.Schema.Fields)
+	}
+	want := [][]string{{"Charlie", "3"}, {"Bob", "2"}}
+	if len(result.Rows) != 2 || result.Rows[0][0] != want[0][0] || result.Rows[0][1] != want[0][1] || result.Rows[1][0] != want[1][0] {
+		t.Errorf("Rows = %v, want %v", result.Rows, want)
+	}
+	if len(result.FilteredRows[0]) != 3 {
+		t.Errorf("FilteredRows must keep all fields, got %v", result.FilteredRows[0])
+	}
+
+	query.Fields = []string{"Email"}
+	if _, err := executor.Execute(query, rows, schemaObj); err == nil {
+		t.Error("Expected error for unknown projected field")
+	}
+}
+
+func TestExecutorFilterCollation(t *testing.T) {
+	executor := NewExecutor()
+
+	schemaObj := schema.NewBuilder().
+		AddInteger("ID", true).
+		AddText("Email", 100).
+		AddText("City", 50).
+		Build()
+
+	rows := [][]string{
+		{"1", "Ann@Mail.ru", "Москва"},
+		{"2", " ann@mail.ru ", "МОСКВА"},
+		{"3", "bob@mail.ru", "Казань"},
+		{"4", "\x00", "москва"},
+	}
+
+	run := func(filters *packet.Filters) []string {
+		t.Helper()
+		query := packet.NewQuery()
+		query.Filters = filters
+		result, err := executor.Execute(query, rows, schemaObj)
+		if err != nil {
+			t.Fatalf("Execute failed: %v", err)
+		}
+		ids := make([]string, len(result.FilteredRows))
+		for i, row := range result.FilteredRows {
+			ids[i] = row[0]
+		}
+		return ids
+	}
+	and := func(filters ...packet.Filter) *packet.Filters {
+		return &packet.Filters{And: &packet.LogicalGroup{Filters: filters}}
+	}
+
+	if got := run(and(packet.Filter{Field: "Email", Operator: "eq", Value: "ann@mail.ru"})); len(got) != 0 {
+		t.Errorf("byte-wise eq: expected no rows, got %v", got)
+	}
+	if got := run(and(packet.Filter{Field: "Email", Operator: "eq", Value: "ANN@mail.ru", Collation: "ci"})); fmt.Sprint(got) != "[1]" {
+		t.Errorf("ci eq: expected [1], got %v", got)
+	}
+	if got := run(and(packet.Filter{Field: "Email", Operator: "eq", Value: "ANN@mail.ru", Collation: "ci,trim"})); fmt.Sprint(got) != "[1 2]" {
+		t.Errorf("ci,trim eq: expected [1 2], got %v", got)
+	}
+	if got := run(and(packet.Filter{Field: "City", Operator: "in", Value: "москва,Казань", Collation: "ci"})); fmt.Sprint(got) != "[1 2 3 4]" {
+		t.Errorf("ci in: expected all rows, got %v", got)
+	}
+	if got := run(and(packet.Filter{Field: "Email", Operator: "like", Value: "ANN%", Collation: "ci"})); fmt.Sprint(got) != "[1]" {
+		t.Errorf("ci like: expected [1], got %v", got)
+	}
+	// NULL не удовлетворяет и отрицанию
+	if got := run(and(packet.Filter{Field: "Email", Operator: "ne", Value: "bob@mail.ru", Collation: "ci"})); fmt.Sprint(got) != "[1 2]" {
+		t.Errorf("ci ne: expected [1 2], got %v", got)
+	}
+	// Язык: сравнение по правилам collator'а
+	if got := run(and(packet.Filter{Field: "City", Operator: "lt", Value: "Л", Collation: "ru,ci"})); fmt.Sprint(got) != "[3]" {
+		t.Errorf("ru lt: expected [3], got %v", got)
+	}
+
+	// Коллация запроса — для текстовых условий без своей, "binary" её отменяет
+	filters := and(
+		packet.Filter{Field: "ID", Operator: "lt", Value: "4"},
+		packet.Filter{Field: "City", Operator: "eq", Value: "москва"},
+	)
+	filters.Collation = "ci"
+	if got := run(filters); fmt.Sprint(got) != "[1 2]" {
+		t.Errorf("query collation: expected [1 2], got %v", got)
+	}
+	coerced, _ := executor.CoerceTextFilters(filters, schemaObj)
+	if coerced.And.Filters[0].Collation != "" || coerced.And.Filters[1].Collation != "ci" {
+		t.Errorf("query collation must reach text filters only: %+v", coerced.And.Filters)
+	}
+	filters.And.Filters[1].Collation = "binary"
+	if got := run(filters); len(got) != 0 {
+		t.Errorf("binary override: expected no rows, got %v", got)
+	}
+
+	// Коллация над нетекстовым полем отклоняется на валидации
+	query := packet.NewQuery()
+	query.Filters = and(packet.Filter{Field: "ID", Operator: "eq", Value: "1", Collation: "ci"})
+	if _, err := executor.Execute(query, rows, schemaObj); err == nil {
+		t.Error("Expected validation error for collation on INTEGER field")
+	}
+}
+
+func TestExecutorFunctionValidation(t *testing.T) {
+	executor := NewExecutor()
+
+	schemaObj := schema.NewBuilder().
+		AddText("Name", 100).
+		AddDate("Hired").
+		Build()
+
+	rows := [][]string{
+		{"Alice", "2026-10-01"},
+		{"Bob", "2026-09-30"},
+	}
+
+	query := packet.NewQuery()
+	query.Filters = &packet.Filters{
+		And: &packet.LogicalGroup{
+			Filters: []packet.Filter{
+				{Field: "Hired", Operator: "eq", Value: "2026-10", Trunc: "month"},
+			},
+		},
+	}
+	result, err := executor.Execute(query, rows, schemaObj)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if result.MatchedRows != 1 || result.FilteredRows[0][0] != "Alice" {
+		t.Errorf("Expected only Alice, got %v", result.FilteredRows)
+	}
+
+	for _, f := range []packet.Filter{
+		{Field: "Name", Operator: "regex", Value: "a(b"},
+		{Field: "Name", Operator: "last", Value: "7 days"},
+		{Field: "Hired", Operator: "last", Value: "7 fortnights"},
+		{Field: "Name", Operator: "eq", Value: "2026-10-01", Trunc: "month"},
+		{Field: "Hired", Operator: "eq", Value: "2026-10-01", Trunc: "decade"},
+		{Field: "Hired", Operator: "like", Value: "2026%", Trunc: "month"},
+		{Field: "Hired", Operator: "eq", Value: "October", Trunc: "month"},
+	} {
+		query.Filters.And.Filters[0] = f
+		if _, err := executor.Execute(query, rows, schemaObj); err == nil {
+			t.Errorf("Expected validation error for %+v", f)
+		}
+	}
+}
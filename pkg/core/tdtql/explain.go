@@ -0,0 +1,142 @@
+package tdtql
+
+import (
+	"fmt"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+// Explain возвращает план выполнения запроса в памяти без чтения строк:
+// фильтры, стадии Execute и их оценки по rowCount строк на входе
+// (-1 — число строк неизвестно). Запрос проверяется по схеме, как в
+// Execute. Адаптеры с SQL-источником строят план через
+// base.ExportHelper.Explain — там видно, что уйдёт в SQL.
+func (e *Executor) Explain(query *packet.Query, schemaObj packet.Schema, rowCount int64) (*packet.ExecutionPlan, error) {
+	plan := &packet.ExecutionPlan{
+		Strategy:         packet.PlanMaterialize,
+		EstimatedRows:    rowCount,
+		Selectivity:      1,
+		EstimatedMatches: rowCount,
+		Reason:           "in-memory execution",
+	}
+	if query == nil {
+		return plan, nil
+	}
+	if query.Join != nil {
+		return nil, fmt.Errorf("query joins table %s: explain it with the export helper", query.Join.Table)
+	}
+	if err := e.ValidateQuery(query, schemaObj); err != nil {
+		return nil, err
+	}
+	plan.Selectivity = EstimateSelectivity(query.Filters)
+	if rowCount >= 0 {
+		plan.EstimatedMatches = int64(float64(rowCount) * plan.Selectivity)
+	}
+	plan.Filters = PlanFilters(query.Filters, packet.ExecMemory)
+	plan.Stages = PlanStages(query, rowCount)
+	return plan, nil
+}
+
+// PlanFilters перечисляет фильтры запроса (вложенные группы — в порядке
+// обхода) с местом выполнения where и оценкой селективности каждого.
+func PlanFilters(filters *packet.Filters, where string) []packet.PlanFilter {
+	if filters == nil {
+		return nil
+	}
+	var out []packet.PlanFilter
+	var walk func(group *packet.LogicalGroup)
+	walk = func(group *packet.LogicalGroup) {
+		if group == nil {
+			return
+		}
+		for _, f := range group.Filters {
+			out = append(out, packet.PlanFilter{
+				Field:       f.Field,
+				Operator:    f.Operator,
+				Value:       filterValue(f),
+				Where:       where,
+				Selectivity: filterSelectivity(f),
+			})
+		}
+		for i := range group.And {
+			walk(&group.And[i])
+		}
+		for i := range group.Or {
+			walk(&group.Or[i])
+		}
+	}
+	walk(filters.And)
+	walk(filters.Or)
+	return out
+}
+
+// filterValue — значение фильтра для плана (between — обе границы,
+// с усечением даты — единица: "month:2026-10-01").
+func filterValue(f packet.Filter) string {
+	value := f.Value
+	if f.Operator == "between" {
+		value = f.Value + ".." + f.Value2
+	}
+	if f.Trunc != "" {
+		value = f.Trunc + ":" + value
+	}
+	return value
+}
+
+// PlanStages — стадии Execute над input строками (-1 — неизвестно) с
+// оценками строк на выходе; ActualRows = -1 до выполнения.
+func PlanStages(query *packet.Query, input int64) []packet.PlanStage {
+	if query == nil {
+		return nil
+	}
+	var stages []packet.PlanStage
+	add := func(name string, rows int64) {
+		stages = append(stages, packet.PlanStage{Name: name, Where: packet.ExecMemory, EstimatedRows: rows, ActualRows: -1})
+	}
+	rows := input
+	if query.Filters != nil {
+		if rows >= 0 {
+			rows = int64(float64(rows) * EstimateSelectivity(query.Filters))
+		}
+		add(packet.StageFilter, rows)
+	}
+	if query.OrderBy != nil {
+		add(packet.StageSort, rows)
+	}
+	if query.Offset > 0 || query.Limit != 0 {
+		rows = paginatedRows(rows, query.Offset, query.Limit)
+		add(packet.StagePaginate, rows)
+	}
+	if len(query.Fields) > 0 {
+		add(packet.StageProject, rows)
+	}
+	return stages
+}
+
+// paginatedRows — оценка строк после OFFSET/LIMIT.
+func paginatedRows(rows int64, offset, limit int) int64 {
+	if limit < 0 {
+		limit = -limit
+	}
+	if rows < 0 {
+		if limit > 0 {
+			return int64(limit)
+		}
+		return -1
+	}
+	rows = max(rows-int64(max(offset, 0)), 0)
+	if limit > 0 {
+		rows = min(rows, int64(limit))
+	}
+	return rows
+}
+
+// planStage — стадия name среди stages (nil — стадии нет).
+func planStage(stages []packet.PlanStage, name string) *packet.PlanStage {
+	for i := range stages {
+		if stages[i].Name == name {
+			return &stages[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package tdtql
+
+import (
+	"testing"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+	"github.com/ruslano69/tdtp-framework/pkg/core/schema"
+)
+
+func explainQuery() *packet.Query {
+	query := packet.NewQuery()
+	query.Filters = &packet.Filters{And: &packet.LogicalGroup{
+		Filters: []packet.Filter{{Field: "Age", Operator: "between", Value: "20", Value2: "35"}},
+		Or: []packet.LogicalGroup{{Filters: []packet.Filter{
+			{Field: "Name", Operator: "eq", Value: "Bob"},
+			{Field: "Name", Operator: "eq", Value: "Eve"},
+		}}},
+	}}
+	query.OrderBy = &packet.OrderBy{Field: "Age", Direction: "DESC"}
+	query.Limit = 1
+	return query
+}
+
+func TestExecutorExplain(t *testing.T) {
+	s := schema.NewBuilder().AddInteger("ID", true).AddText("Name", 100).AddInteger("Age", false).Build()
+
+	plan, err := NewExecutor().Explain(explainQuery(), s, 1_000_000)
+	if err != nil {
+		t.Fatalf("Explain: %v", err)
+	}
+	if plan.Strategy != packet.PlanMaterialize || plan.EstimatedRows != 1_000_000 {
+		t.Errorf("plan = %+v", plan)
+	}
+	if len(plan.Filters) != 3 || plan.Filters[0].Value != "20..35" || plan.Filters[2].Field != "Name" {
+		t.Errorf("filters = %+v", plan.Filters)
+	}
+	for _, f := range plan.Filters {
+		if f.Where != packet.ExecMemory {
+			t.Errorf("filter %s evaluated in %s, want memory", f.Field, f.Where)
+		}
+	}
+	var names []string
+	for _, st := range plan.Stages {
+		names = append(names, st.Name)
+		if st.ActualRows != -1 {
+			t.Errorf("stage %s: ActualRows = %d, want -1 before execution", st.Name, st.ActualRows)
+		}
+	}
+	if got := len(names); got != 3 || names[0] != packet.StageFilter || names[2] != packet.StagePaginate {
+		t.Errorf("stages = %v", names)
+	}
+	if plan.Stages[2].EstimatedRows != 1 {
+		t.Errorf("paginate estimate = %d, want 1", plan.Stages[2].EstimatedRows)
+	}
+
+	bad := packet.NewQuery()
+	bad.Fields = []string{"missing"}
+	if _, err := NewExecutor().Explain(bad, s, -1); err == nil {
+		t.Error("Explain must validate the query")
+	}
+}
+
+func TestExecutorStages(t *testing.T) {
+	s := schema.NewBuilder().AddInteger("ID", true).AddText("Name", 100).AddInteger("Age", false).Build()
+	rows := [][]string{{"1", "Alice", "25"}, {"2", "Bob", "30"}, {"3", "Eve", "35"}, {"4", "Dan", "40"}}
+
+	result, err := NewExecutor().Execute(explainQuery(), rows, s)
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	want := map[string]int64{packet.StageFilter: 2, packet.StageSort: 2, packet.StagePaginate: 1}
+	if len(result.Stages) != len(want) {
+		t.Fatalf("stages = %+v", result.Stages)
+	}
+	for _, st := range result.Stages {
+		if st.ActualRows != want[st.Name] || st.ElapsedMs < 0 {
+			t.Errorf("stage %s: actual %d (want %d), %.3f ms", st.Name, st.ActualRows, want[st.Name], st.ElapsedMs)
+		}
+	}
+}
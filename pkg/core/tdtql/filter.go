@@ -3,6 +3,7 @@ package tdtql
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
 	"github.com/ruslano69/tdtp-framework/pkg/core/schema"
@@ -36,12 +37,22 @@ func (f *FilterEngine) ApplyFilters(
 	result := [][]string{}
 
 	// Build name→index and name→FieldDef maps once (O(fields)) instead of per-row linear scan.
-	fieldIdx := make(map[string]int, len(schemaObj.Fields))
-	fieldDefs := make(map[string]schema.FieldDef, len(schemaObj.Fields))
+	scope := &filterScope{
+		converter:  converter,
+		fieldIdx:   make(map[string]int, len(schemaObj.Fields)),
+		fieldDefs:  make(map[string]schema.FieldDef, len(schemaObj.Fields)),
+		collations: make(map[string]filterCollation),
+		collators:  collatorSet{},
+		now:        timeNow(),
+		windows:    make(map[string][2]time.Time),
+	}
+	if filters != nil {
+		scope.defaultCollation = filters.Collation
+	}
 	for i, sf := range schemaObj.Fields {
 		key := strings.ToLower(sf.Name)
-		fieldIdx[key] = i
-		fieldDefs[key] = schema.FieldDef{
+		scope.fieldIdx[key] = i
+		scope.fieldDefs[key] = schema.FieldDef{
 			Name:      sf.Name,
 			Type:      schema.DataType(sf.Type),
 			Length:    sf.Length,
@@ -54,7 +65,7 @@ func (f *FilterEngine) ApplyFilters(
 	}
 
 	for _, row := range rows {
-		match, err := f.evaluateFilters(filters, row, converter, stats, fieldIdx, fieldDefs)
+		match, err := f.evaluateFilters(filters, row, stats, scope)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -67,14 +78,63 @@ func (f *FilterEngine) ApplyFilters(
 	return result, stats, nil
 }
 
+// filterScope — состояние одного вызова ApplyFilters: индексы полей схемы,
+// коллация запроса по умолчанию и разобранные коллации условий. Collator не
+// безопасен для конкурентного использования, поэтому набор — на вызов.
+type filterScope struct {
+	converter        *schema.Converter
+	fieldIdx         map[string]int
+	fieldDefs        map[string]schema.FieldDef
+	defaultCollation string                     // Filters.Collation
+	collations       map[string]filterCollation // разобранные коллации по строке
+	collators        collatorSet
+	now              time.Time               // «сейчас» оператора last — одно на вызов
+	windows          map[string][2]time.Time // интервалы last по значению условия
+}
+
+// collation возвращает коллацию условия над значением типа fieldType:
+// Filter.Collation, иначе коллация запроса. Коллация действует только на
+// текстовые значения.
+func (s *filterScope) collation(filter *packet.Filter, fieldType schema.DataType) (filterCollation, error) {
+	spec := filter.Collation
+	if spec == "" {
+		spec = s.defaultCollation
+	}
+	if spec == "" || schema.NormalizeType(fieldType) != schema.TypeText {
+		return filterCollation{}, nil
+	}
+	if c, ok := s.collations[spec]; ok {
+		return c, nil
+	}
+	c, err := parseFilterCollation(spec)
+	if err != nil {
+		return filterCollation{}, err
+	}
+	s.collations[spec] = c
+	return c, nil
+}
+
+// lastWindow возвращает интервал [from, to) оператора last со значением
+// value относительно момента вызова ApplyFilters.
+func (s *filterScope) lastWindow(value string) (from, to time.Time, err error) {
+	if w, ok := s.windows[value]; ok {
+		return w[0], w[1], nil
+	}
+	period, err := parseLastPeriod(value)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	from, to, _ = period.window(s.now)
+	s.windows[value] = [2]time.Time{from, to}
+	return from, to, nil
+}
+
 // evaluateFilters проверяет соответствие строки фильтрам
 func (f *FilterEngine) evaluateFilters(
 	filters *packet.Filters,
 	row []string,
-	converter *schema.Converter,
 	stats map[string]int,
-	fieldIdx map[string]int,
-	fieldDefs map[string]schema.FieldDef,
+	scope *filterScope,
 ) (bool, error) {
 
 	if filters == nil {
@@ -83,12 +143,12 @@ func (f *FilterEngine) evaluateFilters(
 
 	// Проверяем And группу
 	if filters.And != nil {
-		return f.evaluateLogicalGroup(filters.And, "AND", row, converter, stats, fieldIdx, fieldDefs)
+		return f.evaluateLogicalGroup(filters.And, "AND", row, stats, scope)
 	}
 
 	// Проверяем Or группу
 	if filters.Or != nil {
-		return f.evaluateLogicalGroup(filters.Or, "OR", row, converter, stats, fieldIdx, fieldDefs)
+		return f.evaluateLogicalGroup(filters.Or, "OR", row, stats, scope)
 	}
 
 	return true, nil
@@ -99,10 +159,8 @@ func (f *FilterEngine) evaluateLogicalGroup(
 	group *packet.LogicalGroup,
 	operator string,
 	row []string,
-	converter *schema.Converter,
 	stats map[string]int,
-	fieldIdx map[string]int,
-	fieldDefs map[string]schema.FieldDef,
+	scope *filterScope,
 ) (bool, error) {
 
 	if operator == "AND" {
@@ -110,7 +168,7 @@ func (f *FilterEngine) evaluateLogicalGroup(
 
 		// Проверяем фильтры
 		for _, filter := range group.Filters {
-			match, err := f.evaluateFilter(&filter, row, converter, fieldIdx, fieldDefs)
+			match, err := f.evaluateFilter(&filter, row, scope)
 			if err != nil {
 				return false, err
 			}
@@ -126,7 +184,7 @@ func (f *FilterEngine) evaluateLogicalGroup(
 
 		// Проверяем вложенные And группы
 		for _, andGroup := range group.And {
-			match, err := f.evaluateLogicalGroup(&andGroup, "AND", row, converter, stats, fieldIdx, fieldDefs)
+			match, err := f.evaluateLogicalGroup(&andGroup, "AND", row, stats, scope)
 			if err != nil {
 				return false, err
 			}
@@ -137,7 +195,7 @@ func (f *FilterEngine) evaluateLogicalGroup(
 
 		// Проверяем вложенные Or группы
 		for _, orGroup := range group.Or {
-			match, err := f.evaluateLogicalGroup(&orGroup, "OR", row, converter, stats, fieldIdx, fieldDefs)
+			match, err := f.evaluateLogicalGroup(&orGroup, "OR", row, stats, scope)
 			if err != nil {
 				return false, err
 			}
@@ -153,7 +211,7 @@ func (f *FilterEngine) evaluateLogicalGroup(
 
 		// Проверяем фильтры
 		for _, filter := range group.Filters {
-			match, err := f.evaluateFilter(&filter, row, converter, fieldIdx, fieldDefs)
+			match, err := f.evaluateFilter(&filter, row, scope)
 			if err != nil {
 				return false, err
 			}
@@ -166,7 +224,7 @@ func (f *FilterEngine) evaluateLogicalGroup(
 
 		// Проверяем вложенные And группы
 		for _, andGroup := range group.And {
-			match, err := f.evaluateLogicalGroup(&andGroup, "AND", row, converter, stats, fieldIdx, fieldDefs)
+			match, err := f.evaluateLogicalGroup(&andGroup, "AND", row, stats, scope)
 			if err != nil {
 				return false, err
 			}
@@ -177,7 +235,7 @@ func (f *FilterEngine) evaluateLogicalGroup(
 
 		// Проверяем вложенные Or группы
 		for _, orGroup := range group.Or {
-			match, err := f.evaluateLogicalGroup(&orGroup, "OR", row, converter, stats, fieldIdx, fieldDefs)
+			match, err := f.evaluateLogicalGroup(&orGroup, "OR", row, stats, scope)
 			if err != nil {
 				return false, err
 			}
@@ -194,13 +252,12 @@ func (f *FilterEngine) evaluateLogicalGroup(
 func (f *FilterEngine) evaluateFilter(
 	filter *packet.Filter,
 	row []string,
-	converter *schema.Converter,
-	fieldIdx map[string]int,
-	fieldDefs map[string]schema.FieldDef,
+	scope *filterScope,
 ) (bool, error) {
 
+	converter := scope.converter
 	key := strings.ToLower(filter.Field)
-	fieldIndex, ok := fieldIdx[key]
+	fieldIndex, ok := scope.fieldIdx[key]
 	if !ok {
 		return false, fmt.Errorf("field '%s' not found in schema", filter.Field)
 	}
@@ -210,7 +267,37 @@ func (f *FilterEngine) evaluateFilter(
 	}
 
 	rowValue := row[fieldIndex]
-	fieldDef := fieldDefs[key]
+	fieldDef := scope.fieldDefs[key]
+
+	// Явное приведение типа: сравниваем в типе Cast, а не в типе поля.
+	// Строка, значение которой не приводится (или NULL), условию не удовлетворяет.
+	if filter.Cast != "" && filter.Operator != "is_null" && filter.Operator != "is_not_null" {
+		fieldDef = castFieldDef(fieldDef, filter.Cast)
+		if rowValue == nullSentinel {
+			return false, nil
+		}
+		tv, err := converter.ParseValue(rowValue, fieldDef)
+		if err != nil || tv.IsNull {
+			return false, nil
+		}
+	}
+
+	if filter.Trunc != "" {
+		return f.evaluateTrunc(filter, rowValue, fieldDef, converter)
+	}
+
+	// Сравнение строк по коллации (ci, trim, язык); регистр в regex
+	// задаётся флагом шаблона (?i)
+	if filter.Operator != "is_null" && filter.Operator != "is_not_null" &&
+		filter.Operator != "regex" && filter.Operator != "not_regex" {
+		collation, err := scope.collation(filter, fieldDef.Type)
+		if err != nil {
+			return false, fmt.Errorf("filter on '%s': %w", filter.Field, err)
+		}
+		if collation.active() {
+			return f.evaluateCollated(filter, rowValue, collation, scope)
+		}
+	}
 
 	// Применяем оператор
 	switch filter.Operator {
@@ -239,6 +326,26 @@ func (f *FilterEngine) evaluateFilter(
 	case "not_like":
 		result, err := f.comparator.Like(rowValue, filter.Value)
 		return !result, err
+	case "regex", "not_regex":
+		if rowValue == nullSentinel {
+			return false, nil
+		}
+		result, err := f.comparator.Regex(rowValue, filter.Value)
+		return result == (filter.Operator == "regex"), err
+	case "starts_with":
+		return rowValue != nullSentinel && strings.HasPrefix(rowValue, filter.Value), nil
+	case "ends_with":
+		return rowValue != nullSentinel && strings.HasSuffix(rowValue, filter.Value), nil
+	case "last":
+		t, ok := rowTime(rowValue, fieldDef, converter)
+		if !ok {
+			return false, nil
+		}
+		from, to, err := scope.lastWindow(filter.Value)
+		if err != nil {
+			return false, fmt.Errorf("filter on '%s': %w", filter.Field, err)
+		}
+		return !t.Before(from) && t.Before(to), nil
 	case "is_null":
 		return rowValue == "" || rowValue == nullSentinel, nil
 	case "is_not_null":
@@ -247,3 +354,141 @@ func (f *FilterEngine) evaluateFilter(
 		return false, fmt.Errorf("unknown operator: %s", filter.Operator)
 	}
 }
+
+// evaluateTrunc проверяет условие с усечением дат (Filter.Trunc): дата
+// строки и значения условия сравниваются по началу периода, так что eq
+// '2026-10-01' с Trunc month — любая дата октября. NULL и значение, не
+// разбираемое как дата, условию не удовлетворяют.
+func (f *FilterEngine) evaluateTrunc(filter *packet.Filter, rowValue string, fieldDef schema.FieldDef, converter *schema.Converter) (bool, error) {
+	t, ok := rowTime(rowValue, fieldDef, converter)
+	if !ok {
+		return false, nil
+	}
+	row := truncTime(t, filter.Trunc)
+	compare := func(value string) (int, error) {
+		v, err := parseDateValue(value)
+		if err != nil {
+			return 0, fmt.Errorf("filter on '%s': %w", filter.Field, err)
+		}
+		return row.Compare(truncTime(v, filter.Trunc)), nil
+	}
+
+	switch filter.Operator {
+	case "in", "not_in":
+		found := false
+		for _, v := range strings.Split(filter.Value, ",") {
+			c, err := compare(v)
+			if err != nil {
+				return false, err
+			}
+			if c == 0 {
+				found = true
+				break
+			}
+		}
+		return found == (filter.Operator == "in"), nil
+	case "between":
+		low, err := compare(filter.Value)
+		if err != nil {
+			return false, err
+		}
+		high, err := compare(filter.Value2)
+		if err != nil {
+			return false, err
+		}
+		return low >= 0 && high <= 0, nil
+	}
+
+	c, err := compare(filter.Value)
+	if err != nil {
+		return false, err
+	}
+	switch filter.Operator {
+	case "eq":
+		return c == 0, nil
+	case "ne":
+		return c != 0, nil
+	case "gt":
+		return c > 0, nil
+	case "gte":
+		return c >= 0, nil
+	case "lt":
+		return c < 0, nil
+	case "lte":
+		return c <= 0, nil
+	default:
+		return false, fmt.Errorf("date_trunc is not applicable to operator %s", filter.Operator)
+	}
+}
+
+// rowTime разбирает значение строки как дату; false — NULL или значение,
+// не разбираемое как дата.
+func rowTime(rowValue string, fieldDef schema.FieldDef, converter *schema.Converter) (time.Time, bool) {
+	if rowValue == "" || rowValue == nullSentinel {
+		return time.Time{}, false
+	}
+	tv, err := converter.ParseValue(rowValue, fieldDef)
+	if err != nil || tv.IsNull || tv.TimeValue == nil {
+		return time.Time{}, false
+	}
+	return *tv.TimeValue, true
+}
+
+// evaluateCollated проверяет условие над текстовым значением по коллации:
+// оба значения нормализуются (trim, ci), а с тегом языка сравниваются
+// collator'ом. LIKE, starts_with и ends_with учитывают только trim и ci. NULL, как и в SQL
+// (LOWER(NULL) = 'x' — не истина), не удовлетворяет ни одному оператору.
+func (f *FilterEngine) evaluateCollated(filter *packet.Filter, rowValue string, collation filterCollation, scope *filterScope) (bool, error) {
+	if rowValue == nullSentinel {
+		return false, nil
+	}
+	collator, err := scope.collators.get(collation.tag)
+	if err != nil {
+		return false, err
+	}
+	row := collation.normalize(rowValue)
+	compare := func(value string) int {
+		value = collation.normalize(value)
+		if collator != nil {
+			return collator.CompareString(row, value)
+		}
+		return strings.Compare(row, value)
+	}
+
+	switch filter.Operator {
+	case "eq":
+		return compare(filter.Value) == 0, nil
+	case "ne":
+		return compare(filter.Value) != 0, nil
+	case "gt":
+		return compare(filter.Value) > 0, nil
+	case "gte":
+		return compare(filter.Value) >= 0, nil
+	case "lt":
+		return compare(filter.Value) < 0, nil
+	case "lte":
+		return compare(filter.Value) <= 0, nil
+	case "between":
+		return compare(filter.Value) >= 0 && compare(filter.Value2) <= 0, nil
+	case "in", "not_in":
+		found := false
+		for _, v := range strings.Split(filter.Value, ",") {
+			if compare(strings.TrimSpace(v)) == 0 {
+				found = true
+				break
+			}
+		}
+		return found == (filter.Operator == "in"), nil
+	case "like":
+		return f.comparator.Like(row, collation.normalize(filter.Value))
+	case "not_like":
+		result, err := f.comparator.Like(row, collation.normalize(filter.Value))
+		return !result, err
+	case "starts_with":
+		return strings.HasPrefix(row, collation.normalize(filter.Value)), nil
+	case "ends_with":
+		return strings.HasSuffix(row, collation.normalize(filter.Value)), nil
+	default:
+		return false, fmt.Errorf("unknown operator: %s", filter.Operator)
+	}
+}
@@ -1,7 +1,9 @@
 package tdtql
 
 import (
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
 	"github.com/ruslano69/tdtp-framework/pkg/core/schema"
@@ -555,3 +557,112 @@ func TestFilterEngine_EmptyRows(t *testing.T) {
 		t.Errorf("expected 0 rows, got %d", len(result))
 	}
 }
+
+func TestFilterEngine_RegexAndAffixes(t *testing.T) {
+	engine := NewFilterEngine()
+	converter := schema.NewConverter()
+	schemaObj := packet.Schema{Fields: []packet.Field{{Name: "code", Type: "TEXT"}}}
+	rows := [][]string{{"A100"}, {"a200"}, {"B300"}, {"A1x"}, {nullSentinel}}
+
+	tests := []struct {
+		filter packet.Filter
+		want   []string
+	}{
+		{packet.Filter{Field: "code", Operator: "regex", Value: "^A[0-9]+$"}, []string{"A100"}},
+		{packet.Filter{Field: "code", Operator: "regex", Value: "(?i)^a[0-9]+$"}, []string{"A100", "a200"}},
+		{packet.Filter{Field: "code", Operator: "not_regex", Value: "^A"}, []string{"a200", "B300"}},
+		{packet.Filter{Field: "code", Operator: "starts_with", Value: "A1"}, []string{"A100", "A1x"}},
+		{packet.Filter{Field: "code", Operator: "starts_with", Value: "a", Collation: "ci"}, []string{"A100", "a200", "A1x"}},
+		{packet.Filter{Field: "code", Operator: "ends_with", Value: "00"}, []string{"A100", "a200", "B300"}},
+	}
+	for _, tt := range tests {
+		filters := &packet.Filters{And: &packet.LogicalGroup{Filters: []packet.Filter{tt.filter}}}
+		result, _, err := engine.ApplyFilters(filters, rows, schemaObj, converter)
+		if err != nil {
+			t.Fatalf("%s %q: %v", tt.filter.Operator, tt.filter.Value, err)
+		}
+		var got []string
+		for _, r := range result {
+			got = append(got, r[0])
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("%s %q: expected %v, got %v", tt.filter.Operator, tt.filter.Value, tt.want, got)
+		}
+	}
+}
+
+func TestLastPeriod_WindowBoundaries(t *testing.T) {
+	now := time.Date(2026, 10, 16, 14, 30, 0, 0, time.UTC)
+	tests := []struct {
+		value    string
+		from, to string
+	}{
+		{"1 day", "2026-10-16", "2026-10-17"},
+		{"7 days", "2026-10-10", "2026-10-17"},
+		{"1 week", "2026-10-10", "2026-10-17"},
+		{"1 month", "2026-09-17", "2026-10-17"},
+		{"1 year", "2025-10-17", "2026-10-17"},
+	}
+	for _, tt := range tests {
+		period, err := parseLastPeriod(tt.value)
+		if err != nil {
+			t.Fatalf("%q: %v", tt.value, err)
+		}
+		from, to, rolling := period.window(now)
+		if rolling {
+			t.Errorf("%q: calendar period reported as rolling", tt.value)
+		}
+		if got := from.Format("2006-01-02"); got != tt.from {
+			t.Errorf("%q: expected window from %s, got %s", tt.value, tt.from, got)
+		}
+		if got := to.Format("2006-01-02"); got != tt.to {
+			t.Errorf("%q: expected window to %s, got %s", tt.value, tt.to, got)
+		}
+		if days := int(to.Sub(from).Hours() / 24); tt.value == "7 days" && days != 7 {
+			t.Errorf("%q: expected 7 calendar days, got %d", tt.value, days)
+		}
+	}
+}
+
+func TestFilterEngine_DateFunctions(t *testing.T) {
+	defer func(now func() time.Time) { timeNow = now }(timeNow)
+	timeNow = func() time.Time { return time.Date(2026, 10, 16, 14, 30, 0, 0, time.UTC) }
+
+	engine := NewFilterEngine()
+	converter := schema.NewConverter()
+	schemaObj := packet.Schema{Fields: []packet.Field{{Name: "d", Type: "TIMESTAMP"}}}
+	rows := [][]string{
+		{"2026-10-16T09:00:00Z"},
+		{"2026-10-10T00:00:00Z"},
+		{"2026-10-09T23:59:59Z"},
+		{"2026-09-30T12:00:00Z"},
+		{"2026-07-01T00:00:00Z"},
+		{""},
+	}
+
+	tests := []struct {
+		filter packet.Filter
+		want   int
+	}{
+		{packet.Filter{Field: "d", Operator: "last", Value: "7 days"}, 2},
+		{packet.Filter{Field: "d", Operator: "last", Value: "6 hours"}, 1},
+		{packet.Filter{Field: "d", Operator: "eq", Value: "2026-10-20", Trunc: "month"}, 3},
+		{packet.Filter{Field: "d", Operator: "ne", Value: "2026-10", Trunc: "month"}, 2},
+		{packet.Filter{Field: "d", Operator: "eq", Value: "2026-10-14", Trunc: "week"}, 1},
+		{packet.Filter{Field: "d", Operator: "gt", Value: "2026-09-15", Trunc: "month"}, 3},
+		{packet.Filter{Field: "d", Operator: "lte", Value: "2026-09-01", Trunc: "month"}, 2},
+		{packet.Filter{Field: "d", Operator: "eq", Value: "2026-08-01", Trunc: "quarter"}, 2},
+		{packet.Filter{Field: "d", Operator: "in", Value: "2026-10-10,2026-07-01", Trunc: "day"}, 2},
+		{packet.Filter{Field: "d", Operator: "between", Value: "2026-09-30", Value2: "2026-10-09", Trunc: "day"}, 2},
+	}
+	for _, tt := range tests {
+		filters := &packet.Filters{And: &packet.LogicalGroup{Filters: []packet.Filter{tt.filter}}}
+		result, _, err := engine.ApplyFilters(filters, rows, schemaObj, converter)
+		if err != nil {
+			t.Fatalf("%+v: %v", tt.filter, err)
+		}
+		if len(result) != tt.want {
+			t.Errorf("%+v: expected %d rows, got %d: %v", tt.filter, tt.want, len(result), result)
+		}
+	}
+}
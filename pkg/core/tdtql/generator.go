@@ -41,9 +41,93 @@ func (g *Generator) Generate(stmt *SelectStatement) (*packet.Query, error) {
 		query.Offset = *stmt.Offset
 	}
 
+	// JOIN и квалифицированные имена полей (c.name → customers.name)
+	if err := g.resolveNames(stmt, query); err != nil {
+		return nil, err
+	}
+
 	return query, nil
 }
 
+// tableNames — квалификаторы полей одной таблицы запроса: псевдоним и имя.
+type tableNames []string
+
+// strip снимает квалификатор таблицы с имени поля; ok == false — поле
+// квалифицировано не этой таблицей (или не квалифицировано).
+func (t tableNames) strip(name string) (string, bool) {
+	for _, q := range t {
+		if q != "" && len(name) > len(q)+1 && name[len(q)] == '.' && strings.EqualFold(name[:len(q)], q) {
+			return name[len(q)+1:], true
+		}
+	}
+	return name, false
+}
+
+// resolveNames строит Query.Join и приводит имена полей (SELECT, WHERE,
+// ORDER BY) к именам строки результата: поля основной таблицы (users.id,
+// u.id) — без префикса, поля присоединённой (orders.total, o.total) —
+// "<table>.<field>". Имя без квалификатора относится к основной таблице.
+func (g *Generator) resolveNames(stmt *SelectStatement, query *packet.Query) error {
+	main := tableNames{stmt.TableAlias, stmt.TableName}
+	var joined tableNames
+	if stmt.Join != nil {
+		joined = tableNames{stmt.Join.Alias, stmt.Join.Table}
+		query.Join = &packet.Join{Table: stmt.Join.Table, Type: strings.ToLower(stmt.Join.Type)}
+		for _, c := range stmt.Join.On {
+			l, lok := main.strip(c.Left)
+			r, rok := joined.strip(c.Right)
+			if !lok || !rok {
+				// ON b.y = a.x
+				l, lok = main.strip(c.Right)
+				r, rok = joined.strip(c.Left)
+			}
+			if !lok || !rok {
+				return fmt.Errorf("ON %s = %s: qualify the fields with names or aliases of both tables", c.Left, c.Right)
+			}
+			query.Join.On = append(query.Join.On, packet.JoinOn{Left: l, Right: r})
+		}
+	}
+
+	resolve := func(name string) string {
+		if f, ok := main.strip(name); ok {
+			return f
+		}
+		if f, ok := joined.strip(name); ok {
+			return query.Join.JoinedField(f)
+		}
+		return name
+	}
+	if query.Filters != nil {
+		resolveGroupNames(query.Filters.And, resolve)
+		resolveGroupNames(query.Filters.Or, resolve)
+	}
+	for _, f := range stmt.Fields {
+		query.Fields = append(query.Fields, resolve(f))
+	}
+	if query.OrderBy != nil {
+		query.OrderBy.Field = resolve(query.OrderBy.Field)
+		for i := range query.OrderBy.Fields {
+			query.OrderBy.Fields[i].Name = resolve(query.OrderBy.Fields[i].Name)
+		}
+	}
+	return nil
+}
+
+func resolveGroupNames(group *packet.LogicalGroup, resolve func(string) string) {
+	if group == nil {
+		return
+	}
+	for i := range group.Filters {
+		group.Filters[i].Field = resolve(group.Filters[i].Field)
+	}
+	for i := range group.And {
+		resolveGroupNames(&group.And[i], resolve)
+	}
+	for i := range group.Or {
+		resolveGroupNames(&group.Or[i], resolve)
+	}
+}
+
 // generateFilters генерирует Filters из Expression
 func (g *Generator) generateFilters(expr Expression) (*packet.Filters, error) {
 	filters := &packet.Filters{}
@@ -174,9 +258,12 @@ func (g *Generator) expressionToFilter(expr Expression) (*packet.Filter, error)
 	switch e := expr.(type) {
 	case *ComparisonExpression:
 		return &packet.Filter{
-			Field:    e.Field,
-			Operator: e.Operator,
-			Value:    fmt.Sprintf("%v", e.Value),
+			Field:     e.Field,
+			Operator:  e.Operator,
+			Value:     fmt.Sprintf("%v", e.Value),
+			Cast:      e.Cast,
+			Collation: e.Collation,
+			Trunc:     e.Trunc,
 		}, nil
 
 	case *InExpression:
@@ -185,9 +272,12 @@ func (g *Generator) expressionToFilter(expr Expression) (*packet.Filter, error)
 			operator = "not_in"
 		}
 		return &packet.Filter{
-			Field:    e.Field,
-			Operator: operator,
-			Value:    strings.Join(e.Values, ","),
+			Field:     e.Field,
+			Operator:  operator,
+			Value:     strings.Join(e.Values, ","),
+			Cast:      e.Cast,
+			Collation: e.Collation,
+			Trunc:     e.Trunc,
 		}, nil
 
 	case *BetweenExpression:
@@ -196,10 +286,13 @@ func (g *Generator) expressionToFilter(expr Expression) (*packet.Filter, error)
 			return nil, fmt.Errorf("NOT BETWEEN not supported yet")
 		}
 		return &packet.Filter{
-			Field:    e.Field,
-			Operator: operator,
-			Value:    e.Low,
-			Value2:   e.High,
+			Field:     e.Field,
+			Operator:  operator,
+			Value:     e.Low,
+			Value2:    e.High,
+			Cast:      e.Cast,
+			Collation: e.Collation,
+			Trunc:     e.Trunc,
 		}, nil
 
 	case *IsNullExpression:
@@ -224,6 +317,7 @@ func (g *Generator) generateOrderBy(clauses []*OrderByClause) *packet.OrderBy {
 		return &packet.OrderBy{
 			Field:     clauses[0].Field,
 			Direction: clauses[0].Direction,
+			Collation: clauses[0].Collation,
 		}
 	}
 
@@ -236,6 +330,7 @@ func (g *Generator) generateOrderBy(clauses []*OrderByClause) *packet.OrderBy {
 		orderBy.Fields[i] = packet.OrderField{
 			Name:      clause.Field,
 			Direction: clause.Direction,
+			Collation: clause.Collation,
 		}
 	}
 
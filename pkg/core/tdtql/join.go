@@ -0,0 +1,155 @@
+package tdtql
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+// joinKeySep разделяет значения составного ключа соединения
+const joinKeySep = "\x1f"
+
+// JoinSchema возвращает схему строки соединения: поля left, затем поля
+// right под именами join.JoinedField. Ключ результата — ключ основной
+// таблицы, поля присоединённой ключевыми не считаются.
+func JoinSchema(left packet.Schema, join *packet.Join, right packet.Schema) packet.Schema {
+	fields := make([]packet.Field, 0, len(left.Fields)+len(right.Fields))
+	fields = append(fields, left.Fields...)
+	for _, f := range right.Fields {
+		f.Name = join.JoinedField(f.Name)
+		f.Key = false
+		fields = append(fields, f)
+	}
+	return packet.Schema{Fields: fields}
+}
+
+// PrepareJoin проверяет Join относительно схем обеих таблиц и приводит тип
+// и имена полей условий к каноническим (как NormalizeQueryFields).
+func (e *Executor) PrepareJoin(join *packet.Join, left, right packet.Schema) error {
+	if join.Table == "" {
+		return fmt.Errorf("join: table is required")
+	}
+	switch t := strings.ToLower(join.Type); t {
+	case "", packet.JoinInner:
+		join.Type = packet.JoinInner
+	case packet.JoinLeft:
+		join.Type = t
+	default:
+		return fmt.Errorf("join %s: unsupported type %q (inner, left)", join.Table, join.Type)
+	}
+	if len(join.On) == 0 {
+		return fmt.Errorf("join %s: at least one On condition is required", join.Table)
+	}
+	for i, on := range join.On {
+		l, err := e.validator.GetFieldByName(left, on.Left)
+		if err != nil {
+			return fmt.Errorf("join %s: field '%s' not found in main table", join.Table, on.Left)
+		}
+		r, err := e.validator.GetFieldByName(right, on.Right)
+		if err != nil {
+			return fmt.Errorf("join %s: field '%s' not found in joined table", join.Table, on.Right)
+		}
+		join.On[i] = packet.JoinOn{Left: l.Name, Right: r.Name}
+	}
+	return nil
+}
+
+// ExecuteJoin выполняет запрос с Join над строками двух таблиц: соединяет
+// их (hash join по условиям On), затем фильтрует, сортирует и ограничивает
+// результат как Execute. Возвращает и схему строк результата (JoinSchema).
+//
+// Значения ключей сравниваются как есть, в каноническом виде TDTP; NULL
+// (пустое значение) не совпадает ни с чем, как в SQL.
+func (e *Executor) ExecuteJoin(
+	query *packet.Query,
+	leftRows [][]string, leftSchema packet.Schema,
+	rightRows [][]string, rightSchema packet.Schema,
+) (*ExecutionResult, packet.Schema, error) {
+	if query == nil || query.Join == nil {
+		return nil, packet.Schema{}, fmt.Errorf("query has no Join")
+	}
+	if err := e.PrepareJoin(query.Join, leftSchema, rightSchema); err != nil {
+		return nil, packet.Schema{}, err
+	}
+
+	joinedSchema := JoinSchema(leftSchema, query.Join, rightSchema)
+	start := time.Now()
+	joined := joinRows(query.Join, leftRows, leftSchema, rightRows, rightSchema)
+	joinStage := packet.PlanStage{Name: packet.StageJoin, Where: packet.ExecMemory, EstimatedRows: -1}
+	joinStage.Done(len(joined), start)
+
+	rest := *query
+	rest.Join = nil
+	result, err := e.Execute(&rest, joined, joinedSchema)
+	if err != nil {
+		return nil, packet.Schema{}, err
+	}
+	result.Stages = append([]packet.PlanStage{joinStage}, result.Stages...)
+	result.QueryContext.OriginalQuery = *query
+	return result, joinedSchema, nil
+}
+
+// joinRows соединяет строки: порядок основной таблицы сохраняется, пары
+// строки идут в порядке присоединённой. Поля On должны быть проверены
+// PrepareJoin.
+func joinRows(join *packet.Join, leftRows [][]string, leftSchema packet.Schema, rightRows [][]string, rightSchema packet.Schema) [][]string {
+	leftIdx := make([]int, len(join.On))
+	rightIdx := make([]int, len(join.On))
+	for i, on := range join.On {
+		leftIdx[i] = fieldIndex(leftSchema, on.Left)
+		rightIdx[i] = fieldIndex(rightSchema, on.Right)
+	}
+
+	index := make(map[string][]int, len(rightRows))
+	for i, row := range rightRows {
+		if key, ok := joinKey(row, rightIdx); ok {
+			index[key] = append(index[key], i)
+		}
+	}
+
+	width := len(leftSchema.Fields) + len(rightSchema.Fields)
+	var out [][]string
+	for _, row := range leftRows {
+		var matches []int
+		if key, ok := joinKey(row, leftIdx); ok {
+			matches = index[key]
+		}
+		if len(matches) == 0 && join.Type == packet.JoinLeft {
+			joined := make([]string, width)
+			copy(joined, row)
+			out = append(out, joined)
+			continue
+		}
+		for _, m := range matches {
+			joined := make([]string, 0, width)
+			joined = append(joined, row...)
+			joined = append(joined, rightRows[m]...)
+			out = append(out, joined)
+		}
+	}
+	return out
+}
+
+// joinKey собирает ключ строки; ok == false — одно из значений NULL.
+func joinKey(row []string, idx []int) (string, bool) {
+	parts := make([]string, len(idx))
+	for i, fi := range idx {
+		if fi >= len(row) || row[fi] == "" || row[fi] == nullSentinel {
+			return "", false
+		}
+		parts[i] = row[fi]
+	}
+	return strings.Join(parts, joinKeySep), true
+}
+
+// fieldIndex — позиция поля в схеме (имя без учёта регистра), -1 — нет поля.
+func fieldIndex(s packet.Schema, name string) int {
+	for i, f := range s.Fields {
+		if strings.EqualFold(f.Name, name) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,208 @@
+package tdtql
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+	"github.com/ruslano69/tdtp-framework/pkg/core/schema"
+)
+
+func joinTestData() (packet.Schema, [][]string, packet.Schema, [][]string) {
+	orders := schema.NewBuilder().
+		AddInteger("ID", true).
+		AddInteger("CustomerID", false).
+		AddDecimal("Total", 10, 2).
+		Build()
+	orderRows := [][]string{
+		{"1", "10", "100.00"},
+		{"2", "20", "250.00"},
+		{"3", "10", "75.50"},
+		{"4", "", "10.00"},    // без клиента (NULL)
+		{"5", "99", "500.00"}, // клиента нет в справочнике
+	}
+	customers := schema.NewBuilder().
+		AddInteger("ID", true).
+		AddText("Name", 100).
+		AddText("Country", 2).
+		Build()
+	customerRows := [][]string{
+		{"10", "Alice", "RU"},
+		{"20", "Bob", "DE"},
+		{"30", "Carol", "RU"},
+	}
+	return orders, orderRows, customers, customerRows
+}
+
+func TestExecuteJoin_Inner(t *testing.T) {
+	orders, orderRows, customers, customerRows := joinTestData()
+	query := packet.NewQuery()
+	query.Join = &packet.Join{Table: "customers", On: []packet.JoinOn{{Left: "customerid", Right: "id"}}}
+
+	result, joined, err := NewExecutor().ExecuteJoin(query, orderRows, orders, customerRows, customers)
+	if err != nil {
+		t.Fatalf("ExecuteJoin: %v", err)
+	}
+
+	var names []string
+	for _, f := range joined.Fields {
+		names = append(names, f.Name)
+	}
+	wantNames := []string{"ID", "CustomerID", "Total", "customers.ID", "customers.Name", "customers.Country"}
+	if !reflect.DeepEqual(names, wantNames) {
+		t.Errorf("joined schema = %v, want %v", names, wantNames)
+	}
+	if joined.Fields[3].Key {
+		t.Error("key of the joined table must not be a key of the result")
+	}
+
+	want := [][]string{
+		{"1", "10", "100.00", "10", "Alice", "RU"},
+		{"2", "20", "250.00", "20", "Bob", "DE"},
+		{"3", "10", "75.50", "10", "Alice", "RU"},
+	}
+	if !reflect.DeepEqual(result.FilteredRows, want) {
+		t.Errorf("rows = %v, want %v", result.FilteredRows, want)
+	}
+	if query.Join.Type != packet.JoinInner || query.Join.On[0].Left != "CustomerID" || query.Join.On[0].Right != "ID" {
+		t.Errorf("join not normalized: %+v", query.Join)
+	}
+	if result.QueryContext.OriginalQuery.Join == nil {
+		t.Error("OriginalQuery must keep the Join")
+	}
+}
+
+func TestExecuteJoin_LeftWithFilterAndOrder(t *testing.T) {
+	orders, orderRows, customers, customerRows := joinTestData()
+	query := packet.NewQuery()
+	query.Join = &packet.Join{Table: "customers", Type: "LEFT", On: []packet.JoinOn{{Left: "CustomerID", Right: "ID"}}}
+	query.Filters = &packet.Filters{And: &packet.LogicalGroup{
+		Filters: []packet.Filter{{Field: "customers.Country", Operator: "ne", Value: "DE"}},
+	}}
+	query.OrderBy = &packet.OrderBy{Field: "Total", Direction: "DESC"}
+
+	result, _, err := NewExecutor().ExecuteJoin(query, orderRows, orders, customerRows, customers)
+	if err != nil {
+		t.Fatalf("ExecuteJoin: %v", err)
+	}
+	// Заказы 4 и 5 остаются без пары: поля клиента пусты
+	want := [][]string{
+		{"5", "99", "500.00", "", "", ""},
+		{"1", "10", "100.00", "10", "Alice", "RU"},
+		{"3", "10", "75.50", "10", "Alice", "RU"},
+		{"4", "", "10.00", "", "", ""},
+	}
+	if !reflect.DeepEqual(result.FilteredRows, want) {
+		t.Errorf("rows = %v, want %v", result.FilteredRows, want)
+	}
+	if result.TotalRows != 5 || result.MatchedRows != 4 {
+		t.Errorf("total %d, matched %d", result.TotalRows, result.MatchedRows)
+	}
+}
+
+func TestExecuteJoin_Errors(t *testing.T) {
+	orders, orderRows, customers, customerRows := joinTestData()
+	for name, join := range map[string]*packet.Join{
+		"no table":      {On: []packet.JoinOn{{Left: "CustomerID", Right: "ID"}}},
+		"no on":         {Table: "customers"},
+		"bad type":      {Table: "customers", Type: "full", On: []packet.JoinOn{{Left: "CustomerID", Right: "ID"}}},
+		"unknown left":  {Table: "customers", On: []packet.JoinOn{{Left: "Nope", Right: "ID"}}},
+		"unknown right": {Table: "customers", On: []packet.JoinOn{{Left: "CustomerID", Right: "Nope"}}},
+	} {
+		query := packet.NewQuery()
+		query.Join = join
+		if _, _, err := NewExecutor().ExecuteJoin(query, orderRows, orders, customerRows, customers); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+
+	query := packet.NewQuery()
+	query.Join = &packet.Join{Table: "customers", On: []packet.JoinOn{{Left: "CustomerID", Right: "ID"}}}
+	if _, err := NewExecutor().Execute(query, orderRows, orders); err == nil || !strings.Contains(err.Error(), "ExecuteJoin") {
+		t.Errorf("Execute with Join = %v, want error", err)
+	}
+}
+
+func TestTranslate_Join(t *testing.T) {
+	query, err := NewTranslator().Translate(
+		"SELECT * FROM orders o LEFT OUTER JOIN customers AS c ON c.id = o.customer_id AND o.region = c.region " +
+			"WHERE c.country = 'RU' AND o.total > 100 ORDER BY c.name, orders.id DESC")
+	if err != nil {
+		t.Fatalf("Translate: %v", err)
+	}
+	want := &packet.Join{Table: "customers", Type: packet.JoinLeft, On: []packet.JoinOn{
+		{Left: "customer_id", Right: "id"},
+		{Left: "region", Right: "region"},
+	}}
+	if !reflect.DeepEqual(query.Join, want) {
+		t.Errorf("Join = %+v, want %+v", query.Join, want)
+	}
+	filters := query.Filters.And.Filters
+	if filters[0].Field != "customers.country" || filters[1].Field != "total" {
+		t.Errorf("filters = %+v", filters)
+	}
+	if f := query.OrderBy.Fields; f[0].Name != "customers.name" || f[1].Name != "id" {
+		t.Errorf("order by = %+v", f)
+	}
+
+	if q, err := NewTranslator().Translate("SELECT * FROM orders JOIN customers ON orders.customer_id = customers.id"); err != nil || q.Join.Type != packet.JoinInner {
+		t.Errorf("plain JOIN: %+v, %v", q, err)
+	}
+	if q, err := NewTranslator().Translate("SELECT o.id, c.name FROM orders o JOIN customers c ON o.customer_id = c.id"); err != nil ||
+		!reflect.DeepEqual(q.Fields, []string{"id", "customers.name"}) {
+		t.Errorf("join projection: %+v, %v", q, err)
+	}
+	for _, sql := range []string{
+		"SELECT * FROM orders JOIN customers ON customer_id = id",            // поля без таблиц
+		"SELECT * FROM orders JOIN customers ON orders.total > customers.id", // не равенство
+		"SELECT * FROM orders LEFT customers ON orders.customer_id = customers.id",
+		"SELECT * FROM orders JOIN customers WHERE orders.id = 1",
+	} {
+		if _, err := NewTranslator().Translate(sql); err == nil {
+			t.Errorf("%s: expected error", sql)
+		}
+	}
+}
+
+func TestSQLGenerator_Join(t *testing.T) {
+	query, err := NewTranslator().Translate(
+		"SELECT * FROM orders o LEFT JOIN customers c ON o.customer_id = c.id WHERE c.country = 'RU' ORDER BY o.total DESC LIMIT 10")
+	if err != nil {
+		t.Fatalf("Translate: %v", err)
+	}
+	g := NewSQLGenerator()
+	if !g.CanTranslateToSQL(query) {
+		t.Fatal("join query must be translatable")
+	}
+	sql, err := g.GenerateSQL("orders", query)
+	if err != nil {
+		t.Fatalf("GenerateSQL: %v", err)
+	}
+	want := "SELECT orders.*, customers.* FROM orders LEFT JOIN customers ON orders.customer_id = customers.id " +
+		"WHERE customers.country = 'RU' ORDER BY orders.total DESC LIMIT 10"
+	if sql != want {
+		t.Errorf("SQL:\n%s\nwant:\n%s", sql, want)
+	}
+
+	query.Fields = []string{"id", "customers.name"}
+	if sql, _ = g.GenerateSQL("orders", query); !strings.HasPrefix(sql, "SELECT orders.id, customers.name FROM ") {
+		t.Errorf("projection: %s", sql)
+	}
+
+	// Generator без Join не должен унаследовать квалификацию
+	if sql, _ = g.GenerateSQL("users", &packet.Query{Filters: &packet.Filters{And: &packet.LogicalGroup{
+		Filters: []packet.Filter{{Field: "id", Operator: "eq", Value: "1"}},
+	}}}); sql != "SELECT * FROM users WHERE id = 1" {
+		t.Errorf("plain query after join: %s", sql)
+	}
+
+	query.Limit = -5
+	if g.CanTranslateToSQL(query) {
+		t.Error("tail limit with Join must not be translatable")
+	}
+	self := &packet.Query{Join: &packet.Join{Table: "employees", On: []packet.JoinOn{{Left: "manager_id", Right: "id"}}}}
+	if _, err := g.GenerateSQL("employees", self); err == nil {
+		t.Error("self-join must not be translated")
+	}
+}
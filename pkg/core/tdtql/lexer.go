@@ -37,8 +37,11 @@ const (
 	TokenOrderBy
 	TokenAsc
 	TokenDesc
+	TokenCollate
 	TokenLimit
 	TokenOffset
+	TokenCast
+	TokenAs
 
 	// Операторы
 	// TokenEq represents the = operator.
@@ -52,6 +55,7 @@ const (
 	TokenRParen // )
 	TokenComma  // ,
 	TokenStar   // *
+	TokenDot    // . (квалифицированное имя: table.field)
 )
 
 // Token представляет токен
@@ -149,6 +153,9 @@ func (l *Lexer) NextToken() Token {
 	case '*':
 		tok.Type = TokenStar
 		tok.Literal = string(l.ch)
+	case '.':
+		tok.Type = TokenDot
+		tok.Literal = string(l.ch)
 	case '\'':
 		tok.Type = TokenString
 		tok.Literal = l.readString(l.ch)
@@ -318,10 +325,16 @@ func lookupKeyword(ident string) TokenType {
 		"asc":     TokenAsc,
 		"DESC":    TokenDesc,
 		"desc":    TokenDesc,
+		"COLLATE": TokenCollate,
+		"collate": TokenCollate,
 		"LIMIT":   TokenLimit,
 		"limit":   TokenLimit,
 		"OFFSET":  TokenOffset,
 		"offset":  TokenOffset,
+		"CAST":    TokenCast,
+		"cast":    TokenCast,
+		"AS":      TokenAs,
+		"as":      TokenAs,
 	}
 
 	if tok, ok := keywords[ident]; ok {
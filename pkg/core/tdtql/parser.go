@@ -3,6 +3,7 @@ package tdtql
 import (
 	"fmt"
 	"strconv"
+	"strings"
 )
 
 // Parser SQL парсер
@@ -63,12 +64,19 @@ func (p *Parser) ParseSelect() (*SelectStatement, error) {
 	}
 	p.nextToken()
 
-	// * или список полей (пока поддерживаем только *)
+	// * или список полей: name, table.name, ...
 	if p.curToken.Type == TokenStar {
 		p.nextToken()
 	} else {
-		// Пропускаем список полей до FROM
-		for p.curToken.Type != TokenFrom && p.curToken.Type != TokenEOF {
+		for {
+			name, ok := p.parseName()
+			if !ok {
+				return nil, fmt.Errorf("expected field name or * in SELECT list, got %v", p.curToken.Type)
+			}
+			stmt.Fields = append(stmt.Fields, name)
+			if p.curToken.Type != TokenComma {
+				break
+			}
 			p.nextToken()
 		}
 	}
@@ -78,12 +86,26 @@ func (p *Parser) ParseSelect() (*SelectStatement, error) {
 		return nil, fmt.Errorf("expected FROM")
 	}
 
-	// TableName
-	if p.curToken.Type != TokenIdent {
+	// TableName [[AS] alias]
+	table, ok := p.parseName()
+	if !ok {
 		return nil, fmt.Errorf("expected table name")
 	}
-	stmt.TableName = p.curToken.Literal
-	p.nextToken()
+	stmt.TableName = table
+	alias, err := p.parseAlias()
+	if err != nil {
+		return nil, err
+	}
+	stmt.TableAlias = alias
+
+	// JOIN (опционально)
+	if p.isWord("JOIN") || p.isWord("INNER") || p.isWord("LEFT") {
+		join, err := p.parseJoin()
+		if err != nil {
+			return nil, err
+		}
+		stmt.Join = join
+	}
 
 	// WHERE (опционально)
 	if p.curToken.Type == TokenWhere {
@@ -141,6 +163,98 @@ func (p *Parser) ParseSelect() (*SelectStatement, error) {
 	return stmt, nil
 }
 
+// parseName читает имя, возможно квалифицированное: table.field, schema.table.
+func (p *Parser) parseName() (string, bool) {
+	if p.curToken.Type != TokenIdent {
+		return "", false
+	}
+	name := p.curToken.Literal
+	p.nextToken()
+	for p.curToken.Type == TokenDot && p.peekToken.Type == TokenIdent {
+		name += "." + p.peekToken.Literal
+		p.nextToken()
+		p.nextToken()
+	}
+	return name, true
+}
+
+// isWord проверяет контекстное ключевое слово (JOIN, ON...): вне FROM эти
+// слова — обычные имена полей, поэтому лексер их не выделяет.
+func (p *Parser) isWord(word string) bool {
+	return p.curToken.Type == TokenIdent && strings.EqualFold(p.curToken.Literal, word)
+}
+
+// parseAlias читает необязательный псевдоним таблицы: [AS] alias.
+func (p *Parser) parseAlias() (string, error) {
+	if p.curToken.Type == TokenAs {
+		p.nextToken()
+		if p.curToken.Type != TokenIdent {
+			return "", fmt.Errorf("expected alias after AS")
+		}
+	} else if p.curToken.Type != TokenIdent || p.isWord("JOIN") || p.isWord("INNER") || p.isWord("LEFT") || p.isWord("ON") {
+		return "", nil
+	}
+	alias := p.curToken.Literal
+	p.nextToken()
+	return alias, nil
+}
+
+// parseJoin парсит [INNER | LEFT [OUTER]] JOIN table [alias] ON a.x = b.y [AND ...]
+func (p *Parser) parseJoin() (*JoinClause, error) {
+	join := &JoinClause{Type: "INNER"}
+	switch {
+	case p.isWord("INNER"):
+		p.nextToken()
+	case p.isWord("LEFT"):
+		join.Type = "LEFT"
+		p.nextToken()
+		if p.isWord("OUTER") {
+			p.nextToken()
+		}
+	}
+	if !p.isWord("JOIN") {
+		return nil, fmt.Errorf("expected JOIN")
+	}
+	p.nextToken()
+
+	table, ok := p.parseName()
+	if !ok {
+		return nil, fmt.Errorf("expected table name after JOIN")
+	}
+	join.Table = table
+	alias, err := p.parseAlias()
+	if err != nil {
+		return nil, err
+	}
+	join.Alias = alias
+
+	if !p.isWord("ON") {
+		return nil, fmt.Errorf("expected ON after JOIN %s", table)
+	}
+	p.nextToken()
+	for {
+		left, ok := p.parseName()
+		if !ok {
+			return nil, fmt.Errorf("expected field name in ON")
+		}
+		if p.curToken.Type != TokenEq {
+			return nil, fmt.Errorf("only equality conditions are supported in ON, got %v", p.curToken.Type)
+		}
+		p.nextToken()
+		right, ok := p.parseName()
+		if !ok {
+			return nil, fmt.Errorf("expected field name after = in ON")
+		}
+		join.On = append(join.On, &JoinCondition{Left: left, Right: right})
+
+		if p.curToken.Type != TokenAnd {
+			break
+		}
+		p.nextToken()
+	}
+	return join, nil
+}
+
 // parseExpression парсит выражение с приоритетами
 // Приоритет: NOT (3) > AND (2) > OR (1)
 func (p *Parser) parseExpression(precedence int) (Expression, error) {
@@ -213,14 +327,155 @@ func (p *Parser) parseExpression(precedence int) (Expression, error) {
 	return left, nil
 }
 
-// parseCondition парсит одно условие (field op value)
+// parseCondition парсит одно условие (field op value [COLLATE 'ci'])
 func (p *Parser) parseCondition() (Expression, error) {
+	var expr Expression
+	var err error
+	if p.curToken.Type == TokenCast {
+		expr, err = p.parseCastCondition()
+	} else if p.isWord("DATE_TRUNC") && p.peekToken.Type == TokenLParen {
+		expr, err = p.parseTruncCondition()
+	} else {
+		field, ok := p.parseName()
+		if !ok {
+			return nil, fmt.Errorf("expected field name, got %v", p.curToken.Type)
+		}
+		expr, err = p.parseFieldCondition(field)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return p.parseConditionCollate(expr)
+}
+
+// parseConditionCollate парсит коллацию после условия:
+// email = 'a@b.c' COLLATE 'ci,trim', city IN ('Москва') COLLATE ci
+func (p *Parser) parseConditionCollate(expr Expression) (Expression, error) {
+	if p.curToken.Type != TokenCollate {
+		return expr, nil
+	}
+	p.nextToken()
+	if p.curToken.Type != TokenString && p.curToken.Type != TokenIdent {
+		return nil, fmt.Errorf("expected collation name after COLLATE")
+	}
+	collation := p.curToken.Literal
+	if err := ValidateFilterCollation(collation); err != nil {
+		return nil, err
+	}
+	p.nextToken()
+
+	switch e := expr.(type) {
+	case *ComparisonExpression:
+		e.Collation = mergeCollation(e.Collation, collation)
+	case *InExpression:
+		e.Collation = collation
+	case *BetweenExpression:
+		e.Collation = collation
+	default:
+		return nil, fmt.Errorf("COLLATE is not applicable to IS NULL")
+	}
+	return expr, nil
+}
+
+// mergeCollation добавляет к коллации ILIKE ("ci") опции из COLLATE.
+func mergeCollation(base, collation string) string {
+	if base == "" {
+		return collation
+	}
+	return base + "," + collation
+}
+
+// parseCastCondition парсит условие над приведённым полем:
+// CAST(Balance AS REAL) > 1000
+func (p *Parser) parseCastCondition() (Expression, error) {
+	p.nextToken() // CAST
+	if !p.expectToken(TokenLParen) {
+		return nil, fmt.Errorf("expected ( after CAST")
+	}
+	field, ok := p.parseName()
+	if !ok {
+		return nil, fmt.Errorf("expected field name in CAST, got %v", p.curToken.Type)
+	}
+	if !p.expectToken(TokenAs) {
+		return nil, fmt.Errorf("expected AS in CAST")
+	}
 	if p.curToken.Type != TokenIdent {
-		return nil, fmt.Errorf("expected field name, got %v", p.curToken.Type)
+		return nil, fmt.Errorf("expected type name in CAST, got %v", p.curToken.Type)
+	}
+	cast := strings.ToUpper(p.curToken.Literal)
+	if err := ValidateCast(cast); err != nil {
+		return nil, err
+	}
+	p.nextToken()
+	if !p.expectToken(TokenRParen) {
+		return nil, fmt.Errorf("expected ) after CAST type")
+	}
+
+	expr, err := p.parseFieldCondition(field)
+	if err != nil {
+		return nil, err
+	}
+	switch e := expr.(type) {
+	case *ComparisonExpression:
+		e.Cast = cast
+	case *InExpression:
+		e.Cast = cast
+	case *BetweenExpression:
+		e.Cast = cast
+	default:
+		return nil, fmt.Errorf("CAST is not applicable to IS NULL")
 	}
+	return expr, nil
+}
 
-	field := p.curToken.Literal
+// parseTruncCondition парсит условие над датой, усечённой до периода:
+// DATE_TRUNC('month', CreatedAt) = '2026-10-01'
+func (p *Parser) parseTruncCondition() (Expression, error) {
+	p.nextToken() // DATE_TRUNC
+	if !p.expectToken(TokenLParen) {
+		return nil, fmt.Errorf("expected ( after DATE_TRUNC")
+	}
+	if p.curToken.Type != TokenString && p.curToken.Type != TokenIdent {
+		return nil, fmt.Errorf("expected unit in DATE_TRUNC, got %v", p.curToken.Type)
+	}
+	unit := strings.ToLower(p.curToken.Literal)
+	if err := ValidateTrunc(unit); err != nil {
+		return nil, err
+	}
 	p.nextToken()
+	if !p.expectToken(TokenComma) {
+		return nil, fmt.Errorf("expected , after DATE_TRUNC unit")
+	}
+	field, ok := p.parseName()
+	if !ok {
+		return nil, fmt.Errorf("expected field name in DATE_TRUNC, got %v", p.curToken.Type)
+	}
+	if !p.expectToken(TokenRParen) {
+		return nil, fmt.Errorf("expected ) after DATE_TRUNC field")
+	}
+
+	expr, err := p.parseFieldCondition(field)
+	if err != nil {
+		return nil, err
+	}
+	switch e := expr.(type) {
+	case *ComparisonExpression:
+		if e.Operator != "eq" && e.Operator != "ne" && !coercibleOperators[e.Operator] {
+			return nil, fmt.Errorf("DATE_TRUNC is not applicable to %s", e.Operator)
+		}
+		e.Trunc = unit
+	case *InExpression:
+		e.Trunc = unit
+	case *BetweenExpression:
+		e.Trunc = unit
+	default:
+		return nil, fmt.Errorf("DATE_TRUNC is not applicable to IS NULL")
+	}
+	return expr, nil
+}
+
+// parseFieldCondition парсит оператор и значения условия для уже прочитанного поля
+func (p *Parser) parseFieldCondition(field string) (Expression, error) {
 
 	// IS NULL / IS NOT NULL
 	if p.curToken.Type == TokenIs {
@@ -242,11 +497,8 @@ func (p *Parser) parseCondition() (Expression, error) {
 		p.nextToken()
 		return p.parseInExpression(field, false)
 	}
-	if p.curToken.Type == TokenNot {
+	if p.curToken.Type == TokenNot && p.peekToken.Type == TokenIn {
 		p.nextToken()
-		if p.curToken.Type != TokenIn {
-			return nil, fmt.Errorf("expected IN after NOT")
-		}
 		p.nextToken()
 		return p.parseInExpression(field, true)
 	}
@@ -257,8 +509,9 @@ func (p *Parser) parseCondition() (Expression, error) {
 		return p.parseBetweenExpression(field, false)
 	}
 
-	// LIKE / NOT LIKE
-	var operator string
+	// LIKE / NOT LIKE, ILIKE / NOT ILIKE (LIKE без учёта регистра),
+	// REGEXP / NOT REGEXP, STARTS WITH, ENDS WITH, LAST 7 DAYS
+	var operator, collation string
 	switch p.curToken.Type {
 	case TokenEq:
 		operator = "eq"
@@ -276,13 +529,37 @@ func (p *Parser) parseCondition() (Expression, error) {
 		operator = "like"
 	case TokenNot:
 		p.nextToken()
-		if p.curToken.Type == TokenLike {
+		switch {
+		case p.curToken.Type == TokenLike:
 			operator = "not_like"
-		} else {
-			return nil, fmt.Errorf("expected LIKE after NOT")
+		case p.isWord("ILIKE"):
+			operator, collation = "not_like", CollationCaseInsensitive
+		case p.isWord("REGEXP"):
+			operator = "not_regex"
+		default:
+			return nil, fmt.Errorf("expected IN, LIKE or REGEXP after NOT")
 		}
 	default:
-		return nil, fmt.Errorf("expected operator, got %v", p.curToken.Type)
+		switch {
+		case p.isWord("ILIKE"):
+			operator, collation = "like", CollationCaseInsensitive
+		case p.isWord("REGEXP"):
+			operator = "regex"
+		case p.isWord("STARTS"), p.isWord("ENDS"):
+			word := strings.ToUpper(p.curToken.Literal)
+			operator = "starts_with"
+			if word == "ENDS" {
+				operator = "ends_with"
+			}
+			p.nextToken()
+			if !p.isWord("WITH") {
+				return nil, fmt.Errorf("expected WITH after %s", word)
+			}
+		case p.isWord("LAST"):
+			return p.parseLastExpression(field)
+		default:
+			return nil, fmt.Errorf("expected operator, got %v", p.curToken.Type)
+		}
 	}
 
 	p.nextToken()
@@ -297,10 +574,41 @@ func (p *Parser) parseCondition() (Expression, error) {
 	}
 	p.nextToken()
 
+	return &ComparisonExpression{
+		Field:     field,
+		Operator:  operator,
+		Value:     value,
+		Collation: collation,
+	}, nil
+}
+
+// parseLastExpression парсит период оператора LAST: LAST 7 DAYS или
+// LAST '7 days'.
+func (p *Parser) parseLastExpression(field string) (Expression, error) {
+	p.nextToken() // LAST
+	var period string
+	switch p.curToken.Type {
+	case TokenString:
+		period = p.curToken.Literal
+	case TokenNumber:
+		period = p.curToken.Literal
+		p.nextToken()
+		if p.curToken.Type != TokenIdent {
+			return nil, fmt.Errorf("expected unit after LAST %s (e.g. DAYS)", period)
+		}
+		period += " " + strings.ToLower(p.curToken.Literal)
+	default:
+		return nil, fmt.Errorf("expected period after LAST (e.g. LAST 7 DAYS)")
+	}
+	if _, err := parseLastPeriod(period); err != nil {
+		return nil, err
+	}
+	p.nextToken()
+
 	return &ComparisonExpression{
 		Field:    field,
-		Operator: operator,
-		Value:    value,
+		Operator: "last",
+		Value:    period,
 	}, nil
 }
 
@@ -372,15 +680,25 @@ func (p *Parser) parseOrderBy() ([]*OrderByClause, error) {
 	clauses := []*OrderByClause{}
 
 	for {
-		if p.curToken.Type != TokenIdent {
+		field, ok := p.parseName()
+		if !ok {
 			return nil, fmt.Errorf("expected field name in ORDER BY")
 		}
 
 		clause := &OrderByClause{
-			Field:     p.curToken.Literal,
+			Field:     field,
 			Direction: "ASC", // по умолчанию
 		}
-		p.nextToken()
+
+		// COLLATE 'ru' | COLLATE ru
+		if p.curToken.Type == TokenCollate {
+			p.nextToken()
+			if p.curToken.Type != TokenString && p.curToken.Type != TokenIdent {
+				return nil, fmt.Errorf("expected collation name after COLLATE")
+			}
+			clause.Collation = p.curToken.Literal
+			p.nextToken()
+		}
 
 		// ASC/DESC
 		switch p.curToken.Type {
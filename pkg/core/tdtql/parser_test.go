@@ -2,6 +2,8 @@ package tdtql
 
 import (
 	"testing"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
 )
 
 func TestParser_SelectFromWhere(t *testing.T) {
@@ -324,6 +326,60 @@ func TestParser_MultipleOrderBy(t *testing.T) {
 	}
 }
 
+func TestParser_OrderByCollate(t *testing.T) {
+	input := "SELECT * FROM Users ORDER BY city COLLATE 'ru' DESC, name COLLATE binary"
+	parser := NewParser(input)
+
+	stmt, err := parser.ParseSelect()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+
+	if len(stmt.OrderBy) != 2 {
+		t.Fatalf("expected 2 ORDER BY clauses, got %d", len(stmt.OrderBy))
+	}
+
+	if c := stmt.OrderBy[0]; c.Field != "city" || c.Collation != "ru" || c.Direction != "DESC" {
+		t.Errorf("first ORDER BY clause incorrect: %+v", c)
+	}
+
+	if c := stmt.OrderBy[1]; c.Field != "name" || c.Collation != "binary" || c.Direction != "ASC" {
+		t.Errorf("second ORDER BY clause incorrect: %+v", c)
+	}
+}
+
+func TestParser_CastCondition(t *testing.T) {
+	input := "SELECT * FROM Accounts WHERE CAST(Balance AS real) > 1000 AND CAST(Opened AS DATE) BETWEEN '2024-01-01' AND '2024-12-31'"
+	parser := NewParser(input)
+
+	stmt, err := parser.ParseSelect()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+
+	and, ok := stmt.Where.(*BinaryExpression)
+	if !ok {
+		t.Fatalf("expected BinaryExpression, got %T", stmt.Where)
+	}
+
+	cmp, ok := and.Left.(*ComparisonExpression)
+	if !ok || cmp.Field != "Balance" || cmp.Cast != "REAL" || cmp.Operator != "gt" {
+		t.Errorf("CAST comparison incorrect: %+v", and.Left)
+	}
+
+	between, ok := and.Right.(*BetweenExpression)
+	if !ok || between.Field != "Opened" || between.Cast != "DATE" {
+		t.Errorf("CAST between incorrect: %+v", and.Right)
+	}
+
+	if _, err := NewParser("SELECT * FROM T WHERE CAST(x AS GEOMETRY) > 1").ParseSelect(); err == nil {
+		t.Error("expected error for unsupported CAST type")
+	}
+	if _, err := NewParser("SELECT * FROM T WHERE CAST(x AS REAL) IS NULL").ParseSelect(); err == nil {
+		t.Error("expected error for CAST with IS NULL")
+	}
+}
+
 func TestParser_Limit(t *testing.T) {
 	input := "SELECT * FROM Users LIMIT 100"
 	parser := NewParser(input)
@@ -508,3 +564,87 @@ func TestParser_CaseInsensitiveKeywords(t *testing.T) {
 		})
 	}
 }
+
+func TestParser_ConditionCollate(t *testing.T) {
+	input := "SELECT * FROM Users WHERE email = 'Ann@Mail.ru' COLLATE 'ci,trim' AND city IN ('Москва', 'Казань') COLLATE ci AND name ILIKE 'ann%'"
+	parser := NewParser(input)
+
+	stmt, err := parser.ParseSelect()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+
+	outer, ok := stmt.Where.(*BinaryExpression)
+	if !ok {
+		t.Fatalf("expected BinaryExpression, got %T", stmt.Where)
+	}
+	inner, ok := outer.Left.(*BinaryExpression)
+	if !ok {
+		t.Fatalf("expected nested BinaryExpression, got %T", outer.Left)
+	}
+
+	if cmp, ok := inner.Left.(*ComparisonExpression); !ok || cmp.Collation != "ci,trim" || cmp.Operator != "eq" {
+		t.Errorf("COLLATE comparison incorrect: %+v", inner.Left)
+	}
+	if in, ok := inner.Right.(*InExpression); !ok || in.Collation != "ci" {
+		t.Errorf("COLLATE IN incorrect: %+v", inner.Right)
+	}
+	if like, ok := outer.Right.(*ComparisonExpression); !ok || like.Operator != "like" || like.Collation != "ci" {
+		t.Errorf("ILIKE incorrect: %+v", outer.Right)
+	}
+
+	if _, err := NewParser("SELECT * FROM T WHERE x IS NULL COLLATE ci").ParseSelect(); err == nil {
+		t.Error("expected error for COLLATE with IS NULL")
+	}
+	if _, err := NewParser("SELECT * FROM T WHERE x = 'a' COLLATE 'ru,de'").ParseSelect(); err == nil {
+		t.Error("expected error for two language tags")
+	}
+}
+
+func TestParser_FunctionOperators(t *testing.T) {
+	translator := NewTranslator()
+	query, err := translator.Translate("SELECT * FROM Orders WHERE code REGEXP '^A[0-9]+$' AND name NOT REGEXP 'test' AND email ENDS WITH '@corp.ru' AND sku STARTS WITH 'X-' AND CreatedAt LAST 7 DAYS AND DATE_TRUNC('month', ShippedAt) = '2026-10-01' AND DATE_TRUNC(week, PaidAt) BETWEEN '2026-10-05' AND '2026-10-12' AND name NOT LIKE 'x%'")
+	if err != nil {
+		t.Fatalf("Translate failed: %v", err)
+	}
+	want := []packet.Filter{
+		{Field: "code", Operator: "regex", Value: "^A[0-9]+$"},
+		{Field: "name", Operator: "not_regex", Value: "test"},
+		{Field: "email", Operator: "ends_with", Value: "@corp.ru"},
+		{Field: "sku", Operator: "starts_with", Value: "X-"},
+		{Field: "CreatedAt", Operator: "last", Value: "7 days"},
+		{Field: "ShippedAt", Operator: "eq", Value: "2026-10-01", Trunc: "month"},
+		{Field: "PaidAt", Operator: "between", Value: "2026-10-05", Value2: "2026-10-12", Trunc: "week"},
+		{Field: "name", Operator: "not_like", Value: "x%"},
+	}
+	got := query.Filters.And.Filters
+	if len(got) != len(want) {
+		t.Fatalf("expected %d filters, got %d: %+v", len(want), len(got), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("filter %d: expected %+v, got %+v", i, want[i], got[i])
+		}
+	}
+
+	quoted, err := translator.Translate("SELECT * FROM Orders WHERE CreatedAt LAST '24 hours'")
+	if err != nil {
+		t.Fatalf("Translate failed: %v", err)
+	}
+	if f := quoted.Filters.And.Filters[0]; f.Operator != "last" || f.Value != "24 hours" {
+		t.Errorf("LAST '24 hours' incorrect: %+v", f)
+	}
+
+	for _, input := range []string{
+		"SELECT * FROM T WHERE d LAST 7 FORTNIGHTS",
+		"SELECT * FROM T WHERE d LAST 0 DAYS",
+		"SELECT * FROM T WHERE DATE_TRUNC('decade', d) = '2026-01-01'",
+		"SELECT * FROM T WHERE DATE_TRUNC('month', d) LIKE '2026%'",
+		"SELECT * FROM T WHERE DATE_TRUNC('month', d) IS NULL",
+		"SELECT * FROM T WHERE s STARTS 'x'",
+	} {
+		if _, err := translator.Translate(input); err == nil {
+			t.Errorf("expected error for %q", input)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package tdtql
+
+import (
+	"strings"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+// Оценки селективности операторов без статистики СУБД (классические
+// эвристики оптимизаторов System R / PostgreSQL для «неизвестного» столбца).
+const (
+	selEq      = 0.005
+	selRange   = 1.0 / 3
+	selBetween = 0.1 // диапазон с двумя границами уже одиночного
+	selLike    = 0.25
+	selNull    = 0.05
+)
+
+// EstimateSelectivity оценивает долю строк (0..1), проходящих фильтры.
+//
+// Оценка грубая и нужна только для выбора стратегии выполнения
+// (pushdown / потоковая фильтрация / чтение всей таблицы): условия AND
+// считаются независимыми (произведение), OR — через дополнение
+// 1 - Π(1 - s). nil-фильтры дают 1.
+func EstimateSelectivity(filters *packet.Filters) float64 {
+	if filters == nil {
+		return 1
+	}
+	switch {
+	case filters.And != nil:
+		return groupSelectivity(filters.And, true)
+	case filters.Or != nil:
+		return groupSelectivity(filters.Or, false)
+	}
+	return 1
+}
+
+func groupSelectivity(group *packet.LogicalGroup, and bool) float64 {
+	var parts []float64
+	for _, f := range group.Filters {
+		parts = append(parts, filterSelectivity(f))
+	}
+	for i := range group.And {
+		parts = append(parts, groupSelectivity(&group.And[i], true))
+	}
+	for i := range group.Or {
+		parts = append(parts, groupSelectivity(&group.Or[i], false))
+	}
+	if len(parts) == 0 {
+		return 1
+	}
+
+	if and {
+		sel := 1.0
+		for _, p := range parts {
+			sel *= p
+		}
+		return sel
+	}
+	miss := 1.0
+	for _, p := range parts {
+		miss *= 1 - p
+	}
+	return 1 - miss
+}
+
+func filterSelectivity(f packet.Filter) float64 {
+	// Равенство с усечением даты — диапазон (весь период)
+	if f.Trunc != "" {
+		switch f.Operator {
+		case "eq":
+			return selBetween
+		case "ne":
+			return 1 - selBetween
+		}
+	}
+	switch f.Operator {
+	case "eq":
+		return selEq
+	case "ne":
+		return 1 - selEq
+	case "gt", "gte", "lt", "lte":
+		return selRange
+	case "between":
+		return selBetween
+	case "in":
+		return min(1, selEq*float64(strings.Count(f.Value, ",")+1))
+	case "not_in":
+		return 1 - min(1, selEq*float64(strings.Count(f.Value, ",")+1))
+	case "like", "regex", "starts_with", "ends_with":
+		return selLike
+	case "not_like", "not_regex":
+		return 1 - selLike
+	case "last":
+		return selRange
+	case "is_null":
+		return selNull
+	case "is_not_null":
+		return 1 - selNull
+	default:
+		return 1
+	}
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
 	"github.com/ruslano69/tdtp-framework/pkg/core/schema"
+	"golang.org/x/text/collate"
 )
 
 // Sorter сортирует данные
@@ -36,6 +37,7 @@ func (s *Sorter) Sort(
 
 	// Определяем поля для сортировки
 	var sortFields []sortField
+	collators := collatorSet{}
 
 	switch {
 	case orderBy.Field != "":
@@ -45,14 +47,16 @@ func (s *Sorter) Sort(
 			return nil, err
 		}
 
-		sortFields = []sortField{
-			{
-				name:      orderBy.Field,
-				index:     index,
-				direction: orderBy.Direction,
-				field:     field,
-			},
+		sf := sortField{
+			name:      orderBy.Field,
+			index:     index,
+			direction: orderBy.Direction,
+			field:     field,
+		}
+		if sf.collator, err = collators.get(resolveCollation("", orderBy.Collation, field)); err != nil {
+			return nil, err
 		}
+		sortFields = []sortField{sf}
 	case len(orderBy.Fields) > 0:
 		// Множественная сортировка
 		for _, f := range orderBy.Fields {
@@ -61,12 +65,16 @@ func (s *Sorter) Sort(
 				return nil, err
 			}
 
-			sortFields = append(sortFields, sortField{
+			sf := sortField{
 				name:      f.Name,
 				index:     index,
 				direction: f.Direction,
 				field:     field,
-			})
+			}
+			if sf.collator, err = collators.get(resolveCollation(f.Collation, orderBy.Collation, field)); err != nil {
+				return nil, err
+			}
+			sortFields = append(sortFields, sf)
 		}
 	default:
 		return result, nil
@@ -86,6 +94,19 @@ type sortField struct {
 	index     int
 	direction string // ASC или DESC
 	field     packet.Field
+	collator  *collate.Collator // nil = побайтовое сравнение текста
+}
+
+// resolveCollation выбирает коллацию поля: OrderField.Collation,
+// затем OrderBy.Collation запроса, затем Field.Collation из схемы.
+func resolveCollation(fieldOverride, queryDefault string, field packet.Field) string {
+	if fieldOverride != "" {
+		return fieldOverride
+	}
+	if queryDefault != "" {
+		return queryDefault
+	}
+	return field.Collation
 }
 
 // getFieldInfo находит информацию о поле
@@ -108,7 +129,7 @@ func (s *Sorter) compareRows(row1, row2 []string, sortFields []sortField, conver
 		val1 := row1[sf.index]
 		val2 := row2[sf.index]
 
-		cmp := s.compareValues(val1, val2, sf.field, converter)
+		cmp := s.compareValues(val1, val2, sf.field, sf.collator, converter)
 
 		if cmp == 0 {
 			continue // равны, проверяем следующее поле
@@ -125,7 +146,8 @@ func (s *Sorter) compareRows(row1, row2 []string, sortFields []sortField, conver
 
 // compareValues сравнивает два значения
 // Возвращает: -1 если val1 < val2, 0 если равны, 1 если val1 > val2
-func (s *Sorter) compareValues(val1, val2 string, field packet.Field, converter *schema.Converter) int {
+// collator применяется только к текстовым полям; nil — побайтовое сравнение.
+func (s *Sorter) compareValues(val1, val2 string, field packet.Field, collator *collate.Collator, converter *schema.Converter) int {
 	normalized := schema.NormalizeType(schema.DataType(field.Type))
 
 	// NULL обработка
@@ -202,8 +224,14 @@ func (s *Sorter) compareValues(val1, val2 string, field packet.Field, converter
 		}
 		return 0
 
+	case schema.TypeText:
+		if collator != nil {
+			return collator.CompareString(val1, val2)
+		}
+		return s.compareStrings(val1, val2)
+
 	default:
-		// Текстовое и остальные типы
+		// Остальные типы
 		return s.compareStrings(val1, val2)
 	}
 }
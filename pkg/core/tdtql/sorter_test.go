@@ -425,3 +425,82 @@ func TestSorter_CaseSensitiveText(t *testing.T) {
 		t.Errorf("expected ALICE first, got %s", result[0][1])
 	}
 }
+
+func TestSorter_Collation(t *testing.T) {
+	sorter := NewSorter()
+	converter := schema.NewConverter()
+
+	schemaObj := packet.Schema{
+		Fields: []packet.Field{
+			{Name: "name", Type: "TEXT"},
+		},
+	}
+
+	// В UTF-8 "ё" (U+0451) идёт после "я" (U+044F) — побайтовый порядок
+	// ставит "ёж" в конец, русская коллация — после "еда".
+	rows := [][]string{
+		{"яблоко"},
+		{"ёж"},
+		{"жук"},
+		{"еда"},
+	}
+
+	tests := []struct {
+		name     string
+		orderBy  *packet.OrderBy
+		schema   packet.Schema
+		expected []string
+	}{
+		{
+			name:     "binary by default",
+			orderBy:  &packet.OrderBy{Field: "name", Direction: "ASC"},
+			schema:   schemaObj,
+			expected: []string{"еда", "жук", "яблоко", "ёж"},
+		},
+		{
+			name:     "query collation",
+			orderBy:  &packet.OrderBy{Field: "name", Direction: "ASC", Collation: "ru"},
+			schema:   schemaObj,
+			expected: []string{"еда", "ёж", "жук", "яблоко"},
+		},
+		{
+			name:     "schema field collation",
+			orderBy:  &packet.OrderBy{Field: "name", Direction: "ASC"},
+			schema:   packet.Schema{Fields: []packet.Field{{Name: "name", Type: "TEXT", Collation: "ru"}}},
+			expected: []string{"еда", "ёж", "жук", "яблоко"},
+		},
+		{
+			name: "per-field override wins over query",
+			orderBy: &packet.OrderBy{
+				Collation: "ru",
+				Fields:    []packet.OrderField{{Name: "name", Direction: "DESC", Collation: "binary"}},
+			},
+			schema:   schemaObj,
+			expected: []string{"ёж", "яблоко", "жук", "еда"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := sorter.Sort(rows, tt.orderBy, tt.schema, converter)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for i, row := range result {
+				if row[0] != tt.expected[i] {
+					t.Errorf("row[%d]: expected %s, got %s", i, tt.expected[i], row[0])
+				}
+			}
+		})
+	}
+}
+
+func TestSorter_InvalidCollation(t *testing.T) {
+	sorter := NewSorter()
+	schemaObj := packet.Schema{Fields: []packet.Field{{Name: "name", Type: "TEXT"}}}
+	orderBy := &packet.OrderBy{Field: "name", Collation: "not a tag!"}
+
+	if _, err := sorter.Sort([][]string{{"a"}, {"b"}}, orderBy, schemaObj, schema.NewConverter()); err == nil {
+		t.Fatal("expected error for invalid collation tag")
+	}
+}
@@ -3,23 +3,66 @@ package tdtql
 import (
 	"fmt"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
 )
 
 // SQLGenerator конвертирует TDTQL запросы в SQL
-type SQLGenerator struct{}
+type SQLGenerator struct {
+	column func(name string) string // квалификация полей запроса с Join (nil — имя как есть)
+	ilike  bool                     // LIKE без учёта регистра — ILIKE (PostgreSQL)
+	regex  RegexDialect             // синтаксис regex СУБД (пусто — regex в памяти)
+	ascii  bool                     // LOWER СУБД меняет регистр только у ASCII (SQLite)
+}
+
+// RegexDialect — синтаксис регулярных выражений СУБД для pushdown
+// операторов regex и not_regex.
+type RegexDialect string
+
+const (
+	// RegexPostgres — field ~ 'p' (~* без учёта регистра).
+	RegexPostgres RegexDialect = "postgres"
+	// RegexMySQL — REGEXP_LIKE(field, 'p', 'c') (MySQL 8.0+), обратная
+	// косая черта в литерале удваивается.
+	RegexMySQL RegexDialect = "mysql"
+	// RegexOracle — REGEXP_LIKE(field, 'p', 'c').
+	RegexOracle RegexDialect = "oracle"
+)
 
 // NewSQLGenerator создает новый SQL генератор
 func NewSQLGenerator() *SQLGenerator {
 	return &SQLGenerator{}
 }
 
-// quoteTableName quotes each part of a (schema-qualified) table name.
+// SetILike включает ILIKE для LIKE с коллацией ci (СУБД с ILIKE —
+// PostgreSQL). По умолчанию — переносимое LOWER(field) LIKE LOWER(pattern).
+func (g *SQLGenerator) SetILike(enabled bool) {
+	g.ilike = enabled
+}
+
+// SetASCIILower сообщает, что LOWER СУБД меняет регистр только у ASCII
+// (SQLite): условие с коллацией ci и не-ASCII значением ('Москва') в SQL
+// не транслируется и фильтруется в памяти, где регистр Unicode.
+func (g *SQLGenerator) SetASCIILower(enabled bool) {
+	g.ascii = enabled
+}
+
+// SetRegex задаёт синтаксис регулярных выражений СУБД. По умолчанию
+// (SQLite, MS SQL — без регулярных выражений) запрос с regex не
+// транслируется в SQL и фильтруется в памяти.
+func (g *SQLGenerator) SetRegex(dialect RegexDialect) {
+	g.regex = dialect
+}
+
+// QuoteTableName quotes each part of a (schema-qualified) table name the way
+// GenerateSQL emits it in the FROM clause (SQLAdapter implementations match
+// on this form).
 // "ZTR$Employee"        → `"ZTR$Employee"`
 // "public.ZTR$Employee" → `"public"."ZTR$Employee"`
 // "[ZTR$Employee]"      → `"ZTR$Employee"`  (MSSQL brackets stripped before ANSI-quoting)
-func quoteTableName(name string) string {
+func QuoteTableName(name string) string {
 	name = StripBrackets(name) // normalise MSSQL-style [bracket] quoting before ANSI-quoting
 	parts := strings.Split(name, ".")
 	for i, p := range parts {
@@ -30,20 +73,34 @@ func quoteTableName(name string) string {
 
 // GenerateSQL конвертирует Query в SQL SELECT statement
 func (g *SQLGenerator) GenerateSQL(tableName string, query *packet.Query) (string, error) {
-	qTable := quoteTableName(tableName)
+	qTable := QuoteTableName(tableName)
 	if query == nil {
 		return fmt.Sprintf("SELECT * FROM %s", qTable), nil
 	}
 
+	from, star := qTable, "*"
+	if query.Join != nil {
+		if query.Limit < 0 {
+			return "", fmt.Errorf("tail limit with Join cannot be translated to SQL")
+		}
+		ilike, regex := g.ilike, g.regex
+		var err error
+		if g, from, err = newJoinSQLGenerator(tableName, query.Join); err != nil {
+			return "", err
+		}
+		g.ilike, g.regex = ilike, regex
+		star = qTable + ".*, " + QuoteTableName(query.Join.Table) + ".*"
+	}
+
 	var parts []string
 	if len(query.Fields) > 0 {
 		quoted := make([]string, len(query.Fields))
 		for i, f := range query.Fields {
-			quoted[i] = quoteFieldName(f)
+			quoted[i] = g.columnName(f)
 		}
-		parts = append(parts, fmt.Sprintf("SELECT %s FROM %s", strings.Join(quoted, ", "), qTable))
+		parts = append(parts, fmt.Sprintf("SELECT %s FROM %s", strings.Join(quoted, ", "), from))
 	} else {
-		parts = append(parts, fmt.Sprintf("SELECT * FROM %s", qTable))
+		parts = append(parts, fmt.Sprintf("SELECT %s FROM %s", star, from))
 	}
 
 	// WHERE clause
@@ -94,6 +151,52 @@ func (g *SQLGenerator) GenerateSQL(tableName string, query *packet.Query) (strin
 	return strings.Join(parts, " "), nil
 }
 
+// newJoinSQLGenerator возвращает генератор для запроса с Join и FROM
+// clause с соединением: поля основной таблицы квалифицируются её именем,
+// поля "<join>.<field>" — именем присоединённой таблицы.
+func newJoinSQLGenerator(tableName string, join *packet.Join) (*SQLGenerator, string, error) {
+	if strings.EqualFold(StripBrackets(tableName), StripBrackets(join.Table)) {
+		return nil, "", fmt.Errorf("self-join of %s cannot be translated to SQL", tableName)
+	}
+	var kind string
+	switch strings.ToLower(join.Type) {
+	case "", packet.JoinInner:
+		kind = "INNER JOIN"
+	case packet.JoinLeft:
+		kind = "LEFT JOIN"
+	default:
+		return nil, "", fmt.Errorf("unsupported join type: %s", join.Type)
+	}
+	if len(join.On) == 0 {
+		return nil, "", fmt.Errorf("join %s has no On conditions", join.Table)
+	}
+
+	mainTable, joinTable := QuoteTableName(tableName), QuoteTableName(join.Table)
+	prefix := join.Table + "."
+	g := &SQLGenerator{column: func(name string) string {
+		if len(name) > len(prefix) && strings.EqualFold(name[:len(prefix)], prefix) {
+			return joinTable + "." + quoteFieldName(name[len(prefix):])
+		}
+		return mainTable + "." + quoteFieldName(name)
+	}}
+
+	conditions := make([]string, len(join.On))
+	for i, on := range join.On {
+		conditions[i] = fmt.Sprintf("%s = %s.%s", g.column(on.Left), joinTable, quoteFieldName(on.Right))
+	}
+	from := fmt.Sprintf("%s %s %s ON %s", mainTable, kind, joinTable, strings.Join(conditions, " AND "))
+	return g, from, nil
+}
+
+// columnName возвращает имя поля для SQL: квалифицированное в запросе с
+// Join, иначе — квотированное при необходимости.
+func (g *SQLGenerator) columnName(name string) string {
+	if g.column != nil {
+		return g.column(name)
+	}
+	return quoteFieldName(name)
+}
+
 // generateWhereClause конвертирует Filters в SQL WHERE
 func (g *SQLGenerator) generateWhereClause(filters *packet.Filters) (string, error) {
 	if filters == nil {
@@ -173,7 +276,32 @@ func quoteFieldName(name string) string {
 
 // generateFilterCondition конвертирует Filter в SQL условие
 func (g *SQLGenerator) generateFilterCondition(filter packet.Filter) (string, error) {
-	field := quoteFieldName(filter.Field)
+	field := g.columnName(filter.Field)
+	if filter.Cast != "" && filter.Operator != "is_null" && filter.Operator != "is_not_null" {
+		sqlType, ok := sqlCastType(filter.Cast)
+		if !ok {
+			return "", fmt.Errorf("cast to %s cannot be translated to SQL", filter.Cast)
+		}
+		field = fmt.Sprintf("CAST(%s AS %s)", field, sqlType)
+	}
+	if filter.Trunc != "" {
+		return g.generateTruncCondition(field, filter)
+	}
+	if filter.Operator == "regex" || filter.Operator == "not_regex" {
+		return g.generateRegexCondition(field, filter)
+	}
+	if filter.Collation != "" && filter.Operator != "is_null" && filter.Operator != "is_not_null" {
+		collation, err := parseFilterCollation(filter.Collation)
+		if err != nil {
+			return "", err
+		}
+		if collation.tag != "" {
+			return "", fmt.Errorf("collation %q cannot be translated to SQL", filter.Collation)
+		}
+		if collation.active() {
+			return g.generateCollatedCondition(field, filter, collation)
+		}
+	}
 	operator := filter.Operator
 	value := filter.Value
 	value2 := filter.Value2
@@ -231,6 +359,20 @@ func (g *SQLGenerator) generateFilterCondition(filter packet.Filter) (string, er
 	case "not_like":
 		return fmt.Sprintf("%s NOT LIKE %s", field, escapedValue), nil
 
+	case "starts_with":
+		return fmt.Sprintf("%s LIKE %s ESCAPE '!'", field, quoteSQLString(escapeLikePattern(value)+"%")), nil
+
+	case "ends_with":
+		return fmt.Sprintf("%s LIKE %s ESCAPE '!'", field, quoteSQLString("%"+escapeLikePattern(value))), nil
+
+	case "last":
+		period, err := parseLastPeriod(value)
+		if err != nil {
+			return "", err
+		}
+		from, to, rolling := period.window(timeNow())
+		return fmt.Sprintf("%s >= %s AND %s < %s", field, dateLiteral(from, rolling), field, dateLiteral(to, rolling)), nil
+
 	case "is_null":
 		return fmt.Sprintf("%s IS NULL", field), nil
 
@@ -242,6 +384,211 @@ func (g *SQLGenerator) generateFilterCondition(filter packet.Filter) (string, er
 	}
 }
 
+// generateCollatedCondition конвертирует Filter с коллацией ci/trim: поле
+// оборачивается в LTRIM(RTRIM(...)) и LOWER(...), значения — строковые
+// литералы, обрезанные заранее и под LOWER(...), чтобы регистр у поля и
+// значения приводила одна и та же СУБД. LIKE (и starts_with, ends_with) с
+// ci при SetILike — ILIKE.
+func (g *SQLGenerator) generateCollatedCondition(field string, filter packet.Filter, c filterCollation) (string, error) {
+	like := "LIKE"
+	fold := c.fold
+	switch filter.Operator {
+	case "like", "not_like", "starts_with", "ends_with":
+		if fold && g.ilike {
+			like, fold = "ILIKE", false
+		}
+	}
+	if c.trim {
+		field = fmt.Sprintf("LTRIM(RTRIM(%s))", field)
+	}
+	if fold {
+		field = fmt.Sprintf("LOWER(%s)", field)
+	}
+	literal := func(v string) string {
+		if c.trim {
+			v = strings.Trim(v, " ")
+		}
+		quoted := "'" + strings.ReplaceAll(v, "'", "''") + "'"
+		if fold {
+			return fmt.Sprintf("LOWER(%s)", quoted)
+		}
+		return quoted
+	}
+	list := func(value string) string {
+		values := strings.Split(value, ",")
+		for i, v := range values {
+			values[i] = literal(strings.TrimSpace(v))
+		}
+		return strings.Join(values, ", ")
+	}
+
+	switch filter.Operator {
+	case "eq":
+		return fmt.Sprintf("%s = %s", field, literal(filter.Value)), nil
+	case "ne":
+		return fmt.Sprintf("%s != %s", field, literal(filter.Value)), nil
+	case "gt":
+		return fmt.Sprintf("%s > %s", field, literal(filter.Value)), nil
+	case "gte":
+		return fmt.Sprintf("%s >= %s", field, literal(filter.Value)), nil
+	case "lt":
+		return fmt.Sprintf("%s < %s", field, literal(filter.Value)), nil
+	case "lte":
+		return fmt.Sprintf("%s <= %s", field, literal(filter.Value)), nil
+	case "between":
+		if filter.Value2 == "" {
+			return "", fmt.Errorf("BETWEEN operator requires value2")
+		}
+		return fmt.Sprintf("%s BETWEEN %s AND %s", field, literal(filter.Value), literal(filter.Value2)), nil
+	case "in":
+		return fmt.Sprintf("%s IN (%s)", field, list(filter.Value)), nil
+	case "not_in":
+		return fmt.Sprintf("%s NOT IN (%s)", field, list(filter.Value)), nil
+	case "like":
+		return fmt.Sprintf("%s %s %s", field, like, literal(filter.Value)), nil
+	case "not_like":
+		return fmt.Sprintf("%s NOT %s %s", field, like, literal(filter.Value)), nil
+	case "starts_with", "ends_with":
+		v := filter.Value
+		if c.trim {
+			v = strings.Trim(v, " ")
+		}
+		pattern := escapeLikePattern(v) + "%"
+		if filter.Operator == "ends_with" {
+			pattern = "%" + escapeLikePattern(v)
+		}
+		return fmt.Sprintf("%s %s %s ESCAPE '!'", field, like, literal(pattern)), nil
+	default:
+		return "", fmt.Errorf("unsupported operator: %s", filter.Operator)
+	}
+}
+
+// generateRegexCondition транслирует regex / not_regex в синтаксис СУБД
+// (SetRegex). Флаг (?i) в начале шаблона — сравнение без учёта регистра:
+// ~* в PostgreSQL, параметр 'i' у REGEXP_LIKE. Диалекты шаблонов у СУБД
+// различаются (POSIX ARE, ICU, POSIX ERE) — переносимы классы символов,
+// якоря, квантификаторы и альтернативы.
+func (g *SQLGenerator) generateRegexCondition(field string, filter packet.Filter) (string, error) {
+	pattern, fold := strings.CutPrefix(filter.Value, "(?i)")
+	not := filter.Operator == "not_regex"
+	switch g.regex {
+	case RegexPostgres:
+		op := "~"
+		if fold {
+			op = "~*"
+		}
+		if not {
+			op = "!" + op
+		}
+		return fmt.Sprintf("%s %s %s", field, op, quoteSQLString(pattern)), nil
+	case RegexMySQL, RegexOracle:
+		literal := quoteSQLString(pattern)
+		if g.regex == RegexMySQL {
+			literal = strings.ReplaceAll(literal, `\`, `\\`)
+		}
+		flags := "'c'"
+		if fold {
+			flags = "'i'"
+		}
+		condition := fmt.Sprintf("REGEXP_LIKE(%s, %s, %s)", field, literal, flags)
+		if not {
+			condition = "NOT " + condition
+		}
+		return condition, nil
+	default:
+		return "", fmt.Errorf("regex cannot be translated to SQL for this database")
+	}
+}
+
+// generateTruncCondition транслирует условие с усечением дат
+// (Filter.Trunc) в диапазоны над самим полем, без функций СУБД: eq
+// '2026-10-15' с Trunc month — field >= '2026-10-01' AND field <
+// '2026-11-01'. Такие условия переносимы и используют индекс по полю.
+func (g *SQLGenerator) generateTruncCondition(field string, filter packet.Filter) (string, error) {
+	bounds := func(value string) (start, next string, err error) {
+		t, err := parseDateValue(value)
+		if err != nil {
+			return "", "", err
+		}
+		return dateLiteral(truncTime(t, filter.Trunc), false), dateLiteral(nextPeriod(t, filter.Trunc), false), nil
+	}
+	inPeriod := func(value string) (string, error) {
+		start, next, err := bounds(value)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s >= %s AND %s < %s", field, start, field, next), nil
+	}
+
+	switch filter.Operator {
+	case "in", "not_in":
+		var ranges []string
+		for _, v := range strings.Split(filter.Value, ",") {
+			r, err := inPeriod(v)
+			if err != nil {
+				return "", err
+			}
+			ranges = append(ranges, "("+r+")")
+		}
+		condition := "(" + strings.Join(ranges, " OR ") + ")"
+		if filter.Operator == "not_in" {
+			condition = "NOT " + condition
+		}
+		return condition, nil
+	case "between":
+		start, _, err := bounds(filter.Value)
+		if err != nil {
+			return "", err
+		}
+		_, next, err := bounds(filter.Value2)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s >= %s AND %s < %s", field, start, field, next), nil
+	}
+
+	start, next, err := bounds(filter.Value)
+	if err != nil {
+		return "", err
+	}
+	switch filter.Operator {
+	case "eq":
+		return fmt.Sprintf("%s >= %s AND %s < %s", field, start, field, next), nil
+	case "ne":
+		return fmt.Sprintf("(%s < %s OR %s >= %s)", field, start, field, next), nil
+	case "gt":
+		return fmt.Sprintf("%s >= %s", field, next), nil
+	case "gte":
+		return fmt.Sprintf("%s >= %s", field, start), nil
+	case "lt":
+		return fmt.Sprintf("%s < %s", field, start), nil
+	case "lte":
+		return fmt.Sprintf("%s < %s", field, next), nil
+	default:
+		return "", fmt.Errorf("date_trunc is not applicable to operator %s", filter.Operator)
+	}
+}
+
+// dateLiteral — SQL-литерал границы диапазона дат: 'YYYY-MM-DD' для
+// полуночи, с временем ('YYYY-MM-DDTHH:MM:SS') — для скользящих окон.
+func dateLiteral(t time.Time, withTime bool) string {
+	if withTime {
+		return "'" + t.Format("2006-01-02T15:04:05") + "'"
+	}
+	return "'" + t.Format("2006-01-02") + "'"
+}
+
+// escapeLikePattern экранирует символы шаблона LIKE (%, _ и сам '!')
+// для LIKE ... ESCAPE '!': starts_with / ends_with ищут строку буквально.
+func escapeLikePattern(s string) string {
+	return strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(s)
+}
+
+// quoteSQLString — строковый SQL-литерал без распознавания чисел.
+func quoteSQLString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
 // escapeSQLValue экранирует значение для SQL
 func (g *SQLGenerator) escapeSQLValue(value string) string {
 	if value == "" {
@@ -307,11 +654,11 @@ func (g *SQLGenerator) generateReversedOrderByClause(orderBy *packet.OrderBy) st
 	parts := make([]string, 0, 1+len(orderBy.Fields))
 
 	if orderBy.Field != "" {
-		parts = append(parts, fmt.Sprintf("%s %s", quoteFieldName(orderBy.Field), reverseDirection(orderBy.Direction)))
+		parts = append(parts, fmt.Sprintf("%s %s", g.columnName(orderBy.Field), reverseDirection(orderBy.Direction)))
 	}
 
 	for _, field := range orderBy.Fields {
-		parts = append(parts, fmt.Sprintf("%s %s", quoteFieldName(field.Name), reverseDirection(field.Direction)))
+		parts = append(parts, fmt.Sprintf("%s %s", g.columnName(field.Name), reverseDirection(field.Direction)))
 	}
 
 	return strings.Join(parts, ", ")
@@ -331,7 +678,7 @@ func (g *SQLGenerator) generateOrderByClause(orderBy *packet.OrderBy) string {
 		if orderBy.Direction != "" {
 			direction = strings.ToUpper(orderBy.Direction)
 		}
-		parts = append(parts, fmt.Sprintf("%s %s", quoteFieldName(orderBy.Field), direction))
+		parts = append(parts, fmt.Sprintf("%s %s", g.columnName(orderBy.Field), direction))
 	}
 
 	// Множественная сортировка
@@ -340,16 +687,116 @@ func (g *SQLGenerator) generateOrderByClause(orderBy *packet.OrderBy) string {
 		if field.Direction != "" {
 			direction = strings.ToUpper(field.Direction)
 		}
-		parts = append(parts, fmt.Sprintf("%s %s", quoteFieldName(field.Name), direction))
+		parts = append(parts, fmt.Sprintf("%s %s", g.columnName(field.Name), direction))
 	}
 
 	return strings.Join(parts, ", ")
 }
 
-// CanTranslateToSQL проверяет можно ли запрос транслировать в SQL
-// (в текущей реализации можем транслировать все)
+// CanTranslateToSQL проверяет можно ли запрос транслировать в SQL.
+//
+// Явная коллация в ORDER BY (COLLATE 'ru') не транслируется: имена коллаций
+// у СУБД несовместимы между собой и с BCP 47, а смысл запроса — получить
+// порядок, не зависящий от настроек конкретной базы. Такой запрос
+// сортируется в памяти executor'ом.
+//
+// То же касается Filter.Cast к DATETIME/TIMESTAMP/TEXT: имена этих типов в
+// CAST различаются между СУБД, поэтому такие условия фильтруются в памяти,
+// и коллации фильтров с тегом языка (Filter.Collation "ru"); ci и trim
+// транслируются в LOWER и LTRIM(RTRIM) (ci с не-ASCII значением при
+// SetASCIILower — в память).
+//
+// Запрос с Join и отрицательным Limit (tail) не транслируется: внешний
+// запрос обёртки не видит квалифицированных имён соединения.
+//
+// Операторы regex / not_regex транслируются только с SetRegex (СУБД с
+// регулярными выражениями); коллация к ним не применяется.
 func (g *SQLGenerator) CanTranslateToSQL(query *packet.Query) bool {
-	// В будущем здесь может быть более сложная логика
-	// Например, если добавим операторы которые нельзя транслировать
+	if query == nil {
+		return true
+	}
+	if query.OrderBy.HasCollation() {
+		return false
+	}
+	if query.Join != nil && query.Limit < 0 {
+		return false
+	}
+	if query.Filters != nil {
+		return collationTranslatable(query.Filters.Collation) &&
+			g.filtersTranslatable(query.Filters.And, query.Filters.Collation) &&
+			g.filtersTranslatable(query.Filters.Or, query.Filters.Collation)
+	}
+	return true
+}
+
+// filtersTranslatable проверяет, что все Cast, Collation и regex в группе
+// переносимы в SQL. collation — коллация запроса (Filters.Collation) для
+// условий без своей.
+func (g *SQLGenerator) filtersTranslatable(group *packet.LogicalGroup, collation string) bool {
+	if group == nil {
+		return true
+	}
+	for _, f := range group.Filters {
+		if f.Operator == "regex" || f.Operator == "not_regex" {
+			if g.regex == "" {
+				return false
+			}
+		} else if !g.filterCollationTranslatable(f, collation) {
+			return false
+		}
+		if f.Cast == "" {
+			continue
+		}
+		if _, ok := sqlCastType(f.Cast); !ok {
+			return false
+		}
+	}
+	for i := range group.And {
+		if !g.filtersTranslatable(&group.And[i], collation) {
+			return false
+		}
+	}
+	for i := range group.Or {
+		if !g.filtersTranslatable(&group.Or[i], collation) {
+			return false
+		}
+	}
+	return true
+}
+
+// filterCollationTranslatable — коллация условия (своя или запроса)
+// переносима в SQL: без тега языка, а ci при SetASCIILower — только для
+// ASCII-значений, которые LOWER СУБД и strings.ToLower приводят одинаково.
+func (g *SQLGenerator) filterCollationTranslatable(f packet.Filter, collation string) bool {
+	spec := f.Collation
+	if spec == "" {
+		spec = collation
+	}
+	if !collationTranslatable(spec) {
+		return false
+	}
+	if !g.ascii || spec == "" {
+		return true
+	}
+	c, _ := parseFilterCollation(spec)
+	return !c.fold || (isASCII(f.Value) && isASCII(f.Value2))
+}
+
+// isASCII сообщает, что строка состоит только из ASCII-символов.
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
 	return true
 }
+
+// collationTranslatable — коллация фильтра без тега языка (ci, trim).
+func collationTranslatable(spec string) bool {
+	if spec == "" {
+		return true
+	}
+	c, err := parseFilterCollation(spec)
+	return err == nil && c.tag == ""
+}
@@ -3,6 +3,7 @@ package tdtql
 import (
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
 )
@@ -532,3 +533,192 @@ func TestSQLGenerator_BracketTableName(t *testing.T) {
 		})
 	}
 }
+
+func TestSQLGenerator_CollationNotPushedDown(t *testing.T) {
+	generator := NewSQLGenerator()
+	translator := NewTranslator()
+
+	query, err := translator.Translate("SELECT * FROM Users ORDER BY name COLLATE 'ru'")
+	if err != nil {
+		t.Fatalf("Translation failed: %v", err)
+	}
+	if query.OrderBy == nil || query.OrderBy.Collation != "ru" {
+		t.Fatalf("expected collation to reach packet.OrderBy, got %+v", query.OrderBy)
+	}
+	if generator.CanTranslateToSQL(query) {
+		t.Error("query with explicit collation must not be pushed down to SQL")
+	}
+
+	plain, err := translator.Translate("SELECT * FROM Users ORDER BY name")
+	if err != nil {
+		t.Fatalf("Translation failed: %v", err)
+	}
+	if !generator.CanTranslateToSQL(plain) {
+		t.Error("query without collation should be pushed down to SQL")
+	}
+}
+
+func TestSQLGenerator_Cast(t *testing.T) {
+	generator := NewSQLGenerator()
+	translator := NewTranslator()
+
+	query, err := translator.Translate("SELECT * FROM Accounts WHERE CAST(Balance AS REAL) > 1000")
+	if err != nil {
+		t.Fatalf("Translation failed: %v", err)
+	}
+	if !generator.CanTranslateToSQL(query) {
+		t.Fatal("numeric cast should be pushed down to SQL")
+	}
+	sql, err := generator.GenerateSQL("Accounts", query)
+	if err != nil {
+		t.Fatalf("GenerateSQL failed: %v", err)
+	}
+	if !strings.Contains(sql, "CAST(Balance AS DECIMAL(38,10)) > 1000") {
+		t.Errorf("expected CAST in WHERE, got: %s", sql)
+	}
+
+	tsQuery, err := translator.Translate("SELECT * FROM Accounts WHERE CAST(Opened AS TIMESTAMP) > '2024-01-01T00:00:00Z'")
+	if err != nil {
+		t.Fatalf("Translation failed: %v", err)
+	}
+	if generator.CanTranslateToSQL(tsQuery) {
+		t.Error("timestamp cast is not portable and must be filtered in memory")
+	}
+}
+
+func TestSQLGenerator_FilterCollation(t *testing.T) {
+	translator := NewTranslator()
+
+	query, err := translator.Translate("SELECT * FROM Users WHERE email = 'Ann@Mail.ru ' COLLATE 'ci,trim' AND city IN ('Москва', 'Казань') COLLATE ci AND name ILIKE 'ann%'")
+	if err != nil {
+		t.Fatalf("Translation failed: %v", err)
+	}
+	generator := NewSQLGenerator()
+	if !generator.CanTranslateToSQL(query) {
+		t.Fatal("ci/trim collation should be pushed down to SQL")
+	}
+	sql, err := generator.GenerateSQL("Users", query)
+	if err != nil {
+		t.Fatalf("GenerateSQL failed: %v", err)
+	}
+	for _, want := range []string{
+		"LOWER(LTRIM(RTRIM(email))) = LOWER('Ann@Mail.ru')",
+		"LOWER(city) IN (LOWER('Москва'), LOWER('Казань'))",
+		"LOWER(name) LIKE LOWER('ann%')",
+	} {
+		if !strings.Contains(sql, want) {
+			t.Errorf("expected %q in SQL, got: %s", want, sql)
+		}
+	}
+
+	generator.SetILike(true)
+	sql, err = generator.GenerateSQL("Users", query)
+	if err != nil {
+		t.Fatalf("GenerateSQL failed: %v", err)
+	}
+	if !strings.Contains(sql, "name ILIKE 'ann%'") || !strings.Contains(sql, "LOWER(city) IN") {
+		t.Errorf("expected ILIKE for LIKE and LOWER for IN, got: %s", sql)
+	}
+
+	locale, err := translator.Translate("SELECT * FROM Users WHERE city = 'Ёлки' COLLATE 'ru,ci'")
+	if err != nil {
+		t.Fatalf("Translation failed: %v", err)
+	}
+	if generator.CanTranslateToSQL(locale) {
+		t.Error("language collation is not portable and must be filtered in memory")
+	}
+}
+
+func TestSQLGenerator_RegexAndDateFunctions(t *testing.T) {
+	defer func(now func() time.Time) { timeNow = now }(timeNow)
+	timeNow = func() time.Time { return time.Date(2026, 10, 16, 14, 30, 0, 0, time.UTC) }
+
+	translator := NewTranslator()
+	query, err := translator.Translate("SELECT * FROM Orders WHERE sku STARTS WITH '10%_a!' AND email ENDS WITH '@corp.ru' COLLATE ci AND CreatedAt LAST 7 DAYS AND UpdatedAt LAST 6 HOURS AND DATE_TRUNC('month', ShippedAt) = '2026-10-15' AND DATE_TRUNC('month', PaidAt) > '2026-09-03' AND DATE_TRUNC('day', DueAt) IN ('2026-10-01', '2026-10-03')")
+	if err != nil {
+		t.Fatalf("Translation failed: %v", err)
+	}
+	generator := NewSQLGenerator()
+	if !generator.CanTranslateToSQL(query) {
+		t.Fatal("affix and date operators should be pushed down to SQL")
+	}
+	sql, err := generator.GenerateSQL("Orders", query)
+	if err != nil {
+		t.Fatalf("GenerateSQL failed: %v", err)
+	}
+	for _, want := range []string{
+		"sku LIKE '10!%!_a!!%' ESCAPE '!'",
+		"LOWER(email) LIKE LOWER('%@corp.ru') ESCAPE '!'",
+		"CreatedAt >= '2026-10-10' AND CreatedAt < '2026-10-17'",
+		"UpdatedAt >= '2026-10-16T08:30:00' AND UpdatedAt < '2026-10-16T14:30:00'",
+		"ShippedAt >= '2026-10-01' AND ShippedAt < '2026-11-01'",
+		"PaidAt >= '2026-10-01'",
+		"((DueAt >= '2026-10-01' AND DueAt < '2026-10-02') OR (DueAt >= '2026-10-03' AND DueAt < '2026-10-04'))",
+	} {
+		if !strings.Contains(sql, want) {
+			t.Errorf("expected %q in SQL, got: %s", want, sql)
+		}
+	}
+
+	regex, err := translator.Translate("SELECT * FROM Users WHERE code REGEXP '(?i)^a\\d' AND name NOT REGEXP \"it's\"")
+	if err != nil {
+		t.Fatalf("Translation failed: %v", err)
+	}
+	if generator.CanTranslateToSQL(regex) {
+		t.Error("regex without a database dialect must be filtered in memory")
+	}
+	for dialect, want := range map[RegexDialect][]string{
+		RegexPostgres: {`code ~* '^a\d'`, `name !~ 'it''s'`},
+		RegexMySQL:    {`REGEXP_LIKE(code, '^a\\d', 'i')`, `NOT REGEXP_LIKE(name, 'it''s', 'c')`},
+		RegexOracle:   {`REGEXP_LIKE(code, '^a\d', 'i')`, `NOT REGEXP_LIKE(name, 'it''s', 'c')`},
+	} {
+		generator.SetRegex(dialect)
+		if !generator.CanTranslateToSQL(regex) {
+			t.Fatalf("%s: regex should be pushed down", dialect)
+		}
+		sql, err := generator.GenerateSQL("Users", regex)
+		if err != nil {
+			t.Fatalf("%s: GenerateSQL failed: %v", dialect, err)
+		}
+		for _, w := range want {
+			if !strings.Contains(sql, w) {
+				t.Errorf("%s: expected %q in SQL, got: %s", dialect, w, sql)
+			}
+		}
+	}
+}
+
+func TestSQLGenerator_ASCIILowerKeepsUnicodeCIInMemory(t *testing.T) {
+	translator := NewTranslator()
+	generator := NewSQLGenerator()
+	generator.SetASCIILower(true)
+
+	for input, want := range map[string]bool{
+		"SELECT * FROM Users WHERE email = 'Ann@Mail.ru' COLLATE ci":       true,
+		"SELECT * FROM Users WHERE city = 'Москва' COLLATE ci":             false,
+		"SELECT * FROM Users WHERE city IN ('Kazan', 'Москва') COLLATE ci": false,
+		"SELECT * FROM Users WHERE city = 'Москва' COLLATE trim":           true,
+		"SELECT * FROM Users WHERE city = 'Москва'":                        true,
+	} {
+		query, err := translator.Translate(input)
+		if err != nil {
+			t.Fatalf("%s: %v", input, err)
+		}
+		if got := generator.CanTranslateToSQL(query); got != want {
+			t.Errorf("%s: CanTranslateToSQL = %v, want %v", input, got, want)
+		}
+	}
+
+	// Коллация запроса действует на условия без своей
+	query := packet.NewQuery()
+	query.Filters = &packet.Filters{
+		Collation: "ci",
+		And:       &packet.LogicalGroup{Filters: []packet.Filter{{Field: "city", Operator: "eq", Value: "МОСКВА"}}},
+	}
+	if generator.CanTranslateToSQL(query) {
+		t.Error("query-level ci with a non-ASCII value must be filtered in memory")
+	}
+	if !NewSQLGenerator().CanTranslateToSQL(query) {
+		t.Error("Unicode LOWER (default) should push ci down")
+	}
+}
@@ -361,3 +361,29 @@ func TestTranslatorFullQuery(t *testing.T) {
 		t.Error("OFFSET incorrect")
 	}
 }
+
+func TestTranslatorFields(t *testing.T) {
+	translator := NewTranslator()
+
+	query, err := translator.Translate("SELECT Name, CustTable.Balance FROM CustTable WHERE City = 'Omsk' ORDER BY Age")
+	if err != nil {
+		t.Fatalf("translate error: %v", err)
+	}
+	if len(query.Fields) != 2 || query.Fields[0] != "Name" || query.Fields[1] != "Balance" {
+		t.Errorf("Fields = %v, want [Name Balance]", query.Fields)
+	}
+
+	if query, err = translator.Translate("SELECT * FROM CustTable"); err != nil || query.Fields != nil {
+		t.Errorf("SELECT *: Fields = %v, err = %v", query.Fields, err)
+	}
+
+	for _, sql := range []string{
+		"SELECT Name, FROM CustTable",
+		"SELECT Name, * FROM CustTable",
+		"SELECT FROM CustTable",
+	} {
+		if _, err := translator.Translate(sql); err == nil {
+			t.Errorf("%s: expected error", sql)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package crypto
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Шифрование отдельных значений колонок (encryption at rest).
+//
+// Формат значения:
+//
+//	enc:v1:<key_id>:base64(nonce || ciphertext || tag)
+//
+// key_id хранится в самом значении: после ротации ключа старые строки
+// остаются читаемыми, пока ключ есть в keyring. Версия и key_id входят в
+// AAD — подмена префикса ломает аутентификацию.
+//
+// Формат отличает шифртекст от текста лишь структурно (IsEncryptedValue);
+// окончательно значение зашифровано, только если сходится тег GCM
+// (DecryptColumnValue с ключом key_id).
+
+// ColumnAlgorithm — алгоритм шифрования значений колонок.
+const ColumnAlgorithm = "aes-256-gcm"
+
+const (
+	columnValueVersion = "v1"
+	columnValuePrefix  = "enc:" + columnValueVersion + ":"
+
+	// Размеры nonce и тега AES-GCM (cipher.NewGCM)
+	columnNonceSize = 12
+	columnTagSize   = 16
+)
+
+// ErrKeyNotFound — ключа с таким key_id у потребителя нет (не авторизован).
+var ErrKeyNotFound = errors.New("column key not found")
+
+// KeyProvider выдаёт ключи шифрования колонок по key_id.
+//
+// xZMercury здесь не подходит напрямую: его ключи одноразовые
+// (burn-on-read), а данные в целевой БД должны читаться годами.
+// Долгоживущие ключи берутся из конфигурации или файла keyring.
+type KeyProvider interface {
+	ColumnKey(keyID string) ([]byte, error)
+}
+
+// StaticKeys — ключи в памяти: key_id → 32-байтный ключ.
+type StaticKeys map[string][]byte
+
+// ColumnKey реализует KeyProvider.
+func (k StaticKeys) ColumnKey(keyID string) ([]byte, error) {
+	key, ok := k[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrKeyNotFound, keyID)
+	}
+	return key, nil
+}
+
+// ParseKey разбирает ключ из конфигурации: base64 (44 символа), hex
+// (64 символа) или "env:NAME" — значение переменной окружения в тех же
+// форматах.
+func ParseKey(s string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	if name, ok := strings.CutPrefix(s, "env:"); ok {
+		s = strings.TrimSpace(os.Getenv(name))
+		if s == "" {
+			return nil, fmt.Errorf("environment variable %s is empty", name)
+		}
+	}
+	if len(s) == 64 {
+		if key, err := hex.DecodeString(s); err == nil {
+			return key, nil
+		}
+	}
+	key, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("key must be base64 or hex: %w", err)
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("key must be 32 bytes, got %d", len(key))
+	}
+	return key, nil
+}
+
+// LoadKeyring читает файл keyring: строки "key_id = key" (формат key — как
+// в ParseKey), пустые строки и комментарии "#" пропускаются.
+func LoadKeyring(path string) (StaticKeys, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open keyring: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	keys := make(StaticKeys)
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		id, value, ok := strings.Cut(text, "=")
+		id = strings.TrimSpace(id)
+		if !ok || id == "" {
+			return nil, fmt.Errorf("keyring %s:%d: expected key_id = key", path, line)
+		}
+		key, err := ParseKey(value)
+		if err != nil {
+			return nil, fmt.Errorf("keyring %s:%d: %w", path, line, err)
+		}
+		keys[id] = key
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read keyring: %w", err)
+	}
+	return keys, nil
+}
+
+// IsEncryptedValue сообщает, имеет ли значение формат EncryptColumnValue:
+// префикс версии, key_id и base64 не короче nonce и тега GCM. Подлинность
+// без ключа не проверить — её подтверждает DecryptColumnValue.
+func IsEncryptedValue(value string) bool {
+	_, _, ok := splitColumnValue(value)
+	return ok
+}
+
+// EncryptColumnValue шифрует значение ключом keyID.
+func EncryptColumnValue(keys KeyProvider, keyID, plaintext string) (string, error) {
+	if keyID == "" || strings.Contains(keyID, ":") {
+		return "", fmt.Errorf("invalid column key id %q", keyID)
+	}
+	key, err := keys.ColumnKey(keyID)
+	if err != nil {
+		return "", err
+	}
+	gcm, err := newColumnGCM(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", fmt.Errorf("encrypt column: generate nonce: %w", err)
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(plaintext), columnAAD(keyID))
+	return columnValuePrefix + keyID + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// DecryptColumnValue расшифровывает значение; key_id берётся из значения.
+// Без ключа возвращает ошибку, оборачивающую ErrKeyNotFound.
+func DecryptColumnValue(keys KeyProvider, value string) (plaintext, keyID string, err error) {
+	keyID, raw, ok := splitColumnValue(value)
+	if !ok {
+		return "", "", fmt.Errorf("decrypt column: value is not encrypted")
+	}
+	key, err := keys.ColumnKey(keyID)
+	if err != nil {
+		return "", keyID, err
+	}
+	gcm, err := newColumnGCM(key)
+	if err != nil {
+		return "", keyID, err
+	}
+	out, err := gcm.Open(nil, raw[:columnNonceSize], raw[columnNonceSize:], columnAAD(keyID))
+	if err != nil {
+		return "", keyID, fmt.Errorf("decrypt column: authentication failed (wrong key or corrupted data): %w", err)
+	}
+	return string(out), keyID, nil
+}
+
+// splitColumnValue разбирает значение формата v1: key_id и nonce || ciphertext || tag.
+func splitColumnValue(value string) (keyID string, raw []byte, ok bool) {
+	rest, ok := strings.CutPrefix(value, columnValuePrefix)
+	if !ok {
+		return "", nil, false
+	}
+	keyID, encoded, ok := strings.Cut(rest, ":")
+	if !ok || keyID == "" {
+		return "", nil, false
+	}
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(raw) < columnNonceSize+columnTagSize {
+		return "", nil, false
+	}
+	return keyID, raw, true
+}
+
+// columnAAD — дополнительные данные GCM: версия формата и key_id.
+func columnAAD(keyID string) []byte {
+	return []byte(columnValueVersion + ":" + keyID)
+}
+
+func newColumnGCM(key []byte) (cipher.AEAD, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("column key must be 32 bytes, got %d", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("create cipher: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("create GCM: %w", err)
+	}
+	return gcm, nil
+}
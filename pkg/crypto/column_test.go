@@ -0,0 +1,87 @@
+package crypto
+
+import (
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testColumnKeys() StaticKeys {
+	key := make([]byte, 32)
+	for i := range key {
+		key[i] = byte(i + 7)
+	}
+	return StaticKeys{"pii-2025": key}
+}
+
+func TestColumnValue_RoundTrip(t *testing.T) {
+	keys := testColumnKeys()
+	enc, err := EncryptColumnValue(keys, "pii-2025", "123-45-6789")
+	if err != nil {
+		t.Fatalf("EncryptColumnValue() error = %v", err)
+	}
+	if !strings.HasPrefix(enc, "enc:v1:pii-2025:") || !IsEncryptedValue(enc) {
+		t.Fatalf("unexpected format: %s", enc)
+	}
+	again, _ := EncryptColumnValue(keys, "pii-2025", "123-45-6789")
+	if again == enc {
+		t.Error("same plaintext must give different ciphertext (random nonce)")
+	}
+
+	plain, keyID, err := DecryptColumnValue(keys, enc)
+	if err != nil || plain != "123-45-6789" || keyID != "pii-2025" {
+		t.Fatalf("DecryptColumnValue() = %q, %q, %v", plain, keyID, err)
+	}
+
+	// Подмена key_id в префиксе ломает аутентификацию (AAD).
+	keys["other"] = keys["pii-2025"]
+	if _, _, err := DecryptColumnValue(keys, strings.Replace(enc, "pii-2025", "other", 1)); err == nil {
+		t.Error("expected authentication error for swapped key id")
+	}
+
+	if _, _, err := DecryptColumnValue(StaticKeys{}, enc); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("expected ErrKeyNotFound, got %v", err)
+	}
+	// Текст, похожий на шифртекст, форматом v1 не является.
+	for _, v := range []string{"enc:", "plain", "enc:pii-2025:abc", "enc:v1:pii-2025:abc", "enc:v1:pii-2025:" + base64.StdEncoding.EncodeToString([]byte("short")), "enc:v1::" + enc[len("enc:v1:pii-2025:"):]} {
+		if IsEncryptedValue(v) {
+			t.Errorf("IsEncryptedValue(%q) = true", v)
+		}
+	}
+	// Формат v1 без верного тега не расшифровывается.
+	forged := "enc:v1:pii-2025:" + base64.StdEncoding.EncodeToString(make([]byte, 40))
+	if !IsEncryptedValue(forged) {
+		t.Fatal("forged value must pass the format check")
+	}
+	if _, _, err := DecryptColumnValue(keys, forged); err == nil || errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("expected authentication error for forged value, got %v", err)
+	}
+}
+
+func TestLoadKeyring(t *testing.T) {
+	key := base64.StdEncoding.EncodeToString(make([]byte, 32))
+	t.Setenv("TDTP_TEST_COLUMN_KEY", strings.Repeat("ab", 32))
+
+	path := filepath.Join(t.TempDir(), "keyring")
+	content := "# column keys\npii-2024 = " + key + "\n\npii-2025 = env:TDTP_TEST_COLUMN_KEY\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	keys, err := LoadKeyring(path)
+	if err != nil {
+		t.Fatalf("LoadKeyring() error = %v", err)
+	}
+	if len(keys) != 2 || len(keys["pii-2025"]) != 32 || keys["pii-2025"][0] != 0xab {
+		t.Errorf("unexpected keyring: %v", keys)
+	}
+
+	if err := os.WriteFile(path, []byte("pii = c2hvcnQ=\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadKeyring(path); err == nil {
+		t.Error("expected error for short key")
+	}
+}
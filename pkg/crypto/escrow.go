@@ -0,0 +1,163 @@
+package crypto
+
+// Депонирование ключей (key escrow).
+//
+// xZMercury выдаёт ключ пакета один раз (burn-on-read) и стирает его по TTL,
+// поэтому архивный зашифрованный пакет без депонирования прочитать нельзя.
+// В режиме депонирования ключ пакета дополнительно оборачивается открытым
+// RSA-ключом депозитария (RSA-OAEP-SHA256) и кладётся в открытый Header
+// пакета (packet.KeyEscrow). Закрытый ключ депозитария хранится офлайн у
+// службы безопасности и восстанавливает ключ пакета без xZMercury
+// (tdtpcli --decrypt-archive).
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"os"
+)
+
+// EscrowAlgoRSAOAEP — алгоритм обёртки ключа пакета (KeyEscrow algo="...").
+const EscrowAlgoRSAOAEP = "rsa-oaep-sha256"
+
+// escrowLabel — метка OAEP: обёрнутый ключ нельзя выдать за шифртекст
+// другого протокола с тем же RSA-ключом.
+var escrowLabel = []byte("tdtp-key-escrow")
+
+// minEscrowBits — минимальный размер RSA-ключа депозитария.
+const minEscrowBits = 2048
+
+// EscrowKey — открытый ключ депозитария, которым оборачиваются ключи пакетов.
+type EscrowKey struct {
+	pub *rsa.PublicKey
+	ID  string // отпечаток открытого ключа (EscrowKeyID)
+}
+
+// EscrowPrivateKey — закрытый ключ депозитария для восстановления ключей.
+type EscrowPrivateKey struct {
+	priv *rsa.PrivateKey
+	ID   string // отпечаток соответствующего открытого ключа
+}
+
+// LoadEscrowKey читает открытый ключ депозитария из PEM-файла
+// ("PUBLIC KEY", PKIX): openssl rsa -in escrow.pem -pubout -out escrow.pub.pem.
+func LoadEscrowKey(path string) (*EscrowKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("escrow: read public key: %w", err)
+	}
+	return ParseEscrowKey(data)
+}
+
+// ParseEscrowKey разбирает открытый ключ депозитария в PEM.
+func ParseEscrowKey(pemData []byte) (*EscrowKey, error) {
+	block, _ := pem.Decode(pemData)
+	if block == nil || block.Type != "PUBLIC KEY" {
+		return nil, fmt.Errorf("escrow: expected PEM \"PUBLIC KEY\" block")
+	}
+	parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("escrow: parse public key: %w", err)
+	}
+	pub, ok := parsed.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("escrow: public key must be RSA, got %T", parsed)
+	}
+	if pub.N.BitLen() < minEscrowBits {
+		return nil, fmt.Errorf("escrow: RSA key must be at least %d bits, got %d", minEscrowBits, pub.N.BitLen())
+	}
+	id, err := EscrowKeyID(pub)
+	if err != nil {
+		return nil, err
+	}
+	return &EscrowKey{pub: pub, ID: id}, nil
+}
+
+// LoadEscrowPrivateKey читает закрытый ключ депозитария из PEM-файла
+// ("PRIVATE KEY" PKCS#8 или "RSA PRIVATE KEY" PKCS#1).
+func LoadEscrowPrivateKey(path string) (*EscrowPrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("escrow: read private key: %w", err)
+	}
+	return ParseEscrowPrivateKey(data)
+}
+
+// ParseEscrowPrivateKey разбирает закрытый ключ депозитария в PEM.
+func ParseEscrowPrivateKey(pemData []byte) (*EscrowPrivateKey, error) {
+	block, _ := pem.Decode(pemData)
+	if block == nil {
+		return nil, fmt.Errorf("escrow: no PEM block in private key")
+	}
+	var priv *rsa.PrivateKey
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		k, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("escrow: parse private key: %w", err)
+		}
+		priv = k
+	case "PRIVATE KEY":
+		parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("escrow: parse private key: %w", err)
+		}
+		k, ok := parsed.(*rsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("escrow: private key must be RSA, got %T", parsed)
+		}
+		priv = k
+	default:
+		return nil, fmt.Errorf("escrow: unsupported PEM block %q", block.Type)
+	}
+	id, err := EscrowKeyID(&priv.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	return &EscrowPrivateKey{priv: priv, ID: id}, nil
+}
+
+// EscrowKeyID — отпечаток открытого ключа депозитария: первые 8 байт
+// SHA-256 от PKIX DER в hex. Пишется в пакет рядом с обёрнутым ключом,
+// чтобы после смены ключа депозитария было видно, каким ключом открывать.
+func EscrowKeyID(pub *rsa.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return "", fmt.Errorf("escrow: marshal public key: %w", err)
+	}
+	sum := sha256.Sum256(der)
+	return hex.EncodeToString(sum[:8]), nil
+}
+
+// Wrap оборачивает 32-байтный ключ пакета и возвращает его в base64.
+func (e *EscrowKey) Wrap(key []byte) (string, error) {
+	if len(key) != 32 {
+		return "", fmt.Errorf("escrow: key must be 32 bytes, got %d", len(key))
+	}
+	wrapped, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, e.pub, key, escrowLabel)
+	if err != nil {
+		return "", fmt.Errorf("escrow: wrap key: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(wrapped), nil
+}
+
+// Unwrap восстанавливает ключ пакета из результата Wrap.
+func (e *EscrowPrivateKey) Unwrap(wrapped string) ([]byte, error) {
+	raw, err := base64.StdEncoding.DecodeString(wrapped)
+	if err != nil {
+		return nil, fmt.Errorf("escrow: invalid base64: %w", err)
+	}
+	key, err := rsa.DecryptOAEP(sha256.New(), nil, e.priv, raw, escrowLabel)
+	if err != nil {
+		return nil, fmt.Errorf("escrow: unwrap key (wrong escrow key?): %w", err)
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("escrow: unwrapped key must be 32 bytes, got %d", len(key))
+	}
+	return key, nil
+}
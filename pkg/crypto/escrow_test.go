@@ -0,0 +1,89 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+)
+
+// newEscrowPair генерирует ключ депозитария и возвращает открытый ключ в PEM
+// и закрытый в PKCS#8 PEM.
+func newEscrowPair(t *testing.T, bits int) (pubPEM, privPEM []byte) {
+	t.Helper()
+	priv, err := rsa.GenerateKey(rand.Reader, bits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubDER, err := x509.MarshalPKIXPublicKey(&priv.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privDER, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}),
+		pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privDER})
+}
+
+func TestEscrow_WrapUnwrap(t *testing.T) {
+	pubPEM, privPEM := newEscrowPair(t, 2048)
+	pub, err := ParseEscrowKey(pubPEM)
+	if err != nil {
+		t.Fatalf("ParseEscrowKey() error = %v", err)
+	}
+	priv, err := ParseEscrowPrivateKey(privPEM)
+	if err != nil {
+		t.Fatalf("ParseEscrowPrivateKey() error = %v", err)
+	}
+	if pub.ID != priv.ID || len(pub.ID) != 16 {
+		t.Fatalf("key IDs: public %q, private %q", pub.ID, priv.ID)
+	}
+
+	key := bytes.Repeat([]byte{7}, 32)
+	wrapped, err := pub.Wrap(key)
+	if err != nil {
+		t.Fatalf("Wrap() error = %v", err)
+	}
+	got, err := priv.Unwrap(wrapped)
+	if err != nil {
+		t.Fatalf("Unwrap() error = %v", err)
+	}
+	if !bytes.Equal(got, key) {
+		t.Error("Unwrap() key mismatch")
+	}
+}
+
+func TestEscrow_WrongPrivateKey(t *testing.T) {
+	pubPEM, _ := newEscrowPair(t, 2048)
+	_, otherPEM := newEscrowPair(t, 2048)
+	pub, _ := ParseEscrowKey(pubPEM)
+	other, _ := ParseEscrowPrivateKey(otherPEM)
+
+	wrapped, err := pub.Wrap(make([]byte, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := other.Unwrap(wrapped); err == nil {
+		t.Error("Unwrap() with another escrow key: expected error")
+	}
+}
+
+func TestEscrow_InvalidKeys(t *testing.T) {
+	weakPEM, privPEM := newEscrowPair(t, 1024)
+	if _, err := ParseEscrowKey(weakPEM); err == nil {
+		t.Error("ParseEscrowKey() 1024-bit key: expected error")
+	}
+	if _, err := ParseEscrowKey(privPEM); err == nil {
+		t.Error("ParseEscrowKey() private key PEM: expected error")
+	}
+
+	strongPEM, _ := newEscrowPair(t, 2048)
+	pub, _ := ParseEscrowKey(strongPEM)
+	if _, err := pub.Wrap(make([]byte, 16)); err == nil {
+		t.Error("Wrap() 16-byte key: expected error")
+	}
+}
@@ -4,6 +4,7 @@ package diff
 import (
 	"encoding/json"
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
@@ -11,11 +12,12 @@ import (
 
 // DiffResult представляет результат сравнения двух TDTP пакетов
 type DiffResult struct {
-	Added    [][]string    // Добавленные строки (есть в B, нет в A)
-	Removed  [][]string    // Удалённые строки (есть в A, нет в B)
-	Modified []ModifiedRow // Изменённые строки
-	Stats    DiffStats     // Статистика
-	Schema   packet.Schema // Схема данных
+	Added     [][]string    // Добавленные строки (есть в B, нет в A)
+	Removed   [][]string    // Удалённые строки (есть в A, нет в B)
+	Modified  []ModifiedRow // Изменённые строки
+	Stats     DiffStats     // Статистика
+	Schema    packet.Schema // Схема данных
+	KeyFields []string      // Ключевые поля, по которым сопоставлялись строки
 }
 
 // ModifiedRow представляет изменённую строку
@@ -112,7 +114,8 @@ func (d *Differ) Compare(packetA, packetB *packet.DataPacket) (*DiffResult, erro
 	mapB := d.buildRowMap(rowsB, keyIndices)
 
 	result := &DiffResult{
-		Schema: packetA.Schema,
+		Schema:    packetA.Schema,
+		KeyFields: keyFields,
 		Stats: DiffStats{
 			TotalInA: len(rowsA),
 			TotalInB: len(rowsB),
@@ -240,6 +243,42 @@ func (d *Differ) contains(slice []int, val int) bool {
 	return false
 }
 
+// ToDeltaPacket строит delta-пакет из изменённых строк: ключ и изменённые
+// поля со старыми и новыми значениями (packet.NewDeltaPacket). Добавленные
+// и удалённые строки в delta-пакет не попадают — их переносит обычный пакет.
+// Ключевыми в схеме пакета становятся KeyFields сравнения.
+func (r *DiffResult) ToDeltaPacket(tableName string) (*packet.DataPacket, error) {
+	schema := packet.Schema{Fields: make([]packet.Field, len(r.Schema.Fields))}
+	for i, f := range r.Schema.Fields {
+		f.Key = slices.ContainsFunc(r.KeyFields, func(k string) bool { return strings.EqualFold(k, f.Name) })
+		schema.Fields[i] = f
+	}
+
+	modified := slices.Clone(r.Modified)
+	slices.SortFunc(modified, func(a, b ModifiedRow) int { return strings.Compare(a.Key, b.Key) })
+
+	rows := make([]packet.DeltaRow, 0, len(modified))
+	for _, m := range modified {
+		var dr packet.DeltaRow
+		for i, f := range schema.Fields {
+			if f.Key && i < len(m.NewRow) {
+				dr.Key = append(dr.Key, m.NewRow[i])
+			}
+		}
+		indices := make([]int, 0, len(m.Changes))
+		for i := range m.Changes {
+			indices = append(indices, i)
+		}
+		slices.Sort(indices)
+		for _, i := range indices {
+			c := m.Changes[i]
+			dr.Changes = append(dr.Changes, packet.DeltaChange{Field: c.FieldName, Old: c.OldValue, New: c.NewValue})
+		}
+		rows = append(rows, dr)
+	}
+	return packet.NewDeltaPacket(tableName, schema, rows)
+}
+
 // FormatText форматирует результат в текстовый вид
 func (r *DiffResult) FormatText() string {
 	var sb strings.Builder
@@ -374,3 +374,44 @@ func findSubstring(s, substr string) bool {
 	}
 	return false
 }
+
+func TestDiffResult_ToDeltaPacket(t *testing.T) {
+	fields := []string{"id", "name", "email"}
+	packetA := createTestPacket("users", fields, [][]string{
+		{"1", "Alice", "alice@example.com"},
+		{"2", "Bob", "bob@example.com"},
+		{"3", "Carol", "carol@example.com"},
+	})
+	packetB := createTestPacket("users", fields, [][]string{
+		{"1", "Alice", "alice@example.org"},
+		{"2", "Robert", "bob@example.org"},
+		{"4", "Dave", "dave@example.com"},
+	})
+
+	result, err := NewDiffer(DiffOptions{KeyFields: []string{"id"}}).Compare(packetA, packetB)
+	if err != nil {
+		t.Fatalf("Compare failed: %v", err)
+	}
+
+	pkt, err := result.ToDeltaPacket("users")
+	if err != nil {
+		t.Fatalf("ToDeltaPacket failed: %v", err)
+	}
+	if !pkt.Data.Delta || !pkt.Schema.Fields[0].Key {
+		t.Fatal("expected delta packet with id as key")
+	}
+
+	rows, err := pkt.DeltaRows()
+	if err != nil {
+		t.Fatalf("DeltaRows failed: %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("Expected 2 delta rows (added/removed excluded), got %d", len(rows))
+	}
+	if rows[0].Key[0] != "1" || len(rows[0].Changes) != 1 || rows[0].Changes[0].New != "alice@example.org" {
+		t.Errorf("Unexpected first row: %+v", rows[0])
+	}
+	if rows[1].Key[0] != "2" || len(rows[1].Changes) != 2 || rows[1].Changes[0].Field != "name" {
+		t.Errorf("Unexpected second row: %+v", rows[1])
+	}
+}
@@ -0,0 +1,194 @@
+package etl
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ruslano69/tdtp-framework/pkg/brokers"
+)
+
+// Backpressure потокового экспорта в брокер.
+//
+// Экспорт без обратной связи публикует части быстрее, чем потребитель их
+// разбирает: очередь RabbitMQ растёт, пока у брокера не кончится память.
+// С output.backpressure экспорт опрашивает глубину очереди
+// (brokers.DepthReporter) перед отправкой части: от slow_depth вставляет
+// задержку, растущую к pause_depth, а с pause_depth останавливается, пока
+// глубина не опустится до resume_depth. Ошибка опроса не тормозит экспорт —
+// брокер без метрик не должен останавливать выгрузку.
+
+const (
+	defaultBackpressurePoll     = time.Second
+	defaultBackpressureMaxDelay = time.Second
+	backpressurePollTimeout     = 10 * time.Second
+)
+
+// BackpressureConfig — пороги глубины очереди для потокового экспорта в
+// rabbitmq, kafka (lag consumer group) и filequeue.
+type BackpressureConfig struct {
+	SlowDepth      int64 `yaml:"slow_depth"`       // с этой глубины отправка замедляется (0 — без замедления)
+	PauseDepth     int64 `yaml:"pause_depth"`      // с этой глубины отправка останавливается (обязателен)
+	ResumeDepth    int64 `yaml:"resume_depth"`     // пауза длится до этой глубины (default pause_depth/2)
+	MaxDelayMs     int   `yaml:"max_delay_ms"`     // задержка части у pause_depth, от slow_depth растёт линейно (default 1000)
+	PollIntervalMs int   `yaml:"poll_interval_ms"` // глубина опрашивается не чаще (default 1000)
+	MaxPauseSec    int   `yaml:"max_pause_sec"`    // пауза дольше — ошибка экспорта (0 — ждать сколько нужно)
+}
+
+// Validate проверяет пороги.
+func (c *BackpressureConfig) Validate() error {
+	if c.PauseDepth <= 0 {
+		return fmt.Errorf("pause_depth must be positive")
+	}
+	if c.SlowDepth < 0 || c.ResumeDepth < 0 || c.MaxDelayMs < 0 || c.PollIntervalMs < 0 || c.MaxPauseSec < 0 {
+		return fmt.Errorf("thresholds and intervals must not be negative")
+	}
+	if c.SlowDepth >= c.PauseDepth {
+		return fmt.Errorf("slow_depth (%d) must be below pause_depth (%d)", c.SlowDepth, c.PauseDepth)
+	}
+	if c.ResumeDepth >= c.PauseDepth {
+		return fmt.Errorf("resume_depth (%d) must be below pause_depth (%d)", c.ResumeDepth, c.PauseDepth)
+	}
+	return nil
+}
+
+// ThrottleStats — время, которое экспорт провёл в backpressure.
+type ThrottleStats struct {
+	Throttled  time.Duration // всего: паузы и задержки частей
+	Paused     time.Duration // из них паузы
+	Pauses     int
+	MaxDepth   int64 // наибольшая замеченная глубина очереди
+	PollErrors int
+}
+
+// throttle применяет BackpressureConfig к одному экспорту.
+type throttle struct {
+	cfg      BackpressureConfig
+	depth    brokers.DepthReporter
+	poll     time.Duration
+	maxDelay time.Duration
+	stats    ThrottleStats
+
+	lastPoll  time.Time
+	lastDepth int64
+	lastOK    bool
+
+	now   func() time.Time
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// newThrottle возвращает nil без backpressure или если брокер не сообщает
+// глубину очереди (тогда экспорт идёт без ограничений, с предупреждением).
+func newThrottle(cfg *BackpressureConfig, broker brokers.MessageBroker) *throttle {
+	if cfg == nil {
+		return nil
+	}
+	depth, ok := broker.(brokers.DepthReporter)
+	if !ok {
+		fmt.Printf("  ⚠ backpressure: %s does not report queue depth, export is not throttled\n", broker.GetBrokerType())
+		return nil
+	}
+	t := &throttle{
+		cfg:      *cfg,
+		depth:    depth,
+		poll:     defaultBackpressurePoll,
+		maxDelay: defaultBackpressureMaxDelay,
+		now:      time.Now,
+		sleep:    sleepContext,
+	}
+	if cfg.PollIntervalMs > 0 {
+		t.poll = time.Duration(cfg.PollIntervalMs) * time.Millisecond
+	}
+	if cfg.MaxDelayMs > 0 {
+		t.maxDelay = time.Duration(cfg.MaxDelayMs) * time.Millisecond
+	}
+	if t.cfg.ResumeDepth == 0 {
+		t.cfg.ResumeDepth = cfg.PauseDepth / 2
+	}
+	return t
+}
+
+// wait вызывается перед отправкой части: задерживает её или ждёт, пока
+// очередь разберут. Ошибка — отмена ctx или пауза дольше max_pause_sec.
+func (t *throttle) wait(ctx context.Context) error {
+	if t == nil {
+		return nil
+	}
+	depth, ok := t.depthNow(ctx, false)
+	if !ok {
+		return nil
+	}
+
+	if depth >= t.cfg.PauseDepth {
+		return t.pause(ctx, depth)
+	}
+	if t.cfg.SlowDepth > 0 && depth >= t.cfg.SlowDepth {
+		delay := time.Duration(int64(t.maxDelay) * (depth - t.cfg.SlowDepth) / (t.cfg.PauseDepth - t.cfg.SlowDepth))
+		if delay > 0 {
+			t.stats.Throttled += delay
+			return t.sleep(ctx, delay)
+		}
+	}
+	return nil
+}
+
+// pause ждёт, пока глубина не опустится до resume_depth.
+func (t *throttle) pause(ctx context.Context, depth int64) error {
+	start := t.now()
+	t.stats.Pauses++
+	fmt.Printf("  ⚠ backpressure: queue depth %d ≥ %d, export paused\n", depth, t.cfg.PauseDepth)
+	defer func() {
+		paused := t.now().Sub(start)
+		t.stats.Paused += paused
+		t.stats.Throttled += paused
+	}()
+
+	for depth > t.cfg.ResumeDepth {
+		if t.cfg.MaxPauseSec > 0 && t.now().Sub(start) >= time.Duration(t.cfg.MaxPauseSec)*time.Second {
+			return fmt.Errorf("backpressure: queue depth %d still above %d after %ds pause", depth, t.cfg.ResumeDepth, t.cfg.MaxPauseSec)
+		}
+		if err := t.sleep(ctx, t.poll); err != nil {
+			return err
+		}
+		var ok bool
+		if depth, ok = t.depthNow(ctx, true); !ok {
+			break // глубина неизвестна — продолжаем без ограничений
+		}
+	}
+	fmt.Printf("  backpressure: export resumed after %s (queue depth %d)\n", t.now().Sub(start).Round(time.Millisecond), depth)
+	return nil
+}
+
+// depthNow опрашивает брокер не чаще poll_interval (force — всегда).
+func (t *throttle) depthNow(ctx context.Context, force bool) (int64, bool) {
+	if !force && !t.lastPoll.IsZero() && t.now().Sub(t.lastPoll) < t.poll {
+		return t.lastDepth, t.lastOK
+	}
+	pollCtx, cancel := context.WithTimeout(ctx, backpressurePollTimeout)
+	defer cancel()
+	depth, err := t.depth.QueueDepth(pollCtx)
+	t.lastPoll = t.now()
+	if err != nil {
+		if t.stats.PollErrors == 0 {
+			fmt.Printf("  ⚠ backpressure: queue depth unavailable, not throttling: %v\n", err)
+		}
+		t.stats.PollErrors++
+		t.lastOK = false
+		return 0, false
+	}
+	t.lastDepth, t.lastOK = depth, true
+	t.stats.MaxDepth = max(t.stats.MaxDepth, depth)
+	return depth, true
+}
+
+// sleepContext ждёт d или отмены ctx.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
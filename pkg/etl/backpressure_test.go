@@ -0,0 +1,150 @@
+package etl
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ruslano69/tdtp-framework/pkg/brokers"
+)
+
+// depthBroker — брокер с заданной последовательностью глубин очереди.
+type depthBroker struct {
+	brokers.MessageBroker
+	depths []int64 // последняя повторяется
+	err    error
+	polls  int
+}
+
+func (b *depthBroker) QueueDepth(context.Context) (int64, error) {
+	b.polls++
+	if b.err != nil {
+		return 0, b.err
+	}
+	d := b.depths[min(b.polls-1, len(b.depths)-1)]
+	return d, nil
+}
+
+func (b *depthBroker) GetBrokerType() string { return "test" }
+
+// newTestThrottle — throttle с виртуальными часами: sleep сдвигает время.
+func newTestThrottle(cfg BackpressureConfig, b *depthBroker) (*throttle, *time.Time, *[]time.Duration) {
+	th := newThrottle(&cfg, b)
+	now := time.Unix(1_700_000_000, 0)
+	var slept []time.Duration
+	th.now = func() time.Time { return now }
+	th.sleep = func(_ context.Context, d time.Duration) error {
+		slept = append(slept, d)
+		now = now.Add(d)
+		return nil
+	}
+	return th, &now, &slept
+}
+
+func TestThrottle_SlowDown(t *testing.T) {
+	b := &depthBroker{depths: []int64{100, 550}}
+	th, now, slept := newTestThrottle(BackpressureConfig{SlowDepth: 100, PauseDepth: 1000, MaxDelayMs: 900}, b)
+
+	// 100 — на пороге slow_depth, задержки нет
+	if err := th.wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	*now = now.Add(time.Second)
+	// 550 — середина slow..pause → половина max_delay
+	if err := th.wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(*slept) != 1 || (*slept)[0] != 450*time.Millisecond {
+		t.Errorf("slept %v, want [450ms]", *slept)
+	}
+	if th.stats.Throttled != 450*time.Millisecond || th.stats.Pauses != 0 || th.stats.MaxDepth != 550 {
+		t.Errorf("stats = %+v", th.stats)
+	}
+}
+
+func TestThrottle_PauseUntilResume(t *testing.T) {
+	b := &depthBroker{depths: []int64{1200, 900, 600, 400}}
+	th, _, slept := newTestThrottle(BackpressureConfig{PauseDepth: 1000, PollIntervalMs: 2000}, b)
+
+	if err := th.wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	// resume_depth по умолчанию 500: ждём 900 → 600 → 400
+	if len(*slept) != 3 || b.polls != 4 {
+		t.Errorf("slept %v after %d polls", *slept, b.polls)
+	}
+	if th.stats.Pauses != 1 || th.stats.Paused != 6*time.Second || th.stats.Throttled != 6*time.Second || th.stats.MaxDepth != 1200 {
+		t.Errorf("stats = %+v", th.stats)
+	}
+
+	// Следующая часть в пределах poll_interval использует последний замер
+	if err := th.wait(context.Background()); err != nil || b.polls != 4 {
+		t.Errorf("wait = %v, polls %d (no re-poll within the interval)", err, b.polls)
+	}
+}
+
+func TestThrottle_MaxPause(t *testing.T) {
+	b := &depthBroker{depths: []int64{5000}}
+	th, _, _ := newTestThrottle(BackpressureConfig{PauseDepth: 1000, PollIntervalMs: 1000, MaxPauseSec: 3}, b)
+
+	err := th.wait(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "after 3s pause") {
+		t.Fatalf("wait = %v, want max pause error", err)
+	}
+	if th.stats.Paused != 3*time.Second {
+		t.Errorf("paused %s, want 3s", th.stats.Paused)
+	}
+}
+
+func TestThrottle_PollErrorDoesNotBlock(t *testing.T) {
+	b := &depthBroker{err: errors.New("management API unavailable")}
+	th, _, slept := newTestThrottle(BackpressureConfig{PauseDepth: 10}, b)
+	if err := th.wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(*slept) != 0 || th.stats.PollErrors != 1 {
+		t.Errorf("slept %v, stats %+v", *slept, th.stats)
+	}
+}
+
+func TestNewThrottle_NoDepthReporter(t *testing.T) {
+	fq, err := brokers.New(brokers.Config{Type: "filequeue", Dir: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if newThrottle(nil, fq) != nil {
+		t.Error("no backpressure config must not throttle")
+	}
+	if newThrottle(&BackpressureConfig{PauseDepth: 1}, fq) == nil {
+		t.Error("filequeue reports queue depth")
+	}
+	var plain struct{ brokers.MessageBroker }
+	plain.MessageBroker = fq
+	if newThrottle(&BackpressureConfig{PauseDepth: 1}, plain) != nil {
+		t.Error("broker without QueueDepth must not be throttled")
+	}
+}
+
+func TestBackpressureConfig_Validate(t *testing.T) {
+	valid := BackpressureConfig{SlowDepth: 1000, PauseDepth: 5000, ResumeDepth: 2000}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("valid config: %v", err)
+	}
+	for name, cfg := range map[string]BackpressureConfig{
+		"no pause":         {SlowDepth: 10},
+		"slow above pause": {SlowDepth: 500, PauseDepth: 100},
+		"resume at pause":  {PauseDepth: 100, ResumeDepth: 100},
+		"negative":         {PauseDepth: 100, MaxPauseSec: -1},
+	} {
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+
+	out := OutputConfig{Type: "tdtp", TDTP: &TDTPOutputConfig{Destination: "out.xml", Format: "xml"}, Backpressure: &valid}
+	if err := out.Validate(); err == nil || !strings.Contains(err.Error(), "backpressure") {
+		t.Errorf("tdtp output with backpressure: %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+package etl
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ruslano69/tdtp-framework/pkg/storage"
+)
+
+// Цепочки пайплайнов: источник type: pipeline читает опубликованный
+// результат другого пайплайна.
+//
+//	sources:
+//	  - name: clean_orders
+//	    type: pipeline
+//	    dsn: pipelines/stage1_clean_orders.yaml
+//
+// DSN — путь к YAML пайплайна-поставщика. При загрузке источника читается
+// его output.tdtp.destination (результат последнего запуска поставщика), и
+// источник загружается как tdtp (локальный файл) или tdtp-s3 (S3) со всеми
+// частями набора. Пути к файлам между стадиями прописывать не нужно:
+// смена destination поставщика подхватывается автоматически.
+
+// SourceTypePipeline — источник, читающий результат другого пайплайна.
+const SourceTypePipeline = "pipeline"
+
+// ResolvePipelineSource заменяет источник type: pipeline источником tdtp
+// или tdtp-s3, указывающим на результат пайплайна source.DSN. Имя
+// источника и его настройки (sanitize, fast, timeout, booleans) сохраняются.
+func ResolvePipelineSource(source SourceConfig) (SourceConfig, error) {
+	upstream, err := LoadConfig(source.DSN)
+	if err != nil {
+		return SourceConfig{}, fmt.Errorf("pipeline source %s: %w", source.DSN, err)
+	}
+	out := upstream.Output
+	if !strings.EqualFold(out.Type, "tdtp") || out.TDTP == nil {
+		return SourceConfig{}, fmt.Errorf("pipeline source %s: pipeline '%s' publishes to output.type '%s', only tdtp output can be chained",
+			source.DSN, upstream.Name, out.Type)
+	}
+	// Ключи xZMercury выдаются один раз (burn-on-read): чтение стадией
+	// цепочки лишило бы получателя ключа
+	if out.TDTP.Encryption {
+		return SourceConfig{}, fmt.Errorf("pipeline source %s: encrypted output of pipeline '%s' cannot be chained", source.DSN, upstream.Name)
+	}
+
+	// Путь, зависящий от переменных запуска ({{date}}), по конфигу не восстановить
+	if m := reYAMLVar.FindString(out.TDTP.Destination); m != "" {
+		return SourceConfig{}, fmt.Errorf("pipeline source %s: destination of pipeline '%s' depends on run variable %s, use type: tdtp with the file path",
+			source.DSN, upstream.Name, m)
+	}
+
+	resolved := source
+	resolved.DSN = out.TDTP.Destination
+	resolved.MultiPart = true
+	resolved.Type = "tdtp"
+	if storage.IsRemote(out.TDTP.Destination) {
+		if out.TDTP.S3 == nil {
+			return SourceConfig{}, fmt.Errorf("pipeline source %s: pipeline '%s' writes to %s without tdtp.s3 settings",
+				source.DSN, upstream.Name, out.TDTP.Destination)
+		}
+		s3cfg := *out.TDTP.S3
+		resolved.Type = "tdtp-s3"
+		resolved.S3 = &s3cfg
+	}
+	return resolved, nil
+}
@@ -0,0 +1,95 @@
+package etl
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+// writeStagePipeline пишет YAML пайплайна-поставщика с заданным output.
+func writeStagePipeline(t *testing.T, dir, output string) string {
+	t.Helper()
+	path := filepath.Join(dir, "stage1.yaml")
+	config := `
+name: stage1
+sources:
+  - name: raw
+    type: tdtp
+    dsn: raw.xml
+workspace:
+  type: sqlite
+  mode: memory
+transform:
+  sql: SELECT * FROM raw
+output:
+` + output
+	if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestPipelineSource_LoadsUpstreamOutput(t *testing.T) {
+	dir := t.TempDir()
+	published := filepath.Join(dir, "clean_orders.xml")
+	stage1 := writeStagePipeline(t, dir, `
+  type: tdtp
+  tdtp:
+    format: xml
+    destination: `+published+"\n")
+
+	gen := packet.NewGenerator()
+	packets, err := gen.GenerateReference("result", packet.Schema{Fields: []packet.Field{{Name: "id", Type: "INTEGER"}}},
+		[][]string{{"1"}, {"2"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := gen.WriteToFile(packets[0], published); err != nil {
+		t.Fatal(err)
+	}
+
+	source := SourceConfig{Name: "clean_orders", Type: SourceTypePipeline, DSN: stage1}
+	if err := source.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	resolved, err := ResolvePipelineSource(source)
+	if err != nil {
+		t.Fatalf("ResolvePipelineSource: %v", err)
+	}
+	if resolved.Type != "tdtp" || resolved.DSN != published || !resolved.MultiPart || resolved.Name != "clean_orders" {
+		t.Errorf("resolved source: %+v", resolved)
+	}
+
+	data, err := NewLoader([]SourceConfig{source}, ErrorHandlingConfig{}).LoadOne(context.Background(), "clean_orders")
+	if err != nil {
+		t.Fatalf("LoadOne: %v", err)
+	}
+	if data.Packet.Header.TableName != "clean_orders" || len(data.Packet.Data.Rows) != 2 {
+		t.Errorf("loaded %s with %d rows", data.Packet.Header.TableName, len(data.Packet.Data.Rows))
+	}
+}
+
+func TestPipelineSource_Unchainable(t *testing.T) {
+	tests := []struct {
+		name, output, want string
+	}{
+		{"xlsx output", "  type: xlsx\n  xlsx:\n    destination: out.xlsx\n", "only tdtp output"},
+		{"run variable", "  type: tdtp\n  tdtp:\n    format: xml\n    destination: out_{{date}}.xml\n", "{{date}}"},
+		{"encrypted", "  type: tdtp\n  tdtp:\n    format: xml\n    destination: out.xml\n    encryption: true\n", "encrypted"},
+	}
+	for _, tt := range tests {
+		stage1 := writeStagePipeline(t, t.TempDir(), tt.output)
+		_, err := ResolvePipelineSource(SourceConfig{Name: "s", Type: SourceTypePipeline, DSN: stage1})
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: got %v, want error containing %q", tt.name, err, tt.want)
+		}
+	}
+
+	if err := (&SourceConfig{Name: "s", Type: SourceTypePipeline, DSN: "x.yaml", Timezone: "UTC"}).Validate(); err == nil {
+		t.Error("expected error for timezone on pipeline source")
+	}
+}
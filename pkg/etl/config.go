@@ -4,8 +4,14 @@ package etl
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+	"github.com/ruslano69/tdtp-framework/pkg/adapters/base"
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+	"github.com/ruslano69/tdtp-framework/pkg/core/schema"
 	"github.com/ruslano69/tdtp-framework/pkg/processors"
 	"github.com/ruslano69/tdtp-framework/pkg/storage"
 	"gopkg.in/yaml.v3"
@@ -26,8 +32,15 @@ type PipelineConfig struct {
 	ErrorHandling ErrorHandlingConfig        `yaml:"error_handling"`
 	ResultLog     ResultLogConfig            `yaml:"result_log"`
 	Security      SecurityConfig             `yaml:"security"`
+	Encryption    string                     `yaml:"encryption"` // required — запретить незашифрованный вывод (EncryptionRequired)
 }
 
+// Значения PipelineConfig.Encryption.
+const (
+	EncryptionOptional = ""         // шифрование по output.tdtp.encryption (по умолчанию)
+	EncryptionRequired = "required" // любой незашифрованный вывод — ошибка до начала работы
+)
+
 // SecurityConfig определяет параметры интеграции с xZMercury для шифрования результатов.
 // Используется когда output.tdtp.encryption: true.
 type SecurityConfig struct {
@@ -36,6 +49,7 @@ type SecurityConfig struct {
 	KeyTTLSeconds     int    `yaml:"key_ttl_seconds"`    // TTL ключа в Mercury Redis (по умолчанию 86400)
 	MercuryTimeoutMs  int    `yaml:"mercury_timeout_ms"` // Таймаут обращения к xZMercury (по умолчанию 5000)
 	ServerSecret      string `yaml:"server_secret"`      // HMAC-ключ xZMercury; fallback: $MERCURY_SERVER_SECRET
+	EscrowPublicKey   string `yaml:"escrow_public_key"`  // PEM открытого ключа депозитария (только v1.5); fallback: $TDTP_ESCROW_PUBLIC_KEY
 }
 
 // ResultLogConfig определяет параметры публикации результата выполнения пайплайна
@@ -71,8 +85,8 @@ func (s *SanitizeFieldsConfig) IsActive() bool {
 // SourceConfig определяет источник данных (PostgreSQL, MSSQL, MySQL, SQLite, TDTP, TDTP-enc, TDTP-S3)
 type SourceConfig struct {
 	Name             string `yaml:"name"`               // Имя источника (будет использовано как имя таблицы в workspace)
-	Type             string `yaml:"type"`               // Тип: postgres, mssql, mysql, sqlite, tdtp, tdtp-enc, tdtp-s3
-	DSN              string `yaml:"dsn"`                // Data Source Name: строка подключения, путь к файлу или s3://bucket/key
+	Type             string `yaml:"type"`               // Тип: postgres, mssql, mysql, sqlite, tdtp, tdtp-enc, tdtp-s3, pipeline
+	DSN              string `yaml:"dsn"`                // Data Source Name: строка подключения, путь к файлу, s3://bucket/key или YAML пайплайна (type: pipeline)
 	Query            string `yaml:"query"`              // SQL запрос для извлечения данных (не используется для type: tdtp/tdtp-enc/tdtp-s3)
 	Timeout          int    `yaml:"timeout"`            // Таймаут в секундах (0 = без таймаута)
 	MultiPart        bool   `yaml:"multi_part"`         // Для type: tdtp/tdtp-s3 — загружать все части набора автоматически
@@ -81,6 +95,19 @@ type SourceConfig struct {
 	// NoDateSentinels — список дат-заглушек для "нет даты" (DB-specific conventions).
 	// Пример для MSSQL: ["1900-01-01", "1753-01-01"]
 	NoDateSentinels []string `yaml:"no_date_sentinels"`
+	// Timezone — часовой пояс (IANA), в котором источник хранит datetime без
+	// смещения. Такие значения переводятся в UTC, подозрительные (переходы на
+	// летнее время, сдвиг полуночи) попадают в отчёт. Только для DB-источников.
+	// Пример: timezone: Europe/Moscow
+	Timezone string `yaml:"timezone"`
+	// Booleans — текстовое представление BOOLEAN-колонок источника (Y/N, Да/Нет),
+	// ColumnBooleans — то же для отдельных колонок. Значения переводятся в 1/0.
+	// Пример:
+	//   booleans: {true_values: [Y], false_values: [N]}
+	//   column_booleans:
+	//     is_active: {true_values: [Да], false_values: [Нет]}
+	Booleans       *schema.BoolMapping           `yaml:"booleans,omitempty"`
+	ColumnBooleans map[string]schema.BoolMapping `yaml:"column_booleans,omitempty"`
 	// S3 — конфигурация S3-совместимого хранилища (SeaweedFS, MinIO и т.п.).
 	// Используется только для type: tdtp-s3. DSN может быть s3://bucket/key (bucket перекрывает S3.Bucket)
 	// или просто ключом (путём к объекту) при заданном S3.Bucket.
@@ -95,6 +122,11 @@ type SourceConfig struct {
 	// Fast — пропустить DetectAndApply (SpecialValues) для этого источника.
 	// Переопределяет performance.fast на уровне источника.
 	Fast bool `yaml:"fast"`
+	// ColumnEncryption — колонки источника, зашифрованные at rest
+	// (base.ColumnEncryptionConfig): значения расшифровываются при чтении,
+	// если ключ есть. Правила "table.column" сопоставляются с name
+	// источника. Только для DB-источников.
+	ColumnEncryption *base.ColumnEncryptionConfig `yaml:"column_encryption,omitempty"`
 }
 
 // WorkspaceConfig определяет временное хранилище для объединения данных
@@ -113,11 +145,14 @@ type TransformConfig struct {
 
 // OutputConfig определяет назначение для результатов
 type OutputConfig struct {
-	Type     string                `yaml:"type"`               // Тип: tdtp, rabbitmq, kafka, xlsx
-	TDTP     *TDTPOutputConfig     `yaml:"tdtp,omitempty"`     // Конфигурация для TDTP
-	RabbitMQ *RabbitMQOutputConfig `yaml:"rabbitmq,omitempty"` // Конфигурация для RabbitMQ
-	Kafka    *KafkaOutputConfig    `yaml:"kafka,omitempty"`    // Конфигурация для Kafka
-	XLSX     *XLSXOutputConfig     `yaml:"xlsx,omitempty"`     // Конфигурация для XLSX
+	Type      string                 `yaml:"type"`                // Тип: tdtp, rabbitmq, kafka, filequeue, xlsx, email, report
+	TDTP      *TDTPOutputConfig      `yaml:"tdtp,omitempty"`      // Конфигурация для TDTP
+	RabbitMQ  *RabbitMQOutputConfig  `yaml:"rabbitmq,omitempty"`  // Конфигурация для RabbitMQ
+	Kafka     *KafkaOutputConfig     `yaml:"kafka,omitempty"`     // Конфигурация для Kafka
+	FileQueue *FileQueueOutputConfig `yaml:"filequeue,omitempty"` // Конфигурация для файловой очереди
+	XLSX      *XLSXOutputConfig      `yaml:"xlsx,omitempty"`      // Конфигурация для XLSX
+	Email     *EmailOutputConfig     `yaml:"email,omitempty"`     // Конфигурация для email (SMTP)
+	Report    *ReportOutputConfig    `yaml:"report,omitempty"`    // Конфигурация отчёта по шаблону
 
 	// Fallback — резервный канал доставки.
 	// Если primary-канал (Type) недоступен, tdtpcli автоматически переключается на fallback.
@@ -126,6 +161,13 @@ type OutputConfig struct {
 	// Resilience — настройки circuit breaker для primary-канала.
 	// По умолчанию: max_failures=3, timeout_sec=60.
 	Resilience *OutputResilienceConfig `yaml:"resilience,omitempty"`
+	// Priority (0..9) проставляется в Header.Priority выходных пакетов.
+	// 9 (packet.PriorityUrgent) — срочное обновление справочника, обгоняющее bulk-загрузки:
+	// RabbitMQ — priority queue (rabbitmq.max_priority), Kafka — kafka.urgent_topic.
+	Priority int `yaml:"priority"`
+	// Backpressure — притормаживание потокового экспорта в брокер по
+	// глубине очереди (rabbitmq, kafka, filequeue; см. BackpressureConfig).
+	Backpressure *BackpressureConfig `yaml:"backpressure,omitempty"`
 }
 
 // OutputResilienceConfig настраивает circuit breaker для primary-канала доставки.
@@ -140,6 +182,30 @@ type XLSXOutputConfig struct {
 	Sheet       string `yaml:"sheet"`       // Имя листа (пустое = имя таблицы результата)
 }
 
+// ReportOutputConfig определяет параметры отчёта по шаблону (pkg/report).
+// Формат отчёта — по расширению destination: .html, .pdf (HTML шаблон через
+// внешний конвертер) или .xlsx (Excel шаблон с именованными диапазонами).
+// Таблица результата доступна шаблону по имени transform.result_table.
+type ReportOutputConfig struct {
+	Template    string            `yaml:"template"`    // Шаблон: .html/.htm или .xlsx
+	Destination string            `yaml:"destination"` // Путь к отчёту: .html, .pdf или .xlsx
+	PDFCommand  []string          `yaml:"pdf_command"` // Конвертер HTML→PDF с {input}/{output} (по умолчанию wkhtmltopdf)
+	Params      map[string]string `yaml:"params"`      // Параметры шаблона (.Params)
+}
+
+// Format возвращает формат отчёта по расширению destination: html, pdf или xlsx
+func (r *ReportOutputConfig) Format() string {
+	switch strings.ToLower(filepath.Ext(r.Destination)) {
+	case ".html", ".htm":
+		return "html"
+	case ".pdf":
+		return "pdf"
+	case ".xlsx":
+		return "xlsx"
+	}
+	return ""
+}
+
 // TDTPOutputConfig определяет параметры экспорта в TDTP формат
 type TDTPOutputConfig struct {
 	Format        string            `yaml:"format"`         // Формат: xml, json (в будущем)
@@ -147,6 +213,8 @@ type TDTPOutputConfig struct {
 	Compress      bool              `yaml:"compress"`       // Алиас для compression (совместимость с CLI)
 	CompressAlgo  string            `yaml:"compress_algo"`  // Алгоритм: zstd (по умолчанию) или kanzi
 	CompressLevel int               `yaml:"compress_level"` // Уровень: 1-19 (zstd), 6-7 (kanzi)
+	DictDir       string            `yaml:"dict_dir"`       // Каталог словарей zstd <table>.zdict (см. processors.LoadZstdDictDir)
+	EmbedDict     bool              `yaml:"embed_dict"`     // Встраивать словарь в каждую часть
 	Destination   string            `yaml:"destination"`    // Путь к файлу или s3://bucket/key
 	Encryption    bool              `yaml:"encryption"`     // Шифровать результат через xZMercury (AES-256-GCM)
 	EncryptionV13 bool              `yaml:"encryption_v13"` // true = legacy TDTP v1.3 whole-blob формат вместо v1.5 section-level (по умолчанию)
@@ -166,12 +234,27 @@ type RabbitMQOutputConfig struct {
 	User     string `yaml:"user"`
 	Password string `yaml:"password"`
 	Queue    string `yaml:"queue"`
+	// MaxPriority > 0 — очередь объявляется как priority queue (x-max-priority).
+	// Должен совпадать с существующей очередью и с настройкой консьюмера.
+	MaxPriority int `yaml:"max_priority"`
+}
+
+// FileQueueOutputConfig определяет параметры отправки в файловую очередь
+// (air-gapped контуры: общая папка или съёмный носитель, см. brokers.FileQueue)
+type FileQueueOutputConfig struct {
+	Dir   string `yaml:"dir"`   // Каталог очереди
+	Queue string `yaml:"queue"` // Подкаталог очереди (необязательно)
 }
 
 // KafkaOutputConfig определяет параметры отправки в Kafka
 type KafkaOutputConfig struct {
 	Brokers []string `yaml:"brokers"` // Список Kafka brokers
 	Topic   string   `yaml:"topic"`   // Kafka topic
+	// UrgentTopic — topic для пакетов с priority >= 9; пустой = всё в Topic.
+	UrgentTopic string `yaml:"urgent_topic"`
+	// ConsumerGroup — группа потребителей, чей lag учитывает backpressure
+	// (по умолчанию группа импорта tdtp-consumer-group).
+	ConsumerGroup string `yaml:"consumer_group"`
 
 	// Streaming spool — для надёжной отправки больших таблиц.
 	// Каждый пакет сжимается и пишется на диск; отдельная горутина
@@ -190,7 +273,8 @@ type KafkaOutputConfig struct {
 
 // PerformanceConfig определяет параметры производительности
 type PerformanceConfig struct {
-	MaxMemoryMB     int  `yaml:"max_memory_mb"`    // Максимальная память для workspace (MB)
+	MaxMemoryMB     int  `yaml:"max_memory_mb"`    // Максимальная память: Go heap + workspace SQLite (MB); превышение — ранний abort (0 — без лимита)
+	MaxRows         int  `yaml:"max_rows"`         // Максимум строк в workspace и в результате transform (0 — без лимита)
 	BatchSize       int  `yaml:"batch_size"`       // Размер batch для импорта
 	ParallelSources bool `yaml:"parallel_sources"` // Загружать источники параллельно
 	// Fast — глобальный режим --fast для всего пайплайна.
@@ -274,14 +358,66 @@ func (c *PipelineConfig) Validate() error {
 		return fmt.Errorf("output: %w", err)
 	}
 
+	// Проверка encryption: required
+	if err := c.validateEncryption(); err != nil {
+		return fmt.Errorf("encryption: %w", err)
+	}
+
+	// Депонирование ключа кладёт обёрнутый ключ в Header v1.5
+	if c.Security.EscrowPublicKey != "" {
+		for out := &c.Output; out != nil; out = out.Fallback {
+			if out.TDTP != nil && out.TDTP.Encryption && out.TDTP.EncryptionV13 {
+				return fmt.Errorf("security.escrow_public_key requires v1.5 encryption, but tdtp.encryption_v13 is set")
+			}
+		}
+	}
+
 	// Проверка result_log (опционально)
 	if err := c.ResultLog.Validate(); err != nil {
 		return fmt.Errorf("result_log: %w", err)
 	}
 
+	// Проверка performance
+	if err := c.Performance.Validate(); err != nil {
+		return fmt.Errorf("performance: %w", err)
+	}
+
 	return nil
 }
 
+// validateEncryption проверяет, что при encryption: required каждый канал
+// вывода, включая fallback, пишет только зашифрованный TDTP: брокеры, xlsx,
+// email и отчёты шифровать не умеют.
+func (c *PipelineConfig) validateEncryption() error {
+	switch c.Encryption {
+	case EncryptionOptional:
+		return nil
+	case EncryptionRequired:
+	default:
+		return fmt.Errorf("unsupported value '%s', must be '%s' or empty", c.Encryption, EncryptionRequired)
+	}
+	path := "output"
+	for out := &c.Output; out != nil; out = out.Fallback {
+		if !strings.EqualFold(out.Type, "tdtp") {
+			return fmt.Errorf("required, but %s.type '%s' does not support encryption (only tdtp)", path, out.Type)
+		}
+		if out.TDTP == nil || !out.TDTP.Encryption {
+			return fmt.Errorf("required, but %s.tdtp.encryption is not enabled", path)
+		}
+		path += ".fallback"
+	}
+	return nil
+}
+
+// isTDTP сообщает, что источник читает готовые TDTP-пакеты, а не БД.
+func (s *SourceConfig) isTDTP() bool {
+	switch s.Type {
+	case "tdtp", "tdtp-enc", "tdtp-s3", SourceTypePipeline:
+		return true
+	}
+	return false
+}
+
 // Validate проверяет корректность SourceConfig
 func (s *SourceConfig) Validate() error {
 	if s.Name == "" {
@@ -303,16 +439,39 @@ func (s *SourceConfig) Validate() error {
 		"tdtp":     true, // TDTP XML/JSON file — DSN is the file path, query not required
 		"tdtp-enc": true, // Encrypted TDTP file — requires mercury_url for key retrieval
 		"tdtp-s3":  true, // TDTP file in S3-compatible storage — DSN is s3://bucket/key or just key
+		"pipeline": true, // Output of another pipeline — DSN is its YAML config (see ResolvePipelineSource)
 	}
-	if !validTypes[s.Type] {
-		return fmt.Errorf("unsupported type '%s', must be one of: postgres, mssql, mysql, sqlite, tdtp, tdtp-enc, tdtp-s3", s.Type)
+	// Внешний адаптер (pkg/adapters/plugin) допустим под своим типом
+	if !validTypes[s.Type] && !adapters.IsRegistered(s.Type) {
+		return fmt.Errorf("unsupported type '%s', must be one of: postgres, mssql, mysql, sqlite, tdtp, tdtp-enc, tdtp-s3, pipeline or a loaded adapter plugin", s.Type)
 	}
 
 	// query обязателен для DB-источников, для TDTP-файлов не нужен
-	if s.Type != "tdtp" && s.Type != "tdtp-enc" && s.Type != "tdtp-s3" && s.Query == "" {
+	if !s.isTDTP() && s.Query == "" {
 		return fmt.Errorf("query is required for type '%s'", s.Type)
 	}
 
+	// timezone — для naive datetime из БД; в TDTP-файлах значения уже в UTC
+	if s.Timezone != "" {
+		if s.isTDTP() {
+			return fmt.Errorf("timezone is not supported for type '%s'", s.Type)
+		}
+		if _, err := time.LoadLocation(s.Timezone); err != nil {
+			return fmt.Errorf("invalid timezone '%s': %w", s.Timezone, err)
+		}
+	}
+
+	if s.Booleans != nil {
+		if err := s.Booleans.Validate(); err != nil {
+			return fmt.Errorf("booleans: %w", err)
+		}
+	}
+	for column, m := range s.ColumnBooleans {
+		if err := m.Validate(); err != nil {
+			return fmt.Errorf("column_booleans.%s: %w", column, err)
+		}
+	}
+
 	// multi_part имеет смысл только для tdtp и tdtp-s3
 	if s.MultiPart && s.Type != "tdtp" && s.Type != "tdtp-s3" {
 		return fmt.Errorf("multi_part is only supported for type 'tdtp' or 'tdtp-s3'")
@@ -365,6 +524,17 @@ func (t *TransformConfig) Validate() error {
 	return nil
 }
 
+// Validate проверяет корректность PerformanceConfig
+func (p *PerformanceConfig) Validate() error {
+	if p.MaxMemoryMB < 0 {
+		return fmt.Errorf("max_memory_mb must not be negative (0 = no limit)")
+	}
+	if p.MaxRows < 0 {
+		return fmt.Errorf("max_rows must not be negative (0 = no limit)")
+	}
+	return nil
+}
+
 // Validate проверяет корректность ErrorHandlingConfig
 func (e *ErrorHandlingConfig) Validate() error {
 	if e.OnSourceError != "" && e.OnSourceError != "fail" && e.OnSourceError != "continue" {
@@ -445,6 +615,14 @@ func (o *OutputConfig) Validate() error {
 			return fmt.Errorf("kafka.topic is required")
 		}
 
+	case "filequeue":
+		if o.FileQueue == nil {
+			return fmt.Errorf("filequeue configuration is required when type is 'filequeue'")
+		}
+		if o.FileQueue.Dir == "" {
+			return fmt.Errorf("filequeue.dir is required")
+		}
+
 	case "xlsx":
 		if o.XLSX == nil {
 			return fmt.Errorf("xlsx configuration is required when type is 'xlsx'")
@@ -453,8 +631,49 @@ func (o *OutputConfig) Validate() error {
 			return fmt.Errorf("xlsx.destination is required")
 		}
 
+	case "email":
+		if o.Email == nil {
+			return fmt.Errorf("email configuration is required when type is 'email'")
+		}
+		if err := o.Email.Validate(); err != nil {
+			return err
+		}
+
+	case "report":
+		if o.Report == nil {
+			return fmt.Errorf("report configuration is required when type is 'report'")
+		}
+		if o.Report.Template == "" {
+			return fmt.Errorf("report.template is required")
+		}
+		if o.Report.Destination == "" {
+			return fmt.Errorf("report.destination is required")
+		}
+		format := o.Report.Format()
+		if format == "" {
+			return fmt.Errorf("report.destination must end with .html, .pdf or .xlsx")
+		}
+		if xlsxTemplate := strings.EqualFold(filepath.Ext(o.Report.Template), ".xlsx"); xlsxTemplate != (format == "xlsx") {
+			return fmt.Errorf("report.template %s cannot produce a %s report: use an .xlsx template for .xlsx and an HTML template for .html/.pdf", o.Report.Template, format)
+		}
+
 	default:
-		return fmt.Errorf("unsupported output type '%s', must be one of: tdtp, rabbitmq, kafka, xlsx", o.Type)
+		return fmt.Errorf("unsupported output type '%s', must be one of: tdtp, rabbitmq, kafka, filequeue, xlsx, email, report", o.Type)
+	}
+
+	if o.Priority < packet.PriorityNormal || o.Priority > packet.MaxPriority {
+		return fmt.Errorf("priority must be between %d and %d, got %d", packet.PriorityNormal, packet.MaxPriority, o.Priority)
+	}
+
+	if o.Backpressure != nil {
+		switch o.Type {
+		case "rabbitmq", "kafka", "filequeue":
+		default:
+			return fmt.Errorf("backpressure is only supported for rabbitmq, kafka and filequeue outputs, not '%s'", o.Type)
+		}
+		if err := o.Backpressure.Validate(); err != nil {
+			return fmt.Errorf("backpressure: %w", err)
+		}
 	}
 
 	// Валидация резервного канала (рекурсивно, но без вложенного fallback)
@@ -526,6 +745,13 @@ func (c *PipelineConfig) SetDefaults() {
 		setTDTPCompressionDefaults(c.Output.Fallback.TDTP)
 	}
 
+	if c.Output.Email != nil {
+		setEmailDefaults(c.Output.Email)
+	}
+	if c.Output.Fallback != nil && c.Output.Fallback.Email != nil {
+		setEmailDefaults(c.Output.Fallback.Email)
+	}
+
 	// Defaults для resilience
 	if c.Output.Resilience != nil {
 		if c.Output.Resilience.MaxFailures == 0 {
@@ -570,9 +796,6 @@ func (c *PipelineConfig) SetDefaults() {
 	}
 
 	// Defaults для performance
-	if c.Performance.MaxMemoryMB == 0 {
-		c.Performance.MaxMemoryMB = 2048 // 2GB по умолчанию
-	}
 	if c.Performance.BatchSize == 0 {
 		c.Performance.BatchSize = 10000
 	}
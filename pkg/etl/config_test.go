@@ -4,6 +4,8 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/schema"
 )
 
 func TestLoadConfig(t *testing.T) {
@@ -318,8 +320,8 @@ func TestPipelineConfig_SetDefaults(t *testing.T) {
 		t.Errorf("TDTP format default = %s, want xml", config.Output.TDTP.Format)
 	}
 
-	if config.Performance.MaxMemoryMB != 2048 {
-		t.Errorf("Performance max_memory_mb default = %d, want 2048", config.Performance.MaxMemoryMB)
+	if config.Performance.MaxMemoryMB != 0 {
+		t.Errorf("Performance max_memory_mb default = %d, want 0 (no limit)", config.Performance.MaxMemoryMB)
 	}
 
 	if config.Performance.BatchSize != 10000 {
@@ -403,6 +405,58 @@ func TestSourceConfig_Validate(t *testing.T) {
 			wantErr: true,
 			errMsg:  "unsupported type",
 		},
+		{
+			name: "Valid timezone",
+			source: SourceConfig{
+				Name:     "test",
+				Type:     "mssql",
+				DSN:      "sqlserver://localhost/test",
+				Query:    "SELECT * FROM users",
+				Timezone: "Europe/Moscow",
+			},
+			wantErr: false,
+		},
+		{
+			name: "Unknown timezone",
+			source: SourceConfig{
+				Name:     "test",
+				Type:     "mssql",
+				DSN:      "sqlserver://localhost/test",
+				Query:    "SELECT * FROM users",
+				Timezone: "Europe/Atlantis",
+			},
+			wantErr: true,
+			errMsg:  "invalid timezone",
+		},
+		{
+			name: "Timezone for TDTP file",
+			source: SourceConfig{
+				Name:     "test",
+				Type:     "tdtp",
+				DSN:      "users.tdtp.xml",
+				Timezone: "Europe/Moscow",
+			},
+			wantErr: true,
+			errMsg:  "timezone is not supported",
+		},
+		{
+			name: "Invalid column boolean mapping",
+			source: SourceConfig{
+				Name:  "test",
+				Type:  "sqlite",
+				DSN:   "test.db",
+				Query: "SELECT * FROM users",
+				Booleans: &schema.BoolMapping{
+					TrueValues:  []string{"Y"},
+					FalseValues: []string{"N"},
+				},
+				ColumnBooleans: map[string]schema.BoolMapping{
+					"active": {TrueValues: []string{"Y"}},
+				},
+			},
+			wantErr: true,
+			errMsg:  "column_booleans.active",
+		},
 	}
 
 	for _, tt := range tests {
@@ -552,6 +606,41 @@ func TestOutputConfig_Validate(t *testing.T) {
 			wantErr: true,
 			errMsg:  "must be 'xml' or 'json'",
 		},
+		{
+			name: "Valid report output",
+			output: OutputConfig{
+				Type: "report",
+				Report: &ReportOutputConfig{
+					Template:    "./monthly.html",
+					Destination: "./out/monthly.pdf",
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Report unknown format",
+			output: OutputConfig{
+				Type: "report",
+				Report: &ReportOutputConfig{
+					Template:    "./monthly.html",
+					Destination: "./out/monthly.docx",
+				},
+			},
+			wantErr: true,
+			errMsg:  "must end with .html, .pdf or .xlsx",
+		},
+		{
+			name: "Report HTML template for XLSX",
+			output: OutputConfig{
+				Type: "report",
+				Report: &ReportOutputConfig{
+					Template:    "./monthly.html",
+					Destination: "./out/monthly.xlsx",
+				},
+			},
+			wantErr: true,
+			errMsg:  "use an .xlsx template",
+		},
 		{
 			name: "Mixed case type normalized (RabbitMQ)",
 			output: OutputConfig{
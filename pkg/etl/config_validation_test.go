@@ -6,7 +6,7 @@ import (
 
 // Этот файл содержит дополнительные тесты для валидации конфигов
 // Основные тесты находятся в config_test.go
-// Здесь тестируются только ErrorHandlingConfig и TransformConfig которых нет в config_test.go
+// Здесь тестируются только ErrorHandlingConfig, TransformConfig и PerformanceConfig которых нет в config_test.go
 
 func TestErrorHandlingConfig_Validate(t *testing.T) {
 	tests := []struct {
@@ -141,3 +141,85 @@ func TestTransformConfig_Validate(t *testing.T) {
 		})
 	}
 }
+
+func TestPerformanceConfig_Validate(t *testing.T) {
+	for _, cfg := range []PerformanceConfig{{MaxMemoryMB: -1}, {MaxRows: -5}} {
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("Validate(%+v) accepted a negative limit", cfg)
+		}
+	}
+	if err := (&PerformanceConfig{MaxMemoryMB: 512, MaxRows: 1_000_000}).Validate(); err != nil {
+		t.Errorf("Validate() unexpected error = %v", err)
+	}
+}
+
+func TestPipelineConfig_ValidateEncryption(t *testing.T) {
+	encrypted := func() *OutputConfig {
+		return &OutputConfig{Type: "tdtp", TDTP: &TDTPOutputConfig{Format: "xml", Destination: "out.xml", Encryption: true}}
+	}
+	tests := []struct {
+		name       string
+		encryption string
+		output     func() OutputConfig
+		errMsg     string
+	}{
+		{
+			name:       "Optional allows plaintext",
+			encryption: EncryptionOptional,
+			output:     func() OutputConfig { return OutputConfig{Type: "kafka"} },
+		},
+		{
+			name:       "Required with encrypted tdtp",
+			encryption: EncryptionRequired,
+			output:     func() OutputConfig { return *encrypted() },
+		},
+		{
+			name:       "Required rejects plaintext tdtp",
+			encryption: EncryptionRequired,
+			output: func() OutputConfig {
+				out := *encrypted()
+				out.TDTP = &TDTPOutputConfig{Format: "xml", Destination: "out.xml"}
+				return out
+			},
+			errMsg: "output.tdtp.encryption is not enabled",
+		},
+		{
+			name:       "Required rejects broker",
+			encryption: EncryptionRequired,
+			output:     func() OutputConfig { return OutputConfig{Type: "rabbitmq"} },
+			errMsg:     "output.type 'rabbitmq' does not support encryption",
+		},
+		{
+			name:       "Required rejects plaintext fallback",
+			encryption: EncryptionRequired,
+			output: func() OutputConfig {
+				out := *encrypted()
+				out.Fallback = &OutputConfig{Type: "filequeue"}
+				return out
+			},
+			errMsg: "output.fallback.type 'filequeue'",
+		},
+		{
+			name:       "Unknown value",
+			encryption: "always",
+			output:     func() OutputConfig { return *encrypted() },
+			errMsg:     "unsupported value 'always'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := PipelineConfig{Encryption: tt.encryption, Output: tt.output()}
+			err := c.validateEncryption()
+			if tt.errMsg == "" {
+				if err != nil {
+					t.Errorf("validateEncryption() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !contains(err.Error(), tt.errMsg) {
+				t.Errorf("validateEncryption() error = %v, should contain %q", err, tt.errMsg)
+			}
+		})
+	}
+}
@@ -0,0 +1,319 @@
+package etl
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Режимы TLS для email-выхода.
+const (
+	EmailTLSStartTLS = "starttls" // STARTTLS после подключения (порт 587) — по умолчанию
+	EmailTLSImplicit = "tls"      // TLS с первого байта (SMTPS, порт 465)
+	EmailTLSNone     = "none"     // без шифрования (порт 25) — только для доверенного релея
+)
+
+const (
+	defaultEmailMaxSizeKB = 10 * 1024 // типичный лимит почтовых серверов на вложение
+	defaultEmailSubject   = "TDTP export: {{.Table}} ({{.Date}})"
+	defaultEmailBody      = "Attached: {{.Filename}} ({{.Rows}} rows from {{.Table}})."
+	emailDialTimeout      = 30 * time.Second
+)
+
+// EmailOutputConfig определяет параметры отправки результата по email
+// вложением — для небольших ночных выгрузок справочников партнёрам.
+//
+// Subject и Body — шаблоны text/template с полями {{.Table}}, {{.Rows}},
+// {{.Date}} (YYYY-MM-DD), {{.Time}} (time.Time), {{.Pipeline}} и
+// {{.Filename}}. CLI-переменные пайплайна ({{name}}) подставляются раньше.
+type EmailOutputConfig struct {
+	Host          string   `yaml:"host"`            // SMTP-сервер
+	Port          int      `yaml:"port"`            // По умолчанию: 587 (starttls), 465 (tls), 25 (none)
+	TLS           string   `yaml:"tls"`             // starttls (по умолчанию), tls, none
+	TLSSkipVerify bool     `yaml:"tls_skip_verify"` // Не проверять сертификат сервера
+	Username      string   `yaml:"username"`        // Пустой — без аутентификации
+	Password      string   `yaml:"password"`
+	PasswordEnv   string   `yaml:"password_env"` // Имя переменной окружения с паролем (вместо password)
+	From          string   `yaml:"from"`
+	To            []string `yaml:"to"`
+	Cc            []string `yaml:"cc"`
+	Bcc           []string `yaml:"bcc"`
+	Subject       string   `yaml:"subject"`     // Шаблон темы
+	Body          string   `yaml:"body"`        // Шаблон текста письма
+	Format        string   `yaml:"format"`      // Формат вложения: tdtp (XML, по умолчанию) или xlsx
+	MaxSizeKB     int      `yaml:"max_size_kb"` // Лимит размера вложения (по умолчанию 10 MB)
+}
+
+// setEmailDefaults устанавливает значения по умолчанию для EmailOutputConfig.
+func setEmailDefaults(c *EmailOutputConfig) {
+	c.TLS = strings.ToLower(strings.TrimSpace(c.TLS))
+	if c.TLS == "" {
+		c.TLS = EmailTLSStartTLS
+	}
+	if c.Port == 0 {
+		switch c.TLS {
+		case EmailTLSImplicit:
+			c.Port = 465
+		case EmailTLSNone:
+			c.Port = 25
+		default:
+			c.Port = 587
+		}
+	}
+	c.Format = strings.ToLower(strings.TrimSpace(c.Format))
+	if c.Format == "" {
+		c.Format = "tdtp"
+	}
+	if c.MaxSizeKB <= 0 {
+		c.MaxSizeKB = defaultEmailMaxSizeKB
+	}
+	if c.Subject == "" {
+		c.Subject = defaultEmailSubject
+	}
+	if c.Body == "" {
+		c.Body = defaultEmailBody
+	}
+}
+
+// Validate проверяет корректность EmailOutputConfig
+func (c *EmailOutputConfig) Validate() error {
+	if c.Host == "" {
+		return fmt.Errorf("email.host is required")
+	}
+	if _, err := mail.ParseAddress(c.From); err != nil {
+		return fmt.Errorf("email.from is invalid: %w", err)
+	}
+	if len(c.To) == 0 {
+		return fmt.Errorf("email.to requires at least one recipient")
+	}
+	for _, list := range [][]string{c.To, c.Cc, c.Bcc} {
+		for _, addr := range list {
+			if _, err := mail.ParseAddress(addr); err != nil {
+				return fmt.Errorf("invalid email recipient %q: %w", addr, err)
+			}
+		}
+	}
+	switch strings.ToLower(c.TLS) {
+	case "", EmailTLSStartTLS, EmailTLSImplicit, EmailTLSNone:
+	default:
+		return fmt.Errorf("email.tls must be one of: starttls, tls, none (got %q)", c.TLS)
+	}
+	switch strings.ToLower(c.Format) {
+	case "", "tdtp", "xlsx":
+	default:
+		return fmt.Errorf("email.format must be tdtp or xlsx (got %q)", c.Format)
+	}
+	for name, text := range map[string]string{"subject": c.Subject, "body": c.Body} {
+		if _, err := template.New(name).Option("missingkey=error").Parse(text); err != nil {
+			return fmt.Errorf("email.%s template: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// password возвращает пароль SMTP: password_env имеет приоритет.
+func (c *EmailOutputConfig) password() string {
+	if c.PasswordEnv != "" {
+		return os.Getenv(c.PasswordEnv)
+	}
+	return c.Password
+}
+
+// emailTemplateData — поля, доступные в шаблонах subject/body.
+type emailTemplateData struct {
+	Table    string
+	Rows     int
+	Date     string
+	Time     time.Time
+	Pipeline string
+	Filename string
+}
+
+// emailAttachment — содержимое и метаданные вложения.
+type emailAttachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+func renderEmailTemplate(name, text string, data emailTemplateData) (string, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("email %s template: %w", name, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("email %s template: %w", name, err)
+	}
+	return buf.String(), nil
+}
+
+// buildEmailMessage собирает MIME-сообщение multipart/mixed: текст письма
+// и вложение в base64. Bcc в заголовки не попадает.
+func buildEmailMessage(cfg *EmailOutputConfig, subject, body string, att emailAttachment, now time.Time) ([]byte, error) {
+	var msg bytes.Buffer
+	mw := multipart.NewWriter(&msg)
+
+	var hdr bytes.Buffer
+	writeHeader := func(k, v string) { fmt.Fprintf(&hdr, "%s: %s\r\n", k, v) }
+	writeHeader("From", cfg.From)
+	writeHeader("To", strings.Join(cfg.To, ", "))
+	if len(cfg.Cc) > 0 {
+		writeHeader("Cc", strings.Join(cfg.Cc, ", "))
+	}
+	writeHeader("Subject", mime.QEncoding.Encode("utf-8", subject))
+	writeHeader("Date", now.Format(time.RFC1123Z))
+	writeHeader("Message-ID", emailMessageID(cfg.From))
+	writeHeader("MIME-Version", "1.0")
+	writeHeader("Content-Type", "multipart/mixed; boundary="+strconv.Quote(mw.Boundary()))
+	hdr.WriteString("\r\n")
+
+	textPart, err := mw.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {"text/plain; charset=utf-8"},
+		"Content-Transfer-Encoding": {"base64"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := writeBase64Lines(textPart, []byte(body)); err != nil {
+		return nil, err
+	}
+
+	attPart, err := mw.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {mime.FormatMediaType(att.ContentType, map[string]string{"name": att.Filename})},
+		"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": att.Filename})},
+		"Content-Transfer-Encoding": {"base64"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := writeBase64Lines(attPart, att.Data); err != nil {
+		return nil, err
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+	return append(hdr.Bytes(), msg.Bytes()...), nil
+}
+
+// writeBase64Lines пишет base64 строками по 76 символов (RFC 2045).
+func writeBase64Lines(w interface{ Write([]byte) (int, error) }, data []byte) error {
+	enc := base64.StdEncoding.EncodeToString(data)
+	for len(enc) > 76 {
+		if _, err := fmt.Fprintf(w, "%s\r\n", enc[:76]); err != nil {
+			return err
+		}
+		enc = enc[76:]
+	}
+	_, err := fmt.Fprintf(w, "%s\r\n", enc)
+	return err
+}
+
+func emailMessageID(from string) string {
+	domain := "tdtp.local"
+	if addr, err := mail.ParseAddress(from); err == nil {
+		if _, d, ok := strings.Cut(addr.Address, "@"); ok {
+			domain = d
+		}
+	}
+	var b [12]byte
+	_, _ = rand.Read(b[:])
+	return "<" + hex.EncodeToString(b[:]) + "@" + domain + ">"
+}
+
+// envelopeAddress извлекает адрес для MAIL FROM/RCPT TO из "Name <addr>".
+func envelopeAddress(s string) (string, error) {
+	addr, err := mail.ParseAddress(s)
+	if err != nil {
+		return "", fmt.Errorf("invalid email address %q: %w", s, err)
+	}
+	return addr.Address, nil
+}
+
+// sendSMTP доставляет сообщение через SMTP с учётом режима TLS и аутентификации.
+func sendSMTP(ctx context.Context, cfg *EmailOutputConfig, msg []byte) error {
+	addr := net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port))
+	tlsCfg := &tls.Config{ServerName: cfg.Host, InsecureSkipVerify: cfg.TLSSkipVerify} //nolint:gosec // явная опция tls_skip_verify
+
+	dialer := &net.Dialer{Timeout: emailDialTimeout}
+	var conn net.Conn
+	var err error
+	if cfg.TLS == EmailTLSImplicit {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: tlsCfg}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect to SMTP server %s: %w", addr, err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	c, err := smtp.NewClient(conn, cfg.Host)
+	if err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("SMTP handshake failed: %w", err)
+	}
+	defer func() { _ = c.Close() }()
+
+	if cfg.TLS == EmailTLSStartTLS {
+		if ok, _ := c.Extension("STARTTLS"); !ok {
+			return fmt.Errorf("SMTP server %s does not support STARTTLS (set email.tls: none to send unencrypted)", addr)
+		}
+		if err := c.StartTLS(tlsCfg); err != nil {
+			return fmt.Errorf("STARTTLS failed: %w", err)
+		}
+	}
+	if cfg.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", cfg.Username, cfg.password(), cfg.Host)); err != nil {
+			return fmt.Errorf("SMTP authentication failed: %w", err)
+		}
+	}
+
+	from, err := envelopeAddress(cfg.From)
+	if err != nil {
+		return err
+	}
+	if err := c.Mail(from); err != nil {
+		return fmt.Errorf("SMTP MAIL FROM rejected: %w", err)
+	}
+	for _, list := range [][]string{cfg.To, cfg.Cc, cfg.Bcc} {
+		for _, r := range list {
+			rcpt, err := envelopeAddress(r)
+			if err != nil {
+				return err
+			}
+			if err := c.Rcpt(rcpt); err != nil {
+				return fmt.Errorf("SMTP recipient %s rejected: %w", rcpt, err)
+			}
+		}
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return fmt.Errorf("SMTP DATA failed: %w", err)
+	}
+	if _, err := w.Write(msg); err != nil {
+		return fmt.Errorf("SMTP DATA failed: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("SMTP message rejected: %w", err)
+	}
+	return c.Quit()
+}
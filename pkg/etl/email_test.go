@@ -0,0 +1,261 @@
+package etl
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+func TestEmailOutputConfig_Validate(t *testing.T) {
+	valid := EmailOutputConfig{Host: "smtp.example.com", From: "TDTP <etl@example.com>", To: []string{"partner@example.org"}}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("valid config: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(c *EmailOutputConfig)
+	}{
+		{"no host", func(c *EmailOutputConfig) { c.Host = "" }},
+		{"bad from", func(c *EmailOutputConfig) { c.From = "not an address" }},
+		{"no recipients", func(c *EmailOutputConfig) { c.To = nil }},
+		{"bad cc", func(c *EmailOutputConfig) { c.Cc = []string{"@@"} }},
+		{"bad tls", func(c *EmailOutputConfig) { c.TLS = "ssl3" }},
+		{"bad format", func(c *EmailOutputConfig) { c.Format = "csv" }},
+		{"bad template", func(c *EmailOutputConfig) { c.Subject = "{{.Table" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := valid
+			tt.mutate(&c)
+			if err := c.Validate(); err == nil {
+				t.Error("expected validation error")
+			}
+		})
+	}
+}
+
+func TestSetEmailDefaults(t *testing.T) {
+	c := EmailOutputConfig{TLS: "TLS"}
+	setEmailDefaults(&c)
+	if c.TLS != EmailTLSImplicit || c.Port != 465 || c.Format != "tdtp" || c.MaxSizeKB != defaultEmailMaxSizeKB {
+		t.Errorf("unexpected defaults: %+v", c)
+	}
+	c = EmailOutputConfig{}
+	setEmailDefaults(&c)
+	if c.TLS != EmailTLSStartTLS || c.Port != 587 || c.Subject == "" || c.Body == "" {
+		t.Errorf("unexpected defaults: %+v", c)
+	}
+}
+
+func TestBuildEmailMessage(t *testing.T) {
+	cfg := &EmailOutputConfig{
+		From: "TDTP <etl@example.com>",
+		To:   []string{"a@example.org", "b@example.org"},
+		Cc:   []string{"c@example.org"},
+		Bcc:  []string{"hidden@example.org"},
+	}
+	subject, err := renderEmailTemplate("subject", "Справочник {{.Table}}: {{.Rows}} строк", emailTemplateData{Table: "Currencies", Rows: 42})
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload := []byte(strings.Repeat("<R>RUB|643</R>", 20))
+	raw, err := buildEmailMessage(cfg, subject, "see attachment", emailAttachment{
+		Filename: "Currencies_20260101.tdtp.xml", ContentType: "application/xml", Data: payload,
+	}, time.Date(2026, 1, 1, 3, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg, err := mail.ReadMessage(strings.NewReader(string(raw)))
+	if err != nil {
+		t.Fatalf("ReadMessage: %v", err)
+	}
+	gotSubject, _ := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if gotSubject != "Справочник Currencies: 42 строк" {
+		t.Errorf("subject = %q", gotSubject)
+	}
+	if msg.Header.Get("Bcc") != "" || strings.Contains(string(raw), "hidden@example.org") {
+		t.Error("Bcc must not appear in message headers")
+	}
+	if msg.Header.Get("Cc") != "c@example.org" {
+		t.Errorf("Cc = %q", msg.Header.Get("Cc"))
+	}
+
+	_, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	if _, err := mr.NextPart(); err != nil { // text
+		t.Fatal(err)
+	}
+	att, err := mr.NextPart()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if att.FileName() != "Currencies_20260101.tdtp.xml" {
+		t.Errorf("attachment filename = %q", att.FileName())
+	}
+	data, err := io.ReadAll(base64.NewDecoder(base64.StdEncoding, att))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != string(payload) {
+		t.Error("attachment content mismatch")
+	}
+}
+
+// smtpCapture — конверт и DATA, принятые fake-сервером.
+type smtpCapture struct {
+	auth string
+	from string
+	rcpt []string
+	data string
+}
+
+// startFakeSMTP — минимальный SMTP-сервер без TLS: принимает одно письмо
+// и отдаёт его в канал после QUIT.
+func startFakeSMTP(t *testing.T) (int, <-chan smtpCapture) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = ln.Close() })
+	out := make(chan smtpCapture, 1)
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		r := bufio.NewReader(conn)
+		reply := func(s string) { _, _ = io.WriteString(conn, s+"\r\n") }
+		var cap smtpCapture
+
+		reply("220 fake ESMTP")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			cmd := strings.ToUpper(line)
+			switch {
+			case strings.HasPrefix(cmd, "EHLO"):
+				reply("250-fake")
+				reply("250 AUTH PLAIN")
+			case strings.HasPrefix(cmd, "AUTH PLAIN"):
+				cap.auth = strings.TrimSpace(line[len("AUTH PLAIN"):])
+				reply("235 ok")
+			case strings.HasPrefix(cmd, "MAIL FROM:"):
+				cap.from = strings.Trim(line[len("MAIL FROM:"):], "<> ")
+				reply("250 ok")
+			case strings.HasPrefix(cmd, "RCPT TO:"):
+				cap.rcpt = append(cap.rcpt, strings.Trim(line[len("RCPT TO:"):], "<> "))
+				reply("250 ok")
+			case cmd == "DATA":
+				reply("354 go ahead")
+				var sb strings.Builder
+				for {
+					l, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if l == ".\r\n" {
+						break
+					}
+					sb.WriteString(l)
+				}
+				cap.data = sb.String()
+				reply("250 queued")
+			case cmd == "QUIT":
+				reply("221 bye")
+				out <- cap
+				return
+			default:
+				reply("250 ok")
+			}
+		}
+	}()
+	return ln.Addr().(*net.TCPAddr).Port, out
+}
+
+func TestSendSMTP(t *testing.T) {
+	port, captured := startFakeSMTP(t)
+	cfg := &EmailOutputConfig{
+		Host:     "127.0.0.1",
+		Port:     port,
+		TLS:      EmailTLSNone,
+		Username: "etl",
+		Password: "s3cret",
+		From:     "TDTP <etl@example.com>",
+		To:       []string{"partner@example.org"},
+		Bcc:      []string{"archive@example.com"},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := sendSMTP(ctx, cfg, []byte("Subject: x\r\n\r\nhello\r\n")); err != nil {
+		t.Fatalf("sendSMTP: %v", err)
+	}
+
+	got := <-captured
+	auth, _ := base64.StdEncoding.DecodeString(got.auth)
+	if string(auth) != "\x00etl\x00s3cret" {
+		t.Errorf("auth = %q", auth)
+	}
+	if got.from != "etl@example.com" {
+		t.Errorf("MAIL FROM = %q", got.from)
+	}
+	if strings.Join(got.rcpt, ",") != "partner@example.org,archive@example.com" {
+		t.Errorf("RCPT TO = %v", got.rcpt)
+	}
+	if !strings.Contains(got.data, "hello") {
+		t.Errorf("DATA = %q", got.data)
+	}
+}
+
+func TestSendSMTP_StartTLSRequired(t *testing.T) {
+	port, _ := startFakeSMTP(t)
+	cfg := &EmailOutputConfig{
+		Host: "127.0.0.1", Port: port, TLS: EmailTLSStartTLS,
+		From: "etl@example.com", To: []string{"partner@example.org"},
+	}
+	err := sendSMTP(context.Background(), cfg, []byte("x"))
+	if err == nil || !strings.Contains(err.Error(), "STARTTLS") {
+		t.Fatalf("err = %v, want STARTTLS error", err)
+	}
+}
+
+func TestExportToEmail_SizeGuard(t *testing.T) {
+	e := &Exporter{config: OutputConfig{Type: "email", Email: &EmailOutputConfig{
+		Host: "127.0.0.1", Port: 1, From: "etl@example.com", To: []string{"p@example.org"}, MaxSizeKB: 1,
+	}}}
+	pkt := newTestPacketForEmail(200)
+	err := e.exportToEmail(context.Background(), pkt)
+	if err == nil || !strings.Contains(err.Error(), "max_size_kb") {
+		t.Fatalf("err = %v, want size guard error", err)
+	}
+}
+
+func newTestPacketForEmail(rows int) *packet.DataPacket {
+	pkt := packet.NewDataPacket(packet.TypeReference, "Currencies")
+	pkt.Schema = packet.Schema{Fields: []packet.Field{{Name: "id", Type: "INTEGER"}, {Name: "name", Type: "TEXT"}}}
+	for i := range rows {
+		pkt.Data.Rows = append(pkt.Data.Rows, packet.Row{Value: strconv.Itoa(i) + "|Currency name " + strconv.Itoa(i)})
+	}
+	return pkt
+}
@@ -3,20 +3,26 @@ package etl
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/ruslano69/tdtp-framework/pkg/brokers"
 	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+	tdtpcrypto "github.com/ruslano69/tdtp-framework/pkg/crypto"
 	"github.com/ruslano69/tdtp-framework/pkg/mercury"
 	"github.com/ruslano69/tdtp-framework/pkg/pipeline"
 	"github.com/ruslano69/tdtp-framework/pkg/processors"
+	"github.com/ruslano69/tdtp-framework/pkg/progress"
+	"github.com/ruslano69/tdtp-framework/pkg/report"
 	"github.com/ruslano69/tdtp-framework/pkg/resilience"
 	"github.com/ruslano69/tdtp-framework/pkg/storage"
+	"github.com/ruslano69/tdtp-framework/pkg/tracing"
 	"github.com/ruslano69/tdtp-framework/pkg/xlsx"
 )
 
@@ -39,6 +45,64 @@ type Exporter struct {
 	preExportChain *processors.Chain          // процессоры маскирования/нормализации/валидации перед экспортом
 	cb             *resilience.CircuitBreaker // circuit breaker для primary-канала (nil = без CB)
 	fast           bool                       // performance.fast: skip DetectAndApply in GenerateReference
+	parts          []StageStats               // статистика записанных частей (Stats)
+	preExportTime  time.Duration              // суммарное время preExportChain
+	throttle       ThrottleStats              // backpressure потокового экспорта
+}
+
+// Stats возвращает статистику по частям и суммарное время pre-export
+// процессоров с момента создания экспортера.
+func (e *Exporter) Stats() (parts []StageStats, preExport time.Duration) {
+	return e.parts, e.preExportTime
+}
+
+// Throttle возвращает время, проведённое потоковым экспортом в
+// backpressure (output.backpressure).
+func (e *Exporter) Throttle() ThrottleStats {
+	return e.throttle
+}
+
+// recordStreamPart добавляет статистику части streaming-экспорта (число
+// частей заранее неизвестно).
+func (e *Exporter) recordStreamPart(ctx context.Context, partNum, rows int, bytes int64, start time.Time, wait time.Duration, err error) {
+	e.addPart(ctx, StageStats{
+		Name:     fmt.Sprintf("part %d", partNum),
+		Rows:     rows,
+		Bytes:    bytes,
+		Duration: time.Since(start),
+		Wait:     wait,
+		Error:    err,
+	}, partNum, 0)
+}
+
+// recordPart добавляет статистику части: start — начало её обработки,
+// wait — время ввода-вывода внутри неё. Строки берутся из RecordsInPart:
+// у частей GenerateReference Data.Rows пуст до записи.
+func (e *Exporter) recordPart(ctx context.Context, part *packet.DataPacket, start time.Time, bytes int64, wait time.Duration, err error) {
+	rows := part.Header.RecordsInPart
+	if rows == 0 {
+		rows = len(part.Data.Rows)
+	}
+	e.addPart(ctx, StageStats{
+		Name:     fmt.Sprintf("part %d/%d", part.Header.PartNumber, part.Header.TotalParts),
+		Rows:     rows,
+		Bytes:    bytes,
+		Duration: time.Since(start),
+		Wait:     wait,
+		Error:    err,
+	}, part.Header.PartNumber, part.Header.TotalParts)
+}
+
+// addPart сохраняет статистику части и сообщает о ней (--progress-format
+// json); total == 0 — число частей заранее неизвестно (streaming).
+func (e *Exporter) addPart(ctx context.Context, st StageStats, done, total int) {
+	e.parts = append(e.parts, st)
+	ev := progress.Event{Event: progress.Progress, Step: StepExport, Item: st.Name, Rows: int64(st.Rows),
+		Done: done, Total: total, DurationMs: st.Duration.Milliseconds()}
+	if st.Error != nil {
+		ev.Error = st.Error.Error()
+	}
+	progress.Emit(ctx, ev)
 }
 
 // SetFast propagates the performance.fast flag so packet generation skips
@@ -127,12 +191,14 @@ func (e *Exporter) applyPreExport(ctx context.Context, pkt *packet.DataPacket) e
 	if e.preExportChain == nil || e.preExportChain.IsEmpty() {
 		return nil
 	}
+	start := time.Now()
+	defer func() { e.preExportTime += time.Since(start) }()
 	rows := pkt.GetRows()
 	processed, err := e.preExportChain.Process(ctx, rows, pkt.Schema)
 	if err != nil {
 		return fmt.Errorf("pre-export processor failed: %w", err)
 	}
-	pkt.Data = packet.RowsToData(processed)
+	pkt.SetRows(processed)
 	return nil
 }
 
@@ -173,6 +239,8 @@ func (e *Exporter) Export(ctx context.Context, dataPacket *packet.DataPacket) (*
 				preExportChain: e.preExportChain,
 			}
 			fbResult, err := fbExporter.exportDirect(ctx, dataPacket, *e.config.Fallback)
+			e.parts = append(e.parts, fbExporter.parts...)
+			e.preExportTime += fbExporter.preExportTime
 			if err == nil && fbResult != nil {
 				// Сообщаем куда реально ушли данные
 				result.OutputType = fbResult.OutputType + "(fallback)"
@@ -208,6 +276,13 @@ func (e *Exporter) exportDirect(ctx context.Context, dataPacket *packet.DataPack
 		RowsExported: len(dataPacket.Data.Rows),
 	}
 
+	if cfg.Priority > 0 {
+		dataPacket.Header.Priority = cfg.Priority
+	}
+	// Получатель результата продолжает трассу запуска (брокеры отправляют
+	// сам dataPacket, части tdtp получают контекст в exportToTDTP)
+	tracing.Inject(ctx, &dataPacket.Header)
+
 	// Вычисляем destination для результата
 	tmpExporter := &Exporter{config: cfg}
 	result.Destination = tmpExporter.getDestination()
@@ -228,11 +303,26 @@ func (e *Exporter) exportDirect(ctx context.Context, dataPacket *packet.DataPack
 		result.Error = err
 		return result, err
 
+	case "filequeue":
+		err := e.exportToFileQueue(ctx, dataPacket)
+		result.Error = err
+		return result, err
+
+	case "email":
+		err := e.exportToEmail(ctx, dataPacket)
+		result.Error = err
+		return result, err
+
 	case "xlsx":
 		err := e.exportToXLSX(dataPacket)
 		result.Error = err
 		return result, err
 
+	case "report":
+		err := e.exportToReport(ctx, dataPacket)
+		result.Error = err
+		return result, err
+
 	default:
 		err := fmt.Errorf("unsupported output type: %s", cfg.Type)
 		result.Error = err
@@ -282,11 +372,20 @@ func (e *Exporter) exportToTDTP(ctx context.Context, dataPacket *packet.DataPack
 		integrityRegistrar = e.resolveHashRegistrar()
 	}
 
+	if e.config.TDTP.DictDir != "" {
+		if _, err := processors.LoadZstdDictDir(e.config.TDTP.DictDir); err != nil {
+			return fmt.Errorf("failed to load compression dictionaries: %w", err)
+		}
+	}
+
 	for _, part := range parts {
+		partStart := time.Now()
+
 		// Встраиваем метаданные pipeline (v1.4) если заданы
 		if e.pipelineCtx != nil {
 			part.PipelineContext = e.pipelineCtx
 		}
+		tracing.Inject(ctx, &part.Header)
 
 		// v1.4 integrity is mandatory ahead of v1.5 encryption, not
 		// opt-in — see pkg/pipeline/produce.go's doc comment: without
@@ -316,7 +415,11 @@ func (e *Exporter) exportToTDTP(ctx context.Context, dataPacket *packet.DataPack
 			if err != nil {
 				return fmt.Errorf("failed to generate XML for part %d: %w", part.Header.PartNumber, err)
 			}
-			if err := e.exportEncrypted(ctx, generator, xmlData, partDest); err != nil {
+			// Wait — шифрование с запросом ключа у xZMercury и запись
+			ioStart := time.Now()
+			err = e.exportEncrypted(ctx, generator, xmlData, partDest)
+			e.recordPart(ctx, part, partStart, int64(len(xmlData)), time.Since(ioStart), err)
+			if err != nil {
 				return err
 			}
 			continue
@@ -327,7 +430,10 @@ func (e *Exporter) exportToTDTP(ctx context.Context, dataPacket *packet.DataPack
 			// (QueryContext/Schema/Data go opaque, Header stays plain) —
 			// marshal happens AFTER, inside exportEncryptedV15, so it
 			// serializes the encrypted state, not the original plaintext.
-			if err := e.exportEncryptedV15(ctx, generator, part, partDest); err != nil {
+			ioStart := time.Now()
+			err := e.exportEncryptedV15(ctx, generator, part, partDest)
+			e.recordPart(ctx, part, partStart, 0, time.Since(ioStart), err)
+			if err != nil {
 				return err
 			}
 			continue
@@ -338,14 +444,15 @@ func (e *Exporter) exportToTDTP(ctx context.Context, dataPacket *packet.DataPack
 			return fmt.Errorf("failed to generate XML for part %d: %w", part.Header.PartNumber, err)
 		}
 
+		ioStart := time.Now()
 		if storage.IsRemote(partDest) {
-			if err := e.uploadToStorage(ctx, xmlData, partDest, part); err != nil {
-				return err
-			}
-		} else {
-			if err := os.WriteFile(partDest, xmlData, 0o600); err != nil {
-				return fmt.Errorf("failed to write part %d: %w", part.Header.PartNumber, err)
-			}
+			err = e.uploadToStorage(ctx, xmlData, partDest, part)
+		} else if err = os.WriteFile(partDest, xmlData, 0o600); err != nil {
+			err = fmt.Errorf("failed to write part %d: %w", part.Header.PartNumber, err)
+		}
+		e.recordPart(ctx, part, partStart, int64(len(xmlData)), time.Since(ioStart), err)
+		if err != nil {
+			return err
 		}
 	}
 	return nil
@@ -430,19 +537,10 @@ func (e *Exporter) exportEncrypted(ctx context.Context, generator *packet.Genera
 		return fmt.Errorf("encryption enabled but packageUUID not set")
 	}
 
-	// Выбираем Mercury-клиент: кастомный (DevClient / тесты) или production-клиент по конфигу
-	var binder processors.MercuryBinder
-	if e.mercuryBinder != nil {
-		binder = e.mercuryBinder
-	} else {
-		binder = mercury.NewClient(e.security.MercuryURL, e.security.MercuryTimeoutMs)
-	}
-
-	serverSecret := e.security.ServerSecret
-	if serverSecret == "" {
-		serverSecret = os.Getenv("MERCURY_SERVER_SECRET")
+	encryptor, err := e.newFileEncryptor(e.packageUUID)
+	if err != nil {
+		return err
 	}
-	encryptor := processors.NewFileEncryptor(binder, serverSecret, e.packageUUID, e.pipelineName)
 
 	result, errCode, encErr := encryptor.Encrypt(ctx, xmlData)
 	if encErr != nil {
@@ -465,6 +563,38 @@ func (e *Exporter) exportEncrypted(ctx context.Context, generator *packet.Genera
 	return nil
 }
 
+// newFileEncryptor создаёт FileEncryptor для packageUUID по SecurityConfig:
+// Mercury-клиент (или замена из WithMercuryBinder), HMAC-секрет и открытый
+// ключ депозитария, если включено депонирование ключей.
+func (e *Exporter) newFileEncryptor(packageUUID string) (*processors.FileEncryptor, error) {
+	// Выбираем Mercury-клиент: кастомный (DevClient / тесты) или production-клиент по конфигу
+	var binder processors.MercuryBinder
+	if e.mercuryBinder != nil {
+		binder = e.mercuryBinder
+	} else {
+		binder = mercury.NewClient(e.security.MercuryURL, e.security.MercuryTimeoutMs)
+	}
+
+	serverSecret := e.security.ServerSecret
+	if serverSecret == "" {
+		serverSecret = os.Getenv("MERCURY_SERVER_SECRET")
+	}
+	encryptor := processors.NewFileEncryptor(binder, serverSecret, packageUUID, e.pipelineName)
+
+	escrowPath := e.security.EscrowPublicKey
+	if escrowPath == "" {
+		escrowPath = os.Getenv("TDTP_ESCROW_PUBLIC_KEY")
+	}
+	if escrowPath != "" {
+		escrow, err := tdtpcrypto.LoadEscrowKey(escrowPath)
+		if err != nil {
+			return nil, err
+		}
+		encryptor.SetEscrow(escrow)
+	}
+	return encryptor, nil
+}
+
 // exportEncryptedV15 шифрует part TDTP v1.5 section-level форматом
 // (QueryContext/Schema/Data становятся ciphertext, Header остаётся plain
 // XML) и записывает результат в destination. Ключ привязывается к
@@ -484,18 +614,10 @@ func (e *Exporter) exportEncryptedV15(ctx context.Context, generator *packet.Gen
 		return fmt.Errorf("v1.5 encryption requires part.Header.MessageID to be set")
 	}
 
-	var binder processors.MercuryBinder
-	if e.mercuryBinder != nil {
-		binder = e.mercuryBinder
-	} else {
-		binder = mercury.NewClient(e.security.MercuryURL, e.security.MercuryTimeoutMs)
-	}
-
-	serverSecret := e.security.ServerSecret
-	if serverSecret == "" {
-		serverSecret = os.Getenv("MERCURY_SERVER_SECRET")
+	encryptor, err := e.newFileEncryptor(packageUUID)
+	if err != nil {
+		return err
 	}
-	encryptor := processors.NewFileEncryptor(binder, serverSecret, packageUUID, e.pipelineName)
 
 	errCode, encErr := encryptor.EncryptSectionsV15(ctx, part)
 	if encErr != nil {
@@ -553,13 +675,14 @@ func (e *Exporter) exportToRabbitMQ(ctx context.Context, dataPacket *packet.Data
 
 	// Создаем broker
 	broker, err := brokers.New(brokers.Config{
-		Type:     "rabbitmq",
-		Host:     cfg.Host,
-		Port:     cfg.Port,
-		User:     cfg.User,
-		Password: cfg.Password,
-		Queue:    cfg.Queue,
-		Durable:  true, // Очередь переживает перезапуск
+		Type:        "rabbitmq",
+		Host:        cfg.Host,
+		Port:        cfg.Port,
+		User:        cfg.User,
+		Password:    cfg.Password,
+		Queue:       cfg.Queue,
+		Durable:     true, // Очередь переживает перезапуск
+		MaxPriority: cfg.MaxPriority,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to create RabbitMQ broker: %w", err)
@@ -584,13 +707,47 @@ func (e *Exporter) exportToRabbitMQ(ctx context.Context, dataPacket *packet.Data
 	}
 
 	// Отправляем в RabbitMQ
-	if err := broker.Send(ctx, xmlData); err != nil {
+	if err := brokers.SendWithPriority(ctx, broker, xmlData, dataPacket.Header.Priority); err != nil {
 		return fmt.Errorf("failed to send to RabbitMQ: %w", err)
 	}
 
 	return nil
 }
 
+// exportToFileQueue экспортирует в файловую очередь.
+// Части генерируются так же, как для RabbitMQ (один пакет = одно сообщение).
+func (e *Exporter) exportToFileQueue(ctx context.Context, dataPacket *packet.DataPacket) error {
+	if e.config.FileQueue == nil {
+		return fmt.Errorf("filequeue config is not set")
+	}
+
+	broker, err := brokers.New(brokers.Config{
+		Type:  "filequeue",
+		Dir:   e.config.FileQueue.Dir,
+		Queue: e.config.FileQueue.Queue,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create filequeue broker: %w", err)
+	}
+	if err := broker.Connect(ctx); err != nil {
+		return fmt.Errorf("failed to open filequeue: %w", err)
+	}
+	defer func() { _ = broker.Close() }()
+
+	if e.pipelineCtx != nil {
+		dataPacket.PipelineContext = e.pipelineCtx
+	}
+
+	xmlData, err := packet.NewGenerator().ToXML(dataPacket, false)
+	if err != nil {
+		return fmt.Errorf("failed to generate XML: %w", err)
+	}
+	if err := brokers.SendWithPriority(ctx, broker, xmlData, dataPacket.Header.Priority); err != nil {
+		return fmt.Errorf("failed to send to filequeue: %w", err)
+	}
+	return nil
+}
+
 // exportToKafka экспортирует в Kafka.
 //
 // Маршруты (выбираются автоматически по конфигу):
@@ -617,9 +774,10 @@ func (e *Exporter) exportToKafka(ctx context.Context, dataPacket *packet.DataPac
 
 	// ── Legacy: один пакет = одно Kafka-сообщение ─────────────────────────
 	broker, err := brokers.New(brokers.Config{
-		Type:    "kafka",
-		Brokers: cfg.Brokers,
-		Topic:   cfg.Topic,
+		Type:        "kafka",
+		Brokers:     cfg.Brokers,
+		Topic:       cfg.Topic,
+		UrgentTopic: cfg.UrgentTopic,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to create Kafka broker: %w", err)
@@ -640,7 +798,7 @@ func (e *Exporter) exportToKafka(ctx context.Context, dataPacket *packet.DataPac
 		return fmt.Errorf("failed to generate XML: %w", err)
 	}
 
-	if err := broker.Send(ctx, xmlData); err != nil {
+	if err := brokers.SendWithPriority(ctx, broker, xmlData, dataPacket.Header.Priority); err != nil {
 		return fmt.Errorf("failed to send to Kafka: %w", err)
 	}
 
@@ -721,6 +879,141 @@ func (e *Exporter) exportToXLSX(dataPacket *packet.DataPacket) error {
 	return xlsx.ToXLSX(dataPacket, destination, e.config.XLSX.Sheet)
 }
 
+// exportToReport рендерит результат в отчёт по шаблону (HTML, PDF или XLSX).
+func (e *Exporter) exportToReport(ctx context.Context, dataPacket *packet.DataPacket) error {
+	cfg := e.config.Report
+	if cfg == nil {
+		return fmt.Errorf("report configuration is not set")
+	}
+
+	r, err := report.New(dataPacket)
+	if err != nil {
+		return fmt.Errorf("failed to prepare report: %w", err)
+	}
+	for k, v := range cfg.Params {
+		r.Params[k] = v
+	}
+
+	if dir := filepath.Dir(cfg.Destination); dir != "." {
+		if err := os.MkdirAll(dir, 0o750); err != nil {
+			return fmt.Errorf("failed to create output directory: %w", err)
+		}
+	}
+
+	if cfg.Format() == "xlsx" {
+		return r.RenderXLSX(cfg.Template, cfg.Destination)
+	}
+
+	tmpl, err := os.ReadFile(cfg.Template)
+	if err != nil {
+		return fmt.Errorf("failed to read report template: %w", err)
+	}
+	switch cfg.Format() {
+	case "html":
+		f, err := os.Create(cfg.Destination)
+		if err != nil {
+			return fmt.Errorf("failed to create report: %w", err)
+		}
+		err = r.RenderHTML(f, string(tmpl))
+		if cerr := f.Close(); err == nil && cerr != nil {
+			err = fmt.Errorf("failed to write report: %w", cerr)
+		}
+		return err
+	case "pdf":
+		pdf := report.DefaultPDFConfig()
+		if len(cfg.PDFCommand) > 0 {
+			pdf = report.PDFConfig{Command: cfg.PDFCommand[0], Args: cfg.PDFCommand[1:]}
+		}
+		return r.RenderPDF(ctx, string(tmpl), cfg.Destination, pdf)
+	default:
+		return fmt.Errorf("unsupported report destination %s", cfg.Destination)
+	}
+}
+
+// exportToEmail отправляет пакет (TDTP XML или XLSX) вложением по email.
+// Вложение больше email.max_size_kb не отправляется: почта — канал для
+// небольших справочников, большие выгрузки — через tdtp/s3 или брокер.
+func (e *Exporter) exportToEmail(ctx context.Context, dataPacket *packet.DataPacket) error {
+	if e.config.Email == nil {
+		return fmt.Errorf("email configuration is not set")
+	}
+	cfg := *e.config.Email
+	setEmailDefaults(&cfg)
+
+	if e.pipelineCtx != nil {
+		dataPacket.PipelineContext = e.pipelineCtx
+	}
+
+	now := time.Now()
+	table := dataPacket.Header.TableName
+	att := emailAttachment{Filename: fmt.Sprintf("%s_%s", table, now.Format("20060102"))}
+	switch cfg.Format {
+	case "xlsx":
+		data, err := renderXLSXBytes(dataPacket)
+		if err != nil {
+			return err
+		}
+		att.Filename += ".xlsx"
+		att.ContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+		att.Data = data
+	default:
+		data, err := packet.NewGenerator().ToXML(dataPacket, false)
+		if err != nil {
+			return fmt.Errorf("failed to generate XML: %w", err)
+		}
+		att.Filename += ".tdtp.xml"
+		att.ContentType = "application/xml"
+		att.Data = data
+	}
+
+	if limit := cfg.MaxSizeKB * 1024; len(att.Data) > limit {
+		return fmt.Errorf("email attachment %s is %d KB, exceeds email.max_size_kb=%d — use tdtp/s3 output for large exports",
+			att.Filename, (len(att.Data)+1023)/1024, cfg.MaxSizeKB)
+	}
+
+	data := emailTemplateData{
+		Table:    table,
+		Rows:     len(dataPacket.Data.Rows),
+		Date:     now.Format("2006-01-02"),
+		Time:     now,
+		Pipeline: e.pipelineName,
+		Filename: att.Filename,
+	}
+	subject, err := renderEmailTemplate("subject", cfg.Subject, data)
+	if err != nil {
+		return err
+	}
+	body, err := renderEmailTemplate("body", cfg.Body, data)
+	if err != nil {
+		return err
+	}
+
+	msg, err := buildEmailMessage(&cfg, subject, body, att, now)
+	if err != nil {
+		return fmt.Errorf("failed to build email: %w", err)
+	}
+	if err := sendSMTP(ctx, &cfg, msg); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
+
+// renderXLSXBytes рендерит пакет в XLSX через временный файл
+// (xlsx.ToXLSX пишет только в файл).
+func renderXLSXBytes(dataPacket *packet.DataPacket) ([]byte, error) {
+	dir, err := os.MkdirTemp("", "tdtp-email-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	path := filepath.Join(dir, "export.xlsx")
+	if err := xlsx.ToXLSX(dataPacket, path, ""); err != nil {
+		return nil, fmt.Errorf("failed to render XLSX: %w", err)
+	}
+	return os.ReadFile(path)
+}
+
 // lastSep возвращает позицию последнего разделителя пути (/ или \).
 func lastSep(path string) int {
 	for i := len(path) - 1; i >= 0; i-- {
@@ -751,10 +1044,22 @@ func (e *Exporter) getDestination() string {
 				e.config.Kafka.Brokers,
 				e.config.Kafka.Topic)
 		}
+	case "filequeue":
+		if e.config.FileQueue != nil {
+			return filepath.Join(e.config.FileQueue.Dir, e.config.FileQueue.Queue)
+		}
+	case "email":
+		if e.config.Email != nil {
+			return "mailto:" + strings.Join(e.config.Email.To, ",")
+		}
 	case "xlsx":
 		if e.config.XLSX != nil {
 			return e.config.XLSX.Destination
 		}
+	case "report":
+		if e.config.Report != nil {
+			return e.config.Report.Destination
+		}
 	}
 	return "unknown"
 }
@@ -796,6 +1101,28 @@ func (e *Exporter) ValidateConfig() error {
 			return fmt.Errorf("kafka topic is required")
 		}
 
+	case "filequeue":
+		if e.config.FileQueue == nil {
+			return fmt.Errorf("filequeue config is required for filequeue output")
+		}
+		if e.config.FileQueue.Dir == "" {
+			return fmt.Errorf("filequeue dir is required")
+		}
+
+	case "email":
+		if e.config.Email == nil {
+			return fmt.Errorf("email config is required for email output")
+		}
+		return e.config.Email.Validate()
+
+	case "report":
+		if e.config.Report == nil {
+			return fmt.Errorf("report config is required for report output")
+		}
+		if e.config.Report.Template == "" || e.config.Report.Destination == "" {
+			return fmt.Errorf("report template and destination are required")
+		}
+
 	default:
 		return fmt.Errorf("unsupported output type: %s", e.config.Type)
 	}
@@ -836,7 +1163,18 @@ func (e *Exporter) compressDataPacket(dataPacket *packet.DataPacket, algo string
 		return nil
 	}
 
-	compressedData, stats, err := processors.CompressDataForTdtpAlgo(rowStrings, algo, level)
+	// zstd со словарём таблицы, если он загружен (tdtp.dict_dir)
+	var (
+		compressedData string
+		stats          processors.CompressionStats
+		err            error
+	)
+	dict := processors.TableZstdDict(dataPacket.Header.TableName)
+	if algo == processors.AlgoZstd && dict != nil {
+		compressedData, stats, err = processors.CompressDataForTdtpDict(rowStrings, level, dict)
+	} else {
+		compressedData, stats, err = processors.CompressDataForTdtpAlgo(rowStrings, algo, level)
+	}
 	if err != nil {
 		return fmt.Errorf("compression failed: %w", err)
 	}
@@ -846,15 +1184,20 @@ func (e *Exporter) compressDataPacket(dataPacket *packet.DataPacket, algo string
 		return nil
 	}
 
-	// Обновляем DataPacket сжатыми данными, сохраняя compact/tail атрибуты
+	// Обновляем DataPacket сжатыми данными, сохраняя compact/tail/delta/delete атрибуты
 	dataPacket.Data = packet.Data{
 		Compression: algo,
 		Compact:     dataPacket.Data.Compact,
 		Tail:        dataPacket.Data.Tail,
+		Delta:       dataPacket.Data.Delta,
+		Delete:      dataPacket.Data.Delete,
 		Rows: []packet.Row{
 			{Value: compressedData},
 		},
 	}
+	if algo == processors.AlgoZstd && dict != nil {
+		processors.SetPacketZstdDict(dataPacket, dict, e.config.TDTP != nil && e.config.TDTP.EmbedDict)
+	}
 
 	return nil
 }
@@ -868,6 +1211,7 @@ type StreamingExportResult struct {
 	PartsSent   int
 	ErrorsCount int
 	Errors      []error
+	Throttle    ThrottleStats // backpressure (output.backpressure)
 }
 
 // ExportStream выполняет потоковый экспорт данных в RabbitMQ/Kafka
@@ -890,6 +1234,9 @@ func (e *Exporter) ExportStream(ctx context.Context, streamResult *StreamingResu
 	case "kafka":
 		return e.exportStreamToKafka(ctx, streamResult, tableName)
 
+	case "filequeue":
+		return e.exportStreamToFileQueue(ctx, streamResult, tableName)
+
 	case "tdtp":
 		// Для файлового экспорта используем batch режим (нужно знать TotalParts заранее)
 		return nil, fmt.Errorf("streaming export to TDTP files is not supported, use batch Export() instead")
@@ -916,13 +1263,14 @@ func (e *Exporter) exportStreamToRabbitMQ(ctx context.Context, streamResult *Str
 
 	// Создаем broker
 	broker, err := brokers.New(brokers.Config{
-		Type:     "rabbitmq",
-		Host:     cfg.Host,
-		Port:     cfg.Port,
-		User:     cfg.User,
-		Password: cfg.Password,
-		Queue:    cfg.Queue,
-		Durable:  true,
+		Type:        "rabbitmq",
+		Host:        cfg.Host,
+		Port:        cfg.Port,
+		User:        cfg.User,
+		Password:    cfg.Password,
+		Queue:       cfg.Queue,
+		Durable:     true,
+		MaxPriority: cfg.MaxPriority,
 	})
 	if err != nil {
 		result.Errors = append(result.Errors, err)
@@ -948,9 +1296,11 @@ func (e *Exporter) exportStreamToKafka(ctx context.Context, streamResult *Stream
 
 	// Создаем broker
 	broker, err := brokers.New(brokers.Config{
-		Type:    "kafka",
-		Brokers: cfg.Brokers,
-		Topic:   cfg.Topic,
+		Type:          "kafka",
+		Brokers:       cfg.Brokers,
+		Topic:         cfg.Topic,
+		UrgentTopic:   cfg.UrgentTopic,
+		ConsumerGroup: cfg.ConsumerGroup,
 	})
 	if err != nil {
 		result.Errors = append(result.Errors, err)
@@ -961,7 +1311,33 @@ func (e *Exporter) exportStreamToKafka(ctx context.Context, streamResult *Stream
 	return e.exportStreamToBroker(ctx, broker, streamResult, tableName, result)
 }
 
-// exportStreamToBroker выполняет общую логику потокового экспорта в любой broker (RabbitMQ/Kafka)
+// exportStreamToFileQueue выполняет потоковый экспорт в файловую очередь
+func (e *Exporter) exportStreamToFileQueue(ctx context.Context, streamResult *StreamingResult, tableName string) (*StreamingExportResult, error) {
+	if e.config.FileQueue == nil {
+		return nil, fmt.Errorf("filequeue config is not set")
+	}
+
+	cfg := e.config.FileQueue
+
+	result := &StreamingExportResult{
+		OutputType:  "FileQueue",
+		Destination: filepath.Join(cfg.Dir, cfg.Queue),
+	}
+
+	broker, err := brokers.New(brokers.Config{
+		Type:  "filequeue",
+		Dir:   cfg.Dir,
+		Queue: cfg.Queue,
+	})
+	if err != nil {
+		result.Errors = append(result.Errors, err)
+		return result, fmt.Errorf("failed to create filequeue broker: %w", err)
+	}
+
+	return e.exportStreamToBroker(ctx, broker, streamResult, tableName, result)
+}
+
+// exportStreamToBroker выполняет общую логику потокового экспорта в любой broker (RabbitMQ/Kafka/filequeue)
 func (e *Exporter) exportStreamToBroker(ctx context.Context, broker brokers.MessageBroker, streamResult *StreamingResult, tableName string, result *StreamingExportResult) (*StreamingExportResult, error) {
 	// Подключаемся к broker
 	if err := broker.Connect(ctx); err != nil {
@@ -970,6 +1346,18 @@ func (e *Exporter) exportStreamToBroker(ctx context.Context, broker brokers.Mess
 	}
 	defer func() { _ = broker.Close() }()
 
+	// Остановка по backpressure отменяет генерацию оставшихся частей
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	throttle := newThrottle(e.config.Backpressure, broker)
+	var throttleErr error
+	defer func() {
+		if throttle != nil {
+			result.Throttle = throttle.stats
+			e.throttle = throttle.stats
+		}
+	}()
+
 	// Создаем streaming generator
 	streamGen := packet.NewStreamingGenerator()
 
@@ -984,6 +1372,10 @@ func (e *Exporter) exportStreamToBroker(ctx context.Context, broker brokers.Mess
 
 	// Обрабатываем части по мере их генерации
 	for part := range partsChan {
+		if throttleErr != nil {
+			continue // дочитываем канал до остановки генератора
+		}
+		partStart := time.Now()
 		if part.Error != nil {
 			result.Errors = append(result.Errors, part.Error)
 			result.ErrorsCount++
@@ -997,6 +1389,10 @@ func (e *Exporter) exportStreamToBroker(ctx context.Context, broker brokers.Mess
 			continue
 		}
 
+		if e.config.Priority > 0 {
+			part.Packet.Header.Priority = e.config.Priority
+		}
+
 		// Генерируем XML из пакета
 		generator := packet.NewGenerator()
 		xmlData, err := generator.ToXML(part.Packet, false) // compact XML
@@ -1006,9 +1402,19 @@ func (e *Exporter) exportStreamToBroker(ctx context.Context, broker brokers.Mess
 			continue
 		}
 
+		// Очередь переполнена — ждём потребителя
+		if err := throttle.wait(ctx); err != nil {
+			throttleErr = fmt.Errorf("part %d: %w", part.PartNum, err)
+			cancel()
+			continue
+		}
+
 		// Отправляем в broker
-		if err := broker.Send(ctx, xmlData); err != nil {
-			result.Errors = append(result.Errors, fmt.Errorf("failed to send part %d to broker: %w", part.PartNum, err))
+		sendStart := time.Now()
+		sendErr := brokers.SendWithPriority(ctx, broker, xmlData, part.Packet.Header.Priority)
+		e.recordStreamPart(ctx, part.PartNum, len(part.Packet.Data.Rows), int64(len(xmlData)), partStart, time.Since(sendStart), sendErr)
+		if sendErr != nil {
+			result.Errors = append(result.Errors, fmt.Errorf("failed to send part %d to broker: %w", part.PartNum, sendErr))
 			result.ErrorsCount++
 			continue
 		}
@@ -1016,6 +1422,12 @@ func (e *Exporter) exportStreamToBroker(ctx context.Context, broker brokers.Mess
 		result.PartsSent++
 	}
 
+	if throttleErr != nil {
+		result.Errors = append(result.Errors, throttleErr)
+		result.ErrorsCount++
+		return result, fmt.Errorf("streaming export aborted: %w", throttleErr)
+	}
+
 	// Проверяем ошибки из канала ErrorChan
 	select {
 	case err := <-streamResult.ErrorChan:
@@ -1042,6 +1454,13 @@ func (e *Exporter) exportStreamToBroker(ctx context.Context, broker brokers.Mess
 
 	// Если были ошибки при отправке частей, возвращаем ошибку
 	if result.ErrorsCount > 0 {
+		// Превышение лимита — не сбой отправки: наружу идёт его диагностика
+		var budgetErr *BudgetError
+		for _, err := range result.Errors {
+			if errors.As(err, &budgetErr) {
+				return result, fmt.Errorf("streaming export aborted: %w", budgetErr)
+			}
+		}
 		return result, fmt.Errorf("streaming export completed with %d errors", result.ErrorsCount)
 	}
 
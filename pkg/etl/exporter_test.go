@@ -1,6 +1,9 @@
 package etl
 
 import (
+	"context"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
@@ -351,3 +354,40 @@ func TestExporter_ValidateConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestExporter_ExportToReport_HTML(t *testing.T) {
+	dir := t.TempDir()
+	tmpl := filepath.Join(dir, "report.html")
+	dest := filepath.Join(dir, "out", "report.html")
+	if err := os.WriteFile(tmpl, []byte(`{{.Params.title}}: {{count "result"}} rows, max id {{max "result" "id"}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	pkt := packet.NewDataPacket(packet.TypeReference, "result")
+	pkt.Schema = packet.Schema{Fields: []packet.Field{{Name: "id", Type: "INTEGER", Key: true}}}
+	pkt.SetRows([][]string{{"7"}, {"12"}, {"9"}})
+
+	exp := NewExporter(OutputConfig{
+		Type: "report",
+		Report: &ReportOutputConfig{
+			Template:    tmpl,
+			Destination: dest,
+			Params:      map[string]string{"title": "Daily"},
+		},
+	})
+	result, err := exp.Export(context.Background(), pkt)
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	if result.Destination != dest {
+		t.Errorf("Destination = %q, want %q", result.Destination, dest)
+	}
+
+	got, err := os.ReadFile(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Daily: 3 rows, max id 12"; string(got) != want {
+		t.Errorf("report = %q, want %q", got, want)
+	}
+}
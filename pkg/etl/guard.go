@@ -0,0 +1,134 @@
+package etl
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"runtime"
+)
+
+// ErrBudgetExceeded — превышен performance.max_rows или max_memory_mb
+// (errors.Is для *BudgetError).
+var ErrBudgetExceeded = errors.New("pipeline resource budget exceeded")
+
+// guardCheckEvery — как часто (в строках) проверяются лимиты при загрузке
+// workspace и чтении результата transform. ReadMemStats останавливает мир
+// на десятки микросекунд — на каждой строке это слишком дорого.
+const guardCheckEvery = 10000
+
+// BudgetError — диагностика раннего abort: какой лимит, на каком шаге и
+// каком источнике превышен.
+type BudgetError struct {
+	Limit  string // "max_rows" или "max_memory_mb"
+	Step   string // StepLoad, StepPopulate, StepTransform, StepTransformExport
+	Source string // источник; "" — результат transform
+	Used   int64  // строк или MB
+	Max    int64
+}
+
+func (e *BudgetError) Error() string {
+	where := e.Step
+	if e.Source != "" {
+		where = fmt.Sprintf("%s of source '%s'", e.Step, e.Source)
+	}
+	unit := "rows"
+	if e.Limit == "max_memory_mb" {
+		unit = "MB"
+	}
+	return fmt.Sprintf("performance.%s exceeded during %s: %d %s > %d (raise the limit or narrow the source with filters/fields)",
+		e.Limit, where, e.Used, unit, e.Max)
+}
+
+// Is делает BudgetError совместимой с errors.Is(err, ErrBudgetExceeded).
+func (e *BudgetError) Is(target error) bool {
+	return target == ErrBudgetExceeded
+}
+
+// ResourceGuard следит за performance.max_rows и max_memory_mb и
+// прерывает пайплайн до того, как процесс убьёт OOM. Память — Go heap
+// плюс страницы SQLite workspace (modernc выделяет их вне Go heap).
+// nil-guard ничего не проверяет.
+type ResourceGuard struct {
+	maxRows     int64
+	maxBytes    uint64
+	rows        int64  // строк, загруженных в workspace
+	workspaceSz uint64 // последний замер размера workspace
+
+	memUsage func() uint64 // замена heap-замера в тестах
+}
+
+// rowQuerier — *sql.DB или *sql.Tx workspace.
+type rowQuerier interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// NewResourceGuard создаёт guard по PerformanceConfig (nil — лимитов нет).
+func NewResourceGuard(perf PerformanceConfig) *ResourceGuard {
+	if perf.MaxRows <= 0 && perf.MaxMemoryMB <= 0 {
+		return nil
+	}
+	return &ResourceGuard{
+		maxRows:  int64(max(perf.MaxRows, 0)),
+		maxBytes: uint64(max(perf.MaxMemoryMB, 0)) << 20,
+	}
+}
+
+// AddRows учитывает n строк источника, загруженных в workspace.
+func (g *ResourceGuard) AddRows(step, source string, n int) error {
+	if g == nil {
+		return nil
+	}
+	g.rows += int64(n)
+	if g.maxRows > 0 && g.rows > g.maxRows {
+		return &BudgetError{Limit: "max_rows", Step: step, Source: source, Used: g.rows, Max: g.maxRows}
+	}
+	return nil
+}
+
+// CheckRows проверяет строки, материализованные вне workspace (результат
+// transform), против max_rows.
+func (g *ResourceGuard) CheckRows(step, source string, rows int) error {
+	if g == nil || g.maxRows <= 0 || int64(rows) <= g.maxRows {
+		return nil
+	}
+	return &BudgetError{Limit: "max_rows", Step: step, Source: source, Used: int64(rows), Max: g.maxRows}
+}
+
+// CheckMemory проверяет max_memory_mb. q — соединение workspace для
+// замера его размера; nil — взять последний замер (workspace :memory:
+// живёт в одном соединении, и пока открыт курсор SELECT, второе соединение
+// увидело бы пустую базу). Перед abort запускает GC и меряет ещё раз:
+// HeapAlloc включает мусор, который просто не успели собрать.
+func (g *ResourceGuard) CheckMemory(ctx context.Context, q rowQuerier, step, source string) error {
+	if g == nil || g.maxBytes == 0 {
+		return nil
+	}
+	if q != nil {
+		var size int64
+		err := q.QueryRowContext(ctx,
+			"SELECT page_count * page_size FROM pragma_page_count(), pragma_page_size()").Scan(&size)
+		if err == nil && size > 0 {
+			g.workspaceSz = uint64(size)
+		}
+	}
+	if g.heap()+g.workspaceSz <= g.maxBytes {
+		return nil
+	}
+	runtime.GC()
+	used := g.heap() + g.workspaceSz
+	if used <= g.maxBytes {
+		return nil
+	}
+	return &BudgetError{Limit: "max_memory_mb", Step: step, Source: source,
+		Used: int64(used >> 20), Max: int64(g.maxBytes >> 20)}
+}
+
+func (g *ResourceGuard) heap() uint64 {
+	if g.memUsage != nil {
+		return g.memUsage()
+	}
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	return ms.HeapAlloc
+}
@@ -0,0 +1,100 @@
+package etl
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+func TestNewResourceGuard_NoLimits(t *testing.T) {
+	g := NewResourceGuard(PerformanceConfig{})
+	if g != nil {
+		t.Fatalf("guard without limits = %+v, want nil", g)
+	}
+	// nil-guard — no-op
+	if err := g.AddRows(StepPopulate, "orders", 1<<30); err != nil {
+		t.Errorf("nil AddRows: %v", err)
+	}
+	if err := g.CheckMemory(context.Background(), nil, StepLoad, ""); err != nil {
+		t.Errorf("nil CheckMemory: %v", err)
+	}
+}
+
+func TestResourceGuard_MaxRows(t *testing.T) {
+	g := NewResourceGuard(PerformanceConfig{MaxRows: 100})
+
+	if err := g.AddRows(StepPopulate, "orders", 60); err != nil {
+		t.Fatalf("AddRows under limit: %v", err)
+	}
+	err := g.AddRows(StepPopulate, "customers", 50)
+	var be *BudgetError
+	if !errors.As(err, &be) || !errors.Is(err, ErrBudgetExceeded) {
+		t.Fatalf("AddRows over limit = %v, want *BudgetError", err)
+	}
+	if be.Limit != "max_rows" || be.Source != "customers" || be.Used != 110 || be.Max != 100 {
+		t.Errorf("budget error = %+v", be)
+	}
+	if !strings.Contains(err.Error(), "populate of source 'customers'") {
+		t.Errorf("diagnostic does not name the source: %v", err)
+	}
+
+	if err := g.CheckRows(StepTransform, "", 101); err == nil {
+		t.Error("CheckRows(101) with max_rows 100 passed")
+	}
+}
+
+func TestResourceGuard_MaxMemory(t *testing.T) {
+	g := NewResourceGuard(PerformanceConfig{MaxMemoryMB: 64})
+	heap := uint64(10 << 20)
+	g.memUsage = func() uint64 { return heap }
+
+	if err := g.CheckMemory(context.Background(), nil, StepLoad, "orders"); err != nil {
+		t.Fatalf("CheckMemory under limit: %v", err)
+	}
+
+	heap = 100 << 20
+	err := g.CheckMemory(context.Background(), nil, StepLoad, "orders")
+	var be *BudgetError
+	if !errors.As(err, &be) {
+		t.Fatalf("CheckMemory over limit = %v, want *BudgetError", err)
+	}
+	if be.Limit != "max_memory_mb" || be.Step != StepLoad || be.Source != "orders" || be.Used != 100 || be.Max != 64 {
+		t.Errorf("budget error = %+v", be)
+	}
+}
+
+func TestWorkspace_LoadData_AbortsOnMaxRows(t *testing.T) {
+	ctx := context.Background()
+	ws, err := NewWorkspace(ctx)
+	if err != nil {
+		t.Fatalf("NewWorkspace: %v", err)
+	}
+	defer func() { _ = ws.Close(ctx) }()
+	ws.SetGuard(NewResourceGuard(PerformanceConfig{MaxRows: guardCheckEvery}))
+
+	fields := []packet.Field{{Name: "id", Type: "INTEGER"}}
+	if err := ws.CreateTable(ctx, "big", fields); err != nil {
+		t.Fatalf("CreateTable: %v", err)
+	}
+	rows := make([][]string, guardCheckEvery+1)
+	for i := range rows {
+		rows[i] = []string{strconv.Itoa(i)}
+	}
+	pkt := packet.NewDataPacket(packet.TypeReference, "big")
+	pkt.Schema.Fields = fields
+	pkt.Data = packet.RowsToData(rows)
+
+	err = ws.LoadData(ctx, "big", pkt)
+	if !errors.Is(err, ErrBudgetExceeded) {
+		t.Fatalf("LoadData = %v, want budget error", err)
+	}
+	// Транзакция откатилась — в workspace ничего не осталось
+	var n int
+	if err := ws.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM "big"`).Scan(&n); err != nil || n != 0 {
+		t.Errorf("rows left after abort = %d (%v), want 0", n, err)
+	}
+}
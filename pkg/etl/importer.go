@@ -7,38 +7,56 @@ import (
 	"sync"
 	"time"
 
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
 	"github.com/ruslano69/tdtp-framework/pkg/brokers"
 	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+	"github.com/ruslano69/tdtp-framework/pkg/processors"
 )
 
 // ImporterConfig содержит конфигурацию импортера
 type ImporterConfig struct {
-	Type     string // "RabbitMQ" или "Kafka"
-	RabbitMQ *RabbitMQInputConfig
-	Kafka    *KafkaInputConfig
-	Workers  int // Количество параллельных воркеров для обработки частей
+	Type      string // "RabbitMQ", "Kafka" или "FileQueue"
+	RabbitMQ  *RabbitMQInputConfig
+	Kafka     *KafkaInputConfig
+	FileQueue *FileQueueInputConfig
+	Workers   int // Количество параллельных воркеров для обработки частей
+
+	// Limits — ограничение нагрузки на целевую БД: строк/сек и число
+	// одновременных вызовов handler (см. adapters.Governor). Нулевое
+	// значение — без ограничений.
+	Limits adapters.ImportLimits
 }
 
 // RabbitMQInputConfig конфигурация для чтения из RabbitMQ
 type RabbitMQInputConfig struct {
-	Host     string
-	Port     int
-	User     string
-	Password string
-	Queue    string
+	Host        string
+	Port        int
+	User        string
+	Password    string
+	Queue       string
+	MaxPriority int // > 0 — очередь объявлена как priority queue (x-max-priority), должно совпадать с продюсером
 }
 
 // KafkaInputConfig конфигурация для чтения из Kafka
 type KafkaInputConfig struct {
-	Brokers []string
-	Topic   string
-	GroupID string
+	Brokers     []string
+	Topic       string
+	UrgentTopic string // Читать также topic срочных пакетов (см. KafkaOutputConfig.UrgentTopic)
+	GroupID     string
+}
+
+// FileQueueInputConfig конфигурация для чтения из файловой очереди
+// (каталог на общей папке или съёмном носителе, см. brokers.FileQueue)
+type FileQueueInputConfig struct {
+	Dir   string
+	Queue string // Подкаталог очереди (необязательно)
 }
 
 // ImportResult представляет результат импорта одной части
 type ImportResult struct {
 	PartNumber int
 	TotalParts int // Из Header.TotalParts
+	Priority   int // Из Header.Priority
 	RowsCount  int
 	Error      error
 	Duration   time.Duration
@@ -70,6 +88,7 @@ type ImportStats struct {
 	EndTime         time.Time
 	Duration        time.Duration
 	AvgPartDuration time.Duration
+	UrgentParts     int // Части с Priority >= packet.PriorityUrgent, обработанные вне очереди
 }
 
 // Import выполняет параллельный импорт из брокера
@@ -83,15 +102,21 @@ func (pi *ParallelImporter) Import(
 		StartTime: time.Now(),
 	}
 
+	governor, err := adapters.NewGovernor(pi.config.Limits)
+	if err != nil {
+		return stats, fmt.Errorf("invalid import limits: %w", err)
+	}
+
 	// Создаем broker в зависимости от типа
 	var broker brokers.MessageBroker
-	var err error
 
 	switch pi.config.Type {
 	case "RabbitMQ":
 		broker, err = pi.createRabbitMQBroker()
 	case "Kafka":
 		broker, err = pi.createKafkaBroker()
+	case "FileQueue":
+		broker, err = pi.createFileQueueBroker()
 	default:
 		return nil, fmt.Errorf("unsupported broker type: %s", pi.config.Type)
 	}
@@ -106,8 +131,12 @@ func (pi *ParallelImporter) Import(
 	}
 	defer func() { _ = broker.Close() }()
 
-	// Создаем каналы для координации воркеров
+	// Создаем каналы для координации воркеров.
+	// Срочные пакеты (Header.Priority >= PriorityUrgent) идут в отдельный канал,
+	// который воркеры всегда выбирают первым — срочное обновление справочника
+	// не ждёт, пока разберётся очередь bulk-частей.
 	partsChan := make(chan []byte, pi.config.Workers*2)
+	urgentChan := make(chan []byte, pi.config.Workers*2)
 	resultsChan := make(chan *ImportResult, pi.config.Workers*2)
 	errorsChan := make(chan error, 1)
 
@@ -117,12 +146,13 @@ func (pi *ParallelImporter) Import(
 	// Запускаем воркеры для параллельной обработки
 	for i := 0; i < pi.config.Workers; i++ {
 		wg.Add(1)
-		go pi.worker(ctx, i, partsChan, resultsChan, handler, &wg)
+		go pi.worker(ctx, i, urgentChan, partsChan, resultsChan, handler, governor, &wg)
 	}
 
 	// Горутина для получения сообщений из брокера
 	go func() {
 		defer close(partsChan)
+		defer close(urgentChan)
 
 		for {
 			select {
@@ -143,8 +173,12 @@ func (pi *ParallelImporter) Import(
 				}
 
 				// Отправляем в канал для обработки воркерами
+				target := partsChan
+				if packet.PeekPriority(msg) >= packet.PriorityUrgent {
+					target = urgentChan
+				}
 				select {
-				case partsChan <- msg:
+				case target <- msg:
 				case <-ctx.Done():
 					errorsChan <- ctx.Err()
 					return
@@ -169,6 +203,10 @@ func (pi *ParallelImporter) Import(
 			stats.TotalParts = result.TotalParts
 		}
 
+		if result.Priority >= packet.PriorityUrgent {
+			stats.UrgentParts++
+		}
+
 		if result.Error != nil {
 			stats.Errors = append(stats.Errors, fmt.Errorf("part %d: %w", result.PartNumber, result.Error))
 		}
@@ -197,13 +235,53 @@ func (pi *ParallelImporter) Import(
 	return stats, nil
 }
 
+// nextPart выбирает следующую часть для воркера: срочный канал имеет
+// строгий приоритет над обычным. Возвращает ok=false когда оба канала
+// закрыты и пусты или контекст отменён.
+func nextPart(ctx context.Context, urgentChan, partsChan <-chan []byte) ([]byte, bool) {
+	for urgentChan != nil || partsChan != nil {
+		// Неблокирующая проверка срочного канала перед общим select
+		if urgentChan != nil {
+			select {
+			case msg, ok := <-urgentChan:
+				if !ok {
+					urgentChan = nil
+					continue
+				}
+				return msg, true
+			default:
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, false
+		case msg, ok := <-urgentChan:
+			if !ok {
+				urgentChan = nil
+				continue
+			}
+			return msg, true
+		case msg, ok := <-partsChan:
+			if !ok {
+				partsChan = nil
+				continue
+			}
+			return msg, true
+		}
+	}
+	return nil, false
+}
+
 // worker обрабатывает части из канала параллельно
 func (pi *ParallelImporter) worker(
 	ctx context.Context,
 	workerID int,
+	urgentChan <-chan []byte,
 	partsChan <-chan []byte,
 	resultsChan chan<- *ImportResult,
 	handler func(ctx context.Context, dataPacket *packet.DataPacket) error,
+	governor *adapters.Governor,
 	wg *sync.WaitGroup,
 ) {
 	defer wg.Done()
@@ -211,42 +289,57 @@ func (pi *ParallelImporter) worker(
 	parser := packet.NewParser()
 
 	for {
-		select {
-		case <-ctx.Done():
+		xmlData, ok := nextPart(ctx, urgentChan, partsChan)
+		if !ok {
+			// Каналы закрыты или контекст отменён, завершаем воркер
 			return
+		}
 
-		case xmlData, ok := <-partsChan:
-			if !ok {
-				// Канал закрыт, завершаем воркер
-				return
-			}
-
-			startTime := time.Now()
-
-			// Парсим TDTP пакет
-			dataPacket, err := parser.Parse(bytes.NewReader(xmlData))
-			if err != nil {
-				resultsChan <- &ImportResult{
-					Error:    fmt.Errorf("worker %d: failed to parse packet: %w", workerID, err),
-					Duration: time.Since(startTime),
-				}
-				continue
-			}
-
-			// Обрабатываем пакет через handler
-			err = handler(ctx, dataPacket)
+		startTime := time.Now()
 
+		// Парсим TDTP пакет; сжатые данные распаковываются до Governor,
+		// иначе лимит строк считал бы сжатый пакет одной строкой
+		dataPacket, err := parser.ParseWithDecompression(bytes.NewReader(xmlData), decompressRows)
+		if err != nil {
 			resultsChan <- &ImportResult{
-				PartNumber: dataPacket.Header.PartNumber,
-				TotalParts: dataPacket.Header.TotalParts,
-				RowsCount:  len(dataPacket.Data.Rows),
-				Error:      err,
-				Duration:   time.Since(startTime),
+				Error:    fmt.Errorf("worker %d: failed to parse packet: %w", workerID, err),
+				Duration: time.Since(startTime),
 			}
+			continue
+		}
+
+		// Обрабатываем пакет через handler (с учётом лимитов нагрузки на БД)
+		rows := packetRowCount(dataPacket)
+		err = governor.Do(ctx, rows, func() error {
+			return handler(ctx, dataPacket)
+		})
+
+		resultsChan <- &ImportResult{
+			PartNumber: dataPacket.Header.PartNumber,
+			TotalParts: dataPacket.Header.TotalParts,
+			Priority:   dataPacket.Header.Priority,
+			RowsCount:  rows,
+			Error:      err,
+			Duration:   time.Since(startTime),
 		}
 	}
 }
 
+// decompressRows — распаковка данных пакета для Parser.ParseWithDecompression.
+func decompressRows(_ context.Context, compressed, algo string) ([]string, error) {
+	return processors.DecompressDataForTdtpWithAlgo(compressed, algo)
+}
+
+// packetRowCount — число строк пакета для Governor и статистики.
+// Зашифрованные данные расшифровывает только handler, поэтому для них
+// берётся RecordsInPart из заголовка.
+func packetRowCount(pkt *packet.DataPacket) int {
+	if pkt.Data.Encryption != "" && pkt.Header.RecordsInPart > 0 {
+		return pkt.Header.RecordsInPart
+	}
+	return len(pkt.Data.Rows)
+}
+
 // createRabbitMQBroker создает RabbitMQ брокер для чтения
 func (pi *ParallelImporter) createRabbitMQBroker() (brokers.MessageBroker, error) {
 	if pi.config.RabbitMQ == nil {
@@ -256,13 +349,14 @@ func (pi *ParallelImporter) createRabbitMQBroker() (brokers.MessageBroker, error
 	cfg := pi.config.RabbitMQ
 
 	return brokers.New(brokers.Config{
-		Type:     "rabbitmq",
-		Host:     cfg.Host,
-		Port:     cfg.Port,
-		User:     cfg.User,
-		Password: cfg.Password,
-		Queue:    cfg.Queue,
-		Durable:  true,
+		Type:        "rabbitmq",
+		Host:        cfg.Host,
+		Port:        cfg.Port,
+		User:        cfg.User,
+		Password:    cfg.Password,
+		Queue:       cfg.Queue,
+		Durable:     true,
+		MaxPriority: cfg.MaxPriority,
 	})
 }
 
@@ -275,9 +369,30 @@ func (pi *ParallelImporter) createKafkaBroker() (brokers.MessageBroker, error) {
 	cfg := pi.config.Kafka
 
 	return brokers.New(brokers.Config{
-		Type:    "kafka",
-		Brokers: cfg.Brokers,
-		Topic:   cfg.Topic,
+		Type:          "kafka",
+		Brokers:       cfg.Brokers,
+		Topic:         cfg.Topic,
+		UrgentTopic:   cfg.UrgentTopic,
+		ConsumerGroup: cfg.GroupID,
+	})
+}
+
+// createFileQueueBroker создает файловую очередь для чтения.
+// Сообщения подтверждаются при получении (AutoAck): воркеры обрабатывают
+// части параллельно, и «последнее полученное» сообщение для AckLast не
+// определено — как и для остальных брокеров, ошибки частей попадают в ImportStats.
+func (pi *ParallelImporter) createFileQueueBroker() (brokers.MessageBroker, error) {
+	if pi.config.FileQueue == nil {
+		return nil, fmt.Errorf("filequeue config is not set")
+	}
+
+	cfg := pi.config.FileQueue
+
+	return brokers.New(brokers.Config{
+		Type:    "filequeue",
+		Dir:     cfg.Dir,
+		Queue:   cfg.Queue,
+		AutoAck: true,
 	})
 }
 
@@ -1,9 +1,13 @@
 package etl
 
 import (
+	"context"
+	"sync"
 	"testing"
 
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
 	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+	"github.com/ruslano69/tdtp-framework/pkg/processors"
 )
 
 func TestExtractBatchID(t *testing.T) {
@@ -328,3 +332,76 @@ func TestEdgeCases(t *testing.T) {
 		}
 	})
 }
+
+// TestNextPartPrefersUrgent проверяет, что срочные части выбираются раньше
+// уже ожидающих обычных частей.
+func TestNextPartPrefersUrgent(t *testing.T) {
+	ctx := context.Background()
+	parts := make(chan []byte, 3)
+	urgent := make(chan []byte, 1)
+
+	parts <- []byte("bulk-1")
+	parts <- []byte("bulk-2")
+	urgent <- []byte("urgent")
+	close(urgent)
+	close(parts)
+
+	want := []string{"urgent", "bulk-1", "bulk-2"}
+	for i, w := range want {
+		msg, ok := nextPart(ctx, urgent, parts)
+		if !ok {
+			t.Fatalf("step %d: nextPart returned ok=false, want %q", i, w)
+		}
+		if string(msg) != w {
+			t.Errorf("step %d: got %q, want %q", i, msg, w)
+		}
+	}
+
+	if _, ok := nextPart(ctx, urgent, parts); ok {
+		t.Error("expected ok=false after both channels are drained")
+	}
+}
+
+func TestWorkerCountsDecompressedRows(t *testing.T) {
+	rows := []string{"1|a", "2|b", "3|c", "4|d", "5|e"}
+	compressed, _, err := processors.CompressDataForTdtp(rows, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkt := packet.NewDataPacket(packet.TypeReference, "items")
+	pkt.Schema = packet.Schema{Fields: []packet.Field{{Name: "id", Type: "INTEGER"}, {Name: "name", Type: "TEXT"}}}
+	pkt.Data.Compression = "zstd"
+	pkt.Data.Rows = []packet.Row{{Value: compressed}}
+	xmlData, err := packet.NewGenerator().ToXML(pkt, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parts := make(chan []byte, 1)
+	parts <- xmlData
+	close(parts)
+	results := make(chan *ImportResult, 1)
+
+	var handled int
+	handler := func(_ context.Context, p *packet.DataPacket) error {
+		handled = len(p.Data.Rows)
+		return nil
+	}
+	// Лимит строк/с — Governor должен получить 5 строк, а не 1 сжатую.
+	governor, err := adapters.NewGovernor(adapters.ImportLimits{MaxRowsPerSec: 1000})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	(&ParallelImporter{}).worker(context.Background(), 0, nil, parts, results, handler, governor, &wg)
+
+	res := <-results
+	if res.Error != nil {
+		t.Fatalf("worker: %v", res.Error)
+	}
+	if res.RowsCount != len(rows) || handled != len(rows) {
+		t.Errorf("RowsCount = %d, handler saw %d rows, want %d", res.RowsCount, handled, len(rows))
+	}
+}
@@ -19,6 +19,7 @@ import (
 	tdtpcrypto "github.com/ruslano69/tdtp-framework/pkg/crypto"
 	"github.com/ruslano69/tdtp-framework/pkg/mercury"
 	"github.com/ruslano69/tdtp-framework/pkg/processors"
+	"github.com/ruslano69/tdtp-framework/pkg/progress"
 	"github.com/ruslano69/tdtp-framework/pkg/storage"
 )
 
@@ -76,12 +77,17 @@ func decompressTDTPPacket(pkt *packet.DataPacket) error {
 			return fmt.Errorf("checksum mismatch: %w", err)
 		}
 	}
+	if err := processors.LoadPacketZstdDict(pkt); err != nil {
+		return err
+	}
 	rows, err := processors.DecompressDataForTdtp(compressed)
 	if err != nil {
 		return fmt.Errorf("failed to decompress: %w", err)
 	}
 	pkt.Data.Compression = ""
 	pkt.Data.Checksum = ""
+	pkt.Data.ZstdDictID = ""
+	pkt.Data.ZstdDict = ""
 	pkt.Data.Rows = make([]packet.Row, len(rows))
 	for i, r := range rows {
 		pkt.Data.Rows[i] = packet.Row{Value: r}
@@ -122,6 +128,7 @@ func loadTDTPFile(source SourceConfig) (*packet.DataPacket, error) {
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse TDTP file '%s': %w", fp, err)
 		}
+		compressed := pkt.Data.Compression != "" // несжатые строки сверил с Header.Checksum ParseFile
 		if err := decompressTDTPPacket(pkt); err != nil {
 			return nil, fmt.Errorf("file '%s': %w", fp, err)
 		}
@@ -130,12 +137,18 @@ func loadTDTPFile(source SourceConfig) (*packet.DataPacket, error) {
 				return nil, fmt.Errorf("file '%s': compact expansion failed: %w", fp, err)
 			}
 		}
+		if compressed {
+			if err := packet.VerifyRowChecksum(pkt); err != nil {
+				return nil, fmt.Errorf("file '%s': %w", fp, err)
+			}
+		}
 		if merged == nil {
 			merged = pkt
 		} else {
 			// Склеиваем строки последующих частей в первый пакет.
 			merged.Data.Rows = append(merged.Data.Rows, pkt.Data.Rows...)
 			merged.Header.RecordsInPart += pkt.Header.RecordsInPart
+			merged.Header.Checksum = nil // сумма первой части к склейке не относится
 		}
 	}
 
@@ -288,6 +301,7 @@ func loadTDTPFromS3(ctx context.Context, source SourceConfig) (*packet.DataPacke
 		if parseErr != nil {
 			return nil, fmt.Errorf("tdtp-s3: parse s3://%s/%s: %w", bucket, k, parseErr)
 		}
+		verified := pkt.Data.Compression == "" && !pkt.Data.Compact // ParseBytes сверил Header.Checksum
 		if err := decompressTDTPPacket(pkt); err != nil {
 			return nil, fmt.Errorf("tdtp-s3: decompress s3://%s/%s: %w", bucket, k, err)
 		}
@@ -296,12 +310,18 @@ func loadTDTPFromS3(ctx context.Context, source SourceConfig) (*packet.DataPacke
 				return nil, fmt.Errorf("tdtp-s3: compact expand s3://%s/%s: %w", bucket, k, err)
 			}
 		}
+		if !verified {
+			if err := packet.VerifyRowChecksum(pkt); err != nil {
+				return nil, fmt.Errorf("tdtp-s3: s3://%s/%s: %w", bucket, k, err)
+			}
+		}
 
 		if merged == nil {
 			merged = pkt
 		} else {
 			merged.Data.Rows = append(merged.Data.Rows, pkt.Data.Rows...)
 			merged.Header.RecordsInPart += pkt.Header.RecordsInPart
+			merged.Header.Checksum = nil // сумма первой части к склейке не относится
 		}
 	}
 
@@ -328,13 +348,27 @@ type SourceData struct {
 	TableName  string
 	Packet     *packet.DataPacket
 	Error      error
+	Duration   time.Duration // время загрузки источника
+
+	// Timezone — отчёт о переводе naive datetime в UTC (source.timezone),
+	// nil — часовой пояс не задан
+	Timezone *adapters.TimezoneReport
+}
+
+// AdapterPool выдаёт готовые адаптеры БД-источников вместо открытия нового
+// соединения на каждую загрузку (тёплый пул tdtpserve). release возвращает
+// адаптер в пул; ненулевая ошибка загрузки означает, что соединение могло
+// сломаться, и пул его закрывает.
+type AdapterPool interface {
+	Acquire(ctx context.Context, source SourceConfig) (adapter adapters.Adapter, release func(err error), err error)
 }
 
 // Loader отвечает за загрузку данных из источников
 type Loader struct {
 	sources       []SourceConfig
 	errorHandling ErrorHandlingConfig
-	fast          bool // performance.fast global override
+	fast          bool        // performance.fast global override
+	pool          AdapterPool // nil — адаптер открывается и закрывается на каждую загрузку
 }
 
 // NewLoader создает новый загрузчик данных
@@ -352,6 +386,12 @@ func (l *Loader) SetFast(fast bool) {
 	l.fast = fast
 }
 
+// SetAdapterPool берёт адаптеры БД-источников из пула: пул сам проверяет
+// соединение перед выдачей, поэтому Ping при загрузке не выполняется.
+func (l *Loader) SetAdapterPool(pool AdapterPool) {
+	l.pool = pool
+}
+
 // LoadAll загружает данные из всех источников параллельно
 func (l *Loader) LoadAll(ctx context.Context) ([]SourceData, error) {
 	if len(l.sources) == 0 {
@@ -376,7 +416,10 @@ func (l *Loader) LoadAll(ctx context.Context) ([]SourceData, error) {
 			}
 
 			// Загружаем данные из источника
-			pkt, err := l.loadFromSource(ctx, src)
+			start := time.Now()
+			pkt, tz, err := l.loadFromSource(ctx, src)
+			result.Duration = time.Since(start)
+			result.Timezone = tz
 			if err != nil {
 				result.Error = err
 			} else {
@@ -399,9 +442,16 @@ func (l *Loader) LoadAll(ctx context.Context) ([]SourceData, error) {
 
 	for result := range results {
 		allResults = append(allResults, result)
+		e := progress.Event{Event: progress.Progress, Step: StepLoad, Item: result.SourceName,
+			Done: len(allResults), Total: len(l.sources), DurationMs: result.Duration.Milliseconds()}
+		if result.Packet != nil {
+			e.Rows = int64(result.Packet.Header.RecordsInPart)
+		}
 		if result.Error != nil {
+			e.Error = result.Error.Error()
 			sourceErrors = append(sourceErrors, fmt.Errorf("source '%s': %w", result.SourceName, result.Error))
 		}
+		progress.Emit(ctx, e)
 	}
 
 	// Обработка ошибок согласно on_source_error стратегии
@@ -441,7 +491,7 @@ func (l *Loader) LoadOne(ctx context.Context, sourceName string) (*SourceData, e
 	}
 
 	// Загружаем данные
-	pkt, err := l.loadFromSource(ctx, *source)
+	pkt, tz, err := l.loadFromSource(ctx, *source)
 	if err != nil {
 		return &SourceData{
 			SourceName: source.Name,
@@ -454,11 +504,13 @@ func (l *Loader) LoadOne(ctx context.Context, sourceName string) (*SourceData, e
 		SourceName: source.Name,
 		TableName:  source.Name,
 		Packet:     pkt,
+		Timezone:   tz,
 	}, nil
 }
 
-// loadFromSource загружает данные из конкретного источника
-func (l *Loader) loadFromSource(ctx context.Context, source SourceConfig) (*packet.DataPacket, error) {
+// loadFromSource загружает данные из конкретного источника. Второе значение —
+// отчёт о переводе datetime в UTC (nil, если source.timezone не задан).
+func (l *Loader) loadFromSource(ctx context.Context, source SourceConfig) (_ *packet.DataPacket, _ *adapters.TimezoneReport, err error) {
 	// Применяем timeout из конфигурации источника
 	var timeoutCtx context.Context
 	var cancel context.CancelFunc
@@ -470,32 +522,50 @@ func (l *Loader) loadFromSource(ctx context.Context, source SourceConfig) (*pack
 		timeoutCtx = ctx
 	}
 
+	// Результат другого пайплайна — читаем его output как tdtp/tdtp-s3.
+	if source.Type == SourceTypePipeline {
+		resolved, err := ResolvePipelineSource(source)
+		if err != nil {
+			return nil, nil, err
+		}
+		source = resolved
+	}
+
 	// TDTP-файл не требует адаптера — данные уже в TDTP-формате, читаем напрямую.
 	if source.Type == "tdtp" {
-		return loadTDTPFile(source)
+		pkt, err := loadTDTPFile(source)
+		return pkt, nil, err
 	}
 
 	// Зашифрованный TDTP-файл — получаем ключ от xZMercury и расшифровываем.
 	if source.Type == "tdtp-enc" {
-		return loadEncryptedTDTPFile(timeoutCtx, source)
+		pkt, err := loadEncryptedTDTPFile(timeoutCtx, source)
+		return pkt, nil, err
 	}
 
 	// TDTP-файл в S3-совместимом хранилище (SeaweedFS, MinIO, AWS S3 и т.п.).
 	if source.Type == "tdtp-s3" {
-		return loadTDTPFromS3(timeoutCtx, source)
+		pkt, err := loadTDTPFromS3(timeoutCtx, source)
+		return pkt, nil, err
 	}
 	_ = timeoutCtx // используется далее
 
-	// Создаем адаптер для источника
-	adapter, err := adapters.New(timeoutCtx, adapters.Config{
-		Type:            source.Type,
-		DSN:             source.DSN,
-		NoDateSentinels: source.NoDateSentinels,
-	})
-	if err != nil {
-		return nil, fmt.Errorf("failed to create adapter: %w", err)
+	// Адаптер из пула (уже проверен) или новый на время загрузки
+	var adapter adapters.Adapter
+	if l.pool != nil {
+		var release func(error)
+		adapter, release, err = l.pool.Acquire(timeoutCtx, source)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to acquire adapter: %w", err)
+		}
+		defer func() { release(err) }()
+	} else {
+		adapter, err = NewSourceAdapter(timeoutCtx, source)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create adapter: %w", err)
+		}
+		defer func() { _ = adapter.Close(timeoutCtx) }()
 	}
-	defer func() { _ = adapter.Close(timeoutCtx) }()
 
 	// --fast: skip SpecialValues detection. Per-source flag takes precedence
 	// over the global performance.fast loader flag.
@@ -507,21 +577,69 @@ func (l *Loader) loadFromSource(ctx context.Context, source SourceConfig) (*pack
 	}
 
 	// Проверяем соединение
-	if err := adapter.Ping(timeoutCtx); err != nil {
-		return nil, fmt.Errorf("failed to ping database: %w", err)
+	if l.pool == nil {
+		if err := adapter.Ping(timeoutCtx); err != nil {
+			return nil, nil, fmt.Errorf("failed to ping database: %w", err)
+		}
 	}
 
 	// Выполняем SQL запрос источника с учетом timeout
 	// Используем ExecuteRawSQL для выполнения произвольного SELECT
 	pkt, err := l.executeSourceQuery(timeoutCtx, adapter, source)
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute query: %w", err)
+		return nil, nil, fmt.Errorf("failed to execute query: %w", err)
 	}
 
 	// Обновляем имя таблицы в пакете на alias
 	pkt.Header.TableName = source.Name
 
-	return pkt, nil
+	// Результат SQL источника не проходит экспорт адаптера (SealPackets):
+	// колонки at rest расшифровываются здесь
+	cipher, err := sourceCipher(source)
+	if err != nil {
+		return nil, nil, err
+	}
+	if cipher != nil {
+		if _, _, err := cipher.DecryptPacket(pkt); err != nil {
+			return nil, nil, fmt.Errorf("column decryption failed: %w", err)
+		}
+	}
+
+	var tz *adapters.TimezoneReport
+	if r, ok := adapter.(adapters.TimezoneReporter); ok {
+		tz = r.TimezoneReport()
+	}
+
+	return pkt, tz, nil
+}
+
+// NewSourceAdapter открывает адаптер БД-источника по его конфигурации.
+func NewSourceAdapter(ctx context.Context, source SourceConfig) (adapters.Adapter, error) {
+	cipher, err := sourceCipher(source)
+	if err != nil {
+		return nil, err
+	}
+	return adapters.New(ctx, adapters.Config{
+		Type:            source.Type,
+		DSN:             source.DSN,
+		NoDateSentinels: source.NoDateSentinels,
+		SourceTimezone:  source.Timezone,
+		Booleans:        source.Booleans,
+		ColumnBooleans:  source.ColumnBooleans,
+		ColumnCipher:    cipher,
+	})
+}
+
+// sourceCipher собирает шифратор колонок источника (nil — не настроен).
+func sourceCipher(source SourceConfig) (adapters.ColumnCipher, error) {
+	if source.ColumnEncryption == nil {
+		return nil, nil
+	}
+	cipher, err := source.ColumnEncryption.Cipher()
+	if err != nil {
+		return nil, fmt.Errorf("source %s: column_encryption: %w", source.Name, err)
+	}
+	return cipher, nil
 }
 
 // executeSourceQuery выполняет SQL запрос источника и возвращает DataPacket
@@ -3,12 +3,18 @@ package etl
 import (
 	"context"
 	"fmt"
+	"os"
 	"time"
 
 	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
 	"github.com/ruslano69/tdtp-framework/pkg/core/schema"
+	"github.com/ruslano69/tdtp-framework/pkg/mercury"
 	"github.com/ruslano69/tdtp-framework/pkg/processors"
+	"github.com/ruslano69/tdtp-framework/pkg/progress"
 	"github.com/ruslano69/tdtp-framework/pkg/sanitize"
+	"github.com/ruslano69/tdtp-framework/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // ProcessorStats представляет статистику выполнения ETL
@@ -20,6 +26,17 @@ type ProcessorStats struct {
 	TotalRowsLoaded   int
 	TotalRowsExported int
 	Errors            []error
+
+	Steps   []StageStats // шаги по порядку выполнения (StepLoad, StepTransform, ...)
+	Sources []StageStats // по источникам, в порядке config.Sources
+	Parts   []StageStats // по частям вывода
+
+	// Throttle — backpressure потокового экспорта в брокер (output.backpressure)
+	Throttle ThrottleStats
+
+	// Unmapped — незамапленные значения pre-export процессоров value_mapper:
+	// поле → значение → количество (nil — маппинга нет)
+	Unmapped map[string]map[string]int64
 }
 
 // Processor представляет главный ETL процессор
@@ -34,6 +51,8 @@ type Processor struct {
 	mercuryBinder  processors.MercuryBinder // опциональная замена mercury.Client (dev-режим, тесты)
 	preExportChain *processors.Chain        // цепочка pre-export процессоров из config.Processors.PreExport
 	pipelineCtx    *packet.PipelineContext  // метаданные pipeline (v1.4), встраиваются в пакеты при экспорте
+	guard          *ResourceGuard           // performance.max_rows / max_memory_mb (nil — без лимитов)
+	stepSpan       trace.Span               // спан текущего шага (beginStep → addStep)
 }
 
 // NewProcessor создает новый ETL процессор
@@ -70,53 +89,94 @@ func (p *Processor) SetPipelineContext(ctx *packet.PipelineContext) *Processor {
 }
 
 // Execute выполняет весь ETL процесс
-func (p *Processor) Execute(ctx context.Context) error {
+func (p *Processor) Execute(ctx context.Context) (err error) {
 	// Генерируем UUID пакета в самом начале — он станет публичным идентификатором
 	// результата и binding-якорем для ключа шифрования xZMercury (UUID-binding флоу).
 	p.packageUUID = packet.GenerateUUID()
 
+	// Спан запуска; шаги — дочерние спаны etl.<шаг> (beginStep)
+	ctx, span := tracing.Start(ctx, "etl.pipeline",
+		attribute.String("etl.pipeline", p.config.Name), attribute.String("etl.package_uuid", p.packageUUID))
+	defer func() {
+		span.SetAttributes(attribute.Int("etl.rows_loaded", p.stats.TotalRowsLoaded), attribute.Int("etl.rows_exported", p.stats.TotalRowsExported))
+		tracing.End(span, err)
+	}()
+
 	p.stats.StartTime = time.Now()
 	defer func() {
 		p.stats.EndTime = time.Now()
 		p.stats.Duration = p.stats.EndTime.Sub(p.stats.StartTime)
 	}()
 
+	// 0. encryption: required — ключи проверяются до загрузки источников,
+	// а не после выгрузки всех данных
+	if p.config.Encryption == EncryptionRequired {
+		if err := p.checkEncryptionReady(ctx); err != nil {
+			return fmt.Errorf("encryption required: %w", err)
+		}
+	}
+
 	// 1. Создаем workspace
-	if err := p.initWorkspace(ctx); err != nil {
+	stepCtx, stepStart := p.beginStep(ctx, StepWorkspace)
+	err = p.initWorkspace(stepCtx)
+	p.addStep(ctx, StepWorkspace, stepStart, 0, 0, err)
+	if err != nil {
 		return fmt.Errorf("failed to initialize workspace: %w", err)
 	}
 	defer p.closeWorkspace(ctx)
 
 	// 2. Загружаем данные из всех источников
-	sourcesData, err := p.loadSources(ctx)
+	stepCtx, stepStart = p.beginStep(ctx, StepLoad)
+	sourcesData, err := p.loadSources(stepCtx)
+	if err == nil {
+		err = p.checkLoadBudget(stepCtx)
+	}
+	var loadedBytes int64
+	for _, src := range p.stats.Sources {
+		loadedBytes += src.Bytes
+	}
+	p.addStep(ctx, StepLoad, stepStart, p.stats.TotalRowsLoaded, loadedBytes, err)
 	if err != nil {
 		return fmt.Errorf("failed to load sources: %w", err)
 	}
 
 	// 3. Создаем таблицы в workspace и загружаем данные
-	if err := p.populateWorkspace(ctx, sourcesData); err != nil {
+	stepCtx, stepStart = p.beginStep(ctx, StepPopulate)
+	err = p.populateWorkspace(stepCtx, sourcesData)
+	p.addStep(ctx, StepPopulate, stepStart, p.stats.TotalRowsLoaded, 0, err)
+	if err != nil {
 		return fmt.Errorf("failed to populate workspace: %w", err)
 	}
 
 	// 4. Выполняем трансформацию и экспорт
 	// Стратегия зависит от типа output:
-	// - Streaming (RabbitMQ/Kafka): SQL выполняется потоком через ExecuteSQLStream (не загружает в память)
+	// - Streaming (RabbitMQ/Kafka/filequeue): SQL выполняется потоком через ExecuteSQLStream (не загружает в память)
 	// - Batch (TDTP): SQL выполняется полностью через ExecuteSQL (нужно знать TotalParts для XML)
 	//
 	// Исключение: если задан fallback-канал, всегда используем batch-режим.
 	// Streaming-канал (RowsChan) можно прочитать только один раз — при ошибке primary
 	// данные уже потеряны и re-execute невозможен. Batch загружает данные в память,
 	// что даёт возможность повторно отправить их через fallback.
-	isBrokerStreaming := (p.config.Output.Type == "rabbitmq" || p.config.Output.Type == "kafka") &&
+	isBrokerStreaming := (p.config.Output.Type == "rabbitmq" || p.config.Output.Type == "kafka" ||
+		p.config.Output.Type == "filequeue") &&
 		p.config.Output.Fallback == nil
 	if isBrokerStreaming {
 		// Streaming: SQL выполняется один раз внутри exportResultsStreaming
-		if err := p.exportResultsStreaming(ctx); err != nil {
+		stepCtx, stepStart = p.beginStep(ctx, StepTransformExport)
+		err := p.exportResultsStreaming(stepCtx)
+		p.addExportSteps(ctx, StepTransformExport, stepStart, err)
+		if err != nil {
 			return fmt.Errorf("failed to export results (streaming): %w", err)
 		}
 	} else {
 		// Batch: выполняем SQL, загружаем все данные в память, экспортируем
-		result, err := p.executeTransformation(ctx)
+		stepCtx, stepStart = p.beginStep(ctx, StepTransform)
+		result, err := p.executeTransformation(stepCtx)
+		resultRows := 0
+		if result != nil && result.Packet != nil {
+			resultRows = len(result.Packet.Data.Rows)
+		}
+		p.addStep(ctx, StepTransform, stepStart, resultRows, 0, err)
 		if err != nil {
 			return fmt.Errorf("failed to execute transformation: %w", err)
 		}
@@ -126,7 +186,10 @@ func (p *Processor) Execute(ctx context.Context) error {
 		// not renamed or computed by transform.sql.
 		p.applySchemaPassthrough(result, sourcesData)
 
-		if err := p.exportResults(ctx, result); err != nil {
+		stepCtx, stepStart = p.beginStep(ctx, StepExport)
+		err = p.exportResults(stepCtx, result)
+		p.addExportSteps(ctx, StepExport, stepStart, err)
+		if err != nil {
 			return fmt.Errorf("failed to export results: %w", err)
 		}
 	}
@@ -134,6 +197,98 @@ func (p *Processor) Execute(ctx context.Context) error {
 	return nil
 }
 
+// beginStep сообщает о начале шага (--progress-format json), начинает его
+// спан etl.<name> и возвращает контекст шага и время его начала для addStep.
+func (p *Processor) beginStep(ctx context.Context, name string) (context.Context, time.Time) {
+	progress.Emit(ctx, progress.Event{Event: progress.StepStarted, Step: name})
+	ctx, p.stepSpan = tracing.Start(ctx, "etl."+name)
+	return ctx, time.Now()
+}
+
+// addStep записывает статистику шага, начатого в start, и сообщает о его
+// завершении.
+func (p *Processor) addStep(ctx context.Context, name string, start time.Time, rows int, bytes int64, err error) {
+	st := StageStats{
+		Name:     name,
+		Rows:     rows,
+		Bytes:    bytes,
+		Duration: time.Since(start),
+		Error:    err,
+	}
+	p.stats.Steps = append(p.stats.Steps, st)
+
+	if p.stepSpan != nil {
+		p.stepSpan.SetAttributes(attribute.Int("etl.rows", rows), attribute.Int64("etl.bytes", bytes))
+		tracing.End(p.stepSpan, err)
+		p.stepSpan = nil
+	}
+
+	e := progress.Event{Event: progress.StepCompleted, Step: name, Rows: int64(rows), DurationMs: st.Duration.Milliseconds()}
+	if err != nil {
+		e.Event, e.Error = progress.StepFailed, err.Error()
+	}
+	progress.Emit(ctx, e)
+}
+
+// addExportSteps записывает шаг экспорта, его части и время pre-export
+// процессоров, собранные экспортером.
+func (p *Processor) addExportSteps(ctx context.Context, name string, start time.Time, err error) {
+	parts, preExport := p.exporter.Stats()
+	p.stats.Parts = parts
+	p.stats.Throttle = p.exporter.Throttle()
+	var written int64
+	var wait time.Duration
+	for _, part := range parts {
+		written += part.Bytes
+		wait += part.Wait
+	}
+	p.addStep(ctx, name, start, p.stats.TotalRowsExported, written, err)
+	p.stats.Steps[len(p.stats.Steps)-1].Wait = wait
+	if p.preExportChain != nil {
+		p.stats.Unmapped = p.preExportChain.UnmappedValues()
+	}
+	if preExport > 0 {
+		p.stats.Steps = append(p.stats.Steps, StageStats{
+			Name:     StepPreExport,
+			Rows:     p.stats.TotalRowsExported,
+			Duration: preExport,
+		})
+	}
+}
+
+// mercuryHealthChecker — binder, умеющий проверить доступность сервиса
+// ключей (mercury.Client). DevClient и тестовые binder'ы его не реализуют
+// и считаются доступными.
+type mercuryHealthChecker interface {
+	Health(ctx context.Context) error
+}
+
+// checkEncryptionReady проверяет, что результат удастся зашифровать:
+// каналы вывода (validateEncryption — конфигурацию могли собрать и без
+// LoadConfig), секрет для проверки HMAC ключей и доступность xZMercury.
+func (p *Processor) checkEncryptionReady(ctx context.Context) error {
+	if err := p.config.validateEncryption(); err != nil {
+		return err
+	}
+	if p.config.Security.ServerSecret == "" && os.Getenv("MERCURY_SERVER_SECRET") == "" {
+		return fmt.Errorf("security.server_secret or $MERCURY_SERVER_SECRET is required to verify xZMercury keys")
+	}
+
+	binder := p.mercuryBinder
+	if binder == nil {
+		if p.config.Security.MercuryURL == "" {
+			return fmt.Errorf("security.mercury_url is required")
+		}
+		binder = mercury.NewClient(p.config.Security.MercuryURL, p.config.Security.MercuryTimeoutMs)
+	}
+	if hc, ok := binder.(mercuryHealthChecker); ok {
+		if err := hc.Health(ctx); err != nil {
+			return fmt.Errorf("xZMercury is not available: %w", err)
+		}
+	}
+	return nil
+}
+
 // initWorkspace инициализирует workspace
 func (p *Processor) initWorkspace(ctx context.Context) error {
 	workspace, err := NewWorkspace(ctx)
@@ -142,6 +297,8 @@ func (p *Processor) initWorkspace(ctx context.Context) error {
 	}
 
 	p.workspace = workspace
+	p.guard = NewResourceGuard(p.config.Performance)
+	workspace.SetGuard(p.guard)
 	p.executor = NewExecutor(workspace)
 	p.exporter = NewExporter(p.config.Output)
 
@@ -193,10 +350,25 @@ func (p *Processor) loadSources(ctx context.Context) ([]SourceData, error) {
 
 	// Подсчитываем статистику только для успешно загруженных источников
 	successCount := 0
+	var slowest time.Duration
 	for _, data := range sourcesData {
-		if data.Error == nil && data.Packet != nil {
-			successCount++
-			p.stats.TotalRowsLoaded += data.Packet.Header.RecordsInPart
+		slowest = max(slowest, data.Duration)
+	}
+	p.stats.Sources = make([]StageStats, 0, len(sourcesData))
+	for _, src := range p.config.Sources {
+		for _, data := range sourcesData {
+			if data.SourceName != src.Name {
+				continue
+			}
+			st := StageStats{Name: data.SourceName, Duration: data.Duration, Wait: slowest - data.Duration, Error: data.Error,
+				Timezone: data.Timezone}
+			if data.Error == nil && data.Packet != nil {
+				successCount++
+				p.stats.TotalRowsLoaded += data.Packet.Header.RecordsInPart
+				st.Rows = data.Packet.Header.RecordsInPart
+				st.Bytes = packetBytes(data.Packet)
+			}
+			p.stats.Sources = append(p.stats.Sources, st)
 		}
 	}
 	p.stats.SourcesLoaded = successCount
@@ -205,9 +377,28 @@ func (p *Processor) loadSources(ctx context.Context) ([]SourceData, error) {
 	return sourcesData, err
 }
 
+// checkLoadBudget — ранний abort после загрузки источников, до workspace:
+// max_rows — по накопленной сумме в порядке config.Sources (виноват тот
+// источник, на котором сумма перевалила лимит), память — с указанием самого
+// крупного источника.
+func (p *Processor) checkLoadBudget(ctx context.Context) error {
+	total := 0
+	var largest StageStats
+	for _, src := range p.stats.Sources {
+		total += src.Rows
+		if err := p.guard.CheckRows(StepLoad, src.Name, total); err != nil {
+			return err
+		}
+		if src.Bytes > largest.Bytes {
+			largest = src
+		}
+	}
+	return p.guard.CheckMemory(ctx, nil, StepLoad, largest.Name)
+}
+
 // populateWorkspace создает таблицы и загружает данные в workspace
 func (p *Processor) populateWorkspace(ctx context.Context, sourcesData []SourceData) error {
-	for _, source := range sourcesData {
+	for i, source := range sourcesData {
 		// Обработка ошибок источника согласно on_source_error стратегии
 		if source.Error != nil {
 			switch p.config.ErrorHandling.OnSourceError {
@@ -256,6 +447,8 @@ func (p *Processor) populateWorkspace(ctx context.Context, sourcesData []SourceD
 		if err := p.workspace.LoadData(ctx, source.TableName, source.Packet); err != nil {
 			return fmt.Errorf("failed to load data into '%s': %w", source.TableName, err)
 		}
+		progress.Emit(ctx, progress.Event{Event: progress.Progress, Step: StepPopulate, Item: source.SourceName,
+			Rows: int64(source.Packet.Header.RecordsInPart), Done: i + 1, Total: len(sourcesData)})
 	}
 
 	return nil
@@ -345,6 +538,15 @@ func (p *Processor) GetStats() ProcessorStats {
 	return p.stats
 }
 
+// StatsReport возвращает статистику запуска по шагам, источникам и частям
+// вывода (--stats-json).
+func (p *Processor) StatsReport() StatsReport {
+	r := p.stats.Report()
+	r.Pipeline = p.config.Name
+	r.PackageUUID = p.packageUUID
+	return r
+}
+
 // Validate проверяет конфигурацию процессора перед выполнением
 func (p *Processor) Validate() error {
 	if p.config == nil {
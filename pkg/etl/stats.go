@@ -0,0 +1,195 @@
+package etl
+
+import (
+	"time"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+// Шаги ETL в ProcessorStats.Steps.
+const (
+	StepWorkspace       = "workspace"        // создание SQLite workspace и экспортера
+	StepLoad            = "load"             // параллельная загрузка источников
+	StepPopulate        = "populate"         // CREATE TABLE + INSERT источников в workspace
+	StepTransform       = "transform"        // transform.sql (batch)
+	StepExport          = "export"           // pre-export процессоры, генерация и запись частей
+	StepTransformExport = "transform_export" // streaming: SQL и отправка частей идут одним потоком
+	StepPreExport       = "pre_export"       // процессоры маскирования/нормализации (внутри export)
+)
+
+// StageStats — статистика одного источника, шага или части вывода.
+//
+// Wait — время, не зависящее от самого этапа: для источника — простой в
+// ожидании самого медленного параллельного источника (у источника на
+// критическом пути Wait == 0); для части и шага экспорта — ввод-вывод
+// (запись файла, upload, xZMercury, отправка в брокер). В streaming-режиме
+// разница между шагом и суммой частей — ожидание строк из SQL.
+type StageStats struct {
+	Name     string
+	Rows     int
+	Bytes    int64
+	Duration time.Duration
+	Wait     time.Duration
+	Error    error
+
+	Timezone *adapters.TimezoneReport // источники с timezone: перевод datetime в UTC
+}
+
+// Bottleneck — самый долгий шаг и самый долгий источник/часть внутри него.
+type Bottleneck struct {
+	Step     string
+	Item     string // имя источника (load) или части (export); "" — шаг неделим
+	Duration time.Duration
+	Share    float64 // доля шага от общего времени, 0..1
+}
+
+// Bottleneck возвращает самое узкое место запуска (nil — шагов нет).
+func (s ProcessorStats) Bottleneck() *Bottleneck {
+	var slowest *StageStats
+	for i := range s.Steps {
+		if s.Steps[i].Name == StepPreExport {
+			continue // вложен в export — не конкурирует с ним
+		}
+		if slowest == nil || s.Steps[i].Duration > slowest.Duration {
+			slowest = &s.Steps[i]
+		}
+	}
+	if slowest == nil {
+		return nil
+	}
+
+	b := &Bottleneck{Step: slowest.Name, Duration: slowest.Duration}
+	if s.Duration > 0 {
+		b.Share = float64(slowest.Duration) / float64(s.Duration)
+	}
+	var items []StageStats
+	switch slowest.Name {
+	case StepLoad:
+		items = s.Sources
+	case StepExport, StepTransformExport:
+		items = s.Parts
+	}
+	var longest time.Duration
+	for _, it := range items {
+		if it.Duration > longest {
+			longest, b.Item = it.Duration, it.Name
+		}
+	}
+	return b
+}
+
+// StatsReport — статистика запуска для --stats-json: длительности в
+// миллисекундах, ошибки строками. Формат стабилен — его читают дашборды.
+type StatsReport struct {
+	Pipeline      string            `json:"pipeline,omitempty"`
+	PackageUUID   string            `json:"package_uuid,omitempty"`
+	StartTime     time.Time         `json:"start_time"`
+	EndTime       time.Time         `json:"end_time"`
+	DurationMs    int64             `json:"duration_ms"`
+	SourcesLoaded int               `json:"sources_loaded"`
+	RowsLoaded    int               `json:"rows_loaded"`
+	RowsExported  int               `json:"rows_exported"`
+	Steps         []StageReport     `json:"steps"`
+	Sources       []StageReport     `json:"sources,omitempty"`
+	Parts         []StageReport     `json:"parts,omitempty"`
+	Bottleneck    *BottleneckReport `json:"bottleneck,omitempty"`
+	Backpressure  *ThrottleReport   `json:"backpressure,omitempty"`
+	Errors        []string          `json:"errors,omitempty"`
+}
+
+// ThrottleReport — ThrottleStats в StatsReport.
+type ThrottleReport struct {
+	ThrottledMs int64 `json:"throttled_ms"`
+	PausedMs    int64 `json:"paused_ms"`
+	Pauses      int   `json:"pauses"`
+	MaxDepth    int64 `json:"max_depth"`
+	PollErrors  int   `json:"poll_errors,omitempty"`
+}
+
+// StageReport — StageStats в StatsReport.
+type StageReport struct {
+	Name       string  `json:"name"`
+	Rows       int     `json:"rows"`
+	Bytes      int64   `json:"bytes,omitempty"`
+	DurationMs int64   `json:"duration_ms"`
+	WaitMs     int64   `json:"wait_ms,omitempty"`
+	RowsPerSec float64 `json:"rows_per_sec,omitempty"`
+	Error      string  `json:"error,omitempty"`
+}
+
+// BottleneckReport — Bottleneck в StatsReport.
+type BottleneckReport struct {
+	Step       string  `json:"step"`
+	Item       string  `json:"item,omitempty"`
+	DurationMs int64   `json:"duration_ms"`
+	Share      float64 `json:"share"`
+}
+
+// Report переводит статистику в StatsReport.
+func (s ProcessorStats) Report() StatsReport {
+	r := StatsReport{
+		StartTime:     s.StartTime,
+		EndTime:       s.EndTime,
+		DurationMs:    s.Duration.Milliseconds(),
+		SourcesLoaded: s.SourcesLoaded,
+		RowsLoaded:    s.TotalRowsLoaded,
+		RowsExported:  s.TotalRowsExported,
+		Steps:         stageReports(s.Steps),
+		Sources:       stageReports(s.Sources),
+		Parts:         stageReports(s.Parts),
+	}
+	if b := s.Bottleneck(); b != nil {
+		r.Bottleneck = &BottleneckReport{
+			Step:       b.Step,
+			Item:       b.Item,
+			DurationMs: b.Duration.Milliseconds(),
+			Share:      b.Share,
+		}
+	}
+	if t := s.Throttle; t != (ThrottleStats{}) {
+		r.Backpressure = &ThrottleReport{
+			ThrottledMs: t.Throttled.Milliseconds(),
+			PausedMs:    t.Paused.Milliseconds(),
+			Pauses:      t.Pauses,
+			MaxDepth:    t.MaxDepth,
+			PollErrors:  t.PollErrors,
+		}
+	}
+	for _, err := range s.Errors {
+		r.Errors = append(r.Errors, err.Error())
+	}
+	return r
+}
+
+func stageReports(stages []StageStats) []StageReport {
+	if len(stages) == 0 {
+		return nil
+	}
+	out := make([]StageReport, len(stages))
+	for i, st := range stages {
+		out[i] = StageReport{
+			Name:       st.Name,
+			Rows:       st.Rows,
+			Bytes:      st.Bytes,
+			DurationMs: st.Duration.Milliseconds(),
+			WaitMs:     st.Wait.Milliseconds(),
+		}
+		if st.Duration > 0 && st.Rows > 0 {
+			out[i].RowsPerSec = float64(st.Rows) / st.Duration.Seconds()
+		}
+		if st.Error != nil {
+			out[i].Error = st.Error.Error()
+		}
+	}
+	return out
+}
+
+// packetBytes — объём строк пакета (сырые значения, без XML-обвязки).
+func packetBytes(pkt *packet.DataPacket) int64 {
+	var n int64
+	for _, row := range pkt.Data.Rows {
+		n += int64(len(row.Value))
+	}
+	return n
+}
@@ -0,0 +1,94 @@
+package etl
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestProcessorStats_Bottleneck(t *testing.T) {
+	stats := ProcessorStats{
+		Duration: 10 * time.Second,
+		Steps: []StageStats{
+			{Name: StepLoad, Duration: 6 * time.Second},
+			{Name: StepTransform, Duration: time.Second},
+			{Name: StepExport, Duration: 2 * time.Second},
+			{Name: StepPreExport, Duration: 9 * time.Second}, // вложен в export — не считается
+		},
+		Sources: []StageStats{
+			{Name: "orders", Duration: 6 * time.Second},
+			{Name: "customers", Duration: 2 * time.Second, Wait: 4 * time.Second},
+		},
+	}
+
+	b := stats.Bottleneck()
+	if b == nil || b.Step != StepLoad || b.Item != "orders" {
+		t.Fatalf("Bottleneck = %+v, want load/orders", b)
+	}
+	if b.Share != 0.6 {
+		t.Errorf("Share = %v, want 0.6", b.Share)
+	}
+
+	if (ProcessorStats{}).Bottleneck() != nil {
+		t.Error("Bottleneck of empty stats must be nil")
+	}
+}
+
+func TestProcessorStats_Report(t *testing.T) {
+	stats := ProcessorStats{
+		Duration:          3 * time.Second,
+		TotalRowsExported: 500,
+		Steps: []StageStats{
+			{Name: StepExport, Rows: 500, Bytes: 4096, Duration: 2 * time.Second, Wait: 1500 * time.Millisecond},
+		},
+		Parts: []StageStats{
+			{Name: "part 1/1", Rows: 500, Duration: 2 * time.Second, Error: errors.New("disk full")},
+		},
+		Errors: []error{errors.New("source 'x' skipped")},
+	}
+
+	r := stats.Report()
+	if r.DurationMs != 3000 || r.RowsExported != 500 {
+		t.Errorf("report totals = %+v", r)
+	}
+	if len(r.Steps) != 1 || r.Steps[0].WaitMs != 1500 || r.Steps[0].RowsPerSec != 250 {
+		t.Errorf("steps = %+v", r.Steps)
+	}
+	if len(r.Parts) != 1 || r.Parts[0].Error != "disk full" {
+		t.Errorf("parts = %+v", r.Parts)
+	}
+	if r.Sources != nil {
+		t.Errorf("sources = %+v, want omitted", r.Sources)
+	}
+	if r.Bottleneck == nil || r.Bottleneck.Step != StepExport || r.Bottleneck.Item != "part 1/1" {
+		t.Errorf("bottleneck = %+v", r.Bottleneck)
+	}
+	if len(r.Errors) != 1 {
+		t.Errorf("errors = %v", r.Errors)
+	}
+}
+
+func TestExporter_RecordsPartStats(t *testing.T) {
+	dest := filepath.Join(t.TempDir(), "out.tdtp.xml")
+	exp := NewExporter(OutputConfig{Type: "tdtp", TDTP: &TDTPOutputConfig{Destination: dest}})
+
+	if _, err := exp.Export(context.Background(), makeExporterTestPacket(t)); err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+
+	parts, preExport := exp.Stats()
+	if len(parts) != 1 {
+		t.Fatalf("parts = %+v, want 1", parts)
+	}
+	if parts[0].Name != "part 1/1" || parts[0].Rows != 2 || parts[0].Bytes == 0 || parts[0].Error != nil {
+		t.Errorf("part = %+v", parts[0])
+	}
+	if parts[0].Wait > parts[0].Duration {
+		t.Errorf("wait %v exceeds part duration %v", parts[0].Wait, parts[0].Duration)
+	}
+	if preExport != 0 {
+		t.Errorf("preExport = %v without a pre-export chain", preExport)
+	}
+}
@@ -108,6 +108,16 @@ func applyOutputVars(out *OutputConfig, vars map[string]string) {
 	if out.XLSX != nil {
 		out.XLSX.Destination = substituteYAML(out.XLSX.Destination, vars)
 	}
+	if out.Report != nil {
+		out.Report.Destination = substituteYAML(out.Report.Destination, vars)
+		for k, v := range out.Report.Params {
+			out.Report.Params[k] = substituteYAML(v, vars)
+		}
+	}
+	if out.Email != nil {
+		out.Email.Subject = substituteYAML(out.Email.Subject, vars)
+		out.Email.Body = substituteYAML(out.Email.Body, vars)
+	}
 	applyOutputVars(out.Fallback, vars)
 }
 
@@ -171,6 +181,16 @@ func collectOutputDeclared(out *OutputConfig, scanYAML func(string)) {
 	if out.XLSX != nil {
 		scanYAML(out.XLSX.Destination)
 	}
+	if out.Report != nil {
+		scanYAML(out.Report.Destination)
+		for _, v := range out.Report.Params {
+			scanYAML(v)
+		}
+	}
+	if out.Email != nil {
+		scanYAML(out.Email.Subject)
+		scanYAML(out.Email.Body)
+	}
 	collectOutputDeclared(out.Fallback, scanYAML)
 }
 
@@ -18,6 +18,12 @@ type Workspace struct {
 	adapter adapters.Adapter
 	db      *sql.DB
 	tables  map[string]bool // Список созданных таблиц
+	guard   *ResourceGuard  // performance.max_rows / max_memory_mb (nil — без лимитов)
+}
+
+// SetGuard включает проверку лимитов при загрузке данных и выполнении SQL.
+func (w *Workspace) SetGuard(guard *ResourceGuard) {
+	w.guard = guard
 }
 
 // NewWorkspace создает новый :memory: workspace
@@ -115,8 +121,18 @@ func (w *Workspace) LoadData(ctx context.Context, tableName string, dataPacket *
 
 	txStmt := tx.StmtContext(ctx, stmt)
 
-	// Вставляем каждую строку
+	// Вставляем каждую строку. Лимиты проверяются каждые guardCheckEvery
+	// строк — abort до того, как источник целиком раздует workspace.
+	pending := 0
 	for i, values := range rows {
+		if pending == guardCheckEvery {
+			if err := w.checkPopulate(ctx, tx, tableName, pending); err != nil {
+				return err
+			}
+			pending = 0
+		}
+		pending++
+
 		if len(values) != numFields {
 			return fmt.Errorf("row %d has %d values, expected %d", i, len(values), numFields)
 		}
@@ -132,6 +148,10 @@ func (w *Workspace) LoadData(ctx context.Context, tableName string, dataPacket *
 		}
 	}
 
+	if err := w.checkPopulate(ctx, tx, tableName, pending); err != nil {
+		return err
+	}
+
 	// Коммитим транзакцию
 	if err := tx.Commit(); err != nil {
 		return fmt.Errorf("failed to commit transaction: %w", err)
@@ -140,6 +160,15 @@ func (w *Workspace) LoadData(ctx context.Context, tableName string, dataPacket *
 	return nil
 }
 
+// checkPopulate учитывает rows загруженных строк источника и проверяет
+// лимиты. Размер workspace меряется через tx — то же соединение.
+func (w *Workspace) checkPopulate(ctx context.Context, tx *sql.Tx, source string, rows int) error {
+	if err := w.guard.AddRows(StepPopulate, source, rows); err != nil {
+		return err
+	}
+	return w.guard.CheckMemory(ctx, tx, StepPopulate, source)
+}
+
 // ExecuteSQL выполняет SQL запрос в workspace и возвращает результат как DataPacket
 func (w *Workspace) ExecuteSQL(ctx context.Context, sqlQuery, resultTableName string) (*packet.DataPacket, error) {
 	// Выполняем SELECT запрос
@@ -203,11 +232,22 @@ func (w *Workspace) ExecuteSQL(ctx context.Context, sqlQuery, resultTableName st
 		}
 
 		allRows = append(allRows, rowValues)
+		if len(allRows)%guardCheckEvery == 0 {
+			if err := w.guard.CheckRows(StepTransform, "", len(allRows)); err != nil {
+				return nil, err
+			}
+			if err := w.guard.CheckMemory(ctx, nil, StepTransform, ""); err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("error reading rows: %w", err)
 	}
+	if err := w.guard.CheckRows(StepTransform, "", len(allRows)); err != nil {
+		return nil, err
+	}
 
 	result.Data = packet.RowsToData(allRows)
 	result.Header.RecordsInPart = len(allRows)
@@ -277,6 +317,9 @@ func (w *Workspace) ExecuteSQLStream(ctx context.Context, sqlQuery, resultTableN
 			}
 		}
 
+		// Строки не копятся — проверяется только память (медленный
+		// консьюмер и буферы брокера тоже её расходуют).
+		scanned := 0
 		for rows.Next() {
 			// Проверяем контекст
 			select {
@@ -286,6 +329,14 @@ func (w *Workspace) ExecuteSQLStream(ctx context.Context, sqlQuery, resultTableN
 			default:
 			}
 
+			scanned++
+			if scanned%guardCheckEvery == 0 {
+				if err := w.guard.CheckMemory(ctx, nil, StepTransformExport, ""); err != nil {
+					errorChan <- err
+					return
+				}
+			}
+
 			if err := rows.Scan(valuePtrs...); err != nil {
 				errorChan <- fmt.Errorf("failed to scan row: %w", err)
 				return
@@ -281,3 +281,64 @@ func TestWorkspace_LoadData_EmptyPacket(t *testing.T) {
 		t.Fatalf("expected count=0, got %v", rows)
 	}
 }
+
+// TestOpenAsDB проверяет packet.OpenAsDB на SQLite-драйвере, который
+// регистрирует адаптер: части одной таблицы сливаются, NULL-маркеры
+// становятся NULL, JOIN между таблицами пакетов работает.
+func TestOpenAsDB(t *testing.T) {
+	ctx := context.Background()
+
+	usersSchema := packet.Schema{Fields: []packet.Field{
+		{Name: "id", Type: "INTEGER", Key: true},
+		{Name: "name", Type: "TEXT"},
+	}}
+	ordersSchema := packet.Schema{Fields: []packet.Field{
+		{Name: "user_id", Type: "INTEGER"},
+		{Name: "amount", Type: "REAL", SpecialValues: &packet.SpecialValues{
+			Null: &packet.MarkerValue{Marker: packet.SpecNullMarker},
+		}},
+	}}
+
+	gen := packet.NewGenerator()
+	users, err := gen.GenerateReference("users", usersSchema, [][]string{{"1", "Alice"}, {"2", "Bob"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	part1, err := gen.GenerateReference("orders", ordersSchema, [][]string{{"1", "10.5"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	part2, err := gen.GenerateReference("orders", ordersSchema, [][]string{{"1", packet.SpecNullMarker}, {"2", "3"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pdb, err := packet.OpenAsDB(ctx, users[0], part1[0], part2[0])
+	if err != nil {
+		t.Fatalf("OpenAsDB: %v", err)
+	}
+	defer func() { _ = pdb.Close() }()
+
+	orders, ok := pdb.Table("ORDERS")
+	if !ok || orders.Rows != 3 {
+		t.Fatalf("orders table = %+v, %v; want 3 rows", orders, ok)
+	}
+
+	var nulls int
+	if err := pdb.DB.QueryRowContext(ctx, `SELECT COUNT(*) FROM orders WHERE amount IS NULL`).Scan(&nulls); err != nil {
+		t.Fatal(err)
+	}
+	if nulls != 1 {
+		t.Errorf("NULL amounts = %d, want 1", nulls)
+	}
+
+	var total float64
+	err = pdb.DB.QueryRowContext(ctx,
+		`SELECT SUM(o.amount) FROM orders o JOIN users u ON u.id = o.user_id WHERE u.name = ?`, "Alice").Scan(&total)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 10.5 {
+		t.Errorf("Alice total = %v, want 10.5", total)
+	}
+}
@@ -0,0 +1,45 @@
+package fake
+
+// Небольшие словари для правдоподобных значений. Цель — похожие на живые
+// данные распределения длин и алфавита (латиница + кириллица), а не
+// реалистичная демография.
+
+var firstNames = []string{
+	"Alexander", "Maria", "Ivan", "Anna", "Dmitry", "Elena", "Sergey", "Olga",
+	"Nikolai", "Tatiana", "John", "Emma", "Michael", "Sophia", "David", "Laura",
+	"Алексей", "Наталья", "Павел", "Ирина", "Андрей", "Светлана", "Михаил", "Юлия",
+}
+
+var lastNames = []string{
+	"Ivanov", "Smirnova", "Kuznetsov", "Popova", "Sokolov", "Lebedeva", "Kozlov",
+	"Novikova", "Morozov", "Petrova", "Smith", "Johnson", "Williams", "Brown",
+	"Garcia", "Miller", "Волков", "Соловьёва", "Васильев", "Зайцева", "Павлов", "Семёнова",
+}
+
+var cities = []string{
+	"Moscow", "Saint Petersburg", "Novosibirsk", "Yekaterinburg", "Kazan",
+	"Almaty", "Minsk", "Yerevan", "Berlin", "Warsaw", "Prague", "Vienna",
+	"London", "Madrid", "Москва", "Самара", "Омск", "Пермь",
+}
+
+var countries = []string{
+	"RU", "KZ", "BY", "AM", "DE", "PL", "CZ", "AT", "GB", "ES", "US", "FR",
+}
+
+var companies = []string{
+	"Acme", "Globex", "Initech", "Umbrella", "Stark Industries", "Wayne Enterprises",
+	"Северсталь Трейд", "Восток Логистик", "Polar Systems", "Baltic Freight",
+}
+
+var companySuffixes = []string{"LLC", "Ltd", "Group", "Inc", "ООО", "АО"}
+
+var emailDomains = []string{
+	"example.com", "example.org", "mail.test", "corp.example", "test.local",
+}
+
+var words = []string{
+	"lorem", "ipsum", "dolor", "sit", "amet", "consectetur", "adipiscing", "elit",
+	"sed", "do", "eiusmod", "tempor", "incididunt", "labore", "dolore", "magna",
+	"aliqua", "order", "invoice", "delivery", "payment", "account", "status",
+	"заказ", "оплата", "доставка", "счёт", "клиент", "склад",
+}
@@ -0,0 +1,516 @@
+// Package fake генерирует синтетические данные по TDTP-схеме — для
+// нагрузочного тестирования адаптеров, брокеров и пайплайнов.
+//
+// Значения соответствуют типам схемы (INTEGER, REAL/DECIMAL со scale, DATE,
+// DATETIME, BOOLEAN 0/1, BLOB base64, TEXT с учётом length), ключевые
+// INTEGER-поля заполняются последовательностью. Подсказки (Hint) задают вид
+// значения (email, phone, name, enum …), диапазон, распределение и долю NULL.
+// Для TEXT-полей без подсказки вид угадывается по имени: email, phone,
+// first_name, city, …
+//
+//	gen, _ := fake.New(schema, fake.Config{Seed: 1, Hints: map[string]fake.Hint{
+//	    "status": {Kind: fake.KindEnum, Values: []string{"new", "paid", "shipped"}, Weights: []float64{1, 5, 3}},
+//	    "amount": {Min: "1", Max: "5000", Distribution: fake.DistNormal},
+//	}})
+//	packets, _ := gen.Packets("orders", 1_000_000)
+package fake
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+	"github.com/ruslano69/tdtp-framework/pkg/core/schema"
+)
+
+// Виды значений (Hint.Kind).
+const (
+	KindSequence  = "sequence" // 1, 2, 3 … (от Min); для TEXT — <field>-<n>
+	KindEnum      = "enum"     // одно из Values (с весами Weights)
+	KindEmail     = "email"
+	KindPhone     = "phone"
+	KindName      = "name" // имя и фамилия
+	KindFirstName = "first_name"
+	KindLastName  = "last_name"
+	KindCity      = "city"
+	KindCountry   = "country" // ISO 3166 alpha-2
+	KindCompany   = "company"
+	KindUUID      = "uuid"
+	KindText      = "text" // несколько слов, до length поля
+)
+
+// Распределения числовых значений и дат (Hint.Distribution).
+const (
+	DistUniform = "uniform" // по умолчанию
+	DistNormal  = "normal"  // центр диапазона, σ = (Max-Min)/6, с отсечением по границам
+	DistZipf    = "zipf"    // степенной «хвост»: малые значения (и первые Values) встречаются чаще
+)
+
+// Hint — подсказка для генерации одной колонки.
+type Hint struct {
+	Kind string `yaml:"kind,omitempty" json:"kind,omitempty"`
+
+	// Values/Weights — набор значений для enum; без весов — равновероятно.
+	Values  []string  `yaml:"values,omitempty" json:"values,omitempty"`
+	Weights []float64 `yaml:"weights,omitempty" json:"weights,omitempty"`
+
+	// Min/Max — диапазон: число для числовых полей, дата (YYYY-MM-DD или
+	// RFC3339) для DATE/DATETIME/TIMESTAMP.
+	Min string `yaml:"min,omitempty" json:"min,omitempty"`
+	Max string `yaml:"max,omitempty" json:"max,omitempty"`
+
+	Distribution string `yaml:"distribution,omitempty" json:"distribution,omitempty"`
+
+	// NullRate — доля пустых значений (NULL), 0..1.
+	NullRate float64 `yaml:"null_rate,omitempty" json:"null_rate,omitempty"`
+}
+
+// Config — параметры генератора.
+type Config struct {
+	// Seed делает данные воспроизводимыми; 0 — случайный.
+	Seed uint64 `yaml:"seed,omitempty" json:"seed,omitempty"`
+
+	// Hints — подсказки по именам полей.
+	Hints map[string]Hint `yaml:"hints,omitempty" json:"hints,omitempty"`
+}
+
+// Generator выдаёт строки по схеме. Не потокобезопасен: для параллельной
+// генерации создавайте по генератору на горутину с разными Seed.
+type Generator struct {
+	schema  packet.Schema
+	columns []column
+	rng     *rand.Rand
+	row     int64
+}
+
+type column struct {
+	nullRate float64
+	gen      func(g *Generator) string
+}
+
+// New создаёт генератор для схемы.
+func New(sch packet.Schema, cfg Config) (*Generator, error) {
+	if len(sch.Fields) == 0 {
+		return nil, fmt.Errorf("schema has no fields")
+	}
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = rand.Uint64()
+	}
+	g := &Generator{
+		schema: sch,
+		rng:    rand.New(rand.NewPCG(seed, seed^0x9e3779b97f4a7c15)),
+	}
+	for name := range cfg.Hints {
+		if fieldIndex(sch, name) < 0 {
+			return nil, fmt.Errorf("hint for unknown field '%s'", name)
+		}
+	}
+	for _, f := range sch.Fields {
+		hint, ok := cfg.Hints[f.Name]
+		if !ok {
+			hint = inferHint(f)
+		}
+		col, err := newColumn(f, hint)
+		if err != nil {
+			return nil, fmt.Errorf("field '%s': %w", f.Name, err)
+		}
+		g.columns = append(g.columns, col)
+	}
+	return g, nil
+}
+
+// Schema возвращает схему генератора.
+func (g *Generator) Schema() packet.Schema {
+	return g.schema
+}
+
+// Row возвращает следующую строку.
+func (g *Generator) Row() []string {
+	g.row++
+	row := make([]string, len(g.columns))
+	for i, c := range g.columns {
+		if c.nullRate > 0 && g.rng.Float64() < c.nullRate {
+			continue
+		}
+		row[i] = c.gen(g)
+	}
+	return row
+}
+
+// Rows возвращает n следующих строк.
+func (g *Generator) Rows(n int) [][]string {
+	rows := make([][]string, n)
+	for i := range rows {
+		rows[i] = g.Row()
+	}
+	return rows
+}
+
+// Each генерирует n строк пачками по batch и передаёт их в fn — для объёмов,
+// которые не нужно держать в памяти целиком. Ошибка из fn прерывает генерацию.
+func (g *Generator) Each(n, batch int, fn func(rows [][]string) error) error {
+	if batch <= 0 {
+		batch = 1000
+	}
+	for done := 0; done < n; {
+		size := min(batch, n-done)
+		if err := fn(g.Rows(size)); err != nil {
+			return err
+		}
+		done += size
+	}
+	return nil
+}
+
+// Packets генерирует n строк и упаковывает их в reference-пакеты
+// (с разбиением по размеру, как при обычном экспорте).
+func (g *Generator) Packets(tableName string, n int) ([]*packet.DataPacket, error) {
+	return packet.NewGenerator().GenerateReference(tableName, g.schema, g.Rows(n))
+}
+
+// inferHint угадывает вид значения по имени TEXT-поля; ключевые поля
+// получают последовательность.
+func inferHint(f packet.Field) Hint {
+	if f.Key {
+		return Hint{Kind: KindSequence}
+	}
+	if strings.EqualFold(f.Subtype, "uuid") {
+		return Hint{Kind: KindUUID}
+	}
+	if schema.NormalizeType(schema.DataType(f.Type)) != schema.TypeText {
+		return Hint{}
+	}
+	name := strings.ToLower(f.Name)
+	switch {
+	case strings.Contains(name, "email") || strings.Contains(name, "mail"):
+		return Hint{Kind: KindEmail}
+	case strings.Contains(name, "phone") || strings.HasPrefix(name, "tel"):
+		return Hint{Kind: KindPhone}
+	case strings.Contains(name, "first") && strings.Contains(name, "name"):
+		return Hint{Kind: KindFirstName}
+	case (strings.Contains(name, "last") && strings.Contains(name, "name")) || strings.Contains(name, "surname"):
+		return Hint{Kind: KindLastName}
+	case name == "name" || name == "full_name" || name == "fullname" || name == "fio":
+		return Hint{Kind: KindName}
+	case strings.Contains(name, "city"):
+		return Hint{Kind: KindCity}
+	case strings.Contains(name, "country"):
+		return Hint{Kind: KindCountry}
+	case strings.Contains(name, "company") || strings.Contains(name, "org"):
+		return Hint{Kind: KindCompany}
+	case strings.Contains(name, "uuid") || strings.Contains(name, "guid"):
+		return Hint{Kind: KindUUID}
+	}
+	return Hint{}
+}
+
+func newColumn(f packet.Field, h Hint) (column, error) {
+	if h.NullRate < 0 || h.NullRate > 1 {
+		return column{}, fmt.Errorf("null_rate must be within 0..1")
+	}
+	typ := schema.NormalizeType(schema.DataType(f.Type))
+	gen, err := valueGen(f, typ, h)
+	if err != nil {
+		return column{}, err
+	}
+	if typ == schema.TypeText && f.Length > 0 {
+		inner := gen
+		gen = func(g *Generator) string { return truncateRunes(inner(g), f.Length) }
+	}
+	return column{nullRate: h.NullRate, gen: gen}, nil
+}
+
+func valueGen(f packet.Field, typ schema.DataType, h Hint) (func(*Generator) string, error) {
+	switch h.Kind {
+	case KindEnum:
+		return enumGen(h)
+	case KindSequence:
+		start := int64(1)
+		if h.Min != "" {
+			v, err := strconv.ParseInt(h.Min, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("sequence min: %w", err)
+			}
+			start = v
+		}
+		if typ == schema.TypeText {
+			prefix := strings.ToLower(f.Name) + "-"
+			return func(g *Generator) string { return prefix + strconv.FormatInt(start+g.row-1, 10) }, nil
+		}
+		return func(g *Generator) string { return strconv.FormatInt(start+g.row-1, 10) }, nil
+	case KindEmail:
+		return func(g *Generator) string {
+			return fmt.Sprintf("%s.%s%d@%s",
+				strings.ToLower(pick(g, asciiFirstNames)), strings.ToLower(pick(g, asciiLastNames)),
+				g.rng.IntN(1000), pick(g, emailDomains))
+		}, nil
+	case KindPhone:
+		return func(g *Generator) string {
+			return fmt.Sprintf("+7 (9%02d) %03d-%02d-%02d", g.rng.IntN(100), g.rng.IntN(1000), g.rng.IntN(100), g.rng.IntN(100))
+		}, nil
+	case KindName:
+		return func(g *Generator) string { return pick(g, firstNames) + " " + pick(g, lastNames) }, nil
+	case KindFirstName:
+		return func(g *Generator) string { return pick(g, firstNames) }, nil
+	case KindLastName:
+		return func(g *Generator) string { return pick(g, lastNames) }, nil
+	case KindCity:
+		return func(g *Generator) string { return pick(g, cities) }, nil
+	case KindCountry:
+		return func(g *Generator) string { return pick(g, countries) }, nil
+	case KindCompany:
+		return func(g *Generator) string { return pick(g, companies) + " " + pick(g, companySuffixes) }, nil
+	case KindUUID:
+		return func(g *Generator) string {
+			hi, lo := g.rng.Uint64(), g.rng.Uint64()
+			hi = hi&^0xf000 | 0x4000     // version 4
+			lo = lo&^(0xc<<60) | 0x8<<60 // variant RFC 4122
+			return fmt.Sprintf("%08x-%04x-%04x-%04x-%012x",
+				hi>>32, (hi>>16)&0xffff, hi&0xffff, lo>>48, lo&0xffffffffffff)
+		}, nil
+	case KindText:
+		return textGen(f), nil
+	case "":
+	default:
+		return nil, fmt.Errorf("unknown hint kind %q", h.Kind)
+	}
+
+	switch typ {
+	case schema.TypeInteger:
+		lo, hi, err := numRange(h, 0, 1_000_000)
+		if err != nil {
+			return nil, err
+		}
+		draw, err := drawer(h.Distribution)
+		if err != nil {
+			return nil, err
+		}
+		return func(g *Generator) string {
+			return strconv.FormatInt(int64(math.Round(lo+draw(g)*(hi-lo))), 10)
+		}, nil
+	case schema.TypeReal, schema.TypeDecimal:
+		lo, hi, err := numRange(h, 0, 10_000)
+		if err != nil {
+			return nil, err
+		}
+		draw, err := drawer(h.Distribution)
+		if err != nil {
+			return nil, err
+		}
+		scale := f.Scale
+		if typ == schema.TypeDecimal && scale == 0 && f.Precision == 0 {
+			scale = 2 // DECIMAL по умолчанию (18,2)
+		}
+		if typ == schema.TypeReal && scale == 0 {
+			scale = 4
+		}
+		return func(g *Generator) string {
+			return strconv.FormatFloat(lo+draw(g)*(hi-lo), 'f', scale, 64)
+		}, nil
+	case schema.TypeBoolean:
+		return func(g *Generator) string { return strconv.Itoa(g.rng.IntN(2)) }, nil
+	case schema.TypeDate, schema.TypeDatetime, schema.TypeTimestamp:
+		from, to, err := timeRange(h)
+		if err != nil {
+			return nil, err
+		}
+		draw, err := drawer(h.Distribution)
+		if err != nil {
+			return nil, err
+		}
+		span := to.Sub(from).Seconds()
+		return func(g *Generator) string {
+			t := from.Add(time.Duration(draw(g)*span) * time.Second)
+			if typ == schema.TypeDate {
+				return t.Format("2006-01-02")
+			}
+			return t.Format(time.RFC3339)
+		}, nil
+	case schema.TypeBlob:
+		size := f.Length
+		if size <= 0 || size > 256 {
+			size = 32
+		}
+		return func(g *Generator) string {
+			b := make([]byte, size)
+			for i := range b {
+				b[i] = byte(g.rng.IntN(256))
+			}
+			return base64.StdEncoding.EncodeToString(b)
+		}, nil
+	default:
+		return textGen(f), nil
+	}
+}
+
+func enumGen(h Hint) (func(*Generator) string, error) {
+	if len(h.Values) == 0 {
+		return nil, fmt.Errorf("enum hint requires values")
+	}
+	if len(h.Weights) == 0 {
+		if h.Distribution == DistZipf {
+			// Частоты 1, 1/2, 1/3 … — первые значения встречаются чаще.
+			for i := range h.Values {
+				h.Weights = append(h.Weights, 1/float64(i+1))
+			}
+		} else {
+			return func(g *Generator) string { return pick(g, h.Values) }, nil
+		}
+	}
+	if len(h.Weights) != len(h.Values) {
+		return nil, fmt.Errorf("enum weights count (%d) != values count (%d)", len(h.Weights), len(h.Values))
+	}
+	cumulative := make([]float64, len(h.Weights))
+	total := 0.0
+	for i, w := range h.Weights {
+		if w < 0 {
+			return nil, fmt.Errorf("enum weight must be >= 0")
+		}
+		total += w
+		cumulative[i] = total
+	}
+	if total == 0 {
+		return nil, fmt.Errorf("enum weights sum to zero")
+	}
+	values := h.Values
+	return func(g *Generator) string {
+		x := g.rng.Float64() * total
+		for i, c := range cumulative {
+			if x < c {
+				return values[i]
+			}
+		}
+		return values[len(values)-1]
+	}, nil
+}
+
+func textGen(f packet.Field) func(*Generator) string {
+	// Длинные поля (описания, комментарии) получают пропорционально больше слов.
+	maxWords := 4
+	if f.Length > 0 {
+		maxWords = min(max(f.Length/8, 1), 60)
+	}
+	return func(g *Generator) string {
+		n := 1 + g.rng.IntN(maxWords)
+		parts := make([]string, n)
+		for i := range parts {
+			parts[i] = pick(g, words)
+		}
+		return strings.Join(parts, " ")
+	}
+}
+
+// drawer возвращает функцию, выдающую число из [0, 1] по распределению.
+func drawer(dist string) (func(*Generator) float64, error) {
+	switch dist {
+	case "", DistUniform:
+		return func(g *Generator) float64 { return g.rng.Float64() }, nil
+	case DistNormal:
+		return func(g *Generator) float64 {
+			return math.Min(1, math.Max(0, 0.5+g.rng.NormFloat64()/6))
+		}, nil
+	case DistZipf:
+		return func(g *Generator) float64 {
+			// Парето-подобный хвост: P(x > t) убывает как степень t.
+			return math.Pow(g.rng.Float64(), 4)
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown distribution %q", dist)
+	}
+}
+
+func numRange(h Hint, defLo, defHi float64) (float64, float64, error) {
+	lo, hi := defLo, defHi
+	var err error
+	if h.Min != "" {
+		if lo, err = strconv.ParseFloat(h.Min, 64); err != nil {
+			return 0, 0, fmt.Errorf("invalid min %q", h.Min)
+		}
+		if h.Max == "" && hi < lo {
+			hi = lo + defHi - defLo
+		}
+	}
+	if h.Max != "" {
+		if hi, err = strconv.ParseFloat(h.Max, 64); err != nil {
+			return 0, 0, fmt.Errorf("invalid max %q", h.Max)
+		}
+	}
+	if hi < lo {
+		return 0, 0, fmt.Errorf("max (%v) < min (%v)", hi, lo)
+	}
+	return lo, hi, nil
+}
+
+func timeRange(h Hint) (time.Time, time.Time, error) {
+	to := time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC)
+	from := to.AddDate(-5, 0, 0)
+	var err error
+	if h.Min != "" {
+		if from, err = parseTime(h.Min); err != nil {
+			return from, to, err
+		}
+	}
+	if h.Max != "" {
+		if to, err = parseTime(h.Max); err != nil {
+			return from, to, err
+		}
+	}
+	if to.Before(from) {
+		return from, to, fmt.Errorf("max (%s) is before min (%s)", h.Max, h.Min)
+	}
+	return from, to, nil
+}
+
+func parseTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return t, fmt.Errorf("invalid date %q (want YYYY-MM-DD or RFC3339)", s)
+	}
+	return t, nil
+}
+
+func pick(g *Generator, list []string) string {
+	return list[g.rng.IntN(len(list))]
+}
+
+func truncateRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
+
+func fieldIndex(sch packet.Schema, name string) int {
+	for i, f := range sch.Fields {
+		if f.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// asciiFirstNames/asciiLastNames — словари для email (только латиница).
+var (
+	asciiFirstNames = asciiOnly(firstNames)
+	asciiLastNames  = asciiOnly(lastNames)
+)
+
+func asciiOnly(list []string) []string {
+	var out []string
+	for _, s := range list {
+		if utf8.RuneCountInString(s) == len(s) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
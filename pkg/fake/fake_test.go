@@ -0,0 +1,139 @@
+package fake
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+	"github.com/ruslano69/tdtp-framework/pkg/core/schema"
+)
+
+func ordersSchema() packet.Schema {
+	return packet.Schema{Fields: []packet.Field{
+		{Name: "id", Type: "INTEGER", Key: true},
+		{Name: "email", Type: "TEXT", Length: 100},
+		{Name: "phone", Type: "TEXT", Length: 20},
+		{Name: "status", Type: "TEXT", Length: 10},
+		{Name: "amount", Type: "DECIMAL", Precision: 12, Scale: 2},
+		{Name: "created", Type: "DATE"},
+		{Name: "paid_at", Type: "DATETIME"},
+		{Name: "active", Type: "BOOLEAN"},
+		{Name: "note", Type: "TEXT", Length: 8},
+		{Name: "payload", Type: "BLOB"},
+	}}
+}
+
+func TestGenerator_ValuesMatchSchema(t *testing.T) {
+	sch := ordersSchema()
+	g, err := New(sch, Config{Seed: 1, Hints: map[string]Hint{
+		"status": {Kind: KindEnum, Values: []string{"new", "paid"}},
+		"amount": {Min: "10", Max: "20"},
+		"note":   {NullRate: 0.5},
+	}})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	conv := schema.NewConverter()
+	email := regexp.MustCompile(`^[a-z]+\.[a-z]+\d+@[a-z.]+$`)
+	nulls := 0
+	for i, row := range g.Rows(500) {
+		if row[0] != strconv.Itoa(i+1) {
+			t.Fatalf("row %d: key = %q, want sequence", i, row[0])
+		}
+		if !email.MatchString(row[1]) {
+			t.Errorf("email %q", row[1])
+		}
+		if row[3] != "new" && row[3] != "paid" {
+			t.Errorf("status %q outside enum", row[3])
+		}
+		if v, _ := strconv.ParseFloat(row[4], 64); v < 10 || v > 20 || !strings.Contains(row[4], ".") {
+			t.Errorf("amount %q outside [10,20]", row[4])
+		}
+		if row[8] == "" {
+			nulls++
+		}
+		for j, f := range sch.Fields {
+			if row[j] == "" {
+				continue
+			}
+			if _, err := conv.ParseValue(row[j], schema.FieldDef{
+				Name: f.Name, Type: schema.DataType(f.Type), Length: f.Length,
+				Precision: f.Precision, Scale: f.Scale,
+			}); err != nil {
+				t.Errorf("row %d field %s = %q: %v", i, f.Name, row[j], err)
+			}
+		}
+	}
+	if nulls < 150 || nulls > 350 {
+		t.Errorf("note: %d NULLs of 500, want ~250", nulls)
+	}
+}
+
+func TestGenerator_SeedIsReproducible(t *testing.T) {
+	a, _ := New(ordersSchema(), Config{Seed: 42})
+	b, _ := New(ordersSchema(), Config{Seed: 42})
+	for i := 0; i < 50; i++ {
+		ra, rb := a.Row(), b.Row()
+		if strings.Join(ra, "|") != strings.Join(rb, "|") {
+			t.Fatalf("row %d differs:\n%v\n%v", i, ra, rb)
+		}
+	}
+}
+
+func TestGenerator_EnumWeights(t *testing.T) {
+	sch := packet.Schema{Fields: []packet.Field{{Name: "s", Type: "TEXT"}}}
+	g, err := New(sch, Config{Seed: 3, Hints: map[string]Hint{
+		"s": {Kind: KindEnum, Values: []string{"a", "b"}, Weights: []float64{9, 1}},
+	}})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	counts := map[string]int{}
+	for _, row := range g.Rows(10_000) {
+		counts[row[0]]++
+	}
+	if counts["a"] < 8500 || counts["a"] > 9500 {
+		t.Errorf("a = %d of 10000, want ~9000", counts["a"])
+	}
+}
+
+func TestGenerator_Packets(t *testing.T) {
+	g, _ := New(ordersSchema(), Config{Seed: 5})
+	packets, err := g.Packets("orders", 1000)
+	if err != nil {
+		t.Fatalf("Packets: %v", err)
+	}
+	total := 0
+	for _, p := range packets {
+		total += p.Header.RecordsInPart
+	}
+	if total != 1000 {
+		t.Errorf("packets hold %d rows, want 1000", total)
+	}
+
+	n := 0
+	if err := g.Each(2500, 1000, func(rows [][]string) error { n += len(rows); return nil }); err != nil || n != 2500 {
+		t.Errorf("Each: %d rows, err %v", n, err)
+	}
+}
+
+func TestNew_Errors(t *testing.T) {
+	sch := ordersSchema()
+	cases := map[string]Config{
+		"unknown field":        {Hints: map[string]Hint{"nope": {}}},
+		"unknown kind":         {Hints: map[string]Hint{"note": {Kind: "ssn"}}},
+		"enum without values":  {Hints: map[string]Hint{"status": {Kind: KindEnum}}},
+		"weights mismatch":     {Hints: map[string]Hint{"status": {Kind: KindEnum, Values: []string{"a"}, Weights: []float64{1, 2}}}},
+		"bad range":            {Hints: map[string]Hint{"amount": {Min: "5", Max: "1"}}},
+		"unknown distribution": {Hints: map[string]Hint{"amount": {Distribution: "cauchy"}}},
+		"bad null rate":        {Hints: map[string]Hint{"note": {NullRate: 2}}},
+	}
+	for name, cfg := range cases {
+		if _, err := New(sch, cfg); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+// Package history хранит историю запусков пайплайнов и синхронизаций:
+// хеш конфигурации, время начала и окончания, статистику по шагам, число
+// перенесённых строк и ошибку. Без неё статистика живёт только в выводе
+// процесса и пропадает вместе с ним.
+//
+// Хранилище — таблица в SQLite или PostgreSQL (Store); драйвер database/sql
+// регистрирует вызывающий (tdtpcli: pkg/adapters/sqlite и pgx/v5/stdlib).
+package history
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Kind — тип запуска.
+type Kind string
+
+const (
+	KindPipeline Kind = "pipeline" // tdtpcli --pipeline
+	KindSync     Kind = "sync"     // tdtpcli --sync-incremental
+	KindExport   Kind = "export"   // tdtpcli --export-broker (доставка по частям, Run.Parts)
+)
+
+// Status — итог запуска (значения совпадают со статусами resultlog).
+type Status string
+
+const (
+	StatusSuccess             Status = "success"
+	StatusFailed              Status = "failed"
+	StatusCompletedWithErrors Status = "completed_with_errors" // error-пакет записан, exit 0
+)
+
+// Run — запись об одном запуске.
+type Run struct {
+	ID          string            `json:"id"`
+	Kind        Kind              `json:"kind"`
+	Name        string            `json:"name"`                  // имя пайплайна / таблицы
+	ConfigHash  string            `json:"config_hash,omitempty"` // SHA-256 снимка конфигурации (HashConfig)
+	Status      Status            `json:"status"`
+	StartedAt   time.Time         `json:"started_at"`
+	FinishedAt  time.Time         `json:"finished_at"`
+	DurationMs  int64             `json:"duration_ms"`
+	RowsRead    int64             `json:"rows_read"`
+	RowsWritten int64             `json:"rows_written"`
+	Error       string            `json:"error,omitempty"`
+	Steps       []Step            `json:"steps,omitempty"`
+	Parts       []Part            `json:"parts,omitempty"` // состояние доставки частей (KindExport)
+	Metadata    map[string]string `json:"metadata,omitempty"`
+	Host        string            `json:"host,omitempty"`
+}
+
+// Step — статистика одного шага запуска.
+type Step struct {
+	Name       string `json:"name"`
+	Rows       int64  `json:"rows"`
+	DurationMs int64  `json:"duration_ms,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// PartStatus — состояние доставки части.
+type PartStatus string
+
+const (
+	PartDelivered PartStatus = "delivered"
+	PartFailed    PartStatus = "failed"
+)
+
+// Part — доставка одной части выгрузки. Недоставленную часть повтор
+// (--retry-failed-parts) отправляет из архива (Archive) или выгружает
+// заново строки с ключами Keys (значения KeyField строк части) — диапазон
+// захватил бы строки соседних частей.
+type Part struct {
+	Number    int        `json:"number"`
+	Status    PartStatus `json:"status"`
+	MessageID string     `json:"message_id,omitempty"`
+	Rows      int        `json:"rows"`
+	Error     string     `json:"error,omitempty"`
+	Archive   string     `json:"archive,omitempty"` // файл с отправляемым сообщением
+	KeyField  string     `json:"key_field,omitempty"`
+	Keys      []string   `json:"keys,omitempty"`
+}
+
+// NewRun начинает запись о запуске.
+func NewRun(kind Kind, name string) *Run {
+	host, _ := os.Hostname()
+	return &Run{
+		ID:        uuid.New().String(),
+		Kind:      kind,
+		Name:      name,
+		StartedAt: time.Now().UTC(),
+		Host:      host,
+	}
+}
+
+// Finish фиксирует окончание запуска. err == nil — успех; статус
+// StatusCompletedWithErrors, выставленный заранее, сохраняется.
+func (r *Run) Finish(err error) {
+	r.FinishedAt = time.Now().UTC()
+	r.DurationMs = r.FinishedAt.Sub(r.StartedAt).Milliseconds()
+	switch {
+	case err != nil:
+		r.Status = StatusFailed
+		r.Error = err.Error()
+	case r.Status == "":
+		r.Status = StatusSuccess
+	}
+}
+
+// AddStep добавляет статистику шага.
+func (r *Run) AddStep(name string, rows int64, duration time.Duration) {
+	r.Steps = append(r.Steps, Step{Name: name, Rows: rows, DurationMs: duration.Milliseconds()})
+}
+
+// SetPart добавляет или заменяет часть с тем же номером (части — по номеру).
+func (r *Run) SetPart(p Part) {
+	i := sort.Search(len(r.Parts), func(i int) bool { return r.Parts[i].Number >= p.Number })
+	if i < len(r.Parts) && r.Parts[i].Number == p.Number {
+		r.Parts[i] = p
+		return
+	}
+	r.Parts = append(r.Parts, Part{})
+	copy(r.Parts[i+1:], r.Parts[i:])
+	r.Parts[i] = p
+}
+
+// FailedParts возвращает недоставленные части.
+func (r *Run) FailedParts() []Part {
+	var failed []Part
+	for _, p := range r.Parts {
+		if p.Status != PartDelivered {
+			failed = append(failed, p)
+		}
+	}
+	return failed
+}
+
+// HashConfig — SHA-256 конфигурации и подставленных переменных: один и
+// тот же файл с разными @var — разные снимки.
+func HashConfig(data []byte, vars map[string]string) string {
+	h := sha256.New()
+	h.Write(data)
+	names := make([]string, 0, len(vars))
+	for k := range vars {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	for _, k := range names {
+		h.Write([]byte("\n@" + k + "=" + vars[k]))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
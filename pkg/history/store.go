@@ -0,0 +1,263 @@
+package history
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultTable — таблица истории по умолчанию.
+const DefaultTable = "tdtp_runs"
+
+// Config — подключение к хранилищу истории.
+type Config struct {
+	Type  string `yaml:"type"`            // sqlite, postgres
+	DSN   string `yaml:"dsn"`             // путь к файлу SQLite или строка подключения PostgreSQL
+	Table string `yaml:"table,omitempty"` // по умолчанию tdtp_runs
+}
+
+// driverNames — драйвер database/sql для Config.Type.
+var driverNames = map[string]string{
+	"sqlite":   "sqlite",
+	"postgres": "pgx",
+}
+
+var tableNameRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Store — таблица истории запусков.
+type Store struct {
+	db       *sql.DB
+	table    string
+	postgres bool
+}
+
+// Filter — условия поиска запусков. Пустые поля не фильтруют.
+type Filter struct {
+	Kind   Kind
+	Name   string
+	Status Status
+	Since  time.Time
+	Until  time.Time
+	Limit  int // 0 — 50
+}
+
+// Open подключается к хранилищу и создаёт таблицу, если её нет.
+func Open(cfg Config) (*Store, error) {
+	driver, ok := driverNames[cfg.Type]
+	if !ok {
+		return nil, fmt.Errorf("history.type %q not supported (expected sqlite or postgres)", cfg.Type)
+	}
+	if cfg.DSN == "" {
+		return nil, fmt.Errorf("history.dsn is required")
+	}
+	table := cfg.Table
+	if table == "" {
+		table = DefaultTable
+	}
+	if !tableNameRe.MatchString(table) {
+		return nil, fmt.Errorf("history.table %q is not a valid identifier", table)
+	}
+
+	db, err := sql.Open(driver, cfg.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history store: %w", err)
+	}
+	// Параллельные tdtpcli пишут в один файл: ждать блокировку, а не падать
+	// с SQLITE_BUSY (как и audit.database).
+	if cfg.Type == "sqlite" {
+		for _, pragma := range []string{"PRAGMA busy_timeout = 5000", "PRAGMA journal_mode = WAL"} {
+			if _, err := db.Exec(pragma); err != nil {
+				_ = db.Close()
+				return nil, fmt.Errorf("failed to apply %q: %w", pragma, err)
+			}
+		}
+	}
+
+	s := &Store{db: db, table: table, postgres: cfg.Type == "postgres"}
+	if err := s.createTable(); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to create history table: %w", err)
+	}
+	return s, nil
+}
+
+// Close закрывает подключение.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+func (s *Store) createTable() error {
+	query := fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %s (
+			id VARCHAR(64) PRIMARY KEY,
+			kind VARCHAR(20) NOT NULL,
+			name VARCHAR(255) NOT NULL,
+			config_hash VARCHAR(64),
+			status VARCHAR(30) NOT NULL,
+			started_at TIMESTAMP NOT NULL,
+			finished_at TIMESTAMP NOT NULL,
+			duration_ms BIGINT DEFAULT 0,
+			rows_read BIGINT DEFAULT 0,
+			rows_written BIGINT DEFAULT 0,
+			error_message TEXT,
+			steps TEXT,
+			metadata TEXT,
+			host VARCHAR(255),
+			parts TEXT
+		)
+	`, s.table)
+	if _, err := s.db.Exec(query); err != nil {
+		return err
+	}
+	// Таблицы, созданные до появления parts
+	if _, err := s.db.Exec(fmt.Sprintf("SELECT parts FROM %s WHERE 1=0", s.table)); err != nil {
+		if _, err := s.db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN parts TEXT", s.table)); err != nil {
+			return err
+		}
+	}
+	for _, column := range []string{"started_at", "name", "status"} {
+		index := fmt.Sprintf("CREATE INDEX IF NOT EXISTS idx_%s_%s ON %s(%s)", s.table, column, s.table, column)
+		if _, err := s.db.Exec(index); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Record сохраняет запуск.
+func (s *Store) Record(ctx context.Context, r *Run) error {
+	steps, err := json.Marshal(r.Steps)
+	if err != nil {
+		return fmt.Errorf("failed to marshal steps: %w", err)
+	}
+	metadata, err := json.Marshal(r.Metadata)
+	if err != nil {
+		return fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+	var parts sql.NullString
+	if len(r.Parts) > 0 {
+		data, err := json.Marshal(r.Parts)
+		if err != nil {
+			return fmt.Errorf("failed to marshal parts: %w", err)
+		}
+		parts = sql.NullString{String: string(data), Valid: true}
+	}
+	query := fmt.Sprintf(`
+		INSERT INTO %s (
+			id, kind, name, config_hash, status, started_at, finished_at, duration_ms,
+			rows_read, rows_written, error_message, steps, metadata, host, parts
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, s.table)
+	_, err = s.db.ExecContext(ctx, s.rebind(query),
+		r.ID, string(r.Kind), r.Name, r.ConfigHash, string(r.Status),
+		r.StartedAt.UTC(), r.FinishedAt.UTC(), r.DurationMs,
+		r.RowsRead, r.RowsWritten, r.Error, string(steps), string(metadata), r.Host, parts,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record run: %w", err)
+	}
+	return nil
+}
+
+// Get возвращает запуск по ID (nil — не найден).
+func (s *Store) Get(ctx context.Context, id string) (*Run, error) {
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE id = ?", runColumns, s.table)
+	runs, err := s.query(ctx, s.rebind(query), id)
+	if err != nil || len(runs) == 0 {
+		return nil, err
+	}
+	return runs[0], nil
+}
+
+// Query возвращает запуски по фильтру, новые первыми.
+func (s *Store) Query(ctx context.Context, f Filter) ([]*Run, error) {
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE 1=1", runColumns, s.table)
+	var args []any
+	if f.Kind != "" {
+		query += " AND kind = ?"
+		args = append(args, string(f.Kind))
+	}
+	if f.Name != "" {
+		query += " AND name = ?"
+		args = append(args, f.Name)
+	}
+	if f.Status != "" {
+		query += " AND status = ?"
+		args = append(args, string(f.Status))
+	}
+	if !f.Since.IsZero() {
+		query += " AND started_at >= ?"
+		args = append(args, f.Since.UTC())
+	}
+	if !f.Until.IsZero() {
+		query += " AND started_at <= ?"
+		args = append(args, f.Until.UTC())
+	}
+	limit := f.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+	query += " ORDER BY started_at DESC LIMIT " + strconv.Itoa(limit)
+	return s.query(ctx, s.rebind(query), args...)
+}
+
+const runColumns = `id, kind, name, config_hash, status, started_at, finished_at, duration_ms,
+	rows_read, rows_written, error_message, steps, metadata, host, parts`
+
+func (s *Store) query(ctx context.Context, query string, args ...any) ([]*Run, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query history: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var runs []*Run
+	for rows.Next() {
+		r := &Run{}
+		var kind, status string
+		var configHash, errMsg, steps, metadata, host, parts sql.NullString
+		if err := rows.Scan(&r.ID, &kind, &r.Name, &configHash, &status, &r.StartedAt, &r.FinishedAt,
+			&r.DurationMs, &r.RowsRead, &r.RowsWritten, &errMsg, &steps, &metadata, &host, &parts); err != nil {
+			return nil, fmt.Errorf("failed to scan history row: %w", err)
+		}
+		r.Kind, r.Status = Kind(kind), Status(status)
+		r.ConfigHash, r.Error, r.Host = configHash.String, errMsg.String, host.String
+		if steps.Valid && steps.String != "" {
+			_ = json.Unmarshal([]byte(steps.String), &r.Steps) // повреждённый JSON — без шагов
+		}
+		if metadata.Valid && metadata.String != "" {
+			_ = json.Unmarshal([]byte(metadata.String), &r.Metadata)
+		}
+		if parts.Valid && parts.String != "" {
+			_ = json.Unmarshal([]byte(parts.String), &r.Parts)
+		}
+		runs = append(runs, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating history rows: %w", err)
+	}
+	return runs, nil
+}
+
+// rebind заменяет ? на $1, $2, ... для PostgreSQL.
+func (s *Store) rebind(query string) string {
+	if !s.postgres {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, c := range query {
+		if c == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
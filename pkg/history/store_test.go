@@ -0,0 +1,178 @@
+package history
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+func openTestStore(t *testing.T) *Store {
+	t.Helper()
+	s, err := Open(Config{Type: "sqlite", DSN: filepath.Join(t.TempDir(), "history.db")})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	return s
+}
+
+func TestStore_RecordAndQuery(t *testing.T) {
+	ctx := context.Background()
+	s := openTestStore(t)
+
+	ok := NewRun(KindPipeline, "daily-sales")
+	ok.StartedAt = time.Now().UTC().Add(-2 * time.Hour)
+	ok.ConfigHash = HashConfig([]byte("name: daily-sales"), nil)
+	ok.RowsRead, ok.RowsWritten = 120, 100
+	ok.AddStep("load", 120, 3*time.Second)
+	ok.Metadata = map[string]string{"mode": "safe"}
+	ok.Finish(nil)
+
+	failed := NewRun(KindSync, "orders")
+	failed.Finish(errors.New("export failed: connection refused"))
+
+	for _, r := range []*Run{ok, failed} {
+		if err := s.Record(ctx, r); err != nil {
+			t.Fatalf("Record: %v", err)
+		}
+	}
+
+	all, err := s.Query(ctx, Filter{})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if len(all) != 2 || all[0].ID != failed.ID {
+		t.Fatalf("expected 2 runs, newest first; got %d", len(all))
+	}
+
+	got, err := s.Get(ctx, ok.ID)
+	if err != nil || got == nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got.Status != StatusSuccess || got.RowsWritten != 100 || got.ConfigHash != ok.ConfigHash {
+		t.Errorf("round trip mismatch: %+v", got)
+	}
+	if len(got.Steps) != 1 || got.Steps[0].Name != "load" || got.Steps[0].DurationMs != 3000 {
+		t.Errorf("steps = %+v", got.Steps)
+	}
+	if got.Metadata["mode"] != "safe" {
+		t.Errorf("metadata = %v", got.Metadata)
+	}
+
+	byStatus, _ := s.Query(ctx, Filter{Status: StatusFailed})
+	if len(byStatus) != 1 || byStatus[0].Error != "export failed: connection refused" {
+		t.Errorf("status filter: %+v", byStatus)
+	}
+	byKind, _ := s.Query(ctx, Filter{Kind: KindPipeline, Name: "daily-sales"})
+	if len(byKind) != 1 || byKind[0].ID != ok.ID {
+		t.Errorf("kind/name filter: %+v", byKind)
+	}
+	recent, _ := s.Query(ctx, Filter{Since: time.Now().UTC().Add(-time.Hour)})
+	if len(recent) != 1 || recent[0].ID != failed.ID {
+		t.Errorf("since filter: %+v", recent)
+	}
+
+	if missing, err := s.Get(ctx, "nope"); err != nil || missing != nil {
+		t.Errorf("Get(missing) = %v, %v", missing, err)
+	}
+}
+
+func TestStore_Parts(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "history.db")
+
+	// Таблица без колонки parts (до версии с доставкой по частям)
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`CREATE TABLE tdtp_runs (id VARCHAR(64) PRIMARY KEY, kind VARCHAR(20) NOT NULL,
+		name VARCHAR(255) NOT NULL, config_hash VARCHAR(64), status VARCHAR(30) NOT NULL,
+		started_at TIMESTAMP NOT NULL, finished_at TIMESTAMP NOT NULL, duration_ms BIGINT DEFAULT 0,
+		rows_read BIGINT DEFAULT 0, rows_written BIGINT DEFAULT 0, error_message TEXT, steps TEXT,
+		metadata TEXT, host VARCHAR(255))`); err != nil {
+		t.Fatal(err)
+	}
+	_ = db.Close()
+
+	s, err := Open(Config{Type: "sqlite", DSN: path})
+	if err != nil {
+		t.Fatalf("Open with old table: %v", err)
+	}
+	defer func() { _ = s.Close() }()
+
+	run := NewRun(KindExport, "orders")
+	run.SetPart(Part{Number: 2, Status: PartFailed, Error: "timeout", KeyField: "id", Keys: []string{"101", "200"}})
+	run.SetPart(Part{Number: 1, Status: PartDelivered, Rows: 100})
+	run.SetPart(Part{Number: 3, Status: PartDelivered, Rows: 100})
+	run.Finish(errors.New("1 of 3 part(s) not delivered"))
+	if err := s.Record(ctx, run); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+
+	got, err := s.Get(ctx, run.ID)
+	if err != nil || got == nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if len(got.Parts) != 3 || got.Parts[0].Number != 1 || got.Parts[2].Number != 3 {
+		t.Fatalf("parts = %+v", got.Parts)
+	}
+	failed := got.FailedParts()
+	if len(failed) != 1 || failed[0].Number != 2 || !slices.Equal(failed[0].Keys, []string{"101", "200"}) {
+		t.Errorf("FailedParts = %+v", failed)
+	}
+
+	got.SetPart(Part{Number: 2, Status: PartDelivered, Rows: 100})
+	if len(got.Parts) != 3 || len(got.FailedParts()) != 0 {
+		t.Errorf("SetPart did not replace part 2: %+v", got.Parts)
+	}
+}
+
+func TestOpen_RejectsBadConfig(t *testing.T) {
+	for _, cfg := range []Config{
+		{Type: "oracle", DSN: "x"},
+		{Type: "sqlite"},
+		{Type: "sqlite", DSN: "x.db", Table: "runs; DROP TABLE x"},
+	} {
+		if _, err := Open(cfg); err == nil {
+			t.Errorf("Open(%+v) succeeded", cfg)
+		}
+	}
+}
+
+func TestRun_Finish(t *testing.T) {
+	r := NewRun(KindPipeline, "p")
+	r.Status = StatusCompletedWithErrors
+	r.Finish(nil)
+	if r.Status != StatusCompletedWithErrors || r.FinishedAt.IsZero() {
+		t.Errorf("Finish(nil) overwrote status: %+v", r)
+	}
+
+	r = NewRun(KindPipeline, "p")
+	r.Finish(errors.New("boom"))
+	if r.Status != StatusFailed || r.Error != "boom" {
+		t.Errorf("Finish(err) = %+v", r)
+	}
+}
+
+func TestHashConfig_IncludesVariables(t *testing.T) {
+	cfg := []byte("name: p")
+	a := HashConfig(cfg, map[string]string{"date": "2026-01-01"})
+	b := HashConfig(cfg, map[string]string{"date": "2026-01-02"})
+	if a == b || a != HashConfig(cfg, map[string]string{"date": "2026-01-01"}) {
+		t.Error("hash must be stable and depend on variables")
+	}
+}
+
+func TestRebind(t *testing.T) {
+	s := &Store{postgres: true}
+	if got := s.rebind("a = ? AND b = ?"); got != "a = $1 AND b = $2" {
+		t.Errorf("rebind = %q", got)
+	}
+}
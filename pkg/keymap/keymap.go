@@ -0,0 +1,253 @@
+// Package keymap выдаёт суррогатные ключи хранилища по бизнес-ключам
+// систем-источников и хранит соответствия между запусками: один и тот же
+// клиент из CRM получает тот же customer_sk при каждой загрузке, а его
+// ключ в биллинге можно привязать к тому же суррогату (Store.Link).
+//
+// Хранилище — таблица в SQLite или PostgreSQL (Store); драйвер database/sql
+// регистрирует вызывающий (tdtpcli: pkg/adapters/sqlite и pgx/v5/stdlib).
+// Mapper дописывает суррогат в пакеты импорта (base.ImportHelper.SetKeyMapper),
+// процессор surrogate_key — в строки ETL.
+package keymap
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+// KeySeparator разделяет значения составного бизнес-ключа.
+const KeySeparator = "\x1f"
+
+// DefaultCacheSize — соответствий в памяти Mapper по умолчанию на пару
+// entity/system; при переполнении кеш сбрасывается.
+const DefaultCacheSize = 1_000_000
+
+// Resolver — хранилище соответствий; ему удовлетворяет *Store.
+type Resolver interface {
+	Lookup(ctx context.Context, entity, system string, keys []string) (map[string]int64, error)
+	Resolve(ctx context.Context, entity, system string, keys []string) (map[string]int64, error)
+}
+
+// Mapping — суррогатный ключ одной сущности в таблице пакета.
+type Mapping struct {
+	Table  string   `yaml:"table"`  // таблица пакета (Header.TableName)
+	System string   `yaml:"system"` // система-источник бизнес-ключа
+	Entity string   `yaml:"entity"` // сущность хранилища (пространство суррогатов)
+	Fields []string `yaml:"fields"` // поля бизнес-ключа (составной — несколько)
+	Target string   `yaml:"target"` // поле суррогата; добавляется в схему, если его нет
+}
+
+func (m Mapping) validate() error {
+	switch {
+	case m.System == "" || m.Entity == "":
+		return fmt.Errorf("key mapping requires system and entity")
+	case len(m.Fields) == 0:
+		return fmt.Errorf("key mapping %s: fields are required", m.Entity)
+	case m.Target == "":
+		return fmt.Errorf("key mapping %s: target is required", m.Entity)
+	}
+	for _, f := range m.Fields {
+		if f == m.Target {
+			return fmt.Errorf("key mapping %s: target %s must differ from business key fields", m.Entity, f)
+		}
+	}
+	return nil
+}
+
+// JoinKey собирает бизнес-ключ из значений полей.
+func JoinKey(values []string) string {
+	return strings.Join(values, KeySeparator)
+}
+
+// Mapper дописывает суррогатные ключи в пакеты импорта. Новым бизнес-ключам
+// суррогаты выдаются, в пакетах удаления — только ищутся (неизвестный ключ
+// оставляет поле суррогата пустым). Повторная обработка пакета безопасна:
+// бизнес-ключ остаётся в пакете, суррогат перезаписывается тем же значением.
+type Mapper struct {
+	store     Resolver
+	mappings  []Mapping
+	cacheSize int
+
+	mu    sync.Mutex
+	cache map[string]map[string]int64 // entity + KeySeparator + system → ключ → суррогат
+}
+
+// NewMapper создаёт Mapper поверх store.
+func NewMapper(store Resolver, mappings ...Mapping) (*Mapper, error) {
+	if store == nil {
+		return nil, fmt.Errorf("key mapper requires a store")
+	}
+	for _, m := range mappings {
+		if m.Table == "" {
+			return nil, fmt.Errorf("key mapping %s: table is required", m.Entity)
+		}
+		if err := m.validate(); err != nil {
+			return nil, err
+		}
+	}
+	return &Mapper{
+		store:     store,
+		mappings:  mappings,
+		cacheSize: DefaultCacheSize,
+		cache:     make(map[string]map[string]int64),
+	}, nil
+}
+
+// SetCacheSize ограничивает кеш соответствий на пару entity/system
+// (0 — без кеша).
+func (m *Mapper) SetCacheSize(n int) {
+	m.cacheSize = n
+}
+
+// MapPacket дописывает суррогаты всех Mapping таблицы пакета.
+func (m *Mapper) MapPacket(ctx context.Context, pkt *packet.DataPacket) error {
+	var mappings []Mapping
+	for _, mp := range m.mappings {
+		if mp.Table == pkt.Header.TableName {
+			mappings = append(mappings, mp)
+		}
+	}
+	if len(mappings) == 0 {
+		return nil
+	}
+	pkt.MaterializeRows()
+	if err := packet.ExpandCompactRows(pkt); err != nil {
+		return fmt.Errorf("key mapping: %w", err)
+	}
+
+	rows := pkt.GetRows()
+	for _, mp := range mappings {
+		target := -1
+		for i, f := range pkt.Schema.Fields {
+			if f.Name == mp.Target {
+				target = i
+			}
+		}
+		if target < 0 {
+			pkt.Schema.Fields = append(pkt.Schema.Fields, packet.Field{Name: mp.Target, Type: "INTEGER"})
+			target = len(pkt.Schema.Fields) - 1
+		}
+		if err := m.MapRows(ctx, mp, rows, pkt.Schema, target, !pkt.Data.Delete); err != nil {
+			return fmt.Errorf("table %s: %w", pkt.Header.TableName, err)
+		}
+	}
+
+	pkt.Data.Rows = packet.RowsToData(rows).Rows
+	pkt.Header.RecordsInPart = len(rows)
+	return nil
+}
+
+// MapRows записывает в колонку target строк rows суррогаты бизнес-ключей
+// mp.Fields (строки короче схемы дополняются). assign == false — только
+// поиск: неизвестный ключ оставляет колонку пустой. Пустые и NULL-значения
+// ключевых полей суррогата не получают.
+func (m *Mapper) MapRows(ctx context.Context, mp Mapping, rows [][]string, schema packet.Schema, target int, assign bool) error {
+	if err := mp.validate(); err != nil {
+		return err
+	}
+	idx := make([]int, len(mp.Fields))
+	nulls := make([]string, len(mp.Fields))
+	for i, name := range mp.Fields {
+		idx[i] = -1
+		for j, f := range schema.Fields {
+			if f.Name == name {
+				idx[i] = j
+				if f.SpecialValues != nil && f.SpecialValues.Null != nil {
+					nulls[i] = f.SpecialValues.Null.Marker
+				}
+			}
+		}
+		if idx[i] < 0 {
+			return fmt.Errorf("key mapping %s: field %s not found in schema", mp.Entity, name)
+		}
+	}
+
+	keys := make([]string, len(rows))
+	values := make([]string, len(idx))
+	for r, row := range rows {
+		complete := true
+		for i, j := range idx {
+			if j >= len(row) || row[j] == "" || row[j] == nulls[i] {
+				complete = false
+				break
+			}
+			values[i] = row[j]
+		}
+		if complete {
+			keys[r] = JoinKey(values)
+		}
+	}
+
+	ids, missing := m.cached(mp, keys)
+	if len(missing) > 0 {
+		var found map[string]int64
+		var err error
+		if assign {
+			found, err = m.store.Resolve(ctx, mp.Entity, mp.System, missing)
+		} else {
+			found, err = m.store.Lookup(ctx, mp.Entity, mp.System, missing)
+		}
+		if err != nil {
+			return fmt.Errorf("key mapping %s: %w", mp.Entity, err)
+		}
+		for k, id := range found {
+			ids[k] = id
+		}
+		m.remember(mp, found)
+	}
+
+	for r, row := range rows {
+		for len(row) <= target {
+			row = append(row, "")
+		}
+		row[target] = ""
+		if id, ok := ids[keys[r]]; ok && keys[r] != "" {
+			row[target] = strconv.FormatInt(id, 10)
+		}
+		rows[r] = row
+	}
+	return nil
+}
+
+// cached делит непустые keys на найденные в кеше и отсутствующие в нём.
+func (m *Mapper) cached(mp Mapping, keys []string) (map[string]int64, []string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	cache := m.cache[mp.Entity+KeySeparator+mp.System]
+	ids := make(map[string]int64)
+	var missing []string
+	for _, k := range keys {
+		if k == "" {
+			continue
+		}
+		if id, ok := cache[k]; ok {
+			ids[k] = id
+		} else {
+			missing = append(missing, k)
+		}
+	}
+	return ids, missing
+}
+
+// remember добавляет found в кеш пары entity/system; переполненный кеш
+// сбрасывается.
+func (m *Mapper) remember(mp Mapping, found map[string]int64) {
+	if m.cacheSize <= 0 {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	name := mp.Entity + KeySeparator + mp.System
+	cache := m.cache[name]
+	if cache == nil || len(cache)+len(found) > m.cacheSize {
+		cache = make(map[string]int64, len(found))
+		m.cache[name] = cache
+	}
+	for k, id := range found {
+		cache[k] = id
+	}
+}
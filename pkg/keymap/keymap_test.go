@@ -0,0 +1,125 @@
+package keymap
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+// memoryStore — Resolver в памяти.
+type memoryStore struct {
+	ids     map[string]int64
+	next    int64
+	lookups int
+}
+
+func (s *memoryStore) Lookup(_ context.Context, entity, system string, keys []string) (map[string]int64, error) {
+	s.lookups++
+	out := make(map[string]int64)
+	for _, k := range keys {
+		if id, ok := s.ids[entity+"/"+system+"/"+k]; ok {
+			out[k] = id
+		}
+	}
+	return out, nil
+}
+
+func (s *memoryStore) Resolve(ctx context.Context, entity, system string, keys []string) (map[string]int64, error) {
+	out, _ := s.Lookup(ctx, entity, system, keys)
+	for _, k := range keys {
+		if _, ok := out[k]; !ok {
+			s.next++
+			s.ids[entity+"/"+system+"/"+k] = s.next
+			out[k] = s.next
+		}
+	}
+	return out, nil
+}
+
+func newTestPacket(rows [][]string) *packet.DataPacket {
+	pkt := packet.NewDataPacket(packet.TypeReference, "orders")
+	pkt.Schema.Fields = []packet.Field{
+		{Name: "region", Type: "TEXT"},
+		{Name: "customer_id", Type: "TEXT"},
+		{Name: "amount", Type: "DECIMAL"},
+	}
+	pkt.SetRows(rows)
+	return pkt
+}
+
+func TestMapper_MapPacket(t *testing.T) {
+	store := &memoryStore{ids: map[string]int64{}}
+	m, err := NewMapper(store, Mapping{
+		Table: "orders", System: "crm", Entity: "customer",
+		Fields: []string{"region", "customer_id"}, Target: "customer_sk",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	pkt := newTestPacket([][]string{{"EU", "7", "1.00"}, {"US", "7", "2.00"}, {"EU", "7", "3.00"}, {"EU", "", "4.00"}})
+	if err := m.MapPacket(ctx, pkt); err != nil {
+		t.Fatal(err)
+	}
+	last := pkt.Schema.Fields[len(pkt.Schema.Fields)-1]
+	if last.Name != "customer_sk" || last.Type != "INTEGER" {
+		t.Fatalf("target field = %+v", last)
+	}
+	want := [][]string{{"EU", "7", "1.00", "1"}, {"US", "7", "2.00", "2"}, {"EU", "7", "3.00", "1"}, {"EU", "", "4.00", ""}}
+	if got := pkt.GetRows(); !reflect.DeepEqual(got, want) {
+		t.Errorf("rows = %q, want %q", got, want)
+	}
+
+	// Повторная обработка и следующий пакет обслуживаются кешем
+	lookups := store.lookups
+	if err := m.MapPacket(ctx, pkt); err != nil {
+		t.Fatal(err)
+	}
+	if got := pkt.GetRows(); !reflect.DeepEqual(got, want) || len(pkt.Schema.Fields) != 4 {
+		t.Errorf("remapped packet changed: %q", got)
+	}
+	if store.lookups != lookups {
+		t.Errorf("expected cached keys, store called %d times", store.lookups-lookups)
+	}
+}
+
+func TestMapper_DeletePacketLooksUpOnly(t *testing.T) {
+	store := &memoryStore{ids: map[string]int64{"customer/crm/7": 42}}
+	m, err := NewMapper(store, Mapping{
+		Table: "orders", System: "crm", Entity: "customer",
+		Fields: []string{"customer_id"}, Target: "customer_sk",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pkt := newTestPacket([][]string{{"EU", "7", ""}, {"EU", "8", ""}})
+	pkt.Data.Delete = true
+	if err := m.MapPacket(context.Background(), pkt); err != nil {
+		t.Fatal(err)
+	}
+	rows := pkt.GetRows()
+	if rows[0][3] != "42" || rows[1][3] != "" {
+		t.Errorf("rows = %q", rows)
+	}
+	if store.next != 0 || !pkt.Data.Delete {
+		t.Error("delete packet must not assign surrogates")
+	}
+}
+
+func TestNewMapper_InvalidMapping(t *testing.T) {
+	store := &memoryStore{ids: map[string]int64{}}
+	for _, mp := range []Mapping{
+		{System: "crm", Entity: "customer", Fields: []string{"id"}, Target: "sk"},
+		{Table: "t", Entity: "customer", Fields: []string{"id"}, Target: "sk"},
+		{Table: "t", System: "crm", Entity: "customer", Target: "sk"},
+		{Table: "t", System: "crm", Entity: "customer", Fields: []string{"id"}, Target: "id"},
+	} {
+		if _, err := NewMapper(store, mp); err == nil {
+			t.Errorf("expected error for %+v", mp)
+		}
+	}
+}
@@ -0,0 +1,275 @@
+package keymap
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultTable — таблица соответствий по умолчанию.
+const DefaultTable = "tdtp_key_map"
+
+// lookupChunk — бизнес-ключей в одном SELECT ... IN (...) и в одном
+// многострочном INSERT: 5 параметров на строку укладываются в лимиты
+// SQLite (32766) и PostgreSQL (65535).
+const lookupChunk = 500
+
+// Config — подключение к хранилищу соответствий.
+type Config struct {
+	Type  string `yaml:"type"`            // sqlite, postgres
+	DSN   string `yaml:"dsn"`             // путь к файлу SQLite или строка подключения PostgreSQL
+	Table string `yaml:"table,omitempty"` // по умолчанию tdtp_key_map
+}
+
+// driverNames — драйвер database/sql для Config.Type.
+var driverNames = map[string]string{
+	"sqlite":   "sqlite",
+	"postgres": "pgx",
+}
+
+var tableNameRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Store — таблица соответствий (entity, system, business_key) → surrogate.
+// Суррогаты выдаются последовательно в пределах entity; ключи разных систем
+// одной сущности могут указывать на один суррогат (Link).
+type Store struct {
+	db       *sql.DB
+	table    string
+	postgres bool
+}
+
+// Open подключается к хранилищу и создаёт таблицу, если её нет.
+func Open(cfg Config) (*Store, error) {
+	driver, ok := driverNames[cfg.Type]
+	if !ok {
+		return nil, fmt.Errorf("keymap.type %q not supported (expected sqlite or postgres)", cfg.Type)
+	}
+	if cfg.DSN == "" {
+		return nil, fmt.Errorf("keymap.dsn is required")
+	}
+	table := cfg.Table
+	if table == "" {
+		table = DefaultTable
+	}
+	if !tableNameRe.MatchString(table) {
+		return nil, fmt.Errorf("keymap.table %q is not a valid identifier", table)
+	}
+
+	db, err := sql.Open(driver, cfg.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open key map store: %w", err)
+	}
+	// Параллельные загрузки пишут в один файл: ждать блокировку, а не падать
+	// с SQLITE_BUSY (как и history).
+	if cfg.Type == "sqlite" {
+		for _, pragma := range []string{"PRAGMA busy_timeout = 5000", "PRAGMA journal_mode = WAL"} {
+			if _, err := db.Exec(pragma); err != nil {
+				_ = db.Close()
+				return nil, fmt.Errorf("failed to apply %q: %w", pragma, err)
+			}
+		}
+	}
+
+	s := &Store{db: db, table: table, postgres: cfg.Type == "postgres"}
+	if err := s.createTable(); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to create key map table: %w", err)
+	}
+	return s, nil
+}
+
+// Close закрывает подключение.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+func (s *Store) createTable() error {
+	query := fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %s (
+			entity VARCHAR(255) NOT NULL,
+			system VARCHAR(255) NOT NULL,
+			business_key VARCHAR(1000) NOT NULL,
+			surrogate BIGINT NOT NULL,
+			created_at TIMESTAMP NOT NULL,
+			PRIMARY KEY (entity, system, business_key)
+		)
+	`, s.table)
+	if _, err := s.db.Exec(query); err != nil {
+		return err
+	}
+	// Один суррогат в системе — один бизнес-ключ: защищает от гонки выдачи
+	index := fmt.Sprintf("CREATE UNIQUE INDEX IF NOT EXISTS idx_%s_surrogate ON %s(entity, system, surrogate)", s.table, s.table)
+	_, err := s.db.Exec(index)
+	return err
+}
+
+// Lookup возвращает известные суррогаты бизнес-ключей keys системы system.
+// Неизвестных ключей в результате нет.
+func (s *Store) Lookup(ctx context.Context, entity, system string, keys []string) (map[string]int64, error) {
+	return s.lookup(ctx, s.db, entity, system, unique(keys))
+}
+
+// Resolve возвращает суррогаты всех keys, выдавая новые неизвестным ключам
+// (MAX(surrogate) сущности + 1, ...) в одной транзакции.
+func (s *Store) Resolve(ctx context.Context, entity, system string, keys []string) (map[string]int64, error) {
+	keys = unique(keys)
+	result, err := s.lookup(ctx, s.db, entity, system, keys)
+	if err != nil || len(result) == len(keys) {
+		return result, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin key map transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if s.postgres {
+		// Выдача суррогатов сущности — последовательно между процессами
+		if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock(hashtext($1))", entity); err != nil {
+			return nil, fmt.Errorf("failed to lock key map entity: %w", err)
+		}
+	}
+
+	var missing []string
+	for _, k := range keys {
+		if _, ok := result[k]; !ok {
+			missing = append(missing, k)
+		}
+	}
+	// Ключи могли выдать параллельно — перечитываем внутри транзакции
+	found, err := s.lookup(ctx, tx, entity, system, missing)
+	if err != nil {
+		return nil, err
+	}
+	fresh := missing[:0]
+	for _, k := range missing {
+		if id, ok := found[k]; ok {
+			result[k] = id
+		} else {
+			fresh = append(fresh, k)
+		}
+	}
+
+	var next int64
+	query := fmt.Sprintf("SELECT COALESCE(MAX(surrogate), 0) FROM %s WHERE entity = ?", s.table)
+	if err := tx.QueryRowContext(ctx, s.rebind(query), entity).Scan(&next); err != nil {
+		return nil, fmt.Errorf("failed to read max surrogate: %w", err)
+	}
+
+	now := time.Now().UTC()
+	for start := 0; start < len(fresh); start += lookupChunk {
+		chunk := fresh[start:min(start+lookupChunk, len(fresh))]
+		values := make([]string, len(chunk))
+		args := make([]any, 0, len(chunk)*5)
+		for i, k := range chunk {
+			next++
+			result[k] = next
+			values[i] = "(?, ?, ?, ?, ?)"
+			args = append(args, entity, system, k, next, now)
+		}
+		insert := fmt.Sprintf("INSERT INTO %s (entity, system, business_key, surrogate, created_at) VALUES %s",
+			s.table, strings.Join(values, ", "))
+		if _, err := tx.ExecContext(ctx, s.rebind(insert), args...); err != nil {
+			return nil, fmt.Errorf("failed to insert surrogate keys: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit surrogate keys: %w", err)
+	}
+	return result, nil
+}
+
+// Link привязывает бизнес-ключ key системы system к существующему суррогату
+// (та же сущность из другой системы). Повторная привязка к другому
+// суррогату — ошибка.
+func (s *Store) Link(ctx context.Context, entity, system, key string, surrogate int64) error {
+	known, err := s.Lookup(ctx, entity, system, []string{key})
+	if err != nil {
+		return err
+	}
+	if id, ok := known[key]; ok {
+		if id != surrogate {
+			return fmt.Errorf("key %s/%s/%q is already mapped to %d", entity, system, key, id)
+		}
+		return nil
+	}
+	query := fmt.Sprintf("INSERT INTO %s (entity, system, business_key, surrogate, created_at) VALUES (?, ?, ?, ?, ?)", s.table)
+	if _, err := s.db.ExecContext(ctx, s.rebind(query), entity, system, key, surrogate, time.Now().UTC()); err != nil {
+		return fmt.Errorf("failed to link key: %w", err)
+	}
+	return nil
+}
+
+// querier — *sql.DB или *sql.Tx.
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+func (s *Store) lookup(ctx context.Context, q querier, entity, system string, keys []string) (map[string]int64, error) {
+	result := make(map[string]int64, len(keys))
+	for start := 0; start < len(keys); start += lookupChunk {
+		chunk := keys[start:min(start+lookupChunk, len(keys))]
+		args := make([]any, 0, len(chunk)+2)
+		args = append(args, entity, system)
+		for _, k := range chunk {
+			args = append(args, k)
+		}
+		query := fmt.Sprintf("SELECT business_key, surrogate FROM %s WHERE entity = ? AND system = ? AND business_key IN (%s)",
+			s.table, strings.TrimSuffix(strings.Repeat("?, ", len(chunk)), ", "))
+		rows, err := q.QueryContext(ctx, s.rebind(query), args...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up surrogate keys: %w", err)
+		}
+		for rows.Next() {
+			var key string
+			var id int64
+			if err := rows.Scan(&key, &id); err != nil {
+				_ = rows.Close()
+				return nil, fmt.Errorf("failed to scan key map row: %w", err)
+			}
+			result[key] = id
+		}
+		err = rows.Err()
+		_ = rows.Close()
+		if err != nil {
+			return nil, fmt.Errorf("error iterating key map rows: %w", err)
+		}
+	}
+	return result, nil
+}
+
+func unique(keys []string) []string {
+	seen := make(map[string]bool, len(keys))
+	out := make([]string, 0, len(keys))
+	for _, k := range keys {
+		if !seen[k] {
+			seen[k] = true
+			out = append(out, k)
+		}
+	}
+	return out
+}
+
+// rebind заменяет ? на $1, $2, ... для PostgreSQL.
+func (s *Store) rebind(query string) string {
+	if !s.postgres {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, c := range query {
+		if c == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
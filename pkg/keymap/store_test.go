@@ -0,0 +1,112 @@
+package keymap
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	_ "modernc.org/sqlite"
+)
+
+func openTestStore(t *testing.T) *Store {
+	t.Helper()
+	s, err := Open(Config{Type: "sqlite", DSN: filepath.Join(t.TempDir(), "keymap.db")})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	return s
+}
+
+func TestStore_ResolveStable(t *testing.T) {
+	ctx := context.Background()
+	s := openTestStore(t)
+
+	first, err := s.Resolve(ctx, "customer", "crm", []string{"C-1", "C-2", "C-1"})
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if len(first) != 2 || first["C-1"] == first["C-2"] {
+		t.Fatalf("expected two distinct surrogates, got %v", first)
+	}
+
+	second, err := s.Resolve(ctx, "customer", "crm", []string{"C-2", "C-3"})
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if second["C-2"] != first["C-2"] {
+		t.Errorf("C-2 remapped: %d → %d", first["C-2"], second["C-2"])
+	}
+	if second["C-3"] != 3 {
+		t.Errorf("C-3 = %d, want 3", second["C-3"])
+	}
+
+	// Другая сущность — своё пространство суррогатов
+	other, err := s.Resolve(ctx, "product", "crm", []string{"C-1"})
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if other["C-1"] != 1 {
+		t.Errorf("product C-1 = %d, want 1", other["C-1"])
+	}
+}
+
+func TestStore_LookupAndLink(t *testing.T) {
+	ctx := context.Background()
+	s := openTestStore(t)
+
+	ids, err := s.Resolve(ctx, "customer", "crm", []string{"C-1"})
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if err := s.Link(ctx, "customer", "billing", "ACC-77", ids["C-1"]); err != nil {
+		t.Fatalf("Link: %v", err)
+	}
+	if err := s.Link(ctx, "customer", "billing", "ACC-77", ids["C-1"]+1); err == nil {
+		t.Error("expected error relinking to another surrogate")
+	}
+
+	found, err := s.Lookup(ctx, "customer", "billing", []string{"ACC-77", "ACC-78"})
+	if err != nil {
+		t.Fatalf("Lookup: %v", err)
+	}
+	if len(found) != 1 || found["ACC-77"] != ids["C-1"] {
+		t.Errorf("Lookup = %v, want ACC-77 → %d", found, ids["C-1"])
+	}
+}
+
+func TestStore_ResolveChunks(t *testing.T) {
+	ctx := context.Background()
+	s := openTestStore(t)
+
+	keys := make([]string, lookupChunk*2+7)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("K%05d", i)
+	}
+	ids, err := s.Resolve(ctx, "order", "erp", keys)
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	again, err := s.Lookup(ctx, "order", "erp", keys)
+	if err != nil {
+		t.Fatalf("Lookup: %v", err)
+	}
+	if len(ids) != len(keys) || len(again) != len(keys) {
+		t.Fatalf("resolved %d, looked up %d of %d keys", len(ids), len(again), len(keys))
+	}
+	for _, k := range keys {
+		if ids[k] != again[k] {
+			t.Fatalf("%s: %d != %d", k, ids[k], again[k])
+		}
+	}
+}
+
+func TestOpen_InvalidConfig(t *testing.T) {
+	if _, err := Open(Config{Type: "mysql", DSN: "x"}); err == nil {
+		t.Error("expected error for unsupported type")
+	}
+	if _, err := Open(Config{Type: "sqlite", DSN: "x.db", Table: "bad;name"}); err == nil {
+		t.Error("expected error for invalid table name")
+	}
+}
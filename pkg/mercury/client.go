@@ -152,6 +152,24 @@ func (c *Client) RetrieveKey(ctx context.Context, packageUUID, caller string) (s
 	return result.KeyB64, nil
 }
 
+// Health проверяет доступность xZMercury: GET /healthz.
+// Недоступный сервис — ErrMercuryUnavailable, ответ не 200 — ErrMercuryError.
+func (c *Client) Health(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/healthz", http.NoBody)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrMercuryUnavailable, err.Error())
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: healthz HTTP %d", ErrMercuryError, resp.StatusCode)
+	}
+	return nil
+}
+
 // VerifyHMAC проверяет HMAC-SHA256(packageUUID+":"+mode, serverSecret).
 // mode должен совпадать с режимом сервера ("dev" или "prod") — он включён
 // в подпись, поэтому dev-binding не пройдёт верификацию на prod-консьюмере
@@ -257,6 +257,31 @@ func TestRetrieveKey_EmptyKeyInResponse(t *testing.T) {
 	}
 }
 
+// --- Health ---
+
+func TestHealth(t *testing.T) {
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/healthz" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	client := newTestClient(server)
+	if err := client.Health(context.Background()); err != nil {
+		t.Fatalf("Health() = %v", err)
+	}
+	status = http.StatusServiceUnavailable
+	if err := client.Health(context.Background()); !errors.Is(err, ErrMercuryError) {
+		t.Errorf("Health() error = %v, want ErrMercuryError", err)
+	}
+	if err := NewClient("http://127.0.0.1:1", 200).Health(context.Background()); !errors.Is(err, ErrMercuryUnavailable) {
+		t.Errorf("Health() error = %v, want ErrMercuryUnavailable", err)
+	}
+}
+
 // --- VerifyHMAC ---
 
 func TestVerifyHMAC_Valid(t *testing.T) {
@@ -0,0 +1,47 @@
+package mercury
+
+import (
+	"context"
+	"fmt"
+)
+
+// PacketKeys — источник ключей шифрования пакетов TDTP v1.5 через xZMercury
+// (base.PacketKeyProvider): ключ привязывается к Header.MessageID пакета при
+// экспорте (BindKey) и забирается получателем при импорте (RetrieveKey,
+// burn-on-read — каждый пакет расшифровывается один раз).
+type PacketKeys struct {
+	Client       *Client
+	PipelineName string // имя пайплайна в BindKey (ACL/квоты xZMercury)
+	Caller       string // идентификатор потребителя для audit trail RetrieveKey
+
+	// ServerSecret — MERCURY_SERVER_SECRET для проверки HMAC привязки.
+	// "dev-mode" явно отключает проверку; пусто — ошибка.
+	ServerSecret string
+}
+
+// EncryptionKey привязывает новый ключ к messageID и проверяет HMAC ответа.
+func (k *PacketKeys) EncryptionKey(ctx context.Context, messageID string) ([]byte, error) {
+	binding, err := k.Client.BindKey(ctx, messageID, k.PipelineName)
+	if err != nil {
+		return nil, fmt.Errorf("bind key: %w", err)
+	}
+
+	if k.ServerSecret == "" {
+		return nil, fmt.Errorf("%w: server secret not set — "+
+			"HMAC verification is mandatory; use \"dev-mode\" to opt out explicitly",
+			ErrHMACVerificationFailed)
+	}
+	if k.ServerSecret != "dev-mode" && !VerifyHMAC(messageID, binding.HMAC, k.ServerSecret, binding.Mode) {
+		return nil, fmt.Errorf("%w: uuid=%s mode=%s", ErrHMACVerificationFailed, messageID, binding.Mode)
+	}
+	return DecodeKey(binding.KeyB64)
+}
+
+// DecryptionKey забирает ключ пакета messageID (ключ сжигается сервером).
+func (k *PacketKeys) DecryptionKey(ctx context.Context, messageID string) ([]byte, error) {
+	keyB64, err := k.Client.RetrieveKey(ctx, messageID, k.Caller)
+	if err != nil {
+		return nil, fmt.Errorf("retrieve key (uuid=%s): %w", messageID, err)
+	}
+	return DecodeKey(keyB64)
+}
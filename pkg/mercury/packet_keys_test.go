@@ -0,0 +1,47 @@
+package mercury
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPacketKeys(t *testing.T) {
+	const secret = "s3cret"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/keys/bind":
+			var req BindKeyRequest
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			_ = json.NewEncoder(w).Encode(KeyBinding{
+				KeyB64: testKey32,
+				HMAC:   computeHMAC(req.PackageUUID, secret),
+				Mode:   "prod",
+			})
+		case "/api/keys/retrieve":
+			_ = json.NewEncoder(w).Encode(map[string]string{"key_b64": testKey32})
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	keys := &PacketKeys{Client: newTestClient(server), PipelineName: "p", ServerSecret: secret}
+	if key, err := keys.EncryptionKey(ctx, "REF-2025-abc-P1"); err != nil || len(key) != 32 {
+		t.Fatalf("EncryptionKey: %v", err)
+	}
+	if key, err := keys.DecryptionKey(ctx, "REF-2025-abc-P1"); err != nil || len(key) != 32 {
+		t.Fatalf("DecryptionKey: %v", err)
+	}
+
+	keys.ServerSecret = "other"
+	if _, err := keys.EncryptionKey(ctx, "REF-2025-abc-P1"); !errors.Is(err, ErrHMACVerificationFailed) {
+		t.Errorf("expected HMAC failure, got %v", err)
+	}
+	keys.ServerSecret = ""
+	if _, err := keys.EncryptionKey(ctx, "REF-2025-abc-P1"); !errors.Is(err, ErrHMACVerificationFailed) {
+		t.Errorf("expected error for missing server secret, got %v", err)
+	}
+}
@@ -0,0 +1,288 @@
+// Package operations — реестр выполняющихся операций (экспорт, импорт,
+// синхронизация, пайплайн): список с прогрессом и отмена без убийства
+// процесса.
+//
+// Реестр — каталог на хосте (по умолчанию $TMPDIR/tdtp-operations), общий
+// для всех процессов: tdtpcli регистрирует в нём свои запуски, tdtpserve —
+// свои refresh, а tdtpcli --operations и GET /api/operations видят все.
+// Операция — файл <id>.json, который её процесс обновляет раз в секунду
+// (heartbeat + прогресс). Отмена — файл <id>.cancel: процесс операции
+// замечает его и отменяет context операции, команды завершаются так же,
+// как по ошибке (транзакции импорта откатываются). Операция без heartbeat
+// дольше StaleAfter (процесс убит) из списка удаляется.
+package operations
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ruslano69/tdtp-framework/pkg/progress"
+)
+
+const (
+	// HeartbeatInterval — как часто операция обновляет файл и проверяет отмену.
+	HeartbeatInterval = time.Second
+	// StaleAfter — операция без heartbeat дольше считается завершённой аварийно.
+	StaleAfter = 30 * time.Second
+)
+
+// ErrNotFound — операции с таким ID нет (уже завершилась или не было).
+var ErrNotFound = errors.New("operation not found")
+
+// Info — состояние операции (содержимое <id>.json).
+type Info struct {
+	ID              string    `json:"id"`
+	Command         string    `json:"command"` // export, import, sync-incremental, pipeline, refresh...
+	Name            string    `json:"name"`    // таблица / файл / пайплайн
+	PID             int       `json:"pid"`
+	Host            string    `json:"host"`
+	StartedAt       time.Time `json:"started_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+	Step            string    `json:"step,omitempty"` // из событий progress
+	Item            string    `json:"item,omitempty"`
+	Done            int       `json:"done,omitempty"`
+	Total           int       `json:"total,omitempty"`
+	Percent         float64   `json:"percent,omitempty"`
+	Rows            int64     `json:"rows,omitempty"`
+	CancelRequested bool      `json:"cancel_requested,omitempty"`
+}
+
+// Registry — каталог операций.
+type Registry struct {
+	dir        string
+	interval   time.Duration
+	staleAfter time.Duration
+	now        func() time.Time
+}
+
+// DefaultDir — TDTP_OPERATIONS_DIR или $TMPDIR/tdtp-operations.
+func DefaultDir() string {
+	if dir := os.Getenv("TDTP_OPERATIONS_DIR"); dir != "" {
+		return dir
+	}
+	return filepath.Join(os.TempDir(), "tdtp-operations")
+}
+
+// Open открывает (создаёт) каталог реестра.
+func Open(dir string) (*Registry, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("operations registry %s: %w", dir, err)
+	}
+	return &Registry{dir: dir, interval: HeartbeatInterval, staleAfter: StaleAfter, now: time.Now}, nil
+}
+
+// Dir — каталог реестра.
+func (r *Registry) Dir() string {
+	return r.dir
+}
+
+// Operation — зарегистрированная операция своего процесса.
+type Operation struct {
+	reg    *Registry
+	cancel context.CancelFunc
+	stop   chan struct{}
+	done   chan struct{}
+	once   sync.Once
+
+	mu   sync.Mutex
+	info Info
+}
+
+// Start регистрирует операцию и возвращает её context: он отменяется по
+// Cancel из любого процесса. События progress из этого context обновляют
+// прогресс операции (в дополнение к уже подключённому Reporter). End
+// обязателен — он снимает операцию с учёта.
+func (r *Registry) Start(ctx context.Context, command, name string) (context.Context, *Operation, error) {
+	id, err := newID()
+	if err != nil {
+		return ctx, nil, err
+	}
+	host, _ := os.Hostname()
+	now := r.now().UTC()
+	op := &Operation{
+		reg:  r,
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+		info: Info{ID: id, Command: command, Name: name, PID: os.Getpid(), Host: host, StartedAt: now, UpdatedAt: now},
+	}
+	if err := op.write(); err != nil {
+		return ctx, nil, err
+	}
+
+	ctx, op.cancel = context.WithCancel(ctx)
+	ctx = progress.Tee(ctx, op)
+	go op.watch()
+	return ctx, op, nil
+}
+
+// ID — идентификатор операции (для --cancel и /api/operations/<id>/cancel).
+func (o *Operation) ID() string {
+	if o == nil {
+		return ""
+	}
+	return o.info.ID
+}
+
+// Emit принимает события progress: шаг, элемент, счётчики и строки.
+func (o *Operation) Emit(e progress.Event) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if e.Step != "" {
+		o.info.Step = e.Step
+	}
+	if e.Item != "" {
+		o.info.Item = e.Item
+	}
+	if e.Total > 0 {
+		o.info.Done, o.info.Total, o.info.Percent = e.Done, e.Total, e.Percent
+	}
+	if e.Rows > 0 {
+		o.info.Rows = e.Rows
+	}
+}
+
+// End снимает операцию с учёта. Безопасен для nil и повторного вызова.
+func (o *Operation) End() {
+	if o == nil {
+		return
+	}
+	o.once.Do(func() {
+		close(o.stop)
+		<-o.done
+		o.cancel()
+		_ = os.Remove(o.reg.path(o.info.ID, ".json"))
+		_ = os.Remove(o.reg.path(o.info.ID, ".cancel"))
+	})
+}
+
+// watch — heartbeat и проверка запроса отмены.
+func (o *Operation) watch() {
+	defer close(o.done)
+	ticker := time.NewTicker(o.reg.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-o.stop:
+			return
+		case <-ticker.C:
+		}
+		if _, err := os.Stat(o.reg.path(o.info.ID, ".cancel")); err == nil {
+			o.mu.Lock()
+			first := !o.info.CancelRequested
+			o.info.CancelRequested = true
+			o.mu.Unlock()
+			if first {
+				fmt.Fprintf(os.Stderr, "  ⚠ Operation %s cancelled\n", o.info.ID)
+				o.cancel()
+			}
+		}
+		_ = o.write()
+	}
+}
+
+func (o *Operation) write() error {
+	o.mu.Lock()
+	o.info.UpdatedAt = o.reg.now().UTC()
+	data, err := json.Marshal(o.info)
+	o.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	// Через временный файл: List не должен прочитать недописанный JSON
+	path := o.reg.path(o.info.ID, ".json")
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("register operation: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("register operation: %w", err)
+	}
+	return nil
+}
+
+// List возвращает выполняющиеся операции, старые первыми. Операции без
+// heartbeat дольше StaleAfter удаляются из реестра.
+func (r *Registry) List() ([]Info, error) {
+	entries, err := os.ReadDir(r.dir)
+	if err != nil {
+		return nil, fmt.Errorf("operations registry %s: %w", r.dir, err)
+	}
+	now := r.now()
+	var out []Info
+	for _, e := range entries {
+		id, ok := strings.CutSuffix(e.Name(), ".json")
+		if !ok {
+			continue
+		}
+		info, err := r.read(id)
+		if err != nil {
+			continue // удалена между ReadDir и чтением
+		}
+		if now.Sub(info.UpdatedAt) > r.staleAfter {
+			_ = os.Remove(r.path(id, ".json"))
+			_ = os.Remove(r.path(id, ".cancel"))
+			continue
+		}
+		out = append(out, info)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].StartedAt.Before(out[j].StartedAt) })
+	return out, nil
+}
+
+// Cancel запрашивает отмену операции; процесс операции отменит её в
+// течение HeartbeatInterval.
+func (r *Registry) Cancel(id string) error {
+	if !validID(id) {
+		return fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	info, err := r.read(id)
+	if err != nil || r.now().Sub(info.UpdatedAt) > r.staleAfter {
+		return fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	if err := os.WriteFile(r.path(id, ".cancel"), nil, 0o600); err != nil {
+		return fmt.Errorf("cancel operation %s: %w", id, err)
+	}
+	return nil
+}
+
+func (r *Registry) read(id string) (Info, error) {
+	var info Info
+	data, err := os.ReadFile(r.path(id, ".json"))
+	if err != nil {
+		return info, err
+	}
+	err = json.Unmarshal(data, &info)
+	return info, err
+}
+
+func (r *Registry) path(id, ext string) string {
+	return filepath.Join(r.dir, id+ext)
+}
+
+func newID() (string, error) {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("operation id: %w", err)
+	}
+	return "op-" + hex.EncodeToString(b), nil
+}
+
+// validID не даёт ID из запроса выйти за каталог реестра.
+func validID(id string) bool {
+	rest, ok := strings.CutPrefix(id, "op-")
+	if !ok || rest == "" {
+		return false
+	}
+	_, err := hex.DecodeString(rest)
+	return err == nil
+}
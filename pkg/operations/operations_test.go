@@ -0,0 +1,115 @@
+package operations
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ruslano69/tdtp-framework/pkg/progress"
+)
+
+func openTest(t *testing.T) *Registry {
+	t.Helper()
+	r, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.interval = 10 * time.Millisecond
+	return r
+}
+
+func TestStartListEnd(t *testing.T) {
+	r := openTest(t)
+	ctx, op, err := r.Start(context.Background(), "import", "orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	progress.Emit(ctx, progress.Event{Event: progress.Progress, Step: "load", Item: "part 2", Done: 2, Total: 4, Rows: 500})
+	time.Sleep(50 * time.Millisecond) // heartbeat записывает прогресс
+
+	ops, err := r.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ops) != 1 {
+		t.Fatalf("List = %d operations, want 1", len(ops))
+	}
+	got := ops[0]
+	if got.ID != op.ID() || got.Command != "import" || got.Name != "orders" || got.PID != os.Getpid() {
+		t.Errorf("info = %+v", got)
+	}
+	if got.Done != 2 || got.Total != 4 || got.Percent != 50 || got.Rows != 500 || got.Item != "part 2" {
+		t.Errorf("progress = %+v", got)
+	}
+
+	op.End()
+	op.End() // повторный вызов безопасен
+	if ops, _ := r.List(); len(ops) != 0 {
+		t.Errorf("ended operation still listed: %+v", ops)
+	}
+	if ctx.Err() == nil {
+		t.Error("End must release the operation context")
+	}
+}
+
+func TestCancel(t *testing.T) {
+	r := openTest(t)
+	ctx, op, err := r.Start(context.Background(), "export", "users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer op.End()
+
+	// Отмена из «другого процесса» — через тот же каталог
+	other, err := Open(r.Dir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := other.Cancel(op.ID()); err != nil {
+		t.Fatalf("Cancel: %v", err)
+	}
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("operation context not cancelled")
+	}
+	time.Sleep(30 * time.Millisecond)
+	if ops, _ := r.List(); len(ops) != 1 || !ops[0].CancelRequested {
+		t.Errorf("List = %+v, want cancel_requested", ops)
+	}
+}
+
+func TestCancelUnknown(t *testing.T) {
+	r := openTest(t)
+	for _, id := range []string{"op-0123456789ab", "../etc/passwd", ""} {
+		if err := r.Cancel(id); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Cancel(%q) = %v, want ErrNotFound", id, err)
+		}
+	}
+}
+
+func TestListDropsStale(t *testing.T) {
+	r := openTest(t)
+	_, op, err := r.Start(context.Background(), "sync-incremental", "orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Процесс «убит»: heartbeat остановлен, файл остался
+	close(op.stop)
+	<-op.done
+
+	r.now = func() time.Time { return time.Now().Add(StaleAfter + time.Minute) }
+	if ops, _ := r.List(); len(ops) != 0 {
+		t.Errorf("stale operation listed: %+v", ops)
+	}
+	if _, err := os.Stat(r.path(op.ID(), ".json")); !os.IsNotExist(err) {
+		t.Error("stale operation file must be removed")
+	}
+	if err := r.Cancel(op.ID()); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Cancel(stale) = %v, want ErrNotFound", err)
+	}
+}
@@ -51,3 +51,27 @@ func (c *Chain) Len() int {
 func (c *Chain) IsEmpty() bool {
 	return len(c.processors) == 0
 }
+
+// UnmappedValues собирает отчёты процессоров цепочки, реализующих
+// UnmappedReporter (nil — таких процессоров нет).
+func (c *Chain) UnmappedValues() map[string]map[string]int64 {
+	var out map[string]map[string]int64
+	for _, proc := range c.processors {
+		r, ok := proc.(UnmappedReporter)
+		if !ok {
+			continue
+		}
+		if out == nil {
+			out = make(map[string]map[string]int64)
+		}
+		for field, values := range r.UnmappedValues() {
+			if out[field] == nil {
+				out[field] = make(map[string]int64)
+			}
+			for v, n := range values {
+				out[field][v] += n
+			}
+		}
+	}
+	return out
+}
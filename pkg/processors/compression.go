@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/klauspost/compress/zstd"
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
 )
 
 // Алгоритмы сжатия, поддерживаемые TDTP.
@@ -77,10 +78,15 @@ type DecompressionProcessor struct {
 }
 
 // NewDecompressionProcessor создает новый, готовый к использованию процессор распаковки.
+// Подключает словари из реестра (см. compression_dict.go) — фрейм со словарём
+// распаковывается, если словарь с его ID зарегистрирован.
 func NewDecompressionProcessor() (*DecompressionProcessor, error) {
 	opts := []zstd.DOption{
 		zstd.WithDecoderConcurrency(4), // Использовать до 4 ядер для распаковки
 	}
+	if dicts := registeredZstdDicts(); len(dicts) > 0 {
+		opts = append(opts, zstd.WithDecoderDicts(dicts...))
+	}
 	decoder, err := zstd.NewReader(nil, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create zstd decoder: %w", err)
@@ -104,7 +110,7 @@ func (p *DecompressionProcessor) ProcessBlock(_ context.Context, input []byte) (
 	// 2. Распаковать. Библиотека сама управляет памятью для результата.
 	decompressed, err := p.decoder.DecodeAll(decoded[:n], nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decompress zstd: %w", err)
+		return nil, fmt.Errorf("failed to decompress zstd: %w", wrapZstdDictError(err))
 	}
 
 	return decompressed, nil
@@ -179,6 +185,24 @@ func ShouldCompress(dataSize, minSize int) bool {
 
 // --- Functions for TDTP Integration ---
 
+// init регистрирует zstd и kanzi в core/packet: Generator со включённым
+// сжатием (ExportHelper.SetCompression) и packet.DecompressPacketData
+// (ImportHelper) работают без явной передачи функций сжатия.
+func init() {
+	for _, algo := range []string{AlgoZstd, AlgoKanzi} {
+		packet.RegisterCompressor(algo, func(_ context.Context, rows []string, level int) (string, error) {
+			compressed, _, err := CompressDataForTdtpAlgo(rows, algo, level)
+			return compressed, err
+		})
+	}
+	packet.SetDecompressor(func(_ context.Context, compressed, algo string) ([]string, error) {
+		if algo != AlgoZstd && algo != AlgoKanzi {
+			return nil, fmt.Errorf("unsupported compression algorithm: %s", algo)
+		}
+		return DecompressDataForTdtpAlgo(compressed, algo)
+	})
+}
+
 // CompressDataForTdtp сжимает строки данных для TDTP пакета.
 // Строки объединяются через \n; \n внутри значений полей экранируется в writeEscaped.
 func CompressDataForTdtp(rows []string, level int) (compressedRow string, stats CompressionStats, err error) {
@@ -0,0 +1,265 @@
+// File: pkg/processors/compression_dict.go
+
+package processors
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/klauspost/compress/dict"
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+// Словари zstd для повторяющихся справочных данных.
+//
+// Справочники (валюты, склады, номенклатура) выгружаются маленькими пакетами
+// с одинаковыми префиксами и значениями: zstd без словаря на них почти не
+// выигрывает — в коротком фрейме не на что ссылаться. Словарь, обученный на
+// образцах пакетов таблицы, даёт эти ссылки заранее.
+//
+// Фрейм zstd хранит ID словаря, поэтому распаковка не требует изменений в
+// вызывающем коде: Decompress подключает все словари из реестра, а нужный
+// выбирается по ID. Словарь попадает в реестр тремя путями:
+//   - LoadZstdDictDir — каталог <table>.zdict (общий «реестр» словарей);
+//   - RegisterZstdDict — программно;
+//   - из пакета: <Data dict="ID"><CompressionDict>base64</CompressionDict> — для
+//     получателей без общего каталога (Parser.DecompressData регистрирует
+//     встроенный словарь перед распаковкой).
+
+const (
+	// DefaultZstdDictSize — размер словаря по умолчанию. Для справочников
+	// больший словарь почти не улучшает сжатие, но раздувает встроенные копии.
+	DefaultZstdDictSize = 64 * 1024
+
+	// ZstdDictExt — расширение файлов словарей в каталоге реестра.
+	ZstdDictExt = ".zdict"
+
+	zstdDictMinID       = 32768 // ID < 32768 зарезервированы форматом zstd
+	zstdDictSampleLines = 64    // строк в одном образце для обучения
+)
+
+// ErrZstdDictConflict — под тем же ID уже зарегистрирован другой словарь.
+var ErrZstdDictConflict = errors.New("zstd dictionary ID conflict")
+
+// ZstdDict — обученный словарь zstd.
+type ZstdDict struct {
+	ID   uint32
+	Data []byte
+}
+
+// zstdDictRegistry хранит словари для распаковки (по ID) и для сжатия (по таблице).
+var zstdDictRegistry = struct {
+	sync.RWMutex
+	byID    map[uint32][]byte
+	byTable map[string]*ZstdDict
+}{
+	byID:    make(map[uint32][]byte),
+	byTable: make(map[string]*ZstdDict),
+}
+
+func init() {
+	packet.SetDictionaryLoader(func(encoded string) error {
+		_, err := RegisterEmbeddedZstdDict(encoded)
+		return err
+	})
+}
+
+// TrainZstdDict обучает словарь на образцах — строках данных пакетов одной
+// таблицы (в том виде, в каком их сжимает CompressDataForTdtp).
+// Строки группируются в образцы по zstdDictSampleLines.
+// ID словаря детерминирован: одинаковые образцы дают одинаковый ID.
+// maxSize <= 0 — DefaultZstdDictSize.
+func TrainZstdDict(rows []string, maxSize int) (*ZstdDict, error) {
+	if len(rows) == 0 {
+		return nil, errors.New("no sample rows to train dictionary")
+	}
+	if maxSize <= 0 {
+		maxSize = DefaultZstdDictSize
+	}
+
+	h := fnv.New32a()
+	samples := make([][]byte, 0, len(rows)/zstdDictSampleLines+1)
+	for start := 0; start < len(rows); start += zstdDictSampleLines {
+		sample := []byte(strings.Join(rows[start:min(start+zstdDictSampleLines, len(rows))], "\n"))
+		_, _ = h.Write(sample)
+		samples = append(samples, sample)
+	}
+	id := zstdDictMinID + h.Sum32()%(1<<31-zstdDictMinID)
+
+	data, err := dict.BuildZstdDict(samples, dict.Options{
+		MaxDictSize: maxSize,
+		HashBytes:   6,
+		ZstdDictID:  id,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to train zstd dictionary: %w", err)
+	}
+	return &ZstdDict{ID: id, Data: data}, nil
+}
+
+// ParseZstdDict проверяет словарь и извлекает его ID.
+func ParseZstdDict(data []byte) (*ZstdDict, error) {
+	hdr, err := zstd.InspectDictionary(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid zstd dictionary: %w", err)
+	}
+	if hdr.ID() == 0 {
+		return nil, errors.New("invalid zstd dictionary: missing dictionary ID")
+	}
+	return &ZstdDict{ID: hdr.ID(), Data: data}, nil
+}
+
+// RegisterZstdDict добавляет словарь в реестр распаковки. Повторная
+// регистрация того же словаря допустима; другой словарь с уже занятым ID —
+// ошибка ErrZstdDictConflict (встроенный в пакет словарь не должен подменять
+// словарь из реестра, которым распаковываются остальные пакеты).
+func RegisterZstdDict(data []byte) (*ZstdDict, error) {
+	d, err := ParseZstdDict(data)
+	if err != nil {
+		return nil, err
+	}
+	zstdDictRegistry.Lock()
+	defer zstdDictRegistry.Unlock()
+	if existing, ok := zstdDictRegistry.byID[d.ID]; ok {
+		if !bytes.Equal(existing, d.Data) {
+			return nil, fmt.Errorf("%w: dictionary %d is already registered with different content", ErrZstdDictConflict, d.ID)
+		}
+		return &ZstdDict{ID: d.ID, Data: existing}, nil
+	}
+	zstdDictRegistry.byID[d.ID] = d.Data
+	return d, nil
+}
+
+// RegisterEmbeddedZstdDict регистрирует словарь, встроенный в пакет (base64).
+func RegisterEmbeddedZstdDict(encoded string) (*ZstdDict, error) {
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("invalid embedded dictionary: %w", err)
+	}
+	return RegisterZstdDict(data)
+}
+
+// SetTableZstdDict назначает словарь для сжатия пакетов таблицы
+// (и регистрирует его для распаковки).
+func SetTableZstdDict(table string, data []byte) (*ZstdDict, error) {
+	d, err := RegisterZstdDict(data)
+	if err != nil {
+		return nil, err
+	}
+	zstdDictRegistry.Lock()
+	zstdDictRegistry.byTable[strings.ToLower(table)] = d
+	zstdDictRegistry.Unlock()
+	return d, nil
+}
+
+// TableZstdDict возвращает словарь для сжатия пакетов таблицы или nil.
+func TableZstdDict(table string) *ZstdDict {
+	zstdDictRegistry.RLock()
+	defer zstdDictRegistry.RUnlock()
+	return zstdDictRegistry.byTable[strings.ToLower(table)]
+}
+
+// LoadZstdDictDir загружает все <table>.zdict из каталога: каждый словарь
+// доступен для распаковки по ID и для сжатия пакетов таблицы <table>.
+// Возвращает имена загруженных таблиц.
+func LoadZstdDictDir(dir string) ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*"+ZstdDictExt))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(dir); err != nil {
+		return nil, fmt.Errorf("dictionary directory: %w", err)
+	}
+	slices.Sort(paths)
+	tables := make([]string, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read dictionary: %w", err)
+		}
+		table := strings.TrimSuffix(filepath.Base(path), ZstdDictExt)
+		if _, err := SetTableZstdDict(table, data); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		tables = append(tables, table)
+	}
+	return tables, nil
+}
+
+// registeredZstdDicts возвращает копию словарей реестра для декодера.
+func registeredZstdDicts() [][]byte {
+	zstdDictRegistry.RLock()
+	defer zstdDictRegistry.RUnlock()
+	dicts := make([][]byte, 0, len(zstdDictRegistry.byID))
+	for _, d := range zstdDictRegistry.byID {
+		dicts = append(dicts, d)
+	}
+	return dicts
+}
+
+// CompressDataForTdtpDict сжимает строки TDTP-пакета zstd со словарём.
+// Результат распаковывается обычным Decompress, если словарь есть в реестре.
+func CompressDataForTdtpDict(rows []string, level int, d *ZstdDict) (compressedRow string, stats CompressionStats, err error) {
+	if len(rows) == 0 {
+		return "", CompressionStats{}, nil
+	}
+
+	originalData := []byte(strings.Join(rows, "\n"))
+	start := time.Now()
+
+	encoder, err := zstd.NewWriter(nil,
+		zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(level)),
+		zstd.WithEncoderDict(d.Data),
+	)
+	if err != nil {
+		return "", CompressionStats{}, fmt.Errorf("failed to create zstd encoder: %w", err)
+	}
+	compressed := encoder.EncodeAll(originalData, nil)
+	_ = encoder.Close()
+
+	encoded := base64.StdEncoding.EncodeToString(compressed)
+	stats = GetCompressionStats(originalData, []byte(encoded), time.Since(start))
+	return encoded, stats, nil
+}
+
+// SetPacketZstdDict отмечает в пакете ID словаря, которым сжаты данные.
+// embed — встроить и сам словарь: получатель без общего каталога словарей
+// сможет распаковать данные (ценой размера пакета — имеет смысл для первого
+// пакета или редких выгрузок).
+func SetPacketZstdDict(pkt *packet.DataPacket, d *ZstdDict, embed bool) {
+	pkt.Data.ZstdDictID = strconv.FormatUint(uint64(d.ID), 10)
+	pkt.Data.ZstdDict = ""
+	if embed {
+		pkt.Data.ZstdDict = base64.StdEncoding.EncodeToString(d.Data)
+	}
+}
+
+// wrapZstdDictError дополняет ошибку «неизвестный словарь» подсказкой.
+func wrapZstdDictError(err error) error {
+	if errors.Is(err, zstd.ErrUnknownDictionary) {
+		return fmt.Errorf("%w: load the sender's dictionaries with --dict-dir (or export with --embed-dict)", err)
+	}
+	return err
+}
+
+// LoadPacketZstdDict регистрирует словарь, встроенный в пакет, если он есть.
+// Нужен коду, распаковывающему Data в обход Parser.DecompressData.
+func LoadPacketZstdDict(pkt *packet.DataPacket) error {
+	if pkt.Data.ZstdDict == "" {
+		return nil
+	}
+	_, err := RegisterEmbeddedZstdDict(pkt.Data.ZstdDict)
+	return err
+}
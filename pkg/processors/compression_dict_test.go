@@ -0,0 +1,211 @@
+package processors
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/dict"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+// referenceRows — строки «справочника»: повторяющиеся префиксы и значения.
+func referenceRows(prefix string, n int) []string {
+	rows := make([]string, n)
+	for i := range rows {
+		rows[i] = fmt.Sprintf("%d|%s-%04d|Склад центральный, Москва|RUB|active|2026-01-%02d", i, prefix, i%97, i%28+1)
+	}
+	return rows
+}
+
+func TestTrainZstdDict(t *testing.T) {
+	rows := referenceRows("WH", 2000)
+	d, err := TrainZstdDict(rows, 16*1024)
+	if err != nil {
+		t.Fatalf("TrainZstdDict: %v", err)
+	}
+	if d.ID < zstdDictMinID {
+		t.Errorf("dictionary ID %d is in the reserved range", d.ID)
+	}
+	if len(d.Data) == 0 || len(d.Data) > 16*1024+1024 {
+		t.Errorf("unexpected dictionary size %d", len(d.Data))
+	}
+
+	again, err := TrainZstdDict(rows, 16*1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.ID != d.ID {
+		t.Errorf("ID is not deterministic: %d vs %d", again.ID, d.ID)
+	}
+
+	parsed, err := ParseZstdDict(d.Data)
+	if err != nil {
+		t.Fatalf("ParseZstdDict: %v", err)
+	}
+	if parsed.ID != d.ID {
+		t.Errorf("parsed ID = %d, want %d", parsed.ID, d.ID)
+	}
+
+	if _, err := TrainZstdDict(nil, 0); err == nil {
+		t.Error("expected error for empty samples")
+	}
+	if _, err := ParseZstdDict([]byte("not a dictionary")); err == nil {
+		t.Error("expected error for invalid dictionary")
+	}
+}
+
+func TestCompressDataForTdtpDict_RoundTrip(t *testing.T) {
+	d, err := TrainZstdDict(referenceRows("CUR", 3000), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	packetRows := referenceRows("CUR", 20) // маленький пакет — типичный справочник
+
+	compressed, stats, err := CompressDataForTdtpDict(packetRows, 3, d)
+	if err != nil {
+		t.Fatalf("CompressDataForTdtpDict: %v", err)
+	}
+	plain, plainStats, err := CompressDataForTdtp(packetRows, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.CompressedSize >= plainStats.CompressedSize {
+		t.Errorf("dictionary did not help: %d bytes with dict, %d without", stats.CompressedSize, plainStats.CompressedSize)
+	}
+
+	// Без словаря в реестре — понятная ошибка с подсказкой.
+	if _, err := DecompressDataForTdtpWithAlgo(compressed, AlgoZstd); err == nil || !strings.Contains(err.Error(), "--dict-dir") {
+		t.Fatalf("err = %v, want unknown dictionary hint", err)
+	}
+
+	if _, err := RegisterZstdDict(d.Data); err != nil {
+		t.Fatal(err)
+	}
+	got, err := DecompressDataForTdtpWithAlgo(compressed, AlgoZstd)
+	if err != nil {
+		t.Fatalf("decompress with registered dictionary: %v", err)
+	}
+	if !slices.Equal(got, packetRows) {
+		t.Error("round trip mismatch")
+	}
+
+	// Пакеты без словаря по-прежнему распаковываются.
+	if got, err := DecompressDataForTdtpWithAlgo(plain, AlgoZstd); err != nil || !slices.Equal(got, packetRows) {
+		t.Errorf("plain zstd after registering dictionary: err = %v", err)
+	}
+}
+
+func TestEmbeddedZstdDict_ParserRoundTrip(t *testing.T) {
+	d, err := TrainZstdDict(referenceRows("EMB", 1500), 8*1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows := referenceRows("EMB", 10)
+	compressed, _, err := CompressDataForTdtpDict(rows, 3, d)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pkt := packet.NewDataPacket(packet.TypeReference, "Warehouses")
+	pkt.Schema = packet.Schema{Fields: []packet.Field{{Name: "id", Type: "INTEGER"}}}
+	pkt.Data.Compression = AlgoZstd
+	pkt.Data.Rows = []packet.Row{{Value: compressed}}
+	SetPacketZstdDict(pkt, d, true)
+
+	xmlData, err := packet.NewGenerator().ToXML(pkt, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(xmlData), fmt.Sprintf(`dict="%d"`, d.ID)) || !strings.Contains(string(xmlData), "<CompressionDict>") {
+		t.Fatalf("dictionary not written to XML")
+	}
+
+	parsed, err := packet.NewParser().ParseBytesWithDecompression(xmlData,
+		func(_ context.Context, compressed, algo string) ([]string, error) {
+			return DecompressDataForTdtpWithAlgo(compressed, algo)
+		})
+	if err != nil {
+		t.Fatalf("parse with embedded dictionary: %v", err)
+	}
+	if len(parsed.Data.Rows) != len(rows) || parsed.Data.Rows[0].Value != rows[0] {
+		t.Errorf("unexpected rows after decompression: %d", len(parsed.Data.Rows))
+	}
+	if parsed.Data.ZstdDictID != "" || parsed.Data.ZstdDict != "" {
+		t.Error("dictionary attributes must be cleared after decompression")
+	}
+}
+
+func TestLoadZstdDictDir(t *testing.T) {
+	dir := t.TempDir()
+	d, err := TrainZstdDict(referenceRows("DIR", 1000), 8*1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "Currencies"+ZstdDictExt), d.Data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "readme.txt"), []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tables, err := LoadZstdDictDir(dir)
+	if err != nil {
+		t.Fatalf("LoadZstdDictDir: %v", err)
+	}
+	if !slices.Equal(tables, []string{"Currencies"}) {
+		t.Errorf("tables = %v", tables)
+	}
+	if got := TableZstdDict("currencies"); got == nil || got.ID != d.ID {
+		t.Errorf("TableZstdDict = %+v, want ID %d", got, d.ID)
+	}
+	if TableZstdDict("Unknown") != nil {
+		t.Error("unexpected dictionary for unknown table")
+	}
+
+	if _, err := LoadZstdDictDir(filepath.Join(dir, "missing")); err == nil {
+		t.Error("expected error for missing directory")
+	}
+}
+
+func TestRegisterZstdDict_IDConflict(t *testing.T) {
+	d, err := TrainZstdDict(referenceRows("CFL", 1500), 8*1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := RegisterZstdDict(d.Data); err != nil {
+		t.Fatal(err)
+	}
+	// Тот же словарь повторно — не ошибка.
+	if _, err := RegisterZstdDict(slices.Clone(d.Data)); err != nil {
+		t.Fatalf("re-registering the same dictionary: %v", err)
+	}
+
+	// Другой словарь с тем же ID не подменяет зарегистрированный.
+	other, err := dict.BuildZstdDict([][]byte{
+		[]byte(strings.Join(referenceRows("XYZ", 64), "\n")),
+		[]byte(strings.Join(referenceRows("QRS", 64), "\n")),
+	}, dict.Options{MaxDictSize: 8 * 1024, HashBytes: 6, ZstdDictID: d.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := RegisterEmbeddedZstdDict(base64.StdEncoding.EncodeToString(other)); !errors.Is(err, ErrZstdDictConflict) {
+		t.Fatalf("err = %v, want ErrZstdDictConflict", err)
+	}
+
+	rows := referenceRows("CFL", 10)
+	compressed, _, err := CompressDataForTdtpDict(rows, 3, d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := DecompressDataForTdtpWithAlgo(compressed, AlgoZstd); err != nil || !slices.Equal(got, rows) {
+		t.Errorf("registered dictionary was replaced: err = %v", err)
+	}
+}
@@ -3,9 +3,12 @@ package processors
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
 )
 
 // TestCompressionProcessor тестирует сжатие данных
@@ -565,3 +568,37 @@ func TestCompressDecompress_NewlineInField(t *testing.T) {
 		})
 	}
 }
+
+func TestPacketCompressionRegistered(t *testing.T) {
+	rows := make([][]string, 200)
+	for i := range rows {
+		rows[i] = []string{fmt.Sprint(i), "ACTIVE", "line1\nline2|x"}
+	}
+	schema := packet.Schema{Fields: []packet.Field{
+		{Name: "id", Type: "INTEGER", Key: true},
+		{Name: "status", Type: "TEXT"},
+		{Name: "note", Type: "TEXT"},
+	}}
+
+	gen := packet.NewGenerator()
+	gen.SetSkipSpecialValues(true)
+	gen.EnableCompression()
+	packets, err := gen.GenerateReference("t", schema, rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkt := packets[0]
+	if pkt.Data.Compression != AlgoZstd {
+		t.Fatalf("compression = %q, want zstd", pkt.Data.Compression)
+	}
+	if err := ValidateChecksum([]byte(pkt.Data.Rows[0].Value), pkt.Data.Checksum); err != nil {
+		t.Errorf("checksum format differs from ComputeChecksum: %v", err)
+	}
+
+	if err := packet.DecompressPacketData(context.Background(), pkt); err != nil {
+		t.Fatal(err)
+	}
+	if got := pkt.GetRows(); !reflect.DeepEqual(got, rows) {
+		t.Errorf("rows differ after round trip: %q", got[:2])
+	}
+}
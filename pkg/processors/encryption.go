@@ -31,6 +31,7 @@ type FileEncryptor struct {
 	serverSecret string
 	packageUUID  string
 	pipelineName string
+	escrow       *tdtpcrypto.EscrowKey // депонирование ключа (SetEscrow); nil — без депонирования
 }
 
 // MercuryBinder — интерфейс для BindKey, позволяет подменять в тестах и dev-режиме.
@@ -49,6 +50,13 @@ func NewFileEncryptor(client MercuryBinder, serverSecret, packageUUID, pipelineN
 	}
 }
 
+// SetEscrow включает депонирование: ключ пакета дополнительно оборачивается
+// открытым ключом депозитария и кладётся в Header (только v1.5 —
+// у блоба v1.3 нет открытого заголовка для обёрнутого ключа).
+func (f *FileEncryptor) SetEscrow(escrow *tdtpcrypto.EscrowKey) {
+	f.escrow = escrow
+}
+
 // bindAndDecodeKey получает ключ от Mercury и верифицирует HMAC — общий для
 // v1.3 (Encrypt, whole-blob) и v1.5 (EncryptSectionsV15) первый шаг; разница
 // между форматами начинается только после того как raw key на руках.
@@ -94,6 +102,9 @@ func (f *FileEncryptor) bindAndDecodeKey(ctx context.Context) ([]byte, string, e
 //   - errCode: mercury.ErrCode* — код для error-пакета при сбое
 //   - error: детальная ошибка
 func (f *FileEncryptor) Encrypt(ctx context.Context, plaintext []byte) (*EncryptionResult, string, error) {
+	if f.escrow != nil {
+		return nil, mercury.ErrCodeMercuryError, fmt.Errorf("key escrow requires v1.5 section-level encryption: the v1.3 blob has no header to carry the escrowed key")
+	}
 	key, errCode, err := f.bindAndDecodeKey(ctx)
 	if err != nil {
 		return nil, errCode, err
@@ -127,6 +138,11 @@ func (f *FileEncryptor) EncryptSectionsV15(ctx context.Context, pkt *packet.Data
 	if err := packet.EncryptSections(pkt, key); err != nil {
 		return mercury.ErrCodeMercuryError, fmt.Errorf("encrypt sections: %w", err)
 	}
+	if f.escrow != nil {
+		if err := packet.EscrowPacketKey(pkt, key, f.escrow); err != nil {
+			return mercury.ErrCodeMercuryError, err
+		}
+	}
 	return "", nil
 }
 
@@ -1,10 +1,16 @@
 package processors
 
 import (
+	"bytes"
 	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
 	"testing"
 
 	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+	tdtpcrypto "github.com/ruslano69/tdtp-framework/pkg/crypto"
 	"github.com/ruslano69/tdtp-framework/pkg/mercury"
 )
 
@@ -112,3 +118,41 @@ func TestEncryptSectionsV15_MissingServerSecret(t *testing.T) {
 		t.Fatal("expected error when serverSecret is empty")
 	}
 }
+
+func TestEncryptSectionsV15_Escrow(t *testing.T) {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubDER, _ := x509.MarshalPKIXPublicKey(&priv.PublicKey)
+	escrow, err := tdtpcrypto.ParseEscrowKey(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	officer, err := tdtpcrypto.ParseEscrowPrivateKey(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(priv)}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pkt := makeProcEncryptTestPacket(t)
+	binder := &mockBinder{keyB64: "AQIDBAUGBwgJCgsMDQ4PEBESExQVFhcYGRobHB0eHyA=", mode: "dev"}
+	fe := NewFileEncryptor(binder, "dev-mode", pkt.Header.MessageID, "test-pipeline")
+	fe.SetEscrow(escrow)
+
+	if _, err := fe.EncryptSectionsV15(context.Background(), pkt); err != nil {
+		t.Fatalf("EncryptSectionsV15: %v", err)
+	}
+	got, err := packet.RecoverPacketKey(pkt, officer)
+	if err != nil {
+		t.Fatalf("RecoverPacketKey: %v", err)
+	}
+	want, _ := mercury.DecodeKey(binder.keyB64)
+	if !bytes.Equal(got, want) {
+		t.Error("escrowed key differs from the Mercury key")
+	}
+
+	// v1.3 blob has no header for the escrowed key
+	if _, _, err := fe.Encrypt(context.Background(), []byte("<DataPacket/>")); err == nil {
+		t.Error("Encrypt (v1.3) with escrow: expected error")
+	}
+}
@@ -31,6 +31,22 @@ func NewFactory() *Factory {
 		return NewFieldValidatorFromConfig(params)
 	})
 
+	f.Register("field_pseudonymizer", func(params map[string]any) (Processor, error) {
+		return NewFieldPseudonymizerFromConfig(params)
+	})
+
+	f.Register("field_tokenizer", func(params map[string]any) (Processor, error) {
+		return NewFieldTokenizerFromConfig(params)
+	})
+
+	f.Register("value_mapper", func(params map[string]any) (Processor, error) {
+		return NewFieldValueMapperFromConfig(params)
+	})
+
+	f.Register("surrogate_key", func(params map[string]any) (Processor, error) {
+		return NewSurrogateKeyAssignerFromConfig(params)
+	})
+
 	return f
 }
 
@@ -0,0 +1,130 @@
+package processors
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+	"github.com/ruslano69/tdtp-framework/pkg/core/schema"
+)
+
+// FieldPseudonymizer заменяет значения полей детерминированными псевдонимами
+// HMAC-SHA256(secret, value).
+//
+// В отличие от FieldMasker одно и то же исходное значение всегда даёт один и
+// тот же псевдоним — в том числе в разных таблицах. Поэтому псевдонимизация
+// первичного ключа и ссылающихся на него внешних ключей сохраняет связи между
+// таблицами выборки. Без секрета псевдонимы нельзя сопоставить с исходными
+// значениями перебором.
+//
+// INTEGER-поля получают положительное целое (до 2^53, чтобы значение
+// сохранялось в REAL/JSON без потерь), остальные — hex-токен, усечённый до
+// Length поля, если она задана.
+type FieldPseudonymizer struct {
+	name   string
+	secret []byte
+	fields map[string]bool
+}
+
+// NewFieldPseudonymizer создает псевдонимизатор для указанных полей.
+func NewFieldPseudonymizer(secret []byte, fields []string) (*FieldPseudonymizer, error) {
+	if len(secret) == 0 {
+		return nil, fmt.Errorf("pseudonymizer secret is required")
+	}
+	set := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		set[f] = true
+	}
+	return &FieldPseudonymizer{name: "field_pseudonymizer", secret: secret, fields: set}, nil
+}
+
+// NewFieldPseudonymizerFromConfig создает псевдонимизатор из конфигурации.
+//
+//	params:
+//	  fields: [id, customer_id, email]
+//	  secret: "..."          # или
+//	  secret_env: TDTP_PSEUDO_SECRET
+func NewFieldPseudonymizerFromConfig(params map[string]any) (*FieldPseudonymizer, error) {
+	rawFields, ok := params["fields"].([]any)
+	if !ok {
+		return nil, fmt.Errorf("missing or invalid 'fields' parameter")
+	}
+	fields := make([]string, 0, len(rawFields))
+	for _, f := range rawFields {
+		fields = append(fields, fmt.Sprintf("%v", f))
+	}
+
+	secret, _ := params["secret"].(string)
+	if env, ok := params["secret_env"].(string); ok && env != "" {
+		secret = os.Getenv(env)
+		if secret == "" {
+			return nil, fmt.Errorf("environment variable %s is empty", env)
+		}
+	}
+	return NewFieldPseudonymizer([]byte(secret), fields)
+}
+
+// Name возвращает имя процессора
+func (p *FieldPseudonymizer) Name() string {
+	return p.name
+}
+
+// Process реализует интерфейс PreProcessor
+func (p *FieldPseudonymizer) Process(ctx context.Context, data [][]string, sch packet.Schema) ([][]string, error) {
+	type column struct {
+		index   int
+		integer bool
+		length  int
+	}
+	var columns []column
+	for i, field := range sch.Fields {
+		if !p.fields[field.Name] {
+			continue
+		}
+		columns = append(columns, column{
+			index:   i,
+			integer: schema.NormalizeType(schema.DataType(field.Type)) == schema.TypeInteger,
+			length:  field.Length,
+		})
+	}
+	if len(columns) == 0 {
+		return data, nil
+	}
+
+	result := make([][]string, len(data))
+	for i, row := range data {
+		newRow := make([]string, len(row))
+		copy(newRow, row)
+		for _, col := range columns {
+			if col.index >= len(newRow) || newRow[col.index] == "" {
+				continue // пустое значение/NULL остаётся как есть
+			}
+			newRow[col.index] = p.pseudonym(newRow[col.index], col.integer, col.length)
+		}
+		result[i] = newRow
+	}
+	return result, nil
+}
+
+func (p *FieldPseudonymizer) pseudonym(value string, integer bool, length int) string {
+	mac := hmac.New(sha256.New, p.secret)
+	mac.Write([]byte(strings.TrimSpace(value)))
+	sum := mac.Sum(nil)
+
+	if integer {
+		n := binary.BigEndian.Uint64(sum[:8]) & (1<<53 - 1)
+		return strconv.FormatUint(n+1, 10)
+	}
+	token := hex.EncodeToString(sum)
+	if length > 0 && length < len(token) {
+		token = token[:length]
+	}
+	return token
+}
@@ -0,0 +1,72 @@
+package processors
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+func TestFieldPseudonymizer_ConsistentAcrossTables(t *testing.T) {
+	p, err := NewFieldPseudonymizerFromConfig(map[string]any{
+		"fields": []any{"id", "customer_id", "email"},
+		"secret": "s3cret",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create pseudonymizer: %v", err)
+	}
+
+	customers := packet.Schema{Fields: []packet.Field{
+		{Name: "id", Type: "INTEGER", Key: true},
+		{Name: "email", Type: "TEXT", Length: 16},
+	}}
+	orders := packet.Schema{Fields: []packet.Field{
+		{Name: "order_id", Type: "INTEGER", Key: true},
+		{Name: "customer_id", Type: "INTEGER"},
+	}}
+
+	c, err := p.Process(context.Background(), [][]string{{"42", "john@example.com"}, {"7", ""}}, customers)
+	if err != nil {
+		t.Fatalf("Process customers: %v", err)
+	}
+	o, err := p.Process(context.Background(), [][]string{{"1", "42"}}, orders)
+	if err != nil {
+		t.Fatalf("Process orders: %v", err)
+	}
+
+	if c[0][0] == "42" {
+		t.Error("id was not pseudonymized")
+	}
+	if _, err := strconv.ParseInt(c[0][0], 10, 64); err != nil {
+		t.Errorf("INTEGER pseudonym %q is not an integer", c[0][0])
+	}
+	if o[0][1] != c[0][0] {
+		t.Errorf("FK pseudonym %q != PK pseudonym %q", o[0][1], c[0][0])
+	}
+	if o[0][0] != "1" {
+		t.Errorf("order_id must stay unchanged, got %q", o[0][0])
+	}
+	if len(c[0][1]) != 16 || c[0][1] == "john@example.com" {
+		t.Errorf("email pseudonym = %q, want 16-char token", c[0][1])
+	}
+	if c[1][1] != "" {
+		t.Errorf("empty value must stay empty, got %q", c[1][1])
+	}
+}
+
+func TestFieldPseudonymizer_SecretChangesOutput(t *testing.T) {
+	sch := packet.Schema{Fields: []packet.Field{{Name: "id", Type: "INTEGER"}}}
+	a, _ := NewFieldPseudonymizer([]byte("a"), []string{"id"})
+	b, _ := NewFieldPseudonymizer([]byte("b"), []string{"id"})
+
+	ra, _ := a.Process(context.Background(), [][]string{{"1"}}, sch)
+	rb, _ := b.Process(context.Background(), [][]string{{"1"}}, sch)
+	if ra[0][0] == rb[0][0] {
+		t.Error("different secrets produced the same pseudonym")
+	}
+
+	if _, err := NewFieldPseudonymizer(nil, []string{"id"}); err == nil {
+		t.Error("expected error for empty secret")
+	}
+}
@@ -0,0 +1,214 @@
+package processors
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+// TokenProvider — внешний сервис токенизации (vault для PAN и т.п.).
+//
+// Tokenize получает пачку значений одного поля и возвращает токены в том же
+// порядке. Одинаковые значения должны давать одинаковые токены — иначе
+// кеш FieldTokenizer и связи между таблицами потеряют смысл.
+type TokenProvider interface {
+	Tokenize(ctx context.Context, field string, values []string) ([]string, error)
+}
+
+// Значения по умолчанию для FieldTokenizer.
+const (
+	DefaultTokenizeBatchSize = 500
+	DefaultTokenCacheSize    = 100_000
+)
+
+// FieldTokenizer заменяет значения полей токенами внешнего TokenProvider.
+//
+// В отличие от FieldMasker и FieldPseudonymizer токен выдаёт сервис,
+// который хранит соответствие «токен → значение» (детокенизация возможна
+// только через него) — обязательное требование для карточных данных (PCI DSS).
+//
+// Провайдер вызывается пачками по BatchSize уникальных значений, ещё не
+// попавших в кеш. Кеш ограничен CacheSize записей; при переполнении он
+// очищается целиком — токены детерминированы, поэтому это влияет только на
+// число запросов.
+type FieldTokenizer struct {
+	name      string
+	fields    map[string]bool
+	provider  TokenProvider
+	batchSize int
+	cacheSize int
+
+	mu    sync.Mutex
+	cache map[tokenKey]string
+}
+
+type tokenKey struct{ field, value string }
+
+// NewFieldTokenizer создаёт токенизатор указанных полей.
+// batchSize/cacheSize <= 0 — значения по умолчанию.
+func NewFieldTokenizer(provider TokenProvider, fields []string, batchSize, cacheSize int) (*FieldTokenizer, error) {
+	if provider == nil {
+		return nil, fmt.Errorf("token provider is required")
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("tokenizer requires at least one field")
+	}
+	if batchSize <= 0 {
+		batchSize = DefaultTokenizeBatchSize
+	}
+	if cacheSize <= 0 {
+		cacheSize = DefaultTokenCacheSize
+	}
+	set := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		set[f] = true
+	}
+	return &FieldTokenizer{
+		name:      "field_tokenizer",
+		fields:    set,
+		provider:  provider,
+		batchSize: batchSize,
+		cacheSize: cacheSize,
+		cache:     make(map[tokenKey]string),
+	}, nil
+}
+
+// NewFieldTokenizerFromConfig создаёт токенизатор с HTTP-провайдером.
+//
+//	params:
+//	  fields: [card_number, pan]
+//	  url: https://tokenizer.internal
+//	  auth_token_env: TOKENIZER_TOKEN   # Authorization: Bearer ...
+//	  timeout_ms: 5000
+//	  batch_size: 500
+//	  cache_size: 100000
+func NewFieldTokenizerFromConfig(params map[string]any) (*FieldTokenizer, error) {
+	rawFields, ok := params["fields"].([]any)
+	if !ok {
+		return nil, fmt.Errorf("missing or invalid 'fields' parameter")
+	}
+	fields := make([]string, 0, len(rawFields))
+	for _, f := range rawFields {
+		fields = append(fields, fmt.Sprintf("%v", f))
+	}
+
+	url, _ := params["url"].(string)
+	if url == "" {
+		return nil, fmt.Errorf("missing 'url' parameter")
+	}
+	var authToken string
+	if env, ok := params["auth_token_env"].(string); ok && env != "" {
+		authToken = os.Getenv(env)
+		if authToken == "" {
+			return nil, fmt.Errorf("environment variable %s is empty", env)
+		}
+	}
+	provider := NewHTTPTokenizer(url, authToken, intParam(params, "timeout_ms"))
+	return NewFieldTokenizer(provider, fields, intParam(params, "batch_size"), intParam(params, "cache_size"))
+}
+
+// intParam читает целый параметр YAML (int или float64 после JSON).
+func intParam(params map[string]any, name string) int {
+	switch v := params[name].(type) {
+	case int:
+		return v
+	case float64:
+		return int(v)
+	}
+	return 0
+}
+
+// Name возвращает имя процессора
+func (t *FieldTokenizer) Name() string {
+	return t.name
+}
+
+// Process реализует интерфейс PreProcessor
+func (t *FieldTokenizer) Process(ctx context.Context, data [][]string, schema packet.Schema) ([][]string, error) {
+	var columns []int
+	for i, field := range schema.Fields {
+		if t.fields[field.Name] {
+			columns = append(columns, i)
+		}
+	}
+	if len(columns) == 0 {
+		return data, nil
+	}
+
+	tokens := make(map[tokenKey]string)
+	for _, col := range columns {
+		if err := t.resolve(ctx, schema.Fields[col].Name, data, col, tokens); err != nil {
+			return nil, err
+		}
+	}
+
+	result := make([][]string, len(data))
+	for i, row := range data {
+		newRow := make([]string, len(row))
+		copy(newRow, row)
+		for _, col := range columns {
+			if col < len(newRow) && newRow[col] != "" { // пустое значение/NULL остаётся как есть
+				newRow[col] = tokens[tokenKey{schema.Fields[col].Name, newRow[col]}]
+			}
+		}
+		result[i] = newRow
+	}
+	return result, nil
+}
+
+// resolve заполняет tokens для всех значений колонки: из кеша или пачками
+// у провайдера.
+func (t *FieldTokenizer) resolve(ctx context.Context, field string, data [][]string, col int, tokens map[tokenKey]string) error {
+	var missing []string
+	t.mu.Lock()
+	for _, row := range data {
+		if col >= len(row) || row[col] == "" {
+			continue
+		}
+		key := tokenKey{field, row[col]}
+		if _, ok := tokens[key]; ok {
+			continue
+		}
+		if token, ok := t.cache[key]; ok {
+			tokens[key] = token
+			continue
+		}
+		tokens[key] = ""
+		missing = append(missing, row[col])
+	}
+	t.mu.Unlock()
+
+	for start := 0; start < len(missing); start += t.batchSize {
+		batch := missing[start:min(start+t.batchSize, len(missing))]
+		got, err := t.provider.Tokenize(ctx, field, batch)
+		if err != nil {
+			return fmt.Errorf("tokenize field %s: %w", field, err)
+		}
+		if len(got) != len(batch) {
+			return fmt.Errorf("tokenize field %s: provider returned %d tokens for %d values", field, len(got), len(batch))
+		}
+		for i, v := range batch {
+			if got[i] == "" {
+				return fmt.Errorf("tokenize field %s: empty token", field)
+			}
+			tokens[tokenKey{field, v}] = got[i]
+		}
+		t.store(field, batch, got)
+	}
+	return nil
+}
+
+// store кладёт токены в кеш; при переполнении кеш очищается целиком.
+func (t *FieldTokenizer) store(field string, values, tokens []string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.cache)+len(values) > t.cacheSize {
+		clear(t.cache)
+	}
+	for i, v := range values {
+		t.cache[tokenKey{field, v}] = tokens[i]
+	}
+}
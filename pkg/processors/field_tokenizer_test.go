@@ -0,0 +1,106 @@
+package processors
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+func TestFieldTokenizer_HTTPBatchAndCache(t *testing.T) {
+	var calls, valuesSent int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/tokenize" || r.Header.Get("Authorization") != "Bearer t0k" {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		var req TokenizeRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		calls++
+		valuesSent += len(req.Values)
+		resp := TokenizeResponse{}
+		for _, v := range req.Values {
+			resp.Tokens = append(resp.Tokens, "tok_"+req.Field+"_"+v[len(v)-4:])
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer srv.Close()
+
+	t.Setenv("TDTP_TEST_TOKENIZER", "t0k")
+	tok, err := NewFieldTokenizerFromConfig(map[string]any{
+		"fields":         []any{"pan"},
+		"url":            srv.URL,
+		"auth_token_env": "TDTP_TEST_TOKENIZER",
+		"batch_size":     2,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create tokenizer: %v", err)
+	}
+
+	sch := packet.Schema{Fields: []packet.Field{
+		{Name: "id", Type: "INTEGER", Key: true},
+		{Name: "pan", Type: "TEXT"},
+	}}
+	data := [][]string{
+		{"1", "4111111111111111"},
+		{"2", "5500000000000004"},
+		{"3", "4111111111111111"},
+		{"4", ""},
+		{"5", "340000000000009"},
+	}
+	out, err := tok.Process(context.Background(), data, sch)
+	if err != nil {
+		t.Fatalf("Process: %v", err)
+	}
+	if out[0][1] != "tok_pan_1111" || out[2][1] != out[0][1] || out[4][1] != "tok_pan_0009" {
+		t.Errorf("unexpected tokens: %v", out)
+	}
+	if out[3][1] != "" || out[0][0] != "1" || data[0][1] != "4111111111111111" {
+		t.Errorf("empty values, other fields and input must stay unchanged: %v", out)
+	}
+	if calls != 2 || valuesSent != 3 { // 3 уникальных значения пачками по 2
+		t.Errorf("calls = %d, values sent = %d", calls, valuesSent)
+	}
+
+	// Повторная обработка берёт токены из кеша.
+	if _, err := tok.Process(context.Background(), data, sch); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 {
+		t.Errorf("cached values were requested again (%d calls)", calls)
+	}
+}
+
+type shortProvider struct{}
+
+func (shortProvider) Tokenize(context.Context, string, []string) ([]string, error) {
+	return []string{"x"}, nil
+}
+
+func TestFieldTokenizer_ProviderErrors(t *testing.T) {
+	tok, err := NewFieldTokenizer(shortProvider{}, []string{"pan"}, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sch := packet.Schema{Fields: []packet.Field{{Name: "pan", Type: "TEXT"}}}
+	if _, err := tok.Process(context.Background(), [][]string{{"a"}, {"b"}}, sch); err == nil {
+		t.Error("expected error for token count mismatch")
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "vault sealed", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+	if _, err := NewHTTPTokenizer(srv.URL, "", 0).Tokenize(context.Background(), "pan", []string{"a"}); err == nil {
+		t.Error("expected error for HTTP 503")
+	}
+	if _, err := NewFieldTokenizerFromConfig(map[string]any{"fields": []any{"pan"}}); err == nil {
+		t.Error("expected error without url")
+	}
+}
@@ -0,0 +1,74 @@
+package processors
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+	"github.com/ruslano69/tdtp-framework/pkg/keymap"
+)
+
+// sequenceKeys — keymap.Resolver в памяти: суррогаты по порядку появления.
+type sequenceKeys map[string]int64
+
+func (s sequenceKeys) Lookup(_ context.Context, _, _ string, keys []string) (map[string]int64, error) {
+	out := make(map[string]int64)
+	for _, k := range keys {
+		if id, ok := s[k]; ok {
+			out[k] = id
+		}
+	}
+	return out, nil
+}
+
+func (s sequenceKeys) Resolve(ctx context.Context, entity, system string, keys []string) (map[string]int64, error) {
+	for _, k := range keys {
+		if _, ok := s[k]; !ok {
+			s[k] = int64(len(s) + 100)
+		}
+	}
+	return s.Lookup(ctx, entity, system, keys)
+}
+
+func TestSurrogateKeyAssigner(t *testing.T) {
+	p, err := NewSurrogateKeyAssigner(sequenceKeys{}, keymap.Mapping{
+		System: "crm", Entity: "customer", Fields: []string{"customer_id"}, Target: "customer_sk",
+	}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sch := packet.Schema{Fields: []packet.Field{
+		{Name: "customer_id", Type: "TEXT", SpecialValues: &packet.SpecialValues{Null: &packet.MarkerValue{Marker: "[NULL]"}}},
+		{Name: "customer_sk", Type: "INTEGER"},
+	}}
+	data := [][]string{{"A", ""}, {"B", ""}, {"A", ""}, {"[NULL]", "5"}}
+	got, err := p.Process(context.Background(), data, sch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{{"A", "100"}, {"B", "101"}, {"A", "100"}, {"[NULL]", ""}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if data[0][1] != "" {
+		t.Error("input rows must not be modified")
+	}
+
+	if _, err := p.Process(context.Background(), data, packet.Schema{Fields: sch.Fields[:1]}); err == nil {
+		t.Error("expected error for missing target field")
+	}
+}
+
+func TestSurrogateKeyAssignerFromConfig_Invalid(t *testing.T) {
+	for _, params := range []map[string]any{
+		{"system": "crm", "entity": "customer", "fields": []any{"id"}, "target": "sk"},
+		{"store": map[string]any{"type": "sqlite", "dsn": "k.db"}, "entity": "customer", "fields": []any{"id"}, "target": "sk"},
+		{"store": map[string]any{"type": "sqlite", "dsn": "k.db"}, "system": "crm", "entity": "customer", "target": "sk"},
+	} {
+		if _, err := NewFactory().Create(Config{Type: "surrogate_key", Params: params}); err == nil {
+			t.Errorf("expected error for %v", params)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package processors
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// HTTPTokenizer — эталонный TokenProvider поверх HTTP API токенизации.
+//
+//	POST {baseURL}/tokenize
+//	{"field": "card_number", "values": ["4111111111111111", ...]}
+//	→ 200 {"tokens": ["tok_9f2c...", ...]}
+//
+// Токены возвращаются в порядке значений. Любой другой статус — ошибка
+// (тело ответа попадает в текст ошибки).
+type HTTPTokenizer struct {
+	baseURL    string
+	authToken  string
+	httpClient *http.Client
+}
+
+// TokenizeRequest — тело запроса POST /tokenize.
+type TokenizeRequest struct {
+	Field  string   `json:"field"`
+	Values []string `json:"values"`
+}
+
+// TokenizeResponse — ответ POST /tokenize.
+type TokenizeResponse struct {
+	Tokens []string `json:"tokens"`
+}
+
+// NewHTTPTokenizer создаёт клиент. authToken (опционально) уходит в
+// Authorization: Bearer; timeoutMs <= 0 — 5 секунд.
+func NewHTTPTokenizer(baseURL, authToken string, timeoutMs int) *HTTPTokenizer {
+	if timeoutMs <= 0 {
+		timeoutMs = 5000
+	}
+	return &HTTPTokenizer{
+		baseURL:   strings.TrimRight(baseURL, "/"),
+		authToken: authToken,
+		httpClient: &http.Client{
+			Timeout: time.Duration(timeoutMs) * time.Millisecond,
+		},
+	}
+}
+
+// Tokenize реализует TokenProvider.
+func (c *HTTPTokenizer) Tokenize(ctx context.Context, field string, values []string) ([]string, error) {
+	data, err := json.Marshal(TokenizeRequest{Field: field, Values: values})
+	if err != nil {
+		return nil, fmt.Errorf("marshal tokenize request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/tokenize", bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.authToken != "" {
+		req.Header.Set("Authorization", "Bearer "+c.authToken)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("tokenizer unavailable: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("tokenizer error: HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var result TokenizeResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("decode tokenize response: %w", err)
+	}
+	return result.Tokens, nil
+}
@@ -0,0 +1,137 @@
+package processors
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+type lookupTables map[string]*packet.DataPacket
+
+func (l lookupTables) ExportTable(_ context.Context, table string) ([]*packet.DataPacket, error) {
+	return []*packet.DataPacket{l[table]}, nil
+}
+
+func TestFieldValueMapper_InlineAndPolicies(t *testing.T) {
+	mapper, err := NewFieldValueMapperFromConfig(map[string]any{
+		"unmapped": "null",
+		"fields": map[string]any{
+			"status": map[string]any{
+				"values":   map[any]any{10: "ACTIVE", 20: "BLOCKED"},
+				"unmapped": "pass",
+			},
+			"kind": map[string]any{
+				"values": map[string]any{"a": "Alpha"},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sch := packet.Schema{Fields: []packet.Field{
+		{Name: "id", Type: "INTEGER"},
+		{Name: "status", Type: "TEXT"},
+		{Name: "kind", Type: "TEXT", SpecialValues: &packet.SpecialValues{Null: &packet.MarkerValue{Marker: "[NULL]"}}},
+	}}
+	data := [][]string{
+		{"1", "10", "a"},
+		{"2", "99", "b"},
+		{"3", "", "[NULL]"},
+		{"4", "99", "b"},
+	}
+	got, err := mapper.Process(context.Background(), data, sch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"1", "ACTIVE", "Alpha"},
+		{"2", "99", "[NULL]"},
+		{"3", "", "[NULL]"},
+		{"4", "99", "[NULL]"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if data[0][1] != "10" {
+		t.Error("input rows must not be modified")
+	}
+
+	report := mapper.UnmappedValues()
+	wantReport := map[string]map[string]int64{"status": {"99": 2}, "kind": {"b": 2}}
+	if !reflect.DeepEqual(report, wantReport) {
+		t.Errorf("report = %v, want %v", report, wantReport)
+	}
+	if chainReport := NewChain(mapper).UnmappedValues(); !reflect.DeepEqual(chainReport, wantReport) {
+		t.Errorf("chain report = %v", chainReport)
+	}
+}
+
+func TestFieldValueMapper_Fail(t *testing.T) {
+	mapper, err := NewFieldValueMapper(map[string]ValueMapping{
+		"status": {Values: map[string]string{"10": "ACTIVE"}},
+	}, UnmappedFail)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sch := packet.Schema{Fields: []packet.Field{{Name: "status", Type: "TEXT"}}}
+	_, err = mapper.Process(context.Background(), [][]string{{"10"}, {"30"}, {"20"}}, sch)
+	if err == nil || !strings.Contains(err.Error(), "status: 20, 30") {
+		t.Fatalf("expected unmapped error, got %v", err)
+	}
+}
+
+func TestFieldValueMapper_LookupTable(t *testing.T) {
+	regions := packet.NewDataPacket(packet.TypeReference, "regions")
+	regions.Schema = packet.Schema{Fields: []packet.Field{
+		{Name: "id", Type: "INTEGER", Key: true},
+		{Name: "name", Type: "TEXT"},
+	}}
+	regions.SetRows([][]string{{"1", "North"}, {"2", "South"}})
+
+	mapper, err := NewFieldValueMapper(map[string]ValueMapping{
+		"region": {Table: "regions", Key: "id", Value: "name", Reverse: true},
+	}, UnmappedFail)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mapper.WithLookupSource(lookupTables{"regions": regions})
+
+	sch := packet.Schema{Fields: []packet.Field{{Name: "region", Type: "TEXT"}}}
+	got, err := mapper.Process(context.Background(), [][]string{{"South"}, {"North"}}, sch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := [][]string{{"2"}, {"1"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestFieldValueMapper_ConfigErrors(t *testing.T) {
+	cases := map[string]map[string]any{
+		"no fields":      {},
+		"bad policy":     {"unmapped": "drop", "fields": map[string]any{"s": map[string]any{"values": map[string]any{"1": "a"}}}},
+		"empty mapping":  {"fields": map[string]any{"s": map[string]any{}}},
+		"table no key":   {"fields": map[string]any{"s": map[string]any{"table": "t", "value": "name"}}},
+		"lookup no dsn":  {"lookup": map[string]any{"type": "postgres"}, "fields": map[string]any{"s": map[string]any{"table": "t", "key": "id", "value": "name"}}},
+		"values + table": {"fields": map[string]any{"s": map[string]any{"table": "t", "key": "id", "value": "name", "values": map[string]any{"1": "a"}}}},
+	}
+	for name, params := range cases {
+		if _, err := NewFieldValueMapperFromConfig(params); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+
+	// Таблица без источника — ошибка при первой обработке
+	mapper, err := NewFieldValueMapper(map[string]ValueMapping{"s": {Table: "t", Key: "id", Value: "name"}}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sch := packet.Schema{Fields: []packet.Field{{Name: "s", Type: "TEXT"}}}
+	if _, err := mapper.Process(context.Background(), [][]string{{"1"}}, sch); err == nil {
+		t.Error("expected error for table mapping without lookup source")
+	}
+}
@@ -0,0 +1,161 @@
+// Package progress — структурированные события хода выполнения для CI и
+// обёрток (tdtpcli --progress-format json): по одному JSON-объекту на
+// строку (NDJSON), без разбора человекочитаемого лога.
+//
+// Reporter передаётся через context (как commands.OpMetrics): команды и
+// pkg/etl вызывают Emit/Begin, не меняя сигнатур; без Reporter в ctx всё —
+// no-op.
+package progress
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// Типы событий.
+const (
+	RunStarted    = "run_started"
+	RunCompleted  = "run_completed"
+	RunFailed     = "run_failed"
+	StepStarted   = "step_started"
+	StepCompleted = "step_completed"
+	StepFailed    = "step_failed"
+	Progress      = "progress" // промежуточный: источник загружен, часть записана
+)
+
+// Event — одно событие. Формат стабилен: его читают внешние консьюмеры.
+type Event struct {
+	Time       time.Time `json:"ts"`
+	Event      string    `json:"event"`
+	Command    string    `json:"command,omitempty"` // pipeline, import
+	Name       string    `json:"name,omitempty"`    // пайплайн / таблица / файл
+	Step       string    `json:"step,omitempty"`
+	Item       string    `json:"item,omitempty"` // источник, часть
+	Rows       int64     `json:"rows,omitempty"`
+	Done       int       `json:"done,omitempty"`  // выполнено единиц (источников, частей)
+	Total      int       `json:"total,omitempty"` // всего единиц
+	Percent    float64   `json:"percent,omitempty"`
+	DurationMs int64     `json:"duration_ms,omitempty"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// Reporter принимает события.
+type Reporter interface {
+	Emit(Event)
+}
+
+// JSONReporter пишет события в w по одному на строку. Безопасен для
+// параллельных вызовов (источники пайплайна грузятся в горутинах).
+type JSONReporter struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// NewJSONReporter создаёт NDJSON-репортёр.
+func NewJSONReporter(w io.Writer) *JSONReporter {
+	return &JSONReporter{enc: json.NewEncoder(w)}
+}
+
+// Emit сериализует событие; ошибка записи игнорируется — прогресс не
+// должен ронять сам запуск.
+func (r *JSONReporter) Emit(e Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_ = r.enc.Encode(e)
+}
+
+type reporterKey struct{}
+
+// WithReporter прикрепляет Reporter к ctx.
+func WithReporter(ctx context.Context, r Reporter) context.Context {
+	return context.WithValue(ctx, reporterKey{}, r)
+}
+
+// Emit отправляет событие Reporter'у из ctx (no-op без него). Time и
+// Percent (из Done/Total) заполняются, если не заданы.
+func Emit(ctx context.Context, e Event) {
+	r, ok := ctx.Value(reporterKey{}).(Reporter)
+	if !ok {
+		return
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+	if e.Percent == 0 && e.Total > 0 {
+		e.Percent = float64(e.Done) * 100 / float64(e.Total)
+	}
+	r.Emit(e)
+}
+
+// Run — выполняющаяся команда: Begin отправляет run_started, End —
+// run_completed или run_failed.
+type Run struct {
+	ctx     context.Context
+	command string
+	name    string
+	start   time.Time
+}
+
+// Begin отправляет run_started.
+func Begin(ctx context.Context, command, name string) *Run {
+	Emit(ctx, Event{Event: RunStarted, Command: command, Name: name})
+	return &Run{ctx: ctx, command: command, name: name, start: time.Now()}
+}
+
+// SetName уточняет имя запуска (например, имя пайплайна из YAML).
+func (r *Run) SetName(name string) {
+	r.name = name
+}
+
+// End отправляет итог запуска.
+func (r *Run) End(err error, rows int64) {
+	e := Event{Event: RunCompleted, Command: r.command, Name: r.name, Rows: rows,
+		DurationMs: time.Since(r.start).Milliseconds()}
+	if err != nil {
+		e.Event, e.Error = RunFailed, err.Error()
+	}
+	Emit(r.ctx, e)
+}
+
+// Step — выполняющийся шаг.
+type Step struct {
+	ctx   context.Context
+	name  string
+	start time.Time
+}
+
+// StartStep отправляет step_started.
+func StartStep(ctx context.Context, name string) *Step {
+	Emit(ctx, Event{Event: StepStarted, Step: name})
+	return &Step{ctx: ctx, name: name, start: time.Now()}
+}
+
+// End отправляет step_completed или step_failed.
+func (s *Step) End(err error, rows int64) {
+	e := Event{Event: StepCompleted, Step: s.name, Rows: rows, DurationMs: time.Since(s.start).Milliseconds()}
+	if err != nil {
+		e.Event, e.Error = StepFailed, err.Error()
+	}
+	Emit(s.ctx, e)
+}
+
+// Tee прикрепляет r к ctx в дополнение к уже прикреплённому Reporter —
+// события получают оба (например, --progress-format json и реестр
+// операций).
+func Tee(ctx context.Context, r Reporter) context.Context {
+	if prev, ok := ctx.Value(reporterKey{}).(Reporter); ok {
+		r = teeReporter{prev, r}
+	}
+	return WithReporter(ctx, r)
+}
+
+type teeReporter []Reporter
+
+func (t teeReporter) Emit(e Event) {
+	for _, r := range t {
+		r.Emit(e)
+	}
+}
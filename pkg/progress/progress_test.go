@@ -0,0 +1,107 @@
+package progress
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func decodeEvents(t *testing.T, buf *bytes.Buffer) []Event {
+	t.Helper()
+	var events []Event
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var e Event
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			t.Fatalf("line %q is not JSON: %v", line, err)
+		}
+		events = append(events, e)
+	}
+	return events
+}
+
+func TestEmit_NoReporter(t *testing.T) {
+	// Без Reporter в ctx — no-op, без паники
+	Emit(context.Background(), Event{Event: Progress, Done: 1, Total: 2})
+	Begin(context.Background(), "pipeline", "x.yaml").End(nil, 0)
+	StartStep(context.Background(), "load").End(errors.New("boom"), 0)
+}
+
+func TestJSONReporter_RunAndSteps(t *testing.T) {
+	var buf bytes.Buffer
+	ctx := WithReporter(context.Background(), NewJSONReporter(&buf))
+
+	run := Begin(ctx, "pipeline", "daily.yaml")
+	run.SetName("daily-sales")
+	step := StartStep(ctx, "load")
+	Emit(ctx, Event{Event: Progress, Step: "load", Item: "orders", Rows: 10, Done: 1, Total: 4})
+	step.End(nil, 10)
+	StartStep(ctx, "export").End(errors.New("disk full"), 0)
+	run.End(errors.New("export failed"), 0)
+
+	events := decodeEvents(t, &buf)
+	want := []string{RunStarted, StepStarted, Progress, StepCompleted, StepStarted, StepFailed, RunFailed}
+	if len(events) != len(want) {
+		t.Fatalf("got %d events, want %d:\n%s", len(events), len(want), buf.String())
+	}
+	for i, e := range events {
+		if e.Event != want[i] {
+			t.Errorf("event[%d] = %q, want %q", i, e.Event, want[i])
+		}
+		if e.Time.IsZero() {
+			t.Errorf("event[%d] has no timestamp", i)
+		}
+	}
+	if events[0].Command != "pipeline" || events[0].Name != "daily.yaml" {
+		t.Errorf("run_started = %+v", events[0])
+	}
+	if p := events[2]; p.Percent != 25 || p.Item != "orders" || p.Rows != 10 {
+		t.Errorf("progress = %+v, want 25%% of orders", p)
+	}
+	if events[5].Step != "export" || events[5].Error != "disk full" {
+		t.Errorf("step_failed = %+v", events[5])
+	}
+	if events[6].Name != "daily-sales" || events[6].Error != "export failed" {
+		t.Errorf("run_failed = %+v", events[6])
+	}
+}
+
+func TestJSONReporter_OmitsEmptyFields(t *testing.T) {
+	var buf bytes.Buffer
+	ctx := WithReporter(context.Background(), NewJSONReporter(&buf))
+	StartStep(ctx, "transform")
+
+	line := strings.TrimSpace(buf.String())
+	for _, field := range []string{`"rows"`, `"percent"`, `"error"`, `"total"`} {
+		if strings.Contains(line, field) {
+			t.Errorf("step_started carries empty %s: %s", field, line)
+		}
+	}
+}
+
+type recorder struct{ events []Event }
+
+func (r *recorder) Emit(e Event) { r.events = append(r.events, e) }
+
+func TestTee(t *testing.T) {
+	var buf bytes.Buffer
+	rec := &recorder{}
+	ctx := Tee(WithReporter(context.Background(), NewJSONReporter(&buf)), rec)
+	StartStep(ctx, "load").End(nil, 5)
+
+	if n := len(decodeEvents(t, &buf)); n != 2 {
+		t.Errorf("JSON reporter got %d events, want 2", n)
+	}
+	if len(rec.events) != 2 || rec.events[1].Rows != 5 {
+		t.Errorf("tee reporter got %+v", rec.events)
+	}
+
+	// Без прежнего Reporter Tee — то же, что WithReporter
+	rec = &recorder{}
+	Emit(Tee(context.Background(), rec), Event{Event: Progress})
+	if len(rec.events) != 1 {
+		t.Errorf("tee without previous reporter got %d events", len(rec.events))
+	}
+}
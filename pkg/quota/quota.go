@@ -0,0 +1,322 @@
+// Package quota ограничивает объём, который получатели забирают из одного
+// источника: строки, байты и число запросов за час и за сутки. Когда
+// источник кормит много команд, одна выгрузка без фильтра не должна съедать
+// базу за всех.
+//
+// Tracker проверяет лимиты до запуска экспорта (Check) и учитывает
+// выгруженное после (Record). Расход хранится в JSON-файле и переживает
+// перезапуск; приближение к лимиту (Config.AlertPercent) сообщается через
+// Tracker.OnAlert один раз за окно.
+package quota
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ErrExceeded — лимит получателя исчерпан.
+var ErrExceeded = errors.New("quota exceeded")
+
+// ErrUnknownRecipient — получатель не описан в Config и Default не задан.
+var ErrUnknownRecipient = errors.New("unknown quota recipient")
+
+// Limits — лимиты одного окна; 0 — без ограничения.
+type Limits struct {
+	Rows     int64 `yaml:"rows,omitempty" json:"rows,omitempty"`
+	Bytes    int64 `yaml:"bytes,omitempty" json:"bytes,omitempty"`
+	Requests int64 `yaml:"requests,omitempty" json:"requests,omitempty"`
+}
+
+// Quota — лимиты получателя за час и за сутки.
+type Quota struct {
+	Hourly Limits `yaml:"hourly,omitempty"`
+	Daily  Limits `yaml:"daily,omitempty"`
+}
+
+// Config — квоты всех получателей.
+type Config struct {
+	Recipients map[string]Quota `yaml:"recipients"`
+	Default    *Quota           `yaml:"default,omitempty"` // для получателей не из списка; nil — такие запросы отклоняются
+
+	StateFile    string `yaml:"state_file"`              // JSON с расходом; "" — только в памяти
+	AlertPercent int    `yaml:"alert_percent,omitempty"` // порог предупреждения, по умолчанию 80
+}
+
+// DefaultAlertPercent — порог предупреждения по умолчанию.
+const DefaultAlertPercent = 80
+
+// Usage — расход одного окна.
+type Usage struct {
+	Window   string `json:"window"` // "2006-01-02T15" (час) или "2006-01-02" (сутки), UTC
+	Rows     int64  `json:"rows"`
+	Bytes    int64  `json:"bytes"`
+	Requests int64  `json:"requests"`
+
+	Alerted bool `json:"alerted,omitempty"` // предупреждение этого окна уже отправлено
+}
+
+// WindowStatus — расход и лимиты окна.
+type WindowStatus struct {
+	Limits  Limits    `json:"limits"`
+	Used    Usage     `json:"used"`
+	ResetAt time.Time `json:"reset_at"`
+}
+
+// Exhausted сообщает, исчерпан ли хотя бы один лимит окна.
+func (w WindowStatus) Exhausted() bool {
+	_, ok := exhausted(w.Limits, w.Used)
+	return ok
+}
+
+// Status — состояние квоты получателя.
+type Status struct {
+	Recipient string       `json:"recipient"`
+	Hourly    WindowStatus `json:"hourly"`
+	Daily     WindowStatus `json:"daily"`
+}
+
+// Alert — получатель приблизился к лимиту окна.
+type Alert struct {
+	Recipient string
+	Window    string // "hourly" | "daily"
+	Resource  string // "rows" | "bytes" | "requests"
+	Used      int64
+	Limit     int64
+}
+
+func (a Alert) String() string {
+	return fmt.Sprintf("recipient %s used %d of %d %s %s (%d%%)",
+		a.Recipient, a.Used, a.Limit, a.Window, a.Resource, a.Used*100/a.Limit)
+}
+
+type recipientUsage struct {
+	Hourly Usage `json:"hourly"`
+	Daily  Usage `json:"daily"`
+}
+
+// Tracker учитывает расход получателей. Безопасен для параллельного
+// использования в одном процессе; процессы с общим StateFile могут терять
+// учёт друг друга — для tdtpcli, запускаемого по расписанию, это допустимо.
+type Tracker struct {
+	cfg Config
+
+	// OnAlert вызывается, когда расход окна переходит порог AlertPercent.
+	// По умолчанию — предупреждение в stderr.
+	OnAlert func(Alert)
+
+	mu    sync.Mutex
+	usage map[string]*recipientUsage
+}
+
+// Open создаёт Tracker и загружает расход из cfg.StateFile.
+func Open(cfg Config) (*Tracker, error) {
+	if cfg.AlertPercent <= 0 {
+		cfg.AlertPercent = DefaultAlertPercent
+	}
+	t := &Tracker{
+		cfg:   cfg,
+		usage: make(map[string]*recipientUsage),
+		OnAlert: func(a Alert) {
+			fmt.Fprintf(os.Stderr, "⚠ quota: %s\n", a)
+		},
+	}
+	if cfg.StateFile == "" {
+		return t, nil
+	}
+	data, err := os.ReadFile(cfg.StateFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return t, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read quota state: %w", err)
+	}
+	if err := json.Unmarshal(data, &t.usage); err != nil {
+		return nil, fmt.Errorf("failed to parse quota state %s: %w", cfg.StateFile, err)
+	}
+	return t, nil
+}
+
+// Check проверяет, можно ли получателю запустить ещё один экспорт, и
+// возвращает состояние квоты. Исчерпанный лимит — ErrExceeded (вместе с
+// состоянием, чтобы показать его получателю).
+func (t *Tracker) Check(recipient string, now time.Time) (Status, error) {
+	q, err := t.quota(recipient)
+	if err != nil {
+		return Status{}, err
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	u := t.current(recipient, now)
+	st := status(recipient, q, u, now)
+
+	if res, ok := exhausted(q.Hourly, u.Hourly); ok {
+		return st, fmt.Errorf("%w: hourly %s limit %d reached for %s", ErrExceeded, res, limitOf(q.Hourly, res), recipient)
+	}
+	if res, ok := exhausted(q.Daily, u.Daily); ok {
+		return st, fmt.Errorf("%w: daily %s limit %d reached for %s", ErrExceeded, res, limitOf(q.Daily, res), recipient)
+	}
+	return st, nil
+}
+
+// Record учитывает выполненный экспорт: один запрос, rows строк и bytes
+// байт. Расход сохраняется в StateFile.
+func (t *Tracker) Record(recipient string, rows, bytes int64, now time.Time) (Status, error) {
+	q, err := t.quota(recipient)
+	if err != nil {
+		return Status{}, err
+	}
+	t.mu.Lock()
+	u := t.current(recipient, now)
+	var alerts []Alert
+	for _, w := range []struct {
+		name   string
+		limits Limits
+		usage  *Usage
+	}{{"hourly", q.Hourly, &u.Hourly}, {"daily", q.Daily, &u.Daily}} {
+		w.usage.Requests++
+		w.usage.Rows += rows
+		w.usage.Bytes += bytes
+		if a, ok := t.alert(recipient, w.name, w.limits, w.usage); ok {
+			alerts = append(alerts, a)
+		}
+	}
+	st := status(recipient, q, u, now)
+	err = t.save()
+	t.mu.Unlock()
+
+	for _, a := range alerts {
+		if t.OnAlert != nil {
+			t.OnAlert(a)
+		}
+	}
+	return st, err
+}
+
+// Status возвращает состояние квоты получателя без учёта запроса.
+func (t *Tracker) Status(recipient string, now time.Time) (Status, error) {
+	q, err := t.quota(recipient)
+	if err != nil {
+		return Status{}, err
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return status(recipient, q, t.current(recipient, now), now), nil
+}
+
+func (t *Tracker) quota(recipient string) (Quota, error) {
+	if q, ok := t.cfg.Recipients[recipient]; ok {
+		return q, nil
+	}
+	if t.cfg.Default != nil {
+		return *t.cfg.Default, nil
+	}
+	return Quota{}, fmt.Errorf("%w: %q", ErrUnknownRecipient, recipient)
+}
+
+// current возвращает расход получателя, сбрасывая истёкшие окна.
+// Вызывается под mu.
+func (t *Tracker) current(recipient string, now time.Time) *recipientUsage {
+	u := t.usage[recipient]
+	if u == nil {
+		u = &recipientUsage{}
+		t.usage[recipient] = u
+	}
+	hour, day := windows(now)
+	if u.Hourly.Window != hour {
+		u.Hourly = Usage{Window: hour}
+	}
+	if u.Daily.Window != day {
+		u.Daily = Usage{Window: day}
+	}
+	return u
+}
+
+// alert отмечает первый переход порога в окне. Вызывается под mu.
+func (t *Tracker) alert(recipient, window string, l Limits, u *Usage) (Alert, bool) {
+	if u.Alerted {
+		return Alert{}, false
+	}
+	for _, r := range []struct {
+		name        string
+		used, limit int64
+	}{{"rows", u.Rows, l.Rows}, {"bytes", u.Bytes, l.Bytes}, {"requests", u.Requests, l.Requests}} {
+		if r.limit > 0 && r.used*100 >= r.limit*int64(t.cfg.AlertPercent) {
+			u.Alerted = true
+			return Alert{Recipient: recipient, Window: window, Resource: r.name, Used: r.used, Limit: r.limit}, true
+		}
+	}
+	return Alert{}, false
+}
+
+// save пишет расход в StateFile через временный файл. Вызывается под mu.
+func (t *Tracker) save() error {
+	if t.cfg.StateFile == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(t.usage, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(t.cfg.StateFile), ".quota-*")
+	if err != nil {
+		return fmt.Errorf("failed to save quota state: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("failed to save quota state: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("failed to save quota state: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), t.cfg.StateFile); err != nil {
+		return fmt.Errorf("failed to save quota state: %w", err)
+	}
+	return nil
+}
+
+// exhausted — первый исчерпанный ресурс окна. Запросы проверяются с учётом
+// проверяемого: лимит 10 разрешает ровно 10 запросов.
+func exhausted(l Limits, u Usage) (string, bool) {
+	switch {
+	case l.Requests > 0 && u.Requests >= l.Requests:
+		return "requests", true
+	case l.Rows > 0 && u.Rows >= l.Rows:
+		return "rows", true
+	case l.Bytes > 0 && u.Bytes >= l.Bytes:
+		return "bytes", true
+	}
+	return "", false
+}
+
+func limitOf(l Limits, resource string) int64 {
+	switch resource {
+	case "rows":
+		return l.Rows
+	case "bytes":
+		return l.Bytes
+	}
+	return l.Requests
+}
+
+func windows(now time.Time) (hour, day string) {
+	now = now.UTC()
+	return now.Format("2006-01-02T15"), now.Format("2006-01-02")
+}
+
+func status(recipient string, q Quota, u *recipientUsage, now time.Time) Status {
+	now = now.UTC()
+	hour := now.Truncate(time.Hour)
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	return Status{
+		Recipient: recipient,
+		Hourly:    WindowStatus{Limits: q.Hourly, Used: u.Hourly, ResetAt: hour.Add(time.Hour)},
+		Daily:     WindowStatus{Limits: q.Daily, Used: u.Daily, ResetAt: day.AddDate(0, 0, 1)},
+	}
+}
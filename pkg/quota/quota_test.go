@@ -0,0 +1,106 @@
+package quota
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var t0 = time.Date(2026, 3, 10, 14, 20, 0, 0, time.UTC)
+
+func TestTracker_ExceedsHourlyRows(t *testing.T) {
+	tr, err := Open(Config{Recipients: map[string]Quota{
+		"bi": {Hourly: Limits{Rows: 1000}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr.OnAlert = nil
+
+	if _, err := tr.Check("bi", t0); err != nil {
+		t.Fatalf("first check: %v", err)
+	}
+	if _, err := tr.Record("bi", 1000, 50_000, t0); err != nil {
+		t.Fatal(err)
+	}
+	st, err := tr.Check("bi", t0.Add(time.Minute))
+	if !errors.Is(err, ErrExceeded) {
+		t.Fatalf("err = %v, want ErrExceeded", err)
+	}
+	if st.Hourly.Used.Rows != 1000 || !st.Hourly.ResetAt.Equal(time.Date(2026, 3, 10, 15, 0, 0, 0, time.UTC)) {
+		t.Errorf("status = %+v", st.Hourly)
+	}
+
+	// Следующий час — окно сброшено
+	if _, err := tr.Check("bi", t0.Add(time.Hour)); err != nil {
+		t.Errorf("next hour: %v", err)
+	}
+}
+
+func TestTracker_RequestLimit(t *testing.T) {
+	tr, _ := Open(Config{Recipients: map[string]Quota{"bi": {Daily: Limits{Requests: 2}}}})
+	tr.OnAlert = nil
+	for i := 0; i < 2; i++ {
+		if _, err := tr.Check("bi", t0); err != nil {
+			t.Fatalf("request %d: %v", i+1, err)
+		}
+		_, _ = tr.Record("bi", 1, 1, t0)
+	}
+	if _, err := tr.Check("bi", t0); !errors.Is(err, ErrExceeded) {
+		t.Errorf("third request: err = %v, want ErrExceeded", err)
+	}
+}
+
+func TestTracker_UnknownRecipient(t *testing.T) {
+	tr, _ := Open(Config{Recipients: map[string]Quota{"bi": {}}})
+	if _, err := tr.Check("ops", t0); !errors.Is(err, ErrUnknownRecipient) {
+		t.Errorf("err = %v, want ErrUnknownRecipient", err)
+	}
+
+	tr, _ = Open(Config{Default: &Quota{Daily: Limits{Rows: 10}}})
+	if _, err := tr.Check("ops", t0); err != nil {
+		t.Errorf("default quota: %v", err)
+	}
+}
+
+func TestTracker_AlertOncePerWindow(t *testing.T) {
+	tr, _ := Open(Config{Recipients: map[string]Quota{"bi": {Daily: Limits{Bytes: 100}}}, AlertPercent: 80})
+	var alerts []Alert
+	tr.OnAlert = func(a Alert) { alerts = append(alerts, a) }
+
+	_, _ = tr.Record("bi", 1, 50, t0)
+	_, _ = tr.Record("bi", 1, 35, t0) // 85%
+	_, _ = tr.Record("bi", 1, 10, t0) // 95% — уже предупреждали
+	if len(alerts) != 1 || alerts[0].Resource != "bytes" || alerts[0].Window != "daily" {
+		t.Fatalf("alerts = %+v", alerts)
+	}
+
+	_, _ = tr.Record("bi", 1, 90, t0.AddDate(0, 0, 1))
+	if len(alerts) != 2 {
+		t.Errorf("next day alerts = %d, want 2", len(alerts))
+	}
+}
+
+func TestTracker_Persisted(t *testing.T) {
+	cfg := Config{
+		Recipients: map[string]Quota{"bi": {Daily: Limits{Rows: 100}}},
+		StateFile:  filepath.Join(t.TempDir(), "quota.json"),
+	}
+	tr, err := Open(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr.OnAlert = nil
+	if _, err := tr.Record("bi", 100, 10, t0); err != nil {
+		t.Fatal(err)
+	}
+
+	tr, err = Open(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tr.Check("bi", t0.Add(time.Hour)); !errors.Is(err, ErrExceeded) {
+		t.Errorf("after reopen: err = %v, want ErrExceeded", err)
+	}
+}
@@ -0,0 +1,92 @@
+package report
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/tdtql"
+)
+
+// Funcs возвращает функции шаблонов (общие для HTML, PDF и XLSX):
+//
+//	count "orders" ["status = 'paid'"]          — число строк
+//	sum|avg|min|max "orders" "amount" ["where"] — агрегат по полю, NULL не учитываются
+//	rows "orders" ["where"]                     — строки как map поле → значение
+//	fields "orders"                             — имена полей по порядку
+//	number "12.50"                              — строку в float64 (для printf)
+//
+// WHERE — условие TDTQL в SQL-синтаксисе (как --where в tdtpcli),
+// несколько условий объединяются через AND.
+// Результаты агрегатов — строки в формате TDTP, NULL — пустая строка.
+// Результат возвращается как map[string]any, пригодный и для
+// text/template.FuncMap, и для html/template.FuncMap.
+func (r *Report) Funcs() map[string]any {
+	aggregate := func(fn tdtql.AggregateFunc) func(table, field string, where ...string) (string, error) {
+		return func(table, field string, where ...string) (string, error) {
+			return r.Aggregate(fn, table, field, joinWhere(where))
+		}
+	}
+
+	return map[string]any{
+		"count": func(table string, where ...string) (string, error) {
+			return r.Aggregate(tdtql.AggCount, table, "*", joinWhere(where))
+		},
+		"sum": aggregate(tdtql.AggSum),
+		"avg": aggregate(tdtql.AggAvg),
+		"min": aggregate(tdtql.AggMin),
+		"max": aggregate(tdtql.AggMax),
+		"rows": func(table string, where ...string) ([]map[string]string, error) {
+			t, rows, err := r.Where(table, joinWhere(where))
+			if err != nil {
+				return nil, err
+			}
+			result := make([]map[string]string, len(rows))
+			for i, row := range rows {
+				m := make(map[string]string, len(t.Schema.Fields))
+				for c, f := range t.Schema.Fields {
+					if c < len(row) {
+						m[f.Name] = row[c]
+					}
+				}
+				result[i] = m
+			}
+			return result, nil
+		},
+		"fields": func(table string) ([]string, error) {
+			t, err := r.Table(table)
+			if err != nil {
+				return nil, err
+			}
+			names := make([]string, len(t.Schema.Fields))
+			for i, f := range t.Schema.Fields {
+				names[i] = f.Name
+			}
+			return names, nil
+		},
+		"number": func(v string) (float64, error) {
+			if v == "" {
+				return 0, nil
+			}
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return 0, fmt.Errorf("not a number: %q", v)
+			}
+			return f, nil
+		},
+	}
+}
+
+// joinWhere объединяет несколько условий через AND
+func joinWhere(where []string) string {
+	if len(where) == 1 {
+		return where[0]
+	}
+	parts := make([]string, 0, len(where))
+	for _, w := range where {
+		if strings.TrimSpace(w) != "" {
+			parts = append(parts, "("+w+")")
+		}
+	}
+	return strings.Join(parts, " AND ")
+}
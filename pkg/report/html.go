@@ -0,0 +1,85 @@
+package report
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// RenderHTML рендерит html/template шаблон отчёта. Данные шаблона — сам
+// Report (.Tables, .Params, .Generated), функции — Funcs.
+func (r *Report) RenderHTML(w io.Writer, tmpl string) error {
+	t, err := template.New("report").Funcs(r.Funcs()).Parse(tmpl)
+	if err != nil {
+		return fmt.Errorf("failed to parse report template: %w", err)
+	}
+	if err := t.Execute(w, r); err != nil {
+		return fmt.Errorf("failed to render report: %w", err)
+	}
+	return nil
+}
+
+// PDFConfig — внешний конвертер HTML → PDF. В Args плейсхолдеры {input}
+// и {output} заменяются путями HTML и PDF файлов.
+//
+//	wkhtmltopdf (по умолчанию): Command "wkhtmltopdf", Args ["{input}", "{output}"]
+//	Chromium: Command "chromium", Args ["--headless", "--print-to-pdf={output}", "{input}"]
+type PDFConfig struct {
+	Command string
+	Args    []string
+}
+
+// DefaultPDFConfig — wkhtmltopdf из PATH
+func DefaultPDFConfig() PDFConfig {
+	return PDFConfig{Command: "wkhtmltopdf", Args: []string{"{input}", "{output}"}}
+}
+
+// RenderPDF рендерит HTML шаблон во временный файл и конвертирует его в
+// outFile внешней командой cfg (пустая — DefaultPDFConfig).
+func (r *Report) RenderPDF(ctx context.Context, tmpl, outFile string, cfg PDFConfig) error {
+	if cfg.Command == "" {
+		cfg = DefaultPDFConfig()
+	}
+
+	dir, err := os.MkdirTemp("", "tdtp-report-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	htmlPath := filepath.Join(dir, "report.html")
+	f, err := os.Create(htmlPath)
+	if err != nil {
+		return fmt.Errorf("failed to create temp HTML: %w", err)
+	}
+	err = r.RenderHTML(f, tmpl)
+	if cerr := f.Close(); err == nil && cerr != nil {
+		err = fmt.Errorf("failed to write temp HTML: %w", cerr)
+	}
+	if err != nil {
+		return err
+	}
+
+	outAbs, err := filepath.Abs(outFile)
+	if err != nil {
+		return fmt.Errorf("invalid output path: %w", err)
+	}
+	args := make([]string, len(cfg.Args))
+	for i, a := range cfg.Args {
+		args[i] = strings.NewReplacer("{input}", htmlPath, "{output}", outAbs).Replace(a)
+	}
+
+	cmd := exec.CommandContext(ctx, cfg.Command, args...) //nolint:gosec // G204: converter is configured by the operator
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("PDF converter %s failed: %w: %s", cfg.Command, err, strings.TrimSpace(string(out)))
+	}
+	if _, err := os.Stat(outAbs); err != nil {
+		return fmt.Errorf("PDF converter %s did not create %s", cfg.Command, outFile)
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+// Package report рендерит TDTP пакеты в отчёты по шаблонам:
+// HTML (html/template), PDF (HTML через внешний конвертер) и XLSX
+// (шаблон Excel с именованными диапазонами и {{...}} плейсхолдерами).
+//
+// Шаблоны всех форматов используют одни функции (см. Report.Funcs):
+// агрегаты count/sum/avg/min/max с необязательным TDTQL WHERE, строки
+// таблицы rows и поля fields.
+package report
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+	"github.com/ruslano69/tdtp-framework/pkg/core/tdtql"
+)
+
+// Table — данные одной таблицы отчёта (все части пакетов таблицы)
+type Table struct {
+	Name   string
+	Schema packet.Schema
+	Rows   [][]string
+}
+
+// Report — набор таблиц, доступных шаблону по имени Header.TableName
+type Report struct {
+	Tables    []*Table          // в порядке первых пакетов таблиц
+	Params    map[string]string // произвольные параметры шаблона (.Params)
+	Generated time.Time         // время формирования отчёта (.Generated)
+
+	executor   *tdtql.Executor
+	translator *tdtql.Translator
+}
+
+// New собирает отчёт из пакетов. Части одной таблицы объединяются и должны
+// иметь одинаковую схему; compact-пакеты разворачиваются. Сжатые,
+// зашифрованные, delta и delete пакеты не принимаются.
+func New(packets ...*packet.DataPacket) (*Report, error) {
+	r := &Report{
+		Params:     make(map[string]string),
+		Generated:  time.Now(),
+		executor:   tdtql.NewExecutor(),
+		translator: tdtql.NewTranslator(),
+	}
+
+	for _, pkt := range packets {
+		if pkt == nil {
+			continue
+		}
+		name := pkt.Header.TableName
+		if err := checkPacket(pkt); err != nil {
+			return nil, fmt.Errorf("table %s, part %d: %w", name, pkt.Header.PartNumber, err)
+		}
+		if pkt.Data.Compact {
+			expanded := *pkt // ExpandCompactRows меняет пакет — разворачиваем копию
+			if err := packet.ExpandCompactRows(&expanded); err != nil {
+				return nil, fmt.Errorf("table %s, part %d: %w", name, pkt.Header.PartNumber, err)
+			}
+			pkt = &expanded
+		}
+
+		t, ok := r.lookup(name)
+		if !ok {
+			t = &Table{Name: name, Schema: pkt.Schema}
+			r.Tables = append(r.Tables, t)
+		} else if !packet.SchemaEquals(t.Schema, pkt.Schema) {
+			return nil, fmt.Errorf("table %s, part %d: schema differs from previous parts", name, pkt.Header.PartNumber)
+		}
+		t.Rows = append(t.Rows, pkt.GetRows()...)
+	}
+	return r, nil
+}
+
+// Table возвращает таблицу по имени (без учёта регистра)
+func (r *Report) Table(name string) (*Table, error) {
+	t, ok := r.lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("table %q is not in the report", name)
+	}
+	return t, nil
+}
+
+func (r *Report) lookup(name string) (*Table, bool) {
+	for _, t := range r.Tables {
+		if strings.EqualFold(t.Name, name) {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+// Where возвращает строки таблицы, удовлетворяющие TDTQL WHERE
+// (пустое условие — все строки)
+func (r *Report) Where(table, where string) (*Table, [][]string, error) {
+	t, err := r.Table(table)
+	if err != nil {
+		return nil, nil, err
+	}
+	if strings.TrimSpace(where) == "" {
+		return t, t.Rows, nil
+	}
+	filters, err := r.translator.TranslateWhere(where)
+	if err != nil {
+		return nil, nil, fmt.Errorf("table %s: invalid WHERE %q: %w", t.Name, where, err)
+	}
+	rows, err := r.executor.ExecuteWhere(filters, t.Rows, t.Schema)
+	if err != nil {
+		return nil, nil, fmt.Errorf("table %s: WHERE %q: %w", t.Name, where, err)
+	}
+	return t, rows, nil
+}
+
+// Aggregate вычисляет агрегат по полю таблицы на строках, отобранных
+// TDTQL WHERE (см. tdtql.Executor.Aggregate)
+func (r *Report) Aggregate(fn tdtql.AggregateFunc, table, field, where string) (string, error) {
+	t, rows, err := r.Where(table, where)
+	if err != nil {
+		return "", err
+	}
+	v, err := r.executor.Aggregate(fn, field, rows, t.Schema)
+	if err != nil {
+		return "", fmt.Errorf("table %s: %w", t.Name, err)
+	}
+	return v, nil
+}
+
+// checkPacket отклоняет пакеты, строки которых нельзя использовать как есть
+func checkPacket(pkt *packet.DataPacket) error {
+	switch {
+	case pkt.Header.TableName == "":
+		return fmt.Errorf("packet has no table name")
+	case pkt.Schema.Encryption != "" || pkt.Data.Encryption != "":
+		return fmt.Errorf("packet is encrypted")
+	case pkt.Data.Compression != "":
+		return fmt.Errorf("packet is compressed (%s), decompress it first", pkt.Data.Compression)
+	case pkt.Data.Delta || pkt.Data.Delete:
+		return fmt.Errorf("delta and delete packets cannot be rendered")
+	}
+	return nil
+}
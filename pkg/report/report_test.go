@@ -0,0 +1,180 @@
+package report
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+	"github.com/xuri/excelize/v2"
+)
+
+func ordersPacket(part int, rows [][]string) *packet.DataPacket {
+	pkt := packet.NewDataPacket(packet.TypeReference, "orders")
+	pkt.Header.PartNumber = part
+	pkt.Schema = packet.Schema{Fields: []packet.Field{
+		{Name: "id", Type: "INTEGER", Key: true},
+		{Name: "customer", Type: "TEXT", Length: 50},
+		{Name: "status", Type: "TEXT", Length: 10},
+		{Name: "amount", Type: "DECIMAL", Precision: 10, Scale: 2},
+	}}
+	pkt.SetRows(rows)
+	return pkt
+}
+
+func testReport(t *testing.T) *Report {
+	t.Helper()
+	r, err := New(
+		ordersPacket(1, [][]string{
+			{"1", "Acme", "paid", "100.50"},
+			{"2", "<Globex>", "open", "20.00"},
+		}),
+		ordersPacket(2, [][]string{
+			{"3", "Acme", "paid", "9.50"},
+		}),
+	)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	r.Params["period"] = "2024-Q1"
+	return r
+}
+
+func TestNew_MergesParts(t *testing.T) {
+	r := testReport(t)
+	if len(r.Tables) != 1 || len(r.Tables[0].Rows) != 3 {
+		t.Fatalf("tables = %+v, want one table with 3 rows", r.Tables)
+	}
+
+	other := ordersPacket(3, nil)
+	other.Schema.Fields = other.Schema.Fields[:2]
+	if _, err := New(ordersPacket(1, nil), other); err == nil {
+		t.Error("parts with different schemas should fail")
+	}
+
+	compressed := ordersPacket(1, nil)
+	compressed.Data.Compression = "zstd"
+	if _, err := New(compressed); err == nil {
+		t.Error("compressed packet should be rejected")
+	}
+}
+
+func TestRenderHTML(t *testing.T) {
+	r := testReport(t)
+	tmpl := `<h1>{{.Params.period}}</h1>` +
+		`<p>{{count "orders"}} orders, paid {{sum "orders" "amount" "status = 'paid'"}}, ` +
+		`max {{max "orders" "amount"}}, avg {{printf "%.2f" (avg "orders" "amount" | number)}}</p>` +
+		`{{range rows "orders" "amount > 10" "customer LIKE 'A%'"}}<td>{{.id}}</td>{{end}}` +
+		`{{range rows "orders" "status = 'open'"}}<td>{{.customer}}</td>{{end}}`
+
+	var sb strings.Builder
+	if err := r.RenderHTML(&sb, tmpl); err != nil {
+		t.Fatalf("RenderHTML: %v", err)
+	}
+	want := `<h1>2024-Q1</h1><p>3 orders, paid 110.00, max 100.50, avg 43.33</p>` +
+		`<td>1</td><td>&lt;Globex&gt;</td>`
+	if sb.String() != want {
+		t.Errorf("got  %s\nwant %s", sb.String(), want)
+	}
+
+	if err := r.RenderHTML(&sb, `{{sum "missing" "amount"}}`); err == nil {
+		t.Error("unknown table should fail")
+	}
+	if err := r.RenderHTML(&sb, `{{sum "orders" "customer"}}`); err == nil {
+		t.Error("SUM over TEXT should fail")
+	}
+}
+
+func TestRenderPDF_ExternalCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh as a stand-in converter")
+	}
+	r := testReport(t)
+	out := filepath.Join(t.TempDir(), "report.pdf")
+
+	// "Конвертер" копирует HTML как есть
+	cfg := PDFConfig{Command: "sh", Args: []string{"-c", `cp "$0" "$1"`, "{input}", "{output}"}}
+	if err := r.RenderPDF(context.Background(), `total {{sum "orders" "amount"}}`, out, cfg); err != nil {
+		t.Fatalf("RenderPDF: %v", err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "total 130.00" {
+		t.Errorf("converter input = %q", data)
+	}
+
+	cfg.Args = []string{"-c", "echo broken >&2; exit 1"}
+	if err := r.RenderPDF(context.Background(), "x", out, cfg); err == nil || !strings.Contains(err.Error(), "broken") {
+		t.Errorf("expected converter failure with its output, got %v", err)
+	}
+}
+
+func TestRenderXLSX(t *testing.T) {
+	r := testReport(t)
+	dir := t.TempDir()
+	templatePath := filepath.Join(dir, "template.xlsx")
+	outPath := filepath.Join(dir, "report.xlsx")
+
+	tpl := excelize.NewFile()
+	_ = tpl.SetCellStr("Sheet1", "A1", "Orders {{.Params.period}}")
+	_ = tpl.SetCellStr("Sheet1", "A2", "id")
+	_ = tpl.SetCellStr("Sheet1", "D2", "amount")
+	_ = tpl.SetCellStr("Sheet1", "A4", "Total")
+	_ = tpl.SetCellStr("Sheet1", "D4", `{{sum "orders" "amount"}}`)
+	if err := tpl.SetDefinedName(&excelize.DefinedName{Name: "orders", RefersTo: "Sheet1!$A$3"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tpl.SaveAs(templatePath); err != nil {
+		t.Fatal(err)
+	}
+	_ = tpl.Close()
+
+	if err := r.RenderXLSX(templatePath, outPath); err != nil {
+		t.Fatalf("RenderXLSX: %v", err)
+	}
+
+	f, err := excelize.OpenFile(outPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+
+	cells := map[string]string{
+		"A1": "Orders 2024-Q1",
+		"A3": "1", "B3": "Acme", "D3": "100.5",
+		"A4": "2", "B4": "<Globex>",
+		"A5": "3",
+		"A6": "Total", // итог сдвинут вставленными строками
+		"D6": "130",   // число, а не текст
+	}
+	for cell, want := range cells {
+		got, _ := f.GetCellValue("Sheet1", cell, excelize.Options{RawCellValue: true})
+		if got != want {
+			t.Errorf("%s = %q, want %q", cell, got, want)
+		}
+	}
+	if typ, _ := f.GetCellType("Sheet1", "D6"); typ == excelize.CellTypeSharedString || typ == excelize.CellTypeInlineString {
+		t.Errorf("D6 should be numeric, got cell type %v", typ)
+	}
+}
+
+func TestParseRefersTo(t *testing.T) {
+	tests := []struct{ ref, sheet, cell string }{
+		{"Sheet1!$A$5", "Sheet1", "A5"},
+		{"='My ''Q1'' sheet'!$B$2:$E$2", "My 'Q1' sheet", "B2"},
+	}
+	for _, tt := range tests {
+		sheet, cell, ok := parseRefersTo(tt.ref)
+		if !ok || sheet != tt.sheet || cell != tt.cell {
+			t.Errorf("parseRefersTo(%q) = %q, %q, %v", tt.ref, sheet, cell, ok)
+		}
+	}
+	if _, _, ok := parseRefersTo("#REF!"); ok {
+		t.Error("#REF! should not parse")
+	}
+}
@@ -0,0 +1,178 @@
+package report
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+	"github.com/ruslano69/tdtp-framework/pkg/xlsx"
+	"github.com/xuri/excelize/v2"
+)
+
+// RenderXLSX заполняет Excel шаблон templateFile и сохраняет результат в outFile.
+//
+// Шаблон размечается средствами Excel:
+//   - именованный диапазон (Formulas → Name Manager) с именем таблицы отчёта
+//     указывает первую ячейку строк данных; строки пишутся вниз от неё, поля —
+//     вправо в порядке схемы. Под данные вставляются строки листа, поэтому
+//     итоги и текст ниже сдвигаются, а стили первой строки копируются на все;
+//   - ячейки с {{...}} вычисляются как text/template с функциями Funcs
+//     ({{sum "orders" "amount"}}, {{.Params.period}}). Если ячейка — одно
+//     выражение с числовым результатом, пишется число.
+//
+// Плейсхолдеры вычисляются до вставки данных: значения из пакетов шаблонами
+// не интерпретируются.
+func (r *Report) RenderXLSX(templateFile, outFile string) error {
+	f, err := excelize.OpenFile(templateFile)
+	if err != nil {
+		return fmt.Errorf("failed to open report template: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	if err := r.fillPlaceholders(f); err != nil {
+		return err
+	}
+
+	anchors, err := r.tableAnchors(f)
+	if err != nil {
+		return err
+	}
+	for _, a := range anchors {
+		if err := fillTable(f, a); err != nil {
+			return fmt.Errorf("table %s: %w", a.table.Name, err)
+		}
+	}
+
+	if err := f.SaveAs(outFile); err != nil {
+		return fmt.Errorf("failed to save report: %w", err)
+	}
+	return nil
+}
+
+// fillPlaceholders вычисляет ячейки с {{...}} на всех листах
+func (r *Report) fillPlaceholders(f *excelize.File) error {
+	funcs := r.Funcs()
+	for _, sheet := range f.GetSheetList() {
+		rows, err := f.GetRows(sheet, excelize.Options{RawCellValue: true})
+		if err != nil {
+			return fmt.Errorf("failed to read sheet %s: %w", sheet, err)
+		}
+		for ri, row := range rows {
+			for ci, value := range row {
+				if !strings.Contains(value, "{{") {
+					continue
+				}
+				cell, _ := excelize.CoordinatesToCellName(ci+1, ri+1)
+
+				t, err := template.New(cell).Funcs(funcs).Parse(value)
+				if err != nil {
+					return fmt.Errorf("%s!%s: %w", sheet, cell, err)
+				}
+				var sb strings.Builder
+				if err := t.Execute(&sb, r); err != nil {
+					return fmt.Errorf("%s!%s: %w", sheet, cell, err)
+				}
+
+				result := sb.String()
+				if isSingleAction(value) {
+					if num, err := strconv.ParseFloat(result, 64); err == nil {
+						_ = f.SetCellValue(sheet, cell, num)
+						continue
+					}
+				}
+				_ = f.SetCellStr(sheet, cell, result)
+			}
+		}
+	}
+	return nil
+}
+
+// isSingleAction — значение ячейки состоит из одного {{...}}
+func isSingleAction(value string) bool {
+	v := strings.TrimSpace(value)
+	return strings.HasPrefix(v, "{{") && strings.HasSuffix(v, "}}") && strings.Count(v, "{{") == 1
+}
+
+// tableAnchor — первая ячейка строк данных таблицы в шаблоне
+type tableAnchor struct {
+	table    *Table
+	sheet    string
+	col, row int
+}
+
+// tableAnchors находит именованные диапазоны таблиц отчёта. Порядок — снизу
+// вверх внутри листа, чтобы вставка строк не сдвигала ещё не заполненные.
+func (r *Report) tableAnchors(f *excelize.File) ([]tableAnchor, error) {
+	var anchors []tableAnchor
+	for _, dn := range f.GetDefinedName() {
+		t, ok := r.lookup(dn.Name)
+		if !ok {
+			continue
+		}
+		sheet, cell, ok := parseRefersTo(dn.RefersTo)
+		if !ok {
+			return nil, fmt.Errorf("named range %s: unsupported reference %q", dn.Name, dn.RefersTo)
+		}
+		col, row, err := excelize.CellNameToCoordinates(cell)
+		if err != nil {
+			return nil, fmt.Errorf("named range %s: %w", dn.Name, err)
+		}
+		anchors = append(anchors, tableAnchor{table: t, sheet: sheet, col: col, row: row})
+	}
+
+	slices.SortFunc(anchors, func(a, b tableAnchor) int {
+		if a.sheet != b.sheet {
+			return strings.Compare(a.sheet, b.sheet)
+		}
+		return b.row - a.row
+	})
+	return anchors, nil
+}
+
+// parseRefersTo разбирает ссылку именованного диапазона ('Sheet 1'!$A$5 или
+// Sheet1!$A$5:$D$5) в лист и первую ячейку
+func parseRefersTo(ref string) (sheet, cell string, ok bool) {
+	ref = strings.TrimPrefix(ref, "=")
+	i := strings.LastIndex(ref, "!")
+	if i <= 0 {
+		return "", "", false
+	}
+	sheet = strings.ReplaceAll(strings.Trim(ref[:i], "'"), "''", "'")
+	cell, _, _ = strings.Cut(ref[i+1:], ":")
+	cell = strings.ReplaceAll(cell, "$", "")
+	return sheet, cell, cell != ""
+}
+
+// fillTable вставляет под якорем строки для данных таблицы и пишет их
+func fillTable(f *excelize.File, a tableAnchor) error {
+	n := len(a.table.Rows)
+	if n == 0 {
+		return nil
+	}
+
+	if n > 1 {
+		if err := f.InsertRows(a.sheet, a.row+1, n-1); err != nil {
+			return fmt.Errorf("failed to insert rows: %w", err)
+		}
+		// Стили строки-образца — на все вставленные строки
+		for c := range a.table.Schema.Fields {
+			from, _ := excelize.CoordinatesToCellName(a.col+c, a.row)
+			style, err := f.GetCellStyle(a.sheet, from)
+			if err != nil || style == 0 {
+				continue
+			}
+			top, _ := excelize.CoordinatesToCellName(a.col+c, a.row+1)
+			bottom, _ := excelize.CoordinatesToCellName(a.col+c, a.row+n-1)
+			_ = f.SetCellStyle(a.sheet, top, bottom, style)
+		}
+	}
+
+	pkt := packet.NewDataPacket(packet.TypeReference, a.table.Name)
+	pkt.Schema = a.table.Schema
+	pkt.SetRows(a.table.Rows)
+	xlsx.WriteRows(f, a.sheet, a.col, a.row, pkt)
+	return nil
+}
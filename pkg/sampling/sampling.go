@@ -0,0 +1,440 @@
+// Package sampling строит согласованные выборки из нескольких таблиц БД:
+// N случайных (или стратифицированных) строк на таблицу плюс все строки,
+// на которые эти строки ссылаются по внешним ключам.
+//
+// Результат предназначен для dev/test-датасетов из production: выборка
+// проходит через процессоры маскирования/псевдонимизации
+// (pkg/processors) и выгружается обычными TDTP-пакетами, которые
+// импортируются в тестовую БД без нарушения ссылочной целостности.
+package sampling
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+
+	"github.com/ruslano69/tdtp-framework/pkg/adapters"
+	"github.com/ruslano69/tdtp-framework/pkg/adapters/base"
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+// fetchChunkSize — сколько значений ключа запрашивается одним OR-фильтром
+// при догрузке строк, на которые ссылается выборка.
+const fetchChunkSize = 200
+
+// TableSpec описывает выборку из одной таблицы.
+type TableSpec struct {
+	Name string `yaml:"name"`
+
+	// Rows — сколько строк выбрать случайно. 0 — только строки, на которые
+	// ссылаются другие таблицы выборки (типично для справочников).
+	Rows int `yaml:"rows,omitempty"`
+
+	// All — взять таблицу целиком (Rows игнорируется).
+	All bool `yaml:"all,omitempty"`
+
+	// StratifyBy — поле для стратифицированной выборки: Rows распределяются
+	// между значениями поля пропорционально их частоте, но каждое значение
+	// получает хотя бы одну строку.
+	StratifyBy string `yaml:"stratify_by,omitempty"`
+}
+
+// Relation — ссылка Table.Column → RefTable.RefColumn.
+type Relation struct {
+	Table     string `yaml:"table"`
+	Column    string `yaml:"column"`
+	RefTable  string `yaml:"ref_table"`
+	RefColumn string `yaml:"ref_column"`
+}
+
+// Config — параметры выборки.
+type Config struct {
+	Tables []TableSpec `yaml:"tables"`
+
+	// Relations — связи между таблицами. Если пусто, берутся внешние ключи
+	// из метаданных БД (Adapter.InspectTable). Связи с таблицами вне Tables
+	// игнорируются.
+	Relations []Relation `yaml:"relations,omitempty"`
+
+	// Seed делает выборку воспроизводимой; 0 — случайная.
+	Seed uint64 `yaml:"seed,omitempty"`
+}
+
+// Source — то, что нужно выборке от адаптера (adapters.Adapter подходит).
+//
+// Если Source дополнительно реализует base.RowStreamer, таблицы читаются
+// потоком и в памяти остаются только отобранные строки.
+type Source interface {
+	GetTableSchema(ctx context.Context, tableName string) (packet.Schema, error)
+	ExportTable(ctx context.Context, tableName string) ([]*packet.DataPacket, error)
+	ExportTableWithQuery(ctx context.Context, tableName string, query *packet.Query, sender, recipient string) ([]*packet.DataPacket, error)
+}
+
+// tableInspector — опциональный интерфейс для автоопределения связей.
+type tableInspector interface {
+	InspectTable(ctx context.Context, tableName string) (*adapters.TableReport, error)
+}
+
+// TableSample — результат выборки по одной таблице.
+type TableSample struct {
+	Table  string
+	Schema packet.Schema
+	Rows   [][]string
+
+	// Sampled — строк, отобранных случайно; Referenced — догруженных по ссылкам.
+	Sampled    int
+	Referenced int
+
+	// present[column][value] — значения (в том числе запрошенные, но не
+	// найденные) по колонкам, на которые ссылаются связи.
+	present map[int]map[string]bool
+}
+
+// Sample выполняет выборку: сначала независимо по каждой таблице, затем
+// замыкает её по связям, пока все внешние ключи отобранных строк не
+// указывают на строки выборки (висячие ссылки, которых нет и в источнике,
+// остаются как есть). Порядок результата совпадает с cfg.Tables.
+func Sample(ctx context.Context, src Source, cfg Config) ([]*TableSample, error) {
+	if len(cfg.Tables) == 0 {
+		return nil, fmt.Errorf("no tables to sample")
+	}
+
+	rng := newRand(cfg.Seed)
+	samples := make([]*TableSample, 0, len(cfg.Tables))
+	byName := make(map[string]*TableSample, len(cfg.Tables))
+
+	for _, spec := range cfg.Tables {
+		key := strings.ToLower(spec.Name)
+		if _, dup := byName[key]; dup {
+			return nil, fmt.Errorf("table %s listed twice", spec.Name)
+		}
+		ts, err := sampleTable(ctx, src, spec, rng)
+		if err != nil {
+			return nil, fmt.Errorf("sample %s: %w", spec.Name, err)
+		}
+		samples = append(samples, ts)
+		byName[key] = ts
+	}
+
+	relations := cfg.Relations
+	if len(relations) == 0 {
+		var err error
+		if relations, err = discoverRelations(ctx, src, cfg.Tables); err != nil {
+			return nil, err
+		}
+	}
+	if err := closeOverRelations(ctx, src, byName, relations); err != nil {
+		return nil, err
+	}
+	return samples, nil
+}
+
+func newRand(seed uint64) *rand.Rand {
+	if seed == 0 {
+		seed = rand.Uint64()
+	}
+	return rand.New(rand.NewPCG(seed, seed^0x9e3779b97f4a7c15))
+}
+
+// sampleTable отбирает строки одной таблицы.
+func sampleTable(ctx context.Context, src Source, spec TableSpec, rng *rand.Rand) (*TableSample, error) {
+	sch, err := src.GetTableSchema(ctx, spec.Name)
+	if err != nil {
+		return nil, fmt.Errorf("get schema: %w", err)
+	}
+	ts := &TableSample{Table: spec.Name, Schema: sch, present: make(map[int]map[string]bool)}
+	if spec.Rows < 0 {
+		return nil, fmt.Errorf("rows must be >= 0")
+	}
+	if !spec.All && spec.Rows == 0 {
+		return ts, nil
+	}
+
+	switch {
+	case spec.All:
+		err = scanTable(ctx, src, spec.Name, sch, func(row []string) error {
+			ts.Rows = append(ts.Rows, row)
+			return nil
+		})
+	case spec.StratifyBy != "":
+		col := fieldIndex(sch, spec.StratifyBy)
+		if col < 0 {
+			return nil, fmt.Errorf("stratify_by field '%s' not found in schema", spec.StratifyBy)
+		}
+		ts.Rows, err = stratifiedSample(ctx, src, spec, sch, col, rng)
+	default:
+		r := newReservoir(spec.Rows, rng)
+		err = scanTable(ctx, src, spec.Name, sch, func(row []string) error {
+			r.add(row)
+			return nil
+		})
+		ts.Rows = r.rows
+	}
+	if err != nil {
+		return nil, err
+	}
+	ts.Sampled = len(ts.Rows)
+	return ts, nil
+}
+
+// stratifiedSample держит по резервуару на каждое значение поля и после
+// чтения делит spec.Rows между стратами пропорционально числу строк
+// (метод наибольшего остатка, минимум одна строка на страту).
+func stratifiedSample(ctx context.Context, src Source, spec TableSpec, sch packet.Schema, col int, rng *rand.Rand) ([][]string, error) {
+	strata := make(map[string]*reservoir)
+	var order []string // порядок появления страт — для воспроизводимости при Seed
+	total := 0
+	err := scanTable(ctx, src, spec.Name, sch, func(row []string) error {
+		v := ""
+		if col < len(row) {
+			v = row[col]
+		}
+		r, ok := strata[v]
+		if !ok {
+			r = newReservoir(spec.Rows, rng)
+			strata[v] = r
+			order = append(order, v)
+		}
+		r.add(row)
+		total++
+		return nil
+	})
+	if err != nil || total == 0 {
+		return nil, err
+	}
+
+	quotas := make(map[string]int, len(order))
+	remainders := make(map[string]float64, len(order))
+	assigned := 0
+	for _, v := range order {
+		exact := float64(spec.Rows) * float64(strata[v].seen) / float64(total)
+		q := max(1, int(exact))
+		quotas[v] = q
+		remainders[v] = exact - float64(int(exact))
+		assigned += q
+	}
+	for assigned < spec.Rows {
+		best := ""
+		bestRem := -1.0
+		for _, v := range order {
+			if quotas[v] < strata[v].seen && remainders[v] > bestRem {
+				best, bestRem = v, remainders[v]
+			}
+		}
+		if best == "" {
+			break // строк меньше, чем запрошено
+		}
+		quotas[best]++
+		remainders[best] = -1
+		assigned++
+	}
+
+	var rows [][]string
+	for _, v := range order {
+		r := strata[v]
+		rng.Shuffle(len(r.rows), func(i, j int) { r.rows[i], r.rows[j] = r.rows[j], r.rows[i] })
+		rows = append(rows, r.rows[:min(quotas[v], len(r.rows))]...)
+	}
+	return rows, nil
+}
+
+// reservoir — равномерная выборка k строк из потока неизвестной длины
+// (Algorithm R).
+type reservoir struct {
+	k    int
+	seen int
+	rows [][]string
+	rng  *rand.Rand
+}
+
+func newReservoir(k int, rng *rand.Rand) *reservoir {
+	return &reservoir{k: k, rng: rng}
+}
+
+func (r *reservoir) add(row []string) {
+	r.seen++
+	if len(r.rows) < r.k {
+		r.rows = append(r.rows, row)
+		return
+	}
+	if j := r.rng.IntN(r.seen); j < r.k {
+		r.rows[j] = row
+	}
+}
+
+// scanTable читает все строки таблицы: потоком, если Source это умеет,
+// иначе через ExportTable.
+func scanTable(ctx context.Context, src Source, table string, sch packet.Schema, fn func(row []string) error) error {
+	if streamer, ok := src.(base.RowStreamer); ok {
+		return streamer.StreamAllRows(ctx, table, sch, fn)
+	}
+	packets, err := src.ExportTable(ctx, table)
+	if err != nil {
+		return fmt.Errorf("export table: %w", err)
+	}
+	return eachPacketRow(packets, fn)
+}
+
+func eachPacketRow(packets []*packet.DataPacket, fn func(row []string) error) error {
+	parser := packet.NewParser()
+	for _, pkt := range packets {
+		pkt.MaterializeRows()
+		for _, row := range pkt.Data.Rows {
+			if err := fn(parser.GetRowValues(row)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// discoverRelations берёт внешние ключи выбранных таблиц из метаданных БД.
+func discoverRelations(ctx context.Context, src Source, tables []TableSpec) ([]Relation, error) {
+	inspector, ok := src.(tableInspector)
+	if !ok {
+		return nil, nil
+	}
+	var relations []Relation
+	for _, spec := range tables {
+		report, err := inspector.InspectTable(ctx, spec.Name)
+		if err != nil {
+			return nil, fmt.Errorf("inspect %s: %w", spec.Name, err)
+		}
+		for _, fk := range report.ForeignKeys {
+			relations = append(relations, Relation{
+				Table:     spec.Name,
+				Column:    fk.Column,
+				RefTable:  fk.ReferencesTable,
+				RefColumn: fk.ReferencesColumn,
+			})
+		}
+	}
+	return relations, nil
+}
+
+// closeOverRelations догружает строки, на которые ссылаются строки выборки,
+// до неподвижной точки: догруженные строки сами могут ссылаться дальше
+// (в том числе на свою же таблицу — иерархии).
+func closeOverRelations(ctx context.Context, src Source, byName map[string]*TableSample, relations []Relation) error {
+	for changed := true; changed; {
+		changed = false
+		for _, rel := range relations {
+			child, parent := byName[strings.ToLower(rel.Table)], byName[strings.ToLower(rel.RefTable)]
+			if child == nil || parent == nil {
+				continue
+			}
+			ci, pi := fieldIndex(child.Schema, rel.Column), fieldIndex(parent.Schema, rel.RefColumn)
+			if ci < 0 || pi < 0 {
+				return fmt.Errorf("relation %s.%s -> %s.%s: column not found in schema",
+					rel.Table, rel.Column, rel.RefTable, rel.RefColumn)
+			}
+
+			have := parent.values(pi)
+			var missing []string
+			for _, row := range child.Rows {
+				if ci >= len(row) || row[ci] == "" || have[row[ci]] {
+					continue
+				}
+				have[row[ci]] = true // запрошено — повторно не ищем, даже если не найдётся
+				missing = append(missing, row[ci])
+			}
+
+			for start := 0; start < len(missing); start += fetchChunkSize {
+				chunk := missing[start:min(start+fetchChunkSize, len(missing))]
+				rows, err := fetchRows(ctx, src, parent, rel.RefColumn, chunk)
+				if err != nil {
+					return fmt.Errorf("fetch %s rows referenced by %s.%s: %w", rel.RefTable, rel.Table, rel.Column, err)
+				}
+				if parent.addReferenced(rows) > 0 {
+					changed = true
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// values возвращает (и строит при первом обращении) множество значений колонки.
+func (ts *TableSample) values(col int) map[string]bool {
+	set, ok := ts.present[col]
+	if !ok {
+		set = make(map[string]bool, len(ts.Rows))
+		for _, row := range ts.Rows {
+			if col < len(row) {
+				set[row[col]] = true
+			}
+		}
+		ts.present[col] = set
+	}
+	return set
+}
+
+// addReferenced добавляет строки, которых ещё нет в выборке (по ключевым
+// полям схемы, а без них — по всей строке), и возвращает число добавленных.
+func (ts *TableSample) addReferenced(rows [][]string) int {
+	seen := make(map[string]bool, len(ts.Rows))
+	for _, row := range ts.Rows {
+		seen[ts.rowKey(row)] = true
+	}
+	added := 0
+	for _, row := range rows {
+		k := ts.rowKey(row)
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		ts.Rows = append(ts.Rows, row)
+		for col, set := range ts.present {
+			if col < len(row) {
+				set[row[col]] = true
+			}
+		}
+		added++
+	}
+	ts.Referenced += added
+	return added
+}
+
+func (ts *TableSample) rowKey(row []string) string {
+	var parts []string
+	for i, f := range ts.Schema.Fields {
+		if f.Key && i < len(row) {
+			parts = append(parts, row[i])
+		}
+	}
+	if len(parts) == 0 {
+		parts = row
+	}
+	return strings.Join(parts, "\x00")
+}
+
+// fetchRows читает строки таблицы, у которых column принимает одно из values.
+func fetchRows(ctx context.Context, src Source, ts *TableSample, column string, values []string) ([][]string, error) {
+	group := &packet.LogicalGroup{}
+	for _, v := range values {
+		group.Filters = append(group.Filters, packet.Filter{Field: column, Operator: "eq", Value: v})
+	}
+	query := packet.NewQuery()
+	query.Filters = &packet.Filters{Or: group}
+
+	packets, err := src.ExportTableWithQuery(ctx, ts.Table, query, "", "")
+	if err != nil {
+		return nil, err
+	}
+	var rows [][]string
+	err = eachPacketRow(packets, func(row []string) error {
+		rows = append(rows, row)
+		return nil
+	})
+	return rows, err
+}
+
+func fieldIndex(sch packet.Schema, name string) int {
+	for i, f := range sch.Fields {
+		if strings.EqualFold(f.Name, name) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,193 @@
+package sampling
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+	"github.com/ruslano69/tdtp-framework/pkg/core/tdtql"
+)
+
+// memSource — in-memory Source: таблицы как схема + строки.
+type memSource struct {
+	schemas map[string]packet.Schema
+	rows    map[string][][]string
+	queries int
+}
+
+func (m *memSource) GetTableSchema(_ context.Context, table string) (packet.Schema, error) {
+	sch, ok := m.schemas[table]
+	if !ok {
+		return packet.Schema{}, fmt.Errorf("table %s not found", table)
+	}
+	return sch, nil
+}
+
+func (m *memSource) ExportTable(_ context.Context, table string) ([]*packet.DataPacket, error) {
+	return packet.NewGenerator().GenerateReference(table, m.schemas[table], m.rows[table])
+}
+
+func (m *memSource) ExportTableWithQuery(_ context.Context, table string, query *packet.Query, _, _ string) ([]*packet.DataPacket, error) {
+	m.queries++
+	result, err := tdtql.NewExecutor().Execute(query, m.rows[table], m.schemas[table])
+	if err != nil {
+		return nil, err
+	}
+	return packet.NewGenerator().GenerateReference(table, m.schemas[table], result.FilteredRows)
+}
+
+func newShop() *memSource {
+	m := &memSource{
+		schemas: map[string]packet.Schema{
+			"countries": {Fields: []packet.Field{
+				{Name: "code", Type: "TEXT", Key: true},
+				{Name: "name", Type: "TEXT"},
+			}},
+			"customers": {Fields: []packet.Field{
+				{Name: "id", Type: "INTEGER", Key: true},
+				{Name: "country", Type: "TEXT"},
+				{Name: "referrer_id", Type: "INTEGER"},
+			}},
+			"orders": {Fields: []packet.Field{
+				{Name: "id", Type: "INTEGER", Key: true},
+				{Name: "customer_id", Type: "INTEGER"},
+				{Name: "status", Type: "TEXT"},
+			}},
+		},
+		rows: map[string][][]string{},
+	}
+	for i, c := range []string{"RU", "KZ", "BY", "AM"} {
+		m.rows["countries"] = append(m.rows["countries"], []string{c, fmt.Sprintf("Country %d", i)})
+	}
+	for i := 1; i <= 50; i++ {
+		referrer := ""
+		if i > 1 {
+			referrer = fmt.Sprint(i - 1) // цепочка рефералов: 50 → 49 → … → 1
+		}
+		m.rows["customers"] = append(m.rows["customers"],
+			[]string{fmt.Sprint(i), []string{"RU", "KZ", "BY"}[i%3], referrer})
+	}
+	for i := 1; i <= 200; i++ {
+		status := "done"
+		if i%10 == 0 {
+			status = "refund"
+		}
+		m.rows["orders"] = append(m.rows["orders"], []string{fmt.Sprint(i), fmt.Sprint(i%50 + 1), status})
+	}
+	return m
+}
+
+var shopRelations = []Relation{
+	{Table: "orders", Column: "customer_id", RefTable: "customers", RefColumn: "id"},
+	{Table: "customers", Column: "country", RefTable: "countries", RefColumn: "code"},
+	{Table: "customers", Column: "referrer_id", RefTable: "customers", RefColumn: "id"},
+}
+
+func sampleByName(t *testing.T, samples []*TableSample) map[string]*TableSample {
+	t.Helper()
+	out := make(map[string]*TableSample, len(samples))
+	for _, s := range samples {
+		out[s.Table] = s
+	}
+	return out
+}
+
+func TestSample_ReferentialIntegrity(t *testing.T) {
+	src := newShop()
+	samples, err := Sample(context.Background(), src, Config{
+		Tables: []TableSpec{
+			{Name: "orders", Rows: 5},
+			{Name: "customers"},
+			{Name: "countries"},
+		},
+		Relations: shopRelations,
+		Seed:      7,
+	})
+	if err != nil {
+		t.Fatalf("Sample: %v", err)
+	}
+	got := sampleByName(t, samples)
+
+	if n := got["orders"].Sampled; n != 5 || len(got["orders"].Rows) != 5 {
+		t.Fatalf("orders: sampled %d, rows %d, want 5", n, len(got["orders"].Rows))
+	}
+	if got["customers"].Sampled != 0 || got["customers"].Referenced == 0 {
+		t.Errorf("customers: sampled %d, referenced %d", got["customers"].Sampled, got["customers"].Referenced)
+	}
+
+	// Каждая ссылка выборки разрешается внутри выборки.
+	for _, rel := range shopRelations {
+		child, parent := got[rel.Table], got[rel.RefTable]
+		ci, pi := fieldIndex(child.Schema, rel.Column), fieldIndex(parent.Schema, rel.RefColumn)
+		keys := map[string]bool{}
+		for _, row := range parent.Rows {
+			keys[row[pi]] = true
+		}
+		for _, row := range child.Rows {
+			if row[ci] != "" && !keys[row[ci]] {
+				t.Errorf("%s.%s=%s has no row in %s", rel.Table, rel.Column, row[ci], rel.RefTable)
+			}
+		}
+	}
+
+	// Цепочка рефералов замыкается до первого клиента.
+	ids := map[string]bool{}
+	for _, row := range got["customers"].Rows {
+		if ids[row[0]] {
+			t.Errorf("customer %s duplicated", row[0])
+		}
+		ids[row[0]] = true
+	}
+	if !ids["1"] {
+		t.Error("referral chain not closed: customer 1 missing")
+	}
+	if len(got["countries"].Rows) > 3 {
+		t.Errorf("countries: %d rows, AM is never referenced", len(got["countries"].Rows))
+	}
+}
+
+func TestSample_SeedIsReproducible(t *testing.T) {
+	cfg := Config{Tables: []TableSpec{{Name: "orders", Rows: 10}}, Seed: 42}
+	a, err := Sample(context.Background(), newShop(), cfg)
+	if err != nil {
+		t.Fatalf("Sample: %v", err)
+	}
+	b, _ := Sample(context.Background(), newShop(), cfg)
+	for i := range a[0].Rows {
+		if a[0].Rows[i][0] != b[0].Rows[i][0] {
+			t.Fatalf("row %d differs: %v vs %v", i, a[0].Rows[i], b[0].Rows[i])
+		}
+	}
+}
+
+func TestSample_Stratified(t *testing.T) {
+	samples, err := Sample(context.Background(), newShop(), Config{
+		Tables: []TableSpec{{Name: "orders", Rows: 10, StratifyBy: "status"}},
+		Seed:   1,
+	})
+	if err != nil {
+		t.Fatalf("Sample: %v", err)
+	}
+	counts := map[string]int{}
+	for _, row := range samples[0].Rows {
+		counts[row[2]]++
+	}
+	// 180 done / 20 refund → 9 / 1.
+	if counts["done"] != 9 || counts["refund"] != 1 {
+		t.Errorf("strata = %v, want done:9 refund:1", counts)
+	}
+}
+
+func TestSample_Errors(t *testing.T) {
+	src := newShop()
+	if _, err := Sample(context.Background(), src, Config{}); err == nil {
+		t.Error("expected error for empty config")
+	}
+	_, err := Sample(context.Background(), src, Config{
+		Tables: []TableSpec{{Name: "orders", Rows: 1, StratifyBy: "nope"}},
+	})
+	if err == nil {
+		t.Error("expected error for unknown stratify_by field")
+	}
+}
@@ -0,0 +1,254 @@
+package security
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+	"gopkg.in/yaml.v3"
+)
+
+// ErrPolicyViolation — запрос явно обращается к колонке, которую политика
+// не разрешает выдавать получателю.
+var ErrPolicyViolation = errors.New("export policy violation")
+
+// PolicyAction — что делать с колонкой, не разрешённой получателю.
+type PolicyAction string
+
+const (
+	PolicyDrop PolicyAction = "drop" // колонка удаляется из схемы и строк (по умолчанию)
+	PolicyMask PolicyAction = "mask" // значения заменяются на PolicyMaskValue
+)
+
+// PolicyMaskValue — значение маскированной колонки.
+const PolicyMaskValue = "***"
+
+// ExportPolicy — политика выдачи колонок по системам-получателям
+// (согласие субъекта, минимизация данных).
+//
+//	tables:
+//	  customers:
+//	    columns:
+//	      email: { allow: [crm, billing] }          # остальным — drop
+//	      phone: { allow: [crm], action: mask }
+//	      notes: { allow: ["*"] }
+//
+// Колонки, не упомянутые в политике, выдаются всем. Получатель сверяется с
+// Header.Recipient пакета; пустой получатель не входит ни в один список,
+// кроме "*". Имена таблиц и колонок сравниваются без учёта регистра.
+type ExportPolicy struct {
+	Tables map[string]TableExportPolicy `yaml:"tables"`
+}
+
+// TableExportPolicy — политика колонок одной таблицы.
+type TableExportPolicy struct {
+	Columns map[string]ColumnExportPolicy `yaml:"columns"`
+}
+
+// ColumnExportPolicy — разрешённые получатели колонки.
+type ColumnExportPolicy struct {
+	Allow  []string     `yaml:"allow"`
+	Action PolicyAction `yaml:"action,omitempty"`
+}
+
+// PolicyReport — что политика сделала с пакетами.
+type PolicyReport struct {
+	Dropped      []string // удалённые колонки
+	Masked       []string // маскированные колонки
+	MaskedValues int
+}
+
+// LoadExportPolicy читает политику из YAML-файла.
+func LoadExportPolicy(path string) (*ExportPolicy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read export policy: %w", err)
+	}
+	var p ExportPolicy
+	if err := yaml.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("failed to parse export policy: %w", err)
+	}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Validate проверяет действия колонок.
+func (p *ExportPolicy) Validate() error {
+	for table, tp := range p.Tables {
+		for column, cp := range tp.Columns {
+			switch cp.Action {
+			case "", PolicyDrop, PolicyMask:
+			default:
+				return fmt.Errorf("export policy %s.%s: unknown action %q (expected drop or mask)", table, column, cp.Action)
+			}
+		}
+	}
+	return nil
+}
+
+// restricted возвращает колонки таблицы (в нижнем регистре), не разрешённые
+// получателю, и действие для каждой.
+func (p *ExportPolicy) restricted(table, recipient string) map[string]PolicyAction {
+	var tp *TableExportPolicy
+	for name, t := range p.Tables {
+		if strings.EqualFold(name, table) {
+			tp = &t
+			break
+		}
+	}
+	if tp == nil {
+		return nil
+	}
+	denied := make(map[string]PolicyAction)
+	for column, cp := range tp.Columns {
+		if slices.Contains(cp.Allow, "*") || (recipient != "" && slices.ContainsFunc(cp.Allow, func(a string) bool {
+			return strings.EqualFold(a, recipient)
+		})) {
+			continue
+		}
+		action := cp.Action
+		if action == "" {
+			action = PolicyDrop
+		}
+		denied[strings.ToLower(column)] = action
+	}
+	return denied
+}
+
+// CheckQuery отклоняет запрос, который явно называет запрещённую получателю
+// колонку — в проекции, фильтре или сортировке (фильтр по скрытой колонке
+// раскрывает её значения так же, как выборка). Возвращает ошибку,
+// оборачивающую ErrPolicyViolation.
+func (p *ExportPolicy) CheckQuery(table, recipient string, query *packet.Query) error {
+	if query == nil {
+		return nil
+	}
+	denied := p.restricted(table, recipient)
+	if len(denied) == 0 {
+		return nil
+	}
+
+	var names []string
+	names = append(names, query.Fields...)
+	if query.Filters != nil {
+		names = appendFilterFields(names, query.Filters.And)
+		names = appendFilterFields(names, query.Filters.Or)
+	}
+	if query.OrderBy != nil {
+		names = append(names, query.OrderBy.Field)
+		for _, f := range query.OrderBy.Fields {
+			names = append(names, f.Name)
+		}
+	}
+
+	var violations []string
+	for _, name := range names {
+		if _, ok := denied[strings.ToLower(name)]; ok && !slices.Contains(violations, name) {
+			violations = append(violations, name)
+		}
+	}
+	if len(violations) > 0 {
+		return fmt.Errorf("%w: recipient %q is not allowed to receive %s.%s",
+			ErrPolicyViolation, recipient, table, strings.Join(violations, ", "))
+	}
+	return nil
+}
+
+func appendFilterFields(names []string, group *packet.LogicalGroup) []string {
+	if group == nil {
+		return names
+	}
+	for _, f := range group.Filters {
+		names = append(names, f.Field)
+	}
+	for i := range group.And {
+		names = appendFilterFields(names, &group.And[i])
+	}
+	for i := range group.Or {
+		names = appendFilterFields(names, &group.Or[i])
+	}
+	return names
+}
+
+// Apply удаляет или маскирует колонки пакета, не разрешённые его
+// получателю (Header.Recipient). Пакет должен быть несжатым.
+func (p *ExportPolicy) Apply(pkt *packet.DataPacket, report *PolicyReport) error {
+	denied := p.restricted(pkt.Header.TableName, pkt.Header.Recipient)
+	if len(denied) == 0 {
+		return nil
+	}
+
+	var keep []int
+	masked := make(map[int]bool)
+	for i, f := range pkt.Schema.Fields {
+		switch denied[strings.ToLower(f.Name)] {
+		case PolicyDrop:
+			addName(&report.Dropped, f.Name)
+			continue
+		case PolicyMask:
+			masked[i] = true
+			addName(&report.Masked, f.Name)
+		}
+		keep = append(keep, i)
+	}
+	if len(keep) == len(pkt.Schema.Fields) && len(masked) == 0 {
+		return nil
+	}
+	if pkt.Data.Compression != "" {
+		return fmt.Errorf("export policy: packet must be decompressed before filtering")
+	}
+	if pkt.Data.Delta || pkt.Data.Delete {
+		return fmt.Errorf("export policy is not supported for delta and delete packets")
+	}
+
+	pkt.MaterializeRows()
+	parser := packet.NewParser()
+	for r, row := range pkt.Data.Rows {
+		values := parser.GetRowValues(row)
+		out := make([]string, 0, len(keep))
+		for _, i := range keep {
+			v := ""
+			if i < len(values) {
+				v = values[i]
+			}
+			if masked[i] && v != "" && v != nullMarker(pkt.Schema.Fields[i]) {
+				v = PolicyMaskValue
+				report.MaskedValues++
+			}
+			out = append(out, v)
+		}
+		pkt.Data.Rows[r] = packet.Row{Value: packet.JoinRowEscaped(out)}
+	}
+
+	fields := make([]packet.Field, 0, len(keep))
+	for _, i := range keep {
+		f := pkt.Schema.Fields[i]
+		if masked[i] {
+			// Маска не укладывается в числовые/датовые типы.
+			f.Type, f.Length, f.Precision, f.Scale, f.Subtype = "TEXT", 0, 0, 0, ""
+		}
+		fields = append(fields, f)
+	}
+	pkt.Schema.Fields = fields
+	return nil
+}
+
+func nullMarker(f packet.Field) string {
+	if f.SpecialValues != nil && f.SpecialValues.Null != nil {
+		return f.SpecialValues.Null.Marker
+	}
+	return ""
+}
+
+func addName(names *[]string, name string) {
+	if !slices.Contains(*names, name) {
+		*names = append(*names, name)
+		sort.Strings(*names)
+	}
+}
@@ -0,0 +1,108 @@
+package security
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+func testExportPolicy() *ExportPolicy {
+	return &ExportPolicy{Tables: map[string]TableExportPolicy{
+		"Customers": {Columns: map[string]ColumnExportPolicy{
+			"email": {Allow: []string{"crm", "billing"}},
+			"phone": {Allow: []string{"crm"}, Action: PolicyMask},
+			"notes": {Allow: []string{"*"}},
+		}},
+	}}
+}
+
+func customersPacket(t *testing.T, recipient string) *packet.DataPacket {
+	t.Helper()
+	schema := packet.Schema{Fields: []packet.Field{
+		{Name: "id", Type: "INTEGER", Key: true},
+		{Name: "email", Type: "TEXT"},
+		{Name: "phone", Type: "INTEGER"},
+		{Name: "notes", Type: "TEXT"},
+	}}
+	packets, err := packet.NewGenerator().GenerateReference("customers", schema, [][]string{
+		{"1", "a@x.io", "5551234", "vip"},
+		{"2", "b@x.io", "", ""},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	packets[0].Header.Recipient = recipient
+	return packets[0]
+}
+
+func TestExportPolicy_Apply(t *testing.T) {
+	policy := testExportPolicy()
+
+	var report PolicyReport
+	pkt := customersPacket(t, "analytics")
+	if err := policy.Apply(pkt, &report); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	var names []string
+	for _, f := range pkt.Schema.Fields {
+		names = append(names, f.Name+":"+f.Type)
+	}
+	if got := strings.Join(names, ","); got != "id:INTEGER,phone:TEXT,notes:TEXT" {
+		t.Errorf("schema = %s", got)
+	}
+	if pkt.Data.Rows[0].Value != "1|***|vip" || pkt.Data.Rows[1].Value != "2||" {
+		t.Errorf("rows = %v", pkt.Data.Rows)
+	}
+	if len(report.Dropped) != 1 || len(report.Masked) != 1 || report.MaskedValues != 1 {
+		t.Errorf("report = %+v", report)
+	}
+
+	// crm разрешены все колонки
+	pkt = customersPacket(t, "CRM")
+	if err := policy.Apply(pkt, &PolicyReport{}); err != nil {
+		t.Fatal(err)
+	}
+	pkt.MaterializeRows()
+	if len(pkt.Schema.Fields) != 4 || pkt.Data.Rows[0].Value != "1|a@x.io|5551234|vip" {
+		t.Errorf("crm packet changed: %v", pkt.Data.Rows[0])
+	}
+}
+
+func TestExportPolicy_CheckQuery(t *testing.T) {
+	policy := testExportPolicy()
+
+	query := packet.NewQuery()
+	query.Fields = []string{"id", "Email"}
+	if err := policy.CheckQuery("customers", "analytics", query); !errors.Is(err, ErrPolicyViolation) {
+		t.Errorf("projection: expected policy violation, got %v", err)
+	}
+	if err := policy.CheckQuery("customers", "billing", query); err != nil {
+		t.Errorf("billing projection: %v", err)
+	}
+
+	query = packet.NewQuery()
+	query.Filters = &packet.Filters{And: &packet.LogicalGroup{Or: []packet.LogicalGroup{
+		{Filters: []packet.Filter{{Field: "phone", Operator: "like", Value: "555%"}}},
+	}}}
+	if err := policy.CheckQuery("customers", "billing", query); !errors.Is(err, ErrPolicyViolation) {
+		t.Errorf("filter: expected policy violation, got %v", err)
+	}
+	if err := policy.CheckQuery("orders", "billing", query); err != nil {
+		t.Errorf("table without policy: %v", err)
+	}
+}
+
+func TestLoadExportPolicy(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	data := "tables:\n  customers:\n    columns:\n      email: { allow: [crm], action: hide }\n"
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadExportPolicy(path); err == nil {
+		t.Error("expected error for unknown action")
+	}
+}
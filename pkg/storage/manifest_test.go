@@ -0,0 +1,147 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// memStore is an in-memory ObjectStorage for tests.
+type memStore struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func newMemStore() *memStore { return &memStore{objects: map[string][]byte{}} }
+
+func (m *memStore) Put(_ context.Context, key string, r io.Reader, _ map[string]string) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.objects[key] = data
+	return nil
+}
+
+func (m *memStore) Get(_ context.Context, key string) (io.ReadCloser, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.objects[key]
+	if !ok {
+		return nil, fmt.Errorf("no such key %s", key)
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (m *memStore) Stat(_ context.Context, key string) (*ObjectInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.objects[key]
+	if !ok {
+		return nil, fmt.Errorf("no such key %s", key)
+	}
+	return &ObjectInfo{Key: key, Size: int64(len(data))}, nil
+}
+
+func (m *memStore) List(_ context.Context, prefix string) ([]ObjectInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []ObjectInfo
+	for k, v := range m.objects {
+		if strings.HasPrefix(k, prefix) {
+			out = append(out, ObjectInfo{Key: k, Size: int64(len(v))})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	return out, nil
+}
+
+func (m *memStore) Delete(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.objects, key)
+	return nil
+}
+
+func (m *memStore) Close() error { return nil }
+
+func TestManifestRecorder_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	store := newMemStore()
+	rec := NewManifestRecorder(store)
+
+	for _, n := range []int{10, 2, 1} {
+		key := fmt.Sprintf("land/orders_part_%d_of_10.xml", n)
+		body := fmt.Sprintf("<packet part=%d/>", n)
+		if err := rec.Put(ctx, key, strings.NewReader(body), map[string]string{"rows": "5"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := rec.SetStatistics(map[string]int64{"row_count": 15}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rec.WriteManifest(ctx, "land/orders.xml", "orders"); err != nil {
+		t.Fatal(err)
+	}
+
+	m, sum, err := ReadManifest(ctx, store, "land/orders.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sum) != 64 || m.Table != "orders" || len(m.Objects) != 3 {
+		t.Fatalf("manifest = %+v, sum = %q", m, sum)
+	}
+	var stats map[string]int64
+	if err := json.Unmarshal(m.Statistics, &stats); err != nil || stats["row_count"] != 15 {
+		t.Errorf("statistics = %s (%v)", m.Statistics, err)
+	}
+	if m.Objects[0].Key != "land/orders_part_1_of_10.xml" || m.Objects[2].Key != "land/orders_part_10_of_10.xml" {
+		t.Errorf("objects not in part order: %+v", m.Objects)
+	}
+	for _, o := range m.Objects {
+		if o.Rows != 5 {
+			t.Errorf("%s: rows = %d", o.Key, o.Rows)
+		}
+		if err := o.Verify(store.objects[o.Key]); err != nil {
+			t.Errorf("Verify(%s): %v", o.Key, err)
+		}
+	}
+
+	tampered := append([]byte(nil), store.objects[m.Objects[0].Key]...)
+	tampered[1] = 'X'
+	if err := m.Objects[0].Verify(tampered); err == nil {
+		t.Error("Verify() of tampered object: expected error")
+	}
+}
+
+func TestReceipt_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	store := newMemStore()
+
+	if r, err := ReadReceipt(ctx, store, "land/orders.xml"); r != nil || err != nil {
+		t.Fatalf("missing receipt: r = %v, err = %v", r, err)
+	}
+	want := &Receipt{ManifestKey: ManifestKey("land/orders.xml"), ManifestSHA256: "abc", Target: "postgres:orders", Rows: 15}
+	if err := PutReceipt(ctx, store, "land/orders.xml", want); err != nil {
+		t.Fatal(err)
+	}
+	// Prefix match alone must not count as the receipt
+	if ok, _ := Exists(ctx, store, "land/orders.xml"); ok {
+		t.Error("Exists() matched a longer key")
+	}
+	got, err := ReadReceipt(ctx, store, "land/orders.xml")
+	if err != nil || got == nil {
+		t.Fatalf("ReadReceipt() = %v, %v", got, err)
+	}
+	if got.ManifestSHA256 != "abc" || got.Target != "postgres:orders" || got.Rows != 15 {
+		t.Errorf("receipt = %+v", got)
+	}
+}
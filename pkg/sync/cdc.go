@@ -0,0 +1,196 @@
+package sync
+
+import (
+	"fmt"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+// Захват изменений (CDC) через логическую репликацию.
+//
+// Отслеживание по TrackingField не видит строк, изменённых без обновления
+// updated_at, и удалений. Стратегия TrackingCDC читает поток изменений из
+// слота логической репликации источника (PostgreSQL: wal2json): каждое
+// INSERT/UPDATE/DELETE приходит в порядке фиксации транзакций. Контрольная
+// точка — LSN последней прочитанной транзакции (SyncState.LastSyncValue).
+//
+// Доставка «хотя бы один раз»: источник только просматривает изменения
+// слота (peek), а сдвигает слот до сохранённой контрольной точки в начале
+// следующего чтения — после того как вызывающий записал пакеты и обновил
+// состояние. Повтор пачки после сбоя безопасен: upsert и удаление по
+// ключу идемпотентны.
+
+// ChangeOp — вид изменения строки.
+type ChangeOp string
+
+const (
+	// ChangeInsert - INSERT
+	ChangeInsert ChangeOp = "insert"
+	// ChangeUpdate - UPDATE
+	ChangeUpdate ChangeOp = "update"
+	// ChangeDelete - DELETE
+	ChangeDelete ChangeOp = "delete"
+)
+
+// ChangeColumn — значение колонки в изменении, уже в формате TDTP.
+type ChangeColumn struct {
+	Name  string
+	Value string
+}
+
+// Change — одно изменение строки из потока CDC.
+type Change struct {
+	LSN string // позиция изменения в журнале источника
+	Op  ChangeOp
+
+	// Columns - новая версия строки (insert, update)
+	Columns []ChangeColumn
+
+	// Identity - прежние значения REPLICA IDENTITY: ключевые колонки,
+	// при REPLICA IDENTITY FULL — вся строка (update, delete)
+	Identity []ChangeColumn
+}
+
+// changeState — итоговое состояние строки после свёртки пачки изменений.
+type changeState struct {
+	key     []string // значения ключевых полей
+	row     []string
+	deleted bool
+}
+
+// ChangePackets сворачивает изменения одной таблицы (в порядке фиксации)
+// в пакеты: для каждого ключа остаётся последнее состояние — строка для
+// upsert (пакеты данных) или удаление (пакеты удаления в конце). Ключи
+// пакетов данных и удаления не пересекаются, поэтому порядок применения в
+// приёмнике (base.SplitDeletePackets: сначала данные) не меняет результат.
+//
+// UPDATE с изменением первичного ключа даёт удаление старого ключа и
+// строку с новым. Колонку, которой нет в изменении (TOAST-значение, не
+// менявшееся в UPDATE), берёт из Identity или из более раннего изменения
+// той же строки в пачке; иначе — ошибка: нужен REPLICA IDENTITY FULL.
+func ChangePackets(tableName string, schema packet.Schema, changes []Change) ([]*packet.DataPacket, error) {
+	var keyIdx []int
+	for i, f := range schema.Fields {
+		if f.Key {
+			keyIdx = append(keyIdx, i)
+		}
+	}
+	if len(keyIdx) == 0 {
+		return nil, fmt.Errorf("CDC for %s requires key fields in schema", tableName)
+	}
+
+	var order []string
+	states := make(map[string]*changeState)
+	set := func(key string, st *changeState) {
+		if _, ok := states[key]; !ok {
+			order = append(order, key)
+		}
+		states[key] = st
+	}
+
+	for _, ch := range changes {
+		switch ch.Op {
+		case ChangeDelete:
+			cols := ch.Identity
+			if len(cols) == 0 {
+				cols = ch.Columns
+			}
+			key, values, err := changeKey(schema, keyIdx, cols)
+			if err != nil {
+				return nil, fmt.Errorf("delete at %s: %w", ch.LSN, err)
+			}
+			set(key, &changeState{key: values, deleted: true})
+
+		case ChangeInsert, ChangeUpdate:
+			newKey, newValues, err := changeKey(schema, keyIdx, ch.Columns)
+			if err != nil {
+				return nil, fmt.Errorf("%s at %s: %w", ch.Op, ch.LSN, err)
+			}
+			var prev []string
+			if ch.Op == ChangeUpdate && len(ch.Identity) > 0 {
+				if oldKey, oldValues, err := changeKey(schema, keyIdx, ch.Identity); err == nil && oldKey != newKey {
+					if st := states[oldKey]; st != nil {
+						prev = st.row
+					}
+					set(oldKey, &changeState{key: oldValues, deleted: true})
+				}
+			}
+			if st := states[newKey]; st != nil && st.row != nil {
+				prev = st.row
+			}
+			row, err := changeRow(schema, ch, prev)
+			if err != nil {
+				return nil, err
+			}
+			set(newKey, &changeState{key: newValues, row: row})
+
+		default:
+			return nil, fmt.Errorf("unknown change operation %q at %s", ch.Op, ch.LSN)
+		}
+	}
+
+	var rows, deleted [][]string
+	for _, key := range order {
+		st := states[key]
+		if !st.deleted {
+			rows = append(rows, st.row)
+			continue
+		}
+		deleted = append(deleted, st.key)
+	}
+
+	var packets []*packet.DataPacket
+	if len(rows) > 0 {
+		var err error
+		packets, err = packet.NewGenerator().GenerateReference(tableName, schema, rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate packets: %w", err)
+		}
+	}
+	tombstones, err := TombstonePackets(tableName, schema, deleted)
+	if err != nil {
+		return nil, err
+	}
+	return append(packets, tombstones...), nil
+}
+
+// changeKey возвращает значения ключевых полей из колонок изменения (в
+// порядке схемы) и их JoinRowEscaped — ключ свёртки.
+func changeKey(schema packet.Schema, keyIdx []int, cols []ChangeColumn) (string, []string, error) {
+	key := make([]string, len(keyIdx))
+	for j, i := range keyIdx {
+		v, ok := changeValue(cols, schema.Fields[i].Name)
+		if !ok {
+			return "", nil, fmt.Errorf("key field '%s' missing from change (check REPLICA IDENTITY)", schema.Fields[i].Name)
+		}
+		key[j] = v
+	}
+	return packet.JoinRowEscaped(key), key, nil
+}
+
+// changeRow собирает строку в порядке схемы: Columns, затем Identity,
+// затем prev — предыдущая версия строки в той же пачке.
+func changeRow(schema packet.Schema, ch Change, prev []string) ([]string, error) {
+	row := make([]string, len(schema.Fields))
+	for i, f := range schema.Fields {
+		if v, ok := changeValue(ch.Columns, f.Name); ok {
+			row[i] = v
+		} else if v, ok := changeValue(ch.Identity, f.Name); ok {
+			row[i] = v
+		} else if prev != nil {
+			row[i] = prev[i]
+		} else {
+			return nil, fmt.Errorf("%s at %s: column '%s' missing from change (unchanged TOAST value?); set REPLICA IDENTITY FULL on the table", ch.Op, ch.LSN, f.Name)
+		}
+	}
+	return row, nil
+}
+
+func changeValue(cols []ChangeColumn, name string) (string, bool) {
+	for _, c := range cols {
+		if c.Name == name {
+			return c.Value, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,101 @@
+package sync
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+var cdcSchema = packet.Schema{Fields: []packet.Field{
+	{Name: "id", Type: "INTEGER", Key: true},
+	{Name: "name", Type: "TEXT"},
+	{Name: "note", Type: "TEXT"},
+}}
+
+func cols(kv ...string) []ChangeColumn {
+	out := make([]ChangeColumn, 0, len(kv)/2)
+	for i := 0; i+1 < len(kv); i += 2 {
+		out = append(out, ChangeColumn{Name: kv[i], Value: kv[i+1]})
+	}
+	return out
+}
+
+// TestChangePackets_CommitOrder — для каждого ключа остаётся последнее
+// состояние: удаление и повторная вставка дают строку, вставка и
+// удаление — пакет удаления.
+func TestChangePackets_CommitOrder(t *testing.T) {
+	changes := []Change{
+		{LSN: "0/1", Op: ChangeInsert, Columns: cols("id", "1", "name", "Alice", "note", "a")},
+		{LSN: "0/2", Op: ChangeUpdate, Columns: cols("id", "1", "name", "Alicia", "note", "b"), Identity: cols("id", "1")},
+		{LSN: "0/3", Op: ChangeDelete, Identity: cols("id", "2")},
+		{LSN: "0/4", Op: ChangeInsert, Columns: cols("id", "2", "name", "Bob", "note", "")},
+		{LSN: "0/5", Op: ChangeInsert, Columns: cols("id", "3", "name", "Carol", "note", "")},
+		{LSN: "0/6", Op: ChangeDelete, Identity: cols("id", "3")},
+	}
+	packets, err := ChangePackets("users", cdcSchema, changes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(packets) != 2 {
+		t.Fatalf("packets = %d, want data + tombstone", len(packets))
+	}
+	want := [][]string{{"1", "Alicia", "b"}, {"2", "Bob", ""}}
+	if rows := packets[0].GetRows(); !reflect.DeepEqual(rows, want) {
+		t.Errorf("rows = %v, want %v", rows, want)
+	}
+	keys, err := packets[1].DeleteKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !packets[1].Data.Delete || !reflect.DeepEqual(keys, [][]string{{"3"}}) {
+		t.Errorf("tombstone keys = %v", keys)
+	}
+}
+
+// TestChangePackets_KeyChange — UPDATE первичного ключа: удаление старого
+// ключа и строка с новым; колонка без значения (TOAST) берётся из
+// предыдущей версии строки в пачке.
+func TestChangePackets_KeyChange(t *testing.T) {
+	changes := []Change{
+		{LSN: "0/1", Op: ChangeInsert, Columns: cols("id", "1", "name", "Alice", "note", "long")},
+		{LSN: "0/2", Op: ChangeUpdate, Columns: cols("id", "10", "name", "Alice"), Identity: cols("id", "1")},
+	}
+	packets, err := ChangePackets("users", cdcSchema, changes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rows := packets[0].GetRows(); !reflect.DeepEqual(rows, [][]string{{"10", "Alice", "long"}}) {
+		t.Errorf("rows = %v", rows)
+	}
+	keys, _ := packets[1].DeleteKeys()
+	if !reflect.DeepEqual(keys, [][]string{{"1"}}) {
+		t.Errorf("tombstone keys = %v", keys)
+	}
+}
+
+func TestChangePackets_Errors(t *testing.T) {
+	update := []Change{{LSN: "0/1", Op: ChangeUpdate, Columns: cols("id", "1", "name", "x"), Identity: cols("id", "1")}}
+	if _, err := ChangePackets("users", cdcSchema, update); err == nil {
+		t.Error("missing column without REPLICA IDENTITY FULL: expected error")
+	}
+	noKey := packet.Schema{Fields: []packet.Field{{Name: "id", Type: "INTEGER"}}}
+	if _, err := ChangePackets("users", noKey, nil); err == nil {
+		t.Error("schema without key fields: expected error")
+	}
+}
+
+func TestIncrementalConfig_CDC(t *testing.T) {
+	cfg := IncrementalConfig{Enabled: true, Mode: SyncModeIncremental, Strategy: TrackingCDC}
+	if err := cfg.Validate(); err == nil {
+		t.Error("cdc without slot: expected error")
+	}
+	cfg.Slot = "tdtp_users"
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("cdc with slot: %v", err)
+	}
+	cfg.Deletes = DeleteDetection{Strategy: DeleteSoft, Field: "deleted_at"}
+	if err := cfg.Validate(); err == nil {
+		t.Error("cdc with delete detection: expected error")
+	}
+}
@@ -23,6 +23,11 @@ const (
 	TrackingSequence TrackingStrategy = "sequence"
 	// TrackingVersion - отслеживание по version field
 	TrackingVersion TrackingStrategy = "version"
+	// TrackingCDC - поток изменений из слота логической репликации (cdc.go)
+	TrackingCDC TrackingStrategy = "cdc"
+	// TrackingChangeTable - SQL Server Change Tracking (CHANGETABLE):
+	// контрольная точка — версия отслеживания изменений
+	TrackingChangeTable TrackingStrategy = "changetable"
 )
 
 // IncrementalConfig содержит конфигурацию для инкрементальной синхронизации
@@ -38,8 +43,13 @@ type IncrementalConfig struct {
 
 	// TrackingField - имя поля для отслеживания изменений
 	// Примеры: "updated_at", "modified_at", "id", "version"
+	// Для TrackingCDC и TrackingChangeTable не используется
 	TrackingField string
 
+	// Slot - TrackingCDC: имя слота логической репликации источника
+	// (создаётся при первом чтении). Один слот на таблицу
+	Slot string
+
 	// StateFile - путь к файлу с состоянием синхронизации
 	// Если не указан, используется "./sync_state.json"
 	StateFile string
@@ -54,6 +64,10 @@ type IncrementalConfig struct {
 	// OrderBy - направление сортировки (ASC или DESC)
 	// По умолчанию ASC для правильной последовательности
 	OrderBy string
+
+	// Deletes - перенос удалённых в источнике строк пакетами удаления
+	// (tombstones, см. tombstone.go). По умолчанию удаления не переносятся
+	Deletes DeleteDetection
 }
 
 // Validate проверяет корректность конфигурации
@@ -66,16 +80,25 @@ func (c *IncrementalConfig) Validate() error {
 		return fmt.Errorf("invalid sync mode: %s (supported: full, incremental)", c.Mode)
 	}
 
-	if c.TrackingField == "" {
-		return fmt.Errorf("tracking_field is required for incremental sync")
-	}
-
 	if c.Strategy == "" {
 		c.Strategy = TrackingTimestamp // По умолчанию timestamp
 	}
 
-	if c.Strategy != TrackingTimestamp && c.Strategy != TrackingSequence && c.Strategy != TrackingVersion {
-		return fmt.Errorf("invalid tracking strategy: %s (supported: timestamp, sequence, version)", c.Strategy)
+	switch c.Strategy {
+	case TrackingCDC, TrackingChangeTable:
+		// Изменения и удаления приходят из журнала источника
+		if c.Strategy == TrackingCDC && c.Slot == "" {
+			return fmt.Errorf("slot is required for cdc tracking")
+		}
+		if c.Deletes.Strategy != DeleteNone {
+			return fmt.Errorf("delete detection is not used with %s tracking: deletes come from the change stream", c.Strategy)
+		}
+	case TrackingTimestamp, TrackingSequence, TrackingVersion:
+		if c.TrackingField == "" {
+			return fmt.Errorf("tracking_field is required for incremental sync")
+		}
+	default:
+		return fmt.Errorf("invalid tracking strategy: %s (supported: timestamp, sequence, version, cdc, changetable)", c.Strategy)
 	}
 
 	if c.StateFile == "" {
@@ -90,6 +113,10 @@ func (c *IncrementalConfig) Validate() error {
 		return fmt.Errorf("invalid order_by: %s (supported: ASC, DESC)", c.OrderBy)
 	}
 
+	if err := c.Deletes.Validate(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -72,6 +72,25 @@ func TestIncrementalConfig_Validate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "change tracking without tracking field",
+			config: IncrementalConfig{
+				Enabled:  true,
+				Mode:     SyncModeIncremental,
+				Strategy: TrackingChangeTable,
+			},
+			wantErr: false,
+		},
+		{
+			name: "change tracking with delete detection",
+			config: IncrementalConfig{
+				Enabled:  true,
+				Mode:     SyncModeIncremental,
+				Strategy: TrackingChangeTable,
+				Deletes:  DeleteDetection{Strategy: DeleteSoft, Field: "is_deleted"},
+			},
+			wantErr: true,
+		},
 		{
 			name: "invalid order by",
 			config: IncrementalConfig{
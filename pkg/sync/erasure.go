@@ -0,0 +1,251 @@
+package sync
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+// Стирание данных субъекта (GDPR, «право на забвение») во всех копиях таблицы.
+//
+// Ключи субъекта задаются явно или TDTQL-фильтром по источнику; из них
+// строится пакет удаления (packet.NewDeletePacket), который применяется к
+// каждому приёмнику. После удаления приёмник перечитывается по тем же
+// ключам — квитанция фиксирует, что строк не осталось. Сами значения ключей
+// в квитанцию не попадают (только SHA-256 набора): квитанция хранится
+// дольше, чем данные субъекта.
+
+// ErasureSource — то, что нужно от источника для поиска ключей субъекта
+// (реализуется adapters.Adapter).
+type ErasureSource interface {
+	GetTableSchema(ctx context.Context, tableName string) (packet.Schema, error)
+	ExportTableWithQuery(ctx context.Context, tableName string, query *packet.Query, sender, recipient string) ([]*packet.DataPacket, error)
+}
+
+// ErasureTarget — копия таблицы, из которой стираются данные: адаптеры
+// SQLite/PostgreSQL/MySQL/MS SQL (ApplyDelete — base.DeleteApplier).
+type ErasureTarget interface {
+	ApplyDelete(ctx context.Context, packets []*packet.DataPacket) (int, error)
+	ExportTableWithQuery(ctx context.Context, tableName string, query *packet.Query, sender, recipient string) ([]*packet.DataPacket, error)
+}
+
+// NamedErasureTarget — приёмник с именем для квитанции.
+type NamedErasureTarget struct {
+	Name   string
+	Target ErasureTarget
+}
+
+// ErasureRequest — запрос на стирание.
+type ErasureRequest struct {
+	Table string
+	Keys  [][]string    // значения первичного ключа (в порядке ключевых полей схемы)
+	Query *packet.Query // либо фильтр, находящий строки субъекта в источнике
+
+	SubjectRef string // внешний идентификатор обращения (номер заявки), не PII
+	Reason     string
+}
+
+// ErasureReceipt — доказательство выполнения стирания.
+type ErasureReceipt struct {
+	SubjectRef  string                 `json:"subject_ref,omitempty"`
+	Reason      string                 `json:"reason,omitempty"`
+	Table       string                 `json:"table"`
+	KeyFields   []string               `json:"key_fields"`
+	KeyCount    int                    `json:"key_count"`
+	KeysDigest  string                 `json:"keys_digest"`
+	StartedAt   time.Time              `json:"started_at"`
+	CompletedAt time.Time              `json:"completed_at"`
+	Complete    bool                   `json:"complete"`
+	Targets     []ErasureTargetReceipt `json:"targets"`
+}
+
+// ErasureTargetReceipt — результат по одному приёмнику.
+//
+// Proof — SHA-256 от KeysDigest, имени приёмника и результата: связывает
+// запись о приёмнике с набором ключей, не раскрывая их.
+type ErasureTargetReceipt struct {
+	Name        string    `json:"name"`
+	RowsDeleted int       `json:"rows_deleted"`
+	Remaining   int       `json:"remaining"`
+	Verified    bool      `json:"verified"`
+	Error       string    `json:"error,omitempty"`
+	CompletedAt time.Time `json:"completed_at"`
+	Proof       string    `json:"proof"`
+}
+
+// erasureVerifyBatch — ключей в одном проверочном запросе.
+const erasureVerifyBatch = 100
+
+// BuildErasurePacket строит пакет удаления по запросу: ключевые поля берутся
+// из схемы источника, ключи — из req.Keys или строк источника по req.Query.
+func BuildErasurePacket(ctx context.Context, source ErasureSource, req ErasureRequest) (*packet.DataPacket, error) {
+	if req.Table == "" {
+		return nil, fmt.Errorf("erasure requires a table")
+	}
+	if (len(req.Keys) == 0) == (req.Query == nil) {
+		return nil, fmt.Errorf("erasure requires either keys or a filter")
+	}
+	schema, err := source.GetTableSchema(ctx, req.Table)
+	if err != nil {
+		return nil, fmt.Errorf("source schema: %w", err)
+	}
+
+	keys := req.Keys
+	if req.Query != nil {
+		if keys, err = findSubjectKeys(ctx, source, req.Table, schema, req.Query); err != nil {
+			return nil, err
+		}
+		if len(keys) == 0 {
+			return nil, fmt.Errorf("no rows of %s match the subject filter in source; pass the keys explicitly", req.Table)
+		}
+	}
+	return packet.NewDeletePacket(req.Table, schema, keys)
+}
+
+// findSubjectKeys выбирает значения ключа строк источника, подходящих под фильтр.
+func findSubjectKeys(ctx context.Context, source ErasureSource, table string, schema packet.Schema, query *packet.Query) ([][]string, error) {
+	packets, err := source.ExportTableWithQuery(ctx, table, query, "", "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to find subject rows: %w", err)
+	}
+	var keys [][]string
+	parser := packet.NewParser()
+	for _, pkt := range packets {
+		pkt.MaterializeRows()
+		idx, err := keyIndices(pkt.Schema, packet.ExtractKeyFields(schema))
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range pkt.Data.Rows {
+			values := parser.GetRowValues(row)
+			key := make([]string, len(idx))
+			for i, j := range idx {
+				if j < len(values) {
+					key[i] = values[j]
+				}
+			}
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+// Erase применяет пакет удаления к каждому приёмнику и проверяет результат.
+// Ошибка одного приёмника не останавливает остальные: квитанция отражает
+// состояние каждой копии, Complete — все копии подтверждены.
+func Erase(ctx context.Context, pkt *packet.DataPacket, targets []NamedErasureTarget, req ErasureRequest) (*ErasureReceipt, error) {
+	keys, err := pkt.DeleteKeys()
+	if err != nil {
+		return nil, err
+	}
+	keyFields := make([]string, len(pkt.Schema.Fields))
+	for i, f := range pkt.Schema.Fields {
+		keyFields[i] = f.Name
+	}
+
+	receipt := &ErasureReceipt{
+		SubjectRef: req.SubjectRef,
+		Reason:     req.Reason,
+		Table:      pkt.Header.TableName,
+		KeyFields:  keyFields,
+		KeyCount:   len(keys),
+		KeysDigest: erasureKeysDigest(pkt.Header.TableName, keyFields, keys),
+		StartedAt:  time.Now().UTC(),
+		Complete:   len(targets) > 0,
+	}
+
+	for _, t := range targets {
+		tr := ErasureTargetReceipt{Name: t.Name}
+		tr.RowsDeleted, err = t.Target.ApplyDelete(ctx, []*packet.DataPacket{pkt})
+		if err == nil {
+			tr.Remaining, err = countRemaining(ctx, t.Target, pkt.Header.TableName, keyFields, keys)
+		}
+		if err != nil {
+			tr.Error = err.Error()
+		}
+		tr.Verified = err == nil && tr.Remaining == 0
+		tr.CompletedAt = time.Now().UTC()
+		tr.Proof = erasureProof(receipt.KeysDigest, tr)
+		receipt.Complete = receipt.Complete && tr.Verified
+		receipt.Targets = append(receipt.Targets, tr)
+	}
+	receipt.CompletedAt = time.Now().UTC()
+	return receipt, nil
+}
+
+// WriteFile сохраняет квитанцию в JSON.
+func (r *ErasureReceipt) WriteFile(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal erasure receipt: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write erasure receipt: %w", err)
+	}
+	return nil
+}
+
+// countRemaining перечитывает приёмник по ключам (пачками, OR из AND-групп
+// по ключевым полям) и возвращает число найденных строк.
+func countRemaining(ctx context.Context, target ErasureTarget, table string, keyFields []string, keys [][]string) (int, error) {
+	remaining := 0
+	for start := 0; start < len(keys); start += erasureVerifyBatch {
+		or := &packet.LogicalGroup{}
+		for _, key := range keys[start:min(start+erasureVerifyBatch, len(keys))] {
+			and := packet.LogicalGroup{}
+			for i, name := range keyFields {
+				and.Filters = append(and.Filters, packet.Filter{Field: name, Operator: "eq", Value: key[i]})
+			}
+			or.And = append(or.And, and)
+		}
+		query := packet.NewQuery()
+		query.Filters = &packet.Filters{Or: or}
+
+		packets, err := target.ExportTableWithQuery(ctx, table, query, "", "")
+		if err != nil {
+			return 0, fmt.Errorf("verification query failed: %w", err)
+		}
+		for _, p := range packets {
+			p.MaterializeRows()
+			remaining += len(p.Data.Rows)
+		}
+	}
+	return remaining, nil
+}
+
+// erasureKeysDigest — SHA-256 таблицы, ключевых полей и отсортированных ключей.
+func erasureKeysDigest(table string, keyFields []string, keys [][]string) string {
+	encoded := make([]string, len(keys))
+	for i, k := range keys {
+		encoded[i] = packet.JoinRowEscaped(k)
+	}
+	sort.Strings(encoded)
+
+	h := sha256.New()
+	h.Write([]byte(table + "\n" + strings.Join(keyFields, "|") + "\n"))
+	for _, k := range encoded {
+		h.Write([]byte(k + "\n"))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func erasureProof(keysDigest string, tr ErasureTargetReceipt) string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{
+		keysDigest,
+		tr.Name,
+		strconv.Itoa(tr.RowsDeleted),
+		strconv.Itoa(tr.Remaining),
+		strconv.FormatBool(tr.Verified),
+		tr.CompletedAt.Format(time.RFC3339Nano),
+	}, "\n")))
+	return hex.EncodeToString(sum[:])
+}
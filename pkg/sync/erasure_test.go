@@ -0,0 +1,116 @@
+package sync
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+	"github.com/ruslano69/tdtp-framework/pkg/core/tdtql"
+)
+
+// erasureTable — fakeTable с фильтрацией TDTQL и удалением по ключу id.
+type erasureTable struct {
+	*fakeTable
+	ignoreDeletes bool // «сломанная» копия: удаление не доходит
+}
+
+func (e *erasureTable) ExportTableWithQuery(_ context.Context, table string, query *packet.Query, _, _ string) ([]*packet.DataPacket, error) {
+	rows, err := tdtql.NewExecutor().ExecuteWhere(query.Filters, e.rows, e.schema)
+	if err != nil {
+		return nil, err
+	}
+	return packet.NewGenerator().GenerateReference(table, e.schema, rows)
+}
+
+func (e *erasureTable) ApplyDelete(_ context.Context, packets []*packet.DataPacket) (int, error) {
+	deleted := 0
+	for _, pkt := range packets {
+		keys, err := pkt.DeleteKeys()
+		if err != nil {
+			return 0, err
+		}
+		if e.ignoreDeletes {
+			continue
+		}
+		for _, k := range keys {
+			for i, row := range e.rows {
+				if row[0] == k[0] {
+					e.rows = append(e.rows[:i], e.rows[i+1:]...)
+					deleted++
+					break
+				}
+			}
+		}
+	}
+	return deleted, nil
+}
+
+func TestBuildErasurePacket(t *testing.T) {
+	ctx := context.Background()
+	source := &erasureTable{fakeTable: newFakeTable(10)}
+
+	query := packet.NewQuery()
+	query.Filters = &packet.Filters{And: &packet.LogicalGroup{Filters: []packet.Filter{
+		{Field: "name", Operator: "eq", Value: "name 3"},
+	}}}
+	pkt, err := BuildErasurePacket(ctx, source, ErasureRequest{Table: "users", Query: query})
+	if err != nil {
+		t.Fatalf("BuildErasurePacket: %v", err)
+	}
+	keys, _ := pkt.DeleteKeys()
+	if len(keys) != 1 || keys[0][0] != "3" || len(pkt.Schema.Fields) != 1 {
+		t.Errorf("keys = %v, schema = %+v", keys, pkt.Schema.Fields)
+	}
+
+	query.Filters.And.Filters[0].Value = "nobody"
+	if _, err := BuildErasurePacket(ctx, source, ErasureRequest{Table: "users", Query: query}); err == nil {
+		t.Error("expected error when filter matches no rows")
+	}
+	if _, err := BuildErasurePacket(ctx, source, ErasureRequest{Table: "users"}); err == nil {
+		t.Error("expected error without keys and filter")
+	}
+}
+
+func TestErase(t *testing.T) {
+	ctx := context.Background()
+	replica := &erasureTable{fakeTable: newFakeTable(10)}
+	stale := &erasureTable{fakeTable: newFakeTable(10), ignoreDeletes: true}
+
+	req := ErasureRequest{Table: "users", Keys: [][]string{{"2"}, {"5"}, {"42"}}, SubjectRef: "DSR-1"}
+	pkt, err := BuildErasurePacket(ctx, replica, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	receipt, err := Erase(ctx, pkt, []NamedErasureTarget{{Name: "replica", Target: replica}}, req)
+	if err != nil {
+		t.Fatalf("Erase: %v", err)
+	}
+	tr := receipt.Targets[0]
+	if !receipt.Complete || tr.RowsDeleted != 2 || tr.Remaining != 0 || !tr.Verified || tr.Proof == "" {
+		t.Errorf("receipt = %+v", receipt)
+	}
+	if len(replica.rows) != 8 || receipt.KeyCount != 3 || receipt.KeysDigest == "" {
+		t.Errorf("rows left %d, receipt %+v", len(replica.rows), receipt)
+	}
+
+	// Повторная доставка безопасна; несработавшая копия видна в квитанции.
+	receipt, err = Erase(ctx, pkt, []NamedErasureTarget{
+		{Name: "replica", Target: replica},
+		{Name: "stale", Target: stale},
+	}, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if receipt.Complete || !receipt.Targets[0].Verified || receipt.Targets[0].RowsDeleted != 0 {
+		t.Errorf("replica receipt = %+v", receipt.Targets[0])
+	}
+	if receipt.Targets[1].Verified || receipt.Targets[1].Remaining != 2 {
+		t.Errorf("stale receipt = %+v", receipt.Targets[1])
+	}
+
+	if err := receipt.WriteFile(filepath.Join(t.TempDir(), "receipt.json")); err != nil {
+		t.Fatal(err)
+	}
+}
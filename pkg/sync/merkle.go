@@ -0,0 +1,339 @@
+package sync
+
+import (
+	"encoding/binary"
+	"encoding/gob"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/zeebo/xxh3"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+// Merkle-дерево хешей строк — для периодической сверки реплицированных таблиц.
+//
+// Строка попадает в лист по хешу первичного ключа, а не по диапазону ключей:
+// раскладка не зависит от числа строк и одинакова на обеих сторонах. Хеш
+// листа — сумма хешей его строк по модулю 2^64. Сумма коммутативна, поэтому
+// порядок чтения строк не важен, а Put/Delete (например, из потока CDC)
+// обновляют дерево без полного пересчёта.
+//
+// CompareMerkle спускается от корня только в различающиеся поддеревья; в
+// различающихся листьях сравниваются пары «хеш ключа → хеш строки». Лист
+// хранит и значения ключей: полные строки расходящихся ключей выбираются
+// по ключу, без повторного чтения таблицы (см. Reconcile).
+
+// DefaultMerkleDepth — глубина дерева по умолчанию: 4096 листьев.
+const DefaultMerkleDepth = 12
+
+// maxMerkleDepth ограничивает число листьев (и размер снимка) — 1M.
+const maxMerkleDepth = 20
+
+// MerkleTree — дерево хешей строк одной таблицы.
+type MerkleTree struct {
+	Table     string
+	KeyFields []string
+	Depth     int
+	// Tracked — значение TrackingField, по которое дерево актуально
+	// (ReconcileOptions.StateDir); пусто — дерево построено без него.
+	Tracked string
+
+	leaves []merkleLeaf
+	nodes  [][]uint64 // nodes[level][index]: level 0 — корень, level Depth — листья
+	dirty  bool
+}
+
+type merkleLeaf struct {
+	Sum  uint64
+	Rows map[uint64]uint64   // хеш ключа → хеш строки
+	Keys map[uint64][]string // хеш ключа → значения ключа
+}
+
+// NewMerkleTree создаёт пустое дерево. depth <= 0 — DefaultMerkleDepth.
+func NewMerkleTree(table string, keyFields []string, depth int) (*MerkleTree, error) {
+	if depth <= 0 {
+		depth = DefaultMerkleDepth
+	}
+	if depth > maxMerkleDepth {
+		return nil, fmt.Errorf("merkle depth %d exceeds maximum %d", depth, maxMerkleDepth)
+	}
+	if len(keyFields) == 0 {
+		return nil, fmt.Errorf("merkle tree for %s requires key fields", table)
+	}
+	return &MerkleTree{
+		Table:     table,
+		KeyFields: keyFields,
+		Depth:     depth,
+		leaves:    make([]merkleLeaf, 1<<depth),
+		dirty:     true,
+	}, nil
+}
+
+// MerkleKeyHash — хеш значений ключевых полей строки.
+func MerkleKeyHash(key []string) uint64 {
+	return xxh3.HashString(packet.JoinRowEscaped(key))
+}
+
+// merkleRowHash — хеш значений строки (в порядке схемы).
+func merkleRowHash(row []string) uint64 {
+	return xxh3.HashString(packet.JoinRowEscaped(row))
+}
+
+// merkleMix связывает хеш строки с её ключом: иначе одинаковые строки
+// под разными ключами дали бы одинаковый вклад в сумму листа.
+func merkleMix(keyHash, rowHash uint64) uint64 {
+	var b [16]byte
+	binary.LittleEndian.PutUint64(b[:8], keyHash)
+	binary.LittleEndian.PutUint64(b[8:], rowHash)
+	return xxh3.Hash(b[:])
+}
+
+func (t *MerkleTree) leafIndex(keyHash uint64) int {
+	return int(keyHash >> (64 - t.Depth))
+}
+
+// Put добавляет или заменяет строку с ключом key.
+func (t *MerkleTree) Put(key, row []string) {
+	keyHash, rowHash := MerkleKeyHash(key), merkleRowHash(row)
+	leaf := &t.leaves[t.leafIndex(keyHash)]
+	if leaf.Rows == nil {
+		leaf.Rows = make(map[uint64]uint64)
+	}
+	if leaf.Keys == nil {
+		leaf.Keys = make(map[uint64][]string)
+	}
+	if old, ok := leaf.Rows[keyHash]; ok {
+		if old == rowHash {
+			return
+		}
+		leaf.Sum -= merkleMix(keyHash, old)
+	}
+	leaf.Rows[keyHash] = rowHash
+	leaf.Keys[keyHash] = slices.Clone(key)
+	leaf.Sum += merkleMix(keyHash, rowHash)
+	t.dirty = true
+}
+
+// Delete удаляет строку с ключом key (если она есть).
+func (t *MerkleTree) Delete(key []string) {
+	keyHash := MerkleKeyHash(key)
+	leaf := &t.leaves[t.leafIndex(keyHash)]
+	if old, ok := leaf.Rows[keyHash]; ok {
+		leaf.Sum -= merkleMix(keyHash, old)
+		delete(leaf.Rows, keyHash)
+		delete(leaf.Keys, keyHash)
+		t.dirty = true
+	}
+}
+
+// Key возвращает значения ключа строки по хешу ключа.
+func (t *MerkleTree) Key(keyHash uint64) ([]string, bool) {
+	key, ok := t.leaves[t.leafIndex(keyHash)].Keys[keyHash]
+	return key, ok
+}
+
+// Has сообщает, есть ли в дереве строка с ключом key.
+func (t *MerkleTree) Has(key []string) bool {
+	keyHash := MerkleKeyHash(key)
+	_, ok := t.leaves[t.leafIndex(keyHash)].Rows[keyHash]
+	return ok
+}
+
+// keys вызывает fn для значений ключа каждой строки дерева.
+func (t *MerkleTree) keys(fn func(key []string)) {
+	for i := range t.leaves {
+		for _, key := range t.leaves[i].Keys {
+			fn(key)
+		}
+	}
+}
+
+// Len возвращает число строк в дереве.
+func (t *MerkleTree) Len() int {
+	n := 0
+	for i := range t.leaves {
+		n += len(t.leaves[i].Rows)
+	}
+	return n
+}
+
+// Root возвращает корневой хеш; равные корни — равные таблицы.
+func (t *MerkleTree) Root() uint64 {
+	return t.Node(0, 0)
+}
+
+// Node возвращает хеш узла: level 0 — корень, level Depth — листья.
+func (t *MerkleTree) Node(level, index int) uint64 {
+	t.rebuild()
+	return t.nodes[level][index]
+}
+
+// LeafRows возвращает пары «хеш ключа → хеш строки» листа (только чтение).
+func (t *MerkleTree) LeafRows(index int) map[uint64]uint64 {
+	return t.leaves[index].Rows
+}
+
+// rebuild пересчитывает внутренние узлы после изменений.
+func (t *MerkleTree) rebuild() {
+	if !t.dirty {
+		return
+	}
+	t.nodes = make([][]uint64, t.Depth+1)
+	level := make([]uint64, len(t.leaves))
+	var b [16]byte
+	for i, leaf := range t.leaves {
+		if len(leaf.Rows) == 0 {
+			continue // пустой лист — 0, чтобы пустые поддеревья совпадали дёшево
+		}
+		binary.LittleEndian.PutUint64(b[:8], leaf.Sum)
+		binary.LittleEndian.PutUint64(b[8:], uint64(len(leaf.Rows)))
+		level[i] = xxh3.Hash(b[:])
+	}
+	t.nodes[t.Depth] = level
+	for d := t.Depth - 1; d >= 0; d-- {
+		child := t.nodes[d+1]
+		parent := make([]uint64, len(child)/2)
+		for i := range parent {
+			l, r := child[2*i], child[2*i+1]
+			if l == 0 && r == 0 {
+				continue
+			}
+			binary.LittleEndian.PutUint64(b[:8], l)
+			binary.LittleEndian.PutUint64(b[8:], r)
+			parent[i] = xxh3.Hash(b[:])
+		}
+		t.nodes[d] = parent
+	}
+	t.dirty = false
+}
+
+// MerkleDiff — результат сравнения деревьев источника и приёмника.
+type MerkleDiff struct {
+	Missing []uint64 // хеши ключей строк, которых нет в приёмнике
+	Changed []uint64 // хеши ключей строк, отличающихся в приёмнике
+	Extra   []uint64 // хеши ключей строк, которых нет в источнике
+
+	NodesCompared  int // сравнено узлов (объём «обмена» деревьями)
+	LeavesDiverged int // различающихся листьев
+}
+
+// InSync сообщает, совпадают ли таблицы.
+func (d *MerkleDiff) InSync() bool {
+	return len(d.Missing) == 0 && len(d.Changed) == 0 && len(d.Extra) == 0
+}
+
+// CompareMerkle находит расхождения между деревьями source и target.
+func CompareMerkle(source, target *MerkleTree) (*MerkleDiff, error) {
+	if source.Depth != target.Depth {
+		return nil, fmt.Errorf("merkle depth mismatch: %d vs %d", source.Depth, target.Depth)
+	}
+
+	diff := &MerkleDiff{}
+	type node struct{ level, index int }
+	stack := []node{{0, 0}}
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		diff.NodesCompared++
+		if source.Node(n.level, n.index) == target.Node(n.level, n.index) {
+			continue
+		}
+		if n.level < source.Depth {
+			stack = append(stack, node{n.level + 1, 2*n.index + 1}, node{n.level + 1, 2 * n.index})
+			continue
+		}
+
+		diff.LeavesDiverged++
+		src, dst := source.LeafRows(n.index), target.LeafRows(n.index)
+		for k, h := range src {
+			switch th, ok := dst[k]; {
+			case !ok:
+				diff.Missing = append(diff.Missing, k)
+			case th != h:
+				diff.Changed = append(diff.Changed, k)
+			}
+		}
+		for k := range dst {
+			if _, ok := src[k]; !ok {
+				diff.Extra = append(diff.Extra, k)
+			}
+		}
+	}
+	return diff, nil
+}
+
+// merkleSnapshot — сериализуемая форма дерева.
+type merkleSnapshot struct {
+	Table     string
+	KeyFields []string
+	Depth     int
+	Tracked   string
+	Leaves    []merkleLeaf
+}
+
+// Save сохраняет снимок дерева: его можно поддерживать между сверками
+// через Put/Delete или передать другой стороне.
+func (t *MerkleTree) Save(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create merkle snapshot: %w", err)
+	}
+	snap := merkleSnapshot{Table: t.Table, KeyFields: t.KeyFields, Depth: t.Depth, Tracked: t.Tracked, Leaves: t.leaves}
+	if err := gob.NewEncoder(f).Encode(&snap); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to write merkle snapshot: %w", err)
+	}
+	return f.Close()
+}
+
+// LoadMerkleTree загружает снимок, сохранённый Save.
+func LoadMerkleTree(path string) (*MerkleTree, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open merkle snapshot: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var snap merkleSnapshot
+	if err := gob.NewDecoder(f).Decode(&snap); err != nil {
+		return nil, fmt.Errorf("failed to read merkle snapshot: %w", err)
+	}
+	t, err := NewMerkleTree(snap.Table, snap.KeyFields, snap.Depth)
+	if err != nil {
+		return nil, err
+	}
+	if len(snap.Leaves) != len(t.leaves) {
+		return nil, fmt.Errorf("corrupt merkle snapshot: %d leaves for depth %d", len(snap.Leaves), snap.Depth)
+	}
+	t.leaves, t.Tracked = snap.Leaves, snap.Tracked
+	return t, nil
+}
+
+// keyIndices возвращает индексы ключевых полей в схеме.
+func keyIndices(schema packet.Schema, keyFields []string) ([]int, error) {
+	idx := make([]int, len(keyFields))
+	for i, k := range keyFields {
+		idx[i] = -1
+		for j, f := range schema.Fields {
+			if strings.EqualFold(f.Name, k) {
+				idx[i] = j
+				break
+			}
+		}
+		if idx[i] < 0 {
+			return nil, fmt.Errorf("key field %s not found in schema", k)
+		}
+	}
+	return idx, nil
+}
+
+func rowKey(row []string, idx []int) []string {
+	key := make([]string, len(idx))
+	for i, j := range idx {
+		key[i] = row[j]
+	}
+	return key
+}
@@ -0,0 +1,291 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+	"github.com/ruslano69/tdtp-framework/pkg/core/tdtql"
+)
+
+// fakeTable — TableSource в памяти (через ExportTable).
+type fakeTable struct {
+	schema packet.Schema
+	rows   [][]string
+}
+
+func (f *fakeTable) GetTableSchema(context.Context, string) (packet.Schema, error) {
+	return f.schema, nil
+}
+
+func (f *fakeTable) ExportTable(_ context.Context, table string) ([]*packet.DataPacket, error) {
+	return packet.NewGenerator().GenerateReference(table, f.schema, f.rows)
+}
+
+func newFakeTable(n int) *fakeTable {
+	t := &fakeTable{schema: packet.Schema{Fields: []packet.Field{
+		{Name: "id", Type: "INTEGER", Key: true},
+		{Name: "name", Type: "TEXT"},
+	}}}
+	for i := range n {
+		t.rows = append(t.rows, []string{fmt.Sprint(i), fmt.Sprintf("name %d", i)})
+	}
+	return t
+}
+
+func TestMerkleTree_PutDelete(t *testing.T) {
+	a, err := NewMerkleTree("t", []string{"id"}, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := NewMerkleTree("t", []string{"id"}, 4)
+
+	// Порядок вставки не влияет на корень.
+	for i := range 50 {
+		a.Put([]string{fmt.Sprint(i)}, []string{fmt.Sprint(i), "x"})
+	}
+	for i := 49; i >= 0; i-- {
+		b.Put([]string{fmt.Sprint(i)}, []string{fmt.Sprint(i), "x"})
+	}
+	if a.Root() != b.Root() || a.Len() != 50 {
+		t.Fatalf("roots differ for the same rows (len %d)", a.Len())
+	}
+
+	root := a.Root()
+	a.Put([]string{"7"}, []string{"7", "changed"})
+	if a.Root() == root {
+		t.Error("root did not change after update")
+	}
+	a.Put([]string{"7"}, []string{"7", "x"})
+	if a.Root() != root {
+		t.Error("root not restored after reverting update")
+	}
+	a.Delete([]string{"7"})
+	if a.Root() == root || a.Len() != 49 {
+		t.Error("delete did not change tree")
+	}
+
+	if _, err := NewMerkleTree("t", nil, 4); err == nil {
+		t.Error("expected error without key fields")
+	}
+	if _, err := NewMerkleTree("t", []string{"id"}, maxMerkleDepth+1); err == nil {
+		t.Error("expected error for excessive depth")
+	}
+}
+
+func TestCompareMerkle(t *testing.T) {
+	src, _ := NewMerkleTree("t", []string{"id"}, 8)
+	dst, _ := NewMerkleTree("t", []string{"id"}, 8)
+	for i := range 1000 {
+		row := []string{fmt.Sprint(i), "v"}
+		src.Put(row[:1], row)
+		dst.Put(row[:1], row)
+	}
+
+	diff, err := CompareMerkle(src, dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !diff.InSync() || diff.NodesCompared != 1 {
+		t.Fatalf("identical trees: %+v", diff)
+	}
+
+	dst.Delete([]string{"10"})                   // missing
+	dst.Put([]string{"20"}, []string{"20", "w"}) // changed
+	dst.Put([]string{"5000"}, []string{"5000", "v"})
+
+	diff, err = CompareMerkle(src, dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(diff.Missing, []uint64{MerkleKeyHash([]string{"10"})}) ||
+		!slices.Equal(diff.Changed, []uint64{MerkleKeyHash([]string{"20"})}) ||
+		!slices.Equal(diff.Extra, []uint64{MerkleKeyHash([]string{"5000"})}) {
+		t.Errorf("unexpected diff: %+v", diff)
+	}
+	// Спуск только в расходящиеся ветви: ≤ 3 пути по 2 узла на уровень.
+	if limit := 1 + 3*2*8; diff.NodesCompared > limit {
+		t.Errorf("compared %d nodes, want ≤ %d", diff.NodesCompared, limit)
+	}
+
+	other, _ := NewMerkleTree("t", []string{"id"}, 6)
+	if _, err := CompareMerkle(src, other); err == nil {
+		t.Error("expected depth mismatch error")
+	}
+}
+
+func TestMerkleTree_SaveLoad(t *testing.T) {
+	tree, _ := NewMerkleTree("orders", []string{"id"}, 6)
+	for i := range 100 {
+		tree.Put([]string{fmt.Sprint(i)}, []string{fmt.Sprint(i), "row"})
+	}
+	path := filepath.Join(t.TempDir(), "orders.merkle")
+	if err := tree.Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	loaded, err := LoadMerkleTree(path)
+	if err != nil {
+		t.Fatalf("LoadMerkleTree: %v", err)
+	}
+	if loaded.Root() != tree.Root() || loaded.Len() != 100 || loaded.Table != "orders" {
+		t.Error("loaded tree differs from saved")
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	source := newFakeTable(500)
+	target := newFakeTable(500)
+	target.rows = slices.Delete(target.rows, 3, 4)          // id=3 missing
+	target.rows[10] = []string{target.rows[10][0], "stale"} // id=11 changed
+	target.rows = append(target.rows, []string{"9999", "orphan"})
+
+	result, err := Reconcile(context.Background(), source, target, "items", ReconcileOptions{Depth: 6})
+	if err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+	if result.SourceRows != 500 || result.TargetRows != 500 {
+		t.Errorf("rows: source %d, target %d", result.SourceRows, result.TargetRows)
+	}
+
+	var upsertIDs []string
+	for _, r := range result.Upserts {
+		upsertIDs = append(upsertIDs, r[0])
+	}
+	slices.Sort(upsertIDs)
+	if !slices.Equal(upsertIDs, []string{"11", "3"}) {
+		t.Errorf("upserts = %v", upsertIDs)
+	}
+	if len(result.Extras) != 1 || result.Extras[0][0] != "9999" {
+		t.Errorf("extras = %v", result.Extras)
+	}
+
+	// После применения корректировок таблицы совпадают.
+	target.rows = slices.DeleteFunc(target.rows, func(r []string) bool { return r[0] == "11" || r[0] == "9999" })
+	target.rows = append(target.rows, result.Upserts...)
+	result, err = Reconcile(context.Background(), source, target, "items", ReconcileOptions{Depth: 6})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Diff.InSync() {
+		t.Errorf("tables still differ: %+v", result.Diff)
+	}
+}
+
+func TestReconcile_SchemaMismatch(t *testing.T) {
+	source := newFakeTable(1)
+	target := newFakeTable(1)
+	target.schema.Fields[1].Name = "title"
+	if _, err := Reconcile(context.Background(), source, target, "items", ReconcileOptions{}); err == nil {
+		t.Error("expected schema mismatch error")
+	}
+}
+
+// queryTable — fakeTable, который выбирает строки запросом и считает
+// полные чтения таблицы.
+type queryTable struct {
+	*fakeTable
+	scans   int
+	queries int
+}
+
+func (q *queryTable) ExportTable(ctx context.Context, table string) ([]*packet.DataPacket, error) {
+	q.scans++
+	return q.fakeTable.ExportTable(ctx, table)
+}
+
+func (q *queryTable) ExportTableWithQuery(_ context.Context, table string, query *packet.Query, _, _ string) ([]*packet.DataPacket, error) {
+	q.queries++
+	res, err := tdtql.NewExecutor().Execute(query, q.rows, q.schema)
+	if err != nil {
+		return nil, err
+	}
+	return packet.NewGenerator().GenerateReference(table, res.Schema, res.Rows)
+}
+
+func newQueryTable(n int) *queryTable {
+	t := newFakeTable(n)
+	t.schema.Fields = append(t.schema.Fields, packet.Field{Name: "version", Type: "INTEGER"})
+	for i := range t.rows {
+		t.rows[i] = append(t.rows[i], "1")
+	}
+	return &queryTable{fakeTable: t}
+}
+
+// TestReconcile_FetchByKey — строки расходящихся ключей выбираются по
+// ключу после сравнения деревьев: каждая сторона читается целиком один раз.
+func TestReconcile_FetchByKey(t *testing.T) {
+	source, target := newQueryTable(300), newQueryTable(300)
+	target.rows[5] = []string{"5", "stale", "1"}
+	target.rows = append(target.rows, []string{"9999", "orphan", "1"})
+
+	result, err := Reconcile(context.Background(), source, target, "items", ReconcileOptions{Depth: 6})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if source.scans != 1 || target.scans != 1 {
+		t.Errorf("full scans: source %d, target %d; want 1 each", source.scans, target.scans)
+	}
+	if len(result.Upserts) != 1 || result.Upserts[0][1] != "name 5" {
+		t.Errorf("upserts = %v", result.Upserts)
+	}
+	if len(result.Extras) != 1 || result.Extras[0][0] != "9999" {
+		t.Errorf("extras = %v", result.Extras)
+	}
+}
+
+// TestReconcile_State — со StateDir деревья сохраняются и следующая сверка
+// обновляет их по TrackingField и ключам, не читая таблицы целиком.
+func TestReconcile_State(t *testing.T) {
+	ctx := context.Background()
+	source, target := newQueryTable(200), newQueryTable(200)
+	opts := ReconcileOptions{Depth: 6, StateDir: t.TempDir(), TrackingField: "version"}
+
+	result, err := Reconcile(ctx, source, target, "items", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Diff.InSync() || source.scans != 1 || target.scans != 1 {
+		t.Fatalf("first run: in sync %v, scans %d/%d", result.Diff.InSync(), source.scans, target.scans)
+	}
+
+	// Изменение, удаление и вставка «задним числом» (без нового version)
+	source.rows[7] = []string{"7", "renamed", "2"}
+	source.rows = slices.Delete(source.rows, 20, 21) // id=20
+	target.rows = append(target.rows, []string{"5000", "late", "1"})
+
+	result, err = Reconcile(ctx, source, target, "items", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if source.scans != 1 || target.scans != 1 {
+		t.Errorf("state run rescanned tables: %d/%d", source.scans, target.scans)
+	}
+	d := result.Diff
+	if len(d.Changed) != 1 || len(d.Missing) != 0 || len(d.Extra) != 2 {
+		t.Fatalf("diff = %+v", d)
+	}
+	if len(result.Upserts) != 1 || result.Upserts[0][1] != "renamed" {
+		t.Errorf("upserts = %v", result.Upserts)
+	}
+
+	// Применённые корректировки попадают в дерево приёмника
+	target.rows[7] = source.rows[7]
+	if err := result.MarkApplied(); err != nil {
+		t.Fatal(err)
+	}
+	target.rows = slices.DeleteFunc(target.rows, func(r []string) bool { return r[0] == "20" || r[0] == "5000" })
+	result, err = Reconcile(ctx, source, target, "items", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Diff.InSync() {
+		t.Errorf("tables still differ: %+v", result.Diff)
+	}
+
+	if _, err := Reconcile(ctx, source, target, "items", ReconcileOptions{StateDir: opts.StateDir}); err == nil {
+		t.Error("state without tracking field: want error")
+	}
+}
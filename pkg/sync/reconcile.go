@@ -0,0 +1,444 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+	"github.com/ruslano69/tdtp-framework/pkg/core/tdtql"
+)
+
+// TableSource — то, что сверке нужно от стороны (реализуется adapters.Adapter).
+// Если источник умеет StreamAllRows (SQLite/MySQL/MSSQL), таблица читается
+// построчно, иначе — через ExportTable.
+type TableSource interface {
+	GetTableSchema(ctx context.Context, tableName string) (packet.Schema, error)
+	ExportTable(ctx context.Context, tableName string) ([]*packet.DataPacket, error)
+}
+
+// querySource — источник, который выбирает строки запросом
+// (adapters.Adapter): строки расходящихся ключей читаются по ключу, а
+// сохранённое дерево (StateDir) обновляется без полного чтения таблицы.
+type querySource interface {
+	ExportTableWithQuery(ctx context.Context, tableName string, query *packet.Query, sender, recipient string) ([]*packet.DataPacket, error)
+}
+
+// reconcileKeysPerQuery ограничивает число ключей в одном запросе строк.
+const reconcileKeysPerQuery = 500
+
+// ReconcileOptions — параметры Merkle-сверки.
+type ReconcileOptions struct {
+	KeyFields []string // ключ (пусто — первичный ключ схемы источника)
+	Depth     int      // глубина дерева (0 — DefaultMerkleDepth)
+
+	// StateDir — каталог, где деревья сторон хранятся между сверками
+	// (<table>.source.merkle, <table>.target.merkle). Сохранённое дерево
+	// не строится заново, а обновляется: перечитываются строки, у которых
+	// TrackingField больше сохранённого значения, а удалённые и вставленные
+	// задним числом строки находятся по одним ключевым колонкам. Изменение
+	// строки без изменения TrackingField такое обновление не видит.
+	StateDir      string
+	TrackingField string // обязательно со StateDir
+}
+
+// ReconcileResult — расхождения и корректирующие строки.
+type ReconcileResult struct {
+	Schema packet.Schema
+	Diff   *MerkleDiff
+
+	SourceRows int
+	TargetRows int
+
+	// Upserts — строки источника для недостающих и отличающихся ключей:
+	// импорт в приёмник со StrategyReplace устраняет расхождение.
+	Upserts [][]string
+	// Extras — строки, которые есть только в приёмнике.
+	Extras [][]string
+
+	target    *MerkleTree
+	targetIdx []int
+	statePath string // файл дерева приёмника (StateDir)
+}
+
+// Reconcile сверяет таблицу источника и приёмника через Merkle-деревья и
+// собирает корректирующие строки. Деревья сравниваются до чтения строк:
+// полные строки выбираются только для расходящихся ключей, по ключу (у
+// источников без ExportTableWithQuery — одним проходом с фильтром). Со
+// StateDir деревья переживают сверку и обновляются инкрементально.
+// Значения сравниваются в TDTP-представлении, поэтому сверка работает и
+// между разными СУБД.
+func Reconcile(ctx context.Context, source, target TableSource, tableName string, opts ReconcileOptions) (*ReconcileResult, error) {
+	if opts.StateDir != "" && opts.TrackingField == "" {
+		return nil, fmt.Errorf("reconcile state requires a tracking field")
+	}
+	schema, err := source.GetTableSchema(ctx, tableName)
+	if err != nil {
+		return nil, fmt.Errorf("source schema: %w", err)
+	}
+	targetSchema, err := target.GetTableSchema(ctx, tableName)
+	if err != nil {
+		return nil, fmt.Errorf("target schema: %w", err)
+	}
+	if err := sameColumns(schema, targetSchema); err != nil {
+		return nil, fmt.Errorf("schema mismatch: %w", err)
+	}
+
+	keyFields := opts.KeyFields
+	if len(keyFields) == 0 {
+		keyFields = packet.ExtractKeyFields(schema)
+	}
+	idx, err := keyIndices(schema, keyFields)
+	if err != nil {
+		return nil, err
+	}
+
+	srcTree, err := sideTree(ctx, source, "source", tableName, schema, keyFields, opts)
+	if err != nil {
+		return nil, fmt.Errorf("source tree: %w", err)
+	}
+	dstTree, err := sideTree(ctx, target, "target", tableName, targetSchema, keyFields, opts)
+	if err != nil {
+		return nil, fmt.Errorf("target tree: %w", err)
+	}
+
+	diff, err := CompareMerkle(srcTree, dstTree)
+	if err != nil {
+		return nil, err
+	}
+	result := &ReconcileResult{
+		Schema:     schema,
+		Diff:       diff,
+		SourceRows: srcTree.Len(),
+		TargetRows: dstTree.Len(),
+		target:     dstTree,
+		targetIdx:  idx,
+	}
+
+	if want := hashSet(diff.Missing, diff.Changed); len(want) > 0 {
+		if result.Upserts, err = fetchRows(ctx, source, srcTree, tableName, schema, idx, want); err != nil {
+			return nil, fmt.Errorf("source rows: %w", err)
+		}
+	}
+	if want := hashSet(diff.Extra); len(want) > 0 {
+		if result.Extras, err = fetchRows(ctx, target, dstTree, tableName, targetSchema, idx, want); err != nil {
+			return nil, fmt.Errorf("target rows: %w", err)
+		}
+	}
+
+	if opts.StateDir != "" {
+		if err := srcTree.Save(reconcileStatePath(opts.StateDir, tableName, "source")); err != nil {
+			return nil, err
+		}
+		result.statePath = reconcileStatePath(opts.StateDir, tableName, "target")
+		if err := dstTree.Save(result.statePath); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// MarkApplied отмечает, что Upserts записаны в приёмник: сохранённое
+// дерево приёмника (StateDir) принимает их строки, иначе следующая сверка
+// не увидела бы записи с прежним значением TrackingField.
+func (r *ReconcileResult) MarkApplied() error {
+	if r.statePath == "" {
+		return nil
+	}
+	for _, row := range r.Upserts {
+		r.target.Put(rowKey(row, r.targetIdx), row)
+	}
+	return r.target.Save(r.statePath)
+}
+
+func reconcileStatePath(dir, tableName, side string) string {
+	return filepath.Join(dir, tableName+"."+side+".merkle")
+}
+
+// sideTree возвращает дерево стороны: построенное полным чтением таблицы
+// или, со StateDir, сохранённое и обновлённое инкрементально.
+func sideTree(ctx context.Context, src TableSource, side, tableName string, schema packet.Schema, keyFields []string, opts ReconcileOptions) (*MerkleTree, error) {
+	if opts.StateDir == "" {
+		return BuildMerkleTree(ctx, src, tableName, schema, keyFields, opts.Depth)
+	}
+	qs, ok := src.(querySource)
+	if !ok {
+		return nil, fmt.Errorf("%s does not support queries needed for reconcile state", side)
+	}
+	idx, err := keyIndices(schema, keyFields)
+	if err != nil {
+		return nil, err
+	}
+
+	tree, err := LoadMerkleTree(reconcileStatePath(opts.StateDir, tableName, side))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	depth := opts.Depth
+	if depth <= 0 {
+		depth = DefaultMerkleDepth
+	}
+	if tree != nil && (tree.Tracked == "" || tree.Depth != depth || !sameFieldNames(tree.KeyFields, keyFields)) {
+		tree = nil // снимок другой сверки или без отметки — строится заново
+	}
+
+	// Отметка берётся до чтения: строки, изменённые во время чтения,
+	// перечитает следующая сверка
+	mark, err := trackingMark(ctx, qs, tableName, opts.TrackingField)
+	if err != nil {
+		return nil, err
+	}
+	if tree == nil {
+		if tree, err = BuildMerkleTree(ctx, src, tableName, schema, keyFields, depth); err != nil {
+			return nil, err
+		}
+		tree.Tracked = mark
+		return tree, nil
+	}
+
+	// Изменённые строки
+	query := packet.NewQuery()
+	query.Filters = &packet.Filters{And: &packet.LogicalGroup{Filters: []packet.Filter{
+		{Field: opts.TrackingField, Operator: "gt", Value: tree.Tracked},
+	}}}
+	err = queryRows(ctx, qs, tableName, query, len(schema.Fields), func(row []string) error {
+		tree.Put(rowKey(row, idx), row)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("changed rows: %w", err)
+	}
+
+	// Удалённые и вставленные задним числом строки — по ключевым колонкам
+	seen := make(map[uint64]struct{}, tree.Len())
+	var added [][]string
+	query = packet.NewQuery()
+	query.Fields = keyFields
+	err = queryRows(ctx, qs, tableName, query, len(keyFields), func(key []string) error {
+		seen[MerkleKeyHash(key)] = struct{}{}
+		if !tree.Has(key) {
+			added = append(added, key)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("keys: %w", err)
+	}
+	var gone [][]string
+	tree.keys(func(key []string) {
+		if _, ok := seen[MerkleKeyHash(key)]; !ok {
+			gone = append(gone, key)
+		}
+	})
+	for _, key := range gone {
+		tree.Delete(key)
+	}
+	err = rowsByKeys(ctx, qs, tableName, schema, keyFields, added, func(row []string) error {
+		tree.Put(rowKey(row, idx), row)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("added rows: %w", err)
+	}
+	if mark != "" {
+		tree.Tracked = mark
+	}
+	return tree, nil
+}
+
+// trackingMark возвращает наибольшее значение trackingField в таблице
+// (пусто — таблица пуста).
+func trackingMark(ctx context.Context, src querySource, tableName, trackingField string) (string, error) {
+	query := packet.NewQuery()
+	query.Fields = []string{trackingField}
+	query.Filters = &packet.Filters{And: &packet.LogicalGroup{Filters: []packet.Filter{
+		{Field: trackingField, Operator: "is_not_null"},
+	}}}
+	query.OrderBy = &packet.OrderBy{Field: trackingField, Direction: "DESC"}
+	query.Limit = 1
+	var mark string
+	err := queryRows(ctx, src, tableName, query, 1, func(row []string) error {
+		mark = row[0]
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("tracking field %s: %w", trackingField, err)
+	}
+	return mark, nil
+}
+
+// BuildMerkleTree строит дерево по содержимому таблицы.
+func BuildMerkleTree(ctx context.Context, src TableSource, tableName string, schema packet.Schema, keyFields []string, depth int) (*MerkleTree, error) {
+	idx, err := keyIndices(schema, keyFields)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := NewMerkleTree(tableName, keyFields, depth)
+	if err != nil {
+		return nil, err
+	}
+	err = scanRows(ctx, src, tableName, schema, func(row []string) error {
+		tree.Put(rowKey(row, idx), row)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
+
+// fetchRows выбирает строки, хеши ключей которых входят в want: по
+// значениям ключей из дерева или, если источник не выбирает запросом,
+// одним проходом по таблице. Выбранные строки обновляют дерево, ключи без
+// строк (удалены после чтения дерева) из него удаляются.
+func fetchRows(ctx context.Context, src TableSource, tree *MerkleTree, tableName string, schema packet.Schema, idx []int, want map[uint64]struct{}) ([][]string, error) {
+	qs, ok := src.(querySource)
+	if !ok {
+		return collectRows(ctx, src, tableName, schema, idx, want)
+	}
+	keys := make([][]string, 0, len(want))
+	for h := range want {
+		if key, ok := tree.Key(h); ok {
+			keys = append(keys, key)
+		}
+	}
+	var rows [][]string
+	found := make(map[uint64]struct{}, len(want))
+	err := rowsByKeys(ctx, qs, tableName, schema, tree.KeyFields, keys, func(row []string) error {
+		key := rowKey(row, idx)
+		h := MerkleKeyHash(key)
+		if _, ok := want[h]; ok {
+			rows = append(rows, row)
+			found[h] = struct{}{}
+			tree.Put(key, row)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range keys {
+		if _, ok := found[MerkleKeyHash(key)]; !ok {
+			tree.Delete(key)
+		}
+	}
+	return rows, nil
+}
+
+// rowsByKeys выбирает строки с заданными значениями ключа — запросами по
+// reconcileKeysPerQuery ключей. Ключи сравниваются точно (binary), без
+// коллации по умолчанию.
+func rowsByKeys(ctx context.Context, src querySource, tableName string, schema packet.Schema, keyFields []string, keys [][]string, fn func(row []string) error) error {
+	for start := 0; start < len(keys); start += reconcileKeysPerQuery {
+		var match packet.LogicalGroup
+		for _, key := range keys[start:min(start+reconcileKeysPerQuery, len(keys))] {
+			eq := packet.LogicalGroup{Filters: make([]packet.Filter, len(keyFields))}
+			for i, field := range keyFields {
+				eq.Filters[i] = packet.Filter{Field: field, Operator: "eq", Value: key[i], Collation: tdtql.CollationBinary}
+			}
+			match.And = append(match.And, eq)
+		}
+		query := packet.NewQuery()
+		query.Filters = &packet.Filters{Or: &match}
+		if err := queryRows(ctx, src, tableName, query, len(schema.Fields), fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// queryRows выполняет запрос; fn получает строки, дополненные до n значений.
+func queryRows(ctx context.Context, src querySource, tableName string, query *packet.Query, n int, fn func(row []string) error) error {
+	packets, err := src.ExportTableWithQuery(ctx, tableName, query, "", "")
+	if err != nil {
+		return err
+	}
+	for _, pkt := range packets {
+		for _, row := range pkt.GetRows() {
+			if err := fn(padRow(row, n)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// collectRows выбирает строки, хеши ключей которых входят в want.
+func collectRows(ctx context.Context, src TableSource, tableName string, schema packet.Schema, idx []int, want map[uint64]struct{}) ([][]string, error) {
+	var rows [][]string
+	err := scanRows(ctx, src, tableName, schema, func(row []string) error {
+		if _, ok := want[MerkleKeyHash(rowKey(row, idx))]; ok {
+			rows = append(rows, row)
+		}
+		return nil
+	})
+	return rows, err
+}
+
+// scanRows читает все строки таблицы; fn получает значения в порядке схемы.
+func scanRows(ctx context.Context, src TableSource, tableName string, schema packet.Schema, fn func(row []string) error) error {
+	if s, ok := src.(interface {
+		StreamAllRows(ctx context.Context, tableName string, schema packet.Schema, fn func(row []string) error) error
+	}); ok {
+		return s.StreamAllRows(ctx, tableName, schema, func(row []string) error {
+			return fn(padRow(row, len(schema.Fields)))
+		})
+	}
+
+	packets, err := src.ExportTable(ctx, tableName)
+	if err != nil {
+		return err
+	}
+	parser := packet.NewParser()
+	for _, pkt := range packets {
+		pkt.MaterializeRows()
+		for _, r := range pkt.Data.Rows {
+			if err := fn(padRow(parser.GetRowValues(r), len(schema.Fields))); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// padRow дополняет короткую строку пустыми значениями (как при импорте).
+func padRow(row []string, n int) []string {
+	for len(row) < n {
+		row = append(row, "")
+	}
+	return row
+}
+
+func hashSet(lists ...[]uint64) map[uint64]struct{} {
+	set := make(map[uint64]struct{})
+	for _, l := range lists {
+		for _, h := range l {
+			set[h] = struct{}{}
+		}
+	}
+	return set
+}
+
+// sameFieldNames сравнивает списки имён полей без учёта регистра.
+func sameFieldNames(a, b []string) bool {
+	return slices.EqualFunc(a, b, strings.EqualFold)
+}
+
+// sameColumns проверяет, что колонки совпадают по именам и порядку:
+// хеш строки зависит от порядка значений. Типы не сравниваются — у разных
+// СУБД они разные, а значения сравниваются в TDTP-представлении.
+func sameColumns(a, b packet.Schema) error {
+	if len(a.Fields) != len(b.Fields) {
+		return fmt.Errorf("different number of fields: %d vs %d", len(a.Fields), len(b.Fields))
+	}
+	for i := range a.Fields {
+		if !strings.EqualFold(a.Fields[i].Name, b.Fields[i].Name) {
+			return fmt.Errorf("field name mismatch at position %d: %s vs %s", i, a.Fields[i].Name, b.Fields[i].Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,397 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"github.com/ruslano69/tdtp-framework/pkg/audit"
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+// Планировщик инкрементальных синхронизаций.
+//
+// Задачи запускаются по cron-выражениям (5 полей или @hourly, @daily,
+// @every 15m). Запуск задачи, предыдущий запуск которой ещё идёт,
+// пропускается (защита от наложения). Jitter добавляет случайную задержку,
+// чтобы задачи с одинаковым расписанием не нагружали источник одновременно.
+// Состояние каждой задачи хранится в отдельном файле StateManager, каждый
+// запуск и пропуск записывается в аудит (audit.OpSync).
+
+// ErrJobRunning — предыдущий запуск задачи ещё не завершён.
+var ErrJobRunning = errors.New("sync job is already running")
+
+// SyncFunc выполняет одну синхронизацию от контрольной точки state и
+// возвращает новую. Состояние сохраняет планировщик: после ошибки
+// контрольная точка не меняется, и следующий запуск повторяет чтение.
+type SyncFunc func(ctx context.Context, state *SyncState) (SyncResult, error)
+
+// SyncResult — итог одного запуска задачи.
+type SyncResult struct {
+	LastSyncValue   string // новая контрольная точка
+	LastDeleteValue string // контрольная точка журнала удалений (пусто — не менять)
+	Records         int64  // перенесено записей
+
+	// Schema — схема источника, с которой прошёл запуск (nil — не менять
+	// сохранённую): следующий запуск сравнивает с ней (DiffSchema)
+	Schema *packet.Schema
+
+	// More — партия заполнена до BatchSize, за ней могут быть ещё изменения
+	// (учитывается при CommitInterval > 0)
+	More bool
+}
+
+// ScheduledJob — синхронизация по расписанию.
+type ScheduledJob struct {
+	// Name — уникальное имя задачи: ключ аудита и имя файла состояния
+	Name string `yaml:"name"`
+
+	// Schedule — cron-выражение: "*/15 * * * *", "@hourly", "@every 10m"
+	Schedule string `yaml:"schedule"`
+
+	// Table — ключ состояния в StateManager (по умолчанию Name)
+	Table string `yaml:"table"`
+
+	// StateFile — файл состояния (по умолчанию <StateDir>/<Name>.json)
+	StateFile string `yaml:"state_file"`
+
+	// Jitter — случайная задержка запуска от 0 до Jitter
+	Jitter time.Duration `yaml:"jitter"`
+
+	// CommitInterval > 0 — запуск дочитывает накопившиеся изменения
+	// партиями (Run повторяется, пока SyncResult.More), а контрольная точка
+	// сохраняется после партии, если с прошлого сохранения прошло
+	// CommitInterval, и после последней партии. 0 — одна партия за запуск.
+	CommitInterval time.Duration `yaml:"commit_interval"`
+
+	// Run — сама синхронизация
+	Run SyncFunc `yaml:"-"`
+}
+
+// SchedulerConfig — параметры планировщика.
+type SchedulerConfig struct {
+	// StateDir — каталог файлов состояния задач (по умолчанию ".")
+	StateDir string
+
+	// Location — часовой пояс расписаний (по умолчанию time.Local)
+	Location *time.Location
+
+	// Audit — журнал запусков (nil — без аудита)
+	Audit audit.Logger
+
+	// OnSkip вызывается, когда запуск задачи пропущен из-за наложения
+	// (например, для счётчика метрик)
+	OnSkip func(job string)
+}
+
+// Scheduler запускает задачи синхронизации по расписанию.
+type Scheduler struct {
+	cron   *cron.Cron
+	config SchedulerConfig
+
+	mu      sync.Mutex
+	jobs    map[string]*scheduledEntry
+	baseCtx context.Context
+	cancel  context.CancelFunc
+}
+
+// scheduledEntry — зарегистрированная задача.
+type scheduledEntry struct {
+	job     ScheduledJob
+	state   *StateManager
+	entryID cron.EntryID
+	running bool
+}
+
+// jobNamePattern — допустимые имена задач (имя становится именем файла).
+var jobNamePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// NewScheduler создаёт планировщик. Задачи добавляются Add, запуск — Start.
+func NewScheduler(config SchedulerConfig) *Scheduler {
+	if config.StateDir == "" {
+		config.StateDir = "."
+	}
+	if config.Location == nil {
+		config.Location = time.Local
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		cron:    cron.New(cron.WithLocation(config.Location)),
+		config:  config,
+		jobs:    make(map[string]*scheduledEntry),
+		baseCtx: ctx,
+		cancel:  cancel,
+	}
+}
+
+// Add регистрирует задачу: проверяет расписание и открывает файл состояния.
+func (s *Scheduler) Add(job ScheduledJob) error {
+	if !jobNamePattern.MatchString(job.Name) {
+		return fmt.Errorf("invalid sync job name %q (letters, digits, '_', '.', '-')", job.Name)
+	}
+	if job.Run == nil {
+		return fmt.Errorf("sync job %s: Run is required", job.Name)
+	}
+	if job.Jitter < 0 {
+		return fmt.Errorf("sync job %s: jitter must not be negative", job.Name)
+	}
+	if job.CommitInterval < 0 {
+		return fmt.Errorf("sync job %s: commit interval must not be negative", job.Name)
+	}
+	schedule, err := cron.ParseStandard(job.Schedule)
+	if err != nil {
+		return fmt.Errorf("sync job %s: invalid schedule %q: %w", job.Name, job.Schedule, err)
+	}
+	if job.Table == "" {
+		job.Table = job.Name
+	}
+	if job.StateFile == "" {
+		if err := os.MkdirAll(s.config.StateDir, 0o750); err != nil {
+			return fmt.Errorf("failed to create state directory: %w", err)
+		}
+		job.StateFile = filepath.Join(s.config.StateDir, job.Name+".json")
+	}
+	state, err := NewStateManager(job.StateFile, true)
+	if err != nil {
+		return fmt.Errorf("sync job %s: %w", job.Name, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.jobs[job.Name]; exists {
+		return fmt.Errorf("sync job %s is already registered", job.Name)
+	}
+	entry := &scheduledEntry{job: job, state: state}
+	entry.entryID = s.cron.Schedule(schedule, cron.FuncJob(func() {
+		s.mu.Lock()
+		ctx := s.baseCtx
+		s.mu.Unlock()
+		_ = s.run(ctx, entry, true) //nolint:errcheck // результат записан в состояние и аудит
+	}))
+	s.jobs[job.Name] = entry
+	return nil
+}
+
+// Start запускает планировщик. Отмена ctx прерывает выполняющиеся задачи
+// и останавливает планировщик.
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	s.baseCtx, s.cancel = context.WithCancel(ctx)
+	runCtx := s.baseCtx
+	s.mu.Unlock()
+
+	s.cron.Start()
+	go func() {
+		<-runCtx.Done()
+		s.cron.Stop()
+	}()
+}
+
+// Stop останавливает планировщик и ждёт завершения выполняющихся задач
+// (не дольше, чем живёт ctx). Новые запуски не начинаются.
+func (s *Scheduler) Stop(ctx context.Context) error {
+	done := s.cron.Stop()
+	select {
+	case <-done.Done():
+		s.mu.Lock()
+		s.cancel()
+		s.mu.Unlock()
+		return nil
+	case <-ctx.Done():
+		// Прерываем выполняющиеся задачи
+		s.mu.Lock()
+		s.cancel()
+		s.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// RunNow выполняет задачу немедленно, вне расписания и без jitter.
+// Если задача уже выполняется, возвращает ErrJobRunning.
+func (s *Scheduler) RunNow(ctx context.Context, name string) error {
+	s.mu.Lock()
+	entry, ok := s.jobs[name]
+	s.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("sync job %s is not registered", name)
+	}
+	return s.run(ctx, entry, false)
+}
+
+// NextRun возвращает время следующего запуска задачи по расписанию
+// (нулевое, если планировщик не запущен).
+func (s *Scheduler) NextRun(name string) (time.Time, bool) {
+	s.mu.Lock()
+	entry, ok := s.jobs[name]
+	s.mu.Unlock()
+	if !ok {
+		return time.Time{}, false
+	}
+	return s.cron.Entry(entry.entryID).Next, true
+}
+
+// State возвращает текущее состояние синхронизации задачи.
+func (s *Scheduler) State(name string) (*SyncState, bool) {
+	s.mu.Lock()
+	entry, ok := s.jobs[name]
+	s.mu.Unlock()
+	if !ok {
+		return nil, false
+	}
+	return entry.state.GetState(entry.job.Table), true
+}
+
+// run выполняет задачу, если она не выполняется, и сохраняет результат.
+func (s *Scheduler) run(ctx context.Context, entry *scheduledEntry, scheduled bool) error {
+	job := entry.job
+	s.mu.Lock()
+	if entry.running {
+		s.mu.Unlock()
+		if s.config.OnSkip != nil {
+			s.config.OnSkip(job.Name)
+		}
+		s.audit(ctx, audit.NewEntry(audit.OpSync, audit.StatusSkipped).
+			WithResource(job.Table).
+			WithMetadata("job", job.Name).
+			WithMetadata("reason", "previous run still in progress"))
+		return fmt.Errorf("sync job %s: %w", job.Name, ErrJobRunning)
+	}
+	entry.running = true
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		entry.running = false
+		s.mu.Unlock()
+	}()
+
+	if scheduled && job.Jitter > 0 {
+		delay := time.Duration(rand.Int63n(int64(job.Jitter))) //nolint:gosec // math/rand is fine for jitter
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+
+	start := time.Now()
+	result, err := s.runBatches(ctx, entry)
+	if err != nil {
+		if stateErr := entry.state.UpdateStateWithError(job.Table, err); stateErr != nil {
+			err = fmt.Errorf("%w (failed to save error state: %v)", err, stateErr)
+		}
+	}
+
+	status := audit.StatusSuccess
+	if err != nil {
+		status = audit.StatusFailure
+	}
+	record := audit.NewEntry(audit.OpSync, status).
+		WithResource(job.Table).
+		WithRecordsAffected(result.Records).
+		WithDuration(time.Since(start)).
+		WithMetadata("job", job.Name).
+		WithMetadata("scheduled", scheduled)
+	if err != nil {
+		record.WithError(err)
+	} else {
+		record.WithMetadata("last_sync_value", result.LastSyncValue)
+	}
+	s.audit(ctx, record)
+
+	if err != nil {
+		return fmt.Errorf("sync job %s: %w", job.Name, err)
+	}
+	return nil
+}
+
+// runBatches выполняет Run и сохраняет контрольные точки: одну партию
+// или, при CommitInterval, партии до исчерпания изменений. После ошибки
+// сохраняются партии, прочитанные до неё; ошибочную повторит следующий
+// запуск.
+func (s *Scheduler) runBatches(ctx context.Context, entry *scheduledEntry) (SyncResult, error) {
+	job := entry.job
+	state := entry.state.GetState(job.Table)
+	if job.CommitInterval <= 0 {
+		result, err := job.Run(ctx, state)
+		if err != nil {
+			return result, err
+		}
+		return result, saveSyncResult(entry.state, job.Table, result)
+	}
+
+	current := *state
+	total := SyncResult{LastSyncValue: current.LastSyncValue}
+	lastCommit := time.Now()
+	pending := false
+	for {
+		result, err := job.Run(ctx, &current)
+		if err != nil {
+			if pending {
+				if saveErr := saveSyncResult(entry.state, job.Table, total); saveErr != nil {
+					return total, fmt.Errorf("%w (failed to save checkpoint: %v)", err, saveErr)
+				}
+			}
+			return total, err
+		}
+		// Контрольная точка не сдвинулась — дальше читать нечего
+		// (например, партию целиком заняли строки с одним значением tracking field)
+		stalled := result.LastSyncValue == current.LastSyncValue
+		total.LastSyncValue = result.LastSyncValue
+		if result.LastDeleteValue != "" {
+			total.LastDeleteValue = result.LastDeleteValue
+			current.LastDeleteValue = result.LastDeleteValue
+		}
+		if result.Schema != nil {
+			total.Schema = result.Schema
+			current.SchemaHash = SchemaHash(*result.Schema)
+			current.Schema = result.Schema.Fields
+		}
+		total.Records += result.Records
+		current.LastSyncValue = result.LastSyncValue
+		pending = true
+
+		last := !result.More || stalled || ctx.Err() != nil
+		if last || time.Since(lastCommit) >= job.CommitInterval {
+			if err := saveSyncResult(entry.state, job.Table, total); err != nil {
+				return total, err
+			}
+			lastCommit, pending = time.Now(), false
+		}
+		if last {
+			return total, nil
+		}
+	}
+}
+
+// saveSyncResult сохраняет контрольные точки успешного запуска.
+func saveSyncResult(state *StateManager, table string, result SyncResult) error {
+	if err := state.UpdateState(table, result.LastSyncValue, result.Records); err != nil {
+		return err
+	}
+	if result.LastDeleteValue != "" {
+		if err := state.UpdateDeleteState(table, result.LastDeleteValue); err != nil {
+			return err
+		}
+	}
+	if result.Schema != nil {
+		return state.UpdateSchemaState(table, *result.Schema)
+	}
+	return nil
+}
+
+// audit записывает запись аудита; ошибка журнала не влияет на синхронизацию.
+func (s *Scheduler) audit(ctx context.Context, entry *audit.Entry) {
+	if s.config.Audit == nil {
+		return
+	}
+	_ = s.config.Audit.Log(context.WithoutCancel(ctx), entry) //nolint:errcheck // аудит не прерывает синхронизацию
+}
@@ -0,0 +1,271 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	stdsync "sync"
+	"testing"
+	"time"
+
+	"github.com/ruslano69/tdtp-framework/pkg/audit"
+)
+
+// memoryAppender собирает записи аудита.
+type memoryAppender struct {
+	mu      stdsync.Mutex
+	entries []*audit.Entry
+}
+
+func (m *memoryAppender) Append(_ context.Context, entry *audit.Entry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries = append(m.entries, entry)
+	return nil
+}
+
+func (m *memoryAppender) Close() error { return nil }
+
+func (m *memoryAppender) statuses() []audit.Status {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make([]audit.Status, len(m.entries))
+	for i, e := range m.entries {
+		out[i] = e.Status
+	}
+	return out
+}
+
+func TestScheduler_RunNowSavesState(t *testing.T) {
+	dir := t.TempDir()
+	appender := &memoryAppender{}
+	s := NewScheduler(SchedulerConfig{StateDir: dir, Audit: audit.NewLogger(audit.SyncConfig(), appender)})
+
+	var seen []string
+	fail := false
+	err := s.Add(ScheduledJob{
+		Name:     "orders",
+		Schedule: "*/5 * * * *",
+		Run: func(_ context.Context, state *SyncState) (SyncResult, error) {
+			seen = append(seen, state.LastSyncValue)
+			if fail {
+				return SyncResult{}, errors.New("source unavailable")
+			}
+			return SyncResult{LastSyncValue: "100", Records: 3}, nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+
+	ctx := context.Background()
+	if err := s.RunNow(ctx, "orders"); err != nil {
+		t.Fatalf("RunNow: %v", err)
+	}
+	fail = true
+	if err := s.RunNow(ctx, "orders"); err == nil {
+		t.Fatal("expected error from failing run")
+	}
+
+	if len(seen) != 2 || seen[0] != "" || seen[1] != "100" {
+		t.Errorf("checkpoints passed to Run: %q", seen)
+	}
+
+	// Ошибка не сдвигает контрольную точку; состояние — в файле задачи
+	sm, err := NewStateManager(filepath.Join(dir, "orders.json"), false)
+	if err != nil {
+		t.Fatalf("NewStateManager: %v", err)
+	}
+	state := sm.GetState("orders")
+	if state.LastSyncValue != "100" || state.RecordsExported != 3 || state.LastError != "source unavailable" {
+		t.Errorf("saved state: %+v", state)
+	}
+
+	got := appender.statuses()
+	if len(got) != 2 || got[0] != audit.StatusSuccess || got[1] != audit.StatusFailure {
+		t.Errorf("audit statuses: %v", got)
+	}
+}
+
+func TestScheduler_OverlapSkipped(t *testing.T) {
+	appender := &memoryAppender{}
+	s := NewScheduler(SchedulerConfig{StateDir: t.TempDir(), Audit: audit.NewLogger(audit.SyncConfig(), appender)})
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	err := s.Add(ScheduledJob{
+		Name:     "slow",
+		Schedule: "@every 1h",
+		Run: func(_ context.Context, _ *SyncState) (SyncResult, error) {
+			close(started)
+			<-release
+			return SyncResult{LastSyncValue: "1"}, nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- s.RunNow(context.Background(), "slow") }()
+	<-started
+
+	if err := s.RunNow(context.Background(), "slow"); !errors.Is(err, ErrJobRunning) {
+		t.Errorf("overlapping run: got %v, want ErrJobRunning", err)
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("first run: %v", err)
+	}
+
+	got := appender.statuses()
+	if len(got) != 2 || got[0] != audit.StatusSkipped || got[1] != audit.StatusSuccess {
+		t.Errorf("audit statuses: %v", got)
+	}
+}
+
+func TestScheduler_CommitIntervalDrains(t *testing.T) {
+	dir := t.TempDir()
+	s := NewScheduler(SchedulerConfig{StateDir: dir})
+
+	// Три полные партии и хвост; четвёртая партия падает при первом запуске
+	var seen []string
+	fail := true
+	err := s.Add(ScheduledJob{
+		Name:           "history",
+		Schedule:       "@hourly",
+		CommitInterval: time.Hour,
+		Run: func(_ context.Context, state *SyncState) (SyncResult, error) {
+			seen = append(seen, state.LastSyncValue)
+			switch state.LastSyncValue {
+			case "":
+				return SyncResult{LastSyncValue: "10", Records: 10, More: true}, nil
+			case "10":
+				return SyncResult{LastSyncValue: "20", Records: 10, More: true}, nil
+			case "20":
+				if fail {
+					return SyncResult{}, errors.New("connection reset")
+				}
+				return SyncResult{LastSyncValue: "25", Records: 5}, nil
+			}
+			return SyncResult{}, errors.New("unexpected checkpoint " + state.LastSyncValue)
+		},
+	})
+	if err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+
+	ctx := context.Background()
+	if err := s.RunNow(ctx, "history"); err == nil {
+		t.Fatal("expected error from failing batch")
+	}
+	// Прочитанные до ошибки партии зафиксированы, хотя CommitInterval не истёк
+	if state, _ := s.State("history"); state.LastSyncValue != "20" || state.LastError == "" {
+		t.Errorf("state after failure: %+v", state)
+	}
+
+	fail = false
+	if err := s.RunNow(ctx, "history"); err != nil {
+		t.Fatalf("RunNow: %v", err)
+	}
+	if len(seen) != 4 || seen[3] != "20" {
+		t.Errorf("checkpoints passed to Run: %q", seen)
+	}
+	sm, err := NewStateManager(filepath.Join(dir, "history.json"), false)
+	if err != nil {
+		t.Fatalf("NewStateManager: %v", err)
+	}
+	if state := sm.GetState("history"); state.LastSyncValue != "25" || state.RecordsExported != 5 || state.LastError != "" {
+		t.Errorf("saved state: %+v", state)
+	}
+}
+
+func TestScheduler_CommitIntervalStall(t *testing.T) {
+	s := NewScheduler(SchedulerConfig{StateDir: t.TempDir()})
+	runs := 0
+	err := s.Add(ScheduledJob{
+		Name:           "same-ts",
+		Schedule:       "@hourly",
+		CommitInterval: time.Nanosecond,
+		Run: func(context.Context, *SyncState) (SyncResult, error) {
+			runs++
+			// Партия заполнена, но контрольная точка не сдвигается
+			return SyncResult{LastSyncValue: "2024-01-01", Records: 100, More: true}, nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if err := s.RunNow(context.Background(), "same-ts"); err != nil {
+		t.Fatalf("RunNow: %v", err)
+	}
+	if runs != 2 {
+		t.Errorf("Run called %d times, want 2 (stop when the checkpoint stalls)", runs)
+	}
+}
+
+func TestScheduler_AddValidation(t *testing.T) {
+	run := func(context.Context, *SyncState) (SyncResult, error) { return SyncResult{}, nil }
+	s := NewScheduler(SchedulerConfig{StateDir: t.TempDir()})
+
+	tests := []struct {
+		name string
+		job  ScheduledJob
+	}{
+		{"bad schedule", ScheduledJob{Name: "a", Schedule: "every minute", Run: run}},
+		{"path in name", ScheduledJob{Name: "../a", Schedule: "@hourly", Run: run}},
+		{"no run", ScheduledJob{Name: "a", Schedule: "@hourly"}},
+		{"negative jitter", ScheduledJob{Name: "a", Schedule: "@hourly", Jitter: -time.Second, Run: run}},
+		{"negative commit interval", ScheduledJob{Name: "a", Schedule: "@hourly", CommitInterval: -time.Second, Run: run}},
+	}
+	for _, tt := range tests {
+		if err := s.Add(tt.job); err == nil {
+			t.Errorf("%s: expected error", tt.name)
+		}
+	}
+
+	if err := s.Add(ScheduledJob{Name: "a", Schedule: "0 2 * * *", Run: run}); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if err := s.Add(ScheduledJob{Name: "a", Schedule: "@hourly", Run: run}); err == nil {
+		t.Error("expected error for duplicate job")
+	}
+}
+
+func TestScheduler_StartStop(t *testing.T) {
+	s := NewScheduler(SchedulerConfig{StateDir: t.TempDir(), Location: time.UTC})
+	ran := make(chan struct{}, 1)
+	err := s.Add(ScheduledJob{
+		Name:     "tick",
+		Schedule: "@every 1s",
+		Run: func(context.Context, *SyncState) (SyncResult, error) {
+			select {
+			case ran <- struct{}{}:
+			default:
+			}
+			return SyncResult{LastSyncValue: "x"}, nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+
+	s.Start(context.Background())
+	if next, ok := s.NextRun("tick"); !ok || next.IsZero() {
+		t.Errorf("NextRun: %v %v", next, ok)
+	}
+	select {
+	case <-ran:
+	case <-time.After(5 * time.Second):
+		t.Fatal("job did not run on schedule")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.Stop(ctx); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	if state, _ := s.State("tick"); state.LastSyncValue != "x" {
+		t.Errorf("state: %+v", state)
+	}
+}
@@ -0,0 +1,217 @@
+package sync
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ruslano69/tdtp-framework/pkg/audit"
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+// Изменение схемы источника во время непрерывной синхронизации.
+//
+// Состояние задачи хранит последнюю известную схему таблицы и её хэш
+// (SchemaHash). Каждый запуск сравнивает с ними текущую схему источника;
+// при расхождении задача поступает по SchemaDriftPolicy, а событие
+// записывается в аудит (audit.OpSchemaChange).
+
+// SchemaDriftPolicy — реакция синхронизации на изменение схемы источника.
+type SchemaDriftPolicy string
+
+const (
+	// DriftPause — запуск завершается ошибкой ErrSchemaDrift, контрольная
+	// точка не сдвигается, пока схема не принята (accept) или приёмник не
+	// обновлён (migrate). Политика по умолчанию.
+	DriftPause SchemaDriftPolicy = "pause"
+
+	// DriftMigrate — новые колонки добавляются в таблицу приёмника
+	// (MigrateSchema), синхронизация продолжается. Удалённые и изменённые
+	// колонки автоматически не переносятся — запуск останавливается, как
+	// при DriftPause.
+	DriftMigrate SchemaDriftPolicy = "migrate"
+
+	// DriftAccept — новая схема принимается без изменения приёмника
+	// (например, пакеты импортируются с автоматическим созданием колонок).
+	DriftAccept SchemaDriftPolicy = "accept"
+)
+
+// ErrSchemaDrift — схема источника изменилась, и политика не позволяет
+// продолжить синхронизацию.
+var ErrSchemaDrift = errors.New("source schema changed")
+
+// ParseSchemaDriftPolicy разбирает политику: pause (по умолчанию), migrate,
+// accept.
+func ParseSchemaDriftPolicy(s string) (SchemaDriftPolicy, error) {
+	switch p := SchemaDriftPolicy(strings.ToLower(strings.TrimSpace(s))); p {
+	case "":
+		return DriftPause, nil
+	case DriftPause, DriftMigrate, DriftAccept:
+		return p, nil
+	}
+	return "", fmt.Errorf("unknown schema drift policy %q (pause, migrate, accept)", s)
+}
+
+// SchemaHash возвращает отпечаток схемы: имена (без учёта регистра), типы,
+// размеры и ключ полей в их порядке.
+func SchemaHash(schema packet.Schema) string {
+	h := sha256.New()
+	for _, f := range schema.Fields {
+		fmt.Fprintf(h, "%s\x1f%s\x1f%d\x1f%d\x1f%d\x1f%t\n",
+			strings.ToLower(f.Name), strings.ToUpper(f.Type), f.Length, f.Precision, f.Scale, f.Key)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// SchemaDrift — различие двух схем одной таблицы.
+type SchemaDrift struct {
+	Added   []packet.Field // колонки, которых не было
+	Removed []packet.Field // колонки, которых больше нет
+	Changed []packet.Field // колонки с другим типом, размером или ключом (новое описание)
+}
+
+// DiffSchema сравнивает прежнюю и текущую схемы по именам колонок (без учёта
+// регистра). Порядок колонок не учитывается.
+func DiffSchema(previous, current packet.Schema) SchemaDrift {
+	old := make(map[string]packet.Field, len(previous.Fields))
+	for _, f := range previous.Fields {
+		old[strings.ToLower(f.Name)] = f
+	}
+	var drift SchemaDrift
+	seen := make(map[string]bool, len(current.Fields))
+	for _, f := range current.Fields {
+		name := strings.ToLower(f.Name)
+		seen[name] = true
+		prev, ok := old[name]
+		switch {
+		case !ok:
+			drift.Added = append(drift.Added, f)
+		case !strings.EqualFold(prev.Type, f.Type) || prev.Length != f.Length ||
+			prev.Precision != f.Precision || prev.Scale != f.Scale || prev.Key != f.Key:
+			drift.Changed = append(drift.Changed, f)
+		}
+	}
+	for _, f := range previous.Fields {
+		if !seen[strings.ToLower(f.Name)] {
+			drift.Removed = append(drift.Removed, f)
+		}
+	}
+	return drift
+}
+
+// Empty сообщает, что схемы совпадают (с точностью до порядка колонок).
+func (d SchemaDrift) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// Additive сообщает, что колонки только добавлены — такое изменение
+// переносится на приёмник MigrateSchema.
+func (d SchemaDrift) Additive() bool {
+	return len(d.Added) > 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// String — краткое описание: "+email TEXT(255), -phone, ~age DECIMAL(10,2)".
+func (d SchemaDrift) String() string {
+	var parts []string
+	for _, f := range d.Added {
+		parts = append(parts, "+"+describeField(f))
+	}
+	for _, f := range d.Removed {
+		parts = append(parts, "-"+f.Name)
+	}
+	for _, f := range d.Changed {
+		parts = append(parts, "~"+describeField(f))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// describeField — имя и тип колонки с размером.
+func describeField(f packet.Field) string {
+	switch {
+	case f.Precision > 0:
+		return fmt.Sprintf("%s %s(%d,%d)", f.Name, f.Type, f.Precision, f.Scale)
+	case f.Length > 0:
+		return fmt.Sprintf("%s %s(%d)", f.Name, f.Type, f.Length)
+	}
+	return f.Name + " " + f.Type
+}
+
+// SchemaTarget — то, что миграции нужно от приёмника (реализуется
+// adapters.Adapter). Колонки добавляются, если приёмник реализует
+// AddColumns (adapters.ColumnAdder).
+type SchemaTarget interface {
+	TableExists(ctx context.Context, tableName string) (bool, error)
+	GetTableSchema(ctx context.Context, tableName string) (packet.Schema, error)
+}
+
+// columnAdder — приёмник, умеющий ALTER TABLE ... ADD COLUMN.
+type columnAdder interface {
+	AddColumns(ctx context.Context, tableName string, fields []packet.Field) error
+}
+
+// MigrateSchema переносит добавленные колонки drift в таблицу приёмника и
+// возвращает те, что действительно добавлены (уже существующие
+// пропускаются, повторный вызов безопасен). Отсутствующую таблицу создаст
+// импорт по схеме пакета. Удалённые и изменённые колонки не переносятся:
+// их изменение требует решения оператора.
+func MigrateSchema(ctx context.Context, target SchemaTarget, tableName string, drift SchemaDrift) ([]packet.Field, error) {
+	if len(drift.Removed) > 0 || len(drift.Changed) > 0 {
+		return nil, fmt.Errorf("%w: %s: only added columns can be migrated automatically (%s)", ErrSchemaDrift, tableName, drift)
+	}
+	if len(drift.Added) == 0 {
+		return nil, nil
+	}
+	exists, err := target.TableExists(ctx, tableName)
+	if err != nil || !exists {
+		return nil, err
+	}
+	schema, err := target.GetTableSchema(ctx, tableName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read target schema of %s: %w", tableName, err)
+	}
+	present := make(map[string]bool, len(schema.Fields))
+	for _, f := range schema.Fields {
+		present[strings.ToLower(f.Name)] = true
+	}
+	var missing []packet.Field
+	for _, f := range drift.Added {
+		if !present[strings.ToLower(f.Name)] {
+			f.Key = false // ключ существующей таблицы не меняется
+			missing = append(missing, f)
+		}
+	}
+	if len(missing) == 0 {
+		return nil, nil
+	}
+	adder, ok := target.(columnAdder)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s: target %T cannot add columns", ErrSchemaDrift, tableName, target)
+	}
+	if err := adder.AddColumns(ctx, tableName, missing); err != nil {
+		return nil, fmt.Errorf("failed to add columns to target table %s: %w", tableName, err)
+	}
+	return missing, nil
+}
+
+// SchemaDriftEntry — запись аудита о смене схемы таблицы: изменения,
+// политика и её исход (err == nil — синхронизация продолжена).
+func SchemaDriftEntry(tableName string, drift SchemaDrift, policy SchemaDriftPolicy, err error) *audit.Entry {
+	status := audit.StatusSuccess
+	if err != nil {
+		status = audit.StatusFailure
+	}
+	entry := audit.NewEntry(audit.OpSchemaChange, status).
+		WithResource(tableName).
+		WithMetadata("policy", string(policy)).
+		WithMetadata("changes", drift.String()).
+		WithMetadata("added", len(drift.Added)).
+		WithMetadata("removed", len(drift.Removed)).
+		WithMetadata("changed", len(drift.Changed))
+	if err != nil {
+		entry.WithError(err)
+	}
+	return entry
+}
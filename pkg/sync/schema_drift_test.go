@@ -0,0 +1,140 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+func driftTestSchema() packet.Schema {
+	return packet.Schema{Fields: []packet.Field{
+		{Name: "id", Type: "INTEGER", Key: true},
+		{Name: "name", Type: "TEXT", Length: 100},
+		{Name: "phone", Type: "TEXT", Length: 20},
+	}}
+}
+
+func TestDiffSchema(t *testing.T) {
+	previous := driftTestSchema()
+	current := packet.Schema{Fields: []packet.Field{
+		{Name: "ID", Type: "integer", Key: true},
+		{Name: "name", Type: "TEXT", Length: 200},
+		{Name: "email", Type: "TEXT", Length: 255},
+	}}
+
+	drift := DiffSchema(previous, current)
+	if got, want := drift.String(), "+email TEXT(255), -phone, ~name TEXT(200)"; got != want {
+		t.Errorf("drift = %q, want %q", got, want)
+	}
+	if drift.Empty() || drift.Additive() {
+		t.Errorf("drift %+v: removed and changed columns are not additive", drift)
+	}
+
+	// Порядок колонок не изменение схемы, но отпечаток другой
+	reordered := packet.Schema{Fields: []packet.Field{previous.Fields[2], previous.Fields[0], previous.Fields[1]}}
+	if !DiffSchema(previous, reordered).Empty() {
+		t.Error("reordered columns must not be a drift")
+	}
+	if SchemaHash(previous) == SchemaHash(reordered) {
+		t.Error("hash must depend on the column order")
+	}
+	if SchemaHash(previous) != SchemaHash(driftTestSchema()) {
+		t.Error("hash must be stable")
+	}
+}
+
+func TestParseSchemaDriftPolicy(t *testing.T) {
+	for in, want := range map[string]SchemaDriftPolicy{"": DriftPause, "Migrate": DriftMigrate, " accept ": DriftAccept} {
+		if got, err := ParseSchemaDriftPolicy(in); err != nil || got != want {
+			t.Errorf("%q: got %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseSchemaDriftPolicy("drop"); err == nil {
+		t.Error("unknown policy must fail")
+	}
+}
+
+// driftTarget — приёмник в памяти.
+type driftTarget struct {
+	schema packet.Schema
+	exists bool
+	added  []packet.Field
+}
+
+func (d *driftTarget) TableExists(context.Context, string) (bool, error) { return d.exists, nil }
+
+func (d *driftTarget) GetTableSchema(context.Context, string) (packet.Schema, error) {
+	return d.schema, nil
+}
+
+func (d *driftTarget) AddColumns(_ context.Context, _ string, fields []packet.Field) error {
+	d.added = append(d.added, fields...)
+	d.schema.Fields = append(d.schema.Fields, fields...)
+	return nil
+}
+
+// readOnlyTarget не умеет добавлять колонки.
+type readOnlyTarget struct{ SchemaTarget }
+
+func TestMigrateSchema(t *testing.T) {
+	ctx := context.Background()
+	target := &driftTarget{schema: driftTestSchema(), exists: true}
+	drift := SchemaDrift{Added: []packet.Field{
+		{Name: "email", Type: "TEXT", Length: 255},
+		{Name: "PHONE", Type: "TEXT", Length: 20}, // уже есть в приёмнике
+	}}
+
+	added, err := MigrateSchema(ctx, target, "users", drift)
+	if err != nil {
+		t.Fatalf("MigrateSchema: %v", err)
+	}
+	if len(added) != 1 || added[0].Name != "email" || len(target.added) != 1 {
+		t.Errorf("added %+v, target got %+v", added, target.added)
+	}
+	// Повторный вызов ничего не добавляет
+	if added, err = MigrateSchema(ctx, target, "users", drift); err != nil || len(added) != 0 {
+		t.Errorf("second migration: %+v, %v", added, err)
+	}
+
+	// Таблицу создаст импорт
+	if added, err = MigrateSchema(ctx, &driftTarget{}, "users", drift); err != nil || added != nil {
+		t.Errorf("missing table: %+v, %v", added, err)
+	}
+
+	removed := SchemaDrift{Removed: []packet.Field{{Name: "phone", Type: "TEXT"}}}
+	if _, err := MigrateSchema(ctx, target, "users", removed); !errors.Is(err, ErrSchemaDrift) {
+		t.Errorf("removed column: err = %v, want ErrSchemaDrift", err)
+	}
+	readOnly := readOnlyTarget{&driftTarget{schema: driftTestSchema(), exists: true}}
+	if _, err := MigrateSchema(ctx, readOnly, "users", drift); !errors.Is(err, ErrSchemaDrift) {
+		t.Errorf("target without AddColumns: err = %v, want ErrSchemaDrift", err)
+	}
+}
+
+func TestStateManager_SchemaSurvivesCheckpoints(t *testing.T) {
+	sm, err := NewStateManager(filepath.Join(t.TempDir(), "state.json"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sm.UpdateSchemaState("users", driftTestSchema()); err != nil {
+		t.Fatal(err)
+	}
+	if err := sm.UpdateState("users", "42", 10); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := NewStateManager(sm.GetStatePath(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	state := loaded.GetState("users")
+	if state.SchemaHash != SchemaHash(driftTestSchema()) || len(state.Schema) != 3 || state.LastSyncValue != "42" {
+		t.Errorf("state = %+v", state)
+	}
+	if drift := DiffSchema(packet.Schema{Fields: state.Schema}, driftTestSchema()); !drift.Empty() {
+		t.Errorf("saved schema differs: %s", drift)
+	}
+}
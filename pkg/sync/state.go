@@ -6,6 +6,8 @@ import (
 	"os"
 	"sync"
 	"time"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
 )
 
 // SyncState представляет состояние синхронизации для конкретной таблицы
@@ -15,6 +17,11 @@ type SyncState struct {
 	LastSyncTime    time.Time `json:"last_sync_time"`   // Время последней синхронизации
 	RecordsExported int64     `json:"records_exported"` // Количество экспортированных записей
 	LastError       string    `json:"last_error,omitempty"`
+
+	LastDeleteValue string `json:"last_delete_value,omitempty"` // Контрольная точка журнала удалений (DeleteLog)
+
+	SchemaHash string         `json:"schema_hash,omitempty"` // Отпечаток последней известной схемы источника (SchemaHash)
+	Schema     []packet.Field `json:"schema,omitempty"`      // Последняя известная схема источника
 }
 
 // StateManager управляет состоянием синхронизации для нескольких таблиц
@@ -75,6 +82,11 @@ func (sm *StateManager) UpdateState(tableName, lastSyncValue string, recordsExpo
 		LastSyncTime:    time.Now(),
 		RecordsExported: recordsExported,
 	}
+	if prev, ok := sm.states[tableName]; ok {
+		state.LastDeleteValue = prev.LastDeleteValue
+		state.SchemaHash = prev.SchemaHash
+		state.Schema = prev.Schema
+	}
 
 	sm.states[tableName] = state
 
@@ -85,6 +97,48 @@ func (sm *StateManager) UpdateState(tableName, lastSyncValue string, recordsExpo
 	return nil
 }
 
+// UpdateDeleteState сохраняет контрольную точку журнала удалений таблицы
+// (DeleteLog) — отдельно от LastSyncValue: журнал читается по своему
+// tracking-полю
+func (sm *StateManager) UpdateDeleteState(tableName, lastDeleteValue string) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	state, exists := sm.states[tableName]
+	if !exists {
+		state = &SyncState{TableName: tableName}
+		sm.states[tableName] = state
+	}
+	state.LastDeleteValue = lastDeleteValue
+
+	if sm.autoSave {
+		return sm.saveUnsafe()
+	}
+
+	return nil
+}
+
+// UpdateSchemaState запоминает схему источника таблицы — с ней сравнивается
+// схема следующего запуска (DiffSchema)
+func (sm *StateManager) UpdateSchemaState(tableName string, schema packet.Schema) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	state, exists := sm.states[tableName]
+	if !exists {
+		state = &SyncState{TableName: tableName}
+		sm.states[tableName] = state
+	}
+	state.SchemaHash = SchemaHash(schema)
+	state.Schema = schema.Fields
+
+	if sm.autoSave {
+		return sm.saveUnsafe()
+	}
+
+	return nil
+}
+
 // UpdateStateWithError обновляет состояние с информацией об ошибке
 func (sm *StateManager) UpdateStateWithError(tableName string, err error) error {
 	sm.mu.Lock()
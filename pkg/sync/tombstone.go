@@ -0,0 +1,265 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+// Перенос удалений при инкрементальной синхронизации (tombstones).
+//
+// Выгрузка по TrackingField видит только вставки и изменения: строка,
+// удалённая в источнике, просто пропадает из выборки и остаётся в приёмнике
+// навсегда. Удаления переносятся пакетами удаления (packet.NewDeletePacket,
+// Data delete="true") с первичными ключами удалённых строк; приёмник
+// применяет их после пакетов с данными (base.SplitDeletePackets).
+//
+// Источник удалений (DeleteDetection.Strategy):
+//   - DeleteSoft — мягкое удаление: строка остаётся в таблице с признаком
+//     (is_deleted = 1, deleted_at IS NOT NULL) и обновлённым TrackingField,
+//     поэтому попадает в инкрементальную выборку; такие строки уходят
+//     ключами в пакет удаления вместо данных;
+//   - DeleteLog — журнал удалений (CDC): отдельная таблица с ключевыми
+//     колонками и своим tracking-полем, которую заполняет триггер ON DELETE
+//     или CDC-процесс. Читается со своей контрольной точкой
+//     (SyncState.LastDeleteValue).
+
+// DeleteStrategy определяет, как обнаруживаются удалённые строки.
+type DeleteStrategy string
+
+const (
+	// DeleteNone - удаления не переносятся (по умолчанию)
+	DeleteNone DeleteStrategy = ""
+	// DeleteSoft - мягкое удаление по колонке-признаку
+	DeleteSoft DeleteStrategy = "soft"
+	// DeleteLog - журнал удалений (триггер / CDC)
+	DeleteLog DeleteStrategy = "log"
+)
+
+// tombstonesPerPacket — ключей в одном пакете удаления: пакет остаётся
+// в пределах обычного размера сообщения даже с составным ключом.
+const tombstonesPerPacket = 10000
+
+// DeleteDetection — настройка переноса удалений.
+type DeleteDetection struct {
+	Strategy DeleteStrategy
+
+	// Field - DeleteSoft: колонка признака удаления (is_deleted, deleted_at).
+	// DeleteLog: tracking-колонка журнала (пусто — TrackingField таблицы).
+	Field string
+
+	// Value - DeleteSoft: значение Field удалённой строки ("1" для BOOLEAN).
+	// Пусто — удалена любая строка с непустым Field (deleted_at).
+	Value string
+
+	// LogTable - DeleteLog: таблица журнала удалений
+	LogTable string
+}
+
+// ParseDeleteDetection разбирает спецификацию --sync-deletes:
+//
+//	soft:<field>[=<value>]   soft:is_deleted=1, soft:deleted_at
+//	log:<table>[=<field>]    log:orders_deleted, log:orders_deleted=deleted_at
+func ParseDeleteDetection(spec string) (DeleteDetection, error) {
+	if spec == "" {
+		return DeleteDetection{}, nil
+	}
+	kind, arg, _ := strings.Cut(spec, ":")
+	name, value, _ := strings.Cut(arg, "=")
+	var d DeleteDetection
+	switch DeleteStrategy(kind) {
+	case DeleteSoft:
+		d = DeleteDetection{Strategy: DeleteSoft, Field: name, Value: value}
+	case DeleteLog:
+		d = DeleteDetection{Strategy: DeleteLog, LogTable: name, Field: value}
+	default:
+		return DeleteDetection{}, fmt.Errorf("invalid delete detection %q (expected soft:<field>[=<value>] or log:<table>[=<field>])", spec)
+	}
+	return d, d.Validate()
+}
+
+// Validate проверяет корректность настройки.
+func (d DeleteDetection) Validate() error {
+	switch d.Strategy {
+	case DeleteNone:
+		return nil
+	case DeleteSoft:
+		if d.Field == "" {
+			return fmt.Errorf("delete field is required for soft delete detection")
+		}
+	case DeleteLog:
+		if d.LogTable == "" {
+			return fmt.Errorf("delete log table is required for log delete detection")
+		}
+	default:
+		return fmt.Errorf("invalid delete strategy: %s (supported: soft, log)", d.Strategy)
+	}
+	return nil
+}
+
+// IsDeleted сообщает, помечает ли значение колонки-признака строку как
+// удалённую (DeleteSoft).
+func (d DeleteDetection) IsDeleted(value string) bool {
+	if d.Value == "" {
+		return value != ""
+	}
+	return value == d.Value
+}
+
+// SplitSoftDeleted делит строки инкрементальной выборки на живые и ключи
+// мягко удалённых (DeleteSoft). schema должна содержать ключевые поля и
+// колонку-признак.
+func SplitSoftDeleted(schema packet.Schema, rows [][]string, d DeleteDetection) (live, keys [][]string, err error) {
+	flag := -1
+	var keyIdx []int
+	for i, f := range schema.Fields {
+		if f.Name == d.Field {
+			flag = i
+		}
+		if f.Key {
+			keyIdx = append(keyIdx, i)
+		}
+	}
+	if flag < 0 {
+		return nil, nil, fmt.Errorf("delete field '%s' not found in schema", d.Field)
+	}
+	if len(keyIdx) == 0 {
+		return nil, nil, fmt.Errorf("soft delete detection requires key fields in schema")
+	}
+	// NULL с маркером (SpecialValues) — как пустое значение: deleted_at не задан
+	null := ""
+	if sv := schema.Fields[flag].SpecialValues; sv != nil && sv.Null != nil {
+		null = sv.Null.Marker
+	}
+
+	live = rows[:0:0]
+	for _, row := range rows {
+		if flag >= len(row) || row[flag] == null || !d.IsDeleted(row[flag]) {
+			live = append(live, row)
+			continue
+		}
+		key := make([]string, len(keyIdx))
+		for j, idx := range keyIdx {
+			key[j] = row[idx]
+		}
+		keys = append(keys, key)
+	}
+	return live, keys, nil
+}
+
+// SplitSoftDeletedPackets применяет SplitSoftDeleted к пакетам выгрузки:
+// мягко удалённые строки убираются из пакетов с данными (опустевшие
+// пакеты отбрасываются), их ключи добавляются пакетами удаления в конец.
+// Возвращает пакеты и число удалённых строк.
+func SplitSoftDeletedPackets(packets []*packet.DataPacket, d DeleteDetection) ([]*packet.DataPacket, int, error) {
+	if len(packets) == 0 {
+		return packets, 0, nil
+	}
+	var keys [][]string
+	out := make([]*packet.DataPacket, 0, len(packets))
+	for _, pkt := range packets {
+		if pkt.Data.Compression != "" || pkt.Data.Encryption != "" {
+			return nil, 0, fmt.Errorf("packet %s: soft delete detection requires uncompressed, unencrypted rows", pkt.Header.MessageID)
+		}
+		live, deleted, err := SplitSoftDeleted(pkt.Schema, pkt.GetRows(), d)
+		if err != nil {
+			return nil, 0, err
+		}
+		keys = append(keys, deleted...)
+		if len(deleted) == 0 {
+			out = append(out, pkt)
+			continue
+		}
+		if len(live) > 0 {
+			pkt.SetRows(live)
+			out = append(out, pkt)
+		}
+	}
+
+	first := packets[0]
+	tombstones, err := TombstonePackets(first.Header.TableName, first.Schema, keys)
+	if err != nil {
+		return nil, 0, err
+	}
+	return append(out, tombstones...), len(keys), nil
+}
+
+// TombstonePackets строит пакеты удаления для keys (значения ключевых
+// полей schema в порядке схемы), не больше tombstonesPerPacket ключей в
+// пакете. Пустой keys — nil.
+func TombstonePackets(tableName string, schema packet.Schema, keys [][]string) ([]*packet.DataPacket, error) {
+	var packets []*packet.DataPacket
+	for start := 0; start < len(keys); start += tombstonesPerPacket {
+		end := min(start+tombstonesPerPacket, len(keys))
+		pkt, err := packet.NewDeletePacket(tableName, schema, keys[start:end])
+		if err != nil {
+			return nil, err
+		}
+		packets = append(packets, pkt)
+	}
+	return packets, nil
+}
+
+// DeleteLogSource — то, что нужно от источника для чтения журнала удалений
+// (реализуется adapters.Adapter).
+type DeleteLogSource interface {
+	ExportTableWithQuery(ctx context.Context, tableName string, query *packet.Query, sender, recipient string) ([]*packet.DataPacket, error)
+}
+
+// ReadDeleteLog читает записи журнала d.LogTable с tracking-полем больше
+// after (не больше limit, 0 — без ограничения) и возвращает ключи
+// удалённых строк в порядке keyFields и tracking-значение последней
+// записи — следующую контрольную точку (after, если записей нет).
+// Колонки ключа в журнале называются так же, как в таблице.
+func ReadDeleteLog(ctx context.Context, src DeleteLogSource, d DeleteDetection, trackingField string, keyFields []packet.Field, after string, limit int) ([][]string, string, error) {
+	if d.Field != "" {
+		trackingField = d.Field
+	}
+	query := packet.NewQuery()
+	if after != "" {
+		query.Filters = &packet.Filters{And: &packet.LogicalGroup{Filters: []packet.Filter{
+			{Field: trackingField, Operator: ">", Value: after},
+		}}}
+	}
+	query.OrderBy = &packet.OrderBy{Field: trackingField, Direction: "ASC"}
+	query.Limit = limit
+
+	packets, err := src.ExportTableWithQuery(ctx, d.LogTable, query, "tdtp-sync", "")
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read delete log %s: %w", d.LogTable, err)
+	}
+
+	var keys [][]string
+	last := after
+	for _, pkt := range packets {
+		idx := make(map[string]int, len(pkt.Schema.Fields))
+		for i, f := range pkt.Schema.Fields {
+			idx[f.Name] = i
+		}
+		tracking, ok := idx[trackingField]
+		if !ok {
+			return nil, "", fmt.Errorf("tracking field '%s' not found in delete log %s", trackingField, d.LogTable)
+		}
+		keyIdx := make([]int, len(keyFields))
+		for j, f := range keyFields {
+			if keyIdx[j], ok = idx[f.Name]; !ok {
+				return nil, "", fmt.Errorf("key field '%s' not found in delete log %s", f.Name, d.LogTable)
+			}
+		}
+		for _, row := range pkt.GetRows() {
+			key := make([]string, len(keyIdx))
+			for j, i := range keyIdx {
+				if i < len(row) {
+					key[j] = row[i]
+				}
+			}
+			keys = append(keys, key)
+			if tracking < len(row) {
+				last = row[tracking]
+			}
+		}
+	}
+	return keys, last, nil
+}
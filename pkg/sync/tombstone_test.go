@@ -0,0 +1,158 @@
+package sync
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+var tombstoneSchema = packet.Schema{Fields: []packet.Field{
+	{Name: "id", Type: "INTEGER", Key: true},
+	{Name: "name", Type: "TEXT"},
+	{Name: "deleted_at", Type: "TIMESTAMP"},
+	{Name: "updated_at", Type: "TIMESTAMP"},
+}}
+
+func TestParseDeleteDetection(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    DeleteDetection
+		wantErr bool
+	}{
+		{"", DeleteDetection{}, false},
+		{"soft:is_deleted=1", DeleteDetection{Strategy: DeleteSoft, Field: "is_deleted", Value: "1"}, false},
+		{"soft:deleted_at", DeleteDetection{Strategy: DeleteSoft, Field: "deleted_at"}, false},
+		{"log:orders_deleted=deleted_at", DeleteDetection{Strategy: DeleteLog, LogTable: "orders_deleted", Field: "deleted_at"}, false},
+		{"soft:", DeleteDetection{}, true},
+		{"cdc:orders", DeleteDetection{}, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseDeleteDetection(tt.spec)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q: err = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("%q: got %+v, want %+v", tt.spec, got, tt.want)
+		}
+	}
+}
+
+// TestSplitSoftDeleted — NULL-маркер признака считается пустым значением.
+func TestSplitSoftDeleted(t *testing.T) {
+	schema := tombstoneSchema
+	schema.Fields = append([]packet.Field(nil), schema.Fields...)
+	schema.Fields[2].SpecialValues = &packet.SpecialValues{Null: &packet.MarkerValue{Marker: "[NULL]"}}
+	rows := [][]string{
+		{"1", "Alice", "[NULL]", "t1"},
+		{"2", "Bob", "2025-01-02", "t2"},
+		{"3", "Carol", "", "t3"},
+	}
+
+	live, keys, err := SplitSoftDeleted(schema, rows, DeleteDetection{Strategy: DeleteSoft, Field: "deleted_at"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(live) != 2 || live[0][0] != "1" || live[1][0] != "3" {
+		t.Errorf("live = %v", live)
+	}
+	if !reflect.DeepEqual(keys, [][]string{{"2"}}) {
+		t.Errorf("keys = %v", keys)
+	}
+
+	if _, _, err := SplitSoftDeleted(schema, rows, DeleteDetection{Strategy: DeleteSoft, Field: "is_deleted"}); err == nil {
+		t.Error("expected error for missing delete field")
+	}
+}
+
+// TestSplitSoftDeletedPackets — опустевший пакет отбрасывается, ключи
+// уходят пакетом удаления в конец.
+func TestSplitSoftDeletedPackets(t *testing.T) {
+	gen := packet.NewGenerator()
+	first, err := gen.GenerateReference("users", tombstoneSchema, [][]string{{"1", "Alice", "", "t1"}, {"2", "Bob", "x", "t2"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := gen.GenerateReference("users", tombstoneSchema, [][]string{{"3", "Carol", "x", "t3"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	packets, deleted, err := SplitSoftDeletedPackets(append(first, second...), DeleteDetection{Strategy: DeleteSoft, Field: "deleted_at"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted != 2 || len(packets) != 2 {
+		t.Fatalf("deleted = %d, packets = %d", deleted, len(packets))
+	}
+	if rows := packets[0].GetRows(); len(rows) != 1 || rows[0][0] != "1" || packets[0].Header.RecordsInPart != 1 {
+		t.Errorf("data packet rows = %v", rows)
+	}
+	tomb := packets[1]
+	if !tomb.Data.Delete || tomb.Header.TableName != "users" {
+		t.Fatalf("last packet is not a tombstone: %+v", tomb.Header)
+	}
+	keys, err := tomb.DeleteKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(keys, [][]string{{"2"}, {"3"}}) {
+		t.Errorf("tombstone keys = %v", keys)
+	}
+}
+
+type fakeDeleteLog struct {
+	rows  [][]string
+	query *packet.Query
+}
+
+func (f *fakeDeleteLog) ExportTableWithQuery(_ context.Context, tableName string, query *packet.Query, _, _ string) ([]*packet.DataPacket, error) {
+	f.query = query
+	schema := packet.Schema{Fields: []packet.Field{{Name: "deleted_at", Type: "TIMESTAMP"}, {Name: "id", Type: "INTEGER"}}}
+	return packet.NewGenerator().GenerateReference(tableName, schema, f.rows)
+}
+
+func TestReadDeleteLog(t *testing.T) {
+	src := &fakeDeleteLog{rows: [][]string{{"t5", "7"}, {"t6", "9"}}}
+	d := DeleteDetection{Strategy: DeleteLog, LogTable: "users_deleted", Field: "deleted_at"}
+	keyFields := []packet.Field{{Name: "id", Type: "INTEGER", Key: true}}
+
+	keys, last, err := ReadDeleteLog(context.Background(), src, d, "updated_at", keyFields, "t4", 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(keys, [][]string{{"7"}, {"9"}}) || last != "t6" {
+		t.Errorf("keys = %v, last = %q", keys, last)
+	}
+	f := src.query.Filters.And.Filters[0]
+	if f.Field != "deleted_at" || f.Operator != ">" || f.Value != "t4" || src.query.Limit != 100 {
+		t.Errorf("query = %+v, filter = %+v", src.query, f)
+	}
+
+	src.rows = nil
+	if _, last, _ := ReadDeleteLog(context.Background(), src, d, "updated_at", keyFields, "t6", 0); last != "t6" {
+		t.Errorf("empty log moved checkpoint to %q", last)
+	}
+}
+
+// TestUpdateState_KeepsDeleteCheckpoint — контрольные точки данных и
+// журнала удалений двигаются независимо.
+func TestUpdateState_KeepsDeleteCheckpoint(t *testing.T) {
+	sm, err := NewStateManager(filepath.Join(t.TempDir(), "state.json"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sm.UpdateDeleteState("users", "t6"); err != nil {
+		t.Fatal(err)
+	}
+	if err := sm.UpdateState("users", "t9", 3); err != nil {
+		t.Fatal(err)
+	}
+	state := sm.GetState("users")
+	if state.LastSyncValue != "t9" || state.LastDeleteValue != "t6" {
+		t.Errorf("state = %+v", state)
+	}
+}
@@ -0,0 +1,304 @@
+// Package tracing — распределённая трассировка OpenTelemetry.
+//
+// Путь пакета TDTP от БД-источника через брокер до целевой БД собирается в
+// одну трассу: экспорт (ExportHelper) → публикация в брокер → получение →
+// импорт (ImportHelper). Контекст трассы переносится в заголовке пакета
+// расширениями traceparent/tracestate (W3C Trace Context, см.
+// packet.ExtTraceParent), поэтому трасса не рвётся ни на брокере, ни на
+// файле, переданном через съёмный носитель.
+//
+// Пока Init не вызван, используется no-op провайдер OpenTelemetry: спаны
+// ничего не стоят, а заголовки пакетов не меняются.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+// InstrumentationName — имя инструментирующей библиотеки в спанах.
+const InstrumentationName = "github.com/ruslano69/tdtp-framework"
+
+// Экспортёры спанов (Config.Exporter).
+const (
+	ExporterOTLP   = "otlp"   // OTLP/HTTP (Jaeger, Tempo, OpenTelemetry Collector)
+	ExporterStdout = "stdout" // JSON в stderr — для отладки
+)
+
+// Config — настройки трассировки.
+//
+//	tracing:
+//	  enabled: true
+//	  endpoint: otel-collector:4318
+//	  insecure: true
+//	  service_name: tdtp-orders-export
+//	  sample_ratio: 0.1
+type Config struct {
+	Enabled bool `yaml:"enabled"`
+
+	// Exporter — otlp (по умолчанию) или stdout
+	Exporter string `yaml:"exporter,omitempty"`
+
+	// Endpoint — host:port приёмника OTLP/HTTP. Пусто — переменные
+	// OTEL_EXPORTER_OTLP_ENDPOINT / OTEL_EXPORTER_OTLP_TRACES_ENDPOINT,
+	// затем localhost:4318
+	Endpoint string `yaml:"endpoint,omitempty"`
+
+	// Insecure — HTTP вместо HTTPS
+	Insecure bool `yaml:"insecure,omitempty"`
+
+	// ServiceName — service.name трасс (по умолчанию OTEL_SERVICE_NAME или tdtpcli)
+	ServiceName string `yaml:"service_name,omitempty"`
+
+	// SampleRatio — доля записываемых трасс, 0..1 (0 — все). Решение
+	// принимается в корне трассы: получатель пакета следует решению
+	// отправителя (ParentBased)
+	SampleRatio float64 `yaml:"sample_ratio,omitempty"`
+}
+
+// Validate проверяет настройки.
+func (c Config) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	switch strings.ToLower(c.Exporter) {
+	case "", ExporterOTLP, ExporterStdout:
+	default:
+		return fmt.Errorf("tracing: unknown exporter %q (valid: otlp, stdout)", c.Exporter)
+	}
+	if c.SampleRatio < 0 || c.SampleRatio > 1 {
+		return fmt.Errorf("tracing: sample_ratio must be between 0 and 1, got %g", c.SampleRatio)
+	}
+	return nil
+}
+
+// ConfigFromEnv включает трассировку по стандартным переменным окружения
+// OpenTelemetry, если в конфигурации её нет: задан OTEL_EXPORTER_OTLP_ENDPOINT
+// или OTEL_EXPORTER_OTLP_TRACES_ENDPOINT (адрес читает сам экспортёр).
+func ConfigFromEnv(cfg Config) Config {
+	if cfg.Enabled || strings.EqualFold(os.Getenv("OTEL_SDK_DISABLED"), "true") {
+		return cfg
+	}
+	if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" || os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != "" {
+		cfg.Enabled = true
+		cfg.Exporter = ExporterOTLP
+	}
+	return cfg
+}
+
+// Init устанавливает глобальный провайдер трассировки и propagator W3C
+// Trace Context. Возвращаемая shutdown выгружает накопленные спаны — её
+// нужно вызвать перед выходом из процесса. Для выключенной трассировки
+// shutdown ничего не делает.
+func Init(ctx context.Context, cfg Config) (shutdown func(context.Context) error, err error) {
+	noop := func(context.Context) error { return nil }
+	if !cfg.Enabled {
+		return noop, nil
+	}
+	if err := cfg.Validate(); err != nil {
+		return noop, err
+	}
+
+	var exporter sdktrace.SpanExporter
+	switch strings.ToLower(cfg.Exporter) {
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stderr))
+	default:
+		var opts []otlptracehttp.Option
+		if cfg.Endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpoint(cfg.Endpoint))
+		}
+		if cfg.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		exporter, err = otlptracehttp.New(ctx, opts...)
+	}
+	if err != nil {
+		return noop, fmt.Errorf("tracing: failed to create %s exporter: %w", cfg.Exporter, err)
+	}
+
+	serviceName := cfg.ServiceName
+	if serviceName == "" {
+		serviceName = os.Getenv("OTEL_SERVICE_NAME")
+	}
+	if serviceName == "" {
+		serviceName = "tdtpcli"
+	}
+	res, err := resource.Merge(resource.Default(),
+		resource.NewSchemaless(semconv.ServiceName(serviceName)))
+	if err != nil {
+		return noop, fmt.Errorf("tracing: failed to build resource: %w", err)
+	}
+
+	sampler := sdktrace.AlwaysSample()
+	if cfg.SampleRatio > 0 && cfg.SampleRatio < 1 {
+		sampler = sdktrace.TraceIDRatioBased(cfg.SampleRatio)
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sampler)),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	return provider.Shutdown, nil
+}
+
+// Tracer возвращает трассировщик фреймворка (глобальный провайдер).
+func Tracer() trace.Tracer {
+	return otel.Tracer(InstrumentationName)
+}
+
+// Start начинает внутренний спан — дочерний для спана из ctx.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// StartFromPacket начинает спан обработки полученного пакета. Родитель —
+// контекст трассы из заголовка пакета (спан отправителя), так что обработка
+// продолжает трассу отправителя. Если спан из ctx уже принадлежит этой
+// трассе (импорт внутри спана получения) или заголовок без контекста —
+// родитель спан из ctx; спан из ctx другой трассы становится ссылкой (link).
+func StartFromPacket(ctx context.Context, name string, h *packet.Header, kind trace.SpanKind, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	opts := []trace.SpanStartOption{trace.WithSpanKind(kind), trace.WithAttributes(attrs...)}
+	if h != nil {
+		local := trace.SpanContextFromContext(ctx)
+		remote := trace.SpanContextFromContext(Extract(context.Background(), h))
+		if remote.IsValid() && remote.TraceID() != local.TraceID() {
+			if local.IsValid() {
+				opts = append(opts, trace.WithLinks(trace.Link{SpanContext: local}))
+			}
+			ctx = trace.ContextWithRemoteSpanContext(ctx, remote)
+		}
+		opts = append(opts, trace.WithAttributes(PacketAttrs(h)...))
+	}
+	return Tracer().Start(ctx, name, opts...)
+}
+
+// StartPublish начинает спан отправки пакетов в брокер (producer). Контекст
+// из возвращённого ctx записывается в заголовки отправляемых пакетов (Inject).
+func StartPublish(ctx context.Context, system, destination string) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, "broker.publish", trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(MessagingAttrs(system, destination, "send")...))
+}
+
+// StartConsume начинает спан обработки полученного из брокера пакета
+// (consumer) — продолжение трассы отправителя.
+func StartConsume(ctx context.Context, h *packet.Header, system, destination string) (context.Context, trace.Span) {
+	return StartFromPacket(ctx, "broker.consume", h, trace.SpanKindConsumer, MessagingAttrs(system, destination, "receive")...)
+}
+
+// End завершает спан, отмечая ошибку err.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// ========== Контекст трассы в заголовке пакета ==========
+
+// headerCarrier — заголовок пакета как носитель контекста propagation.
+type headerCarrier struct {
+	h *packet.Header
+}
+
+func (c headerCarrier) Get(key string) string {
+	v, _ := c.h.Extension(key)
+	return v
+}
+
+// Set пишет расширение; значение, не прошедшее проверку реестра
+// расширений, не переносится (трасса просто рвётся на этом пакете).
+func (c headerCarrier) Set(key, value string) {
+	_ = c.h.SetExtension(key, value)
+}
+
+func (c headerCarrier) Keys() []string {
+	return c.h.Extensions.Keys()
+}
+
+// Inject записывает контекст трассы из ctx в заголовок пакета. Без
+// активного спана (или без Init) заголовок не меняется.
+func Inject(ctx context.Context, h *packet.Header) {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return
+	}
+	otel.GetTextMapPropagator().Inject(ctx, headerCarrier{h})
+}
+
+// InjectPackets записывает контекст трассы из ctx в заголовки пакетов.
+func InjectPackets(ctx context.Context, packets []*packet.DataPacket) {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return
+	}
+	for _, pkt := range packets {
+		Inject(ctx, &pkt.Header)
+	}
+}
+
+// Extract возвращает ctx с контекстом трассы из заголовка пакета.
+func Extract(ctx context.Context, h *packet.Header) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, headerCarrier{h})
+}
+
+// ========== Атрибуты ==========
+
+// Ключи атрибутов TDTP.
+const (
+	AttrTable     = attribute.Key("tdtp.table")
+	AttrMessageID = attribute.Key("tdtp.message_id")
+	AttrPart      = attribute.Key("tdtp.part")
+	AttrParts     = attribute.Key("tdtp.parts")
+	AttrPackets   = attribute.Key("tdtp.packets")
+	AttrRows      = attribute.Key("tdtp.rows")
+	AttrStrategy  = attribute.Key("tdtp.strategy")
+
+	AttrTrackingField = attribute.Key("tdtp.tracking_field")
+	AttrPlan          = attribute.Key("tdtql.plan") // packet.ExecutionPlan.Strategy
+)
+
+// PacketAttrs — атрибуты спана для пакета: таблица, MessageID, номер части.
+func PacketAttrs(h *packet.Header) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{AttrTable.String(h.TableName), AttrMessageID.String(h.MessageID)}
+	if h.TotalParts > 0 {
+		attrs = append(attrs, AttrPart.Int(h.PartNumber), AttrParts.Int(h.TotalParts))
+	}
+	return attrs
+}
+
+// SetPacketStats добавляет к спану число пакетов и строк.
+func SetPacketStats(span trace.Span, packets []*packet.DataPacket) {
+	rows := 0
+	for _, pkt := range packets {
+		rows += len(pkt.Data.Rows)
+	}
+	span.SetAttributes(AttrPackets.Int(len(packets)), AttrRows.Int(rows))
+}
+
+// MessagingAttrs — атрибуты спана брокера (семантические соглашения
+// OpenTelemetry для messaging): система (rabbitmq, kafka, ...), очередь и
+// операция (send, receive).
+func MessagingAttrs(system, destination, operation string) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		semconv.MessagingSystemKey.String(system),
+		semconv.MessagingDestinationName(destination),
+		semconv.MessagingOperationTypeKey.String(operation),
+	}
+}
@@ -0,0 +1,129 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+// useRecorder ставит глобальный провайдер, пишущий спаны в память.
+func useRecorder(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	prevProvider, prevPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prevProvider)
+		otel.SetTextMapPropagator(prevPropagator)
+	})
+	return recorder
+}
+
+func newTestPacket(t *testing.T) *packet.DataPacket {
+	t.Helper()
+	packets, err := packet.NewGenerator().GenerateReference("orders",
+		packet.Schema{Fields: []packet.Field{{Name: "id", Type: "INTEGER", Key: true}}}, [][]string{{"1"}, {"2"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return packets[0]
+}
+
+// TestPacketJourney_SingleTrace checks that the trace context survives XML
+// serialization: the consumer span continues the producer's trace and the
+// import span nests under the consumer span.
+func TestPacketJourney_SingleTrace(t *testing.T) {
+	recorder := useRecorder(t)
+	pkt := newTestPacket(t)
+
+	ctx, publish := Start(context.Background(), "broker.publish")
+	Inject(ctx, &pkt.Header)
+	publish.End()
+
+	gen := packet.NewGenerator()
+	data, err := gen.ToXML(pkt, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	received, err := packet.NewParser().ParseBytes(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	consumeCtx, consume := StartFromPacket(context.Background(), "broker.consume", &received.Header, trace.SpanKindConsumer)
+	_, imp := StartFromPacket(consumeCtx, "tdtp.import", &received.Header, trace.SpanKindInternal)
+	End(imp, errors.New("constraint violation"))
+	End(consume, nil)
+
+	spans := recorder.Ended()
+	if len(spans) != 3 {
+		t.Fatalf("got %d spans", len(spans))
+	}
+	pub, con, im := spans[0], spans[2], spans[1]
+	if con.SpanContext().TraceID() != pub.SpanContext().TraceID() || con.Parent().SpanID() != pub.SpanContext().SpanID() {
+		t.Errorf("consume span is not a child of publish span")
+	}
+	if im.Parent().SpanID() != con.SpanContext().SpanID() || len(im.Links()) != 0 {
+		t.Errorf("import span is not a child of consume span")
+	}
+	if im.Status().Code != codes.Error {
+		t.Errorf("import span status: %v", im.Status())
+	}
+}
+
+// TestStartFromPacket_LinksLocalTrace checks that a packet from another trace
+// becomes the parent and the local span a link.
+func TestStartFromPacket_LinksLocalTrace(t *testing.T) {
+	recorder := useRecorder(t)
+	pkt := newTestPacket(t)
+
+	producerCtx, producer := Start(context.Background(), "tdtp.export")
+	Inject(producerCtx, &pkt.Header)
+	producer.End()
+
+	localCtx, local := Start(context.Background(), "tdtpcli")
+	_, imp := StartFromPacket(localCtx, "tdtp.import", &pkt.Header, trace.SpanKindInternal)
+	imp.End()
+	local.End()
+
+	got := recorder.Ended()[1]
+	if got.Parent().SpanID() != producer.SpanContext().SpanID() {
+		t.Error("import span is not a child of the producer span")
+	}
+	if len(got.Links()) != 1 || got.Links()[0].SpanContext.SpanID() != local.SpanContext().SpanID() {
+		t.Errorf("links: %+v", got.Links())
+	}
+}
+
+// TestInject_NoSpan checks that without an active span the header is left
+// unchanged (tracing disabled).
+func TestInject_NoSpan(t *testing.T) {
+	useRecorder(t)
+	pkt := newTestPacket(t)
+	Inject(context.Background(), &pkt.Header)
+	if len(pkt.Header.Extensions) != 0 {
+		t.Errorf("extensions: %v", pkt.Header.Extensions)
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	if err := (Config{Enabled: true, Exporter: "zipkin"}).Validate(); err == nil {
+		t.Error("expected error for unknown exporter")
+	}
+	if err := (Config{Enabled: true, SampleRatio: 2}).Validate(); err == nil {
+		t.Error("expected error for sample_ratio > 1")
+	}
+	if err := (Config{Enabled: true, Exporter: ExporterStdout, SampleRatio: 0.5}).Validate(); err != nil {
+		t.Errorf("valid config: %v", err)
+	}
+}
@@ -102,6 +102,27 @@ func ToXLSX(pkt *packet.DataPacket, filePath, sheetName string) error {
 		_ = f.SetCellStyle(sheetName, cell, cell, headerStyle)
 	}
 
+	// Write data rows below the header
+	WriteRows(f, sheetName, 1, 2, pkt)
+
+	// Auto-fit columns
+	for col := range pkt.Schema.Fields {
+		colName := columnName(col + 1)
+		_ = f.SetColWidth(sheetName, colName, colName, 15)
+	}
+
+	// Save file
+	return f.SaveAs(filePath)
+}
+
+// WriteRows - write the rows of an uncompressed packet into an open workbook
+//
+// Rows go to sheet starting at the 1-based column col and row row, one
+// field per column in schema order, with the same typed conversion and
+// Excel traps as ToXLSX. Cells that already have a style (e.g. a formatted
+// report template row) keep it; unstyled cells get the number format of
+// the field type.
+func WriteRows(f *excelize.File, sheet string, col, row int, pkt *packet.DataPacket) {
 	// Pre-build schema.FieldDef slice for the core converter (reuse across rows)
 	pktParser := packet.NewParser()
 	conv := schema.NewConverter()
@@ -120,15 +141,16 @@ func ToXLSX(pkt *packet.DataPacket, filePath, sheetName string) error {
 	}
 
 	// Parse and write data rows using core framework primitives
-	for rowIdx, row := range pkt.Data.Rows {
+	pkt.MaterializeRows()
+	for rowIdx, r := range pkt.Data.Rows {
 		// GetRowValues handles escape sequences (\| inside field values)
-		values := pktParser.GetRowValues(row)
-		for col, fld := range pkt.Schema.Fields {
-			if col >= len(values) {
+		values := pktParser.GetRowValues(r)
+		for c, fld := range pkt.Schema.Fields {
+			if c >= len(values) {
 				continue
 			}
-			cell := columnName(col+1) + strconv.Itoa(rowIdx+2)
-			tv, err := conv.ParseValue(values[col], fieldDefs[col])
+			cell := columnName(col+c) + strconv.Itoa(row+rowIdx)
+			tv, err := conv.ParseValue(values[c], fieldDefs[c])
 			if err != nil || tv.IsNull {
 				// Leave cell blank — do not call SetCellValue
 				continue
@@ -145,24 +167,17 @@ func ToXLSX(pkt *packet.DataPacket, filePath, sheetName string) error {
 				// Use SetCellStr to guarantee the value is stored as text.
 				// This prevents Excel from interpreting strings starting with
 				// =, +, -, @ as formulas (formula injection trap).
-				_ = f.SetCellStr(sheetName, cell, cellVal.(string))
+				_ = f.SetCellStr(sheet, cell, cellVal.(string))
 				// Do NOT apply a numeric/date style to text-forced cells
 				// (e.g. pre-1900 date strings, big-integer strings).
 			} else {
-				_ = f.SetCellValue(sheetName, cell, cellVal)
-				applyCellFormat(f, sheetName, cell, fieldType)
+				_ = f.SetCellValue(sheet, cell, cellVal)
+				if style, _ := f.GetCellStyle(sheet, cell); style == 0 {
+					applyCellFormat(f, sheet, cell, fieldType)
+				}
 			}
 		}
 	}
-
-	// Auto-fit columns
-	for col := range pkt.Schema.Fields {
-		colName := columnName(col + 1)
-		_ = f.SetColWidth(sheetName, colName, colName, 15)
-	}
-
-	// Save file
-	return f.SaveAs(filePath)
 }
 
 // FromXLSX - convert XLSX file to TDTP packet
@@ -181,6 +196,42 @@ func ToXLSX(pkt *packet.DataPacket, filePath, sheetName string) error {
 //
 //	packet, err := xlsx.FromXLSX("input.xlsx", "Orders")
 func FromXLSX(filePath, sheetName string) (*packet.DataPacket, error) {
+	return FromXLSXWithOptions(filePath, sheetName, ImportOptions{})
+}
+
+// ImportOptions holds per-source options for FromXLSXWithOptions.
+type ImportOptions struct {
+	// NumberFormat parses numeric fields stored as text cells with locale
+	// separators ("1 234,56" from 1C or a localized Excel). Nil — text cells
+	// are taken as is. Numeric cells are never affected.
+	NumberFormat *schema.NumberFormat
+
+	// Booleans parses BOOLEAN text cells ("Да"/"Нет", "Y"/"N"). Nil — only
+	// TRUE/1 are true. Real boolean cells and 1/0 are always accepted; other
+	// text fails the import instead of silently becoming false.
+	Booleans *schema.BoolMapping
+
+	// Dates parses DATE/DATETIME/TIMESTAMP text cells written in a locale
+	// order ("31.12.2024", "12/31/2024"), per column. A schema.DateAuto column
+	// is resolved from all its text cells before the rows are read. Nil — text
+	// cells are taken as is. Date cells (Excel serials) are never affected.
+	Dates *schema.DateFormats
+
+	// Warn receives non-fatal import warnings, e.g. an auto-detected date
+	// column where no value tells the day from the month. Nil — dropped.
+	Warn func(msg string)
+}
+
+// FromXLSXWithOptions is FromXLSX with per-source import options.
+// A text cell in an INTEGER/REAL/DECIMAL column that does not match
+// opts.NumberFormat fails the import instead of being silently mangled, and
+// so does a text cell in a date column that does not match opts.Dates.
+//
+// Example:
+//
+//	ru, _ := schema.LookupNumberFormat("ru")
+//	packet, err := xlsx.FromXLSXWithOptions("1c_export.xlsx", "", xlsx.ImportOptions{NumberFormat: &ru})
+func FromXLSXWithOptions(filePath, sheetName string, opts ImportOptions) (*packet.DataPacket, error) {
 	f, err := excelize.OpenFile(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open file: %w", err)
@@ -218,6 +269,11 @@ func FromXLSX(filePath, sheetName string) (*packet.DataPacket, error) {
 		})
 	}
 
+	dateFormats, err := resolveDateFormats(rows, fields, opts)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create packet
 	pkt := packet.NewDataPacket(packet.TypeReference, sheetName)
 	pkt.Header.RecordsInPart = len(rows) - 1
@@ -245,6 +301,30 @@ func FromXLSX(filePath, sheetName string) (*packet.DataPacket, error) {
 				continue
 			}
 
+			if opts.NumberFormat != nil && raw != "" && isNumericType(field.Type) {
+				localized, err := localizedNumber(f, sheetName, col, rowIdx, raw, opts.NumberFormat)
+				if err != nil {
+					return nil, fmt.Errorf("row %d, field %s: %w", rowIdx+1, field.Name, err)
+				}
+				raw = localized
+			}
+
+			if opts.Booleans != nil && raw != "" && schema.NormalizeType(schema.DataType(field.Type)) == schema.TypeBoolean {
+				b, err := mappedBool(raw, opts.Booleans)
+				if err != nil {
+					return nil, fmt.Errorf("row %d, field %s: %w", rowIdx+1, field.Name, err)
+				}
+				raw = b
+			}
+
+			if df := dateFormats[col]; df != "" && isTextDate(raw) {
+				date, err := df.Normalize(raw, schema.DataType(field.Type))
+				if err != nil {
+					return nil, fmt.Errorf("row %d, field %s: %w", rowIdx+1, field.Name, err)
+				}
+				raw = date
+			}
+
 			values[col] = convertFromExcel(raw, schema.DataType(field.Type))
 		}
 
@@ -388,6 +468,107 @@ func convertFromExcel(value string, fieldType schema.DataType) string {
 	return value
 }
 
+// mappedBool converts a BOOLEAN cell by m ("Да" → "1"). Raw boolean cells
+// (1/0) and TRUE/FALSE pass as is.
+func mappedBool(raw string, m *schema.BoolMapping) (string, error) {
+	switch strings.ToUpper(raw) {
+	case "1", "0", "TRUE", "FALSE":
+		return raw, nil
+	}
+	b, err := m.Parse(raw)
+	if err != nil {
+		return "", err
+	}
+	if b {
+		return "1", nil
+	}
+	return "0", nil
+}
+
+// isNumericType reports whether a TDTP type holds a number.
+func isNumericType(fieldType string) bool {
+	switch schema.NormalizeType(schema.DataType(fieldType)) {
+	case schema.TypeInteger, schema.TypeReal, schema.TypeDecimal:
+		return true
+	}
+	return false
+}
+
+// isDateType reports whether a TDTP type holds a date.
+func isDateType(fieldType string) bool {
+	switch schema.NormalizeType(schema.DataType(fieldType)) {
+	case schema.TypeDate, schema.TypeDatetime, schema.TypeTimestamp:
+		return true
+	}
+	return false
+}
+
+// isTextDate reports whether a trimmed raw value of a date column is text
+// ("31.12.2024") rather than an Excel serial or an empty cell.
+func isTextDate(raw string) bool {
+	if raw == "" {
+		return false
+	}
+	_, err := strconv.ParseFloat(raw, 64)
+	return err != nil
+}
+
+// resolveDateFormats returns the date format of every column ("" — none),
+// detecting schema.DateAuto columns from their text cells. A column where
+// no value tells the day from the month falls back to dmy with a warning;
+// one mixing day-first and month-first dates fails the import.
+func resolveDateFormats(rows [][]string, fields []packet.Field, opts ImportOptions) ([]schema.DateFormat, error) {
+	formats := make([]schema.DateFormat, len(fields))
+	if opts.Dates == nil {
+		return formats, nil
+	}
+	for col, field := range fields {
+		if !isDateType(field.Type) {
+			continue
+		}
+		df := opts.Dates.For(field.Name)
+		if df != schema.DateAuto {
+			formats[col] = df
+			continue
+		}
+		var samples []string
+		for _, row := range rows[1:] {
+			if col < len(row) {
+				if raw := strings.TrimSpace(row[col]); isTextDate(raw) && !isExcelError(raw) {
+					samples = append(samples, raw)
+				}
+			}
+		}
+		detection, err := schema.DetectDateFormat(samples, "")
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", field.Name, err)
+		}
+		if detection.Ambiguous && opts.Warn != nil {
+			opts.Warn(fmt.Sprintf("field %s: day and month are ambiguous in every date (e.g. %q), read as %s; set a date format to confirm",
+				field.Name, detection.Example, detection.Format))
+		}
+		formats[col] = detection.Format
+	}
+	return formats, nil
+}
+
+// localizedNumber normalizes a text cell written in nf ("1 234,56" → "1234.56").
+// Numeric cells already hold the canonical raw value and are returned as is.
+func localizedNumber(f *excelize.File, sheet string, col, rowIdx int, raw string, nf *schema.NumberFormat) (string, error) {
+	cell, err := excelize.CoordinatesToCellName(col+1, rowIdx+1)
+	if err != nil {
+		return "", err
+	}
+	cellType, err := f.GetCellType(sheet, cell)
+	if err != nil {
+		return "", err
+	}
+	if cellType != excelize.CellTypeSharedString && cellType != excelize.CellTypeInlineString {
+		return raw, nil
+	}
+	return nf.Normalize(raw)
+}
+
 // isExcelError returns true for well-known Excel error cell values.
 // These must map to canonical NULL on import.
 func isExcelError(s string) bool {
@@ -3,11 +3,14 @@ package xlsx
 import (
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+	"github.com/ruslano69/tdtp-framework/pkg/core/schema"
+	"github.com/xuri/excelize/v2"
 )
 
 // makePacket builds a minimal DataPacket for roundtrip tests.
@@ -359,3 +362,166 @@ func TestIntegration_MultiColumn_NoErrors(t *testing.T) {
 		t.Errorf("row3 formula string: expected '=formula', got %q", cellValue(t, out, 3, 3))
 	}
 }
+
+// ── Localized numbers (FromXLSXWithOptions) ─────────────────────────────────
+
+// writeLocalizedSheet writes a sheet with numbers typed as text ("1 234,56")
+// next to a real numeric cell, as 1C and localized Excel exports do.
+func writeLocalizedSheet(t *testing.T, amount string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "1c.xlsx")
+	f := excelize.NewFile()
+	_ = f.SetCellStr("Sheet1", "A1", "qty (INTEGER)")
+	_ = f.SetCellStr("Sheet1", "B1", "amount (DECIMAL)")
+	_ = f.SetCellStr("Sheet1", "C1", "price (REAL)")
+	_ = f.SetCellStr("Sheet1", "A2", "12 000")
+	_ = f.SetCellStr("Sheet1", "B2", amount)
+	_ = f.SetCellValue("Sheet1", "C2", 1234.5) // numeric cell: raw value untouched
+	if err := f.SaveAs(path); err != nil {
+		t.Fatal(err)
+	}
+	_ = f.Close()
+	return path
+}
+
+func TestIntegration_LocalizedNumbers(t *testing.T) {
+	path := writeLocalizedSheet(t, "1 234,56")
+	ru, _ := schema.LookupNumberFormat("ru")
+
+	out, err := FromXLSXWithOptions(path, "Sheet1", ImportOptions{NumberFormat: &ru})
+	if err != nil {
+		t.Fatalf("FromXLSXWithOptions failed: %v", err)
+	}
+	for col, want := range []string{"12000", "1234.56", "1234.5"} {
+		if got := cellValue(t, out, 0, col); got != want {
+			t.Errorf("col %d: expected %q, got %q", col, want, got)
+		}
+	}
+
+	// Without a number format text cells are taken as is
+	out, err = FromXLSX(path, "Sheet1")
+	if err != nil {
+		t.Fatalf("FromXLSX failed: %v", err)
+	}
+	if got := cellValue(t, out, 0, 1); got != "1 234,56" {
+		t.Errorf("without number format: expected raw text, got %q", got)
+	}
+}
+
+func TestIntegration_LocalizedNumbers_Mismatch(t *testing.T) {
+	path := writeLocalizedSheet(t, "1.234,56")
+	en, _ := schema.LookupNumberFormat("en")
+
+	_, err := FromXLSXWithOptions(path, "Sheet1", ImportOptions{NumberFormat: &en})
+	if err == nil || !strings.Contains(err.Error(), "row 2") {
+		t.Errorf("expected error for numbers in the wrong locale, got %v", err)
+	}
+}
+
+func TestIntegration_MappedBooleans(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "flags.xlsx")
+	f := excelize.NewFile()
+	_ = f.SetCellStr("Sheet1", "A1", "active (BOOLEAN)")
+	_ = f.SetCellStr("Sheet1", "A2", "Да")
+	_ = f.SetCellStr("Sheet1", "A3", "нет")
+	_ = f.SetCellValue("Sheet1", "A4", true) // real boolean cell
+	_ = f.SetCellStr("Sheet1", "A5", "может быть")
+	if err := f.SaveAs(path); err != nil {
+		t.Fatal(err)
+	}
+	_ = f.Close()
+
+	m := &schema.BoolMapping{TrueValues: []string{"Да"}, FalseValues: []string{"Нет"}}
+	_, err := FromXLSXWithOptions(path, "Sheet1", ImportOptions{Booleans: m})
+	if err == nil || !strings.Contains(err.Error(), "row 5") {
+		t.Fatalf("expected error for unmapped value in row 5, got %v", err)
+	}
+
+	f, _ = excelize.OpenFile(path)
+	_ = f.RemoveRow("Sheet1", 5)
+	_ = f.Save()
+	_ = f.Close()
+
+	out, err := FromXLSXWithOptions(path, "Sheet1", ImportOptions{Booleans: m})
+	if err != nil {
+		t.Fatalf("FromXLSXWithOptions failed: %v", err)
+	}
+	for row, want := range []string{"1", "0", "1"} {
+		if got := cellValue(t, out, row, 0); got != want {
+			t.Errorf("row %d: expected %q, got %q", row, want, got)
+		}
+	}
+}
+
+// ── Localized dates (ImportOptions.Dates) ───────────────────────────────────
+
+// writeDateSheet writes text dates in two columns next to a real date cell.
+func writeDateSheet(t *testing.T, created, shipped []string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "dates.xlsx")
+	f := excelize.NewFile()
+	_ = f.SetCellStr("Sheet1", "A1", "created (DATE)")
+	_ = f.SetCellStr("Sheet1", "B1", "shipped (TIMESTAMP)")
+	_ = f.SetCellStr("Sheet1", "C1", "paid (DATE)")
+	for i := range created {
+		row := strconv.Itoa(i + 2)
+		_ = f.SetCellStr("Sheet1", "A"+row, created[i])
+		_ = f.SetCellStr("Sheet1", "B"+row, shipped[i])
+		_ = f.SetCellValue("Sheet1", "C"+row, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)) // date cell: serial
+	}
+	if err := f.SaveAs(path); err != nil {
+		t.Fatal(err)
+	}
+	_ = f.Close()
+	return path
+}
+
+func TestIntegration_LocalizedDates(t *testing.T) {
+	path := writeDateSheet(t,
+		[]string{"31.12.2024", "01.02.2024"},
+		[]string{"12/31/2024 10:30", "02/01/2024 08:00"})
+
+	var warnings []string
+	dates := &schema.DateFormats{Default: schema.DateDMY, Columns: map[string]schema.DateFormat{"shipped": schema.DateAuto}}
+	out, err := FromXLSXWithOptions(path, "Sheet1", ImportOptions{Dates: dates, Warn: func(msg string) { warnings = append(warnings, msg) }})
+	if err != nil {
+		t.Fatalf("FromXLSXWithOptions failed: %v", err)
+	}
+	want := [][]string{
+		{"2024-12-31", "2024-12-31T10:30:00Z", "2024-03-01"},
+		{"2024-02-01", "2024-02-01T08:00:00Z", "2024-03-01"},
+	}
+	for row, cols := range want {
+		for col, w := range cols {
+			if got := cellValue(t, out, row, col); got != w {
+				t.Errorf("row %d col %d: expected %q, got %q", row, col, w, got)
+			}
+		}
+	}
+	if len(warnings) != 0 {
+		t.Errorf("unexpected warnings: %v", warnings)
+	}
+}
+
+func TestIntegration_LocalizedDates_Ambiguous(t *testing.T) {
+	path := writeDateSheet(t, []string{"01/02/2024", "03/04/2024"}, []string{"", ""})
+
+	var warnings []string
+	dates := &schema.DateFormats{Default: schema.DateAuto}
+	out, err := FromXLSXWithOptions(path, "Sheet1", ImportOptions{Dates: dates, Warn: func(msg string) { warnings = append(warnings, msg) }})
+	if err != nil {
+		t.Fatalf("FromXLSXWithOptions failed: %v", err)
+	}
+	if got := cellValue(t, out, 0, 0); got != "2024-02-01" {
+		t.Errorf("ambiguous date read as %q, expected dmy fallback 2024-02-01", got)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "created") {
+		t.Errorf("expected one warning for created, got %v", warnings)
+	}
+
+	// Day-first and month-first dates in one column
+	path = writeDateSheet(t, []string{"31/12/2024", "12/31/2024"}, []string{"", ""})
+	if _, err := FromXLSXWithOptions(path, "Sheet1", ImportOptions{Dates: dates}); err == nil {
+		t.Error("expected error for mixed date formats")
+	}
+}
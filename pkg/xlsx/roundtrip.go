@@ -0,0 +1,290 @@
+package xlsx
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+	"github.com/ruslano69/tdtp-framework/pkg/core/schema"
+)
+
+// EditChanges - manual edits found in a re-imported XLSX compared with the
+// packet it was exported from (see CompareEdited).
+type EditChanges struct {
+	Table     string
+	Schema    packet.Schema // schema of the original packet
+	Modified  []EditedRow   // rows whose non-key cells were edited
+	Added     [][]string    // XLSX rows whose key is not in the original (original column order)
+	Removed   [][]string    // original rows whose key is missing from the XLSX
+	Unchanged int
+}
+
+// EditedRow - one edited row: key values (as in the original packet) and
+// the edited cells with original and new values.
+type EditedRow struct {
+	SheetRow int // 1-based row number in the sheet (header is row 1)
+	Key      []string
+	Changes  []packet.DeltaChange
+}
+
+// CompareEdited diffs an XLSX re-imported with FromXLSX against the original
+// packet it was exported from, matching rows by the original primary key.
+//
+// Cells are compared by value, not by text, so formatting the round trip
+// itself introduces is not reported as an edit:
+//   - numbers: 10.50 and 10.5 are equal; BIGINTs written as text compare as integers
+//   - dates/datetimes: compared to the second, ignoring time zone (Excel has neither)
+//   - booleans: TRUE / 1 / true are equal
+//   - blank cells equal NULL, [NULL] markers, and NaN/±Inf (written as blank by ToXLSX)
+//
+// Columns are matched by name, so reordered columns are fine; a column that
+// is not in the original schema is an error, a deleted column is not compared.
+// Key columns are never reported as edited: a row with an edited key appears
+// as one removed and one added row.
+func CompareEdited(original, edited *packet.DataPacket) (*EditChanges, error) {
+	if original == nil || edited == nil {
+		return nil, fmt.Errorf("packets cannot be nil")
+	}
+	if original.Data.Compression != "" || original.Data.Compact {
+		return nil, fmt.Errorf("original packet must be decompressed and expanded")
+	}
+
+	fields := original.Schema.Fields
+	keyIdx := make([]int, 0, 1)
+	for i, f := range fields {
+		if f.Key {
+			keyIdx = append(keyIdx, i)
+		}
+	}
+	if len(keyIdx) == 0 {
+		return nil, fmt.Errorf("original packet has no primary key: edits cannot be matched to rows")
+	}
+
+	// XLSX column → original field index
+	colMap := make([]int, len(edited.Schema.Fields))
+	for c, ef := range edited.Schema.Fields {
+		colMap[c] = slices.IndexFunc(fields, func(f packet.Field) bool { return strings.EqualFold(f.Name, ef.Name) })
+		if colMap[c] < 0 {
+			return nil, fmt.Errorf("column %q is not in the original table %s", ef.Name, original.Header.TableName)
+		}
+	}
+	for _, k := range keyIdx {
+		if !slices.Contains(colMap, k) {
+			return nil, fmt.Errorf("key column %q is missing from the sheet", fields[k].Name)
+		}
+	}
+
+	norm := newValueNormalizer(original.Schema)
+	rowKey := func(values []string) string {
+		parts := make([]string, len(keyIdx))
+		for i, k := range keyIdx {
+			parts[i] = norm.canonical(k, values[k])
+		}
+		return packet.JoinRowEscaped(parts)
+	}
+
+	originalRows := original.GetRows()
+	byKey := make(map[string]int, len(originalRows))
+	for i, row := range originalRows {
+		if len(row) != len(fields) {
+			return nil, fmt.Errorf("original row %d has %d values, expected %d", i, len(row), len(fields))
+		}
+		byKey[rowKey(row)] = i
+	}
+
+	changes := &EditChanges{Table: original.Header.TableName, Schema: original.Schema}
+	seen := make(map[string]int, len(byKey)) // key → sheet row
+	for r, editedRow := range edited.GetRows() {
+		sheetRow := r + 2
+
+		// Reorder into original column positions; present=false marks a deleted column
+		values := make([]string, len(fields))
+		present := make([]bool, len(fields))
+		for c, v := range editedRow {
+			if c < len(colMap) {
+				values[colMap[c]] = v
+				present[colMap[c]] = true
+			}
+		}
+
+		key := rowKey(values)
+		if prev, dup := seen[key]; dup {
+			return nil, fmt.Errorf("sheet rows %d and %d have the same key", prev, sheetRow)
+		}
+		seen[key] = sheetRow
+
+		o, ok := byKey[key]
+		if !ok {
+			changes.Added = append(changes.Added, values)
+			continue
+		}
+		orig := originalRows[o]
+
+		row := EditedRow{SheetRow: sheetRow}
+		for _, k := range keyIdx {
+			row.Key = append(row.Key, orig[k])
+		}
+		for i, f := range fields {
+			if f.Key || !present[i] || norm.canonical(i, orig[i]) == norm.canonical(i, values[i]) {
+				continue
+			}
+			row.Changes = append(row.Changes, packet.DeltaChange{
+				Field: f.Name,
+				Old:   orig[i],
+				New:   norm.tdtpValue(i, values[i]),
+			})
+		}
+		if len(row.Changes) > 0 {
+			changes.Modified = append(changes.Modified, row)
+		} else {
+			changes.Unchanged++
+		}
+	}
+
+	for _, row := range originalRows {
+		if _, ok := seen[rowKey(row)]; !ok {
+			changes.Removed = append(changes.Removed, row)
+		}
+	}
+	return changes, nil
+}
+
+// HasEdits reports whether any cell was edited (added/removed rows excluded)
+func (c *EditChanges) HasEdits() bool {
+	return len(c.Modified) > 0
+}
+
+// DeltaPacket returns an update-only delta packet with just the edited cells
+// (packet.NewDeltaPacket): importing it runs UPDATEs by key and cannot
+// overwrite or delete other rows. Added and removed rows are not included.
+func (c *EditChanges) DeltaPacket() (*packet.DataPacket, error) {
+	rows := make([]packet.DeltaRow, len(c.Modified))
+	for i, m := range c.Modified {
+		rows[i] = packet.DeltaRow{Key: m.Key, Changes: m.Changes}
+	}
+	return packet.NewDeltaPacket(c.Table, c.Schema, rows)
+}
+
+// Summary returns a human-readable list of edits for review before import
+func (c *EditChanges) Summary() string {
+	var sb strings.Builder
+	cells := 0
+	for _, m := range c.Modified {
+		cells += len(m.Changes)
+	}
+	fmt.Fprintf(&sb, "Table %s: %d edited row(s), %d edited cell(s), %d unchanged row(s)\n",
+		c.Table, len(c.Modified), cells, c.Unchanged)
+
+	keyNames := packet.ExtractKeyFields(c.Schema)
+	for _, m := range c.Modified {
+		key := make([]string, len(m.Key))
+		for i, v := range m.Key {
+			key[i] = keyNames[i] + "=" + v
+		}
+		fmt.Fprintf(&sb, "  row %d (%s):\n", m.SheetRow, strings.Join(key, ", "))
+		for _, ch := range m.Changes {
+			fmt.Fprintf(&sb, "    %s: %q → %q\n", ch.Field, ch.Old, ch.New)
+		}
+	}
+	if len(c.Added) > 0 {
+		fmt.Fprintf(&sb, "  ⚠ %d row(s) added in the sheet — not applied (update-only)\n", len(c.Added))
+	}
+	if len(c.Removed) > 0 {
+		fmt.Fprintf(&sb, "  ⚠ %d row(s) deleted from the sheet — not applied (update-only)\n", len(c.Removed))
+	}
+	return sb.String()
+}
+
+// valueNormalizer maps cell values of both sides to a canonical form
+type valueNormalizer struct {
+	fields []packet.Field
+	defs   []schema.FieldDef
+	conv   *schema.Converter
+}
+
+func newValueNormalizer(s packet.Schema) *valueNormalizer {
+	n := &valueNormalizer{fields: s.Fields, defs: make([]schema.FieldDef, len(s.Fields)), conv: schema.NewConverter()}
+	for i, f := range s.Fields {
+		n.defs[i] = schema.FieldDef{
+			Name:      f.Name,
+			Type:      schema.DataType(f.Type),
+			Length:    f.Length,
+			Precision: f.Precision,
+			Scale:     f.Scale,
+			Timezone:  f.Timezone,
+			Nullable:  true,
+		}
+	}
+	return n
+}
+
+// nullCanonical - canonical form of NULL and of values an XLSX cell cannot hold
+const nullCanonical = "\x00"
+
+// isNull reports values that ToXLSX writes as a blank cell
+func (n *valueNormalizer) isNull(i int, v string) bool {
+	if v == "" || v == packet.SpecNullMarker || v == nullCanonical {
+		return true
+	}
+	sv := n.fields[i].SpecialValues
+	if sv == nil {
+		return false
+	}
+	for _, m := range []*packet.MarkerValue{sv.Null, sv.NaN, sv.Infinity, sv.NegInfinity, sv.NoDate} {
+		if m != nil && v == m.Marker {
+			return true
+		}
+	}
+	return false
+}
+
+// canonical returns the value of field i in a form where equal values of
+// either side compare equal as strings
+func (n *valueNormalizer) canonical(i int, v string) string {
+	if n.isNull(i, v) {
+		return nullCanonical
+	}
+	if t := schema.NormalizeType(n.defs[i].Type); t == schema.TypeBoolean || t == schema.TypeBool {
+		// ToXLSX writes non 0/1 booleans ("true") as text, FromXLSX reads TRUE as 1
+		switch strings.ToLower(v) {
+		case "1", "true", "t", "yes":
+			return "true"
+		case "0", "false", "f", "no":
+			return "false"
+		}
+		return v
+	}
+	tv, err := n.conv.ParseValue(v, n.defs[i])
+	if err != nil || tv.IsNull {
+		return v
+	}
+	switch {
+	case tv.IntValue != nil:
+		return strconv.FormatInt(*tv.IntValue, 10)
+	case tv.FloatValue != nil:
+		if math.IsNaN(*tv.FloatValue) || math.IsInf(*tv.FloatValue, 0) {
+			return nullCanonical
+		}
+		return strconv.FormatFloat(*tv.FloatValue, 'g', -1, 64)
+	case tv.TimeValue != nil:
+		// Excel keeps neither zone nor sub-second precision
+		return tv.TimeValue.Format("2006-01-02T15:04:05")
+	case tv.StringValue != nil:
+		return *tv.StringValue
+	}
+	return v
+}
+
+// tdtpValue - edited value as written into the delta packet: blank cells
+// become the field's NULL marker when the original schema declares one
+func (n *valueNormalizer) tdtpValue(i int, v string) string {
+	if v == "" {
+		if sv := n.fields[i].SpecialValues; sv != nil && sv.Null != nil {
+			return sv.Null.Marker
+		}
+	}
+	return v
+}
@@ -0,0 +1,122 @@
+package xlsx
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ruslano69/tdtp-framework/pkg/core/packet"
+)
+
+// editPackets returns an original export and its XLSX re-import (as
+// FromXLSX builds it: columns reordered, values in Excel round-trip form)
+func editPackets(t *testing.T, editedRows [][]string) (*packet.DataPacket, *packet.DataPacket) {
+	t.Helper()
+	original := packet.NewDataPacket(packet.TypeReference, "orders")
+	original.Schema = packet.Schema{Fields: []packet.Field{
+		{Name: "id", Type: "INTEGER", Key: true},
+		{Name: "amount", Type: "DECIMAL", Precision: 10, Scale: 2, SpecialValues: &packet.SpecialValues{
+			Null: &packet.MarkerValue{Marker: packet.SpecNullMarker},
+		}},
+		{Name: "paid", Type: "BOOLEAN"},
+		{Name: "created", Type: "DATETIME"},
+		{Name: "note", Type: "TEXT"},
+	}}
+	original.SetRows([][]string{
+		{"1", "10.50", "true", "2024-01-02 10:00:00", "first"},
+		{"2", packet.SpecNullMarker, "0", "2024-01-03 11:30:00", ""},
+		{"3", "7", "1", "2024-01-04 12:00:00", "third"},
+	})
+
+	// Sheet columns: note, id, amount, paid, created
+	edited := packet.NewDataPacket(packet.TypeReference, "orders")
+	edited.Schema = packet.Schema{Fields: []packet.Field{
+		{Name: "note", Type: "TEXT"},
+		{Name: "id", Type: "INTEGER", Key: true},
+		{Name: "amount", Type: "DECIMAL"},
+		{Name: "paid", Type: "BOOLEAN"},
+		{Name: "created", Type: "DATETIME"},
+	}}
+	edited.SetRows(editedRows)
+	return original, edited
+}
+
+func TestCompareEdited_RoundTripFormattingIsNotAnEdit(t *testing.T) {
+	original, edited := editPackets(t, [][]string{
+		{"first", "1", "10.5", "1", "2024-01-02T10:00:00Z"},
+		{"", "2", "", "0", "2024-01-03T11:30:00Z"},
+		{"third", "3", "7.00", "1", "2024-01-04T12:00:00Z"},
+	})
+
+	changes, err := CompareEdited(original, edited)
+	if err != nil {
+		t.Fatalf("CompareEdited: %v", err)
+	}
+	if changes.HasEdits() || changes.Unchanged != 3 || len(changes.Added)+len(changes.Removed) != 0 {
+		t.Errorf("unexpected changes:\n%s", changes.Summary())
+	}
+}
+
+func TestCompareEdited_DeltaPacket(t *testing.T) {
+	original, edited := editPackets(t, [][]string{
+		{"first (checked)", "1", "", "1", "2024-01-02T10:00:00Z"}, // note edited, amount cleared
+		{"", "2", "", "0", "2024-01-03T11:30:00Z"},                // unchanged
+		{"new", "4", "1", "0", "2024-02-01T00:00:00Z"},            // added; row 3 deleted
+	})
+
+	changes, err := CompareEdited(original, edited)
+	if err != nil {
+		t.Fatalf("CompareEdited: %v", err)
+	}
+	if len(changes.Modified) != 1 || len(changes.Added) != 1 || len(changes.Removed) != 1 {
+		t.Fatalf("unexpected changes:\n%s", changes.Summary())
+	}
+
+	m := changes.Modified[0]
+	if m.SheetRow != 2 || m.Key[0] != "1" || len(m.Changes) != 2 {
+		t.Fatalf("modified row = %+v", m)
+	}
+	if c := m.Changes[0]; c.Field != "amount" || c.Old != "10.50" || c.New != packet.SpecNullMarker {
+		t.Errorf("amount change = %+v, want cleared cell as [NULL]", c)
+	}
+	if c := m.Changes[1]; c.Field != "note" || c.New != "first (checked)" {
+		t.Errorf("note change = %+v", c)
+	}
+
+	delta, err := changes.DeltaPacket()
+	if err != nil {
+		t.Fatalf("DeltaPacket: %v", err)
+	}
+	rows, err := delta.DeltaRows()
+	if err != nil || !delta.Data.Delta || len(rows) != 1 {
+		t.Fatalf("delta rows = %+v, %v", rows, err)
+	}
+
+	summary := changes.Summary()
+	for _, want := range []string{"1 edited row(s), 2 edited cell(s)", "id=1", "1 row(s) added", "1 row(s) deleted"} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("summary lacks %q:\n%s", want, summary)
+		}
+	}
+}
+
+func TestCompareEdited_Errors(t *testing.T) {
+	original, edited := editPackets(t, [][]string{
+		{"first", "1", "10.5", "1", "2024-01-02T10:00:00Z"},
+		{"again", "1", "10.5", "1", "2024-01-02T10:00:00Z"},
+	})
+	if _, err := CompareEdited(original, edited); err == nil || !strings.Contains(err.Error(), "same key") {
+		t.Errorf("expected duplicate key error, got %v", err)
+	}
+
+	original, edited = editPackets(t, nil)
+	edited.Schema.Fields[0].Name = "comment"
+	if _, err := CompareEdited(original, edited); err == nil || !strings.Contains(err.Error(), "comment") {
+		t.Errorf("expected unknown column error, got %v", err)
+	}
+
+	original, edited = editPackets(t, nil)
+	original.Schema.Fields[0].Key = false
+	if _, err := CompareEdited(original, edited); err == nil {
+		t.Error("expected error for original without primary key")
+	}
+}
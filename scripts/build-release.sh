@@ -0,0 +1,61 @@
+#!/bin/sh
+# Build self-contained tdtpcli / tdtpserve binaries for a driver preset.
+#
+# Usage: sh scripts/build-release.sh [full|minimal|cgo] [GOOS/GOARCH ...]
+#
+#   full     all adapters (postgres, mssql, mysql, mongodb, oracle, sqlite,
+#            access on Windows), S3 and Kafka. Every bundled driver is pure Go,
+#            so the build is static: CGO_ENABLED=0, no libc, no client libraries.
+#   minimal  -tags minimal: SQLite only, no S3, no Kafka.
+#   cgo      full set with CGO_ENABLED=1 and -tags sqlcipher: encrypted SQLite
+#            databases via go-sqlcipher (pkg/adapters/sqlite/cipher.go). Host
+#            platform only.
+#
+# Extra tags (e.g. production, nooracle) go through TAGS:
+#   TAGS="production nooracle" sh scripts/build-release.sh full linux/amd64
+#
+# Without platforms: linux/amd64 linux/arm64 windows/amd64 darwin/amd64 darwin/arm64.
+# Output: dist/<preset>/<binary>-<goos>-<goarch>[.exe] + checksums.txt; presets
+# other than full carry their name (tdtpcli-minimal-linux-amd64, checksums-minimal.txt)
+# so release assets of several presets do not collide.
+# Check what a binary contains with: tdtpcli --version / tdtpserve --version.
+
+set -e
+
+PRESET=${1:-full}
+[ $# -gt 0 ] && shift
+PLATFORMS=${*:-"linux/amd64 linux/arm64 windows/amd64 darwin/amd64 darwin/arm64"}
+
+case "$PRESET" in
+    full)    CGO=0; PRESET_TAGS="" ;;
+    minimal) CGO=0; PRESET_TAGS="minimal" ;;
+    cgo)     CGO=1; PRESET_TAGS="sqlcipher"; PLATFORMS="$(go env GOOS)/$(go env GOARCH)" ;;
+    *)
+        echo "unknown preset: $PRESET (full|minimal|cgo)" >&2
+        exit 2
+        ;;
+esac
+
+BUILD_TAGS=$(echo "$PRESET_TAGS $TAGS" | xargs | tr ' ' ',')
+OUT="dist/$PRESET"
+SUFFIX=""
+[ "$PRESET" != "full" ] && SUFFIX="-$PRESET"
+mkdir -p "$OUT"
+
+for platform in $PLATFORMS; do
+    GOOS=${platform%/*}
+    GOARCH=${platform#*/}
+    EXT=""
+    [ "$GOOS" = "windows" ] && EXT=".exe"
+
+    for cmd in tdtpcli tdtpserve; do
+        BIN="$OUT/$cmd$SUFFIX-$GOOS-$GOARCH$EXT"
+        echo "→ $BIN (tags: ${BUILD_TAGS:-none}, cgo=$CGO)"
+        CGO_ENABLED=$CGO GOOS=$GOOS GOARCH=$GOARCH \
+            go build -trimpath -tags "$BUILD_TAGS" -ldflags='-s -w' \
+            -o "$BIN" "./cmd/$cmd"
+    done
+done
+
+(cd "$OUT" && sha256sum -- tdtp* > "checksums$SUFFIX.txt")
+echo "✓ $OUT"